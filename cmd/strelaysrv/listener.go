@@ -5,6 +5,7 @@ package main
 import (
 	"crypto/tls"
 	"encoding/hex"
+	"io"
 	"log"
 	"net"
 	"sync"
@@ -20,6 +21,7 @@ var (
 	outboxesMut    = sync.RWMutex{}
 	outboxes       = make(map[syncthingprotocol.DeviceID]chan interface{})
 	numConnections atomic.Int64
+	numStreams     atomic.Int64
 )
 
 func listener(_, addr string, config *tls.Config, token string) {
@@ -81,7 +83,7 @@ func protocolConnectionHandler(tcpConn net.Conn, config *tls.Config, token strin
 	}
 
 	state := conn.ConnectionState()
-	if debug && state.NegotiatedProtocol != protocol.ProtocolName {
+	if debug && state.NegotiatedProtocol != protocol.ProtocolName && state.NegotiatedProtocol != protocol.ProtocolNameV2 {
 		log.Println("Protocol negotiation error")
 	}
 
@@ -106,7 +108,18 @@ func protocolConnectionHandler(tcpConn net.Conn, config *tls.Config, token strin
 	// return. Applies also when the connection gets closed, so the pattern
 	// below is to close the connection on error, then wait for the error
 	// signal from messageReader to exit.
-	go messageReader(conn, messages, errors)
+	//
+	// With protocol version 2, sessions can be joined as streams over this
+	// connection, so messages are read and written through a Mux.
+	var w io.Writer = conn
+	var mux *protocol.Mux
+	if state.NegotiatedProtocol == protocol.ProtocolNameV2 {
+		mux = protocol.NewMux(conn)
+		w = mux
+		go muxMessageReader(mux, messages, errors)
+	} else {
+		go messageReader(conn, messages, errors)
+	}
 
 	pingTicker := time.NewTicker(pingInterval)
 	defer pingTicker.Stop()
@@ -128,13 +141,13 @@ func protocolConnectionHandler(tcpConn net.Conn, config *tls.Config, token strin
 					if debug {
 						log.Printf("invalid token %s\n", msg.Token)
 					}
-					protocol.WriteMessage(conn, protocol.ResponseWrongToken)
+					protocol.WriteMessage(w, protocol.ResponseWrongToken)
 					conn.Close()
 					continue
 				}
 
 				if overLimit.Load() {
-					protocol.WriteMessage(conn, protocol.RelayFull{})
+					protocol.WriteMessage(w, protocol.RelayFull{})
 					if debug {
 						log.Println("Refusing join request from", id, "due to being over limits")
 					}
@@ -147,7 +160,7 @@ func protocolConnectionHandler(tcpConn net.Conn, config *tls.Config, token strin
 				_, ok := outboxes[id]
 				outboxesMut.RUnlock()
 				if ok {
-					protocol.WriteMessage(conn, protocol.ResponseAlreadyConnected)
+					protocol.WriteMessage(w, protocol.ResponseAlreadyConnected)
 					if debug {
 						log.Println("Already have a peer with the same ID", id, conn.RemoteAddr())
 					}
@@ -160,7 +173,7 @@ func protocolConnectionHandler(tcpConn net.Conn, config *tls.Config, token strin
 				outboxesMut.Unlock()
 				joined = true
 
-				protocol.WriteMessage(conn, protocol.ResponseSuccess)
+				protocol.WriteMessage(w, protocol.ResponseSuccess)
 
 			case protocol.ConnectRequest:
 				requestedPeer, err := syncthingprotocol.DeviceIDFromBytes(msg.ID)
@@ -168,7 +181,7 @@ func protocolConnectionHandler(tcpConn net.Conn, config *tls.Config, token strin
 					if debug {
 						log.Println(id, "is looking for an invalid peer ID")
 					}
-					protocol.WriteMessage(conn, protocol.ResponseNotFound)
+					protocol.WriteMessage(w, protocol.ResponseNotFound)
 					conn.Close()
 					continue
 				}
@@ -179,7 +192,7 @@ func protocolConnectionHandler(tcpConn net.Conn, config *tls.Config, token strin
 					if debug {
 						log.Println(id, "is looking for", requestedPeer, "which does not exist")
 					}
-					protocol.WriteMessage(conn, protocol.ResponseNotFound)
+					protocol.WriteMessage(w, protocol.ResponseNotFound)
 					conn.Close()
 					continue
 				}
@@ -191,7 +204,7 @@ func protocolConnectionHandler(tcpConn net.Conn, config *tls.Config, token strin
 				clientInvitation := ses.GetClientInvitationMessage()
 				serverInvitation := ses.GetServerInvitationMessage()
 
-				if err := protocol.WriteMessage(conn, clientInvitation); err != nil {
+				if err := protocol.WriteMessage(w, clientInvitation); err != nil {
 					if debug {
 						log.Printf("Error sending invitation from %s to client: %s", id, err)
 					}
@@ -210,10 +223,22 @@ func protocolConnectionHandler(tcpConn net.Conn, config *tls.Config, token strin
 					}
 
 				}
-				conn.Close()
+				if mux == nil {
+					conn.Close()
+				}
+				// Otherwise the client joins the session over this
+				// connection.
+
+			case protocol.JoinStreamRequest:
+				if mux == nil {
+					protocol.WriteMessage(w, protocol.ResponseUnexpectedMessage)
+					conn.Close()
+					continue
+				}
+				joinStream(mux, msg)
 
 			case protocol.Ping:
-				if err := protocol.WriteMessage(conn, protocol.Pong{}); err != nil {
+				if err := protocol.WriteMessage(w, protocol.Pong{}); err != nil {
 					if debug {
 						log.Println("Error writing pong:", err)
 					}
@@ -228,7 +253,7 @@ func protocolConnectionHandler(tcpConn net.Conn, config *tls.Config, token strin
 				if debug {
 					log.Printf("Unknown message %s: %T", id, message)
 				}
-				protocol.WriteMessage(conn, protocol.ResponseUnexpectedMessage)
+				protocol.WriteMessage(w, protocol.ResponseUnexpectedMessage)
 				conn.Close()
 			}
 
@@ -257,7 +282,7 @@ func protocolConnectionHandler(tcpConn net.Conn, config *tls.Config, token strin
 			return
 
 		case <-pingTicker.C:
-			if !joined {
+			if !joined && (mux == nil || mux.NumStreams() == 0) {
 				if debug {
 					log.Println(id, "didn't join within", pingInterval)
 				}
@@ -265,7 +290,7 @@ func protocolConnectionHandler(tcpConn net.Conn, config *tls.Config, token strin
 				continue
 			}
 
-			if err := protocol.WriteMessage(conn, protocol.Ping{}); err != nil {
+			if err := protocol.WriteMessage(w, protocol.Ping{}); err != nil {
 				if debug {
 					log.Println(id, err)
 				}
@@ -276,7 +301,7 @@ func protocolConnectionHandler(tcpConn net.Conn, config *tls.Config, token strin
 				if debug {
 					log.Println("Dropping", id, "as it has no sessions and we are over our limits")
 				}
-				protocol.WriteMessage(conn, protocol.RelayFull{})
+				protocol.WriteMessage(w, protocol.RelayFull{})
 				conn.Close()
 
 				limitCheckTimer.Reset(time.Second)
@@ -294,7 +319,7 @@ func protocolConnectionHandler(tcpConn net.Conn, config *tls.Config, token strin
 			if debug {
 				log.Printf("Sending message %T to %s", msg, id)
 			}
-			if err := protocol.WriteMessage(conn, msg); err != nil {
+			if err := protocol.WriteMessage(w, msg); err != nil {
 				if debug {
 					log.Println(id, err)
 				}
@@ -364,6 +389,47 @@ func sessionConnectionHandler(conn net.Conn) {
 	}
 }
 
+// joinStream adds a stream of the multiplexed connection to the session it
+// asks to join.
+func joinStream(mux *protocol.Mux, msg protocol.JoinStreamRequest) {
+	respond := func(resp protocol.Response) {
+		protocol.WriteMessage(mux, protocol.StreamResponse{Stream: msg.Stream, Code: resp.Code, Message: resp.Message})
+	}
+
+	ses := findSession(string(msg.Key))
+	if debug {
+		log.Println("Stream", msg.Stream, "session lookup", ses, hex.EncodeToString(msg.Key)[:5])
+	}
+	if ses == nil {
+		respond(protocol.ResponseNotFound)
+		return
+	}
+
+	stream, err := mux.AddStream(msg.Stream)
+	if err != nil {
+		if debug {
+			log.Println("Failed to add stream for session", ses, err)
+		}
+		respond(protocol.ResponseUnexpectedMessage)
+		return
+	}
+	if !ses.AddConnection(stream) {
+		if debug {
+			log.Println("Failed to add", stream, "to session", ses)
+		}
+		stream.Close()
+		respond(protocol.ResponseAlreadyConnected)
+		return
+	}
+	numStreams.Add(1)
+	go func() {
+		<-ses.done
+		numStreams.Add(-1)
+	}()
+
+	respond(protocol.ResponseSuccess)
+}
+
 func messageReader(conn net.Conn, messages chan<- interface{}, errors chan<- error) {
 	numConnections.Add(1)
 	defer numConnections.Add(-1)
@@ -377,3 +443,18 @@ func messageReader(conn net.Conn, messages chan<- interface{}, errors chan<- err
 		messages <- msg
 	}
 }
+
+func muxMessageReader(mux *protocol.Mux, messages chan<- interface{}, errors chan<- error) {
+	numConnections.Add(1)
+	defer numConnections.Add(-1)
+
+	for {
+		select {
+		case msg := <-mux.Messages():
+			messages <- msg
+		case <-mux.Done():
+			errors <- mux.Err()
+			return
+		}
+	}
+}
@@ -165,7 +165,7 @@ func main() {
 
 	tlsCfg := &tls.Config{
 		Certificates:           []tls.Certificate{cert},
-		NextProtos:             []string{protocol.ProtocolName},
+		NextProtos:             []string{protocol.ProtocolNameV2, protocol.ProtocolName},
 		ClientAuth:             tls.RequestClientCert,
 		SessionTicketsDisabled: true,
 		InsecureSkipVerify:     true,
@@ -53,6 +53,7 @@ func newSession(serverid, clientid syncthingprotocol.DeviceID, sessionLimitBps i
 		limiter:   sessionRateLimit,
 		connsChan: make(chan net.Conn),
 		conns:     make([]net.Conn, 0, 2),
+		done:      make(chan struct{}),
 	}
 
 	if debug {
@@ -118,6 +119,7 @@ type session struct {
 
 	connsChan chan net.Conn
 	conns     []net.Conn
+	done      chan struct{} // closed when the session has ended
 }
 
 func (s *session) AddConnection(conn net.Conn) bool {
@@ -125,10 +127,12 @@ func (s *session) AddConnection(conn net.Conn) bool {
 		log.Println("New connection for", s, "from", conn.RemoteAddr())
 	}
 
+	// The session may be busy taking the other connection. Each key can
+	// only be used once, so there are no more than two connections added.
 	select {
 	case s.connsChan <- conn:
 		return true
-	default:
+	case <-s.done:
 	}
 	return false
 }
@@ -213,6 +217,7 @@ done:
 	// If we are here because of case 2 or 3, we are potentially closing some or
 	// all connections a second time.
 	s.CloseConns()
+	close(s.done)
 
 	if debug {
 		log.Println("Session", s, "stopping")
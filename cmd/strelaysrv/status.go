@@ -53,6 +53,7 @@ func getStatus(w http.ResponseWriter, _ *http.Request) {
 	sessionMut.Unlock()
 	status["numConnections"] = numConnections.Load()
 	status["numProxies"] = numProxies.Load()
+	status["numStreams"] = numStreams.Load()
 	status["bytesProxied"] = bytesProxied.Load()
 	status["goVersion"] = runtime.Version()
 	status["goOS"] = runtime.GOOS
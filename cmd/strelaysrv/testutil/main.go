@@ -81,7 +81,7 @@ func main() {
 		}()
 
 		for {
-			conn, err := client.JoinSession(ctx, <-recv)
+			conn, err := relay.JoinSession(ctx, <-recv)
 			if err != nil {
 				log.Fatalln("Failed to join", err)
 			}
@@ -95,16 +95,11 @@ func main() {
 			log.Fatal(err)
 		}
 
-		invite, err := client.GetInvitationFromRelay(ctx, uri, id, []tls.Certificate{cert}, 10*time.Second)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		log.Println("Received invitation", invite)
-		conn, err := client.JoinSession(ctx, invite)
+		conn, invite, err := client.DialSession(ctx, uri, id, []tls.Certificate{cert}, 10*time.Second)
 		if err != nil {
 			log.Fatalln("Failed to join", err)
 		}
+		log.Println("Received invitation", invite)
 		log.Println("Joined", conn.RemoteAddr(), conn.LocalAddr())
 		connectToStdio(stdin, conn)
 		log.Println("Finished", conn.RemoteAddr(), conn.LocalAddr())
@@ -8,6 +8,7 @@
 package decrypt
 
 import (
+	"bytes"
 	"encoding/binary"
 	"encoding/json"
 	"errors"
@@ -31,7 +32,8 @@ type CLI struct {
 	Path       string `arg:"" required:"1" help:"Path to encrypted folder"`
 	To         string `xor:"mode" placeholder:"PATH" help:"Destination directory, when decrypting"`
 	VerifyOnly bool   `xor:"mode" help:"Don't write decrypted files to disk (but verify plaintext hashes)"`
-	Password   string `help:"Folder password for decryption / verification" env:"FOLDER_PASSWORD"`
+	Password   string `xor:"key" help:"Folder password for decryption / verification" env:"FOLDER_PASSWORD"`
+	Recovery   string `xor:"key" placeholder:"CODE" help:"Recovery code of the folder key, instead of the password" env:"FOLDER_RECOVERY_CODE"`
 	FolderID   string `help:"Folder ID of the encrypted folder, if it cannot be determined automatically"`
 	Continue   bool   `help:"Continue processing next file in case of error, instead of aborting"`
 	Verbose    bool   `help:"Show verbose progress information"`
@@ -58,22 +60,36 @@ func (c *CLI) Run() error {
 		c.TokenPath = filepath.Join(config.DefaultMarkerName, config.EncryptionTokenName)
 	}
 
+	token, tokenErr := c.readToken()
 	if c.FolderID == "" {
 		// We should try to figure out the folder ID
-		folderID, err := c.getFolderID()
-		if err != nil {
+		if tokenErr != nil {
 			log.Println("No --folder-id given and couldn't read folder token")
-			return fmt.Errorf("getting folder ID: %w", err)
+			return fmt.Errorf("getting folder ID: %w", tokenErr)
 		}
 
-		c.FolderID = folderID
+		c.FolderID = token.FolderID
 		if c.Verbose {
 			log.Println("Found folder ID:", c.FolderID)
 		}
 	}
 
 	c.keyGen = protocol.NewKeyGenerator()
-	c.folderKey = c.keyGen.KeyFromPassword(c.FolderID, c.Password)
+	if c.Recovery != "" {
+		key, err := protocol.KeyFromRecoveryCode(c.Recovery)
+		if err != nil {
+			return err
+		}
+		c.folderKey = key
+	} else {
+		c.folderKey = c.keyGen.KeyFromPassword(c.FolderID, c.Password)
+	}
+
+	// The token tells a wrong password right away, rather than failing on
+	// every file.
+	if tokenErr == nil && token.FolderID == c.FolderID && !bytes.Equal(token.Token, protocol.KeyToken(c.FolderID, c.folderKey)) {
+		return errors.New("the password or recovery code doesn't match the folder token")
+	}
 
 	return c.walk()
 }
@@ -114,21 +130,21 @@ func (c *CLI) withContinue(err error) error {
 	return err
 }
 
-// getFolderID returns the folder ID found in the encrypted token, or an
-// error.
-func (c *CLI) getFolderID() (string, error) {
+// readToken returns the encrypted token of the folder, with the folder ID,
+// or an error.
+func (c *CLI) readToken() (storedEncryptionToken, error) {
 	tokenPath := filepath.Join(c.Path, c.TokenPath)
 	bs, err := os.ReadFile(tokenPath)
 	if err != nil {
-		return "", fmt.Errorf("reading folder token: %w", err)
+		return storedEncryptionToken{}, fmt.Errorf("reading folder token: %w", err)
 	}
 
 	var tok storedEncryptionToken
 	if err := json.Unmarshal(bs, &tok); err != nil {
-		return "", fmt.Errorf("parsing folder token: %w", err)
+		return storedEncryptionToken{}, fmt.Errorf("parsing folder token: %w", err)
 	}
 
-	return tok.FolderID, nil
+	return tok, nil
 }
 
 // process handles the file named path in srcFs, decrypting it into dstFs
@@ -43,6 +43,7 @@ import (
 	"github.com/syncthing/syncthing/internal/db/sqlite"
 	"github.com/syncthing/syncthing/internal/slogutil"
 	_ "github.com/syncthing/syncthing/lib/automaxprocs"
+	"github.com/syncthing/syncthing/lib/backup"
 	"github.com/syncthing/syncthing/lib/build"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/dialer"
@@ -157,6 +158,7 @@ type serveCmd struct {
 	AuditFile                 string        `name:"auditfile" help:"Specify audit file (use \"-\" for stdout, \"--\" for stderr)" placeholder:"PATH" env:"STAUDITFILE"`
 	DBMaintenanceInterval     time.Duration `help:"Database maintenance interval" default:"8h" env:"STDBMAINTENANCEINTERVAL"`
 	DBDeleteRetentionInterval time.Duration `help:"Database deleted item retention interval" default:"10920h" env:"STDBDELETERETENTIONINTERVAL"`
+	DBBloomFilterMiB          int           `name:"db-bloom-filter-mib" help:"Memory for database lookup filters, in MiB (zero to disable)" default:"0" placeholder:"MIB" env:"STDBBLOOMFILTERMIB"`
 	GUIAddress                string        `name:"gui-address" help:"Override GUI address (e.g. \"http://192.0.2.42:8443\")" placeholder:"URL" env:"STGUIADDRESS"`
 	GUIAPIKey                 string        `name:"gui-apikey" help:"Override GUI API key" placeholder:"API-KEY" env:"STGUIAPIKEY"`
 	LogFile                   string        `name:"log-file" aliases:"logfile" help:"Log file name (see below)" default:"${logFile}" placeholder:"PATH" env:"STLOGFILE"`
@@ -172,6 +174,7 @@ type serveCmd struct {
 	NoRestart                 bool          `help:"Do not restart Syncthing when exiting due to API/GUI command, upgrade, or crash" env:"STNORESTART"`
 	NoUpgrade                 bool          `help:"Disable automatic upgrades" env:"STNOUPGRADE"`
 	Paused                    bool          `help:"Start with all devices and folders paused" env:"STPAUSED"`
+	RestoreBackup             string        `name:"restore-backup" help:"Restore configuration and database from backup file before starting (passphrase in $STBACKUPPASSPHRASE)" placeholder:"PATH" env:"STRESTOREBACKUP"`
 	Unpaused                  bool          `help:"Start with all devices and folders unpaused" env:"STUNPAUSED"`
 
 	// Debug options below
@@ -454,6 +457,10 @@ func (c *serveCmd) syncthingMain() {
 		os.Exit(1)
 	}
 
+	if c.RestoreBackup != "" {
+		cert = restoreBackup(c.RestoreBackup, cert)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -509,7 +516,7 @@ func (c *serveCmd) syncthingMain() {
 		os.Exit(1)
 	}
 
-	sdb, err := syncthing.OpenDatabase(locations.Get(locations.Database), c.DBDeleteRetentionInterval)
+	sdb, err := syncthing.OpenDatabase(locations.Get(locations.Database), c.DBDeleteRetentionInterval, int64(c.DBBloomFilterMiB)<<20)
 	if err != nil {
 		slog.Error("Error opening database", slogutil.Error(err))
 		os.Exit(1)
@@ -686,6 +693,32 @@ func auditWriter(auditFile string) io.Writer {
 	return fd
 }
 
+// restoreBackup restores the given backup and returns the restored
+// certificate. The backup file is renamed afterwards so that it isn't
+// restored again when Syncthing restarts.
+func restoreBackup(file string, cert tls.Certificate) tls.Certificate {
+	restored := file + ".restored"
+	if _, err := os.Stat(file); errors.Is(err, os.ErrNotExist) {
+		if _, err := os.Stat(restored); err == nil {
+			slog.Info("Backup was already restored", slogutil.FilePath(file))
+			return cert
+		}
+	}
+	if err := backup.Restore(file, os.Getenv("STBACKUPPASSPHRASE")); err != nil {
+		slog.Error("Failed to restore backup", slogutil.FilePath(file), slogutil.Error(err))
+		os.Exit(svcutil.ExitError.AsInt())
+	}
+	if err := os.Rename(file, restored); err != nil {
+		slog.Warn("Failed to rename restored backup", slogutil.FilePath(file), slogutil.Error(err))
+	}
+	cert, err := tls.LoadX509KeyPair(locations.Get(locations.CertFile), locations.Get(locations.KeyFile))
+	if err != nil {
+		slog.Error("Failed to load restored certificate", slogutil.Error(err))
+		os.Exit(svcutil.ExitError.AsInt())
+	}
+	return cert
+}
+
 func (c *serveCmd) autoUpgradePossible() bool {
 	if upgrade.DisabledByCompilation {
 		return false
@@ -739,6 +772,18 @@ func autoUpgrade(cfg config.Wrapper, app *syncthing.App, evLogger events.Logger)
 			continue
 		}
 
+		if opts.AutoUpgradePeerCheck != config.AutoUpgradePeerCheckOff {
+			incompatible := incompatibleUpgradePeers(app, rel)
+			for _, peer := range incompatible {
+				slog.Warn("Upgrade would be incompatible with remote device", slog.String("device", peer.DeviceID), slog.String("newVersion", rel.Tag), slog.String("reason", peer.Reason))
+			}
+			if len(incompatible) > 0 && opts.AutoUpgradePeerCheck == config.AutoUpgradePeerCheckDefer {
+				slog.Warn("Deferring automatic upgrade until remote devices are compatible", slog.String("newVersion", rel.Tag), slog.Int("devices", len(incompatible)))
+				timer.Reset(checkInterval)
+				continue
+			}
+		}
+
 		slog.Info("Automatic upgrade", "current", build.Version, "latest", rel.Tag)
 		err = upgrade.To(rel)
 		if err != nil {
@@ -754,6 +799,26 @@ func autoUpgrade(cfg config.Wrapper, app *syncthing.App, evLogger events.Logger)
 	}
 }
 
+// incompatibleUpgradePeers returns the devices that would be incompatible
+// with the release, going by the clients they last connected with.
+func incompatibleUpgradePeers(app *syncthing.App, rel upgrade.Release) []upgrade.Peer {
+	if app.Internals == nil {
+		return nil
+	}
+	devStats, err := app.Internals.DeviceStatistics()
+	if err != nil {
+		slog.Warn("Failed to get device statistics for upgrade check", slogutil.Error(err))
+		return nil
+	}
+	var peers []upgrade.Peer
+	for id, st := range devStats {
+		if peer, ok := upgrade.PeerFromClient(id.String(), st.LastClient); ok {
+			peers = append(peers, peer)
+		}
+	}
+	return upgrade.IncompatiblePeers(rel, peers)
+}
+
 func initialAutoUpgradeCheck(misc *db.Typed) (upgrade.Release, error) {
 	if last, ok, err := misc.Time(upgradeCheckKey); err == nil && ok && time.Since(last) < upgradeCheckInterval {
 		return upgrade.Release{}, errTooEarlyUpgradeCheck
@@ -94,6 +94,7 @@ angular.module('syncthing.core')
             FOLDER_SCAN_PROGRESS: 'FolderScanProgress',   // Emitted every ScanProgressIntervalS seconds, indicating how far into the scan it is at.
             FOLDER_PAUSED: 'FolderPaused',   // Emitted when a folder is paused
             FOLDER_RESUMED: 'FolderResumed',   // Emitted when a folder is resumed
+            FOLDER_HEALTH_CHANGED: 'FolderHealthChanged',   // Emitted when a folder becomes healthy or unhealthy, or the kinds of warnings about it change
 
             start: function () {
                 $http.get(urlbase + '/events?limit=1')
@@ -1025,10 +1025,10 @@ angular.module('syncthing.core')
             if (status === 'unknown') {
                 return 'info';
             }
-            if (status === 'stopped' || status === 'outofsync' || status === 'error' || status === 'faileditems' || status === 'localunencrypted') {
+            if (status === 'stopped' || status === 'outofsync' || status === 'error' || status === 'faileditems' || status === 'localunencrypted' || status === 'read-only') {
                 return 'danger';
             }
-            if (status === 'unshared' || status === 'scan-waiting' || status === 'sync-waiting' || status === 'clean-waiting') {
+            if (status === 'unshared' || status === 'scan-waiting' || status === 'sync-waiting' || status === 'clean-waiting' || status === 'dependency-waiting' || status === 'quota-exceeded') {
                 return 'warning';
             }
 
@@ -1214,6 +1214,7 @@ angular.module('syncthing.core')
         $scope.folderStatusIcon = function(cfg) {
             switch ($scope.folderStatus(cfg)) {
                 case 'clean-waiting':
+                case 'dependency-waiting':
                 case 'scan-waiting':
                 case 'sync-preparing':
                 case 'sync-waiting':
@@ -1229,6 +1230,10 @@ angular.module('syncthing.core')
                     return 'fa-check';
                 case 'paused':
                     return 'fa-pause';
+                case 'quota-exceeded':
+                    return 'fa-database';
+                case 'read-only':
+                    return 'fa-lock';
                 case 'scanning':
                     return 'fa-search';
                 case 'stopped':
@@ -1248,6 +1253,8 @@ angular.module('syncthing.core')
                     return $translate.instant('Waiting to Clean');
                 case 'cleaning':
                     return $translate.instant('Cleaning Versions');
+                case 'dependency-waiting':
+                    return $translate.instant('Waiting for Other Folder');
                 case 'faileditems':
                     return $translate.instant('Failed Items');
                 case 'idle':
@@ -1260,6 +1267,10 @@ angular.module('syncthing.core')
                     return $translate.instant('Out of Sync');
                 case 'paused':
                     return $translate.instant('Paused');
+                case 'quota-exceeded':
+                    return $translate.instant('Over Quota');
+                case 'read-only':
+                    return $translate.instant('Read-Only Filesystem');
                 case 'scan-waiting':
                     return $translate.instant('Waiting to Scan');
                 case 'scanning':
@@ -1410,6 +1421,27 @@ angular.module('syncthing.core')
             }
         }
 
+        // What a direction ("send" or "receive") of the primary connection
+        // to the device is limited by, or empty when idle or unknown.
+        $scope.bandwidthLimitedBy = function (deviceID, direction) {
+            var conn = $scope.connections[deviceID];
+            if (!conn || !conn.primary || !conn.primary.bandwidth) {
+                return '';
+            }
+            switch (conn.primary.bandwidth[direction].limitedBy) {
+                case "link":
+                    return $translate.instant('Network');
+                case "limiter":
+                    return $translate.instant('Rate Limit');
+                case "peer":
+                    return $translate.instant('Remote Device');
+                case "local":
+                    return $translate.instant('This Device');
+                default:
+                    return '';
+            }
+        };
+
         $scope.rdConnTypeIcon = function (type) {
             switch (type) {
             case "tcplan":
@@ -2378,7 +2410,7 @@ angular.module('syncthing.core')
             if (!$scope.currentFolder._guiVersioning) {
                 return false;
             }
-            return ['none', 'external'].indexOf($scope.currentFolder._guiVersioning.selector) === -1;
+            return ['none', 'external', 'systemtrash'].indexOf($scope.currentFolder._guiVersioning.selector) === -1;
         };
 
         function initVersioningEditing() {
@@ -2593,6 +2625,8 @@ angular.module('syncthing.core')
             case "external":
                 folderCfg.versioning.params.command = '' + folderCfg._guiVersioning.externalCommand;
                 break;
+            case "systemtrash":
+                break;
             default:
                 folderCfg.versioning = {type: ''};
             }
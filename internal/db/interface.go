@@ -60,6 +60,9 @@ type DB interface {
 	CountLocal(folder string, device protocol.DeviceID) (Counts, error)
 	CountNeed(folder string, device protocol.DeviceID) (Counts, error)
 	CountReceiveOnlyChanged(folder string) (Counts, error)
+	// The prefix variants count the named directory and everything below it
+	CountGlobalPrefix(folder, prefix string) (Counts, error)
+	CountNeedPrefix(folder string, device protocol.DeviceID, prefix string) (Counts, error)
 
 	// Index IDs
 	DropAllIndexIDs() error
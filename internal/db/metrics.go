@@ -188,6 +188,16 @@ func (m metricsDB) CountReceiveOnlyChanged(folder string) (Counts, error) {
 	return m.DB.CountReceiveOnlyChanged(folder)
 }
 
+func (m metricsDB) CountGlobalPrefix(folder, prefix string) (Counts, error) {
+	defer m.account(folder, "CountGlobalPrefix")()
+	return m.DB.CountGlobalPrefix(folder, prefix)
+}
+
+func (m metricsDB) CountNeedPrefix(folder string, device protocol.DeviceID, prefix string) (Counts, error) {
+	defer m.account(folder, "CountNeedPrefix")()
+	return m.DB.CountNeedPrefix(folder, device, prefix)
+}
+
 func (m metricsDB) GetDeviceSequence(folder string, device protocol.DeviceID) (int64, error) {
 	defer m.account(folder, "GetDeviceSequence")()
 	return m.DB.GetDeviceSequence(folder, device)
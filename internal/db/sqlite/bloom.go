@@ -0,0 +1,112 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package sqlite
+
+import (
+	"hash/maphash"
+	"math"
+	"sync/atomic"
+)
+
+const (
+	// Bits per item for a false positive rate of about one percent, and
+	// the least we accept when memory is tight (about fifteen percent).
+	bloomBitsPerItem    = 10
+	bloomMinBitsPerItem = 4
+)
+
+var bloomSeed1, bloomSeed2 = maphash.MakeSeed(), maphash.MakeSeed()
+
+// bloomFilter is a Bloom filter that is safe for concurrent use. It answers
+// whether a key may have been added, with false positives but never false
+// negatives.
+type bloomFilter struct {
+	words    []atomic.Uint64
+	k        uint64
+	capacity int64
+	added    atomic.Int64
+}
+
+// newBloomFilter returns a filter for the given number of items using the
+// given number of bits, rounded up to whole words.
+func newBloomFilter(capacity, bits int64) *bloomFilter {
+	capacity = max(capacity, 1)
+	words := max((bits+63)/64, 1)
+	k := uint64(math.Round(float64(words*64) / float64(capacity) * math.Ln2))
+	return &bloomFilter{
+		words:    make([]atomic.Uint64, words),
+		k:        min(max(k, 1), 16),
+		capacity: capacity,
+	}
+}
+
+func (b *bloomFilter) add(key []byte) {
+	h1, h2 := bloomHashes(key)
+	m := uint64(len(b.words)) * 64
+	for i := range b.k {
+		bit := (h1 + i*h2) % m
+		b.words[bit/64].Or(1 << (bit % 64))
+	}
+	b.added.Add(1)
+}
+
+func (b *bloomFilter) mayContain(key []byte) bool {
+	h1, h2 := bloomHashes(key)
+	m := uint64(len(b.words)) * 64
+	for i := range b.k {
+		bit := (h1 + i*h2) % m
+		if b.words[bit/64].Load()&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// overfull returns true when so many more items than planned for have been
+// added that the false positive rate is poor.
+func (b *bloomFilter) overfull() bool {
+	return b.added.Load() > 2*b.capacity
+}
+
+// bytes returns the memory used by the filter.
+func (b *bloomFilter) bytes() int64 {
+	return int64(len(b.words)) * 8
+}
+
+func bloomHashes(key []byte) (uint64, uint64) {
+	// The second hash is odd so that it's never a multiple of the filter size.
+	return maphash.Bytes(bloomSeed1, key), maphash.Bytes(bloomSeed2, key) | 1
+}
+
+// bloomBudget limits the total memory used by the filters of all folders.
+type bloomBudget struct {
+	limit int64
+	used  atomic.Int64
+}
+
+// reserve returns the number of bits to use for a filter for the given
+// number of items, reserving the memory for it, or zero if there isn't
+// enough memory left for a useful filter.
+func (b *bloomBudget) reserve(items int64) int64 {
+	want := max(items, 1) * bloomBitsPerItem
+	for {
+		used := b.used.Load()
+		avail := (b.limit - used) * 8
+		bits := min(want, avail)
+		if bits < max(items, 1)*bloomMinBitsPerItem {
+			return 0
+		}
+		bits = (bits + 63) / 64 * 64
+		if b.used.CompareAndSwap(used, used+bits/8) {
+			return bits
+		}
+	}
+}
+
+func (b *bloomBudget) release(bytes int64) {
+	b.used.Add(-bytes)
+}
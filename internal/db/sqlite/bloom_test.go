@@ -0,0 +1,138 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package sqlite
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/itererr"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestBloomFilter(t *testing.T) {
+	t.Parallel()
+
+	const items = 10000
+	f := newBloomFilter(items, items*bloomBitsPerItem)
+
+	key := func(i int) []byte {
+		return binary.BigEndian.AppendUint64(nil, uint64(i))
+	}
+	for i := range items {
+		f.add(key(i))
+	}
+	for i := range items {
+		if !f.mayContain(key(i)) {
+			t.Fatal("false negative for", i)
+		}
+	}
+
+	fps := 0
+	for i := items; i < 2*items; i++ {
+		if f.mayContain(key(i)) {
+			fps++
+		}
+	}
+	// About one percent is expected; allow some slack.
+	if rate := float64(fps) / items; rate > 0.03 {
+		t.Errorf("false positive rate %.3f is too high", rate)
+	}
+	if f.overfull() {
+		t.Error("should not be overfull at capacity")
+	}
+}
+
+func TestBloomBudget(t *testing.T) {
+	t.Parallel()
+
+	b := &bloomBudget{limit: 1000} // 8000 bits
+
+	// Gets all it asks for.
+	bits := b.reserve(500)
+	if bits != 5056 { // 5000 rounded up to whole words
+		t.Fatal("unexpected bits", bits)
+	}
+	// Gets what is left, as that is still at least the minimum.
+	bits = b.reserve(500)
+	if bits != 2944 {
+		t.Fatal("unexpected bits", bits)
+	}
+	// Nothing left.
+	if bits := b.reserve(10); bits != 0 {
+		t.Fatal("expected no bits, got", bits)
+	}
+	b.release(2944 / 8)
+	if bits := b.reserve(10); bits != 128 {
+		t.Fatal("unexpected bits", bits)
+	}
+}
+
+func TestBloomLookups(t *testing.T) {
+	t.Parallel()
+
+	sdb, err := Open(t.TempDir(), WithBloomFilterMemory(1<<20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := sdb.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	files := []protocol.FileInfo{
+		genFile("a", 3, 0),
+		genFile("b", 3, 0),
+	}
+	if err := sdb.Update(folderID, protocol.LocalDeviceID, files[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	// Wait for the initial build to complete
+	fdb, err := sdb.getFolderDB(folderID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for fdb.blooms.check(bloomNames, []byte("a")) == bloomUnknown {
+		time.Sleep(10 * time.Millisecond)
+	}
+	for fdb.blooms.running.Load() {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// Files added both before and after the build are found
+	if err := sdb.Update(folderID, protocol.LocalDeviceID, files[1:]); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range files {
+		if _, ok, err := sdb.GetDeviceFile(folderID, protocol.LocalDeviceID, f.Name); err != nil || !ok {
+			t.Fatal("expected to find", f.Name, err)
+		}
+		vals, err := itererr.Collect(sdb.AllLocalBlocksWithHash(folderID, f.Blocks[0].Hash))
+		if err != nil || len(vals) != 1 {
+			t.Fatal("expected one block for", f.Name, err)
+		}
+		metas, err := itererr.Collect(sdb.AllLocalFilesWithBlocksHash(folderID, protocol.BlocksHash(f.Blocks)))
+		if err != nil || len(metas) != 1 {
+			t.Fatal("expected one file for", f.Name, err)
+		}
+	}
+
+	// Nonexistent keys are not
+	if fdb.blooms.check(bloomNames, []byte("nonexistent")) == bloomUnknown {
+		t.Fatal("expected a filter")
+	}
+	if _, ok, err := sdb.GetDeviceFile(folderID, protocol.LocalDeviceID, "nonexistent"); err != nil || ok {
+		t.Fatal("expected not found", err)
+	}
+	vals, err := itererr.Collect(sdb.AllLocalBlocksWithHash(folderID, []byte("nonexistent")))
+	if err != nil || len(vals) != 0 {
+		t.Fatal("expected no blocks", err)
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package sqlite
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// BackupInto writes a copy of the main and folder databases into dir,
+// using the same layout as the database directory. Each database is
+// copied consistently while the database remains in use.
+func (s *DB) BackupInto(dir string) error {
+	if err := s.baseDB.vacuumInto(filepath.Join(dir, filepath.Base(s.baseDB.path))); err != nil {
+		return wrap(err, "main")
+	}
+
+	s.folderDBsMut.RLock()
+	fdbs := slices.Collect(maps.Values(s.folderDBs))
+	s.folderDBsMut.RUnlock()
+
+	for _, fdb := range fdbs {
+		rel, err := filepath.Rel(s.pathBase, fdb.path)
+		if err != nil {
+			return wrap(err)
+		}
+		if err := fdb.vacuumInto(filepath.Join(dir, rel)); err != nil {
+			return wrap(err, fdb.folderID)
+		}
+	}
+	return nil
+}
+
+func (s *baseDB) vacuumInto(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	_, err := s.sql.Exec(`VACUUM INTO ?`, path)
+	return err
+}
@@ -88,6 +88,8 @@ func (s *DB) getFolderDB(folder string, create bool) (*folderDB, error) {
 	if err != nil {
 		return nil, wrap(err)
 	}
+	fdb.blooms = newFolderBlooms(folder, s.bloomBudget)
+	fdb.blooms.rebuild(fdb)
 	s.folderDBs[folder] = fdb
 	return fdb, nil
 }
@@ -309,6 +311,28 @@ func (s *DB) CountReceiveOnlyChanged(folder string) (db.Counts, error) {
 	return fdb.CountReceiveOnlyChanged()
 }
 
+func (s *DB) CountGlobalPrefix(folder, prefix string) (db.Counts, error) {
+	fdb, err := s.getFolderDB(folder, false)
+	if errors.Is(err, errNoSuchFolder) {
+		return db.Counts{}, nil
+	}
+	if err != nil {
+		return db.Counts{}, err
+	}
+	return fdb.CountGlobalPrefix(prefix)
+}
+
+func (s *DB) CountNeedPrefix(folder string, device protocol.DeviceID, prefix string) (db.Counts, error) {
+	fdb, err := s.getFolderDB(folder, false)
+	if errors.Is(err, errNoSuchFolder) {
+		return db.Counts{}, nil
+	}
+	if err != nil {
+		return db.Counts{}, err
+	}
+	return fdb.CountNeedPrefix(device, prefix)
+}
+
 func (s *DB) DropAllIndexIDs() error {
 	return s.forEachFolder(func(fdb *folderDB) error {
 		return fdb.DropAllIndexIDs()
@@ -624,3 +624,75 @@ func TestDeletedAfterConflict(t *testing.T) {
 		t.Error("should be deleted")
 	}
 }
+
+func TestCountPrefix(t *testing.T) {
+	t.Parallel()
+
+	db, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := db.Close(); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	// The remote device has a directory tree, of which we have only part.
+	remote := []protocol.FileInfo{
+		genDir("proj", 100),
+		genFile("proj/a", 1, 101),
+		genDir("proj/sub", 102),
+		genFile("proj/sub/b", 2, 103),
+		genFile("projx", 4, 104), // shares the prefix but isn't in the directory
+		genFile("other", 8, 105),
+	}
+	if err := db.Update(folderID, protocol.DeviceID{42}, remote); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Update(folderID, protocol.LocalDeviceID, remote[:3]); err != nil {
+		t.Fatal(err)
+	}
+
+	glob, err := db.CountGlobalPrefix(folderID, "proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Directories count with their synthetic size
+	if glob.Files != 2 || glob.Directories != 2 || glob.Bytes != 3*blockSize+2*protocol.SyntheticDirectorySize {
+		t.Errorf("bad global count for prefix: %+v", glob)
+	}
+
+	need, err := db.CountNeedPrefix(folderID, protocol.LocalDeviceID, "proj/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if need.Files != 1 || need.Directories != 0 || need.Bytes != 2*blockSize {
+		t.Errorf("bad local need for prefix: %+v", need)
+	}
+
+	need, err = db.CountNeedPrefix(folderID, protocol.LocalDeviceID, "proj/sub/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if need.Files != 1 {
+		t.Errorf("bad local need for single file: %+v", need)
+	}
+
+	// The remote device has everything, and another device nothing below
+	// the prefix.
+	need, err = db.CountNeedPrefix(folderID, protocol.DeviceID{42}, "proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if need.Files != 0 || need.Directories != 0 {
+		t.Errorf("bad remote need for prefix: %+v", need)
+	}
+	need, err = db.CountNeedPrefix(folderID, protocol.DeviceID{43}, "proj")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if need.Files != 2 || need.Directories != 2 || need.Bytes != 3*blockSize+2*protocol.SyntheticDirectorySize {
+		t.Errorf("bad remote need for prefix: %+v", need)
+	}
+}
@@ -28,6 +28,7 @@ type DB struct {
 
 	pathBase        string
 	deleteRetention time.Duration
+	bloomBudget     *bloomBudget
 
 	folderDBsMut   sync.RWMutex
 	folderDBs      map[string]*folderDB
@@ -48,6 +49,17 @@ func WithDeleteRetention(d time.Duration) Option {
 	}
 }
 
+// WithBloomFilterMemory enables filters that answer negative block and file
+// lookups without querying the database, using at most the given number of
+// bytes for all folders together.
+func WithBloomFilterMemory(bytes int64) Option {
+	return func(s *DB) {
+		if bytes > 0 {
+			s.bloomBudget = &bloomBudget{limit: bytes}
+		}
+	}
+}
+
 func Open(path string, opts ...Option) (*DB, error) {
 	pragmas := []string{
 		"journal_mode = WAL",
@@ -136,6 +136,9 @@ func (s *Service) periodic(ctx context.Context) error {
 			return wrap(err)
 		}
 
+		// Deleted keys linger in the lookup filters until they're rebuilt.
+		fdb.blooms.rebuild(fdb)
+
 		// Update the successful GC sequence.
 		return wrap(meta.PutInt64(lastSuccessfulGCSeqKey, seq))
 	}))
@@ -0,0 +1,265 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package sqlite
+
+import (
+	"iter"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+)
+
+// The filters kept per folder, to answer negative lookups without going to
+// the database. Each covers the local device only.
+const (
+	bloomBlocks     = "blocks"     // block hashes, for AllLocalBlocksWithHash
+	bloomBlocklists = "blocklists" // blocklist hashes, for AllLocalFilesWithBlocksHash
+	bloomNames      = "names"      // file names, for GetDeviceFile
+)
+
+// The queries listing the keys of each filter.
+var bloomQueries = map[string]string{
+	bloomBlocks: `SELECT hash FROM blocks`,
+	bloomBlocklists: `
+		SELECT DISTINCT blocklist_hash FROM files
+		WHERE device_idx = {{.LocalDeviceIdx}} AND blocklist_hash IS NOT NULL
+	`,
+	bloomNames: `
+		SELECT n.name FROM files f
+		INNER JOIN file_names n ON f.name_idx = n.idx
+		WHERE f.device_idx = {{.LocalDeviceIdx}}
+	`,
+}
+
+var (
+	metricBloomLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "db",
+		Name:      "bloom_lookups_total",
+		Help:      "Total number of lookups in the database lookup filters, per folder, filter and result (negative or positive)",
+	}, []string{"folder", "filter", "result"})
+	metricBloomFalsePositives = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "db",
+		Name:      "bloom_false_positives_total",
+		Help:      "Total number of positive filter lookups where the database had no match, per folder and filter",
+	}, []string{"folder", "filter"})
+	metricBloomBytes = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "syncthing",
+		Subsystem: "db",
+		Name:      "bloom_bytes",
+		Help:      "Memory used by the database lookup filters, per folder and filter",
+	}, []string{"folder", "filter"})
+)
+
+type bloomResult int
+
+const (
+	bloomUnknown  bloomResult = iota // no filter; ask the database
+	bloomNegative                    // definitely not present
+	bloomPositive                    // possibly present; ask the database
+)
+
+// folderBlooms holds the filters for a folder. Filters are built in the
+// background when the folder database is opened and rebuilt after garbage
+// collection, as deleted keys are never removed. Until a filter is built
+// all lookups go to the database. A nil *folderBlooms does nothing.
+type folderBlooms struct {
+	folder string
+	budget *bloomBudget
+
+	mut      sync.RWMutex
+	filters  map[string]*bloomFilter // ready for lookups
+	building map[string]*bloomFilter // being filled from the database
+	closed   bool
+	running  atomic.Bool // a rebuild is in progress
+}
+
+func newFolderBlooms(folder string, budget *bloomBudget) *folderBlooms {
+	if budget == nil || budget.limit <= 0 {
+		return nil
+	}
+	return &folderBlooms{
+		folder:   folder,
+		budget:   budget,
+		filters:  make(map[string]*bloomFilter),
+		building: make(map[string]*bloomFilter),
+	}
+}
+
+// add records a key added to the database. It must be called with the
+// update lock held, before the transaction commits.
+func (b *folderBlooms) add(kind string, key []byte) {
+	if b == nil {
+		return
+	}
+	b.mut.RLock()
+	defer b.mut.RUnlock()
+	if f := b.filters[kind]; f != nil {
+		f.add(key)
+	}
+	if f := b.building[kind]; f != nil {
+		f.add(key)
+	}
+}
+
+func (b *folderBlooms) check(kind string, key []byte) bloomResult {
+	if b == nil {
+		return bloomUnknown
+	}
+	b.mut.RLock()
+	f := b.filters[kind]
+	b.mut.RUnlock()
+	if f == nil {
+		return bloomUnknown
+	}
+	if f.mayContain(key) {
+		metricBloomLookups.WithLabelValues(b.folder, kind, "positive").Inc()
+		return bloomPositive
+	}
+	metricBloomLookups.WithLabelValues(b.folder, kind, "negative").Inc()
+	return bloomNegative
+}
+
+func (b *folderBlooms) falsePositive(kind string) {
+	metricBloomFalsePositives.WithLabelValues(b.folder, kind).Inc()
+}
+
+// needsRebuild returns true if a filter has had many more keys added than
+// it was sized for.
+func (b *folderBlooms) needsRebuild() bool {
+	if b == nil {
+		return false
+	}
+	b.mut.RLock()
+	defer b.mut.RUnlock()
+	for _, f := range b.filters {
+		if f.overfull() {
+			return true
+		}
+	}
+	return false
+}
+
+// rebuild builds all filters from the database, replacing the current
+// ones when done. It returns immediately if a build is already running.
+func (b *folderBlooms) rebuild(fdb *folderDB) {
+	if b == nil {
+		return
+	}
+	if !b.running.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer b.running.Store(false)
+		for kind := range bloomQueries {
+			if err := b.build(fdb, kind); err != nil {
+				slog.Debug("Failed to build database lookup filter", "folder", b.folder, "filter", kind, slogutil.Error(err))
+				return
+			}
+		}
+	}()
+}
+
+func (b *folderBlooms) build(fdb *folderDB, kind string) error {
+	// Size and register the new filter with the update lock held, so that
+	// every key is either committed before we read the database or added
+	// to the new filter by the update.
+	fdb.updateLock.Lock()
+	var items int64
+	if err := fdb.stmt(`SELECT count(*) FROM (` + bloomQueries[kind] + `)`).Get(&items); err != nil {
+		fdb.updateLock.Unlock()
+		return wrap(err)
+	}
+	// Leave room for growth until the next rebuild.
+	items += items/4 + 1000
+	bits := b.budget.reserve(items)
+	if bits == 0 {
+		fdb.updateLock.Unlock()
+		slog.Debug("Not enough memory budget for database lookup filter", "folder", b.folder, "filter", kind, "items", items)
+		return nil
+	}
+	f := newBloomFilter(items, bits)
+	b.mut.Lock()
+	b.building[kind] = f
+	b.mut.Unlock()
+	fdb.updateLock.Unlock()
+
+	err := b.fill(fdb, kind, f)
+
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	delete(b.building, kind)
+	if err != nil || b.closed {
+		b.budget.release(f.bytes())
+		return err
+	}
+	if old := b.filters[kind]; old != nil {
+		b.budget.release(old.bytes())
+	}
+	b.filters[kind] = f
+	metricBloomBytes.WithLabelValues(b.folder, kind).Set(float64(f.bytes()))
+	return nil
+}
+
+func (*folderBlooms) fill(fdb *folderDB, kind string, f *bloomFilter) error {
+	rows, err := fdb.stmt(bloomQueries[kind]).Queryx()
+	if err != nil {
+		return wrap(err)
+	}
+	defer rows.Close()
+	var key []byte
+	for rows.Next() {
+		if err := rows.Scan(&key); err != nil {
+			return wrap(err)
+		}
+		f.add(key)
+	}
+	return wrap(rows.Err())
+}
+
+// release frees the memory of the filters; no more are built.
+func (b *folderBlooms) release() {
+	if b == nil {
+		return
+	}
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	for kind, f := range b.filters {
+		b.budget.release(f.bytes())
+		metricBloomBytes.DeleteLabelValues(b.folder, kind)
+	}
+	clear(b.filters)
+	b.closed = true
+}
+
+// bloomChecked wraps a lookup result, counting a false positive if the
+// filter said the key was present but the database had nothing.
+func bloomChecked[T any](fdb *folderDB, res bloomResult, kind string, it iter.Seq[T], errFn func() error) (iter.Seq[T], func() error) {
+	if res != bloomPositive {
+		return it, errFn
+	}
+	return func(yield func(T) bool) {
+		found := false
+		defer func() {
+			if !found && errFn() == nil {
+				fdb.blooms.falsePositive(kind)
+			}
+		}()
+		for v := range it {
+			found = true
+			if !yield(v) {
+				return
+			}
+		}
+	}, errFn
+}
@@ -7,7 +7,10 @@
 package sqlite
 
 import (
+	"strings"
+
 	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/lib/osutil"
 	"github.com/syncthing/syncthing/lib/protocol"
 )
 
@@ -105,6 +108,83 @@ func (s *folderDB) needSizeRemote(device protocol.DeviceID) (db.Counts, error) {
 	return summarizeCounts(res), nil
 }
 
+func (s *folderDB) CountGlobalPrefix(prefix string) (db.Counts, error) {
+	if prefix == "" {
+		return s.CountGlobal()
+	}
+	dir, start, end := prefixRange(prefix)
+
+	var res []countsRow
+	if err := s.stmt(`
+		SELECT g.type, count(*) as count, sum(g.size) as size, g.local_flags, g.deleted FROM files g
+		INNER JOIN file_names n ON g.name_idx = n.idx
+		WHERE g.local_flags & {{.FlagLocalGlobal}} != 0 AND g.local_flags & {{.LocalInvalidFlags}} = 0
+		AND (n.name = ? OR n.name >= ? AND n.name < ?)
+		GROUP BY g.type, g.local_flags, g.deleted
+	`).Select(&res, dir, start, end); err != nil {
+		return db.Counts{}, wrap(err)
+	}
+	return summarizeCounts(res), nil
+}
+
+func (s *folderDB) CountNeedPrefix(device protocol.DeviceID, prefix string) (db.Counts, error) {
+	if prefix == "" {
+		return s.CountNeed(device)
+	}
+	dir, start, end := prefixRange(prefix)
+
+	var res []countsRow
+	if device == protocol.LocalDeviceID {
+		if err := s.stmt(`
+			SELECT g.type, count(*) as count, sum(g.size) as size, g.local_flags, g.deleted FROM files g
+			INNER JOIN file_names n ON g.name_idx = n.idx
+			WHERE g.local_flags & {{.FlagLocalNeeded}} != 0
+			AND (n.name = ? OR n.name >= ? AND n.name < ?)
+			GROUP BY g.type, g.local_flags, g.deleted
+		`).Select(&res, dir, start, end); err != nil {
+			return db.Counts{}, wrap(err)
+		}
+		return summarizeCounts(res), nil
+	}
+
+	// Same as needSizeRemote, limited to the prefix
+	if err := s.stmt(`
+		SELECT g.type, count(*) as count, sum(g.size) as size, g.local_flags, g.deleted FROM files g
+		INNER JOIN file_names n ON g.name_idx = n.idx
+		WHERE g.local_flags & {{.FlagLocalGlobal}} != 0 AND NOT g.deleted AND g.local_flags & {{.LocalInvalidFlags}} = 0
+		AND (n.name = ? OR n.name >= ? AND n.name < ?) AND NOT EXISTS (
+			SELECT 1 FROM FILES f
+			INNER JOIN devices d ON d.idx = f.device_idx
+			WHERE f.name_idx = g.name_idx AND f.version_idx = g.version_idx AND d.device_id = ?
+		)
+		GROUP BY g.type, g.local_flags, g.deleted
+
+		UNION ALL
+
+		SELECT g.type, count(*) as count, sum(g.size) as size, g.local_flags, g.deleted FROM files g
+		INNER JOIN file_names n ON g.name_idx = n.idx
+		WHERE g.local_flags & {{.FlagLocalGlobal}} != 0 AND g.deleted AND g.local_flags & {{.LocalInvalidFlags}} = 0
+		AND (n.name = ? OR n.name >= ? AND n.name < ?) AND EXISTS (
+			SELECT 1 FROM FILES f
+			INNER JOIN devices d ON d.idx = f.device_idx
+			WHERE f.name_idx = g.name_idx AND d.device_id = ? AND NOT f.deleted AND f.local_flags & {{.LocalInvalidFlags}} = 0
+		)
+		GROUP BY g.type, g.local_flags, g.deleted
+	`).Select(&res, dir, start, end, device.String(),
+		dir, start, end, device.String()); err != nil {
+		return db.Counts{}, wrap(err)
+	}
+	return summarizeCounts(res), nil
+}
+
+// prefixRange returns the normalized directory name and the range of names
+// below it.
+func prefixRange(prefix string) (dir, start, end string) {
+	dir = strings.Trim(osutil.NormalizedFilename(prefix), "/")
+	start = dir + "/"
+	return dir, start, prefixEnd(start)
+}
+
 func summarizeCounts(res []countsRow) db.Counts {
 	c := db.Counts{
 		DeviceID: protocol.LocalDeviceID,
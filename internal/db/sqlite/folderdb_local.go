@@ -26,6 +26,13 @@ import (
 func (s *folderDB) GetDeviceFile(device protocol.DeviceID, file string) (protocol.FileInfo, bool, error) {
 	file = osutil.NormalizedFilename(file)
 
+	var bloom bloomResult
+	if device == protocol.LocalDeviceID {
+		if bloom = s.blooms.check(bloomNames, []byte(file)); bloom == bloomNegative {
+			return protocol.FileInfo{}, false, nil
+		}
+	}
+
 	var ind indirectFI
 	err := s.stmt(`
 		SELECT fi.fiprotobuf, bl.blprotobuf FROM fileinfos fi
@@ -36,6 +43,9 @@ func (s *folderDB) GetDeviceFile(device protocol.DeviceID, file string) (protoco
 		WHERE d.device_id = ? AND n.name = ?
 	`).Get(&ind, device.String(), file)
 	if errors.Is(err, sql.ErrNoRows) {
+		if bloom == bloomPositive {
+			s.blooms.falsePositive(bloomNames)
+		}
 		return protocol.FileInfo{}, false, nil
 	}
 	if err != nil {
@@ -95,23 +105,33 @@ func (s *folderDB) AllLocalFilesWithPrefix(device protocol.DeviceID, prefix stri
 }
 
 func (s *folderDB) AllLocalFilesWithBlocksHash(h []byte) (iter.Seq[db.FileMetadata], func() error) {
-	return iterStructs[db.FileMetadata](s.stmt(`
+	bloom := s.blooms.check(bloomBlocklists, h)
+	if bloom == bloomNegative {
+		return func(func(db.FileMetadata) bool) {}, func() error { return nil }
+	}
+	it, errFn := iterStructs[db.FileMetadata](s.stmt(`
 		SELECT f.sequence, n.name, f.type, f.modified as modnanos, f.size, f.deleted, f.local_flags as localflags FROM files f
 		INNER JOIN file_names n ON f.name_idx = n.idx
 		WHERE f.device_idx = {{.LocalDeviceIdx}} AND f.blocklist_hash = ?
 	`).Queryx(h))
+	return bloomChecked(s, bloom, bloomBlocklists, it, errFn)
 }
 
 func (s *folderDB) AllLocalBlocksWithHash(hash []byte) (iter.Seq[db.BlockMapEntry], func() error) {
 	// We involve the files table in this select because deletion of blocks
 	// & blocklists is deferred (garbage collected) while the files list is
 	// not. This filters out blocks that are in fact deleted.
-	return iterStructs[db.BlockMapEntry](s.stmt(`
+	bloom := s.blooms.check(bloomBlocks, hash)
+	if bloom == bloomNegative {
+		return func(func(db.BlockMapEntry) bool) {}, func() error { return nil }
+	}
+	it, errFn := iterStructs[db.BlockMapEntry](s.stmt(`
 		SELECT f.blocklist_hash as blocklisthash, b.idx as blockindex, b.offset, b.size, n.name as filename FROM files f
 		INNER JOIN file_names n ON f.name_idx = n.idx
 		LEFT JOIN blocks b ON f.blocklist_hash = b.blocklist_hash
 		WHERE f.device_idx = {{.LocalDeviceIdx}} AND b.hash = ?
 	`).Queryx(hash))
+	return bloomChecked(s, bloom, bloomBlocks, it, errFn)
 }
 
 func (s *folderDB) ListDevicesForFolder() ([]protocol.DeviceID, error) {
@@ -18,6 +18,7 @@ type folderDB struct {
 
 	localDeviceIdx  int64
 	deleteRetention time.Duration
+	blooms          *folderBlooms // nil unless enabled
 }
 
 func openFolderDB(folder, path string, deleteRetention time.Duration) (*folderDB, error) {
@@ -108,3 +109,8 @@ func (s *folderDB) deviceIdxLocked(deviceID protocol.DeviceID) (int64, error) {
 
 	return idx, nil
 }
+
+func (s *folderDB) Close() error {
+	s.blooms.release()
+	return s.baseDB.Close()
+}
@@ -142,6 +142,13 @@ func (s *folderDB) Update(device protocol.DeviceID, fs []protocol.FileInfo) erro
 			return wrap(err, "insert version")
 		}
 
+		if device == protocol.LocalDeviceID {
+			s.blooms.add(bloomNames, []byte(f.Name))
+			if blockshash != nil {
+				s.blooms.add(bloomBlocklists, f.BlocksHash)
+			}
+		}
+
 		var localSeq int64
 		if err := insertFileStmt.Get(&localSeq, deviceIdx, remoteSeq, f.Type, f.ModTime().UnixNano(), f.Size, f.IsDeleted(), f.LocalFlags, blockshash, nameIdx, versionIdx); err != nil {
 			return wrap(err, "insert file")
@@ -189,6 +196,9 @@ func (s *folderDB) Update(device protocol.DeviceID, fs []protocol.FileInfo) erro
 	}
 
 	s.periodicCheckpointLocked(fs)
+	if s.blooms.needsRebuild() {
+		s.blooms.rebuild(s)
+	}
 	return nil
 }
 
@@ -308,12 +318,13 @@ func (s *folderDB) DropFilesNamed(device protocol.DeviceID, names []string) erro
 	return wrap(tx.Commit())
 }
 
-func (*folderDB) insertBlocksLocked(tx *txPreparedStmts, blocklistHash []byte, blocks []protocol.BlockInfo) error {
+func (s *folderDB) insertBlocksLocked(tx *txPreparedStmts, blocklistHash []byte, blocks []protocol.BlockInfo) error {
 	if len(blocks) == 0 {
 		return nil
 	}
 	bs := make([]map[string]any, len(blocks))
 	for i, b := range blocks {
+		s.blooms.add(bloomBlocks, b.Hash)
 		bs[i] = map[string]any{
 			"hash":           b.Hash,
 			"blocklist_hash": blocklistHash,
@@ -0,0 +1,158 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package faultinject lets tests inject failures at named points in the
+// connection and model code, to exercise failure paths deterministically.
+// The checks are only compiled in when building with the "faultinject"
+// tag, as the test target of build.go does; otherwise they're no-ops.
+// Even then nothing is injected unless a test arms a fault.
+package faultinject
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Enabled returns whether fault injection is built in. Tests arming faults
+// should skip without it.
+func Enabled() bool {
+	return enabled
+}
+
+// A Point names a place in the code where faults can be injected.
+type Point string
+
+const (
+	// Dialing a connection, keyed by the dial URI.
+	Dial Point = "connections/dial"
+	// The TLS handshake, keyed by the remote address.
+	Handshake Point = "connections/handshake"
+	// Each read from an established connection, keyed by device ID.
+	Read Point = "connections/read"
+	// Writing file metadata to the database, keyed by folder ID.
+	DBWrite Point = "model/dbwrite"
+	// Writing file data to disk while pulling, keyed by folder ID.
+	DiskWrite Point = "model/diskwrite"
+)
+
+// ErrInjected is returned at an injection point when the fault doesn't
+// specify another error.
+var ErrInjected = errors.New("injected fault")
+
+// A Fault describes what happens when an injection point is reached.
+type Fault struct {
+	// Delay, if set, is slept before anything else happens.
+	Delay time.Duration
+	// Err is returned from the injection point. Without Err and Delay,
+	// ErrInjected is returned.
+	Err error
+	// Key, if set, limits the fault to calls with that key.
+	Key string
+	// Count, if set, disarms the fault after it has been hit that many
+	// times.
+	Count int
+}
+
+type armedFault struct {
+	Fault
+	hits int
+}
+
+var (
+	armed  atomic.Int32 // number of armed points, for a cheap check
+	mut    sync.Mutex
+	faults = make(map[Point]*armedFault)
+)
+
+// Set arms the fault at the point, replacing any fault already there. The
+// returned function disarms it again.
+func Set(p Point, f Fault) (clear func()) {
+	if f.Err == nil && f.Delay == 0 {
+		f.Err = ErrInjected
+	}
+	mut.Lock()
+	if _, ok := faults[p]; !ok {
+		armed.Add(1)
+	}
+	faults[p] = &armedFault{Fault: f}
+	mut.Unlock()
+	return func() { Clear(p) }
+}
+
+// Clear disarms the fault at the point, if any.
+func Clear(p Point) {
+	mut.Lock()
+	defer mut.Unlock()
+	clearLocked(p)
+}
+
+func clearLocked(p Point) {
+	if _, ok := faults[p]; ok {
+		delete(faults, p)
+		armed.Add(-1)
+	}
+}
+
+// Reset disarms all faults.
+func Reset() {
+	mut.Lock()
+	defer mut.Unlock()
+	for p := range faults {
+		clearLocked(p)
+	}
+}
+
+// Check is called at the injection point. It returns the injected error,
+// after the injected delay, if a fault is armed at the point for the key,
+// and nil otherwise.
+func Check(p Point, key string) error {
+	if !enabled || armed.Load() == 0 {
+		return nil
+	}
+
+	mut.Lock()
+	f, ok := faults[p]
+	if !ok || f.Key != "" && f.Key != key {
+		mut.Unlock()
+		return nil
+	}
+	f.hits++
+	if f.Count > 0 && f.hits >= f.Count {
+		clearLocked(p)
+	}
+	delay, err := f.Delay, f.Err
+	mut.Unlock()
+
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return err
+}
+
+// Reader returns a reader that checks the point before each read from r.
+// When built without fault injection it returns r itself.
+func Reader(p Point, key string, r io.Reader) io.Reader {
+	if !enabled {
+		return r
+	}
+	return &reader{p: p, key: key, r: r}
+}
+
+type reader struct {
+	p   Point
+	key string
+	r   io.Reader
+}
+
+func (r *reader) Read(bs []byte) (int, error) {
+	if err := Check(r.p, r.key); err != nil {
+		return 0, err
+	}
+	return r.r.Read(bs)
+}
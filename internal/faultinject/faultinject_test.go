@@ -0,0 +1,64 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package faultinject
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	if !enabled {
+		t.Skip("built without the faultinject tag")
+	}
+	defer Reset()
+
+	if err := Check(Dial, "a"); err != nil {
+		t.Fatal("unexpected fault before arming:", err)
+	}
+
+	errTest := errors.New("test")
+	Set(Dial, Fault{Err: errTest, Key: "a", Count: 2})
+	if err := Check(Dial, "b"); err != nil {
+		t.Error("fault for another key:", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := Check(Dial, "a"); !errors.Is(err, errTest) {
+			t.Errorf("expected injected error, got %v", err)
+		}
+	}
+	if err := Check(Dial, "a"); err != nil {
+		t.Error("fault still armed after its count:", err)
+	}
+
+	clear := Set(DBWrite, Fault{})
+	if err := Check(DBWrite, "any"); !errors.Is(err, ErrInjected) {
+		t.Errorf("expected the default error, got %v", err)
+	}
+	clear()
+	if err := Check(DBWrite, "any"); err != nil {
+		t.Error("fault still armed after clearing:", err)
+	}
+}
+
+func TestReader(t *testing.T) {
+	if !enabled {
+		t.Skip("built without the faultinject tag")
+	}
+	defer Reset()
+
+	r := Reader(Read, "dev", strings.NewReader("data"))
+	Set(Read, Fault{Count: 1})
+	if _, err := r.Read(make([]byte, 4)); !errors.Is(err, ErrInjected) {
+		t.Errorf("expected injected error, got %v", err)
+	}
+	if bs, err := io.ReadAll(r); err != nil || string(bs) != "data" {
+		t.Errorf("unexpected read result %q, %v", bs, err)
+	}
+}
@@ -108,6 +108,7 @@ func (h *formattingHandler) Handle(_ context.Context, rec slog.Record) error {
 	for _, rec := range h.opts.recs {
 		rec.record(line)
 	}
+	recordCaptures(line)
 
 	// If there's an output, print the line.
 	if h.opts.out != nil {
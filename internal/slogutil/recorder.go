@@ -8,6 +8,7 @@ package slogutil
 
 import (
 	"log/slog"
+	"slices"
 	"sync"
 	"time"
 )
@@ -57,3 +58,72 @@ func (r *lineRecorder) Since(t time.Time) []Line {
 	}
 	return nil
 }
+
+const maxCaptureLines = 100000
+
+var captures struct {
+	mut  sync.RWMutex
+	list []*LineCapture
+}
+
+// A LineCapture collects the log lines accepted by its filter from when it
+// is started until it is stopped. Unlike a Recorder it keeps everything,
+// up to a generous limit, as it's meant for a limited time.
+type LineCapture struct {
+	filter  func(Line) bool
+	mut     sync.Mutex
+	lines   []Line
+	dropped int
+}
+
+// StartCapture starts collecting the log lines accepted by the filter.
+func StartCapture(filter func(Line) bool) *LineCapture {
+	c := &LineCapture{filter: filter}
+	captures.mut.Lock()
+	captures.list = append(captures.list, c)
+	captures.mut.Unlock()
+	return c
+}
+
+// Stop stops collecting lines; those collected remain available.
+func (c *LineCapture) Stop() {
+	captures.mut.Lock()
+	captures.list = slices.DeleteFunc(captures.list, func(o *LineCapture) bool { return o == c })
+	captures.mut.Unlock()
+}
+
+// Lines returns the lines collected so far, and the number of lines
+// dropped for exceeding the limit.
+func (c *LineCapture) Lines() ([]Line, int) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return slices.Clone(c.lines), c.dropped
+}
+
+// Count returns the number of lines collected so far, and dropped.
+func (c *LineCapture) Count() (int, int) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	return len(c.lines), c.dropped
+}
+
+func (c *LineCapture) record(line Line) {
+	if !c.filter(line) {
+		return
+	}
+	c.mut.Lock()
+	if len(c.lines) < maxCaptureLines {
+		c.lines = append(c.lines, line)
+	} else {
+		c.dropped++
+	}
+	c.mut.Unlock()
+}
+
+func recordCaptures(line Line) {
+	captures.mut.RLock()
+	for _, c := range captures.list {
+		c.record(line)
+	}
+	captures.mut.RUnlock()
+}
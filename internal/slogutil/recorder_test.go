@@ -0,0 +1,34 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package slogutil
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestLineCapture(t *testing.T) {
+	l := slog.New(&formattingHandler{opts: &formattingOptions{out: io.Discard}})
+	c := StartCapture(func(line Line) bool {
+		return strings.Contains(line.Message, "ABCDEFG")
+	})
+
+	l.Info("Connected to device", "device", "ABCDEFG")
+	l.Info("Connected to device", "device", "HIJKLMN")
+	c.Stop()
+	l.Info("Disconnected from device", "device", "ABCDEFG")
+
+	lines, dropped := c.Lines()
+	if len(lines) != 1 || dropped != 0 {
+		t.Fatalf("expected one line captured, got %v (%d dropped)", lines, dropped)
+	}
+	if !strings.HasPrefix(lines[0].Message, "Connected to device") {
+		t.Errorf("unexpected line %q", lines[0].Message)
+	}
+}
@@ -12,12 +12,14 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"log/slog"
+	"maps"
 	"net"
 	"net/http"
 	"net/url"
@@ -44,6 +46,7 @@ import (
 
 	"github.com/syncthing/syncthing/internal/db"
 	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/backup"
 	"github.com/syncthing/syncthing/lib/build"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/connections"
@@ -52,8 +55,10 @@ import (
 	"github.com/syncthing/syncthing/lib/fs"
 	"github.com/syncthing/syncthing/lib/locations"
 	"github.com/syncthing/syncthing/lib/model"
+	"github.com/syncthing/syncthing/lib/profile"
 	"github.com/syncthing/syncthing/lib/protocol"
 	"github.com/syncthing/syncthing/lib/rand"
+	"github.com/syncthing/syncthing/lib/stats"
 	"github.com/syncthing/syncthing/lib/svcutil"
 	"github.com/syncthing/syncthing/lib/tlsutil"
 	"github.com/syncthing/syncthing/lib/upgrade"
@@ -68,6 +73,7 @@ const (
 	EventSubBufferSize    = 1000
 	defaultEventTimeout   = time.Minute
 	httpsCertLifetimeDays = 820
+	folderPreviewTimeout  = 30 * time.Second
 )
 
 type service struct {
@@ -93,10 +99,15 @@ type service struct {
 	listenerAddr         net.Addr
 	exitChan             chan *svcutil.FatalErr
 	miscDB               *db.Typed
+	backups              *backup.Service
+	profiles             *profile.Service
 	shutdownTimeout      time.Duration
 
 	guiErrors slogutil.Recorder
 	systemLog slogutil.Recorder
+
+	captureMut sync.Mutex
+	capture    *debugCapture // the latest debug capture
 }
 
 var _ config.Verifier = &service{}
@@ -107,7 +118,7 @@ type Service interface {
 	WaitForStart() error
 }
 
-func New(id protocol.DeviceID, cfg config.Wrapper, assetDir, tlsDefaultCommonName string, m model.Model, defaultSub, diskSub events.BufferedSubscription, evLogger events.Logger, discoverer discover.Manager, connectionsService connections.Service, urService *ur.Service, fss model.FolderSummaryService, errors, systemLog slogutil.Recorder, noUpgrade bool, miscDB *db.Typed) Service {
+func New(id protocol.DeviceID, cfg config.Wrapper, assetDir, tlsDefaultCommonName string, m model.Model, defaultSub, diskSub events.BufferedSubscription, evLogger events.Logger, discoverer discover.Manager, connectionsService connections.Service, urService *ur.Service, fss model.FolderSummaryService, errors, systemLog slogutil.Recorder, noUpgrade bool, miscDB *db.Typed, backups *backup.Service, profiles *profile.Service) Service {
 	return &service{
 		id:      id,
 		cfg:     cfg,
@@ -130,6 +141,8 @@ func New(id protocol.DeviceID, cfg config.Wrapper, assetDir, tlsDefaultCommonNam
 		startedOnce:          make(chan struct{}),
 		exitChan:             make(chan *svcutil.FatalErr, 1),
 		miscDB:               miscDB,
+		backups:              backups,
+		profiles:             profiles,
 		shutdownTimeout:      100 * time.Millisecond,
 	}
 }
@@ -250,62 +263,119 @@ func (s *service) Serve(ctx context.Context) error {
 	restMux := httprouter.New()
 
 	// The GET handlers
-	restMux.HandlerFunc(http.MethodGet, "/rest/cluster/pending/devices", s.getPendingDevices) // -
-	restMux.HandlerFunc(http.MethodGet, "/rest/cluster/pending/folders", s.getPendingFolders) // [device]
-	restMux.HandlerFunc(http.MethodGet, "/rest/db/completion", s.getDBCompletion)             // [device] [folder]
-	restMux.HandlerFunc(http.MethodGet, "/rest/db/file", s.getDBFile)                         // folder file
-	restMux.HandlerFunc(http.MethodGet, "/rest/db/ignores", s.getDBIgnores)                   // folder
-	restMux.HandlerFunc(http.MethodGet, "/rest/db/need", s.getDBNeed)                         // folder [perpage] [page]
-	restMux.HandlerFunc(http.MethodGet, "/rest/db/remoteneed", s.getDBRemoteNeed)             // device folder [perpage] [page]
-	restMux.HandlerFunc(http.MethodGet, "/rest/db/localchanged", s.getDBLocalChanged)         // folder [perpage] [page]
-	restMux.HandlerFunc(http.MethodGet, "/rest/db/status", s.getDBStatus)                     // folder
-	restMux.HandlerFunc(http.MethodGet, "/rest/db/browse", s.getDBBrowse)                     // folder [prefix] [dirsonly] [levels]
-	restMux.HandlerFunc(http.MethodGet, "/rest/folder/versions", s.getFolderVersions)         // folder
-	restMux.HandlerFunc(http.MethodGet, "/rest/folder/errors", s.getFolderErrors)             // folder [perpage] [page]
-	restMux.HandlerFunc(http.MethodGet, "/rest/folder/pullerrors", s.getFolderErrors)         // folder (deprecated)
-	restMux.HandlerFunc(http.MethodGet, "/rest/events", s.getIndexEvents)                     // [since] [limit] [timeout] [events]
-	restMux.HandlerFunc(http.MethodGet, "/rest/events/disk", s.getDiskEvents)                 // [ [since] [limit] [timeout]
-	restMux.HandlerFunc(http.MethodGet, "/rest/noauth/health", s.getHealth)                   // -
-	restMux.HandlerFunc(http.MethodGet, "/rest/stats/device", s.getDeviceStats)               // -
-	restMux.HandlerFunc(http.MethodGet, "/rest/stats/folder", s.getFolderStats)               // -
-	restMux.HandlerFunc(http.MethodGet, "/rest/svc/deviceid", s.getDeviceID)                  // id
-	restMux.HandlerFunc(http.MethodGet, "/rest/svc/lang", s.getLang)                          // -
-	restMux.HandlerFunc(http.MethodGet, "/rest/svc/report", s.getReport)                      // -
-	restMux.HandlerFunc(http.MethodGet, "/rest/svc/random/string", s.getRandomString)         // [length]
-	restMux.HandlerFunc(http.MethodGet, "/rest/system/browse", s.getSystemBrowse)             // current
-	restMux.HandlerFunc(http.MethodGet, "/rest/system/connections", s.getSystemConnections)   // -
-	restMux.HandlerFunc(http.MethodGet, "/rest/system/discovery", s.getSystemDiscovery)       // -
-	restMux.HandlerFunc(http.MethodGet, "/rest/system/error", s.getSystemError)               // -
-	restMux.HandlerFunc(http.MethodGet, "/rest/system/paths", s.getSystemPaths)               // -
-	restMux.HandlerFunc(http.MethodGet, "/rest/system/ping", s.restPing)                      // -
-	restMux.HandlerFunc(http.MethodGet, "/rest/system/status", s.getSystemStatus)             // -
-	restMux.HandlerFunc(http.MethodGet, "/rest/system/upgrade", s.getSystemUpgrade)           // -
-	restMux.HandlerFunc(http.MethodGet, "/rest/system/version", s.getSystemVersion)           // -
-	restMux.HandlerFunc(http.MethodGet, "/rest/system/loglevels", s.getSystemDebug)           // -
-	restMux.HandlerFunc(http.MethodGet, "/rest/system/log", s.getSystemLog)                   // [since]
-	restMux.HandlerFunc(http.MethodGet, "/rest/system/log.txt", s.getSystemLogTxt)            // [since]
+	restMux.HandlerFunc(http.MethodGet, "/rest/cluster/configsync", s.getClusterConfigSync)                 // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/cluster/identitychanges", s.getIdentityChanges)              // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/cluster/pending/devices", s.getPendingDevices)               // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/cluster/pending/folders", s.getPendingFolders)               // [device]
+	restMux.HandlerFunc(http.MethodGet, "/rest/cluster/pending/folders/preview", s.getPendingFolderPreview) // device folder [entries]
+	restMux.HandlerFunc(http.MethodGet, "/rest/cluster/queue", s.getClusterQueue)                           // device
+	restMux.HandlerFunc(http.MethodGet, "/rest/cluster/revocations", s.getClusterRevocations)               // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/cluster/revocations/pending", s.getPendingRevocations)       // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/config/drift", s.getConfigDrift)                             // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/db/completion", s.getDBCompletion)                           // [device] [folder] [prefix]
+	restMux.HandlerFunc(http.MethodGet, "/rest/db/conflicts", s.getDBConflicts)                             // folder
+	restMux.HandlerFunc(http.MethodGet, "/rest/db/conflicts/versions", s.getDBConflictVersions)             // folder conflict
+	restMux.HandlerFunc(http.MethodGet, "/rest/db/file", s.getDBFile)                                       // folder file
+	restMux.HandlerFunc(http.MethodGet, "/rest/db/ignores", s.getDBIgnores)                                 // folder
+	restMux.HandlerFunc(http.MethodGet, "/rest/db/need", s.getDBNeed)                                       // folder [perpage] [page]
+	restMux.HandlerFunc(http.MethodGet, "/rest/db/remoteneed", s.getDBRemoteNeed)                           // device folder [perpage] [page]
+	restMux.HandlerFunc(http.MethodGet, "/rest/db/localchanged", s.getDBLocalChanged)                       // folder [perpage] [page]
+	restMux.HandlerFunc(http.MethodGet, "/rest/db/localchanged/report", s.getDBLocalChangedReport)          // folder [perpage] [page] [diff] [format]
+	restMux.HandlerFunc(http.MethodGet, "/rest/db/pullpreview", s.getDBPullPreview)                         // folder
+	restMux.HandlerFunc(http.MethodGet, "/rest/db/status", s.getDBStatus)                                   // folder
+	restMux.HandlerFunc(http.MethodGet, "/rest/db/status/all", s.getDBStatusAll)                            // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/db/browse", s.getDBBrowse)                                   // folder [prefix] [dirsonly] [levels]
+	restMux.HandlerFunc(http.MethodGet, "/rest/db/waitidle", s.getDBWaitIdle)                               // folder [timeout]
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/anomalies", s.getFolderAnomalies)                     // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/attime", s.getFolderAtTime)                           // folder time [prefix]
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/capabilities", s.getFolderCapabilities)               // folder
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/handover", s.getFolderHandover)                       // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/handover/pending", s.getPendingFolderHandovers)       // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/ownership/pending", s.getPendingOwnershipClaims)      // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/versions", s.getFolderVersions)                       // folder
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/errors", s.getFolderErrors)                           // folder [perpage] [page]
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/health", s.getFolderHealth)                           // -
+	restMux.Handle(http.MethodGet, "/rest/folder/health/:id", s.getFolderHealthID)                          // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/pullerrors", s.getFolderErrors)                       // folder (deprecated)
+	restMux.HandlerFunc(http.MethodGet, "/rest/folder/recoverycode", s.getFolderRecoveryCode)               // folder device
+	restMux.HandlerFunc(http.MethodGet, "/rest/events", s.getIndexEvents)                                   // [since] [limit] [timeout] [events]
+	restMux.HandlerFunc(http.MethodGet, "/rest/events/disk", s.getDiskEvents)                               // [ [since] [limit] [timeout]
+	restMux.HandlerFunc(http.MethodGet, "/rest/noauth/health", s.getHealth)                                 // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/stats/device", s.getDeviceStats)                             // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/stats/device/addresses", s.getDeviceAddressLedger)           // [device] [format]
+	restMux.HandlerFunc(http.MethodGet, "/rest/stats/folder", s.getFolderStats)                             // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/svc/deviceid", s.getDeviceID)                                // id
+	restMux.HandlerFunc(http.MethodGet, "/rest/svc/lang", s.getLang)                                        // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/svc/report", s.getReport)                                    // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/svc/random/string", s.getRandomString)                       // [length]
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/browse", s.getSystemBrowse)                           // current
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/capture", s.getSystemCapture)                         // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/connections", s.getSystemConnections)                 // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/connections/attempts", s.getSystemConnectionAttempts) // device
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/connections/budgets", s.getSystemConnectionBudgets)   // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/connections/health", s.getSystemConnectionHealth)     // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/discovery", s.getSystemDiscovery)                     // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/error", s.getSystemError)                             // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/infrastructure", s.getSystemInfrastructure)           // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/paths", s.getSystemPaths)                             // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/ping", s.restPing)                                    // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/status", s.getSystemStatus)                           // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/upgrade", s.getSystemUpgrade)                         // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/version", s.getSystemVersion)                         // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/loglevels", s.getSystemDebug)                         // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/log", s.getSystemLog)                                 // [since]
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/log.txt", s.getSystemLogTxt)                          // [since]
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/backup", s.getSystemBackups)                          // -
+	restMux.HandlerFunc(http.MethodGet, "/rest/system/backup/file", s.getSystemBackupFile)                  // name
 
 	// The POST handlers
-	restMux.HandlerFunc(http.MethodPost, "/rest/db/prio", s.postDBPrio)                          // folder file
-	restMux.HandlerFunc(http.MethodPost, "/rest/db/ignores", s.postDBIgnores)                    // folder
-	restMux.HandlerFunc(http.MethodPost, "/rest/db/override", s.postDBOverride)                  // folder
-	restMux.HandlerFunc(http.MethodPost, "/rest/db/revert", s.postDBRevert)                      // folder
-	restMux.HandlerFunc(http.MethodPost, "/rest/db/scan", s.postDBScan)                          // folder [sub...] [delay]
-	restMux.HandlerFunc(http.MethodPost, "/rest/folder/versions", s.postFolderVersionsRestore)   // folder <body>
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/error", s.postSystemError)                // <body>
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/error/clear", s.postSystemErrorClear)     // -
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/ping", s.restPing)                        // -
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/reset", s.postSystemReset)                // [folder]
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/restart", s.postSystemRestart)            // -
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/shutdown", s.postSystemShutdown)          // -
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/upgrade", s.postSystemUpgrade)            // -
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/pause", s.makeDevicePauseHandler(true))   // [device]
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/resume", s.makeDevicePauseHandler(false)) // [device]
-	restMux.HandlerFunc(http.MethodPost, "/rest/system/loglevels", s.postSystemDebug)            // [enable] [disable]
+	restMux.HandlerFunc(http.MethodPost, "/rest/cluster/configsync/approve", s.postClusterConfigSyncApprove)   // device part [key]
+	restMux.HandlerFunc(http.MethodPost, "/rest/cluster/configsync/promote", s.postClusterConfigSyncPromote)   // device
+	restMux.HandlerFunc(http.MethodPost, "/rest/cluster/identitychanges/approve", s.postIdentityChangeApprove) // device
+	restMux.HandlerFunc(http.MethodPost, "/rest/cluster/queue", s.postClusterQueue)                            // device kind [key] <body>
+	restMux.HandlerFunc(http.MethodPost, "/rest/cluster/revocations", s.postClusterRevocations)                // device [distrust]
+	restMux.HandlerFunc(http.MethodPost, "/rest/cluster/revocations/approve", s.postRevocationsApprove)        // device
+	restMux.HandlerFunc(http.MethodPost, "/rest/config/drift/correct", s.postConfigDriftCorrect)               // -
+	restMux.HandlerFunc(http.MethodPost, "/rest/db/conflicts/resolve", s.postDBConflictResolve)                // folder conflict keep
+	restMux.HandlerFunc(http.MethodPost, "/rest/db/prio", s.postDBPrio)                                        // folder file
+	restMux.HandlerFunc(http.MethodPost, "/rest/db/ignores", s.postDBIgnores)                                  // folder
+	restMux.HandlerFunc(http.MethodPost, "/rest/db/merge", s.postDBMerge)                                      // target source [dryrun]
+	restMux.HandlerFunc(http.MethodPost, "/rest/db/override", s.postDBOverride)                                // folder
+	restMux.HandlerFunc(http.MethodPost, "/rest/db/revert", s.postDBRevert)                                    // folder
+	restMux.HandlerFunc(http.MethodPost, "/rest/db/scan", s.postDBScan)                                        // folder [sub...] [delay]
+	restMux.HandlerFunc(http.MethodPost, "/rest/db/tempcleanup", s.postDBTempCleanup)                          // folder
+	restMux.HandlerFunc(http.MethodPost, "/rest/folder/attime", s.postFolderAtTime)                            // folder time target [prefix]
+	restMux.HandlerFunc(http.MethodPost, "/rest/folder/capabilities", s.postFolderCapabilities)                // folder
+	restMux.HandlerFunc(http.MethodPost, "/rest/folder/handover", s.postFolderHandover)                        // folder device [after]
+	restMux.HandlerFunc(http.MethodPost, "/rest/folder/handover/approve", s.postFolderHandoverApprove)         // folder device
+	restMux.HandlerFunc(http.MethodPost, "/rest/folder/ownership/approve", s.postOwnershipClaimApprove)        // folder device
+	restMux.HandlerFunc(http.MethodPost, "/rest/folder/versions", s.postFolderVersionsRestore)                 // folder <body>
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/capture", s.postSystemCapture)                          // device|folder [duration]
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/error", s.postSystemError)                              // <body>
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/error/clear", s.postSystemErrorClear)                   // -
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/ping", s.restPing)                                      // -
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/reset", s.postSystemReset)                              // [folder]
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/restart", s.postSystemRestart)                          // -
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/shutdown", s.postSystemShutdown)                        // -
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/upgrade", s.postSystemUpgrade)                          // -
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/pause", s.makeDevicePauseHandler(true))                 // [device]
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/resume", s.makeDevicePauseHandler(false))               // [device]
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/loglevels", s.postSystemDebug)                          // [enable] [disable]
+	restMux.HandlerFunc(http.MethodPost, "/rest/system/backup", s.postSystemBackup)                            // <body>
 
 	// The DELETE handlers
-	restMux.HandlerFunc(http.MethodDelete, "/rest/cluster/pending/devices", s.deletePendingDevices) // device
-	restMux.HandlerFunc(http.MethodDelete, "/rest/cluster/pending/folders", s.deletePendingFolders) // folder [device]
+	restMux.HandlerFunc(http.MethodDelete, "/rest/cluster/configsync", s.deleteClusterConfigSync)              // device part [key]
+	restMux.HandlerFunc(http.MethodDelete, "/rest/cluster/identitychanges", s.deleteIdentityChanges)           // device
+	restMux.HandlerFunc(http.MethodDelete, "/rest/cluster/pending/devices", s.deletePendingDevices)            // device
+	restMux.HandlerFunc(http.MethodDelete, "/rest/cluster/pending/folders", s.deletePendingFolders)            // folder [device]
+	restMux.HandlerFunc(http.MethodDelete, "/rest/cluster/revocations/pending", s.deletePendingRevocations)    // device
+	restMux.HandlerFunc(http.MethodDelete, "/rest/folder/anomalies", s.deleteFolderAnomalies)                  // folder
+	restMux.HandlerFunc(http.MethodDelete, "/rest/folder/handover", s.deleteFolderHandover)                    // folder
+	restMux.HandlerFunc(http.MethodDelete, "/rest/folder/handover/pending", s.deletePendingFolderHandover)     // folder device
+	restMux.HandlerFunc(http.MethodDelete, "/rest/folder/ownership/pending", s.deletePendingOwnershipClaim)    // folder device
+	restMux.HandlerFunc(http.MethodDelete, "/rest/system/backup", s.deleteSystemBackup)                        // name
+	restMux.HandlerFunc(http.MethodDelete, "/rest/system/capture", s.deleteSystemCapture)                      // -
+	restMux.HandlerFunc(http.MethodDelete, "/rest/system/connections/budgets", s.deleteSystemConnectionBudget) // device
 
 	// Config endpoints
 
@@ -337,6 +407,7 @@ func (s *service) Serve(ctx context.Context) error {
 	debugMux := http.NewServeMux()
 	debugMux.HandleFunc("/rest/debug/cpuprof", s.getCPUProf) // duration
 	debugMux.HandleFunc("/rest/debug/heapprof", s.getHeapProf)
+	debugMux.HandleFunc("/rest/debug/capture", s.getDebugCapture)
 	debugMux.HandleFunc("/rest/debug/support", s.getSupportBundle)
 	debugMux.HandleFunc("/rest/debug/file", s.getDebugFile)
 	restMux.Handler(http.MethodGet, "/rest/debug/*method", debugMux)
@@ -436,6 +507,17 @@ func (s *service) Serve(ctx context.Context) error {
 		}
 	}()
 
+	// Serve the control socket alongside, with the same endpoints
+
+	if guiCfg.RPCSocket != "" {
+		if rpcListener, err := listenRPC(guiCfg.RPCSocket); err != nil {
+			slog.ErrorContext(ctx, "Failed to start control socket", slogutil.FilePath(guiCfg.RPCSocket), slogutil.Error(err))
+		} else {
+			defer rpcListener.Close()
+			go s.serveRPC(ctx, rpcListener, noCacheRestMux)
+		}
+	}
+
 	// Wait for stop, restart or error signals
 
 	err = nil
@@ -630,6 +712,98 @@ func noCacheMiddleware(h http.Handler) http.Handler {
 	})
 }
 
+func (s *service) getIdentityChanges(w http.ResponseWriter, _ *http.Request) {
+	sendJSON(w, s.model.IdentityChanges())
+}
+
+// postIdentityChangeApprove accepts the new certificate of a device whose
+// identity changed, replacing the device ID in the configuration.
+func (s *service) postIdentityChangeApprove(w http.ResponseWriter, r *http.Request) {
+	deviceID, err := protocol.DeviceIDFromString(r.URL.Query().Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch err := s.model.ApproveIdentityChange(deviceID); {
+	case err == nil:
+	case errors.Is(err, model.ErrNoIdentityChange):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *service) deleteIdentityChanges(w http.ResponseWriter, r *http.Request) {
+	deviceID, err := protocol.DeviceIDFromString(r.URL.Query().Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := s.model.DismissIdentityChange(deviceID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+	}
+}
+
+func (s *service) getClusterConfigSync(w http.ResponseWriter, _ *http.Request) {
+	changes, err := s.model.PendingConfigSync()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, map[string]interface{}{
+		"pending": changes,
+	})
+}
+
+func (s *service) postClusterConfigSyncApprove(w http.ResponseWriter, r *http.Request) {
+	s.handleConfigSyncChange(w, r, s.model.ApproveConfigSync)
+}
+
+func (s *service) deleteClusterConfigSync(w http.ResponseWriter, r *http.Request) {
+	s.handleConfigSyncChange(w, r, s.model.RejectConfigSync)
+}
+
+func (*service) handleConfigSyncChange(w http.ResponseWriter, r *http.Request, fn func(protocol.DeviceID, string, string) error) {
+	qs := r.URL.Query()
+	deviceID, err := protocol.DeviceIDFromString(qs.Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch err := fn(deviceID, qs.Get("part"), qs.Get("key")); {
+	case err == nil:
+	case errors.Is(err, model.ErrConfigSyncNotPending):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// postClusterConfigSyncPromote makes this device take over from the given
+// device, which it has been a warm spare for.
+func (s *service) postClusterConfigSyncPromote(w http.ResponseWriter, r *http.Request) {
+	deviceID, err := protocol.DeviceIDFromString(r.URL.Query().Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	resumed, err := s.model.PromoteConfigSyncSpare(deviceID)
+	switch {
+	case err == nil:
+		sendJSON(w, map[string]interface{}{
+			"folders": resumed,
+		})
+	case errors.Is(err, model.ErrConfigSyncDisabled):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusNotFound)
+	}
+}
+
 func (s *service) getPendingDevices(w http.ResponseWriter, _ *http.Request) {
 	devices, err := s.model.PendingDevices()
 	if err != nil {
@@ -672,6 +846,96 @@ func (s *service) getPendingFolders(w http.ResponseWriter, r *http.Request) {
 	sendJSON(w, folders)
 }
 
+// getPendingFolderPreview fetches a summary of the contents of a folder
+// from the device offering it, so that the user can make an informed
+// decision on accepting it.
+func (s *service) getPendingFolderPreview(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+
+	deviceID, err := protocol.DeviceIDFromString(qs.Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	folder := qs.Get("folder")
+	if folder == "" {
+		http.Error(w, "missing folder", http.StatusBadRequest)
+		return
+	}
+	entries := model.DefaultFolderPreviewEntries
+	if str := qs.Get("entries"); str != "" {
+		entries, err = strconv.Atoi(str)
+		if err != nil || entries <= 0 {
+			http.Error(w, "invalid entries", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), folderPreviewTimeout)
+	defer cancel()
+	preview, err := s.model.RequestFolderPreview(ctx, deviceID, folder, entries)
+	switch {
+	case err == nil:
+		sendJSON(w, preview)
+	case errors.Is(err, model.ErrDeviceNotConnected), errors.Is(err, protocol.ErrNoSuchFile):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, context.DeadlineExceeded):
+		http.Error(w, err.Error(), http.StatusGatewayTimeout)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *service) getClusterQueue(w http.ResponseWriter, r *http.Request) {
+	deviceID, err := protocol.DeviceIDFromString(r.URL.Query().Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	msgs, err := s.model.QueuedDeviceMessages(deviceID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, map[string]interface{}{
+		"messages": msgs,
+	})
+}
+
+func (s *service) postClusterQueue(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	deviceID, err := protocol.DeviceIDFromString(qs.Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, ok := s.cfg.Device(deviceID); !ok {
+		http.Error(w, "unknown device", http.StatusNotFound)
+		return
+	}
+	kind := qs.Get("kind")
+	if kind == "" {
+		http.Error(w, "missing kind", http.StatusBadRequest)
+		return
+	}
+
+	payload, err := io.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch err := s.model.QueueDeviceMessage(deviceID, kind, qs.Get("key"), payload); {
+	case err == nil:
+	case errors.Is(err, model.ErrControlMessageTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func (s *service) deletePendingFolders(w http.ResponseWriter, r *http.Request) {
 	qs := r.URL.Query()
 
@@ -780,7 +1044,19 @@ func (s *service) getDBCompletion(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if comp, err := s.model.Completion(device, folder); err != nil {
+	var comp model.FolderCompletion
+	var err error
+	if prefix := qs.Get("prefix"); prefix != "" {
+		// Completion of a directory within the folder
+		if folder == "" {
+			http.Error(w, "prefix requires a folder", http.StatusBadRequest)
+			return
+		}
+		comp, err = s.model.PrefixCompletion(device, folder, prefix)
+	} else {
+		comp, err = s.model.Completion(device, folder)
+	}
+	if err != nil {
 		status := http.StatusInternalServerError
 		if isFolderNotFound(err) {
 			status = http.StatusNotFound
@@ -791,6 +1067,36 @@ func (s *service) getDBCompletion(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// getDBWaitIdle blocks until the folder is idle and in sync with all
+// connected devices, giving scripts a reliable synchronization point. The
+// timeout is given in seconds and defaults to one minute; zero means
+// waiting until the client goes away.
+func (s *service) getDBWaitIdle(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	folder := qs.Get("folder")
+	timeoutS := 60.0
+	if str := qs.Get("timeout"); str != "" {
+		var err error
+		timeoutS, err = strconv.ParseFloat(str, 64)
+		if err != nil || timeoutS < 0 {
+			http.Error(w, "invalid timeout", http.StatusBadRequest)
+			return
+		}
+	}
+
+	err := s.model.WaitForFolderIdle(r.Context(), folder, time.Duration(timeoutS*float64(time.Second)))
+	switch {
+	case err == nil:
+		sendJSON(w, map[string]interface{}{"folder": folder, "idle": true})
+	case errors.Is(err, model.ErrFolderIdleTimeout):
+		http.Error(w, err.Error(), http.StatusGatewayTimeout)
+	case isFolderNotFound(err):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func (s *service) getDBStatus(w http.ResponseWriter, r *http.Request) {
 	qs := r.URL.Query()
 	folder := qs.Get("folder")
@@ -801,6 +1107,15 @@ func (s *service) getDBStatus(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *service) getDBStatusAll(w http.ResponseWriter, r *http.Request) {
+	snap, err := s.fss.Summaries(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, snap)
+}
+
 func (s *service) postDBOverride(_ http.ResponseWriter, r *http.Request) {
 	qs := r.URL.Query()
 	folder := qs.Get("folder")
@@ -894,10 +1209,175 @@ func (s *service) getDBLocalChanged(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// localChangeCSVPageSize is how many local changes are fetched at a time
+// when exporting them all as CSV.
+const localChangeCSVPageSize = 1000
+
+func (s *service) getDBLocalChangedReport(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+
+	folder := qs.Get("folder")
+	withDiff := qs.Get("diff") == "true"
+
+	switch qs.Get("format") {
+	case "", "json":
+		page, perpage := getPagingParams(qs)
+		changes, err := s.model.LocalChangeReport(folder, page, perpage, withDiff)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		sendJSON(w, map[string]interface{}{
+			"changes": changes,
+			"page":    page,
+			"perpage": perpage,
+		})
+	case "csv":
+		s.writeLocalChangeCSV(w, folder, withDiff)
+	default:
+		http.Error(w, "unsupported format", http.StatusBadRequest)
+	}
+}
+
+// writeLocalChangeCSV streams all the local changes of the folder as CSV,
+// a page at a time.
+func (s *service) writeLocalChangeCSV(w http.ResponseWriter, folder string, withDiff bool) {
+	changes, err := s.model.LocalChangeReport(folder, 1, localChangeCSVPageSize, withDiff)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	filename := fmt.Sprintf("localchanged-%s-%s.csv", folder, time.Now().Format("2006-01-02T150405"))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", "attachment; filename="+url.PathEscape(filename))
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"name", "type", "action", "modified", "modifiedBy", "size", "globalSize", "sizeDelta", "blocks", "changedBlocks", "changedBytes"})
+	for page := 1; ; page++ {
+		if page > 1 {
+			changes, err = s.model.LocalChangeReport(folder, page, localChangeCSVPageSize, withDiff)
+			if err != nil {
+				// Too late for an error status; the export ends short.
+				slog.Warn("Failed to export local changes", slog.String("folder", folder), slogutil.Error(err))
+				break
+			}
+		}
+		for _, c := range changes {
+			var blocks, changedBlocks, changedBytes string
+			if c.Diff != nil {
+				blocks = strconv.Itoa(c.Diff.Blocks)
+				changedBlocks = strconv.Itoa(c.Diff.ChangedBlocks)
+				changedBytes = strconv.FormatInt(c.Diff.ChangedBytes, 10)
+			}
+			cw.Write([]string{
+				c.Name, c.Type, c.Action,
+				c.Modified.Format(time.RFC3339Nano), c.ModifiedBy.String(),
+				strconv.FormatInt(c.Size, 10), strconv.FormatInt(c.GlobalSize, 10), strconv.FormatInt(c.SizeDelta, 10),
+				blocks, changedBlocks, changedBytes,
+			})
+		}
+		cw.Flush()
+		if len(changes) < localChangeCSVPageSize {
+			break
+		}
+	}
+}
+
+// getDBPullPreview returns what the next pull of the folder would do,
+// without doing it.
+func (s *service) getDBPullPreview(w http.ResponseWriter, r *http.Request) {
+	preview, err := s.model.PullPreview(r.URL.Query().Get("folder"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	sendJSON(w, preview)
+}
+
+func (s *service) getDBConflicts(w http.ResponseWriter, r *http.Request) {
+	conflicts, err := s.model.Conflicts(r.URL.Query().Get("folder"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	sendJSON(w, map[string]interface{}{
+		"conflicts": conflicts,
+	})
+}
+
+func (s *service) getDBConflictVersions(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	file, conflict, err := s.model.ConflictVersions(qs.Get("folder"), qs.Get("conflict"))
+	switch {
+	case err == nil:
+	case errors.Is(err, model.ErrNoSuchConflict) || isFolderNotFound(err):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, map[string]interface{}{
+		"file":     jsonFileInfo(file),
+		"conflict": jsonFileInfo(conflict),
+	})
+}
+
+func (s *service) postDBConflictResolve(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	switch err := s.model.ResolveConflict(qs.Get("folder"), qs.Get("conflict"), qs.Get("keep")); {
+	case err == nil:
+	case errors.Is(err, model.ErrNoSuchConflict) || isFolderNotFound(err):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func (s *service) getSystemConnections(w http.ResponseWriter, _ *http.Request) {
 	sendJSON(w, s.model.ConnectionStats())
 }
 
+// getSystemConnectionAttempts returns the timing of the latest attempts at
+// connecting with the device.
+func (s *service) getSystemConnectionAttempts(w http.ResponseWriter, r *http.Request) {
+	device, err := protocol.DeviceIDFromString(r.URL.Query().Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	attempts := s.connectionsService.ConnectionAttempts(device)
+	if attempts == nil {
+		attempts = []connections.ConnectionAttempt{}
+	}
+	sendJSON(w, attempts)
+}
+
+// getSystemConnectionBudgets returns the connection error budgets of the
+// devices that have had errors recently or exhausted their budget.
+func (s *service) getSystemConnectionBudgets(w http.ResponseWriter, _ *http.Request) {
+	sendJSON(w, s.connectionsService.ConnectionErrorBudgets())
+}
+
+// getSystemConnectionHealth returns the health of connections, overall and
+// by device and address, and the resulting adaptive keep-alive interval.
+func (s *service) getSystemConnectionHealth(w http.ResponseWriter, _ *http.Request) {
+	sendJSON(w, s.connectionsService.ConnectionHealth())
+}
+
+// deleteSystemConnectionBudget acknowledges that the device exhausted its
+// connection error budget.
+func (s *service) deleteSystemConnectionBudget(w http.ResponseWriter, r *http.Request) {
+	device, err := protocol.DeviceIDFromString(r.URL.Query().Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.connectionsService.AcknowledgeConnectionErrorBudget(device) {
+		http.Error(w, "error budget of device not exhausted", http.StatusNotFound)
+	}
+}
+
 func (s *service) getDeviceStats(w http.ResponseWriter, _ *http.Request) {
 	stats, err := s.model.DeviceStatistics()
 	if err != nil {
@@ -907,6 +1387,49 @@ func (s *service) getDeviceStats(w http.ResponseWriter, _ *http.Request) {
 	sendJSON(w, stats)
 }
 
+// getDeviceAddressLedger returns the addresses devices were seen at, for
+// all devices or the given one, as JSON or exported as CSV.
+func (s *service) getDeviceAddressLedger(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	ledgers, err := s.model.DeviceAddressLedgers()
+	if err != nil {
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if device := qs.Get("device"); device != "" {
+		id, err := protocol.DeviceIDFromString(device)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ledger, ok := ledgers[id]
+		if !ok {
+			http.Error(w, "unknown device", http.StatusNotFound)
+			return
+		}
+		ledgers = map[protocol.DeviceID][]stats.AddressLedgerEntry{id: ledger}
+	}
+
+	switch qs.Get("format") {
+	case "", "json":
+		sendJSON(w, ledgers)
+	case "csv":
+		filename := fmt.Sprintf("device-addresses-%s.csv", time.Now().Format("2006-01-02T150405"))
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", "attachment; filename="+url.PathEscape(filename))
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"device", "address", "transport", "firstSeen", "lastSeen"})
+		for _, id := range slices.SortedFunc(maps.Keys(ledgers), protocol.DeviceID.Compare) {
+			for _, e := range ledgers[id] {
+				cw.Write([]string{id.String(), e.Address, e.Transport, e.FirstSeen.Format(time.RFC3339), e.LastSeen.Format(time.RFC3339)})
+			}
+		}
+		cw.Flush()
+	default:
+		http.Error(w, "unsupported format", http.StatusBadRequest)
+	}
+}
+
 func (s *service) getFolderStats(w http.ResponseWriter, _ *http.Request) {
 	stats, err := s.model.FolderStatistics()
 	if err != nil {
@@ -1060,6 +1583,7 @@ func (s *service) getSystemStatus(w http.ResponseWriter, _ *http.Request) {
 
 	res["connectionServiceStatus"] = s.connectionsService.ListenerStatus()
 	res["lastDialStatus"] = s.connectionsService.ConnectionStatus()
+	res["ipv6Only"] = s.cfg.Options().IPv6Only
 	res["cpuPercent"] = 0 // deprecated from API
 	res["pathSeparator"] = string(filepath.Separator)
 	res["urVersionMax"] = ur.Version
@@ -1111,22 +1635,124 @@ func (s *service) getSystemLogTxt(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-type fileEntry struct {
-	name string
-	data []byte
+// getConfigDrift returns how the configuration drifted from the reference
+// profile, as of the latest check.
+func (s *service) getConfigDrift(w http.ResponseWriter, _ *http.Request) {
+	if s.profiles == nil {
+		http.Error(w, "reference profiles are not available", http.StatusNotImplemented)
+		return
+	}
+	sendJSON(w, s.profiles.Status())
 }
 
-func (s *service) getSupportBundle(w http.ResponseWriter, r *http.Request) {
-	var files []fileEntry
-	const profilingDuration = 4 * time.Second
-
-	// Redacted configuration as a JSON
-	if jsonConfig, err := json.MarshalIndent(getRedactedConfig(s), "", "  "); err != nil {
-		slog.Warn("Failed to create config.json in support bundle", slogutil.Error(err))
-	} else {
-		files = append(files, fileEntry{name: "config.json.txt", data: jsonConfig})
+// postConfigDriftCorrect undoes the drift from the reference profile.
+func (s *service) postConfigDriftCorrect(w http.ResponseWriter, _ *http.Request) {
+	if s.profiles == nil {
+		http.Error(w, "reference profiles are not available", http.StatusNotImplemented)
+		return
 	}
-
+	status, err := s.profiles.Correct()
+	switch {
+	case errors.Is(err, profile.ErrNoProfile):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		sendJSON(w, status)
+	}
+}
+
+func (s *service) getSystemBackups(w http.ResponseWriter, _ *http.Request) {
+	if s.backups == nil {
+		http.Error(w, "backups are not available", http.StatusNotImplemented)
+		return
+	}
+	backups, err := s.backups.List()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if backups == nil {
+		backups = []backup.Info{}
+	}
+	sendJSON(w, backups)
+}
+
+func (s *service) postSystemBackup(w http.ResponseWriter, r *http.Request) {
+	if s.backups == nil {
+		http.Error(w, "backups are not available", http.StatusNotImplemented)
+		return
+	}
+	var req struct {
+		Passphrase string `json:"passphrase"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	info, err := s.backups.Create(req.Passphrase)
+	if err != nil {
+		slog.Warn("Failed to create backup", slogutil.Error(err))
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, info)
+}
+
+// getSystemBackupFile serves the backup file, supporting range requests so
+// that interrupted downloads can be resumed.
+func (s *service) getSystemBackupFile(w http.ResponseWriter, r *http.Request) {
+	if s.backups == nil {
+		http.Error(w, "backups are not available", http.StatusNotImplemented)
+		return
+	}
+	fd, info, err := s.backups.Open(r.URL.Query().Get("name"))
+	if errors.Is(err, backup.ErrNoSuchBackup) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer fd.Close()
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", info.Name))
+	http.ServeContent(w, r, info.Name, info.ModTime, fd)
+}
+
+func (s *service) deleteSystemBackup(w http.ResponseWriter, r *http.Request) {
+	if s.backups == nil {
+		http.Error(w, "backups are not available", http.StatusNotImplemented)
+		return
+	}
+	err := s.backups.Remove(r.URL.Query().Get("name"))
+	if errors.Is(err, backup.ErrNoSuchBackup) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+type fileEntry struct {
+	name string
+	data []byte
+}
+
+func (s *service) getSupportBundle(w http.ResponseWriter, r *http.Request) {
+	var files []fileEntry
+	const profilingDuration = 4 * time.Second
+
+	// Redacted configuration as a JSON
+	if jsonConfig, err := json.MarshalIndent(getRedactedConfig(s), "", "  "); err != nil {
+		slog.Warn("Failed to create config.json in support bundle", slogutil.Error(err))
+	} else {
+		files = append(files, fileEntry{name: "config.json.txt", data: jsonConfig})
+	}
+
 	// Log as a text
 	var buflog bytes.Buffer
 	for _, line := range s.systemLog.Since(time.Time{}) {
@@ -1259,6 +1885,12 @@ func (s *service) getSupportBundle(w http.ResponseWriter, r *http.Request) {
 	io.Copy(w, &zipFilesBuffer)
 }
 
+// getSystemInfrastructure returns the outcome of the latest probes of the
+// global discovery servers and relay pools in use.
+func (s *service) getSystemInfrastructure(w http.ResponseWriter, _ *http.Request) {
+	sendJSON(w, s.connectionsService.InfrastructureStatus())
+}
+
 func (s *service) getSystemDiscovery(w http.ResponseWriter, _ *http.Request) {
 	devices := make(map[string]discover.CacheEntry)
 
@@ -1417,10 +2049,28 @@ func (s *service) getSystemUpgrade(w http.ResponseWriter, _ *http.Request) {
 	res["latest"] = rel.Tag
 	res["newer"] = upgrade.CompareVersions(rel.Tag, build.Version) == upgrade.Newer
 	res["majorNewer"] = upgrade.CompareVersions(rel.Tag, build.Version) == upgrade.MajorNewer
+	res["incompatiblePeers"] = s.incompatibleUpgradePeers(rel)
 
 	sendJSON(w, res)
 }
 
+// incompatibleUpgradePeers returns the devices that would be incompatible
+// with the release, going by the clients they last connected with.
+func (s *service) incompatibleUpgradePeers(rel upgrade.Release) []upgrade.Peer {
+	peers := []upgrade.Peer{}
+	devStats, err := s.model.DeviceStatistics()
+	if err != nil {
+		return peers
+	}
+	var known []upgrade.Peer
+	for id, st := range devStats {
+		if peer, ok := upgrade.PeerFromClient(id.String(), st.LastClient); ok {
+			known = append(known, peer)
+		}
+	}
+	return append(peers, upgrade.IncompatiblePeers(rel, known)...)
+}
+
 func (*service) getDeviceID(w http.ResponseWriter, r *http.Request) {
 	qs := r.URL.Query()
 	idStr := qs.Get("id")
@@ -1558,6 +2208,29 @@ func (s *service) postDBScan(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// postDBTempCleanup removes leftover temporary files of the folder now,
+// returning what was removed and kept.
+func (s *service) postDBTempCleanup(w http.ResponseWriter, r *http.Request) {
+	report, err := s.model.CleanTempFiles(r.URL.Query().Get("folder"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, report)
+}
+
+// postDBMerge merges the source folder into the target folder, or with
+// dryrun only reports what the merge would do.
+func (s *service) postDBMerge(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	report, err := s.model.MergeFolders(qs.Get("target"), qs.Get("source"), qs.Get("dryrun") == "true")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, report)
+}
+
 func (s *service) postDBPrio(w http.ResponseWriter, r *http.Request) {
 	qs := r.URL.Query()
 	folder := qs.Get("folder")
@@ -1618,6 +2291,364 @@ func (s *service) postFolderVersionsRestore(w http.ResponseWriter, r *http.Reque
 	sendJSON(w, errorStringMap(ferr))
 }
 
+func (s *service) getFolderAtTime(w http.ResponseWriter, r *http.Request) {
+	s.folderAtTime(w, r, "")
+}
+
+func (s *service) postFolderAtTime(w http.ResponseWriter, r *http.Request) {
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		http.Error(w, "missing target", http.StatusBadRequest)
+		return
+	}
+	s.folderAtTime(w, r, target)
+}
+
+// folderAtTime lists, or with a target restores, the folder as it was at
+// the given time.
+func (s *service) folderAtTime(w http.ResponseWriter, r *http.Request, target string) {
+	qs := r.URL.Query()
+	at, err := time.Parse(time.RFC3339, qs.Get("time"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	report, err := s.model.FolderAtTime(qs.Get("folder"), qs.Get("prefix"), at, target)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, report)
+}
+
+func (s *service) getFolderAnomalies(w http.ResponseWriter, _ *http.Request) {
+	sendJSON(w, s.model.ChangeAnomalies())
+}
+
+// deleteFolderAnomalies acknowledges the change anomaly of a folder. A
+// folder paused because of it must be resumed separately.
+func (s *service) deleteFolderAnomalies(w http.ResponseWriter, r *http.Request) {
+	if err := s.model.AcknowledgeChangeAnomaly(r.URL.Query().Get("folder")); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+	}
+}
+
+// getFolderCapabilities returns what the filesystem of a folder was found
+// to support, or null if it hasn't been probed.
+func (s *service) getFolderCapabilities(w http.ResponseWriter, r *http.Request) {
+	caps, err := s.model.FolderCapabilities(r.URL.Query().Get("folder"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	sendJSON(w, caps)
+}
+
+// postFolderCapabilities probes the filesystem of a folder again.
+func (s *service) postFolderCapabilities(w http.ResponseWriter, r *http.Request) {
+	caps, err := s.model.ProbeFolderCapabilities(r.URL.Query().Get("folder"))
+	if errors.Is(err, model.ErrFolderMissing) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, caps)
+}
+
+func (s *service) getFolderHandover(w http.ResponseWriter, _ *http.Request) {
+	handovers, err := s.model.FolderHandovers()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, handovers)
+}
+
+// postFolderHandover starts handing the responsibility for a folder over
+// to another device; the progress is reported by getFolderHandover and
+// FolderHandoverProgress events.
+func (s *service) postFolderHandover(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	deviceID, err := protocol.DeviceIDFromString(qs.Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	handover, err := s.model.StartFolderHandover(qs.Get("folder"), deviceID, qs.Get("after"))
+	switch {
+	case err == nil:
+		sendJSON(w, handover)
+	case errors.Is(err, model.ErrFolderMissing):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, model.ErrHandoverInProgress):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, model.ErrHandoverTarget), errors.Is(err, model.ErrHandoverAfter):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// deleteFolderHandover cancels the handover of a folder, or forgets about
+// it once finished.
+func (s *service) deleteFolderHandover(w http.ResponseWriter, r *http.Request) {
+	switch err := s.model.ClearFolderHandover(r.URL.Query().Get("folder")); {
+	case err == nil:
+	case errors.Is(err, model.ErrHandoverNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *service) getPendingFolderHandovers(w http.ResponseWriter, _ *http.Request) {
+	pending, err := s.model.PendingFolderHandovers()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, pending)
+}
+
+// postFolderHandoverApprove applies a takeover or repoint message received
+// from an introducer.
+func (s *service) postFolderHandoverApprove(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	deviceID, err := protocol.DeviceIDFromString(qs.Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch err := s.model.ApproveFolderHandover(qs.Get("folder"), deviceID); {
+	case err == nil:
+	case errors.Is(err, model.ErrHandoverNotPending), errors.Is(err, model.ErrFolderMissing):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *service) deletePendingFolderHandover(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	deviceID, err := protocol.DeviceIDFromString(qs.Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch err := s.model.RejectFolderHandover(qs.Get("folder"), deviceID); {
+	case err == nil:
+	case errors.Is(err, model.ErrHandoverNotPending):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *service) getPendingOwnershipClaims(w http.ResponseWriter, _ *http.Request) {
+	pending, err := s.model.PendingOwnershipClaims()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, pending)
+}
+
+// postOwnershipClaimApprove configures the prefixes a remote device
+// announced owning in a folder for it.
+func (s *service) postOwnershipClaimApprove(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	deviceID, err := protocol.DeviceIDFromString(qs.Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch err := s.model.ApproveOwnershipClaim(qs.Get("folder"), deviceID); {
+	case err == nil:
+	case errors.Is(err, model.ErrOwnershipClaimNotPending), errors.Is(err, model.ErrFolderMissing):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *service) deletePendingOwnershipClaim(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	deviceID, err := protocol.DeviceIDFromString(qs.Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch err := s.model.RejectOwnershipClaim(qs.Get("folder"), deviceID); {
+	case err == nil:
+	case errors.Is(err, model.ErrOwnershipClaimNotPending):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *service) getClusterRevocations(w http.ResponseWriter, _ *http.Request) {
+	revocations, err := s.model.DeviceRevocations()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, revocations)
+}
+
+// postClusterRevocations revokes a device, e.g. one that was lost; how the
+// revocation spreads is reported by getClusterRevocations and
+// DeviceRevocationProgress events.
+func (s *service) postClusterRevocations(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	deviceID, err := protocol.DeviceIDFromString(qs.Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	revocation, err := s.model.RevokeDevice(deviceID, qs.Get("distrust") == "true")
+	switch {
+	case err == nil:
+		sendJSON(w, revocation)
+	case errors.Is(err, model.ErrRevocationTarget):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, model.ErrDeviceRevoked):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *service) getPendingRevocations(w http.ResponseWriter, _ *http.Request) {
+	pending, err := s.model.PendingDeviceRevocations()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sendJSON(w, pending)
+}
+
+// postRevocationsApprove confirms a revocation received from a
+// device that didn't introduce the revoked one.
+func (s *service) postRevocationsApprove(w http.ResponseWriter, r *http.Request) {
+	deviceID, err := protocol.DeviceIDFromString(r.URL.Query().Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	revocation, err := s.model.ApproveDeviceRevocation(deviceID)
+	switch {
+	case err == nil:
+		sendJSON(w, revocation)
+	case errors.Is(err, model.ErrRevocationNotPending):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *service) deletePendingRevocations(w http.ResponseWriter, r *http.Request) {
+	deviceID, err := protocol.DeviceIDFromString(r.URL.Query().Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch err := s.model.RejectDeviceRevocation(deviceID); {
+	case err == nil:
+	case errors.Is(err, model.ErrRevocationNotPending):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// folderHealth is the latest health check of a folder, nothing before the
+// first one.
+type folderHealth struct {
+	Status      *config.FolderHealthStatus    `json:"status,omitempty"`
+	Performance *model.FolderPerformanceStats `json:"performance,omitempty"`
+}
+
+// healthModel returns the model's health monitoring, answering 501 if it
+// has none.
+func (s *service) healthModel(w http.ResponseWriter) (model.HealthMonitoringModel, bool) {
+	hm, ok := s.model.(model.HealthMonitoringModel)
+	if !ok {
+		http.Error(w, "Folder health monitoring is not available", http.StatusNotImplemented)
+	}
+	return hm, ok
+}
+
+func folderHealthOf(hm model.HealthMonitoringModel, folder string) folderHealth {
+	var health folderHealth
+	if status, ok := hm.GetFolderHealthStatus(folder); ok {
+		health.Status = &status
+	}
+	if perf, ok := hm.GetFolderPerformanceStats(folder); ok {
+		health.Performance = &perf
+	}
+	return health
+}
+
+// getFolderHealth returns the health of all folders, by folder ID.
+func (s *service) getFolderHealth(w http.ResponseWriter, _ *http.Request) {
+	hm, ok := s.healthModel(w)
+	if !ok {
+		return
+	}
+	folders := s.cfg.Folders()
+	res := make(map[string]folderHealth, len(folders))
+	for id := range folders {
+		res[id] = folderHealthOf(hm, id)
+	}
+	sendJSON(w, res)
+}
+
+func (s *service) getFolderHealthID(w http.ResponseWriter, _ *http.Request, p httprouter.Params) {
+	folder := p.ByName("id")
+	if _, ok := s.cfg.Folder(folder); !ok {
+		http.Error(w, "No folder with given ID", http.StatusNotFound)
+		return
+	}
+	hm, ok := s.healthModel(w)
+	if !ok {
+		return
+	}
+	sendJSON(w, folderHealthOf(hm, folder))
+}
+
+// getFolderRecoveryCode returns the recovery code of the key the folder is
+// encrypted with for the device.
+func (s *service) getFolderRecoveryCode(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	device, err := protocol.DeviceIDFromString(qs.Get("device"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	code, err := s.model.EncryptionRecoveryCode(qs.Get("folder"), device)
+	switch {
+	case err == nil:
+		sendJSON(w, map[string]string{"recoveryCode": code})
+	case errors.Is(err, model.ErrFolderMissing):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, model.ErrNotSharedEncrypted):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 func (s *service) getFolderErrors(w http.ResponseWriter, r *http.Request) {
 	qs := r.URL.Query()
 	folder := qs.Get("folder")
@@ -10,6 +10,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -25,6 +26,7 @@ import (
 	"time"
 
 	"github.com/d4l3k/messagediff"
+	"github.com/julienschmidt/httprouter"
 	"github.com/thejerf/suture/v4"
 
 	"github.com/syncthing/syncthing/internal/db"
@@ -90,7 +92,7 @@ func TestStopAfterBrokenConfig(t *testing.T) {
 		mdb.Close()
 	})
 	kdb := db.NewMiscDB(mdb)
-	srv := New(protocol.LocalDeviceID, w, "", "syncthing", nil, nil, nil, events.NoopLogger, nil, nil, nil, nil, nil, nil, false, kdb).(*service)
+	srv := New(protocol.LocalDeviceID, w, "", "syncthing", nil, nil, nil, events.NoopLogger, nil, nil, nil, nil, nil, nil, false, kdb, nil, nil).(*service)
 
 	srv.started = make(chan string)
 
@@ -265,6 +267,28 @@ func TestAPIServiceRequests(t *testing.T) {
 			Type:   "application/json",
 			Prefix: "",
 		},
+		{
+			URL:    "/rest/db/waitidle?folder=default&timeout=1",
+			Code:   200,
+			Type:   "application/json",
+			Prefix: "{",
+		},
+		{
+			URL:  "/rest/db/waitidle?folder=default&timeout=-1",
+			Code: 400,
+		},
+
+		// /rest/folder
+		{
+			URL:    "/rest/folder/health",
+			Code:   200,
+			Type:   "application/json",
+			Prefix: "{",
+		},
+		{
+			URL:  "/rest/folder/health/default",
+			Code: 404,
+		},
 
 		// /rest/stats
 		{
@@ -1035,7 +1059,7 @@ func startHTTP(t *testing.T, cfg config.Wrapper) string {
 }
 
 func startHTTPWithShutdownTimeout(t *testing.T, cfg config.Wrapper, shutdownTimeout time.Duration) string {
-	m := new(modelmocks.Model)
+	m := new(modelmocks.HealthMonitoringModel)
 	assetDir := "../../gui"
 	eventSub := new(eventmocks.BufferedSubscription)
 	diskEventSub := new(eventmocks.BufferedSubscription)
@@ -1057,7 +1081,7 @@ func startHTTPWithShutdownTimeout(t *testing.T, cfg config.Wrapper, shutdownTime
 		mdb.Close()
 	})
 	kdb := db.NewMiscDB(mdb)
-	svc := New(protocol.LocalDeviceID, cfg, assetDir, "syncthing", m, eventSub, diskEventSub, events.NoopLogger, discoverer, connections, urService, mockedSummary, errorLog, systemLog, false, kdb).(*service)
+	svc := New(protocol.LocalDeviceID, cfg, assetDir, "syncthing", m, eventSub, diskEventSub, events.NoopLogger, discoverer, connections, urService, mockedSummary, errorLog, systemLog, false, kdb, nil, nil).(*service)
 	svc.started = addrChan
 
 	if shutdownTimeout > 0 {
@@ -1575,7 +1599,7 @@ func TestEventMasks(t *testing.T) {
 		mdb.Close()
 	})
 	kdb := db.NewMiscDB(mdb)
-	svc := New(protocol.LocalDeviceID, cfg, "", "syncthing", nil, defSub, diskSub, events.NoopLogger, nil, nil, nil, nil, nil, nil, false, kdb).(*service)
+	svc := New(protocol.LocalDeviceID, cfg, "", "syncthing", nil, defSub, diskSub, events.NoopLogger, nil, nil, nil, nil, nil, nil, false, kdb, nil, nil).(*service)
 
 	if mask := svc.getEventMask(""); mask != DefaultEventMask {
 		t.Errorf("incorrect default mask %x != %x", int64(mask), int64(DefaultEventMask))
@@ -1841,6 +1865,58 @@ func TestSanitizedHostname(t *testing.T) {
 	}
 }
 
+func TestFolderHealth(t *testing.T) {
+	t.Parallel()
+
+	cfg := newMockedConfig()
+	cfg.FoldersReturns(map[string]config.FolderConfiguration{"default": {ID: "default"}})
+	cfg.FolderReturns(config.FolderConfiguration{ID: "default"}, true)
+	get := func(s *service, id string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		if id == "" {
+			s.getFolderHealth(w, httptest.NewRequest(http.MethodGet, "/rest/folder/health", nil))
+		} else {
+			s.getFolderHealthID(w, httptest.NewRequest(http.MethodGet, "/rest/folder/health/"+id, nil), httprouter.Params{{Key: "id", Value: id}})
+		}
+		return w
+	}
+
+	s := &service{cfg: cfg, model: new(modelmocks.Model)}
+	if w := get(s, ""); w.Code != http.StatusNotImplemented {
+		t.Errorf("expected 501 without health monitoring, got %d", w.Code)
+	}
+
+	hm := new(modelmocks.HealthMonitoringModel)
+	hm.GetFolderHealthStatusReturns(config.FolderHealthStatus{FolderID: "default", Healthy: true}, true)
+	s = &service{cfg: cfg, model: hm}
+	w := get(s, "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var all map[string]folderHealth
+	if err := json.Unmarshal(w.Body.Bytes(), &all); err != nil {
+		t.Fatal(err)
+	}
+	if h := all["default"]; h.Status == nil || !h.Status.Healthy || h.Performance != nil {
+		t.Errorf("unexpected health %+v", h)
+	}
+
+	w = get(s, "default")
+	var one folderHealth
+	if err := json.Unmarshal(w.Body.Bytes(), &one); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || one.Status == nil || one.Status.FolderID != "default" {
+		t.Errorf("unexpected response %d %+v", w.Code, one)
+	}
+
+	cfg = newMockedConfig()
+	s = &service{cfg: cfg, model: hm}
+	if w := get(s, "missing"); w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for an unknown folder, got %d", w.Code)
+	}
+}
+
 // runningInContainer returns true if we are inside Docker or LXC. It might
 // be prone to false negatives if things change in the future, but likely
 // not false positives.
@@ -1861,3 +1937,52 @@ func runningInContainer() bool {
 	}
 	return false
 }
+
+func TestDBWaitIdleTimeout(t *testing.T) {
+	t.Parallel()
+
+	m := new(modelmocks.Model)
+	m.WaitForFolderIdleReturns(model.ErrFolderIdleTimeout)
+	s := &service{model: m}
+	w := httptest.NewRecorder()
+	s.getDBWaitIdle(w, httptest.NewRequest(http.MethodGet, "/rest/db/waitidle?folder=default&timeout=1", nil))
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("expected 504 on timeout, got %d", w.Code)
+	}
+}
+
+func TestDBLocalChangedReportCSVAllPages(t *testing.T) {
+	t.Parallel()
+
+	m := new(modelmocks.Model)
+	m.LocalChangeReportCalls(func(_ string, page, perpage int, _ bool) ([]model.LocalChange, error) {
+		// Two full pages and a partial one.
+		n := perpage
+		if page == 3 {
+			n = 5
+		} else if page > 3 {
+			n = 0
+		}
+		changes := make([]model.LocalChange, n)
+		for i := range changes {
+			changes[i] = model.LocalChange{Name: fmt.Sprintf("file-%d-%d", page, i), Action: model.LocalChangeAdded}
+		}
+		return changes, nil
+	})
+	s := &service{model: m}
+	w := httptest.NewRecorder()
+	s.getDBLocalChangedReport(w, httptest.NewRequest(http.MethodGet, "/rest/db/localchanged/report?folder=default&format=csv&page=1&perpage=10", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := 1 + 2*localChangeCSVPageSize + 5; len(records) != expected {
+		t.Errorf("expected %d records, got %d", expected, len(records))
+	}
+	if calls := m.LocalChangeReportCallCount(); calls != 3 {
+		t.Errorf("expected 3 pages to be fetched, got %d", calls)
+	}
+}
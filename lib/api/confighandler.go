@@ -205,6 +205,33 @@ func (c *configMuxBuilder) registerDevice(path string) {
 		}
 		c.finish(w, waiter)
 	})
+
+	// Adds the device given by the query as the replacement of this one,
+	// which is removed once the new device has connected.
+	c.Handle(http.MethodPost, path+"/replace", func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		device, ok := deviceFromParams(w, p)
+		if !ok {
+			return
+		}
+		newID, err := protocol.DeviceIDFromString(r.URL.Query().Get("device"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var replaceErr error
+		waiter, err := c.cfg.Modify(func(cfg *config.Configuration) {
+			replaceErr = cfg.ReplaceDevice(device.DeviceID, newID)
+		})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if replaceErr != nil {
+			http.Error(w, replaceErr.Error(), http.StatusBadRequest)
+			return
+		}
+		c.finish(w, waiter)
+	})
 }
 
 func (c *configMuxBuilder) registerDefaultFolder(path string) {
@@ -0,0 +1,332 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+const (
+	defaultCaptureDuration = 10 * time.Minute
+	maxCaptureDuration     = time.Hour
+)
+
+// The packages whose log level is raised to debug while capturing, for a
+// device and for a folder.
+var (
+	deviceCapturePackages = []string{"connections", "dialer", "protocol", "model"}
+	folderCapturePackages = []string{"model", "scanner", "versioner", "watchaggregator"}
+)
+
+// debugCaptureStatus is what is known about a debug capture.
+type debugCaptureStatus struct {
+	Device   string    `json:"device,omitempty"`
+	Folder   string    `json:"folder,omitempty"`
+	Started  time.Time `json:"started"`
+	Until    time.Time `json:"until"`
+	Stopped  time.Time `json:"stopped"` // zero while running
+	Packages []string  `json:"packages"`
+	// What was captured so far
+	LogLines     int `json:"logLines"`
+	DroppedLines int `json:"droppedLines"`
+	Events       int `json:"events"`
+}
+
+// A debugCapture records the log lines and events concerning one device or
+// folder for a while, with the log level of the related packages raised to
+// debug, as a focused alternative to debugging everything with STTRACE.
+type debugCapture struct {
+	debugCaptureStatus
+
+	mut      sync.Mutex
+	lineText string // what identifies the subject in log lines
+	evText   string // and in the JSON of event data
+	lines    *slogutil.LineCapture
+	events   []events.Event
+	cancel   context.CancelFunc
+	artifact []byte // once stopped
+}
+
+func (c *debugCapture) status() debugCaptureStatus {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	res := c.debugCaptureStatus
+	res.Events = len(c.events)
+	res.LogLines, res.DroppedLines = c.lines.Count()
+	return res
+}
+
+// run collects the events until the capture ends, then restores the log
+// levels and puts together the artifact.
+func (c *debugCapture) run(ctx context.Context, sub events.Subscription, restore func(), extra func() []fileEntry) {
+	defer sub.Unsubscribe()
+loop:
+	for {
+		select {
+		case ev := <-sub.C():
+			data, err := json.Marshal(ev.Data)
+			if err != nil || !bytes.Contains(data, []byte(c.evText)) {
+				continue
+			}
+			c.mut.Lock()
+			c.events = append(c.events, ev)
+			c.mut.Unlock()
+		case <-ctx.Done():
+			break loop
+		}
+	}
+	c.cancel()
+	c.lines.Stop()
+	restore()
+
+	c.mut.Lock()
+	c.Stopped = time.Now()
+	c.mut.Unlock()
+	artifact, err := c.zip(extra())
+	if err != nil {
+		slog.Warn("Failed to create debug capture artifact", slogutil.Error(err))
+	}
+	c.mut.Lock()
+	c.artifact = artifact
+	c.mut.Unlock()
+	slog.Info("Debug capture finished", slog.String("device", c.Device), slog.String("folder", c.Folder))
+}
+
+func (c *debugCapture) zip(extra []fileEntry) ([]byte, error) {
+	status := c.status()
+	statusJSON, err := json.MarshalIndent(status, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	var logBuf bytes.Buffer
+	lines, _ := c.lines.Lines()
+	for _, line := range lines {
+		line.WriteTo(&logBuf, slogutil.DefaultLineFormat)
+	}
+	c.mut.Lock()
+	eventsJSON, err := json.MarshalIndent(c.events, "", "  ")
+	c.mut.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	files := append([]fileEntry{
+		{name: "capture.json", data: statusJSON},
+		{name: "log.txt", data: logBuf.Bytes()},
+		{name: "events.json", data: eventsJSON},
+	}, extra...)
+	var buf bytes.Buffer
+	if err := writeZip(&buf, files); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// raiseLogLevels sets the packages to debug level, returning a function
+// that restores the previous levels of those that are still at debug.
+func raiseLogLevels(pkgs []string) func() {
+	levels := slogutil.PackageLevels()
+	prev := make(map[string]slog.Level)
+	for _, pkg := range pkgs {
+		if level, ok := levels[pkg]; ok && level > slog.LevelDebug {
+			prev[pkg] = level
+			slogutil.SetPackageLevel(pkg, slog.LevelDebug)
+		}
+	}
+	return func() {
+		levels := slogutil.PackageLevels()
+		for pkg, level := range prev {
+			if levels[pkg] == slog.LevelDebug {
+				slogutil.SetPackageLevel(pkg, level)
+			}
+		}
+	}
+}
+
+// postSystemCapture starts capturing the logs and events concerning a
+// device or a folder.
+func (s *service) postSystemCapture(w http.ResponseWriter, r *http.Request) {
+	qs := r.URL.Query()
+	duration := defaultCaptureDuration
+	if dur := qs.Get("duration"); dur != "" {
+		var err error
+		duration, err = time.ParseDuration(dur)
+		if err != nil || duration <= 0 || duration > maxCaptureDuration {
+			http.Error(w, fmt.Sprintf("duration must be positive and at most %v", maxCaptureDuration), http.StatusBadRequest)
+			return
+		}
+	}
+
+	c := &debugCapture{
+		debugCaptureStatus: debugCaptureStatus{
+			Started: time.Now(),
+			Until:   time.Now().Add(duration),
+		},
+	}
+	var extra func() []fileEntry
+	switch {
+	case qs.Get("device") != "" && qs.Get("folder") == "":
+		device, err := protocol.DeviceIDFromString(qs.Get("device"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, ok := s.cfg.Device(device); !ok {
+			http.Error(w, "no such device", http.StatusNotFound)
+			return
+		}
+		c.Device = device.String()
+		c.Packages = deviceCapturePackages
+		// Log lines mostly have the short ID, which also matches the start
+		// of the full one.
+		c.lineText = device.Short().String()
+		c.evText = jsonString(c.Device)
+		extra = func() []fileEntry {
+			return captureJSONFiles(map[string]any{
+				"connection-attempts.json": s.connectionsService.ConnectionAttempts(device),
+				"connection-health.json":   s.connectionsService.ConnectionHealth().Devices[device.String()],
+			})
+		}
+	case qs.Get("folder") != "" && qs.Get("device") == "":
+		folder := qs.Get("folder")
+		if _, ok := s.cfg.Folder(folder); !ok {
+			http.Error(w, "no such folder", http.StatusNotFound)
+			return
+		}
+		c.Folder = folder
+		c.Packages = folderCapturePackages
+		c.lineText = folder
+		c.evText = jsonString(folder)
+		extra = func() []fileEntry {
+			errs, _ := s.model.FolderErrors(folder)
+			return captureJSONFiles(map[string]any{
+				"folder-errors.json": errs,
+			})
+		}
+	default:
+		http.Error(w, "either device or folder must be given", http.StatusBadRequest)
+		return
+	}
+
+	s.captureMut.Lock()
+	defer s.captureMut.Unlock()
+	if s.capture != nil && s.capture.status().Stopped.IsZero() {
+		http.Error(w, "a debug capture is already running", http.StatusConflict)
+		return
+	}
+
+	ctx, cancel := context.WithDeadline(context.Background(), c.Until)
+	c.cancel = cancel
+	sub := s.evLogger.Subscribe(events.AllEvents)
+	restore := raiseLogLevels(c.Packages)
+	c.lines = slogutil.StartCapture(func(line slogutil.Line) bool {
+		return strings.Contains(line.Message, c.lineText)
+	})
+	s.capture = c
+	go c.run(ctx, sub, restore, extra)
+
+	slog.Info("Debug capture started", slog.String("device", c.Device), slog.String("folder", c.Folder), slog.Duration("duration", duration))
+	sendJSON(w, c.status())
+}
+
+// getSystemCapture returns the state of the latest debug capture.
+func (s *service) getSystemCapture(w http.ResponseWriter, _ *http.Request) {
+	s.captureMut.Lock()
+	c := s.capture
+	s.captureMut.Unlock()
+	if c == nil {
+		http.Error(w, "no debug capture", http.StatusNotFound)
+		return
+	}
+	sendJSON(w, c.status())
+}
+
+// deleteSystemCapture ends the running debug capture early.
+func (s *service) deleteSystemCapture(w http.ResponseWriter, _ *http.Request) {
+	s.captureMut.Lock()
+	c := s.capture
+	s.captureMut.Unlock()
+	if c == nil {
+		http.Error(w, "no debug capture", http.StatusNotFound)
+		return
+	}
+	c.cancel()
+}
+
+// getDebugCapture serves the artifact of the latest finished debug
+// capture.
+func (s *service) getDebugCapture(w http.ResponseWriter, _ *http.Request) {
+	s.captureMut.Lock()
+	c := s.capture
+	s.captureMut.Unlock()
+	if c == nil {
+		http.Error(w, "no debug capture", http.StatusNotFound)
+		return
+	}
+	c.mut.Lock()
+	artifact := c.artifact
+	c.mut.Unlock()
+	if artifact == nil {
+		http.Error(w, "debug capture not finished", http.StatusConflict)
+		return
+	}
+
+	subject := c.Device
+	if subject == "" {
+		subject = c.Folder
+	}
+	if device, err := protocol.DeviceIDFromString(subject); err == nil {
+		subject = device.Short().String()
+	}
+	zipFileName := fmt.Sprintf("debug-capture-%s-%s.zip", sanitizedFileNamePart(subject), c.Started.Format("2006-01-02T150405"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", "attachment; filename="+zipFileName)
+	w.Write(artifact)
+}
+
+func captureJSONFiles(contents map[string]any) []fileEntry {
+	var files []fileEntry
+	for name, v := range contents {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			slog.Warn("Failed to serialize debug capture file", slog.String("name", name), slogutil.Error(err))
+			continue
+		}
+		files = append(files, fileEntry{name: name, data: data})
+	}
+	return files
+}
+
+func jsonString(s string) string {
+	bs, _ := json.Marshal(s)
+	return string(bs)
+}
+
+// sanitizedFileNamePart keeps only the characters of s that are safe in a
+// file name.
+func sanitizedFileNamePart(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, s)
+}
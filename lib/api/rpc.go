@@ -0,0 +1,356 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/events"
+)
+
+// The control socket speaks JSON-RPC 2.0, one message per line. A
+// connection first authenticates with "auth" and the param "apiKey", the
+// same API key as for the REST API; anything else fails until then. The
+// other methods are the REST endpoints, named by the lower case HTTP method and the path
+// below /rest with dots for slashes: "get.system.status" is GET
+// /rest/system/status, "post.db.scan" is POST /rest/db/scan. The params are
+// an object with the optional members "path" (appended to the endpoint
+// path, e.g. a folder ID for "get.config.folders"), "query" (the query
+// parameters) and "body" (the request body). The result is the response of
+// the endpoint. In addition, "events.subscribe" with the optional param
+// "events" (a comma separated list of event types) makes the server send
+// the events as "event" notifications on the connection, until
+// "events.unsubscribe". There is no separate schema: the methods and their
+// results are those of the REST API.
+
+const (
+	rpcVersion = "2.0"
+
+	rpcErrParse          = -32700
+	rpcErrInvalidRequest = -32600
+	rpcErrMethodNotFound = -32601
+	rpcErrInvalidParams  = -32602
+	rpcErrEndpoint       = -32000 // the endpoint returned an error status
+	rpcErrUnauthorized   = -32001 // not authenticated with the API key yet
+
+	rpcMaxMessageSize  = 16 << 20
+	rpcSocketFileMode  = 0o600
+	rpcEventMethodName = "event"
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"` // absent for notifications
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type rpcNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type rpcParams struct {
+	Path  string                 `json:"path"`
+	Query map[string]interface{} `json:"query"` // strings, numbers, booleans or lists of them
+	Body  json.RawMessage        `json:"body"`
+}
+
+type rpcAuthParams struct {
+	APIKey string `json:"apiKey"`
+}
+
+type rpcSubscribeParams struct {
+	Events string `json:"events"`
+}
+
+// rpcSocketListener removes the control socket when closed.
+type rpcSocketListener struct {
+	net.Listener
+	path string
+}
+
+func (l *rpcSocketListener) Close() error {
+	err := l.Listener.Close()
+	os.Remove(l.path)
+	return err
+}
+
+// listenRPC opens the control socket, readable and writable by our user
+// only.
+func listenRPC(path string) (net.Listener, error) {
+	// The socket is created with the umask, so it's bound in a directory
+	// only we can enter and moved into place once it has its final mode;
+	// until then nobody else can connect to it.
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".rpc-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	tmpPath := filepath.Join(dir, "socket")
+	listener, err := net.Listen("unix", tmpPath)
+	if err != nil {
+		return nil, err
+	}
+	// It's no longer at the path it was bound to, see below, so the
+	// listener can't unlink it; rpcSocketListener does.
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	if err := os.Chmod(tmpPath, rpcSocketFileMode); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	os.Remove(path)
+	if err := os.Rename(tmpPath, path); err != nil {
+		listener.Close()
+		return nil, err
+	}
+	return &rpcSocketListener{Listener: listener, path: path}, nil
+}
+
+// serveRPC serves the control socket until the listener is closed, handing
+// requests to the REST handler.
+func (s *service) serveRPC(ctx context.Context, listener net.Listener, handler http.Handler) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	slog.InfoContext(ctx, "Control socket listening", slogutil.Address(listener.Addr()))
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.serveRPCConn(ctx, conn, handler)
+	}
+}
+
+type rpcConn struct {
+	conn          net.Conn
+	authenticated bool       // only touched by the connection's reader
+	mut           sync.Mutex // serializes writes and protects sub
+	sub           events.Subscription
+}
+
+func (c *rpcConn) send(msg interface{}) {
+	bs, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	_, _ = c.conn.Write(append(bs, '\n'))
+}
+
+func (c *rpcConn) unsubscribe() {
+	c.mut.Lock()
+	sub := c.sub
+	c.sub = nil
+	c.mut.Unlock()
+	if sub != nil {
+		sub.Unsubscribe()
+	}
+}
+
+func (s *service) serveRPCConn(ctx context.Context, conn net.Conn, handler http.Handler) {
+	c := &rpcConn{conn: conn}
+	defer c.unsubscribe()
+	defer conn.Close()
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(nil, rpcMaxMessageSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var req rpcRequest
+		if err := json.Unmarshal(line, &req); err != nil {
+			c.send(rpcResponse{JSONRPC: rpcVersion, ID: json.RawMessage("null"), Error: &rpcError{Code: rpcErrParse, Message: err.Error()}})
+			continue
+		}
+		resp := s.handleRPC(ctx, c, req, handler)
+		if len(req.ID) == 0 {
+			// A notification, which gets no response
+			continue
+		}
+		resp.JSONRPC = rpcVersion
+		resp.ID = req.ID
+		c.send(resp)
+	}
+}
+
+func (s *service) handleRPC(ctx context.Context, c *rpcConn, req rpcRequest, handler http.Handler) rpcResponse {
+	if req.JSONRPC != rpcVersion || req.Method == "" {
+		return rpcResponse{Error: &rpcError{Code: rpcErrInvalidRequest, Message: "invalid request"}}
+	}
+
+	if req.Method == "auth" {
+		var params rpcAuthParams
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				return rpcResponse{Error: &rpcError{Code: rpcErrInvalidParams, Message: err.Error()}}
+			}
+		}
+		c.authenticated = s.cfg.GUI().IsValidAPIKey(params.APIKey)
+		if !c.authenticated {
+			return rpcResponse{Error: &rpcError{Code: rpcErrUnauthorized, Message: "invalid API key"}}
+		}
+		return rpcResponse{Result: json.RawMessage("true")}
+	}
+	if !c.authenticated {
+		return rpcResponse{Error: &rpcError{Code: rpcErrUnauthorized, Message: "not authenticated"}}
+	}
+
+	switch req.Method {
+	case "events.subscribe":
+		var params rpcSubscribeParams
+		if len(req.Params) > 0 {
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				return rpcResponse{Error: &rpcError{Code: rpcErrInvalidParams, Message: err.Error()}}
+			}
+		}
+		c.unsubscribe()
+		sub := s.evLogger.Subscribe(s.getEventMask(params.Events))
+		c.mut.Lock()
+		c.sub = sub
+		c.mut.Unlock()
+		go func() {
+			for ev := range sub.C() {
+				c.send(rpcNotification{JSONRPC: rpcVersion, Method: rpcEventMethodName, Params: ev})
+			}
+		}()
+		return rpcResponse{Result: json.RawMessage("true")}
+
+	case "events.unsubscribe":
+		c.unsubscribe()
+		return rpcResponse{Result: json.RawMessage("true")}
+	}
+
+	verb, path, ok := rpcEndpoint(req.Method)
+	if !ok {
+		return rpcResponse{Error: &rpcError{Code: rpcErrMethodNotFound, Message: "method not found"}}
+	}
+	var params rpcParams
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return rpcResponse{Error: &rpcError{Code: rpcErrInvalidParams, Message: err.Error()}}
+		}
+	}
+	if params.Path != "" {
+		path += "/" + strings.Trim(params.Path, "/")
+	}
+
+	r, err := http.NewRequestWithContext(ctx, verb, "/", bytes.NewReader(params.Body))
+	if err != nil {
+		return rpcResponse{Error: &rpcError{Code: rpcErrInvalidParams, Message: err.Error()}}
+	}
+	r.URL.Path = path
+	query := r.URL.Query()
+	for key, val := range params.Query {
+		if vals, ok := val.([]interface{}); ok {
+			for _, v := range vals {
+				query.Add(key, fmt.Sprint(v))
+			}
+		} else {
+			query.Add(key, fmt.Sprint(val))
+		}
+	}
+	r.URL.RawQuery = query.Encode()
+	if len(params.Body) > 0 {
+		r.Header.Set("Content-Type", "application/json")
+	}
+
+	w := newRPCResponseWriter()
+	handler.ServeHTTP(w, r)
+	body := w.body.Bytes()
+
+	switch {
+	case w.status == http.StatusNotFound && string(bytes.TrimSpace(body)) == "404 page not found",
+		w.status == http.StatusMethodNotAllowed:
+		// No such route
+		return rpcResponse{Error: &rpcError{Code: rpcErrMethodNotFound, Message: "method not found"}}
+	case w.status >= http.StatusBadRequest:
+		return rpcResponse{Error: &rpcError{
+			Code:    rpcErrEndpoint,
+			Message: strings.TrimSpace(string(body)),
+			Data:    map[string]int{"status": w.status},
+		}}
+	case len(bytes.TrimSpace(body)) == 0:
+		return rpcResponse{Result: json.RawMessage("null")}
+	case json.Valid(body):
+		return rpcResponse{Result: json.RawMessage(body)}
+	default:
+		bs, _ := json.Marshal(string(body))
+		return rpcResponse{Result: bs}
+	}
+}
+
+// rpcEndpoint returns the HTTP method and path of the REST endpoint for the
+// RPC method.
+func rpcEndpoint(method string) (string, string, bool) {
+	verb, rest, ok := strings.Cut(method, ".")
+	if !ok || rest == "" {
+		return "", "", false
+	}
+	switch verb {
+	case "get", "post", "put", "patch", "delete":
+	default:
+		return "", "", false
+	}
+	return strings.ToUpper(verb), "/rest/" + strings.ReplaceAll(rest, ".", "/"), true
+}
+
+// rpcResponseWriter collects the response of a REST handler.
+type rpcResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func newRPCResponseWriter() *rpcResponseWriter {
+	return &rpcResponseWriter{header: make(http.Header), status: http.StatusOK}
+}
+
+func (w *rpcResponseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *rpcResponseWriter) WriteHeader(status int) {
+	w.status = status
+}
+
+func (w *rpcResponseWriter) Write(p []byte) (int, error) {
+	return w.body.Write(p)
+}
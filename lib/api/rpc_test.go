@@ -0,0 +1,116 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+func TestRPCEndpoint(t *testing.T) {
+	cases := []struct {
+		method, verb, path string
+		ok                 bool
+	}{
+		{"get.system.status", http.MethodGet, "/rest/system/status", true},
+		{"post.db.scan", http.MethodPost, "/rest/db/scan", true},
+		{"delete.cluster.pending.devices", http.MethodDelete, "/rest/cluster/pending/devices", true},
+		{"system.status", "", "", false},
+		{"get", "", "", false},
+	}
+	for _, tc := range cases {
+		verb, path, ok := rpcEndpoint(tc.method)
+		if verb != tc.verb || path != tc.path || ok != tc.ok {
+			t.Errorf("rpcEndpoint(%q) = %q, %q, %v", tc.method, verb, path, ok)
+		}
+	}
+}
+
+func TestHandleRPC(t *testing.T) {
+	mux := httprouter.New()
+	mux.Handle(http.MethodGet, "/rest/config/folders/:id", func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		sendJSON(w, map[string]string{"id": p.ByName("id"), "q": r.URL.Query().Get("q")})
+	})
+	mux.HandlerFunc(http.MethodPost, "/rest/db/scan", func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "no such folder", http.StatusNotFound)
+	})
+
+	cfg := newMockedConfig()
+	cfg.GUIReturns(config.GUIConfiguration{APIKey: "secret"})
+	s := &service{cfg: cfg}
+	c := &rpcConn{}
+	call := func(method, params string) rpcResponse {
+		req := rpcRequest{JSONRPC: rpcVersion, ID: json.RawMessage("1"), Method: method}
+		if params != "" {
+			req.Params = json.RawMessage(params)
+		}
+		return s.handleRPC(context.Background(), c, req, mux)
+	}
+
+	// Nothing goes before authenticating with the API key
+	if resp := call("get.config.folders", `{"path": "abcd"}`); resp.Error == nil || resp.Error.Code != rpcErrUnauthorized {
+		t.Fatalf("expected unauthorized, got %+v", resp)
+	}
+	if resp := call("auth", `{"apiKey": "wrong"}`); resp.Error == nil || resp.Error.Code != rpcErrUnauthorized {
+		t.Fatalf("expected invalid API key, got %+v", resp)
+	}
+	if resp := call("events.subscribe", ""); resp.Error == nil || resp.Error.Code != rpcErrUnauthorized {
+		t.Fatalf("expected unauthorized, got %+v", resp)
+	}
+	if resp := call("auth", `{"apiKey": "secret"}`); resp.Error != nil {
+		t.Fatalf("expected to authenticate, got %+v", resp.Error)
+	}
+
+	resp := call("get.config.folders", `{"path": "abcd", "query": {"q": "x"}}`)
+	if resp.Error != nil {
+		t.Fatal(resp.Error)
+	}
+	var res map[string]string
+	if err := json.Unmarshal(resp.Result, &res); err != nil {
+		t.Fatal(err)
+	}
+	if res["id"] != "abcd" || res["q"] != "x" {
+		t.Errorf("unexpected result %v", res)
+	}
+
+	if resp := call("post.db.scan", ""); resp.Error == nil || resp.Error.Code != rpcErrEndpoint || resp.Error.Message != "no such folder" {
+		t.Errorf("expected endpoint error, got %+v", resp.Error)
+	}
+	if resp := call("get.no.such.thing", ""); resp.Error == nil || resp.Error.Code != rpcErrMethodNotFound {
+		t.Errorf("expected method not found, got %+v", resp.Error)
+	}
+	if resp := call("nonsense", ""); resp.Error == nil || resp.Error.Code != rpcErrMethodNotFound {
+		t.Errorf("expected method not found, got %+v", resp.Error)
+	}
+}
+
+func TestListenRPCRemovesSocket(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("no UNIX sockets")
+	}
+	path := filepath.Join(t.TempDir(), "rpc.sock")
+	listener, err := listenRPC(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != rpcSocketFileMode {
+		t.Fatalf("expected the socket with mode %o: %v, %v", rpcSocketFileMode, info, err)
+	}
+	listener.Close()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected the socket to be removed on close, got %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package backup creates and restores backups of the configuration, the
+// device identity and the index database, so that a device can be
+// recovered after disk loss without rehashing and resyncing everything.
+package backup
+
+import (
+	"archive/tar"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/locations"
+)
+
+const (
+	configPrefix   = "config/"
+	databasePrefix = "database/"
+)
+
+// A Snapshotter writes a consistent copy of the database into a directory.
+type Snapshotter interface {
+	BackupInto(dir string) error
+}
+
+// layout says where the backed up files live.
+type layout struct {
+	files    map[string]string // member name -> path
+	required []string          // member names
+	database string
+}
+
+func defaultLayout() layout {
+	return layout{
+		files: map[string]string{
+			"config.xml":     locations.Get(locations.ConfigFile),
+			"cert.pem":       locations.Get(locations.CertFile),
+			"key.pem":        locations.Get(locations.KeyFile),
+			"https-cert.pem": locations.Get(locations.HTTPSCertFile),
+			"https-key.pem":  locations.Get(locations.HTTPSKeyFile),
+		},
+		required: []string{"config.xml", "cert.pem", "key.pem"},
+		database: locations.Get(locations.Database),
+	}
+}
+
+// Create writes a backup to w, encrypted if a passphrase is given.
+func Create(w io.Writer, db Snapshotter, passphrase string) error {
+	return create(w, db, passphrase, defaultLayout())
+}
+
+func create(w io.Writer, db Snapshotter, passphrase string, l layout) error {
+	snapDir, err := os.MkdirTemp(filepath.Dir(l.database), ".backup-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(snapDir)
+	if err := db.BackupInto(snapDir); err != nil {
+		return fmt.Errorf("database snapshot: %w", err)
+	}
+
+	cw, err := newChunkWriter(w, passphrase, defaultChunkSize)
+	if err != nil {
+		return err
+	}
+	tw := tar.NewWriter(cw)
+
+	for _, name := range slices.Sorted(maps.Keys(l.files)) {
+		err := addFile(tw, configPrefix+name, l.files[name])
+		if errors.Is(err, fs.ErrNotExist) && !slices.Contains(l.required, name) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	err = filepath.WalkDir(snapDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(snapDir, p)
+		if err != nil {
+			return err
+		}
+		return addFile(tw, databasePrefix+filepath.ToSlash(rel), p)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return cw.Close()
+}
+
+func addFile(tw *tar.Writer, name, src string) error {
+	fd, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	info, err := fd.Stat()
+	if err != nil {
+		return err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0o600,
+		Size:    info.Size(),
+		ModTime: info.ModTime(),
+	}); err != nil {
+		return err
+	}
+	_, err = io.Copy(tw, fd)
+	return err
+}
+
+// Restore restores the backup in the given file over the current
+// configuration, identity and database, which are kept alongside with a
+// ".pre-restore-<time>" suffix. The backup is verified in full before
+// anything is replaced. This must be done before the configuration and
+// database are loaded.
+func Restore(file, passphrase string) error {
+	return restore(file, passphrase, defaultLayout())
+}
+
+func restore(file, passphrase string, l layout) error {
+	fd, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	cr, err := newChunkReader(fd, passphrase)
+	if err != nil {
+		return err
+	}
+
+	// Extract into temporary directories next to where things go, so that
+	// they can be renamed into place.
+	configDir, err := os.MkdirTemp(filepath.Dir(l.files["config.xml"]), ".restore-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(configDir)
+	dbDir, err := os.MkdirTemp(filepath.Dir(l.database), ".restore-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dbDir)
+
+	extracted := make(map[string]string) // config member name -> extracted path
+	hasDatabase := false
+	tr := tar.NewReader(cr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return err
+		}
+		name := path.Clean(hdr.Name)
+		var dst string
+		switch {
+		case strings.HasPrefix(name, configPrefix):
+			member := strings.TrimPrefix(name, configPrefix)
+			if _, ok := l.files[member]; !ok {
+				return fmt.Errorf("%w: unexpected member %q", ErrCorrupt, hdr.Name)
+			}
+			dst = filepath.Join(configDir, member)
+			extracted[member] = dst
+		case strings.HasPrefix(name, databasePrefix) && filepath.IsLocal(strings.TrimPrefix(name, databasePrefix)):
+			dst = filepath.Join(dbDir, filepath.FromSlash(strings.TrimPrefix(name, databasePrefix)))
+			hasDatabase = true
+		default:
+			return fmt.Errorf("%w: unexpected member %q", ErrCorrupt, hdr.Name)
+		}
+		if err := extractFile(tr, dst); err != nil {
+			return err
+		}
+	}
+
+	// Check that everything needed is there and usable.
+	for _, name := range l.required {
+		if _, ok := extracted[name]; !ok {
+			return fmt.Errorf("%w: %s is missing", ErrCorrupt, name)
+		}
+	}
+	if !hasDatabase {
+		return fmt.Errorf("%w: the database is missing", ErrCorrupt)
+	}
+	if _, err := tls.LoadX509KeyPair(extracted["cert.pem"], extracted["key.pem"]); err != nil {
+		return fmt.Errorf("%w: device certificate: %w", ErrCorrupt, err)
+	}
+
+	// Put the restored files in place, keeping what was there.
+	suffix := ".pre-restore-" + time.Now().Format("20060102-150405")
+	for _, name := range slices.Sorted(maps.Keys(extracted)) {
+		if err := replace(extracted[name], l.files[name], suffix); err != nil {
+			return err
+		}
+	}
+	if err := replace(dbDir, l.database, suffix); err != nil {
+		return err
+	}
+	slog.Info("Restored configuration and database from backup", slogutil.FilePath(file))
+	return nil
+}
+
+func extractFile(r io.Reader, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0o700); err != nil {
+		return err
+	}
+	fd, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(fd, r); err != nil {
+		fd.Close()
+		return err
+	}
+	return fd.Close()
+}
+
+// replace moves src to dst, moving an existing dst aside first.
+func replace(src, dst, suffix string) error {
+	if _, err := os.Lstat(dst); err == nil {
+		if err := os.Rename(dst, dst+suffix); err != nil {
+			return err
+		}
+	}
+	return os.Rename(src, dst)
+}
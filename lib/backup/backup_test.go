@@ -0,0 +1,214 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package backup
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/tlsutil"
+)
+
+func TestChunksRoundTrip(t *testing.T) {
+	data := make([]byte, 10000)
+	rand.Read(data)
+
+	for _, passphrase := range []string{"", "secret"} {
+		var buf bytes.Buffer
+		cw, err := newChunkWriter(&buf, passphrase, 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Odd sized writes that don't line up with chunks
+		for rest := data; len(rest) > 0; {
+			n := min(len(rest), 777)
+			if _, err := cw.Write(rest[:n]); err != nil {
+				t.Fatal(err)
+			}
+			rest = rest[n:]
+		}
+		if err := cw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		backup := buf.Bytes()
+
+		cr, err := newChunkReader(bytes.NewReader(backup), passphrase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		read, err := io.ReadAll(cr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(read, data) {
+			t.Errorf("passphrase %q: data differs after round trip", passphrase)
+		}
+		if passphrase != "" && bytes.Contains(backup, data[:100]) {
+			t.Error("encrypted backup contains plain text")
+		}
+
+		// Truncating the backup at a chunk boundary must be detected.
+		cr, err = newChunkReader(bytes.NewReader(backup[:headerSize+3*(5+1000+32)]), passphrase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadAll(cr); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("passphrase %q: truncated backup gave %v, expected ErrCorrupt", passphrase, err)
+		}
+
+		// So must a flipped bit.
+		corrupt := bytes.Clone(backup)
+		corrupt[len(corrupt)/2] ^= 1
+		cr, err = newChunkReader(bytes.NewReader(corrupt), passphrase)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.ReadAll(cr); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("passphrase %q: corrupted backup gave %v, expected ErrCorrupt", passphrase, err)
+		}
+	}
+}
+
+func TestChunksPassphrase(t *testing.T) {
+	var buf bytes.Buffer
+	cw, err := newChunkWriter(&buf, "secret", 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cw.Write([]byte("hello"))
+	if err := cw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := newChunkReader(bytes.NewReader(buf.Bytes()), ""); !errors.Is(err, ErrNoPassphrase) {
+		t.Errorf("got %v, expected ErrNoPassphrase", err)
+	}
+	cr, err := newChunkReader(bytes.NewReader(buf.Bytes()), "wrong")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(cr); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("got %v, expected ErrWrongPassphrase", err)
+	}
+}
+
+type fakeDB map[string]string
+
+func (f fakeDB) BackupInto(dir string) error {
+	for name, data := range f {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func testLayout(t *testing.T) layout {
+	t.Helper()
+	dir := t.TempDir()
+	l := layout{
+		files: map[string]string{
+			"config.xml":     filepath.Join(dir, "config.xml"),
+			"cert.pem":       filepath.Join(dir, "cert.pem"),
+			"key.pem":        filepath.Join(dir, "key.pem"),
+			"https-cert.pem": filepath.Join(dir, "https-cert.pem"),
+		},
+		required: []string{"config.xml", "cert.pem", "key.pem"},
+		database: filepath.Join(dir, "index-v2"),
+	}
+	if _, err := tlsutil.NewCertificate(l.files["cert.pem"], l.files["key.pem"], "syncthing", 1, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(l.files["config.xml"], []byte("<configuration/>"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(l.database, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestCreateRestore(t *testing.T) {
+	l := testLayout(t)
+	db := fakeDB{"main.db": "main", "folders/abcd.db": "folder"}
+
+	var buf bytes.Buffer
+	if err := create(&buf, db, "secret", l); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "test"+fileExtension)
+	if err := os.WriteFile(file, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	origCert, err := os.ReadFile(l.files["cert.pem"])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Change things after the backup; the restore should bring back the
+	// state at the time of the backup.
+	os.WriteFile(l.files["config.xml"], []byte("<changed/>"), 0o600)
+
+	// A failed restore leaves everything alone.
+	if err := restore(file, "wrong", l); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("got %v, expected ErrWrongPassphrase", err)
+	}
+	if bs, _ := os.ReadFile(l.files["config.xml"]); string(bs) != "<changed/>" {
+		t.Errorf("config changed by failed restore: %q", bs)
+	}
+
+	if err := restore(file, "secret", l); err != nil {
+		t.Fatal(err)
+	}
+	if bs, _ := os.ReadFile(l.files["config.xml"]); string(bs) != "<configuration/>" {
+		t.Errorf("config not restored: %q", bs)
+	}
+	if bs, _ := os.ReadFile(l.files["cert.pem"]); !bytes.Equal(bs, origCert) {
+		t.Error("certificate not restored")
+	}
+	if _, err := os.Stat(l.files["https-cert.pem"]); !errors.Is(err, os.ErrNotExist) {
+		t.Error("optional file not in the backup should not exist")
+	}
+	for name, data := range db {
+		bs, err := os.ReadFile(filepath.Join(l.database, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(bs) != data {
+			t.Errorf("%s: got %q, expected %q", name, bs, data)
+		}
+	}
+}
+
+func TestRestoreRequiresIdentity(t *testing.T) {
+	l := testLayout(t)
+	os.Remove(l.files["key.pem"])
+
+	var buf bytes.Buffer
+	l.required = []string{"config.xml"}
+	if err := create(&buf, fakeDB{"main.db": "main"}, "", l); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "test"+fileExtension)
+	if err := os.WriteFile(file, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	l.required = []string{"config.xml", "cert.pem", "key.pem"}
+	if err := restore(file, "", l); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("got %v, expected ErrCorrupt", err)
+	}
+}
@@ -0,0 +1,296 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package backup
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+// A backup file is a header followed by a sequence of chunks, each
+// individually checksummed or, with a passphrase, encrypted and
+// authenticated. The chunk index and a flag marking the last chunk are
+// covered by the checksum or authentication, so that reordered, dropped
+// and truncated chunks are detected.
+//
+//	header: magic[8] version[1] flags[1] chunkSize[4] salt[16]
+//	chunk:  last[1] length[4] payload[length]
+//
+// Without encryption the payload is the data followed by its SHA-256 sum.
+
+const (
+	magic            = "STBACKUP"
+	formatVersion    = 1
+	flagEncrypted    = 1
+	saltSize         = 16
+	headerSize       = len(magic) + 1 + 1 + 4 + saltSize
+	defaultChunkSize = 1 << 20
+	maxChunkSize     = 64 << 20
+)
+
+var (
+	ErrCorrupt         = errors.New("backup is corrupt or truncated")
+	ErrWrongPassphrase = errors.New("wrong passphrase, or backup is corrupt")
+	ErrNoPassphrase    = errors.New("backup is encrypted and requires a passphrase")
+)
+
+type header struct {
+	flags     byte
+	chunkSize uint32
+	salt      [saltSize]byte
+}
+
+func (h header) marshal() []byte {
+	bs := make([]byte, 0, headerSize)
+	bs = append(bs, magic...)
+	bs = append(bs, formatVersion, h.flags)
+	bs = binary.BigEndian.AppendUint32(bs, h.chunkSize)
+	return append(bs, h.salt[:]...)
+}
+
+func readHeader(r io.Reader) (header, []byte, error) {
+	bs := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, bs); err != nil {
+		return header{}, nil, fmt.Errorf("%w: %w", ErrCorrupt, err)
+	}
+	if string(bs[:len(magic)]) != magic {
+		return header{}, nil, errors.New("not a backup file")
+	}
+	if v := bs[len(magic)]; v != formatVersion {
+		return header{}, nil, fmt.Errorf("unsupported backup format version %d", v)
+	}
+	h := header{
+		flags:     bs[len(magic)+1],
+		chunkSize: binary.BigEndian.Uint32(bs[len(magic)+2:]),
+	}
+	copy(h.salt[:], bs[len(magic)+6:])
+	if h.chunkSize == 0 || h.chunkSize > maxChunkSize {
+		return header{}, nil, fmt.Errorf("%w: bad chunk size %d", ErrCorrupt, h.chunkSize)
+	}
+	return h, bs, nil
+}
+
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, 32768, 8, 1, chacha20poly1305.KeySize)
+}
+
+// chunkAD returns the data authenticated along with each chunk.
+func chunkAD(hdr []byte, index uint64, last bool) []byte {
+	ad := binary.BigEndian.AppendUint64(bytes.Clone(hdr), index)
+	if last {
+		return append(ad, 1)
+	}
+	return append(ad, 0)
+}
+
+func chunkNonce(index uint64) []byte {
+	// The key is unique to each backup, by the random salt, so the chunk
+	// index is a fine nonce.
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], index)
+	return nonce
+}
+
+// chunkWriter splits what's written to it into chunks. Close must be
+// called to write the last chunk.
+type chunkWriter struct {
+	w     io.Writer
+	hdr   []byte
+	aead  cipher.AEAD // nil without encryption
+	buf   []byte
+	size  int
+	index uint64
+	err   error
+}
+
+func newChunkWriter(w io.Writer, passphrase string, chunkSize int) (*chunkWriter, error) {
+	h := header{chunkSize: uint32(chunkSize)}
+	var aead cipher.AEAD
+	if passphrase != "" {
+		h.flags |= flagEncrypted
+		if _, err := rand.Read(h.salt[:]); err != nil {
+			return nil, err
+		}
+		key, err := deriveKey(passphrase, h.salt[:])
+		if err != nil {
+			return nil, err
+		}
+		if aead, err = chacha20poly1305.NewX(key); err != nil {
+			return nil, err
+		}
+	}
+	hdr := h.marshal()
+	if _, err := w.Write(hdr); err != nil {
+		return nil, err
+	}
+	return &chunkWriter{
+		w:    w,
+		hdr:  hdr,
+		aead: aead,
+		buf:  make([]byte, 0, chunkSize),
+		size: chunkSize,
+	}, nil
+}
+
+func (c *chunkWriter) Write(bs []byte) (int, error) {
+	written := 0
+	for len(bs) > 0 {
+		if c.err != nil {
+			return written, c.err
+		}
+		n := min(len(bs), c.size-len(c.buf))
+		c.buf = append(c.buf, bs[:n]...)
+		bs = bs[n:]
+		written += n
+		if len(c.buf) == c.size {
+			c.err = c.flush(false)
+		}
+	}
+	return written, c.err
+}
+
+func (c *chunkWriter) Close() error {
+	if c.err != nil {
+		return c.err
+	}
+	c.err = c.flush(true)
+	if c.err == nil {
+		c.err = errors.New("backup writer closed")
+		return nil
+	}
+	return c.err
+}
+
+func (c *chunkWriter) flush(last bool) error {
+	ad := chunkAD(c.hdr, c.index, last)
+	var payload []byte
+	if c.aead != nil {
+		payload = c.aead.Seal(nil, chunkNonce(c.index), c.buf, ad)
+	} else {
+		sum := sha256.Sum256(append(ad, c.buf...))
+		payload = append(c.buf, sum[:]...)
+	}
+
+	var chunkHdr [5]byte
+	if last {
+		chunkHdr[0] = 1
+	}
+	binary.BigEndian.PutUint32(chunkHdr[1:], uint32(len(payload)))
+	if _, err := c.w.Write(chunkHdr[:]); err != nil {
+		return err
+	}
+	if _, err := c.w.Write(payload); err != nil {
+		return err
+	}
+	c.index++
+	c.buf = c.buf[:0]
+	return nil
+}
+
+// chunkReader verifies and, when encrypted, decrypts the chunks written by
+// a chunkWriter. It returns an error wrapping ErrCorrupt when the data
+// fails verification or ends before the last chunk.
+type chunkReader struct {
+	r     io.Reader
+	hdr   []byte
+	size  int
+	aead  cipher.AEAD
+	buf   []byte
+	index uint64
+	last  bool
+}
+
+func newChunkReader(r io.Reader, passphrase string) (*chunkReader, error) {
+	h, hdr, err := readHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	c := &chunkReader{r: r, hdr: hdr, size: int(h.chunkSize)}
+	if h.flags&flagEncrypted != 0 {
+		if passphrase == "" {
+			return nil, ErrNoPassphrase
+		}
+		key, err := deriveKey(passphrase, h.salt[:])
+		if err != nil {
+			return nil, err
+		}
+		if c.aead, err = chacha20poly1305.NewX(key); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *chunkReader) Read(bs []byte) (int, error) {
+	for len(c.buf) == 0 {
+		if c.last {
+			// Anything after the last chunk means it's not our data.
+			var extra [1]byte
+			if n, _ := c.r.Read(extra[:]); n > 0 {
+				return 0, fmt.Errorf("%w: data after the last chunk", ErrCorrupt)
+			}
+			return 0, io.EOF
+		}
+		if err := c.next(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(bs, c.buf)
+	c.buf = c.buf[n:]
+	return n, nil
+}
+
+func (c *chunkReader) next() error {
+	var chunkHdr [5]byte
+	if _, err := io.ReadFull(c.r, chunkHdr[:]); err != nil {
+		return fmt.Errorf("%w: %w", ErrCorrupt, err)
+	}
+	last := chunkHdr[0] == 1
+	length := int(binary.BigEndian.Uint32(chunkHdr[1:]))
+	overhead := sha256.Size
+	if c.aead != nil {
+		overhead = c.aead.Overhead()
+	}
+	if length < overhead || length > c.size+overhead {
+		return fmt.Errorf("%w: bad chunk length %d", ErrCorrupt, length)
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return fmt.Errorf("%w: %w", ErrCorrupt, err)
+	}
+
+	ad := chunkAD(c.hdr, c.index, last)
+	if c.aead != nil {
+		data, err := c.aead.Open(payload[:0], chunkNonce(c.index), payload, ad)
+		if err != nil {
+			if c.index == 0 {
+				return ErrWrongPassphrase
+			}
+			return fmt.Errorf("%w: chunk %d fails authentication", ErrCorrupt, c.index)
+		}
+		c.buf = data
+	} else {
+		data := payload[:length-sha256.Size]
+		sum := sha256.Sum256(append(ad, data...))
+		if !bytes.Equal(sum[:], payload[length-sha256.Size:]) {
+			return fmt.Errorf("%w: chunk %d has a bad checksum", ErrCorrupt, c.index)
+		}
+		c.buf = data
+	}
+	c.index++
+	c.last = last
+	return nil
+}
@@ -0,0 +1,137 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package backup
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const fileExtension = ".stbackup"
+
+var ErrNoSuchBackup = errors.New("no such backup")
+
+// Info describes a backup file kept by the Service.
+type Info struct {
+	Name    string    `json:"name"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// The Service keeps backup files in a directory, from where they can be
+// downloaded, resuming interrupted downloads as needed.
+type Service struct {
+	db  Snapshotter
+	dir string
+	mut sync.Mutex // one backup at a time
+}
+
+func NewService(db Snapshotter, dir string) *Service {
+	return &Service{db: db, dir: dir}
+}
+
+// Create writes a new backup, encrypted if a passphrase is given.
+func (s *Service) Create(passphrase string) (Info, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return Info{}, err
+	}
+	name := "syncthing-" + time.Now().Format("20060102-150405") + fileExtension
+	path := filepath.Join(s.dir, name)
+	tmp := path + ".tmp"
+	fd, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return Info{}, err
+	}
+	err = Create(fd, s.db, passphrase)
+	if cerr := fd.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return Info{}, err
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return Info{}, err
+	}
+	return Info{Name: name, Size: fi.Size(), ModTime: fi.ModTime()}, nil
+}
+
+// List returns the backups, oldest first.
+func (s *Service) List() ([]Info, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var res []Info
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Name(), fileExtension) {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		res = append(res, Info{Name: e.Name(), Size: fi.Size(), ModTime: fi.ModTime()})
+	}
+	slices.SortFunc(res, func(a, b Info) int { return a.ModTime.Compare(b.ModTime) })
+	return res, nil
+}
+
+// Open opens the named backup for reading. The caller must close it.
+func (s *Service) Open(name string) (*os.File, Info, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, Info{}, err
+	}
+	fd, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, Info{}, ErrNoSuchBackup
+	} else if err != nil {
+		return nil, Info{}, err
+	}
+	fi, err := fd.Stat()
+	if err != nil {
+		fd.Close()
+		return nil, Info{}, err
+	}
+	return fd, Info{Name: name, Size: fi.Size(), ModTime: fi.ModTime()}, nil
+}
+
+// Remove removes the named backup.
+func (s *Service) Remove(name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); errors.Is(err, os.ErrNotExist) {
+		return ErrNoSuchBackup
+	} else if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *Service) path(name string) (string, error) {
+	if name != filepath.Base(name) || !strings.HasSuffix(name, fileExtension) {
+		return "", ErrNoSuchBackup
+	}
+	return filepath.Join(s.dir, name), nil
+}
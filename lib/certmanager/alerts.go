@@ -65,6 +65,10 @@ const (
 
 	// AlertTypeInvalid indicates certificate files are invalid
 	AlertTypeInvalid
+
+	// AlertTypePeerRejected indicates a connection was rejected because
+	// the peer's certificate is expired or not yet valid
+	AlertTypePeerRejected
 )
 
 const (
@@ -97,6 +101,9 @@ func (as *AlertService) Serve(ctx context.Context) error {
 	ticker := time.NewTicker(as.checkInterval)
 	defer ticker.Stop()
 
+	sub := as.evLogger.Subscribe(events.DeviceCertificateRejected)
+	defer sub.Unsubscribe()
+
 	// Check immediately on startup
 	as.checkCertificates()
 
@@ -104,6 +111,10 @@ func (as *AlertService) Serve(ctx context.Context) error {
 		select {
 		case <-ticker.C:
 			as.checkCertificates()
+		case ev, ok := <-sub.C():
+			if ok {
+				as.peerCertificateRejected(ev)
+			}
 		case <-ctx.Done():
 			slog.Info("Stopping certificate expiration alert service")
 			return nil
@@ -258,7 +269,7 @@ func (as *AlertService) createAlert(certFile string, deviceID protocol.DeviceID,
 		case AlertTypeExpiringSoon:
 			// Notify every 24 hours for regular alerts
 			shouldNotify = timeSinceLastNotify >= 24*time.Hour
-		case AlertTypeExpired:
+		case AlertTypeExpired, AlertTypePeerRejected:
 			// Notify every 12 hours for expired certificates
 			shouldNotify = timeSinceLastNotify >= 12*time.Hour
 		case AlertTypeMissing, AlertTypeInvalid:
@@ -287,6 +298,41 @@ func (as *AlertService) createAlert(certFile string, deviceID protocol.DeviceID,
 	}
 }
 
+// peerCertificateRejected creates or updates an alert for a peer whose
+// certificate was rejected under strict certificate expiry.
+func (as *AlertService) peerCertificateRejected(ev events.Event) {
+	data, ok := ev.Data.(map[string]string)
+	if !ok {
+		return
+	}
+	deviceID, err := protocol.DeviceIDFromString(data["device"])
+	if err != nil {
+		return
+	}
+	notAfter, _ := time.Parse(time.RFC3339, data["notAfter"])
+
+	alertKey := "device:" + deviceID.String()
+	if alert, exists := as.alerts[alertKey]; exists {
+		alert.NotAfter = notAfter
+		if time.Since(alert.LastNotified) >= 12*time.Hour {
+			as.sendAlertNotification(alert)
+			alert.LastNotified = time.Now()
+		}
+		return
+	}
+
+	alert := &CertificateAlert{
+		DeviceID:     deviceID,
+		Subject:      data["subject"],
+		NotAfter:     notAfter,
+		AlertType:    AlertTypePeerRejected,
+		CreatedAt:    time.Now(),
+		LastNotified: time.Now(),
+	}
+	as.alerts[alertKey] = alert
+	as.sendAlertNotification(alert)
+}
+
 // removeAlert removes an alert for a certificate that is no longer expiring
 func (as *AlertService) removeAlert(certFile string) {
 	if _, exists := as.alerts[certFile]; exists {
@@ -299,7 +345,7 @@ func (as *AlertService) removeAlert(certFile string) {
 func (as *AlertService) processAlerts() {
 	now := time.Now()
 
-	for _, alert := range as.alerts {
+	for key, alert := range as.alerts {
 		// Re-check if alert is still valid
 		timeSinceCreated := now.Sub(alert.CreatedAt)
 
@@ -308,7 +354,7 @@ func (as *AlertService) processAlerts() {
 			slog.Debug("Removing old certificate alert",
 				"file", alert.CertificateFile,
 				"age", timeSinceCreated.String())
-			delete(as.alerts, alert.CertificateFile)
+			delete(as.alerts, key)
 			continue
 		}
 
@@ -323,7 +369,7 @@ func (as *AlertService) processAlerts() {
 		case AlertTypeExpiringSoon:
 			// Notify every 24 hours for regular alerts
 			shouldNotify = timeSinceLastNotify >= 24*time.Hour
-		case AlertTypeExpired:
+		case AlertTypeExpired, AlertTypePeerRejected:
 			// Notify every 12 hours for expired certificates
 			shouldNotify = timeSinceLastNotify >= 12*time.Hour
 		case AlertTypeMissing, AlertTypeInvalid:
@@ -481,6 +527,11 @@ func (as *AlertService) sendAlertNotification(alert *CertificateAlert) {
 	case AlertTypeInvalid:
 		slog.Error("Certificate files are invalid",
 			"file", alert.CertificateFile)
+	case AlertTypePeerRejected:
+		slog.Error("Rejecting device with expired or not yet valid certificate",
+			"device", alert.DeviceID.String(),
+			"subject", alert.Subject,
+			"expires", alert.NotAfter.Format(time.RFC3339))
 	}
 
 	// Send event using Failure event type instead of undefined CertificateError
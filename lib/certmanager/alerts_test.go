@@ -10,6 +10,10 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
 )
 
 func TestResolveCertificateFiles(t *testing.T) {
@@ -88,3 +92,27 @@ func TestResolveCertificateFiles(t *testing.T) {
 		t.Errorf("Expected key file %s, got %s", altKeyFile, resolvedKey)
 	}
 }
+
+func TestPeerCertificateRejectedAlert(t *testing.T) {
+	as := NewAlertService(events.NoopLogger)
+	deviceID := protocol.DeviceID{1, 2, 3}
+	notAfter := time.Now().Add(-time.Hour).Truncate(time.Second)
+	ev := events.Event{Type: events.DeviceCertificateRejected, Data: map[string]string{
+		"device":   deviceID.String(),
+		"subject":  "CN=syncthing",
+		"notAfter": notAfter.Format(time.RFC3339),
+	}}
+
+	// Repeated rejections of the same device update a single alert.
+	as.peerCertificateRejected(ev)
+	as.peerCertificateRejected(ev)
+
+	if len(as.alerts) != 1 {
+		t.Fatalf("expected one alert, got %d", len(as.alerts))
+	}
+	for _, alert := range as.alerts {
+		if alert.AlertType != AlertTypePeerRejected || alert.DeviceID != deviceID || !alert.NotAfter.Equal(notAfter) {
+			t.Errorf("unexpected alert %+v", alert)
+		}
+	}
+}
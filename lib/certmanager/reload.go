@@ -0,0 +1,107 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package certmanager
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+)
+
+// How often the certificate files are checked for changes
+const reloadCheckInterval = time.Minute
+
+// A Reloader watches the certificate and key files and hands a changed
+// certificate to onReload, so that it can be taken into use at runtime.
+// Replacing the files is then all it takes to rotate the certificate.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	current  tls.Certificate
+	modTime  time.Time
+	onReload func(tls.Certificate) error
+}
+
+// NewReloader returns a reloader for the files, currently holding the
+// given certificate. Only changes to the files from now on are picked up.
+func NewReloader(certFile, keyFile string, current tls.Certificate, onReload func(tls.Certificate) error) *Reloader {
+	r := &Reloader{
+		certFile: certFile,
+		keyFile:  keyFile,
+		current:  current,
+		onReload: onReload,
+	}
+	r.modTime, _ = r.filesModTime()
+	return r
+}
+
+// Serve implements suture.Service
+func (r *Reloader) Serve(ctx context.Context) error {
+	ticker := time.NewTicker(reloadCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.check()
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (r *Reloader) String() string {
+	return "certmanager.Reloader@" + r.certFile
+}
+
+// check reloads the certificate if the files changed. A pair that doesn't
+// load, e.g. when only one of the files was replaced so far, is retried on
+// the next check.
+func (r *Reloader) check() {
+	modTime, err := r.filesModTime()
+	if err != nil || modTime.Equal(r.modTime) {
+		return
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		slog.Warn("Failed to load changed certificate", slog.String("certFile", r.certFile), slogutil.Error(err))
+		return
+	}
+	if slices.EqualFunc(cert.Certificate, r.current.Certificate, bytes.Equal) {
+		r.modTime = modTime
+		return
+	}
+
+	if err := r.onReload(cert); err != nil {
+		slog.Warn("Failed to use changed certificate", slog.String("certFile", r.certFile), slogutil.Error(err))
+		return
+	}
+	slog.Info("Reloaded certificate", slog.String("certFile", r.certFile))
+	r.current = cert
+	r.modTime = modTime
+}
+
+// filesModTime returns the latest modification time of the files
+func (r *Reloader) filesModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
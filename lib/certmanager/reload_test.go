@@ -0,0 +1,69 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package certmanager
+
+import (
+	"bytes"
+	"crypto/tls"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/certutil"
+)
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	cert, err := certutil.NewCertificate(certFile, keyFile, "syncthing", 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var reloaded []tls.Certificate
+	var reloadErr error
+	r := NewReloader(certFile, keyFile, cert, func(cert tls.Certificate) error {
+		reloaded = append(reloaded, cert)
+		return reloadErr
+	})
+
+	r.check()
+	if len(reloaded) != 0 {
+		t.Fatal("unchanged files should not be reloaded")
+	}
+
+	newCert, err := certutil.NewCertificate(certFile, keyFile, "syncthing", 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Minute)
+	for _, file := range []string{certFile, keyFile} {
+		if err := os.Chtimes(file, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A failure to use the certificate is retried on the next check.
+	reloadErr = errors.New("boom")
+	r.check()
+	reloadErr = nil
+	r.check()
+	if len(reloaded) != 2 {
+		t.Fatalf("expected two reload attempts, got %d", len(reloaded))
+	}
+	if !bytes.Equal(reloaded[1].Certificate[0], newCert.Certificate[0]) {
+		t.Error("reloaded certificate should be the new one")
+	}
+
+	r.check()
+	if len(reloaded) != 2 {
+		t.Error("certificate should not be reloaded again")
+	}
+}
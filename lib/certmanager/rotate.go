@@ -0,0 +1,123 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package certmanager
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/certutil"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// A Rotator replaces the certificate with a newly generated one some time
+// before it expires. The new pair is written next to the current one and
+// renamed into place, keeping the previous files with an ".old" suffix.
+// Taking the new certificate into use is left to a Reloader watching the
+// same files.
+type Rotator struct {
+	certFile     string
+	keyFile      string
+	commonName   string
+	lifetimeDays int
+	before       time.Duration
+	current      tls.Certificate
+}
+
+// NewRotator returns a rotator for the files, currently holding the given
+// certificate, generating new ones valid for lifetimeDays the given time
+// before the current one expires.
+func NewRotator(certFile, keyFile, commonName string, lifetimeDays int, current tls.Certificate, before time.Duration) *Rotator {
+	return &Rotator{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		commonName:   commonName,
+		lifetimeDays: lifetimeDays,
+		before:       before,
+		current:      current,
+	}
+}
+
+// Serve implements suture.Service
+func (r *Rotator) Serve(ctx context.Context) error {
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	r.check(time.Now())
+	for {
+		select {
+		case <-ticker.C:
+			r.check(time.Now())
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (r *Rotator) String() string {
+	return "certmanager.Rotator@" + r.certFile
+}
+
+// check rotates the certificate if it expires within the rotation period.
+// A failed rotation is retried on the next check.
+func (r *Rotator) check(now time.Time) {
+	if len(r.current.Certificate) == 0 {
+		return
+	}
+	leaf, err := certutil.ParseCertificate(r.current.Certificate[0])
+	if err != nil {
+		slog.Warn("Failed to parse certificate for rotation", slog.String("certFile", r.certFile), slogutil.Error(err))
+		return
+	}
+	if leaf.NotAfter.Sub(now) > r.before {
+		return
+	}
+
+	slog.Info("Rotating certificate before it expires", slog.String("certFile", r.certFile), slog.Time("notAfter", leaf.NotAfter))
+	cert, err := r.rotate()
+	if err != nil {
+		slog.Error("Failed to rotate certificate", slog.String("certFile", r.certFile), slogutil.Error(err))
+		return
+	}
+	slog.Info("Rotated certificate", slog.String("certFile", r.certFile), protocol.NewDeviceID(cert.Certificate[0]).LogAttr())
+	r.current = cert
+}
+
+// rotate generates the new pair and moves it into place. The key goes
+// first; until the certificate follows, the files don't make a valid pair
+// and a Reloader keeps the current one.
+func (r *Rotator) rotate() (tls.Certificate, error) {
+	cert, err := certutil.NewCertificate(r.certFile+".new", r.keyFile+".new", r.commonName, r.lifetimeDays, false)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	for _, file := range []string{r.keyFile, r.certFile} {
+		if err := keepOld(file); err != nil {
+			return tls.Certificate{}, errors.Join(err, os.Remove(r.certFile+".new"), os.Remove(r.keyFile+".new"))
+		}
+	}
+	for _, file := range []string{r.keyFile, r.certFile} {
+		if err := os.Rename(file+".new", file); err != nil {
+			return tls.Certificate{}, fmt.Errorf("%w (previous pair kept with .old suffix)", err)
+		}
+	}
+	return cert, nil
+}
+
+// keepOld copies the file to one with an ".old" suffix
+func keepOld(file string) error {
+	bs, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file+".old", bs, 0o600)
+}
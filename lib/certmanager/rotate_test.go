@@ -0,0 +1,63 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package certmanager
+
+import (
+	"bytes"
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/certutil"
+)
+
+func TestRotator(t *testing.T) {
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	cert, err := certutil.NewCertificate(certFile, keyFile, "syncthing", 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRotator(certFile, keyFile, "syncthing", 10, cert, 12*time.Hour)
+	r.check(time.Now())
+	if !bytes.Equal(r.current.Certificate[0], cert.Certificate[0]) {
+		t.Fatal("certificate rotated too early")
+	}
+
+	later := time.Now().Add(48 * time.Hour)
+	r.check(later)
+	if bytes.Equal(r.current.Certificate[0], cert.Certificate[0]) {
+		t.Fatal("certificate not rotated")
+	}
+	loaded, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(loaded.Certificate[0], r.current.Certificate[0]) {
+		t.Error("files should hold the rotated certificate")
+	}
+	old, err := tls.LoadX509KeyPair(certFile+".old", keyFile+".old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(old.Certificate[0], cert.Certificate[0]) {
+		t.Error("previous certificate should be kept")
+	}
+	if _, err := os.Stat(certFile + ".new"); !os.IsNotExist(err) {
+		t.Error("new certificate file left behind")
+	}
+
+	rotated := r.current
+	r.check(later)
+	if !bytes.Equal(r.current.Certificate[0], rotated.Certificate[0]) {
+		t.Error("rotated certificate should not be rotated again")
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+// Bandwidth classes of connections. A connection is metered when its local
+// address is in one of the metered networks, otherwise relayed, LAN or WAN.
+const (
+	BandwidthClassLAN     = "lan"
+	BandwidthClassWAN     = "wan"
+	BandwidthClassRelay   = "relay"
+	BandwidthClassMetered = "metered"
+)
+
+// BandwidthClasses lists the bandwidth classes in order of precedence.
+var BandwidthClasses = []string{BandwidthClassMetered, BandwidthClassRelay, BandwidthClassLAN, BandwidthClassWAN}
+
+// A BandwidthClassConfiguration limits the rate of all connections of a
+// class together, in addition to the overall and device limits.
+type BandwidthClassConfiguration struct {
+	Class       string `json:"class" xml:"class,attr"`
+	MaxSendKbps int    `json:"maxSendKbps" xml:"maxSendKbps"`
+	MaxRecvKbps int    `json:"maxRecvKbps" xml:"maxRecvKbps"`
+	// How much may pass at once after a quiet period, zero meaning the
+	// default burst size
+	BurstKiB int `json:"burstKiB" xml:"burstKiB"`
+}
+
+// BandwidthClass returns the configuration of the class, if any.
+func (opts OptionsConfiguration) BandwidthClass(class string) (BandwidthClassConfiguration, bool) {
+	for _, c := range opts.BandwidthClasses {
+		if c.Class == class {
+			return c, true
+		}
+	}
+	return BandwidthClassConfiguration{}, false
+}
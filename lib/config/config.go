@@ -42,6 +42,9 @@ var (
 	DefaultTCPPort = 22000
 	// DefaultQUICPort defines default QUIC port used if the URI does not specify one, for example quic://0.0.0.0
 	DefaultQUICPort = 22000
+	// DefaultWebSocketPort defines default port used for WebSocket over TLS
+	// if the URI does not specify one, for example wss://0.0.0.0/syncthing
+	DefaultWebSocketPort = 443
 	// DefaultListenAddresses should be substituted when the configuration
 	// contains <listenAddress>default</listenAddress>. This is done by the
 	// "consumer" of the configuration as we don't want these saved to the
@@ -354,6 +357,9 @@ func (cfg *Configuration) prepareFolders(myID protocol.DeviceID, existingDevices
 	// situation.
 	sharedFolders := make(map[protocol.DeviceID][]string, len(cfg.Devices))
 	existingFolders := make(map[string]*FolderConfiguration, len(cfg.Folders))
+	if err := cfg.prepareViews(); err != nil {
+		return nil, err
+	}
 	for i := range cfg.Folders {
 		folder := &cfg.Folders[i]
 
@@ -398,6 +404,7 @@ func (cfg *Configuration) prepareFolders(myID protocol.DeviceID, existingDevices
 	slices.SortFunc(cfg.Folders, func(a, b FolderConfiguration) int {
 		return strings.Compare(a.ID, b.ID)
 	})
+	cfg.prepareDependencies(myID)
 	return sharedFolders, nil
 }
 
@@ -485,6 +492,68 @@ func (cfg *Configuration) SetDevices(devices []DeviceConfiguration) {
 	cfg.Devices = append(cfg.Devices, filtered...)
 }
 
+// ReplaceDevice adds newID as the replacement of the device oldID, taking
+// over its settings, addresses and folder shares. The old device is kept
+// until RetireReplacedDevice is called for the new one, so that the
+// transition doesn't leave the folders unshared.
+func (cfg *Configuration) ReplaceDevice(oldID, newID protocol.DeviceID) error {
+	old, _, ok := cfg.Device(oldID)
+	if !ok {
+		return fmt.Errorf("device %s is not configured", oldID.Short())
+	}
+	if newID == protocol.EmptyDeviceID || newID == oldID {
+		return errors.New("invalid replacement device ID")
+	}
+	if _, _, ok := cfg.Device(newID); ok {
+		return fmt.Errorf("device %s is already configured", newID.Short())
+	}
+
+	replacement := old.Copy()
+	replacement.DeviceID = newID
+	replacement.Replaces = oldID
+	cfg.SetDevice(replacement)
+	for i := range cfg.Folders {
+		if dev, ok := cfg.Folders[i].Device(oldID); ok {
+			dev.DeviceID = newID
+			dev.OwnedPrefixes = slices.Clone(dev.OwnedPrefixes)
+			cfg.Folders[i].Devices = append(cfg.Folders[i].Devices, dev)
+		}
+	}
+	return nil
+}
+
+// RetireReplacedDevice removes the device replaced by the given one, if
+// any, pointing whatever it introduced at the replacement. It returns the
+// ID of the removed device.
+func (cfg *Configuration) RetireReplacedDevice(id protocol.DeviceID) (protocol.DeviceID, bool) {
+	_, i, ok := cfg.Device(id)
+	if !ok || cfg.Devices[i].Replaces == protocol.EmptyDeviceID {
+		return protocol.EmptyDeviceID, false
+	}
+	oldID := cfg.Devices[i].Replaces
+	cfg.Devices[i].Replaces = protocol.EmptyDeviceID
+
+	if _, j, ok := cfg.Device(oldID); ok {
+		cfg.Devices = append(cfg.Devices[:j], cfg.Devices[j+1:]...)
+	}
+	for i := range cfg.Devices {
+		if cfg.Devices[i].IntroducedBy == oldID {
+			cfg.Devices[i].IntroducedBy = id
+		}
+	}
+	for i := range cfg.Folders {
+		cfg.Folders[i].Devices = slices.DeleteFunc(cfg.Folders[i].Devices, func(dev FolderDeviceConfiguration) bool {
+			return dev.DeviceID == oldID
+		})
+		for j := range cfg.Folders[i].Devices {
+			if cfg.Folders[i].Devices[j].IntroducedBy == oldID {
+				cfg.Folders[i].Devices[j].IntroducedBy = id
+			}
+		}
+	}
+	return oldID, true
+}
+
 func (cfg *Configuration) Folder(id string) (FolderConfiguration, int, bool) {
 	for i, folder := range cfg.Folders {
 		if folder.ID == id {
@@ -11,6 +11,7 @@ import (
 	"context"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -54,51 +55,70 @@ func TestDefaultValues(t *testing.T) {
 		Version: CurrentVersion,
 		Folders: []FolderConfiguration{},
 		Options: OptionsConfiguration{
-			RawListenAddresses:        []string{"default"},
-			RawGlobalAnnServers:       []string{"default"},
-			GlobalAnnEnabled:          true,
-			LocalAnnEnabled:           true,
-			LocalAnnPort:              21027,
-			LocalAnnMCAddr:            "[ff12::8384]:21027",
-			MaxSendKbps:               0,
-			MaxRecvKbps:               0,
-			ReconnectIntervalS:        60,
-			RelaysEnabled:             true,
-			RelayReconnectIntervalM:   10,
-			StartBrowser:              true,
-			NATEnabled:                true,
-			NATLeaseM:                 60,
-			NATRenewalM:               30,
-			NATTimeoutS:               10,
-			AutoUpgradeIntervalH:      12,
-			KeepTemporariesH:          24,
-			CacheIgnoredFiles:         false,
-			ProgressUpdateIntervalS:   5,
-			LimitBandwidthInLan:       false,
-			MinHomeDiskFree:           Size{1, "%"},
-			URURL:                     "https://data.syncthing.net/newdata",
-			URInitialDelayS:           1800,
-			URPostInsecurely:          false,
-			ReleasesURL:               "https://upgrades.syncthing.net/meta.json",
-			AlwaysLocalNets:           []string{},
-			OverwriteRemoteDevNames:   false,
-			TempIndexMinBlocks:        10,
-			UnackedNotificationIDs:    []string{"authenticationUserAndPassword"},
-			SetLowPriority:            true,
-			CRURL:                     "https://crash.syncthing.net/newcrash",
-			CREnabled:                 true,
-			StunKeepaliveStartS:       180,
-			StunKeepaliveMinS:         20,
-			RawStunServers:            []string{"default"},
-			AnnounceLANAddresses:      true,
-			FeatureFlags:              []string{},
-			AuditEnabled:              false,
-			AuditFile:                 "",
-			ConnectionPriorityTCPLAN:  10,
-			ConnectionPriorityQUICLAN: 20,
-			ConnectionPriorityTCPWAN:  30,
-			ConnectionPriorityQUICWAN: 40,
-			ConnectionPriorityRelay:   50,
+			RawListenAddresses:             []string{"default"},
+			RawGlobalAnnServers:            []string{"default"},
+			GlobalAnnEnabled:               true,
+			LocalAnnEnabled:                true,
+			LocalAnnPort:                   21027,
+			LocalAnnMCAddr:                 "[ff12::8384]:21027",
+			MaxSendKbps:                    0,
+			MaxRecvKbps:                    0,
+			ReconnectIntervalS:             60,
+			RelaysEnabled:                  true,
+			RelayReconnectIntervalM:        10,
+			StartBrowser:                   true,
+			NATEnabled:                     true,
+			NATLeaseM:                      60,
+			NATRenewalM:                    30,
+			NATTimeoutS:                    10,
+			AutoUpgradeIntervalH:           12,
+			KeepTemporariesH:               24,
+			CacheIgnoredFiles:              false,
+			ProgressUpdateIntervalS:        5,
+			LimitBandwidthInLan:            false,
+			MinHomeDiskFree:                Size{1, "%"},
+			URURL:                          "https://data.syncthing.net/newdata",
+			URInitialDelayS:                1800,
+			URPostInsecurely:               false,
+			ReleasesURL:                    "https://upgrades.syncthing.net/meta.json",
+			AlwaysLocalNets:                []string{},
+			OverwriteRemoteDevNames:        false,
+			TempIndexMinBlocks:             10,
+			UnackedNotificationIDs:         []string{"authenticationUserAndPassword"},
+			SetLowPriority:                 true,
+			CRURL:                          "https://crash.syncthing.net/newcrash",
+			CREnabled:                      true,
+			StunKeepaliveStartS:            180,
+			StunKeepaliveMinS:              20,
+			RawStunServers:                 []string{"default"},
+			AnnounceLANAddresses:           true,
+			FeatureFlags:                   []string{},
+			AuditEnabled:                   false,
+			AuditFile:                      "",
+			ConnectionPriorityTCPLAN:       10,
+			ConnectionPriorityQUICLAN:      20,
+			ConnectionPriorityTCPWAN:       30,
+			ConnectionPriorityQUICWAN:      40,
+			ConnectionPriorityWebSocket:    45,
+			ConnectionPriorityRelay:        50,
+			DemuxHostnames:                 []string{},
+			DemuxWebSocketPath:             "/syncthing",
+			TempCleanupIntervalS:           0,
+			KeepOrphanTemporariesH:         1,
+			KeepRemovedFolderIndexH:        24,
+			InfraProbeIntervalS:            600,
+			ZstdCompressionLevel:           3,
+			DeviceAbsenceAnomalyDays:       30,
+			DialJitterS:                    10,
+			AnnounceJitterS:                60,
+			AutoUpgradePeerCheck:           "warn",
+			ReferenceProfileCheckIntervalS: 300,
+			LANHeartbeatPort:               21028,
+			LANHeartbeatIntervalMs:         250,
+			ConnectionWeightLatency:        25,
+			ConnectionWeightStability:      25,
+			ConnectionWeightBandwidth:      25,
+			ConnectionWeightPriority:       25,
 		},
 		Defaults: Defaults{
 			Folder: FolderConfiguration{
@@ -258,52 +278,53 @@ func TestNoListenAddresses(t *testing.T) {
 
 func TestOverriddenValues(t *testing.T) {
 	expected := OptionsConfiguration{
-		RawListenAddresses:        []string{"tcp://:23000"},
-		RawGlobalAnnServers:       []string{"udp4://syncthing.nym.se:22026"},
-		GlobalAnnEnabled:          false,
-		LocalAnnEnabled:           false,
-		LocalAnnPort:              42123,
-		LocalAnnMCAddr:            "quux:3232",
-		MaxSendKbps:               1234,
-		MaxRecvKbps:               2341,
-		ReconnectIntervalS:        6000,
-		RelaysEnabled:             false,
-		RelayReconnectIntervalM:   20,
-		StartBrowser:              false,
-		NATEnabled:                false,
-		NATLeaseM:                 90,
-		NATRenewalM:               15,
-		NATTimeoutS:               15,
-		AutoUpgradeIntervalH:      24,
-		KeepTemporariesH:          48,
-		CacheIgnoredFiles:         true,
-		ProgressUpdateIntervalS:   10,
-		LimitBandwidthInLan:       true,
-		MinHomeDiskFree:           Size{5.2, "%"},
-		URSeen:                    8,
-		URAccepted:                4,
-		URURL:                     "https://localhost/newdata",
-		URInitialDelayS:           800,
-		URPostInsecurely:          true,
-		ReleasesURL:               "https://localhost/releases",
-		AlwaysLocalNets:           []string{},
-		OverwriteRemoteDevNames:   true,
-		TempIndexMinBlocks:        100,
-		UnackedNotificationIDs:    []string{"asdfasdf"},
-		SetLowPriority:            false,
-		CRURL:                     "https://localhost/newcrash",
-		CREnabled:                 false,
-		StunKeepaliveStartS:       9000,
-		StunKeepaliveMinS:         900,
-		RawStunServers:            []string{"foo"},
-		FeatureFlags:              []string{"feature"},
-		AuditEnabled:              true,
-		AuditFile:                 "nggyu",
-		ConnectionPriorityTCPLAN:  40,
-		ConnectionPriorityQUICLAN: 45,
-		ConnectionPriorityTCPWAN:  50,
-		ConnectionPriorityQUICWAN: 55,
-		ConnectionPriorityRelay:   9000,
+		RawListenAddresses:          []string{"tcp://:23000"},
+		RawGlobalAnnServers:         []string{"udp4://syncthing.nym.se:22026"},
+		GlobalAnnEnabled:            false,
+		LocalAnnEnabled:             false,
+		LocalAnnPort:                42123,
+		LocalAnnMCAddr:              "quux:3232",
+		MaxSendKbps:                 1234,
+		MaxRecvKbps:                 2341,
+		ReconnectIntervalS:          6000,
+		RelaysEnabled:               false,
+		RelayReconnectIntervalM:     20,
+		StartBrowser:                false,
+		NATEnabled:                  false,
+		NATLeaseM:                   90,
+		NATRenewalM:                 15,
+		NATTimeoutS:                 15,
+		AutoUpgradeIntervalH:        24,
+		KeepTemporariesH:            48,
+		CacheIgnoredFiles:           true,
+		ProgressUpdateIntervalS:     10,
+		LimitBandwidthInLan:         true,
+		MinHomeDiskFree:             Size{5.2, "%"},
+		URSeen:                      8,
+		URAccepted:                  4,
+		URURL:                       "https://localhost/newdata",
+		URInitialDelayS:             800,
+		URPostInsecurely:            true,
+		ReleasesURL:                 "https://localhost/releases",
+		AlwaysLocalNets:             []string{},
+		OverwriteRemoteDevNames:     true,
+		TempIndexMinBlocks:          100,
+		UnackedNotificationIDs:      []string{"asdfasdf"},
+		SetLowPriority:              false,
+		CRURL:                       "https://localhost/newcrash",
+		CREnabled:                   false,
+		StunKeepaliveStartS:         9000,
+		StunKeepaliveMinS:           900,
+		RawStunServers:              []string{"foo"},
+		FeatureFlags:                []string{"feature"},
+		AuditEnabled:                true,
+		AuditFile:                   "nggyu",
+		ConnectionPriorityTCPLAN:    40,
+		ConnectionPriorityQUICLAN:   45,
+		ConnectionPriorityTCPWAN:    50,
+		ConnectionPriorityQUICWAN:   55,
+		ConnectionPriorityWebSocket: 8000,
+		ConnectionPriorityRelay:     9000,
 	}
 	expectedPath := "/media/syncthing"
 
@@ -332,30 +353,42 @@ func TestDeviceAddressesDynamic(t *testing.T) {
 	name, _ := os.Hostname()
 	expected := map[protocol.DeviceID]DeviceConfiguration{
 		device1: {
-			DeviceID:        device1,
-			Addresses:       []string{"dynamic"},
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:            device1,
+			Addresses:           []string{"dynamic"},
+			AllowedNetworks:     []string{},
+			IgnoredFolders:      []ObservedFolder{},
+			TrafficClass:        TrafficClassNormal,
+			PreferredTransports: []string{},
+			ExpectedNetworks:    []string{},
 		},
 		device2: {
-			DeviceID:        device2,
-			Addresses:       []string{"dynamic"},
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:            device2,
+			Addresses:           []string{"dynamic"},
+			AllowedNetworks:     []string{},
+			IgnoredFolders:      []ObservedFolder{},
+			TrafficClass:        TrafficClassNormal,
+			PreferredTransports: []string{},
+			ExpectedNetworks:    []string{},
 		},
 		device3: {
-			DeviceID:        device3,
-			Addresses:       []string{"dynamic"},
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:            device3,
+			Addresses:           []string{"dynamic"},
+			AllowedNetworks:     []string{},
+			IgnoredFolders:      []ObservedFolder{},
+			TrafficClass:        TrafficClassNormal,
+			PreferredTransports: []string{},
+			ExpectedNetworks:    []string{},
 		},
 		device4: {
-			DeviceID:        device4,
-			Name:            name, // Set when auto created
-			Addresses:       []string{"dynamic"},
-			Compression:     CompressionMetadata,
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:            device4,
+			Name:                name, // Set when auto created
+			Addresses:           []string{"dynamic"},
+			Compression:         CompressionMetadata,
+			AllowedNetworks:     []string{},
+			IgnoredFolders:      []ObservedFolder{},
+			TrafficClass:        TrafficClassNormal,
+			PreferredTransports: []string{},
+			ExpectedNetworks:    []string{},
 		},
 	}
 
@@ -375,33 +408,45 @@ func TestDeviceCompression(t *testing.T) {
 	name, _ := os.Hostname()
 	expected := map[protocol.DeviceID]DeviceConfiguration{
 		device1: {
-			DeviceID:        device1,
-			Addresses:       []string{"dynamic"},
-			Compression:     CompressionMetadata,
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:            device1,
+			Addresses:           []string{"dynamic"},
+			Compression:         CompressionMetadata,
+			AllowedNetworks:     []string{},
+			IgnoredFolders:      []ObservedFolder{},
+			TrafficClass:        TrafficClassNormal,
+			PreferredTransports: []string{},
+			ExpectedNetworks:    []string{},
 		},
 		device2: {
-			DeviceID:        device2,
-			Addresses:       []string{"dynamic"},
-			Compression:     CompressionMetadata,
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:            device2,
+			Addresses:           []string{"dynamic"},
+			Compression:         CompressionMetadata,
+			AllowedNetworks:     []string{},
+			IgnoredFolders:      []ObservedFolder{},
+			TrafficClass:        TrafficClassNormal,
+			PreferredTransports: []string{},
+			ExpectedNetworks:    []string{},
 		},
 		device3: {
-			DeviceID:        device3,
-			Addresses:       []string{"dynamic"},
-			Compression:     CompressionNever,
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:            device3,
+			Addresses:           []string{"dynamic"},
+			Compression:         CompressionNever,
+			AllowedNetworks:     []string{},
+			IgnoredFolders:      []ObservedFolder{},
+			TrafficClass:        TrafficClassNormal,
+			PreferredTransports: []string{},
+			ExpectedNetworks:    []string{},
 		},
 		device4: {
-			DeviceID:        device4,
-			Name:            name, // Set when auto created
-			Addresses:       []string{"dynamic"},
-			Compression:     CompressionMetadata,
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:            device4,
+			Name:                name, // Set when auto created
+			Addresses:           []string{"dynamic"},
+			Compression:         CompressionMetadata,
+			AllowedNetworks:     []string{},
+			IgnoredFolders:      []ObservedFolder{},
+			TrafficClass:        TrafficClassNormal,
+			PreferredTransports: []string{},
+			ExpectedNetworks:    []string{},
 		},
 	}
 
@@ -421,30 +466,42 @@ func TestDeviceAddressesStatic(t *testing.T) {
 	name, _ := os.Hostname()
 	expected := map[protocol.DeviceID]DeviceConfiguration{
 		device1: {
-			DeviceID:        device1,
-			Addresses:       []string{"tcp://192.0.2.1", "tcp://192.0.2.2"},
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:            device1,
+			Addresses:           []string{"tcp://192.0.2.1", "tcp://192.0.2.2"},
+			AllowedNetworks:     []string{},
+			IgnoredFolders:      []ObservedFolder{},
+			TrafficClass:        TrafficClassNormal,
+			PreferredTransports: []string{},
+			ExpectedNetworks:    []string{},
 		},
 		device2: {
-			DeviceID:        device2,
-			Addresses:       []string{"tcp://192.0.2.3:6070", "tcp://[2001:db8::42]:4242"},
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:            device2,
+			Addresses:           []string{"tcp://192.0.2.3:6070", "tcp://[2001:db8::42]:4242"},
+			AllowedNetworks:     []string{},
+			IgnoredFolders:      []ObservedFolder{},
+			TrafficClass:        TrafficClassNormal,
+			PreferredTransports: []string{},
+			ExpectedNetworks:    []string{},
 		},
 		device3: {
-			DeviceID:        device3,
-			Addresses:       []string{"tcp://[2001:db8::44]:4444", "tcp://192.0.2.4:6090"},
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:            device3,
+			Addresses:           []string{"tcp://[2001:db8::44]:4444", "tcp://192.0.2.4:6090"},
+			AllowedNetworks:     []string{},
+			IgnoredFolders:      []ObservedFolder{},
+			TrafficClass:        TrafficClassNormal,
+			PreferredTransports: []string{},
+			ExpectedNetworks:    []string{},
 		},
 		device4: {
-			DeviceID:        device4,
-			Name:            name, // Set when auto created
-			Addresses:       []string{"dynamic"},
-			Compression:     CompressionMetadata,
-			AllowedNetworks: []string{},
-			IgnoredFolders:  []ObservedFolder{},
+			DeviceID:            device4,
+			Name:                name, // Set when auto created
+			Addresses:           []string{"dynamic"},
+			Compression:         CompressionMetadata,
+			AllowedNetworks:     []string{},
+			IgnoredFolders:      []ObservedFolder{},
+			TrafficClass:        TrafficClassNormal,
+			PreferredTransports: []string{},
+			ExpectedNetworks:    []string{},
 		},
 	}
 
@@ -1480,6 +1537,127 @@ func TestReceiveEncryptedFolderFixed(t *testing.T) {
 	}
 }
 
+func TestFolderViewPrepared(t *testing.T) {
+	cfg := Configuration{
+		Version: CurrentVersion,
+		Folders: []FolderConfiguration{
+			{ID: "photos", Path: "testdata/photos"},
+			{ID: "docs", Path: "testdata/docs"},
+			{
+				ID:   "view",
+				Type: FolderTypeSendOnly,
+				ViewSources: []FolderViewSource{
+					{Folder: "photos", Path: "2024"},
+					{Folder: "docs", Path: "Public", As: "Shared/Docs"},
+					{Folder: "missing", Path: "x"},
+				},
+			},
+		},
+	}
+
+	if err := cfg.prepare(device1); err != nil {
+		t.Fatal(err)
+	}
+
+	var view FolderConfiguration
+	for _, f := range cfg.Folders {
+		if f.ID == "view" {
+			view = f
+		}
+	}
+	if view.FilesystemType != FilesystemTypeView || view.Type != FolderTypeSendOnly {
+		t.Errorf("unexpected view folder type %v, %v", view.FilesystemType, view.Type)
+	}
+	ffs := view.Filesystem()
+	names, err := ffs.DirNames(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{".stfolder", "2024", "Shared"}) {
+		t.Errorf("unexpected view contents %v", names)
+	}
+	if err := view.CheckPath(); err != nil {
+		t.Error("view should have a marker:", err)
+	}
+
+	// Preparing again derives the same view.
+	path := view.Path
+	if err := cfg.prepare(device1); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range cfg.Folders {
+		if f.ID == "view" && f.Path != path {
+			t.Errorf("view path changed to %q", f.Path)
+		}
+	}
+}
+
+func TestFolderViewInvalid(t *testing.T) {
+	photos := FolderConfiguration{ID: "photos", Path: "testdata/photos"}
+	cases := []struct {
+		view FolderConfiguration
+		err  error
+	}{
+		{FolderConfiguration{ID: "view", Type: FolderTypeSendOnly, ViewSources: []FolderViewSource{{Folder: "photos", Path: "../docs"}}}, errViewSourcePath},
+		{FolderConfiguration{ID: "view", Type: FolderTypeSendOnly, ViewSources: []FolderViewSource{{Folder: "photos", Path: "2024/../../docs"}}}, errViewSourcePath},
+		{FolderConfiguration{ID: "view", Type: FolderTypeSendOnly, ViewSources: []FolderViewSource{{Folder: "photos", Path: "/etc"}}}, errViewSourcePath},
+		{FolderConfiguration{ID: "view", Type: FolderTypeSendOnly, Path: "testdata/view", ViewSources: []FolderViewSource{{Folder: "photos", Path: "2024"}}}, errViewPath},
+		{FolderConfiguration{ID: "view", ViewSources: []FolderViewSource{{Folder: "photos", Path: "2024"}}}, errViewType},
+	}
+	for _, tc := range cases {
+		cfg := Configuration{Version: CurrentVersion, Folders: []FolderConfiguration{photos, tc.view}}
+		if err := cfg.prepare(device1); !errors.Is(err, tc.err) {
+			t.Errorf("%+v: got %v, expected %v", tc.view, err, tc.err)
+		}
+	}
+}
+
+func TestFolderDependenciesPrepared(t *testing.T) {
+	shared := []FolderDeviceConfiguration{{DeviceID: device2}}
+	cfg := Configuration{
+		Devices: []DeviceConfiguration{{DeviceID: device2}, {DeviceID: device3}},
+		Folders: []FolderConfiguration{
+			{ID: "apps", Path: "testdata/apps", Devices: shared},
+			{ID: "data", Path: "testdata/data", Devices: shared, DependsOn: []FolderDependency{
+				{Folder: "apps", CompletionPct: 90},
+				{Folder: "missing"},
+				{Folder: "data"},
+			}},
+			// Not shared with device2, so nothing arrives from the same device
+			{ID: "other", Path: "testdata/other", Devices: []FolderDeviceConfiguration{{DeviceID: device3}}, DependsOn: []FolderDependency{
+				{Folder: "apps"},
+			}},
+			// A cycle of x -> y -> z -> x, of which z -> x goes
+			{ID: "x", Path: "testdata/x", Devices: shared, DependsOn: []FolderDependency{{Folder: "y"}}},
+			{ID: "y", Path: "testdata/y", Devices: shared, DependsOn: []FolderDependency{{Folder: "z"}}},
+			{ID: "z", Path: "testdata/z", Devices: shared, DependsOn: []FolderDependency{{Folder: "x"}}},
+		},
+	}
+
+	if err := cfg.prepare(device1); err != nil {
+		t.Fatal(err)
+	}
+
+	deps := make(map[string][]FolderDependency)
+	for _, f := range cfg.Folders {
+		deps[f.ID] = f.DependsOn
+	}
+	if !slices.Equal(deps["data"], []FolderDependency{{Folder: "apps", CompletionPct: 90}}) {
+		t.Errorf("unexpected dependencies of data: %v", deps["data"])
+	}
+	if len(deps["other"]) != 0 {
+		t.Errorf("unexpected dependencies of other: %v", deps["other"])
+	}
+	if len(deps["x"]) != 1 || len(deps["y"]) != 1 || len(deps["z"]) != 0 {
+		t.Errorf("unexpected dependencies in cycle: %v, %v, %v", deps["x"], deps["y"], deps["z"])
+	}
+
+	if th := (FolderDependency{Folder: "apps"}).Threshold(); th != 100 {
+		t.Errorf("expected a default threshold of 100, got %v", th)
+	}
+}
+
 func TestXattrFilter(t *testing.T) {
 	cases := []struct {
 		in     []string
@@ -1686,3 +1864,90 @@ func TestCopyMatching(t *testing.T) {
 		t.Error("NoCopy")
 	}
 }
+
+func TestReplaceDevice(t *testing.T) {
+	cfg := New(device1)
+	cfg.SetDevice(DeviceConfiguration{DeviceID: device2, Name: "old", Addresses: []string{"tcp://192.0.2.42:22000"}, Introducer: true})
+	cfg.SetDevice(DeviceConfiguration{DeviceID: device4, IntroducedBy: device2})
+	cfg.SetFolder(FolderConfiguration{ID: "a", Devices: []FolderDeviceConfiguration{{DeviceID: device1}, {DeviceID: device2, EncryptionPassword: "secret"}}})
+	cfg.SetFolder(FolderConfiguration{ID: "b", Devices: []FolderDeviceConfiguration{{DeviceID: device1}}})
+
+	if err := cfg.ReplaceDevice(device3, device2); err == nil {
+		t.Error("expected error replacing an unknown device")
+	}
+	if err := cfg.ReplaceDevice(device2, device4); err == nil {
+		t.Error("expected error replacing by a configured device")
+	}
+	if err := cfg.ReplaceDevice(device2, device3); err != nil {
+		t.Fatal(err)
+	}
+
+	dev, _, ok := cfg.Device(device3)
+	if !ok {
+		t.Fatal("replacement device not added")
+	}
+	if dev.Name != "old" || !dev.Introducer || len(dev.Addresses) != 1 || dev.Replaces != device2 {
+		t.Error("replacement device didn't take over the settings:", dev)
+	}
+	if fdev, ok := cfg.Folders[0].Device(device3); !ok || fdev.EncryptionPassword != "secret" {
+		t.Error("replacement device didn't take over the folder share")
+	}
+	if cfg.Folders[1].SharedWith(device3) {
+		t.Error("replacement device should only get the shares of the old one")
+	}
+	if _, _, ok := cfg.Device(device2); !ok {
+		t.Error("old device should be kept until retired")
+	}
+
+	if _, ok := cfg.RetireReplacedDevice(device4); ok {
+		t.Error("device not replacing anything shouldn't retire anything")
+	}
+	if old, ok := cfg.RetireReplacedDevice(device3); !ok || old != device2 {
+		t.Fatal("expected old device to be retired")
+	}
+	if _, _, ok := cfg.Device(device2); ok {
+		t.Error("old device should be removed")
+	}
+	if cfg.Folders[0].SharedWith(device2) {
+		t.Error("old device should be unshared")
+	}
+	if dev, _, _ := cfg.Device(device3); dev.Replaces != protocol.EmptyDeviceID {
+		t.Error("replacement should be done")
+	}
+	if dev, _, _ := cfg.Device(device4); dev.IntroducedBy != device3 {
+		t.Error("introduced device should point at the replacement")
+	}
+}
+
+func TestConnectionPolicyPrepared(t *testing.T) {
+	cfg := New(device1)
+	cfg.Options.ConnectionWeightLAN = -5
+	cfg.Devices = append(cfg.Devices, DeviceConfiguration{
+		DeviceID:                device2,
+		PreferredTransports:     []string{" QUIC", "tcp", "quic", "carrier-pigeon"},
+		ConnectionWeightLatency: 60,
+		ConnectionWeightLAN:     -1,
+	})
+	cfg.prepare(device1)
+
+	if cfg.Options.ConnectionWeightLAN != 0 {
+		t.Error("negative weight should be zero, got", cfg.Options.ConnectionWeightLAN)
+	}
+	dev, _, _ := cfg.Device(device2)
+	if !slices.Equal(dev.PreferredTransports, []string{TransportQUIC, TransportTCP}) {
+		t.Error("unexpected preferred transports", dev.PreferredTransports)
+	}
+	if dev.TransportAllowed(TransportRelay) || !dev.TransportAllowed(TransportTCP) {
+		t.Error("only the preferred transports should be allowed")
+	}
+
+	weights := dev.ConnectionWeights(cfg.Options)
+	expected := ConnectionWeights{Latency: 60, Stability: 25, Bandwidth: 25, Priority: 25, LAN: 0}
+	if weights != expected {
+		t.Errorf("got weights %+v, expected %+v", weights, expected)
+	}
+
+	if dev, _, _ := cfg.Device(device1); !dev.TransportAllowed(TransportRelay) {
+		t.Error("any transport should be allowed without preferred ones")
+	}
+}
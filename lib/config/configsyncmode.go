@@ -0,0 +1,53 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+// ConfigSyncMode is how configuration is replicated with a device.
+type ConfigSyncMode int32
+
+const (
+	// Nothing is sent to or accepted from the device.
+	ConfigSyncModeOff ConfigSyncMode = 0
+	// Changes are sent, and changes received wait for approval.
+	ConfigSyncModeApprove ConfigSyncMode = 1
+	// Changes are sent, and changes received are applied unless they
+	// conflict with local changes.
+	ConfigSyncModeAuto ConfigSyncMode = 2
+)
+
+func (m ConfigSyncMode) String() string {
+	switch m {
+	case ConfigSyncModeOff:
+		return "off"
+	case ConfigSyncModeApprove:
+		return "approve"
+	case ConfigSyncModeAuto:
+		return "auto"
+	default:
+		return "unknown"
+	}
+}
+
+func (m ConfigSyncMode) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *ConfigSyncMode) UnmarshalText(bs []byte) error {
+	switch string(bs) {
+	case "approve":
+		*m = ConfigSyncModeApprove
+	case "auto":
+		*m = ConfigSyncModeAuto
+	default:
+		*m = ConfigSyncModeOff
+	}
+	return nil
+}
+
+func (m *ConfigSyncMode) ParseDefault(str string) error {
+	return m.UnmarshalText([]byte(str))
+}
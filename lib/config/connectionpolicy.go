@@ -0,0 +1,87 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/syncthing/syncthing/lib/stringutil"
+)
+
+// Transports of connections, as named in the preferred transports of
+// devices.
+const (
+	TransportQUIC      = "quic"
+	TransportTCP       = "tcp"
+	TransportRelay     = "relay"
+	TransportWebSocket = "websocket"
+	TransportLinkLocal = "linklocal"
+	TransportUnix      = "unix"
+)
+
+// Transports lists the transports of connections.
+var Transports = []string{TransportQUIC, TransportTCP, TransportRelay, TransportWebSocket, TransportLinkLocal, TransportUnix}
+
+// ConnectionWeights are the weights of the metrics connections to a device
+// are scored by, when choosing which of them to keep.
+type ConnectionWeights struct {
+	Latency   int
+	Stability int
+	Bandwidth int
+	Priority  int
+	LAN       int
+}
+
+// ConnectionWeights returns the weights of scoring connections to the
+// device, which are the overall ones where the device doesn't set its own.
+func (cfg DeviceConfiguration) ConnectionWeights(opts OptionsConfiguration) ConnectionWeights {
+	w := ConnectionWeights{
+		Latency:   opts.ConnectionWeightLatency,
+		Stability: opts.ConnectionWeightStability,
+		Bandwidth: opts.ConnectionWeightBandwidth,
+		Priority:  opts.ConnectionWeightPriority,
+		LAN:       opts.ConnectionWeightLAN,
+	}
+	for _, o := range []struct {
+		override int
+		weight   *int
+	}{
+		{cfg.ConnectionWeightLatency, &w.Latency},
+		{cfg.ConnectionWeightStability, &w.Stability},
+		{cfg.ConnectionWeightBandwidth, &w.Bandwidth},
+		{cfg.ConnectionWeightPriority, &w.Priority},
+		{cfg.ConnectionWeightLAN, &w.LAN},
+	} {
+		if o.override > 0 {
+			*o.weight = o.override
+		}
+	}
+	return w
+}
+
+// TransportAllowed returns whether connections to the device may use the
+// transport, which is any transport unless it has preferred ones.
+func (cfg DeviceConfiguration) TransportAllowed(transport string) bool {
+	return len(cfg.PreferredTransports) == 0 || slices.Contains(cfg.PreferredTransports, transport)
+}
+
+func prepareConnectionWeights(weights ...*int) {
+	for _, w := range weights {
+		*w = max(*w, 0)
+	}
+}
+
+func preparePreferredTransports(transports []string) []string {
+	for i := range transports {
+		transports[i] = strings.ToLower(transports[i])
+	}
+	transports = stringutil.UniqueTrimmedStrings(transports)
+	return slices.DeleteFunc(transports, func(t string) bool {
+		return !slices.Contains(Transports, t)
+	})
+}
@@ -9,7 +9,9 @@ package config
 import (
 	"fmt"
 	"log/slog"
+	"net"
 	"slices"
+	"strings"
 
 	"github.com/syncthing/syncthing/lib/protocol"
 )
@@ -36,6 +38,44 @@ type DeviceConfiguration struct {
 	Untrusted                bool              `json:"untrusted" xml:"untrusted"`
 	RemoteGUIPort            int               `json:"remoteGUIPort" xml:"remoteGUIPort"`
 	RawNumConnections        int               `json:"numConnections" xml:"numConnections"`
+	StrictCertificateExpiry  bool              `json:"strictCertificateExpiry" xml:"strictCertificateExpiry"`
+	DialSourceAddress        string            `json:"dialSourceAddress" xml:"dialSourceAddress,omitempty"`
+	DialInterface            string            `json:"dialInterface" xml:"dialInterface,omitempty"`
+	// Networks the device is expected to connect from, like
+	// AllowedNetworks; connections from elsewhere are reported as device
+	// anomalies but not refused.
+	ExpectedNetworks []string `json:"expectedNetworks" xml:"expectedNetwork,omitempty"`
+	// Replication of configuration with the device, making either a warm
+	// spare of the other. It takes both devices enabling it, and the
+	// parts replicated are those both have selected.
+	ConfigSync        ConfigSyncMode `json:"configSync" xml:"configSync" default:"off"`
+	ConfigSyncFolders bool           `json:"configSyncFolders" xml:"configSyncFolders" default:"true"`
+	ConfigSyncDevices bool           `json:"configSyncDevices" xml:"configSyncDevices" default:"true"`
+	ConfigSyncIgnores bool           `json:"configSyncIgnores" xml:"configSyncIgnores" default:"true"`
+	// Encrypt block data end to end on relayed connections, in addition to
+	// TLS. Relayed connections are refused unless the device seals blocks
+	// as well.
+	SealRelayedBlocks bool `json:"sealRelayedBlocks" xml:"sealRelayedBlocks"`
+	// The device this one replaces, e.g. after reinstalling it with a new
+	// certificate. The old entry is removed once this device connects.
+	Replaces protocol.DeviceID `json:"replaces" xml:"replaces,attr" nodefault:"true"`
+	// The error budget of connections with the device. Exceeding it raises
+	// a warning about the device until acknowledged. Zero disables either.
+	DisconnectBudgetPerHour int `json:"disconnectBudgetPerHour" xml:"disconnectBudgetPerHour" default:"10"`
+	FailedDialBudgetPerDay  int `json:"failedDialBudgetPerDay" xml:"failedDialBudgetPerDay" default:"500"`
+	// How the device shares the overall rate limit with others, normal or
+	// background.
+	TrafficClass string `json:"trafficClass" xml:"trafficClass" default:"normal"`
+	// Transports connections to the device may use, the more preferred
+	// first. Empty allows any transport.
+	PreferredTransports []string `json:"preferredTransports" xml:"preferredTransport,omitempty"`
+	// Weights of scoring connections to the device, overriding those in
+	// the options. Zero uses the option.
+	ConnectionWeightLatency   int `json:"connectionWeightLatency" xml:"connectionWeightLatency"`
+	ConnectionWeightStability int `json:"connectionWeightStability" xml:"connectionWeightStability"`
+	ConnectionWeightBandwidth int `json:"connectionWeightBandwidth" xml:"connectionWeightBandwidth"`
+	ConnectionWeightPriority  int `json:"connectionWeightPriority" xml:"connectionWeightPriority"`
+	ConnectionWeightLAN       int `json:"connectionWeightLAN" xml:"connectionWeightLAN"`
 }
 
 func (cfg DeviceConfiguration) Copy() DeviceConfiguration {
@@ -44,6 +84,8 @@ func (cfg DeviceConfiguration) Copy() DeviceConfiguration {
 	copy(c.Addresses, cfg.Addresses)
 	c.AllowedNetworks = make([]string, len(cfg.AllowedNetworks))
 	copy(c.AllowedNetworks, cfg.AllowedNetworks)
+	c.ExpectedNetworks = slices.Clone(cfg.ExpectedNetworks)
+	c.PreferredTransports = slices.Clone(cfg.PreferredTransports)
 	c.IgnoredFolders = make([]ObservedFolder, len(cfg.IgnoredFolders))
 	copy(c.IgnoredFolders, cfg.IgnoredFolders)
 	return c
@@ -62,6 +104,15 @@ func (cfg *DeviceConfiguration) prepare(sharedFolders []string) {
 
 	cfg.IgnoredFolders = sortedObservedFolderSlice(ignoredFolders)
 
+	cfg.DialSourceAddress, cfg.DialInterface = prepareDialSource(cfg.DialSourceAddress, cfg.DialInterface)
+
+	if !slices.Contains(TrafficClasses, cfg.TrafficClass) {
+		cfg.TrafficClass = TrafficClassNormal
+	}
+
+	cfg.PreferredTransports = preparePreferredTransports(cfg.PreferredTransports)
+	prepareConnectionWeights(&cfg.ConnectionWeightLatency, &cfg.ConnectionWeightStability, &cfg.ConnectionWeightBandwidth, &cfg.ConnectionWeightPriority, &cfg.ConnectionWeightLAN)
+
 	// A device cannot be simultaneously untrusted and an introducer, nor
 	// auto accept folders.
 	if cfg.Untrusted {
@@ -124,3 +175,17 @@ func (cfg *DeviceConfiguration) Description() string {
 	}
 	return fmt.Sprintf("%s (%s)", cfg.Name, cfg.DeviceID.Short())
 }
+
+// prepareDialSource cleans up the source address and interface settings
+// for outgoing connections, dropping an address that isn't a valid IP.
+// Whether the interface exists is checked when dialing, as it may come and
+// go.
+func prepareDialSource(address, iface string) (string, string) {
+	address = strings.TrimSpace(address)
+	iface = strings.TrimSpace(iface)
+	if address != "" && net.ParseIP(address) == nil {
+		slog.Warn("Ignoring invalid dial source address", slog.String("address", address))
+		address = ""
+	}
+	return address, iface
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"unicode"
+)
+
+// MinEncryptionPasswordBits is the estimated strength, in bits, below which
+// new encryption passwords are refused. The key derivation slows down
+// guessing, but an untrusted device has all the time in the world.
+const MinEncryptionPasswordBits = 60
+
+var ErrEncryptionPasswordWeak = errors.New("encryption password too weak")
+
+// EncryptionPasswordStrength estimates the strength of the password in
+// bits, from its length and the kinds of characters in it. Repeating
+// characters don't count for much.
+func EncryptionPasswordStrength(password string) float64 {
+	var lower, upper, digit, symbol, other bool
+	distinct := make(map[rune]struct{})
+	length := 0
+	for _, r := range password {
+		length++
+		distinct[r] = struct{}{}
+		switch {
+		case r > unicode.MaxASCII:
+			other = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	if length == 0 {
+		return 0
+	}
+
+	pool := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.present {
+			pool += class.size
+		}
+	}
+	bits := float64(length) * math.Log2(float64(pool))
+	if half := float64(length) / 2; float64(len(distinct)) < half {
+		bits *= float64(len(distinct)) / half
+	}
+	return bits
+}
+
+// CheckEncryptionPassword returns ErrEncryptionPasswordWeak, with the
+// estimated strength, for a password below MinEncryptionPasswordBits.
+func CheckEncryptionPassword(password string) error {
+	if bits := EncryptionPasswordStrength(password); bits < MinEncryptionPasswordBits {
+		return fmt.Errorf("%w: about %.0f bits, at least %d needed; use a longer password or passphrase", ErrEncryptionPasswordWeak, bits, MinEncryptionPasswordBits)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckEncryptionPassword(t *testing.T) {
+	cases := []struct {
+		password string
+		ok       bool
+	}{
+		{"", false},
+		{"password", false},
+		{"aaaaaaaaaaaaaaaaaaaaaaaa", false},
+		{"12345678901234", false},
+		{"Tr0ub4dor&3", true},
+		{"correct horse battery staple", true},
+	}
+	for _, tc := range cases {
+		err := CheckEncryptionPassword(tc.password)
+		if tc.ok && err != nil {
+			t.Errorf("%q: unexpected error: %v", tc.password, err)
+		}
+		if !tc.ok && !errors.Is(err, ErrEncryptionPasswordWeak) {
+			t.Errorf("%q: expected a weak password error, got %v", tc.password, err)
+		}
+	}
+}
@@ -11,8 +11,10 @@ import "github.com/syncthing/syncthing/lib/fs"
 type FilesystemType string
 
 const (
-	FilesystemTypeBasic FilesystemType = "basic"
-	FilesystemTypeFake  FilesystemType = "fake"
+	FilesystemTypeBasic  FilesystemType = "basic"
+	FilesystemTypeFake   FilesystemType = "fake"
+	FilesystemTypeMemory FilesystemType = "memory"
+	FilesystemTypeView   FilesystemType = "view"
 )
 
 func (t FilesystemType) ToFS() fs.FilesystemType {
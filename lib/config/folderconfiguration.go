@@ -37,6 +37,7 @@ var (
 const (
 	DefaultMarkerName          = ".stfolder"
 	EncryptionTokenName        = "syncthing-encryption_password_token" //nolint: gosec
+	EncryptionDeviceTokensName = "syncthing-encryption_device_tokens"  //nolint: gosec
 	maxConcurrentWritesDefault = 16
 	maxConcurrentWritesLimit   = 256
 )
@@ -45,6 +46,8 @@ type FolderDeviceConfiguration struct {
 	DeviceID           protocol.DeviceID `json:"deviceID" xml:"id,attr"`
 	IntroducedBy       protocol.DeviceID `json:"introducedBy" xml:"introducedBy,attr"`
 	EncryptionPassword string            `json:"encryptionPassword" xml:"encryptionPassword"`
+	// Path prefixes of the folder the device is the advisory owner of
+	OwnedPrefixes []string `json:"ownedPrefixes" xml:"ownedPrefix"`
 }
 
 type FolderConfiguration struct {
@@ -87,10 +90,91 @@ type FolderConfiguration struct {
 	SyncXattrs              bool                        `json:"syncXattrs" xml:"syncXattrs"`
 	SendXattrs              bool                        `json:"sendXattrs" xml:"sendXattrs"`
 	XattrFilter             XattrFilter                 `json:"xattrFilter" xml:"xattrFilter"`
+	PreserveHardLinks       bool                        `json:"preserveHardLinks" xml:"preserveHardLinks"`
+
+	// Hybrid watching, complementing the kernel watcher with polling on
+	// network filesystems
+	FSWatcherHybridPoll      bool `json:"fsWatcherHybridPoll" xml:"fsWatcherHybridPoll"`
+	FSWatcherHybridIntervalS int  `json:"fsWatcherHybridIntervalS" xml:"fsWatcherHybridIntervalS" default:"30"`
+
+	// Lengthen the rescan interval, up to eight times the configured one,
+	// while the watcher catches all changes, and shorten it, down to a
+	// quarter, when scans find changes the watcher missed
+	AdaptiveRescanInterval bool `json:"adaptiveRescanInterval" xml:"adaptiveRescanInterval"`
 
 	// Folder priority
 	Priority int `json:"priority" xml:"priority" default:"0"`
 
+	// Share of the outstanding block requests to a device, relative to the
+	// other folders pulling from it at the same time
+	PullWeight int `json:"pullWeight" xml:"pullWeight" default:"1"`
+
+	// Bandwidth of the blocks sent and received for the folder, in KiB/s,
+	// on top of the overall and device limits, so that a busy folder can't
+	// starve others sharing a connection. Zero is unlimited.
+	MaxSendKbps int `json:"maxSendKbps" xml:"maxSendKbps" restart:"false"`
+	MaxRecvKbps int `json:"maxRecvKbps" xml:"maxRecvKbps" restart:"false"`
+
+	// Pulling that makes no progress for this long, with work pending and
+	// devices connected, is reported as stalled. Zero disables detection.
+	PullStallTimeoutS int `json:"pullStallTimeoutS" xml:"pullStallTimeoutS" default:"300"`
+
+	// Files being pulled and block requests outstanding at once. Zero
+	// means a limit suited to the detected filesystem, negative no limit.
+	MaxConcurrentPullFiles     int `json:"maxConcurrentPullFiles" xml:"maxConcurrentPullFiles"`
+	MaxConcurrentBlockRequests int `json:"maxConcurrentBlockRequests" xml:"maxConcurrentBlockRequests"`
+
+	// Disk I/O priority of scanning and pulling, "default" meaning the
+	// global setting
+	ScanIOPriority IOPriority `json:"scanIOPriority" xml:"scanIOPriority" default:"default"`
+	PullIOPriority IOPriority `json:"pullIOPriority" xml:"pullIOPriority" default:"default"`
+
+	// Change anomaly detection: a minute with at least the minimum number
+	// of files created, modified or deleted by local changes, and more
+	// than the factor times the usual rate, is flagged and optionally
+	// pauses the folder. A factor of zero disables detection.
+	ChangeAnomalyFactor     float64 `json:"changeAnomalyFactor" xml:"changeAnomalyFactor" default:"20"`
+	ChangeAnomalyMinChanges int     `json:"changeAnomalyMinChanges" xml:"changeAnomalyMinChanges" default:"100"`
+	ChangeAnomalyPause      bool    `json:"changeAnomalyPause" xml:"changeAnomalyPause"`
+
+	// Archive mode: deletions are neither pulled nor announced, so files
+	// only accumulate. Modifications sync as usual, and a rename arrives
+	// elsewhere as a copy under the new name.
+	ArchiveMode bool `json:"archiveMode" xml:"archiveMode"`
+
+	// Paths of other folders shared read only by this one instead of a
+	// directory of its own; see FolderViewSource
+	ViewSources []FolderViewSource `json:"viewSources" xml:"viewSource"`
+
+	// A file deleted on another device is only deleted here once at least
+	// this many other devices have our version of it, or a newer one, per
+	// their index; until then the deletion waits as a pull error. Zero
+	// disables the check.
+	MinDeleteRedundancy int `json:"minDeleteRedundancy" xml:"minDeleteRedundancy"`
+
+	// Files matching these patterns, e.g. camera RAWs or video that are
+	// never edited in place, are taken to be unchanged while their size
+	// and modification time are, and not hashed again when only other
+	// metadata changes. Up to TrustedVerifyMiB of them are hashed again
+	// per full scan, in turn, to catch any change that slipped by.
+	TrustedPatterns  []string `json:"trustedPatterns" xml:"trustedPattern"`
+	TrustedVerifyMiB int      `json:"trustedVerifyMiB" xml:"trustedVerifyMiB"`
+
+	// Other folders that must be synced before this one pulls; see
+	// FolderDependency
+	DependsOn []FolderDependency `json:"dependsOn" xml:"dependsOn" restart:"false"`
+
+	// The most data the folder may hold, in bytes, zero meaning no limit.
+	// Files that would take the folder over it aren't pulled, while
+	// deletions and files that don't grow it still are, until enough
+	// space is freed.
+	QuotaBytes int64 `json:"quotaBytes" xml:"quotaBytes"`
+
+	// Read each block back from disk after writing it while pulling, and
+	// fail the file if it doesn't match, to catch storage that silently
+	// loses or corrupts writes. Costs a sync and a read per block.
+	VerifyWrites bool `json:"verifyWrites" xml:"verifyWrites"`
+
 	// Resumable transfers
 	ResumableTransfersEnabled bool `json:"resumableTransfersEnabled" xml:"resumableTransfersEnabled" default:"true"`
 
@@ -128,7 +212,13 @@ func (f FolderConfiguration) Copy() FolderConfiguration {
 	c := f
 	c.Devices = make([]FolderDeviceConfiguration, len(f.Devices))
 	copy(c.Devices, f.Devices)
+	for i := range c.Devices {
+		c.Devices[i].OwnedPrefixes = slices.Clone(f.Devices[i].OwnedPrefixes)
+	}
 	c.Versioning = f.Versioning.Copy()
+	c.ViewSources = slices.Clone(f.ViewSources)
+	c.TrustedPatterns = slices.Clone(f.TrustedPatterns)
+	c.DependsOn = slices.Clone(f.DependsOn)
 	return c
 }
 
@@ -145,6 +235,9 @@ func (f FolderConfiguration) Filesystem(extraOpts ...fs.Option) fs.Filesystem {
 	if !f.CaseSensitiveFS {
 		opts = append(opts, new(fs.OptionDetectCaseConflicts))
 	}
+	if f.FilesystemType == FilesystemTypeBasic && f.FSWatcherEnabled && f.FSWatcherHybridPoll {
+		opts = append(opts, &fs.OptionHybridWatch{Interval: time.Duration(f.FSWatcherHybridIntervalS) * time.Second})
+	}
 	opts = append(opts, extraOpts...)
 	return fs.NewFilesystem(f.FilesystemType.ToFS(), f.Path, opts...)
 }
@@ -275,6 +368,12 @@ func (f *FolderConfiguration) CreateRoot() (err error) {
 	return err
 }
 
+// IgnoresIncomingDeletes returns whether deletions by other devices are not
+// applied to this folder.
+func (f FolderConfiguration) IgnoresIncomingDeletes() bool {
+	return f.IgnoreDelete || f.ArchiveMode
+}
+
 func (f FolderConfiguration) Description() string {
 	if f.Label == "" {
 		return f.ID
@@ -311,6 +410,9 @@ func (f *FolderConfiguration) prepare(myID protocol.DeviceID, existingDevices ma
 	slices.SortFunc(f.Devices, func(a, b FolderDeviceConfiguration) int {
 		return a.DeviceID.Compare(b.DeviceID)
 	})
+	for i := range f.Devices {
+		f.Devices[i].OwnedPrefixes = normalizeOwnedPrefixes(f.Devices[i].OwnedPrefixes)
+	}
 
 	if f.RescanIntervalS > MaxRescanIntervalS {
 		f.RescanIntervalS = MaxRescanIntervalS
@@ -325,6 +427,10 @@ func (f *FolderConfiguration) prepare(myID protocol.DeviceID, existingDevices ma
 		f.FSWatcherDelayS = 0.01
 	}
 
+	if f.FSWatcherHybridIntervalS <= 0 {
+		f.FSWatcherHybridIntervalS = 30
+	}
+
 	if f.Versioning.CleanupIntervalS > MaxRescanIntervalS {
 		f.Versioning.CleanupIntervalS = MaxRescanIntervalS
 	} else if f.Versioning.CleanupIntervalS < 0 {
@@ -341,6 +447,18 @@ func (f *FolderConfiguration) prepare(myID protocol.DeviceID, existingDevices ma
 		f.MaxConcurrentWrites = maxConcurrentWritesLimit
 	}
 
+	if f.PullWeight <= 0 {
+		f.PullWeight = 1
+	}
+
+	if f.MinDeleteRedundancy < 0 {
+		f.MinDeleteRedundancy = 0
+	}
+
+	if f.TrustedVerifyMiB < 0 {
+		f.TrustedVerifyMiB = 0
+	}
+
 	if f.Type == FolderTypeReceiveEncrypted {
 		f.IgnorePerms = true
 	}
@@ -497,6 +615,25 @@ func (f XattrFilter) GetMaxTotalSize() int {
 	return f.MaxTotalSize
 }
 
+// normalizeOwnedPrefixes cleans the ownership prefixes into the form of
+// file names in the index, with forward slashes and no leading or trailing
+// slash, dropping empty and duplicate ones.
+func normalizeOwnedPrefixes(prefixes []string) []string {
+	if len(prefixes) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		prefix = strings.Trim(path.Clean("/"+strings.ReplaceAll(prefix, "\\", "/")), "/")
+		if prefix == "" || slices.Contains(out, prefix) {
+			continue
+		}
+		out = append(out, prefix)
+	}
+	slices.Sort(out)
+	return out
+}
+
 func (f *FolderConfiguration) UnmarshalJSON(data []byte) error {
 	structutil.SetDefaults(f)
 
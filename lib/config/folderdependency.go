@@ -0,0 +1,102 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"log/slog"
+	"slices"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// A FolderDependency makes a folder wait with pulling until another folder
+// is synced far enough, for example application binaries before the data
+// they use. Only folders shared with a common device depend on each other,
+// as the order matters for what arrives from the same device.
+type FolderDependency struct {
+	Folder string `json:"folder" xml:"folder,attr"`
+	// How far the other folder must be synced, in percent; all of it when
+	// not set
+	CompletionPct float64 `json:"completionPct" xml:"completionPct,attr,omitempty"`
+}
+
+// Threshold returns the completion percentage the other folder must reach.
+func (d FolderDependency) Threshold() float64 {
+	if d.CompletionPct <= 0 || d.CompletionPct > 100 {
+		return 100
+	}
+	return d.CompletionPct
+}
+
+// prepareDependencies drops the dependencies that can never be met: on
+// unknown folders, on folders not shared with a common device, and those
+// that would make folders wait for each other in a cycle. The folders are
+// sorted by ID, so which dependency of a cycle goes is stable.
+func (cfg *Configuration) prepareDependencies(myID protocol.DeviceID) {
+	byID := make(map[string]*FolderConfiguration, len(cfg.Folders))
+	for i := range cfg.Folders {
+		byID[cfg.Folders[i].ID] = &cfg.Folders[i]
+	}
+
+	for i := range cfg.Folders {
+		folder := &cfg.Folders[i]
+		folder.DependsOn = slices.DeleteFunc(folder.DependsOn, func(dep FolderDependency) bool {
+			other, ok := byID[dep.Folder]
+			switch {
+			case !ok || other == folder:
+				slog.Warn("Skipping dependency on unknown folder", folder.LogAttr(), slog.String("dependency", dep.Folder))
+				return true
+			case !folder.sharesDeviceWith(other, myID):
+				slog.Warn("Skipping dependency on folder not shared with the same devices", folder.LogAttr(), slog.String("dependency", dep.Folder))
+				return true
+			}
+			return false
+		})
+	}
+
+	// Depth first search, dropping any dependency on a folder that is
+	// still being visited as it closes a cycle.
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(cfg.Folders))
+	var visit func(folder *FolderConfiguration)
+	visit = func(folder *FolderConfiguration) {
+		state[folder.ID] = visiting
+		folder.DependsOn = slices.DeleteFunc(folder.DependsOn, func(dep FolderDependency) bool {
+			switch state[dep.Folder] {
+			case visiting:
+				slog.Warn("Skipping dependency that would form a cycle", folder.LogAttr(), slog.String("dependency", dep.Folder))
+				return true
+			case unvisited:
+				visit(byID[dep.Folder])
+			}
+			return false
+		})
+		state[folder.ID] = visited
+	}
+	for i := range cfg.Folders {
+		if state[cfg.Folders[i].ID] == unvisited {
+			visit(&cfg.Folders[i])
+		}
+	}
+}
+
+// sharesDeviceWith returns whether the folders are shared with at least one
+// common remote device.
+func (f *FolderConfiguration) sharesDeviceWith(other *FolderConfiguration, myID protocol.DeviceID) bool {
+	for _, dev := range f.Devices {
+		if dev.DeviceID != myID && slices.ContainsFunc(other.Devices, func(o FolderDeviceConfiguration) bool {
+			return o.DeviceID == dev.DeviceID
+		}) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+// A FolderViewSource is a path of another folder that is part of a view. A
+// folder with view sources shares those paths, read only and without
+// duplicating any data, instead of a directory of its own.
+type FolderViewSource struct {
+	Folder string `json:"folder" xml:"folder,attr"`
+	Path   string `json:"path" xml:"path,attr"`
+	// Where the path appears in the view, by default the same as in the
+	// source folder
+	As string `json:"as" xml:"as,attr,omitempty"`
+}
+
+var (
+	errViewPath       = errors.New("folder with view sources can't have a path of its own")
+	errViewType       = errors.New("folder with view sources must be send only")
+	errViewSourcePath = errors.New("view source path must be relative and within its folder")
+)
+
+// ViewPath returns the slash separated path of the source in the view.
+func (s FolderViewSource) ViewPath() string {
+	p := s.As
+	if p == "" {
+		p = s.Path
+	}
+	return path.Clean(strings.Trim(filepath.ToSlash(p), "/"))
+}
+
+// sourcePath returns the cleaned, slash separated path of the source in
+// its folder, or an error if it's absolute or leads out of the folder.
+func (s FolderViewSource) sourcePath() (string, error) {
+	p := path.Clean(filepath.ToSlash(s.Path))
+	if filepath.IsAbs(s.Path) || path.IsAbs(p) || filepath.VolumeName(s.Path) != "" || p == ".." || strings.HasPrefix(p, "../") {
+		return "", fmt.Errorf("%w: %q", errViewSourcePath, s.Path)
+	}
+	return p, nil
+}
+
+// prepareViews points the folders with view sources at a view composed of
+// their sources, as send only folders. The path of such a folder is
+// derived, so it must not have one of its own.
+func (cfg *Configuration) prepareViews() error {
+	byID := make(map[string]FolderConfiguration, len(cfg.Folders))
+	for _, folder := range cfg.Folders {
+		byID[folder.ID] = folder
+	}
+	for i := range cfg.Folders {
+		folder := &cfg.Folders[i]
+		if len(folder.ViewSources) == 0 {
+			continue
+		}
+		// A path set before is the view derived the last time around.
+		if folder.Path != "" && folder.FilesystemType != FilesystemTypeView {
+			return fmt.Errorf("folder %q: %w", folder.ID, errViewPath)
+		}
+		if folder.Type != FolderTypeSendOnly {
+			return fmt.Errorf("folder %q: %w", folder.ID, errViewType)
+		}
+
+		var mounts []fs.ViewMount
+		for _, src := range folder.ViewSources {
+			srcPath, err := src.sourcePath()
+			if err != nil {
+				return fmt.Errorf("folder %q: %w", folder.ID, err)
+			}
+			srcFolder, ok := byID[src.Folder]
+			switch {
+			case !ok:
+				slog.Warn("Skipping view source of unknown folder", folder.LogAttr(), slog.String("source", src.Folder))
+				continue
+			case len(srcFolder.ViewSources) > 0, srcFolder.Type == FolderTypeReceiveEncrypted:
+				slog.Warn("Skipping view source that can't be shared in a view", folder.LogAttr(), slog.String("source", src.Folder))
+				continue
+			}
+			mounts = append(mounts, fs.ViewMount{
+				Path:   src.ViewPath(),
+				FSType: srcFolder.FilesystemType.ToFS(),
+				Root:   filepath.Join(srcFolder.Path, filepath.FromSlash(srcPath)),
+			})
+		}
+
+		marker := folder.MarkerName
+		if marker == "" {
+			marker = DefaultMarkerName
+		}
+		folder.Path = fs.ViewURI(mounts, marker)
+		folder.FilesystemType = FilesystemTypeView
+	}
+	return nil
+}
@@ -33,6 +33,9 @@ type GUIConfiguration struct {
 	InsecureSkipHostCheck     bool     `json:"insecureSkipHostcheck" xml:"insecureSkipHostcheck,omitempty"`
 	InsecureAllowFrameLoading bool     `json:"insecureAllowFrameLoading" xml:"insecureAllowFrameLoading,omitempty"`
 	SendBasicAuthPrompt       bool     `json:"sendBasicAuthPrompt" xml:"sendBasicAuthPrompt,attr"`
+	// Path of a UNIX socket serving the API as JSON-RPC, with the same
+	// API key; the socket is accessible to our user only.
+	RPCSocket string `json:"rpcSocket" xml:"rpcSocket,omitempty"`
 }
 
 func (c GUIConfiguration) IsAuthEnabled() bool {
@@ -0,0 +1,77 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+import "github.com/syncthing/syncthing/lib/osutil"
+
+// IOPriority is the disk I/O priority of scanning or pulling. The default
+// priority on a folder means to use the global setting.
+type IOPriority int32
+
+const (
+	IOPriorityDefault IOPriority = 0
+	IOPriorityNormal  IOPriority = 1
+	IOPriorityLow     IOPriority = 2
+	IOPriorityIdle    IOPriority = 3
+)
+
+func (p IOPriority) String() string {
+	switch p {
+	case IOPriorityDefault:
+		return "default"
+	case IOPriorityNormal:
+		return "normal"
+	case IOPriorityLow:
+		return "low"
+	case IOPriorityIdle:
+		return "idle"
+	default:
+		return "unknown"
+	}
+}
+
+func (p IOPriority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+func (p *IOPriority) UnmarshalText(bs []byte) error {
+	switch string(bs) {
+	case "normal":
+		*p = IOPriorityNormal
+	case "low":
+		*p = IOPriorityLow
+	case "idle":
+		*p = IOPriorityIdle
+	default:
+		*p = IOPriorityDefault
+	}
+	return nil
+}
+
+func (p *IOPriority) ParseDefault(str string) error {
+	return p.UnmarshalText([]byte(str))
+}
+
+// Or returns the priority, or the fallback if it's the default.
+func (p IOPriority) Or(fallback IOPriority) IOPriority {
+	if p == IOPriorityDefault {
+		return fallback
+	}
+	return p
+}
+
+// OSPriority returns the corresponding I/O priority hint.
+func (p IOPriority) OSPriority() osutil.IOPriority {
+	switch p {
+	case IOPriorityLow:
+		return osutil.IOPriorityLow
+	case IOPriorityIdle:
+		return osutil.IOPriorityIdle
+	default:
+		return osutil.IOPriorityNormal
+	}
+}
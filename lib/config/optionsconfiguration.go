@@ -9,8 +9,10 @@ package config
 import (
 	"fmt"
 	"net"
+	"os"
 	"runtime"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/syncthing/syncthing/lib/protocol"
@@ -19,6 +21,18 @@ import (
 	"github.com/syncthing/syncthing/lib/structutil"
 )
 
+const (
+	StatusBeaconDetailSummary = "summary"
+	StatusBeaconDetailFolders = "folders"
+	StatusBeaconDetailFull    = "full"
+)
+
+const (
+	AutoUpgradePeerCheckWarn  = "warn"
+	AutoUpgradePeerCheckDefer = "defer"
+	AutoUpgradePeerCheckOff   = "off"
+)
+
 type OptionsConfiguration struct {
 	RawListenAddresses          []string `json:"listenAddresses" xml:"listenAddress" default:"default"`
 	RawGlobalAnnServers         []string `json:"globalAnnounceServers" xml:"globalAnnounceServer" default:"default"`
@@ -26,11 +40,13 @@ type OptionsConfiguration struct {
 	LocalAnnEnabled             bool     `json:"localAnnounceEnabled" xml:"localAnnounceEnabled" default:"true"`
 	LocalAnnPort                int      `json:"localAnnouncePort" xml:"localAnnouncePort" default:"21027"`
 	LocalAnnMCAddr              string   `json:"localAnnounceMCAddr" xml:"localAnnounceMCAddr" default:"[ff12::8384]:21027"`
+	LocalAnnMDNSEnabled         bool     `json:"localAnnounceMDNSEnabled" xml:"localAnnounceMDNSEnabled"`
 	MaxSendKbps                 int      `json:"maxSendKbps" xml:"maxSendKbps"`
 	MaxRecvKbps                 int      `json:"maxRecvKbps" xml:"maxRecvKbps"`
 	ReconnectIntervalS          int      `json:"reconnectionIntervalS" xml:"reconnectionIntervalS" default:"60"`
 	RelaysEnabled               bool     `json:"relaysEnabled" xml:"relaysEnabled" default:"true"`
 	RelayReconnectIntervalM     int      `json:"relayReconnectIntervalM" xml:"relayReconnectIntervalM" default:"10"`
+	RelaySpeedTestEnabled       bool     `json:"relaySpeedTestEnabled" xml:"relaySpeedTestEnabled"`
 	StartBrowser                bool     `json:"startBrowser" xml:"startBrowser" default:"true"`
 	NATEnabled                  bool     `json:"natEnabled" xml:"natEnabled" default:"true"`
 	NATLeaseM                   int      `json:"natLeaseMinutes" xml:"natLeaseMinutes" default:"60"`
@@ -80,6 +96,7 @@ type OptionsConfiguration struct {
 	ConnectionPriorityQUICLAN          int `json:"connectionPriorityQuicLan" xml:"connectionPriorityQuicLan" default:"20"`
 	ConnectionPriorityTCPWAN           int `json:"connectionPriorityTcpWan" xml:"connectionPriorityTcpWan" default:"30"`
 	ConnectionPriorityQUICWAN          int `json:"connectionPriorityQuicWan" xml:"connectionPriorityQuicWan" default:"40"`
+	ConnectionPriorityWebSocket        int `json:"connectionPriorityWebSocket" xml:"connectionPriorityWebSocket" default:"45"`
 	ConnectionPriorityRelay            int `json:"connectionPriorityRelay" xml:"connectionPriorityRelay" default:"50"`
 	ConnectionPriorityUpgradeThreshold int `json:"connectionPriorityUpgradeThreshold" xml:"connectionPriorityUpgradeThreshold" default:"0"`
 
@@ -88,8 +105,14 @@ type OptionsConfiguration struct {
 	AdaptiveKeepAliveMinS    int  `json:"adaptiveKeepAliveMinS" xml:"adaptiveKeepAliveMinS" default:"10"`
 	AdaptiveKeepAliveMaxS    int  `json:"adaptiveKeepAliveMaxS" xml:"adaptiveKeepAliveMaxS" default:"60"`
 
-	// Multipath settings
+	// Multipath settings. With multipath enabled, block requests are
+	// striped over all connections to a device by their delivery rate.
 	MultipathEnabled bool `json:"multipathEnabled" xml:"multipathEnabled" default:"false"`
+	// Keep our block requests, and so the responses carrying the data,
+	// off the primary connection to a device, which then carries index
+	// and control traffic. What the other device sends on its own, such
+	// as its index updates and requests, follows its own setting.
+	MultipathTrafficAffinity bool `json:"multipathTrafficAffinity" xml:"multipathTrafficAffinity" default:"false"`
 
 	// Folder priority settings
 	FolderSyncStrategy string `json:"folderSyncStrategy" xml:"folderSyncStrategy" default:"random"`
@@ -112,11 +135,178 @@ type OptionsConfiguration struct {
 	ProtocolFallbackThreshold int      `json:"protocolFallbackThreshold" xml:"protocolFallbackThreshold" default:"3"`
 	PreferredProtocols        []string `json:"preferredProtocols" xml:"preferredProtocol" default:"quic,tcp,relay"`
 
+	// Weights of the metrics connections to a device are scored by when
+	// choosing which to keep. Devices may override them.
+	ConnectionWeightLatency   int `json:"connectionWeightLatency" xml:"connectionWeightLatency" default:"25"`
+	ConnectionWeightStability int `json:"connectionWeightStability" xml:"connectionWeightStability" default:"25"`
+	ConnectionWeightBandwidth int `json:"connectionWeightBandwidth" xml:"connectionWeightBandwidth" default:"25"`
+	ConnectionWeightPriority  int `json:"connectionWeightPriority" xml:"connectionWeightPriority" default:"25"`
+	ConnectionWeightLAN       int `json:"connectionWeightLAN" xml:"connectionWeightLAN" default:"0"`
+
 	// Connection replacement thresholds
 	ConnectionReplacementAgeThreshold      int `json:"connectionReplacementAgeThreshold" xml:"connectionReplacementAgeThreshold" default:"30"`           // seconds
 	ConnectionReplacementActivityThreshold int `json:"connectionReplacementActivityThreshold" xml:"connectionReplacementActivityThreshold" default:"60"` // seconds
 	ConnectionReplacementPriorityThreshold int `json:"connectionReplacementPriorityThreshold" xml:"connectionReplacementPriorityThreshold" default:"10"` // priority points
 
+	// Sync statistics digest: per folder and device summaries for each
+	// "daily" or "weekly" period, posted as JSON to the webhook URL and/or
+	// appended to the report file. Empty period means disabled.
+	StatsDigestPeriod     string `json:"statsDigestPeriod" xml:"statsDigestPeriod"`
+	StatsDigestWebhookURL string `json:"statsDigestWebhookURL" xml:"statsDigestWebhookURL"`
+	StatsDigestFile       string `json:"statsDigestFile" xml:"statsDigestFile"`
+
+	// Reject peers whose certificate is expired or not yet valid, for all
+	// devices. See also the per device setting.
+	StrictCertificateExpiry bool `json:"strictCertificateExpiry" xml:"strictCertificateExpiry"`
+
+	// Generate a new device certificate this many days before the current
+	// one expires. Zero disables rotation. As the device ID follows from
+	// the certificate, the new one comes with a new device ID, taken on
+	// by a restart, which other devices need to accept anew. The previous
+	// certificate and key are kept with an ".old" suffix.
+	CertificateRotationDays int `json:"certificateRotationDays" xml:"certificateRotationDays" restart:"true"`
+
+	// Devices sending more than this percentage of blocks that fail hash
+	// verification are flagged and only used as a last resort when
+	// pulling. Zero disables flagging.
+	BlockCorruptionThresholdPct int `json:"blockCorruptionThresholdPct" xml:"blockCorruptionThresholdPct" default:"1"`
+
+	// Verify blocks served to other devices against the local index, so
+	// that data gone bad on disk is not handed out. A file found corrupt
+	// is withdrawn from the index, to be replaced from other devices.
+	VerifyServedBlocks bool `json:"verifyServedBlocks" xml:"verifyServedBlocks"`
+
+	// Bind outgoing TCP and QUIC connections to this source IP address
+	// and/or network interface. Can be overridden per device.
+	DialSourceAddress string `json:"dialSourceAddress" xml:"dialSourceAddress"`
+	DialInterface     string `json:"dialInterface" xml:"dialInterface"`
+
+	// Status beacon: a signed health summary posted periodically to the
+	// collection URL, for monitoring many headless devices. The detail
+	// level is "summary" (totals only), "folders" (per folder, by ID) or
+	// "full" (adding folder labels and the device name). Empty URL means
+	// disabled.
+	StatusBeaconURL       string `json:"statusBeaconURL" xml:"statusBeaconURL"`
+	StatusBeaconIntervalS int    `json:"statusBeaconIntervalS" xml:"statusBeaconIntervalS" default:"300"`
+	StatusBeaconDetail    string `json:"statusBeaconDetail" xml:"statusBeaconDetail" default:"summary"`
+
+	// Outstanding block requests to each device, shared between folders
+	// according to their pull weights. Zero means the default, negative
+	// disables the fair sharing.
+	RawMaxPullPendingPerDeviceKiB int `json:"maxPullPendingPerDeviceKiB" xml:"maxPullPendingPerDeviceKiB"`
+
+	// Disk I/O priority of scanning (hashing) and pulling, unless set per
+	// folder: "normal", "low" or "idle". Applied as I/O scheduling hints
+	// where the OS supports them, and by throttling elsewhere.
+	ScanIOPriority IOPriority `json:"scanIOPriority" xml:"scanIOPriority" default:"normal"`
+	PullIOPriority IOPriority `json:"pullIOPriority" xml:"pullIOPriority" default:"normal"`
+
+	// Global limits on folders scanning, folders doing their initial sync
+	// and hasher routines across all scanning folders, within the overall
+	// maxFolderConcurrency. Folders wait their turn in order. Zero or less
+	// means no limit of its own.
+	MaxScanConcurrency        int `json:"maxScanConcurrency" xml:"maxScanConcurrency"`
+	MaxInitialSyncConcurrency int `json:"maxInitialSyncConcurrency" xml:"maxInitialSyncConcurrency"`
+	MaxHashers                int `json:"maxHashers" xml:"maxHashers"`
+
+	// Octal file mode of the sockets of "unix://" listen addresses, such
+	// as "0660" to let a group of local users connect. Empty leaves the
+	// mode to the umask.
+	RawUnixListenerPermissions string `json:"unixListenerPermissions" xml:"unixListenerPermissions,omitempty"`
+
+	// Rules for "demux://" listen addresses, which share a port such as 443
+	// with a web server. TLS connections asking for no server name or one
+	// of DemuxHostnames, HTTP CONNECT requests for one of DemuxHostnames
+	// and WebSocket upgrades on DemuxWebSocketPath are taken as BEP.
+	// Anything else is passed on to DemuxFallbackAddress, or closed when
+	// that is empty.
+	DemuxHostnames       []string `json:"demuxHostnames" xml:"demuxHostname"`
+	DemuxWebSocketPath   string   `json:"demuxWebSocketPath" xml:"demuxWebSocketPath" default:"/syncthing"`
+	DemuxFallbackAddress string   `json:"demuxFallbackAddress" xml:"demuxFallbackAddress"`
+
+	// Folders look for leftover temporary files at this interval, zero
+	// (the default) disabling it. Temporary files belonging to no needed
+	// file are orphans and kept for KeepOrphanTemporariesH; the others for
+	// KeepTemporariesH.
+	TempCleanupIntervalS   int `json:"tempCleanupIntervalS" xml:"tempCleanupIntervalS" default:"0"`
+	KeepOrphanTemporariesH int `json:"keepOrphanTemporariesH" xml:"keepOrphanTemporariesH" default:"1"`
+
+	// The index of a removed folder is kept on standby for this long, zero
+	// dropping it right away. When the folder is added again with the same
+	// ID and path, and its marker still in place, the index is reused and
+	// the folder only rescanned for changes instead of hashed anew.
+	KeepRemovedFolderIndexH int `json:"keepRemovedFolderIndexH" xml:"keepRemovedFolderIndexH" default:"24"`
+
+	// Interval at which the global discovery servers and relay pools in
+	// use are probed for reachability and the relay lists refreshed, zero
+	// disabling it.
+	InfraProbeIntervalS int `json:"infraProbeIntervalS" xml:"infraProbeIntervalS" default:"600"`
+
+	// Offer zstd compression to other devices, used instead of LZ4 with
+	// those that support it too, at the given level (1-22, higher
+	// compressing better but slower).
+	ZstdCompression      bool `json:"zstdCompression" xml:"zstdCompression"`
+	ZstdCompressionLevel int  `json:"zstdCompressionLevel" xml:"zstdCompressionLevel" default:"3"`
+
+	// Device anomalies, signs that a known device may be impersonated, are
+	// raised as events and posted as JSON to the webhook URL, if set. A
+	// device coming back after at least DeviceAbsenceAnomalyDays (zero
+	// disabling the check) with another client or client version is one.
+	SecurityWebhookURL       string `json:"securityWebhookURL" xml:"securityWebhookURL"`
+	DeviceAbsenceAnomalyDays int    `json:"deviceAbsenceAnomalyDays" xml:"deviceAbsenceAnomalyDays" default:"30"`
+
+	// Rate limits per class of connection (LAN, WAN, relay, metered), on
+	// top of the overall and device limits. Connections from a local
+	// address in one of the metered networks are metered.
+	BandwidthClasses []BandwidthClassConfiguration `json:"bandwidthClasses" xml:"bandwidthClass"`
+	MeteredNetworks  []string                      `json:"meteredNetworks" xml:"meteredNetwork"`
+
+	// Operate on an IPv6 only network: IPv4 listeners and local discovery
+	// broadcasts are skipped, and IPv4 addresses dialed through NAT64 when
+	// a prefix is found using DNS64 (RFC 7050), or else not at all.
+	IPv6Only bool `json:"ipv6Only" xml:"ipv6Only"`
+
+	// Spread reconnecting after an outage over time, rather than having
+	// the whole cluster dial and announce at once: the first dials after
+	// startup and the redials of lost connections are delayed by up to
+	// DialJitterS, the first global announcement by up to AnnounceJitterS,
+	// and the intervals after vary somewhat. Delays are seeded by the
+	// device ID, so they differ between devices but not between restarts.
+	DialJitterS     int `json:"dialJitterS" xml:"dialJitterS" default:"10"`
+	AnnounceJitterS int `json:"announceJitterS" xml:"announceJitterS" default:"60"`
+
+	// How dialing and talking to discovery servers back off after
+	// failures, overriding the defaults per subsystem.
+	RetryPolicies []RetryPolicyConfiguration `json:"retryPolicies" xml:"retryPolicy"`
+
+	// What automatic upgrades do about a release that devices we sync
+	// with would be incompatible with, going by the client they last
+	// connected with: "warn" and upgrade, "defer" the upgrade until they
+	// are compatible, or nothing when "off".
+	AutoUpgradePeerCheck string `json:"autoUpgradePeerCheck" xml:"autoUpgradePeerCheck" default:"warn"`
+
+	// A reference profile of the configuration for managed deployments,
+	// a JSON file signed with the ECDSA key whose public part, in PEM
+	// format, is in ReferenceProfileKey; the signature is in the same
+	// place as the profile, with ".sig" appended. The configuration is
+	// compared to the profile every ReferenceProfileCheckIntervalS and on
+	// every change, and drift from it is reported, or undone with
+	// ReferenceProfileAutoCorrect.
+	ReferenceProfile               string `json:"referenceProfile" xml:"referenceProfile"`
+	ReferenceProfileKey            string `json:"referenceProfileKey" xml:"referenceProfileKey"`
+	ReferenceProfileAutoCorrect    bool   `json:"referenceProfileAutoCorrect" xml:"referenceProfileAutoCorrect"`
+	ReferenceProfileCheckIntervalS int    `json:"referenceProfileCheckIntervalS" xml:"referenceProfileCheckIntervalS" default:"300"`
+
+	// Send authenticated UDP heartbeats to the port LANHeartbeatPort of
+	// devices connected over the LAN, every LANHeartbeatIntervalMs. A LAN
+	// connection to a device that had been sending heartbeats is closed
+	// once three intervals pass without one, rather than waiting for the
+	// connection to time out, and heartbeats from a device not connected
+	// make us dial it right away. Both devices need this enabled.
+	LANHeartbeatEnabled    bool `json:"lanHeartbeatEnabled" xml:"lanHeartbeatEnabled"`
+	LANHeartbeatPort       int  `json:"lanHeartbeatPort" xml:"lanHeartbeatPort" default:"21028"`
+	LANHeartbeatIntervalMs int  `json:"lanHeartbeatIntervalMs" xml:"lanHeartbeatIntervalMs" default:"250"`
+
 	// Legacy deprecated
 	DeprecatedUPnPEnabled        bool     `json:"-" xml:"upnpEnabled,omitempty"`        // Deprecated: Do not use.
 	DeprecatedUPnPLeaseM         int      `json:"-" xml:"upnpLeaseMinutes,omitempty"`   // Deprecated: Do not use.
@@ -137,6 +327,10 @@ func (opts OptionsConfiguration) Copy() OptionsConfiguration {
 	copy(optsCopy.AlwaysLocalNets, opts.AlwaysLocalNets)
 	optsCopy.UnackedNotificationIDs = make([]string, len(opts.UnackedNotificationIDs))
 	copy(optsCopy.UnackedNotificationIDs, opts.UnackedNotificationIDs)
+	optsCopy.DemuxHostnames = slices.Clone(opts.DemuxHostnames)
+	optsCopy.BandwidthClasses = slices.Clone(opts.BandwidthClasses)
+	optsCopy.MeteredNetworks = slices.Clone(opts.MeteredNetworks)
+	optsCopy.RetryPolicies = slices.Clone(opts.RetryPolicies)
 	return optsCopy
 }
 
@@ -145,6 +339,15 @@ func (opts *OptionsConfiguration) prepare(guiPWIsSet bool) {
 
 	opts.RawListenAddresses = stringutil.UniqueTrimmedStrings(opts.RawListenAddresses)
 	opts.RawGlobalAnnServers = stringutil.UniqueTrimmedStrings(opts.RawGlobalAnnServers)
+	opts.DemuxHostnames = stringutil.UniqueTrimmedStrings(opts.DemuxHostnames)
+	opts.BandwidthClasses = slices.DeleteFunc(opts.BandwidthClasses, func(c BandwidthClassConfiguration) bool {
+		return !slices.Contains(BandwidthClasses, c.Class)
+	})
+	opts.RetryPolicies = slices.DeleteFunc(opts.RetryPolicies, func(p RetryPolicyConfiguration) bool {
+		return !slices.Contains(RetrySubsystems, p.Subsystem)
+	})
+
+	opts.DialSourceAddress, opts.DialInterface = prepareDialSource(opts.DialSourceAddress, opts.DialInterface)
 
 	// Very short reconnection intervals are annoying
 	if opts.ReconnectIntervalS < 5 {
@@ -175,6 +378,15 @@ func (opts *OptionsConfiguration) prepare(guiPWIsSet bool) {
 		opts.ConnectionPriorityTCPWAN = opts.ConnectionPriorityTCPLAN + 1
 	}
 
+	if opts.ZstdCompressionLevel < 1 || opts.ZstdCompressionLevel > 22 {
+		opts.ZstdCompressionLevel = protocol.DefaultZstdLevel
+	}
+
+	// Heartbeats any more often are just noise on the network.
+	if opts.LANHeartbeatIntervalMs < 50 {
+		opts.LANHeartbeatIntervalMs = 50
+	}
+
 	// Ensure connection stability settings are within valid ranges
 	if opts.ConnectionStabilityMinScore < 0 {
 		opts.ConnectionStabilityMinScore = 0
@@ -219,11 +431,27 @@ func (opts *OptionsConfiguration) prepare(guiPWIsSet bool) {
 		opts.ConnectionReplacementPriorityThreshold = 50
 	}
 
+	prepareConnectionWeights(&opts.ConnectionWeightLatency, &opts.ConnectionWeightStability, &opts.ConnectionWeightBandwidth, &opts.ConnectionWeightPriority, &opts.ConnectionWeightLAN)
+
 	// Set default preferred protocols if none specified
 	if len(opts.PreferredProtocols) == 0 {
 		opts.PreferredProtocols = []string{"quic", "tcp", "relay"}
 	}
 
+	if opts.StatusBeaconIntervalS < 60 {
+		opts.StatusBeaconIntervalS = 60
+	}
+	switch opts.StatusBeaconDetail {
+	case StatusBeaconDetailSummary, StatusBeaconDetailFolders, StatusBeaconDetailFull:
+	default:
+		opts.StatusBeaconDetail = StatusBeaconDetailSummary
+	}
+	switch opts.AutoUpgradePeerCheck {
+	case AutoUpgradePeerCheckWarn, AutoUpgradePeerCheckDefer, AutoUpgradePeerCheckOff:
+	default:
+		opts.AutoUpgradePeerCheck = AutoUpgradePeerCheckWarn
+	}
+
 	// If usage reporting is enabled we must have a unique ID.
 	if opts.URAccepted > 0 && opts.URUniqueID == "" {
 		opts.URUniqueID = rand.String(8)
@@ -261,6 +489,17 @@ func (opts OptionsConfiguration) ListenAddresses() []string {
 	return stringutil.UniqueTrimmedStrings(addresses)
 }
 
+// UnixListenerPermissions returns the file mode for the sockets of UNIX
+// domain socket listeners, or zero to leave it as is.
+func (opts OptionsConfiguration) UnixListenerPermissions() os.FileMode {
+	perm, err := strconv.ParseUint(opts.RawUnixListenerPermissions, 8, 32)
+	if err != nil {
+		// ignore incorrectly formatted permissions
+		return 0
+	}
+	return os.FileMode(perm) & os.ModePerm
+}
+
 func (opts OptionsConfiguration) StunServers() []string {
 	var addresses []string
 	for _, addr := range opts.RawStunServers {
@@ -353,6 +592,26 @@ func (opts OptionsConfiguration) MaxConcurrentIncomingRequestKiB() int {
 	return opts.RawMaxCIRequestKiB
 }
 
+func (opts OptionsConfiguration) MaxPullPendingPerDeviceKiB() int {
+	// Negative is disabled, which in limiter land is spelled zero
+	if opts.RawMaxPullPendingPerDeviceKiB < 0 {
+		return 0
+	}
+
+	if opts.RawMaxPullPendingPerDeviceKiB == 0 {
+		// The default is 64 MiB, twice the default per folder
+		return 64 * 1024 // KiB
+	}
+
+	// As for incoming requests, allow at least a couple of blocks.
+	const minAllowed = 2 * protocol.MaxBlockSize / 1024
+	if opts.RawMaxPullPendingPerDeviceKiB < minAllowed {
+		return minAllowed
+	}
+
+	return opts.RawMaxPullPendingPerDeviceKiB
+}
+
 func (opts OptionsConfiguration) AutoUpgradeEnabled() bool {
 	return opts.AutoUpgradeIntervalH > 0
 }
@@ -0,0 +1,32 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+// Subsystems whose retry policy can be configured: dialing devices, and
+// lookups and announcements to global discovery servers.
+const (
+	RetrySubsystemDialing      = "dialing"
+	RetrySubsystemLookups      = "lookups"
+	RetrySubsystemAnnouncement = "announcement"
+)
+
+// RetrySubsystems lists the subsystems whose retry policy can be configured.
+var RetrySubsystems = []string{RetrySubsystemDialing, RetrySubsystemLookups, RetrySubsystemAnnouncement}
+
+// A RetryPolicyConfiguration overrides how a subsystem backs off after
+// failures. Fields left at zero keep the default of the subsystem.
+type RetryPolicyConfiguration struct {
+	Subsystem   string  `json:"subsystem" xml:"subsystem,attr"`
+	BaseDelayMs int     `json:"baseDelayMs" xml:"baseDelayMs"`
+	MaxDelayS   int     `json:"maxDelayS" xml:"maxDelayS"`
+	Factor      float64 `json:"factor" xml:"factor"`
+	// Random variation of the delays, in percent of them
+	JitterPct int `json:"jitterPct" xml:"jitterPct"`
+	// Stop trying for CoolDownS after BreakAfter failures in a row
+	BreakAfter int `json:"breakAfter" xml:"breakAfter"`
+	CoolDownS  int `json:"coolDownS" xml:"coolDownS"`
+}
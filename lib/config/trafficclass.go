@@ -0,0 +1,18 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package config
+
+// Traffic classes of devices. Where the overall rate limit is reached,
+// the bandwidth is shared between the devices waiting for it by weight of
+// their class, so that background devices mostly get what's left over.
+const (
+	TrafficClassNormal     = "normal"
+	TrafficClassBackground = "background"
+)
+
+// TrafficClasses lists the traffic classes of devices.
+var TrafficClasses = []string{TrafficClassNormal, TrafficClassBackground}
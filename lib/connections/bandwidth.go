@@ -0,0 +1,298 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// What a direction of a connection is held back by.
+const (
+	BandwidthIdle    = "idle"    // little or no data moving
+	BandwidthLink    = "link"    // the network takes data no faster
+	BandwidthLimiter = "limiter" // the configured rate limits
+	BandwidthPeer    = "peer"    // waiting for the other device
+	BandwidthLocal   = "local"   // this device processes data no faster
+)
+
+const (
+	bandwidthSampleInterval = 5 * time.Second
+	// Less than this rate counts as idle.
+	bandwidthIdleBps = 1024
+	// A direction is limited by something when it spends more than this
+	// fraction of the interval waiting for it.
+	bandwidthLimitedFraction = 0.5
+	// Link estimates from writes are only taken when writes blocked for at
+	// least this fraction of the interval; shorter writes just copy into
+	// the socket buffer.
+	bandwidthBlockedFraction = 0.1
+	// Weight of a new sample in the link estimate, and how much of the
+	// receive peak is kept per interval.
+	bandwidthEWMAWeight = 0.3
+	bandwidthPeakDecay  = 0.95
+	// Estimates changing by more than this fraction cause an event.
+	bandwidthChangeFraction = 0.25
+)
+
+// BandwidthEstimate describes how fast a connection moves data in each
+// direction, how fast the network could move it, and what it's limited by.
+type BandwidthEstimate struct {
+	At      time.Time         `json:"at"`
+	Send    DirectionEstimate `json:"send"`
+	Receive DirectionEstimate `json:"receive"`
+}
+
+type DirectionEstimate struct {
+	ThroughputBps int64  `json:"throughputBps"` // over the last sample interval
+	AvailableBps  int64  `json:"availableBps"`  // estimated network capacity, zero if not known yet
+	LimitedBy     string `json:"limitedBy"`
+}
+
+// A bandwidthEstimator times reads and writes on both sides of the rate
+// limiters. Time spent in writes on the connection is the network pushing
+// back: on TCP the socket buffer being full, on QUIC the flow control and
+// congestion window pacing the stream. Time spent in the limited reader
+// and writer beyond that is the rate limiters.
+type bandwidthEstimator struct {
+	sendBytes      atomic.Int64
+	sendConnNanos  atomic.Int64 // in writes on the connection
+	sendTotalNanos atomic.Int64 // in writes including rate limiting
+	recvBytes      atomic.Int64
+	recvConnNanos  atomic.Int64 // in reads on the connection
+	recvTotalNanos atomic.Int64 // in reads including rate limiting
+
+	mut       sync.Mutex
+	last      time.Time
+	sendAvail float64 // bytes/s, moving average of blocked write rates
+	recvAvail float64 // bytes/s, decaying peak of the receive rate
+	cur       BandwidthEstimate
+}
+
+func newBandwidthEstimator(now time.Time) *bandwidthEstimator {
+	return &bandwidthEstimator{last: now, cur: BandwidthEstimate{
+		At:      now,
+		Send:    DirectionEstimate{LimitedBy: BandwidthIdle},
+		Receive: DirectionEstimate{LimitedBy: BandwidthIdle},
+	}}
+}
+
+// wrapConn returns a ReadWriter timing the reads and writes on the
+// connection itself, to be wrapped by the rate limiters.
+func (b *bandwidthEstimator) wrapConn(rw io.ReadWriter) io.ReadWriter {
+	return struct {
+		io.Reader
+		io.Writer
+	}{
+		&timedReader{rw, &b.recvConnNanos, &b.recvBytes},
+		&timedWriter{rw, &b.sendConnNanos, &b.sendBytes},
+	}
+}
+
+// wrapLimited returns the rate limited reader and writer, timed.
+func (b *bandwidthEstimator) wrapLimited(rd io.Reader, wr io.Writer) (io.Reader, io.Writer) {
+	return &timedReader{rd, &b.recvTotalNanos, nil}, &timedWriter{wr, &b.sendTotalNanos, nil}
+}
+
+// sample updates the estimate with what happened since the last sample.
+// It returns the new estimate, and whether it changed enough to be worth
+// telling about.
+func (b *bandwidthEstimator) sample(now time.Time) (BandwidthEstimate, bool) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+
+	secs := now.Sub(b.last).Seconds()
+	if secs <= 0 {
+		return b.cur, false
+	}
+	b.last = now
+
+	sendBytes := float64(b.sendBytes.Swap(0))
+	sendConn := time.Duration(b.sendConnNanos.Swap(0)).Seconds()
+	sendTotal := time.Duration(b.sendTotalNanos.Swap(0)).Seconds()
+	recvBytes := float64(b.recvBytes.Swap(0))
+	recvConn := time.Duration(b.recvConnNanos.Swap(0)).Seconds()
+	recvTotal := time.Duration(b.recvTotalNanos.Swap(0)).Seconds()
+
+	prev := b.cur
+	next := BandwidthEstimate{At: now}
+
+	// Sending
+	sendRate := sendBytes / secs
+	if sendConn/secs >= bandwidthBlockedFraction {
+		rate := sendBytes / sendConn
+		if b.sendAvail == 0 {
+			b.sendAvail = rate
+		} else {
+			b.sendAvail += bandwidthEWMAWeight * (rate - b.sendAvail)
+		}
+	}
+	b.sendAvail = max(b.sendAvail, sendRate)
+	next.Send.ThroughputBps = int64(sendRate)
+	next.Send.AvailableBps = int64(b.sendAvail)
+	switch {
+	case sendRate < bandwidthIdleBps:
+		next.Send.LimitedBy = BandwidthIdle
+	case (sendTotal-sendConn)/secs >= bandwidthLimitedFraction:
+		next.Send.LimitedBy = BandwidthLimiter
+	case sendConn/secs >= bandwidthLimitedFraction:
+		next.Send.LimitedBy = BandwidthLink
+	default:
+		// We sent all we had; the peer isn't asking for more.
+		next.Send.LimitedBy = BandwidthPeer
+	}
+
+	// Receiving. We can't see the network pushing back on the other side,
+	// so the estimate is the recent peak rate.
+	recvRate := recvBytes / secs
+	b.recvAvail = max(b.recvAvail*bandwidthPeakDecay, recvRate)
+	next.Receive.ThroughputBps = int64(recvRate)
+	next.Receive.AvailableBps = int64(b.recvAvail)
+	switch {
+	case recvRate < bandwidthIdleBps:
+		next.Receive.LimitedBy = BandwidthIdle
+	case (recvTotal-recvConn)/secs >= bandwidthLimitedFraction:
+		next.Receive.LimitedBy = BandwidthLimiter
+	case recvConn/secs < bandwidthLimitedFraction:
+		// Data was waiting whenever we read.
+		next.Receive.LimitedBy = BandwidthLocal
+	case recvRate >= (1-bandwidthChangeFraction)*b.recvAvail:
+		next.Receive.LimitedBy = BandwidthLink
+	default:
+		next.Receive.LimitedBy = BandwidthPeer
+	}
+
+	b.cur = next
+	changed := next.Send.LimitedBy != prev.Send.LimitedBy ||
+		next.Receive.LimitedBy != prev.Receive.LimitedBy ||
+		changedMuch(next.Send.AvailableBps, prev.Send.AvailableBps) ||
+		changedMuch(next.Receive.AvailableBps, prev.Receive.AvailableBps)
+	return next, changed
+}
+
+func (b *bandwidthEstimator) estimate() BandwidthEstimate {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	return b.cur
+}
+
+func changedMuch(cur, prev int64) bool {
+	diff := cur - prev
+	if diff < 0 {
+		diff = -diff
+	}
+	return float64(diff) > bandwidthChangeFraction*float64(max(cur, prev))
+}
+
+// timedReader adds the time spent in reads, and optionally the bytes
+// read, to the counters.
+type timedReader struct {
+	r     io.Reader
+	nanos *atomic.Int64
+	bytes *atomic.Int64 // may be nil
+}
+
+func (t *timedReader) Read(buf []byte) (int, error) {
+	t0 := time.Now()
+	n, err := t.r.Read(buf)
+	t.nanos.Add(int64(time.Since(t0)))
+	if t.bytes != nil {
+		t.bytes.Add(int64(n))
+	}
+	return n, err
+}
+
+type timedWriter struct {
+	w     io.Writer
+	nanos *atomic.Int64
+	bytes *atomic.Int64 // may be nil
+}
+
+func (t *timedWriter) Write(buf []byte) (int, error) {
+	t0 := time.Now()
+	n, err := t.w.Write(buf)
+	t.nanos.Add(int64(time.Since(t0)))
+	if t.bytes != nil {
+		t.bytes.Add(int64(n))
+	}
+	return n, err
+}
+
+// bandwidthEstimators keeps the estimator of each connection, by
+// connection ID.
+type bandwidthEstimators struct {
+	mut        sync.Mutex
+	estimators map[string]*bandwidthEstimator
+	devices    map[string]protocol.DeviceID
+}
+
+func newBandwidthEstimators() *bandwidthEstimators {
+	return &bandwidthEstimators{
+		estimators: make(map[string]*bandwidthEstimator),
+		devices:    make(map[string]protocol.DeviceID),
+	}
+}
+
+func (e *bandwidthEstimators) add(device protocol.DeviceID, connID string) *bandwidthEstimator {
+	b := newBandwidthEstimator(time.Now())
+	e.mut.Lock()
+	e.estimators[connID] = b
+	e.devices[connID] = device
+	e.mut.Unlock()
+	return b
+}
+
+func (e *bandwidthEstimators) remove(connID string) {
+	e.mut.Lock()
+	delete(e.estimators, connID)
+	delete(e.devices, connID)
+	e.mut.Unlock()
+}
+
+func (e *bandwidthEstimators) estimates() map[string]BandwidthEstimate {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+	res := make(map[string]BandwidthEstimate, len(e.estimators))
+	for id, b := range e.estimators {
+		res[id] = b.estimate()
+	}
+	return res
+}
+
+// serve samples all connections periodically, sending an event for those
+// where the estimate or the limiting factor changed.
+func (e *bandwidthEstimators) serve(ctx context.Context, evLogger events.Logger) error {
+	ticker := time.NewTicker(bandwidthSampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			e.mut.Lock()
+			for id, b := range e.estimators {
+				est, changed := b.sample(now)
+				if !changed {
+					continue
+				}
+				evLogger.Log(events.ConnectionBandwidthChanged, map[string]interface{}{
+					"device":       e.devices[id].String(),
+					"connectionID": id,
+					"send":         est.Send,
+					"receive":      est.Receive,
+				})
+			}
+			e.mut.Unlock()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
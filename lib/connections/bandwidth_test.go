@@ -0,0 +1,118 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestBandwidthEstimatorSend(t *testing.T) {
+	t0 := time.Now()
+	b := newBandwidthEstimator(t0)
+
+	cases := []struct {
+		bytes       int64
+		conn, total time.Duration
+		limitedBy   string
+	}{
+		{0, 0, 0, BandwidthIdle},
+		{10 << 20, time.Second / 10, 4 * time.Second, BandwidthLimiter},
+		{10 << 20, 4 * time.Second, 4 * time.Second, BandwidthLink},
+		{10 << 20, time.Second / 100, time.Second / 100, BandwidthPeer},
+	}
+	for i, tc := range cases {
+		b.sendBytes.Store(tc.bytes)
+		b.sendConnNanos.Store(int64(tc.conn))
+		b.sendTotalNanos.Store(int64(tc.total))
+		est, _ := b.sample(t0.Add(time.Duration(i+1) * bandwidthSampleInterval))
+		if est.Send.LimitedBy != tc.limitedBy {
+			t.Errorf("case %d: limited by %q, expected %q", i, est.Send.LimitedBy, tc.limitedBy)
+		}
+		if est.Send.ThroughputBps != tc.bytes/int64(bandwidthSampleInterval/time.Second) {
+			t.Errorf("case %d: unexpected throughput %d", i, est.Send.ThroughputBps)
+		}
+	}
+
+	// The rate limited interval gives a lower bound of 2 MiB/s, the link
+	// limited one moves the estimate towards 2.5 MiB/s, and the peer
+	// limited one doesn't lower it.
+	est := b.estimate()
+	lower, link := float64(2<<20), float64(5<<19)
+	if exp := int64(lower + bandwidthEWMAWeight*(link-lower)); est.Send.AvailableBps != exp {
+		t.Errorf("available bandwidth %d, expected %d", est.Send.AvailableBps, exp)
+	}
+}
+
+func TestBandwidthEstimatorReceive(t *testing.T) {
+	t0 := time.Now()
+	b := newBandwidthEstimator(t0)
+
+	cases := []struct {
+		bytes       int64
+		conn, total time.Duration
+		limitedBy   string
+	}{
+		{10 << 20, 4 * time.Second, 4 * time.Second, BandwidthLink},
+		{10 << 20, time.Second, time.Second, BandwidthLocal},
+		{10 << 20, time.Second, 4 * time.Second, BandwidthLimiter},
+		{1 << 20, 4 * time.Second, 4 * time.Second, BandwidthPeer},
+		{0, 5 * time.Second, 5 * time.Second, BandwidthIdle},
+	}
+	for i, tc := range cases {
+		b.recvBytes.Store(tc.bytes)
+		b.recvConnNanos.Store(int64(tc.conn))
+		b.recvTotalNanos.Store(int64(tc.total))
+		est, _ := b.sample(t0.Add(time.Duration(i+1) * bandwidthSampleInterval))
+		if est.Receive.LimitedBy != tc.limitedBy {
+			t.Errorf("case %d: limited by %q, expected %q", i, est.Receive.LimitedBy, tc.limitedBy)
+		}
+	}
+}
+
+func TestBandwidthEstimatorChanged(t *testing.T) {
+	t0 := time.Now()
+	b := newBandwidthEstimator(t0)
+
+	if _, changed := b.sample(t0.Add(bandwidthSampleInterval)); changed {
+		t.Error("idle to idle should not be a change")
+	}
+	b.sendBytes.Store(10 << 20)
+	b.sendConnNanos.Store(int64(4 * time.Second))
+	b.sendTotalNanos.Store(int64(4 * time.Second))
+	if _, changed := b.sample(t0.Add(2 * bandwidthSampleInterval)); !changed {
+		t.Error("idle to link limited should be a change")
+	}
+	b.sendBytes.Store(10 << 20)
+	b.sendConnNanos.Store(int64(4 * time.Second))
+	b.sendTotalNanos.Store(int64(4 * time.Second))
+	if _, changed := b.sample(t0.Add(3 * bandwidthSampleInterval)); changed {
+		t.Error("the same again should not be a change")
+	}
+}
+
+func TestBandwidthEstimatorWrap(t *testing.T) {
+	b := newBandwidthEstimator(time.Now())
+	var buf bytes.Buffer
+	rw := b.wrapConn(&buf)
+	rd, wr := b.wrapLimited(rw, rw)
+
+	if _, err := wr.Write(make([]byte, 1000)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadFull(rd, make([]byte, 600)); err != nil {
+		t.Fatal(err)
+	}
+	if b.sendBytes.Load() != 1000 || b.recvBytes.Load() != 600 {
+		t.Errorf("unexpected byte counts %d, %d", b.sendBytes.Load(), b.recvBytes.Load())
+	}
+	if b.sendTotalNanos.Load() < b.sendConnNanos.Load() || b.recvTotalNanos.Load() < b.recvConnNanos.Load() {
+		t.Error("total time should include connection time")
+	}
+}
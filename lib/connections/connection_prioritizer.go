@@ -31,6 +31,7 @@ type PriorityConnectionScore struct {
 	StabilityScore  float64
 	BandwidthScore  float64
 	PriorityScore   float64
+	LANScore        float64
 	
 	// Weighted composite score (0-100)
 	CompositeScore  float64
@@ -45,6 +46,7 @@ func (cp *ConnectionPrioritizer) EvaluateConnection(conn protocol.Connection) Pr
 	// Get health metrics if available
 	var latencyScore float64 = 50.0
 	var bandwidthScore float64 = 50.0
+	var stabilityScore float64 = 50.0
 	
 	// Try to get health monitor from connection
 	if healthMonitoredConn, ok := conn.(interface{ HealthMonitor() *HealthMonitor }); ok {
@@ -57,38 +59,51 @@ func (cp *ConnectionPrioritizer) EvaluateConnection(conn protocol.Connection) Pr
 			if throughput, exists := metrics["throughputMbps"]; exists {
 				bandwidthScore = cp.normalizeBandwidthScore(throughput)
 			}
+			if packetLoss, exists := metrics["packetLossPercent"]; exists {
+				stabilityScore = cp.normalizeStabilityScore(packetLoss)
+			}
 		}
 	}
 	
-	// Get stability metrics from stability manager if available
-	var stabilityScore float64 = 50.0
-	
 	// Priority score (lower priority value is better)
 	priorityScore := cp.normalizePriorityScore(conn.Priority())
-	
-	// Calculate composite score with weighted factors
-	// Weights can be adjusted based on importance
-	latencyWeight := 0.25
-	stabilityWeight := 0.25
-	bandwidthWeight := 0.25
-	priorityWeight := 0.25
-	
-	compositeScore := (latencyScore * latencyWeight) +
-		(stabilityScore * stabilityWeight) +
-		(bandwidthScore * bandwidthWeight) +
-		(priorityScore * priorityWeight)
+
+	var lanScore float64
+	if conn.IsLocal() {
+		lanScore = 100
+	}
+
+	// Calculate composite score with the weights configured for the
+	// device, relative to their sum
+	weights := cp.weights(conn)
+	var compositeScore float64
+	if total := weights.Latency + weights.Stability + weights.Bandwidth + weights.Priority + weights.LAN; total > 0 {
+		compositeScore = (latencyScore*float64(weights.Latency) +
+			stabilityScore*float64(weights.Stability) +
+			bandwidthScore*float64(weights.Bandwidth) +
+			priorityScore*float64(weights.Priority) +
+			lanScore*float64(weights.LAN)) / float64(total)
+	}
 	
 	return PriorityConnectionScore{
 		LatencyScore:    latencyScore,
 		StabilityScore:  stabilityScore,
 		BandwidthScore:  bandwidthScore,
 		PriorityScore:   priorityScore,
+		LANScore:        lanScore,
 		CompositeScore:  compositeScore,
 		Connection:      conn,
 		Priority:        conn.Priority(),
 	}
 }
 
+// weights returns the weights of scoring connections to the device of the
+// connection.
+func (cp *ConnectionPrioritizer) weights(conn protocol.Connection) config.ConnectionWeights {
+	devCfg, _ := cp.cfg.Device(conn.DeviceID())
+	return devCfg.ConnectionWeights(cp.cfg.Options())
+}
+
 // CompareConnections compares two connections and returns true if the first is better than the second
 func (cp *ConnectionPrioritizer) CompareConnections(conn1, conn2 protocol.Connection) bool {
 	score1 := cp.EvaluateConnection(conn1)
@@ -162,6 +177,13 @@ func (cp *ConnectionPrioritizer) normalizeBandwidthScore(bandwidthMbps float64)
 	return score
 }
 
+// normalizeStabilityScore converts packet loss in percent to a 0-100 score
+// Lower packet loss = higher score
+func (cp *ConnectionPrioritizer) normalizeStabilityScore(packetLossPercent float64) float64 {
+	// Consider 10% packet loss as unusable
+	return min(max(100.0-packetLossPercent*10.0, 0), 100)
+}
+
 // normalizePriorityScore converts connection priority to a 0-100 score
 // Lower priority values = higher score
 func (cp *ConnectionPrioritizer) normalizePriorityScore(priority int) float64 {
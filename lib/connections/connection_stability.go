@@ -23,7 +23,10 @@ type ConnectionStabilityManager struct {
 	// Stability tracking
 	connectionHistory map[string]*ConnectionHistory
 	shortLivedCount   int
+	churnCount        int // connections closed
 	lastConnectionAt  time.Time
+	lastCloseReason   string
+	lastClosedAt      time.Time
 	
 	// Adaptive parameters
 	adaptiveReconnectInterval time.Duration
@@ -111,6 +114,10 @@ func (csm *ConnectionStabilityManager) RecordConnectionClosed(conn protocol.Conn
 			csm.shortLivedCount++
 		}
 	}
+	csm.churnCount++
+	csm.lastCloseReason = reason
+	csm.lastClosedAt = now
+	csm.pruneHistoryLocked()
 	
 	// Update stability score based on connection closure
 	csm.updateStabilityScore()
@@ -123,7 +130,10 @@ func (csm *ConnectionStabilityManager) RecordConnectionClosed(conn protocol.Conn
 func (csm *ConnectionStabilityManager) GetStabilityMetrics() StabilityMetrics {
 	csm.mut.RLock()
 	defer csm.mut.RUnlock()
-	
+	return csm.stabilityMetricsLocked()
+}
+
+func (csm *ConnectionStabilityManager) stabilityMetricsLocked() StabilityMetrics {
 	totalConnections := len(csm.connectionHistory)
 	if totalConnections == 0 {
 		return StabilityMetrics{
@@ -198,7 +208,7 @@ func (csm *ConnectionStabilityManager) updateStabilityScore() {
 	// 2. Average connection duration (higher is better)
 	// 3. Connection churn rate (lower is better)
 	
-	metrics := csm.GetStabilityMetrics()
+	metrics := csm.stabilityMetricsLocked()
 	
 	// Normalize metrics to 0-1 scale
 	shortLivedScore := 1.0 - metrics.ShortLivedConnectionRate // Invert (lower short-lived rate is better)
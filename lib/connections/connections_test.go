@@ -10,12 +10,15 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"io"
 	"math/rand"
 	"net"
 	"net/url"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"sync"
 	"testing"
@@ -196,6 +199,54 @@ func TestGetDialer(t *testing.T) {
 	}
 }
 
+func TestCheckCertificateValidity(t *testing.T) {
+	now := time.Now()
+	cert := &x509.Certificate{NotBefore: now.Add(-time.Hour), NotAfter: now.Add(time.Hour)}
+
+	if err := checkCertificateValidity(cert, now); err != nil {
+		t.Error("unexpected error for valid certificate:", err)
+	}
+	if err := checkCertificateValidity(cert, now.Add(2*time.Hour)); !errors.Is(err, errCertificateExpired) {
+		t.Error("expected expired certificate error, got", err)
+	}
+	if err := checkCertificateValidity(cert, now.Add(-2*time.Hour)); !errors.Is(err, errCertificateNotYetValid) {
+		t.Error("expected not yet valid certificate error, got", err)
+	}
+}
+
+func TestSetCertificate(t *testing.T) {
+	cert := mustGetCert(t)
+	s := &service{
+		Supervisor:     suture.New("test", suture.Spec{}),
+		myID:           protocol.NewDeviceID(cert.Certificate[0]),
+		tlsCfg:         &tls.Config{Certificates: []tls.Certificate{cert}},
+		listeners:      make(map[string]genericListener),
+		listenerTokens: make(map[string]suture.ServiceToken),
+	}
+	orig := s.tlsConfig()
+
+	other := mustGetCert(t)
+	if err := s.SetCertificate(other); !errors.Is(err, ErrCertificateIdentity) {
+		t.Error("expected identity error for another device's certificate, got", err)
+	}
+	if s.tlsConfig() != orig {
+		t.Error("TLS config should be unchanged")
+	}
+
+	// The same leaf with another chain keeps the device ID.
+	renewed := cert
+	renewed.Certificate = [][]byte{cert.Certificate[0], other.Certificate[0]}
+	if err := s.SetCertificate(renewed); err != nil {
+		t.Fatal(err)
+	}
+	if cur := s.tlsConfig(); cur == orig || len(cur.Certificates[0].Certificate) != 2 {
+		t.Error("expected a new TLS config with the renewed certificate")
+	}
+	if len(orig.Certificates[0].Certificate) != 1 {
+		t.Error("the previous TLS config should not be modified")
+	}
+}
+
 func TestConnectionStatus(t *testing.T) {
 	s := newConnectionStatusHandler()
 
@@ -374,6 +425,7 @@ func TestConnectionEstablishment(t *testing.T) {
 	addrs := []string{
 		"tcp://127.0.0.1:0",
 		"quic://127.0.0.1:0",
+		"demux://127.0.0.1:0",
 	}
 
 	send := make([]byte, 128<<10)
@@ -381,6 +433,10 @@ func TestConnectionEstablishment(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	if runtime.GOOS != "windows" {
+		addrs = append(addrs, "unix://"+filepath.Join(t.TempDir(), "bep.sock"))
+	}
+
 	for _, addr := range addrs {
 		proto := strings.SplitN(addr, ":", 2)[0]
 
@@ -504,3 +560,38 @@ func mustGetCert(b interface{ Fatal(...interface{}) }) tls.Certificate {
 	}
 	return cert
 }
+
+func TestBlockSealingRequired(t *testing.T) {
+	cert, err := tlsutil.NewCertificateInMemory("syncthing", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remoteCert, err := tlsutil.NewCertificateInMemory("syncthing", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, err := x509.ParseCertificate(remoteCert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	device := protocol.NewDeviceID(remote.Raw)
+
+	cfg := config.New(protocol.LocalDeviceID)
+	cfg.Devices = append(cfg.Devices, config.DeviceConfiguration{DeviceID: device, SealRelayedBlocks: true})
+	s := &service{
+		cfg:    config.Wrap("", cfg, protocol.LocalDeviceID, events.NoopLogger),
+		myID:   protocol.NewDeviceID(cert.Certificate[0]),
+		tlsCfg: &tls.Config{Certificates: []tls.Certificate{cert}},
+	}
+	relayed := internalConn{connType: connTypeRelayClient}
+
+	if _, err := s.blockSealingOption(relayed, device, remote, protocol.Hello{}); !errors.Is(err, errBlockSealingRequired) {
+		t.Error("expected a relayed connection without sealing to be refused, got", err)
+	}
+	if opt, err := s.blockSealingOption(relayed, device, remote, protocol.Hello{BlockSealing: true}); err != nil || opt == nil {
+		t.Error("expected to seal blocks, got", err)
+	}
+	if opt, err := s.blockSealingOption(internalConn{connType: connTypeTCPClient}, device, remote, protocol.Hello{}); err != nil || opt != nil {
+		t.Error("expected nothing to seal on a direct connection, got", err)
+	}
+}
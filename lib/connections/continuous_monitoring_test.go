@@ -346,6 +346,40 @@ func (m *monitoringMockService) ConnectionStatus() map[string]ConnectionStatusEn
 	return result
 }
 
+func (m *monitoringMockService) ConnectionAttempts(protocol.DeviceID) []ConnectionAttempt {
+	// Mock implementation
+	return nil
+}
+
+func (m *monitoringMockService) ConnectionErrorBudgets() map[string]DeviceErrorBudget {
+	// Mock implementation
+	return nil
+}
+
+func (m *monitoringMockService) ConnectionHealth() ConnectionHealthReport {
+	// Mock implementation
+	return ConnectionHealthReport{}
+}
+
+func (m *monitoringMockService) ReconnectDevice(protocol.DeviceID, error) {
+	// Mock implementation
+}
+
+func (m *monitoringMockService) LimitFolderSend(context.Context, string, int) error {
+	// Mock implementation
+	return nil
+}
+
+func (m *monitoringMockService) LimitFolderRecv(context.Context, string, int) error {
+	// Mock implementation
+	return nil
+}
+
+func (m *monitoringMockService) AcknowledgeConnectionErrorBudget(protocol.DeviceID) bool {
+	// Mock implementation
+	return false
+}
+
 func (m *monitoringMockService) NATType() string {
 	// Mock implementation
 	return "unknown"
@@ -380,6 +414,14 @@ func (m *monitoringMockService) PacketScheduler() *PacketScheduler {
 	return nil
 }
 
+func (m *monitoringMockService) BandwidthEstimates() map[string]BandwidthEstimate {
+	return nil
+}
+
+func (m *monitoringMockService) InfrastructureStatus() map[string]InfrastructureStatusEntry {
+	return nil
+}
+
 func (m *monitoringMockService) DialNow() {
 	// Mock implementation - just log that it was called
 	fmt.Println("DialNow called on mock service")
@@ -395,6 +437,14 @@ func (m *monitoringMockService) ExternalAddresses() []string {
 	return []string{}
 }
 
+func (m *monitoringMockService) SetCertificate(tls.Certificate) error {
+	return nil
+}
+
+func (m *monitoringMockService) SetDatabase(db.KV) {
+	// Mock implementation
+}
+
 // mockConnection implements a mock protocol.Connection for testing
 type mockConnection struct{}
 
@@ -455,6 +505,14 @@ func (m *mockConnection) ResponseDevice(ctx context.Context, response *bep.Respo
 	return nil
 }
 
+func (m *mockConnection) FolderPreview(ctx context.Context, req *protocol.FolderPreviewRequest) (*protocol.FolderPreview, error) {
+	return nil, protocol.ErrNoSuchFile
+}
+
+func (m *mockConnection) ControlMessage(ctx context.Context, msg *protocol.ControlMessage) error {
+	return nil
+}
+
 // monitoringTestModel implements the Model interface for testing monitoring
 type monitoringTestModel struct {
 	t        *testing.T
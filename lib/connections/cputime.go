@@ -0,0 +1,143 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"crypto/tls"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// One in this many reads and writes of a connection is timed to account
+// the CPU time spent in TLS.
+const cryptoSampleInterval = 16
+
+// cryptoMeter estimates the CPU time spent encrypting and decrypting a TLS
+// connection. A sampled read or write through TLS is timed as a whole, as
+// are the reads and writes on the underlying connection it makes; the
+// difference is time spent in TLS, mostly doing crypto, and counts for all
+// the calls since the previous sample.
+type cryptoMeter struct {
+	reads, writes                atomic.Uint64
+	samplingRead, samplingWrite  atomic.Bool
+	rawReadNanos, rawWriteNanos  atomic.Int64
+	encryptNanos, decryptNanos   atomic.Int64
+	encryptMetric, decryptMetric atomic.Pointer[prometheus.Counter]
+}
+
+// setDevice makes the meter account to the metrics of the device, from now
+// on.
+func (m *cryptoMeter) setDevice(deviceID string) {
+	encrypt := metricDeviceTLSSeconds.WithLabelValues(deviceID, "encrypt")
+	decrypt := metricDeviceTLSSeconds.WithLabelValues(deviceID, "decrypt")
+	m.encryptMetric.Store(&encrypt)
+	m.decryptMetric.Store(&decrypt)
+}
+
+func (m *cryptoMeter) times() (encrypt, decrypt time.Duration) {
+	return time.Duration(m.encryptNanos.Load()), time.Duration(m.decryptNanos.Load())
+}
+
+func (m *cryptoMeter) account(total time.Duration, raw *atomic.Int64, nanos *atomic.Int64, metric *atomic.Pointer[prometheus.Counter]) {
+	d := (total - time.Duration(raw.Load())) * cryptoSampleInterval
+	if d <= 0 {
+		return
+	}
+	nanos.Add(int64(d))
+	if c := metric.Load(); c != nil {
+		(*c).Add(d.Seconds())
+	}
+}
+
+// meteredConn is the connection underneath TLS, timing reads and writes
+// while the meter samples.
+type meteredConn struct {
+	net.Conn
+	meter *cryptoMeter
+}
+
+func newMeteredConn(conn net.Conn) *meteredConn {
+	return &meteredConn{Conn: conn, meter: new(cryptoMeter)}
+}
+
+func (c *meteredConn) Read(p []byte) (int, error) {
+	if !c.meter.samplingRead.Load() {
+		return c.Conn.Read(p)
+	}
+	t0 := time.Now()
+	n, err := c.Conn.Read(p)
+	c.meter.rawReadNanos.Add(int64(time.Since(t0)))
+	return n, err
+}
+
+func (c *meteredConn) Write(p []byte) (int, error) {
+	if !c.meter.samplingWrite.Load() {
+		return c.Conn.Write(p)
+	}
+	t0 := time.Now()
+	n, err := c.Conn.Write(p)
+	c.meter.rawWriteNanos.Add(int64(time.Since(t0)))
+	return n, err
+}
+
+// meteredTLSConn is a TLS connection over a meteredConn, sampling its reads
+// and writes.
+type meteredTLSConn struct {
+	tlsConn
+	meter *cryptoMeter
+}
+
+// withCryptoMeter returns the connection wrapped for CPU accounting, when
+// it's TLS over a meteredConn, and its meter.
+func withCryptoMeter(tc tlsConn) (tlsConn, *cryptoMeter) {
+	conn, ok := tc.(*tls.Conn)
+	if !ok {
+		return tc, nil
+	}
+	mc, ok := conn.NetConn().(*meteredConn)
+	if !ok {
+		return tc, nil
+	}
+	return &meteredTLSConn{tlsConn: tc, meter: mc.meter}, mc.meter
+}
+
+func (c *meteredTLSConn) Read(p []byte) (int, error) {
+	m := c.meter
+	if m.reads.Add(1)%cryptoSampleInterval != 0 {
+		return c.tlsConn.Read(p)
+	}
+	m.rawReadNanos.Store(0)
+	m.samplingRead.Store(true)
+	t0 := time.Now()
+	n, err := c.tlsConn.Read(p)
+	total := time.Since(t0)
+	m.samplingRead.Store(false)
+	if err == nil {
+		m.account(total, &m.rawReadNanos, &m.decryptNanos, &m.decryptMetric)
+	}
+	return n, err
+}
+
+func (c *meteredTLSConn) Write(p []byte) (int, error) {
+	m := c.meter
+	if m.writes.Add(1)%cryptoSampleInterval != 0 {
+		return c.tlsConn.Write(p)
+	}
+	m.rawWriteNanos.Store(0)
+	m.samplingWrite.Store(true)
+	t0 := time.Now()
+	n, err := c.tlsConn.Write(p)
+	total := time.Since(t0)
+	m.samplingWrite.Store(false)
+	if err == nil {
+		m.account(total, &m.rawWriteNanos, &m.encryptNanos, &m.encryptMetric)
+	}
+	return n, err
+}
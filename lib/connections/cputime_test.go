@@ -0,0 +1,54 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"crypto/tls"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/tlsutil"
+)
+
+func TestCryptoMeter(t *testing.T) {
+	cert := mustGetCert(t)
+	tlsCfg := tlsutil.SecureDefaultTLS13()
+	tlsCfg.Certificates = []tls.Certificate{cert}
+	tlsCfg.InsecureSkipVerify = true
+
+	a, b := net.Pipe()
+	client := newInternalConn(tls.Client(newMeteredConn(a), tlsCfg), connTypeTCPClient, false, 0)
+	server := newInternalConn(tls.Server(newMeteredConn(b), tlsCfg), connTypeTCPServer, false, 0)
+	if client.cpu == nil || server.cpu == nil {
+		t.Fatal("expected metered connections")
+	}
+	defer client.Close()
+	defer server.Close()
+
+	data := make([]byte, 64<<10)
+	go func() {
+		for range 4 * cryptoSampleInterval {
+			if _, err := client.Write(data); err != nil {
+				return
+			}
+		}
+	}()
+	buf := make([]byte, len(data))
+	for range 4 * cryptoSampleInterval {
+		if _, err := io.ReadFull(server, buf); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if encrypt, _ := client.CryptoTimes(); encrypt <= 0 {
+		t.Error("expected encryption time to be accounted")
+	}
+	if _, decrypt := server.CryptoTimes(); decrypt <= 0 {
+		t.Error("expected decryption time to be accounted")
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"crypto/tls"
+	"net/url"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/connections/registry"
+	"github.com/syncthing/syncthing/lib/dialer"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func init() {
+	dialers["ws"] = &webSocketDialerFactory{}
+}
+
+// webSocketDialer connects to a demux listener through a WebSocket, for
+// addresses like "ws://example.com:443/syncthing".
+type webSocketDialer struct {
+	commonDialer
+}
+
+func (d *webSocketDialer) Dial(ctx context.Context, _ protocol.DeviceID, uri *url.URL) (internalConn, error) {
+	uri = fixupPort(uri, 80)
+
+	trace := connTraceFrom(ctx)
+	conn, err := dialer.DialContext(ctx, "tcp", uri.Host)
+	if err != nil {
+		return internalConn{}, err
+	}
+	trace.step(stepDial)
+
+	_ = conn.SetDeadline(time.Now().Add(getProgressiveDialTimeoutForAddress(uri.Host)))
+	br, err := webSocketClientHandshake(conn, uri.Host, uri.Path)
+	if err != nil {
+		conn.Close()
+		return internalConn{}, err
+	}
+
+	tc := tls.Client(newMeteredConn(newWebSocketConn(conn, br, true)), d.tlsCfg)
+	if err := tlsTimedHandshake(tc); err != nil {
+		conn.Close()
+		return internalConn{}, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	trace.step(stepTLS)
+
+	priority := d.wanPriority
+	isLocal := d.lanChecker.isLANHost(uri.Host)
+	if isLocal {
+		priority = d.lanPriority
+	}
+	return newInternalConn(tc, connTypeWebSocketClient, isLocal, priority), nil
+}
+
+type webSocketDialerFactory struct{}
+
+func (webSocketDialerFactory) New(opts config.OptionsConfiguration, tlsCfg *tls.Config, _ *registry.Registry, lanChecker *lanChecker) genericDialer {
+	return &webSocketDialer{
+		commonDialer: commonDialer{
+			reconnectInterval: time.Duration(opts.ReconnectIntervalS) * time.Second,
+			tlsCfg:            tlsCfg,
+			lanChecker:        lanChecker,
+			lanPriority:       opts.ConnectionPriorityTCPLAN,
+			wanPriority:       opts.ConnectionPriorityTCPWAN,
+		},
+	}
+}
+
+func (webSocketDialerFactory) AlwaysWAN() bool {
+	return false
+}
+
+func (webSocketDialerFactory) Valid(config.Configuration) error {
+	// Always valid
+	return nil
+}
+
+func (webSocketDialerFactory) String() string {
+	return "WebSocket Dialer"
+}
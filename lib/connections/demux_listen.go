@@ -0,0 +1,272 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/connections/registry"
+	"github.com/syncthing/syncthing/lib/dialer"
+	"github.com/syncthing/syncthing/lib/nat"
+	"github.com/syncthing/syncthing/lib/svcutil"
+)
+
+func init() {
+	listeners["demux"] = &demuxListenerFactory{}
+}
+
+// How long a new connection has to show what it is
+const demuxSniffTimeout = 10 * time.Second
+
+// demuxListener accepts BEP on a port shared with a web server; see
+// demux_misc.go.
+type demuxListener struct {
+	svcutil.ServiceWithError
+	onAddressesChangedNotifier
+
+	uri        *url.URL
+	cfg        config.Wrapper
+	tlsCfg     *tls.Config
+	conns      chan internalConn
+	factory    listenerFactory
+	lanChecker *lanChecker
+
+	laddr net.Addr
+	mut   sync.RWMutex
+}
+
+func (t *demuxListener) serve(ctx context.Context) error {
+	listener, err := net.Listen("tcp", t.uri.Host)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to listen (demux)", slogutil.Error(err))
+		return err
+	}
+	defer listener.Close()
+
+	t.mut.Lock()
+	t.laddr = listener.Addr()
+	t.mut.Unlock()
+	defer func() {
+		t.mut.Lock()
+		t.laddr = nil
+		t.mut.Unlock()
+	}()
+
+	t.notifyAddressesChanged(t)
+	defer t.clearAddresses(t)
+
+	slog.InfoContext(ctx, "Demux listener starting", slogutil.Address(listener.Addr()))
+	defer slog.InfoContext(ctx, "Demux listener shutting down", slogutil.Address(listener.Addr()))
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	acceptFailures := 0
+	const maxAcceptFailures = 10
+
+	tcpListener := listener.(*net.TCPListener)
+	for {
+		_ = tcpListener.SetDeadline(time.Now().Add(time.Second))
+		conn, err := tcpListener.Accept()
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				conn.Close()
+			}
+			return nil
+		default:
+		}
+		if err != nil {
+			var ne *net.OpError
+			if ok := errors.As(err, &ne); !ok || !ne.Timeout() {
+				slog.WarnContext(ctx, "Failed to accept demux connection", slogutil.Error(err))
+				acceptFailures++
+				if acceptFailures > maxAcceptFailures {
+					return err
+				}
+				time.Sleep(time.Duration(acceptFailures) * time.Second)
+			}
+			continue
+		}
+		acceptFailures = 0
+
+		if err := dialer.SetTCPOptions(conn); err != nil {
+			l.Debugln("Listen (BEP/demux): setting tcp options:", err)
+		}
+
+		// Sniffing waits for the client, so don't hold up the next one.
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t.handle(ctx, conn)
+		}()
+	}
+}
+
+// handle decides what the connection is for and passes it on.
+func (t *demuxListener) handle(ctx context.Context, conn net.Conn) {
+	opts := t.cfg.Options()
+	_ = conn.SetDeadline(time.Now().Add(demuxSniffTimeout))
+	br := bufio.NewReaderSize(conn, demuxPeekSize)
+
+	first, err := br.Peek(1)
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	var bep net.Conn
+	if first[0] == tlsRecordTypeHandshake {
+		name, err := peekClientHelloServerName(br)
+		if err == nil && (name == "" || matchesHostname(opts.DemuxHostnames, name)) {
+			bep = &bufferedConn{Conn: conn, r: br}
+		}
+	} else if req, n, err := peekHTTPRequest(br); err == nil {
+		switch {
+		case req.Method == http.MethodConnect && matchesHostname(opts.DemuxHostnames, req.Host):
+			_, _ = br.Discard(n)
+			if _, err := conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+				conn.Close()
+				return
+			}
+			bep = &bufferedConn{Conn: conn, r: br}
+		case isWebSocketUpgrade(req) && opts.DemuxWebSocketPath != "" && req.URL.Path == opts.DemuxWebSocketPath &&
+			(len(opts.DemuxHostnames) == 0 || matchesHostname(opts.DemuxHostnames, req.Host)):
+			_, _ = br.Discard(n)
+			if _, err := conn.Write(webSocketServerResponse(req)); err != nil {
+				conn.Close()
+				return
+			}
+			bep = newWebSocketConn(conn, br, false)
+		}
+	}
+
+	if bep == nil {
+		l.Debugln("Listen (BEP/demux): passing on connection from", conn.RemoteAddr())
+		t.forward(ctx, &bufferedConn{Conn: conn, r: br}, opts.DemuxFallbackAddress)
+		return
+	}
+
+	l.Debugln("Listen (BEP/demux): connect from", conn.RemoteAddr())
+	tc := tls.Server(newMeteredConn(bep), t.tlsCfg)
+	if err := tlsTimedHandshake(tc); err != nil {
+		slog.WarnContext(ctx, "Failed TLS handshake", slogutil.Address(conn.RemoteAddr()), slogutil.Error(err))
+		tc.Close()
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	priority := t.cfg.Options().ConnectionPriorityTCPWAN
+	isLocal := t.lanChecker.isLAN(conn.RemoteAddr())
+	if isLocal {
+		priority = t.cfg.Options().ConnectionPriorityTCPLAN
+	}
+	select {
+	case t.conns <- newInternalConn(tc, connTypeDemuxServer, isLocal, priority):
+	case <-ctx.Done():
+		tc.Close()
+	}
+}
+
+// forward passes the connection on to the fallback address, or closes it
+// when there is none.
+func (*demuxListener) forward(ctx context.Context, conn net.Conn, addr string) {
+	defer conn.Close()
+	if addr == "" {
+		return
+	}
+
+	backend, err := (&net.Dialer{Timeout: demuxSniffTimeout}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		l.Debugln("Listen (BEP/demux): dialing fallback:", err)
+		return
+	}
+	defer backend.Close()
+	_ = conn.SetDeadline(time.Time{})
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(backend, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(conn, backend)
+		done <- struct{}{}
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+func (t *demuxListener) URI() *url.URL {
+	return t.uri
+}
+
+// tcpURI is where plain TCP dialers reach us.
+func (t *demuxListener) tcpURI() *url.URL {
+	uri := *t.uri
+	uri.Scheme = "tcp"
+	t.mut.RLock()
+	defer t.mut.RUnlock()
+	return maybeReplacePort(&uri, t.laddr)
+}
+
+func (t *demuxListener) WANAddresses() []*url.URL {
+	return []*url.URL{t.tcpURI()}
+}
+
+func (t *demuxListener) LANAddresses() []*url.URL {
+	uri := t.tcpURI()
+	addrs := []*url.URL{uri}
+	addrs = append(addrs, getURLsForAllAdaptersIfUnspecified(uri.Scheme, uri)...)
+	return addrs
+}
+
+func (t *demuxListener) String() string {
+	return t.uri.String()
+}
+
+func (t *demuxListener) Factory() listenerFactory {
+	return t.factory
+}
+
+func (*demuxListener) NATType() string {
+	return "unknown"
+}
+
+type demuxListenerFactory struct{}
+
+func (f *demuxListenerFactory) New(uri *url.URL, cfg config.Wrapper, tlsCfg *tls.Config, conns chan internalConn, _ *nat.Service, _ *registry.Registry, lanChecker *lanChecker) genericListener {
+	l := &demuxListener{
+		uri:        fixupPort(uri, 443),
+		cfg:        cfg,
+		tlsCfg:     tlsCfg,
+		conns:      conns,
+		factory:    f,
+		lanChecker: lanChecker,
+	}
+	l.ServiceWithError = svcutil.AsService(l.serve, l.String())
+	return l
+}
+
+func (demuxListenerFactory) Valid(_ config.Configuration) error {
+	// Always valid
+	return nil
+}
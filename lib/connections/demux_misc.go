@@ -0,0 +1,341 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // mandated by RFC 6455
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The demux listener shares a port, typically 443, with a web server. It
+// looks at the start of each incoming connection to decide whether it's
+// BEP or for the web server:
+//
+//   - A TLS ClientHello without a server name is BEP, as Syncthing doesn't
+//     send one while browsers always do. One asking for one of the
+//     configured hostnames is BEP too.
+//   - An HTTP CONNECT request for one of the configured hostnames is
+//     answered and followed by BEP, for clients behind HTTP proxies.
+//   - A WebSocket upgrade on the configured path is answered and followed
+//     by BEP in binary WebSocket messages, for networks that only let HTTP
+//     through. These are dialed through "ws://host:port/path" addresses.
+//
+// Everything else is passed on untouched to the fallback address. As plain
+// TCP dialers connect just fine, the listener announces tcp:// addresses.
+
+const (
+	// The size of the buffer the start of a connection is sniffed in,
+	// enough for a TLS record or a reasonable HTTP header.
+	demuxPeekSize = 32 << 10
+
+	tlsRecordTypeHandshake = 0x16
+	webSocketGUID          = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+)
+
+var (
+	errDemuxSniffed       = errors.New("sniffed client hello")
+	errWebSocketHandshake = errors.New("websocket handshake failed")
+	errWebSocketFrame     = errors.New("unexpected websocket frame")
+)
+
+// bufferedConn is a connection read through a buffer holding what was
+// sniffed from it.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
+
+// peekClientHelloServerName returns the server name asked for by the TLS
+// ClientHello at the start of the reader, without consuming it.
+func peekClientHelloServerName(br *bufio.Reader) (string, error) {
+	hdr, err := br.Peek(5)
+	if err != nil {
+		return "", err
+	}
+	record, err := br.Peek(5 + int(binary.BigEndian.Uint16(hdr[3:5])))
+	if err != nil {
+		return "", err
+	}
+
+	var name string
+	err = tls.Server(sniffConn{r: bytes.NewReader(record)}, &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			name = hello.ServerName
+			return nil, errDemuxSniffed
+		},
+	}).Handshake()
+	if !errors.Is(err, errDemuxSniffed) {
+		return "", err
+	}
+	return name, nil
+}
+
+// sniffConn feeds a TLS handshake from a reader and refuses to answer.
+type sniffConn struct {
+	r io.Reader
+}
+
+func (c sniffConn) Read(p []byte) (int, error)     { return c.r.Read(p) }
+func (sniffConn) Write([]byte) (int, error)        { return 0, io.ErrClosedPipe }
+func (sniffConn) Close() error                     { return nil }
+func (sniffConn) LocalAddr() net.Addr              { return nil }
+func (sniffConn) RemoteAddr() net.Addr             { return nil }
+func (sniffConn) SetDeadline(time.Time) error      { return nil }
+func (sniffConn) SetReadDeadline(time.Time) error  { return nil }
+func (sniffConn) SetWriteDeadline(time.Time) error { return nil }
+
+// peekHTTPRequest parses the HTTP request header at the start of the
+// reader, returning it and its length without consuming it.
+func peekHTTPRequest(br *bufio.Reader) (*http.Request, int, error) {
+	n := 1
+	for {
+		buf, err := br.Peek(n)
+		if i := bytes.Index(buf, []byte("\r\n\r\n")); i >= 0 {
+			req, err := http.ReadRequest(bufio.NewReader(bytes.NewReader(buf[:i+4])))
+			return req, i + 4, err
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		n = br.Buffered() + 1
+	}
+}
+
+// matchesHostname returns whether the host, possibly with a port, is one
+// of the hostnames.
+func matchesHostname(hostnames []string, host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	for _, hostname := range hostnames {
+		if strings.EqualFold(hostname, host) {
+			return true
+		}
+	}
+	return false
+}
+
+func isWebSocketUpgrade(req *http.Request) bool {
+	return req.Method == http.MethodGet &&
+		strings.EqualFold(req.Header.Get("Upgrade"), "websocket") &&
+		req.Header.Get("Sec-WebSocket-Key") != ""
+}
+
+func webSocketAccept(key string) string {
+	h := sha1.Sum([]byte(key + webSocketGUID)) //nolint:gosec
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// webSocketClientHandshake upgrades the connection to a WebSocket on the
+// path, returning what was read past the response.
+func webSocketClientHandshake(conn net.Conn, host, path string) (*bufio.Reader, error) {
+	var nonce [16]byte
+	_, _ = rand.Read(nonce[:])
+	key := base64.StdEncoding.EncodeToString(nonce[:])
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+host+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", key)
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != webSocketAccept(key) {
+		return nil, errWebSocketHandshake
+	}
+	return br, nil
+}
+
+// webSocketServerResponse is the answer to an accepted upgrade request.
+func webSocketServerResponse(req *http.Request) []byte {
+	return []byte("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + webSocketAccept(req.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+}
+
+const (
+	wsOpContinuation = 0x0
+	wsOpBinary       = 0x2
+	wsOpClose        = 0x8
+	wsOpPing         = 0x9
+	wsOpPong         = 0xa
+)
+
+// webSocketConn carries a byte stream in binary WebSocket messages, each
+// write being a message of its own. Clients mask what they send, as the
+// protocol requires.
+type webSocketConn struct {
+	net.Conn
+	r      io.Reader
+	client bool
+
+	// The current frame being read
+	remaining int64
+	masked    bool
+	mask      [4]byte
+	maskPos   int
+
+	wmut sync.Mutex
+}
+
+func newWebSocketConn(conn net.Conn, r io.Reader, client bool) *webSocketConn {
+	return &webSocketConn{Conn: conn, r: r, client: client}
+}
+
+func (c *webSocketConn) Read(p []byte) (int, error) {
+	for c.remaining == 0 {
+		if err := c.nextFrame(); err != nil {
+			return 0, err
+		}
+	}
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+	n, err := c.r.Read(p)
+	c.unmask(p[:n])
+	c.remaining -= int64(n)
+	return n, err
+}
+
+func (c *webSocketConn) unmask(p []byte) {
+	if !c.masked {
+		return
+	}
+	for i := range p {
+		p[i] ^= c.mask[c.maskPos%4]
+		c.maskPos++
+	}
+}
+
+// nextFrame reads the next frame header, handling control frames as they
+// come.
+func (c *webSocketConn) nextFrame() error {
+	var hdr [2]byte
+	if _, err := io.ReadFull(c.r, hdr[:]); err != nil {
+		return err
+	}
+	opcode := hdr[0] & 0x0f
+	length := int64(hdr[1] & 0x7f)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return err
+		}
+		length = int64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.r, ext[:]); err != nil {
+			return err
+		}
+		length = int64(binary.BigEndian.Uint64(ext[:]))
+		if length < 0 {
+			return errWebSocketFrame
+		}
+	}
+	c.masked = hdr[1]&0x80 != 0
+	c.maskPos = 0
+	if c.masked {
+		if _, err := io.ReadFull(c.r, c.mask[:]); err != nil {
+			return err
+		}
+	}
+
+	switch opcode {
+	case wsOpBinary, wsOpContinuation:
+		c.remaining = length
+		return nil
+	case wsOpClose:
+		return io.EOF
+	case wsOpPing, wsOpPong:
+		if length > 125 {
+			return errWebSocketFrame
+		}
+		payload := make([]byte, length)
+		if _, err := io.ReadFull(c.r, payload); err != nil {
+			return err
+		}
+		if opcode == wsOpPing {
+			c.unmask(payload)
+			return c.writeFrame(wsOpPong, payload)
+		}
+		return nil
+	default:
+		return errWebSocketFrame
+	}
+}
+
+func (c *webSocketConn) Write(p []byte) (int, error) {
+	if err := c.writeFrame(wsOpBinary, p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *webSocketConn) writeFrame(opcode byte, payload []byte) error {
+	buf := make([]byte, 0, 14+len(payload))
+	buf = append(buf, 0x80|opcode)
+	var maskBit byte
+	if c.client {
+		maskBit = 0x80
+	}
+	switch {
+	case len(payload) < 126:
+		buf = append(buf, maskBit|byte(len(payload)))
+	case len(payload) <= 0xffff:
+		buf = append(buf, maskBit|126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(len(payload)))
+	default:
+		buf = append(buf, maskBit|127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(len(payload)))
+	}
+	if c.client {
+		var mask [4]byte
+		_, _ = rand.Read(mask[:])
+		buf = append(buf, mask[:]...)
+		start := len(buf)
+		buf = append(buf, payload...)
+		for i := range buf[start:] {
+			buf[start+i] ^= mask[i%4]
+		}
+	} else {
+		buf = append(buf, payload...)
+	}
+
+	c.wmut.Lock()
+	defer c.wmut.Unlock()
+	_, err := c.Conn.Write(buf)
+	return err
+}
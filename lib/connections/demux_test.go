@@ -0,0 +1,180 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/thejerf/suture/v4"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/connections/registry"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/tlsutil"
+)
+
+func TestWebSocketConn(t *testing.T) {
+	a, b := net.Pipe()
+	client := newWebSocketConn(a, a, true)
+	server := newWebSocketConn(b, b, false)
+
+	for _, size := range []int{0, 10, 300, 70000} {
+		send := make([]byte, size)
+		for i := range send {
+			send[i] = byte(i)
+		}
+		go func() {
+			_, _ = client.Write(send)
+			_, _ = client.Write([]byte{42})
+		}()
+		recv := make([]byte, size+1)
+		if _, err := io.ReadFull(server, recv); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(recv[:size], send) || recv[size] != 42 {
+			t.Fatalf("data mismatch for %d bytes", size)
+		}
+	}
+
+	// Pings are answered while reading.
+	go func() {
+		_ = client.writeFrame(wsOpPing, []byte("ping"))
+		_, _ = client.Write([]byte("data"))
+	}()
+	go func() {
+		buf := make([]byte, 4)
+		_, _ = io.ReadFull(server, buf)
+	}()
+	if err := client.nextFrame(); err != nil {
+		t.Fatal(err)
+	}
+	if client.remaining != 0 {
+		t.Error("expected the pong to be consumed")
+	}
+}
+
+func TestDemuxListener(t *testing.T) {
+	fallback, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fallback.Close()
+
+	cert := mustGetCert(t)
+	deviceID := protocol.NewDeviceID(cert.Certificate[0])
+	tlsCfg := tlsutil.SecureDefaultTLS13()
+	tlsCfg.Certificates = []tls.Certificate{cert}
+	tlsCfg.ClientAuth = tls.RequestClientCert
+	tlsCfg.InsecureSkipVerify = true
+
+	cfg := config.Configuration{
+		Options: config.OptionsConfiguration{
+			DemuxHostnames:       []string{"sync.example.com"},
+			DemuxWebSocketPath:   "/syncthing",
+			DemuxFallbackAddress: fallback.Addr().String(),
+		},
+	}
+	wcfg := config.Wrap("", cfg, deviceID, events.NoopLogger)
+	lanChecker := &lanChecker{wcfg}
+	conns := make(chan internalConn, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	supervisor := suture.New("main", suture.Spec{PassThroughPanics: true})
+	supervisor.ServeBackground(ctx)
+
+	uri, _ := url.Parse("demux://127.0.0.1:0")
+	listener := (&demuxListenerFactory{}).New(uri, wcfg, tlsCfg, conns, nil, registry.New(), lanChecker)
+	supervisor.Add(listener)
+
+	var host string
+	for {
+		if addrs := listener.LANAddresses(); len(addrs) > 0 && addrs[0].Port() != "0" {
+			host = addrs[0].Host
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	expectBEP := func(t *testing.T, client internalConn) {
+		t.Helper()
+		server := <-conns
+		defer server.Close()
+		defer client.Close()
+		if _, err := client.Write([]byte("hello")); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 5)
+		if _, err := io.ReadFull(server, buf); err != nil || string(buf) != "hello" {
+			t.Fatalf("unexpected read %q, %v", buf, err)
+		}
+	}
+
+	t.Run("websocket", func(t *testing.T) {
+		dialer := (&webSocketDialerFactory{}).New(cfg.Options, tlsCfg, nil, lanChecker)
+		wsURI, _ := url.Parse("ws://" + host + "/syncthing")
+		client, err := dialer.Dial(ctx, deviceID, wsURI)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectBEP(t, client)
+	})
+
+	t.Run("tls", func(t *testing.T) {
+		dialer := (&tcpDialerFactory{}).New(cfg.Options, tlsCfg, registry.New(), lanChecker)
+		tcpURI, _ := url.Parse("tcp://" + host)
+		client, err := dialer.Dial(ctx, deviceID, tcpURI)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectBEP(t, client)
+	})
+
+	t.Run("fallback", func(t *testing.T) {
+		conn, err := net.Dial("tcp", host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		go func() {
+			_ = tls.Client(conn, &tls.Config{ServerName: "www.example.com"}).Handshake()
+		}()
+
+		backend, err := fallback.Accept()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer backend.Close()
+		first, err := bufio.NewReader(backend).Peek(1)
+		if err != nil || first[0] != tlsRecordTypeHandshake {
+			t.Fatalf("expected the client hello at the fallback, got %v, %v", first, err)
+		}
+	})
+}
+
+func TestMatchesHostname(t *testing.T) {
+	hostnames := []string{"sync.example.com"}
+	for host, expected := range map[string]bool{
+		"sync.example.com":     true,
+		"SYNC.example.com:443": true,
+		"www.example.com":      false,
+		"":                     false,
+	} {
+		if matchesHostname(hostnames, host) != expected {
+			t.Errorf("%q: expected %v", host, expected)
+		}
+	}
+}
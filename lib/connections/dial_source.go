@@ -0,0 +1,113 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+var errDialInterfaceAbsent = errors.New("dial interface is not present or down")
+
+// dialSource is the local address and/or interface that outgoing
+// connections are bound to.
+type dialSource struct {
+	address string
+	iface   string
+}
+
+// sourceFor returns the source binding for dialing the device: the
+// device's own setting if any, otherwise the global one.
+func (d *commonDialer) sourceFor(device protocol.DeviceID) dialSource {
+	if d.lanChecker == nil || d.lanChecker.cfg == nil {
+		return dialSource{}
+	}
+	if dev, ok := d.lanChecker.cfg.Device(device); ok && (dev.DialSourceAddress != "" || dev.DialInterface != "") {
+		return dialSource{address: dev.DialSourceAddress, iface: dev.DialInterface}
+	}
+	opts := d.lanChecker.cfg.Options()
+	return dialSource{address: opts.DialSourceAddress, iface: opts.DialInterface}
+}
+
+func (s dialSource) isSet() bool {
+	return s.address != "" || s.iface != ""
+}
+
+// localIP returns the IP address to bind to when connecting to the remote
+// IP. With only an interface given, an address of the interface in the
+// same family as the remote is used.
+func (s dialSource) localIP(remote net.IP) (net.IP, error) {
+	ip, err := s.resolve(remote)
+	dialSourceStatus.report(s.iface, err)
+	return ip, err
+}
+
+func (s dialSource) resolve(remote net.IP) (net.IP, error) {
+	var ifaceAddrs []net.Addr
+	if s.iface != "" {
+		iface, err := net.InterfaceByName(s.iface)
+		if err != nil || iface.Flags&net.FlagUp == 0 {
+			return nil, fmt.Errorf("%w: %s", errDialInterfaceAbsent, s.iface)
+		}
+		if ifaceAddrs, err = iface.Addrs(); err != nil {
+			return nil, fmt.Errorf("dial interface %s: %w", s.iface, err)
+		}
+	}
+
+	if s.address != "" {
+		ip := net.ParseIP(s.address)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid dial source address %q", s.address)
+		}
+		if (ip.To4() == nil) != (remote.To4() == nil) {
+			return nil, fmt.Errorf("dial source address %s can't reach %s", ip, remote)
+		}
+		return ip, nil
+	}
+
+	for _, addr := range ifaceAddrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok || (ipnet.IP.To4() == nil) != (remote.To4() == nil) {
+			continue
+		}
+		if ipnet.IP.IsLinkLocalUnicast() && !remote.IsLinkLocalUnicast() {
+			continue
+		}
+		return ipnet.IP, nil
+	}
+	return nil, fmt.Errorf("dial interface %s has no address that can reach %s", s.iface, remote)
+}
+
+// dialSourceStatus keeps track of which dial interfaces are missing, to
+// warn once when one goes away and tell when it's back.
+var dialSourceStatus = &dialSourceStatusTracker{missing: make(map[string]bool)}
+
+type dialSourceStatusTracker struct {
+	mut     sync.Mutex
+	missing map[string]bool
+}
+
+func (t *dialSourceStatusTracker) report(iface string, err error) {
+	if iface == "" {
+		return
+	}
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	absent := errors.Is(err, errDialInterfaceAbsent)
+	switch {
+	case absent && !t.missing[iface]:
+		slog.Warn("Interface for outgoing connections is not available; not connecting until it is", slog.String("interface", iface))
+	case !absent && t.missing[iface]:
+		slog.Info("Interface for outgoing connections is available again", slog.String("interface", iface))
+	}
+	t.missing[iface] = absent
+}
@@ -0,0 +1,48 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"errors"
+	"net"
+	"testing"
+)
+
+func TestDialSourceResolve(t *testing.T) {
+	remote4 := net.ParseIP("192.0.2.1")
+	remote6 := net.ParseIP("2001:db8::1")
+
+	ip, err := dialSource{address: "127.0.0.1"}.resolve(remote4)
+	if err != nil || !ip.Equal(net.ParseIP("127.0.0.1")) {
+		t.Errorf("got %v, %v", ip, err)
+	}
+	if _, err := (dialSource{address: "127.0.0.1"}).resolve(remote6); err == nil {
+		t.Error("expected an IPv4 source to be unusable for an IPv6 remote")
+	}
+	if _, err := (dialSource{iface: "doesnotexist0"}).resolve(remote4); !errors.Is(err, errDialInterfaceAbsent) {
+		t.Errorf("got %v, expected errDialInterfaceAbsent", err)
+	}
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagLoopback == 0 || iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+		ip, err := dialSource{iface: iface.Name}.resolve(net.ParseIP("127.0.0.2"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ip.IsLoopback() || ip.To4() == nil {
+			t.Errorf("got %v, expected an IPv4 loopback address", ip)
+		}
+		return
+	}
+	t.Log("no loopback interface to test with")
+}
@@ -0,0 +1,262 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+const (
+	// An address that hasn't worked for dialStatsAgeOut, and failed at
+	// least dialStatsAgeOutFailures times in a row since, is aged out:
+	// it's only dialed once per dialStatsAgedOutRedial, as long as the
+	// device has other addresses.
+	dialStatsAgeOut         = 90 * 24 * time.Hour
+	dialStatsAgeOutFailures = 10
+	dialStatsAgedOutRedial  = 24 * time.Hour
+
+	// Addresses not dialed for dialStatsForget, e.g. ones no longer
+	// announced, are dropped from the statistics.
+	dialStatsForget = 365 * 24 * time.Hour
+
+	// The success rate is over about the latest dialStatsMaxAttempts dials,
+	// the connect time the median of the latest dialStatsConnectTimes.
+	dialStatsMaxAttempts  = 64
+	dialStatsConnectTimes = 9
+)
+
+// addressDialStats is how dialing an address of a device has gone.
+type addressDialStats struct {
+	Attempts     int             `json:"attempts"`
+	Successes    int             `json:"successes"`
+	Failures     int             `json:"failures"` // in a row
+	ConnectTimes []time.Duration `json:"connectTimes"`
+	FirstAttempt time.Time       `json:"firstAttempt"`
+	LastAttempt  time.Time       `json:"lastAttempt"`
+	LastSuccess  time.Time       `json:"lastSuccess"`
+}
+
+func (a *addressDialStats) record(now time.Time, connectTime time.Duration, err error) {
+	if a.FirstAttempt.IsZero() {
+		a.FirstAttempt = now
+	}
+	a.LastAttempt = now
+	if a.Attempts >= dialStatsMaxAttempts {
+		// Halve the history so that the rate follows changes.
+		a.Attempts /= 2
+		a.Successes /= 2
+	}
+	a.Attempts++
+	if err != nil {
+		a.Failures++
+		return
+	}
+	a.Successes++
+	a.Failures = 0
+	a.LastSuccess = now
+	a.ConnectTimes = append(a.ConnectTimes, connectTime)
+	if len(a.ConnectTimes) > dialStatsConnectTimes {
+		a.ConnectTimes = a.ConnectTimes[len(a.ConnectTimes)-dialStatsConnectTimes:]
+	}
+}
+
+// successRate is the share of dials that succeeded, a neutral half for an
+// address not dialed yet.
+func (a *addressDialStats) successRate() float64 {
+	if a == nil || a.Attempts == 0 {
+		return 0.5
+	}
+	return float64(a.Successes) / float64(a.Attempts)
+}
+
+// medianConnectTime is zero for an address that never worked.
+func (a *addressDialStats) medianConnectTime() time.Duration {
+	if a == nil || len(a.ConnectTimes) == 0 {
+		return 0
+	}
+	times := slices.Clone(a.ConnectTimes)
+	slices.Sort(times)
+	return times[len(times)/2]
+}
+
+func (a *addressDialStats) agedOut(now time.Time) bool {
+	if a == nil || a.Failures < dialStatsAgeOutFailures {
+		return false
+	}
+	worked := a.LastSuccess
+	if worked.IsZero() {
+		worked = a.FirstAttempt
+	}
+	return now.Sub(worked) > dialStatsAgeOut
+}
+
+// dialStats keeps per device and address statistics on dials, in the
+// database once there is one, to order dial targets by how well they
+// worked and to stop dialing addresses that haven't in months.
+type dialStats struct {
+	mut     sync.Mutex
+	kv      db.KV // nil if the statistics aren't kept, see SetDatabase
+	devices map[protocol.DeviceID]map[string]*addressDialStats
+}
+
+func newDialStats() *dialStats {
+	return &dialStats{
+		devices: make(map[protocol.DeviceID]map[string]*addressDialStats),
+	}
+}
+
+func (d *dialStats) setKV(kv db.KV) {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	d.kv = kv
+	clear(d.devices) // loaded again as needed
+}
+
+func dialStatsKey(device protocol.DeviceID) string {
+	return "dialstats/" + device.String()
+}
+
+// deviceLocked returns the statistics of the device, loading them from the
+// database the first time.
+func (d *dialStats) deviceLocked(device protocol.DeviceID) map[string]*addressDialStats {
+	if addrs, ok := d.devices[device]; ok {
+		return addrs
+	}
+	addrs := make(map[string]*addressDialStats)
+	if d.kv != nil {
+		if bs, err := d.kv.GetKV(dialStatsKey(device)); err == nil {
+			if err := json.Unmarshal(bs, &addrs); err != nil {
+				l.Debugln("Unmarshalling dial statistics of", device.Short(), err)
+				addrs = make(map[string]*addressDialStats)
+			}
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			l.Debugln("Getting dial statistics of", device.Short(), err)
+		}
+	}
+	forgetStale(addrs, time.Now())
+	d.devices[device] = addrs
+	return addrs
+}
+
+// record notes the outcome of dialing the device at the address, which
+// took connectTime if it succeeded.
+func (d *dialStats) record(device protocol.DeviceID, addr string, now time.Time, connectTime time.Duration, err error) {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	addrs := d.deviceLocked(device)
+	a, ok := addrs[addr]
+	if !ok {
+		a = &addressDialStats{}
+		addrs[addr] = a
+	}
+	a.record(now, connectTime, err)
+	forgetStale(addrs, now)
+	if d.kv == nil {
+		return
+	}
+	bs, err := json.Marshal(addrs)
+	if err != nil {
+		return
+	}
+	if err := d.kv.PutKV(dialStatsKey(device), bs); err != nil {
+		l.Debugln("Saving dial statistics of", device.Short(), err)
+	}
+}
+
+func forgetStale(addrs map[string]*addressDialStats, now time.Time) {
+	for addr, a := range addrs {
+		if now.Sub(a.LastAttempt) > dialStatsForget {
+			delete(addrs, addr)
+		}
+	}
+}
+
+// forget drops the statistics of a device that is no longer configured.
+func (d *dialStats) forget(device protocol.DeviceID) {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	delete(d.devices, device)
+	if d.kv != nil {
+		_ = d.kv.DeleteKV(dialStatsKey(device))
+	}
+}
+
+// skip returns the aged out addresses among those of the device that aren't
+// due for their occasional redial. They aren't skipped when all the
+// addresses of the device have aged out, as then there's nothing better to
+// try.
+func (d *dialStats) skip(device protocol.DeviceID, addrs []string, now time.Time) map[string]bool {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	stats := d.deviceLocked(device)
+	skip := make(map[string]bool)
+	for _, addr := range addrs {
+		a := stats[addr]
+		if !a.agedOut(now) {
+			continue
+		}
+		if now.Sub(a.LastAttempt) > dialStatsAgedOutRedial {
+			continue
+		}
+		skip[addr] = true
+	}
+	for _, addr := range addrs {
+		if !stats[addr].agedOut(now) {
+			return skip
+		}
+	}
+	return nil
+}
+
+// failures returns how many times in a row dialing the device at the
+// address has failed.
+func (d *dialStats) failures(device protocol.DeviceID, addr string) int {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	if a := d.deviceLocked(device)[addr]; a != nil {
+		return a.Failures
+	}
+	return 0
+}
+
+// sort orders the dial targets of the device so that the addresses that
+// worked best, and then fastest, are dialed first.
+func (d *dialStats) sort(device protocol.DeviceID, targets []dialTarget) {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	stats := d.deviceLocked(device)
+	slices.SortStableFunc(targets, func(a, b dialTarget) int {
+		sa, sb := stats[a.addr], stats[b.addr]
+		if ra, rb := sa.successRate(), sb.successRate(); ra != rb {
+			if ra > rb {
+				return -1
+			}
+			return 1
+		}
+		ta, tb := sa.medianConnectTime(), sb.medianConnectTime()
+		switch {
+		case ta == tb:
+			return 0
+		case ta == 0:
+			return 1
+		case tb == 0:
+			return -1
+		case ta < tb:
+			return -1
+		default:
+			return 1
+		}
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db/sqlite"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestDialStatsOrder(t *testing.T) {
+	device := protocol.DeviceID{1}
+	now := time.Now()
+	errDial := errors.New("dial failed")
+
+	stats := newDialStats()
+	for range 4 {
+		stats.record(device, "tcp://flaky:22000", now, 10*time.Millisecond, errDial)
+		stats.record(device, "tcp://flaky:22000", now, 10*time.Millisecond, nil)
+		stats.record(device, "tcp://slow:22000", now, time.Second, nil)
+		stats.record(device, "tcp://fast:22000", now, 50*time.Millisecond, nil)
+	}
+
+	targets := []dialTarget{
+		{addr: "tcp://flaky:22000"},
+		{addr: "tcp://new:22000"},
+		{addr: "tcp://slow:22000"},
+		{addr: "tcp://fast:22000"},
+	}
+	stats.sort(device, targets)
+	expected := []string{"tcp://fast:22000", "tcp://slow:22000", "tcp://flaky:22000", "tcp://new:22000"}
+	for i, tgt := range targets {
+		if tgt.addr != expected[i] {
+			t.Errorf("target %d is %s, expected %s", i, tgt.addr, expected[i])
+		}
+	}
+}
+
+func TestDialStatsAgeOut(t *testing.T) {
+	device := protocol.DeviceID{1}
+	start := time.Now().Add(-2 * dialStatsAgeOut)
+	errDial := errors.New("dial failed")
+	addrs := []string{"tcp://old:22000", "tcp://current:22000"}
+
+	stats := newDialStats()
+	stats.record(device, "tcp://old:22000", start, time.Millisecond, nil)
+	now := time.Now()
+	for range dialStatsAgeOutFailures {
+		stats.record(device, "tcp://old:22000", now, 0, errDial)
+	}
+
+	// Without another address to dial, the old one is kept.
+	if skip := stats.skip(device, addrs[:1], now); skip["tcp://old:22000"] {
+		t.Error("expected the only address to be dialed")
+	}
+
+	stats.record(device, "tcp://current:22000", now, time.Millisecond, nil)
+	if skip := stats.skip(device, addrs, now); !skip["tcp://old:22000"] || skip["tcp://current:22000"] {
+		t.Errorf("expected the old address to be skipped, got %v", skip)
+	}
+
+	// It's still dialed once a day.
+	if skip := stats.skip(device, addrs, now.Add(dialStatsAgedOutRedial+time.Minute)); skip["tcp://old:22000"] {
+		t.Error("expected the old address to be due for a redial")
+	}
+}
+
+func TestDialStatsPersisted(t *testing.T) {
+	sdb, err := sqlite.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sdb.Close() })
+
+	device := protocol.DeviceID{1}
+	now := time.Now()
+
+	stats := newDialStats()
+	stats.setKV(sdb)
+	stats.record(device, "tcp://a:22000", now, time.Second, nil)
+	stats.record(device, "tcp://b:22000", now.Add(-2*dialStatsForget), 0, errors.New("dial failed"))
+
+	restarted := newDialStats()
+	restarted.setKV(sdb)
+	restarted.mut.Lock()
+	addrs := restarted.deviceLocked(device)
+	restarted.mut.Unlock()
+	if a := addrs["tcp://a:22000"]; a == nil || a.Successes != 1 || a.medianConnectTime() != time.Second {
+		t.Errorf("expected the statistics to be restored, got %+v", a)
+	}
+	if _, ok := addrs["tcp://b:22000"]; ok {
+		t.Error("expected the address not dialed in a long while to be forgotten")
+	}
+
+	restarted.forget(device)
+	if _, err := sdb.GetKV(dialStatsKey(device)); err == nil {
+		t.Error("expected the statistics of the device to be dropped")
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// The windows the error budgets of devices are counted over.
+const (
+	disconnectBudgetWindow = time.Hour
+	failedDialBudgetWindow = 24 * time.Hour
+)
+
+// DeviceErrorBudget is the state of the connection error budget of a
+// device. Once exhausted it stays so, whatever the counts do, until
+// acknowledged.
+type DeviceErrorBudget struct {
+	DeviceID protocol.DeviceID `json:"deviceID"`
+	// Disconnects in the last hour, and the budget for them
+	Disconnects             int `json:"disconnects"`
+	DisconnectBudgetPerHour int `json:"disconnectBudgetPerHour"`
+	// Failed dials in the last day, and the budget for them
+	FailedDials            int       `json:"failedDials"`
+	FailedDialBudgetPerDay int       `json:"failedDialBudgetPerDay"`
+	Exhausted              bool      `json:"exhausted"`
+	ExhaustedAt            time.Time `json:"exhaustedAt"`
+	// What exhausted the budget
+	Reason string `json:"reason,omitempty"`
+}
+
+type deviceErrors struct {
+	disconnects   []time.Time
+	failedDials   []time.Time
+	lastConnected time.Time
+	exhaustedAt   time.Time
+	reason        string
+}
+
+// errorBudgets counts the connection errors of each device against its
+// budget. Disconnects are counted when the last connection with the device
+// is lost. Failed dials are only counted for devices that were connected
+// within the day, so that devices that are simply offline, like a laptop
+// that is switched off, don't use up their budget.
+type errorBudgets struct {
+	mut     sync.Mutex
+	devices map[protocol.DeviceID]*deviceErrors
+}
+
+func (b *errorBudgets) deviceLocked(device protocol.DeviceID) *deviceErrors {
+	if b.devices == nil {
+		b.devices = make(map[protocol.DeviceID]*deviceErrors)
+	}
+	d, ok := b.devices[device]
+	if !ok {
+		d = &deviceErrors{}
+		b.devices[device] = d
+	}
+	return d
+}
+
+func (b *errorBudgets) recordConnected(device protocol.DeviceID, now time.Time) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	b.deviceLocked(device).lastConnected = now
+}
+
+// recordDisconnect counts a disconnect of the device, returning the budget
+// if this exhausted it.
+func (b *errorBudgets) recordDisconnect(dcfg config.DeviceConfiguration, now time.Time) (DeviceErrorBudget, bool) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	d := b.deviceLocked(dcfg.DeviceID)
+	d.lastConnected = now
+	d.disconnects = append(d.disconnects, now)
+	return b.checkLocked(dcfg, d, now)
+}
+
+// recordFailedDial counts a failed dial of the device, returning the
+// budget if this exhausted it.
+func (b *errorBudgets) recordFailedDial(dcfg config.DeviceConfiguration, now time.Time) (DeviceErrorBudget, bool) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	d, ok := b.devices[dcfg.DeviceID]
+	if !ok || now.Sub(d.lastConnected) > failedDialBudgetWindow {
+		return DeviceErrorBudget{}, false
+	}
+	d.failedDials = append(d.failedDials, now)
+	return b.checkLocked(dcfg, d, now)
+}
+
+func (b *errorBudgets) checkLocked(dcfg config.DeviceConfiguration, d *deviceErrors, now time.Time) (DeviceErrorBudget, bool) {
+	d.disconnects = dropBefore(d.disconnects, now.Add(-disconnectBudgetWindow))
+	d.failedDials = dropBefore(d.failedDials, now.Add(-failedDialBudgetWindow))
+	if !d.exhaustedAt.IsZero() {
+		return DeviceErrorBudget{}, false
+	}
+	switch {
+	case dcfg.DisconnectBudgetPerHour > 0 && len(d.disconnects) > dcfg.DisconnectBudgetPerHour:
+		d.reason = "too many disconnects"
+	case dcfg.FailedDialBudgetPerDay > 0 && len(d.failedDials) > dcfg.FailedDialBudgetPerDay:
+		d.reason = "too many failed dials"
+	default:
+		return DeviceErrorBudget{}, false
+	}
+	d.exhaustedAt = now
+	return d.budget(dcfg), true
+}
+
+// dropBefore removes the times before the cutoff from the sorted times.
+func dropBefore(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && times[i].Before(cutoff) {
+		i++
+	}
+	return times[i:]
+}
+
+func (d *deviceErrors) budget(dcfg config.DeviceConfiguration) DeviceErrorBudget {
+	return DeviceErrorBudget{
+		DeviceID:                dcfg.DeviceID,
+		Disconnects:             len(d.disconnects),
+		DisconnectBudgetPerHour: dcfg.DisconnectBudgetPerHour,
+		FailedDials:             len(d.failedDials),
+		FailedDialBudgetPerDay:  dcfg.FailedDialBudgetPerDay,
+		Exhausted:               !d.exhaustedAt.IsZero(),
+		ExhaustedAt:             d.exhaustedAt,
+		Reason:                  d.reason,
+	}
+}
+
+// budgets returns the error budgets of the configured devices that have
+// had errors.
+func (b *errorBudgets) budgets(devices map[protocol.DeviceID]config.DeviceConfiguration, now time.Time) map[string]DeviceErrorBudget {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	res := make(map[string]DeviceErrorBudget)
+	for id, d := range b.devices {
+		dcfg, ok := devices[id]
+		if !ok {
+			delete(b.devices, id)
+			continue
+		}
+		d.disconnects = dropBefore(d.disconnects, now.Add(-disconnectBudgetWindow))
+		d.failedDials = dropBefore(d.failedDials, now.Add(-failedDialBudgetWindow))
+		if len(d.disconnects) == 0 && len(d.failedDials) == 0 && d.exhaustedAt.IsZero() {
+			continue
+		}
+		res[id.String()] = d.budget(dcfg)
+	}
+	return res
+}
+
+// acknowledge clears the exhausted state of the device's budget, returning
+// whether it was exhausted.
+func (b *errorBudgets) acknowledge(device protocol.DeviceID) bool {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	d, ok := b.devices[device]
+	if !ok || d.exhaustedAt.IsZero() {
+		return false
+	}
+	d.exhaustedAt = time.Time{}
+	d.reason = ""
+	return true
+}
+
+// ConnectionErrorBudgets returns the connection error budgets of devices,
+// by device ID.
+func (s *service) ConnectionErrorBudgets() map[string]DeviceErrorBudget {
+	return s.errorBudgets.budgets(s.cfg.Devices(), time.Now())
+}
+
+// AcknowledgeConnectionErrorBudget clears the warning about the device
+// having exhausted its connection error budget.
+func (s *service) AcknowledgeConnectionErrorBudget(device protocol.DeviceID) bool {
+	return s.errorBudgets.acknowledge(device)
+}
+
+func (s *service) recordDisconnect(device protocol.DeviceID) {
+	dcfg, ok := s.cfg.Device(device)
+	if !ok {
+		return
+	}
+	if budget, exhausted := s.errorBudgets.recordDisconnect(dcfg, time.Now().Truncate(time.Second)); exhausted {
+		s.reportErrorBudgetExhausted(dcfg, budget)
+	}
+}
+
+func (s *service) recordFailedDial(device protocol.DeviceID) {
+	dcfg, ok := s.cfg.Device(device)
+	if !ok {
+		return
+	}
+	if budget, exhausted := s.errorBudgets.recordFailedDial(dcfg, time.Now().Truncate(time.Second)); exhausted {
+		s.reportErrorBudgetExhausted(dcfg, budget)
+	}
+}
+
+func (s *service) reportErrorBudgetExhausted(dcfg config.DeviceConfiguration, budget DeviceErrorBudget) {
+	slog.Warn("Connection with device is unreliable, error budget exhausted", dcfg.DeviceID.LogAttr(), slog.String("reason", budget.Reason), slog.Int("disconnectsLastHour", budget.Disconnects), slog.Int("failedDialsLastDay", budget.FailedDials))
+	s.evLogger.Log(events.DeviceErrorBudgetExhausted, budget)
+}
@@ -0,0 +1,89 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestErrorBudgetDisconnects(t *testing.T) {
+	var b errorBudgets
+	dcfg := config.DeviceConfiguration{DeviceID: protocol.DeviceID{1}, DisconnectBudgetPerHour: 3}
+	devices := map[protocol.DeviceID]config.DeviceConfiguration{dcfg.DeviceID: dcfg}
+	now := time.Unix(1000000, 0)
+
+	for i := range 3 {
+		if _, exhausted := b.recordDisconnect(dcfg, now.Add(time.Duration(i)*time.Minute)); exhausted {
+			t.Fatal("budget should not be exhausted yet")
+		}
+	}
+	// Disconnects older than an hour no longer count.
+	if _, exhausted := b.recordDisconnect(dcfg, now.Add(61*time.Minute)); exhausted {
+		t.Fatal("budget should not be exhausted by old disconnects")
+	}
+	now = now.Add(63 * time.Minute)
+	for range 2 {
+		b.recordDisconnect(dcfg, now)
+	}
+	budget, exhausted := b.recordDisconnect(dcfg, now)
+	if !exhausted || budget.Disconnects != 4 || budget.Reason == "" {
+		t.Fatalf("expected exhausted budget with 4 disconnects, got %+v", budget)
+	}
+	if _, exhausted := b.recordDisconnect(dcfg, now); exhausted {
+		t.Error("exhaustion should only be reported once")
+	}
+
+	// The state stays after the disconnects have aged out, until
+	// acknowledged.
+	later := now.Add(2 * time.Hour)
+	if budget := b.budgets(devices, later)[dcfg.DeviceID.String()]; !budget.Exhausted || budget.Disconnects != 0 {
+		t.Errorf("expected exhausted budget without recent disconnects, got %+v", budget)
+	}
+	if !b.acknowledge(dcfg.DeviceID) {
+		t.Fatal("expected acknowledging to succeed")
+	}
+	if b.acknowledge(dcfg.DeviceID) {
+		t.Error("expected nothing left to acknowledge")
+	}
+	if budgets := b.budgets(devices, later); len(budgets) != 0 {
+		t.Errorf("expected no budgets, got %v", budgets)
+	}
+}
+
+func TestErrorBudgetFailedDials(t *testing.T) {
+	var b errorBudgets
+	dcfg := config.DeviceConfiguration{DeviceID: protocol.DeviceID{1}, FailedDialBudgetPerDay: 2}
+	now := time.Unix(1000000, 0)
+
+	// Failed dials of a device that hasn't been connected don't count.
+	for range 5 {
+		if _, exhausted := b.recordFailedDial(dcfg, now); exhausted {
+			t.Fatal("failed dials of an absent device should not count")
+		}
+	}
+
+	b.recordConnected(dcfg.DeviceID, now)
+	b.recordFailedDial(dcfg, now.Add(time.Minute))
+	b.recordFailedDial(dcfg, now.Add(2*time.Minute))
+	if _, exhausted := b.recordFailedDial(dcfg, now.Add(3*time.Minute)); !exhausted {
+		t.Error("expected exhausted budget")
+	}
+
+	// A zero budget is disabled.
+	dcfg.DeviceID = protocol.DeviceID{2}
+	dcfg.FailedDialBudgetPerDay = 0
+	b.recordConnected(dcfg.DeviceID, now)
+	for range 5 {
+		if _, exhausted := b.recordFailedDial(dcfg, now); exhausted {
+			t.Fatal("a zero budget should never be exhausted")
+		}
+	}
+}
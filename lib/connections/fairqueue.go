@@ -0,0 +1,184 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+
+	"golang.org/x/time/rate"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// trafficClassWeights are the shares of the traffic classes of devices
+// when waiting for the overall limiter; a background device gets one
+// part in seventeen while a normal one is waiting as well.
+var trafficClassWeights = map[string]float64{
+	config.TrafficClassNormal:     16,
+	config.TrafficClassBackground: 1,
+}
+
+// A fairQueue shares a rate limiter between devices by weighted fair
+// queueing over their traffic classes, rather than first come first
+// served. Each wait is tagged with a virtual finish time, advancing by its
+// size over the weight of its class, and the waits take turns on the
+// limiter in order of their tags (self-clocked fair queueing).
+type fairQueue struct {
+	limiter *rate.Limiter
+
+	mut     sync.Mutex
+	classes map[protocol.DeviceID]string
+	finish  map[string]float64 // tag of the latest wait of each class
+	vtime   float64            // tag of the wait having its turn
+	queue   fairTickets
+	busy    bool // a wait has its turn
+}
+
+func newFairQueue(limiter *rate.Limiter) *fairQueue {
+	return &fairQueue{
+		limiter: limiter,
+		classes: make(map[protocol.DeviceID]string),
+		finish:  make(map[string]float64),
+	}
+}
+
+// setClass sets the traffic class of the device, removing it when empty.
+func (q *fairQueue) setClass(device protocol.DeviceID, class string) {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+	if class == "" {
+		delete(q.classes, device)
+		return
+	}
+	q.classes[device] = class
+}
+
+// forDevice returns a waiter for the device on the queue
+func (q *fairQueue) forDevice(device protocol.DeviceID) waiter {
+	return fairWaiter{queue: q, device: device}
+}
+
+// waitN waits for the turn of the device and then for the limiter to
+// allow n bytes.
+func (q *fairQueue) waitN(ctx context.Context, device protocol.DeviceID, n int) error {
+	q.mut.Lock()
+	t := q.enqueueLocked(device, n)
+	q.dispatchLocked()
+	q.mut.Unlock()
+
+	select {
+	case <-t.ready:
+	case <-ctx.Done():
+		q.mut.Lock()
+		select {
+		case <-t.ready:
+			// Got the turn meanwhile; pass it on.
+			q.busy = false
+		default:
+			heap.Remove(&q.queue, t.index)
+		}
+		q.dispatchLocked()
+		q.mut.Unlock()
+		return ctx.Err()
+	}
+
+	err := q.limiter.WaitN(ctx, n)
+	q.mut.Lock()
+	q.busy = false
+	q.dispatchLocked()
+	q.mut.Unlock()
+	return err
+}
+
+// enqueueLocked queues a wait of n bytes for the device, tagged according
+// to its class.
+func (q *fairQueue) enqueueLocked(device protocol.DeviceID, n int) *fairTicket {
+	class := q.classes[device]
+	weight, ok := trafficClassWeights[class]
+	if !ok {
+		class = config.TrafficClassNormal
+		weight = trafficClassWeights[class]
+	}
+	t := &fairTicket{
+		finish: max(q.vtime, q.finish[class]) + float64(n)/weight,
+		ready:  make(chan struct{}),
+	}
+	q.finish[class] = t.finish
+	heap.Push(&q.queue, t)
+	return t
+}
+
+// dispatchLocked gives the turn to the wait with the lowest tag, if no
+// wait has it.
+func (q *fairQueue) dispatchLocked() {
+	if q.busy {
+		return
+	}
+	if len(q.queue) == 0 {
+		// Idle; start over so that the tags don't grow forever.
+		q.vtime = 0
+		clear(q.finish)
+		return
+	}
+	t := heap.Pop(&q.queue).(*fairTicket)
+	q.vtime = t.finish
+	q.busy = true
+	close(t.ready)
+}
+
+// fairWaiter waits on a fairQueue for a device
+type fairWaiter struct {
+	queue  *fairQueue
+	device protocol.DeviceID
+}
+
+func (w fairWaiter) WaitN(ctx context.Context, n int) error {
+	return w.queue.waitN(ctx, w.device, n)
+}
+
+func (w fairWaiter) Limit() rate.Limit {
+	return w.queue.limiter.Limit()
+}
+
+func (w fairWaiter) Burst() int {
+	return w.queue.limiter.Burst()
+}
+
+type fairTicket struct {
+	finish float64
+	index  int
+	ready  chan struct{}
+}
+
+// fairTickets is a heap of tickets by finish tag
+type fairTickets []*fairTicket
+
+func (ts fairTickets) Len() int           { return len(ts) }
+func (ts fairTickets) Less(i, j int) bool { return ts[i].finish < ts[j].finish }
+
+func (ts fairTickets) Swap(i, j int) {
+	ts[i], ts[j] = ts[j], ts[i]
+	ts[i].index = i
+	ts[j].index = j
+}
+
+func (ts *fairTickets) Push(x any) {
+	t := x.(*fairTicket)
+	t.index = len(*ts)
+	*ts = append(*ts, t)
+}
+
+func (ts *fairTickets) Pop() any {
+	old := *ts
+	t := old[len(old)-1]
+	old[len(old)-1] = nil
+	*ts = old[:len(old)-1]
+	return t
+}
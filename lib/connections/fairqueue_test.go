@@ -0,0 +1,86 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/time/rate"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestFairQueueOrder(t *testing.T) {
+	q := newFairQueue(rate.NewLimiter(rate.Inf, limiterBurstSize))
+	normal, background := protocol.DeviceID{1}, protocol.DeviceID{2}
+	q.setClass(normal, config.TrafficClassNormal)
+	q.setClass(background, config.TrafficClassBackground)
+
+	// With another wait having the turn, both devices queue up waits of
+	// the same size, background first.
+	q.busy = true
+	classOf := make(map[*fairTicket]string)
+	for range 4 {
+		classOf[q.enqueueLocked(background, 16<<10)] = config.TrafficClassBackground
+		classOf[q.enqueueLocked(normal, 16<<10)] = config.TrafficClassNormal
+	}
+
+	var order []string
+	for q.queue.Len() > 0 {
+		q.busy = false
+		q.dispatchLocked()
+		for t, class := range classOf {
+			select {
+			case <-t.ready:
+				order = append(order, class)
+				delete(classOf, t)
+			default:
+			}
+		}
+	}
+	for i, class := range order {
+		expected := config.TrafficClassNormal
+		if i >= 4 {
+			expected = config.TrafficClassBackground
+		}
+		if class != expected {
+			t.Fatalf("Expected the normal device to get all its turns first, got %v", order)
+		}
+	}
+
+	// Once idle the tags start over.
+	q.busy = false
+	q.dispatchLocked()
+	if q.vtime != 0 || len(q.finish) != 0 {
+		t.Error("Expected the tags to be reset when idle")
+	}
+}
+
+func TestFairQueueCancel(t *testing.T) {
+	q := newFairQueue(rate.NewLimiter(rate.Inf, limiterBurstSize))
+	device := protocol.DeviceID{1}
+
+	q.busy = true
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := q.forDevice(device).WaitN(ctx, 1024); err != context.Canceled {
+		t.Fatal("Expected the wait to be cancelled, got", err)
+	}
+	if q.queue.Len() != 0 {
+		t.Fatal("Expected the cancelled wait to leave the queue")
+	}
+
+	q.busy = false
+	if err := q.forDevice(device).WaitN(context.Background(), 1024); err != nil {
+		t.Fatal(err)
+	}
+	if q.busy {
+		t.Error("Expected the turn to be given up after the wait")
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// localHealthKey is what the health of addresses not tied to a device, such
+// as those of listeners, is reported under.
+const localHealthKey = "local"
+
+// ConnectionHealthReport is the health of connections as seen by the
+// health monitor.
+type ConnectionHealthReport struct {
+	// The overall health score, 0-100, and the adaptive keep-alive interval
+	// it results in
+	HealthScore        float64 `json:"healthScore"`
+	KeepAliveIntervalS float64 `json:"keepAliveIntervalS"`
+	LatencyMs          float64 `json:"latencyMs"`
+	PacketLossPercent  float64 `json:"packetLossPercent"`
+	// By device ID, or "local", and address
+	Devices map[string]map[string]AddressHealth `json:"devices"`
+}
+
+// AddressHealth is the outcome of the recent connection attempts with an
+// address.
+type AddressHealth struct {
+	Healthy bool `json:"healthy"`
+	// 0-100, from the share of the recent attempts that succeeded
+	HealthScore       float64   `json:"healthScore"`
+	ErrorCategory     string    `json:"errorCategory,omitempty"`
+	ConsecutiveErrors int       `json:"consecutiveErrors"`
+	SuccessCount      int       `json:"successCount"`
+	LastError         *string   `json:"lastError"`
+	LastErrorTime     time.Time `json:"lastErrorTime"`
+	LastSuccessTime   time.Time `json:"lastSuccessTime"`
+}
+
+// report returns the current state of the monitor.
+func (hm *HealthMonitor) report() ConnectionHealthReport {
+	metrics := hm.GetConnectionQualityMetrics()
+	res := ConnectionHealthReport{
+		HealthScore:        hm.GetHealthScore(),
+		KeepAliveIntervalS: hm.GetInterval().Seconds(),
+		LatencyMs:          metrics["latencyMs"],
+		PacketLossPercent:  metrics["packetLossPercent"],
+		Devices:            make(map[string]map[string]AddressHealth),
+	}
+	for id, addrs := range hm.GetAllConnectionHealth() {
+		key := id.String()
+		if id == protocol.LocalDeviceID {
+			key = localHealthKey
+		}
+		devRes := make(map[string]AddressHealth, len(addrs))
+		for addr, h := range addrs {
+			ah := AddressHealth{
+				Healthy:           h.IsHealthy,
+				HealthScore:       addressHealthScore(h),
+				ConsecutiveErrors: h.ConsecutiveErrors,
+				SuccessCount:      h.SuccessCount,
+				LastErrorTime:     h.LastErrorTime,
+				LastSuccessTime:   h.LastSuccessTime,
+			}
+			if h.LastError != nil {
+				errStr := h.LastError.Error()
+				ah.LastError = &errStr
+				ah.ErrorCategory = h.ErrorCategory.String()
+			}
+			devRes[addr] = ah
+		}
+		res.Devices[key] = devRes
+	}
+	return res
+}
+
+// addressHealthScore is the error rate of the address turned into a score
+// on the same scale as the overall one.
+func addressHealthScore(h *ConnectionHealth) float64 {
+	total := h.SuccessCount + h.ConsecutiveErrors
+	if total == 0 {
+		return 100
+	}
+	return 100 * float64(h.SuccessCount) / float64(total)
+}
+
+// ConnectionHealth returns the health of connections, overall and by
+// device and address.
+func (s *service) ConnectionHealth() ConnectionHealthReport {
+	return s.healthMonitor.report()
+}
@@ -0,0 +1,38 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestHealthReport(t *testing.T) {
+	hm := NewHealthMonitor(10)
+	dev := protocol.DeviceID{1}
+	hm.RecordConnectionSuccess(dev, "192.0.2.1:22000")
+	hm.RecordConnectionError(dev, "192.0.2.1:22000", context.DeadlineExceeded)
+	hm.RecordConnectionError(protocol.LocalDeviceID, "0.0.0.0:22000", errors.New("connection reset by peer"))
+
+	report := hm.report()
+	if report.KeepAliveIntervalS <= 0 {
+		t.Errorf("expected a keep-alive interval, got %v", report.KeepAliveIntervalS)
+	}
+	addr, ok := report.Devices[dev.String()]["192.0.2.1:22000"]
+	if !ok {
+		t.Fatalf("missing device address in %+v", report.Devices)
+	}
+	if addr.Healthy || addr.HealthScore != 50 || addr.ErrorCategory != "timeout" || addr.LastError == nil {
+		t.Errorf("unexpected address health %+v", addr)
+	}
+	if _, ok := report.Devices[localHealthKey]["0.0.0.0:22000"]; !ok {
+		t.Errorf("missing local address in %+v", report.Devices)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/osutil"
+	"github.com/syncthing/syncthing/lib/relay/client"
+)
+
+const (
+	infraKindDiscovery = "discovery"
+	infraKindRelayPool = "relayPool"
+
+	// Failed probes in a row before a server is reported unreachable
+	infraProbeWarnFailures = 3
+	infraProbeTimeout      = 30 * time.Second
+	// How often the configuration is checked while probing is disabled
+	infraProbeDisabledRecheck = time.Minute
+)
+
+// InfrastructureStatusEntry is the outcome of probing a global discovery
+// server or relay pool endpoint, or of NAT64 discovery on an IPv6 only
+// network.
+type InfrastructureStatusEntry struct {
+	Kind        string    `json:"kind"`
+	LastProbe   time.Time `json:"lastProbe"`
+	LastSuccess time.Time `json:"lastSuccess"`
+	LatencyMs   float64   `json:"latencyMs"` // of the last successful probe
+	Error       *string   `json:"error"`
+	Failures    int       `json:"failures"`         // consecutive
+	Relays      int       `json:"relays,omitempty"` // in the last fetched list of a relay pool
+	Prefix      string    `json:"prefix,omitempty"` // found by NAT64 discovery
+}
+
+// infraProber periodically checks that the discovery servers and relay
+// pools in use are reachable, refreshing the relay pool lists on the way,
+// so that dead entries in the configuration don't go unnoticed.
+type infraProber struct {
+	cfg config.Wrapper
+
+	mut    sync.Mutex
+	status map[string]InfrastructureStatusEntry // address -> status
+}
+
+func newInfraProber(cfg config.Wrapper) *infraProber {
+	return &infraProber{
+		cfg:    cfg,
+		status: make(map[string]InfrastructureStatusEntry),
+	}
+}
+
+func (p *infraProber) serve(ctx context.Context) error {
+	for {
+		interval := time.Duration(p.cfg.Options().InfraProbeIntervalS) * time.Second
+		if interval > 0 {
+			p.probeAll(ctx)
+		} else {
+			p.mut.Lock()
+			clear(p.status)
+			p.mut.Unlock()
+			interval = infraProbeDisabledRecheck
+		}
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (p *infraProber) probeAll(ctx context.Context) {
+	opts := p.cfg.Options()
+	targets := make(map[string]string) // address -> kind
+	if opts.GlobalAnnEnabled {
+		for _, srv := range opts.GlobalDiscoveryServers() {
+			targets[srv] = infraKindDiscovery
+		}
+	}
+	if opts.RelaysEnabled {
+		for _, addr := range opts.ListenAddresses() {
+			if strings.HasPrefix(addr, "dynamic+") {
+				targets[addr] = infraKindRelayPool
+			}
+		}
+	}
+
+	p.mut.Lock()
+	maps.DeleteFunc(p.status, func(addr string, _ InfrastructureStatusEntry) bool {
+		_, ok := targets[addr]
+		return !ok
+	})
+	p.mut.Unlock()
+
+	for addr, kind := range targets {
+		var latency time.Duration
+		var relays int
+		var err error
+		if kind == infraKindRelayPool {
+			latency, relays, err = probeRelayPool(ctx, addr)
+		} else {
+			latency, err = probeDiscoveryServer(ctx, addr)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		p.record(addr, kind, latency, relays, err)
+	}
+}
+
+func (p *infraProber) record(addr, kind string, latency time.Duration, relays int, err error) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	entry := p.status[addr]
+	entry.Kind = kind
+	entry.LastProbe = time.Now()
+	if err != nil {
+		msg := err.Error()
+		entry.Error = &msg
+		entry.Failures++
+		if entry.Failures == infraProbeWarnFailures {
+			slog.Warn("Infrastructure server is unreachable", slog.String("kind", kind), slog.String("address", addr), slogutil.Error(err))
+		}
+		p.status[addr] = entry
+		return
+	}
+	if entry.Failures >= infraProbeWarnFailures {
+		slog.Info("Infrastructure server is reachable again", slog.String("kind", kind), slog.String("address", addr))
+	}
+	entry.Error = nil
+	entry.Failures = 0
+	entry.LastSuccess = entry.LastProbe
+	entry.LatencyMs = float64(latency) / float64(time.Millisecond)
+	if kind == infraKindRelayPool {
+		entry.Relays = relays
+	}
+	p.status[addr] = entry
+}
+
+func (p *infraProber) statuses() map[string]InfrastructureStatusEntry {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	return maps.Clone(p.status)
+}
+
+// probeDiscoveryServer measures the time to establish a TCP connection to
+// the discovery server.
+func probeDiscoveryServer(ctx context.Context, addr string) (time.Duration, error) {
+	uri, err := url.Parse(addr)
+	if err != nil {
+		return 0, err
+	}
+	host := uri.Host
+	if uri.Port() == "" {
+		host = net.JoinHostPort(uri.Hostname(), "443")
+	}
+	return osutil.TCPPing(ctx, host)
+}
+
+// probeRelayPool fetches the relay list of the pool, which the dynamic relay
+// clients use from then on, and measures the time it took.
+func probeRelayPool(ctx context.Context, addr string) (time.Duration, int, error) {
+	uri, err := url.Parse(addr)
+	if err != nil {
+		return 0, 0, err
+	}
+	uri.Scheme = strings.TrimPrefix(uri.Scheme, "dynamic+")
+
+	ctx, cancel := context.WithTimeout(ctx, infraProbeTimeout)
+	defer cancel()
+	t0 := time.Now()
+	relays, err := client.LookupRelayPool(ctx, uri)
+	return time.Since(t0), len(relays), err
+}
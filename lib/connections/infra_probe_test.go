@@ -0,0 +1,50 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestProbeRelayPool(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte(`{"relays": [{"url": "relay://192.0.2.1:22067"}, {"url": "relay://192.0.2.2:22067"}]}`))
+	}))
+	defer srv.Close()
+
+	_, relays, err := probeRelayPool(context.Background(), "dynamic+"+srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if relays != 2 {
+		t.Errorf("got %d relays, expected 2", relays)
+	}
+}
+
+func TestInfraProberRecord(t *testing.T) {
+	p := newInfraProber(nil)
+	const addr = "https://discovery.example.com/v2/"
+
+	for i := 0; i < infraProbeWarnFailures; i++ {
+		p.record(addr, infraKindDiscovery, 0, 0, errors.New("unreachable"))
+	}
+	entry := p.statuses()[addr]
+	if entry.Failures != infraProbeWarnFailures || entry.Error == nil || !entry.LastSuccess.IsZero() {
+		t.Errorf("unexpected status after failures: %+v", entry)
+	}
+
+	p.record(addr, infraKindDiscovery, 20*time.Millisecond, 0, nil)
+	entry = p.statuses()[addr]
+	if entry.Failures != 0 || entry.Error != nil || entry.LatencyMs != 20 || entry.LastSuccess.IsZero() {
+		t.Errorf("unexpected status after success: %+v", entry)
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+)
+
+const (
+	infraKindNAT64 = "nat64"
+
+	// With DNS64 in place this name resolves to its well known IPv4
+	// addresses, synthesized under the NAT64 prefix in use (RFC 7050).
+	nat64DiscoveryName    = "ipv4only.arpa"
+	nat64LookupTimeout    = 5 * time.Second
+	nat64RefreshInterval  = 30 * time.Minute
+	nat64FailureRetryTime = time.Minute
+)
+
+var (
+	nat64WellKnownAddrs = []netip.Addr{
+		netip.AddrFrom4([4]byte{192, 0, 0, 170}),
+		netip.AddrFrom4([4]byte{192, 0, 0, 171}),
+	}
+	// The prefix lengths allowed by RFC 6052, most common first
+	nat64PrefixLengths = []int{96, 64, 56, 48, 40, 32}
+
+	errIPv4Unreachable = errors.New("IPv4 address unreachable on IPv6 only network without NAT64")
+)
+
+// nat64Detector finds the NAT64 prefix of the network, if any, caching the
+// outcome for a while.
+type nat64Detector struct {
+	lookup func(ctx context.Context, host string) ([]netip.Addr, error)
+
+	mut    sync.Mutex
+	prefix netip.Prefix
+	next   time.Time // when to look again
+	status InfrastructureStatusEntry
+}
+
+func newNAT64Detector() *nat64Detector {
+	return &nat64Detector{
+		lookup: func(ctx context.Context, host string) ([]netip.Addr, error) {
+			return net.DefaultResolver.LookupNetIP(ctx, "ip6", host)
+		},
+	}
+}
+
+// Prefix returns the NAT64 prefix, looking it up if it's due.
+func (d *nat64Detector) Prefix(ctx context.Context) (netip.Prefix, bool) {
+	d.mut.Lock()
+	prefix, next := d.prefix, d.next
+	d.mut.Unlock()
+	if time.Now().Before(next) {
+		return prefix, prefix.IsValid()
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, nat64LookupTimeout)
+	defer cancel()
+	t0 := time.Now()
+	addrs, err := d.lookup(ctx, nat64DiscoveryName)
+	latency := time.Since(t0)
+	if err == nil {
+		prefix = netip.Prefix{}
+		for _, addr := range addrs {
+			if p, ok := nat64PrefixOf(addr); ok {
+				prefix = p
+				break
+			}
+		}
+		if !prefix.IsValid() {
+			err = errors.New("no NAT64 prefix in DNS64 response")
+		}
+	}
+
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	d.status.Kind = infraKindNAT64
+	d.status.LastProbe = t0
+	if err != nil {
+		if d.prefix.IsValid() || d.status.Failures == 0 {
+			slog.Info("No NAT64 found, IPv4 addresses are unreachable", slogutil.Error(err))
+		}
+		msg := err.Error()
+		d.status.Error = &msg
+		d.status.Failures++
+		d.status.Prefix = ""
+		d.prefix = netip.Prefix{}
+		d.next = time.Now().Add(nat64FailureRetryTime)
+		return netip.Prefix{}, false
+	}
+	if prefix != d.prefix {
+		slog.Info("Using NAT64 to reach IPv4 addresses", slog.String("prefix", prefix.String()))
+	}
+	d.status.Error = nil
+	d.status.Failures = 0
+	d.status.LastSuccess = t0
+	d.status.LatencyMs = float64(latency) / float64(time.Millisecond)
+	d.status.Prefix = prefix.String()
+	d.prefix = prefix
+	d.next = time.Now().Add(nat64RefreshInterval)
+	return prefix, true
+}
+
+// Status returns the outcome of the latest lookup, if any.
+func (d *nat64Detector) Status() (InfrastructureStatusEntry, bool) {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	return d.status, !d.status.LastProbe.IsZero()
+}
+
+// nat64PrefixOf returns the prefix under which one of the well known IPv4
+// addresses is embedded in the address.
+func nat64PrefixOf(addr netip.Addr) (netip.Prefix, bool) {
+	if !addr.Is6() || addr.Is4In6() {
+		return netip.Prefix{}, false
+	}
+	for _, bits := range nat64PrefixLengths {
+		prefix := netip.PrefixFrom(addr, bits).Masked()
+		for _, v4 := range nat64WellKnownAddrs {
+			if embedIPv4(prefix, v4) == addr {
+				return prefix, true
+			}
+		}
+	}
+	return netip.Prefix{}, false
+}
+
+// embedIPv4 returns the IPv6 address for the IPv4 address under the NAT64
+// prefix, as laid out in RFC 6052: following the prefix, skipping the
+// reserved bits 64 to 71.
+func embedIPv4(prefix netip.Prefix, v4 netip.Addr) netip.Addr {
+	b := prefix.Masked().Addr().As16()
+	pos := prefix.Bits() / 8
+	for _, c := range v4.As4() {
+		if pos == 8 {
+			pos++
+		}
+		b[pos] = c
+		pos++
+	}
+	return netip.AddrFrom16(b)
+}
+
+// isIPv4Address returns true if the address can only be used over IPv4:
+// it has an IPv4 specific scheme, or an IPv4 literal host other than the
+// unspecified address (which listens on IPv6 as well).
+func isIPv4Address(uri *url.URL) bool {
+	if strings.HasSuffix(uri.Scheme, "4") {
+		return true
+	}
+	addr, err := netip.ParseAddr(uri.Hostname())
+	return err == nil && addr.Unmap().Is4() && !addr.IsUnspecified()
+}
+
+// ipv6OnlyDialTarget returns the address to dial on an IPv6 only network.
+// IPv4 literals are reached through NAT64, if there is one, and are
+// otherwise an error rather than dialed until they time out.
+func ipv6OnlyDialTarget(ctx context.Context, uri *url.URL, nat64 *nat64Detector) (*url.URL, error) {
+	addr, err := netip.ParseAddr(uri.Hostname())
+	if err != nil || !addr.Unmap().Is4() {
+		return uri, nil
+	}
+	prefix, ok := nat64.Prefix(ctx)
+	if !ok {
+		return nil, errIPv4Unreachable
+	}
+	synthesized := embedIPv4(prefix, addr.Unmap()).String()
+	target := *uri
+	target.Scheme = strings.TrimSuffix(uri.Scheme, "4")
+	target.Host = "[" + synthesized + "]"
+	if port := uri.Port(); port != "" {
+		target.Host = net.JoinHostPort(synthesized, port)
+	}
+	return &target, nil
+}
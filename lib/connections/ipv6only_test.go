@@ -0,0 +1,107 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"net/url"
+	"testing"
+)
+
+func TestNAT64Prefix(t *testing.T) {
+	cases := []struct {
+		synthesized string
+		prefix      string
+	}{
+		{"64:ff9b::c000:aa", "64:ff9b::/96"},
+		{"2001:db8:122:344:c0:0:aa00:0", "2001:db8:122:344::/64"},
+		{"2001:db8:c000:aa::", "2001:db8::/32"},
+		{"2001:db8:1c0:0:aa::", "2001:db8:100::/40"},
+	}
+	for _, tc := range cases {
+		prefix, ok := nat64PrefixOf(netip.MustParseAddr(tc.synthesized))
+		if !ok || prefix.String() != tc.prefix {
+			t.Errorf("prefix of %s: got %v, %v, expected %s", tc.synthesized, prefix, ok, tc.prefix)
+		}
+	}
+
+	if _, ok := nat64PrefixOf(netip.MustParseAddr("2001:db8::1")); ok {
+		t.Error("unexpected prefix for an ordinary address")
+	}
+}
+
+func TestIPv6OnlyDialTarget(t *testing.T) {
+	var lookups int
+	var lookupErr error
+	d := newNAT64Detector()
+	d.lookup = func(context.Context, string) ([]netip.Addr, error) {
+		lookups++
+		return []netip.Addr{netip.MustParseAddr("64:ff9b::c000:aa")}, lookupErr
+	}
+
+	target := func(addr string) (string, error) {
+		uri, err := url.Parse(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := ipv6OnlyDialTarget(context.Background(), uri, d)
+		if err != nil {
+			return "", err
+		}
+		return res.String(), nil
+	}
+
+	for addr, expected := range map[string]string{
+		"tcp://192.0.2.1:22000":         "tcp://[64:ff9b::c000:201]:22000",
+		"quic4://192.0.2.1:22000":       "quic://[64:ff9b::c000:201]:22000",
+		"relay://192.0.2.1:22067/?id=x": "relay://[64:ff9b::c000:201]:22067/?id=x",
+		"tcp://[2001:db8::1]:22000":     "tcp://[2001:db8::1]:22000",
+		"tcp://example.com:22000":       "tcp://example.com:22000",
+	} {
+		if res, err := target(addr); err != nil || res != expected {
+			t.Errorf("target for %s: got %q, %v, expected %q", addr, res, err, expected)
+		}
+	}
+	if lookups != 1 {
+		t.Errorf("expected the prefix to be looked up once, got %d", lookups)
+	}
+	if status, ok := d.Status(); !ok || status.Prefix != "64:ff9b::/96" {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	// Without NAT64, IPv4 addresses are not dialed at all.
+	lookupErr = errors.New("no such host")
+	d.next = d.next.AddDate(-1, 0, 0)
+	if _, err := target("tcp://192.0.2.1:22000"); !errors.Is(err, errIPv4Unreachable) {
+		t.Errorf("expected IPv4 address to be unreachable, got %v", err)
+	}
+	if res, err := target("tcp://[2001:db8::1]:22000"); err != nil || res != "tcp://[2001:db8::1]:22000" {
+		t.Errorf("expected IPv6 address to be dialed as is, got %q, %v", res, err)
+	}
+}
+
+func TestIsIPv4Address(t *testing.T) {
+	for addr, expected := range map[string]bool{
+		"tcp://0.0.0.0:22000":      false,
+		"tcp://:22000":             false,
+		"tcp://[::]:22000":         false,
+		"tcp4://:22000":            true,
+		"quic4://:22000":           true,
+		"tcp://192.168.1.2:22000":  true,
+		"relay://192.0.2.1:22067/": true,
+	} {
+		uri, err := url.Parse(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isIPv4Address(uri) != expected {
+			t.Errorf("isIPv4Address(%s) != %v", addr, expected)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"log/slog"
+	"maps"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/osutil"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// LAN heartbeats are small UDP packets sent between devices connected over
+// the LAN, many times a second, so that a device going away is noticed
+// within a second instead of when the connection times out. A heartbeat
+// carries the sender device ID and a sequence number, authenticated with a
+// key agreed on from the device keys of both sides, so they can't be
+// forged or replayed by others on the network.
+
+const (
+	lanHeartbeatMagic   = 0x4c484231 // "LHB1"
+	lanHeartbeatInfo    = "syncthing lan heartbeat"
+	lanHeartbeatMACSize = 16
+	lanHeartbeatSize    = 4 + 32 + 8 + lanHeartbeatMACSize
+
+	// Intervals without a heartbeat before a device is considered gone
+	lanHeartbeatMissed = 3
+	// How long heartbeats are still sent to a device after its last LAN
+	// connection closed, to notice it coming back
+	lanHeartbeatRetain = 10 * time.Minute
+	// How long to wait before trying again when the socket can't be opened
+	lanHeartbeatRetry = time.Minute
+)
+
+var errLANHeartbeatLost = errors.New("LAN heartbeats lost")
+
+type lanHeartbeatPeer struct {
+	cert      *x509.Certificate
+	key       *[32]byte
+	keyErr    error
+	ip        net.IP
+	conns     map[string]protocol.Connection // connection ID -> connection
+	closed    time.Time                      // when the last connection closed
+	lastSeen  time.Time
+	lastSeq   int64
+	receiving bool // heartbeats arrive as expected
+}
+
+// lanHeartbeats sends heartbeats to the devices connected over the LAN and
+// keeps track of theirs. It calls lost with the LAN connections of a device
+// whose heartbeats stopped, and seen for a device not connected whose
+// heartbeats start arriving.
+type lanHeartbeats struct {
+	cfg        config.Wrapper
+	myID       protocol.DeviceID
+	privateKey func() crypto.PrivateKey
+	lost       func(protocol.DeviceID, []protocol.Connection)
+	seen       func(protocol.DeviceID)
+	changed    chan struct{}
+
+	mut   sync.Mutex
+	peers map[protocol.DeviceID]*lanHeartbeatPeer
+}
+
+func newLANHeartbeats(cfg config.Wrapper, myID protocol.DeviceID, privateKey func() crypto.PrivateKey, lost func(protocol.DeviceID, []protocol.Connection), seen func(protocol.DeviceID)) *lanHeartbeats {
+	return &lanHeartbeats{
+		cfg:        cfg,
+		myID:       myID,
+		privateKey: privateKey,
+		lost:       lost,
+		seen:       seen,
+		changed:    make(chan struct{}, 1),
+		peers:      make(map[protocol.DeviceID]*lanHeartbeatPeer),
+	}
+}
+
+// add starts sending heartbeats to the device of a LAN connection.
+func (h *lanHeartbeats) add(device protocol.DeviceID, cert *x509.Certificate, conn protocol.Connection) {
+	ip, err := osutil.IPFromAddr(conn.RemoteAddr())
+	if err != nil {
+		return
+	}
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	p, ok := h.peers[device]
+	if !ok {
+		p = &lanHeartbeatPeer{cert: cert, conns: make(map[string]protocol.Connection)}
+		h.peers[device] = p
+	}
+	p.ip = ip
+	p.conns[conn.ConnectionID()] = conn
+}
+
+// remove forgets a closed connection.
+func (h *lanHeartbeats) remove(device protocol.DeviceID, connID string) {
+	h.mut.Lock()
+	defer h.mut.Unlock()
+	p, ok := h.peers[device]
+	if !ok {
+		return
+	}
+	delete(p.conns, connID)
+	if len(p.conns) == 0 {
+		p.closed = time.Now()
+	}
+}
+
+// configChanged makes serve pick up changed options.
+func (h *lanHeartbeats) configChanged() {
+	select {
+	case h.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (h *lanHeartbeats) serve(ctx context.Context) error {
+	for {
+		opts := h.cfg.Options()
+		var retry <-chan time.Time
+		if opts.LANHeartbeatEnabled {
+			err := h.run(ctx, opts)
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err == nil {
+				// The options changed
+				continue
+			}
+			slog.Warn("Failed to start LAN heartbeats", slog.Int("port", opts.LANHeartbeatPort), slogutil.Error(err))
+			retry = time.After(lanHeartbeatRetry)
+		}
+		select {
+		case <-h.changed:
+		case <-retry:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// run sends and receives heartbeats until the context is cancelled or the
+// heartbeat options change.
+func (h *lanHeartbeats) run(ctx context.Context, opts config.OptionsConfiguration) error {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: opts.LANHeartbeatPort})
+	if err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	go func() {
+		h.receive(conn)
+		close(done)
+	}()
+	defer func() {
+		conn.Close()
+		<-done
+	}()
+
+	interval := time.Duration(opts.LANHeartbeatIntervalMs) * time.Millisecond
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			h.send(conn, opts.LANHeartbeatPort)
+			h.check(interval)
+		case <-h.changed:
+			cur := h.cfg.Options()
+			if cur.LANHeartbeatEnabled != opts.LANHeartbeatEnabled || cur.LANHeartbeatPort != opts.LANHeartbeatPort || cur.LANHeartbeatIntervalMs != opts.LANHeartbeatIntervalMs {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (h *lanHeartbeats) send(conn *net.UDPConn, port int) {
+	type heartbeat struct {
+		addr *net.UDPAddr
+		pkt  []byte
+	}
+
+	now := time.Now()
+	var heartbeats []heartbeat
+	h.mut.Lock()
+	for device, p := range h.peers {
+		if len(p.conns) == 0 && now.Sub(p.closed) > lanHeartbeatRetain {
+			delete(h.peers, device)
+			continue
+		}
+		key, err := h.keyLocked(device, p)
+		if err != nil {
+			continue
+		}
+		heartbeats = append(heartbeats, heartbeat{
+			addr: &net.UDPAddr{IP: p.ip, Port: port},
+			pkt:  lanHeartbeatPacket(key, h.myID, device, now.UnixNano()),
+		})
+	}
+	h.mut.Unlock()
+
+	for _, hb := range heartbeats {
+		if _, err := conn.WriteToUDP(hb.pkt, hb.addr); err != nil {
+			l.Debugf("Sending LAN heartbeat to %v: %v", hb.addr, err)
+		}
+	}
+}
+
+// check reports the devices whose heartbeats stopped.
+func (h *lanHeartbeats) check(interval time.Duration) {
+	deadline := time.Now().Add(-lanHeartbeatMissed * interval)
+	lost := make(map[protocol.DeviceID][]protocol.Connection)
+	h.mut.Lock()
+	for device, p := range h.peers {
+		if !p.receiving || p.lastSeen.After(deadline) {
+			continue
+		}
+		p.receiving = false
+		if len(p.conns) > 0 {
+			lost[device] = slices.Collect(maps.Values(p.conns))
+		}
+	}
+	h.mut.Unlock()
+
+	for device, conns := range lost {
+		h.lost(device, conns)
+	}
+}
+
+func (h *lanHeartbeats) receive(conn *net.UDPConn) {
+	// One byte more than a heartbeat, to tell longer packets apart.
+	buf := make([]byte, lanHeartbeatSize+1)
+	for {
+		n, addr, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		if n == lanHeartbeatSize {
+			h.handle(buf[:n], addr)
+		}
+	}
+}
+
+func (h *lanHeartbeats) handle(pkt []byte, addr *net.UDPAddr) {
+	if binary.BigEndian.Uint32(pkt) != lanHeartbeatMagic {
+		return
+	}
+	sender, err := protocol.DeviceIDFromBytes(pkt[4:36])
+	if err != nil {
+		return
+	}
+	seq := int64(binary.BigEndian.Uint64(pkt[36:44]))
+
+	h.mut.Lock()
+	p, ok := h.peers[sender]
+	if !ok {
+		h.mut.Unlock()
+		return
+	}
+	key, err := h.keyLocked(sender, p)
+	if err != nil || seq <= p.lastSeq || !hmac.Equal(pkt, lanHeartbeatPacket(key, sender, h.myID, seq)) {
+		h.mut.Unlock()
+		return
+	}
+	p.lastSeq = seq
+	p.lastSeen = time.Now()
+	back := !p.receiving && len(p.conns) == 0
+	if !p.receiving {
+		l.Debugf("Receiving LAN heartbeats from %s at %v", sender, addr)
+		p.receiving = true
+	}
+	h.mut.Unlock()
+
+	if back {
+		h.seen(sender)
+	}
+}
+
+// keyLocked returns the heartbeat key for the device, deriving it the
+// first time.
+func (h *lanHeartbeats) keyLocked(device protocol.DeviceID, p *lanHeartbeatPeer) (*[32]byte, error) {
+	if p.key == nil && p.keyErr == nil {
+		p.key, p.keyErr = protocol.DevicePairKey(h.privateKey(), h.myID, p.cert, lanHeartbeatInfo)
+		if p.keyErr != nil {
+			slog.Warn("Not sending LAN heartbeats to device", device.LogAttr(), slogutil.Error(p.keyErr))
+		}
+	}
+	return p.key, p.keyErr
+}
+
+// lanHeartbeatPacket returns the heartbeat from sender to recipient, with
+// a MAC over all of it and the recipient.
+func lanHeartbeatPacket(key *[32]byte, sender, recipient protocol.DeviceID, seq int64) []byte {
+	pkt := make([]byte, 0, lanHeartbeatSize)
+	pkt = binary.BigEndian.AppendUint32(pkt, lanHeartbeatMagic)
+	pkt = append(pkt, sender[:]...)
+	pkt = binary.BigEndian.AppendUint64(pkt, uint64(seq))
+	mac := hmac.New(sha256.New, key[:])
+	mac.Write(pkt)
+	mac.Write(recipient[:])
+	return mac.Sum(pkt)[:lanHeartbeatSize]
+}
@@ -0,0 +1,125 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"crypto"
+	"crypto/x509"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+	protocolmocks "github.com/syncthing/syncthing/lib/protocol/mocks"
+)
+
+type lanHeartbeatTestDevice struct {
+	id   protocol.DeviceID
+	cert *x509.Certificate
+	hb   *lanHeartbeats
+	lost []protocol.Connection
+	seen []protocol.DeviceID
+}
+
+func newLANHeartbeatTestDevice(t *testing.T) *lanHeartbeatTestDevice {
+	t.Helper()
+	tlsCert := mustGetCert(t)
+	cert, err := x509.ParseCertificate(tlsCert.Certificate[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &lanHeartbeatTestDevice{id: protocol.NewDeviceID(tlsCert.Certificate[0]), cert: cert}
+	d.hb = newLANHeartbeats(nil, d.id, func() crypto.PrivateKey { return tlsCert.PrivateKey },
+		func(_ protocol.DeviceID, conns []protocol.Connection) { d.lost = append(d.lost, conns...) },
+		func(device protocol.DeviceID) { d.seen = append(d.seen, device) })
+	return d
+}
+
+// connect adds a LAN connection to other on both sides.
+func (d *lanHeartbeatTestDevice) connect(other *lanHeartbeatTestDevice) {
+	for _, pair := range [][2]*lanHeartbeatTestDevice{{d, other}, {other, d}} {
+		c := &protocolmocks.Connection{}
+		c.DeviceIDReturns(pair[1].id)
+		c.ConnectionIDReturns("lan")
+		c.RemoteAddrReturns(&net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 22000})
+		pair[0].hb.add(pair[1].id, pair[1].cert, c)
+	}
+}
+
+// heartbeat returns a heartbeat from d to other.
+func (d *lanHeartbeatTestDevice) heartbeat(t *testing.T, other *lanHeartbeatTestDevice, seq int64) []byte {
+	t.Helper()
+	d.hb.mut.Lock()
+	defer d.hb.mut.Unlock()
+	key, err := d.hb.keyLocked(other.id, d.hb.peers[other.id])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lanHeartbeatPacket(key, d.id, other.id, seq)
+}
+
+func TestLANHeartbeats(t *testing.T) {
+	a := newLANHeartbeatTestDevice(t)
+	b := newLANHeartbeatTestDevice(t)
+	c := newLANHeartbeatTestDevice(t)
+	a.connect(b)
+	a.connect(c)
+	from := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 21028}
+	interval := 250 * time.Millisecond
+
+	receiving := func() bool {
+		a.hb.mut.Lock()
+		defer a.hb.mut.Unlock()
+		return a.hb.peers[b.id].receiving
+	}
+
+	// A heartbeat meant for another device, or from a device that isn't
+	// who it claims to be, doesn't count.
+	a.hb.handle(b.heartbeat(t, c, 1), from)
+	forged := c.heartbeat(t, a, 1)
+	copy(forged[4:36], b.id[:])
+	a.hb.handle(forged, from)
+	if receiving() {
+		t.Fatal("accepted a heartbeat not from b to a")
+	}
+
+	// Without heartbeats ever arriving, the connection is left alone.
+	a.hb.check(interval)
+	if len(a.lost) != 0 {
+		t.Fatal("lost a device that never sent heartbeats")
+	}
+
+	a.hb.handle(b.heartbeat(t, a, 1), from)
+	if !receiving() {
+		t.Fatal("heartbeat from b not accepted")
+	}
+	a.hb.check(interval)
+	if len(a.lost) != 0 {
+		t.Fatal("lost a device sending heartbeats")
+	}
+
+	// Heartbeats stop, or only replayed ones arrive.
+	a.hb.mut.Lock()
+	a.hb.peers[b.id].lastSeen = time.Now().Add(-time.Second)
+	a.hb.mut.Unlock()
+	a.hb.handle(b.heartbeat(t, a, 1), from)
+	a.hb.check(interval)
+	if len(a.lost) != 1 || a.lost[0].DeviceID() != b.id {
+		t.Fatalf("expected the connection to b to be lost, got %v", a.lost)
+	}
+	if receiving() {
+		t.Error("still receiving after heartbeats stopped")
+	}
+
+	// Once the connection is closed, heartbeats coming back make us dial.
+	a.hb.remove(b.id, "lan")
+	a.hb.handle(b.heartbeat(t, a, 2), from)
+	a.hb.handle(b.heartbeat(t, a, 3), from)
+	if len(a.seen) != 1 || a.seen[0] != b.id {
+		t.Errorf("expected to dial b once, got %v", a.seen)
+	}
+}
@@ -14,6 +14,7 @@ import (
 	"sync"
 	"sync/atomic"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/time/rate"
 
 	"github.com/syncthing/syncthing/lib/config"
@@ -27,15 +28,29 @@ type limiter struct {
 	mu                  sync.Mutex
 	write               *rate.Limiter
 	read                *rate.Limiter
+	writeQueue          *fairQueue // shares write between devices
+	readQueue           *fairQueue // shares read between devices
 	limitsLAN           atomic.Bool
 	deviceReadLimiters  map[protocol.DeviceID]*rate.Limiter
 	deviceWriteLimiters map[protocol.DeviceID]*rate.Limiter
+	classLimiters       map[string]*classLimiter
+	meteredNetworks     []string
+	// Folders have limiters only while limited
+	folderReadLimiters  map[string]*rate.Limiter
+	folderWriteLimiters map[string]*rate.Limiter
+}
+
+// classLimiter limits all connections of a bandwidth class together.
+type classLimiter struct {
+	read, write *rate.Limiter
 }
 
 type waiter interface {
 	// This is the rate limiting operation
 	WaitN(ctx context.Context, n int) error
 	Limit() rate.Limit
+	// No single WaitN may be for more than this
+	Burst() int
 }
 
 const (
@@ -49,6 +64,17 @@ func newLimiter(myId protocol.DeviceID, cfg config.Wrapper) *limiter {
 		read:                rate.NewLimiter(rate.Inf, limiterBurstSize),
 		deviceReadLimiters:  make(map[protocol.DeviceID]*rate.Limiter),
 		deviceWriteLimiters: make(map[protocol.DeviceID]*rate.Limiter),
+		classLimiters:       make(map[string]*classLimiter, len(config.BandwidthClasses)),
+		folderReadLimiters:  make(map[string]*rate.Limiter),
+		folderWriteLimiters: make(map[string]*rate.Limiter),
+	}
+	l.writeQueue = newFairQueue(l.write)
+	l.readQueue = newFairQueue(l.read)
+	for _, class := range config.BandwidthClasses {
+		l.classLimiters[class] = &classLimiter{
+			read:  rate.NewLimiter(rate.Inf, limiterBurstSize),
+			write: rate.NewLimiter(rate.Inf, limiterBurstSize),
+		}
 	}
 
 	cfg.Subscribe(l)
@@ -96,6 +122,8 @@ func (lim *limiter) processDevicesConfigurationLocked(from, to config.Configurat
 			continue
 		}
 		seen[dev.DeviceID] = struct{}{}
+		lim.readQueue.setClass(dev.DeviceID, dev.TrafficClass)
+		lim.writeQueue.setClass(dev.DeviceID, dev.TrafficClass)
 
 		if lim.setLimitsLocked(dev) {
 			readLimitStr := "is unlimited"
@@ -116,6 +144,8 @@ func (lim *limiter) processDevicesConfigurationLocked(from, to config.Configurat
 		if _, ok := seen[dev.DeviceID]; !ok {
 			delete(lim.deviceWriteLimiters, dev.DeviceID)
 			delete(lim.deviceReadLimiters, dev.DeviceID)
+			lim.readQueue.setClass(dev.DeviceID, "")
+			lim.writeQueue.setClass(dev.DeviceID, "")
 		}
 	}
 }
@@ -127,6 +157,8 @@ func (lim *limiter) CommitConfiguration(from, to config.Configuration) bool {
 
 	// Delete, add or update limiters for devices
 	lim.processDevicesConfigurationLocked(from, to)
+	lim.processClassesConfigurationLocked(to.Options)
+	lim.processFoldersConfigurationLocked(to.Folders)
 
 	if from.Options.MaxRecvKbps == to.Options.MaxRecvKbps &&
 		from.Options.MaxSendKbps == to.Options.MaxSendKbps &&
@@ -171,35 +203,182 @@ func (lim *limiter) CommitConfiguration(from, to config.Configuration) bool {
 	return true
 }
 
+// processClassesConfigurationLocked sets the limits of the bandwidth
+// classes; classes without configuration are unlimited.
+func (lim *limiter) processClassesConfigurationLocked(opts config.OptionsConfiguration) {
+	lim.meteredNetworks = opts.MeteredNetworks
+	for _, class := range config.BandwidthClasses {
+		cl := lim.classLimiters[class]
+		cfg, _ := opts.BandwidthClass(class)
+
+		recv, send := rate.Inf, rate.Inf
+		if cfg.MaxRecvKbps > 0 {
+			recv = 1024 * rate.Limit(cfg.MaxRecvKbps)
+		}
+		if cfg.MaxSendKbps > 0 {
+			send = 1024 * rate.Limit(cfg.MaxSendKbps)
+		}
+		burst := limiterBurstSize
+		if cfg.BurstKiB > 0 {
+			burst = cfg.BurstKiB << 10
+		}
+		metricClassLimit.WithLabelValues(class, metricDirectionRecv).Set(limitValue(recv))
+		metricClassLimit.WithLabelValues(class, metricDirectionSend).Set(limitValue(send))
+		if cl.read.Limit() == recv && cl.write.Limit() == send && cl.read.Burst() == burst {
+			continue
+		}
+
+		cl.read.SetLimit(recv)
+		cl.write.SetLimit(send)
+		cl.read.SetBurst(burst)
+		cl.write.SetBurst(burst)
+		if recv != rate.Inf || send != rate.Inf {
+			slog.Info("Bandwidth class is rate limited", slog.String("class", class), slog.Int("sendKiBps", cfg.MaxSendKbps), slog.Int("recvKiBps", cfg.MaxRecvKbps), slog.Int("burstKiB", burst>>10))
+		}
+	}
+}
+
+// processFoldersConfigurationLocked sets the limits of the folders.
+func (lim *limiter) processFoldersConfigurationLocked(folders []config.FolderConfiguration) {
+	seen := make(map[string]struct{}, len(folders))
+	for _, folder := range folders {
+		seen[folder.ID] = struct{}{}
+		readChanged := setFolderLimit(lim.folderReadLimiters, folder.ID, folder.MaxRecvKbps)
+		writeChanged := setFolderLimit(lim.folderWriteLimiters, folder.ID, folder.MaxSendKbps)
+		if readChanged || writeChanged {
+			slog.Info("Folder rate limit changed", folder.LogAttr(), slog.Int("sendKiBps", folder.MaxSendKbps), slog.Int("recvKiBps", folder.MaxRecvKbps))
+		}
+	}
+	for id := range lim.folderReadLimiters {
+		if _, ok := seen[id]; !ok {
+			delete(lim.folderReadLimiters, id)
+		}
+	}
+	for id := range lim.folderWriteLimiters {
+		if _, ok := seen[id]; !ok {
+			delete(lim.folderWriteLimiters, id)
+		}
+	}
+}
+
+// setFolderLimit sets the limit of the folder in KiB/s, removing its
+// limiter when unlimited, and returns whether it changed.
+func setFolderLimit(m map[string]*rate.Limiter, folder string, kbps int) bool {
+	cur, ok := m[folder]
+	if kbps <= 0 {
+		delete(m, folder)
+		return ok
+	}
+	limit := 1024 * rate.Limit(kbps)
+	switch {
+	case !ok:
+		m[folder] = rate.NewLimiter(limit, limiterBurstSize)
+	case cur.Limit() != limit:
+		cur.SetLimit(limit)
+	default:
+		return false
+	}
+	return true
+}
+
+// waitFolder waits until the limiter of the folder in m, if it has one,
+// allows n bytes. Unlike the limits on the connections, which see only
+// bytes, this paces the block requests of each folder.
+func (lim *limiter) waitFolder(ctx context.Context, m map[string]*rate.Limiter, folder string, n int) error {
+	lim.mu.Lock()
+	fl := m[folder]
+	lim.mu.Unlock()
+	if fl == nil {
+		return nil
+	}
+	for n > 0 {
+		// No single WaitN may be for more than the burst size.
+		tokens := min(n, fl.Burst())
+		if err := fl.WaitN(ctx, tokens); err != nil {
+			return err
+		}
+		n -= tokens
+	}
+	return nil
+}
+
+// LimitFolderSend waits until the bandwidth limit of the folder, if any,
+// allows sending n bytes of block data.
+func (s *service) LimitFolderSend(ctx context.Context, folder string, n int) error {
+	return s.limiter.waitFolder(ctx, s.limiter.folderWriteLimiters, folder, n)
+}
+
+// LimitFolderRecv waits until the bandwidth limit of the folder, if any,
+// allows requesting n bytes of block data.
+func (s *service) LimitFolderRecv(ctx context.Context, folder string, n int) error {
+	return s.limiter.waitFolder(ctx, s.limiter.folderReadLimiters, folder, n)
+}
+
+// limitValue returns the limit for metrics, zero meaning unlimited.
+func limitValue(limit rate.Limit) float64 {
+	if limit == rate.Inf {
+		return 0
+	}
+	return float64(limit)
+}
+
+// bandwidthClass returns the class of the connection: metered if its local
+// address is in a metered network, otherwise relay, LAN or WAN.
+func (lim *limiter) bandwidthClass(c internalConn) string {
+	lim.mu.Lock()
+	metered := lim.meteredNetworks
+	lim.mu.Unlock()
+	switch {
+	case len(metered) > 0 && c.LocalAddr() != nil && IsAllowedNetwork(c.LocalAddr().String(), metered):
+		return config.BandwidthClassMetered
+	case c.connType.Transport() == "relay":
+		return config.BandwidthClassRelay
+	case c.IsLocal():
+		return config.BandwidthClassLAN
+	default:
+		return config.BandwidthClassWAN
+	}
+}
+
 func (*limiter) String() string {
 	// required by config.Committer interface
 	return "connections.limiter"
 }
 
-func (lim *limiter) getLimiters(remoteID protocol.DeviceID, rw io.ReadWriter, isLAN bool) (io.Reader, io.Writer) {
+// getLimiters wraps the connection in the limiters of the device, the
+// bandwidth class and overall, the latter shared between devices by their
+// traffic class. With LimitBandwidthInLan unset only the bandwidth class
+// limits apply to LAN connections.
+func (lim *limiter) getLimiters(remoteID protocol.DeviceID, rw io.ReadWriter, isLAN bool, class string) (io.Reader, io.Writer) {
 	lim.mu.Lock()
-	wr := lim.newLimitedWriterLocked(remoteID, rw, isLAN)
-	rd := lim.newLimitedReaderLocked(remoteID, rw, isLAN)
+	wr := lim.newLimitedWriterLocked(remoteID, rw, isLAN, class)
+	rd := lim.newLimitedReaderLocked(remoteID, rw, isLAN, class)
 	lim.mu.Unlock()
 	return rd, wr
 }
 
-func (lim *limiter) newLimitedReaderLocked(remoteID protocol.DeviceID, r io.Reader, isLAN bool) io.Reader {
+func (lim *limiter) newLimitedReaderLocked(remoteID protocol.DeviceID, r io.Reader, isLAN bool, class string) io.Reader {
+	cl := lim.classLimiters[class]
 	return &limitedReader{
 		reader: r,
 		waiterHolder: waiterHolder{
-			waiter:    totalWaiter{lim.getReadLimiterLocked(remoteID), lim.read},
+			waiter:    totalWaiter{lim.getReadLimiterLocked(remoteID), lim.readQueue.forDevice(remoteID)},
+			class:     cl.read,
+			usage:     newClassUsage(class, metricDirectionRecv, cl.read),
 			limitsLAN: &lim.limitsLAN,
 			isLAN:     isLAN,
 		},
 	}
 }
 
-func (lim *limiter) newLimitedWriterLocked(remoteID protocol.DeviceID, w io.Writer, isLAN bool) io.Writer {
+func (lim *limiter) newLimitedWriterLocked(remoteID protocol.DeviceID, w io.Writer, isLAN bool, class string) io.Writer {
+	cl := lim.classLimiters[class]
 	return &limitedWriter{
 		writer: w,
 		waiterHolder: waiterHolder{
-			waiter:    totalWaiter{lim.getWriteLimiterLocked(remoteID), lim.write},
+			waiter:    totalWaiter{lim.getWriteLimiterLocked(remoteID), lim.writeQueue.forDevice(remoteID)},
+			class:     cl.write,
+			usage:     newClassUsage(class, metricDirectionSend, cl.write),
 			limitsLAN: &lim.limitsLAN,
 			isLAN:     isLAN,
 		},
@@ -231,6 +410,7 @@ type limitedReader struct {
 
 func (r *limitedReader) Read(buf []byte) (int, error) {
 	n, err := r.reader.Read(buf)
+	r.usage.add(n)
 	if !r.unlimited() {
 		r.take(n)
 	}
@@ -245,8 +425,11 @@ type limitedWriter struct {
 
 func (w *limitedWriter) Write(buf []byte) (int, error) {
 	if w.unlimited() {
-		return w.writer.Write(buf)
+		n, err := w.writer.Write(buf)
+		w.usage.add(n)
+		return n, err
 	}
+	wt := w.active()
 
 	// This does (potentially) multiple smaller writes in order to be less
 	// bursty with large writes and slow rates. At the same time we don't
@@ -254,10 +437,10 @@ func (w *limitedWriter) Write(buf []byte) (int, error) {
 	// try to be a bit adaptable. We range from the minimum write size of 1
 	// KiB up to the limiter burst size, aiming for about a write every
 	// 10ms.
-	singleWriteSize := int(w.waiter.Limit() / 100)          // 10ms worth of data
+	singleWriteSize := int(wt.Limit() / 100)                // 10ms worth of data
 	singleWriteSize = ((singleWriteSize / 1024) + 1) * 1024 // round up to the next kibibyte
-	if singleWriteSize > limiterBurstSize {
-		singleWriteSize = limiterBurstSize
+	if singleWriteSize > wt.Burst() {
+		singleWriteSize = wt.Burst()
 	}
 
 	written := 0
@@ -268,6 +451,7 @@ func (w *limitedWriter) Write(buf []byte) (int, error) {
 		}
 		w.take(toWrite)
 		n, err := w.writer.Write(buf[written : written+toWrite])
+		w.usage.add(n)
 		written += n
 		if err != nil {
 			return written, err
@@ -281,16 +465,31 @@ func (w *limitedWriter) Write(buf []byte) (int, error) {
 // waiter, valid for both writers and readers
 type waiterHolder struct {
 	waiter    waiter
+	class     waiter      // bandwidth class limiter, may be nil
+	usage     *classUsage // may be nil
 	limitsLAN *atomic.Bool
 	isLAN     bool
 }
 
+// active returns the waiters in effect, or nil if none
+func (w waiterHolder) active() waiter {
+	exempt := w.isLAN && !w.limitsLAN.Load()
+	switch {
+	case w.class == nil && exempt:
+		return nil
+	case w.class == nil:
+		return w.waiter
+	case exempt:
+		return w.class
+	default:
+		return totalWaiter{w.waiter, w.class}
+	}
+}
+
 // unlimited returns true if the waiter is not limiting the rate
 func (w waiterHolder) unlimited() bool {
-	if w.isLAN && !w.limitsLAN.Load() {
-		return true
-	}
-	return w.waiter.Limit() == rate.Inf
+	wt := w.active()
+	return wt == nil || wt.Limit() == rate.Inf
 }
 
 // take is a utility function to consume tokens, because no call to WaitN
@@ -302,20 +501,27 @@ func (w waiterHolder) take(tokens int) {
 	// into the lower level reads so we might get a large amount of data and
 	// end up in the loop further down.
 
-	if tokens <= limiterBurstSize {
+	wt := w.active()
+	if wt == nil {
+		return
+	}
+	defer w.usage.track()
+
+	burst := wt.Burst()
+	if tokens <= burst {
 		// Fast path. We won't get an error from WaitN as we don't pass a
 		// context with a deadline.
-		_ = w.waiter.WaitN(context.TODO(), tokens)
+		_ = wt.WaitN(context.TODO(), tokens)
 		return
 	}
 
 	for tokens > 0 {
-		// Consume limiterBurstSize tokens at a time until we're done.
-		if tokens > limiterBurstSize {
-			_ = w.waiter.WaitN(context.TODO(), limiterBurstSize)
-			tokens -= limiterBurstSize
+		// Consume burst size tokens at a time until we're done.
+		if tokens > burst {
+			_ = wt.WaitN(context.TODO(), burst)
+			tokens -= burst
 		} else {
-			_ = w.waiter.WaitN(context.TODO(), tokens)
+			_ = wt.WaitN(context.TODO(), tokens)
 			tokens = 0
 		}
 	}
@@ -335,6 +541,14 @@ func (tw totalWaiter) WaitN(ctx context.Context, n int) error {
 	return nil
 }
 
+func (tw totalWaiter) Burst() int {
+	burst := limiterBurstSize
+	for _, w := range tw {
+		burst = min(burst, w.Burst())
+	}
+	return burst
+}
+
 func (tw totalWaiter) Limit() rate.Limit {
 	min := rate.Inf
 	for _, w := range tw {
@@ -344,3 +558,31 @@ func (tw totalWaiter) Limit() rate.Limit {
 	}
 	return min
 }
+
+// classUsage tracks the data passing through the limiter of a bandwidth
+// class, and the tokens left in its bucket, for metrics.
+type classUsage struct {
+	limiter *rate.Limiter
+	bytes   prometheus.Counter
+	tokens  prometheus.Gauge
+}
+
+func newClassUsage(class, direction string, limiter *rate.Limiter) *classUsage {
+	return &classUsage{
+		limiter: limiter,
+		bytes:   metricClassBytes.WithLabelValues(class, direction),
+		tokens:  metricClassBurstTokens.WithLabelValues(class, direction),
+	}
+}
+
+func (u *classUsage) add(n int) {
+	if u != nil && n > 0 {
+		u.bytes.Add(float64(n))
+	}
+}
+
+func (u *classUsage) track() {
+	if u != nil && u.limiter.Limit() != rate.Inf {
+		u.tokens.Set(u.limiter.Tokens())
+	}
+}
@@ -370,6 +370,123 @@ func TestTotalWaiterLimit(t *testing.T) {
 	}
 }
 
+func TestBandwidthClassLimits(t *testing.T) {
+	wrapper, wrapperCancel := initConfig()
+	defer wrapperCancel()
+	lim := newLimiter(device1, wrapper)
+
+	waiter, _ := wrapper.Modify(func(cfg *config.Configuration) {
+		cfg.Options.BandwidthClasses = []config.BandwidthClassConfiguration{
+			{Class: config.BandwidthClassMetered, MaxSendKbps: 100, MaxRecvKbps: 200, BurstKiB: 16},
+			{Class: config.BandwidthClassRelay, MaxSendKbps: 300},
+		}
+	})
+	waiter.Wait()
+
+	metered := lim.classLimiters[config.BandwidthClassMetered]
+	if metered.write.Limit() != 100*1024 || metered.read.Limit() != 200*1024 {
+		t.Errorf("unexpected metered limits %v/%v", metered.write.Limit(), metered.read.Limit())
+	}
+	if metered.write.Burst() != 16<<10 || metered.read.Burst() != 16<<10 {
+		t.Errorf("unexpected metered burst %d/%d", metered.write.Burst(), metered.read.Burst())
+	}
+	relay := lim.classLimiters[config.BandwidthClassRelay]
+	if relay.write.Limit() != 300*1024 || relay.read.Limit() != rate.Inf || relay.write.Burst() != limiterBurstSize {
+		t.Errorf("unexpected relay limits %v/%v, burst %d", relay.write.Limit(), relay.read.Limit(), relay.write.Burst())
+	}
+	for _, class := range []string{config.BandwidthClassLAN, config.BandwidthClassWAN} {
+		if cl := lim.classLimiters[class]; cl.write.Limit() != rate.Inf || cl.read.Limit() != rate.Inf {
+			t.Errorf("%s should be unlimited", class)
+		}
+	}
+}
+
+func TestFolderLimits(t *testing.T) {
+	wrapper, wrapperCancel := initConfig()
+	defer wrapperCancel()
+	lim := newLimiter(device1, wrapper)
+
+	limited := wrapper.DefaultFolder()
+	limited.ID = "limited"
+	limited.Path = "limited"
+	limited.MaxSendKbps = 100
+	limited.MaxRecvKbps = 200
+	unlimited := wrapper.DefaultFolder()
+	unlimited.ID = "unlimited"
+	unlimited.Path = "unlimited"
+
+	waiter, _ := wrapper.Modify(func(cfg *config.Configuration) {
+		cfg.SetFolders([]config.FolderConfiguration{limited, unlimited})
+	})
+	waiter.Wait()
+
+	if fl := lim.folderWriteLimiters["limited"]; fl == nil || fl.Limit() != 100*1024 {
+		t.Errorf("unexpected send limiter %v", fl)
+	}
+	if fl := lim.folderReadLimiters["limited"]; fl == nil || fl.Limit() != 200*1024 {
+		t.Errorf("unexpected receive limiter %v", fl)
+	}
+	if _, ok := lim.folderWriteLimiters["unlimited"]; ok {
+		t.Error("unlimited folder should have no limiter")
+	}
+
+	// Waiting for more than the burst size must work, and an unlimited
+	// folder doesn't wait at all.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	lim.folderWriteLimiters["limited"].SetLimit(rate.Inf)
+	if err := lim.waitFolder(ctx, lim.folderWriteLimiters, "limited", 3*limiterBurstSize); err != nil {
+		t.Error(err)
+	}
+	cancel()
+	if err := lim.waitFolder(ctx, lim.folderWriteLimiters, "unlimited", limiterBurstSize); err != nil {
+		t.Error(err)
+	}
+
+	waiter, _ = wrapper.Modify(func(cfg *config.Configuration) {
+		cfg.SetFolders([]config.FolderConfiguration{unlimited})
+	})
+	waiter.Wait()
+
+	if len(lim.folderReadLimiters) != 0 || len(lim.folderWriteLimiters) != 0 {
+		t.Error("removed folder should have no limiters")
+	}
+}
+
+func TestLimitedWriterClass(t *testing.T) {
+	// A LAN connection is exempt from the device and global limits unless
+	// LimitBandwidthInLan is set, but not from the limit of its class.
+	src := make([]byte, 64<<10)
+	dst := new(bytes.Buffer)
+	cw := &countingWriter{w: dst}
+	lw := &limitedWriter{
+		writer: cw,
+		waiterHolder: waiterHolder{
+			waiter:    rate.NewLimiter(rate.Inf, limiterBurstSize),
+			class:     rate.NewLimiter(rate.Limit(1<<20), 8<<10),
+			limitsLAN: new(atomic.Bool),
+			isLAN:     true,
+		},
+	}
+	if lw.unlimited() {
+		t.Fatal("class limit should apply")
+	}
+	if _, err := io.Copy(lw, bytes.NewReader(src)); err != nil {
+		t.Fatal(err)
+	}
+	if cw.writeCount < 8 {
+		t.Errorf("expected writes no larger than the burst, got %d writes", cw.writeCount)
+	}
+	if !bytes.Equal(src, dst.Bytes()) {
+		t.Error("results should be equal")
+	}
+
+	lw.class = rate.NewLimiter(rate.Inf, limiterBurstSize)
+	if !lw.unlimited() {
+		t.Error("unlimited class on an exempt connection should be unlimited")
+	}
+}
+
 func checkActualAndExpected(t *testing.T, actualR, actualW, expectedR, expectedW map[protocol.DeviceID]*rate.Limiter) {
 	t.Helper()
 	if len(expectedW) != len(actualW) || len(expectedR) != len(actualR) {
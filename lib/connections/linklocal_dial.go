@@ -0,0 +1,90 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/connections/registry"
+	"github.com/syncthing/syncthing/lib/dialer"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func init() {
+	dialers["linklocal"] = &linkLocalDialerFactory{}
+}
+
+type linkLocalDialer struct {
+	commonDialer
+}
+
+func (d *linkLocalDialer) Dial(ctx context.Context, _ protocol.DeviceID, uri *url.URL) (internalConn, error) {
+	uri = fixupPort(uri, defaultLinkLocalPort)
+
+	trace := connTraceFrom(ctx)
+	tcaddr, err := net.ResolveTCPAddr("tcp6", uri.Host)
+	if err != nil {
+		return internalConn{}, err
+	}
+	if err := checkLinkLocalAddr(tcaddr); err != nil {
+		return internalConn{}, err
+	}
+	trace.step(stepResolve)
+
+	// Peer-to-peer links are direct by definition, so no proxy.
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp6", tcaddr.String())
+	if err != nil {
+		return internalConn{}, err
+	}
+	if err := dialer.SetTCPOptions(conn); err != nil {
+		l.Debugln("Dial (BEP/linklocal): setting tcp options:", err)
+	}
+	trace.step(stepDial)
+
+	_ = conn.SetDeadline(time.Now().Add(getProgressiveDialTimeoutForAddress(uri.Host)))
+	tc := tls.Client(newMeteredConn(conn), d.tlsCfg)
+	if err := tlsTimedHandshake(tc); err != nil {
+		conn.Close()
+		return internalConn{}, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	trace.step(stepTLS)
+
+	return newInternalConn(tc, connTypeLinkLocalClient, true, d.lanPriority), nil
+}
+
+type linkLocalDialerFactory struct{}
+
+func (linkLocalDialerFactory) New(opts config.OptionsConfiguration, tlsCfg *tls.Config, _ *registry.Registry, lanChecker *lanChecker) genericDialer {
+	return &linkLocalDialer{
+		commonDialer: commonDialer{
+			reconnectInterval: time.Duration(opts.ReconnectIntervalS) * time.Second,
+			tlsCfg:            tlsCfg,
+			lanChecker:        lanChecker,
+			lanPriority:       opts.ConnectionPriorityTCPLAN,
+			wanPriority:       opts.ConnectionPriorityTCPLAN,
+		},
+	}
+}
+
+func (linkLocalDialerFactory) AlwaysWAN() bool {
+	return false
+}
+
+func (linkLocalDialerFactory) Valid(config.Configuration) error {
+	// Always valid
+	return nil
+}
+
+func (linkLocalDialerFactory) String() string {
+	return "Link-local Dialer"
+}
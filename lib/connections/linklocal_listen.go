@@ -0,0 +1,182 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/connections/registry"
+	"github.com/syncthing/syncthing/lib/dialer"
+	"github.com/syncthing/syncthing/lib/nat"
+	"github.com/syncthing/syncthing/lib/svcutil"
+)
+
+func init() {
+	listeners["linklocal"] = &linkLocalListenerFactory{}
+}
+
+// linkLocalListener accepts connections on all IPv6 addresses, dropping
+// those that don't come in over a peer-to-peer link.
+type linkLocalListener struct {
+	svcutil.ServiceWithError
+	onAddressesChangedNotifier
+
+	uri     *url.URL
+	cfg     config.Wrapper
+	tlsCfg  *tls.Config
+	conns   chan internalConn
+	factory listenerFactory
+
+	laddr net.Addr
+	mut   sync.RWMutex
+}
+
+func (t *linkLocalListener) serve(ctx context.Context) error {
+	tcaddr, err := net.ResolveTCPAddr("tcp6", t.uri.Host)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to listen (link-local)", slogutil.Error(err))
+		return err
+	}
+
+	listener, err := net.ListenTCP("tcp6", tcaddr)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to listen (link-local)", slogutil.Error(err))
+		return err
+	}
+	defer listener.Close()
+
+	t.mut.Lock()
+	t.laddr = listener.Addr()
+	t.mut.Unlock()
+	defer func() {
+		t.mut.Lock()
+		t.laddr = nil
+		t.mut.Unlock()
+	}()
+
+	t.notifyAddressesChanged(t)
+	defer t.clearAddresses(t)
+
+	slog.InfoContext(ctx, "Link-local listener starting", slogutil.Address(listener.Addr()))
+	defer slog.InfoContext(ctx, "Link-local listener shutting down", slogutil.Address(listener.Addr()))
+
+	acceptFailures := 0
+	const maxAcceptFailures = 10
+
+	for {
+		_ = listener.SetDeadline(time.Now().Add(time.Second))
+		conn, err := listener.AcceptTCP()
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				conn.Close()
+			}
+			return nil
+		default:
+		}
+		if err != nil {
+			var ne *net.OpError
+			if ok := errors.As(err, &ne); !ok || !ne.Timeout() {
+				slog.WarnContext(ctx, "Failed to accept link-local connection", slogutil.Error(err))
+				acceptFailures++
+				if acceptFailures > maxAcceptFailures {
+					return err
+				}
+				time.Sleep(time.Duration(acceptFailures) * time.Second)
+			}
+			continue
+		}
+		acceptFailures = 0
+
+		if err := checkLinkLocalAddr(conn.RemoteAddr().(*net.TCPAddr)); err != nil {
+			l.Debugln("Listen (BEP/linklocal): rejecting connection:", err)
+			conn.Close()
+			continue
+		}
+		l.Debugln("Listen (BEP/linklocal): connect from", conn.RemoteAddr())
+
+		if err := dialer.SetTCPOptions(conn); err != nil {
+			l.Debugln("Listen (BEP/linklocal): setting tcp options:", err)
+		}
+
+		tc := tls.Server(newMeteredConn(conn), t.tlsCfg)
+		if err := tlsTimedHandshake(tc); err != nil {
+			slog.WarnContext(ctx, "Failed TLS handshake", slogutil.Address(tc.RemoteAddr()), slogutil.Error(err))
+			tc.Close()
+			continue
+		}
+
+		t.conns <- newInternalConn(tc, connTypeLinkLocalServer, true, t.cfg.Options().ConnectionPriorityTCPLAN)
+	}
+}
+
+func (t *linkLocalListener) URI() *url.URL {
+	return t.uri
+}
+
+// WANAddresses returns nothing, as peer-to-peer links are never reachable
+// through global discovery.
+func (*linkLocalListener) WANAddresses() []*url.URL {
+	return nil
+}
+
+// LANAddresses returns the unspecified address with the port we listen on.
+// Local discovery replaces it with the link-local address the announcement
+// came from.
+func (t *linkLocalListener) LANAddresses() []*url.URL {
+	t.mut.RLock()
+	defer t.mut.RUnlock()
+	if t.laddr == nil {
+		return nil
+	}
+	return []*url.URL{maybeReplacePort(t.uri, t.laddr)}
+}
+
+func (t *linkLocalListener) String() string {
+	return t.uri.String()
+}
+
+func (t *linkLocalListener) Factory() listenerFactory {
+	return t.factory
+}
+
+func (*linkLocalListener) NATType() string {
+	return "unknown"
+}
+
+type linkLocalListenerFactory struct{}
+
+func (f *linkLocalListenerFactory) New(uri *url.URL, cfg config.Wrapper, tlsCfg *tls.Config, conns chan internalConn, _ *nat.Service, _ *registry.Registry, _ *lanChecker) genericListener {
+	uri = fixupPort(uri, defaultLinkLocalPort)
+	if host, port, err := net.SplitHostPort(uri.Host); err == nil && host == "" {
+		// An empty host would be dropped by local discovery.
+		uri.Host = net.JoinHostPort("::", port)
+	}
+	l := &linkLocalListener{
+		uri:     uri,
+		cfg:     cfg,
+		tlsCfg:  tlsCfg,
+		conns:   conns,
+		factory: f,
+	}
+	l.ServiceWithError = svcutil.AsService(l.serve, l.String())
+	return l
+}
+
+func (linkLocalListenerFactory) Valid(_ config.Configuration) error {
+	// Always valid
+	return nil
+}
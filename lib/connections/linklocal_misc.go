@@ -0,0 +1,84 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// The link-local transport is an experimental transport for devices that
+// are near each other without any shared network infrastructure. It's TCP
+// over IPv6 link-local addresses, restricted to peer-to-peer links that
+// already exist: Wi-Fi Direct groups (p2p-* interfaces), Apple Wireless
+// Direct Link (awdl*, llw*), and ad-hoc networks where the interface has no
+// other address than a link-local one. It's enabled by adding a listen
+// address like "linklocal://[::]:22002"; local discovery over the link
+// fills in the peer's link-local address.
+//
+// Setting up such a link is left to the operating system. Bringing up
+// Wi-Fi Direct groups, AWDL or Bluetooth links ourselves is out of scope:
+// it needs platform specific APIs, and often privileges, that Syncthing
+// doesn't have, and Bluetooth doesn't carry IP without them anyway.
+
+const defaultLinkLocalPort = 22002
+
+var errNotPeerLink = errors.New("not a peer-to-peer link")
+
+// Interface name prefixes for Wi-Fi Direct and similar peer-to-peer links.
+var peerLinkInterfacePrefixes = []string{"p2p", "awdl", "llw"}
+
+// isPeerLinkInterface returns true if the interface is a peer-to-peer
+// link, or looks like an ad-hoc network by having only link-local
+// addresses.
+func isPeerLinkInterface(iface net.Interface, addrs []net.Addr) bool {
+	if iface.Flags&net.FlagUp == 0 || iface.Flags&net.FlagLoopback != 0 {
+		return false
+	}
+	for _, prefix := range peerLinkInterfacePrefixes {
+		if strings.HasPrefix(iface.Name, prefix) {
+			return true
+		}
+	}
+
+	linkLocal6 := false
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+		if !ipnet.IP.IsLinkLocalUnicast() {
+			return false
+		}
+		if ipnet.IP.To4() == nil {
+			linkLocal6 = true
+		}
+	}
+	return linkLocal6
+}
+
+// checkLinkLocalAddr returns an error unless the address is a link-local
+// IPv6 address on a peer-to-peer link.
+func checkLinkLocalAddr(addr *net.TCPAddr) error {
+	if addr.IP.To4() != nil || !addr.IP.IsLinkLocalUnicast() || addr.Zone == "" {
+		return fmt.Errorf("%w: %s is not a scoped IPv6 link-local address", errNotPeerLink, addr)
+	}
+	iface, err := net.InterfaceByName(addr.Zone)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errNotPeerLink, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return fmt.Errorf("%w: %w", errNotPeerLink, err)
+	}
+	if !isPeerLinkInterface(*iface, addrs) {
+		return fmt.Errorf("%w: %s", errNotPeerLink, iface.Name)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+)
+
+func TestIsPeerLinkInterface(t *testing.T) {
+	ipnet := func(s string) net.Addr {
+		ip, n, err := net.ParseCIDR(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		n.IP = ip
+		return n
+	}
+	up := net.FlagUp | net.FlagMulticast
+
+	cases := []struct {
+		name  string
+		flags net.Flags
+		addrs []net.Addr
+		peer  bool
+	}{
+		{"p2p-wlan0-0", up, []net.Addr{ipnet("192.168.49.1/24"), ipnet("fe80::1/64")}, true},
+		{"awdl0", up, []net.Addr{ipnet("fe80::1/64")}, true},
+		{"awdl0", net.FlagMulticast, []net.Addr{ipnet("fe80::1/64")}, false},
+		{"wlan0", up, []net.Addr{ipnet("fe80::1/64")}, true},
+		{"wlan0", up, []net.Addr{ipnet("169.254.1.1/16"), ipnet("fe80::1/64")}, true},
+		{"wlan0", up, []net.Addr{ipnet("192.168.1.2/24"), ipnet("fe80::1/64")}, false},
+		{"wlan0", up, []net.Addr{ipnet("169.254.1.1/16")}, false},
+		{"lo", up | net.FlagLoopback, []net.Addr{ipnet("fe80::1/64")}, false},
+	}
+	for _, tc := range cases {
+		iface := net.Interface{Name: tc.name, Flags: tc.flags}
+		if peer := isPeerLinkInterface(iface, tc.addrs); peer != tc.peer {
+			t.Errorf("%s %v %v: got %v, expected %v", tc.name, tc.flags, tc.addrs, peer, tc.peer)
+		}
+	}
+}
+
+func TestCheckLinkLocalAddr(t *testing.T) {
+	for _, addr := range []string{"192.0.2.1:22002", "[2001:db8::1]:22002", "[fe80::1]:22002", "[fe80::1%doesnotexist0]:22002"} {
+		tcaddr, err := net.ResolveTCPAddr("tcp", addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := checkLinkLocalAddr(tcaddr); !errors.Is(err, errNotPeerLink) {
+			t.Errorf("%s: got %v, expected errNotPeerLink", addr, err)
+		}
+	}
+}
+
+func TestLinkLocalListenerAddress(t *testing.T) {
+	uri, _ := url.Parse("linklocal://:0")
+	l := (&linkLocalListenerFactory{}).New(uri, nil, nil, nil, nil, nil, nil)
+	if host := l.URI().Host; host != "[::]:0" {
+		t.Errorf("got %s, expected the unspecified IPv6 address", host)
+	}
+	if addrs := l.WANAddresses(); len(addrs) != 0 {
+		t.Errorf("unexpected WAN addresses %v", addrs)
+	}
+}
@@ -54,6 +54,41 @@ var (
 		Name:      "migration_total",
 		Help:      "Total number of connection migrations performed.",
 	}, []string{"device"})
+	metricDeviceTLSSeconds = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "connections",
+		Name:      "tls_cpu_seconds_total",
+		Help:      "Estimated CPU time spent encrypting and decrypting connections, per device and operation (encrypt, decrypt).",
+	}, []string{"device", "operation"})
+)
+
+const (
+	metricDirectionSend = "send"
+	metricDirectionRecv = "recv"
+)
+
+var (
+	// Bandwidth class metrics
+	metricClassBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "connections",
+		Name:      "class_bytes_total",
+		Help:      "Total amount of data sent and received, per bandwidth class (lan, wan, relay, metered) and direction (send, recv).",
+	}, []string{"class", "direction"})
+
+	metricClassLimit = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "syncthing",
+		Subsystem: "connections",
+		Name:      "class_limit_bytes_per_second",
+		Help:      "Rate limit per bandwidth class and direction. Zero means unlimited.",
+	}, []string{"class", "direction"})
+
+	metricClassBurstTokens = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "syncthing",
+		Subsystem: "connections",
+		Name:      "class_burst_tokens",
+		Help:      "Tokens, in bytes, left in the bucket of a rate limited bandwidth class, per direction, as of the last limited transfer.",
+	}, []string{"class", "direction"})
 )
 
 func registerDeviceMetrics(deviceID string) {
@@ -65,4 +100,6 @@ func registerDeviceMetrics(deviceID string) {
 	metricConnectionPoolReused.WithLabelValues(deviceID)
 	metricConnectionPoolExpired.WithLabelValues(deviceID)
 	metricConnectionMigrationCount.WithLabelValues(deviceID)
+	metricDeviceTLSSeconds.WithLabelValues(deviceID, "encrypt")
+	metricDeviceTLSSeconds.WithLabelValues(deviceID, "decrypt")
 }
@@ -0,0 +1,107 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var metricPathSelections = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "syncthing",
+	Subsystem: "connections",
+	Name:      "path_selections_total",
+	Help:      "Total number of block requests striped over the connections to a device, per device and reason the path was picked (only, window, congested).",
+}, []string{"device", "reason"})
+
+// Reasons a path is picked for a block request
+const (
+	pathSelectionOnly      = "only"      // the only candidate
+	pathSelectionWindow    = "window"    // soonest answer among paths with room in their window
+	pathSelectionCongested = "congested" // soonest answer, no path having room
+)
+
+var (
+	descKeepAliveInterval = prometheus.NewDesc("syncthing_connections_keepalive_interval_seconds",
+		"The adaptive keep-alive interval chosen by the health monitor.", nil, nil)
+	descMonitorHealthScore = prometheus.NewDesc("syncthing_connections_monitor_health_score",
+		"The overall health score (0-100) of the health monitor, from latency and packet loss.", nil, nil)
+	descAddressConsecutiveErrors = prometheus.NewDesc("syncthing_connections_address_consecutive_errors",
+		"Failed connection attempts in a row, per device, address and category of the last error.", []string{"device", "address", "category"}, nil)
+	descPathWindow = prometheus.NewDesc("syncthing_connections_path_window_bytes",
+		"Congestion window of a path used for striping block requests, per device and connection.", []string{"device", "connection"}, nil)
+	descPathOutstanding = prometheus.NewDesc("syncthing_connections_path_outstanding_bytes",
+		"Bytes of block requests in flight on a path, per device and connection.", []string{"device", "connection"}, nil)
+	descPathRate = prometheus.NewDesc("syncthing_connections_path_delivery_rate_bytes_per_second",
+		"Smoothed delivery rate of a path, per device and connection. Zero while unknown.", []string{"device", "connection"}, nil)
+	descPathResponseTime = prometheus.NewDesc("syncthing_connections_path_response_time_seconds",
+		"Smoothed response time of block requests on a path, per device and connection.", []string{"device", "connection"}, nil)
+)
+
+// The internalsCollector exposes the state kept by the health monitor and
+// the packet scheduler of the connection service, as of when the metrics
+// are gathered.
+type internalsCollector struct {
+	service atomic.Pointer[service]
+}
+
+var collector = &internalsCollector{}
+
+func init() {
+	prometheus.MustRegister(metricPathSelections, collector)
+}
+
+func (*internalsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descKeepAliveInterval
+	ch <- descMonitorHealthScore
+	ch <- descAddressConsecutiveErrors
+	ch <- descPathWindow
+	ch <- descPathOutstanding
+	ch <- descPathRate
+	ch <- descPathResponseTime
+}
+
+func (c *internalsCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.service.Load()
+	if s == nil {
+		return
+	}
+
+	report := s.healthMonitor.report()
+	ch <- prometheus.MustNewConstMetric(descKeepAliveInterval, prometheus.GaugeValue, report.KeepAliveIntervalS)
+	ch <- prometheus.MustNewConstMetric(descMonitorHealthScore, prometheus.GaugeValue, report.HealthScore)
+	for device, addrs := range report.Devices {
+		for addr, h := range addrs {
+			if h.ConsecutiveErrors == 0 {
+				continue
+			}
+			ch <- prometheus.MustNewConstMetric(descAddressConsecutiveErrors, prometheus.GaugeValue, float64(h.ConsecutiveErrors), device, addr, h.ErrorCategory)
+		}
+	}
+
+	s.packetScheduler.collect(ch)
+}
+
+// collect sends the state of the paths to each device
+func (ps *PacketScheduler) collect(ch chan<- prometheus.Metric) {
+	ps.mut.RLock()
+	defer ps.mut.RUnlock()
+	for device, conns := range ps.connections {
+		for _, conn := range conns {
+			p, ok := ps.paths[conn.ConnectionID()]
+			if !ok {
+				continue
+			}
+			labels := []string{device.String(), conn.ConnectionID()}
+			ch <- prometheus.MustNewConstMetric(descPathWindow, prometheus.GaugeValue, float64(p.window), labels...)
+			ch <- prometheus.MustNewConstMetric(descPathOutstanding, prometheus.GaugeValue, float64(p.outstanding), labels...)
+			ch <- prometheus.MustNewConstMetric(descPathRate, prometheus.GaugeValue, p.rate, labels...)
+			ch <- prometheus.MustNewConstMetric(descPathResponseTime, prometheus.GaugeValue, p.srtt.Seconds(), labels...)
+		}
+	}
+}
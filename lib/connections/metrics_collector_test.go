@@ -0,0 +1,38 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestPacketSchedulerMetrics(t *testing.T) {
+	scheduler := NewPacketScheduler()
+	scheduler.SetStriping(true)
+	deviceID := protocol.LocalDeviceID
+	scheduler.AddConnection(deviceID, NewEnhancedMockConnection("lan", deviceID, 10, 50.0))
+
+	only := metricPathSelections.WithLabelValues(deviceID.String(), pathSelectionOnly)
+	before := testutil.ToFloat64(only)
+	scheduler.stripeRequest(deviceID, 128<<10, "", time.Now())
+	if got := testutil.ToFloat64(only); got != before+1 {
+		t.Errorf("Expected one selection of the only path, got %v", got-before)
+	}
+
+	ch := make(chan prometheus.Metric, 16)
+	scheduler.collect(ch)
+	close(ch)
+	if n := len(ch); n != 4 {
+		t.Errorf("Expected four metrics for the path, got %d", n)
+	}
+}
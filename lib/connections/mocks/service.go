@@ -3,13 +3,26 @@ package mocks
 
 import (
 	"context"
+	"crypto/tls"
 	"sync"
 
+	"github.com/syncthing/syncthing/internal/db"
 	"github.com/syncthing/syncthing/lib/connections"
 	"github.com/syncthing/syncthing/lib/protocol"
 )
 
 type Service struct {
+	AcknowledgeConnectionErrorBudgetStub        func(protocol.DeviceID) bool
+	acknowledgeConnectionErrorBudgetMutex       sync.RWMutex
+	acknowledgeConnectionErrorBudgetArgsForCall []struct {
+		arg1 protocol.DeviceID
+	}
+	acknowledgeConnectionErrorBudgetReturns struct {
+		result1 bool
+	}
+	acknowledgeConnectionErrorBudgetReturnsOnCall map[int]struct {
+		result1 bool
+	}
 	AllAddressesStub        func() []string
 	allAddressesMutex       sync.RWMutex
 	allAddressesArgsForCall []struct {
@@ -20,6 +33,47 @@ type Service struct {
 	allAddressesReturnsOnCall map[int]struct {
 		result1 []string
 	}
+	BandwidthEstimatesStub        func() map[string]connections.BandwidthEstimate
+	bandwidthEstimatesMutex       sync.RWMutex
+	bandwidthEstimatesArgsForCall []struct {
+	}
+	bandwidthEstimatesReturns struct {
+		result1 map[string]connections.BandwidthEstimate
+	}
+	bandwidthEstimatesReturnsOnCall map[int]struct {
+		result1 map[string]connections.BandwidthEstimate
+	}
+	ConnectionAttemptsStub        func(protocol.DeviceID) []connections.ConnectionAttempt
+	connectionAttemptsMutex       sync.RWMutex
+	connectionAttemptsArgsForCall []struct {
+		arg1 protocol.DeviceID
+	}
+	connectionAttemptsReturns struct {
+		result1 []connections.ConnectionAttempt
+	}
+	connectionAttemptsReturnsOnCall map[int]struct {
+		result1 []connections.ConnectionAttempt
+	}
+	ConnectionErrorBudgetsStub        func() map[string]connections.DeviceErrorBudget
+	connectionErrorBudgetsMutex       sync.RWMutex
+	connectionErrorBudgetsArgsForCall []struct {
+	}
+	connectionErrorBudgetsReturns struct {
+		result1 map[string]connections.DeviceErrorBudget
+	}
+	connectionErrorBudgetsReturnsOnCall map[int]struct {
+		result1 map[string]connections.DeviceErrorBudget
+	}
+	ConnectionHealthStub        func() connections.ConnectionHealthReport
+	connectionHealthMutex       sync.RWMutex
+	connectionHealthArgsForCall []struct {
+	}
+	connectionHealthReturns struct {
+		result1 connections.ConnectionHealthReport
+	}
+	connectionHealthReturnsOnCall map[int]struct {
+		result1 connections.ConnectionHealthReport
+	}
 	ConnectionStatusStub        func() map[string]connections.ConnectionStatusEntry
 	connectionStatusMutex       sync.RWMutex
 	connectionStatusArgsForCall []struct {
@@ -65,6 +119,42 @@ type Service struct {
 	getConnectionsForDeviceReturnsOnCall map[int]struct {
 		result1 []protocol.Connection
 	}
+	InfrastructureStatusStub        func() map[string]connections.InfrastructureStatusEntry
+	infrastructureStatusMutex       sync.RWMutex
+	infrastructureStatusArgsForCall []struct {
+	}
+	infrastructureStatusReturns struct {
+		result1 map[string]connections.InfrastructureStatusEntry
+	}
+	infrastructureStatusReturnsOnCall map[int]struct {
+		result1 map[string]connections.InfrastructureStatusEntry
+	}
+	LimitFolderRecvStub        func(context.Context, string, int) error
+	limitFolderRecvMutex       sync.RWMutex
+	limitFolderRecvArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 int
+	}
+	limitFolderRecvReturns struct {
+		result1 error
+	}
+	limitFolderRecvReturnsOnCall map[int]struct {
+		result1 error
+	}
+	LimitFolderSendStub        func(context.Context, string, int) error
+	limitFolderSendMutex       sync.RWMutex
+	limitFolderSendArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 int
+	}
+	limitFolderSendReturns struct {
+		result1 error
+	}
+	limitFolderSendReturnsOnCall map[int]struct {
+		result1 error
+	}
 	ListenerStatusStub        func() map[string]connections.ListenerStatusEntry
 	listenerStatusMutex       sync.RWMutex
 	listenerStatusArgsForCall []struct {
@@ -95,6 +185,12 @@ type Service struct {
 	packetSchedulerReturnsOnCall map[int]struct {
 		result1 *connections.PacketScheduler
 	}
+	ReconnectDeviceStub        func(protocol.DeviceID, error)
+	reconnectDeviceMutex       sync.RWMutex
+	reconnectDeviceArgsForCall []struct {
+		arg1 protocol.DeviceID
+		arg2 error
+	}
 	ServeStub        func(context.Context) error
 	serveMutex       sync.RWMutex
 	serveArgsForCall []struct {
@@ -106,10 +202,87 @@ type Service struct {
 	serveReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SetCertificateStub        func(tls.Certificate) error
+	setCertificateMutex       sync.RWMutex
+	setCertificateArgsForCall []struct {
+		arg1 tls.Certificate
+	}
+	setCertificateReturns struct {
+		result1 error
+	}
+	setCertificateReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SetDatabaseStub        func(db.KV)
+	setDatabaseMutex       sync.RWMutex
+	setDatabaseArgsForCall []struct {
+		arg1 db.KV
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *Service) AcknowledgeConnectionErrorBudget(arg1 protocol.DeviceID) bool {
+	fake.acknowledgeConnectionErrorBudgetMutex.Lock()
+	ret, specificReturn := fake.acknowledgeConnectionErrorBudgetReturnsOnCall[len(fake.acknowledgeConnectionErrorBudgetArgsForCall)]
+	fake.acknowledgeConnectionErrorBudgetArgsForCall = append(fake.acknowledgeConnectionErrorBudgetArgsForCall, struct {
+		arg1 protocol.DeviceID
+	}{arg1})
+	stub := fake.AcknowledgeConnectionErrorBudgetStub
+	fakeReturns := fake.acknowledgeConnectionErrorBudgetReturns
+	fake.recordInvocation("AcknowledgeConnectionErrorBudget", []interface{}{arg1})
+	fake.acknowledgeConnectionErrorBudgetMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Service) AcknowledgeConnectionErrorBudgetCallCount() int {
+	fake.acknowledgeConnectionErrorBudgetMutex.RLock()
+	defer fake.acknowledgeConnectionErrorBudgetMutex.RUnlock()
+	return len(fake.acknowledgeConnectionErrorBudgetArgsForCall)
+}
+
+func (fake *Service) AcknowledgeConnectionErrorBudgetCalls(stub func(protocol.DeviceID) bool) {
+	fake.acknowledgeConnectionErrorBudgetMutex.Lock()
+	defer fake.acknowledgeConnectionErrorBudgetMutex.Unlock()
+	fake.AcknowledgeConnectionErrorBudgetStub = stub
+}
+
+func (fake *Service) AcknowledgeConnectionErrorBudgetArgsForCall(i int) protocol.DeviceID {
+	fake.acknowledgeConnectionErrorBudgetMutex.RLock()
+	defer fake.acknowledgeConnectionErrorBudgetMutex.RUnlock()
+	argsForCall := fake.acknowledgeConnectionErrorBudgetArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Service) AcknowledgeConnectionErrorBudgetReturns(result1 bool) {
+	fake.acknowledgeConnectionErrorBudgetMutex.Lock()
+	defer fake.acknowledgeConnectionErrorBudgetMutex.Unlock()
+	fake.AcknowledgeConnectionErrorBudgetStub = nil
+	fake.acknowledgeConnectionErrorBudgetReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *Service) AcknowledgeConnectionErrorBudgetReturnsOnCall(i int, result1 bool) {
+	fake.acknowledgeConnectionErrorBudgetMutex.Lock()
+	defer fake.acknowledgeConnectionErrorBudgetMutex.Unlock()
+	fake.AcknowledgeConnectionErrorBudgetStub = nil
+	if fake.acknowledgeConnectionErrorBudgetReturnsOnCall == nil {
+		fake.acknowledgeConnectionErrorBudgetReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.acknowledgeConnectionErrorBudgetReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
 func (fake *Service) AllAddresses() []string {
 	fake.allAddressesMutex.Lock()
 	ret, specificReturn := fake.allAddressesReturnsOnCall[len(fake.allAddressesArgsForCall)]
@@ -163,6 +336,226 @@ func (fake *Service) AllAddressesReturnsOnCall(i int, result1 []string) {
 	}{result1}
 }
 
+func (fake *Service) BandwidthEstimates() map[string]connections.BandwidthEstimate {
+	fake.bandwidthEstimatesMutex.Lock()
+	ret, specificReturn := fake.bandwidthEstimatesReturnsOnCall[len(fake.bandwidthEstimatesArgsForCall)]
+	fake.bandwidthEstimatesArgsForCall = append(fake.bandwidthEstimatesArgsForCall, struct {
+	}{})
+	stub := fake.BandwidthEstimatesStub
+	fakeReturns := fake.bandwidthEstimatesReturns
+	fake.recordInvocation("BandwidthEstimates", []interface{}{})
+	fake.bandwidthEstimatesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Service) BandwidthEstimatesCallCount() int {
+	fake.bandwidthEstimatesMutex.RLock()
+	defer fake.bandwidthEstimatesMutex.RUnlock()
+	return len(fake.bandwidthEstimatesArgsForCall)
+}
+
+func (fake *Service) BandwidthEstimatesCalls(stub func() map[string]connections.BandwidthEstimate) {
+	fake.bandwidthEstimatesMutex.Lock()
+	defer fake.bandwidthEstimatesMutex.Unlock()
+	fake.BandwidthEstimatesStub = stub
+}
+
+func (fake *Service) BandwidthEstimatesReturns(result1 map[string]connections.BandwidthEstimate) {
+	fake.bandwidthEstimatesMutex.Lock()
+	defer fake.bandwidthEstimatesMutex.Unlock()
+	fake.BandwidthEstimatesStub = nil
+	fake.bandwidthEstimatesReturns = struct {
+		result1 map[string]connections.BandwidthEstimate
+	}{result1}
+}
+
+func (fake *Service) BandwidthEstimatesReturnsOnCall(i int, result1 map[string]connections.BandwidthEstimate) {
+	fake.bandwidthEstimatesMutex.Lock()
+	defer fake.bandwidthEstimatesMutex.Unlock()
+	fake.BandwidthEstimatesStub = nil
+	if fake.bandwidthEstimatesReturnsOnCall == nil {
+		fake.bandwidthEstimatesReturnsOnCall = make(map[int]struct {
+			result1 map[string]connections.BandwidthEstimate
+		})
+	}
+	fake.bandwidthEstimatesReturnsOnCall[i] = struct {
+		result1 map[string]connections.BandwidthEstimate
+	}{result1}
+}
+
+func (fake *Service) ConnectionAttempts(arg1 protocol.DeviceID) []connections.ConnectionAttempt {
+	fake.connectionAttemptsMutex.Lock()
+	ret, specificReturn := fake.connectionAttemptsReturnsOnCall[len(fake.connectionAttemptsArgsForCall)]
+	fake.connectionAttemptsArgsForCall = append(fake.connectionAttemptsArgsForCall, struct {
+		arg1 protocol.DeviceID
+	}{arg1})
+	stub := fake.ConnectionAttemptsStub
+	fakeReturns := fake.connectionAttemptsReturns
+	fake.recordInvocation("ConnectionAttempts", []interface{}{arg1})
+	fake.connectionAttemptsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Service) ConnectionAttemptsCallCount() int {
+	fake.connectionAttemptsMutex.RLock()
+	defer fake.connectionAttemptsMutex.RUnlock()
+	return len(fake.connectionAttemptsArgsForCall)
+}
+
+func (fake *Service) ConnectionAttemptsCalls(stub func(protocol.DeviceID) []connections.ConnectionAttempt) {
+	fake.connectionAttemptsMutex.Lock()
+	defer fake.connectionAttemptsMutex.Unlock()
+	fake.ConnectionAttemptsStub = stub
+}
+
+func (fake *Service) ConnectionAttemptsArgsForCall(i int) protocol.DeviceID {
+	fake.connectionAttemptsMutex.RLock()
+	defer fake.connectionAttemptsMutex.RUnlock()
+	argsForCall := fake.connectionAttemptsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Service) ConnectionAttemptsReturns(result1 []connections.ConnectionAttempt) {
+	fake.connectionAttemptsMutex.Lock()
+	defer fake.connectionAttemptsMutex.Unlock()
+	fake.ConnectionAttemptsStub = nil
+	fake.connectionAttemptsReturns = struct {
+		result1 []connections.ConnectionAttempt
+	}{result1}
+}
+
+func (fake *Service) ConnectionAttemptsReturnsOnCall(i int, result1 []connections.ConnectionAttempt) {
+	fake.connectionAttemptsMutex.Lock()
+	defer fake.connectionAttemptsMutex.Unlock()
+	fake.ConnectionAttemptsStub = nil
+	if fake.connectionAttemptsReturnsOnCall == nil {
+		fake.connectionAttemptsReturnsOnCall = make(map[int]struct {
+			result1 []connections.ConnectionAttempt
+		})
+	}
+	fake.connectionAttemptsReturnsOnCall[i] = struct {
+		result1 []connections.ConnectionAttempt
+	}{result1}
+}
+
+func (fake *Service) ConnectionErrorBudgets() map[string]connections.DeviceErrorBudget {
+	fake.connectionErrorBudgetsMutex.Lock()
+	ret, specificReturn := fake.connectionErrorBudgetsReturnsOnCall[len(fake.connectionErrorBudgetsArgsForCall)]
+	fake.connectionErrorBudgetsArgsForCall = append(fake.connectionErrorBudgetsArgsForCall, struct {
+	}{})
+	stub := fake.ConnectionErrorBudgetsStub
+	fakeReturns := fake.connectionErrorBudgetsReturns
+	fake.recordInvocation("ConnectionErrorBudgets", []interface{}{})
+	fake.connectionErrorBudgetsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Service) ConnectionErrorBudgetsCallCount() int {
+	fake.connectionErrorBudgetsMutex.RLock()
+	defer fake.connectionErrorBudgetsMutex.RUnlock()
+	return len(fake.connectionErrorBudgetsArgsForCall)
+}
+
+func (fake *Service) ConnectionErrorBudgetsCalls(stub func() map[string]connections.DeviceErrorBudget) {
+	fake.connectionErrorBudgetsMutex.Lock()
+	defer fake.connectionErrorBudgetsMutex.Unlock()
+	fake.ConnectionErrorBudgetsStub = stub
+}
+
+func (fake *Service) ConnectionErrorBudgetsReturns(result1 map[string]connections.DeviceErrorBudget) {
+	fake.connectionErrorBudgetsMutex.Lock()
+	defer fake.connectionErrorBudgetsMutex.Unlock()
+	fake.ConnectionErrorBudgetsStub = nil
+	fake.connectionErrorBudgetsReturns = struct {
+		result1 map[string]connections.DeviceErrorBudget
+	}{result1}
+}
+
+func (fake *Service) ConnectionErrorBudgetsReturnsOnCall(i int, result1 map[string]connections.DeviceErrorBudget) {
+	fake.connectionErrorBudgetsMutex.Lock()
+	defer fake.connectionErrorBudgetsMutex.Unlock()
+	fake.ConnectionErrorBudgetsStub = nil
+	if fake.connectionErrorBudgetsReturnsOnCall == nil {
+		fake.connectionErrorBudgetsReturnsOnCall = make(map[int]struct {
+			result1 map[string]connections.DeviceErrorBudget
+		})
+	}
+	fake.connectionErrorBudgetsReturnsOnCall[i] = struct {
+		result1 map[string]connections.DeviceErrorBudget
+	}{result1}
+}
+
+func (fake *Service) ConnectionHealth() connections.ConnectionHealthReport {
+	fake.connectionHealthMutex.Lock()
+	ret, specificReturn := fake.connectionHealthReturnsOnCall[len(fake.connectionHealthArgsForCall)]
+	fake.connectionHealthArgsForCall = append(fake.connectionHealthArgsForCall, struct {
+	}{})
+	stub := fake.ConnectionHealthStub
+	fakeReturns := fake.connectionHealthReturns
+	fake.recordInvocation("ConnectionHealth", []interface{}{})
+	fake.connectionHealthMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Service) ConnectionHealthCallCount() int {
+	fake.connectionHealthMutex.RLock()
+	defer fake.connectionHealthMutex.RUnlock()
+	return len(fake.connectionHealthArgsForCall)
+}
+
+func (fake *Service) ConnectionHealthCalls(stub func() connections.ConnectionHealthReport) {
+	fake.connectionHealthMutex.Lock()
+	defer fake.connectionHealthMutex.Unlock()
+	fake.ConnectionHealthStub = stub
+}
+
+func (fake *Service) ConnectionHealthReturns(result1 connections.ConnectionHealthReport) {
+	fake.connectionHealthMutex.Lock()
+	defer fake.connectionHealthMutex.Unlock()
+	fake.ConnectionHealthStub = nil
+	fake.connectionHealthReturns = struct {
+		result1 connections.ConnectionHealthReport
+	}{result1}
+}
+
+func (fake *Service) ConnectionHealthReturnsOnCall(i int, result1 connections.ConnectionHealthReport) {
+	fake.connectionHealthMutex.Lock()
+	defer fake.connectionHealthMutex.Unlock()
+	fake.ConnectionHealthStub = nil
+	if fake.connectionHealthReturnsOnCall == nil {
+		fake.connectionHealthReturnsOnCall = make(map[int]struct {
+			result1 connections.ConnectionHealthReport
+		})
+	}
+	fake.connectionHealthReturnsOnCall[i] = struct {
+		result1 connections.ConnectionHealthReport
+	}{result1}
+}
+
 func (fake *Service) ConnectionStatus() map[string]connections.ConnectionStatusEntry {
 	fake.connectionStatusMutex.Lock()
 	ret, specificReturn := fake.connectionStatusReturnsOnCall[len(fake.connectionStatusArgsForCall)]
@@ -407,6 +800,185 @@ func (fake *Service) GetConnectionsForDeviceReturnsOnCall(i int, result1 []proto
 	}{result1}
 }
 
+func (fake *Service) InfrastructureStatus() map[string]connections.InfrastructureStatusEntry {
+	fake.infrastructureStatusMutex.Lock()
+	ret, specificReturn := fake.infrastructureStatusReturnsOnCall[len(fake.infrastructureStatusArgsForCall)]
+	fake.infrastructureStatusArgsForCall = append(fake.infrastructureStatusArgsForCall, struct {
+	}{})
+	stub := fake.InfrastructureStatusStub
+	fakeReturns := fake.infrastructureStatusReturns
+	fake.recordInvocation("InfrastructureStatus", []interface{}{})
+	fake.infrastructureStatusMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Service) InfrastructureStatusCallCount() int {
+	fake.infrastructureStatusMutex.RLock()
+	defer fake.infrastructureStatusMutex.RUnlock()
+	return len(fake.infrastructureStatusArgsForCall)
+}
+
+func (fake *Service) InfrastructureStatusCalls(stub func() map[string]connections.InfrastructureStatusEntry) {
+	fake.infrastructureStatusMutex.Lock()
+	defer fake.infrastructureStatusMutex.Unlock()
+	fake.InfrastructureStatusStub = stub
+}
+
+func (fake *Service) InfrastructureStatusReturns(result1 map[string]connections.InfrastructureStatusEntry) {
+	fake.infrastructureStatusMutex.Lock()
+	defer fake.infrastructureStatusMutex.Unlock()
+	fake.InfrastructureStatusStub = nil
+	fake.infrastructureStatusReturns = struct {
+		result1 map[string]connections.InfrastructureStatusEntry
+	}{result1}
+}
+
+func (fake *Service) InfrastructureStatusReturnsOnCall(i int, result1 map[string]connections.InfrastructureStatusEntry) {
+	fake.infrastructureStatusMutex.Lock()
+	defer fake.infrastructureStatusMutex.Unlock()
+	fake.InfrastructureStatusStub = nil
+	if fake.infrastructureStatusReturnsOnCall == nil {
+		fake.infrastructureStatusReturnsOnCall = make(map[int]struct {
+			result1 map[string]connections.InfrastructureStatusEntry
+		})
+	}
+	fake.infrastructureStatusReturnsOnCall[i] = struct {
+		result1 map[string]connections.InfrastructureStatusEntry
+	}{result1}
+}
+
+func (fake *Service) LimitFolderRecv(arg1 context.Context, arg2 string, arg3 int) error {
+	fake.limitFolderRecvMutex.Lock()
+	ret, specificReturn := fake.limitFolderRecvReturnsOnCall[len(fake.limitFolderRecvArgsForCall)]
+	fake.limitFolderRecvArgsForCall = append(fake.limitFolderRecvArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 int
+	}{arg1, arg2, arg3})
+	stub := fake.LimitFolderRecvStub
+	fakeReturns := fake.limitFolderRecvReturns
+	fake.recordInvocation("LimitFolderRecv", []interface{}{arg1, arg2, arg3})
+	fake.limitFolderRecvMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Service) LimitFolderRecvCallCount() int {
+	fake.limitFolderRecvMutex.RLock()
+	defer fake.limitFolderRecvMutex.RUnlock()
+	return len(fake.limitFolderRecvArgsForCall)
+}
+
+func (fake *Service) LimitFolderRecvCalls(stub func(context.Context, string, int) error) {
+	fake.limitFolderRecvMutex.Lock()
+	defer fake.limitFolderRecvMutex.Unlock()
+	fake.LimitFolderRecvStub = stub
+}
+
+func (fake *Service) LimitFolderRecvArgsForCall(i int) (context.Context, string, int) {
+	fake.limitFolderRecvMutex.RLock()
+	defer fake.limitFolderRecvMutex.RUnlock()
+	argsForCall := fake.limitFolderRecvArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Service) LimitFolderRecvReturns(result1 error) {
+	fake.limitFolderRecvMutex.Lock()
+	defer fake.limitFolderRecvMutex.Unlock()
+	fake.LimitFolderRecvStub = nil
+	fake.limitFolderRecvReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Service) LimitFolderRecvReturnsOnCall(i int, result1 error) {
+	fake.limitFolderRecvMutex.Lock()
+	defer fake.limitFolderRecvMutex.Unlock()
+	fake.LimitFolderRecvStub = nil
+	if fake.limitFolderRecvReturnsOnCall == nil {
+		fake.limitFolderRecvReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.limitFolderRecvReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Service) LimitFolderSend(arg1 context.Context, arg2 string, arg3 int) error {
+	fake.limitFolderSendMutex.Lock()
+	ret, specificReturn := fake.limitFolderSendReturnsOnCall[len(fake.limitFolderSendArgsForCall)]
+	fake.limitFolderSendArgsForCall = append(fake.limitFolderSendArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 int
+	}{arg1, arg2, arg3})
+	stub := fake.LimitFolderSendStub
+	fakeReturns := fake.limitFolderSendReturns
+	fake.recordInvocation("LimitFolderSend", []interface{}{arg1, arg2, arg3})
+	fake.limitFolderSendMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Service) LimitFolderSendCallCount() int {
+	fake.limitFolderSendMutex.RLock()
+	defer fake.limitFolderSendMutex.RUnlock()
+	return len(fake.limitFolderSendArgsForCall)
+}
+
+func (fake *Service) LimitFolderSendCalls(stub func(context.Context, string, int) error) {
+	fake.limitFolderSendMutex.Lock()
+	defer fake.limitFolderSendMutex.Unlock()
+	fake.LimitFolderSendStub = stub
+}
+
+func (fake *Service) LimitFolderSendArgsForCall(i int) (context.Context, string, int) {
+	fake.limitFolderSendMutex.RLock()
+	defer fake.limitFolderSendMutex.RUnlock()
+	argsForCall := fake.limitFolderSendArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Service) LimitFolderSendReturns(result1 error) {
+	fake.limitFolderSendMutex.Lock()
+	defer fake.limitFolderSendMutex.Unlock()
+	fake.LimitFolderSendStub = nil
+	fake.limitFolderSendReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Service) LimitFolderSendReturnsOnCall(i int, result1 error) {
+	fake.limitFolderSendMutex.Lock()
+	defer fake.limitFolderSendMutex.Unlock()
+	fake.LimitFolderSendStub = nil
+	if fake.limitFolderSendReturnsOnCall == nil {
+		fake.limitFolderSendReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.limitFolderSendReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *Service) ListenerStatus() map[string]connections.ListenerStatusEntry {
 	fake.listenerStatusMutex.Lock()
 	ret, specificReturn := fake.listenerStatusReturnsOnCall[len(fake.listenerStatusArgsForCall)]
@@ -566,6 +1138,39 @@ func (fake *Service) PacketSchedulerReturnsOnCall(i int, result1 *connections.Pa
 	}{result1}
 }
 
+func (fake *Service) ReconnectDevice(arg1 protocol.DeviceID, arg2 error) {
+	fake.reconnectDeviceMutex.Lock()
+	fake.reconnectDeviceArgsForCall = append(fake.reconnectDeviceArgsForCall, struct {
+		arg1 protocol.DeviceID
+		arg2 error
+	}{arg1, arg2})
+	stub := fake.ReconnectDeviceStub
+	fake.recordInvocation("ReconnectDevice", []interface{}{arg1, arg2})
+	fake.reconnectDeviceMutex.Unlock()
+	if stub != nil {
+		fake.ReconnectDeviceStub(arg1, arg2)
+	}
+}
+
+func (fake *Service) ReconnectDeviceCallCount() int {
+	fake.reconnectDeviceMutex.RLock()
+	defer fake.reconnectDeviceMutex.RUnlock()
+	return len(fake.reconnectDeviceArgsForCall)
+}
+
+func (fake *Service) ReconnectDeviceCalls(stub func(protocol.DeviceID, error)) {
+	fake.reconnectDeviceMutex.Lock()
+	defer fake.reconnectDeviceMutex.Unlock()
+	fake.ReconnectDeviceStub = stub
+}
+
+func (fake *Service) ReconnectDeviceArgsForCall(i int) (protocol.DeviceID, error) {
+	fake.reconnectDeviceMutex.RLock()
+	defer fake.reconnectDeviceMutex.RUnlock()
+	argsForCall := fake.reconnectDeviceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
 func (fake *Service) Serve(arg1 context.Context) error {
 	fake.serveMutex.Lock()
 	ret, specificReturn := fake.serveReturnsOnCall[len(fake.serveArgsForCall)]
@@ -627,6 +1232,99 @@ func (fake *Service) ServeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *Service) SetCertificate(arg1 tls.Certificate) error {
+	fake.setCertificateMutex.Lock()
+	ret, specificReturn := fake.setCertificateReturnsOnCall[len(fake.setCertificateArgsForCall)]
+	fake.setCertificateArgsForCall = append(fake.setCertificateArgsForCall, struct {
+		arg1 tls.Certificate
+	}{arg1})
+	stub := fake.SetCertificateStub
+	fakeReturns := fake.setCertificateReturns
+	fake.recordInvocation("SetCertificate", []interface{}{arg1})
+	fake.setCertificateMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Service) SetCertificateCallCount() int {
+	fake.setCertificateMutex.RLock()
+	defer fake.setCertificateMutex.RUnlock()
+	return len(fake.setCertificateArgsForCall)
+}
+
+func (fake *Service) SetCertificateCalls(stub func(tls.Certificate) error) {
+	fake.setCertificateMutex.Lock()
+	defer fake.setCertificateMutex.Unlock()
+	fake.SetCertificateStub = stub
+}
+
+func (fake *Service) SetCertificateArgsForCall(i int) tls.Certificate {
+	fake.setCertificateMutex.RLock()
+	defer fake.setCertificateMutex.RUnlock()
+	argsForCall := fake.setCertificateArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Service) SetCertificateReturns(result1 error) {
+	fake.setCertificateMutex.Lock()
+	defer fake.setCertificateMutex.Unlock()
+	fake.SetCertificateStub = nil
+	fake.setCertificateReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Service) SetCertificateReturnsOnCall(i int, result1 error) {
+	fake.setCertificateMutex.Lock()
+	defer fake.setCertificateMutex.Unlock()
+	fake.SetCertificateStub = nil
+	if fake.setCertificateReturnsOnCall == nil {
+		fake.setCertificateReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setCertificateReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Service) SetDatabase(arg1 db.KV) {
+	fake.setDatabaseMutex.Lock()
+	fake.setDatabaseArgsForCall = append(fake.setDatabaseArgsForCall, struct {
+		arg1 db.KV
+	}{arg1})
+	stub := fake.SetDatabaseStub
+	fake.recordInvocation("SetDatabase", []interface{}{arg1})
+	fake.setDatabaseMutex.Unlock()
+	if stub != nil {
+		fake.SetDatabaseStub(arg1)
+	}
+}
+
+func (fake *Service) SetDatabaseCallCount() int {
+	fake.setDatabaseMutex.RLock()
+	defer fake.setDatabaseMutex.RUnlock()
+	return len(fake.setDatabaseArgsForCall)
+}
+
+func (fake *Service) SetDatabaseCalls(stub func(db.KV)) {
+	fake.setDatabaseMutex.Lock()
+	defer fake.setDatabaseMutex.Unlock()
+	fake.SetDatabaseStub = stub
+}
+
+func (fake *Service) SetDatabaseArgsForCall(i int) db.KV {
+	fake.setDatabaseMutex.RLock()
+	defer fake.setDatabaseMutex.RUnlock()
+	argsForCall := fake.setDatabaseArgsForCall[i]
+	return argsForCall.arg1
+}
+
 func (fake *Service) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
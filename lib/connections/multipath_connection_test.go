@@ -105,6 +105,14 @@ func (m *MockConnection) ResponseDevice(ctx context.Context, response *bep.Respo
 	return nil
 }
 
+func (m *MockConnection) FolderPreview(ctx context.Context, req *protocol.FolderPreviewRequest) (*protocol.FolderPreview, error) {
+	return nil, protocol.ErrNoSuchFile
+}
+
+func (m *MockConnection) ControlMessage(ctx context.Context, msg *protocol.ControlMessage) error {
+	return nil
+}
+
 // TestDeviceConnectionTrackerMultipath tests that the device connection tracker
 // can handle multiple connections per device when multipath is enabled
 func TestDeviceConnectionTrackerMultipath(t *testing.T) {
@@ -215,3 +215,11 @@ func (m *EnhancedMockConnection) QueryDevice(ctx context.Context, query *bep.Que
 func (m *EnhancedMockConnection) ResponseDevice(ctx context.Context, response *bep.ResponseDevice) error {
 	return nil
 }
+
+func (m *EnhancedMockConnection) FolderPreview(ctx context.Context, req *protocol.FolderPreviewRequest) (*protocol.FolderPreview, error) {
+	return nil, protocol.ErrNoSuchFile
+}
+
+func (m *EnhancedMockConnection) ControlMessage(ctx context.Context, msg *protocol.ControlMessage) error {
+	return nil
+}
@@ -0,0 +1,165 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"syscall"
+
+	"github.com/syncthing/syncthing/lib/dialer"
+	"github.com/syncthing/syncthing/lib/osutil"
+)
+
+// A TCP listen or dial address may confine its sockets to a Linux network
+// namespace, as created by "ip netns add", and/or a VRF device:
+// "tcp://0.0.0.0:22000?netns=vpn", "tcp://192.0.2.1:22000?vrf=blue". The
+// parameters are local and stripped from the addresses we announce.
+const (
+	netnsParam = "netns"
+	vrfParam   = "vrf"
+)
+
+type netScope struct {
+	netns string
+	vrf   string
+}
+
+func netScopeFromURI(uri *url.URL) netScope {
+	q := uri.Query()
+	return netScope{netns: q.Get(netnsParam), vrf: q.Get(vrfParam)}
+}
+
+func (s netScope) isSet() bool {
+	return s.netns != "" || s.vrf != ""
+}
+
+// strip returns the URI without the scope parameters.
+func (s netScope) strip(uri *url.URL) *url.URL {
+	if !s.isSet() {
+		return uri
+	}
+	q := uri.Query()
+	q.Del(netnsParam)
+	q.Del(vrfParam)
+	stripped := *uri
+	stripped.RawQuery = q.Encode()
+	return &stripped
+}
+
+// run calls fn in the network namespace.
+func (s netScope) run(fn func() error) error {
+	return dialer.InNetNS(s.netns, fn)
+}
+
+// control returns a socket control function binding the socket to the VRF,
+// after applying the given one if any.
+func (s netScope) control(base func(network, address string, c syscall.RawConn) error) func(network, address string, c syscall.RawConn) error {
+	bind := dialer.BindToVRFControl(s.vrf)
+	return func(network, address string, c syscall.RawConn) error {
+		if base != nil {
+			if err := base(network, address, c); err != nil {
+				return err
+			}
+		}
+		return bind(network, address, c)
+	}
+}
+
+// listen opens a TCP listener in the scope.
+func (s netScope) listen(ctx context.Context, network, address string) (net.Listener, error) {
+	var listener net.Listener
+	err := s.run(func() error {
+		lc := net.ListenConfig{Control: s.control(nil)}
+		var err error
+		listener, err = lc.Listen(ctx, network, address)
+		return err
+	})
+	return listener, err
+}
+
+// dial connects in the scope, bypassing any proxy, as that lives outside
+// of it.
+func (s netScope) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	var conn net.Conn
+	err := s.run(func() error {
+		d := net.Dialer{Control: s.control(nil)}
+		var err error
+		conn, err = d.DialContext(ctx, network, address)
+		return err
+	})
+	return conn, err
+}
+
+// interfaceNets returns the addresses of the interfaces in the scope: those
+// in the network namespace, and of them the members of the VRF.
+func (s netScope) interfaceNets() ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	err := s.run(func() error {
+		if s.vrf == "" {
+			var err error
+			nets, err = osutil.GetInterfaceAddrs(true)
+			return err
+		}
+		members, err := dialer.VRFMembers(s.vrf)
+		if err != nil {
+			return err
+		}
+		for _, iface := range members {
+			if iface.Flags&net.FlagRunning == 0 {
+				continue
+			}
+			addrs, err := iface.Addrs()
+			if err != nil {
+				return err
+			}
+			for _, addr := range addrs {
+				if ipnet, ok := addr.(*net.IPNet); ok {
+					nets = append(nets, ipnet)
+				}
+			}
+		}
+		return nil
+	})
+	return nets, err
+}
+
+// lanURIs returns the URI with the addresses of the interfaces in the scope
+// if it is unspecified, as getURLsForAllAdaptersIfUnspecified does for the
+// host.
+func (s netScope) lanURIs(uri *url.URL) []*url.URL {
+	ip, port, err := resolve(uri.Scheme, uri.Host)
+	if err != nil || port == 0 || (len(ip) != 0 && !ip.IsUnspecified()) {
+		return nil
+	}
+	nets, err := s.interfaceNets()
+	if err != nil {
+		l.Debugln("Listing interfaces in", s, err)
+		return nil
+	}
+	var uris []*url.URL
+	for _, hostPort := range privateHostPorts(nets, port) {
+		newURI := *uri
+		newURI.Host = hostPort
+		uris = append(uris, &newURI)
+	}
+	return uris
+}
+
+func (s netScope) String() string {
+	switch {
+	case s.netns != "" && s.vrf != "":
+		return "netns " + s.netns + ", vrf " + s.vrf
+	case s.netns != "":
+		return "netns " + s.netns
+	case s.vrf != "":
+		return "vrf " + s.vrf
+	default:
+		return "default network"
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestNetScopeFromURI(t *testing.T) {
+	cases := []struct {
+		uri      string
+		scope    netScope
+		stripped string
+	}{
+		{"tcp://0.0.0.0:22000", netScope{}, "tcp://0.0.0.0:22000"},
+		{"tcp://0.0.0.0:22000?netns=vpn", netScope{netns: "vpn"}, "tcp://0.0.0.0:22000"},
+		{"tcp://192.0.2.1:22000?vrf=blue", netScope{vrf: "blue"}, "tcp://192.0.2.1:22000"},
+		{"tcp://[::]:22000?netns=vpn&vrf=blue", netScope{netns: "vpn", vrf: "blue"}, "tcp://[::]:22000"},
+		{"tcp://0.0.0.0:22000?netns=vpn&other=1", netScope{netns: "vpn"}, "tcp://0.0.0.0:22000?other=1"},
+	}
+	for _, tc := range cases {
+		uri, err := url.Parse(tc.uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		scope := netScopeFromURI(uri)
+		if scope != tc.scope {
+			t.Errorf("%s: got scope %+v, expected %+v", tc.uri, scope, tc.scope)
+		}
+		if scope.isSet() != (tc.scope != netScope{}) {
+			t.Errorf("%s: isSet is %v", tc.uri, scope.isSet())
+		}
+		if s := scope.strip(uri).String(); s != tc.stripped {
+			t.Errorf("%s: stripped to %s, expected %s", tc.uri, s, tc.stripped)
+		}
+		if uri.String() != tc.uri {
+			t.Errorf("%s: modified by stripping to %s", tc.uri, uri)
+		}
+	}
+}
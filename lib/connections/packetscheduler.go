@@ -22,6 +22,15 @@ type PacketScheduler struct {
 	lastSelection  map[protocol.DeviceID]protocol.Connection
 	selectionCount map[protocol.DeviceID]map[string]int
 	randSource     *rand.Rand
+
+	// trafficAffinity keeps block requests off the control connection, see
+	// StripeRequest
+	trafficAffinity bool
+
+	// striping spreads block requests over all connections, see
+	// StripeRequest, with the state of each path by connection ID
+	striping bool
+	paths    map[string]*pathState
 }
 
 // NewPacketScheduler creates a new packet scheduler
@@ -30,6 +39,7 @@ func NewPacketScheduler() *PacketScheduler {
 		connections:    make(map[protocol.DeviceID][]protocol.Connection),
 		lastSelection:  make(map[protocol.DeviceID]protocol.Connection),
 		selectionCount: make(map[protocol.DeviceID]map[string]int),
+		paths:          make(map[string]*pathState),
 		randSource:     rand.New(rand.NewSource(time.Now().UnixNano())),
 	}
 }
@@ -70,6 +80,8 @@ func (ps *PacketScheduler) RemoveConnection(deviceID protocol.DeviceID, connID s
 		delete(ps.selectionCount[deviceID], connID)
 	}
 
+	delete(ps.paths, connID)
+
 	// Clear last selection if it was this connection
 	if ps.lastSelection[deviceID] != nil && ps.lastSelection[deviceID].ConnectionID() == connID {
 		ps.lastSelection[deviceID] = nil
@@ -359,3 +371,13 @@ func (ps *PacketScheduler) getTrafficScore(conn protocol.Connection) float64 {
 	// Fallback to health score if traffic metrics not available
 	return ps.getHealthScore(conn)
 }
+
+// SetTrafficAffinity enables or disables the traffic affinity mode. With
+// affinity enabled, block requests are striped over the connections other
+// than the control connection, so the responses carrying the data don't
+// delay index updates on it.
+func (ps *PacketScheduler) SetTrafficAffinity(enabled bool) {
+	ps.mut.Lock()
+	ps.trafficAffinity = enabled
+	ps.mut.Unlock()
+}
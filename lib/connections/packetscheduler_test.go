@@ -8,6 +8,7 @@ package connections
 
 import (
 	"testing"
+	"time"
 
 	"github.com/syncthing/syncthing/lib/protocol"
 )
@@ -182,3 +183,30 @@ func TestPacketSchedulerSingleConnection(t *testing.T) {
 		t.Errorf("Expected single connection to be returned for load balancing, got %v", selected)
 	}
 }
+
+// TestPacketSchedulerTrafficAffinity tests that block requests are kept
+// off the control connection when traffic affinity is enabled
+func TestPacketSchedulerTrafficAffinity(t *testing.T) {
+	const block = 128 << 10
+	scheduler := NewPacketScheduler()
+	deviceID := protocol.LocalDeviceID
+	scheduler.AddConnection(deviceID, NewEnhancedMockConnection("control", deviceID, 10, 90.0))
+	scheduler.AddConnection(deviceID, NewEnhancedMockConnection("data1", deviceID, 20, 80.0))
+	scheduler.AddConnection(deviceID, NewEnhancedMockConnection("data2", deviceID, 30, 70.0))
+	scheduler.SetStriping(true)
+	scheduler.SetTrafficAffinity(true)
+
+	t0 := time.Unix(1000000, 0)
+	for range 2 * initialStripeWindow / block {
+		if conn := scheduler.stripeRequest(deviceID, block, "control", t0); conn.ConnectionID() == "control" {
+			t.Fatal("Expected block requests off the control connection")
+		}
+	}
+
+	// With only the control connection left it carries everything
+	scheduler.RemoveConnection(deviceID, "data1")
+	scheduler.RemoveConnection(deviceID, "data2")
+	if conn := scheduler.stripeRequest(deviceID, block, "control", t0); conn.ConnectionID() != "control" {
+		t.Errorf("Expected fallback to the control connection, got %s", conn.ConnectionID())
+	}
+}
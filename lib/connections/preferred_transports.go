@@ -0,0 +1,55 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+// schemeTransport returns the transport dialing an address of the scheme
+// uses, as named in the preferred transports of devices.
+func schemeTransport(scheme string) string {
+	switch scheme = strings.TrimRight(scheme, "46"); scheme {
+	case "ws", "wss":
+		return config.TransportWebSocket
+	default:
+		return scheme
+	}
+}
+
+// connTypeTransport returns the transport of the connection type, as named
+// in the preferred transports of devices.
+func connTypeTransport(t connType) string {
+	if t == connTypeDemuxServer {
+		// The demultiplexed listener accepts the WebSocket dialer.
+		return config.TransportWebSocket
+	}
+	return t.Transport()
+}
+
+// transportPriority returns the priority of a connection to the device over
+// the transport. Where the device has preferred transports, each step down
+// the list weighs more than any configured connection priority, so that a
+// connection over a more preferred transport is always better.
+func transportPriority(opts config.OptionsConfiguration, cfg config.DeviceConfiguration, transport string, priority int) int {
+	rank := slices.Index(cfg.PreferredTransports, transport)
+	if rank <= 0 {
+		return priority
+	}
+	return priority + rank*transportPriorityStep(opts)
+}
+
+// transportPriorityStep returns the priority difference between adjacent
+// preferred transports, more than that between any configured priorities.
+func transportPriorityStep(opts config.OptionsConfiguration) int {
+	return max(opts.ConnectionPriorityTCPLAN, opts.ConnectionPriorityQUICLAN,
+		opts.ConnectionPriorityTCPWAN, opts.ConnectionPriorityQUICWAN,
+		opts.ConnectionPriorityWebSocket, opts.ConnectionPriorityRelay, 0) + 1
+}
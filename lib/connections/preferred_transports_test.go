@@ -0,0 +1,95 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+	protocolmocks "github.com/syncthing/syncthing/lib/protocol/mocks"
+)
+
+func TestSchemeTransport(t *testing.T) {
+	for scheme, exp := range map[string]string{
+		"tcp":       config.TransportTCP,
+		"tcp6":      config.TransportTCP,
+		"quic4":     config.TransportQUIC,
+		"relay":     config.TransportRelay,
+		"wss":       config.TransportWebSocket,
+		"linklocal": config.TransportLinkLocal,
+	} {
+		if got := schemeTransport(scheme); got != exp {
+			t.Errorf("schemeTransport(%q) = %q, expected %q", scheme, got, exp)
+		}
+	}
+}
+
+func TestTransportPriority(t *testing.T) {
+	opts := config.New(protocol.LocalDeviceID).Options
+	quicFirst := config.DeviceConfiguration{PreferredTransports: []string{config.TransportQUIC, config.TransportTCP}}
+
+	quicWAN := transportPriority(opts, quicFirst, config.TransportQUIC, opts.ConnectionPriorityQUICWAN)
+	tcpLAN := transportPriority(opts, quicFirst, config.TransportTCP, opts.ConnectionPriorityTCPLAN)
+	if quicWAN != opts.ConnectionPriorityQUICWAN {
+		t.Error("the most preferred transport should keep its priority, got", quicWAN)
+	}
+	if tcpLAN <= quicWAN {
+		t.Errorf("TCP on the LAN (%d) should be worse than QUIC over the WAN (%d)", tcpLAN, quicWAN)
+	}
+
+	if prio := transportPriority(opts, config.DeviceConfiguration{}, config.TransportTCP, opts.ConnectionPriorityTCPLAN); prio != opts.ConnectionPriorityTCPLAN {
+		t.Error("priority should be kept without preferred transports, got", prio)
+	}
+}
+
+func TestConnectionCheckEarlyTransport(t *testing.T) {
+	device := protocol.DeviceID{1, 2, 3}
+	cfg := config.New(protocol.LocalDeviceID)
+	cfg.Devices = append(cfg.Devices, config.DeviceConfiguration{DeviceID: device, PreferredTransports: []string{config.TransportQUIC, config.TransportTCP}})
+	s := &service{cfg: config.Wrap("", cfg, protocol.LocalDeviceID, events.NoopLogger)}
+
+	if err := s.connectionCheckEarly(device, internalConn{connType: connTypeRelayServer}); !errors.Is(err, errTransportNotAllowed) {
+		t.Error("expected relayed connection to be refused, got", err)
+	}
+	if err := s.connectionCheckEarly(device, internalConn{connType: connTypeTCPServer}); err != nil {
+		t.Error("expected TCP connection to be accepted, got", err)
+	}
+}
+
+func TestPrioritizerWeights(t *testing.T) {
+	device := protocol.DeviceID{1, 2, 3}
+	prioritizer := func(devCfg config.DeviceConfiguration) *ConnectionPrioritizer {
+		cfg := config.New(protocol.LocalDeviceID)
+		devCfg.DeviceID = device
+		cfg.Devices = append(cfg.Devices, devCfg)
+		return NewConnectionPrioritizer(config.Wrap("", cfg, protocol.LocalDeviceID, events.NoopLogger))
+	}
+	conn := func(local bool, priority int) protocol.Connection {
+		c := &protocolmocks.Connection{}
+		c.DeviceIDReturns(device)
+		c.IsLocalReturns(local)
+		c.PriorityReturns(priority)
+		return c
+	}
+	lan, wan := conn(true, 30), conn(false, 10)
+
+	if !prioritizer(config.DeviceConfiguration{}).CompareConnections(wan, lan) {
+		t.Error("by default the connection with the better priority should win")
+	}
+
+	// Weighing LAN-ness for the device turns that around.
+	cp := prioritizer(config.DeviceConfiguration{ConnectionWeightLAN: 50})
+	if !cp.CompareConnections(lan, wan) {
+		t.Error("with LAN weighed the local connection should win")
+	}
+	if score := cp.EvaluateConnection(lan); score.LANScore != 100 {
+		t.Error("unexpected LAN score", score.LANScore)
+	}
+}
@@ -21,6 +21,7 @@ import (
 
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/connections/registry"
+	"github.com/syncthing/syncthing/lib/dialer"
 	"github.com/syncthing/syncthing/lib/protocol"
 )
 
@@ -39,23 +40,43 @@ func init() {
 
 type quicDialer struct {
 	commonDialer
-	registry *registry.Registry
+	registry       *registry.Registry
+	tcpLANPriority int
+	tcpWANPriority int
 }
 
-func (d *quicDialer) Dial(ctx context.Context, _ protocol.DeviceID, uri *url.URL) (internalConn, error) {
+func (d *quicDialer) Dial(ctx context.Context, id protocol.DeviceID, uri *url.URL) (internalConn, error) {
 	uri = fixupPort(uri, config.DefaultQUICPort)
 
 	network := quicNetwork(uri)
 
+	trace := connTraceFrom(ctx)
 	addr, err := net.ResolveUDPAddr(network, uri.Host)
 	if err != nil {
 		return internalConn{}, err
 	}
+	trace.step(stepResolve)
 
 	// If we created the conn we need to close it at the end. If we got a
 	// Transport from the registry we have no conn to close.
+	// A configured source address or interface needs a conn of its own.
 	var createdConn net.PacketConn
-	transport, _ := d.registry.Get(uri.Scheme, transportConnUnspecified).(*quic.Transport)
+	var transport *quic.Transport
+	if src := d.sourceFor(id); src.isSet() {
+		ip, err := src.localIP(addr.IP)
+		if err != nil {
+			return internalConn{}, err
+		}
+		lc := net.ListenConfig{Control: dialer.BindToDeviceControl(src.iface)}
+		packetConn, err := lc.ListenPacket(ctx, "udp", net.JoinHostPort(ip.String(), "0"))
+		if err != nil {
+			return internalConn{}, err
+		}
+		createdConn = packetConn
+		transport = &quic.Transport{Conn: packetConn}
+	} else {
+		transport, _ = d.registry.Get(uri.Scheme, transportConnUnspecified).(*quic.Transport)
+	}
 	if transport == nil {
 		if packetConn, err := net.ListenPacket("udp", ":0"); err != nil {
 			return internalConn{}, err
@@ -68,7 +89,7 @@ func (d *quicDialer) Dial(ctx context.Context, _ protocol.DeviceID, uri *url.URL
 	ctx, cancel := context.WithTimeout(ctx, quicOperationTimeout)
 	defer cancel()
 
-	session, err := transport.Dial(ctx, addr, d.tlsCfg, quicConfig)
+	session, err := transport.Dial(ctx, addr, d.tlsCfg, quicPaths.config(addr))
 	if err != nil {
 		if createdConn != nil {
 			_ = createdConn.Close()
@@ -85,12 +106,15 @@ func (d *quicDialer) Dial(ctx context.Context, _ protocol.DeviceID, uri *url.URL
 		}
 		return internalConn{}, fmt.Errorf("open stream: %w", err)
 	}
+	quicPaths.attach(session)
+	trace.step(stepDial)
 
-	priority := d.wanPriority
+	priority, tcpPriority := d.wanPriority, d.tcpWANPriority
 	isLocal := d.lanChecker.isLAN(session.RemoteAddr())
 	if isLocal {
-		priority = d.lanPriority
+		priority, tcpPriority = d.lanPriority, d.tcpLANPriority
 	}
+	priority = quicPaths.priority(session.RemoteAddr().String(), priority, tcpPriority)
 
 	conn := &quicTlsConn{
 		Conn:        session,
@@ -100,6 +124,16 @@ func (d *quicDialer) Dial(ctx context.Context, _ protocol.DeviceID, uri *url.URL
 	return newInternalConn(conn, connTypeQUICClient, isLocal, priority), nil
 }
 
+// Priority is the usual dialer priority, unless QUIC has been demoted on
+// the network of the host due to persistent path MTU blackholes.
+func (d *quicDialer) Priority(host string) int {
+	tcpPriority := d.tcpWANPriority
+	if d.lanChecker.isLANHost(host) {
+		tcpPriority = d.tcpLANPriority
+	}
+	return quicPaths.priority(host, d.commonDialer.Priority(host), tcpPriority)
+}
+
 type quicDialerFactory struct{}
 
 func (quicDialerFactory) New(opts config.OptionsConfiguration, tlsCfg *tls.Config, registry *registry.Registry, lanChecker *lanChecker) genericDialer {
@@ -119,7 +153,9 @@ func (quicDialerFactory) New(opts config.OptionsConfiguration, tlsCfg *tls.Confi
 			wanPriority:       opts.ConnectionPriorityQUICWAN,
 			allowsMultiConns:  true,
 		},
-		registry: registry,
+		registry:       registry,
+		tcpLANPriority: opts.ConnectionPriorityTCPLAN,
+		tcpWANPriority: opts.ConnectionPriorityTCPWAN,
 	}
 }
 
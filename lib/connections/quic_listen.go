@@ -117,7 +117,7 @@ func (t *quicListener) serve(ctx context.Context) error {
 	t.registry.Register(t.uri.Scheme, quicTransport)
 	defer t.registry.Unregister(t.uri.Scheme, quicTransport)
 
-	listener, err := quicTransport.Listen(t.tlsCfg, quicConfig)
+	listener, err := quicTransport.Listen(t.tlsCfg, quicPaths.listenConfig())
 	if err != nil {
 		slog.WarnContext(ctx, "Failed to listen (QUIC)", slogutil.Error(err))
 		return err
@@ -198,11 +198,15 @@ func (t *quicListener) serve(ctx context.Context) error {
 			continue
 		}
 
-		priority := t.cfg.Options().ConnectionPriorityQUICWAN
+		quicPaths.attach(session)
+
+		opts := t.cfg.Options()
+		priority, tcpPriority := opts.ConnectionPriorityQUICWAN, opts.ConnectionPriorityTCPWAN
 		isLocal := t.lanChecker.isLAN(session.RemoteAddr())
 		if isLocal {
-			priority = t.cfg.Options().ConnectionPriorityQUICLAN
+			priority, tcpPriority = opts.ConnectionPriorityQUICLAN, opts.ConnectionPriorityTCPLAN
 		}
+		priority = quicPaths.priority(session.RemoteAddr().String(), priority, tcpPriority)
 		conn := &quicTlsConn{
 			Conn:        session,
 			Stream:      stream,
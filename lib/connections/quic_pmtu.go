@@ -0,0 +1,400 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build !noquic
+// +build !noquic
+
+package connections
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/logging"
+)
+
+const (
+	// The datagram size we clamp to on networks where larger datagrams
+	// were found to be dropped. This is the smallest size QUIC permits.
+	quicClampedPacketSize = 1200
+
+	// Number of lost packets larger than the clamped size, each of which
+	// fit within the discovered path MTU, without any of them getting
+	// through while smaller packets do, after which we consider the path a
+	// blackhole for large datagrams.
+	quicBlackholeLostPackets = 5
+
+	// How long we clamp the datagram size on a network after detecting a
+	// blackhole, before trying path MTU discovery again.
+	quicClampDuration = time.Hour
+
+	// Detecting this many blackholes on a network within the window means
+	// the problem is persistent, and QUIC is demoted below TCP there.
+	quicDemoteAfterBlackholes = 3
+	quicBlackholeWindow       = 24 * time.Hour
+	quicDemoteDuration        = 24 * time.Hour
+
+	// Upper bound on the number of in flight large packets tracked per
+	// connection.
+	maxTrackedLargePackets = 1024
+
+	// Application error code used when closing a connection that ran into
+	// a blackhole, so that it gets redialed with a clamped datagram size.
+	quicErrBlackhole quic.ApplicationErrorCode = 2
+)
+
+// quicPaths keeps track of path MTU discovery outcomes for all QUIC
+// connections.
+var quicPaths = newQUICPathTracker()
+
+// quicPathTracker does path MTU blackhole detection on QUIC connections,
+// per remote network. Some networks silently drop large UDP datagrams,
+// which lets QUIC connect (handshake packets are small) but stall as soon
+// as full sized packets are sent. On such networks we clamp the datagram
+// size for new connections, and demote QUIC below TCP when that keeps
+// happening.
+type quicPathTracker struct {
+	mut      sync.Mutex
+	networks map[string]*quicNetworkState
+	monitors map[quic.ConnectionTracingID]*quicPathMonitor
+}
+
+type quicNetworkState struct {
+	mtu          int
+	blackholes   []time.Time // detections within quicBlackholeWindow
+	clampedUntil time.Time
+	demotedUntil time.Time
+}
+
+// expired returns true when nothing about the network is worth remembering
+// any more.
+func (s *quicNetworkState) expired(now time.Time) bool {
+	if now.Before(s.clampedUntil) || now.Before(s.demotedUntil) {
+		return false
+	}
+	for _, ts := range s.blackholes {
+		if now.Sub(ts) <= quicBlackholeWindow {
+			return false
+		}
+	}
+	return true
+}
+
+func newQUICPathTracker() *quicPathTracker {
+	return &quicPathTracker{
+		networks: make(map[string]*quicNetworkState),
+		monitors: make(map[quic.ConnectionTracingID]*quicPathMonitor),
+	}
+}
+
+// config returns the QUIC configuration to use for a connection to the
+// given remote address.
+func (t *quicPathTracker) config(remote net.Addr) *quic.Config {
+	cfg := quicConfig.Clone()
+	cfg.Tracer = t.connectionTracer
+	if t.clamped(quicNetworkKey(remote.String()), time.Now()) {
+		cfg.DisablePathMTUDiscovery = true
+		cfg.InitialPacketSize = quicClampedPacketSize
+	}
+	return cfg
+}
+
+// listenConfig returns the QUIC configuration for a listener, which picks
+// the configuration per connecting client.
+func (t *quicPathTracker) listenConfig() *quic.Config {
+	cfg := quicConfig.Clone()
+	cfg.Tracer = t.connectionTracer
+	cfg.GetConfigForClient = func(info *quic.ClientInfo) (*quic.Config, error) {
+		return t.config(info.RemoteAddr), nil
+	}
+	return cfg
+}
+
+// attach associates an established connection with its monitor, so that
+// it can be closed when it runs into a blackhole.
+func (t *quicPathTracker) attach(conn *quic.Conn) {
+	id, ok := conn.Context().Value(quic.ConnectionTracingKey).(quic.ConnectionTracingID)
+	if !ok {
+		return
+	}
+	t.mut.Lock()
+	mon, ok := t.monitors[id]
+	t.mut.Unlock()
+	if ok {
+		mon.setConn(conn)
+	}
+}
+
+// priority returns the connection priority to use for QUIC towards the
+// given host. If QUIC is demoted on its network it ranks just below TCP.
+func (t *quicPathTracker) priority(host string, priority, tcpPriority int) int {
+	if t.demoted(quicNetworkKey(host), time.Now()) {
+		return max(priority, tcpPriority+1)
+	}
+	return priority
+}
+
+func (t *quicPathTracker) connectionTracer(ctx context.Context, _ logging.Perspective, _ quic.ConnectionID) *logging.ConnectionTracer {
+	id, ok := ctx.Value(quic.ConnectionTracingKey).(quic.ConnectionTracingID)
+	if !ok {
+		return nil
+	}
+	mon := &quicPathMonitor{
+		tracker: t,
+		id:      id,
+		large:   make(map[logging.PacketNumber]struct{}),
+	}
+	t.mut.Lock()
+	t.monitors[id] = mon
+	t.mut.Unlock()
+	return mon.loggingTracer()
+}
+
+func (t *quicPathTracker) clamped(network string, now time.Time) bool {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	state, ok := t.networks[network]
+	return ok && now.Before(state.clampedUntil)
+}
+
+func (t *quicPathTracker) demoted(network string, now time.Time) bool {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	state, ok := t.networks[network]
+	return ok && now.Before(state.demotedUntil)
+}
+
+func (t *quicPathTracker) networkLocked(network string) *quicNetworkState {
+	state, ok := t.networks[network]
+	if !ok {
+		state = &quicNetworkState{}
+		t.networks[network] = state
+	}
+	return state
+}
+
+func (t *quicPathTracker) setMTU(network string, mtu int) {
+	if network == "" {
+		return
+	}
+	t.mut.Lock()
+	t.networkLocked(network).mtu = mtu
+	t.mut.Unlock()
+}
+
+func (t *quicPathTracker) reportBlackhole(network string, now time.Time) {
+	t.mut.Lock()
+	state := t.networkLocked(network)
+	state.blackholes = append(state.blackholes, now)
+	for len(state.blackholes) > 0 && now.Sub(state.blackholes[0]) > quicBlackholeWindow {
+		state.blackholes = state.blackholes[1:]
+	}
+	state.clampedUntil = now.Add(quicClampDuration)
+	demote := len(state.blackholes) >= quicDemoteAfterBlackholes && !now.Before(state.demotedUntil)
+	if demote {
+		state.demotedUntil = now.Add(quicDemoteDuration)
+	}
+	detections := len(state.blackholes)
+	t.mut.Unlock()
+
+	if demote {
+		slog.Warn("Persistent QUIC path MTU blackhole detected; preferring TCP on this network", "network", network, "detections", detections, "until", now.Add(quicDemoteDuration))
+	} else {
+		slog.Info("QUIC path MTU blackhole detected; clamping datagram size", "network", network, "size", quicClampedPacketSize)
+	}
+}
+
+func (t *quicPathTracker) remove(id quic.ConnectionTracingID) {
+	t.mut.Lock()
+	delete(t.monitors, id)
+	t.pruneLocked(time.Now())
+	t.mut.Unlock()
+}
+
+// pruneLocked forgets the networks without open connections that are
+// neither clamped nor demoted and have no blackhole detections within the
+// window, so that we don't keep state for every network we ever talked to.
+func (t *quicPathTracker) pruneLocked(now time.Time) {
+	open := make(map[string]struct{}, len(t.monitors))
+	for _, mon := range t.monitors {
+		mon.mut.Lock()
+		open[mon.network] = struct{}{}
+		mon.mut.Unlock()
+	}
+	for network, state := range t.networks {
+		if _, ok := open[network]; !ok && state.expired(now) {
+			delete(t.networks, network)
+		}
+	}
+}
+
+func (t *quicPathTracker) status(network string, now time.Time) *QUICPathStatus {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	state, ok := t.networks[network]
+	if !ok {
+		return nil
+	}
+	status := &QUICPathStatus{
+		MTU:     state.mtu,
+		Clamped: now.Before(state.clampedUntil),
+		Demoted: now.Before(state.demotedUntil),
+	}
+	for _, ts := range state.blackholes {
+		if now.Sub(ts) <= quicBlackholeWindow {
+			status.Blackholes++
+			status.LastBlackhole = ts
+		}
+	}
+	if status.Demoted {
+		status.DemotedUntil = state.demotedUntil
+	}
+	return status
+}
+
+// quicPathStatus returns the path MTU detection results for the network of
+// a QUIC address, or nil if there are none.
+func quicPathStatus(address string) *QUICPathStatus {
+	uri, err := url.Parse(address)
+	if err != nil || !strings.HasPrefix(uri.Scheme, "quic") {
+		return nil
+	}
+	return quicPaths.status(quicNetworkKey(uri.Host), time.Now())
+}
+
+// quicNetworkKey returns the network a host:port belongs to for the
+// purpose of blackhole tracking, the /24 for IPv4 and the /64 for IPv6.
+// Hosts that are not IP addresses are their own network.
+func quicNetworkKey(hostport string) string {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return host
+	}
+	ip = ip.Unmap()
+	bits := 64
+	if ip.Is4() {
+		bits = 24
+	}
+	prefix, err := ip.Prefix(bits)
+	if err != nil {
+		return host
+	}
+	return prefix.String()
+}
+
+// quicPathMonitor watches a single QUIC connection for large packets being
+// lost while smaller ones get through.
+type quicPathMonitor struct {
+	tracker *quicPathTracker
+	id      quic.ConnectionTracingID
+
+	mut        sync.Mutex
+	network    string
+	conn       *quic.Conn
+	mtu        logging.ByteCount
+	large      map[logging.PacketNumber]struct{}
+	lostLarge  int // since the last acknowledged large packet
+	ackedSmall int // since the first of those losses
+	detected   bool
+}
+
+func (m *quicPathMonitor) loggingTracer() *logging.ConnectionTracer {
+	return &logging.ConnectionTracer{
+		StartedConnection: func(_, remote net.Addr, _, _ logging.ConnectionID) {
+			m.mut.Lock()
+			m.network = quicNetworkKey(remote.String())
+			m.mut.Unlock()
+		},
+		UpdatedMTU: func(mtu logging.ByteCount, _ bool) {
+			m.mut.Lock()
+			m.mtu = mtu
+			network := m.network
+			m.mut.Unlock()
+			m.tracker.setMTU(network, int(mtu))
+		},
+		SentShortHeaderPacket: func(hdr *logging.ShortHeader, size logging.ByteCount, _ logging.ECN, _ *logging.AckFrame, _ []logging.Frame) {
+			m.sent(hdr.PacketNumber, size)
+		},
+		AcknowledgedPacket: func(level logging.EncryptionLevel, pn logging.PacketNumber) {
+			if level == logging.Encryption1RTT {
+				m.acknowledged(pn)
+			}
+		},
+		LostPacket: func(level logging.EncryptionLevel, pn logging.PacketNumber, _ logging.PacketLossReason) {
+			if level == logging.Encryption1RTT {
+				m.lost(pn)
+			}
+		},
+		ClosedConnection: func(error) {
+			m.tracker.remove(m.id)
+		},
+	}
+}
+
+func (m *quicPathMonitor) setConn(conn *quic.Conn) {
+	m.mut.Lock()
+	m.conn = conn
+	m.mut.Unlock()
+}
+
+func (m *quicPathMonitor) sent(pn logging.PacketNumber, size logging.ByteCount) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	// Packets larger than the confirmed path MTU are discovery probes,
+	// which are expected to get lost now and then.
+	if size > quicClampedPacketSize && size <= m.mtu && len(m.large) < maxTrackedLargePackets {
+		m.large[pn] = struct{}{}
+	}
+}
+
+func (m *quicPathMonitor) acknowledged(pn logging.PacketNumber) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if _, ok := m.large[pn]; ok {
+		delete(m.large, pn)
+		m.lostLarge = 0
+		m.ackedSmall = 0
+	} else if m.lostLarge > 0 {
+		m.ackedSmall++
+	}
+}
+
+func (m *quicPathMonitor) lost(pn logging.PacketNumber) {
+	m.mut.Lock()
+	if _, ok := m.large[pn]; !ok {
+		m.mut.Unlock()
+		return
+	}
+	delete(m.large, pn)
+	m.lostLarge++
+	if m.detected || m.lostLarge < quicBlackholeLostPackets || m.ackedSmall == 0 {
+		m.mut.Unlock()
+		return
+	}
+	m.detected = true
+	network, conn := m.network, m.conn
+	m.mut.Unlock()
+
+	m.tracker.reportBlackhole(network, time.Now())
+	if conn != nil {
+		// The datagram size of a live connection can't be lowered, so
+		// close it and let it be redialed with the clamped size. This
+		// runs on the connection's own goroutine, which closing waits
+		// for.
+		go conn.CloseWithError(quicErrBlackhole, "path MTU blackhole")
+	}
+}
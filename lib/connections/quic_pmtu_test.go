@@ -0,0 +1,148 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build !noquic
+// +build !noquic
+
+package connections
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/logging"
+)
+
+func TestQUICNetworkKey(t *testing.T) {
+	cases := map[string]string{
+		"192.0.2.17:22000":         "192.0.2.0/24",
+		"[2001:db8:1:2::5]:22000":  "2001:db8:1:2::/64",
+		"[::ffff:192.0.2.1]:22000": "192.0.2.0/24",
+		"example.com:22000":        "example.com",
+	}
+	for in, expected := range cases {
+		if actual := quicNetworkKey(in); actual != expected {
+			t.Errorf("quicNetworkKey(%q) = %q, expected %q", in, actual, expected)
+		}
+	}
+}
+
+func TestQUICBlackholeDetection(t *testing.T) {
+	tracker := newQUICPathTracker()
+	mon := &quicPathMonitor{
+		tracker: tracker,
+		large:   make(map[logging.PacketNumber]struct{}),
+	}
+	tracer := mon.loggingTracer()
+	remote := &net.UDPAddr{IP: net.ParseIP("192.0.2.17"), Port: 22000}
+	tracer.StartedConnection(nil, remote, logging.ConnectionID{}, logging.ConnectionID{})
+	tracer.UpdatedMTU(1452, true)
+
+	pn := logging.PacketNumber(0)
+	send := func(size logging.ByteCount) logging.PacketNumber {
+		pn++
+		tracer.SentShortHeaderPacket(&logging.ShortHeader{PacketNumber: pn}, size, logging.ECNUnsupported, nil, nil)
+		return pn
+	}
+
+	// Lost MTU probes, larger than the confirmed MTU, don't count.
+	for range quicBlackholeLostPackets {
+		tracer.LostPacket(logging.Encryption1RTT, send(1500), logging.PacketLossTimeThreshold)
+	}
+	tracer.AcknowledgedPacket(logging.Encryption1RTT, send(100))
+	if mon.detected {
+		t.Fatal("lost probes must not be detected as a blackhole")
+	}
+
+	// Full sized packets getting lost while small ones get through do.
+	for range quicBlackholeLostPackets {
+		tracer.LostPacket(logging.Encryption1RTT, send(1400), logging.PacketLossTimeThreshold)
+		tracer.AcknowledgedPacket(logging.Encryption1RTT, send(100))
+	}
+	if !mon.detected {
+		t.Fatal("expected blackhole to be detected")
+	}
+
+	status := quicPathStatusFrom(tracker, remote.String())
+	if status == nil || !status.Clamped || status.Demoted || status.Blackholes != 1 || status.MTU != 1452 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if cfg := tracker.config(remote); !cfg.DisablePathMTUDiscovery || cfg.InitialPacketSize != quicClampedPacketSize {
+		t.Error("expected new connections to the network to be clamped")
+	}
+	if cfg := tracker.config(&net.UDPAddr{IP: net.ParseIP("198.51.100.1"), Port: 22000}); cfg.DisablePathMTUDiscovery {
+		t.Error("expected other networks to be unaffected")
+	}
+}
+
+func TestQUICBlackholeDemotion(t *testing.T) {
+	tracker := newQUICPathTracker()
+	const host = "192.0.2.17:22000"
+	network := quicNetworkKey(host)
+	now := time.Now()
+
+	for i := range quicDemoteAfterBlackholes - 1 {
+		tracker.reportBlackhole(network, now.Add(time.Duration(i)*time.Minute))
+	}
+	if p := tracker.priority(host, 40, 30); p != 40 {
+		t.Errorf("expected undemoted priority 40, got %d", p)
+	}
+
+	tracker.reportBlackhole(network, now.Add(time.Hour))
+	if p := tracker.priority(host, 40, 30); p != 40 {
+		t.Errorf("expected priority worse than TCP to stay 40, got %d", p)
+	}
+	if p := tracker.priority(host, 20, 30); p != 31 {
+		t.Errorf("expected demoted priority 31, got %d", p)
+	}
+
+	// Old detections age out of the window.
+	tracker = newQUICPathTracker()
+	tracker.reportBlackhole(network, now.Add(-2*quicBlackholeWindow))
+	tracker.reportBlackhole(network, now.Add(-quicBlackholeWindow-time.Minute))
+	tracker.reportBlackhole(network, now)
+	if tracker.demoted(network, now) {
+		t.Error("expected no demotion for detections outside the window")
+	}
+}
+
+func TestQUICNetworkPruning(t *testing.T) {
+	tracker := newQUICPathTracker()
+	now := time.Now()
+	open, closed := "192.0.2.0/24", "198.51.100.0/24"
+	old, recent := "203.0.113.0/24", "2001:db8::/64"
+
+	tracker.monitors[1] = &quicPathMonitor{tracker: tracker, id: 1, network: open}
+	tracker.setMTU(open, 1452)
+	tracker.setMTU(closed, 1452)
+	tracker.reportBlackhole(old, now.Add(-2*quicBlackholeWindow))
+	tracker.reportBlackhole(recent, now.Add(-time.Minute))
+
+	tracker.mut.Lock()
+	tracker.pruneLocked(now)
+	tracker.mut.Unlock()
+	for _, network := range []string{open, recent} {
+		if tracker.status(network, now) == nil {
+			t.Errorf("expected %s to be kept", network)
+		}
+	}
+	for _, network := range []string{closed, old} {
+		if tracker.status(network, now) != nil {
+			t.Errorf("expected %s to be pruned", network)
+		}
+	}
+
+	// Closing the last connection on a network forgets it.
+	tracker.remove(1)
+	if tracker.status(open, now) != nil {
+		t.Errorf("expected %s to be pruned after its connection closed", open)
+	}
+}
+
+func quicPathStatusFrom(tracker *quicPathTracker, host string) *QUICPathStatus {
+	return tracker.status(quicNetworkKey(host), time.Now())
+}
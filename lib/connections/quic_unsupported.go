@@ -21,3 +21,7 @@ func init() {
 		dialers[scheme] = invalidDialer{err: errNotInBuild}
 	}
 }
+
+func quicPathStatus(string) *QUICPathStatus {
+	return nil
+}
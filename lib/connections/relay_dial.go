@@ -28,7 +28,7 @@ type relayDialer struct {
 }
 
 func (d *relayDialer) Dial(ctx context.Context, id protocol.DeviceID, uri *url.URL) (internalConn, error) {
-	inv, err := client.GetInvitationFromRelay(ctx, uri, id, d.tlsCfg.Certificates, 10*time.Second)
+	conn, inv, err := client.DialSession(ctx, uri, id, d.tlsCfg.Certificates, 10*time.Second)
 	if err != nil {
 		// Record connection failure for health monitoring
 		if globalService != nil {
@@ -36,36 +36,33 @@ func (d *relayDialer) Dial(ctx context.Context, id protocol.DeviceID, uri *url.U
 		}
 		return internalConn{}, err
 	}
-
-	conn, err := client.JoinSession(ctx, inv)
-	if err != nil {
-		// Record connection failure for health monitoring
-		if globalService != nil {
-			globalService.healthMonitor.RecordConnectionError(id, uri.Host, err)
+	trace := connTraceFrom(ctx)
+	trace.step(stepDial)
+
+	// Sessions multiplexed over the relay connection have no socket of
+	// their own to set options on.
+	if !client.IsMultiplexed(conn) {
+		err = dialer.SetTCPOptions(conn)
+		if err != nil {
+			conn.Close()
+			// Record connection failure for health monitoring
+			if globalService != nil {
+				globalService.healthMonitor.RecordConnectionError(id, uri.Host, err)
+			}
+			return internalConn{}, err
 		}
-		return internalConn{}, err
-	}
 
-	err = dialer.SetTCPOptions(conn)
-	if err != nil {
-		conn.Close()
-		// Record connection failure for health monitoring
-		if globalService != nil {
-			globalService.healthMonitor.RecordConnectionError(id, uri.Host, err)
+		err = dialer.SetTrafficClass(conn, d.trafficClass)
+		if err != nil {
+			l.Debugln("Dial (BEP/relay): setting traffic class:", err)
 		}
-		return internalConn{}, err
-	}
-
-	err = dialer.SetTrafficClass(conn, d.trafficClass)
-	if err != nil {
-		l.Debugln("Dial (BEP/relay): setting traffic class:", err)
 	}
 
 	var tc *tls.Conn
 	if inv.ServerSocket {
-		tc = tls.Server(conn, d.tlsCfg)
+		tc = tls.Server(newMeteredConn(conn), d.tlsCfg)
 	} else {
-		tc = tls.Client(conn, d.tlsCfg)
+		tc = tls.Client(newMeteredConn(conn), d.tlsCfg)
 	}
 
 	// Get progressive dial timeout based on connection history
@@ -95,6 +92,7 @@ func (d *relayDialer) Dial(ctx context.Context, id protocol.DeviceID, uri *url.U
 		tc.Close()
 		return internalConn{}, err
 	}
+	trace.step(stepTLS)
 
 	return newInternalConn(tc, connTypeRelayClient, false, d.wanPriority), nil
 }
@@ -47,7 +47,11 @@ type relayListener struct {
 }
 
 func (t *relayListener) serve(ctx context.Context) error {
-	clnt, err := client.NewClient(t.uri, t.tlsCfg.Certificates, 10*time.Second)
+	var opts []client.Option
+	if t.cfg.Options().RelaySpeedTestEnabled {
+		opts = append(opts, client.OptionSpeedTest())
+	}
+	clnt, err := client.NewClient(t.uri, t.tlsCfg.Certificates, 10*time.Second, opts...)
 	if err != nil {
 		slog.WarnContext(ctx, "Failed to listen (relay)", slogutil.Error(err))
 		// Record connection failure for health monitoring
@@ -81,7 +85,7 @@ func (t *relayListener) handleInvitations(ctx context.Context, clnt client.Relay
 	for {
 		select {
 		case inv := <-invitations:
-			conn, err := client.JoinSession(ctx, inv)
+			conn, err := clnt.JoinSession(ctx, inv)
 			if err != nil {
 				if !errors.Is(err, context.Canceled) {
 					slog.InfoContext(ctx, "Failed to join session", slogutil.Error(err))
@@ -93,21 +97,23 @@ func (t *relayListener) handleInvitations(ctx context.Context, clnt client.Relay
 				continue
 			}
 
-			err = dialer.SetTCPOptions(conn)
-			if err != nil {
-				slog.DebugContext(ctx, "Failed to set TCP options", slogutil.Error(err))
-			}
+			if !client.IsMultiplexed(conn) {
+				err = dialer.SetTCPOptions(conn)
+				if err != nil {
+					slog.DebugContext(ctx, "Failed to set TCP options", slogutil.Error(err))
+				}
 
-			err = dialer.SetTrafficClass(conn, t.cfg.Options().TrafficClass)
-			if err != nil {
-				slog.DebugContext(ctx, "Failed to set traffic class", slogutil.Error(err))
+				err = dialer.SetTrafficClass(conn, t.cfg.Options().TrafficClass)
+				if err != nil {
+					slog.DebugContext(ctx, "Failed to set traffic class", slogutil.Error(err))
+				}
 			}
 
 			var tc *tls.Conn
 			if inv.ServerSocket {
-				tc = tls.Server(conn, t.tlsCfg)
+				tc = tls.Server(newMeteredConn(conn), t.tlsCfg)
 			} else {
-				tc = tls.Client(conn, t.tlsCfg)
+				tc = tls.Client(newMeteredConn(conn), t.tlsCfg)
 			}
 
 			// Get progressive dial timeout based on connection history
@@ -8,12 +8,20 @@ package connections
 
 import (
 	"context"
-	"crypto/rand"
-	"math"
-	"math/big"
 	"time"
+
+	"github.com/syncthing/syncthing/lib/retry"
 )
 
+// dialRetryPolicy is the default policy for redialing an address that
+// failed: the usual redial interval applies unless the policy asks for a
+// longer wait.
+var dialRetryPolicy = retry.Policy{
+	BaseDelay: time.Minute,
+	MaxDelay:  5 * time.Minute,
+	Jitter:    0.1,
+}
+
 // RetryConfig holds configuration for retry logic
 type RetryConfig struct {
 	MaxRetries    int           // Maximum number of retries
@@ -80,72 +88,29 @@ func AdaptiveRetryConfig(category ErrorCategory) RetryConfig {
 	return config
 }
 
+// policy returns the configuration as a retry policy
+func (c RetryConfig) policy() retry.Policy {
+	return retry.Policy{
+		BaseDelay: c.BaseDelay,
+		MaxDelay:  c.MaxDelay,
+		Factor:    c.BackoffFactor,
+		Jitter:    c.Jitter,
+	}
+}
+
 // calculateBackoff calculates the backoff time for a retry attempt
 func calculateBackoff(config RetryConfig, attempt int) time.Duration {
-	// Calculate exponential backoff
-	delay := float64(config.BaseDelay) * math.Pow(config.BackoffFactor, float64(attempt))
-	
-	// Cap at maximum delay
-	if delay > float64(config.MaxDelay) {
-		delay = float64(config.MaxDelay)
-	}
-	
-	// Add jitter to prevent thundering herd
-	if config.Jitter > 0 && config.Jitter <= 1.0 {
-		// Generate random jitter factor between (1-jitter) and (1+jitter)
-		jitterFactor := 1.0
-		if n, err := rand.Int(rand.Reader, big.NewInt(1000)); err == nil {
-			jitterRange := config.Jitter * 2.0
-			jitterFactor = 1.0 - config.Jitter + (float64(n.Int64())/1000.0)*jitterRange
-		}
-		delay *= jitterFactor
-	}
-	
-	return time.Duration(delay)
+	return config.policy().Delay(attempt + 1)
 }
 
 // RetryFunc is a function that can be retried
 type RetryFunc func(ctx context.Context) error
 
-// Retry executes a function with retry logic based on the provided configuration
+// Retry executes a function with retry logic based on the provided
+// configuration, which is the default for the dialing policy: configured
+// overrides of it apply.
 func Retry(ctx context.Context, config RetryConfig, fn RetryFunc) error {
-	var lastErr error
-	
-	for attempt := 0; attempt <= config.MaxRetries; attempt++ {
-		// Execute the function
-		err := fn(ctx)
-		if err == nil {
-			// Success, return nil
-			return nil
-		}
-		
-		// Store the last error
-		lastErr = err
-		
-		// If this was the last attempt, don't sleep and retry
-		if attempt == config.MaxRetries {
-			break
-		}
-		
-		// Calculate backoff time
-		backoff := calculateBackoff(config, attempt)
-		
-		// Create a timer for the backoff
-		timer := time.NewTimer(backoff)
-		
-		// Wait for either the backoff to complete or context cancellation
-		select {
-		case <-timer.C:
-			// Backoff completed, continue to next attempt
-		case <-ctx.Done():
-			// Context cancelled, stop retrying
-			timer.Stop()
-			return ctx.Err()
-		}
-	}
-	
-	// All retries exhausted, return the last error
-	return lastErr
+	return retry.Do(ctx, retry.Dialing, config.policy(), config.MaxRetries+1, fn)
 }
 
 // RetryWithBackoff executes a function with exponential backoff and jitter
@@ -0,0 +1,123 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+const (
+	// How long requests in flight on a connection that is being replaced
+	// are given to finish
+	rollingDrainTimeout = 5 * time.Second
+	// How long the replacement of a connection may take to be established
+	rollingReplaceTimeout = time.Minute
+)
+
+// startRolling marks the device as having its connections replaced one at
+// a time, returning the connections to replace. It returns false if that
+// is already under way.
+func (c *deviceConnectionTracker) startRolling(d protocol.DeviceID) ([]protocol.Connection, bool) {
+	c.connectionsMut.Lock()
+	defer c.connectionsMut.Unlock()
+	if _, ok := c.rolling[d]; ok {
+		return nil, false
+	}
+	if c.rolling == nil {
+		c.rolling = make(map[protocol.DeviceID]chan struct{})
+	}
+	c.rolling[d] = make(chan struct{})
+	return append([]protocol.Connection(nil), c.connections[d]...), true
+}
+
+func (c *deviceConnectionTracker) finishRolling(d protocol.DeviceID) {
+	c.connectionsMut.Lock()
+	defer c.connectionsMut.Unlock()
+	delete(c.rolling, d)
+}
+
+// connectionAddedLocked wakes up a rolling replacement waiting for a new
+// connection to the device.
+func (c *deviceConnectionTracker) connectionAddedLocked(d protocol.DeviceID) {
+	if added, ok := c.rolling[d]; ok {
+		close(added)
+		c.rolling[d] = make(chan struct{})
+	}
+}
+
+// rollingAdded returns a channel that is closed when a connection to the
+// device is next added.
+func (c *deviceConnectionTracker) rollingAdded(d protocol.DeviceID) <-chan struct{} {
+	c.connectionsMut.Lock()
+	defer c.connectionsMut.Unlock()
+	return c.rolling[d]
+}
+
+// ReconnectDevice closes the connections to the device, to be re-dialed,
+// for example to apply configuration changes. A single connection is
+// simply closed. Several are replaced one at a time: each in turn is taken
+// off the packet scheduler, given time to finish the requests in flight,
+// closed, and re-dialed, and the next is only closed once a new connection
+// has been established. That way at least one connection to the device
+// remains throughout. Should a replacement fail to be established, the
+// remaining connections are closed at once.
+func (s *service) ReconnectDevice(device protocol.DeviceID, reason error) {
+	conns, ok := s.startRolling(device)
+	if !ok {
+		l.Debugf("Already reconnecting to %s", device.Short())
+		return
+	}
+	if len(conns) <= 1 {
+		s.finishRolling(device)
+		for _, conn := range conns {
+			conn.Close(reason)
+		}
+		return
+	}
+
+	go func() {
+		defer s.finishRolling(device)
+		// The oldest connection, which is normally the primary one
+		// carrying the index data, is replaced last.
+		for i := len(conns) - 1; i >= 0; i-- {
+			conn := conns[i]
+			added := s.rollingAdded(device)
+			s.drainConnection(device, conn)
+			// Closing makes the device be dialed again right away.
+			conn.Close(reason)
+			if i == 0 {
+				break
+			}
+
+			select {
+			case <-added:
+				l.Debugf("Replaced connection %s to %s", conn, device.Short())
+			case <-time.After(rollingReplaceTimeout):
+				slog.Warn("Failed to replace connection with device in time, closing the remaining ones", device.LogAttr(), slog.Int("remaining", i), slogutil.Error(reason))
+				for _, conn := range conns[:i] {
+					conn.Close(reason)
+				}
+				return
+			}
+		}
+		slog.Info("Replaced connections with device", device.LogAttr(), slog.Int("connections", len(conns)), slogutil.Error(reason))
+	}()
+}
+
+// drainConnection takes the connection off the packet scheduler and waits
+// for the block requests striped over it to be answered, for a while.
+func (s *service) drainConnection(device protocol.DeviceID, conn protocol.Connection) {
+	s.packetScheduler.DrainConnection(device, conn.ConnectionID())
+	deadline := time.Now().Add(rollingDrainTimeout)
+	for s.packetScheduler.Outstanding(conn.ConnectionID()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(100 * time.Millisecond)
+	}
+}
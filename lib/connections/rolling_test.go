@@ -0,0 +1,96 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestRollingSignalsAddedConnections(t *testing.T) {
+	d := protocol.DeviceID{1}
+	c := &deviceConnectionTracker{
+		connections: map[protocol.DeviceID][]protocol.Connection{
+			d: {NewEnhancedMockConnection("a", d, 10, 100), NewEnhancedMockConnection("b", d, 10, 100)},
+		},
+	}
+
+	conns, ok := c.startRolling(d)
+	if !ok || len(conns) != 2 {
+		t.Fatalf("expected to start rolling two connections, got %v, %v", len(conns), ok)
+	}
+	if _, ok := c.startRolling(d); ok {
+		t.Fatal("expected rolling to be under way already")
+	}
+
+	added := c.rollingAdded(d)
+	c.connectionsMut.Lock()
+	c.connectionAddedLocked(protocol.DeviceID{2})
+	c.connectionsMut.Unlock()
+	select {
+	case <-added:
+		t.Fatal("a connection to another device should not be signalled")
+	default:
+	}
+
+	c.connectionsMut.Lock()
+	c.connectionAddedLocked(d)
+	c.connectionsMut.Unlock()
+	select {
+	case <-added:
+	default:
+		t.Fatal("expected the added connection to be signalled")
+	}
+	select {
+	case <-c.rollingAdded(d):
+		t.Fatal("expected a fresh channel for the next connection")
+	default:
+	}
+
+	c.finishRolling(d)
+	if _, ok := c.startRolling(d); !ok {
+		t.Error("expected rolling to be possible again once finished")
+	}
+}
+
+func TestDrainConnectionKeepsAccounting(t *testing.T) {
+	d := protocol.DeviceID{1}
+	ps := NewPacketScheduler()
+	ps.SetStriping(true)
+	a := NewEnhancedMockConnection("a", d, 10, 100)
+	b := NewEnhancedMockConnection("b", d, 10, 100)
+	ps.AddConnection(d, a)
+	ps.AddConnection(d, b)
+
+	var dones []func(error)
+	for range 2 {
+		conn, done := ps.StripeRequest(d, 128<<10, "")
+		if conn.ConnectionID() != "a" {
+			t.Fatalf("expected the first of equal connections to be selected, got %s", conn.ConnectionID())
+		}
+		dones = append(dones, done)
+	}
+
+	ps.DrainConnection(d, "a")
+	for range 2 {
+		conn, done := ps.StripeRequest(d, 128<<10, "")
+		if conn.ConnectionID() != "b" {
+			t.Fatalf("expected a drained connection not to be selected, got %s", conn.ConnectionID())
+		}
+		dones = append(dones, done)
+	}
+	if ps.Outstanding("a") != 256<<10 {
+		t.Fatalf("expected the requests in flight on the drained connection to be accounted for, got %d", ps.Outstanding("a"))
+	}
+	for _, done := range dones {
+		done(nil)
+	}
+	if ps.Outstanding("a") != 0 || ps.Outstanding("b") != 0 {
+		t.Errorf("expected nothing outstanding, got %d and %d", ps.Outstanding("a"), ps.Outstanding("b"))
+	}
+}
@@ -10,6 +10,7 @@ package connections
 
 import (
 	"context"
+	"crypto"
 	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
@@ -29,6 +30,8 @@ import (
 
 	"github.com/thejerf/suture/v4"
 
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/faultinject"
 	"github.com/syncthing/syncthing/internal/slogutil"
 	"github.com/syncthing/syncthing/lib/build"
 	"github.com/syncthing/syncthing/lib/config"
@@ -38,6 +41,7 @@ import (
 	"github.com/syncthing/syncthing/lib/nat"
 	"github.com/syncthing/syncthing/lib/osutil"
 	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/retry"
 	"github.com/syncthing/syncthing/lib/semaphore"
 	"github.com/syncthing/syncthing/lib/sliceutil"
 	"github.com/syncthing/syncthing/lib/stringutil"
@@ -65,15 +69,27 @@ var (
 
 	// Various reasons to reject a connection
 	errNetworkNotAllowed      = errors.New("network not allowed")
+	errTransportNotAllowed    = errors.New("transport not allowed")
 	errDeviceAlreadyConnected = errors.New("already connected to this device")
 	errDeviceIgnored          = errors.New("device is ignored")
 	errConnLimitReached       = errors.New("connection limit reached")
 	errDevicePaused           = errors.New("device is paused")
+	errCertificateExpired     = errors.New("certificate has expired")
+	errCertificateNotYetValid = errors.New("certificate is not yet valid")
+	errBlockSealingRequired   = errors.New("block sealing is required on relayed connections")
 
 	// A connection is being closed to make space for better ones
 	errReplacingConnection = errors.New("replacing connection")
+
+	// A connection is being closed to be re-established with a new
+	// certificate
+	errCertificateReloaded = errors.New("device certificate reloaded")
 )
 
+// ErrCertificateIdentity is returned by SetCertificate for a certificate
+// of another device ID, which can't be taken on without a restart.
+var ErrCertificateIdentity = errors.New("certificate is for another device ID")
+
 const (
 	// Base timeout values - will be made adaptive
 	minTLSHandshakeTimeout  = 5 * time.Second
@@ -95,6 +111,7 @@ const (
 	dialMaxParallel               = 64
 	dialMaxParallelPerDevice      = 8
 	maxNumConnections             = 128 // the maximum number of connections we maintain to any given device
+	listenerRestartTimeout        = 10 * time.Second
 )
 
 // From go/src/crypto/tls/cipher_suites.go
@@ -141,11 +158,22 @@ type Service interface {
 	discover.AddressLister
 	ListenerStatus() map[string]ListenerStatusEntry
 	ConnectionStatus() map[string]ConnectionStatusEntry
+	ConnectionAttempts(device protocol.DeviceID) []ConnectionAttempt
+	ConnectionErrorBudgets() map[string]DeviceErrorBudget // by device ID
+	AcknowledgeConnectionErrorBudget(device protocol.DeviceID) bool
+	ConnectionHealth() ConnectionHealthReport
+	ReconnectDevice(device protocol.DeviceID, reason error)
+	LimitFolderSend(ctx context.Context, folder string, n int) error
+	LimitFolderRecv(ctx context.Context, folder string, n int) error
 	NATType() string
 	GetConnectedDevices() []protocol.DeviceID
 	GetConnectionsForDevice(deviceID protocol.DeviceID) []protocol.Connection
 	PacketScheduler() *PacketScheduler
-	DialNow() // Add this method to trigger immediate dialing
+	BandwidthEstimates() map[string]BandwidthEstimate           // by connection ID
+	InfrastructureStatus() map[string]InfrastructureStatusEntry // by server address
+	DialNow()                                                   // Add this method to trigger immediate dialing
+	SetCertificate(cert tls.Certificate) error
+	SetDatabase(kv db.KV)
 }
 
 type ListenerStatusEntry struct {
@@ -155,8 +183,21 @@ type ListenerStatusEntry struct {
 }
 
 type ConnectionStatusEntry struct {
-	When  time.Time `json:"when"`
-	Error *string   `json:"error"`
+	When     time.Time        `json:"when"`
+	Error    *string          `json:"error"`
+	QUICPath *QUICPathStatus  `json:"quicPath,omitempty"`
+	Steps    []ConnectionStep `json:"steps,omitempty"` // of the latest attempt
+}
+
+// QUICPathStatus describes the outcome of path MTU discovery and blackhole
+// detection on QUIC connections to the network of an address.
+type QUICPathStatus struct {
+	MTU           int       `json:"mtu,omitempty"`
+	Blackholes    int       `json:"blackholes"`
+	LastBlackhole time.Time `json:"lastBlackhole,omitempty"`
+	Clamped       bool      `json:"clamped"`
+	Demoted       bool      `json:"demoted"`
+	DemotedUntil  time.Time `json:"demotedUntil,omitempty"`
 }
 
 type connWithHello struct {
@@ -170,12 +211,15 @@ type connWithHello struct {
 type service struct {
 	*suture.Supervisor
 	connectionStatusHandler
+	connectionAttempts
 	deviceConnectionTracker
+	errorBudgets errorBudgets
 
 	cfg                  config.Wrapper
 	myID                 protocol.DeviceID
 	model                Model
-	tlsCfg               *tls.Config
+	tlsCfg               *tls.Config // protected by tlsCfgMut
+	tlsCfgMut            sync.Mutex
 	discoverer           discover.Finder
 	conns                chan internalConn
 	hellos               chan *connWithHello
@@ -193,10 +237,17 @@ type service struct {
 	adaptiveTimeouts     *adaptiveTimeouts
 	healthMonitor        *HealthMonitor
 	protocolMonitor      *protocol.ProtocolHealthMonitor // Add protocol health monitor
+	bandwidth            *bandwidthEstimators
+	infraProber          *infraProber
+	lanHeartbeats        *lanHeartbeats
+	nat64                *nat64Detector
+	dialStats            *dialStats
 
 	dialNow           chan struct{}
 	dialNowDevices    map[protocol.DeviceID]struct{}
 	dialNowDevicesMut sync.Mutex
+	dialNowLost       bool // connections were lost, see connect
+	dialJitter        *svcutil.Jitter
 
 	listenersMut   sync.RWMutex
 	listeners      map[string]genericListener
@@ -229,16 +280,24 @@ func NewService(cfg config.Wrapper, myID protocol.DeviceID, mdl Model, tlsCfg *t
 		adaptiveTimeouts: newAdaptiveTimeouts(),
 		healthMonitor:    NewHealthMonitorWithConfig(cfg, myID.String()),
 		protocolMonitor:  protocol.NewProtocolHealthMonitor(), // Initialize protocol health monitor
+		bandwidth:        newBandwidthEstimators(),
+		infraProber:      newInfraProber(cfg),
+		nat64:            newNAT64Detector(),
+		dialStats:        newDialStats(),
 
 		dialNow:        make(chan struct{}, 1),
 		dialNowDevices: make(map[protocol.DeviceID]struct{}),
+		dialJitter:     svcutil.NewJitter(myID[:]),
 
 		listeners:      make(map[string]genericListener),
 		listenerTokens: make(map[string]suture.ServiceToken),
 	}
 	
+	service.lanHeartbeats = newLANHeartbeats(cfg, myID, service.privateKey, service.lanHeartbeatsLost, service.lanHeartbeatsSeen)
+
 	// Set global reference to service instance
 	globalService = service
+	collector.service.Store(service)
 	
 	cfg.Subscribe(service)
 
@@ -254,9 +313,12 @@ func NewService(cfg config.Wrapper, myID protocol.DeviceID, mdl Model, tlsCfg *t
 	// the common handling regardless of whether the connection was
 	// incoming or outgoing.
 
-	service.Add(svcutil.AsService(service.handleConns, fmt.Sprintf("%s/connect", service)))
+	service.Add(svcutil.AsService(service.connect, fmt.Sprintf("%s/connect", service)))
 	service.Add(svcutil.AsService(service.handleConns, fmt.Sprintf("%s/handleConns", service)))
 	service.Add(svcutil.AsService(service.handleHellos, fmt.Sprintf("%s/handleHellos", service)))
+	service.Add(svcutil.AsService(service.sampleBandwidth, fmt.Sprintf("%s/sampleBandwidth", service)))
+	service.Add(svcutil.AsService(service.infraProber.serve, fmt.Sprintf("%s/infraProber", service)))
+	service.Add(svcutil.AsService(service.lanHeartbeats.serve, fmt.Sprintf("%s/lanHeartbeats", service)))
 	service.Add(service.natService)
 
 	svcutil.OnSupervisorDone(service.Supervisor, func() {
@@ -275,6 +337,9 @@ func (s *service) handleConns(ctx context.Context) error {
 			return ctx.Err()
 		case c = <-s.conns:
 		}
+		if c.trace == nil {
+			c.trace = newConnTrace(c.RemoteAddr().String(), false)
+		}
 
 		cs := c.ConnectionState()
 
@@ -307,17 +372,40 @@ func (s *service) handleConns(ctx context.Context) error {
 			continue
 		}
 
+		if cfg, ok := s.cfg.Device(remoteID); ok {
+			c.priority = transportPriority(s.cfg.Options(), cfg, connTypeTransport(c.connType), c.priority)
+		}
+
 		if err := s.connectionCheckEarly(remoteID, c); err != nil {
 			slog.DebugContext(ctx, "Connection rejected", remoteID.LogAttr(), slogutil.Address(c.RemoteAddr()), slog.String("type", c.Type()), slogutil.Error(err))
+			s.finishAttempt(remoteID, c.trace, err)
 			c.Close()
 			continue
 		}
 
+		if s.strictCertificateExpiry(remoteID) {
+			if err := checkCertificateValidity(remoteCert, time.Now()); err != nil {
+				slog.WarnContext(ctx, "Rejected connection with invalid certificate", remoteID.LogAttr(), slogutil.Address(c.RemoteAddr()), slogutil.Error(err))
+				s.evLogger.Log(events.DeviceCertificateRejected, map[string]string{
+					"device":    remoteID.String(),
+					"address":   c.RemoteAddr().String(),
+					"subject":   remoteCert.Subject.String(),
+					"notBefore": remoteCert.NotBefore.Format(time.RFC3339),
+					"notAfter":  remoteCert.NotAfter.Format(time.RFC3339),
+					"error":     err.Error(),
+				})
+				s.finishAttempt(remoteID, c.trace, err)
+				c.Close()
+				continue
+			}
+		}
+
 		_ = c.SetDeadline(time.Now().Add(20 * time.Second))
 		go func() {
 			// Exchange Hello messages with the peer.
 			outgoing := s.helloForDevice(remoteID)
 			incoming, err := protocol.ExchangeHello(c, outgoing)
+			c.trace.step(stepHello)
 			// The timestamps are used to create the connection ID.
 			c.connectionID = newConnectionID(outgoing.Timestamp, incoming.Timestamp)
 
@@ -329,7 +417,7 @@ func (s *service) handleConns(ctx context.Context) error {
 	}
 }
 
-func (s *service) helloForDevice(_ protocol.DeviceID) protocol.Hello {
+func (s *service) helloForDevice(remoteID protocol.DeviceID) protocol.Hello {
 	hello := protocol.Hello{
 		ClientName:    "syncthing",
 		ClientVersion: build.Version,
@@ -342,9 +430,66 @@ func (s *service) helloForDevice(_ protocol.DeviceID) protocol.Hello {
 		hello.DeviceName = myCfg.Name
 	}
 
+	if s.cfg.Options().ZstdCompression {
+		hello.Compressions = []protocol.CompressionAlgorithm{protocol.CompressionAlgorithmZstd}
+	}
+
+	if remoteCfg, ok := s.cfg.Device(remoteID); ok && remoteCfg.SealRelayedBlocks {
+		hello.BlockSealing = protocol.BlockSealingSupported(s.privateKey())
+	}
+
 	return hello
 }
 
+// blockSealingOption returns the option to seal blocks on the connection,
+// if it's relayed and sealing is configured for the device, or nil if
+// there's nothing to seal. When sealing is configured but the other side
+// doesn't offer it, or no key can be agreed on, the connection must be
+// refused.
+func (s *service) blockSealingOption(c internalConn, remoteID protocol.DeviceID, remoteCert *x509.Certificate, hello protocol.Hello) (protocol.ConnectionOption, error) {
+	if c.connType.Transport() != "relay" {
+		return nil, nil
+	}
+	if devCfg, ok := s.cfg.Device(remoteID); !ok || !devCfg.SealRelayedBlocks {
+		return nil, nil
+	}
+	if !protocol.NegotiateBlockSealing(s.helloForDevice(remoteID), hello) {
+		return nil, errBlockSealingRequired
+	}
+	key, err := protocol.BlockSealingKey(s.privateKey(), s.myID, remoteCert)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errBlockSealingRequired, err)
+	}
+	return protocol.WithBlockSealing(key), nil
+}
+
+func (s *service) privateKey() crypto.PrivateKey {
+	if certs := s.tlsConfig().Certificates; len(certs) > 0 {
+		return certs[0].PrivateKey
+	}
+	return nil
+}
+
+// strictCertificateExpiry returns whether the certificate validity period
+// is enforced for the device, which Syncthing otherwise ignores.
+func (s *service) strictCertificateExpiry(remoteID protocol.DeviceID) bool {
+	if s.cfg.Options().StrictCertificateExpiry {
+		return true
+	}
+	cfg, ok := s.cfg.Device(remoteID)
+	return ok && cfg.StrictCertificateExpiry
+}
+
+func checkCertificateValidity(cert *x509.Certificate, now time.Time) error {
+	switch {
+	case now.Before(cert.NotBefore):
+		return fmt.Errorf("%w (valid from %s)", errCertificateNotYetValid, cert.NotBefore.Format(time.RFC3339))
+	case now.After(cert.NotAfter):
+		return fmt.Errorf("%w (valid until %s)", errCertificateExpired, cert.NotAfter.Format(time.RFC3339))
+	}
+	return nil
+}
+
 func (s *service) connectionCheckEarly(remoteID protocol.DeviceID, c internalConn) error {
 	// Special handling for local device connections
 	if remoteID == s.myID {
@@ -376,6 +521,10 @@ func (s *service) connectionCheckEarly(remoteID protocol.DeviceID, c internalCon
 		return errNetworkNotAllowed
 	}
 
+	if !cfg.TransportAllowed(connTypeTransport(c.connType)) {
+		return errTransportNotAllowed
+	}
+
 	currentConns := s.numConnectionsForDevice(cfg.DeviceID)
 	desiredConns := s.desiredConnectionsToDevice(cfg.DeviceID)
 	worstPrio := s.worstConnectionPriority(remoteID)
@@ -425,6 +574,7 @@ func (s *service) handleHellos(ctx context.Context) error {
 					slogutil.Error(err),
 					"errorType", fmt.Sprintf("%T", err))
 			}
+			s.finishAttempt(remoteID, c.trace, err)
 			c.Close()
 			continue
 		}
@@ -432,13 +582,16 @@ func (s *service) handleHellos(ctx context.Context) error {
 
 		// The Model will return an error for devices that we don't want to
 		// have a connection with for whatever reason, for example unknown devices.
-		if err := s.model.OnHello(remoteID, c.RemoteAddr(), hello); err != nil {
+		err = s.model.OnHello(remoteID, c.RemoteAddr(), hello)
+		c.trace.step(stepAccept)
+		if err != nil {
 			slog.WarnContext(ctx, "Connection rejected", 
 				remoteID.LogAttr(), 
 				slogutil.Address(c.RemoteAddr()), 
 				slog.Any("type", c.Type()), 
 				slogutil.Error(err),
 				"errorType", fmt.Sprintf("%T", err))
+			s.finishAttempt(remoteID, c.trace, err)
 			c.Close()
 			continue
 		}
@@ -449,6 +602,7 @@ func (s *service) handleHellos(ctx context.Context) error {
 				remoteID.LogAttr(), 
 				slogutil.Address(c.RemoteAddr()),
 				"connectionType", c.Type())
+			s.finishAttempt(remoteID, c.trace, errors.New("device removed from config"))
 			c.Close()
 			continue
 		}
@@ -474,6 +628,7 @@ func (s *service) handleHellos(ctx context.Context) error {
 				slogutil.Error(err),
 				"expectedName", certName,
 				"actualName", remoteCert.Subject.CommonName)
+			s.finishAttempt(remoteID, c.trace, err)
 			c.Close()
 			continue
 		}
@@ -507,24 +662,61 @@ func (s *service) handleHellos(ctx context.Context) error {
 			}
 		}
 
+		sealOpt, err := s.blockSealingOption(c, remoteID, remoteCert, hello)
+		if err != nil {
+			slog.WarnContext(ctx, "Refusing relayed connection without block sealing", remoteID.LogAttr(), slogutil.Address(c.RemoteAddr()), slogutil.Error(err))
+			s.finishAttempt(remoteID, c.trace, err)
+			c.Close()
+			continue
+		}
+
+		if c.cpu != nil {
+			c.cpu.setDevice(remoteID.String())
+		}
+
 		// Wrap the connection in rate limiters. The limiter itself will
 		// keep up with config changes to the rate and whether or not LAN
 		// connections are limited.
-		rd, wr := s.limiter.getLimiters(remoteID, c, c.IsLocal())
+		bw := s.bandwidth.add(remoteID, c.ConnectionID())
+		rd, wr := bw.wrapLimited(s.limiter.getLimiters(remoteID, bw.wrapConn(c), c.IsLocal(), s.limiter.bandwidthClass(c)))
+		rd = faultinject.Reader(faultinject.Read, remoteID.String(), rd)
+
+		// Compress with zstd if both sides offer it, LZ4 otherwise.
+		compressionAlgo := protocol.NegotiateCompression(s.helloForDevice(remoteID), hello)
+		l.Debugf("Compressing messages to %s with %v", remoteID, compressionAlgo)
+
+		connOpts := []protocol.ConnectionOption{protocol.WithCompressionAlgorithm(compressionAlgo, s.cfg.Options().ZstdCompressionLevel)}
+		if sealOpt != nil {
+			l.Debugf("Sealing blocks on relayed connection to %s", remoteID)
+			connOpts = append(connOpts, sealOpt)
+		}
 
-		protoConn := protocol.NewConnection(remoteID, rd, wr, c, s.model, c, deviceCfg.Compression.ToProtocol(), s.keyGen)
+		protoConn := protocol.NewConnection(remoteID, rd, wr, c, s.model, c, deviceCfg.Compression.ToProtocol(), s.keyGen, connOpts...)
 		s.accountAddedConnection(protoConn, hello, s.cfg.Options().ConnectionPriorityUpgradeThreshold, s.cfg)
 		go func() {
 			<-protoConn.Closed()
 			s.accountRemovedConnection(protoConn, s.cfg)
+			s.packetScheduler.RemoveConnection(remoteID, protoConn.ConnectionID())
+			if s.numConnectionsForDevice(remoteID) == 0 {
+				s.recordDisconnect(remoteID)
+			}
+			s.bandwidth.remove(protoConn.ConnectionID())
+			s.lanHeartbeats.remove(remoteID, protoConn.ConnectionID())
 			s.dialNowDevicesMut.Lock()
 			s.dialNowDevices[remoteID] = struct{}{}
+			s.dialNowLost = true
 			s.scheduleDialNow()
 			s.dialNowDevicesMut.Unlock()
 		}()
 
 		slog.InfoContext(ctx, "Established secure connection", remoteID.LogAttr(), slog.Any("connection", c))
+		s.finishAttempt(remoteID, c.trace, nil)
+		s.errorBudgets.recordConnected(remoteID, time.Now())
 
+		s.packetScheduler.AddConnection(remoteID, protoConn)
+		if c.IsLocal() {
+			s.lanHeartbeats.add(remoteID, remoteCert, protoConn)
+		}
 		s.model.AddConnection(protoConn, hello)
 		continue
 	}
@@ -535,13 +727,119 @@ func (s *service) isV2Compatible(version string) bool {
 	return protocol.IsV2Client(version)
 }
 
+func (s *service) connect(ctx context.Context) error {
+	// Map of when to earliest dial each given device + address again
+	nextDialAt := make(nextDialRegistry)
+
+	// Used as delay for the first few connection attempts (adjusted up to
+	// minConnectionLoopSleep), increased exponentially until it reaches
+	// stdConnectionLoopSleep, at which time the normal sleep mechanism
+	// kicks in.
+	initialRampup := time.Second
+
+	// A whole cluster starting at once, e.g. when power comes back, would
+	// otherwise dial all at the same time.
+	if !s.sleepJitter(ctx, time.Duration(s.cfg.Options().DialJitterS)*time.Second) {
+		return ctx.Err()
+	}
+
+	for {
+		cfg := s.cfg.RawCopy()
+		bestDialerPriority := s.bestDialerPriority(cfg)
+		isInitialRampup := initialRampup < stdConnectionLoopSleep
+
+		slog.DebugContext(ctx, "Connection loop",
+			"devicesConfigured", len(cfg.Devices),
+			"connectionLimitMax", cfg.Options.ConnectionLimitMax,
+			"connectionLimitEnough", cfg.Options.ConnectionLimitEnough)
+		if isInitialRampup {
+			slog.DebugContext(ctx, "Connection loop in initial rampup",
+				"rampupDuration", initialRampup)
+		}
+
+		// Used for consistency throughout this loop run, as time passes
+		// while we try connections etc.
+		now := time.Now()
+
+		// Attempt to dial all devices that are unconnected or can be connection-upgraded
+		s.dialDevices(ctx, now, cfg, bestDialerPriority, nextDialAt, isInitialRampup)
+
+		var sleep time.Duration
+		if isInitialRampup {
+			// We are in the initial rampup time, so we slowly increase the
+			// sleep time, varying it to not stay in step with others.
+			sleep = s.dialJitter.Spread(initialRampup, 0.25)
+			initialRampup *= 2
+		} else {
+			// The sleep time is until the next dial scheduled in nextDialAt,
+			// clamped by stdConnectionLoopSleep as we don't want to sleep too
+			// long (config changes might happen).
+			sleep = nextDialAt.sleepDurationAndCleanup(now)
+		}
+
+		// Use adaptive sleep time based on connection success rates
+		if adaptiveSleep := s.adaptiveTimeouts.calculateAdaptiveConnectionLoopSleep(); sleep < adaptiveSleep {
+			sleep = adaptiveSleep
+		}
+
+		// ... while making sure not to loop too quickly either.
+		if sleep < minConnectionLoopSleep {
+			sleep = minConnectionLoopSleep
+		}
+
+		l.Debugln("Next connection loop in", sleep)
+
+		timeout := time.NewTimer(sleep)
+		select {
+		case <-s.dialNow:
+			// Remove affected devices from nextDialAt to dial immediately,
+			// regardless of when we last dialed it (there's cool down in the
+			// registry for too many repeat dials).
+			s.dialNowDevicesMut.Lock()
+			for device := range s.dialNowDevices {
+				nextDialAt.redialDevice(device, now)
+			}
+			s.dialNowDevices = make(map[protocol.DeviceID]struct{})
+			lost := s.dialNowLost
+			s.dialNowLost = false
+			s.dialNowDevicesMut.Unlock()
+			timeout.Stop()
+
+			// Connections lost in an outage are lost all over the cluster
+			// at once; don't have everyone redial at once as well.
+			if lost && !s.sleepJitter(ctx, time.Duration(cfg.Options.DialJitterS)*time.Second) {
+				return ctx.Err()
+			}
+		case <-timeout.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// sleepJitter sleeps for a random part of max, returning false if the
+// context was cancelled meanwhile.
+func (s *service) sleepJitter(ctx context.Context, max time.Duration) bool {
+	delay := s.dialJitter.Delay(max)
+	if delay <= 0 {
+		return true
+	}
+	l.Debugln("Delaying dials by", delay)
+	select {
+	case <-time.After(delay):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
 func (s *service) bestDialerPriority(cfg config.Configuration) int {
 	bestDialerPriority := worstDialerPriority
 	for _, df := range dialers {
 		if df.Valid(cfg) != nil {
 			continue
 		}
-		prio := df.New(cfg.Options, s.tlsCfg, s.registry, s.lanChecker).Priority("127.0.0.1")
+		prio := df.New(cfg.Options, s.tlsConfig(), s.registry, s.lanChecker).Priority("127.0.0.1")
 		if prio < bestDialerPriority {
 			bestDialerPriority = prio
 		}
@@ -709,6 +1007,7 @@ func (s *service) resolveDialTargets(ctx context.Context, now time.Time, cfg con
 		"addresses", addrs,
 		"numAddresses", len(addrs))
 
+	agedOut := s.dialStats.skip(deviceID, addrs, now)
 	dialTargets := make([]dialTarget, 0, len(addrs))
 	for _, addr := range addrs {
 		// Use both device and address, as you might have two devices connected
@@ -717,6 +1016,10 @@ func (s *service) resolveDialTargets(ctx context.Context, now time.Time, cfg con
 			l.Debugf("Not dialing %s via %v as it's not time yet", deviceID.Short(), addr)
 			continue
 		}
+		if agedOut[addr] {
+			l.Debugf("Not dialing %s via %v as it hasn't worked in a long while", deviceID.Short(), addr)
+			continue
+		}
 
 		// If we fail at any step before actually getting the dialer
 		// retry in a minute
@@ -752,6 +1055,21 @@ func (s *service) resolveDialTargets(ctx context.Context, now time.Time, cfg con
 			}
 		}
 
+		if cfg.Options.IPv6Only {
+			if uri, err = ipv6OnlyDialTarget(ctx, uri, s.nat64); err != nil {
+				s.setConnectionStatus(addr, err)
+				l.Debugf("Not dialing %s at %s: %v", deviceID.Short(), addr, err)
+				continue
+			}
+		}
+
+		transport := schemeTransport(uri.Scheme)
+		if !deviceCfg.TransportAllowed(transport) {
+			s.setConnectionStatus(addr, errTransportNotAllowed)
+			l.Debugf("Not dialing %s at %s as %s is not among the preferred transports", deviceID.Short(), addr, transport)
+			continue
+		}
+
 		dialerFactory, err := getDialerFactory(cfg, uri)
 		if errors.Is(err, errUnsupported) {
 			l.Debugf("Dialer for %v: %v", uri, err)
@@ -761,8 +1079,8 @@ func (s *service) resolveDialTargets(ctx context.Context, now time.Time, cfg con
 			continue
 		}
 
-		dialer := dialerFactory.New(s.cfg.Options(), s.tlsCfg, s.registry, s.lanChecker)
-		priority := dialer.Priority(uri.Host)
+		dialer := dialerFactory.New(s.cfg.Options(), s.tlsConfig(), s.registry, s.lanChecker)
+		priority := transportPriority(cfg.Options, deviceCfg, transport, dialer.Priority(uri.Host))
 		currentConns := s.numConnectionsForDevice(deviceCfg.DeviceID)
 		if priority > priorityCutoff {
 			l.Debugf("Not dialing %s at %s using %s as priority is worse than current connection (%d > %d)", deviceID.Short(), addr, dialerFactory, priority, priorityCutoff)
@@ -777,7 +1095,11 @@ func (s *service) resolveDialTargets(ctx context.Context, now time.Time, cfg con
 			continue
 		}
 
-		nextDialAt.set(deviceID, addr, now.Add(dialer.RedialFrequency()))
+		redial := dialer.RedialFrequency()
+		if failures := s.dialStats.failures(deviceID, addr); failures > 0 {
+			redial = max(redial, retry.After(retry.Dialing, dialRetryPolicy, failures))
+		}
+		nextDialAt.set(deviceID, addr, now.Add(redial))
 
 		slog.DebugContext(ctx, "Adding dial target", 
 			"device", deviceID,
@@ -796,6 +1118,8 @@ func (s *service) resolveDialTargets(ctx context.Context, now time.Time, cfg con
 		})
 	}
 
+	// Within a priority, dial the addresses that worked best first.
+	s.dialStats.sort(deviceID, dialTargets)
 	return dialTargets
 }
 
@@ -869,8 +1193,11 @@ func (s *lanChecker) isLAN(addr net.Addr) bool {
 		ip = addr.IP
 	case *net.UDPAddr:
 		ip = addr.IP
+	case *net.UnixAddr:
+		// Same host, or forwarded from somewhere that was trusted with
+		// access to the socket.
+		return true
 	default:
-		// From the standard library, just Unix sockets.
 		// If you invent your own, handle it.
 		return false
 	}
@@ -916,7 +1243,7 @@ func (s *service) createListener(factory listenerFactory, uri *url.URL) bool {
 
 	slog.Debug("Starting listener", "uri", uri)
 
-	listener := factory.New(uri, s.cfg, s.tlsCfg, s.conns, s.natService, s.registry, s.lanChecker)
+	listener := factory.New(uri, s.cfg, s.tlsConfig(), s.conns, s.natService, s.registry, s.lanChecker)
 	listener.OnAddressesChanged(s.logListenAddressesChangedEvent)
 
 	// Retrying a listener many times in rapid succession is unlikely to help,
@@ -933,6 +1260,63 @@ func (s *service) createListener(factory listenerFactory, uri *url.URL) bool {
 	return true
 }
 
+func (s *service) tlsConfig() *tls.Config {
+	s.tlsCfgMut.Lock()
+	defer s.tlsCfgMut.Unlock()
+	return s.tlsCfg
+}
+
+// SetDatabase keeps the connection stability and dial statistics of
+// devices in the database, so that what was learned about them survives
+// restarts. A device picks up its stability history on its next connection.
+func (s *service) SetDatabase(kv db.KV) {
+	s.connectionsMut.Lock()
+	s.stabilityStore = &stabilityStore{kv: kv}
+	s.connectionsMut.Unlock()
+	s.dialStats.setKV(kv)
+}
+
+// SetCertificate makes the certificate the one presented on connections,
+// without a restart. As the device ID follows from the certificate, that
+// only works while it stays the same, e.g. for a renewed chain; other
+// certificates return ErrCertificateIdentity. Listeners are restarted with
+// the new TLS configuration and current connections closed, to be
+// re-established with it.
+func (s *service) SetCertificate(cert tls.Certificate) error {
+	if len(cert.Certificate) == 0 {
+		return errors.New("empty certificate")
+	}
+	if id := protocol.NewDeviceID(cert.Certificate[0]); id != s.myID {
+		return fmt.Errorf("%w: %s", ErrCertificateIdentity, id)
+	}
+
+	s.tlsCfgMut.Lock()
+	tlsCfg := s.tlsCfg.Clone()
+	tlsCfg.Certificates = []tls.Certificate{cert}
+	s.tlsCfg = tlsCfg
+	s.tlsCfgMut.Unlock()
+
+	s.listenersMut.Lock()
+	for addr, listener := range s.listeners {
+		uri, err := url.Parse(addr)
+		if err != nil {
+			continue
+		}
+		_ = s.RemoveAndWait(s.listenerTokens[addr], listenerRestartTimeout)
+		delete(s.listenerTokens, addr)
+		delete(s.listeners, addr)
+		s.createListener(listener.Factory(), uri)
+	}
+	s.listenersMut.Unlock()
+
+	for _, device := range s.GetConnectedDevices() {
+		s.ReconnectDevice(device, errCertificateReloaded)
+	}
+
+	slog.Info("Reloaded device certificate", slog.Int("chainLength", len(cert.Certificate)))
+	return nil
+}
+
 func (s *service) logListenAddressesChangedEvent(l ListenerAddresses) {
 	s.evLogger.Log(events.ListenAddressesChanged, map[string]interface{}{
 		"address": l.URI,
@@ -948,13 +1332,25 @@ func (s *service) CommitConfiguration(from, to config.Configuration) bool {
 		registerDeviceMetrics(dev.DeviceID.String())
 	}
 
+	s.connectionsMut.Lock()
+	store := s.stabilityStore
+	s.connectionsMut.Unlock()
 	for _, dev := range from.Devices {
 		if !newDevices[dev.DeviceID] {
 			metricDeviceActiveConnections.DeleteLabelValues(dev.DeviceID.String())
+			if store != nil {
+				store.forget(dev.DeviceID)
+			}
+			s.dialStats.forget(dev.DeviceID)
+			s.connectionAttempts.forget(dev.DeviceID)
 		}
 	}
 
 	s.checkAndSignalConnectLoopOnUpdatedDevices(from, to)
+	s.lanHeartbeats.configChanged()
+
+	s.packetScheduler.SetTrafficAffinity(to.Options.MultipathEnabled && to.Options.MultipathTrafficAffinity)
+	s.packetScheduler.SetStriping(to.Options.MultipathEnabled)
 
 	s.listenersMut.Lock()
 	seen := make(map[string]struct{})
@@ -983,6 +1379,11 @@ func (s *service) CommitConfiguration(from, to config.Configuration) bool {
 			continue
 		}
 
+		if to.Options.IPv6Only && isIPv4Address(uri) {
+			l.Debugf("Skipping IPv4 listener %v on IPv6 only network", uri)
+			continue
+		}
+
 		if _, ok := s.listeners[addr]; ok {
 			seen[addr] = struct{}{}
 			continue
@@ -1035,6 +1436,29 @@ func (s *service) checkAndSignalConnectLoopOnUpdatedDevices(from, to config.Conf
 	s.dialNowDevicesMut.Unlock()
 }
 
+// lanHeartbeatsLost closes the LAN connections of a device that stopped
+// sending heartbeats, so that we fail over to any others and redial.
+func (s *service) lanHeartbeatsLost(device protocol.DeviceID, conns []protocol.Connection) {
+	slog.Info("Lost LAN heartbeats from device, closing its LAN connections", device.LogAttr())
+	for _, c := range conns {
+		s.healthMonitor.RecordConnectionError(device, c.RemoteAddr().String(), errLANHeartbeatLost)
+		c.Close(errLANHeartbeatLost)
+	}
+}
+
+// lanHeartbeatsSeen dials a device right away when its heartbeats are back
+// while it's not connected.
+func (s *service) lanHeartbeatsSeen(device protocol.DeviceID) {
+	if s.numConnectionsForDevice(device) > 0 {
+		return
+	}
+	l.Debugf("LAN heartbeats from %s are back, dialing", device)
+	s.dialNowDevicesMut.Lock()
+	s.dialNowDevices[device] = struct{}{}
+	s.scheduleDialNow()
+	s.dialNowDevicesMut.Unlock()
+}
+
 func (s *service) scheduleDialNow() {
 	select {
 	case s.dialNow <- struct{}{}:
@@ -1043,10 +1467,15 @@ func (s *service) scheduleDialNow() {
 	}
 }
 
+// AllAddresses returns the LAN and WAN addresses of the listeners, leaving
+// out those only meaningful to this host.
 func (s *service) AllAddresses() []string {
 	s.listenersMut.RLock()
 	var addrs []string
 	for _, listener := range s.listeners {
+		if !isAnnounceable(listener.URI()) {
+			continue
+		}
 		for _, lanAddr := range listener.LANAddresses() {
 			addrs = append(addrs, lanAddr.String())
 		}
@@ -1136,6 +1565,7 @@ func (s *connectionStatusHandler) ConnectionStatus() map[string]ConnectionStatus
 	result := make(map[string]ConnectionStatusEntry)
 	s.connectionStatusMut.RLock()
 	for k, v := range s.connectionStatus {
+		v.QUICPath = quicPathStatus(k)
 		result[k] = v
 	}
 	s.connectionStatusMut.RUnlock()
@@ -1295,6 +1725,9 @@ func tlsTimedHandshake(tc *tls.Conn, adaptiveTimeouts ...*adaptiveTimeouts) erro
 	timeout := timeoutTracker.calculateAdaptiveTLSHandshakeTimeout()
 	tc.SetDeadline(time.Now().Add(timeout))
 	defer tc.SetDeadline(time.Time{})
+	if err := faultinject.Check(faultinject.Handshake, tc.RemoteAddr().String()); err != nil {
+		return err
+	}
 	return tc.Handshake()
 }
 
@@ -1507,8 +1940,11 @@ func (s *service) dialParallel(ctx context.Context, deviceID protocol.DeviceID,
 					wg.Done()
 					sema.Give(1)
 				}()
-				conn, err := tgt.Dial(ctx)
+				trace := newConnTrace(tgt.addr, true)
+				start := time.Now()
+				conn, err := tgt.Dial(withConnTrace(ctx, trace))
 				if err == nil {
+					conn.trace = trace
 					// Add to tracking list before validation
 					allConnsMut.Lock()
 					allConns = append(allConns, conn)
@@ -1530,6 +1966,18 @@ func (s *service) dialParallel(ctx context.Context, deviceID protocol.DeviceID,
 					}
 				}
 				s.setConnectionStatus(tgt.addr, err)
+				if err != nil && !errors.Is(err, context.Canceled) {
+					s.finishAttempt(deviceID, trace, err)
+					s.recordFailedDial(deviceID)
+					s.metricsTracker.RecordConnectionAttempt("failure")
+					s.metricsTracker.RecordConnectionError("dial", categorizeError(err).String())
+				} else if err == nil {
+					s.metricsTracker.RecordConnectionAttempt("success")
+				}
+				if !errors.Is(err, context.Canceled) {
+					now := time.Now()
+					s.dialStats.record(deviceID, tgt.addr, now, now.Sub(start), err)
+				}
 				// Track connection success/failure for adaptive timeouts
 				// Check if this is a version compatibility issue (EOF during TLS handshake often indicates version mismatch)
 				isVersionIssue := err != nil && (errors.Is(err, io.EOF) || strings.Contains(err.Error(), "EOF") || 
@@ -1610,8 +2058,13 @@ func (s *service) validateIdentity(c internalConn, expectedID protocol.DeviceID)
 		return errors.New("connected to self")
 	}
 
-	// We should see the expected device ID
+	// We should see the expected device ID. If not, the device at this
+	// address may have replaced its certificate; let the model surface
+	// that instead of it being just another failed dial.
 	if !remoteID.Equals(expectedID) {
+		if handler, ok := s.model.(identityMismatchHandler); ok {
+			handler.OnIdentityMismatch(expectedID, remoteCert, c.RemoteAddr())
+		}
 		c.Close()
 		return fmt.Errorf("unexpected device id, expected %s got %s", expectedID, remoteID)
 	}
@@ -1750,6 +2203,8 @@ type deviceConnectionTracker struct {
 	hysteresisCtrls   map[protocol.DeviceID]*HysteresisController // hysteresis controllers
 	convergenceMgrs   map[protocol.DeviceID]*ConvergenceManager   // convergence managers
 	connectionPrioritizer *ConnectionPrioritizer                // connection prioritizer
+	rolling           map[protocol.DeviceID]chan struct{}         // devices whose connections are being replaced, see ReconnectDevice
+	stabilityStore    *stabilityStore                             // keeps stability across restarts, nil if not, see SetDatabase
 }
 
 func (c *deviceConnectionTracker) accountAddedConnection(conn protocol.Connection, h protocol.Hello, upgradeThreshold int, cfg config.Wrapper) {
@@ -1770,10 +2225,16 @@ func (c *deviceConnectionTracker) accountAddedConnection(conn protocol.Connectio
 	c.connections[d] = append(c.connections[d], conn)
 	c.wantConnections[d] = int(h.NumConnections)
 	l.Debugf("Added connection for %s (now %d), they want %d connections", d.Short(), len(c.connections[d]), h.NumConnections)
+	c.connectionAddedLocked(d)
 
 	// Initialize stability manager if needed
 	if c.stabilityMgrs[d] == nil {
 		c.stabilityMgrs[d] = NewConnectionStabilityManager(cfg, d)
+		if c.stabilityStore != nil {
+			if rec, ok := c.stabilityStore.load(d); ok {
+				c.stabilityMgrs[d].restore(rec)
+			}
+		}
 	}
 	c.stabilityMgrs[d].RecordConnectionEstablished(conn)
 
@@ -1809,14 +2270,19 @@ func (c *deviceConnectionTracker) accountRemovedConnection(conn protocol.Connect
 			break
 		}
 	}
-	// Clean up if required
+	if mgr, exists := c.stabilityMgrs[d]; exists {
+		// Record connection closure
+		mgr.RecordConnectionClosed(conn, "connection closed")
+		if c.stabilityStore != nil {
+			c.stabilityStore.save(d, mgr.record())
+		}
+	}
+	// Clean up if required; the stability manager of the next connection
+	// picks up from the store.
 	if len(c.connections[d]) == 0 {
 		delete(c.connections, d)
 		delete(c.wantConnections, d)
 		delete(c.stabilityMgrs, d)
-	} else if mgr, exists := c.stabilityMgrs[d]; exists {
-		// Record connection closure
-		mgr.RecordConnectionClosed(conn, "connection closed")
 	}
 
 	// Update active connections metric
@@ -1990,6 +2456,24 @@ func (s *service) PacketScheduler() *PacketScheduler {
 	return s.packetScheduler
 }
 
+func (s *service) sampleBandwidth(ctx context.Context) error {
+	return s.bandwidth.serve(ctx, s.evLogger)
+}
+
+func (s *service) BandwidthEstimates() map[string]BandwidthEstimate {
+	return s.bandwidth.estimates()
+}
+
+func (s *service) InfrastructureStatus() map[string]InfrastructureStatusEntry {
+	statuses := s.infraProber.statuses()
+	if s.cfg.Options().IPv6Only {
+		if status, ok := s.nat64.Status(); ok {
+			statuses[nat64DiscoveryName] = status
+		}
+	}
+	return statuses
+}
+
 // DialNow triggers immediate dialing of all configured devices
 func (s *service) DialNow() {
 	// Add all configured devices to dialNowDevices
@@ -0,0 +1,140 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// maxStabilityHistory is how many connections the stability history of a
+// device keeps, the ones closed longest ago going first.
+const maxStabilityHistory = 32
+
+// stabilityRecord is what's kept of the connection stability of a device
+// across restarts, so that flapping detection and the adaptive reconnect
+// interval don't start cold.
+type stabilityRecord struct {
+	StabilityScore   float64             `json:"stabilityScore"`
+	ShortLivedCount  int                 `json:"shortLivedCount"`
+	ChurnCount       int                 `json:"churnCount"`
+	LastConnectionAt time.Time           `json:"lastConnectionAt"`
+	LastCloseReason  string              `json:"lastCloseReason"`
+	LastClosedAt     time.Time           `json:"lastClosedAt"`
+	History          []ConnectionHistory `json:"history"` // closed connections only
+}
+
+// stabilityStore keeps the stability records of devices in the database.
+type stabilityStore struct {
+	kv db.KV
+}
+
+func stabilityKey(device protocol.DeviceID) string {
+	return "connstability/" + device.String()
+}
+
+func (s *stabilityStore) load(device protocol.DeviceID) (stabilityRecord, bool) {
+	bs, err := s.kv.GetKV(stabilityKey(device))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			l.Debugln("Getting connection stability of", device.Short(), err)
+		}
+		return stabilityRecord{}, false
+	}
+	var rec stabilityRecord
+	if err := json.Unmarshal(bs, &rec); err != nil {
+		l.Debugln("Unmarshalling connection stability of", device.Short(), err)
+		return stabilityRecord{}, false
+	}
+	return rec, true
+}
+
+func (s *stabilityStore) save(device protocol.DeviceID, rec stabilityRecord) {
+	bs, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	if err := s.kv.PutKV(stabilityKey(device), bs); err != nil {
+		l.Debugln("Saving connection stability of", device.Short(), err)
+	}
+}
+
+func (s *stabilityStore) forget(device protocol.DeviceID) {
+	_ = s.kv.DeleteKV(stabilityKey(device))
+}
+
+// record returns what's kept of the manager across restarts.
+func (csm *ConnectionStabilityManager) record() stabilityRecord {
+	csm.mut.RLock()
+	defer csm.mut.RUnlock()
+	rec := stabilityRecord{
+		StabilityScore:   csm.stabilityScore,
+		ShortLivedCount:  csm.shortLivedCount,
+		ChurnCount:       csm.churnCount,
+		LastConnectionAt: csm.lastConnectionAt,
+		LastCloseReason:  csm.lastCloseReason,
+		LastClosedAt:     csm.lastClosedAt,
+	}
+	for _, history := range csm.connectionHistory {
+		if !history.ClosedAt.IsZero() {
+			rec.History = append(rec.History, *history)
+		}
+	}
+	slices.SortFunc(rec.History, func(a, b ConnectionHistory) int {
+		return a.ClosedAt.Compare(b.ClosedAt)
+	})
+	return rec
+}
+
+// restore picks up where a manager with the record left off. Connections
+// established since are kept.
+func (csm *ConnectionStabilityManager) restore(rec stabilityRecord) {
+	csm.mut.Lock()
+	defer csm.mut.Unlock()
+	for _, history := range rec.History {
+		if _, ok := csm.connectionHistory[history.ConnectionID]; !ok {
+			csm.connectionHistory[history.ConnectionID] = &history
+		}
+	}
+	csm.stabilityScore = rec.StabilityScore
+	csm.shortLivedCount += rec.ShortLivedCount
+	csm.churnCount += rec.ChurnCount
+	if rec.LastConnectionAt.After(csm.lastConnectionAt) {
+		csm.lastConnectionAt = rec.LastConnectionAt
+	}
+	csm.lastCloseReason = rec.LastCloseReason
+	csm.lastClosedAt = rec.LastClosedAt
+	csm.pruneHistoryLocked()
+	csm.adjustReconnectInterval()
+}
+
+// pruneHistoryLocked drops the connections closed longest ago beyond
+// maxStabilityHistory.
+func (csm *ConnectionStabilityManager) pruneHistoryLocked() {
+	excess := len(csm.connectionHistory) - maxStabilityHistory
+	if excess <= 0 {
+		return
+	}
+	closed := make([]*ConnectionHistory, 0, len(csm.connectionHistory))
+	for _, history := range csm.connectionHistory {
+		if !history.ClosedAt.IsZero() {
+			closed = append(closed, history)
+		}
+	}
+	slices.SortFunc(closed, func(a, b *ConnectionHistory) int {
+		return a.ClosedAt.Compare(b.ClosedAt)
+	})
+	for _, history := range closed[:min(excess, len(closed))] {
+		delete(csm.connectionHistory, history.ConnectionID)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/syncthing/syncthing/internal/db/sqlite"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+	protocolmocks "github.com/syncthing/syncthing/lib/protocol/mocks"
+)
+
+func TestStabilityStoreAcrossRestarts(t *testing.T) {
+	sdb, err := sqlite.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sdb.Close() })
+
+	device := protocol.DeviceID{1, 2, 3}
+	cfg := config.Wrap("", config.New(protocol.LocalDeviceID), protocol.LocalDeviceID, events.NoopLogger)
+	conn := func(id string) protocol.Connection {
+		c := &protocolmocks.Connection{}
+		c.DeviceIDReturns(device)
+		c.ConnectionIDReturns(id)
+		return c
+	}
+
+	// A daemon that sees a few short lived connections, the last one
+	// closing when it shuts down.
+	before := &deviceConnectionTracker{stabilityStore: &stabilityStore{kv: sdb}}
+	for i := range 3 {
+		c := conn(fmt.Sprint("before", i))
+		before.accountAddedConnection(c, protocol.Hello{}, 0, cfg)
+		before.accountRemovedConnection(c, cfg)
+	}
+
+	// The next one picks up where it left off on the first connection.
+	after := &deviceConnectionTracker{stabilityStore: &stabilityStore{kv: sdb}}
+	after.accountAddedConnection(conn("after"), protocol.Hello{}, 0, cfg)
+	mgr := after.stabilityMgrs[device]
+	if mgr.churnCount != 3 || mgr.shortLivedCount != 3 || mgr.lastCloseReason != "connection closed" {
+		t.Errorf("expected the history to be restored, got churn %d, short lived %d, reason %q", mgr.churnCount, mgr.shortLivedCount, mgr.lastCloseReason)
+	}
+	if len(mgr.connectionHistory) != 4 {
+		t.Errorf("expected three closed and one open connection, got %d", len(mgr.connectionHistory))
+	}
+	if metrics := mgr.GetStabilityMetrics(); metrics.ShortLivedConnectionRate == 0 {
+		t.Error("expected the restored short lived connections to count")
+	}
+
+	// Removing the device forgets it.
+	before.stabilityStore.forget(device)
+	if _, ok := before.stabilityStore.load(device); ok {
+		t.Error("expected no stability record after forgetting the device")
+	}
+}
+
+func TestStabilityHistoryBounded(t *testing.T) {
+	cfg := config.Wrap("", config.New(protocol.LocalDeviceID), protocol.LocalDeviceID, events.NoopLogger)
+	mgr := NewConnectionStabilityManager(cfg, protocol.LocalDeviceID)
+	for i := range 2 * maxStabilityHistory {
+		c := &protocolmocks.Connection{}
+		c.ConnectionIDReturns(fmt.Sprint(i))
+		mgr.RecordConnectionEstablished(c)
+		mgr.RecordConnectionClosed(c, "test")
+	}
+	if len(mgr.connectionHistory) != maxStabilityHistory {
+		t.Errorf("expected %d connections in the history, got %d", maxStabilityHistory, len(mgr.connectionHistory))
+	}
+	if _, ok := mgr.connectionHistory[fmt.Sprint(2*maxStabilityHistory-1)]; !ok {
+		t.Error("expected the latest connection to be kept")
+	}
+}
@@ -0,0 +1,220 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Bounds of the congestion window of a path, the bytes of block requests
+// that may be outstanding on it.
+const (
+	initialStripeWindow = 1 << 20
+	minStripeWindow     = 256 << 10
+	maxStripeWindow     = 64 << 20
+)
+
+// The delivery rate of a path is sampled over at least this long, while it
+// has requests outstanding.
+const stripeRateSample = 250 * time.Millisecond
+
+// pathState is what is known about one path, i.e. connection, to a device
+// from the outcome of the block requests striped over it. The window grows
+// with each answered request, like TCP's, and is halved when a request
+// fails, so that a congested or failing path gets fewer requests.
+type pathState struct {
+	outstanding int64 // bytes requested and not yet answered
+	window      int64
+	ssthresh    int64         // past this the window grows linearly, zero before the first failure
+	rate        float64       // smoothed delivery rate, bytes/s, zero while unknown
+	srtt        time.Duration // smoothed response time
+
+	delivered   int64 // bytes answered since sampleStart
+	sampleStart time.Time
+}
+
+// SetStriping enables or disables striping block requests over all
+// connections to a device, see StripeRequest.
+func (ps *PacketScheduler) SetStriping(enabled bool) {
+	ps.mut.Lock()
+	ps.striping = enabled
+	ps.mut.Unlock()
+}
+
+// Striping returns whether striping is enabled.
+func (ps *PacketScheduler) Striping() bool {
+	ps.mut.RLock()
+	defer ps.mut.RUnlock()
+	return ps.striping
+}
+
+// StripeRequest selects the connection for a block request of the given
+// size, so that a device with several paths, such as a LAN and a WAN one,
+// gets the bandwidth of all of them rather than only that of the best.
+// Each request goes to the path where it's expected to be answered first,
+// given the bytes already outstanding on the path and its delivery rate,
+// preferring paths that have room in their congestion window. With traffic
+// affinity the control connection is left out, as long as there is another
+// one. The returned function must be called with the outcome of the
+// request. It returns nil if striping is disabled or there is no
+// connection.
+func (ps *PacketScheduler) StripeRequest(deviceID protocol.DeviceID, size int, controlConnID string) (protocol.Connection, func(error)) {
+	start := time.Now()
+	conn := ps.stripeRequest(deviceID, int64(size), controlConnID, start)
+	if conn == nil {
+		return nil, nil
+	}
+	connID := conn.ConnectionID()
+	return conn, func(err error) {
+		ps.stripeDone(connID, int64(size), start, time.Now(), err)
+	}
+}
+
+func (ps *PacketScheduler) stripeRequest(deviceID protocol.DeviceID, size int64, controlConnID string, now time.Time) protocol.Connection {
+	ps.mut.Lock()
+	defer ps.mut.Unlock()
+
+	conns := ps.connections[deviceID]
+	if !ps.striping || len(conns) == 0 {
+		return nil
+	}
+	if ps.trafficAffinity && len(conns) > 1 {
+		data := make([]protocol.Connection, 0, len(conns))
+		for _, conn := range conns {
+			if conn.ConnectionID() != controlConnID {
+				data = append(data, conn)
+			}
+		}
+		if len(data) > 0 {
+			conns = data
+		}
+	}
+
+	var best protocol.Connection
+	var bestPath *pathState
+	var bestRoom bool
+	var bestETA time.Duration
+	for _, conn := range conns {
+		p := ps.pathLocked(conn.ConnectionID())
+		room := p.outstanding == 0 || p.outstanding+size <= p.window
+		eta := p.eta(size)
+		if best == nil || (room && !bestRoom) || (room == bestRoom && eta < bestETA) {
+			best, bestPath, bestRoom, bestETA = conn, p, room, eta
+		}
+	}
+
+	switch {
+	case len(conns) == 1:
+		metricPathSelections.WithLabelValues(deviceID.String(), pathSelectionOnly).Inc()
+	case bestRoom:
+		metricPathSelections.WithLabelValues(deviceID.String(), pathSelectionWindow).Inc()
+	default:
+		metricPathSelections.WithLabelValues(deviceID.String(), pathSelectionCongested).Inc()
+	}
+
+	if bestPath.outstanding == 0 {
+		// The path was idle; the delivery rate is sampled from now on.
+		bestPath.delivered = 0
+		bestPath.sampleStart = now
+	}
+	bestPath.outstanding += size
+	return best
+}
+
+func (ps *PacketScheduler) pathLocked(connID string) *pathState {
+	p, ok := ps.paths[connID]
+	if !ok {
+		p = &pathState{window: initialStripeWindow}
+		ps.paths[connID] = p
+	}
+	return p
+}
+
+// eta is the expected time until a request of the given size, sent now,
+// is answered. Paths whose rate is still unknown are expected to answer
+// right away, so that they're tried.
+func (p *pathState) eta(size int64) time.Duration {
+	if p.rate <= 0 {
+		return p.srtt
+	}
+	return p.srtt + time.Duration(float64(p.outstanding+size)/p.rate*float64(time.Second))
+}
+
+func (ps *PacketScheduler) stripeDone(connID string, size int64, start, now time.Time, err error) {
+	ps.mut.Lock()
+	defer ps.mut.Unlock()
+
+	p, ok := ps.paths[connID]
+	if !ok {
+		// The connection is gone.
+		return
+	}
+	p.outstanding = max(p.outstanding-size, 0)
+
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, protocol.ErrGeneric), errors.Is(err, protocol.ErrNoSuchFile), errors.Is(err, protocol.ErrInvalid):
+		// Given up on by the puller, or answered with an error by the
+		// other device, which says nothing about the path.
+		return
+	case err != nil:
+		p.ssthresh = max(p.window/2, minStripeWindow)
+		p.window = p.ssthresh
+		return
+	}
+
+	rtt := now.Sub(start)
+	if p.srtt == 0 {
+		p.srtt = rtt
+	} else {
+		p.srtt += (rtt - p.srtt) / 8
+	}
+
+	p.delivered += size
+	if elapsed := now.Sub(p.sampleStart); elapsed >= stripeRateSample {
+		sample := float64(p.delivered) / elapsed.Seconds()
+		if p.rate == 0 {
+			p.rate = sample
+		} else {
+			p.rate += (sample - p.rate) / 4
+		}
+		p.delivered = 0
+		p.sampleStart = now
+	}
+
+	if p.ssthresh == 0 || p.window < p.ssthresh {
+		p.window += size
+	} else {
+		p.window += size * size / p.window
+	}
+	p.window = min(p.window, maxStripeWindow)
+}
+
+// DrainConnection stops the connection being selected for block requests,
+// while the outcome of those in flight is still accounted for.
+func (ps *PacketScheduler) DrainConnection(deviceID protocol.DeviceID, connID string) {
+	ps.mut.Lock()
+	defer ps.mut.Unlock()
+	ps.connections[deviceID] = slices.DeleteFunc(ps.connections[deviceID], func(conn protocol.Connection) bool {
+		return conn.ConnectionID() == connID
+	})
+}
+
+// Outstanding returns the bytes of the block requests in flight on the
+// connection.
+func (ps *PacketScheduler) Outstanding(connID string) int64 {
+	ps.mut.RLock()
+	defer ps.mut.RUnlock()
+	if p, ok := ps.paths[connID]; ok {
+		return p.outstanding
+	}
+	return 0
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestPacketSchedulerStriping(t *testing.T) {
+	const block = 128 << 10
+	scheduler := NewPacketScheduler()
+	deviceID := protocol.LocalDeviceID
+	scheduler.AddConnection(deviceID, NewEnhancedMockConnection("lan", deviceID, 10, 50.0))
+	scheduler.AddConnection(deviceID, NewEnhancedMockConnection("wan", deviceID, 20, 50.0))
+
+	if conn, _ := scheduler.StripeRequest(deviceID, block, "lan"); conn != nil {
+		t.Fatal("Expected no connection with striping disabled")
+	}
+	scheduler.SetStriping(true)
+
+	// A burst of requests fills the window of one path and goes on to the
+	// other.
+	t0 := time.Unix(1000000, 0)
+	counts := make(map[string]int)
+	for range 2 * initialStripeWindow / block {
+		counts[scheduler.stripeRequest(deviceID, block, "lan", t0).ConnectionID()]++
+	}
+	if counts["lan"] != counts["wan"] {
+		t.Fatalf("Expected the burst striped evenly over both paths, got %v", counts)
+	}
+
+	// The LAN answers quickly, the WAN slowly and fails once.
+	for range counts["lan"] {
+		scheduler.stripeDone("lan", block, t0, t0.Add(300*time.Millisecond), nil)
+	}
+	for range counts["wan"] - 1 {
+		scheduler.stripeDone("wan", block, t0, t0.Add(3*time.Second), nil)
+	}
+	scheduler.stripeDone("wan", block, t0, t0.Add(3*time.Second), errors.New("i/o timeout"))
+
+	lan, wan := scheduler.paths["lan"], scheduler.paths["wan"]
+	if lan.window <= initialStripeWindow || wan.window >= initialStripeWindow {
+		t.Errorf("Expected the LAN window to grow and the WAN one to shrink, got %d and %d", lan.window, wan.window)
+	}
+	if lan.rate <= wan.rate {
+		t.Errorf("Expected a higher LAN delivery rate, got %f and %f", lan.rate, wan.rate)
+	}
+	t1 := t0.Add(4 * time.Second)
+	if conn := scheduler.stripeRequest(deviceID, block, "lan", t1); conn.ConnectionID() != "lan" {
+		t.Errorf("Expected the request on the faster path, got %s", conn.ConnectionID())
+	}
+
+	// A cancelled request says nothing about the path.
+	window := lan.window
+	scheduler.stripeDone("lan", block, t1, t1.Add(time.Second), context.Canceled)
+	if lan.window != window || lan.outstanding != 0 {
+		t.Errorf("Expected the LAN window unchanged and nothing outstanding, got %d and %d", lan.window, lan.outstanding)
+	}
+
+	scheduler.RemoveConnection(deviceID, "wan")
+	if _, ok := scheduler.paths["wan"]; ok {
+		t.Error("Expected the path state to go with the connection")
+	}
+}
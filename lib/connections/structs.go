@@ -9,6 +9,7 @@ package connections
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io"
 	"log/slog"
@@ -18,6 +19,7 @@ import (
 
 	"github.com/thejerf/suture/v4"
 
+	"github.com/syncthing/syncthing/internal/faultinject"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/connections/registry"
 	"github.com/syncthing/syncthing/lib/nat"
@@ -50,7 +52,9 @@ type internalConn struct {
 	isLocal       bool
 	priority      int
 	establishedAt time.Time
-	connectionID  string // set after Hello exchange
+	connectionID  string       // set after Hello exchange
+	trace         *connTrace   // of the connection attempt, may be nil
+	cpu           *cryptoMeter // may be nil
 }
 
 type connType int
@@ -62,6 +66,13 @@ const (
 	connTypeTCPServer
 	connTypeQUICClient
 	connTypeQUICServer
+	connTypeLinkLocalClient
+	connTypeLinkLocalServer
+	connTypeUnixClient
+	connTypeUnixServer
+	connTypeDemuxServer
+	connTypeWebSocketClient
+	connTypeWebSocketServer
 )
 
 func (t connType) String() string {
@@ -78,6 +89,20 @@ func (t connType) String() string {
 		return "quic-client"
 	case connTypeQUICServer:
 		return "quic-server"
+	case connTypeLinkLocalClient:
+		return "linklocal-client"
+	case connTypeLinkLocalServer:
+		return "linklocal-server"
+	case connTypeUnixClient:
+		return "unix-client"
+	case connTypeUnixServer:
+		return "unix-server"
+	case connTypeDemuxServer:
+		return "demux-server"
+	case connTypeWebSocketClient:
+		return "websocket-client"
+	case connTypeWebSocketServer:
+		return "websocket-server"
 	default:
 		return "unknown-type"
 	}
@@ -91,6 +116,14 @@ func (t connType) Transport() string {
 		return "tcp"
 	case connTypeQUICClient, connTypeQUICServer:
 		return "quic"
+	case connTypeLinkLocalClient, connTypeLinkLocalServer:
+		return "linklocal"
+	case connTypeUnixClient, connTypeUnixServer:
+		return "unix"
+	case connTypeDemuxServer:
+		return "demux"
+	case connTypeWebSocketClient, connTypeWebSocketServer:
+		return "websocket"
 	default:
 		return "unknown"
 	}
@@ -98,15 +131,26 @@ func (t connType) Transport() string {
 
 func newInternalConn(tc tlsConn, connType connType, isLocal bool, priority int) internalConn {
 	now := time.Now()
+	tc, cpu := withCryptoMeter(tc)
 	return internalConn{
 		tlsConn:       tc,
 		connType:      connType,
 		isLocal:       isLocal,
 		priority:      priority,
 		establishedAt: now.Truncate(time.Second),
+		cpu:           cpu,
 	}
 }
 
+// CryptoTimes returns the estimated CPU time spent encrypting and
+// decrypting the connection.
+func (c internalConn) CryptoTimes() (encrypt, decrypt time.Duration) {
+	if c.cpu == nil {
+		return 0, 0
+	}
+	return c.cpu.times()
+}
+
 func (c internalConn) Close() error {
 	// *tls.Conn.Close() does more than it says on the tin. Specifically, it
 	// sends a TLS alert message, which might block forever if the
@@ -242,6 +286,13 @@ type Model interface {
 	SetConnectionsService(service Service)
 }
 
+// identityMismatchHandler is an optional interface for models that want to
+// know when a device we dialed presented a certificate for another device
+// ID.
+type identityMismatchHandler interface {
+	OnIdentityMismatch(expected protocol.DeviceID, cert *x509.Certificate, addr net.Addr)
+}
+
 type onAddressesChangedNotifier struct {
 	callbacks []func(ListenerAddresses)
 }
@@ -280,5 +331,8 @@ type dialTarget struct {
 
 func (t dialTarget) Dial(ctx context.Context) (internalConn, error) {
 	l.Debugln("dialing", t.deviceID, t.uri, "prio", t.priority)
+	if err := faultinject.Check(faultinject.Dial, t.uri.String()); err != nil {
+		return internalConn{}, err
+	}
 	return t.dialer.Dial(ctx, t.deviceID, t.uri)
 }
@@ -28,34 +28,36 @@ type tcpDialer struct {
 	registry *registry.Registry
 }
 
-func (d *tcpDialer) Dial(ctx context.Context, _ protocol.DeviceID, uri *url.URL) (internalConn, error) {
+func (d *tcpDialer) Dial(ctx context.Context, id protocol.DeviceID, uri *url.URL) (internalConn, error) {
 	uri = fixupPort(uri, config.DefaultTCPPort)
 
+	trace := connTraceFrom(ctx)
 	tcaddr, err := net.ResolveTCPAddr(uri.Scheme, uri.Host)
 	if err != nil {
 		return internalConn{}, err
 	}
+	trace.step(stepResolve)
 
-	conn, err := dialer.DialContextReusePortFunc(d.registry)(ctx, uri.Scheme, tcaddr.String())
+	conn, err := d.dial(ctx, id, uri, tcaddr)
 	if err != nil {
 		// Record connection failure for health monitoring
 		if globalService != nil {
-			// We don't have device ID here, but we can still record the address
-			// In a more complete implementation, we would pass the device ID down
-			globalService.healthMonitor.RecordConnectionError(protocol.LocalDeviceID, uri.Host, err)
+			globalService.healthMonitor.RecordConnectionError(id, uri.Host, err)
 		}
 		return internalConn{}, err
 	}
+	trace.step(stepDial)
 
 	var tc *tls.Conn
 	if tc, err = d.setupTLS(conn, uri); err != nil {
 		conn.Close()
 		// Record connection failure for health monitoring
 		if globalService != nil {
-			globalService.healthMonitor.RecordConnectionError(protocol.LocalDeviceID, uri.Host, err)
+			globalService.healthMonitor.RecordConnectionError(id, uri.Host, err)
 		}
 		return internalConn{}, err
 	}
+	trace.step(stepTLS)
 
 	priority := d.wanPriority
 	isLocal := d.lanChecker.isLANHost(uri.Host)
@@ -65,17 +67,35 @@ func (d *tcpDialer) Dial(ctx context.Context, _ protocol.DeviceID, uri *url.URL)
 
 	// Record connection success for health monitoring
 	if globalService != nil {
-		globalService.healthMonitor.RecordConnectionSuccess(protocol.LocalDeviceID, uri.Host)
+		globalService.healthMonitor.RecordConnectionSuccess(id, uri.Host)
 	}
 
 	return newInternalConn(tc, connTypeTCPClient, isLocal, priority), nil
 }
 
+// dial connects to the address, in the network namespace or VRF given by
+// it, or else from the configured source address or interface if there is
+// one.
+func (d *tcpDialer) dial(ctx context.Context, id protocol.DeviceID, uri *url.URL, raddr *net.TCPAddr) (net.Conn, error) {
+	if scope := netScopeFromURI(uri); scope.isSet() {
+		return scope.dial(ctx, uri.Scheme, raddr.String())
+	}
+	src := d.sourceFor(id)
+	if !src.isSet() {
+		return dialer.DialContextReusePortFunc(d.registry)(ctx, uri.Scheme, raddr.String())
+	}
+	ip, err := src.localIP(raddr.IP)
+	if err != nil {
+		return nil, err
+	}
+	return dialer.DialContextFrom(ctx, uri.Scheme, raddr.String(), &net.TCPAddr{IP: ip}, dialer.BindToDeviceControl(src.iface))
+}
+
 func (d *tcpDialer) setupTLS(conn net.Conn, uri *url.URL) (*tls.Conn, error) {
 	// Get progressive dial timeout based on connection history
 	timeout := getProgressiveDialTimeoutForAddress(uri.Host)
 	_ = conn.SetDeadline(time.Now().Add(timeout))
-	tc := tls.Client(conn, d.tlsCfg)
+	tc := tls.Client(newMeteredConn(conn), d.tlsCfg)
 	// Use global adaptive timeouts since we don't have access to service instance here
 	err := tlsTimedHandshake(tc)
 	
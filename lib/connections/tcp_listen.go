@@ -43,6 +43,7 @@ type tcpListener struct {
 	factory    listenerFactory
 	registry   *registry.Registry
 	lanChecker *lanChecker
+	scope      netScope // network namespace and VRF, if any
 
 	natService *nat.Service
 	mapping    *nat.Mapping
@@ -62,11 +63,15 @@ func (t *tcpListener) serve(ctx context.Context) error {
 		return err
 	}
 
-	lc := net.ListenConfig{
-		Control: dialer.ReusePortControl,
+	var listener net.Listener
+	if t.scope.isSet() {
+		listener, err = t.scope.listen(ctx, t.uri.Scheme, tcaddr.String())
+	} else {
+		lc := net.ListenConfig{
+			Control: dialer.ReusePortControl,
+		}
+		listener, err = lc.Listen(context.TODO(), t.uri.Scheme, tcaddr.String())
 	}
-
-	listener, err := lc.Listen(context.TODO(), t.uri.Scheme, tcaddr.String())
 	if err != nil {
 		slog.WarnContext(ctx, "Failed to listen (TCP)", slogutil.Error(err))
 		// Record connection failure for health monitoring (safely)
@@ -83,28 +88,38 @@ func (t *tcpListener) serve(ctx context.Context) error {
 	t.notifyAddressesChanged(t)
 	defer t.clearAddresses(t)
 
-	t.registry.Register(t.uri.Scheme, tcaddr)
-	defer t.registry.Unregister(t.uri.Scheme, tcaddr)
-
-	slog.InfoContext(ctx, "TCP listener starting", slogutil.Address(tcaddr))
-	defer slog.InfoContext(ctx, "TCP listener shutting down", slogutil.Address(tcaddr))
+	if t.scope.isSet() {
+		// Neither dialing from the listening port nor port mapping can
+		// work from outside the namespace or VRF.
+		slog.InfoContext(ctx, "TCP listener starting", slogutil.Address(tcaddr), slog.String("scope", t.scope.String()))
+		defer slog.InfoContext(ctx, "TCP listener shutting down", slogutil.Address(tcaddr), slog.String("scope", t.scope.String()))
+	} else {
+		t.registry.Register(t.uri.Scheme, tcaddr)
+		defer t.registry.Unregister(t.uri.Scheme, tcaddr)
+
+		slog.InfoContext(ctx, "TCP listener starting", slogutil.Address(tcaddr))
+		defer slog.InfoContext(ctx, "TCP listener shutting down", slogutil.Address(tcaddr))
+	}
 
-	var ipVersion nat.IPVersion
-	switch t.uri.Scheme {
-	case "tcp4":
-		ipVersion = nat.IPv4Only
-	case "tcp6":
-		ipVersion = nat.IPv6Only
-	default:
-		ipVersion = nat.IPvAny
+	var mapping *nat.Mapping
+	if !t.scope.isSet() {
+		var ipVersion nat.IPVersion
+		switch t.uri.Scheme {
+		case "tcp4":
+			ipVersion = nat.IPv4Only
+		case "tcp6":
+			ipVersion = nat.IPv6Only
+		default:
+			ipVersion = nat.IPvAny
+		}
+		// Converted if-else chain to switch statement for better readability (staticcheck QF1003 fix)
+		mapping = t.natService.NewMapping(nat.TCP, ipVersion, tcaddr.IP, tcaddr.Port)
+		mapping.OnChanged(func() {
+			t.notifyAddressesChanged(t)
+		})
+		// Should be called after t.mapping is nil'ed out.
+		defer t.natService.RemoveMapping(mapping)
 	}
-	// Converted if-else chain to switch statement for better readability (staticcheck QF1003 fix)
-	mapping := t.natService.NewMapping(nat.TCP, ipVersion, tcaddr.IP, tcaddr.Port)
-	mapping.OnChanged(func() {
-		t.notifyAddressesChanged(t)
-	})
-	// Should be called after t.mapping is nil'ed out.
-	defer t.natService.RemoveMapping(mapping)
 
 	t.mut.Lock()
 	t.mapping = mapping
@@ -169,7 +184,7 @@ func (t *tcpListener) serve(ctx context.Context) error {
 			}
 		}
 
-		tc := tls.Server(conn, t.tlsCfg)
+		tc := tls.Server(newMeteredConn(conn), t.tlsCfg)
 		
 		// Get progressive dial timeout based on connection history
 		timeout := getProgressiveDialTimeoutForAddress(t.uri.Host)
@@ -209,18 +224,19 @@ func (t *tcpListener) URI() *url.URL {
 }
 
 func (t *tcpListener) WANAddresses() []*url.URL {
+	announced := t.scope.strip(t.uri)
 	t.mut.RLock()
 	uris := []*url.URL{
-		maybeReplacePort(t.uri, t.laddr),
+		maybeReplacePort(announced, t.laddr),
 	}
 
-	uris = append(uris, portMappingURIs(t.mapping, *t.uri)...)
+	uris = append(uris, portMappingURIs(t.mapping, *announced)...)
 
 	t.mut.RUnlock()
 
 	// If we support ReusePort, add an unspecified zero port address, which will be resolved by the discovery server
 	// in hopes that TCP punch through works.
-	if dialer.SupportsReusePort {
+	if dialer.SupportsReusePort && !t.scope.isSet() {
 		uri := *t.uri
 		uri.Host = "0.0.0.0:0"
 		uris = append([]*url.URL{&uri}, uris...)
@@ -230,10 +246,15 @@ func (t *tcpListener) WANAddresses() []*url.URL {
 
 func (t *tcpListener) LANAddresses() []*url.URL {
 	t.mut.RLock()
-	uri := maybeReplacePort(t.uri, t.laddr)
+	uri := maybeReplacePort(t.scope.strip(t.uri), t.laddr)
 	t.mut.RUnlock()
 	addrs := []*url.URL{uri}
-	addrs = append(addrs, getURLsForAllAdaptersIfUnspecified(uri.Scheme, uri)...)
+	if t.scope.isSet() {
+		// The addresses of the interfaces in the namespace or VRF
+		addrs = append(addrs, t.scope.lanURIs(uri)...)
+	} else {
+		addrs = append(addrs, getURLsForAllAdaptersIfUnspecified(uri.Scheme, uri)...)
+	}
 	return addrs
 }
 
@@ -254,6 +275,7 @@ type tcpListenerFactory struct{}
 func (f *tcpListenerFactory) New(uri *url.URL, cfg config.Wrapper, tlsCfg *tls.Config, conns chan internalConn, natService *nat.Service, registry *registry.Registry, lanChecker *lanChecker) genericListener {
 	l := &tcpListener{
 		uri:        fixupPort(uri, config.DefaultTCPPort),
+		scope:      netScopeFromURI(uri),
 		cfg:        cfg,
 		tlsCfg:     tlsCfg,
 		conns:      conns,
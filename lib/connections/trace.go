@@ -0,0 +1,172 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// The steps in establishing a connection. For QUIC the TLS handshake is
+// part of the dial step. Incoming connections are traced from after the
+// TLS handshake.
+const (
+	stepResolve = "resolve"
+	stepDial    = "dial"
+	stepTLS     = "tls"
+	stepHello   = "hello"
+	stepAccept  = "accept"
+)
+
+// The number of connection attempts kept per device.
+const maxConnectionAttempts = 10
+
+// ConnectionStep is the time spent on a step of establishing a connection.
+type ConnectionStep struct {
+	Name       string  `json:"name"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+// ConnectionAttempt describes an attempt at establishing a connection with
+// a device, successful or not.
+type ConnectionAttempt struct {
+	When     time.Time        `json:"when"`
+	Address  string           `json:"address"`
+	Outgoing bool             `json:"outgoing"`
+	Steps    []ConnectionStep `json:"steps"`
+	TotalMs  float64          `json:"totalMs"`
+	Error    *string          `json:"error"`
+}
+
+// connTrace records the time taken by each step of a connection attempt.
+// A nil *connTrace records nothing.
+type connTrace struct {
+	address  string
+	outgoing bool
+	start    time.Time
+
+	mut   sync.Mutex
+	last  time.Time
+	steps []ConnectionStep
+}
+
+func newConnTrace(address string, outgoing bool) *connTrace {
+	now := time.Now()
+	return &connTrace{address: address, outgoing: outgoing, start: now, last: now}
+}
+
+// step records that the named step is done.
+func (t *connTrace) step(name string) {
+	if t == nil {
+		return
+	}
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	now := time.Now()
+	t.steps = append(t.steps, ConnectionStep{Name: name, DurationMs: durationMs(now.Sub(t.last))})
+	t.last = now
+}
+
+func (t *connTrace) attempt(err error) ConnectionAttempt {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	a := ConnectionAttempt{
+		When:     t.start.UTC(),
+		Address:  t.address,
+		Outgoing: t.outgoing,
+		Steps:    append([]ConnectionStep(nil), t.steps...),
+		TotalMs:  durationMs(t.last.Sub(t.start)),
+	}
+	if err != nil {
+		errStr := err.Error()
+		a.Error = &errStr
+	}
+	return a
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+type connTraceKey struct{}
+
+func withConnTrace(ctx context.Context, t *connTrace) context.Context {
+	return context.WithValue(ctx, connTraceKey{}, t)
+}
+
+// connTraceFrom returns the trace for the dial in progress, or nil.
+func connTraceFrom(ctx context.Context) *connTrace {
+	t, _ := ctx.Value(connTraceKey{}).(*connTrace)
+	return t
+}
+
+// connectionAttempts keeps the latest connection attempts for each
+// configured device.
+type connectionAttempts struct {
+	mut      sync.Mutex
+	attempts map[protocol.DeviceID][]ConnectionAttempt
+}
+
+func (a *connectionAttempts) record(device protocol.DeviceID, attempt ConnectionAttempt) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	if a.attempts == nil {
+		a.attempts = make(map[protocol.DeviceID][]ConnectionAttempt)
+	}
+	attempts := append(a.attempts[device], attempt)
+	if len(attempts) > maxConnectionAttempts {
+		attempts = attempts[len(attempts)-maxConnectionAttempts:]
+	}
+	a.attempts[device] = attempts
+}
+
+// forget drops the attempts of a device that is no longer configured.
+func (a *connectionAttempts) forget(device protocol.DeviceID) {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	delete(a.attempts, device)
+}
+
+// ConnectionAttempts returns the latest attempts at connecting with the
+// device, oldest first.
+func (a *connectionAttempts) ConnectionAttempts(device protocol.DeviceID) []ConnectionAttempt {
+	a.mut.Lock()
+	defer a.mut.Unlock()
+	return append([]ConnectionAttempt(nil), a.attempts[device]...)
+}
+
+// finishAttempt records the outcome of the traced connection attempt. For
+// outgoing connections the steps are also added to the connection status
+// of the address. Attempts by unknown devices aren't kept, as anyone can
+// make those.
+func (s *service) finishAttempt(device protocol.DeviceID, t *connTrace, err error) {
+	if t == nil {
+		return
+	}
+	attempt := t.attempt(err)
+	if _, ok := s.cfg.Device(device); ok {
+		s.connectionAttempts.record(device, attempt)
+	}
+	if !t.outgoing {
+		return
+	}
+
+	s.connectionStatusMut.Lock()
+	defer s.connectionStatusMut.Unlock()
+	entry, ok := s.connectionStatus[t.address]
+	if !ok {
+		return
+	}
+	entry.Steps = attempt.Steps
+	if err != nil {
+		entry.Error = attempt.Error
+	}
+	s.connectionStatus[t.address] = entry
+}
@@ -0,0 +1,81 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestConnectionAttempts(t *testing.T) {
+	dev := protocol.DeviceID{1}
+	cfg := config.New(protocol.LocalDeviceID)
+	cfg.Devices = append(cfg.Devices, config.DeviceConfiguration{DeviceID: dev})
+	s := &service{
+		cfg:                     config.Wrap("", cfg, protocol.LocalDeviceID, events.NoopLogger),
+		connectionStatusHandler: newConnectionStatusHandler(),
+	}
+
+	// A failed outgoing attempt updates the status of the address.
+	trace := newConnTrace("tcp://192.0.2.1:22000", true)
+	ctx := withConnTrace(context.Background(), trace)
+	connTraceFrom(ctx).step(stepResolve)
+	connTraceFrom(ctx).step(stepDial)
+	s.setConnectionStatus(trace.address, nil)
+	s.finishAttempt(dev, trace, errors.New("hello failed"))
+
+	attempts := s.ConnectionAttempts(dev)
+	if len(attempts) != 1 {
+		t.Fatalf("expected one attempt, got %d", len(attempts))
+	}
+	if a := attempts[0]; len(a.Steps) != 2 || a.Steps[0].Name != stepResolve || a.Steps[1].Name != stepDial || a.Error == nil || !a.Outgoing {
+		t.Errorf("unexpected attempt %+v", a)
+	}
+	status := s.ConnectionStatus()[trace.address]
+	if len(status.Steps) != 2 || status.Error == nil || *status.Error != "hello failed" {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	// Only the latest attempts are kept.
+	for range maxConnectionAttempts + 5 {
+		s.finishAttempt(dev, newConnTrace("192.0.2.2:1234", false), nil)
+	}
+	attempts = s.ConnectionAttempts(dev)
+	if len(attempts) != maxConnectionAttempts {
+		t.Fatalf("expected %d attempts, got %d", maxConnectionAttempts, len(attempts))
+	}
+	for _, a := range attempts {
+		if a.Outgoing || a.Error != nil {
+			t.Errorf("old attempt kept: %+v", a)
+		}
+	}
+
+	// Attempts by devices we don't know aren't kept.
+	unknown := protocol.DeviceID{3}
+	s.finishAttempt(unknown, newConnTrace("192.0.2.3:1234", false), errors.New("unknown device"))
+	if attempts := s.ConnectionAttempts(unknown); len(attempts) != 0 {
+		t.Errorf("unexpected attempts by unknown device %+v", attempts)
+	}
+
+	// Removing the device from the config forgets its attempts.
+	s.connectionAttempts.forget(dev)
+	if attempts := s.ConnectionAttempts(dev); len(attempts) != 0 {
+		t.Errorf("unexpected attempts after forgetting %+v", attempts)
+	}
+
+	// Without a trace nothing is recorded, nor does it crash.
+	connTraceFrom(context.Background()).step(stepDial)
+	s.finishAttempt(protocol.DeviceID{2}, nil, nil)
+	if attempts := s.ConnectionAttempts(protocol.DeviceID{2}); len(attempts) != 0 {
+		t.Errorf("unexpected attempts %+v", attempts)
+	}
+}
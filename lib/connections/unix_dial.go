@@ -0,0 +1,84 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/connections/registry"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func init() {
+	dialers["unix"] = &unixDialerFactory{}
+}
+
+type unixDialer struct {
+	commonDialer
+}
+
+func (d *unixDialer) Dial(ctx context.Context, _ protocol.DeviceID, uri *url.URL) (internalConn, error) {
+	path, err := unixSocketPath(uri)
+	if err != nil {
+		return internalConn{}, err
+	}
+
+	trace := connTraceFrom(ctx)
+	conn, err := (&net.Dialer{}).DialContext(ctx, "unix", path)
+	if err != nil {
+		return internalConn{}, err
+	}
+	trace.step(stepDial)
+
+	_ = conn.SetDeadline(time.Now().Add(getProgressiveDialTimeoutForAddress(path)))
+	tc := tls.Client(newMeteredConn(conn), d.tlsCfg)
+	if err := tlsTimedHandshake(tc); err != nil {
+		conn.Close()
+		return internalConn{}, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	trace.step(stepTLS)
+
+	return newInternalConn(tc, connTypeUnixClient, true, d.lanPriority), nil
+}
+
+// Priority is always the LAN priority, as there's no host to check.
+func (d *unixDialer) Priority(string) int {
+	return d.lanPriority
+}
+
+type unixDialerFactory struct{}
+
+func (unixDialerFactory) New(opts config.OptionsConfiguration, tlsCfg *tls.Config, _ *registry.Registry, lanChecker *lanChecker) genericDialer {
+	return &unixDialer{
+		commonDialer: commonDialer{
+			reconnectInterval: time.Duration(opts.ReconnectIntervalS) * time.Second,
+			tlsCfg:            tlsCfg,
+			lanChecker:        lanChecker,
+			lanPriority:       opts.ConnectionPriorityTCPLAN,
+			wanPriority:       opts.ConnectionPriorityTCPLAN,
+		},
+	}
+}
+
+func (unixDialerFactory) AlwaysWAN() bool {
+	return false
+}
+
+func (unixDialerFactory) Valid(config.Configuration) error {
+	// Always valid
+	return nil
+}
+
+func (unixDialerFactory) String() string {
+	return "UNIX Socket Dialer"
+}
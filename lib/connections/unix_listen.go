@@ -0,0 +1,184 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/connections/registry"
+	"github.com/syncthing/syncthing/lib/nat"
+	"github.com/syncthing/syncthing/lib/svcutil"
+)
+
+func init() {
+	listeners["unix"] = &unixListenerFactory{}
+}
+
+// unixListener accepts connections on a UNIX domain socket.
+type unixListener struct {
+	svcutil.ServiceWithError
+	onAddressesChangedNotifier
+
+	uri     *url.URL
+	cfg     config.Wrapper
+	tlsCfg  *tls.Config
+	conns   chan internalConn
+	factory listenerFactory
+
+	listening bool
+	mut       sync.RWMutex
+}
+
+func (t *unixListener) serve(ctx context.Context) error {
+	path, err := unixSocketPath(t.uri)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to listen (unix)", slogutil.Error(err))
+		return err
+	}
+
+	// Remove the socket left behind by an unclean shutdown, lest we get
+	// "address already in use". Anything that isn't a socket is left
+	// alone and makes us fail below instead.
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		_ = os.Remove(path)
+	}
+
+	listener, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to listen (unix)", slogutil.Error(err))
+		return err
+	}
+	defer listener.Close()
+
+	if perm := t.cfg.Options().UnixListenerPermissions(); perm != 0 {
+		// The permissions are the access control, so don't carry on
+		// without them.
+		if err := os.Chmod(path, perm); err != nil {
+			err = fmt.Errorf("setting socket permissions: %w", err)
+			slog.WarnContext(ctx, "Failed to listen (unix)", slogutil.Error(err))
+			return err
+		}
+	}
+
+	t.mut.Lock()
+	t.listening = true
+	t.mut.Unlock()
+	defer func() {
+		t.mut.Lock()
+		t.listening = false
+		t.mut.Unlock()
+	}()
+
+	t.notifyAddressesChanged(t)
+	defer t.clearAddresses(t)
+
+	slog.InfoContext(ctx, "UNIX socket listener starting", slogutil.FilePath(path))
+	defer slog.InfoContext(ctx, "UNIX socket listener shutting down", slogutil.FilePath(path))
+
+	acceptFailures := 0
+	const maxAcceptFailures = 10
+
+	for {
+		_ = listener.SetDeadline(time.Now().Add(time.Second))
+		conn, err := listener.AcceptUnix()
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				conn.Close()
+			}
+			return nil
+		default:
+		}
+		if err != nil {
+			var ne *net.OpError
+			if ok := errors.As(err, &ne); !ok || !ne.Timeout() {
+				slog.WarnContext(ctx, "Failed to accept UNIX socket connection", slogutil.Error(err))
+				acceptFailures++
+				if acceptFailures > maxAcceptFailures {
+					return err
+				}
+				time.Sleep(time.Duration(acceptFailures) * time.Second)
+			}
+			continue
+		}
+		acceptFailures = 0
+
+		l.Debugln("Listen (BEP/unix): connect on", path)
+
+		tc := tls.Server(newMeteredConn(conn), t.tlsCfg)
+		if err := tlsTimedHandshake(tc); err != nil {
+			slog.WarnContext(ctx, "Failed TLS handshake", slogutil.FilePath(path), slogutil.Error(err))
+			tc.Close()
+			continue
+		}
+
+		t.conns <- newInternalConn(tc, connTypeUnixServer, true, t.cfg.Options().ConnectionPriorityTCPLAN)
+	}
+}
+
+func (t *unixListener) URI() *url.URL {
+	return t.uri
+}
+
+// WANAddresses returns nothing, as the socket is only reachable on this
+// host.
+func (*unixListener) WANAddresses() []*url.URL {
+	return nil
+}
+
+// LANAddresses returns the socket address while listening. It's shown in
+// the listener status, but not announced.
+func (t *unixListener) LANAddresses() []*url.URL {
+	t.mut.RLock()
+	defer t.mut.RUnlock()
+	if !t.listening {
+		return nil
+	}
+	return []*url.URL{t.uri}
+}
+
+func (t *unixListener) String() string {
+	return t.uri.String()
+}
+
+func (t *unixListener) Factory() listenerFactory {
+	return t.factory
+}
+
+func (*unixListener) NATType() string {
+	return "unknown"
+}
+
+type unixListenerFactory struct{}
+
+func (f *unixListenerFactory) New(uri *url.URL, cfg config.Wrapper, tlsCfg *tls.Config, conns chan internalConn, _ *nat.Service, _ *registry.Registry, _ *lanChecker) genericListener {
+	l := &unixListener{
+		uri:     uri,
+		cfg:     cfg,
+		tlsCfg:  tlsCfg,
+		conns:   conns,
+		factory: f,
+	}
+	l.ServiceWithError = svcutil.AsService(l.serve, l.String())
+	return l
+}
+
+func (unixListenerFactory) Valid(_ config.Configuration) error {
+	// Always valid
+	return nil
+}
@@ -0,0 +1,41 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"errors"
+	"net/url"
+)
+
+// The unix transport is BEP over a UNIX domain socket, for devices on the
+// same host or with the socket forwarded from elsewhere, e.g. by SSH. It's
+// enabled by a listen address like "unix:///run/syncthing/bep.sock" and
+// dialed through a device address of the same form. Connections are always
+// considered LAN. As a socket path means nothing to other hosts, unix
+// addresses are never announced through discovery.
+
+var errNoSocketPath = errors.New("missing socket path")
+
+// unixSocketPath returns the socket path of the address, which is either
+// absolute ("unix:///path/to/sock") or relative to the working directory
+// ("unix:path/to/sock").
+func unixSocketPath(uri *url.URL) (string, error) {
+	path := uri.Path
+	if uri.Opaque != "" {
+		path = uri.Opaque
+	}
+	if path == "" {
+		return "", errNoSocketPath
+	}
+	return path, nil
+}
+
+// isAnnounceable returns whether the address is meaningful to other hosts
+// and can thus be announced through discovery.
+func isAnnounceable(uri *url.URL) bool {
+	return uri.Scheme != "unix"
+}
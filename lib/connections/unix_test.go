@@ -0,0 +1,50 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"testing"
+)
+
+func TestUnixSocketPath(t *testing.T) {
+	cases := []struct {
+		addr string
+		path string
+		err  error
+	}{
+		{"unix:///run/syncthing/bep.sock", "/run/syncthing/bep.sock", nil},
+		{"unix:bep.sock", "bep.sock", nil},
+		{"unix://", "", errNoSocketPath},
+	}
+	for _, tc := range cases {
+		uri, err := url.Parse(tc.addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path, err := unixSocketPath(uri)
+		if path != tc.path || !errors.Is(err, tc.err) {
+			t.Errorf("%s: got %q, %v, expected %q, %v", tc.addr, path, err, tc.path, tc.err)
+		}
+	}
+}
+
+func TestUnixAddressesNotAnnounced(t *testing.T) {
+	uri, _ := url.Parse("unix:///run/syncthing/bep.sock")
+	if isAnnounceable(uri) {
+		t.Error("unix address should not be announced")
+	}
+	uri, _ = url.Parse("tcp://192.0.2.1:22000")
+	if !isAnnounceable(uri) {
+		t.Error("tcp address should be announced")
+	}
+	if !(&lanChecker{}).isLAN(&net.UnixAddr{Name: "/run/syncthing/bep.sock", Net: "unix"}) {
+		t.Error("unix socket should be LAN")
+	}
+}
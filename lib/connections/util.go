@@ -67,6 +67,11 @@ func getHostPortsForAllAdapters(port int) []string {
 		return nil
 	}
 
+	return privateHostPorts(nets, port)
+}
+
+// privateHostPorts returns host:port for the addresses usable on a LAN.
+func privateHostPorts(nets []*net.IPNet, port int) []string {
 	hostPorts := make([]string, 0, len(nets))
 
 	portStr := strconv.Itoa(port)
@@ -10,10 +10,12 @@ package connections
 
 import (
 	"context"
+	"crypto/tls"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/syncthing/syncthing/internal/db"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/protocol"
 )
@@ -48,12 +50,23 @@ func (m *DefensiveMockService) Stop()        {}
 func (m *DefensiveMockService) String() string { return "DefensiveMockService" }
 func (m *DefensiveMockService) ListenerStatus() map[string]ListenerStatusEntry { return nil }
 func (m *DefensiveMockService) ConnectionStatus() map[string]ConnectionStatusEntry { return nil }
+func (m *DefensiveMockService) ConnectionAttempts(protocol.DeviceID) []ConnectionAttempt { return nil }
+func (m *DefensiveMockService) ConnectionErrorBudgets() map[string]DeviceErrorBudget { return nil }
+func (m *DefensiveMockService) ConnectionHealth() ConnectionHealthReport { return ConnectionHealthReport{} }
+func (m *DefensiveMockService) ReconnectDevice(protocol.DeviceID, error) {}
+func (m *DefensiveMockService) LimitFolderSend(context.Context, string, int) error { return nil }
+func (m *DefensiveMockService) LimitFolderRecv(context.Context, string, int) error { return nil }
+func (m *DefensiveMockService) AcknowledgeConnectionErrorBudget(protocol.DeviceID) bool { return false }
 func (m *DefensiveMockService) NATType() string { return "" }
 func (m *DefensiveMockService) GetConnectedDevices() []protocol.DeviceID { return nil }
 func (m *DefensiveMockService) GetConnectionsForDevice(deviceID protocol.DeviceID) []protocol.Connection { return nil }
 func (m *DefensiveMockService) PacketScheduler() *PacketScheduler { return nil }
+func (m *DefensiveMockService) BandwidthEstimates() map[string]BandwidthEstimate { return nil }
+func (m *DefensiveMockService) InfrastructureStatus() map[string]InfrastructureStatusEntry { return nil }
 func (m *DefensiveMockService) AllAddresses() []string { return nil }
 func (m *DefensiveMockService) ExternalAddresses() []string { return nil }
+func (m *DefensiveMockService) SetCertificate(tls.Certificate) error { return nil }
+func (m *DefensiveMockService) SetDatabase(db.KV) {}
 func (m *DefensiveMockService) RawCopy() config.Configuration { return config.Configuration{} }
 
 // TestDefensiveWindowsNetworkMonitor_Lifecycle tests the complete lifecycle
@@ -10,9 +10,11 @@ package connections
 
 import (
 	"context"
+	"crypto/tls"
 	"testing"
 	"time"
 
+	"github.com/syncthing/syncthing/internal/db"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/protocol"
 )
@@ -33,12 +35,23 @@ func (m *MockService) Stop()        {}
 func (m *MockService) String() string { return "MockService" }
 func (m *MockService) ListenerStatus() map[string]ListenerStatusEntry { return nil }
 func (m *MockService) ConnectionStatus() map[string]ConnectionStatusEntry { return nil }
+func (m *MockService) ConnectionAttempts(protocol.DeviceID) []ConnectionAttempt { return nil }
+func (m *MockService) ConnectionErrorBudgets() map[string]DeviceErrorBudget { return nil }
+func (m *MockService) ConnectionHealth() ConnectionHealthReport { return ConnectionHealthReport{} }
+func (m *MockService) ReconnectDevice(protocol.DeviceID, error) {}
+func (m *MockService) LimitFolderSend(context.Context, string, int) error { return nil }
+func (m *MockService) LimitFolderRecv(context.Context, string, int) error { return nil }
+func (m *MockService) AcknowledgeConnectionErrorBudget(protocol.DeviceID) bool { return false }
 func (m *MockService) NATType() string { return "" }
 func (m *MockService) GetConnectedDevices() []protocol.DeviceID { return nil }
 func (m *MockService) GetConnectionsForDevice(deviceID protocol.DeviceID) []protocol.Connection { return nil }
 func (m *MockService) PacketScheduler() *PacketScheduler { return nil }
+func (m *MockService) BandwidthEstimates() map[string]BandwidthEstimate { return nil }
+func (m *MockService) InfrastructureStatus() map[string]InfrastructureStatusEntry { return nil }
 func (m *MockService) AllAddresses() []string { return nil }
 func (m *MockService) ExternalAddresses() []string { return nil }
+func (m *MockService) SetCertificate(tls.Certificate) error { return nil }
+func (m *MockService) SetDatabase(db.KV) {}
 func (m *MockService) RawCopy() config.Configuration { return config.Configuration{} }
 
 func TestWindowsNetworkMonitor_Integration(t *testing.T) {
@@ -10,10 +10,12 @@ package connections
 
 import (
 	"context"
+	"crypto/tls"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/syncthing/syncthing/internal/db"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/protocol"
 )
@@ -48,12 +50,23 @@ func (m *BasicMockService) Stop()        {}
 func (m *BasicMockService) String() string { return "BasicMockService" }
 func (m *BasicMockService) ListenerStatus() map[string]ListenerStatusEntry { return nil }
 func (m *BasicMockService) ConnectionStatus() map[string]ConnectionStatusEntry { return nil }
+func (m *BasicMockService) ConnectionAttempts(protocol.DeviceID) []ConnectionAttempt { return nil }
+func (m *BasicMockService) ConnectionErrorBudgets() map[string]DeviceErrorBudget { return nil }
+func (m *BasicMockService) ConnectionHealth() ConnectionHealthReport { return ConnectionHealthReport{} }
+func (m *BasicMockService) ReconnectDevice(protocol.DeviceID, error) {}
+func (m *BasicMockService) LimitFolderSend(context.Context, string, int) error { return nil }
+func (m *BasicMockService) LimitFolderRecv(context.Context, string, int) error { return nil }
+func (m *BasicMockService) AcknowledgeConnectionErrorBudget(protocol.DeviceID) bool { return false }
 func (m *BasicMockService) NATType() string { return "" }
 func (m *BasicMockService) GetConnectedDevices() []protocol.DeviceID { return nil }
 func (m *BasicMockService) GetConnectionsForDevice(deviceID protocol.DeviceID) []protocol.Connection { return nil }
 func (m *BasicMockService) PacketScheduler() *PacketScheduler { return nil }
+func (m *BasicMockService) BandwidthEstimates() map[string]BandwidthEstimate { return nil }
+func (m *BasicMockService) InfrastructureStatus() map[string]InfrastructureStatusEntry { return nil }
 func (m *BasicMockService) AllAddresses() []string { return nil }
 func (m *BasicMockService) ExternalAddresses() []string { return nil }
+func (m *BasicMockService) SetCertificate(tls.Certificate) error { return nil }
+func (m *BasicMockService) SetDatabase(db.KV) {}
 func (m *BasicMockService) RawCopy() config.Configuration { return config.Configuration{} }
 
 func TestWindowsNetworkMonitor(t *testing.T) {
@@ -0,0 +1,193 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/connections/registry"
+	"github.com/syncthing/syncthing/lib/dialer"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func init() {
+	dialers["wss"] = &wssDialerFactory{}
+}
+
+// wssDialer connects to a WebSocket listener over HTTPS, through the HTTP
+// proxy from the environment if there is one, for addresses like
+// "wss://example.com:443/syncthing"; see wss_listen.go.
+type wssDialer struct {
+	commonDialer
+}
+
+func (d *wssDialer) Dial(ctx context.Context, _ protocol.DeviceID, uri *url.URL) (internalConn, error) {
+	uri = fixupPort(uri, config.DefaultWebSocketPort)
+
+	trace := connTraceFrom(ctx)
+	conn, err := dialHTTPProxied(ctx, uri.Host)
+	if err != nil {
+		return internalConn{}, err
+	}
+	trace.step(stepDial)
+
+	_ = conn.SetDeadline(time.Now().Add(getProgressiveDialTimeoutForAddress(uri.Host)))
+	outer := tls.Client(conn, wssClientTLSConfig(uri.Hostname()))
+	if err := outer.HandshakeContext(ctx); err != nil {
+		conn.Close()
+		return internalConn{}, err
+	}
+	br, err := webSocketClientHandshake(outer, uri.Host, wssPath(uri))
+	if err != nil {
+		outer.Close()
+		return internalConn{}, err
+	}
+
+	tc := tls.Client(newMeteredConn(newWebSocketConn(outer, br, true)), d.tlsCfg)
+	if err := tlsTimedHandshake(tc); err != nil {
+		outer.Close()
+		return internalConn{}, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	trace.step(stepTLS)
+
+	return newInternalConn(tc, connTypeWebSocketClient, d.lanChecker.isLANHost(uri.Host), d.wanPriority), nil
+}
+
+// wssClientTLSConfig is for the outer TLS of a WebSocket connection. The
+// server isn't verified, as it commonly presents its device certificate
+// and a TLS inspecting proxy may replace it anyway; the devices
+// authenticate each other in the BEP TLS inside.
+func wssClientTLSConfig(serverName string) *tls.Config {
+	return &tls.Config{
+		ServerName:         serverName,
+		NextProtos:         []string{"http/1.1"},
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: true, //nolint:gosec // see above
+	}
+}
+
+// wssPath returns the path of the WebSocket in the address, "/" when there
+// is none.
+func wssPath(uri *url.URL) string {
+	if uri.Path == "" {
+		return "/"
+	}
+	return uri.Path
+}
+
+// dialHTTPProxied connects to the address, through a tunnel by the HTTP
+// proxy for HTTPS from the environment if there is one. Other proxies are
+// up to lib/dialer.
+func dialHTTPProxied(ctx context.Context, addr string) (net.Conn, error) {
+	proxyURL, err := http.ProxyFromEnvironment(&http.Request{URL: &url.URL{Scheme: "https", Host: addr}})
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL == nil || (proxyURL.Scheme != "http" && proxyURL.Scheme != "https") {
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+	return dialHTTPProxy(ctx, proxyURL, addr)
+}
+
+// dialHTTPProxy connects to the address through a tunnel by the HTTP proxy,
+// set up with a CONNECT request.
+func dialHTTPProxy(ctx context.Context, proxyURL *url.URL, addr string) (net.Conn, error) {
+	proxyAddr := proxyURL.Host
+	if proxyURL.Port() == "" {
+		port := "80"
+		if proxyURL.Scheme == "https" {
+			port = "443"
+		}
+		proxyAddr = net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if proxyURL.Scheme == "https" {
+		tc := tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname(), MinVersion: tls.VersionTLS12})
+		if err := tc.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		conn = tc
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if user := proxyURL.User; user != nil {
+		pass, _ := user.Password()
+		r := &http.Request{Header: make(http.Header)}
+		r.SetBasicAuth(user.Username(), pass)
+		req.Header.Set("Proxy-Authorization", r.Header.Get("Authorization"))
+	}
+	if err := req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("proxy %s: %s", proxyURL.Redacted(), resp.Status)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	if br.Buffered() > 0 {
+		// Nothing should follow the answer before we speak, but don't
+		// lose it if it does.
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+type wssDialerFactory struct{}
+
+func (wssDialerFactory) New(opts config.OptionsConfiguration, tlsCfg *tls.Config, _ *registry.Registry, lanChecker *lanChecker) genericDialer {
+	return &wssDialer{
+		commonDialer: commonDialer{
+			reconnectInterval: time.Duration(opts.ReconnectIntervalS) * time.Second,
+			tlsCfg:            tlsCfg,
+			lanChecker:        lanChecker,
+			lanPriority:       opts.ConnectionPriorityWebSocket,
+			wanPriority:       opts.ConnectionPriorityWebSocket,
+		},
+	}
+}
+
+func (wssDialerFactory) AlwaysWAN() bool {
+	return false
+}
+
+func (wssDialerFactory) Valid(config.Configuration) error {
+	// Always valid
+	return nil
+}
+
+func (wssDialerFactory) String() string {
+	return "WebSocket over TLS Dialer"
+}
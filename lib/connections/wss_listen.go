@@ -0,0 +1,225 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/connections/registry"
+	"github.com/syncthing/syncthing/lib/dialer"
+	"github.com/syncthing/syncthing/lib/nat"
+	"github.com/syncthing/syncthing/lib/svcutil"
+)
+
+func init() {
+	listeners["wss"] = &wssListenerFactory{}
+}
+
+// The WebSocket listener is for networks that only let HTTPS through,
+// possibly by way of a proxy. A connection is TLS with our device
+// certificate, the HTTP upgrade to a WebSocket on the path of the listen
+// address, and then BEP with its own TLS in binary WebSocket messages, so
+// that the devices authenticate each other as over any other transport.
+
+// wssListener accepts BEP over WebSockets on addresses like
+// "wss://0.0.0.0:443/syncthing".
+type wssListener struct {
+	svcutil.ServiceWithError
+	onAddressesChangedNotifier
+
+	uri        *url.URL
+	cfg        config.Wrapper
+	tlsCfg     *tls.Config
+	conns      chan internalConn
+	factory    listenerFactory
+	lanChecker *lanChecker
+
+	laddr net.Addr
+	mut   sync.RWMutex
+}
+
+func (t *wssListener) serve(ctx context.Context) error {
+	listener, err := net.Listen("tcp", t.uri.Host)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to listen (WebSocket)", slogutil.Error(err))
+		return err
+	}
+	defer listener.Close()
+
+	t.mut.Lock()
+	t.laddr = listener.Addr()
+	t.mut.Unlock()
+	defer func() {
+		t.mut.Lock()
+		t.laddr = nil
+		t.mut.Unlock()
+	}()
+
+	t.notifyAddressesChanged(t)
+	defer t.clearAddresses(t)
+
+	slog.InfoContext(ctx, "WebSocket listener starting", slogutil.Address(listener.Addr()))
+	defer slog.InfoContext(ctx, "WebSocket listener shutting down", slogutil.Address(listener.Addr()))
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	acceptFailures := 0
+	const maxAcceptFailures = 10
+
+	tcpListener := listener.(*net.TCPListener)
+	for {
+		_ = tcpListener.SetDeadline(time.Now().Add(time.Second))
+		conn, err := tcpListener.Accept()
+		select {
+		case <-ctx.Done():
+			if err == nil {
+				conn.Close()
+			}
+			return nil
+		default:
+		}
+		if err != nil {
+			var ne *net.OpError
+			if ok := errors.As(err, &ne); !ok || !ne.Timeout() {
+				slog.WarnContext(ctx, "Failed to accept WebSocket connection", slogutil.Error(err))
+				acceptFailures++
+				if acceptFailures > maxAcceptFailures {
+					return err
+				}
+				time.Sleep(time.Duration(acceptFailures) * time.Second)
+			}
+			continue
+		}
+		acceptFailures = 0
+
+		if err := dialer.SetTCPOptions(conn); err != nil {
+			l.Debugln("Listen (BEP/wss): setting tcp options:", err)
+		}
+
+		// The handshakes wait for the client, so don't hold up the next one.
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			t.handle(ctx, conn)
+		}()
+	}
+}
+
+// handle does the outer TLS and WebSocket handshakes, then the BEP one.
+func (t *wssListener) handle(ctx context.Context, conn net.Conn) {
+	_ = conn.SetDeadline(time.Now().Add(demuxSniffTimeout))
+	outer := tls.Server(conn, t.outerTLSConfig())
+	if err := outer.HandshakeContext(ctx); err != nil {
+		l.Debugln("Listen (BEP/wss): TLS handshake:", err)
+		conn.Close()
+		return
+	}
+
+	br := bufio.NewReader(outer)
+	req, err := http.ReadRequest(br)
+	if err != nil {
+		outer.Close()
+		return
+	}
+	if !isWebSocketUpgrade(req) || req.URL.Path != wssPath(t.uri) {
+		l.Debugln("Listen (BEP/wss): not a WebSocket upgrade from", conn.RemoteAddr(), req.Method, req.URL.Path)
+		_, _ = outer.Write([]byte("HTTP/1.1 404 Not Found\r\nContent-Length: 0\r\nConnection: close\r\n\r\n"))
+		outer.Close()
+		return
+	}
+	if _, err := outer.Write(webSocketServerResponse(req)); err != nil {
+		outer.Close()
+		return
+	}
+
+	l.Debugln("Listen (BEP/wss): connect from", conn.RemoteAddr())
+	tc := tls.Server(newMeteredConn(newWebSocketConn(outer, br, false)), t.tlsCfg)
+	if err := tlsTimedHandshake(tc); err != nil {
+		slog.WarnContext(ctx, "Failed TLS handshake", slogutil.Address(conn.RemoteAddr()), slogutil.Error(err))
+		tc.Close()
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	select {
+	case t.conns <- newInternalConn(tc, connTypeWebSocketServer, t.lanChecker.isLAN(conn.RemoteAddr()), t.cfg.Options().ConnectionPriorityWebSocket):
+	case <-ctx.Done():
+		tc.Close()
+	}
+}
+
+// outerTLSConfig presents our device certificate over HTTPS, without
+// asking for the client's, which is left to the BEP TLS inside.
+func (t *wssListener) outerTLSConfig() *tls.Config {
+	cfg := t.tlsCfg.Clone()
+	cfg.ClientAuth = tls.NoClientCert
+	cfg.NextProtos = []string{"http/1.1"}
+	return cfg
+}
+
+func (t *wssListener) URI() *url.URL {
+	return t.uri
+}
+
+func (t *wssListener) WANAddresses() []*url.URL {
+	t.mut.RLock()
+	defer t.mut.RUnlock()
+	return []*url.URL{maybeReplacePort(t.uri, t.laddr)}
+}
+
+func (t *wssListener) LANAddresses() []*url.URL {
+	t.mut.RLock()
+	uri := maybeReplacePort(t.uri, t.laddr)
+	t.mut.RUnlock()
+	addrs := []*url.URL{uri}
+	addrs = append(addrs, getURLsForAllAdaptersIfUnspecified("tcp", uri)...)
+	return addrs
+}
+
+func (t *wssListener) String() string {
+	return t.uri.String()
+}
+
+func (t *wssListener) Factory() listenerFactory {
+	return t.factory
+}
+
+func (*wssListener) NATType() string {
+	return "unknown"
+}
+
+type wssListenerFactory struct{}
+
+func (f *wssListenerFactory) New(uri *url.URL, cfg config.Wrapper, tlsCfg *tls.Config, conns chan internalConn, _ *nat.Service, _ *registry.Registry, lanChecker *lanChecker) genericListener {
+	l := &wssListener{
+		uri:        fixupPort(uri, config.DefaultWebSocketPort),
+		cfg:        cfg,
+		tlsCfg:     tlsCfg,
+		conns:      conns,
+		factory:    f,
+		lanChecker: lanChecker,
+	}
+	l.ServiceWithError = svcutil.AsService(l.serve, l.String())
+	return l
+}
+
+func (wssListenerFactory) Valid(_ config.Configuration) error {
+	// Always valid
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package connections
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/thejerf/suture/v4"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/connections/registry"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/tlsutil"
+)
+
+func TestWSSListener(t *testing.T) {
+	cert := mustGetCert(t)
+	deviceID := protocol.NewDeviceID(cert.Certificate[0])
+	tlsCfg := tlsutil.SecureDefaultTLS13()
+	tlsCfg.Certificates = []tls.Certificate{cert}
+	tlsCfg.ClientAuth = tls.RequestClientCert
+	tlsCfg.InsecureSkipVerify = true
+
+	cfg := config.Configuration{
+		Options: config.OptionsConfiguration{ConnectionPriorityWebSocket: 45},
+	}
+	wcfg := config.Wrap("", cfg, deviceID, events.NoopLogger)
+	lanChecker := &lanChecker{wcfg}
+	conns := make(chan internalConn, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	supervisor := suture.New("main", suture.Spec{PassThroughPanics: true})
+	supervisor.ServeBackground(ctx)
+
+	uri, _ := url.Parse("wss://127.0.0.1:0/syncthing")
+	listener := (&wssListenerFactory{}).New(uri, wcfg, tlsCfg, conns, nil, registry.New(), lanChecker)
+	supervisor.Add(listener)
+
+	var addr *url.URL
+	for {
+		if addrs := listener.WANAddresses(); len(addrs) > 0 && addrs[0].Port() != "0" {
+			addr = addrs[0]
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if addr.Scheme != "wss" || addr.Path != "/syncthing" {
+		t.Fatalf("unexpected address %v", addr)
+	}
+
+	expectBEP := func(t *testing.T, client internalConn) {
+		t.Helper()
+		server := <-conns
+		defer server.Close()
+		defer client.Close()
+		if server.Type() != "websocket-server" || client.Priority() != 45 {
+			t.Errorf("unexpected connection %v, priority %d", server.Type(), client.Priority())
+		}
+		if _, err := client.Write([]byte("hello")); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 5)
+		if _, err := io.ReadFull(server, buf); err != nil || string(buf) != "hello" {
+			t.Fatalf("unexpected read %q, %v", buf, err)
+		}
+	}
+
+	t.Run("direct", func(t *testing.T) {
+		dialer := (&wssDialerFactory{}).New(cfg.Options, tlsCfg, nil, lanChecker)
+		client, err := dialer.Dial(ctx, deviceID, addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expectBEP(t, client)
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		proxy, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer proxy.Close()
+		go func() {
+			conn, err := proxy.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			br := bufio.NewReader(conn)
+			req, err := http.ReadRequest(br)
+			if err != nil || req.Method != http.MethodConnect || req.Host != addr.Host {
+				_, _ = conn.Write([]byte("HTTP/1.1 403 Forbidden\r\n\r\n"))
+				return
+			}
+			backend, err := net.Dial("tcp", req.Host)
+			if err != nil {
+				return
+			}
+			defer backend.Close()
+			_, _ = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+			go func() { _, _ = io.Copy(backend, br) }()
+			_, _ = io.Copy(conn, backend)
+		}()
+
+		conn, err := dialHTTPProxy(ctx, &url.URL{Scheme: "http", Host: proxy.Addr().String()}, addr.Host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		outer := tls.Client(conn, wssClientTLSConfig(addr.Hostname()))
+		if err := outer.Handshake(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := webSocketClientHandshake(outer, addr.Host, wssPath(addr)); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("wrong path", func(t *testing.T) {
+		conn, err := net.Dial("tcp", addr.Host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer conn.Close()
+		outer := tls.Client(conn, wssClientTLSConfig(addr.Hostname()))
+		if _, err := webSocketClientHandshake(outer, addr.Host, "/other"); err == nil {
+			t.Error("expected the upgrade on another path to fail")
+		}
+	})
+}
@@ -0,0 +1,39 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package dialer
+
+import (
+	"errors"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// BindToDeviceControl returns a socket control function that binds the
+// socket to the named network interface, so that traffic uses that
+// interface regardless of the routing table. Binding requires privileges
+// on older kernels; without them the socket is left unbound and only the
+// source address decides the route.
+func BindToDeviceControl(iface string) func(network, address string, c syscall.RawConn) error {
+	return func(_, _ string, c syscall.RawConn) error {
+		if iface == "" {
+			return nil
+		}
+		var opErr error
+		err := c.Control(func(fd uintptr) {
+			opErr = unix.SetsockoptString(int(fd), unix.SOL_SOCKET, unix.SO_BINDTODEVICE, iface)
+		})
+		if err != nil {
+			return err
+		}
+		if errors.Is(opErr, unix.EPERM) {
+			l.Debugln("Not permitted to bind socket to interface", iface)
+			return nil
+		}
+		return opErr
+	}
+}
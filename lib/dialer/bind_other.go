@@ -0,0 +1,21 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build !linux
+// +build !linux
+
+package dialer
+
+import "syscall"
+
+// BindToDeviceControl returns a socket control function that does nothing,
+// as binding sockets to an interface is not supported on this platform.
+// The source address decides the route.
+func BindToDeviceControl(_ string) func(network, address string, c syscall.RawConn) error {
+	return func(_, _ string, _ syscall.RawConn) error {
+		return nil
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package dialer
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Named network namespaces, as created by "ip netns add"
+const netnsDir = "/var/run/netns"
+
+// InNetNS calls fn in the named network namespace, or directly if the name
+// is empty. Sockets created by fn belong to the namespace for their
+// lifetime. Entering a namespace requires CAP_SYS_ADMIN.
+func InNetNS(name string, fn func() error) error {
+	if name == "" {
+		return fn()
+	}
+
+	errC := make(chan error, 1)
+	go func() {
+		// The namespace is a property of the thread, which is dedicated to
+		// us until we're back in the original namespace. If we don't get
+		// back the thread stays locked and is terminated with us.
+		runtime.LockOSThread()
+
+		orig, err := os.Open(fmt.Sprintf("/proc/%d/task/%d/ns/net", os.Getpid(), unix.Gettid()))
+		if err != nil {
+			runtime.UnlockOSThread()
+			errC <- fmt.Errorf("network namespace: %w", err)
+			return
+		}
+		defer orig.Close()
+
+		target, err := os.Open(filepath.Join(netnsDir, name))
+		if err != nil {
+			runtime.UnlockOSThread()
+			errC <- fmt.Errorf("network namespace %s: %w", name, err)
+			return
+		}
+		defer target.Close()
+
+		if err := unix.Setns(int(target.Fd()), unix.CLONE_NEWNET); err != nil {
+			runtime.UnlockOSThread()
+			errC <- fmt.Errorf("entering network namespace %s: %w", name, err)
+			return
+		}
+
+		fnErr := fn()
+
+		if err := unix.Setns(int(orig.Fd()), unix.CLONE_NEWNET); err != nil {
+			l.Debugln("Failed to leave network namespace", name, err)
+		} else {
+			runtime.UnlockOSThread()
+		}
+		errC <- fnErr
+	}()
+	return <-errC
+}
+
+// BindToVRFControl returns a socket control function that binds the socket
+// to the named VRF device, confining its traffic to the VRF. Unlike
+// BindToDeviceControl, failure to bind is an error.
+func BindToVRFControl(vrf string) func(network, address string, c syscall.RawConn) error {
+	return func(_, _ string, c syscall.RawConn) error {
+		if vrf == "" {
+			return nil
+		}
+		var opErr error
+		err := c.Control(func(fd uintptr) {
+			opErr = unix.SetsockoptString(int(fd), unix.SOL_SOCKET, unix.SO_BINDTODEVICE, vrf)
+		})
+		if err != nil {
+			return err
+		}
+		if opErr != nil {
+			return fmt.Errorf("binding to VRF %s: %w", vrf, opErr)
+		}
+		return nil
+	}
+}
+
+// VRFMembers returns the interfaces enslaved to the VRF device, in the
+// current network namespace.
+func VRFMembers(vrf string) ([]net.Interface, error) {
+	master, err := net.InterfaceByName(vrf)
+	if err != nil {
+		return nil, fmt.Errorf("VRF %s: %w", vrf, err)
+	}
+	// Which device an interface is enslaved to is only told by netlink.
+	rib, err := syscall.NetlinkRIB(syscall.RTM_GETLINK, syscall.AF_UNSPEC)
+	if err != nil {
+		return nil, fmt.Errorf("listing links: %w", err)
+	}
+	msgs, err := syscall.ParseNetlinkMessage(rib)
+	if err != nil {
+		return nil, fmt.Errorf("listing links: %w", err)
+	}
+	var members []net.Interface
+	for _, msg := range msgs {
+		if msg.Header.Type != syscall.RTM_NEWLINK || len(msg.Data) < syscall.SizeofIfInfomsg {
+			continue
+		}
+		ifim := (*syscall.IfInfomsg)(unsafe.Pointer(&msg.Data[0]))
+		attrs, err := syscall.ParseNetlinkRouteAttr(&msg)
+		if err != nil {
+			continue
+		}
+		for _, attr := range attrs {
+			if attr.Attr.Type != unix.IFLA_MASTER || len(attr.Value) < 4 || int(binary.NativeEndian.Uint32(attr.Value)) != master.Index {
+				continue
+			}
+			if iface, err := net.InterfaceByIndex(int(ifim.Index)); err == nil {
+				members = append(members, *iface)
+			}
+		}
+	}
+	return members, nil
+}
@@ -0,0 +1,45 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build !linux
+// +build !linux
+
+package dialer
+
+import (
+	"errors"
+	"net"
+	"syscall"
+)
+
+var (
+	errNetNSUnsupported = errors.New("network namespaces are not supported on this platform")
+	errVRFUnsupported   = errors.New("VRFs are not supported on this platform")
+)
+
+// InNetNS calls fn if the name is empty, network namespaces being specific
+// to Linux.
+func InNetNS(name string, fn func() error) error {
+	if name != "" {
+		return errNetNSUnsupported
+	}
+	return fn()
+}
+
+// BindToVRFControl returns a socket control function that fails unless the
+// VRF is empty, VRFs being specific to Linux.
+func BindToVRFControl(vrf string) func(network, address string, c syscall.RawConn) error {
+	return func(_, _ string, _ syscall.RawConn) error {
+		if vrf != "" {
+			return errVRFUnsupported
+		}
+		return nil
+	}
+}
+
+func VRFMembers(_ string) ([]net.Interface, error) {
+	return nil, errVRFUnsupported
+}
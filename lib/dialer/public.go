@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"syscall"
 	"time"
 
 	"github.com/syncthing/syncthing/lib/connections/registry"
@@ -101,6 +102,12 @@ func DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
 	return dialContextWithFallback(ctx, proxy.Direct, network, addr)
 }
 
+// DialContextFrom dials like DialContext, binding direct connections to the
+// given local address and applying the socket control function, if any.
+func DialContextFrom(ctx context.Context, network, addr string, laddr net.Addr, control func(network, address string, c syscall.RawConn) error) (net.Conn, error) {
+	return dialContextWithFallback(ctx, &net.Dialer{LocalAddr: laddr, Control: control}, network, addr)
+}
+
 // DialContextReusePort tries dialing via proxy if a proxy is configured, and falls back to
 // a direct connection reusing the port from the connections registry, if no proxy is defined, or connecting via proxy
 // fails. It also in parallel dials without reusing the port, just in case reusing the port affects routing decisions badly.
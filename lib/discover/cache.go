@@ -149,6 +149,12 @@ func (c *cache) Set(id protocol.DeviceID, ce CacheEntry) {
 	c.mut.Unlock()
 }
 
+func (c *cache) Delete(id protocol.DeviceID) {
+	c.mut.Lock()
+	delete(c.entries, id)
+	c.mut.Unlock()
+}
+
 func (c *cache) Get(id protocol.DeviceID) (CacheEntry, bool) {
 	c.mut.Lock()
 	ce, ok := c.entries[id]
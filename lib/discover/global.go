@@ -29,6 +29,8 @@ import (
 	"github.com/syncthing/syncthing/lib/dialer"
 	"github.com/syncthing/syncthing/lib/events"
 	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/retry"
+	"github.com/syncthing/syncthing/lib/svcutil"
 )
 
 type globalClient struct {
@@ -42,10 +44,22 @@ type globalClient struct {
 	noAnnounce     bool
 	noLookup       bool
 	evLogger       events.Logger
-	// Add circuit breaker for server communication
-	circuitBreaker *circuitBreaker
-	// Add backoff for retry logic
-	backoff        *exponentialBackoff
+	lookups        *retry.Backoff
+	announcements  *retry.Backoff
+	jitter         *svcutil.Jitter
+	announceJitter time.Duration // of the first announcement
+}
+
+// A GlobalOption changes the behaviour of a global discovery client.
+type GlobalOption func(*globalClient)
+
+// WithAnnounceJitter delays the first announcement by a random part of max,
+// and varies the intervals after, so that a cluster starting at once
+// doesn't announce at once.
+func WithAnnounceJitter(max time.Duration) GlobalOption {
+	return func(c *globalClient) {
+		c.announceJitter = max
+	}
 }
 
 type httpClient interface {
@@ -61,70 +75,24 @@ const (
 	// Cache TTL constants
 	// defaultCacheTTL                       = 10 * time.Minute
 	// negativeCacheTTL                      = 2 * time.Minute
-	// Circuit breaker constants
-	circuitBreakerFailureThreshold        = 5
-	circuitBreakerRetryTimeout            = 1 * time.Minute
 )
 
-// circuitBreaker implements a simple circuit breaker pattern
-type circuitBreaker struct {
-	mut           sync.Mutex
-	failureCount  int
-	lastFailure   time.Time
-	timeout       time.Duration
-	failureThreshold int
-	open          bool
-}
-
-func newCircuitBreaker(failureThreshold int, timeout time.Duration) *circuitBreaker {
-	return &circuitBreaker{
-		failureThreshold: failureThreshold,
-		timeout:          timeout,
-	}
-}
-
-// Call executes the given function with circuit breaker protection
-func (cb *circuitBreaker) Call(fn func() error) error {
-	cb.mut.Lock()
-	
-	// Check if circuit breaker is open
-	if cb.open {
-		// Check if we should try to close it
-		if time.Since(cb.lastFailure) > cb.timeout {
-			cb.open = false
-			cb.failureCount = 0
-		} else {
-			cb.mut.Unlock()
-			return errors.New("circuit breaker is open")
-		}
-	}
-	
-	cb.mut.Unlock()
-	
-	// Execute the function
-	err := fn()
-	
-	cb.mut.Lock()
-	defer cb.mut.Unlock()
-	
-	if err != nil {
-		cb.failureCount++
-		cb.lastFailure = time.Now()
-		
-		// Check if we should open the circuit breaker
-		if cb.failureCount >= cb.failureThreshold {
-			cb.open = true
-			slog.Warn("Circuit breaker opened due to repeated failures", 
-				"failureCount", cb.failureCount,
-				"threshold", cb.failureThreshold)
-		}
-	} else {
-		// Success, reset failure count
-		cb.failureCount = 0
+// Default retry policies for talking to discovery servers. Announcements
+// back off between attempts; both stop for a while after repeated
+// failures.
+var (
+	lookupRetryPolicy = retry.Policy{
+		BreakAfter: 5,
+		CoolDown:   time.Minute,
+	}
+	announceRetryPolicy = retry.Policy{
+		BaseDelay:  time.Second,
+		MaxDelay:   30 * time.Second,
+		Jitter:     0.5,
+		BreakAfter: 5,
+		CoolDown:   time.Minute,
 	}
-	
-	return err
-}
+)
 
 type announcement struct {
 	Addresses []string `json:"addresses"`
@@ -158,7 +126,7 @@ func (e *lookupError) CacheFor() time.Duration {
 	return e.cacheFor
 }
 
-func NewGlobal(server string, cert tls.Certificate, addrList AddressLister, evLogger events.Logger, registry *registry.Registry) (FinderService, error) {
+func NewGlobal(server string, cert tls.Certificate, addrList AddressLister, evLogger events.Logger, registry *registry.Registry, options ...GlobalOption) (FinderService, error) {
 	server, opts, err := parseOptions(server)
 	if err != nil {
 		return nil, err
@@ -226,8 +194,17 @@ func NewGlobal(server string, cert tls.Certificate, addrList AddressLister, evLo
 		noAnnounce:     opts.noAnnounce,
 		noLookup:       opts.noLookup,
 		evLogger:       evLogger,
-		circuitBreaker: newCircuitBreaker(circuitBreakerFailureThreshold, circuitBreakerRetryTimeout),
-		backoff:        newExponentialBackoff(5, 1*time.Second, 30*time.Second),
+		lookups:        retry.New(retry.Lookups, lookupRetryPolicy),
+		announcements:  retry.New(retry.Announcement, announceRetryPolicy),
+	}
+	var seed []byte
+	if len(cert.Certificate) > 0 {
+		id := protocol.NewDeviceID(cert.Certificate[0])
+		seed = id[:]
+	}
+	cl.jitter = svcutil.NewJitter(seed)
+	for _, opt := range options {
+		opt(cl)
 	}
 	if !opts.noAnnounce {
 		// If we are supposed to announce, it's an error until we've done so.
@@ -255,9 +232,8 @@ func (c *globalClient) Lookup(ctx context.Context, device protocol.DeviceID) (ad
 	q.Set("device", device.String())
 	qURL.RawQuery = q.Encode()
 
-	// Use circuit breaker for lookup requests
 	var resp *http.Response
-	err = c.circuitBreaker.Call(func() error {
+	err = c.lookups.Call(func() error {
 		var innerErr error
 		resp, innerErr = c.queryClient.Get(ctx, qURL.String())
 		if innerErr != nil {
@@ -268,9 +244,6 @@ func (c *globalClient) Lookup(ctx context.Context, device protocol.DeviceID) (ad
 	})
 
 	if err != nil {
-		// Use exponential backoff for retry delay on lookup failures
-		delay := c.backoff.NextDelay()
-		slog.DebugContext(ctx, "Using exponential backoff for lookup retry", "delay", delay)
 		return nil, err
 	}
 
@@ -310,7 +283,7 @@ func (c *globalClient) Serve(ctx context.Context) error {
 		return ctx.Err()
 	}
 
-	timer := time.NewTimer(5 * time.Second)
+	timer := time.NewTimer(5*time.Second + c.jitter.Delay(c.announceJitter))
 	defer timer.Stop()
 
 	eventSub := c.evLogger.Subscribe(events.ListenAddressesChanged)
@@ -365,9 +338,13 @@ func (c *globalClient) sendAnnouncement(ctx context.Context, timer *time.Timer)
 
 	slog.DebugContext(ctx, "send announcement", "server", c.server, "announcement", ann)
 
-	// Use circuit breaker and exponential backoff for announcement
 	var serverRecommendedInterval time.Duration = -1
-	err := c.circuitBreaker.Call(func() error {
+	if err := c.announcements.Allow(); err != nil {
+		c.setError(err)
+		timer.Reset(c.announcements.Policy().CoolDown)
+		return
+	}
+	err := func() error {
 		resp, err := c.announceClient.Post(ctx, c.server, "application/json", bytes.NewReader(postData))
 		if err != nil {
 			slog.DebugContext(ctx, "announce POST", "server", c.server, slogutil.Error(err))
@@ -391,28 +368,25 @@ func (c *globalClient) sendAnnouncement(ctx context.Context, timer *time.Timer)
 		}
 
 		return nil
-	})
+	}()
 
 	if err != nil {
 		slog.WarnContext(ctx, "Failed to send announcement", "server", c.server, "error", err)
 		c.setError(err)
-		
-		// Use exponential backoff for retry delay
-		delay := c.backoff.NextDelay()
-		slog.DebugContext(ctx, "Using exponential backoff for retry", "delay", delay)
+		delay := c.announcements.Failure()
+		slog.DebugContext(ctx, "Retrying announcement", "server", c.server, "delay", delay)
 		timer.Reset(delay)
 		return
 	}
 
-	// Success, reset backoff
-	c.backoff.Reset()
+	c.announcements.Success()
 	c.setError(nil)
 
 	// Use server-recommended interval if provided, otherwise default
 	if serverRecommendedInterval > 0 {
 		timer.Reset(serverRecommendedInterval)
 	} else {
-		timer.Reset(defaultReannounceInterval)
+		timer.Reset(c.jitter.Spread(defaultReannounceInterval, 0.1))
 	}
 }
 
@@ -559,56 +533,6 @@ func (c *contextClient) Post(ctx context.Context, url, ctype string, data io.Rea
 	return c.Client.Do(req)
 }
 
-type exponentialBackoff struct {
-	mut        sync.Mutex
-	attempts   int
-	maxRetries int
-	baseDelay  time.Duration
-	maxDelay   time.Duration
-}
-
-func newExponentialBackoff(maxRetries int, baseDelay, maxDelay time.Duration) *exponentialBackoff {
-	return &exponentialBackoff{
-		maxRetries: maxRetries,
-		baseDelay:  baseDelay,
-		maxDelay:   maxDelay,
-	}
-}
-
-// NextDelay calculates the next delay based on exponential backoff
-func (eb *exponentialBackoff) NextDelay() time.Duration {
-	eb.mut.Lock()
-	defer eb.mut.Unlock()
-	
-	if eb.attempts >= eb.maxRetries {
-		return eb.maxDelay
-	}
-	
-	delay := time.Duration(float64(eb.baseDelay) * pow(2, float64(eb.attempts)))
-	if delay > eb.maxDelay {
-		delay = eb.maxDelay
-	}
-	
-	eb.attempts++
-	return delay
-}
-
-// Reset resets the backoff counter
-func (eb *exponentialBackoff) Reset() {
-	eb.mut.Lock()
-	defer eb.mut.Unlock()
-	eb.attempts = 0
-}
-
-// pow calculates base^exp efficiently
-func pow(base, exp float64) float64 {
-	result := 1.0
-	for i := 0; i < int(exp); i++ {
-		result *= base
-	}
-	return result
-}
-
 func globalDiscoveryIdentity(addr string) string {
 	return "global discovery server " + addr
 }
@@ -621,6 +545,13 @@ func ipv6Identity(addr string) string {
 	return fmt.Sprintf("IPv6 local multicast discovery on address %s", addr)
 }
 
+func mdnsIdentity(ipv6Only bool) string {
+	if ipv6Only {
+		return "IPv6 mDNS local discovery"
+	}
+	return "mDNS local discovery"
+}
+
 func http2EnabledTransport(t *http.Transport) *http.Transport {
 	_ = http2.ConfigureTransport(t)
 	return t
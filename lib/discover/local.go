@@ -558,12 +558,62 @@ func (c *localClient) registerDevice(src net.Addr, device *discoproto.Announce)
 	}
 	c.discoveryStats.lastUpdate = time.Now()
 
-	// Any empty or unspecified addresses should be set to the source address
-	// of the announcement. We also skip any addresses we can't parse.
+	validAddresses := resolveAnnouncedAddresses(id, src, device.Addresses)
 
-	slog.Debug("Registering addresses for device", "device", id, "numAddresses", len(device.Addresses))
+	// Additional check - if we have no valid addresses, try to add the source address directly
+	if len(validAddresses) == 0 {
+		slog.Warn("No valid addresses found, attempting to use source address directly", "device", id, "source", src.String())
+		srcHost, srcPort, err := net.SplitHostPort(src.String())
+		if err == nil {
+			// Try to create a valid address using the source
+			validAddresses = append(validAddresses, "tcp://"+net.JoinHostPort(srcHost, srcPort))
+			slog.Debug("Added source address as fallback", "device", id, "address", validAddresses[0])
+		} else {
+			slog.Warn("Failed to parse source address for fallback", "device", id, "source", src.String(), "error", err)
+		}
+	}
+
+	slog.Debug("Updating device cache", "device", id, "numValidAddresses", len(validAddresses), "addresses", validAddresses)
+	c.Set(id, CacheEntry{
+		Addresses:  validAddresses,
+		when:       time.Now(),
+		found:      true,
+		instanceID: device.InstanceId,
+	})
+
+	// Log additional information if available
+	if device.Version > 0 || device.ClientName != "" {
+		deviceInfo := map[string]interface{}{
+			"device":  id.String(),
+			"addrs":   validAddresses,
+			"version": device.Version,
+			"client":  device.ClientName + " " + device.ClientVersion,
+		}
+		
+		// Add feature information if available
+		if device.Features > 0 {
+			deviceInfo["features"] = c.getFeatureNames(device.Features)
+		}
+		
+		c.evLogger.Log(events.DeviceDiscovered, deviceInfo)
+	} else {
+		// Fall back to original logging format for compatibility
+		c.evLogger.Log(events.DeviceDiscovered, map[string]interface{}{
+			"device": id.String(),
+			"addrs":  validAddresses,
+		})
+	}
+
+	return isNewDevice
+}
+
+// resolveAnnouncedAddresses returns the addresses announced by the device,
+// with any empty or unspecified IP replaced by the source address of the
+// announcement, skipping those we can't parse.
+func resolveAnnouncedAddresses(id protocol.DeviceID, src net.Addr, addrs []string) []string {
+	slog.Debug("Registering addresses for device", "device", id, "numAddresses", len(addrs))
 	var validAddresses []string
-	for i, addr := range device.Addresses {
+	for i, addr := range addrs {
 		slog.Debug("Processing address", "device", id, "addressIndex", i, "address", addr)
 		u, err := url.Parse(addr)
 		if err != nil {
@@ -611,52 +661,7 @@ func (c *localClient) registerDevice(src net.Addr, device *discoproto.Announce)
 			slog.Debug("Accepted address verbatim", "device", id, "address", addr)
 		}
 	}
-
-	// Additional check - if we have no valid addresses, try to add the source address directly
-	if len(validAddresses) == 0 {
-		slog.Warn("No valid addresses found, attempting to use source address directly", "device", id, "source", src.String())
-		srcHost, srcPort, err := net.SplitHostPort(src.String())
-		if err == nil {
-			// Try to create a valid address using the source
-			validAddresses = append(validAddresses, "tcp://"+net.JoinHostPort(srcHost, srcPort))
-			slog.Debug("Added source address as fallback", "device", id, "address", validAddresses[0])
-		} else {
-			slog.Warn("Failed to parse source address for fallback", "device", id, "source", src.String(), "error", err)
-		}
-	}
-
-	slog.Debug("Updating device cache", "device", id, "numValidAddresses", len(validAddresses), "addresses", validAddresses)
-	c.Set(id, CacheEntry{
-		Addresses:  validAddresses,
-		when:       time.Now(),
-		found:      true,
-		instanceID: device.InstanceId,
-	})
-
-	// Log additional information if available
-	if device.Version > 0 || device.ClientName != "" {
-		deviceInfo := map[string]interface{}{
-			"device":  id.String(),
-			"addrs":   validAddresses,
-			"version": device.Version,
-			"client":  device.ClientName + " " + device.ClientVersion,
-		}
-		
-		// Add feature information if available
-		if device.Features > 0 {
-			deviceInfo["features"] = c.getFeatureNames(device.Features)
-		}
-		
-		c.evLogger.Log(events.DeviceDiscovered, deviceInfo)
-	} else {
-		// Fall back to original logging format for compatibility
-		c.evLogger.Log(events.DeviceDiscovered, map[string]interface{}{
-			"device": id.String(),
-			"addrs":  validAddresses,
-		})
-	}
-
-	return isNewDevice
+	return validAddresses
 }
 
 // getFeatureNames returns a slice of feature names for the given feature bitmask
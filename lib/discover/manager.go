@@ -291,8 +291,13 @@ func (m *manager) CommitConfiguration(_, to config.Configuration) (handled bool)
 	}
 
 	if to.Options.LocalAnnEnabled {
-		toIdentities[ipv4Identity(to.Options.LocalAnnPort)] = struct{}{}
+		if !to.Options.IPv6Only {
+			toIdentities[ipv4Identity(to.Options.LocalAnnPort)] = struct{}{}
+		}
 		toIdentities[ipv6Identity(to.Options.LocalAnnMCAddr)] = struct{}{}
+		if to.Options.LocalAnnMDNSEnabled {
+			toIdentities[mdnsIdentity(to.Options.IPv6Only)] = struct{}{}
+		}
 	}
 
 	// Add peer-assisted discovery if enabled
@@ -316,7 +321,7 @@ func (m *manager) CommitConfiguration(_, to config.Configuration) (handled bool)
 			if _, ok := m.finders[identity]; ok {
 				continue
 			}
-			gd, err := NewGlobal(srv, m.cert, m.addressLister, m.evLogger, m.registry)
+			gd, err := NewGlobal(srv, m.cert, m.addressLister, m.evLogger, m.registry, WithAnnounceJitter(time.Duration(to.Options.AnnounceJitterS)*time.Second))
 			if err != nil {
 				slog.Warn("Failed to initialize global discovery", slogutil.Error(err))
 				continue
@@ -332,7 +337,7 @@ func (m *manager) CommitConfiguration(_, to config.Configuration) (handled bool)
 	if to.Options.LocalAnnEnabled {
 		// v4 broadcasts
 		v4Identity := ipv4Identity(to.Options.LocalAnnPort)
-		if _, ok := m.finders[v4Identity]; !ok {
+		if _, ok := m.finders[v4Identity]; !ok && !to.Options.IPv6Only {
 			bcd, err := NewLocal(m.myID, fmt.Sprintf(":%d", to.Options.LocalAnnPort), m.addressLister, m.evLogger)
 			if err != nil {
 				slog.Warn("Failed to initialize IPv4 local discovery", slogutil.Error(err))
@@ -351,6 +356,17 @@ func (m *manager) CommitConfiguration(_, to config.Configuration) (handled bool)
 				m.addLocked(v6Identity, mcd, 0, 0)
 			}
 		}
+
+		// mDNS, for networks that pass nothing else
+		mdnsID := mdnsIdentity(to.Options.IPv6Only)
+		if _, ok := m.finders[mdnsID]; !ok && to.Options.LocalAnnMDNSEnabled {
+			md, err := NewMDNS(m.myID, m.addressLister, m.evLogger, to.Options.IPv6Only)
+			if err != nil {
+				slog.Warn("Failed to initialize mDNS local discovery", slogutil.Error(err))
+			} else {
+				m.addLocked(mdnsID, md, 0, 0)
+			}
+		}
 	}
 
 	// Add peer-assisted discovery if enabled
@@ -0,0 +1,452 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package discover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thejerf/suture/v4"
+	"golang.org/x/net/dns/dnsmessage"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/netutil"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/svcutil"
+)
+
+// mDNS local discovery advertises the device as a DNS-SD service, for
+// networks that pass mDNS but not our broadcasts and multicasts. The
+// service instance is named after the device ID, and its TXT record holds
+// the device ID and the addresses we listen on, like a local announcement.
+//
+//	<device ID>._syncthing._tcp.local. TXT "id=<device ID>" "addr1=tcp://0.0.0.0:22000" ...
+
+const (
+	mdnsService = "_syncthing._tcp.local."
+	mdnsTTL     = uint32(CacheLifeTime / time.Second)
+
+	// Queries are answered at most this often.
+	mdnsMinResponseInterval = time.Second
+
+	// The cache flush bit on the class of records only we publish
+	mdnsClassUnique = dnsmessage.ClassINET | 1<<15
+)
+
+var (
+	mdnsServiceName = dnsmessage.MustNewName(mdnsService)
+	mdnsGroupIPv4   = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+	mdnsGroupIPv6   = &net.UDPAddr{IP: net.ParseIP("ff02::fb"), Port: 5353}
+
+	errNoMulticastSent = errors.New("couldn't send any multicasts")
+)
+
+type mdnsClient struct {
+	*suture.Supervisor
+	*cache
+	myID     protocol.DeviceID
+	addrList AddressLister
+	evLogger events.Logger
+	instance dnsmessage.Name
+	host     dnsmessage.Name
+
+	mut  sync.Mutex
+	errs map[string]error // network -> error
+}
+
+func NewMDNS(id protocol.DeviceID, addrList AddressLister, evLogger events.Logger, ipv6Only bool) (FinderService, error) {
+	instance, err := dnsmessage.NewName(id.String() + "." + mdnsService)
+	if err != nil {
+		return nil, err
+	}
+	host, err := dnsmessage.NewName("syncthing-" + id.Short().String() + ".local.")
+	if err != nil {
+		return nil, err
+	}
+
+	c := &mdnsClient{
+		Supervisor: suture.New("mdns", svcutil.SpecWithDebugLogger()),
+		cache:      newCache(),
+		myID:       id,
+		addrList:   addrList,
+		evLogger:   evLogger,
+		instance:   instance,
+		host:       host,
+		errs:       make(map[string]error),
+	}
+	networks := []string{"udp4", "udp6"}
+	if ipv6Only {
+		networks = networks[1:]
+	}
+	for _, network := range networks {
+		c.Add(svcutil.AsService(func(ctx context.Context) error {
+			return c.serve(ctx, network)
+		}, fmt.Sprintf("%s/%s", c, network)))
+	}
+	return c, nil
+}
+
+// Lookup returns a list of addresses the device is available at.
+func (c *mdnsClient) Lookup(_ context.Context, device protocol.DeviceID) ([]string, error) {
+	if ce, ok := c.Get(device); ok && time.Since(ce.when) < CacheLifeTime {
+		return ce.Addresses, nil
+	}
+	return nil, nil
+}
+
+func (c *mdnsClient) String() string {
+	return "mDNS local"
+}
+
+func (c *mdnsClient) Error() error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	var errs []error
+	for _, network := range slices.Sorted(maps.Keys(c.errs)) {
+		errs = append(errs, c.errs[network])
+	}
+	return errors.Join(errs...)
+}
+
+func (c *mdnsClient) setError(network string, err error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if err != nil {
+		c.errs[network] = err
+	} else {
+		delete(c.errs, network)
+	}
+}
+
+func (c *mdnsClient) serve(ctx context.Context, network string) error {
+	sock, err := listenMDNS(network)
+	c.setError(network, err)
+	if err != nil {
+		return err
+	}
+	defer sock.conn.Close()
+
+	respond := make(chan struct{}, 1)
+	go c.recv(sock, respond)
+
+	// Ask who's there, and tell them about us.
+	if err := sock.send(mdnsQuery()); err != nil {
+		slog.Debug("Failed to send mDNS query", "network", network, slogutil.Error(err))
+	}
+	c.announce(sock, mdnsTTL)
+	lastAnnounce := time.Now()
+
+	ticker := time.NewTicker(BroadcastInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-respond:
+			if time.Since(lastAnnounce) < mdnsMinResponseInterval {
+				continue
+			}
+		case <-ctx.Done():
+			// Say goodbye, making others forget us right away.
+			c.announce(sock, 0)
+			return ctx.Err()
+		}
+		c.announce(sock, mdnsTTL)
+		lastAnnounce = time.Now()
+	}
+}
+
+// recv handles incoming messages until the socket is closed, signalling on
+// respond when we should announce ourselves.
+func (c *mdnsClient) recv(sock *mdnsSocket, respond chan<- struct{}) {
+	buf := make([]byte, 9000)
+	for {
+		n, src, err := sock.conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		if c.handle(buf[:n], src) {
+			select {
+			case respond <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+func (c *mdnsClient) announce(sock *mdnsSocket, ttl uint32) {
+	msg, ok, err := c.response(ttl)
+	if err != nil {
+		slog.Debug("Failed to build mDNS response", slogutil.Error(err))
+		return
+	}
+	if !ok {
+		slog.Debug("No mDNS response to send - no dialable addresses")
+		return
+	}
+	if err := sock.send(msg); err != nil {
+		slog.Debug("Failed to send mDNS response", slogutil.Error(err))
+	}
+}
+
+// response returns the message announcing our service instance, or false
+// if there is nothing useful to announce.
+func (c *mdnsClient) response(ttl uint32) ([]byte, bool, error) {
+	addrs := sanitizeRelayAddresses(filterUndialableLocal(c.addrList.AllAddresses()))
+	if len(addrs) == 0 {
+		return nil, false, nil
+	}
+
+	// Keys in a TXT record must be unique, hence the numbered addresses.
+	// The service port is that of the first one, for the benefit of
+	// other DNS-SD browsers.
+	txt := []string{"id=" + c.myID.String()}
+	var port uint16
+	for i, addr := range addrs {
+		kv := fmt.Sprintf("addr%d=%s", i+1, addr)
+		if len(kv) > 255 {
+			continue
+		}
+		txt = append(txt, kv)
+		if port == 0 {
+			if u, err := url.Parse(addr); err == nil {
+				p, _ := strconv.ParseUint(u.Port(), 10, 16)
+				port = uint16(p)
+			}
+		}
+	}
+
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{Response: true, Authoritative: true})
+	b.EnableCompression()
+	if err := b.StartAnswers(); err != nil {
+		return nil, false, err
+	}
+	shared := dnsmessage.ResourceHeader{Name: mdnsServiceName, Class: dnsmessage.ClassINET, TTL: ttl}
+	if err := b.PTRResource(shared, dnsmessage.PTRResource{PTR: c.instance}); err != nil {
+		return nil, false, err
+	}
+	unique := dnsmessage.ResourceHeader{Name: c.instance, Class: mdnsClassUnique, TTL: ttl}
+	if err := b.SRVResource(unique, dnsmessage.SRVResource{Port: port, Target: c.host}); err != nil {
+		return nil, false, err
+	}
+	if err := b.TXTResource(unique, dnsmessage.TXTResource{TXT: txt}); err != nil {
+		return nil, false, err
+	}
+	if err := b.StartAdditionals(); err != nil {
+		return nil, false, err
+	}
+	unique.Name = c.host
+	for _, ip := range mdnsHostIPs() {
+		var err error
+		if ip4 := ip.To4(); ip4 != nil {
+			err = b.AResource(unique, dnsmessage.AResource{A: [4]byte(ip4)})
+		} else {
+			err = b.AAAAResource(unique, dnsmessage.AAAAResource{AAAA: [16]byte(ip)})
+		}
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	msg, err := b.Finish()
+	return msg, err == nil, err
+}
+
+// handle processes an incoming message, returning whether we should
+// announce ourselves in response.
+func (c *mdnsClient) handle(msg []byte, src net.Addr) bool {
+	var p dnsmessage.Parser
+	hdr, err := p.Start(msg)
+	if err != nil {
+		return false
+	}
+
+	if !hdr.Response {
+		questions, err := p.AllQuestions()
+		if err != nil {
+			return false
+		}
+		for _, q := range questions {
+			if (q.Type == dnsmessage.TypePTR || q.Type == dnsmessage.TypeALL) && strings.EqualFold(q.Name.String(), mdnsService) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if err := p.SkipAllQuestions(); err != nil {
+		return false
+	}
+	records, err := p.AllAnswers()
+	if err != nil {
+		return false
+	}
+	if err := p.SkipAllAuthorities(); err == nil {
+		if additionals, err := p.AllAdditionals(); err == nil {
+			records = append(records, additionals...)
+		}
+	}
+
+	newDevice := false
+	for _, rr := range records {
+		txt, ok := rr.Body.(*dnsmessage.TXTResource)
+		if !ok || !strings.HasSuffix(strings.ToLower(rr.Header.Name.String()), "."+mdnsService) {
+			continue
+		}
+		if c.registerDevice(src, txt.TXT, rr.Header.TTL) {
+			newDevice = true
+		}
+	}
+	return newDevice
+}
+
+// registerDevice caches the addresses from the TXT record of a service
+// instance, or forgets them when it says goodbye. Returns whether the
+// device is new to us.
+func (c *mdnsClient) registerDevice(src net.Addr, txt []string, ttl uint32) bool {
+	var id protocol.DeviceID
+	var addrs []string
+	for _, kv := range txt {
+		key, value, _ := strings.Cut(kv, "=")
+		switch {
+		case key == "id":
+			var err error
+			if id, err = protocol.DeviceIDFromString(value); err != nil {
+				return false
+			}
+		case strings.HasPrefix(key, "addr"):
+			addrs = append(addrs, value)
+		}
+	}
+	if id == protocol.EmptyDeviceID || id == c.myID {
+		return false
+	}
+
+	if ttl == 0 {
+		slog.Debug("Device left according to mDNS", "device", id)
+		c.Delete(id)
+		return false
+	}
+
+	ce, existsAlready := c.Get(id)
+	isNewDevice := !existsAlready || time.Since(ce.when) > CacheLifeTime
+
+	validAddresses := resolveAnnouncedAddresses(id, src, addrs)
+	if len(validAddresses) == 0 {
+		return false
+	}
+	c.Set(id, CacheEntry{
+		Addresses: validAddresses,
+		when:      time.Now(),
+		found:     true,
+	})
+	c.evLogger.Log(events.DeviceDiscovered, map[string]interface{}{
+		"device": id.String(),
+		"addrs":  validAddresses,
+	})
+	return isNewDevice
+}
+
+func mdnsQuery() []byte {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{})
+	_ = b.StartQuestions()
+	_ = b.Question(dnsmessage.Question{Name: mdnsServiceName, Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET})
+	msg, _ := b.Finish()
+	return msg
+}
+
+// mdnsHostIPs returns the addresses of our host name, those of the
+// interfaces that can be reached from the network.
+func mdnsHostIPs() []net.IP {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	var ips []net.IP
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok || !(ipnet.IP.IsGlobalUnicast() || ipnet.IP.IsLinkLocalUnicast()) {
+			continue
+		}
+		ips = append(ips, ipnet.IP.To16())
+	}
+	return ips
+}
+
+type mdnsPacketConn interface {
+	JoinGroup(ifi *net.Interface, group net.Addr) error
+	SetMulticastInterface(ifi *net.Interface) error
+}
+
+type mdnsSocket struct {
+	conn  *net.UDPConn
+	pconn mdnsPacketConn
+	group *net.UDPAddr
+}
+
+func listenMDNS(network string) (*mdnsSocket, error) {
+	group := mdnsGroupIPv4
+	if network == "udp6" {
+		group = mdnsGroupIPv6
+	}
+	conn, err := net.ListenMulticastUDP(network, nil, group)
+	if err != nil {
+		return nil, err
+	}
+	s := &mdnsSocket{conn: conn, group: group}
+	if network == "udp6" {
+		pconn := ipv6.NewPacketConn(conn)
+		_ = pconn.SetMulticastHopLimit(255)
+		s.pconn = pconn
+	} else {
+		pconn := ipv4.NewPacketConn(conn)
+		_ = pconn.SetMulticastTTL(255)
+		s.pconn = pconn
+	}
+	return s, nil
+}
+
+// send multicasts the message on every interface that can, joining the
+// group on those that came up since last time.
+func (s *mdnsSocket) send(msg []byte) error {
+	intfs, err := netutil.Interfaces()
+	if err != nil {
+		return err
+	}
+	sent := 0
+	for i := range intfs {
+		intf := &intfs[i]
+		if intf.Flags&net.FlagRunning == 0 || intf.Flags&net.FlagMulticast == 0 {
+			continue
+		}
+		// Fails when already joined, which is fine.
+		_ = s.pconn.JoinGroup(intf, s.group)
+		if err := s.pconn.SetMulticastInterface(intf); err != nil {
+			continue
+		}
+		if _, err := s.conn.WriteToUDP(msg, s.group); err != nil {
+			slog.Debug("mDNS write error", "interface", intf.Name, slogutil.Error(err))
+			continue
+		}
+		sent++
+	}
+	if sent == 0 {
+		return errNoMulticastSent
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package discover
+
+import (
+	"context"
+	"net"
+	"slices"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestMDNSAnnounce(t *testing.T) {
+	newClient := func(id protocol.DeviceID) *mdnsClient {
+		c, err := NewMDNS(id, &fakeAddressLister{}, events.NoopLogger, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c.(*mdnsClient)
+	}
+	a := newClient(protocol.DeviceID{1, 2, 3})
+	b := newClient(protocol.DeviceID{4, 5, 6})
+	src := &net.UDPAddr{IP: []byte{10, 20, 30, 40}, Port: 5353}
+
+	// Queries for the service are answered.
+	if !b.handle(mdnsQuery(), src) {
+		t.Error("query for the service not answered")
+	}
+
+	msg, ok, err := a.response(mdnsTTL)
+	if err != nil || !ok {
+		t.Fatal("no response", err)
+	}
+	if !b.handle(msg, src) {
+		t.Error("announcement from a new device should make us respond")
+	}
+	addrs, err := b.Lookup(context.Background(), a.myID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"tcp://10.20.30.40:22000", "tcp://192.168.0.1:22000"}
+	if !slices.Equal(addrs, expected) {
+		t.Errorf("got addresses %v, expected %v", addrs, expected)
+	}
+	if b.handle(msg, src) {
+		t.Error("announcement from a known device shouldn't make us respond")
+	}
+
+	// Our own announcements are ignored.
+	a.handle(msg, src)
+	if _, ok := a.Get(a.myID); ok {
+		t.Error("registered ourselves")
+	}
+
+	// Goodbye.
+	msg, _, err = a.response(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.handle(msg, src)
+	if addrs, _ := b.Lookup(context.Background(), a.myID); len(addrs) != 0 {
+		t.Errorf("device still known after goodbye: %v", addrs)
+	}
+}
@@ -58,6 +58,25 @@ const (
 	LoginAttempt
 	Failure
 	FolderHealthChanged
+	FolderIdle
+	DeviceIdentityChanged
+	DeviceCertificateRejected
+	DeviceBlockCorruption
+	ConnectionBandwidthChanged
+	FolderReadOnly
+	ConflictResolved
+	ControlMessageReceived
+	ChangeAnomalyDetected
+	ConfigSyncPending
+	PullStalled
+	TempFilesCleaned
+	OwnershipViolation
+	LocalBlockCorruption
+	DeviceSecurityAnomaly
+	DeviceErrorBudgetExhausted
+	ConfigDriftDetected
+	FolderHandoverProgress
+	DeviceRevocationProgress
 
 	AllEvents = (1 << iota) - 1
 )
@@ -137,6 +156,44 @@ func (t EventType) String() string {
 		return "Failure"
 	case FolderHealthChanged:
 		return "FolderHealthChanged"
+	case FolderIdle:
+		return "FolderIdle"
+	case DeviceIdentityChanged:
+		return "DeviceIdentityChanged"
+	case DeviceCertificateRejected:
+		return "DeviceCertificateRejected"
+	case DeviceBlockCorruption:
+		return "DeviceBlockCorruption"
+	case ConnectionBandwidthChanged:
+		return "ConnectionBandwidthChanged"
+	case FolderReadOnly:
+		return "FolderReadOnly"
+	case ConflictResolved:
+		return "ConflictResolved"
+	case ControlMessageReceived:
+		return "ControlMessageReceived"
+	case ChangeAnomalyDetected:
+		return "ChangeAnomalyDetected"
+	case ConfigSyncPending:
+		return "ConfigSyncPending"
+	case PullStalled:
+		return "PullStalled"
+	case TempFilesCleaned:
+		return "TempFilesCleaned"
+	case OwnershipViolation:
+		return "OwnershipViolation"
+	case LocalBlockCorruption:
+		return "LocalBlockCorruption"
+	case DeviceSecurityAnomaly:
+		return "DeviceSecurityAnomaly"
+	case DeviceErrorBudgetExhausted:
+		return "DeviceErrorBudgetExhausted"
+	case ConfigDriftDetected:
+		return "ConfigDriftDetected"
+	case FolderHandoverProgress:
+		return "FolderHandoverProgress"
+	case DeviceRevocationProgress:
+		return "DeviceRevocationProgress"
 	default:
 		return "Unknown"
 	}
@@ -226,6 +283,44 @@ func UnmarshalEventType(s string) EventType {
 		return Failure
 	case "FolderHealthChanged":
 		return FolderHealthChanged
+	case "FolderIdle":
+		return FolderIdle
+	case "DeviceIdentityChanged":
+		return DeviceIdentityChanged
+	case "DeviceCertificateRejected":
+		return DeviceCertificateRejected
+	case "DeviceBlockCorruption":
+		return DeviceBlockCorruption
+	case "ConnectionBandwidthChanged":
+		return ConnectionBandwidthChanged
+	case "FolderReadOnly":
+		return FolderReadOnly
+	case "ConflictResolved":
+		return ConflictResolved
+	case "ControlMessageReceived":
+		return ControlMessageReceived
+	case "ChangeAnomalyDetected":
+		return ChangeAnomalyDetected
+	case "ConfigSyncPending":
+		return ConfigSyncPending
+	case "PullStalled":
+		return PullStalled
+	case "TempFilesCleaned":
+		return TempFilesCleaned
+	case "OwnershipViolation":
+		return OwnershipViolation
+	case "LocalBlockCorruption":
+		return LocalBlockCorruption
+	case "DeviceSecurityAnomaly":
+		return DeviceSecurityAnomaly
+	case "DeviceErrorBudgetExhausted":
+		return DeviceErrorBudgetExhausted
+	case "ConfigDriftDetected":
+		return ConfigDriftDetected
+	case "FolderHandoverProgress":
+		return FolderHandoverProgress
+	case "DeviceRevocationProgress":
+		return DeviceRevocationProgress
 	default:
 		return 0
 	}
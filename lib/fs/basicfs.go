@@ -47,11 +47,12 @@ func (*OptionJunctionsAsDirs) String() string {
 // The BasicFilesystem implements all aspects by delegating to package os.
 // All paths are relative to the root and cannot (should not) escape the root directory.
 type BasicFilesystem struct {
-	root            string
-	junctionsAsDirs bool
-	options         []Option
-	userCache       *userCache
-	groupCache      *groupCache
+	root               string
+	junctionsAsDirs    bool
+	hybridPollInterval time.Duration
+	options            []Option
+	userCache          *userCache
+	groupCache         *groupCache
 }
 
 type (
@@ -209,6 +210,18 @@ func (f *BasicFilesystem) Rename(oldpath, newpath string) error {
 	return os.Rename(oldpath, newpath)
 }
 
+func (f *BasicFilesystem) CreateHardLink(target, name string) error {
+	target, err := f.rooted(target)
+	if err != nil {
+		return err
+	}
+	name, err = f.rooted(name)
+	if err != nil {
+		return err
+	}
+	return os.Link(target, name)
+}
+
 func (f *BasicFilesystem) Stat(name string) (FileInfo, error) {
 	name, err := f.rooted(name)
 	if err != nil {
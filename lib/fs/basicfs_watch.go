@@ -139,7 +139,10 @@ func (ot *overflowTracker) shouldDecreaseBuffer(lastEvent time.Time) bool {
 func (ot *overflowTracker) getSystemPressure() float64 {
 	ot.mu.Lock()
 	defer ot.mu.Unlock()
+	return ot.systemPressureLocked()
+}
 
+func (ot *overflowTracker) systemPressureLocked() float64 {
 	// Calculate pressure based on overflow rate, buffer utilization, and consecutive overflows
 	ratePressure := ot.overflowRate / 10.0 // Normalize to 0-1 range (assuming 10 overflows/min is high)
 	bufferPressure := float64(ot.adaptiveBuffer-ot.minBufferSize) / float64(ot.maxBufferSize-ot.minBufferSize)
@@ -165,7 +168,7 @@ func (ot *overflowTracker) increaseBuffer() int {
 	defer ot.mu.Unlock()
 
 	// Get adaptive resize factor based on system pressure
-	factor := ot.getAdaptiveResizeFactor()
+	factor := ot.adaptiveResizeFactorLocked()
 
 	// Increase buffer by the adaptive factor
 	ot.adaptiveBuffer = int(float64(ot.adaptiveBuffer) * factor)
@@ -198,7 +201,10 @@ func (ot *overflowTracker) decreaseBuffer() int {
 func (ot *overflowTracker) getOptimalBufferSize(fileCount int) int {
 	ot.mu.Lock()
 	defer ot.mu.Unlock()
+	return ot.optimalBufferSizeLocked(fileCount)
+}
 
+func (ot *overflowTracker) optimalBufferSizeLocked(fileCount int) int {
 	// Calculate buffer size based on file count with logarithmic scaling
 	// This prevents extremely large buffers for huge folders
 	optimalSize := int(float64(ot.minBufferSize) * (1 + (float64(fileCount) / 1000.0)))
@@ -225,10 +231,10 @@ func (ot *overflowTracker) updateBufferSizeBasedOnResources(fileCount int) int {
 	defer ot.mu.Unlock()
 
 	// Get the optimal buffer size for this folder
-	optimalSize := ot.getOptimalBufferSize(fileCount)
+	optimalSize := ot.optimalBufferSizeLocked(fileCount)
 
 	// Get current system pressure
-	pressure := ot.getSystemPressure()
+	pressure := ot.systemPressureLocked()
 
 	// Adjust buffer size based on pressure
 	if pressure > 0.7 {
@@ -257,8 +263,11 @@ func (ot *overflowTracker) updateBufferSizeBasedOnResources(fileCount int) int {
 func (ot *overflowTracker) getAdaptiveResizeFactor() float64 {
 	ot.mu.Lock()
 	defer ot.mu.Unlock()
+	return ot.adaptiveResizeFactorLocked()
+}
 
-	pressure := ot.getSystemPressure()
+func (ot *overflowTracker) adaptiveResizeFactorLocked() float64 {
+	pressure := ot.systemPressureLocked()
 
 	// Return different factors based on pressure
 	if pressure > 0.8 {
@@ -361,6 +370,18 @@ func (wm *watchMetrics) getMetrics() (eventsProcessed, eventsDropped, overflows
 	return wm.eventsProcessed, wm.eventsDropped, wm.overflows, uptime, timeSinceLastEvent
 }
 
+// stats returns the counts as exposed through GetWatchStats
+func (wm *watchMetrics) stats() WatchStats {
+	wm.mu.Lock()
+	defer wm.mu.Unlock()
+	return WatchStats{
+		Started:   wm.startTime,
+		Processed: wm.eventsProcessed,
+		Dropped:   wm.eventsDropped,
+		Overflows: wm.overflows,
+	}
+}
+
 // logMetrics periodically logs metrics for monitoring
 func (wm *watchMetrics) logMetrics(fs *BasicFilesystem, name string) {
 	ticker := time.NewTicker(5 * time.Minute)
@@ -411,7 +432,42 @@ func checkLargeFolder(fs *BasicFilesystem, name string) {
 	}
 }
 
+// nativeWatch is the watcher of the platform, used rather than notify
+// where there is one, see basicfs_watch_darwin.go. Notify is still used
+// when it fails to start.
+var nativeWatch func(f *BasicFilesystem, name string, ignore Matcher, ctx context.Context, ignorePerms bool) (<-chan Event, <-chan error, error)
+
 func (f *BasicFilesystem) Watch(name string, ignore Matcher, ctx context.Context, ignorePerms bool) (<-chan Event, <-chan error, error) {
+	var outChan <-chan Event
+	var errChan <-chan error
+	var err error
+	if nativeWatch != nil {
+		outChan, errChan, err = nativeWatch(f, name, ignore, ctx, ignorePerms)
+		if err != nil {
+			l.Debugln(f.Type(), f.URI(), "Watch: Native watcher failed, using notify:", err)
+			outChan = nil
+		}
+	}
+	if outChan == nil {
+		outChan, errChan, err = f.watchNotify(name, ignore, ctx, ignorePerms)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if f.hybridPollInterval > 0 {
+		// Network filesystems may silently drop kernel events, complement
+		// them by polling the directories that have recently seen activity.
+		hybridChan := make(chan Event)
+		go newHybridPoller(f, name, ignore, f.hybridPollInterval).run(ctx, outChan, hybridChan)
+		l.Debugln(f.Type(), f.URI(), "Watch: Hybrid polling enabled with interval", f.hybridPollInterval)
+		return hybridChan, errChan, nil
+	}
+
+	return outChan, errChan, nil
+}
+
+func (f *BasicFilesystem) watchNotify(name string, ignore Matcher, ctx context.Context, ignorePerms bool) (<-chan Event, <-chan error, error) {
 	watchPath, roots, err := f.watchPaths(name)
 	if err != nil {
 		return nil, nil, err
@@ -485,6 +541,7 @@ func (f *BasicFilesystem) watchLoop(ctx context.Context, name string, roots []st
 	// Initialize metrics tracking
 	metrics := newWatchMetrics()
 	metrics.logMetrics(f, name) // Start periodic logging
+	defer registerWatchStats(f, name, metrics.stats)()
 
 	for {
 		// Detect channel overflow
@@ -0,0 +1,65 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at http://mozilla.org/MPL/2.0/.
+
+//go:build darwin && cgo && !kqueue && !ios
+// +build darwin,cgo,!kqueue,!ios
+
+#include <stdint.h>
+#include <CoreServices/CoreServices.h>
+#include <dispatch/dispatch.h>
+
+#include "_cgo_export.h"
+
+// All streams deliver their events on one serial queue.
+static dispatch_queue_t queue;
+static dispatch_once_t queueOnce;
+
+static void createQueue(void *ctx) {
+	queue = dispatch_queue_create("net.syncthing.fsevents", DISPATCH_QUEUE_SERIAL);
+}
+
+static dispatch_queue_t fseventsQueue(void) {
+	dispatch_once_f(&queueOnce, NULL, createQueue);
+	return queue;
+}
+
+static void streamCallback(ConstFSEventStreamRef stream, void *info, size_t n, void *paths, const FSEventStreamEventFlags flags[], const FSEventStreamEventId ids[]) {
+	fseventsCallback((uintptr_t)info, n, (char **)paths, (uint32_t *)flags);
+}
+
+FSEventStreamRef fseventsStart(uintptr_t handle, const char *path, double latency) {
+	CFStringRef cfPath = CFStringCreateWithCString(NULL, path, kCFStringEncodingUTF8);
+	if (cfPath == NULL) {
+		return NULL;
+	}
+	CFArrayRef paths = CFArrayCreate(NULL, (const void **)&cfPath, 1, &kCFTypeArrayCallBacks);
+	FSEventStreamContext ctx = {0, (void *)handle, NULL, NULL, NULL};
+	FSEventStreamRef stream = FSEventStreamCreate(NULL, streamCallback, &ctx, paths, kFSEventStreamEventIdSinceNow, latency,
+		kFSEventStreamCreateFlagFileEvents | kFSEventStreamCreateFlagNoDefer | kFSEventStreamCreateFlagWatchRoot);
+	CFRelease(paths);
+	CFRelease(cfPath);
+	if (stream == NULL) {
+		return NULL;
+	}
+
+	FSEventStreamSetDispatchQueue(stream, fseventsQueue());
+	if (!FSEventStreamStart(stream)) {
+		FSEventStreamInvalidate(stream);
+		FSEventStreamRelease(stream);
+		return NULL;
+	}
+	return stream;
+}
+
+static void noop(void *ctx) {}
+
+void fseventsStop(FSEventStreamRef stream) {
+	FSEventStreamStop(stream);
+	FSEventStreamInvalidate(stream);
+	FSEventStreamRelease(stream);
+	// Wait for callbacks already on the queue, which use the handle.
+	dispatch_sync_f(fseventsQueue(), NULL, noop);
+}
@@ -4,281 +4,302 @@
 // License, v. 2.0. If a copy of the MPL was not distributed with this file,
 // You can obtain one at http://mozilla.org/MPL/2.0/.
 
-//go:build darwin
-// +build darwin
+//go:build darwin && cgo && !kqueue && !ios
+// +build darwin,cgo,!kqueue,!ios
 
 package fs
 
+/*
+#cgo LDFLAGS: -framework CoreServices
+#include <stdint.h>
+#include <stdlib.h>
+#include <CoreServices/CoreServices.h>
+
+FSEventStreamRef fseventsStart(uintptr_t handle, const char *path, double latency);
+void fseventsStop(FSEventStreamRef stream);
+*/
+import "C"
+
 import (
 	"context"
-	"log"
-	"runtime"
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime/cgo"
+	"slices"
 	"sync"
 	"time"
 	"unicode/utf8"
-
-	"github.com/shirou/gopsutil/v4/mem"
-	"github.com/syncthing/notify"
+	"unsafe"
 )
 
-// darwinWatcher implements a macOS-specific watcher using FSEvents
-type darwinWatcher struct {
-	fs              *BasicFilesystem
-	ctx             context.Context
-	cancel          context.CancelFunc
-	backendChan     chan notify.EventInfo
-	outChan         chan<- Event
-	errChan         chan<- error
-	ignore          Matcher
-	roots           []string
-	bufferSize      int
-	fileCount       int
-	folderName      string
-	overflowTracker *overflowTracker
-	watchMetrics    *watchMetrics
-
-	mut sync.Mutex
-}
+// The watcher on macOS uses an FSEvents stream of its own, with file level
+// events, rather than going through notify. A stream watches the whole
+// tree below the folder without any per directory setup, so it starts
+// right away however large the folder is.
+//
+// When events are lost, be it by FSEvents or because the events queued
+// here reach the adaptive limit, only the directories they were in are
+// scanned again, as long as they are few enough, instead of the whole
+// folder.
+
+const (
+	// FSEvents delivers the events of this long at once.
+	fseventsLatency = 100 * time.Millisecond
+
+	// Beyond this many directories with lost events the whole folder is
+	// scanned.
+	fseventsMaxDroppedDirs = 64
+
+	fseventsKindFlags = C.kFSEventStreamEventFlagItemIsFile | C.kFSEventStreamEventFlagItemIsDir | C.kFSEventStreamEventFlagItemIsSymlink |
+		C.kFSEventStreamEventFlagItemIsHardlink | C.kFSEventStreamEventFlagItemIsLastHardlink
+	fseventsDroppedFlags = C.kFSEventStreamEventFlagMustScanSubDirs | C.kFSEventStreamEventFlagUserDropped | C.kFSEventStreamEventFlagKernelDropped
+	fseventsSkipFlags    = C.kFSEventStreamEventFlagHistoryDone | C.kFSEventStreamEventFlagEventIdsWrapped
+	fseventsRemoveFlags  = C.kFSEventStreamEventFlagItemRemoved | C.kFSEventStreamEventFlagItemRenamed
+)
 
-// newDarwinWatcher creates a new macOS watcher
-func newDarwinWatcher(fs *BasicFilesystem, name string, ignore Matcher, ctx context.Context, ignorePerms bool, fileCount int) (*darwinWatcher, error) {
-	// Count files in the folder to determine optimal buffer size
-	// fileCount is already provided as a parameter
+var errFSEventsRootChanged = errors.New("the watched folder was moved or removed")
 
-	// Use platform-specific buffer size
-	bufferSize := getDarwinOptimalBufferSize(fileCount)
+func init() {
+	nativeWatch = watchFSEvents
+}
 
-	// Initialize overflow tracking for adaptive buffer management
-	overflowTracker := newOverflowTracker()
+type fseventsEvent struct {
+	path  string
+	flags uint32
+}
 
-	// Adjust buffer size based on system resources and folder characteristics
-	bufferSize = overflowTracker.getOptimalBufferSize(fileCount)
+type fseventsWatcher struct {
+	fs          *BasicFilesystem
+	name        string
+	roots       []string
+	ignore      Matcher
+	ignorePerms bool
+	overflow    *overflowTracker
+	metrics     *watchMetrics
+	wake        chan struct{}
+
+	mut         sync.Mutex
+	queue       []fseventsEvent
+	overflowing bool
+	dropped     map[string]struct{} // absolute directories with lost events
+	droppedAll  bool
+}
 
-	backendChan := make(chan notify.EventInfo, bufferSize)
+func watchFSEvents(f *BasicFilesystem, name string, ignore Matcher, ctx context.Context, ignorePerms bool) (<-chan Event, <-chan error, error) {
+	watchPath, roots, err := f.watchPaths(name)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// Use platform-specific event mask
-	eventMask := notify.All
-	if ignorePerms {
-		eventMask &^= notify.Write
+	w := &fseventsWatcher{
+		fs:          f,
+		name:        name,
+		roots:       roots,
+		ignore:      ignore,
+		ignorePerms: ignorePerms,
+		overflow:    newOverflowTrackerWithConfig(backendBuffer, 20*backendBuffer, backendBuffer),
+		metrics:     newWatchMetrics(),
+		wake:        make(chan struct{}, 1),
 	}
 
-	watchPath, roots, err := fs.watchPaths(name)
-	if err != nil {
-		return nil, err
+	handle := cgo.NewHandle(w)
+	path := C.CString(filepath.Dir(watchPath)) // without the trailing "..."
+	defer C.free(unsafe.Pointer(path))
+	stream := C.fseventsStart(C.uintptr_t(handle), path, C.double(fseventsLatency.Seconds()))
+	if stream == nil {
+		handle.Delete()
+		return nil, nil, errors.New("failed to start FSEvents stream")
 	}
 
-	absShouldIgnore := func(absPath string) bool {
-		if !utf8.ValidString(absPath) {
-			return true
-		}
+	outChan := make(chan Event)
+	errChan := make(chan error)
+	go func() {
+		defer handle.Delete()
+		defer C.fseventsStop(stream)
+		w.loop(ctx, outChan, errChan)
+	}()
 
-		rel, err := fs.unrootedChecked(absPath, roots)
-		if err != nil {
-			return true
-		}
-		return ignore.Match(rel).CanSkipDir()
-	}
+	return outChan, errChan, nil
+}
 
-	err = notify.WatchWithFilter(watchPath, backendChan, absShouldIgnore, eventMask)
-	if err != nil {
-		notify.Stop(backendChan)
-		return nil, err
+//export fseventsCallback
+func fseventsCallback(handle C.uintptr_t, n C.size_t, paths **C.char, flags *C.uint32_t) {
+	w := cgo.Handle(handle).Value().(*fseventsWatcher)
+	cpaths := unsafe.Slice(paths, int(n))
+	cflags := unsafe.Slice(flags, int(n))
+	for i := range cpaths {
+		w.push(C.GoString(cpaths[i]), uint32(cflags[i]))
 	}
+}
 
-	// Create context with cancel
-	ctx, cancel := context.WithCancel(ctx)
-
-	w := &darwinWatcher{
-		fs:              fs,
-		ctx:             ctx,
-		cancel:          cancel,
-		backendChan:     backendChan,
-		outChan:         make(chan Event),
-		errChan:         make(chan error),
-		ignore:          ignore,
-		roots:           roots,
-		bufferSize:      bufferSize,
-		fileCount:       fileCount,
-		folderName:      name,
-		overflowTracker: overflowTracker,
-		watchMetrics:    newWatchMetrics(),
+// push queues an event, called on the FSEvents dispatch queue.
+func (w *fseventsWatcher) push(path string, flags uint32) {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+
+	switch {
+	case flags&fseventsSkipFlags != 0:
+		return
+	case flags&fseventsDroppedFlags != 0:
+		// FSEvents lost events below the path
+		w.metrics.recordOverflow()
+		w.dropLocked(path)
+	case len(w.queue) >= w.overflow.getBufferSize():
+		if !w.overflowing {
+			w.overflowing = true
+			w.overflow.recordOverflow()
+			w.metrics.recordOverflow()
+			if w.overflow.shouldIncreaseBuffer() {
+				newSize := w.overflow.increaseBuffer()
+				l.Debugln(w.fs.Type(), w.fs.URI(), "Watch: Increasing adaptive buffer size to", newSize, "due to frequent overflows")
+			}
+		}
+		w.metrics.recordDroppedEvent()
+		w.dropLocked(filepath.Dir(path))
+	default:
+		w.queue = append(w.queue, fseventsEvent{path: path, flags: flags})
 	}
 
-	// Apply macOS-specific optimizations
-	w.optimizeForDarwin()
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
 
-	return w, nil
+func (w *fseventsWatcher) dropLocked(dir string) {
+	if w.droppedAll {
+		return
+	}
+	if w.dropped == nil {
+		w.dropped = make(map[string]struct{})
+	}
+	w.dropped[dir] = struct{}{}
+	if len(w.dropped) > fseventsMaxDroppedDirs {
+		w.droppedAll = true
+		w.dropped = nil
+	}
 }
 
-// watchLoop runs the main event loop for the macOS watcher
-func (w *darwinWatcher) watchLoop() {
-	// Initialize metrics tracking
-	w.watchMetrics.logMetrics(w.fs, w.folderName) // Start periodic logging
+// take returns the queued events and lost directories.
+func (w *fseventsWatcher) take() ([]fseventsEvent, []string, bool) {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+
+	events := w.queue
+	w.queue = nil
+	dropped := slices.Sorted(maps.Keys(w.dropped))
+	droppedAll := w.droppedAll
+	w.dropped = nil
+	w.droppedAll = false
+	if w.overflowing {
+		w.overflowing = false
+	} else {
+		w.overflow.resetConsecutiveOverflows()
+	}
+	return events, dropped, droppedAll
+}
 
-	// Start Prometheus metrics updates
-	metricsUpdateTicker := time.NewTicker(1 * time.Minute)
-	go func() {
-		for range metricsUpdateTicker.C {
-			w.watchMetrics.updatePrometheusMetrics(w.fs, w.overflowTracker)
+func (w *fseventsWatcher) loop(ctx context.Context, outChan chan<- Event, errChan chan<- error) {
+	send := func(ev Event) bool {
+		select {
+		case outChan <- ev:
+			w.metrics.recordEvent()
+			l.Debugln(w.fs.Type(), w.fs.URI(), "Watch: Sending", ev.Name, ev.Type)
+			return true
+		case <-ctx.Done():
+			return false
 		}
-	}()
-	defer metricsUpdateTicker.Stop()
-
-	lastProcessedEvent := time.Now()
+	}
 
-	// Periodically re-evaluate buffer size based on system resources
-	resourceCheckTicker := time.NewTicker(10 * time.Minute)
-	defer resourceCheckTicker.Stop()
+	defer registerWatchStats(w.fs, w.name, w.metrics.stats)()
 
+	lastEvent := time.Now()
 	for {
-		// Detect channel overflow
-		if len(w.backendChan) == cap(w.backendChan) {
-		outer:
-			for {
-				select {
-				case <-w.backendChan:
-					w.watchMetrics.recordDroppedEvent() // Record dropped events
-				default:
-					break outer
-				}
-			}
-			// Record the overflow for adaptive management
-			w.overflowTracker.recordOverflow()
-			w.watchMetrics.recordOverflow() // Record for metrics
-
-			// When next scheduling a scan, do it on the entire folder as events have been lost.
-			w.outChan <- Event{Name: w.folderName, Type: NonRemove}
-			log.Println(w.fs.Type(), w.fs.URI(), "Watch: Event overflow, send \".\"")
-			// Log a warning when buffer overflows to help with debugging
-			log.Println(w.fs.Type(), w.fs.URI(), "Watch: Event buffer overflow detected. Consider increasing buffer size or reducing file change frequency.")
-
-			// Check if we should increase the buffer size based on overflow patterns
-			if w.overflowTracker.shouldIncreaseBuffer() {
-				newSize := w.overflowTracker.increaseBuffer()
-				metricBufferResizes.WithLabelValues(w.fs.URI()).Inc()
-				log.Println(w.fs.Type(), w.fs.URI(), "Watch: Increasing adaptive buffer size to", newSize, "due to frequent overflows")
-			}
+		select {
+		case <-w.wake:
+		case <-ctx.Done():
+			eventsProcessed, eventsDropped, overflows, _, _ := w.metrics.getMetrics()
+			l.Debugln(w.fs.Type(), w.fs.URI(), "Watch: Stopped. Final metrics - Processed:", eventsProcessed,
+				"Dropped:", eventsDropped, "Overflows:", overflows)
+			return
 		}
 
-		// Check if we should decrease the buffer size based on low activity
-		if w.overflowTracker.shouldDecreaseBuffer(lastProcessedEvent) {
-			newSize := w.overflowTracker.decreaseBuffer()
-			metricBufferResizes.WithLabelValues(w.fs.URI()).Inc()
-			log.Println(w.fs.Type(), w.fs.URI(), "Watch: Decreasing adaptive buffer size to", newSize, "due to low activity")
+		if w.overflow.shouldDecreaseBuffer(lastEvent) {
+			newSize := w.overflow.decreaseBuffer()
+			l.Debugln(w.fs.Type(), w.fs.URI(), "Watch: Decreasing adaptive buffer size to", newSize, "due to low activity")
 		}
+		lastEvent = time.Now()
 
-		select {
-		case <-resourceCheckTicker.C:
-			// Periodically re-evaluate buffer size based on system resources
-			newSize := w.overflowTracker.updateBufferSizeBasedOnResources(w.fileCount)
-			if newSize != cap(w.backendChan) {
-				metricBufferResizes.WithLabelValues(w.fs.URI()).Inc()
-				log.Println(w.fs.Type(), w.fs.URI(), "Watch: Adjusted buffer size to", newSize, "based on system resources")
+		events, dropped, droppedAll := w.take()
+
+		if droppedAll {
+			l.Debugln(w.fs.Type(), w.fs.URI(), "Watch: Events lost in many directories, send \".\"")
+			dropped = nil
+			if !send(Event{Name: w.name, Type: NonRemove}) {
+				return
 			}
+		}
+		for _, dir := range dropped {
+			relPath, err := w.fs.unrootedChecked(dir, w.roots)
+			if err != nil {
+				relPath = w.name
+			}
+			if w.ignore.Match(relPath).IsIgnored() {
+				continue
+			}
+			l.Debugln(w.fs.Type(), w.fs.URI(), "Watch: Events lost in", relPath)
+			if !send(Event{Name: relPath, Type: NonRemove}) {
+				return
+			}
+		}
 
-		case ev := <-w.backendChan:
-			evPath := ev.Path()
-			lastProcessedEvent = time.Now()
+		for _, ev := range events {
+			if ev.flags&C.kFSEventStreamEventFlagRootChanged != 0 {
+				select {
+				case errChan <- errFSEventsRootChanged:
+					l.Debugln(w.fs.Type(), w.fs.URI(), "Watch: Sending error", errFSEventsRootChanged)
+				case <-ctx.Done():
+				}
+				return
+			}
 
-			if !utf8.ValidString(evPath) {
-				log.Println(w.fs.Type(), w.fs.URI(), "Watch: Ignoring invalid UTF-8")
+			if !utf8.ValidString(ev.path) {
+				l.Debugln(w.fs.Type(), w.fs.URI(), "Watch: Ignoring invalid UTF-8")
 				continue
 			}
 
-			relPath, err := w.fs.unrootedChecked(evPath, w.roots)
+			relPath, err := w.fs.unrootedChecked(ev.path, w.roots)
 			if err != nil {
 				select {
-				case w.errChan <- err:
-					log.Println(w.fs.Type(), w.fs.URI(), "Watch: Sending error", err)
-				case <-w.ctx.Done():
+				case errChan <- err:
+					l.Debugln(w.fs.Type(), w.fs.URI(), "Watch: Sending error", err)
+				case <-ctx.Done():
 				}
-				notify.Stop(w.backendChan)
-				log.Println(w.fs.Type(), w.fs.URI(), "Watch: Stopped due to", err)
+				l.Debugln(w.fs.Type(), w.fs.URI(), "Watch: Stopped due to", err)
 				return
 			}
 
 			if w.ignore.Match(relPath).IsIgnored() {
-				log.Println(w.fs.Type(), w.fs.URI(), "Watch: Ignoring", relPath)
+				l.Debugln(w.fs.Type(), w.fs.URI(), "Watch: Ignoring", relPath)
 				continue
 			}
-			evType := w.fs.eventType(ev.Event())
-			select {
-			case w.outChan <- Event{Name: relPath, Type: evType}:
-				w.watchMetrics.recordEvent() // Record processed event
-				log.Println(w.fs.Type(), w.fs.URI(), "Watch: Sending", relPath, evType)
-			case <-w.ctx.Done():
-				notify.Stop(w.backendChan)
-				log.Println(w.fs.Type(), w.fs.URI(), "Watch: Stopped")
-				return
+			if w.ignorePerms && ev.flags&^fseventsKindFlags == C.kFSEventStreamEventFlagItemChangeOwner {
+				continue
 			}
-		case <-w.ctx.Done():
-			notify.Stop(w.backendChan)
-			// Log final metrics when stopping
-			w.watchMetrics.getMetrics()
-			log.Println(w.fs.Type(), w.fs.URI(), "Watch: Stopped. Final metrics")
-			return
-		}
-	}
-}
-
-// updatePrometheusMetrics periodically updates Prometheus metrics
-func (w *darwinWatcher) updatePrometheusMetrics() {
-	ticker := time.NewTicker(1 * time.Minute)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-ticker.C:
-			w.watchMetrics.updatePrometheusMetrics(w.fs, w.overflowTracker)
-		case <-w.ctx.Done():
-			return
-		}
-	}
-}
 
-// getDarwinOptimalBufferSize returns the optimal buffer size for macOS
-func getDarwinOptimalBufferSize(fileCount int) int {
-	// macOS FSEvents benefits from larger buffers for large directories
-	baseSize := 1500
-
-	// Adjust based on folder size
-	if fileCount > 50000 {
-		// Very large folder
-		return baseSize * 4
-	} else if fileCount > 10000 {
-		// Large folder
-		return baseSize * 2
-	} else if fileCount < 1000 {
-		// Small folder
-		return baseSize / 2
-	}
-
-	return baseSize
-}
-
-// macOS-specific optimizations for FSEvents
-func (w *darwinWatcher) optimizeForDarwin() {
-	// macOS-specific optimizations can be added here
-	// For example, adjusting buffer sizes based on macOS performance characteristics
-
-	// Get system memory information
-	memInfo, err := mem.VirtualMemory()
-	if err == nil {
-		// If system has plenty of memory, we can use larger buffers
-		if memInfo.Available > 2*1024*1024*1024 { // More than 2GB available
-			w.bufferSize = int(float64(w.bufferSize) * 1.5)
-		} else if memInfo.Available < 512*1024*1024 { // Less than 512MB available
-			w.bufferSize = int(float64(w.bufferSize) * 0.75)
+			// Events of a path are coalesced, so a removed or renamed
+			// item may well be back by now.
+			evType := NonRemove
+			if ev.flags&fseventsRemoveFlags != 0 {
+				if _, err := os.Lstat(ev.path); errors.Is(err, os.ErrNotExist) {
+					evType = Remove
+				}
+			}
+			if !send(Event{Name: relPath, Type: evType}) {
+				return
+			}
 		}
 	}
-
-	// Adjust based on number of CPU cores
-	numCPU := runtime.NumCPU()
-	if numCPU > 8 {
-		// High core count systems can handle more events
-		w.bufferSize = int(float64(w.bufferSize) * 1.2)
-	} else if numCPU < 4 {
-		// Low core count systems need smaller buffers to avoid overwhelming
-		w.bufferSize = int(float64(w.bufferSize) * 0.8)
-	}
 }
@@ -0,0 +1,257 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/syncthing/syncthing/lib/build"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Names and paths are probed up to these lengths; longer ones may or may
+// not work.
+const (
+	maxProbedNameLength = 1024
+	maxProbedPathLength = 4096
+)
+
+var (
+	ErrNameTooLong = errors.New("file name too long for the filesystem")
+	ErrPathTooLong = errors.New("path too long for the filesystem")
+)
+
+// Capabilities are what a filesystem was found to support by
+// ProbeCapabilities.
+type Capabilities struct {
+	Symlinks      bool `json:"symlinks"`
+	Xattrs        bool `json:"xattrs"`
+	HardLinks     bool `json:"hardLinks"`
+	SparseFiles   bool `json:"sparseFiles"` // false also where the platform doesn't tell
+	CaseSensitive bool `json:"caseSensitive"`
+	// The longest file name and relative path that could be created, in
+	// characters as the filesystem counts them, up to the probed maximum
+	MaxNameLength int `json:"maxNameLength"`
+	MaxPathLength int `json:"maxPathLength"`
+	// The granularity of modification times, zero if unknown
+	TimestampResolution time.Duration `json:"timestampResolutionNs"`
+	Probed              time.Time     `json:"probed"`
+}
+
+// ProbeCapabilities finds out what the filesystem supports by trying it,
+// in a temporary directory below the root that is removed afterwards.
+func ProbeCapabilities(filesystem Filesystem) (Capabilities, error) {
+	dir := TempName("capabilities")
+	// Left over from an interrupted probe, perhaps
+	_ = filesystem.RemoveAll(dir)
+	if err := filesystem.Mkdir(dir, 0o700); err != nil {
+		return Capabilities{}, fmt.Errorf("creating probe directory: %w", err)
+	}
+	defer filesystem.RemoveAll(dir)
+	_ = filesystem.Hide(dir)
+
+	file := filepath.Join(dir, "probe")
+	if err := WriteFile(filesystem, file, []byte("syncthing"), 0o600); err != nil {
+		return Capabilities{}, fmt.Errorf("creating probe file: %w", err)
+	}
+
+	caps := Capabilities{Probed: time.Now().Truncate(time.Second)}
+	caps.Symlinks = probeSymlinks(filesystem, file)
+	caps.Xattrs = probeXattrs(filesystem, file)
+	caps.HardLinks = filesystem.CreateHardLink(file, filepath.Join(dir, "hardlink")) == nil
+	caps.SparseFiles = probeSparseFiles(filesystem, filepath.Join(dir, "sparse"))
+	caps.CaseSensitive = probeCaseSensitive(filesystem, file)
+	caps.MaxNameLength = probeMaxNameLength(filesystem, dir)
+	caps.MaxPathLength = probeMaxPathLength(filesystem, dir, caps.MaxNameLength)
+	caps.TimestampResolution = probeTimestampResolution(filesystem, file)
+
+	l.Debugf("Probed capabilities of %v: %+v", filesystem.URI(), caps)
+	return caps, nil
+}
+
+// NameLimits returns the longest file name and relative path the
+// filesystem allows, or zero where no limit was found.
+func (c Capabilities) NameLimits() (name, path int) {
+	if c.MaxNameLength < maxProbedNameLength {
+		name = c.MaxNameLength
+	}
+	if c.MaxPathLength < maxProbedPathLength {
+		path = c.MaxPathLength
+	}
+	return name, path
+}
+
+// CheckName returns an error if the name, relative to the root, is longer
+// than the filesystem was found to allow, so that such files can be
+// rejected up front.
+func (c Capabilities) CheckName(name string) error {
+	maxName, maxPath := c.NameLimits()
+	if maxPath > 0 {
+		if length := nameLength(name); length > maxPath {
+			return fmt.Errorf("%w: %d characters, at most %d are possible", ErrPathTooLong, length, maxPath)
+		}
+	}
+	if maxName > 0 {
+		for _, part := range strings.Split(name, string(PathSeparator)) {
+			if length := nameLength(part); length > maxName {
+				return fmt.Errorf("%w: %q has %d characters, at most %d are possible", ErrNameTooLong, part, length, maxName)
+			}
+		}
+	}
+	return nil
+}
+
+// nameLength is the length of the name as the filesystems of the platform
+// limit it: in UTF-16 code units on Windows and macOS, in bytes elsewhere.
+func nameLength(name string) int {
+	if build.IsWindows || build.IsDarwin {
+		return len(utf16.Encode([]rune(name)))
+	}
+	return len(name)
+}
+
+func probeSymlinks(filesystem Filesystem, file string) bool {
+	if !filesystem.SymlinksSupported() {
+		return false
+	}
+	link := filepath.Join(filepath.Dir(file), "symlink")
+	if err := filesystem.CreateSymlink(filepath.Base(file), link); err != nil {
+		return false
+	}
+	target, err := filesystem.ReadSymlink(link)
+	return err == nil && target == filepath.Base(file)
+}
+
+const probeXattrName = "user.syncthing.probe"
+
+// probeXattrFilter lets the probe attribute through.
+type probeXattrFilter struct{}
+
+func (probeXattrFilter) Permit(string) bool         { return true }
+func (probeXattrFilter) GetMaxSingleEntrySize() int { return 1024 }
+func (probeXattrFilter) GetMaxTotalSize() int       { return 64 << 10 }
+
+func probeXattrs(filesystem Filesystem, file string) bool {
+	xattrs := []protocol.Xattr{{Name: probeXattrName, Value: []byte("1")}}
+	if err := filesystem.SetXattr(file, xattrs, probeXattrFilter{}); err != nil {
+		return false
+	}
+	got, err := filesystem.GetXattr(file, probeXattrFilter{})
+	return err == nil && slices.ContainsFunc(got, func(xa protocol.Xattr) bool {
+		return xa.Name == probeXattrName
+	})
+}
+
+func probeSparseFiles(filesystem Filesystem, name string) bool {
+	const size = 16 << 20
+	fd, err := filesystem.Create(name)
+	if err != nil {
+		return false
+	}
+	_, err = fd.WriteAt([]byte{1}, size-1)
+	if err1 := fd.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return false
+	}
+	fi, err := filesystem.Lstat(name)
+	if err != nil {
+		return false
+	}
+	allocated, ok := allocatedSize(fi)
+	return ok && allocated < size/2
+}
+
+func probeCaseSensitive(filesystem Filesystem, file string) bool {
+	upper := filepath.Join(filepath.Dir(file), strings.ToUpper(filepath.Base(file)))
+	_, err := filesystem.Lstat(upper)
+	return err != nil && !IsErrCaseConflict(err)
+}
+
+// probeMaxNameLength searches for the longest name of a file that can be
+// created in dir.
+func probeMaxNameLength(filesystem Filesystem, dir string) int {
+	lo, hi := 0, maxProbedNameLength
+	for lo < hi {
+		n := (lo + hi + 1) / 2
+		if probeCreate(filesystem, filepath.Join(dir, strings.Repeat("n", n))) {
+			lo = n
+		} else {
+			hi = n - 1
+		}
+	}
+	return lo
+}
+
+// probeMaxPathLength nests directories in dir as deep as possible, then
+// searches for the longest name of a file that can be created in the
+// deepest one.
+func probeMaxPathLength(filesystem Filesystem, dir string, maxNameLength int) int {
+	if maxNameLength == 0 {
+		return 0
+	}
+	elem := strings.Repeat("p", min(maxNameLength, 100))
+	path := dir
+	for len(path)+1+len(elem) <= maxProbedPathLength {
+		next := filepath.Join(path, elem)
+		if err := filesystem.Mkdir(next, 0o700); err != nil {
+			break
+		}
+		path = next
+	}
+
+	lo, hi := 0, min(len(elem), maxProbedPathLength-len(path)-1)
+	for lo < hi {
+		n := (lo + hi + 1) / 2
+		if probeCreate(filesystem, filepath.Join(path, strings.Repeat("n", n))) {
+			lo = n
+		} else {
+			hi = n - 1
+		}
+	}
+	if lo == 0 {
+		return len(path)
+	}
+	return len(path) + 1 + lo
+}
+
+func probeCreate(filesystem Filesystem, name string) bool {
+	fd, err := filesystem.Create(name)
+	if err != nil {
+		return false
+	}
+	fd.Close()
+	_ = filesystem.Remove(name)
+	return true
+}
+
+// probeTimestampResolution sets a modification time with an odd second
+// and all digits of nanoseconds, and sees how much of it is kept.
+func probeTimestampResolution(filesystem Filesystem, file string) time.Duration {
+	want := time.Unix(1_000_000_001, 123_456_789)
+	if err := filesystem.Chtimes(file, want, want); err != nil {
+		return 0
+	}
+	fi, err := filesystem.Lstat(file)
+	if err != nil {
+		return 0
+	}
+	diff := fi.ModTime().Sub(want).Abs()
+	for _, res := range []time.Duration{time.Nanosecond, 100 * time.Nanosecond, time.Microsecond, time.Millisecond, 10 * time.Millisecond, time.Second, 2 * time.Second} {
+		if diff < res {
+			return res
+		}
+	}
+	return 0
+}
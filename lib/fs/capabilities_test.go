@@ -0,0 +1,92 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProbeCapabilities(t *testing.T) {
+	ffs := newFakeFilesystem(t.Name() + "?nostfolder=true")
+	caps, err := ProbeCapabilities(ffs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The fake filesystem claims no symlink support and has neither xattrs
+	// nor hard links.
+	if caps.Symlinks || caps.Xattrs || caps.HardLinks {
+		t.Errorf("unexpected capabilities %+v", caps)
+	}
+	if !caps.CaseSensitive {
+		t.Error("expected a case sensitive filesystem")
+	}
+	if caps.TimestampResolution != time.Nanosecond {
+		t.Errorf("expected nanosecond timestamps, got %v", caps.TimestampResolution)
+	}
+	if name, path := caps.NameLimits(); name != 0 || path != 0 {
+		t.Errorf("expected no name limits, got %d and %d", name, path)
+	}
+	if names, _ := ffs.DirNames("."); len(names) != 0 {
+		t.Errorf("expected the probe to clean up, got %v", names)
+	}
+}
+
+func TestProbeCapabilitiesCaseInsensitive(t *testing.T) {
+	for _, ffs := range []Filesystem{
+		newFakeFilesystem(t.Name() + "?insens=true&timeprecisionsecond=true"),
+		NewFilesystem(FilesystemTypeFake, t.Name()+"-casefs?insens=true&timeprecisionsecond=true", new(OptionDetectCaseConflicts)),
+	} {
+		caps, err := ProbeCapabilities(ffs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if caps.CaseSensitive {
+			t.Errorf("%v: expected a case insensitive filesystem", ffs.URI())
+		}
+		if caps.TimestampResolution != time.Second {
+			t.Errorf("%v: expected second timestamps, got %v", ffs.URI(), caps.TimestampResolution)
+		}
+	}
+}
+
+func TestProbeCapabilitiesBasic(t *testing.T) {
+	fs, _ := setup(t)
+	caps, err := ProbeCapabilities(fs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Anything the tests run on handles these.
+	if caps.MaxNameLength < 143 || caps.MaxPathLength < 200 {
+		t.Errorf("unexpected name limits %d and %d", caps.MaxNameLength, caps.MaxPathLength)
+	}
+	if caps.TimestampResolution == 0 || caps.TimestampResolution > 2*time.Second {
+		t.Errorf("unexpected timestamp resolution %v", caps.TimestampResolution)
+	}
+}
+
+func TestCapabilitiesCheckName(t *testing.T) {
+	caps := Capabilities{MaxNameLength: 10, MaxPathLength: 20}
+	if err := caps.CheckName(filepath.Join("abcdefghij", "abcdefghi")); err != nil {
+		t.Error(err)
+	}
+	if err := caps.CheckName(filepath.Join("abc", "abcdefghijk")); !errors.Is(err, ErrNameTooLong) {
+		t.Errorf("expected a too long name, got %v", err)
+	}
+	if err := caps.CheckName(filepath.Join("abcdefghij", "abcdefghij", "a")); !errors.Is(err, ErrPathTooLong) {
+		t.Errorf("expected a too long path, got %v", err)
+	}
+
+	// Limits at the probed maximum are no limits.
+	caps = Capabilities{MaxNameLength: maxProbedNameLength, MaxPathLength: maxProbedPathLength}
+	if err := caps.CheckName(strings.Repeat("a", 2*maxProbedNameLength)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,13 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+// allocatedSize returns the bytes of storage allocated to the file. Stat
+// results on Windows don't carry it, so this always returns false.
+func allocatedSize(FileInfo) (int64, bool) {
+	return 0, false
+}
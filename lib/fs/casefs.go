@@ -322,6 +322,20 @@ func (f *caseFilesystem) CreateSymlink(target, name string) error {
 	return nil
 }
 
+func (f *caseFilesystem) CreateHardLink(target, name string) error {
+	if err := f.checkCase(target); err != nil {
+		return err
+	}
+	if err := f.checkCase(name); err != nil {
+		return err
+	}
+	if err := f.Filesystem.CreateHardLink(target, name); err != nil {
+		return err
+	}
+	f.dropCache()
+	return nil
+}
+
 func (f *caseFilesystem) Walk(root string, walkFn WalkFunc) error {
 	// Walking the filesystem is likely (in Syncthing's case certainly) done
 	// to pick up external changes, for which caching is undesirable.
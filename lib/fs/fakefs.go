@@ -345,6 +345,12 @@ func (fs *fakeFS) CreateSymlink(target, name string) error {
 	return nil
 }
 
+// CreateHardLink is not supported; the fake filesystem has no shared file
+// data.
+func (*fakeFS) CreateHardLink(_, _ string) error {
+	return errors.ErrUnsupported
+}
+
 func (fs *fakeFS) DirNames(name string) ([]string, error) {
 	fs.mut.Lock()
 	defer fs.mut.Unlock()
@@ -203,6 +203,7 @@ func TestFakeFSCaseSensitive(t *testing.T) {
 	}
 	filesystems := []testFS{
 		{"fakeFS", newFakeFilesystem("/foo")},
+		{"memoryFS", newTestMemoryFilesystem(t, "/foo")},
 	}
 
 	testDir, sensitive := createTestDir(t)
@@ -238,6 +239,7 @@ func TestFakeFSCaseInsensitive(t *testing.T) {
 
 	filesystems := []testFS{
 		{"fakeFS", newFakeFilesystem("/foobar?insens=true")},
+		{"memoryFS", newTestMemoryFilesystem(t, "/foobar?insens=true")},
 	}
 
 	testDir, sensitive := createTestDir(t)
@@ -0,0 +1,26 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build !windows
+// +build !windows
+
+package fs
+
+import (
+	"fmt"
+	"syscall"
+)
+
+// HardLinkID returns an identifier for the data of the file, shared by all
+// hard links to it, and the number of links. It returns false when the
+// filesystem doesn't tell.
+func HardLinkID(fi FileInfo) (string, uint64, bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return "", 0, false
+	}
+	return fmt.Sprintf("%x:%x", st.Dev, st.Ino), uint64(st.Nlink), true //nolint:unconvert
+}
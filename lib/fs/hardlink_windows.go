@@ -0,0 +1,14 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+// HardLinkID returns an identifier for the data of the file, shared by all
+// hard links to it, and the number of links. Stat results on Windows don't
+// carry the file index, so this always returns false.
+func HardLinkID(FileInfo) (string, uint64, bool) {
+	return "", 0, false
+}
@@ -0,0 +1,225 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHybridPollInterval is the time between polling sweeps when
+	// hybrid watching is enabled without an explicit interval.
+	DefaultHybridPollInterval = 30 * time.Second
+
+	// Directories that have not seen an event for this long are no longer
+	// polled, except for the watch root which is always polled.
+	hybridActiveDirTTL = 10 * time.Minute
+
+	// Upper bound on the number of directories polled per sweep, to keep
+	// the cost of a sweep on slow network mounts predictable.
+	hybridMaxActiveDirs = 256
+)
+
+// OptionHybridWatch enables the hybrid watch mode on a basic filesystem.
+// In this mode the kernel watcher is complemented by a low-frequency
+// polling sweep of recently active directories, catching the changes that
+// inotify and ReadDirectoryChangesW silently miss on SMB/NFS mounts.
+type OptionHybridWatch struct {
+	// Interval between polling sweeps. DefaultHybridPollInterval is used
+	// when zero.
+	Interval time.Duration
+}
+
+func (o *OptionHybridWatch) apply(fs Filesystem) Filesystem {
+	if basic, ok := fs.(*BasicFilesystem); !ok {
+		slog.Warn("OptionHybridWatch must only be used with FilesystemTypeBasic")
+	} else {
+		basic.hybridPollInterval = o.Interval
+		if basic.hybridPollInterval <= 0 {
+			basic.hybridPollInterval = DefaultHybridPollInterval
+		}
+	}
+	return fs
+}
+
+func (*OptionHybridWatch) String() string {
+	return "hybridWatch"
+}
+
+type hybridEntryState struct {
+	modTime time.Time
+	size    int64
+	isDir   bool
+}
+
+// hybridPoller forwards the events of a kernel watcher and, in between,
+// periodically lists the recently active directories to synthesize events
+// for changes the kernel watcher did not report.
+type hybridPoller struct {
+	fs       Filesystem
+	root     string
+	ignore   Matcher
+	interval time.Duration
+
+	mut       sync.Mutex
+	active    map[string]time.Time // directory -> last activity
+	snapshots map[string]map[string]hybridEntryState
+}
+
+func newHybridPoller(fs Filesystem, root string, ignore Matcher, interval time.Duration) *hybridPoller {
+	p := &hybridPoller{
+		fs:        fs,
+		root:      root,
+		ignore:    ignore,
+		interval:  interval,
+		active:    make(map[string]time.Time),
+		snapshots: make(map[string]map[string]hybridEntryState),
+	}
+	p.markActive(root, time.Now())
+	// The baseline for the root is taken right away, so that the first
+	// sweep already catches changes made since the watch started.
+	if snap, err := p.snapshot(root); err == nil {
+		p.snapshots[root] = snap
+	} else {
+		l.Debugln(fs.Type(), fs.URI(), "Watch: Hybrid poll of", root, "failed:", err)
+	}
+	return p
+}
+
+// run forwards events from in to out until the context is cancelled,
+// sweeping the active directories every interval.
+func (p *hybridPoller) run(ctx context.Context, in <-chan Event, out chan<- Event) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case ev := <-in:
+			p.markActive(filepath.Dir(ev.Name), time.Now())
+			select {
+			case out <- ev:
+			case <-ctx.Done():
+				return
+			}
+		case <-ticker.C:
+			for _, ev := range p.sweep(time.Now()) {
+				l.Debugln(p.fs.Type(), p.fs.URI(), "Watch: Hybrid poll found change", ev.Name, ev.Type)
+				select {
+				case out <- ev:
+				case <-ctx.Done():
+					return
+				}
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// markActive registers dir as recently active so that it is included in
+// the next sweeps. It's called for every forwarded event and so doesn't
+// touch the filesystem; the baseline for detecting changes in a newly
+// active directory is taken by the next sweep.
+func (p *hybridPoller) markActive(dir string, now time.Time) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	if _, ok := p.active[dir]; !ok && len(p.active) >= hybridMaxActiveDirs {
+		p.evictOldestLocked()
+	}
+	p.active[dir] = now
+}
+
+func (p *hybridPoller) evictOldestLocked() {
+	var oldest string
+	var oldestTime time.Time
+	for dir, t := range p.active {
+		if dir == p.root {
+			continue
+		}
+		if oldest == "" || t.Before(oldestTime) {
+			oldest, oldestTime = dir, t
+		}
+	}
+	delete(p.active, oldest)
+	delete(p.snapshots, oldest)
+}
+
+// sweep lists all active directories and returns events for every entry
+// that appeared, changed or disappeared since the previous listing. The
+// first listing of a directory other than the root, whose baseline is
+// taken at startup, is only kept as the baseline.
+func (p *hybridPoller) sweep(now time.Time) []Event {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	var evs []Event
+	for dir, last := range p.active {
+		if dir != p.root && now.Sub(last) > hybridActiveDirTTL {
+			delete(p.active, dir)
+			delete(p.snapshots, dir)
+			continue
+		}
+
+		cur, err := p.snapshot(dir)
+		if IsNotExist(err) && dir != p.root {
+			evs = append(evs, Event{Name: dir, Type: Remove})
+			delete(p.active, dir)
+			delete(p.snapshots, dir)
+			continue
+		} else if err != nil {
+			l.Debugln(p.fs.Type(), p.fs.URI(), "Watch: Hybrid poll of", dir, "failed:", err)
+			continue
+		}
+
+		prev, ok := p.snapshots[dir]
+		p.snapshots[dir] = cur
+		if !ok {
+			continue
+		}
+		for name, st := range cur {
+			if old, ok := prev[name]; !ok || old != st {
+				evs = append(evs, Event{Name: filepath.Join(dir, name), Type: NonRemove})
+			}
+		}
+		for name := range prev {
+			if _, ok := cur[name]; !ok {
+				evs = append(evs, Event{Name: filepath.Join(dir, name), Type: Remove})
+			}
+		}
+	}
+	return evs
+}
+
+func (p *hybridPoller) snapshot(dir string) (map[string]hybridEntryState, error) {
+	names, err := p.fs.DirNames(dir)
+	if err != nil {
+		return nil, err
+	}
+	snap := make(map[string]hybridEntryState, len(names))
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if IsInternal(path) || (p.ignore != nil && p.ignore.Match(path).IsIgnored()) {
+			continue
+		}
+		info, err := p.fs.Lstat(path)
+		if err != nil {
+			continue
+		}
+		st := hybridEntryState{isDir: info.IsDir()}
+		if !st.isDir {
+			st.modTime = info.ModTime()
+			st.size = info.Size()
+		}
+		snap[name] = st
+	}
+	return snap, nil
+}
@@ -0,0 +1,77 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestHybridPollerSweep(t *testing.T) {
+	fs := newFakeFilesystem(t.Name())
+	if err := fs.MkdirAll("dir", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFile(fs, "dir/existing", []byte("a"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	p := newHybridPoller(fs, ".", nil, time.Second)
+	if _, ok := p.snapshots["."]; !ok {
+		t.Fatal("expected the baseline for the root to be taken at startup")
+	}
+
+	// Changes in the root before the first sweep are found by it, while
+	// the first listing of a newly active directory is its baseline.
+	if err := WriteFile(fs, "early", []byte("c"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p.markActive("dir", now)
+	if evs := p.sweep(now); !slices.Equal(evs, []Event{{Name: "early", Type: NonRemove}}) {
+		t.Fatalf("expected only the change in the root, got %v", evs)
+	}
+
+	if err := WriteFile(fs, "dir/new", []byte("b"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Remove("dir/existing"); err != nil {
+		t.Fatal(err)
+	}
+
+	evs := p.sweep(now)
+	slices.SortFunc(evs, func(a, b Event) int { return int(a.Type) - int(b.Type) })
+	expected := []Event{{Name: filepath.Join("dir", "new"), Type: NonRemove}, {Name: filepath.Join("dir", "existing"), Type: Remove}}
+	if !slices.Equal(evs, expected) {
+		t.Fatalf("expected %v, got %v", expected, evs)
+	}
+
+	if evs := p.sweep(now); len(evs) != 0 {
+		t.Fatalf("expected no events on second sweep, got %v", evs)
+	}
+}
+
+func TestHybridPollerExpiry(t *testing.T) {
+	fs := newFakeFilesystem(t.Name())
+	if err := fs.MkdirAll("dir", 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	p := newHybridPoller(fs, ".", nil, time.Second)
+	p.markActive("dir", now)
+
+	p.sweep(now.Add(2 * hybridActiveDirTTL))
+	if _, ok := p.active["dir"]; ok {
+		t.Error("expected inactive directory to be expired")
+	}
+	if _, ok := p.active["."]; !ok {
+		t.Error("expected root to never expire")
+	}
+}
@@ -60,6 +60,12 @@ func (fs *logFilesystem) CreateSymlink(target, name string) error {
 	return err
 }
 
+func (fs *logFilesystem) CreateHardLink(target, name string) error {
+	err := fs.Filesystem.CreateHardLink(target, name)
+	l.Debugln(fs.getCaller(), fs.Type(), fs.URI(), "CreateHardLink", target, name, err)
+	return err
+}
+
 func (fs *logFilesystem) DirNames(name string) ([]string, error) {
 	names, err := fs.Filesystem.DirNames(name)
 	l.Debugln(fs.getCaller(), fs.Type(), fs.URI(), "DirNames", name, names, err)
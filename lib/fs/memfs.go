@@ -0,0 +1,1032 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"os/user"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+const FilesystemTypeMemory FilesystemType = "memory"
+
+func init() {
+	RegisterFilesystemType(FilesystemTypeMemory, func(root string, _ ...Option) (Filesystem, error) {
+		return newMemoryFilesystem(root), nil
+	})
+}
+
+// The maximum number of symlinks followed when resolving a path.
+const maxMemSymlinkHops = 40
+
+// memoryFS is a filesystem kept entirely in RAM, for ephemeral folders and
+// for tests that want the semantics of a real filesystem without the disk.
+// Unlike fakeFS it keeps the file contents and supports symlinks, hard
+// links and extended attributes, and errors are *os.PathError wrapping the
+// same errors as the os package returns. Absolute symlink targets are
+// relative to the root of the filesystem. The root URI can contain URL
+// query-style parameters:
+//
+//	insens=true  case insensitive and case preserving, like Windows and macOS
+//	norm=nfc|nfd store names in the given Unicode normalization form, and
+//	             look them up regardless of form, like macOS (nfd)
+//	latency=d    the time each operation takes, in time.ParseDuration format
+//	size=n       the capacity in MiB; writes beyond it fail with ENOSPC
+//	             (default unlimited)
+//
+// Memory filesystems with the same root URI see the same files, for the
+// lifetime of the process.
+type memoryFS struct {
+	uri        string
+	insens     bool
+	form       norm.Form
+	normalize  bool
+	latency    time.Duration
+	capacity   int64
+	userCache  *userCache
+	groupCache *groupCache
+
+	mut     sync.Mutex
+	root    *memNode
+	used    int64
+	lastIno uint64
+}
+
+var (
+	memoryFSMut   sync.Mutex
+	memoryFSCache = make(map[string]*memoryFS)
+)
+
+func newMemoryFilesystem(rootURI string) *memoryFS {
+	memoryFSMut.Lock()
+	defer memoryFSMut.Unlock()
+
+	if fs, ok := memoryFSCache[rootURI]; ok {
+		return fs
+	}
+
+	var params url.Values
+	if uri, err := url.Parse(rootURI); err == nil {
+		params = uri.Query()
+	}
+
+	fs := &memoryFS{
+		uri:        rootURI,
+		insens:     params.Get("insens") == "true",
+		userCache:  newValueCache(time.Hour, user.LookupId),
+		groupCache: newValueCache(time.Hour, user.LookupGroupId),
+	}
+	switch params.Get("norm") {
+	case "nfc":
+		fs.form, fs.normalize = norm.NFC, true
+	case "nfd":
+		fs.form, fs.normalize = norm.NFD, true
+	}
+	fs.latency, _ = time.ParseDuration(params.Get("latency"))
+	if size, err := strconv.ParseInt(params.Get("size"), 10, 64); err == nil && size > 0 {
+		fs.capacity = size << 20
+	}
+	fs.root = fs.newNode(memNodeDir, 0o755)
+
+	memoryFSCache[rootURI] = fs
+	return fs
+}
+
+type memNodeKind int
+
+const (
+	memNodeFile memNodeKind = iota
+	memNodeDir
+	memNodeSymlink
+)
+
+// memNode is a file, directory or symlink, i.e. an inode. Hard links are
+// several directory entries pointing at the same node.
+type memNode struct {
+	ino      uint64
+	kind     memNodeKind
+	mode     FileMode // permission bits only
+	uid      int
+	gid      int
+	mtime    time.Time
+	ctime    time.Time
+	nlink    int
+	target   string                // for symlinks
+	content  []byte                // for files
+	children map[string]*memDirent // for directories, by lookup key
+	xattrs   map[string][]byte
+}
+
+type memDirent struct {
+	name string // as created, after normalization
+	node *memNode
+}
+
+// newNode must be called with the lock held, or before the filesystem is
+// shared.
+func (fs *memoryFS) newNode(kind memNodeKind, perm FileMode) *memNode {
+	fs.lastIno++
+	now := time.Now()
+	n := &memNode{
+		ino:   fs.lastIno,
+		kind:  kind,
+		mode:  perm & (ModePerm | ModeSetuid | ModeSetgid | ModeSticky),
+		uid:   os.Getuid(),
+		gid:   os.Getgid(),
+		mtime: now,
+		ctime: now,
+	}
+	if kind == memNodeDir {
+		n.children = make(map[string]*memDirent)
+	}
+	return n
+}
+
+// key returns the name by which an entry is looked up in its directory.
+func (fs *memoryFS) key(name string) string {
+	if fs.insens {
+		return UnicodeLowercaseNormalized(name)
+	}
+	if fs.normalize {
+		return norm.NFC.String(name)
+	}
+	return name
+}
+
+// storedName returns the name as the filesystem records it.
+func (fs *memoryFS) storedName(name string) string {
+	if fs.normalize {
+		return fs.form.String(name)
+	}
+	return name
+}
+
+func (fs *memoryFS) lock() {
+	time.Sleep(fs.latency)
+	fs.mut.Lock()
+}
+
+// splitPath returns the slash separated components of the canonicalized
+// name, which is empty for the root.
+func splitPath(name string) ([]string, error) {
+	name, err := Canonicalize(name)
+	if err != nil {
+		return nil, err
+	}
+	if name == "." {
+		return nil, nil
+	}
+	return splitSlashes(filepath.ToSlash(name)), nil
+}
+
+func splitSlashes(p string) []string {
+	var comps []string
+	for _, comp := range strings.Split(p, "/") {
+		if comp != "" && comp != "." {
+			comps = append(comps, comp)
+		}
+	}
+	return comps
+}
+
+// resolve returns the node at the path given as components. Symlinks are
+// followed, except for the last component unless followLast is set.
+func (fs *memoryFS) resolve(comps []string, followLast bool) (*memNode, error) {
+	stack := []*memNode{fs.root}
+	comps = slices.Clone(comps)
+	hops := 0
+	for len(comps) > 0 {
+		comp := comps[0]
+		comps = comps[1:]
+		if comp == ".." {
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+			continue
+		}
+		cur := stack[len(stack)-1]
+		if cur.kind != memNodeDir {
+			return nil, syscall.ENOTDIR
+		}
+		ent, ok := cur.children[fs.key(comp)]
+		if !ok {
+			return nil, ErrNotExist
+		}
+		if ent.node.kind == memNodeSymlink && (len(comps) > 0 || followLast) {
+			hops++
+			if hops > maxMemSymlinkHops {
+				return nil, syscall.ELOOP
+			}
+			target := filepath.ToSlash(ent.node.target)
+			if path.IsAbs(target) {
+				stack = stack[:1]
+			}
+			comps = append(splitSlashes(target), comps...)
+			continue
+		}
+		stack = append(stack, ent.node)
+	}
+	return stack[len(stack)-1], nil
+}
+
+// lookup returns the node for the name.
+func (fs *memoryFS) lookup(op, name string, follow bool) (*memNode, error) {
+	comps, err := splitPath(name)
+	if err != nil {
+		return nil, &os.PathError{Op: op, Path: name, Err: err}
+	}
+	node, err := fs.resolve(comps, follow)
+	if err != nil {
+		return nil, &os.PathError{Op: op, Path: name, Err: err}
+	}
+	return node, nil
+}
+
+// lookupParent returns the directory containing the name, and the last
+// component of the name.
+func (fs *memoryFS) lookupParent(op, name string) (*memNode, string, error) {
+	comps, err := splitPath(name)
+	if err != nil {
+		return nil, "", &os.PathError{Op: op, Path: name, Err: err}
+	}
+	if len(comps) == 0 || comps[len(comps)-1] == ".." {
+		return nil, "", &os.PathError{Op: op, Path: name, Err: syscall.EINVAL}
+	}
+	dir, err := fs.resolve(comps[:len(comps)-1], true)
+	if err != nil {
+		return nil, "", &os.PathError{Op: op, Path: name, Err: err}
+	}
+	if dir.kind != memNodeDir {
+		return nil, "", &os.PathError{Op: op, Path: name, Err: syscall.ENOTDIR}
+	}
+	return dir, comps[len(comps)-1], nil
+}
+
+// link adds a directory entry for the node, which must not exist.
+func (fs *memoryFS) link(dir *memNode, base string, node *memNode) {
+	dir.children[fs.key(base)] = &memDirent{name: fs.storedName(base), node: node}
+	node.nlink++
+	dir.mtime = time.Now()
+	dir.ctime = dir.mtime
+}
+
+func (fs *memoryFS) unlink(dir *memNode, key string) {
+	ent := dir.children[key]
+	delete(dir.children, key)
+	fs.release(ent.node)
+	dir.mtime = time.Now()
+	dir.ctime = dir.mtime
+}
+
+// release drops a link to the node, giving back the space used by the
+// content once no links remain. (Open files keep working, as on Unix.)
+func (fs *memoryFS) release(node *memNode) {
+	node.nlink--
+	if node.nlink > 0 {
+		return
+	}
+	fs.used -= int64(len(node.content))
+	for _, ent := range node.children {
+		fs.release(ent.node)
+	}
+}
+
+// resize changes the size of the file content, enforcing the capacity.
+// Files that have been removed while open no longer count.
+func (fs *memoryFS) resize(node *memNode, size int64) error {
+	grow := size - int64(len(node.content))
+	if node.nlink == 0 {
+		grow = 0
+	}
+	if grow > 0 && fs.capacity > 0 && fs.used+grow > fs.capacity {
+		return syscall.ENOSPC
+	}
+	if size <= int64(cap(node.content)) {
+		old := len(node.content)
+		node.content = node.content[:size]
+		if size > int64(old) {
+			clear(node.content[old:])
+		}
+	} else {
+		content := make([]byte, size, max(size, 2*int64(cap(node.content))))
+		copy(content, node.content)
+		node.content = content
+	}
+	fs.used += grow
+	return nil
+}
+
+func (fs *memoryFS) Chmod(name string, mode FileMode) error {
+	fs.lock()
+	defer fs.mut.Unlock()
+	node, err := fs.lookup("chmod", name, true)
+	if err != nil {
+		return err
+	}
+	node.mode = mode & (ModePerm | ModeSetuid | ModeSetgid | ModeSticky)
+	node.ctime = time.Now()
+	return nil
+}
+
+func (fs *memoryFS) Lchown(name, uid, gid string) error {
+	nuid, err := strconv.Atoi(uid)
+	if err != nil {
+		return &os.PathError{Op: "lchown", Path: name, Err: err}
+	}
+	ngid, err := strconv.Atoi(gid)
+	if err != nil {
+		return &os.PathError{Op: "lchown", Path: name, Err: err}
+	}
+	fs.lock()
+	defer fs.mut.Unlock()
+	node, err := fs.lookup("lchown", name, false)
+	if err != nil {
+		return err
+	}
+	node.uid, node.gid = nuid, ngid
+	node.ctime = time.Now()
+	return nil
+}
+
+func (fs *memoryFS) Chtimes(name string, _ time.Time, mtime time.Time) error {
+	fs.lock()
+	defer fs.mut.Unlock()
+	node, err := fs.lookup("chtimes", name, true)
+	if err != nil {
+		return err
+	}
+	node.mtime = mtime
+	node.ctime = time.Now()
+	return nil
+}
+
+func (fs *memoryFS) Create(name string) (File, error) {
+	return fs.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o666)
+}
+
+func (fs *memoryFS) CreateSymlink(target, name string) error {
+	fs.lock()
+	defer fs.mut.Unlock()
+	dir, base, err := fs.lookupParent("symlink", name)
+	if err != nil {
+		return err
+	}
+	if _, ok := dir.children[fs.key(base)]; ok {
+		return &os.PathError{Op: "symlink", Path: name, Err: ErrExist}
+	}
+	node := fs.newNode(memNodeSymlink, 0o777)
+	node.target = target
+	fs.link(dir, base, node)
+	return nil
+}
+
+func (fs *memoryFS) CreateHardLink(target, name string) error {
+	fs.lock()
+	defer fs.mut.Unlock()
+	node, err := fs.lookup("link", target, false)
+	if err != nil {
+		return err
+	}
+	if node.kind == memNodeDir {
+		return &os.PathError{Op: "link", Path: target, Err: syscall.EPERM}
+	}
+	dir, base, err := fs.lookupParent("link", name)
+	if err != nil {
+		return err
+	}
+	if _, ok := dir.children[fs.key(base)]; ok {
+		return &os.PathError{Op: "link", Path: name, Err: ErrExist}
+	}
+	fs.link(dir, base, node)
+	node.ctime = time.Now()
+	return nil
+}
+
+// DirNames returns the names in the directory, sorted.
+func (fs *memoryFS) DirNames(name string) ([]string, error) {
+	fs.lock()
+	defer fs.mut.Unlock()
+	node, err := fs.lookup("readdirent", name, true)
+	if err != nil {
+		return nil, err
+	}
+	if node.kind != memNodeDir {
+		return nil, &os.PathError{Op: "readdirent", Path: name, Err: syscall.ENOTDIR}
+	}
+	names := make([]string, 0, len(node.children))
+	for _, ent := range node.children {
+		names = append(names, ent.name)
+	}
+	slices.Sort(names)
+	return names, nil
+}
+
+func (fs *memoryFS) Lstat(name string) (FileInfo, error) {
+	fs.lock()
+	defer fs.mut.Unlock()
+	node, err := fs.lookup("lstat", name, false)
+	if err != nil {
+		return nil, err
+	}
+	return newMemFileInfo(filepath.Base(name), node), nil
+}
+
+func (fs *memoryFS) Stat(name string) (FileInfo, error) {
+	fs.lock()
+	defer fs.mut.Unlock()
+	node, err := fs.lookup("stat", name, true)
+	if err != nil {
+		return nil, err
+	}
+	return newMemFileInfo(filepath.Base(name), node), nil
+}
+
+func (fs *memoryFS) Mkdir(name string, perm FileMode) error {
+	fs.lock()
+	defer fs.mut.Unlock()
+	return fs.mkdirLocked(name, perm)
+}
+
+func (fs *memoryFS) mkdirLocked(name string, perm FileMode) error {
+	dir, base, err := fs.lookupParent("mkdir", name)
+	if err != nil {
+		return err
+	}
+	if _, ok := dir.children[fs.key(base)]; ok {
+		return &os.PathError{Op: "mkdir", Path: name, Err: ErrExist}
+	}
+	fs.link(dir, base, fs.newNode(memNodeDir, perm))
+	return nil
+}
+
+func (fs *memoryFS) MkdirAll(name string, perm FileMode) error {
+	fs.lock()
+	defer fs.mut.Unlock()
+	return fs.mkdirAllLocked(name, perm)
+}
+
+func (fs *memoryFS) mkdirAllLocked(name string, perm FileMode) error {
+	if node, err := fs.lookup("mkdir", name, true); err == nil {
+		if node.kind != memNodeDir {
+			return &os.PathError{Op: "mkdir", Path: name, Err: syscall.ENOTDIR}
+		}
+		return nil
+	}
+	if parent := filepath.Dir(name); parent != name && parent != "." {
+		if err := fs.mkdirAllLocked(parent, perm); err != nil {
+			return err
+		}
+	}
+	return fs.mkdirLocked(name, perm)
+}
+
+func (fs *memoryFS) Open(name string) (File, error) {
+	return fs.OpenFile(name, os.O_RDONLY, 0)
+}
+
+func (fs *memoryFS) OpenFile(name string, flags int, perm FileMode) (File, error) {
+	fs.lock()
+	defer fs.mut.Unlock()
+
+	node, err := fs.lookup("open", name, true)
+	switch {
+	case err == nil:
+		if flags&(os.O_CREATE|os.O_EXCL) == os.O_CREATE|os.O_EXCL {
+			return nil, &os.PathError{Op: "open", Path: name, Err: ErrExist}
+		}
+	case IsNotExist(err) && flags&os.O_CREATE != 0:
+		dir, base, err := fs.lookupParent("open", name)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := dir.children[fs.key(base)]; ok {
+			// A dangling symlink
+			return nil, &os.PathError{Op: "open", Path: name, Err: ErrNotExist}
+		}
+		node = fs.newNode(memNodeFile, perm)
+		fs.link(dir, base, node)
+	default:
+		return nil, err
+	}
+
+	writable := flags&(os.O_WRONLY|os.O_RDWR) != 0
+	if node.kind == memNodeDir && writable {
+		return nil, &os.PathError{Op: "open", Path: name, Err: syscall.EISDIR}
+	}
+	if flags&os.O_TRUNC != 0 && writable && len(node.content) > 0 {
+		_ = fs.resize(node, 0)
+		node.mtime = time.Now()
+		node.ctime = node.mtime
+	}
+	return &memFile{fs: fs, node: node, name: name, flags: flags}, nil
+}
+
+func (fs *memoryFS) ReadSymlink(name string) (string, error) {
+	fs.lock()
+	defer fs.mut.Unlock()
+	node, err := fs.lookup("readlink", name, false)
+	if err != nil {
+		return "", err
+	}
+	if node.kind != memNodeSymlink {
+		return "", &os.PathError{Op: "readlink", Path: name, Err: syscall.EINVAL}
+	}
+	return node.target, nil
+}
+
+func (fs *memoryFS) Remove(name string) error {
+	fs.lock()
+	defer fs.mut.Unlock()
+	dir, base, err := fs.lookupParent("remove", name)
+	if err != nil {
+		return err
+	}
+	key := fs.key(base)
+	ent, ok := dir.children[key]
+	if !ok {
+		return &os.PathError{Op: "remove", Path: name, Err: ErrNotExist}
+	}
+	if len(ent.node.children) > 0 {
+		return &os.PathError{Op: "remove", Path: name, Err: syscall.ENOTEMPTY}
+	}
+	fs.unlink(dir, key)
+	return nil
+}
+
+func (fs *memoryFS) RemoveAll(name string) error {
+	fs.lock()
+	defer fs.mut.Unlock()
+	dir, base, err := fs.lookupParent("unlinkat", name)
+	if IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if _, ok := dir.children[fs.key(base)]; ok {
+		fs.unlink(dir, fs.key(base))
+	}
+	return nil
+}
+
+func (fs *memoryFS) Rename(oldname, newname string) error {
+	fs.lock()
+	defer fs.mut.Unlock()
+
+	oldDir, oldBase, err := fs.lookupParent("rename", oldname)
+	if err != nil {
+		return err
+	}
+	oldKey := fs.key(oldBase)
+	src, ok := oldDir.children[oldKey]
+	if !ok {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: ErrNotExist}
+	}
+	newDir, newBase, err := fs.lookupParent("rename", newname)
+	if err != nil {
+		return err
+	}
+	newKey := fs.key(newBase)
+
+	if dst, ok := newDir.children[newKey]; ok {
+		if dst.node == src.node {
+			if newDir == oldDir && newKey == oldKey {
+				// A change of case or normalization only
+				src.name = fs.storedName(newBase)
+			}
+			return nil
+		}
+		switch {
+		case src.node.kind == memNodeDir && dst.node.kind != memNodeDir:
+			return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: syscall.ENOTDIR}
+		case src.node.kind != memNodeDir && dst.node.kind == memNodeDir:
+			return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: syscall.EISDIR}
+		case len(dst.node.children) > 0:
+			return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: syscall.ENOTEMPTY}
+		}
+	}
+	if src.node.kind == memNodeDir && (src.node == newDir || memContains(src.node, newDir)) {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: syscall.EINVAL}
+	}
+
+	if _, ok := newDir.children[newKey]; ok {
+		fs.unlink(newDir, newKey)
+	}
+	delete(oldDir.children, oldKey)
+	newDir.children[newKey] = &memDirent{name: fs.storedName(newBase), node: src.node}
+	now := time.Now()
+	oldDir.mtime, oldDir.ctime = now, now
+	newDir.mtime, newDir.ctime = now, now
+	src.node.ctime = now
+	return nil
+}
+
+// memContains returns whether the node is somewhere below the directory.
+func memContains(dir, node *memNode) bool {
+	for _, ent := range dir.children {
+		if ent.node == node || ent.node.kind == memNodeDir && memContains(ent.node, node) {
+			return true
+		}
+	}
+	return false
+}
+
+func (*memoryFS) SymlinksSupported() bool {
+	return true
+}
+
+func (*memoryFS) Walk(_ string, _ WalkFunc) error {
+	return errors.New("not implemented")
+}
+
+// Watch is not supported: nothing but ourselves can change the files.
+func (*memoryFS) Watch(_ string, _ Matcher, _ context.Context, _ bool) (<-chan Event, <-chan error, error) {
+	return nil, nil, ErrWatchNotSupported
+}
+
+func (*memoryFS) Hide(_ string) error {
+	return nil
+}
+
+func (*memoryFS) Unhide(_ string) error {
+	return nil
+}
+
+func (fs *memoryFS) Glob(pattern string) ([]string, error) {
+	dir := filepath.Dir(pattern)
+	file := filepath.Base(pattern)
+	names, err := fs.DirNames(dir)
+	if err != nil {
+		return nil, errPathInvalid
+	}
+	var matches []string
+	for _, n := range names {
+		matched, err := filepath.Match(file, n)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			matches = append(matches, filepath.Join(dir, n))
+		}
+	}
+	return matches, nil
+}
+
+func (*memoryFS) Roots() ([]string, error) {
+	return []string{"/"}, nil
+}
+
+// Usage returns the capacity and what's left of it, if limited.
+func (fs *memoryFS) Usage(_ string) (Usage, error) {
+	if fs.capacity == 0 {
+		return Usage{}, errors.ErrUnsupported
+	}
+	fs.mut.Lock()
+	defer fs.mut.Unlock()
+	return Usage{Free: uint64(fs.capacity - fs.used), Total: uint64(fs.capacity)}, nil
+}
+
+func (*memoryFS) Type() FilesystemType {
+	return FilesystemTypeMemory
+}
+
+func (fs *memoryFS) URI() string {
+	return fs.uri
+}
+
+func (*memoryFS) Options() []Option {
+	return nil
+}
+
+func (*memoryFS) SameFile(fi1, fi2 FileInfo) bool {
+	f1, ok1 := fi1.(*memFileInfo)
+	f2, ok2 := fi2.(*memFileInfo)
+	return ok1 && ok2 && f1.ino == f2.ino
+}
+
+func (fs *memoryFS) PlatformData(name string, scanOwnership, scanXattrs bool, xattrFilter XattrFilter) (protocol.PlatformData, error) {
+	return unixPlatformData(fs, name, fs.userCache, fs.groupCache, scanOwnership, scanXattrs, xattrFilter)
+}
+
+func (fs *memoryFS) GetXattr(name string, xattrFilter XattrFilter) ([]protocol.Xattr, error) {
+	fs.lock()
+	defer fs.mut.Unlock()
+	node, err := fs.lookup("getxattr", name, false)
+	if err != nil {
+		return nil, err
+	}
+	return memXattrs(node, xattrFilter), nil
+}
+
+// memXattrs returns the node's attributes permitted by the filter, sorted
+// by name, within the same size limits as on a real filesystem.
+func memXattrs(node *memNode, xattrFilter XattrFilter) []protocol.Xattr {
+	names := make([]string, 0, len(node.xattrs))
+	for attr := range node.xattrs {
+		names = append(names, attr)
+	}
+	slices.Sort(names)
+
+	res := make([]protocol.Xattr, 0, len(names))
+	var totSize int
+	for _, attr := range names {
+		if !xattrFilter.Permit(attr) {
+			continue
+		}
+		val := node.xattrs[attr]
+		if max := xattrFilter.GetMaxSingleEntrySize(); max > 0 && len(attr)+len(val) > max {
+			continue
+		}
+		totSize += len(attr) + len(val)
+		if max := xattrFilter.GetMaxTotalSize(); max > 0 && totSize > max {
+			continue
+		}
+		res = append(res, protocol.Xattr{Name: attr, Value: slices.Clone(val)})
+	}
+	return res
+}
+
+// SetXattr replaces the attributes permitted by the filter with the given
+// set.
+func (fs *memoryFS) SetXattr(name string, xattrs []protocol.Xattr, xattrFilter XattrFilter) error {
+	fs.lock()
+	defer fs.mut.Unlock()
+	node, err := fs.lookup("setxattr", name, false)
+	if err != nil {
+		return err
+	}
+	for _, xa := range memXattrs(node, xattrFilter) {
+		delete(node.xattrs, xa.Name)
+	}
+	if node.xattrs == nil {
+		node.xattrs = make(map[string][]byte)
+	}
+	for _, xa := range xattrs {
+		node.xattrs[xa.Name] = slices.Clone(xa.Value)
+	}
+	node.ctime = time.Now()
+	return nil
+}
+
+func (*memoryFS) underlying() (Filesystem, bool) {
+	return nil, false
+}
+
+// memFile is an open file. Reads and writes are checked against the flags
+// it was opened with.
+type memFile struct {
+	fs     *memoryFS
+	node   *memNode
+	name   string
+	flags  int
+	offset int64
+	closed bool
+}
+
+func (f *memFile) check(op string, write bool) error {
+	switch {
+	case f.closed:
+		return &os.PathError{Op: op, Path: f.name, Err: os.ErrClosed}
+	case f.node.kind == memNodeDir:
+		return &os.PathError{Op: op, Path: f.name, Err: syscall.EISDIR}
+	case write && f.flags&(os.O_WRONLY|os.O_RDWR) == 0, !write && f.flags&os.O_WRONLY != 0:
+		return &os.PathError{Op: op, Path: f.name, Err: syscall.EBADF}
+	}
+	return nil
+}
+
+func (f *memFile) Close() error {
+	f.fs.mut.Lock()
+	defer f.fs.mut.Unlock()
+	if f.closed {
+		return &os.PathError{Op: "close", Path: f.name, Err: os.ErrClosed}
+	}
+	f.closed = true
+	return nil
+}
+
+func (f *memFile) Read(p []byte) (int, error) {
+	f.fs.lock()
+	defer f.fs.mut.Unlock()
+	n, err := f.readAtLocked("read", p, f.offset)
+	f.offset += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (f *memFile) ReadAt(p []byte, offs int64) (int, error) {
+	f.fs.lock()
+	defer f.fs.mut.Unlock()
+	return f.readAtLocked("read", p, offs)
+}
+
+func (f *memFile) readAtLocked(op string, p []byte, offs int64) (int, error) {
+	if err := f.check(op, false); err != nil {
+		return 0, err
+	}
+	if offs < 0 {
+		return 0, &os.PathError{Op: op, Path: f.name, Err: syscall.EINVAL}
+	}
+	if offs >= int64(len(f.node.content)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.node.content[offs:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	f.fs.mut.Lock()
+	defer f.fs.mut.Unlock()
+	if f.closed {
+		return 0, &os.PathError{Op: "seek", Path: f.name, Err: os.ErrClosed}
+	}
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.offset
+	case io.SeekEnd:
+		offset += int64(len(f.node.content))
+	}
+	if offset < 0 {
+		return 0, &os.PathError{Op: "seek", Path: f.name, Err: syscall.EINVAL}
+	}
+	f.offset = offset
+	return offset, nil
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	f.fs.lock()
+	defer f.fs.mut.Unlock()
+	offs := f.offset
+	if f.flags&os.O_APPEND != 0 {
+		offs = int64(len(f.node.content))
+	}
+	n, err := f.writeAtLocked("write", p, offs)
+	f.offset = offs + int64(n)
+	return n, err
+}
+
+func (f *memFile) WriteAt(p []byte, offs int64) (int, error) {
+	if f.flags&os.O_APPEND != 0 {
+		return 0, errors.New("os: invalid use of WriteAt on file opened with O_APPEND")
+	}
+	f.fs.lock()
+	defer f.fs.mut.Unlock()
+	return f.writeAtLocked("write", p, offs)
+}
+
+func (f *memFile) writeAtLocked(op string, p []byte, offs int64) (int, error) {
+	if err := f.check(op, true); err != nil {
+		return 0, err
+	}
+	if offs < 0 {
+		return 0, &os.PathError{Op: op, Path: f.name, Err: syscall.EINVAL}
+	}
+	if end := offs + int64(len(p)); end > int64(len(f.node.content)) {
+		if err := f.fs.resize(f.node, end); err != nil {
+			return 0, &os.PathError{Op: op, Path: f.name, Err: err}
+		}
+	}
+	copy(f.node.content[offs:], p)
+	f.node.mtime = time.Now()
+	f.node.ctime = f.node.mtime
+	return len(p), nil
+}
+
+// Name returns the name as passed when opening the file.
+func (f *memFile) Name() string {
+	return f.name
+}
+
+func (f *memFile) Truncate(size int64) error {
+	f.fs.lock()
+	defer f.fs.mut.Unlock()
+	if err := f.check("truncate", true); err != nil {
+		return err
+	}
+	if size < 0 {
+		return &os.PathError{Op: "truncate", Path: f.name, Err: syscall.EINVAL}
+	}
+	if err := f.fs.resize(f.node, size); err != nil {
+		return &os.PathError{Op: "truncate", Path: f.name, Err: err}
+	}
+	f.node.mtime = time.Now()
+	f.node.ctime = f.node.mtime
+	return nil
+}
+
+func (f *memFile) Stat() (FileInfo, error) {
+	f.fs.mut.Lock()
+	defer f.fs.mut.Unlock()
+	if f.closed {
+		return nil, &os.PathError{Op: "stat", Path: f.name, Err: os.ErrClosed}
+	}
+	return newMemFileInfo(filepath.Base(f.name), f.node), nil
+}
+
+func (*memFile) Sync() error {
+	return nil
+}
+
+// memFileInfo is a snapshot of a node, taken with the lock held.
+type memFileInfo struct {
+	name  string
+	ino   uint64
+	kind  memNodeKind
+	mode  FileMode
+	size  int64
+	uid   int
+	gid   int
+	mtime time.Time
+	ctime time.Time
+}
+
+func newMemFileInfo(name string, node *memNode) *memFileInfo {
+	size := int64(len(node.content))
+	if node.kind == memNodeSymlink {
+		size = int64(len(node.target))
+	}
+	return &memFileInfo{
+		name:  name,
+		ino:   node.ino,
+		kind:  node.kind,
+		mode:  node.mode,
+		size:  size,
+		uid:   node.uid,
+		gid:   node.gid,
+		mtime: node.mtime,
+		ctime: node.ctime,
+	}
+}
+
+func (f *memFileInfo) Name() string {
+	return f.name
+}
+
+func (f *memFileInfo) Mode() FileMode {
+	switch f.kind {
+	case memNodeDir:
+		return f.mode | FileMode(os.ModeDir)
+	case memNodeSymlink:
+		return f.mode | ModeSymlink
+	}
+	return f.mode
+}
+
+func (f *memFileInfo) Size() int64 {
+	return f.size
+}
+
+func (f *memFileInfo) ModTime() time.Time {
+	return f.mtime
+}
+
+func (f *memFileInfo) IsDir() bool {
+	return f.kind == memNodeDir
+}
+
+func (f *memFileInfo) IsRegular() bool {
+	return f.kind == memNodeFile
+}
+
+func (f *memFileInfo) IsSymlink() bool {
+	return f.kind == memNodeSymlink
+}
+
+func (f *memFileInfo) Owner() int {
+	return f.uid
+}
+
+func (f *memFileInfo) Group() int {
+	return f.gid
+}
+
+func (*memFileInfo) Sys() interface{} {
+	return nil
+}
+
+func (f *memFileInfo) InodeChangeTime() time.Time {
+	return f.ctime
+}
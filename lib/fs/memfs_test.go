@@ -0,0 +1,324 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"syscall"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// newTestMemoryFilesystem returns a memory filesystem private to the test,
+// with a folder marker like the other test filesystems.
+func newTestMemoryFilesystem(t *testing.T, uri string) *memoryFS {
+	t.Helper()
+	fs := newMemoryFilesystem(t.Name() + uri)
+	t.Cleanup(func() {
+		memoryFSMut.Lock()
+		delete(memoryFSCache, t.Name()+uri)
+		memoryFSMut.Unlock()
+	})
+	if err := fs.Mkdir(".stfolder", 0o700); err != nil {
+		t.Fatal(err)
+	}
+	return fs
+}
+
+func TestMemoryFSContent(t *testing.T) {
+	fs := newTestMemoryFilesystem(t, "")
+
+	fd, err := fs.Create("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fd.Write([]byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fd.WriteAt([]byte("world"), 10); err != nil {
+		t.Fatal(err)
+	}
+	fd.Close()
+	if _, err := fd.Write([]byte("x")); !errors.Is(err, os.ErrClosed) {
+		t.Errorf("write after close: got %v", err)
+	}
+
+	fd, err = fs.Open("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	bs, err := io.ReadAll(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := []byte("hello\x00\x00\x00\x00\x00world"); !bytes.Equal(bs, exp) {
+		t.Errorf("got %q, expected %q", bs, exp)
+	}
+	if _, err := fd.Write([]byte("x")); !errors.Is(err, syscall.EBADF) {
+		t.Errorf("write to read only file: got %v", err)
+	}
+
+	// Opening for appending
+	fd, err = fs.OpenFile("file", os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fd.Write([]byte("!")); err != nil {
+		t.Fatal(err)
+	}
+	fd.Close()
+	if info, err := fs.Stat("file"); err != nil || info.Size() != 16 {
+		t.Errorf("unexpected size after append: %v, %v", info, err)
+	}
+
+	if _, err := fs.OpenFile("file", os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644); !IsExist(err) {
+		t.Errorf("exclusive create of existing file: got %v", err)
+	}
+}
+
+func TestMemoryFSErrors(t *testing.T) {
+	fs := newTestMemoryFilesystem(t, "")
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(fs.MkdirAll("a/b", 0o755))
+	fd, err := fs.Create("a/b/file")
+	must(err)
+	fd.Close()
+
+	cases := []struct {
+		name string
+		err  error
+		exp  error
+	}{
+		{"missing", fs.Remove("a/missing"), ErrNotExist},
+		{"not empty", fs.Remove("a"), syscall.ENOTEMPTY},
+		{"not a directory", fs.Mkdir("a/b/file/c", 0o755), syscall.ENOTDIR},
+		{"exists", fs.Mkdir("a/b", 0o755), ErrExist},
+		{"into itself", fs.Rename("a", "a/b/c"), syscall.EINVAL},
+		{"dir over file", fs.Rename("a/b", "a/b/file"), syscall.ENOTDIR},
+		{"escaping", fs.Mkdir("../outside", 0o755), errPathTraversingUpwards},
+	}
+	for _, tc := range cases {
+		if !errors.Is(tc.err, tc.exp) {
+			t.Errorf("%s: got %v, expected %v", tc.name, tc.err, tc.exp)
+		}
+	}
+
+	must(fs.RemoveAll("a"))
+	if _, err := fs.Lstat("a/b/file"); !IsNotExist(err) {
+		t.Errorf("expected everything removed, got %v", err)
+	}
+	must(fs.RemoveAll("a"))
+}
+
+func TestMemoryFSSymlinks(t *testing.T) {
+	fs := newTestMemoryFilesystem(t, "")
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(fs.MkdirAll("dir/sub", 0o755))
+	fd, err := fs.Create("dir/sub/file")
+	must(err)
+	_, err = fd.Write([]byte("content"))
+	must(err)
+	fd.Close()
+
+	must(fs.CreateSymlink("sub", "dir/rel"))
+	must(fs.CreateSymlink("/dir/sub/file", "abs"))
+	must(fs.CreateSymlink("loop2", "loop1"))
+	must(fs.CreateSymlink("loop1", "loop2"))
+
+	if target, err := fs.ReadSymlink("dir/rel"); err != nil || target != "sub" {
+		t.Errorf("ReadSymlink: got %q, %v", target, err)
+	}
+	if info, err := fs.Lstat("abs"); err != nil || !info.IsSymlink() {
+		t.Errorf("Lstat should not follow: %v, %v", info, err)
+	}
+	if info, err := fs.Stat("abs"); err != nil || !info.IsRegular() || info.Size() != 7 {
+		t.Errorf("Stat should follow: %v, %v", info, err)
+	}
+	if info, err := fs.Lstat("dir/rel/file"); err != nil || !info.IsRegular() {
+		t.Errorf("symlinks in the path should be followed: %v, %v", info, err)
+	}
+	if _, err := fs.Stat("loop1"); !errors.Is(err, syscall.ELOOP) {
+		t.Errorf("expected a symlink loop, got %v", err)
+	}
+
+	// Removing the link leaves the target
+	must(fs.Remove("dir/rel"))
+	if _, err := fs.Lstat("dir/sub/file"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMemoryFSHardLinks(t *testing.T) {
+	fs := newTestMemoryFilesystem(t, "")
+	fd, err := fs.Create("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd.Close()
+	if err := fs.CreateHardLink("a", "b"); err != nil {
+		t.Fatal(err)
+	}
+
+	fd, err = fs.OpenFile("b", os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := fd.Write([]byte("shared")); err != nil {
+		t.Fatal(err)
+	}
+	fd.Close()
+
+	a, _ := fs.Lstat("a")
+	b, _ := fs.Lstat("b")
+	if a.Size() != 6 || !fs.SameFile(a, b) {
+		t.Errorf("expected the same file, got %v and %v", a, b)
+	}
+	if err := fs.Remove("a"); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := fs.Lstat("b"); err != nil || b.Size() != 6 {
+		t.Errorf("link should survive: %v, %v", b, err)
+	}
+}
+
+func TestMemoryFSXattrs(t *testing.T) {
+	fs := newTestMemoryFilesystem(t, "")
+	fd, err := fs.Create("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd.Close()
+
+	attrs := []protocol.Xattr{
+		{Name: "user.test-b", Value: []byte("2")},
+		{Name: "user.test-a", Value: []byte("1")},
+		{Name: "security.other", Value: []byte("3")},
+	}
+	if err := fs.SetXattr("file", attrs, testXattrFilter{}); err != nil {
+		t.Fatal(err)
+	}
+	res, err := fs.GetXattr("file", testXattrFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(res))
+	for i, xa := range res {
+		names[i] = xa.Name
+	}
+	if !slices.Equal(names, []string{"user.test-a", "user.test-b"}) {
+		t.Errorf("unexpected attributes %v", names)
+	}
+
+	// Setting a new set removes what's permitted and not in it
+	if err := fs.SetXattr("file", attrs[:1], testXattrFilter{}); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := fs.GetXattr("file", testXattrFilter{}); len(res) != 1 || res[0].Name != "user.test-b" {
+		t.Errorf("unexpected attributes %v", res)
+	}
+	if res, _ := fs.GetXattr("file", noXattrFilter{}); len(res) != 2 {
+		t.Errorf("attribute not permitted by the filter should remain, got %v", res)
+	}
+}
+
+type noXattrFilter struct{}
+
+func (noXattrFilter) Permit(string) bool         { return true }
+func (noXattrFilter) GetMaxSingleEntrySize() int { return 0 }
+func (noXattrFilter) GetMaxTotalSize() int       { return 0 }
+
+func TestMemoryFSNormalization(t *testing.T) {
+	const nfc, nfd = "\u00e5", "a\u030a"
+	fs := newTestMemoryFilesystem(t, "?norm=nfd")
+
+	fd, err := fs.Create(nfc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fd.Close()
+
+	if names, _ := fs.DirNames("."); !slices.Contains(names, nfd) || slices.Contains(names, nfc) {
+		t.Errorf("expected the name stored decomposed, got %q", names)
+	}
+	for _, name := range []string{nfc, nfd} {
+		if _, err := fs.Lstat(name); err != nil {
+			t.Errorf("%q: %v", name, err)
+		}
+	}
+	if _, err := fs.Lstat("\u00c5"); !IsNotExist(err) {
+		t.Errorf("should be case sensitive, got %v", err)
+	}
+}
+
+func TestMemoryFSCapacity(t *testing.T) {
+	fs := newTestMemoryFilesystem(t, "?size=1")
+
+	fd, err := fs.Create("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	if err := fd.Truncate(1 << 19); err != nil {
+		t.Fatal(err)
+	}
+	if usage, err := fs.Usage("."); err != nil || usage.Total != 1<<20 || usage.Free != 1<<19 {
+		t.Errorf("unexpected usage %+v, %v", usage, err)
+	}
+	if _, err := fd.WriteAt([]byte("x"), 1<<20); !errors.Is(err, syscall.ENOSPC) {
+		t.Errorf("expected out of space, got %v", err)
+	}
+
+	if err := fs.Remove("file"); err != nil {
+		t.Fatal(err)
+	}
+	if usage, _ := fs.Usage("."); usage.Free != 1<<20 {
+		t.Errorf("space not given back: %+v", usage)
+	}
+}
+
+func TestMemoryFSWalk(t *testing.T) {
+	fs := NewFilesystem(FilesystemTypeMemory, t.Name())
+	if err := fs.MkdirAll("a/b", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.CreateSymlink("b", "a/c"); err != nil {
+		t.Fatal(err)
+	}
+
+	var walked []string
+	err := fs.Walk(".", func(path string, _ FileInfo, err error) error {
+		walked = append(walked, path)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []string{".", "a", "a/b", "a/c"}
+	for i := range exp {
+		exp[i] = filepath.FromSlash(exp[i])
+	}
+	if !slices.Equal(walked, exp) {
+		t.Errorf("walked %v, expected %v", walked, exp)
+	}
+}
@@ -43,6 +43,7 @@ const (
 	metricOpChtimes           = "chtimes"
 	metricOpCreate            = "create"
 	metricOpCreateSymlink     = "createsymlink"
+	metricOpCreateHardLink    = "createhardlink"
 	metricOpDirNames          = "dirnames"
 	metricOpLstat             = "lstat"
 	metricOpMkdir             = "mdkir"
@@ -131,6 +132,11 @@ func (m *metricsFS) CreateSymlink(target, name string) error {
 	return m.next.CreateSymlink(target, name)
 }
 
+func (m *metricsFS) CreateHardLink(target, name string) error {
+	defer m.account(metricOpCreateHardLink)(-1)
+	return m.next.CreateHardLink(target, name)
+}
+
 func (m *metricsFS) DirNames(name string) ([]string, error) {
 	defer m.account(metricOpDirNames)(-1)
 	return m.next.DirNames(name)
@@ -0,0 +1,75 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/shirou/gopsutil/v4/disk"
+)
+
+// networkFilesystemTypes are the filesystem type names, as reported by
+// gopsutil, of mounts that are backed by a remote server and on which
+// kernel change notifications are unreliable.
+var networkFilesystemTypes = []string{
+	"9p", "afpfs", "afs", "cifs", "coda", "fuse.sshfs", "ncpfs",
+	"nfs", "nfs4", "smb", "smb2", "smbfs", "webdav",
+}
+
+// IsNetworkFilesystem reports whether the given filesystem lives on a
+// network mount (SMB, NFS and the like), along with the detected
+// filesystem type. Only basic filesystems are ever considered to be on the
+// network.
+func IsNetworkFilesystem(fs Filesystem) (bool, string) {
+	basic, ok := unwrapFilesystem[*BasicFilesystem](fs)
+	if !ok {
+		return false, ""
+	}
+	if isUNCPath(basic.root) {
+		return true, "unc"
+	}
+	fstype := basicFilesystemType(basic)
+	return slices.Contains(networkFilesystemTypes, fstype), fstype
+}
+
+// removableFilesystemTypes are the filesystem type names of the FAT family,
+// as found on SD cards and USB sticks, which cope badly with many files
+// being written at once.
+var removableFilesystemTypes = []string{
+	"exfat", "fat", "fat32", "msdos", "vfat",
+}
+
+// IsRemovableMediaFilesystem reports whether the given filesystem is
+// formatted as typical for removable media, along with the detected
+// filesystem type.
+func IsRemovableMediaFilesystem(fs Filesystem) (bool, string) {
+	basic, ok := unwrapFilesystem[*BasicFilesystem](fs)
+	if !ok {
+		return false, ""
+	}
+	fstype := basicFilesystemType(basic)
+	return slices.Contains(removableFilesystemTypes, fstype), fstype
+}
+
+func basicFilesystemType(basic *BasicFilesystem) string {
+	u, err := disk.Usage(basic.root)
+	if err != nil {
+		l.Debugln(basic.Type(), basic.URI(), "Failed to detect filesystem type:", err)
+		return ""
+	}
+	return strings.ToLower(u.Fstype)
+}
+
+// isUNCPath returns true for Windows paths of the form \\server\share,
+// including their long filename variant \\?\UNC\server\share.
+func isUNCPath(path string) bool {
+	if strings.HasPrefix(path, `\\?\`) {
+		return strings.HasPrefix(path, `\\?\UNC\`)
+	}
+	return strings.HasPrefix(path, `\\`)
+}
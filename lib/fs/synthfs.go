@@ -0,0 +1,382 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+const FilesystemTypeSynthetic FilesystemType = "synthetic"
+
+func init() {
+	RegisterFilesystemType(FilesystemTypeSynthetic, func(root string, _ ...Option) (Filesystem, error) {
+		return newSyntheticFilesystem(root), nil
+	})
+}
+
+var errSyntheticReadOnly = errors.New("synthetic filesystem is read only")
+
+// syntheticFS is a read only filesystem for testing and benchmarking with
+// millions of files. Unlike fakeFS nothing is kept in memory: the
+// directory tree, the file metadata and the file contents are all derived
+// from the parameters in the root URI:
+//
+//	files=n    the number of files (default 1000)
+//	fanout=n   the maximum number of entries in a directory (default 100)
+//	size=n     the size of each file, in bytes (default 0)
+//	seed=n     to vary the modification times (default 0)
+//	changes=n  the number of change events sent to a watcher (default 0)
+//
+// The files are in directories at the same depth, named like
+// "d1/d23/f45". Change events are for pseudorandomly chosen files.
+type syntheticFS struct {
+	uri     string
+	files   int
+	fanout  int
+	size    int64
+	seed    int64
+	changes int
+
+	depth  int   // number of directory levels below the root
+	leaves int   // number of directories containing files
+	span   []int // number of leaves below a directory at each level
+}
+
+func newSyntheticFilesystem(root string) *syntheticFS {
+	var params url.Values
+	if uri, err := url.Parse(root); err == nil {
+		params = uri.Query()
+	}
+	intParam := func(name string, def int) int {
+		if v, err := strconv.Atoi(params.Get(name)); err == nil {
+			return v
+		}
+		return def
+	}
+
+	fs := &syntheticFS{
+		uri:     root,
+		files:   max(intParam("files", 1000), 0),
+		fanout:  max(intParam("fanout", 100), 2),
+		size:    int64(max(intParam("size", 0), 0)),
+		seed:    int64(intParam("seed", 0)),
+		changes: max(intParam("changes", 0), 0),
+	}
+	fs.leaves = max((fs.files+fs.fanout-1)/fs.fanout, 1)
+	for n := 1; n < fs.leaves; n *= fs.fanout {
+		fs.depth++
+	}
+	fs.span = make([]int, fs.depth+1)
+	fs.span[fs.depth] = 1
+	for i := fs.depth - 1; i >= 0; i-- {
+		fs.span[i] = fs.span[i+1] * fs.fanout
+	}
+	return fs
+}
+
+// syntheticEntry identifies an entry by its level in the tree and its
+// index among the entries at that level. Files are at level depth+1.
+type syntheticEntry struct {
+	level int
+	index int
+}
+
+func (fs *syntheticFS) isFile(e syntheticEntry) bool {
+	return e.level > fs.depth
+}
+
+func (fs *syntheticFS) lookup(name string) (syntheticEntry, error) {
+	name = strings.Trim(filepath.ToSlash(name), "/")
+	if name == "" || name == "." {
+		return syntheticEntry{}, nil
+	}
+	comps := strings.Split(name, "/")
+	if len(comps) > fs.depth+1 {
+		return syntheticEntry{}, ErrNotExist
+	}
+
+	var e syntheticEntry
+	for _, comp := range comps {
+		prefix := "d"
+		if e.level == fs.depth {
+			prefix = "f"
+		}
+		n, err := strconv.Atoi(strings.TrimPrefix(comp, prefix))
+		if err != nil || !strings.HasPrefix(comp, prefix) || n < 0 || n >= fs.fanout || comp != prefix+strconv.Itoa(n) {
+			return syntheticEntry{}, ErrNotExist
+		}
+		e = syntheticEntry{level: e.level + 1, index: e.index*fs.fanout + n}
+		if !fs.exists(e) {
+			return syntheticEntry{}, ErrNotExist
+		}
+	}
+	return e, nil
+}
+
+func (fs *syntheticFS) exists(e syntheticEntry) bool {
+	if fs.isFile(e) {
+		return e.index < fs.files
+	}
+	return e.index*fs.span[e.level] < fs.leaves
+}
+
+// fileName returns the path of the file with the given index.
+func (fs *syntheticFS) fileName(index int) string {
+	comps := make([]string, fs.depth+1)
+	comps[fs.depth] = "f" + strconv.Itoa(index%fs.fanout)
+	for i, leaf := fs.depth-1, index/fs.fanout; i >= 0; i, leaf = i-1, leaf/fs.fanout {
+		comps[i] = "d" + strconv.Itoa(leaf%fs.fanout)
+	}
+	return path.Join(comps...)
+}
+
+func (fs *syntheticFS) hash(name string) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d/%s", fs.seed, name)
+	return h.Sum64()
+}
+
+func (fs *syntheticFS) entry(name string, e syntheticEntry) *fakeEntry {
+	name = filepath.ToSlash(name)
+	if fs.isFile(e) {
+		return &fakeEntry{
+			name:      path.Base(name),
+			entryType: fakeEntryTypeFile,
+			size:      fs.size,
+			mode:      0o644,
+			mtime:     time.Unix(1500000000+int64(fs.hash(name)%(1<<24)), 0),
+		}
+	}
+	return &fakeEntry{
+		name:      path.Base(name),
+		entryType: fakeEntryTypeDir,
+		mode:      0o755,
+		mtime:     time.Unix(1500000000, 0),
+	}
+}
+
+func (*syntheticFS) Chmod(_ string, _ FileMode) error {
+	return errSyntheticReadOnly
+}
+
+func (*syntheticFS) Lchown(_, _, _ string) error {
+	return errSyntheticReadOnly
+}
+
+func (*syntheticFS) Chtimes(_ string, _ time.Time, _ time.Time) error {
+	return errSyntheticReadOnly
+}
+
+func (*syntheticFS) Create(_ string) (File, error) {
+	return nil, errSyntheticReadOnly
+}
+
+func (*syntheticFS) CreateSymlink(_, _ string) error {
+	return errSyntheticReadOnly
+}
+
+func (*syntheticFS) CreateHardLink(_, _ string) error {
+	return errSyntheticReadOnly
+}
+
+func (fs *syntheticFS) DirNames(name string) ([]string, error) {
+	e, err := fs.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	if fs.isFile(e) {
+		return nil, errors.New("not a directory")
+	}
+
+	prefix := "d"
+	if e.level == fs.depth {
+		prefix = "f"
+	}
+	names := make([]string, 0, fs.fanout)
+	for i := range fs.fanout {
+		child := syntheticEntry{level: e.level + 1, index: e.index*fs.fanout + i}
+		if !fs.exists(child) {
+			break
+		}
+		names = append(names, prefix+strconv.Itoa(i))
+	}
+	return names, nil
+}
+
+func (fs *syntheticFS) Lstat(name string) (FileInfo, error) {
+	e, err := fs.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeFileInfo{*fs.entry(name, e)}, nil
+}
+
+func (*syntheticFS) Mkdir(_ string, _ FileMode) error {
+	return errSyntheticReadOnly
+}
+
+func (*syntheticFS) MkdirAll(_ string, _ FileMode) error {
+	return errSyntheticReadOnly
+}
+
+func (fs *syntheticFS) Open(name string) (File, error) {
+	e, err := fs.lookup(name)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeFile{
+		fakeEntry: fs.entry(name, e),
+		mut:       new(sync.Mutex),
+		seed:      int64(fs.hash(filepath.ToSlash(name)) | 1), //nolint:gosec
+	}, nil
+}
+
+func (fs *syntheticFS) OpenFile(name string, flags int, _ FileMode) (File, error) {
+	if flags&(OptWriteOnly|OptReadWrite|OptCreate|OptAppend|OptTruncate) != 0 {
+		return nil, errSyntheticReadOnly
+	}
+	return fs.Open(name)
+}
+
+func (*syntheticFS) ReadSymlink(_ string) (string, error) {
+	return "", errors.New("not a symlink")
+}
+
+func (*syntheticFS) Remove(_ string) error {
+	return errSyntheticReadOnly
+}
+
+func (*syntheticFS) RemoveAll(_ string) error {
+	return errSyntheticReadOnly
+}
+
+func (*syntheticFS) Rename(_, _ string) error {
+	return errSyntheticReadOnly
+}
+
+func (fs *syntheticFS) Stat(name string) (FileInfo, error) {
+	return fs.Lstat(name)
+}
+
+func (*syntheticFS) SymlinksSupported() bool {
+	return false
+}
+
+func (*syntheticFS) Walk(_ string, _ WalkFunc) error {
+	return errors.New("not implemented")
+}
+
+// Watch sends change events for the configured number of files, as fast
+// as they are received, and then waits for the context to be cancelled.
+func (fs *syntheticFS) Watch(name string, ignore Matcher, ctx context.Context, _ bool) (<-chan Event, <-chan error, error) {
+	if fs.changes == 0 || fs.files == 0 {
+		return nil, nil, ErrWatchNotSupported
+	}
+	prefix := strings.Trim(filepath.ToSlash(name), "/")
+	if prefix == "." {
+		prefix = ""
+	}
+
+	events := make(chan Event)
+	errs := make(chan error)
+	go func() {
+		for i := range fs.changes {
+			file := fs.fileName(int(fs.hash(strconv.Itoa(i)) % uint64(fs.files)))
+			if prefix != "" && !strings.HasPrefix(file, prefix+"/") {
+				continue
+			}
+			if ignore != nil && ignore.Match(file).IsIgnored() {
+				continue
+			}
+			select {
+			case events <- Event{Name: filepath.FromSlash(file), Type: NonRemove}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, errs, nil
+}
+
+func (*syntheticFS) Hide(_ string) error {
+	return nil
+}
+
+func (*syntheticFS) Unhide(_ string) error {
+	return nil
+}
+
+func (fs *syntheticFS) Glob(pattern string) ([]string, error) {
+	dir, file := filepath.Split(pattern)
+	names, err := fs.DirNames(dir)
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, n := range names {
+		matched, err := filepath.Match(file, n)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			matches = append(matches, filepath.Join(dir, n))
+		}
+	}
+	return matches, nil
+}
+
+func (*syntheticFS) Roots() ([]string, error) {
+	return []string{"/"}, nil
+}
+
+func (*syntheticFS) Usage(_ string) (Usage, error) {
+	return Usage{}, errors.New("not implemented")
+}
+
+func (*syntheticFS) Type() FilesystemType {
+	return FilesystemTypeSynthetic
+}
+
+func (fs *syntheticFS) URI() string {
+	return fs.uri
+}
+
+func (*syntheticFS) Options() []Option {
+	return nil
+}
+
+func (*syntheticFS) SameFile(fi1, fi2 FileInfo) bool {
+	return fi1.Name() == fi2.Name() && fi1.ModTime().Equal(fi2.ModTime()) && fi1.IsDir() == fi2.IsDir()
+}
+
+func (*syntheticFS) PlatformData(_ string, _, _ bool, _ XattrFilter) (protocol.PlatformData, error) {
+	return protocol.PlatformData{}, nil
+}
+
+func (*syntheticFS) GetXattr(_ string, _ XattrFilter) ([]protocol.Xattr, error) {
+	return nil, nil
+}
+
+func (*syntheticFS) SetXattr(_ string, _ []protocol.Xattr, _ XattrFilter) error {
+	return errSyntheticReadOnly
+}
+
+func (*syntheticFS) underlying() (Filesystem, bool) {
+	return nil, false
+}
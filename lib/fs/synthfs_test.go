@@ -0,0 +1,197 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestSyntheticFSTree(t *testing.T) {
+	cases := []struct {
+		files, fanout, depth int
+	}{
+		{0, 10, 0},
+		{7, 10, 0},
+		{10, 10, 0},
+		{11, 10, 1},
+		{1000, 10, 2},
+		{1234, 10, 3},
+	}
+	for _, tc := range cases {
+		fs := newSyntheticFilesystem(fmtSyntheticURI(tc.files, tc.fanout, 0))
+		if fs.depth != tc.depth {
+			t.Errorf("%d files, fanout %d: depth %d, expected %d", tc.files, tc.fanout, fs.depth, tc.depth)
+		}
+
+		files := 0
+		err := NewWalkFilesystem(fs).Walk(".", func(path string, info FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsRegular() {
+				files++
+				if len(PathComponents(path)) != fs.depth+1 {
+					t.Errorf("%s: file at unexpected depth", path)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if files != tc.files {
+			t.Errorf("%d files, fanout %d: walked %d files", tc.files, tc.fanout, files)
+		}
+
+		// Every file can be found by index.
+		for i := 0; i < tc.files; i += max(tc.files/50, 1) {
+			if _, err := fs.Lstat(fs.fileName(i)); err != nil {
+				t.Errorf("file %d: %v", i, err)
+			}
+		}
+	}
+}
+
+func TestSyntheticFSLookup(t *testing.T) {
+	fs := newSyntheticFilesystem(fmtSyntheticURI(1234, 10, 0))
+
+	for _, name := range []string{"d1", "d1/d2", "d1/d2/d3", "d1/d2/d3/f3", "d1/d2/d3/"} {
+		if _, err := fs.Lstat(name); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	for _, name := range []string{"d2", "d1/d3", "d1/d2/d3/f5", "f1", "d01", "d1/d2/d3/f4/x", "d1/d2/d3/d4", "foo"} {
+		if _, err := fs.Lstat(name); !errors.Is(err, ErrNotExist) {
+			t.Errorf("%s: expected not to exist, got %v", name, err)
+		}
+	}
+
+	if err := fs.Mkdir("d1/new", 0o755); err == nil {
+		t.Error("expected the filesystem to be read only")
+	}
+	if _, err := fs.OpenFile("d0/d0/d0/f0", OptReadWrite, 0o644); err == nil {
+		t.Error("expected the filesystem to be read only")
+	}
+}
+
+func TestSyntheticFSContent(t *testing.T) {
+	fs := newSyntheticFilesystem("?files=100&fanout=10&size=100000&seed=1")
+	read := func(name string) []byte {
+		t.Helper()
+		fd, err := fs.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer fd.Close()
+		bs, err := io.ReadAll(fd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(bs) != 100000 {
+			t.Fatalf("%s: read %d bytes", name, len(bs))
+		}
+		return bs
+	}
+
+	// Contents are stable for a name, and differ between names.
+	if string(read("d1/f2")) != string(read("d1/f2")) {
+		t.Error("contents changed between reads")
+	}
+	if string(read("d1/f2")) == string(read("d2/f2")) {
+		t.Error("different files have the same contents")
+	}
+}
+
+func TestSyntheticFSWatch(t *testing.T) {
+	fs := newSyntheticFilesystem("?files=1000&fanout=10&changes=100")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, _, err := fs.Watch("d1", nil, ctx, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	timeout := time.NewTimer(100 * time.Millisecond)
+	n := 0
+loop:
+	for {
+		select {
+		case ev := <-events:
+			if !filepath.IsLocal(ev.Name) || PathComponents(ev.Name)[0] != "d1" {
+				t.Errorf("event outside the watched directory: %v", ev.Name)
+			}
+			if _, err := fs.Lstat(ev.Name); err != nil {
+				t.Errorf("event for a file that doesn't exist: %v", err)
+			}
+			n++
+		case <-timeout.C:
+			break loop
+		}
+	}
+	// About a tenth of the changes are in the directory.
+	if n == 0 || n > 30 {
+		t.Errorf("got %d events", n)
+	}
+}
+
+// TestSyntheticFSMillions walks a million files, checking that memory usage
+// doesn't grow with the number of files.
+func TestSyntheticFSMillions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("slow test")
+	}
+
+	fs := NewWalkFilesystem(newSyntheticFilesystem(fmtSyntheticURI(1000000, 100, 0)))
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	files := 0
+	err := fs.Walk(".", func(_ string, info FileInfo, err error) error {
+		if err == nil && info.IsRegular() {
+			files++
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+
+	if files != 1000000 {
+		t.Errorf("walked %d files", files)
+	}
+	if growth := int64(after.HeapInuse) - int64(before.HeapInuse); growth > 16<<20 {
+		t.Errorf("heap grew by %d bytes", growth)
+	}
+}
+
+func BenchmarkSyntheticFSWalk(b *testing.B) {
+	fs := NewWalkFilesystem(newSyntheticFilesystem(fmtSyntheticURI(100000, 100, 0)))
+	b.ReportAllocs()
+	for range b.N {
+		err := fs.Walk(".", func(_ string, _ FileInfo, err error) error {
+			return err
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(100000*float64(b.N)/b.Elapsed().Seconds(), "files/s")
+}
+
+func fmtSyntheticURI(files, fanout, size int) string {
+	return fmt.Sprintf("?files=%d&fanout=%d&size=%d", files, fanout, size)
+}
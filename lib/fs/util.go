@@ -7,6 +7,7 @@
 package fs
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -80,6 +81,15 @@ func WindowsInvalidFilename(name string) error {
 	return nil
 }
 
+// IsErrInvalidFilename returns true if the error is due to a name that
+// isn't valid on this platform, as returned by WindowsInvalidFilename.
+func IsErrInvalidFilename(err error) bool {
+	return errors.Is(err, errInvalidFilenameEmpty) ||
+		errors.Is(err, errInvalidFilenameWindowsSpacePeriod) ||
+		errors.Is(err, errInvalidFilenameWindowsReservedName) ||
+		errors.Is(err, errInvalidFilenameWindowsReservedChar)
+}
+
 // SanitizePath takes a string that might contain all kinds of special
 // characters and makes a valid, similar, path name out of it.
 //
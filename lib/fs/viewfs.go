@@ -0,0 +1,356 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+const FilesystemTypeView FilesystemType = "view"
+
+func init() {
+	RegisterFilesystemType(FilesystemTypeView, func(root string, _ ...Option) (Filesystem, error) {
+		return newViewFilesystem(root)
+	})
+}
+
+var errViewReadOnly = errors.New("view is read only")
+
+// A ViewMount places a directory of another filesystem at a path in a view.
+type ViewMount struct {
+	Path   string // slash separated, e.g. "Photos/2024"
+	FSType FilesystemType
+	Root   string
+}
+
+// ViewURI returns the root URI of a view composed of the mounts, for
+// NewFilesystem with FilesystemTypeView. The marker, if given, is presented
+// as a directory at the root of the view.
+func ViewURI(mounts []ViewMount, marker string) string {
+	params := make(url.Values)
+	for _, m := range mounts {
+		params.Add("path", m.Path)
+		params.Add("type", string(m.FSType))
+		params.Add("root", m.Root)
+	}
+	if marker != "" {
+		params.Set("marker", marker)
+	}
+	return "view:?" + params.Encode()
+}
+
+// viewFS is a read only filesystem composed of directories of other
+// filesystems, without copying any data. The directories above the mounts
+// are virtual, containing nothing but the way to the mounts.
+type viewFS struct {
+	uri    string
+	marker string
+	mounts []viewMount // sorted by path
+}
+
+type viewMount struct {
+	path string // slash separated, clean
+	fs   Filesystem
+}
+
+func newViewFilesystem(root string) (*viewFS, error) {
+	uri, err := url.Parse(root)
+	if err != nil {
+		return nil, err
+	}
+	params := uri.Query()
+	paths, types, roots := params["path"], params["type"], params["root"]
+	if len(types) != len(paths) || len(roots) != len(paths) {
+		return nil, errors.New("view: mismatched mount parameters")
+	}
+
+	fs := &viewFS{
+		uri:    root,
+		marker: params.Get("marker"),
+	}
+	for i, p := range paths {
+		p = path.Clean(strings.Trim(filepath.ToSlash(p), "/"))
+		if p == "." || p == ".." || strings.HasPrefix(p, "../") {
+			return nil, fmt.Errorf("view: invalid mount path %q", paths[i])
+		}
+		fs.mounts = append(fs.mounts, viewMount{
+			path: p,
+			fs:   NewFilesystem(FilesystemType(types[i]), roots[i]),
+		})
+	}
+	slices.SortFunc(fs.mounts, func(a, b viewMount) int {
+		return strings.Compare(a.path, b.path)
+	})
+	for i := 1; i < len(fs.mounts); i++ {
+		if prev := fs.mounts[i-1].path; fs.mounts[i].path == prev || strings.HasPrefix(fs.mounts[i].path, prev+"/") {
+			return nil, fmt.Errorf("view: overlapping mounts %q and %q", prev, fs.mounts[i].path)
+		}
+	}
+	return fs, nil
+}
+
+// resolve returns the filesystem and the name in it for a name within a
+// mount. Otherwise virtual tells whether the name is a virtual directory
+// leading to mounts.
+func (fs *viewFS) resolve(name string) (mfs Filesystem, rel string, virtual bool) {
+	name = strings.Trim(filepath.ToSlash(name), "/")
+	if name == "" || name == "." {
+		return nil, "", len(fs.mounts) > 0 || fs.marker != ""
+	}
+	name = path.Clean(name)
+	for _, m := range fs.mounts {
+		if name == m.path {
+			return m.fs, ".", false
+		}
+		if strings.HasPrefix(name, m.path+"/") {
+			return m.fs, filepath.FromSlash(strings.TrimPrefix(name, m.path+"/")), false
+		}
+		if strings.HasPrefix(m.path, name+"/") {
+			virtual = true
+		}
+	}
+	if name == fs.marker {
+		virtual = true
+	}
+	return nil, "", virtual
+}
+
+func (fs *viewFS) virtualDir(name string) FileInfo {
+	return viewDirInfo{name: path.Base(filepath.ToSlash(name))}
+}
+
+// viewDirInfo describes a virtual directory of a view, leading to mounts.
+// It's read only and never changes.
+type viewDirInfo struct {
+	name string
+}
+
+func (i viewDirInfo) Name() string {
+	return i.name
+}
+
+func (viewDirInfo) Mode() FileMode {
+	return 0o555 | FileMode(os.ModeDir)
+}
+
+func (viewDirInfo) Size() int64 {
+	return 0
+}
+
+func (viewDirInfo) ModTime() time.Time {
+	return time.Unix(0, 0)
+}
+
+func (viewDirInfo) IsDir() bool {
+	return true
+}
+
+func (viewDirInfo) IsRegular() bool {
+	return false
+}
+
+func (viewDirInfo) IsSymlink() bool {
+	return false
+}
+
+func (viewDirInfo) Owner() int {
+	return 0
+}
+
+func (viewDirInfo) Group() int {
+	return 0
+}
+
+func (viewDirInfo) Sys() interface{} {
+	return nil
+}
+
+func (viewDirInfo) InodeChangeTime() time.Time {
+	return time.Time{}
+}
+
+func (fs *viewFS) Lstat(name string) (FileInfo, error) {
+	mfs, rel, virtual := fs.resolve(name)
+	switch {
+	case mfs != nil:
+		return mfs.Lstat(rel)
+	case virtual:
+		return fs.virtualDir(name), nil
+	default:
+		return nil, ErrNotExist
+	}
+}
+
+func (fs *viewFS) Stat(name string) (FileInfo, error) {
+	mfs, rel, virtual := fs.resolve(name)
+	switch {
+	case mfs != nil:
+		return mfs.Stat(rel)
+	case virtual:
+		return fs.virtualDir(name), nil
+	default:
+		return nil, ErrNotExist
+	}
+}
+
+func (fs *viewFS) DirNames(name string) ([]string, error) {
+	mfs, rel, virtual := fs.resolve(name)
+	switch {
+	case mfs != nil:
+		return mfs.DirNames(rel)
+	case !virtual:
+		return nil, ErrNotExist
+	}
+
+	// The next path component of the mounts below the virtual directory,
+	// and the marker at the root.
+	prefix := strings.Trim(filepath.ToSlash(name), "/")
+	if prefix == "." {
+		prefix = ""
+	}
+	if prefix != "" {
+		prefix = path.Clean(prefix) + "/"
+	}
+	var names []string
+	if prefix == "" && fs.marker != "" {
+		names = append(names, fs.marker)
+	}
+	for _, m := range fs.mounts {
+		if !strings.HasPrefix(m.path, prefix) {
+			continue
+		}
+		next, _, _ := strings.Cut(strings.TrimPrefix(m.path, prefix), "/")
+		if !slices.Contains(names, next) {
+			names = append(names, next)
+		}
+	}
+	return names, nil
+}
+
+func (fs *viewFS) Open(name string) (File, error) {
+	mfs, rel, virtual := fs.resolve(name)
+	switch {
+	case mfs != nil:
+		return mfs.Open(rel)
+	case virtual:
+		return nil, errors.New("is a directory")
+	default:
+		return nil, ErrNotExist
+	}
+}
+
+func (fs *viewFS) OpenFile(name string, flags int, _ FileMode) (File, error) {
+	if flags&(OptWriteOnly|OptReadWrite|OptCreate|OptAppend|OptTruncate) != 0 {
+		return nil, errViewReadOnly
+	}
+	return fs.Open(name)
+}
+
+func (fs *viewFS) ReadSymlink(name string) (string, error) {
+	if mfs, rel, _ := fs.resolve(name); mfs != nil {
+		return mfs.ReadSymlink(rel)
+	}
+	return "", errors.New("not a symlink")
+}
+
+func (fs *viewFS) SameFile(fi1, fi2 FileInfo) bool {
+	return fi1.Name() == fi2.Name() && fi1.Size() == fi2.Size() && fi1.ModTime().Equal(fi2.ModTime()) && fi1.IsDir() == fi2.IsDir()
+}
+
+func (fs *viewFS) PlatformData(name string, withOwnership, withXattrs bool, xattrFilter XattrFilter) (protocol.PlatformData, error) {
+	if mfs, rel, _ := fs.resolve(name); mfs != nil {
+		return mfs.PlatformData(rel, withOwnership, withXattrs, xattrFilter)
+	}
+	return protocol.PlatformData{}, nil
+}
+
+func (fs *viewFS) GetXattr(name string, xattrFilter XattrFilter) ([]protocol.Xattr, error) {
+	if mfs, rel, _ := fs.resolve(name); mfs != nil {
+		return mfs.GetXattr(rel, xattrFilter)
+	}
+	return nil, nil
+}
+
+func (fs *viewFS) Glob(pattern string) ([]string, error) {
+	dir, file := filepath.Split(pattern)
+	names, err := fs.DirNames(dir)
+	if err != nil {
+		return nil, err
+	}
+	var matches []string
+	for _, n := range names {
+		matched, err := filepath.Match(file, n)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			matches = append(matches, filepath.Join(dir, n))
+		}
+	}
+	return matches, nil
+}
+
+func (*viewFS) Chmod(_ string, _ FileMode) error                           { return errViewReadOnly }
+func (*viewFS) Lchown(_, _, _ string) error                                { return errViewReadOnly }
+func (*viewFS) Chtimes(_ string, _ time.Time, _ time.Time) error           { return errViewReadOnly }
+func (*viewFS) Create(_ string) (File, error)                              { return nil, errViewReadOnly }
+func (*viewFS) CreateSymlink(_, _ string) error                            { return errViewReadOnly }
+func (*viewFS) CreateHardLink(_, _ string) error                           { return errViewReadOnly }
+func (*viewFS) Mkdir(_ string, _ FileMode) error                           { return errViewReadOnly }
+func (*viewFS) MkdirAll(_ string, _ FileMode) error                        { return errViewReadOnly }
+func (*viewFS) Remove(_ string) error                                      { return errViewReadOnly }
+func (*viewFS) RemoveAll(_ string) error                                   { return errViewReadOnly }
+func (*viewFS) Rename(_, _ string) error                                   { return errViewReadOnly }
+func (*viewFS) SetXattr(_ string, _ []protocol.Xattr, _ XattrFilter) error { return errViewReadOnly }
+func (*viewFS) Hide(_ string) error                                        { return nil }
+func (*viewFS) Unhide(_ string) error                                      { return nil }
+func (*viewFS) SymlinksSupported() bool                                    { return true }
+
+func (*viewFS) Walk(_ string, _ WalkFunc) error {
+	return errors.New("not implemented")
+}
+
+// Watch isn't supported; the folders the view is composed of are watched
+// in their own right and the view picks up changes on rescan.
+func (*viewFS) Watch(_ string, _ Matcher, _ context.Context, _ bool) (<-chan Event, <-chan error, error) {
+	return nil, nil, ErrWatchNotSupported
+}
+
+func (*viewFS) Roots() ([]string, error) {
+	return []string{"/"}, nil
+}
+
+func (*viewFS) Usage(_ string) (Usage, error) {
+	return Usage{}, errors.New("not implemented")
+}
+
+func (*viewFS) Type() FilesystemType {
+	return FilesystemTypeView
+}
+
+func (fs *viewFS) URI() string {
+	return fs.uri
+}
+
+func (*viewFS) Options() []Option {
+	return nil
+}
+
+func (*viewFS) underlying() (Filesystem, bool) {
+	return nil, false
+}
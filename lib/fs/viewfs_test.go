@@ -0,0 +1,88 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"io"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestViewFS(t *testing.T) {
+	photos, docs := t.TempDir(), t.TempDir()
+	for _, root := range []string{photos, docs} {
+		fs := NewFilesystem(FilesystemTypeBasic, root)
+		if err := fs.MkdirAll(filepath.Join("2024", "sub"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		fd, err := fs.Create(filepath.Join("2024", "sub", "file"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		fd.Write([]byte("data"))
+		fd.Close()
+	}
+
+	fs, err := newViewFilesystem(ViewURI([]ViewMount{
+		{Path: "Photos/2024", FSType: FilesystemTypeBasic, Root: filepath.Join(photos, "2024")},
+		{Path: "Public", FSType: FilesystemTypeBasic, Root: filepath.Join(docs, "2024")},
+	}, ".stfolder"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := fs.DirNames(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{".stfolder", "Photos", "Public"}) {
+		t.Errorf("unexpected root entries %v", names)
+	}
+	for _, name := range []string{"Photos", ".stfolder"} {
+		if info, err := fs.Stat(name); err != nil || !info.IsDir() {
+			t.Errorf("%s: expected virtual directory, got %v, %v", name, info, err)
+		}
+	}
+	if _, err := fs.Stat("Other"); err == nil {
+		t.Error("expected error for name outside the mounts")
+	}
+
+	fd, err := fs.Open(filepath.Join("Photos", "2024", "sub", "file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	bs, err := io.ReadAll(fd)
+	fd.Close()
+	if err != nil || string(bs) != "data" {
+		t.Errorf("read %q, %v", bs, err)
+	}
+	if names, err := fs.DirNames("Public"); err != nil || !slices.Equal(names, []string{"sub"}) {
+		t.Errorf("unexpected mount entries %v, %v", names, err)
+	}
+
+	if _, err := fs.Create(filepath.Join("Public", "new")); err == nil {
+		t.Error("expected create to fail")
+	}
+	if _, err := fs.OpenFile(filepath.Join("Public", "sub", "file"), OptReadWrite, 0o644); err == nil {
+		t.Error("expected open for writing to fail")
+	}
+	if err := fs.Remove(filepath.Join("Public", "sub", "file")); err == nil {
+		t.Error("expected remove to fail")
+	}
+}
+
+func TestViewFSOverlappingMounts(t *testing.T) {
+	_, err := newViewFilesystem(ViewURI([]ViewMount{
+		{Path: "a", FSType: FilesystemTypeFake, Root: "/TestViewFSOverlap/a"},
+		{Path: "a/b", FSType: FilesystemTypeFake, Root: "/TestViewFSOverlap/b"},
+	}, ""))
+	if err == nil {
+		t.Error("expected error for overlapping mounts")
+	}
+}
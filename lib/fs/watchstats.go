@@ -0,0 +1,71 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"sync"
+	"time"
+)
+
+// WatchStats counts what a watch did since it started. Each overflow means
+// events were lost, and a scan of what they were about was requested in
+// their place.
+type WatchStats struct {
+	Started   time.Time
+	Processed int64
+	Dropped   int64
+	Overflows int64
+}
+
+// Sub returns the counts since the earlier stats of the same watch, or all
+// of them if the watch was restarted since.
+func (s WatchStats) Sub(earlier WatchStats) WatchStats {
+	if !s.Started.Equal(earlier.Started) {
+		return s
+	}
+	return WatchStats{
+		Started:   s.Started,
+		Processed: s.Processed - earlier.Processed,
+		Dropped:   s.Dropped - earlier.Dropped,
+		Overflows: s.Overflows - earlier.Overflows,
+	}
+}
+
+var (
+	watchStatsMut sync.Mutex
+	watchStats    = make(map[string]func() WatchStats)
+)
+
+func watchStatsKey(fs Filesystem, name string) string {
+	return fs.Type().String() + "|" + fs.URI() + "|" + name
+}
+
+// registerWatchStats makes the stats of a running watch available, until
+// the returned function is called.
+func registerWatchStats(fs Filesystem, name string, stats func() WatchStats) func() {
+	key := watchStatsKey(fs, name)
+	watchStatsMut.Lock()
+	watchStats[key] = stats
+	watchStatsMut.Unlock()
+	return func() {
+		watchStatsMut.Lock()
+		delete(watchStats, key)
+		watchStatsMut.Unlock()
+	}
+}
+
+// GetWatchStats returns the stats of the running watch of name on the
+// filesystem, if there is one.
+func GetWatchStats(fs Filesystem, name string) (WatchStats, bool) {
+	watchStatsMut.Lock()
+	stats, ok := watchStats[watchStatsKey(fs, name)]
+	watchStatsMut.Unlock()
+	if !ok {
+		return WatchStats{}, false
+	}
+	return stats(), true
+}
@@ -0,0 +1,47 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package fs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWatchStats(t *testing.T) {
+	fs := newFakeFilesystem(t.Name())
+	if _, ok := GetWatchStats(fs, "."); ok {
+		t.Fatal("stats without a watch")
+	}
+
+	metrics := newWatchMetrics()
+	unregister := registerWatchStats(fs, ".", metrics.stats)
+	metrics.recordEvent()
+	metrics.recordOverflow()
+	stats, ok := GetWatchStats(fs, ".")
+	if !ok || stats.Processed != 1 || stats.Overflows != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	unregister()
+	if _, ok := GetWatchStats(fs, "."); ok {
+		t.Error("stats after the watch stopped")
+	}
+}
+
+func TestWatchStatsSub(t *testing.T) {
+	started := time.Now()
+	prev := WatchStats{Started: started, Processed: 10, Dropped: 1, Overflows: 1}
+	cur := WatchStats{Started: started, Processed: 15, Dropped: 1, Overflows: 2}
+	if delta := cur.Sub(prev); delta.Processed != 5 || delta.Dropped != 0 || delta.Overflows != 1 {
+		t.Errorf("unexpected delta %+v", delta)
+	}
+
+	// A restarted watch counts from zero
+	restarted := WatchStats{Started: started.Add(time.Second), Processed: 3}
+	if delta := restarted.Sub(cur); delta != restarted {
+		t.Errorf("expected %+v after restart, got %+v", restarted, delta)
+	}
+}
@@ -7,6 +7,7 @@
 package ignore
 
 import (
+	"strings"
 	"time"
 
 	"github.com/syncthing/syncthing/lib/ignore/ignoreresult"
@@ -18,44 +19,107 @@ type nower interface {
 
 var clock = nower(defaultClock{})
 
+// The cache holds match results per directory, so that everything below a
+// directory can be dropped at once when it goes away.
 type cache struct {
-	entries map[string]cacheEntry
+	dirs map[string]map[string]cacheEntry // directory -> name -> entry
 }
 
 type cacheEntry struct {
-	result ignoreresult.R
-	access int64 // Unix nanosecond count. Sufficient until the year 2262.
+	result  ignoreresult.R
+	pattern int   // index of the pattern that matched, or -1 for none
+	access  int64 // Unix nanosecond count. Sufficient until the year 2262.
 }
 
 func newCache() *cache {
 	return &cache{
-		entries: make(map[string]cacheEntry),
+		dirs: make(map[string]map[string]cacheEntry),
 	}
 }
 
+func splitKey(key string) (string, string) {
+	if i := strings.LastIndexByte(key, '/'); i >= 0 {
+		return key[:i], key[i+1:]
+	}
+	return "", key
+}
+
 func (c *cache) clean(d time.Duration) {
-	for k, v := range c.entries {
-		if clock.Now().Sub(time.Unix(0, v.access)) > d {
-			delete(c.entries, k)
+	for dir, entries := range c.dirs {
+		for k, v := range entries {
+			if clock.Now().Sub(time.Unix(0, v.access)) > d {
+				delete(entries, k)
+			}
+		}
+		if len(entries) == 0 {
+			delete(c.dirs, dir)
 		}
 	}
 }
 
 func (c *cache) get(key string) (ignoreresult.R, bool) {
-	entry, ok := c.entries[key]
+	dir, name := splitKey(key)
+	entries := c.dirs[dir]
+	entry, ok := entries[name]
 	if ok {
 		entry.access = clock.Now().UnixNano()
-		c.entries[key] = entry
+		entries[name] = entry
+		metricCacheLookups.WithLabelValues(metricResultHit).Inc()
+	} else {
+		metricCacheLookups.WithLabelValues(metricResultMiss).Inc()
 	}
 	return entry.result, ok
 }
 
-func (c *cache) set(key string, result ignoreresult.R) {
-	c.entries[key] = cacheEntry{result, time.Now().UnixNano()}
+func (c *cache) set(key string, result ignoreresult.R, pattern int) {
+	dir, name := splitKey(key)
+	entries, ok := c.dirs[dir]
+	if !ok {
+		entries = make(map[string]cacheEntry)
+		c.dirs[dir] = entries
+	}
+	entries[name] = cacheEntry{result, pattern, time.Now().UnixNano()}
+}
+
+// retain drops all results that may change when only the first n patterns
+// stay the same. Those that were decided by one of the first n patterns
+// still hold, as patterns are evaluated in order. It returns the number of
+// dropped results.
+func (c *cache) retain(n int) int {
+	dropped := 0
+	for dir, entries := range c.dirs {
+		for k, v := range entries {
+			if v.pattern < 0 || v.pattern >= n {
+				delete(entries, k)
+				dropped++
+			}
+		}
+		if len(entries) == 0 {
+			delete(c.dirs, dir)
+		}
+	}
+	return dropped
+}
+
+// forgetDir drops the results for everything below the directory. It
+// returns the number of dropped results.
+func (c *cache) forgetDir(dir string) int {
+	dropped := 0
+	prefix := dir + "/"
+	for d, entries := range c.dirs {
+		if d == dir || strings.HasPrefix(d, prefix) {
+			dropped += len(entries)
+			delete(c.dirs, d)
+		}
+	}
+	return dropped
 }
 
 func (c *cache) len() int {
-	l := len(c.entries)
+	l := 0
+	for _, entries := range c.dirs {
+		l += len(entries)
+	}
 	return l
 }
 
@@ -7,9 +7,11 @@
 package ignore
 
 import (
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/syncthing/syncthing/lib/fs"
 	"github.com/syncthing/syncthing/lib/ignore/ignoreresult"
 )
 
@@ -30,8 +32,8 @@ func TestCache(t *testing.T) {
 
 	// Set and check some items
 
-	c.set("true", ignoreresult.IgnoredDeletable)
-	c.set("false", 0)
+	c.set("true", ignoreresult.IgnoredDeletable, 0)
+	c.set("false", 0, -1)
 
 	res, ok = c.get("true")
 	if !res.IsIgnored() || !res.IsDeletable() || !ok {
@@ -85,6 +87,45 @@ func TestCache(t *testing.T) {
 	}
 }
 
+func TestCacheDelta(t *testing.T) {
+	pats := New(fs.NewFilesystem(fs.FilesystemTypeFake, "cachedelta"), WithCache(true))
+	defer pats.Stop()
+	if err := pats.Parse(strings.NewReader("f1\nf2\n"), ".stignore"); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"f1", "f2", "f3", "d/f4", "d/e/f5", "dd/f6"} {
+		pats.Match(file)
+	}
+
+	// Only the results decided by the unchanged first pattern are kept.
+	if err := pats.Parse(strings.NewReader("f1\nf3\n"), ".stignore"); err != nil {
+		t.Fatal(err)
+	}
+	if l := pats.matches.len(); l != 1 {
+		t.Fatalf("expected 1 cached result, got %d", l)
+	}
+	if _, ok := pats.matches.get("f1"); !ok {
+		t.Error("expected f1 to be kept")
+	}
+	if !pats.Match("f3").IsIgnored() || pats.Match("f2").IsIgnored() {
+		t.Error("stale match results after the patterns changed")
+	}
+
+	// Forgetting a directory drops everything below it, and nothing else.
+	for _, file := range []string{"d/f4", "d/e/f5", "dd/f6"} {
+		pats.Match(file)
+	}
+	pats.ForgetDir("d")
+	for _, file := range []string{"d/f4", "d/e/f5"} {
+		if _, ok := pats.matches.get(file); ok {
+			t.Errorf("expected %s to be dropped", file)
+		}
+	}
+	if _, ok := pats.matches.get("dd/f6"); !ok {
+		t.Error("expected dd/f6 to be kept")
+	}
+}
+
 type fakeClock int64 // milliseconds
 
 func (f *fakeClock) Now() time.Time {
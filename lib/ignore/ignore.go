@@ -216,15 +216,31 @@ func (m *Matcher) parseLocked(r io.Reader, file string) error {
 		return err
 	}
 
-	m.curHash = newHash
-	m.patterns = patterns
 	if m.withCache {
-		m.matches = newCache()
+		if m.matches == nil {
+			m.matches = newCache()
+		} else {
+			// Keep the results decided by the patterns that didn't change.
+			dropped := m.matches.retain(commonPatterns(m.patterns, patterns))
+			metricCacheInvalidations.WithLabelValues(metricReasonPatterns).Add(float64(dropped))
+		}
 	}
+	m.curHash = newHash
+	m.patterns = patterns
 
 	return err
 }
 
+// commonPatterns returns the number of leading patterns that are the same
+// in both lists.
+func commonPatterns(a, b []Pattern) int {
+	n := 0
+	for n < len(a) && n < len(b) && a[n].String() == b[n].String() {
+		n++
+	}
+	return n
+}
+
 // Match matches the patterns plus temporary and internal files.
 //
 // The "file" parameter must be in the OS' native unicode format (NFD on macos,
@@ -253,6 +269,7 @@ func (m *Matcher) Match(file string) (result ignoreresult.R) {
 	// Change backslashes to slashes (on Windows only)
 	file = filepath.ToSlash(file)
 
+	matched := -1
 	if m.matches != nil {
 		// Check the cache for a known result.
 		res, ok := m.matches.get(file)
@@ -262,7 +279,7 @@ func (m *Matcher) Match(file string) (result ignoreresult.R) {
 
 		// Update the cache with the result at return time
 		defer func() {
-			m.matches.set(file, result)
+			m.matches.set(file, result, matched)
 		}()
 	}
 
@@ -272,7 +289,7 @@ func (m *Matcher) Match(file string) (result ignoreresult.R) {
 	// anymore.
 	var lowercaseFile string
 	canSkipDir := true
-	for _, pattern := range m.patterns {
+	for i, pattern := range m.patterns {
 		if canSkipDir && !pattern.allowsSkippingIgnoredDirs() {
 			canSkipDir = false
 		}
@@ -286,9 +303,11 @@ func (m *Matcher) Match(file string) (result ignoreresult.R) {
 				lowercaseFile = strings.ToLower(file)
 			}
 			if pattern.match.Match(lowercaseFile) {
+				matched = i
 				return res
 			}
 		} else if pattern.match.Match(file) {
+			matched = i
 			return res
 		}
 	}
@@ -326,6 +345,44 @@ func (m *Matcher) Hash() string {
 	return m.curHash
 }
 
+// IgnoredDirs returns the directories everything below which is ignored,
+// as given by patterns like "/dir/**" without wildcards in the directory.
+// The directories themselves aren't necessarily ignored. As any negated
+// pattern could include something below them again, there are none if
+// there is one of those.
+func (m *Matcher) IgnoredDirs() []string {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	var dirs []string
+	for _, pat := range m.patterns {
+		if !pat.result.IsIgnored() {
+			return nil
+		}
+		if pat.result.IsCaseFolded() {
+			continue
+		}
+		dir, ok := strings.CutSuffix(strings.TrimPrefix(pat.pattern, "/"), "/**")
+		if !ok || dir == "" || strings.ContainsAny(dir, `*?[]{}\`) {
+			continue
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs
+}
+
+// ForgetDir drops the cached match results for everything below the
+// directory, e.g. because it was removed or renamed.
+func (m *Matcher) ForgetDir(dir string) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if m.matches == nil {
+		return
+	}
+	dropped := m.matches.forgetDir(filepath.ToSlash(dir))
+	metricCacheInvalidations.WithLabelValues(metricReasonDirectory).Add(float64(dropped))
+}
+
 func (m *Matcher) Stop() {
 	close(m.stop)
 }
@@ -12,6 +12,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -1294,6 +1295,31 @@ func TestSkipIgnoredDirs(t *testing.T) {
 	}
 }
 
+func TestIgnoredDirs(t *testing.T) {
+	testFs := newTestFS()
+
+	tcs := []struct {
+		stignore string
+		expected []string
+	}{
+		{"/foo\n/bar/baz/\n", []string{"foo", "bar/baz"}},
+		{"qux\n", []string{"qux"}},
+		{"/foo/**\n*.tmp\n/ba*\n(?i)/quux\n", []string{"foo"}},
+		{"/foo\n!/foo/keep\n", nil},
+		{"*.tmp\n", nil},
+	}
+
+	for _, tc := range tcs {
+		pats := New(testFs)
+		if err := pats.Parse(strings.NewReader(tc.stignore), ".stignore"); err != nil {
+			t.Fatal(err)
+		}
+		if got := pats.IgnoredDirs(); !slices.Equal(got, tc.expected) {
+			t.Errorf("%q: got %v, expected %v", tc.stignore, got, tc.expected)
+		}
+	}
+}
+
 func TestEmptyPatterns(t *testing.T) {
 	testFs := newTestFS()
 
@@ -0,0 +1,35 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package ignore
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	metricCacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "ignore",
+		Name:      "cache_lookups_total",
+		Help:      "Total number of ignore match cache lookups, per result (hit, miss)",
+	}, []string{"result"})
+	metricCacheInvalidations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "ignore",
+		Name:      "cache_invalidated_entries_total",
+		Help:      "Total number of ignore match cache entries dropped, per reason (patterns, directory)",
+	}, []string{"reason"})
+)
+
+const (
+	metricResultHit  = "hit"
+	metricResultMiss = "miss"
+
+	metricReasonPatterns  = "patterns"
+	metricReasonDirectory = "directory"
+)
@@ -35,6 +35,7 @@ const (
 	GUIAssets      LocationEnum = "guiAssets"
 	DefFolder      LocationEnum = "defFolder"
 	LockFile       LocationEnum = "lockFile"
+	BackupDir      LocationEnum = "backupDir"
 )
 
 type BaseDirEnum string
@@ -129,6 +130,7 @@ var locationTemplates = map[LocationEnum]string{
 	GUIAssets:      "${config}/gui",
 	DefFolder:      "${userHome}/Sync",
 	LockFile:       "${data}/syncthing.lock",
+	BackupDir:      "${data}/backups",
 }
 
 var locations = make(map[LocationEnum]string)
@@ -0,0 +1,222 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Kinds of local changes tracked by the change anomaly detector.
+const (
+	changeCreated = iota
+	changeModified
+	changeDeleted
+	numChangeKinds
+)
+
+var changeKindNames = [numChangeKinds]string{"created", "modified", "deleted"}
+
+const (
+	// Changes are counted per minute, and the usual rate is a moving
+	// average over these counts with roughly an hour of memory.
+	changeAnomalyInterval = time.Minute
+	changeAnomalyAlpha    = 0.05
+	// Nothing is flagged until the folder has been observed for this many
+	// intervals, as the initial scan and the first changes say little
+	// about what is normal.
+	changeAnomalyWarmup = 60
+)
+
+var ErrNoChangeAnomaly = errors.New("no change anomaly recorded for folder")
+
+// ChangeAnomaly describes a burst of local changes in a folder, well above
+// the usual rate, such as caused by ransomware encrypting everything or a
+// runaway script.
+type ChangeAnomaly struct {
+	Folder   string    `json:"folder"`
+	Kind     string    `json:"kind"`     // created, modified or deleted
+	Changes  int       `json:"changes"`  // in the minute the anomaly was detected
+	Expected float64   `json:"expected"` // usual changes per minute
+	Detected time.Time `json:"detected"`
+	Paused   bool      `json:"paused"` // whether the folder was paused because of it
+}
+
+// changeAnomalyDetector learns the usual rate of local changes per folder
+// and flags sudden deviations from it. Once flagged, a folder stays
+// flagged, and the rate isn't learned from, until the anomaly is
+// acknowledged.
+type changeAnomalyDetector struct {
+	now func() time.Time
+
+	mut     sync.Mutex
+	folders map[string]*folderChangeRates
+}
+
+type folderChangeRates struct {
+	interval  time.Time // start of the current interval
+	intervals int       // completed intervals observed
+	counts    [numChangeKinds]int
+	usual     [numChangeKinds]float64
+	anomaly   *ChangeAnomaly
+}
+
+func newChangeAnomalyDetector() *changeAnomalyDetector {
+	return &changeAnomalyDetector{
+		now:     time.Now,
+		folders: make(map[string]*folderChangeRates),
+	}
+}
+
+// record counts a local change of the given kind and returns the anomaly,
+// if this change makes for one.
+func (d *changeAnomalyDetector) record(fcfg config.FolderConfiguration, kind int) *ChangeAnomaly {
+	if fcfg.ChangeAnomalyFactor <= 0 {
+		return nil
+	}
+	now := d.now()
+
+	d.mut.Lock()
+	defer d.mut.Unlock()
+
+	r, ok := d.folders[fcfg.ID]
+	if !ok {
+		r = &folderChangeRates{interval: now.Truncate(changeAnomalyInterval)}
+		d.folders[fcfg.ID] = r
+	}
+	r.advance(now)
+	r.counts[kind]++
+
+	if r.anomaly != nil || r.intervals < changeAnomalyWarmup {
+		return nil
+	}
+	n := r.counts[kind]
+	if n < fcfg.ChangeAnomalyMinChanges || float64(n) <= fcfg.ChangeAnomalyFactor*max(r.usual[kind], 1) {
+		return nil
+	}
+	r.anomaly = &ChangeAnomaly{
+		Folder:   fcfg.ID,
+		Kind:     changeKindNames[kind],
+		Changes:  n,
+		Expected: r.usual[kind],
+		Detected: now.Truncate(time.Second),
+		Paused:   fcfg.ChangeAnomalyPause,
+	}
+	anomaly := *r.anomaly
+	return &anomaly
+}
+
+// advance moves on to the interval containing now, learning from the
+// counts of the intervals passed.
+func (r *folderChangeRates) advance(now time.Time) {
+	cur := now.Truncate(changeAnomalyInterval)
+	if !cur.After(r.interval) {
+		return
+	}
+	passed := int(cur.Sub(r.interval) / changeAnomalyInterval)
+	if r.anomaly == nil {
+		// The interval just completed, followed by empty ones.
+		decay := math.Pow(1-changeAnomalyAlpha, float64(passed-1))
+		for i := range r.usual {
+			r.usual[i] = (r.usual[i]*(1-changeAnomalyAlpha) + changeAnomalyAlpha*float64(r.counts[i])) * decay
+		}
+	}
+	r.intervals += passed
+	r.counts = [numChangeKinds]int{}
+	r.interval = cur
+}
+
+func (d *changeAnomalyDetector) anomalies() map[string]ChangeAnomaly {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	res := make(map[string]ChangeAnomaly)
+	for folder, r := range d.folders {
+		if r.anomaly != nil {
+			res[folder] = *r.anomaly
+		}
+	}
+	return res
+}
+
+// acknowledge clears the anomaly. The changes so far in the current
+// interval are accepted and not counted again.
+func (d *changeAnomalyDetector) acknowledge(folder string) error {
+	d.mut.Lock()
+	defer d.mut.Unlock()
+	r, ok := d.folders[folder]
+	if !ok || r.anomaly == nil {
+		return ErrNoChangeAnomaly
+	}
+	r.anomaly = nil
+	r.counts = [numChangeKinds]int{}
+	return nil
+}
+
+func (d *changeAnomalyDetector) forget(folder string) {
+	d.mut.Lock()
+	delete(d.folders, folder)
+	d.mut.Unlock()
+}
+
+// recordLocalChange feeds a change found when scanning to the anomaly
+// detector. existed tells whether the file was known before.
+func (f *folder) recordLocalChange(fi protocol.FileInfo, existed bool) {
+	if fi.IsInvalid() {
+		return
+	}
+	kind := changeModified
+	switch {
+	case fi.IsDeleted():
+		kind = changeDeleted
+	case !existed:
+		kind = changeCreated
+	}
+	if anomaly := f.model.changeAnomalies.record(f.FolderConfiguration, kind); anomaly != nil {
+		f.sl.Warn("Unusually many local changes in folder", slog.String("kind", anomaly.Kind), slog.Int("changes", anomaly.Changes), slog.Float64("expected", anomaly.Expected), slog.Bool("pausing", anomaly.Paused))
+		f.model.raiseChangeAnomaly(*anomaly)
+	}
+}
+
+func (m *model) raiseChangeAnomaly(anomaly ChangeAnomaly) {
+	m.evLogger.Log(events.ChangeAnomalyDetected, anomaly)
+	if !anomaly.Paused {
+		return
+	}
+	// Pausing stops the folder, which waits for the scan we're called
+	// from to finish.
+	go func() {
+		_, err := m.cfg.Modify(func(cfg *config.Configuration) {
+			if fcfg, _, ok := cfg.Folder(anomaly.Folder); ok {
+				fcfg.Paused = true
+				cfg.SetFolder(fcfg)
+			}
+		})
+		if err != nil {
+			slog.Warn("Failed to pause folder after change anomaly", slog.String("folder", anomaly.Folder), slogutil.Error(err))
+		}
+	}()
+}
+
+// ChangeAnomalies returns the unacknowledged change anomalies, keyed by
+// folder.
+func (m *model) ChangeAnomalies() map[string]ChangeAnomaly {
+	return m.changeAnomalies.anomalies()
+}
+
+// AcknowledgeChangeAnomaly clears the anomaly of the folder, so that new
+// ones can be detected. A folder paused because of it stays paused.
+func (m *model) AcknowledgeChangeAnomaly(folder string) error {
+	return m.changeAnomalies.acknowledge(folder)
+}
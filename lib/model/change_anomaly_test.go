@@ -0,0 +1,86 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+func TestChangeAnomalyDetector(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := newChangeAnomalyDetector()
+	d.now = func() time.Time { return now }
+
+	fcfg := config.FolderConfiguration{ID: "default", ChangeAnomalyFactor: 20, ChangeAnomalyMinChanges: 100}
+	burst := func(kind, n int) *ChangeAnomaly {
+		var anomaly *ChangeAnomaly
+		for range n {
+			if a := d.record(fcfg, kind); a != nil {
+				if anomaly != nil {
+					t.Fatal("anomaly raised twice")
+				}
+				anomaly = a
+			}
+		}
+		return anomaly
+	}
+
+	// The initial scan is never an anomaly.
+	if a := burst(changeCreated, 10000); a != nil {
+		t.Fatalf("unexpected anomaly during warmup: %+v", a)
+	}
+
+	// An hour and a half of ten modifications per minute.
+	for range 90 {
+		now = now.Add(time.Minute)
+		if a := burst(changeModified, 10); a != nil {
+			t.Fatalf("unexpected anomaly: %+v", a)
+		}
+	}
+
+	// A hundred times that is not normal.
+	now = now.Add(time.Minute)
+	a := burst(changeModified, 1000)
+	if a == nil {
+		t.Fatal("expected an anomaly")
+	}
+	if a.Kind != "modified" || a.Changes < 190 || a.Changes > 210 || a.Expected < 9 || a.Expected > 11 {
+		t.Errorf("unexpected anomaly %+v", a)
+	}
+	// Nothing else is flagged while the anomaly is pending.
+	if a := burst(changeDeleted, 90); a != nil {
+		t.Fatalf("unexpected anomaly: %+v", a)
+	}
+	if as := d.anomalies(); len(as) != 1 || as["default"].Kind != "modified" {
+		t.Fatalf("unexpected anomalies %+v", as)
+	}
+
+	// Nor is anything learned, until acknowledged.
+	now = now.Add(time.Minute)
+	if a := burst(changeModified, 1000); a != nil {
+		t.Fatalf("unexpected anomaly: %+v", a)
+	}
+	must(t, d.acknowledge("default"))
+	if err := d.acknowledge("default"); !errors.Is(err, ErrNoChangeAnomaly) {
+		t.Errorf("expected no anomaly, got %v", err)
+	}
+	now = now.Add(time.Minute)
+	if a := burst(changeModified, 1000); a == nil {
+		t.Fatal("expected an anomaly after acknowledging")
+	}
+
+	// Disabled detection
+	fcfg.ID = "other"
+	fcfg.ChangeAnomalyFactor = 0
+	if a := burst(changeDeleted, 10000); a != nil {
+		t.Fatalf("unexpected anomaly with detection disabled: %+v", a)
+	}
+}
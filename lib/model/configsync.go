@@ -0,0 +1,645 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/itererr"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+const (
+	configSyncKindPrefix = "configsync/"
+
+	ConfigSyncPartFolder  = "folder"
+	ConfigSyncPartDevice  = "device"
+	ConfigSyncPartIgnores = "ignores"
+)
+
+var (
+	ErrConfigSyncNotPending = errors.New("no such pending configuration change")
+	ErrConfigSyncDisabled   = errors.New("configuration sync is not enabled for the device")
+)
+
+// ConfigSyncChange is a change to the configuration received from a
+// device, waiting for approval.
+type ConfigSyncChange struct {
+	Device   protocol.DeviceID `json:"device"`
+	Part     string            `json:"part"`
+	Key      string            `json:"key"`
+	Version  string            `json:"version"`
+	Deleted  bool              `json:"deleted"`
+	Data     json.RawMessage   `json:"data,omitempty"`
+	Conflict bool              `json:"conflict"`
+	Received time.Time         `json:"received"`
+}
+
+// configSyncItem is the payload of a config sync control message.
+type configSyncItem struct {
+	Version string          `json:"version"`
+	Deleted bool            `json:"deleted,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// configSync replicates the folder definitions, device list and default
+// ignore patterns between two devices that have both enabled it for each
+// other, so that either can stand in for the other. Each item is sent as
+// its own control message through the device queue, which makes transfers
+// resumable across disconnects and restarts. Only items that changed
+// since they were last sent are queued again, and removed items are sent
+// as tombstones.
+//
+// A received item conflicts when the local copy has changed since the two
+// devices last agreed on it. In the auto mode items that don't conflict
+// are applied straight away; everything else waits for approval.
+//
+// Folders created from received definitions are added paused, with a
+// local path below the default folder path. Should the other device be
+// lost, PromoteConfigSyncSpare resumes them and stops the replication.
+//
+// The state is kept in the database: the version of each item last sent
+// to a device, the version each device and we last agreed on, and the
+// changes waiting for approval.
+type configSync struct {
+	kv db.KV
+
+	sendMut sync.Mutex // serializes sending
+	mut     sync.Mutex // serializes handling received items
+}
+
+func newConfigSync(kv db.KV) *configSync {
+	return &configSync{kv: kv}
+}
+
+// get returns the value of the key, if any.
+func (s *configSync) get(key string) ([]byte, bool, error) {
+	bs, err := s.kv.GetKV(key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+	return bs, true, nil
+}
+
+func configSyncKey(kind string, device protocol.DeviceID, part, key string) string {
+	return "configsync/" + kind + "/" + device.String() + "/" + part + "/" + key
+}
+
+func configSyncPartEnabled(dev config.DeviceConfiguration, part string) bool {
+	if dev.ConfigSync == config.ConfigSyncModeOff {
+		return false
+	}
+	switch part {
+	case ConfigSyncPartFolder:
+		return dev.ConfigSyncFolders
+	case ConfigSyncPartDevice:
+		return dev.ConfigSyncDevices
+	case ConfigSyncPartIgnores:
+		return dev.ConfigSyncIgnores
+	default:
+		return false
+	}
+}
+
+// configSyncData returns the item as replicated with peer, without the
+// settings that are local to each device, or nil if there is no such item.
+// Folders are shared with the two devices on either side as they see fit,
+// so those are left out of the device lists.
+func configSyncData(cfg config.Configuration, self, peer protocol.DeviceID, part, key string) []byte {
+	var v any
+	switch part {
+	case ConfigSyncPartFolder:
+		fcfg, _, ok := cfg.Folder(key)
+		if !ok {
+			return nil
+		}
+		fcfg.Path = ""
+		fcfg.Paused = false
+		fcfg.Devices = slices.DeleteFunc(slices.Clone(fcfg.Devices), func(fd config.FolderDeviceConfiguration) bool {
+			return fd.DeviceID == self || fd.DeviceID == peer
+		})
+		slices.SortFunc(fcfg.Devices, func(a, b config.FolderDeviceConfiguration) int {
+			return a.DeviceID.Compare(b.DeviceID)
+		})
+		v = fcfg
+	case ConfigSyncPartDevice:
+		id, err := protocol.DeviceIDFromString(key)
+		if err != nil {
+			return nil
+		}
+		dcfg, _, ok := cfg.Device(id)
+		if !ok {
+			return nil
+		}
+		dcfg = withLocalConfigSync(dcfg, config.DeviceConfiguration{})
+		v = dcfg
+	case ConfigSyncPartIgnores:
+		v = cfg.Defaults.Ignores.Lines
+	default:
+		return nil
+	}
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return bs
+}
+
+// withLocalConfigSync returns the device configuration with the settings
+// that aren't replicated taken from local.
+func withLocalConfigSync(dcfg, local config.DeviceConfiguration) config.DeviceConfiguration {
+	dcfg.ConfigSync = local.ConfigSync
+	dcfg.ConfigSyncFolders = local.ConfigSyncFolders
+	dcfg.ConfigSyncDevices = local.ConfigSyncDevices
+	dcfg.ConfigSyncIgnores = local.ConfigSyncIgnores
+	dcfg.Paused = local.Paused
+	dcfg.IgnoredFolders = local.IgnoredFolders
+	return dcfg
+}
+
+// withLocalFolderConfigSync keeps what concerns where and how the folder is
+// stored here as it is locally. A device could otherwise have an external
+// versioner command run here, or expose other local paths through the
+// folder.
+func withLocalFolderConfigSync(fcfg, local config.FolderConfiguration) config.FolderConfiguration {
+	fcfg.FilesystemType = local.FilesystemType
+	fcfg.Path = local.Path
+	fcfg.Paused = local.Paused
+	fcfg.MarkerName = local.MarkerName
+	fcfg.Versioning = local.Versioning
+	fcfg.ViewSources = local.ViewSources
+	return fcfg
+}
+
+func configSyncVersion(data []byte) string {
+	if data == nil {
+		return ""
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:16])
+}
+
+// configSyncItems returns the items to replicate to the device, keyed by
+// part and key.
+func configSyncItems(cfg config.Configuration, myID protocol.DeviceID, dev config.DeviceConfiguration) map[[2]string][]byte {
+	items := make(map[[2]string][]byte)
+	if configSyncPartEnabled(dev, ConfigSyncPartFolder) {
+		for _, fcfg := range cfg.Folders {
+			items[[2]string{ConfigSyncPartFolder, fcfg.ID}] = configSyncData(cfg, myID, dev.DeviceID, ConfigSyncPartFolder, fcfg.ID)
+		}
+	}
+	if configSyncPartEnabled(dev, ConfigSyncPartDevice) {
+		for _, dcfg := range cfg.Devices {
+			if dcfg.DeviceID == myID || dcfg.DeviceID == dev.DeviceID {
+				continue
+			}
+			key := dcfg.DeviceID.String()
+			items[[2]string{ConfigSyncPartDevice, key}] = configSyncData(cfg, myID, dev.DeviceID, ConfigSyncPartDevice, key)
+		}
+	}
+	if configSyncPartEnabled(dev, ConfigSyncPartIgnores) {
+		items[[2]string{ConfigSyncPartIgnores, ""}] = configSyncData(cfg, myID, dev.DeviceID, ConfigSyncPartIgnores, "")
+	}
+	return items
+}
+
+// sendConfigSync queues the items that changed since they were last sent
+// for each device config sync is enabled for. It always works from the
+// current configuration, as commits may be notified out of order.
+func (m *model) sendConfigSync() {
+	m.configSync.sendMut.Lock()
+	defer m.configSync.sendMut.Unlock()
+
+	cfg := m.cfg.RawCopy()
+	for _, dev := range cfg.Devices {
+		if dev.DeviceID == m.id || dev.ConfigSync == config.ConfigSyncModeOff {
+			continue
+		}
+		if err := m.sendConfigSyncLocked(cfg, dev); err != nil {
+			slog.Warn("Failed to queue configuration for sync", dev.DeviceID.LogAttr(), slogutil.Error(err))
+		}
+	}
+}
+
+func (m *model) sendConfigSyncLocked(cfg config.Configuration, dev config.DeviceConfiguration) error {
+	kv := m.configSync.kv
+	items := configSyncItems(cfg, m.id, dev)
+
+	queue := func(part, key string, item configSyncItem) error {
+		bs, err := json.Marshal(item)
+		if err != nil {
+			return err
+		}
+		if err := m.QueueDeviceMessage(dev.DeviceID, configSyncKindPrefix+part, key, bs); err != nil {
+			return err
+		}
+		l.Debugln("Queued configuration for sync", dev.DeviceID.Short(), part, key, item.Version)
+		return nil
+	}
+
+	// Tombstones for what was sent before and is gone now. Parts no longer
+	// replicated are left alone, as that's no reason to remove them on
+	// the other side.
+	prefix := "configsync/sent/" + dev.DeviceID.String() + "/"
+	it, errFn := kv.PrefixKV(prefix)
+	var gone [][2]string
+	for kv, err := range itererr.Zip(it, errFn) {
+		if err != nil {
+			return err
+		}
+		part, key, _ := strings.Cut(strings.TrimPrefix(kv.Key, prefix), "/")
+		if _, ok := items[[2]string{part, key}]; !ok && configSyncPartEnabled(dev, part) {
+			gone = append(gone, [2]string{part, key})
+		}
+	}
+	for _, pk := range gone {
+		if err := queue(pk[0], pk[1], configSyncItem{Deleted: true}); err != nil {
+			return err
+		}
+		if err := kv.DeleteKV(configSyncKey("sent", dev.DeviceID, pk[0], pk[1])); err != nil {
+			return err
+		}
+	}
+
+	for pk, data := range items {
+		version := configSyncVersion(data)
+		sentKey := configSyncKey("sent", dev.DeviceID, pk[0], pk[1])
+		if sent, ok, err := m.configSync.get(sentKey); err != nil {
+			return err
+		} else if ok && string(sent) == version {
+			continue
+		}
+		if err := queue(pk[0], pk[1], configSyncItem{Version: version, Data: data}); err != nil {
+			return err
+		}
+		if err := kv.PutKV(sentKey, []byte(version)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleConfigSync handles a config sync item received from the device,
+// applying it or keeping it for approval.
+func (m *model) handleConfigSync(device protocol.DeviceID, msg *protocol.ControlMessage) error {
+	part := strings.TrimPrefix(msg.Kind, configSyncKindPrefix)
+	dev, ok := m.cfg.Device(device)
+	if !ok || !configSyncPartEnabled(dev, part) {
+		l.Debugln("Ignoring configuration from device without config sync", device.Short(), part, msg.Key)
+		return nil
+	}
+	if part == ConfigSyncPartDevice && (msg.Key == m.id.String() || msg.Key == device.String()) {
+		return nil
+	}
+	var item configSyncItem
+	if err := json.Unmarshal(msg.Payload, &item); err != nil {
+		return fmt.Errorf("config sync: %w", err)
+	}
+
+	m.configSync.mut.Lock()
+	defer m.configSync.mut.Unlock()
+	kv := m.configSync.kv
+
+	local := configSyncVersion(configSyncData(m.cfg.RawCopy(), m.id, device, part, msg.Key))
+	agreedKey := configSyncKey("agreed", device, part, msg.Key)
+	pendingKey := configSyncKey("pending", device, part, msg.Key)
+	if local == item.Version {
+		// Nothing to do, we're in agreement.
+		if err := kv.PutKV(agreedKey, []byte(local)); err != nil {
+			return err
+		}
+		return kv.DeleteKV(pendingKey)
+	}
+
+	// It's a conflict unless the local item is unchanged since we last
+	// agreed, or it has never been agreed upon and doesn't exist here.
+	agreed, hasAgreed, err := m.configSync.get(agreedKey)
+	if err != nil {
+		return err
+	}
+	change := ConfigSyncChange{
+		Device:   device,
+		Part:     part,
+		Key:      msg.Key,
+		Version:  item.Version,
+		Deleted:  item.Deleted,
+		Data:     item.Data,
+		Conflict: hasAgreed && string(agreed) != local || !hasAgreed && local != "",
+		Received: time.Now(),
+	}
+
+	if dev.ConfigSync == config.ConfigSyncModeAuto && !change.Conflict {
+		return m.applyConfigSyncLocked(change)
+	}
+
+	bs, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+	if err := kv.PutKV(pendingKey, bs); err != nil {
+		return err
+	}
+	l.Debugln("Configuration change from device awaits approval", device.Short(), part, msg.Key, change.Conflict)
+	m.evLogger.Log(events.ConfigSyncPending, map[string]interface{}{
+		"device":   device.String(),
+		"part":     part,
+		"key":      msg.Key,
+		"deleted":  change.Deleted,
+		"conflict": change.Conflict,
+	})
+	return nil
+}
+
+// applyConfigSyncLocked applies the change to the configuration and records
+// it as agreed upon.
+func (m *model) applyConfigSyncLocked(change ConfigSyncChange) error {
+	var created string
+	var defaults config.FolderConfiguration
+	var applyErr error
+	_, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		switch change.Part {
+		case ConfigSyncPartFolder:
+			defaults = cfg.Defaults.Folder
+			created, applyErr = m.applyConfigSyncFolder(cfg, change)
+		case ConfigSyncPartDevice:
+			applyErr = applyConfigSyncDevice(cfg, change)
+		case ConfigSyncPartIgnores:
+			var lines []string
+			if !change.Deleted {
+				applyErr = json.Unmarshal(change.Data, &lines)
+			}
+			cfg.Defaults.Ignores.Lines = lines
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if applyErr != nil {
+		return applyErr
+	}
+
+	kv := m.configSync.kv
+	if created != "" {
+		// Only once the configuration change went through, not to leave
+		// directories behind otherwise.
+		defaultPathFs := fs.NewFilesystem(defaults.FilesystemType.ToFS(), defaults.Path)
+		if err := defaultPathFs.MkdirAll(created, 0o700); err != nil {
+			slog.Warn("Failed to create directory for folder from device", change.Device.LogAttr(), slog.String("folder", change.Key), slogutil.Error(err))
+		}
+		if err := kv.PutKV(configSyncKey("created", change.Device, ConfigSyncPartFolder, change.Key), []byte(change.Version)); err != nil {
+			return err
+		}
+	}
+	if err := kv.PutKV(configSyncKey("agreed", change.Device, change.Part, change.Key), []byte(change.Version)); err != nil {
+		return err
+	}
+	slog.Info("Applied configuration change from device", change.Device.LogAttr(), slog.String("part", change.Part), slog.String("key", change.Key))
+	return kv.DeleteKV(configSyncKey("pending", change.Device, change.Part, change.Key))
+}
+
+// applyConfigSyncFolder applies the folder change to the configuration,
+// returning the directory below the default folder path to create for a
+// new folder.
+func (m *model) applyConfigSyncFolder(cfg *config.Configuration, change ConfigSyncChange) (string, error) {
+	existing, _, ok := cfg.Folder(change.Key)
+	if change.Deleted {
+		if ok {
+			cfg.Folders = slices.DeleteFunc(cfg.Folders, func(fcfg config.FolderConfiguration) bool {
+				return fcfg.ID == change.Key
+			})
+		}
+		return "", nil
+	}
+
+	var fcfg config.FolderConfiguration
+	if err := json.Unmarshal(change.Data, &fcfg); err != nil {
+		return "", err
+	}
+	if fcfg.ID != change.Key {
+		return "", fmt.Errorf("config sync: folder ID %q does not match %q", fcfg.ID, change.Key)
+	}
+	var created string
+	if ok {
+		fcfg = withLocalFolderConfigSync(fcfg, existing)
+		for _, fd := range existing.Devices {
+			if fd.DeviceID == m.id || fd.DeviceID == change.Device {
+				fcfg.Devices = append(fcfg.Devices, fd)
+			}
+		}
+	} else {
+		name, err := configSyncFolderPath(cfg.Defaults.Folder, fcfg)
+		if err != nil {
+			return "", err
+		}
+		local := cfg.Defaults.Folder
+		local.Path = filepath.Join(cfg.Defaults.Folder.Path, name)
+		local.Paused = true
+		fcfg = withLocalFolderConfigSync(fcfg, local)
+		fcfg.Devices = append(fcfg.Devices, config.FolderDeviceConfiguration{DeviceID: change.Device})
+		created = name
+	}
+
+	// The folder may be shared with devices we don't know yet; they are
+	// added with the defaults and completed when their device item arrives.
+	for _, fd := range fcfg.Devices {
+		if _, _, ok := cfg.Device(fd.DeviceID); !ok {
+			dcfg := cfg.Defaults.Device.Copy()
+			dcfg.DeviceID = fd.DeviceID
+			cfg.SetDevice(dcfg)
+		}
+	}
+	cfg.SetFolder(fcfg)
+	return created, nil
+}
+
+// configSyncFolderPath picks a free directory name below the default
+// folder path for a folder received from a device.
+func configSyncFolderPath(defaults config.FolderConfiguration, fcfg config.FolderConfiguration) (string, error) {
+	defaultPathFs := fs.NewFilesystem(defaults.FilesystemType.ToFS(), defaults.Path)
+	for _, alt := range []string{fs.SanitizePath(fcfg.Label), fs.SanitizePath(fcfg.ID)} {
+		if alt == "" {
+			continue
+		}
+		if _, err := defaultPathFs.Lstat(alt); !fs.IsNotExist(err) {
+			continue
+		}
+		return alt, nil
+	}
+	return "", fmt.Errorf("config sync: no free path for folder %q", fcfg.ID)
+}
+
+func applyConfigSyncDevice(cfg *config.Configuration, change ConfigSyncChange) error {
+	id, err := protocol.DeviceIDFromString(change.Key)
+	if err != nil {
+		return err
+	}
+	existing, _, ok := cfg.Device(id)
+	if change.Deleted {
+		if ok {
+			cfg.Devices = slices.DeleteFunc(cfg.Devices, func(dcfg config.DeviceConfiguration) bool {
+				return dcfg.DeviceID == id
+			})
+		}
+		return nil
+	}
+
+	var dcfg config.DeviceConfiguration
+	if err := json.Unmarshal(change.Data, &dcfg); err != nil {
+		return err
+	}
+	if dcfg.DeviceID != id {
+		return fmt.Errorf("config sync: device ID %s does not match %s", dcfg.DeviceID, id)
+	}
+	if !ok {
+		existing = cfg.Defaults.Device
+	}
+	cfg.SetDevice(withLocalConfigSync(dcfg, existing))
+	return nil
+}
+
+// PendingConfigSync returns the configuration changes received from
+// devices that wait for approval.
+func (m *model) PendingConfigSync() ([]ConfigSyncChange, error) {
+	m.configSync.mut.Lock()
+	defer m.configSync.mut.Unlock()
+
+	var changes []ConfigSyncChange
+	it, errFn := m.configSync.kv.PrefixKV("configsync/pending/")
+	for kv, err := range itererr.Zip(it, errFn) {
+		if err != nil {
+			return nil, err
+		}
+		var change ConfigSyncChange
+		if err := json.Unmarshal(kv.Value, &change); err != nil {
+			l.Debugln("unmarshalling pending config sync change", kv.Key, err)
+			continue
+		}
+		changes = append(changes, change)
+	}
+	slices.SortFunc(changes, func(a, b ConfigSyncChange) int {
+		return a.Received.Compare(b.Received)
+	})
+	return changes, nil
+}
+
+// ApproveConfigSync applies a pending configuration change, conflicting or
+// not.
+func (m *model) ApproveConfigSync(device protocol.DeviceID, part, key string) error {
+	m.configSync.mut.Lock()
+	defer m.configSync.mut.Unlock()
+
+	bs, ok, err := m.configSync.get(configSyncKey("pending", device, part, key))
+	if err != nil {
+		return err
+	} else if !ok {
+		return ErrConfigSyncNotPending
+	}
+	var change ConfigSyncChange
+	if err := json.Unmarshal(bs, &change); err != nil {
+		return err
+	}
+	return m.applyConfigSyncLocked(change)
+}
+
+// RejectConfigSync drops a pending configuration change. The local item
+// is sent to the device again the next time it changes.
+func (m *model) RejectConfigSync(device protocol.DeviceID, part, key string) error {
+	m.configSync.mut.Lock()
+	defer m.configSync.mut.Unlock()
+
+	pendingKey := configSyncKey("pending", device, part, key)
+	if _, ok, err := m.configSync.get(pendingKey); err != nil {
+		return err
+	} else if !ok {
+		return ErrConfigSyncNotPending
+	}
+	return m.configSync.kv.DeleteKV(pendingKey)
+}
+
+// PromoteConfigSyncSpare makes this device take over from the given one,
+// which it has been a spare for: the folders created from its
+// configuration are resumed, and config sync with it is turned off so that
+// it can't undo the takeover should it come back. Returns the IDs of the
+// resumed folders.
+func (m *model) PromoteConfigSyncSpare(device protocol.DeviceID) ([]string, error) {
+	dev, ok := m.cfg.Device(device)
+	if !ok {
+		return nil, errDeviceUnknown
+	}
+	if dev.ConfigSync == config.ConfigSyncModeOff {
+		return nil, ErrConfigSyncDisabled
+	}
+
+	m.configSync.mut.Lock()
+	defer m.configSync.mut.Unlock()
+	kv := m.configSync.kv
+
+	createdPrefix := configSyncKey("created", device, ConfigSyncPartFolder, "")
+	var created, drop []string
+	it, errFn := kv.PrefixKV(createdPrefix)
+	for kv, err := range itererr.Zip(it, errFn) {
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, strings.TrimPrefix(kv.Key, createdPrefix))
+		drop = append(drop, kv.Key)
+	}
+	it, errFn = kv.PrefixKV("configsync/pending/" + device.String() + "/")
+	for kv, err := range itererr.Zip(it, errFn) {
+		if err != nil {
+			return nil, err
+		}
+		drop = append(drop, kv.Key)
+	}
+
+	var resumed []string
+	waiter, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		for _, id := range created {
+			if fcfg, _, ok := cfg.Folder(id); ok && fcfg.Paused {
+				fcfg.Paused = false
+				cfg.SetFolder(fcfg)
+				resumed = append(resumed, id)
+			}
+		}
+		if dcfg, _, ok := cfg.Device(device); ok {
+			dcfg.ConfigSync = config.ConfigSyncModeOff
+			cfg.SetDevice(dcfg)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	waiter.Wait()
+
+	for _, key := range drop {
+		if err := kv.DeleteKV(key); err != nil {
+			return nil, err
+		}
+	}
+	slices.SortFunc(resumed, cmp.Compare)
+	slog.Info("Promoted to replace device", device.LogAttr(), slog.Int("folders", len(resumed)))
+	return resumed, nil
+}
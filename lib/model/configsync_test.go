@@ -0,0 +1,171 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestConfigSync(t *testing.T) {
+	tcfg := defaultAutoAcceptCfg.Copy()
+	tcfg.Devices[1].ConfigSync = config.ConfigSyncModeAuto
+	tcfg.Devices[1].ConfigSyncFolders = true
+	tcfg.Devices[1].ConfigSyncDevices = true
+	tcfg.Defaults.Folder.Path = t.TempDir()
+	w, cancel := newConfigWrapper(tcfg)
+	defer cancel()
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	// The configuration of device1, which we are a spare for.
+	remote := defaultAutoAcceptCfg.Copy()
+	remote.SetFolder(config.FolderConfiguration{
+		ID:    "synced",
+		Label: "Synced",
+		Path:  "/elsewhere",
+		Devices: []config.FolderDeviceConfiguration{
+			{DeviceID: device1}, {DeviceID: device2}, {DeviceID: myID},
+		},
+		Versioning: config.VersioningConfiguration{
+			Type:   "external",
+			Params: map[string]string{"command": "/bin/false"},
+		},
+	})
+	receive := func(part, key string) {
+		t.Helper()
+		item := configSyncItem{Deleted: true}
+		if data := configSyncData(remote, device1, myID, part, key); data != nil {
+			item = configSyncItem{Version: configSyncVersion(data), Data: data}
+		}
+		bs, err := json.Marshal(item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		must(t, m.handleConfigSync(device1, &protocol.ControlMessage{
+			Kind:    configSyncKindPrefix + part,
+			Key:     key,
+			Payload: bs,
+			Queued:  time.Now(),
+		}))
+	}
+
+	// A new folder is applied right away, paused and with a local path.
+	receive(ConfigSyncPartFolder, "synced")
+	fcfg, ok := m.cfg.Folder("synced")
+	if !ok {
+		t.Fatal("folder not added")
+	}
+	if !fcfg.Paused || fcfg.Label != "Synced" || fcfg.Path == "/elsewhere" {
+		t.Errorf("unexpected folder %+v", fcfg)
+	}
+	if fcfg.Versioning.Type != "" || fcfg.FilesystemType != tcfg.Defaults.Folder.FilesystemType {
+		t.Errorf("local settings taken from device: %+v", fcfg)
+	}
+	if ids := fcfg.DeviceIDs(); !slices.Contains(ids, device1) || !slices.Contains(ids, device2) {
+		t.Errorf("unexpected devices %v", ids)
+	}
+
+	// Changes on both sides conflict and wait for approval.
+	fcfg.Label = "Local"
+	setFolder(t, w, fcfg)
+	remoteFcfg, _, _ := remote.Folder("synced")
+	remoteFcfg.Label = "Remote"
+	remote.SetFolder(remoteFcfg)
+	receive(ConfigSyncPartFolder, "synced")
+	pending, err := m.PendingConfigSync()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || !pending[0].Conflict || pending[0].Key != "synced" {
+		t.Fatalf("unexpected pending changes %+v", pending)
+	}
+	if fcfg, _ := m.cfg.Folder("synced"); fcfg.Label != "Local" {
+		t.Errorf("conflicting change applied: %+v", fcfg)
+	}
+	must(t, m.ApproveConfigSync(device1, ConfigSyncPartFolder, "synced"))
+	if fcfg, _ := m.cfg.Folder("synced"); fcfg.Label != "Remote" || !fcfg.Paused {
+		t.Errorf("approved change not applied: %+v", fcfg)
+	}
+	if err := m.RejectConfigSync(device1, ConfigSyncPartFolder, "synced"); !errors.Is(err, ErrConfigSyncNotPending) {
+		t.Errorf("expected nothing pending, got %v", err)
+	}
+
+	// Ignores aren't replicated with device1.
+	remote.Defaults.Ignores.Lines = []string{"*.tmp"}
+	receive(ConfigSyncPartIgnores, "")
+	if lines := w.RawCopy().Defaults.Ignores.Lines; len(lines) != 0 {
+		t.Errorf("ignores applied: %v", lines)
+	}
+
+	// Promotion resumes the folder and stops the replication.
+	resumed, err := m.PromoteConfigSyncSpare(device1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(resumed, []string{"synced"}) {
+		t.Errorf("unexpected resumed folders %v", resumed)
+	}
+	if dev, _ := m.cfg.Device(device1); dev.ConfigSync != config.ConfigSyncModeOff {
+		t.Error("config sync not turned off")
+	}
+	if _, err := m.PromoteConfigSyncSpare(device1); !errors.Is(err, ErrConfigSyncDisabled) {
+		t.Errorf("expected config sync disabled, got %v", err)
+	}
+}
+
+func TestConfigSyncSend(t *testing.T) {
+	tcfg := defaultAutoAcceptCfg.Copy()
+	tcfg.Devices[1].ConfigSync = config.ConfigSyncModeApprove
+	tcfg.Devices[1].ConfigSyncDevices = true
+	w, cancel := newConfigWrapper(tcfg)
+	defer cancel()
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	queued := func() []string {
+		t.Helper()
+		msgs, err := m.QueuedDeviceMessages(device1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var res []string
+		for _, msg := range msgs {
+			var item configSyncItem
+			must(t, json.Unmarshal(msg.Payload, &item))
+			res = append(res, msg.Kind+" "+msg.Key+" "+item.Version)
+		}
+		return res
+	}
+
+	// Only device2 is sent, as neither ourselves nor device1 are
+	// replicated to device1.
+	msgs := queued()
+	if len(msgs) != 1 || msgs[0] != "configsync/device "+device2.String()+" "+configSyncVersion(configSyncData(w.RawCopy(), myID, device1, ConfigSyncPartDevice, device2.String())) {
+		t.Fatalf("unexpected messages %v", msgs)
+	}
+
+	// Nothing new for a change that isn't replicated.
+	pauseDevice(t, w, device2, true)
+	if n := len(queued()); n != 1 {
+		t.Errorf("expected one message, got %d", n)
+	}
+
+	// Removal is sent as a tombstone, replacing the queued message.
+	waiter, err := w.RemoveDevice(device2)
+	must(t, err)
+	waiter.Wait()
+	if msgs := queued(); len(msgs) != 1 || msgs[0] != "configsync/device "+device2.String()+" " {
+		t.Errorf("unexpected messages %v", msgs)
+	}
+}
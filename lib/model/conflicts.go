@@ -0,0 +1,240 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/itererr"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/versioner"
+)
+
+// Resolutions of a conflict, i.e. which of the two versions to keep.
+const (
+	// Keep the version in place; the conflict copy is removed.
+	ConflictKeepWinner = "winner"
+	// Keep the conflict copy, which replaces the version in place and
+	// thereby becomes the newest version in the cluster.
+	ConflictKeepConflict = "conflict"
+	// Keep both files as they are and forget about the conflict.
+	ConflictKeepBoth = "both"
+)
+
+var ErrNoSuchConflict = errors.New("no such conflict")
+
+// Conflict describes a conflict copy created when pulling, with the two
+// versions involved as they were at the time.
+type Conflict struct {
+	Path         string          `json:"path"`
+	ConflictPath string          `json:"conflictPath"`
+	Detected     time.Time       `json:"detected"`
+	Winner       ConflictVersion `json:"winner"`   // the version that went into place
+	Conflict     ConflictVersion `json:"conflict"` // the version moved to the conflict copy
+}
+
+type ConflictVersion struct {
+	Size       int64            `json:"size"`
+	Modified   time.Time        `json:"modified"`
+	ModifiedBy protocol.ShortID `json:"modifiedBy"`
+	Version    protocol.Vector  `json:"version"`
+}
+
+func newConflictVersion(f protocol.FileInfo) ConflictVersion {
+	return ConflictVersion{
+		Size:       f.FileSize(),
+		Modified:   f.ModTime(),
+		ModifiedBy: f.ModifiedBy,
+		Version:    f.Version,
+	}
+}
+
+// conflictInbox keeps the unresolved conflicts of all folders in the
+// database, keyed by folder and conflict copy name.
+type conflictInbox struct {
+	kv db.KV
+}
+
+func conflictKey(folder, conflictPath string) string {
+	return "conflicts/" + folder + "/" + conflictPath
+}
+
+func (c *conflictInbox) add(folder string, conflict Conflict) error {
+	bs, err := json.Marshal(conflict)
+	if err != nil {
+		return err
+	}
+	return c.kv.PutKV(conflictKey(folder, conflict.ConflictPath), bs)
+}
+
+func (c *conflictInbox) get(folder, conflictPath string) (Conflict, error) {
+	bs, err := c.kv.GetKV(conflictKey(folder, conflictPath))
+	if errors.Is(err, sql.ErrNoRows) {
+		return Conflict{}, ErrNoSuchConflict
+	} else if err != nil {
+		return Conflict{}, err
+	}
+	var conflict Conflict
+	if err := json.Unmarshal(bs, &conflict); err != nil {
+		return Conflict{}, err
+	}
+	return conflict, nil
+}
+
+func (c *conflictInbox) remove(folder, conflictPath string) error {
+	return c.kv.DeleteKV(conflictKey(folder, conflictPath))
+}
+
+func (c *conflictInbox) list(folder string) ([]Conflict, error) {
+	var conflicts []Conflict
+	it, errFn := c.kv.PrefixKV(conflictKey(folder, ""))
+	for kv, err := range itererr.Zip(it, errFn) {
+		if err != nil {
+			return nil, err
+		}
+		var conflict Conflict
+		if err := json.Unmarshal(kv.Value, &conflict); err != nil {
+			l.Debugln("unmarshalling conflict", kv.Key, err)
+			continue
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts, nil
+}
+
+// recordConflict registers the conflict copy just created for the given
+// file, with the versions involved according to the database.
+func (f *sendReceiveFolder) recordConflict(name, conflictPath string) {
+	conflict := Conflict{
+		Path:         name,
+		ConflictPath: conflictPath,
+		Detected:     time.Now().Truncate(time.Second),
+	}
+	if cur, ok, err := f.db.GetDeviceFile(f.folderID, protocol.LocalDeviceID, name); err == nil && ok {
+		conflict.Conflict = newConflictVersion(cur)
+	}
+	if global, ok, err := f.db.GetGlobalFile(f.folderID, name); err == nil && ok {
+		conflict.Winner = newConflictVersion(global)
+	}
+	if err := f.model.conflicts.add(f.folderID, conflict); err != nil {
+		l.Debugln(f, "recording conflict", err)
+	}
+}
+
+// Conflicts returns the unresolved conflicts in the folder, oldest first.
+// Conflicts whose conflict copy has been removed in the meantime are
+// forgotten.
+func (m *model) Conflicts(folder string) ([]Conflict, error) {
+	fcfg, ok := m.cfg.Folder(folder)
+	if !ok {
+		return nil, ErrFolderMissing
+	}
+	conflicts, err := m.conflicts.list(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	ffs := fcfg.Filesystem()
+	conflicts = slices.DeleteFunc(conflicts, func(c Conflict) bool {
+		if _, err := ffs.Lstat(c.ConflictPath); fs.IsNotExist(err) {
+			m.conflicts.remove(folder, c.ConflictPath)
+			return true
+		}
+		return false
+	})
+	slices.SortFunc(conflicts, func(a, b Conflict) int {
+		if c := a.Detected.Compare(b.Detected); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ConflictPath, b.ConflictPath)
+	})
+	return conflicts, nil
+}
+
+// ConflictVersions returns the current metadata of the file in place and of
+// the conflict copy, as far as they are known to the database.
+func (m *model) ConflictVersions(folder, conflictPath string) (protocol.FileInfo, protocol.FileInfo, error) {
+	conflict, err := m.conflicts.get(folder, conflictPath)
+	if err != nil {
+		return protocol.FileInfo{}, protocol.FileInfo{}, err
+	}
+	file, _, err := m.CurrentFolderFile(folder, conflict.Path)
+	if err != nil {
+		return protocol.FileInfo{}, protocol.FileInfo{}, err
+	}
+	copyFile, _, err := m.CurrentFolderFile(folder, conflict.ConflictPath)
+	if err != nil {
+		return protocol.FileInfo{}, protocol.FileInfo{}, err
+	}
+	return file, copyFile, nil
+}
+
+// ResolveConflict resolves the conflict by keeping the winner, the conflict
+// copy or both. The affected paths are rescanned afterwards, so that the
+// outcome is sent to the other devices.
+func (m *model) ResolveConflict(folder, conflictPath, keep string) error {
+	fcfg, ok := m.cfg.Folder(folder)
+	if !ok {
+		return ErrFolderMissing
+	}
+	conflict, err := m.conflicts.get(folder, conflictPath)
+	if err != nil {
+		return err
+	}
+
+	ffs := fcfg.Filesystem()
+	switch keep {
+	case ConflictKeepWinner:
+		if err := ffs.Remove(conflict.ConflictPath); err != nil && !fs.IsNotExist(err) {
+			return fmt.Errorf("removing conflict copy: %w", err)
+		}
+	case ConflictKeepConflict:
+		m.mut.RLock()
+		ver := m.folderVersioners[folder]
+		m.mut.RUnlock()
+		if ver != nil {
+			info := versioner.ArchiveInfo{ModifiedBy: m.id.String(), Conflict: true}
+			if err := versioner.ArchiveWithInfo(ver, conflict.Path, info); err != nil && !fs.IsNotExist(err) {
+				return fmt.Errorf("archiving replaced version: %w", err)
+			}
+		}
+		if err := ffs.Rename(conflict.ConflictPath, conflict.Path); err != nil {
+			return fmt.Errorf("replacing file with conflict copy: %w", err)
+		}
+	case ConflictKeepBoth:
+	default:
+		return fmt.Errorf("unknown conflict resolution %q", keep)
+	}
+
+	if err := m.conflicts.remove(folder, conflict.ConflictPath); err != nil {
+		return err
+	}
+	m.evLogger.Log(events.ConflictResolved, map[string]string{
+		"folder":       folder,
+		"path":         conflict.Path,
+		"conflictPath": conflict.ConflictPath,
+		"keep":         keep,
+	})
+
+	if keep != ConflictKeepBoth {
+		// Failing this, e.g. as the folder is paused, the change is
+		// picked up by the next scan.
+		if err := m.ScanFolderSubdirs(folder, []string{conflict.Path, conflict.ConflictPath}); err != nil {
+			l.Debugln(m, "scanning after resolving conflict:", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestConflictInbox(t *testing.T) {
+	m, f, wcfgCancel := setupSendReceiveFolder(t)
+	defer wcfgCancel()
+	ffs := f.Filesystem()
+
+	// A local file, which loses against a remote change
+	name := "foo"
+	file := createEmptyFileInfo(t, name, ffs)
+	file.Version = protocol.Vector{}.Update(myID.Short())
+	f.updateLocalsFromScanning([]protocol.FileInfo{file})
+	writeFile(t, ffs, name, []byte("local"))
+
+	scanChan := make(chan string, 1)
+	must(t, f.moveForConflict(name, device1.Short().String(), scanChan))
+	conflictPath := <-scanChan
+	writeFile(t, ffs, name, []byte("remote"))
+
+	conflicts, err := m.Conflicts(f.folderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 || conflicts[0].Path != name || conflicts[0].ConflictPath != conflictPath {
+		t.Fatalf("unexpected conflicts %+v", conflicts)
+	}
+	if !conflicts[0].Conflict.Version.Equal(file.Version) {
+		t.Errorf("expected the local version %v as the conflict, got %v", file.Version, conflicts[0].Conflict.Version)
+	}
+
+	if err := m.ResolveConflict(f.folderID, conflictPath, "neither"); err == nil {
+		t.Error("expected an error for an unknown resolution")
+	}
+
+	// Keeping the conflict copy puts it in place
+	must(t, m.ResolveConflict(f.folderID, conflictPath, ConflictKeepConflict))
+	if data := readFile(t, ffs, name); string(data) != "local" {
+		t.Errorf("expected the conflict copy in place, got %q", data)
+	}
+	if _, err := ffs.Lstat(conflictPath); !fs.IsNotExist(err) {
+		t.Error("conflict copy should be gone")
+	}
+	if err := m.ResolveConflict(f.folderID, conflictPath, ConflictKeepWinner); !errors.Is(err, ErrNoSuchConflict) {
+		t.Errorf("expected the conflict to be resolved, got %v", err)
+	}
+
+	// Conflict copies removed by the user are forgotten
+	must(t, f.moveForConflict(name, device1.Short().String(), scanChan))
+	must(t, ffs.Remove(<-scanChan))
+	if conflicts, err := m.Conflicts(f.folderID); err != nil || len(conflicts) != 0 {
+		t.Errorf("expected no conflicts, got %v, %v", conflicts, err)
+	}
+}
+
+func readFile(t *testing.T, ffs fs.Filesystem, name string) []byte {
+	t.Helper()
+	fd, err := ffs.Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	data, err := io.ReadAll(fd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
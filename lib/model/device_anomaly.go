@@ -0,0 +1,132 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/connections"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Kinds of device anomalies, i.e. signs that someone may be impersonating a
+// known device.
+const (
+	// The device presents another certificate than the pinned one; see
+	// IdentityChange.
+	DeviceAnomalyCertificate = "certificateChanged"
+	// The device connected from outside its expected networks.
+	DeviceAnomalyNetwork = "unexpectedNetwork"
+	// The device came back after a long absence with another client,
+	// client version or capabilities.
+	DeviceAnomalyClient = "clientChangedAfterAbsence"
+)
+
+// A DeviceAnomaly is the data of a DeviceSecurityAnomaly event.
+type DeviceAnomaly struct {
+	DeviceID protocol.DeviceID `json:"deviceID"`
+	Name     string            `json:"name,omitempty"`
+	Kind     string            `json:"kind"`
+	Address  string            `json:"address,omitempty"`
+	Details  string            `json:"details"`
+	Time     time.Time         `json:"time"`
+}
+
+// checkConnectionAnomalies looks for anomalies in a new connection from a
+// known device that wasn't connected before. It must be called before the
+// device is marked as seen.
+func (m *model) checkConnectionAnomalies(deviceCfg config.DeviceConfiguration, conn protocol.Connection, hello protocol.Hello) {
+	addr := conn.RemoteAddr()
+	if len(deviceCfg.ExpectedNetworks) > 0 && isIPAddr(addr) && conn.Transport() != "relay" {
+		// The address of a relayed connection is that of the relay.
+		if !connections.IsAllowedNetwork(addr.String(), deviceCfg.ExpectedNetworks) {
+			m.reportDeviceAnomaly(DeviceAnomaly{
+				DeviceID: deviceCfg.DeviceID,
+				Name:     deviceCfg.Name,
+				Kind:     DeviceAnomalyNetwork,
+				Address:  addr.String(),
+				Details:  fmt.Sprintf("connected from outside %s", strings.Join(deviceCfg.ExpectedNetworks, ", ")),
+			})
+		}
+	}
+
+	m.mut.RLock()
+	sr, ok := m.deviceStatRefs[deviceCfg.DeviceID]
+	m.mut.RUnlock()
+	if !ok {
+		return
+	}
+	client := clientDescription(hello)
+	prevClient, err := sr.GetLastClient()
+	if err != nil {
+		slog.Warn("Failed to load last client of device", deviceCfg.DeviceID.LogAttr(), slogutil.Error(err))
+		return
+	}
+	if err := sr.SetLastClient(client); err != nil {
+		slog.Warn("Failed to store client of device", deviceCfg.DeviceID.LogAttr(), slogutil.Error(err))
+	}
+
+	days := m.cfg.Options().DeviceAbsenceAnomalyDays
+	if days <= 0 || prevClient == "" || prevClient == client {
+		return
+	}
+	lastSeen, err := sr.GetLastSeen()
+	if err != nil {
+		return
+	}
+	absence := time.Since(lastSeen)
+	if absence < time.Duration(days)*24*time.Hour {
+		return
+	}
+	var address string
+	if addr != nil {
+		address = addr.String()
+	}
+	m.reportDeviceAnomaly(DeviceAnomaly{
+		DeviceID: deviceCfg.DeviceID,
+		Name:     deviceCfg.Name,
+		Kind:     DeviceAnomalyClient,
+		Address:  address,
+		Details:  fmt.Sprintf("last seen %d days ago with %s, now %s", int(absence.Hours()/24), prevClient, client),
+	})
+}
+
+// clientDescription describes the client software and the capabilities the
+// device announces.
+func clientDescription(hello protocol.Hello) string {
+	desc := hello.ClientName + " " + hello.ClientVersion
+	if len(hello.Compressions) > 0 {
+		algos := make([]string, len(hello.Compressions))
+		for i, algo := range hello.Compressions {
+			algos[i] = algo.String()
+		}
+		desc += " (" + strings.Join(algos, ", ") + ")"
+	}
+	return desc
+}
+
+func isIPAddr(addr net.Addr) bool {
+	switch addr.(type) {
+	case *net.TCPAddr, *net.UDPAddr:
+		return true
+	default:
+		return false
+	}
+}
+
+func (m *model) reportDeviceAnomaly(anomaly DeviceAnomaly) {
+	anomaly.Time = time.Now().Truncate(time.Second)
+	slog.Warn("Possible impersonation of remote device", anomaly.DeviceID.LogAttr(), slog.String("kind", anomaly.Kind), slog.String("details", anomaly.Details), slogutil.Address(anomaly.Address))
+	m.evLogger.Log(events.DeviceSecurityAnomaly, anomaly)
+}
@@ -0,0 +1,105 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"crypto/x509"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func expectDeviceAnomaly(t *testing.T, sub events.Subscription, kind string) {
+	t.Helper()
+	ev, err := sub.Poll(time.Second)
+	if err != nil {
+		t.Fatalf("expected a %s anomaly, got %v", kind, err)
+	}
+	if anomaly := ev.Data.(DeviceAnomaly); anomaly.Kind != kind || anomaly.DeviceID != device1 {
+		t.Fatalf("expected a %s anomaly for device1, got %+v", kind, anomaly)
+	}
+}
+
+func TestDeviceAnomalyNetwork(t *testing.T) {
+	w, fcfg, wCancel := newDefaultCfgWrapper()
+	defer wCancel()
+	waiter, err := w.Modify(func(cfg *config.Configuration) {
+		for i := range cfg.Devices {
+			if cfg.Devices[i].DeviceID == device1 {
+				cfg.Devices[i].ExpectedNetworks = []string{"10.0.0.0/8"}
+			}
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waiter.Wait()
+	m := setupModel(t, w)
+	defer cleanupModelAndRemoveDir(m, fcfg.Filesystem().URI())
+
+	sub := m.evLogger.Subscribe(events.DeviceSecurityAnomaly)
+	defer sub.Unsubscribe()
+
+	fc := newFakeConnection(device1, m)
+	fc.RemoteAddrReturns(&net.TCPAddr{IP: net.IPv4(10, 1, 2, 3), Port: 22000})
+	m.AddConnection(fc, protocol.Hello{})
+	if ev, err := sub.Poll(100 * time.Millisecond); err == nil {
+		t.Fatalf("unexpected anomaly %+v for a connection from an expected network", ev.Data)
+	}
+	fc.Close(errors.New("test"))
+
+	fc = newFakeConnection(device1, m)
+	fc.RemoteAddrReturns(&net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 22000})
+	m.AddConnection(fc, protocol.Hello{})
+	expectDeviceAnomaly(t, sub, DeviceAnomalyNetwork)
+}
+
+func TestDeviceAnomalyClientAfterAbsence(t *testing.T) {
+	w, fcfg, wCancel := newDefaultCfgWrapper()
+	defer wCancel()
+	m := setupModel(t, w)
+	defer cleanupModelAndRemoveDir(m, fcfg.Filesystem().URI())
+
+	sub := m.evLogger.Subscribe(events.DeviceSecurityAnomaly)
+	defer sub.Unsubscribe()
+
+	// Never seen so far, i.e. absent since 1970.
+	if err := m.deviceStatRefs[device1].SetLastClient("syncthing v1.30.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	fc := newFakeConnection(device1, m)
+	m.AddConnection(fc, protocol.Hello{ClientName: "syncthing", ClientVersion: "v2.0.0"})
+	expectDeviceAnomaly(t, sub, DeviceAnomalyClient)
+	fc.Close(errors.New("test"))
+
+	// Same client again, just now seen
+	fc = newFakeConnection(device1, m)
+	m.AddConnection(fc, protocol.Hello{ClientName: "syncthing", ClientVersion: "v2.0.0"})
+	if ev, err := sub.Poll(100 * time.Millisecond); err == nil {
+		t.Fatalf("unexpected anomaly %+v", ev.Data)
+	}
+}
+
+func TestDeviceAnomalyCertificate(t *testing.T) {
+	w, fcfg, wCancel := newDefaultCfgWrapper()
+	defer wCancel()
+	m := setupModel(t, w)
+	defer cleanupModelAndRemoveDir(m, fcfg.Filesystem().URI())
+
+	sub := m.evLogger.Subscribe(events.DeviceSecurityAnomaly)
+	defer sub.Unsubscribe()
+
+	cert := &x509.Certificate{Raw: []byte("another certificate")}
+	m.OnIdentityMismatch(device1, cert, &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 22000})
+	expectDeviceAnomaly(t, sub, DeviceAnomalyCertificate)
+}
@@ -0,0 +1,637 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"cmp"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/itererr"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+const (
+	deviceRevocationKindPrefix = "revocation/"
+	// Sent to the other devices, which answer with the result.
+	deviceRevocationKindRevoke = deviceRevocationKindPrefix + "revoke"
+	deviceRevocationKindResult = deviceRevocationKindPrefix + "result"
+
+	// Revocations waiting for local confirmation are kept by device and
+	// the device they came from.
+	deviceRevocationPendingPrefix = "revocationpending/"
+)
+
+// The actions recorded in the trail of a revocation.
+const (
+	RevocationActionRevoked      = "revoked"      // removed from the configuration and ignored
+	RevocationActionDisconnected = "disconnected" // connections closed
+	RevocationActionUnshared     = "unshared"     // folder no longer shared with it
+	RevocationActionDistrusted   = "distrusted"   // its changes to a folder withdrawn
+	RevocationActionSent         = "sent"         // revocation passed on to a device
+	RevocationActionApplied      = "applied"      // a device revoked it as well
+	RevocationActionRefused      = "refused"      // a device didn't revoke it
+)
+
+var (
+	ErrRevocationTarget     = errors.New("this device can't revoke itself")
+	ErrDeviceRevoked        = errors.New("the device is already revoked")
+	ErrRevocationNotPending = errors.New("no such pending device revocation")
+)
+
+var (
+	errDeviceRevoked      = errors.New("device was revoked")
+	errRevocationRejected = errors.New("rejected by the user")
+)
+
+// DeviceRevocation is a device that was revoked, e.g. as it was lost, with
+// the trail of what was done about it here and by the other devices.
+type DeviceRevocation struct {
+	Device   protocol.DeviceID `json:"device"`
+	Name     string            `json:"name,omitempty"`
+	Origin   protocol.DeviceID `json:"origin"` // the device it was revoked on first
+	Via      protocol.DeviceID `json:"via"`    // the device that passed it on to us, if any
+	Distrust bool              `json:"distrust"`
+	Revoked  time.Time         `json:"revoked"`
+	Trail    []RevocationEntry `json:"trail"`
+}
+
+// RevocationEntry is something done about a revocation, here or, for
+// applied and refused, by another device.
+type RevocationEntry struct {
+	Time   time.Time         `json:"time"`
+	Action string            `json:"action"`
+	Device protocol.DeviceID `json:"device"`
+	Folder string            `json:"folder,omitempty"`
+	Detail string            `json:"detail,omitempty"`
+}
+
+// PendingDeviceRevocation is a revocation received from another device that
+// waits for local confirmation, as that device didn't introduce the one it
+// revokes.
+type PendingDeviceRevocation struct {
+	Device   protocol.DeviceID `json:"device"`
+	Name     string            `json:"name,omitempty"`
+	From     protocol.DeviceID `json:"from"`
+	Origin   protocol.DeviceID `json:"origin"`
+	Distrust bool              `json:"distrust"`
+	Revoked  time.Time         `json:"revoked"`
+	Received time.Time         `json:"received"`
+}
+
+func (p PendingDeviceRevocation) message() deviceRevocationMessage {
+	return deviceRevocationMessage{
+		Device:   p.Device,
+		Name:     p.Name,
+		Origin:   p.Origin,
+		Distrust: p.Distrust,
+		Revoked:  p.Revoked,
+	}
+}
+
+// deviceRevocationMessage is the payload of the revoke message.
+type deviceRevocationMessage struct {
+	Device   protocol.DeviceID `json:"device"`
+	Name     string            `json:"name,omitempty"`
+	Origin   protocol.DeviceID `json:"origin"`
+	Distrust bool              `json:"distrust"`
+	Revoked  time.Time         `json:"revoked"`
+}
+
+// deviceRevocationResult is the payload of the result message.
+type deviceRevocationResult struct {
+	Applied  bool   `json:"applied"`
+	Reason   string `json:"reason,omitempty"`
+	PassedOn int    `json:"passedOn"`
+}
+
+// deviceRevocations keeps the revoked devices in the database. A revoked
+// device is removed from the configuration and ignored, so that it can't
+// connect again, and the revocation is passed on to the other devices
+// through the device queue. These apply it right away only when it comes
+// from the device that introduced the revoked one to them, in turn passing
+// it on, so it spreads the same way the device was introduced; anything
+// else waits for local confirmation. With distrust, the files in the
+// shared folders last changed by the revoked device are withdrawn, to be
+// pulled again from a device that has another version of them, if any.
+type deviceRevocations struct {
+	kv  db.KV
+	mut sync.Mutex
+}
+
+func newDeviceRevocations(kv db.KV) *deviceRevocations {
+	return &deviceRevocations{kv: kv}
+}
+
+func deviceRevocationKey(device protocol.DeviceID) string {
+	return deviceRevocationKindPrefix + device.String()
+}
+
+func (s *deviceRevocations) getLocked(device protocol.DeviceID) (DeviceRevocation, bool, error) {
+	bs, err := s.kv.GetKV(deviceRevocationKey(device))
+	if errors.Is(err, sql.ErrNoRows) {
+		return DeviceRevocation{}, false, nil
+	} else if err != nil {
+		return DeviceRevocation{}, false, err
+	}
+	var r DeviceRevocation
+	if err := json.Unmarshal(bs, &r); err != nil {
+		return DeviceRevocation{}, false, err
+	}
+	return r, true, nil
+}
+
+func (s *deviceRevocations) putLocked(r DeviceRevocation) error {
+	bs, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return s.kv.PutKV(deviceRevocationKey(r.Device), bs)
+}
+
+func pendingDeviceRevocationKey(device, from protocol.DeviceID) string {
+	return deviceRevocationPendingPrefix + device.String() + "/" + from.String()
+}
+
+// pendingLocked returns the pending revocations of the device, or all of
+// them for the empty device ID.
+func (s *deviceRevocations) pendingLocked(device protocol.DeviceID) ([]PendingDeviceRevocation, error) {
+	prefix := deviceRevocationPendingPrefix
+	if device != protocol.EmptyDeviceID {
+		prefix += device.String() + "/"
+	}
+	var pending []PendingDeviceRevocation
+	it, errFn := s.kv.PrefixKV(prefix)
+	for kv, err := range itererr.Zip(it, errFn) {
+		if err != nil {
+			return nil, err
+		}
+		var p PendingDeviceRevocation
+		if err := json.Unmarshal(kv.Value, &p); err != nil {
+			l.Debugln("unmarshalling pending device revocation", kv.Key, err)
+			continue
+		}
+		pending = append(pending, p)
+	}
+	slices.SortFunc(pending, func(a, b PendingDeviceRevocation) int {
+		return a.Received.Compare(b.Received)
+	})
+	return pending, nil
+}
+
+func (s *deviceRevocations) revoked(device protocol.DeviceID) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	_, ok, err := s.getLocked(device)
+	if err != nil {
+		l.Debugln("loading device revocation", device.Short(), err)
+	}
+	return ok
+}
+
+// RevokeDevice revokes the device: it's disconnected, removed from all
+// folders and the configuration and ignored from now on, and the other
+// devices are told to do the same. With distrust, the files it was the
+// last to change are withdrawn here and on the devices applying it.
+func (m *model) RevokeDevice(device protocol.DeviceID, distrust bool) (DeviceRevocation, error) {
+	if device == m.id || device == protocol.EmptyDeviceID {
+		return DeviceRevocation{}, ErrRevocationTarget
+	}
+	rm := deviceRevocationMessage{
+		Device:   device,
+		Origin:   m.id,
+		Distrust: distrust,
+		Revoked:  time.Now(),
+	}
+	if dev, ok := m.cfg.Device(device); ok {
+		rm.Name = dev.Name
+	}
+	return m.revokeDevice(rm, protocol.EmptyDeviceID)
+}
+
+// DeviceRevocations returns the revoked devices.
+func (m *model) DeviceRevocations() ([]DeviceRevocation, error) {
+	s := m.deviceRevocations
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	revocations := []DeviceRevocation{}
+	it, errFn := s.kv.PrefixKV(deviceRevocationKindPrefix)
+	for kv, err := range itererr.Zip(it, errFn) {
+		if err != nil {
+			return nil, err
+		}
+		var r DeviceRevocation
+		if err := json.Unmarshal(kv.Value, &r); err != nil {
+			l.Debugln("unmarshalling device revocation", kv.Key, err)
+			continue
+		}
+		revocations = append(revocations, r)
+	}
+	slices.SortFunc(revocations, func(a, b DeviceRevocation) int {
+		return cmp.Compare(a.Device.String(), b.Device.String())
+	})
+	return revocations, nil
+}
+
+// revokeDevice applies the revocation, received from the given device or
+// made here when that's empty, and passes it on to the other devices.
+func (m *model) revokeDevice(rm deviceRevocationMessage, via protocol.DeviceID) (DeviceRevocation, error) {
+	device := rm.Device
+	s := m.deviceRevocations
+	s.mut.Lock()
+	if _, ok, err := s.getLocked(device); err != nil {
+		s.mut.Unlock()
+		return DeviceRevocation{}, err
+	} else if ok {
+		s.mut.Unlock()
+		return DeviceRevocation{}, ErrDeviceRevoked
+	}
+	r := DeviceRevocation{
+		Device:   device,
+		Name:     rm.Name,
+		Origin:   rm.Origin,
+		Via:      via,
+		Distrust: rm.Distrust,
+		Revoked:  rm.Revoked,
+		Trail:    []RevocationEntry{},
+	}
+	err := s.putLocked(r)
+	s.mut.Unlock()
+	if err != nil {
+		return DeviceRevocation{}, err
+	}
+	slog.Warn("Revoking device", device.LogAttr(), slog.String("name", rm.Name), slog.String("origin", rm.Origin.String()), slog.Bool("distrust", rm.Distrust))
+
+	// Disconnect first, so nothing more is exchanged with it while the
+	// configuration changes.
+	m.mut.RLock()
+	connIDs := m.deviceConnIDs[device]
+	for _, connID := range connIDs {
+		go m.connections[connID].Close(errDeviceRevoked)
+	}
+	m.mut.RUnlock()
+	if len(connIDs) > 0 {
+		m.recordRevocation(device, RevocationEntry{Action: RevocationActionDisconnected, Device: device})
+	}
+
+	var peers []protocol.DeviceID
+	for id := range m.cfg.Devices() {
+		if id != m.id && id != device && id != via {
+			peers = append(peers, id)
+		}
+	}
+	slices.SortFunc(peers, func(a, b protocol.DeviceID) int {
+		return a.Compare(b)
+	})
+
+	var unshared []string
+	waiter, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		unshared = unshared[:0]
+		for i := range cfg.Folders {
+			fcfg := &cfg.Folders[i]
+			if !fcfg.SharedWith(device) {
+				continue
+			}
+			fcfg.Devices = slices.DeleteFunc(fcfg.Devices, func(dev config.FolderDeviceConfiguration) bool {
+				return dev.DeviceID == device
+			})
+			unshared = append(unshared, fcfg.ID)
+		}
+		// What it introduced stays, as we can't tell it from what it
+		// shouldn't have.
+		for i := range cfg.Folders {
+			for j := range cfg.Folders[i].Devices {
+				if cfg.Folders[i].Devices[j].IntroducedBy == device {
+					cfg.Folders[i].Devices[j].IntroducedBy = protocol.EmptyDeviceID
+				}
+			}
+		}
+		cfg.Devices = slices.DeleteFunc(cfg.Devices, func(dev config.DeviceConfiguration) bool {
+			return dev.DeviceID == device
+		})
+		for i := range cfg.Devices {
+			if cfg.Devices[i].IntroducedBy == device {
+				cfg.Devices[i].IntroducedBy = protocol.EmptyDeviceID
+			}
+		}
+		if !slices.ContainsFunc(cfg.IgnoredDevices, func(dev config.ObservedDevice) bool { return dev.ID == device }) {
+			cfg.IgnoredDevices = append(cfg.IgnoredDevices, config.ObservedDevice{
+				Time: rm.Revoked,
+				ID:   device,
+				Name: rm.Name,
+			})
+		}
+	})
+	if err != nil {
+		return DeviceRevocation{}, fmt.Errorf("revoking device: %w", err)
+	}
+	waiter.Wait()
+	m.recordRevocation(device, RevocationEntry{Action: RevocationActionRevoked, Device: device})
+	for _, folder := range unshared {
+		m.recordRevocation(device, RevocationEntry{Action: RevocationActionUnshared, Folder: folder})
+	}
+
+	if rm.Distrust {
+		// Withdrawing waits for the folders, which may be busy.
+		go m.distrustRevokedDevice(device, unshared)
+	}
+
+	bs, err := json.Marshal(rm)
+	if err != nil {
+		return DeviceRevocation{}, err
+	}
+	for _, peer := range peers {
+		if err := m.QueueDeviceMessage(peer, deviceRevocationKindRevoke, device.String(), bs); err != nil {
+			slog.Warn("Failed to pass device revocation on", device.LogAttr(), slog.String("to", peer.String()), slogutil.Error(err))
+			continue
+		}
+		m.recordRevocation(device, RevocationEntry{Action: RevocationActionSent, Device: peer})
+	}
+
+	s.mut.Lock()
+	r, _, err = s.getLocked(device)
+	s.mut.Unlock()
+	if err != nil {
+		return DeviceRevocation{}, err
+	}
+	// Revocations of it waiting for confirmation are settled by this one.
+	if pending, err := m.takePendingDeviceRevocations(device); err == nil {
+		for _, p := range pending {
+			m.replyDeviceRevocation(p.From, device, r, nil)
+		}
+	}
+	return r, nil
+}
+
+// recordRevocation adds the entry to the trail of the device's revocation.
+func (m *model) recordRevocation(device protocol.DeviceID, entry RevocationEntry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+	s := m.deviceRevocations
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	r, ok, err := s.getLocked(device)
+	if err != nil || !ok {
+		l.Debugln("Not recording revocation", device.Short(), entry.Action, err)
+		return
+	}
+	r.Trail = append(r.Trail, entry)
+	if err := s.putLocked(r); err != nil {
+		slog.Warn("Failed to save device revocation", device.LogAttr(), slogutil.Error(err))
+		return
+	}
+	l.Debugln("Device revocation", device.Short(), entry.Action, entry.Device.Short(), entry.Folder, entry.Detail)
+	m.evLogger.Log(events.DeviceRevocationProgress, map[string]interface{}{
+		"device": device.String(),
+		"entry":  entry,
+	})
+}
+
+// distrustRevokedDevice withdraws the files the device was the last to
+// change in the folders, so that we pull them again from a device having
+// another version, if there is one. Until the other devices distrust it as
+// well, that's the same version and nothing changes.
+func (m *model) distrustRevokedDevice(device protocol.DeviceID, folders []string) {
+	for _, folder := range folders {
+		n, err := m.withdrawFilesModifiedBy(folder, device.Short())
+		entry := RevocationEntry{Action: RevocationActionDistrusted, Folder: folder, Detail: fmt.Sprintf("withdrew %d files", n)}
+		if err != nil {
+			slog.Warn("Failed to withdraw files changed by revoked device", device.LogAttr(), slog.String("folder", folder), slogutil.Error(err))
+			entry.Detail = fmt.Sprintf("withdrew %d files: %v", n, err)
+		}
+		m.recordRevocation(device, entry)
+	}
+}
+
+func (m *model) withdrawFilesModifiedBy(folder string, by protocol.ShortID) (int, error) {
+	m.mut.RLock()
+	runner, ok := m.folderRunners.Get(folder)
+	m.mut.RUnlock()
+	if !ok {
+		return 0, ErrFolderNotRunning
+	}
+
+	var files []protocol.FileInfo
+	it, errFn := m.sdb.AllLocalFiles(folder, protocol.LocalDeviceID)
+	for fi, err := range itererr.Zip(it, errFn) {
+		if err != nil {
+			return 0, err
+		}
+		if fi.ModifiedBy == by && !fi.IsInvalid() && !fi.Version.IsEmpty() {
+			files = append(files, fi)
+		}
+	}
+
+	n := 0
+	for _, fi := range files {
+		if err := runner.quarantineFile(fi.Name, fi.Version); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// handleDeviceRevocation handles a device revocation message from the
+// device.
+func (m *model) handleDeviceRevocation(device protocol.DeviceID, msg *protocol.ControlMessage) error {
+	switch msg.Kind {
+	case deviceRevocationKindRevoke:
+		var rm deviceRevocationMessage
+		if err := json.Unmarshal(msg.Payload, &rm); err != nil {
+			return fmt.Errorf("device revocation: %w", err)
+		}
+		// Applying it changes the configuration and closes connections,
+		// which mustn't hold up the connection it came on.
+		go m.applyDeviceRevocation(device, rm)
+		return nil
+
+	case deviceRevocationKindResult:
+		var res deviceRevocationResult
+		if err := json.Unmarshal(msg.Payload, &res); err != nil {
+			return fmt.Errorf("device revocation: %w", err)
+		}
+		revoked, err := protocol.DeviceIDFromString(msg.Key)
+		if err != nil {
+			return fmt.Errorf("device revocation: %w", err)
+		}
+		entry := RevocationEntry{Action: RevocationActionRefused, Device: device, Detail: res.Reason}
+		if res.Applied {
+			entry.Action = RevocationActionApplied
+			entry.Detail = fmt.Sprintf("passed on to %d devices", res.PassedOn)
+		}
+		m.recordRevocation(revoked, entry)
+		return nil
+
+	default:
+		l.Debugln("Ignoring unknown device revocation message", device.Short(), msg.Kind)
+		return nil
+	}
+}
+
+// applyDeviceRevocation revokes the device as told by the given one, if
+// that introduced it here, and tells it the result. Anything else waits for
+// local confirmation.
+func (m *model) applyDeviceRevocation(from protocol.DeviceID, rm deviceRevocationMessage) {
+	if rm.Device == m.id {
+		slog.Warn("Another device revoked this one", slog.String("from", from.String()), slog.String("origin", rm.Origin.String()))
+		m.replyDeviceRevocation(from, rm.Device, DeviceRevocation{}, ErrRevocationTarget)
+		return
+	}
+	if m.deviceRevocations.revoked(rm.Device) {
+		m.replyDeviceRevocation(from, rm.Device, DeviceRevocation{}, ErrDeviceRevoked)
+		return
+	}
+	if dev, ok := m.cfg.Device(rm.Device); !ok || dev.IntroducedBy != from {
+		m.holdDeviceRevocation(from, rm)
+		return
+	}
+	r, err := m.revokeDevice(rm, from)
+	if err != nil && !errors.Is(err, ErrDeviceRevoked) {
+		slog.Warn("Failed to revoke device", rm.Device.LogAttr(), slog.String("from", from.String()), slogutil.Error(err))
+	}
+	m.replyDeviceRevocation(from, rm.Device, r, err)
+}
+
+// holdDeviceRevocation keeps the revocation from the device until it's
+// confirmed or rejected locally.
+func (m *model) holdDeviceRevocation(from protocol.DeviceID, rm deviceRevocationMessage) {
+	p := PendingDeviceRevocation{
+		Device:   rm.Device,
+		Name:     rm.Name,
+		From:     from,
+		Origin:   rm.Origin,
+		Distrust: rm.Distrust,
+		Revoked:  rm.Revoked,
+		Received: time.Now(),
+	}
+	bs, err := json.Marshal(p)
+	if err != nil {
+		return
+	}
+	s := m.deviceRevocations
+	s.mut.Lock()
+	err = s.kv.PutKV(pendingDeviceRevocationKey(rm.Device, from), bs)
+	s.mut.Unlock()
+	if err != nil {
+		slog.Warn("Failed to save pending device revocation", rm.Device.LogAttr(), slogutil.Error(err))
+		return
+	}
+	slog.Warn("Device revocation from a device that didn't introduce it awaits confirmation", rm.Device.LogAttr(), slog.String("from", from.String()), slog.Bool("distrust", rm.Distrust))
+	m.evLogger.Log(events.DeviceRevocationProgress, map[string]interface{}{
+		"device":  rm.Device.String(),
+		"pending": p,
+	})
+}
+
+// replyDeviceRevocation tells the device that sent the revocation what
+// became of it.
+func (m *model) replyDeviceRevocation(to, device protocol.DeviceID, r DeviceRevocation, err error) {
+	res := deviceRevocationResult{Applied: true}
+	switch {
+	case errors.Is(err, ErrDeviceRevoked):
+	case err != nil:
+		res = deviceRevocationResult{Reason: err.Error()}
+	default:
+		for _, entry := range r.Trail {
+			if entry.Action == RevocationActionSent {
+				res.PassedOn++
+			}
+		}
+	}
+	bs, err := json.Marshal(res)
+	if err != nil {
+		return
+	}
+	if err := m.QueueDeviceMessage(to, deviceRevocationKindResult, device.String(), bs); err != nil {
+		l.Debugln("Queueing device revocation result", to.Short(), device.Short(), err)
+	}
+}
+
+// PendingDeviceRevocations returns the revocations received from other
+// devices that wait for local confirmation.
+func (m *model) PendingDeviceRevocations() ([]PendingDeviceRevocation, error) {
+	s := m.deviceRevocations
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	pending, err := s.pendingLocked(protocol.EmptyDeviceID)
+	if err != nil {
+		return nil, err
+	}
+	if pending == nil {
+		pending = []PendingDeviceRevocation{}
+	}
+	return pending, nil
+}
+
+// ApproveDeviceRevocation revokes the device as pending from other
+// devices, which are told the result.
+func (m *model) ApproveDeviceRevocation(device protocol.DeviceID) (DeviceRevocation, error) {
+	pending, err := m.takePendingDeviceRevocations(device)
+	if err != nil {
+		return DeviceRevocation{}, err
+	}
+	// The first one received is what's applied; any distrust asked for
+	// comes with it.
+	r, err := m.revokeDevice(pending[0].message(), pending[0].From)
+	for _, p := range pending {
+		m.replyDeviceRevocation(p.From, device, r, err)
+	}
+	if errors.Is(err, ErrDeviceRevoked) {
+		s := m.deviceRevocations
+		s.mut.Lock()
+		defer s.mut.Unlock()
+		r, _, err = s.getLocked(device)
+	}
+	return r, err
+}
+
+// RejectDeviceRevocation drops the revocations of the device pending from
+// other devices, which are told it was refused.
+func (m *model) RejectDeviceRevocation(device protocol.DeviceID) error {
+	pending, err := m.takePendingDeviceRevocations(device)
+	if err != nil {
+		return err
+	}
+	slog.Info("Rejected device revocation", device.LogAttr())
+	for _, p := range pending {
+		m.replyDeviceRevocation(p.From, device, DeviceRevocation{}, errRevocationRejected)
+	}
+	return nil
+}
+
+// takePendingDeviceRevocations removes and returns the revocations of the
+// device pending from other devices.
+func (m *model) takePendingDeviceRevocations(device protocol.DeviceID) ([]PendingDeviceRevocation, error) {
+	s := m.deviceRevocations
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	pending, err := s.pendingLocked(device)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) == 0 {
+		return nil, ErrRevocationNotPending
+	}
+	for _, p := range pending {
+		if err := s.kv.DeleteKV(pendingDeviceRevocationKey(p.Device, p.From)); err != nil {
+			return nil, err
+		}
+	}
+	return pending, nil
+}
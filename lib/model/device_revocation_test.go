@@ -0,0 +1,179 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func revocationActions(r DeviceRevocation) []string {
+	var actions []string
+	for _, entry := range r.Trail {
+		actions = append(actions, entry.Action)
+	}
+	return actions
+}
+
+func TestRevokeDevice(t *testing.T) {
+	device3 := protocol.DeviceID{3}
+	fcfg := newFolderConfig()
+	fcfg.Devices = append(fcfg.Devices, config.FolderDeviceConfiguration{DeviceID: device3, IntroducedBy: device1})
+	m := setupHandoverModel(t, fcfg, config.DeviceConfiguration{DeviceID: device3, IntroducedBy: device1})
+
+	if _, err := m.RevokeDevice(myID, false); !errors.Is(err, ErrRevocationTarget) {
+		t.Errorf("expected invalid target, got %v", err)
+	}
+
+	r, err := m.RevokeDevice(device1, false)
+	must(t, err)
+	if r.Origin != myID || r.Via != protocol.EmptyDeviceID {
+		t.Errorf("unexpected origin %v via %v", r.Origin, r.Via)
+	}
+	for _, action := range []string{RevocationActionRevoked, RevocationActionUnshared, RevocationActionSent} {
+		if !slices.Contains(revocationActions(r), action) {
+			t.Errorf("%s missing from trail %+v", action, r.Trail)
+		}
+	}
+	if _, err := m.RevokeDevice(device1, false); !errors.Is(err, ErrDeviceRevoked) {
+		t.Errorf("expected already revoked, got %v", err)
+	}
+
+	if _, ok := m.cfg.Device(device1); ok {
+		t.Error("revoked device still configured")
+	}
+	if !m.cfg.IgnoredDevice(device1) {
+		t.Error("revoked device not ignored")
+	}
+	newCfg, _ := m.cfg.Folder(fcfg.ID)
+	if newCfg.SharedWith(device1) {
+		t.Error("folder still shared with revoked device")
+	}
+	if dev, _ := m.cfg.Device(device3); dev.IntroducedBy != protocol.EmptyDeviceID {
+		t.Error("device introduced by the revoked one not kept")
+	}
+	if dev, _ := newCfg.Device(device3); dev.IntroducedBy != protocol.EmptyDeviceID {
+		t.Error("folder device introduced by the revoked one not kept")
+	}
+	for _, dev := range []protocol.DeviceID{device2, device3} {
+		if kinds := queuedKinds(t, m, dev); !slices.Contains(kinds, deviceRevocationKindRevoke+" "+device1.String()) {
+			t.Errorf("revocation not passed on to %v: %v", dev, kinds)
+		}
+	}
+
+	bs, err := json.Marshal(deviceRevocationResult{Applied: true, PassedOn: 2})
+	must(t, err)
+	must(t, m.handleDeviceRevocation(device2, &protocol.ControlMessage{
+		Kind:    deviceRevocationKindResult,
+		Key:     device1.String(),
+		Payload: bs,
+		Queued:  time.Now(),
+	}))
+	rs, err := m.DeviceRevocations()
+	must(t, err)
+	if len(rs) != 1 {
+		t.Fatalf("expected one revocation, got %+v", rs)
+	}
+	last := rs[0].Trail[len(rs[0].Trail)-1]
+	if last.Action != RevocationActionApplied || last.Device != device2 {
+		t.Errorf("result not recorded: %+v", last)
+	}
+}
+
+func TestApplyDeviceRevocation(t *testing.T) {
+	device3 := protocol.DeviceID{3}
+	device4 := protocol.DeviceID{4}
+	fcfg := newFolderConfig()
+	fcfg.Devices = append(fcfg.Devices, config.FolderDeviceConfiguration{DeviceID: device3}, config.FolderDeviceConfiguration{DeviceID: device4})
+	m := setupHandoverModel(t, fcfg,
+		config.DeviceConfiguration{DeviceID: device1, Introducer: true},
+		config.DeviceConfiguration{DeviceID: device3, IntroducedBy: device1},
+		config.DeviceConfiguration{DeviceID: device4},
+	)
+	revoke := func(device protocol.DeviceID) deviceRevocationMessage {
+		return deviceRevocationMessage{Device: device, Origin: device1, Revoked: time.Now()}
+	}
+	result := deviceRevocationKindResult + " " + device3.String()
+
+	// Only the device that introduced it is followed right away.
+	m.applyDeviceRevocation(device2, revoke(device3))
+	if _, ok := m.cfg.Device(device3); !ok {
+		t.Fatal("revoked on behalf of a device that didn't introduce it")
+	}
+	if pending, _ := m.PendingDeviceRevocations(); len(pending) != 1 || pending[0].From != device2 {
+		t.Errorf("expected revocation to await confirmation, got %+v", pending)
+	}
+
+	m.applyDeviceRevocation(device1, revoke(device3))
+	if _, ok := m.cfg.Device(device3); ok {
+		t.Error("revocation from introducer not applied")
+	}
+	rs, err := m.DeviceRevocations()
+	must(t, err)
+	if len(rs) != 1 || rs[0].Via != device1 || rs[0].Origin != device1 {
+		t.Fatalf("unexpected revocations %+v", rs)
+	}
+	// Passed on to the others, not back, and settling what was pending.
+	if kinds := queuedKinds(t, m, device4); !slices.Contains(kinds, deviceRevocationKindRevoke+" "+device3.String()) {
+		t.Errorf("revocation not passed on: %v", kinds)
+	}
+	if kinds := queuedKinds(t, m, device1); slices.Contains(kinds, deviceRevocationKindRevoke+" "+device3.String()) || !slices.Contains(kinds, result) {
+		t.Errorf("unexpected messages to the introducer: %v", kinds)
+	}
+	if kinds := queuedKinds(t, m, device2); !slices.Contains(kinds, result) {
+		t.Errorf("pending revocation not answered: %v", kinds)
+	}
+	if pending, _ := m.PendingDeviceRevocations(); len(pending) != 0 {
+		t.Errorf("expected nothing pending, got %+v", pending)
+	}
+
+	// A device added here waits for confirmation, even from an introducer.
+	m.applyDeviceRevocation(device1, revoke(device4))
+	if _, ok := m.cfg.Device(device4); !ok {
+		t.Fatal("revoked a device the introducer didn't introduce")
+	}
+	must(t, m.RejectDeviceRevocation(device4))
+	if _, err := m.ApproveDeviceRevocation(device4); !errors.Is(err, ErrRevocationNotPending) {
+		t.Errorf("expected nothing pending, got %v", err)
+	}
+	m.applyDeviceRevocation(device1, revoke(device4))
+	r, err := m.ApproveDeviceRevocation(device4)
+	must(t, err)
+	if r.Device != device4 || r.Via != device1 {
+		t.Errorf("unexpected revocation %+v", r)
+	}
+	if _, ok := m.cfg.Device(device4); ok {
+		t.Error("approved revocation not applied")
+	}
+}
+
+func TestWithdrawFilesModifiedBy(t *testing.T) {
+	m := setupHandoverModel(t, newFolderConfig())
+	version := protocol.Vector{}.Update(device1.Short())
+	must(t, m.sdb.Update("default", protocol.LocalDeviceID, []protocol.FileInfo{
+		{Name: "theirs", Version: version, ModifiedBy: device1.Short()},
+		{Name: "ours", Version: version.Update(myID.Short()), ModifiedBy: myID.Short()},
+	}))
+
+	n, err := m.withdrawFilesModifiedBy("default", device1.Short())
+	must(t, err)
+	if n != 1 {
+		t.Errorf("expected one file withdrawn, got %d", n)
+	}
+	if fi, _, _ := m.CurrentFolderFile("default", "theirs"); !fi.Version.IsEmpty() {
+		t.Errorf("file not withdrawn: %v", fi.Version)
+	}
+	if fi, _, _ := m.CurrentFolderFile("default", "ours"); fi.Version.IsEmpty() {
+		t.Error("file changed here withdrawn")
+	}
+}
@@ -0,0 +1,272 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/itererr"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+const (
+	// Bounds of the queue of pending control messages per device. When
+	// exceeded, the oldest messages are dropped.
+	maxDeviceQueueMessages = 1000
+	maxDeviceQueueBytes    = 16 << 20
+)
+
+var ErrControlMessageTooLarge = errors.New("control message too large")
+
+// QueuedMessage is a control message waiting to be delivered to a device.
+type QueuedMessage struct {
+	Seq     int64     `json:"seq"`
+	Kind    string    `json:"kind"`
+	Key     string    `json:"key"`
+	Payload []byte    `json:"payload"`
+	Queued  time.Time `json:"queued"`
+}
+
+// deviceQueue keeps the control messages for each device in the database
+// until they have been sent, so that devices that are offline at the time
+// get them on their next connection. Messages are delivered in the order
+// they were queued, and a message replaces any message of the same kind
+// and key still waiting.
+type deviceQueue struct {
+	kv db.KV
+
+	mut        sync.Mutex
+	delivering map[protocol.DeviceID]bool
+	received   map[string]time.Time // device/kind/key -> queue time of the latest message received
+}
+
+func newDeviceQueue(kv db.KV) *deviceQueue {
+	return &deviceQueue{
+		kv:         kv,
+		delivering: make(map[protocol.DeviceID]bool),
+		received:   make(map[string]time.Time),
+	}
+}
+
+func deviceQueuePrefix(device protocol.DeviceID) string {
+	return "devicequeue/" + device.String() + "/"
+}
+
+func deviceQueueKey(device protocol.DeviceID, seq int64) string {
+	return fmt.Sprintf("%s%016x", deviceQueuePrefix(device), seq)
+}
+
+func (q *deviceQueue) enqueue(device protocol.DeviceID, kind, key string, payload []byte) error {
+	if len(payload) > maxDeviceQueueBytes {
+		return ErrControlMessageTooLarge
+	}
+
+	q.mut.Lock()
+	defer q.mut.Unlock()
+
+	msgs, err := q.listLocked(device)
+	if err != nil {
+		return err
+	}
+	var seq int64
+	if len(msgs) > 0 {
+		seq = msgs[len(msgs)-1].Seq + 1
+	}
+	if i := slices.IndexFunc(msgs, func(msg QueuedMessage) bool {
+		return msg.Kind == kind && msg.Key == key
+	}); i >= 0 {
+		l.Debugln("Replacing queued control message", device.Short(), kind, key)
+		if err := q.kv.DeleteKV(deviceQueueKey(device, msgs[i].Seq)); err != nil {
+			return err
+		}
+		msgs = slices.Delete(msgs, i, i+1)
+	}
+
+	msg := QueuedMessage{
+		Seq:     seq,
+		Kind:    kind,
+		Key:     key,
+		Payload: payload,
+		Queued:  time.Now(),
+	}
+	bs, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if err := q.kv.PutKV(deviceQueueKey(device, seq), bs); err != nil {
+		return err
+	}
+	msgs = append(msgs, msg)
+
+	// Drop the oldest messages to stay within bounds.
+	size := 0
+	for _, msg := range msgs {
+		size += len(msg.Payload)
+	}
+	for len(msgs) > maxDeviceQueueMessages || size > maxDeviceQueueBytes {
+		l.Debugln("Dropping queued control message", device.Short(), msgs[0].Kind, msgs[0].Key)
+		if err := q.kv.DeleteKV(deviceQueueKey(device, msgs[0].Seq)); err != nil {
+			return err
+		}
+		size -= len(msgs[0].Payload)
+		msgs = msgs[1:]
+	}
+	return nil
+}
+
+func (q *deviceQueue) list(device protocol.DeviceID) ([]QueuedMessage, error) {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+	return q.listLocked(device)
+}
+
+// listLocked returns the queued messages for the device, oldest first.
+func (q *deviceQueue) listLocked(device protocol.DeviceID) ([]QueuedMessage, error) {
+	var msgs []QueuedMessage
+	it, errFn := q.kv.PrefixKV(deviceQueuePrefix(device))
+	for kv, err := range itererr.Zip(it, errFn) {
+		if err != nil {
+			return nil, err
+		}
+		var msg QueuedMessage
+		if err := json.Unmarshal(kv.Value, &msg); err != nil {
+			l.Debugln("unmarshalling queued control message", kv.Key, err)
+			continue
+		}
+		msgs = append(msgs, msg)
+	}
+	slices.SortFunc(msgs, func(a, b QueuedMessage) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+	return msgs, nil
+}
+
+// deliver sends the queued messages to the device in order, removing each
+// once sent. It stops at the first failure; what remains is sent on the
+// next connection.
+func (q *deviceQueue) deliver(ctx context.Context, device protocol.DeviceID, conn protocol.Connection) error {
+	q.mut.Lock()
+	if q.delivering[device] {
+		// Whoever is delivering picks up the new messages as well.
+		q.mut.Unlock()
+		return nil
+	}
+	q.delivering[device] = true
+	q.mut.Unlock()
+
+	for {
+		q.mut.Lock()
+		msgs, err := q.listLocked(device)
+		if err != nil || len(msgs) == 0 {
+			delete(q.delivering, device)
+			q.mut.Unlock()
+			return err
+		}
+		q.mut.Unlock()
+
+		for _, msg := range msgs {
+			err := conn.ControlMessage(ctx, &protocol.ControlMessage{
+				Kind:    msg.Kind,
+				Key:     msg.Key,
+				Payload: msg.Payload,
+				Queued:  msg.Queued,
+			})
+			q.mut.Lock()
+			if err == nil {
+				err = q.kv.DeleteKV(deviceQueueKey(device, msg.Seq))
+			}
+			if err != nil {
+				delete(q.delivering, device)
+				q.mut.Unlock()
+				return err
+			}
+			q.mut.Unlock()
+		}
+	}
+}
+
+// seen records the reception of a message and returns whether it's newer
+// than any message of the same kind and key previously received from the
+// device.
+func (q *deviceQueue) seen(device protocol.DeviceID, msg *protocol.ControlMessage) bool {
+	key := device.String() + "/" + msg.Kind + "/" + msg.Key
+	q.mut.Lock()
+	defer q.mut.Unlock()
+	if last, ok := q.received[key]; ok && !msg.Queued.After(last) {
+		return false
+	}
+	q.received[key] = msg.Queued
+	return true
+}
+
+// QueueDeviceMessage queues a control message for the device, to be sent
+// right away if it's connected and otherwise when it next connects.
+func (m *model) QueueDeviceMessage(device protocol.DeviceID, kind, key string, payload []byte) error {
+	if _, ok := m.cfg.Device(device); !ok {
+		return errDeviceUnknown
+	}
+	if err := m.deviceQueue.enqueue(device, kind, key, payload); err != nil {
+		return err
+	}
+
+	m.mut.RLock()
+	conn, ok := m.connections[m.promotedConnID[device]]
+	m.mut.RUnlock()
+	if ok {
+		go m.deliverQueuedMessages(device, conn)
+	}
+	return nil
+}
+
+// QueuedDeviceMessages returns the control messages waiting to be sent to
+// the device, oldest first.
+func (m *model) QueuedDeviceMessages(device protocol.DeviceID) ([]QueuedMessage, error) {
+	return m.deviceQueue.list(device)
+}
+
+func (m *model) deliverQueuedMessages(device protocol.DeviceID, conn protocol.Connection) {
+	if err := m.deviceQueue.deliver(context.Background(), device, conn); err != nil {
+		l.Debugf("Delivering queued control messages to %s: %v", device.Short(), err)
+	}
+}
+
+// ControlMessage handles a control message from a peer. Messages that are
+// older than one already received for the same kind and key are dropped.
+func (m *model) ControlMessage(conn protocol.Connection, msg *protocol.ControlMessage) error {
+	deviceID := conn.DeviceID()
+	if !m.deviceQueue.seen(deviceID, msg) {
+		l.Debugln("Ignoring outdated control message", deviceID.Short(), msg.Kind, msg.Key)
+		return nil
+	}
+	m.evLogger.Log(events.ControlMessageReceived, map[string]interface{}{
+		"device":  deviceID.String(),
+		"kind":    msg.Kind,
+		"key":     msg.Key,
+		"payload": msg.Payload,
+		"queued":  msg.Queued,
+	})
+	if strings.HasPrefix(msg.Kind, configSyncKindPrefix) {
+		return m.handleConfigSync(deviceID, msg)
+	}
+	if strings.HasPrefix(msg.Kind, folderHandoverKindPrefix) {
+		return m.handleFolderHandover(deviceID, msg)
+	}
+	if strings.HasPrefix(msg.Kind, deviceRevocationKindPrefix) {
+		return m.handleDeviceRevocation(deviceID, msg)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db/sqlite"
+	"github.com/syncthing/syncthing/lib/protocol"
+	protocolmocks "github.com/syncthing/syncthing/lib/protocol/mocks"
+)
+
+func TestDeviceQueue(t *testing.T) {
+	sdb, err := sqlite.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sdb.Close()
+	})
+	q := newDeviceQueue(sdb)
+
+	must(t, q.enqueue(device1, "offer", "a", []byte("a1")))
+	must(t, q.enqueue(device1, "offer", "b", []byte("b1")))
+	must(t, q.enqueue(device2, "offer", "a", []byte("other")))
+	// Replaces the first message, and goes last
+	must(t, q.enqueue(device1, "offer", "a", []byte("a2")))
+
+	msgs, err := q.list(device1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 2 || string(msgs[0].Payload) != "b1" || string(msgs[1].Payload) != "a2" {
+		t.Fatalf("unexpected queue %+v", msgs)
+	}
+
+	// Delivery stops at the first failure and keeps what wasn't sent.
+	conn := &protocolmocks.Connection{}
+	conn.ControlMessageReturnsOnCall(1, errors.New("boom"))
+	if err := q.deliver(context.Background(), device1, conn); err == nil {
+		t.Fatal("expected delivery to fail")
+	}
+	if msgs, _ := q.list(device1); len(msgs) != 1 || string(msgs[0].Payload) != "a2" {
+		t.Fatalf("unexpected queue after failed delivery %+v", msgs)
+	}
+
+	must(t, q.deliver(context.Background(), device1, conn))
+	if msgs, _ := q.list(device1); len(msgs) != 0 {
+		t.Fatalf("expected an empty queue, got %+v", msgs)
+	}
+	if n := conn.ControlMessageCallCount(); n != 3 {
+		t.Fatalf("expected three sends, got %d", n)
+	}
+	for i, exp := range []string{"b1", "a2", "a2"} {
+		if _, msg := conn.ControlMessageArgsForCall(i); string(msg.Payload) != exp {
+			t.Errorf("send %d: expected %q, got %q", i, exp, msg.Payload)
+		}
+	}
+	if msgs, _ := q.list(device2); len(msgs) != 1 {
+		t.Errorf("other device's queue affected: %+v", msgs)
+	}
+}
+
+func TestDeviceQueueBounds(t *testing.T) {
+	sdb, err := sqlite.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sdb.Close()
+	})
+	q := newDeviceQueue(sdb)
+
+	if err := q.enqueue(device1, "patterns", "", make([]byte, maxDeviceQueueBytes+1)); !errors.Is(err, ErrControlMessageTooLarge) {
+		t.Fatalf("expected the message to be rejected, got %v", err)
+	}
+
+	// Filling the queue by size drops the oldest messages.
+	half := make([]byte, maxDeviceQueueBytes/2)
+	for _, key := range []string{"a", "b", "c"} {
+		must(t, q.enqueue(device1, "offer", key, half))
+	}
+	msgs, err := q.list(device1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 2 || msgs[0].Key != "b" || msgs[1].Key != "c" {
+		t.Fatalf("unexpected queue %+v", msgs)
+	}
+}
+
+func TestDeviceQueueSeen(t *testing.T) {
+	q := newDeviceQueue(nil)
+	now := time.Now()
+	msg := func(key string, queued time.Time) *protocol.ControlMessage {
+		return &protocol.ControlMessage{Kind: "offer", Key: key, Queued: queued}
+	}
+
+	if !q.seen(device1, msg("a", now)) {
+		t.Error("first message should be new")
+	}
+	if q.seen(device1, msg("a", now.Add(-time.Second))) {
+		t.Error("older message should be dropped")
+	}
+	if q.seen(device1, msg("a", now)) {
+		t.Error("duplicate message should be dropped")
+	}
+	if !q.seen(device1, msg("b", now)) || !q.seen(device2, msg("a", now)) {
+		t.Error("messages with other keys or from other devices are new")
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+const (
+	// Pending local changes beyond this make the index sender look ahead
+	// for deleted directories to announce first.
+	twoPhaseDeleteThreshold = 10000
+	// Index updates that are mostly deletions are sent at most this often
+	// to peers supporting two-phase deletes, instead of back to back.
+	twoPhaseDeleteBatchInterval = time.Second
+	// A tombstone not followed by the actual directory deletion in this
+	// time is forgotten.
+	dirTombstoneLifetime = 15 * time.Minute
+)
+
+// dirTombstones remembers directories that peers announced as deleted,
+// ahead of the deletions of their contents. The puller doesn't fetch files
+// inside them that the announcing device has, as its deletion is about to
+// supersede them anyway. Expired tombstones are dropped by the folder before
+// pulling.
+type dirTombstones struct {
+	mut     sync.Mutex
+	folders map[string]map[dirTombstoneKey]time.Time // folder -> directory and device -> expiry
+	onClear func(folder string)
+}
+
+type dirTombstoneKey struct {
+	name   string
+	device protocol.DeviceID
+}
+
+func newDirTombstones(onClear func(folder string)) *dirTombstones {
+	return &dirTombstones{
+		folders: make(map[string]map[dirTombstoneKey]time.Time),
+		onClear: onClear,
+	}
+}
+
+func (t *dirTombstones) add(folder string, device protocol.DeviceID, files []protocol.FileInfo) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	dirs, ok := t.folders[folder]
+	if !ok {
+		dirs = make(map[dirTombstoneKey]time.Time)
+		t.folders[folder] = dirs
+	}
+	expires := time.Now().Add(dirTombstoneLifetime)
+	for _, f := range files {
+		if f.IsDirectory() && f.IsDeleted() {
+			dirs[dirTombstoneKey{name: f.Name, device: device}] = expires
+		}
+	}
+	if len(dirs) == 0 {
+		delete(t.folders, folder)
+	}
+}
+
+// resolved forgets the tombstones for the deleted directories in the
+// index update from the device; the deletions have arrived.
+func (t *dirTombstones) resolved(folder string, device protocol.DeviceID, files []protocol.FileInfo) {
+	t.mut.Lock()
+	dirs := t.folders[folder]
+	if len(dirs) == 0 {
+		t.mut.Unlock()
+		return
+	}
+	cleared := false
+	for _, f := range files {
+		if !f.IsDirectory() || !f.IsDeleted() {
+			continue
+		}
+		key := dirTombstoneKey{name: f.Name, device: device}
+		if _, ok := dirs[key]; ok {
+			delete(dirs, key)
+			cleared = true
+		}
+	}
+	if len(dirs) == 0 {
+		delete(t.folders, folder)
+	}
+	t.mut.Unlock()
+	if cleared {
+		t.onClear(folder)
+	}
+}
+
+// forgetDevice forgets the tombstones from a device that disconnected.
+func (t *dirTombstones) forgetDevice(device protocol.DeviceID) {
+	var cleared []string
+	t.mut.Lock()
+	for folder, dirs := range t.folders {
+		n := len(dirs)
+		for key := range dirs {
+			if key.device == device {
+				delete(dirs, key)
+			}
+		}
+		if len(dirs) != n {
+			cleared = append(cleared, folder)
+		}
+		if len(dirs) == 0 {
+			delete(t.folders, folder)
+		}
+	}
+	t.mut.Unlock()
+	for _, folder := range cleared {
+		t.onClear(folder)
+	}
+}
+
+// expire forgets the tombstones of the folder past their lifetime and
+// returns when the next of the remaining ones expires, if any. The folder
+// calls it before pulling, so nothing else needs to know.
+func (t *dirTombstones) expire(folder string) (time.Time, bool) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	dirs := t.folders[folder]
+	now := time.Now()
+	var next time.Time
+	for key, expires := range dirs {
+		if !now.Before(expires) {
+			delete(dirs, key)
+		} else if next.IsZero() || expires.Before(next) {
+			next = expires
+		}
+	}
+	if len(dirs) == 0 {
+		delete(t.folders, folder)
+		return time.Time{}, false
+	}
+	return next, true
+}
+
+// devices returns the devices that announced a directory containing the
+// file as deleted.
+func (t *dirTombstones) devices(folder, name string) []protocol.DeviceID {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	dirs := t.folders[folder]
+	if len(dirs) == 0 {
+		return nil
+	}
+	now := time.Now()
+	var devices []protocol.DeviceID
+	for key, expires := range dirs {
+		if now.Before(expires) && strings.HasPrefix(name, key.name+string(filepath.Separator)) && !slices.Contains(devices, key.device) {
+			devices = append(devices, key.device)
+		}
+	}
+	return devices
+}
+
+// heldByDirTombstone returns whether the needed file is inside a directory
+// a device announced as deleted, while having the file in the version
+// needed or a newer one. Its deletion then supersedes the file, which isn't
+// worth pulling. A version it doesn't have is pulled regardless.
+func (m *model) heldByDirTombstone(folder string, file protocol.FileInfo) bool {
+	if file.IsDeleted() {
+		return false
+	}
+	for _, device := range m.dirTombstones.devices(folder, file.Name) {
+		df, ok, err := m.sdb.GetDeviceFile(folder, device, file.Name)
+		if err == nil && ok && file.Version.LesserEqual(df.Version) {
+			return true
+		}
+	}
+	return false
+}
+
+// topmostDirs returns the directories not inside any of the others.
+func topmostDirs(files []protocol.FileInfo) []protocol.FileInfo {
+	names := make(map[string]struct{}, len(files))
+	for _, f := range files {
+		names[f.Name] = struct{}{}
+	}
+	var res []protocol.FileInfo
+outer:
+	for _, f := range files {
+		for dir := filepath.Dir(f.Name); dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+			if _, ok := names[dir]; ok {
+				continue outer
+			}
+		}
+		res = append(res, f)
+	}
+	return res
+}
@@ -0,0 +1,146 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func deletedDir(name string) protocol.FileInfo {
+	return protocol.FileInfo{Name: filepath.FromSlash(name), Type: protocol.FileInfoTypeDirectory, Deleted: true}
+}
+
+func covers(ts *dirTombstones, folder, name string) bool {
+	return len(ts.devices(folder, filepath.FromSlash(name))) > 0
+}
+
+func TestDirTombstones(t *testing.T) {
+	var cleared []string
+	ts := newDirTombstones(func(folder string) { cleared = append(cleared, folder) })
+	dev1, dev2 := protocol.DeviceID{1}, protocol.DeviceID{2}
+
+	ts.add("f", dev1, []protocol.FileInfo{
+		deletedDir("a/b"),
+		{Name: "c", Type: protocol.FileInfoTypeDirectory}, // not deleted
+	})
+	ts.add("f", dev2, []protocol.FileInfo{deletedDir("d")})
+
+	for name, exp := range map[string]bool{
+		"a/b/file":   true,
+		"a/b/c/file": true,
+		"a/b":        false,
+		"a/file":     false,
+		"c/file":     false,
+		"d/file":     true,
+	} {
+		if got := covers(ts, "f", name); got != exp {
+			t.Errorf("covers(%q) = %v, expected %v", name, got, exp)
+		}
+	}
+	if covers(ts, "other", "a/b/file") {
+		t.Error("tombstone should only cover its own folder")
+	}
+
+	// The deletion from another device doesn't resolve the tombstone
+	ts.resolved("f", dev2, []protocol.FileInfo{deletedDir("a/b")})
+	if !covers(ts, "f", "a/b/file") || len(cleared) != 0 {
+		t.Error("tombstone should only be resolved by its device")
+	}
+	ts.resolved("f", dev1, []protocol.FileInfo{deletedDir("a/b")})
+	if covers(ts, "f", "a/b/file") {
+		t.Error("tombstone should be resolved")
+	}
+	if !slices.Equal(cleared, []string{"f"}) {
+		t.Errorf("unexpected cleared folders %v", cleared)
+	}
+
+	ts.forgetDevice(dev2)
+	if covers(ts, "f", "d/file") {
+		t.Error("tombstone should be forgotten with its device")
+	}
+	if !slices.Equal(cleared, []string{"f", "f"}) {
+		t.Errorf("unexpected cleared folders %v", cleared)
+	}
+}
+
+func TestDirTombstonesPerDevice(t *testing.T) {
+	ts := newDirTombstones(func(string) {})
+	dev1, dev2 := protocol.DeviceID{1}, protocol.DeviceID{2}
+
+	ts.add("f", dev1, []protocol.FileInfo{deletedDir("a")})
+	ts.add("f", dev2, []protocol.FileInfo{deletedDir("a")})
+	if devs := ts.devices("f", filepath.FromSlash("a/file")); len(devs) != 2 {
+		t.Fatalf("expected tombstones from both devices, got %v", devs)
+	}
+	ts.resolved("f", dev1, []protocol.FileInfo{deletedDir("a")})
+	if devs := ts.devices("f", filepath.FromSlash("a/file")); !slices.Equal(devs, []protocol.DeviceID{dev2}) {
+		t.Errorf("expected the other device's tombstone to remain, got %v", devs)
+	}
+
+	// Expired tombstones cover nothing and are dropped when expiring.
+	ts.mut.Lock()
+	ts.folders["f"][dirTombstoneKey{name: "a", device: dev2}] = time.Now().Add(-time.Second)
+	ts.mut.Unlock()
+	if covers(ts, "f", "a/file") {
+		t.Error("expired tombstone should not cover anything")
+	}
+	if _, ok := ts.expire("f"); ok {
+		t.Error("expected no tombstones left")
+	}
+	if len(ts.folders) != 0 {
+		t.Errorf("expired tombstones kept: %v", ts.folders)
+	}
+}
+
+func TestHeldByDirTombstone(t *testing.T) {
+	m, f, wcfgCancel := setupSendReceiveFolder(t)
+	defer wcfgCancel()
+
+	v1 := protocol.Vector{}.Update(device1.Short())
+	v2 := v1.Update(device2.Short())
+	must(t, m.sdb.Update(f.folderID, device1, []protocol.FileInfo{
+		{Name: filepath.FromSlash("dir/file"), Version: v1, Blocks: []protocol.BlockInfo{{Size: 1, Hash: []byte{1}}}, Size: 1},
+	}))
+	m.dirTombstones.add(f.folderID, device1, []protocol.FileInfo{deletedDir("dir")})
+
+	held := protocol.FileInfo{Name: filepath.FromSlash("dir/file"), Version: v1}
+	if !m.heldByDirTombstone(f.folderID, held) {
+		t.Error("file the deleting device has should be held back")
+	}
+	// A newer version from another device isn't superseded by the
+	// deletion and is pulled.
+	newer := protocol.FileInfo{Name: filepath.FromSlash("dir/file"), Version: v2}
+	if m.heldByDirTombstone(f.folderID, newer) {
+		t.Error("newer version should not be held back")
+	}
+	other := protocol.FileInfo{Name: filepath.FromSlash("dir/other"), Version: protocol.Vector{}.Update(device2.Short())}
+	if m.heldByDirTombstone(f.folderID, other) {
+		t.Error("file the deleting device doesn't have should not be held back")
+	}
+}
+
+func TestTopmostDirs(t *testing.T) {
+	dirs := []protocol.FileInfo{
+		deletedDir("a/b/c"),
+		deletedDir("a/b"),
+		deletedDir("a/bc"),
+		deletedDir("x/y/z"),
+		deletedDir("x"),
+	}
+	var names []string
+	for _, f := range topmostDirs(dirs) {
+		names = append(names, filepath.ToSlash(f.Name))
+	}
+	if exp := []string{"a/b", "a/bc", "x"}; !slices.Equal(names, exp) {
+		t.Errorf("got %v, expected %v", names, exp)
+	}
+}
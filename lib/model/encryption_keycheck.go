@@ -0,0 +1,121 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// A device we share a folder with encrypted keeps the token of the password
+// in its folder marker and refuses data encrypted with another one. We keep
+// the tokens the devices confirmed in our folder marker in turn, so that a
+// changed password is refused when the configuration changes, instead of
+// surfacing as an error on the next connection.
+
+var ErrNotSharedEncrypted = errors.New("folder not shared encrypted with device")
+
+var errEncryptionPasswordChanged = errors.New("the device has the folder encrypted with another password; remove the folder on the device to change it")
+
+func deviceTokensPath(cfg config.FolderConfiguration) string {
+	return filepath.Join(cfg.MarkerName, config.EncryptionDeviceTokensName)
+}
+
+func readDeviceTokens(cfg config.FolderConfiguration) (map[protocol.DeviceID][]byte, error) {
+	fd, err := cfg.Filesystem().Open(deviceTokensPath(cfg))
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	var tokens map[protocol.DeviceID][]byte
+	if err := json.NewDecoder(fd).Decode(&tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// rememberDeviceToken keeps the token the device confirmed for the folder.
+func rememberDeviceToken(cfg config.FolderConfiguration, device protocol.DeviceID, token []byte) error {
+	tokens, err := readDeviceTokens(cfg)
+	if err != nil && !fs.IsNotExist(err) {
+		l.Debugf("Reading device encryption tokens of %s, starting over: %v", cfg.Description(), err)
+	}
+	if bytes.Equal(tokens[device], token) {
+		return nil
+	}
+	if tokens == nil {
+		tokens = make(map[protocol.DeviceID][]byte)
+	}
+	tokens[device] = token
+
+	fd, err := cfg.Filesystem().OpenFile(deviceTokensPath(cfg), fs.OptReadWrite|fs.OptCreate|fs.OptTruncate, 0o666)
+	if err != nil {
+		return err
+	}
+	defer fd.Close()
+	return json.NewEncoder(fd).Encode(tokens)
+}
+
+// verifyEncryptionPasswords checks the encryption passwords that are new or
+// changed between the configurations: they must be strong enough, and a
+// changed one must still be the one the device has the folder encrypted
+// with, as far as we know.
+func (m *model) verifyEncryptionPasswords(from, to config.Configuration) error {
+	fromFolders := from.FolderMap()
+	for _, toFolder := range to.Folders {
+		fromFolder, existed := fromFolders[toFolder.ID]
+		var tokens map[protocol.DeviceID][]byte
+		for _, dev := range toFolder.Devices {
+			if dev.EncryptionPassword == "" {
+				continue
+			}
+			fromDev, shared := fromFolder.Device(dev.DeviceID)
+			if existed && shared && fromDev.EncryptionPassword == dev.EncryptionPassword {
+				continue
+			}
+			if err := config.CheckEncryptionPassword(dev.EncryptionPassword); err != nil {
+				return fmt.Errorf("folder %s, device %s: %w", toFolder.Description(), dev.DeviceID.Short(), err)
+			}
+			if !existed || !shared || fromDev.EncryptionPassword == "" {
+				continue
+			}
+			if tokens == nil {
+				var err error
+				if tokens, err = readDeviceTokens(fromFolder); err != nil {
+					tokens = make(map[protocol.DeviceID][]byte)
+				}
+			}
+			known, ok := tokens[dev.DeviceID]
+			if ok && !bytes.Equal(known, protocol.PasswordToken(m.keyGen, toFolder.ID, dev.EncryptionPassword)) {
+				return fmt.Errorf("folder %s, device %s: %w", toFolder.Description(), dev.DeviceID.Short(), errEncryptionPasswordChanged)
+			}
+		}
+	}
+	return nil
+}
+
+// EncryptionRecoveryCode returns the recovery code of the key the folder is
+// encrypted with for the device. It decrypts the folder on the device, e.g.
+// with `syncthing decrypt`, should the password be lost.
+func (m *model) EncryptionRecoveryCode(folder string, device protocol.DeviceID) (string, error) {
+	cfg, ok := m.cfg.Folder(folder)
+	if !ok {
+		return "", ErrFolderMissing
+	}
+	dev, ok := cfg.Device(device)
+	if !ok || dev.EncryptionPassword == "" {
+		return "", ErrNotSharedEncrypted
+	}
+	return protocol.RecoveryCode(m.keyGen.KeyFromPassword(cfg.ID, dev.EncryptionPassword)), nil
+}
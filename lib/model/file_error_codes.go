@@ -0,0 +1,113 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"syscall"
+
+	"github.com/syncthing/syncthing/lib/build"
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+// Machine readable codes for scan and pull errors, for use by GUIs and
+// scripts. The codes are part of the API; don't change existing ones.
+const (
+	ErrCodeUnknown             = "ERR_UNKNOWN"
+	ErrCodePathTooLong         = "ERR_PATH_TOO_LONG"
+	ErrCodePermissionDenied    = "ERR_PERMISSION_DENIED"
+	ErrCodeNoSpace             = "ERR_NO_SPACE"
+	ErrCodeReadOnlyFilesystem  = "ERR_READ_ONLY_FS"
+	ErrCodeTooManyOpenFiles    = "ERR_TOO_MANY_OPEN_FILES"
+	ErrCodeFileInUse           = "ERR_FILE_IN_USE"
+	ErrCodeInvalidFilename     = "ERR_INVALID_FILENAME"
+	ErrCodeCaseConflict        = "ERR_CASE_CONFLICT"
+	ErrCodeNoSource            = "ERR_NO_SOURCE"
+	ErrCodeModified            = "ERR_MODIFIED"
+	ErrCodeDirNotEmpty         = "ERR_DIR_NOT_EMPTY"
+	ErrCodeIncompatibleSymlink = "ERR_INCOMPATIBLE_SYMLINK"
+)
+
+// Windows error numbers not in package syscall.
+const (
+	windowsErrorSharingViolation   = syscall.Errno(32)
+	windowsErrorWriteProtect       = syscall.Errno(19)
+	windowsErrorLockViolation      = syscall.Errno(33)
+	windowsErrorHandleDiskFull     = syscall.Errno(39)
+	windowsErrorDiskFull           = syscall.Errno(112)
+	windowsErrorFilenameExcedRange = syscall.Errno(206)
+)
+
+var fileErrorRemediations = map[string]string{
+	ErrCodePathTooLong:         "Shorten the path, for example by moving the folder closer to the root of the drive, or enable long path support in the operating system.",
+	ErrCodePermissionDenied:    "Make sure the user running Syncthing has read and write permission on the file and its parent directory.",
+	ErrCodeNoSpace:             "Free up space on the disk holding the folder, or lower the minimum free disk space setting.",
+	ErrCodeReadOnlyFilesystem:  "Remount the filesystem read-write, or change the folder type to send only.",
+	ErrCodeTooManyOpenFiles:    "Raise the limit on open files for the user running Syncthing.",
+	ErrCodeFileInUse:           "Close the program that has the file open; syncing is retried automatically.",
+	ErrCodeInvalidFilename:     "Rename the file on the remote device to a name that is valid on this operating system.",
+	ErrCodeCaseConflict:        "Change the upper or lowercase characters of the name on either side to match the other.",
+	ErrCodeNoSource:            "Wait for, or connect, a device that has the current version of the file.",
+	ErrCodeModified:            "No action needed; the file is rescanned and synced again automatically.",
+	ErrCodeDirNotEmpty:         "Remove the remaining files from the directory, or use the (?d) prefix in the ignore patterns to allow deleting them.",
+	ErrCodeIncompatibleSymlink: "Upgrade Syncthing on the device the symlink comes from and rescan there.",
+}
+
+// classifyFileError returns the code and suggested remediation for an error
+// while scanning or pulling a file.
+func classifyFileError(err error) (code, remediation string) {
+	code = fileErrorCode(err)
+	return code, fileErrorRemediations[code]
+}
+
+func fileErrorCode(err error) string {
+	switch {
+	case fs.IsErrCaseConflict(err):
+		return ErrCodeCaseConflict
+	case fs.IsErrInvalidFilename(err):
+		return ErrCodeInvalidFilename
+	case errors.Is(err, errNoDevice), errors.Is(err, errNotAvailable):
+		return ErrCodeNoSource
+	case errors.Is(err, errModified), errors.Is(err, errDirHasToBeScanned):
+		return ErrCodeModified
+	case errors.Is(err, errDirHasIgnored), errors.Is(err, errDirNotEmpty):
+		return ErrCodeDirNotEmpty
+	case errors.Is(err, errIncompatibleSymlink):
+		return ErrCodeIncompatibleSymlink
+	}
+
+	var errno syscall.Errno
+	if errors.As(err, &errno) {
+		switch errno {
+		case syscall.ENAMETOOLONG:
+			return ErrCodePathTooLong
+		case syscall.ENOSPC:
+			return ErrCodeNoSpace
+		case syscall.EROFS:
+			return ErrCodeReadOnlyFilesystem
+		case syscall.EMFILE, syscall.ENFILE:
+			return ErrCodeTooManyOpenFiles
+		}
+		if build.IsWindows {
+			switch errno {
+			case windowsErrorFilenameExcedRange:
+				return ErrCodePathTooLong
+			case windowsErrorDiskFull, windowsErrorHandleDiskFull:
+				return ErrCodeNoSpace
+			case windowsErrorSharingViolation, windowsErrorLockViolation:
+				return ErrCodeFileInUse
+			case windowsErrorWriteProtect:
+				return ErrCodeReadOnlyFilesystem
+			}
+		}
+	}
+
+	if fs.IsPermission(err) {
+		return ErrCodePermissionDenied
+	}
+	return ErrCodeUnknown
+}
@@ -0,0 +1,42 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+func TestClassifyFileError(t *testing.T) {
+	cases := []struct {
+		err  error
+		code string
+	}{
+		{&os.PathError{Op: "open", Path: "foo", Err: syscall.ENAMETOOLONG}, ErrCodePathTooLong},
+		{&os.PathError{Op: "write", Path: "foo", Err: syscall.ENOSPC}, ErrCodeNoSpace},
+		{fmt.Errorf("creating temp file: %w", os.ErrPermission), ErrCodePermissionDenied},
+		{fs.WindowsInvalidFilename("foo?bar"), ErrCodeInvalidFilename},
+		{&fs.CaseConflictError{Given: "Foo", Real: "foo"}, ErrCodeCaseConflict},
+		{fmt.Errorf("pulling: %w", errNoDevice), ErrCodeNoSource},
+		{errDirHasIgnored, ErrCodeDirNotEmpty},
+		{errors.New("something else"), ErrCodeUnknown},
+	}
+	for _, tc := range cases {
+		code, remediation := classifyFileError(tc.err)
+		if code != tc.code {
+			t.Errorf("%v: got code %s, expected %s", tc.err, code, tc.code)
+		}
+		if (remediation == "") != (code == ErrCodeUnknown) {
+			t.Errorf("%v: unexpected remediation %q", tc.err, remediation)
+		}
+	}
+}
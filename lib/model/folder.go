@@ -16,9 +16,11 @@ import (
 	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/faultinject"
 	"github.com/syncthing/syncthing/internal/itererr"
 	"github.com/syncthing/syncthing/internal/slogutil"
 	"github.com/syncthing/syncthing/lib/config"
@@ -59,16 +61,22 @@ type folder struct {
 	sl            *slog.Logger
 
 	scanInterval           time.Duration
+	rescanInterval         atomic.Int64 // effective, adapted by rescanTuner
+	rescanTuner            rescanTuner
+	lastScanChanges        int
+	lastWatchStats         fs.WatchStats
 	scanTimer              *time.Timer
 	scanDelay              chan time.Duration
 	initialScanFinished    chan struct{}
 	scanScheduled          chan struct{}
 	versionCleanupInterval time.Duration
 	versionCleanupTimer    *time.Timer
+	tempCleanupTimer       *time.Timer
 
 	pullScheduled chan struct{}
 	pullPause     time.Duration
 	pullFailTimer *time.Timer
+	readOnly      atomic.Bool // the filesystem was found read-only
 
 	scanErrors []FileError
 	pullErrors []FileError
@@ -89,7 +97,20 @@ type folder struct {
 	puller    puller
 	versioner versioner.Versioner
 
-	warnedKqueue bool
+	warnedKqueue    bool
+	warnedNetworkFS bool
+
+	// Whether pulls count as the initial sync, which is from when the
+	// first pull finds nothing local until a pull succeeds.
+	initialSync        bool
+	initialSyncChecked bool
+	// The number of hashers granted for the current scan
+	scanHashers int
+	// Files not hashed again while unchanged in size and modification
+	// time, nil if none
+	trusted *trustedFiles
+	// What the filesystem was found to support, nil if unknown
+	capabilities *fs.Capabilities
 }
 
 type syncRequest struct {
@@ -113,6 +134,7 @@ func newFolder(model *model, ignores *ignore.Matcher, cfg config.FolderConfigura
 		db:            model.sdb,
 		ignores:       ignores,
 		mtimefs:       cfg.Filesystem(fs.NewMtimeOption(model.sdb, cfg.ID)),
+		trusted:       newTrustedFiles(cfg.TrustedPatterns, cfg.TrustedVerifyMiB),
 		modTimeWindow: cfg.ModTimeWindow(),
 		done:          make(chan struct{}),
 		sl:            slog.Default().With(cfg.LogAttr()),
@@ -124,6 +146,7 @@ func newFolder(model *model, ignores *ignore.Matcher, cfg config.FolderConfigura
 		scanScheduled:          make(chan struct{}, 1),
 		versionCleanupInterval: time.Duration(cfg.Versioning.CleanupIntervalS) * time.Second,
 		versionCleanupTimer:    time.NewTimer(time.Duration(cfg.Versioning.CleanupIntervalS) * time.Second),
+		tempCleanupTimer:       time.NewTimer(time.Duration(model.cfg.Options().TempCleanupIntervalS) * time.Second),
 
 		pullScheduled: make(chan struct{}, 1), // This needs to be 1-buffered so that we queue a pull if we're busy when it comes.
 
@@ -137,11 +160,16 @@ func newFolder(model *model, ignores *ignore.Matcher, cfg config.FolderConfigura
 
 		versioner: ver,
 	}
+	if caps, ok := model.fsCapabilities.current(cfg); ok {
+		f.capabilities = &caps.Capabilities
+	}
+	f.rescanTuner = newRescanTuner(f.scanInterval)
 	f.pullPause = f.pullBasePause()
 	f.pullFailTimer = time.NewTimer(0)
 	<-f.pullFailTimer.C
 
 	registerFolderMetrics(f.ID)
+	f.setRescanInterval(f.scanInterval)
 
 	return &f
 }
@@ -158,6 +186,10 @@ func (f *folder) Serve(ctx context.Context) error {
 	defer func() {
 		f.scanTimer.Stop()
 		f.versionCleanupTimer.Stop()
+		f.tempCleanupTimer.Stop()
+		f.model.dependencyWaits.set(f.ID, "")
+		f.model.folderQuotas.set(f.ID, 0)
+		f.model.writeVerifications.forget(f.ID)
 		f.setState(FolderIdle)
 	}()
 
@@ -247,6 +279,10 @@ func (f *folder) Serve(ctx context.Context) error {
 		case <-f.versionCleanupTimer.C:
 			l.Debugln(f, "Doing version cleanup")
 			f.versionCleanupTimerFired()
+
+		case <-f.tempCleanupTimer.C:
+			l.Debugln(f, "Doing temporary file cleanup")
+			f.tempCleanupTimerFired()
 		}
 
 		if err != nil {
@@ -283,6 +319,7 @@ func (f *folder) ignoresUpdated() {
 	if f.FSWatcherEnabled {
 		f.scheduleWatchRestart()
 	}
+	f.model.ignoresChanged(f.FolderConfiguration, f.ignores)
 }
 
 func (f *folder) SchedulePull() {
@@ -322,11 +359,12 @@ func (f *folder) doInSync(fn func() error) error {
 }
 
 func (f *folder) Reschedule() {
-	if f.scanInterval == 0 {
+	scanInterval := f.RescanInterval()
+	if scanInterval == 0 {
 		return
 	}
-	// Sleep a random time between 3/4 and 5/4 of the configured interval.
-	sleepNanos := (f.scanInterval.Nanoseconds()*3 + rand.Int63n(2*f.scanInterval.Nanoseconds())) / 4 //nolint:gosec
+	// Sleep a random time between 3/4 and 5/4 of the interval.
+	sleepNanos := (scanInterval.Nanoseconds()*3 + rand.Int63n(2*scanInterval.Nanoseconds())) / 4 //nolint:gosec
 	interval := time.Duration(sleepNanos) * time.Nanosecond
 	l.Debugln(f, "next rescan in", interval)
 	f.scanTimer.Reset(interval)
@@ -352,6 +390,10 @@ func (f *folder) getHealthErrorWithoutIgnores() error {
 		return err
 	}
 
+	if err := f.checkReadOnly(); err != nil {
+		return err
+	}
+
 	if minFree := f.model.cfg.Options().MinHomeDiskFree; minFree.Value > 0 {
 		dbPath := locations.Get(locations.Database)
 		if usage, err := fs.NewFilesystem(fs.FilesystemTypeBasic, dbPath).Usage("."); err == nil {
@@ -385,6 +427,9 @@ func (f *folder) pull() (success bool, err error) {
 		}
 	}()
 
+	// Tombstones past their lifetime don't hold anything back any more.
+	tombstoneExpiry, tombstones := f.model.dirTombstones.expire(f.ID)
+
 	// If there is nothing to do, don't even enter sync-waiting state.
 	needCount, err := f.db.CountNeed(f.folderID, protocol.LocalDeviceID)
 	if err != nil {
@@ -395,6 +440,7 @@ func (f *folder) pull() (success bool, err error) {
 		f.errorsMut.Lock()
 		f.pullErrors = nil
 		f.errorsMut.Unlock()
+		f.setQuotaExceeded(0)
 		return true, nil
 	}
 
@@ -402,14 +448,39 @@ func (f *folder) pull() (success bool, err error) {
 	err = f.getHealthErrorWithoutIgnores()
 	if err != nil {
 		l.Debugln("Skipping pull of", f.Description(), "due to folder error:", err)
+		if errors.Is(err, errFolderReadOnly) {
+			// Check again later, as nothing else might trigger a pull
+			f.pullFailTimer.Reset(readOnlyProbeInterval)
+		}
 		return false, err
 	}
 
+	// Folders this one depends on are synced first; a send only folder
+	// has nothing to wait for.
+	if f.Type != config.FolderTypeSendOnly && !f.dependenciesMet() {
+		return true, nil
+	}
+
 	// Send only folder doesn't do any io, it only checks for out-of-sync
 	// items that differ in metadata and updates those.
 	if f.Type != config.FolderTypeSendOnly {
 		f.setState(FolderSyncWaiting)
 
+		if !f.initialSyncChecked {
+			seq, err := f.db.GetDeviceSequence(f.folderID, protocol.LocalDeviceID)
+			if err != nil {
+				return false, err
+			}
+			f.initialSync = seq == 0
+			f.initialSyncChecked = true
+		}
+		if f.initialSync {
+			if _, err := f.model.folderSlots.take(f.ctx, slotClassInitialSync, f.ID, 1); err != nil {
+				return true, err
+			}
+			defer f.model.folderSlots.give(slotClassInitialSync, 1)
+		}
+
 		if err := f.ioLimiter.TakeWithContext(f.ctx, 1); err != nil {
 			return true, err
 		}
@@ -435,9 +506,21 @@ func (f *folder) pull() (success bool, err error) {
 	success, err = f.puller.pull()
 
 	if success && err == nil {
+		f.initialSync = false
+		f.model.scheduleDependents(f.ID)
+		if tombstones {
+			// Pull what the tombstones held back if they expire before
+			// the directory deletions arrive.
+			f.pullFailTimer.Reset(time.Until(tombstoneExpiry))
+		}
 		return true, nil
 	}
 
+	if errors.Is(err, errFolderReadOnly) {
+		f.pullFailTimer.Reset(readOnlyProbeInterval)
+		return false, err
+	}
+
 	// Pulling failed, try again later.
 	delay := f.pullPause + time.Since(startTime)
 	f.sl.Info("Folder failed to sync, will be retried", slog.String("wait", stringutil.NiceDurationString(delay)))
@@ -471,6 +554,20 @@ func (f *folder) scanSubdirs(subDirs []string) error {
 	f.setState(FolderScanWaiting)
 	defer f.setState(FolderIdle)
 
+	if _, err := f.model.folderSlots.take(f.ctx, slotClassScan, f.ID, 1); err != nil {
+		return err
+	}
+	defer f.model.folderSlots.give(slotClassScan, 1)
+	if f.Type != config.FolderTypeReceiveEncrypted {
+		// Encrypted folders aren't hashed.
+		hashers, err := f.model.folderSlots.take(f.ctx, slotClassHashers, f.ID, f.model.numHashers(f.ID))
+		if err != nil {
+			return err
+		}
+		f.scanHashers = hashers
+		defer f.model.folderSlots.give(slotClassHashers, hashers)
+	}
+
 	if err := f.ioLimiter.TakeWithContext(f.ctx, 1); err != nil {
 		return err
 	}
@@ -512,12 +609,15 @@ func (f *folder) scanSubdirs(subDirs []string) error {
 	changes := 0
 	defer func() {
 		l.Debugf("%v finished scanning, detected %v changes", f, changes)
+		f.lastScanChanges = changes
 		if changes > 0 {
 			f.SchedulePull()
 		}
 	}()
 
+	f.trusted.startScan(len(subDirs) == 0)
 	changesHere, err := f.scanSubdirsChangedAndNew(subDirs, batch)
+	f.trusted.finishScan(err == nil)
 	changes += changesHere
 	if err != nil {
 		return err
@@ -545,6 +645,7 @@ func (f *folder) scanSubdirs(subDirs []string) error {
 	if err := batch.Flush(); err != nil {
 		return err
 	}
+	batch.reportOwnershipViolations()
 
 	f.ScanCompleted()
 	return nil
@@ -556,6 +657,8 @@ type scanBatch struct {
 	f           *folder
 	updateBatch *FileInfoBatch
 	toRemove    []string
+	ownership   *folderOwnership
+	violations  []string // changed files owned by other devices
 }
 
 func (f *folder) newScanBatch() *scanBatch {
@@ -563,6 +666,9 @@ func (f *folder) newScanBatch() *scanBatch {
 		f:        f,
 		toRemove: make([]string, 0, maxToRemove),
 	}
+	if f.Type == config.FolderTypeSendReceive {
+		b.ownership = f.model.folderOwnership(f.FolderConfiguration)
+	}
 	b.updateBatch = NewFileInfoBatch(func(fs []protocol.FileInfo) error {
 		if err := b.f.getHealthErrorWithoutIgnores(); err != nil {
 			l.Debugf("Stopping scan of folder %s due to: %s", b.f.Description(), err)
@@ -615,9 +721,13 @@ func (b *scanBatch) Update(fi protocol.FileInfo) (bool, error) {
 		}
 		return false, nil
 	}
+	// Changes under a prefix owned by another device are kept local, like
+	// in a receive-only folder, so that the owner's version prevails.
+	foreign := b.ownership.foreign(fi.Name, b.f.model.id)
 	// Resolve receive-only items which are identical with the global state or
 	// the global item is our own receive-only item.
-	switch gf, ok, err := b.f.db.GetGlobalFile(b.f.folderID, fi.Name); {
+	gf, ok, err := b.f.db.GetGlobalFile(b.f.folderID, fi.Name)
+	switch {
 	case err != nil:
 		return false, err
 	case !ok:
@@ -629,7 +739,7 @@ func (b *scanBatch) Update(fi protocol.FileInfo) (bool, error) {
 			l.Debugf("%v scanning: deleting deleted receive-only local-changed file: %v", b.f, fi)
 			return true, nil
 		}
-	case (b.f.Type == config.FolderTypeReceiveOnly || b.f.Type == config.FolderTypeReceiveEncrypted) &&
+	case (b.f.Type == config.FolderTypeReceiveOnly || b.f.Type == config.FolderTypeReceiveEncrypted || foreign) &&
 		gf.IsEquivalentOptional(fi, protocol.FileInfoComparison{
 			ModTimeWindow:   b.f.modTimeWindow,
 			IgnorePerms:     b.f.IgnorePerms,
@@ -641,7 +751,14 @@ func (b *scanBatch) Update(fi protocol.FileInfo) (bool, error) {
 		// What we have locally is equivalent to the global file.
 		l.Debugf("%v scanning: Merging identical locally changed item with global: %v", b.f, fi)
 		fi = gf
+		foreign = false
+	}
+	if foreign {
+		l.Debugf("%v scanning: local change to item owned by another device: %v", b.f, fi)
+		fi.LocalFlags |= protocol.FlagLocalReceiveOnly
+		b.violations = append(b.violations, fi.Name)
 	}
+	b.f.recordLocalChange(fi, ok && !gf.IsDeleted())
 	b.updateBatch.Append(fi)
 	return true, nil
 }
@@ -663,7 +780,7 @@ func (f *folder) scanSubdirsChangedAndNew(subDirs []string, batch *scanBatch) (i
 		Filesystem:            f.mtimefs,
 		IgnorePerms:           f.IgnorePerms,
 		AutoNormalize:         f.AutoNormalize,
-		Hashers:               f.model.numHashers(f.ID),
+		Hashers:               f.scanHashers,
 		ShortID:               f.shortID,
 		ProgressTickIntervalS: f.ScanProgressIntervalS,
 		LocalFlags:            f.localFlags,
@@ -672,6 +789,14 @@ func (f *folder) scanSubdirsChangedAndNew(subDirs []string, batch *scanBatch) (i
 		ScanOwnership:         f.SendOwnership || f.SyncOwnership,
 		ScanXattrs:            f.SendXattrs || f.SyncXattrs,
 		XattrFilter:           f.XattrFilter,
+		ScanHardLinks:         f.PreserveHardLinks,
+		IOPriority:            f.ScanIOPriority.Or(f.model.cfg.Options().ScanIOPriority).OSPriority(),
+	}
+	if len(f.ViewSources) > 0 {
+		scanConfig.FileSource = newViewFileSource(f.db, f.ViewSources)
+	}
+	if f.trusted != nil && f.Type != config.FolderTypeReceiveEncrypted {
+		scanConfig.ContentClassifier = f.trusted
 	}
 	var fchan chan scanner.ScanResult
 	if f.Type == config.FolderTypeReceiveEncrypted {
@@ -696,6 +821,17 @@ func (f *folder) scanSubdirsChangedAndNew(subDirs []string, batch *scanBatch) (i
 			return changes, err
 		}
 
+		if f.trusted.verified(res.File.Name) {
+			cur, ok, err := f.db.GetDeviceFile(f.folderID, protocol.LocalDeviceID, res.File.Name)
+			if err != nil {
+				return changes, err
+			}
+			if ok && cur.BlocksEqual(res.File) {
+				continue
+			}
+			f.sl.Warn("Trusted file changed without a change in size or modification time", slogutil.FilePath(res.File.Name))
+		}
+
 		if ok, err := batch.Update(res.File); err != nil {
 			return 0, err
 		} else if ok {
@@ -947,6 +1083,9 @@ func (f *folder) scanTimerFired() error {
 
 	select {
 	case <-f.initialScanFinished:
+		if err == nil {
+			f.tuneRescanInterval()
+		}
 	default:
 		if err != nil {
 			f.sl.Error("Failed initial scan", slogutil.Error(err))
@@ -1024,6 +1163,19 @@ func (f *folder) startWatch() {
 	go f.monitorWatch(ctx)
 }
 
+// suggestHybridWatch logs a suggestion to enable hybrid watching, once, if
+// the folder lives on a network filesystem where kernel change
+// notifications are known to be unreliable.
+func (f *folder) suggestHybridWatch(ctx context.Context) {
+	if f.FSWatcherHybridPoll || f.warnedNetworkFS {
+		return
+	}
+	f.warnedNetworkFS = true
+	if ok, fstype := fs.IsNetworkFilesystem(f.mtimefs); ok {
+		slog.InfoContext(ctx, "Folder is on a network filesystem where change notifications may be missed; consider enabling hybrid watching (fsWatcherHybridPoll)", f.LogAttr(), slog.String("fstype", fstype))
+	}
+}
+
 // monitorWatch starts the filesystem watching and retries every minute on failure.
 // It should not be used except in startWatch.
 func (f *folder) monitorWatch(ctx context.Context) {
@@ -1064,6 +1216,7 @@ func (f *folder) monitorWatch(ctx context.Context) {
 				continue
 			}
 			lastWatch = time.Now()
+			f.suggestHybridWatch(ctx)
 			watchaggregator.Aggregate(aggrCtx, eventChan, f.watchChan, f.FolderConfiguration, f.model.cfg, f.evLogger)
 			l.Debugln("Started filesystem watcher for folder", f.Description())
 		case err = <-errChan:
@@ -1188,17 +1341,58 @@ func (f *folder) pullBasePause() time.Duration {
 	return time.Duration(f.PullerPauseS) * time.Second
 }
 
+// archiveInfo describes the change to the file, for the versioner.
+func (f *folder) archiveInfo(file protocol.FileInfo) versioner.ArchiveInfo {
+	return versioner.ArchiveInfo{ModifiedBy: f.deviceIDFromShort(file.ModifiedBy)}
+}
+
+// archiver returns a function archiving files with the versioner for the
+// change, for use with inWritableDir.
+func (f *folder) archiver(info versioner.ArchiveInfo) func(string) error {
+	return func(name string) error {
+		return versioner.ArchiveWithInfo(f.versioner, name, info)
+	}
+}
+
+// deviceIDFromShort returns the ID of the device with the short ID, as far
+// as we know it, or else the short ID.
+func (f *folder) deviceIDFromShort(id protocol.ShortID) string {
+	switch id {
+	case 0:
+		return ""
+	case f.shortID:
+		return f.model.id.String()
+	}
+	for devID := range f.model.cfg.Devices() {
+		if devID.Short() == id {
+			return devID.String()
+		}
+	}
+	return id.String()
+}
+
 func (f *folder) String() string {
 	return fmt.Sprintf("%s/%s@%p", f.Type, f.folderID, f)
 }
 
+// checkName returns an error if the name is too long for the filesystem.
+func (f *folder) checkName(name string) error {
+	if f.capabilities == nil {
+		return nil
+	}
+	return f.capabilities.CheckName(name)
+}
+
+// symlinksSupported returns false if the filesystem was found not to
+// support symlinks.
+func (f *folder) symlinksSupported() bool {
+	return f.capabilities == nil || f.capabilities.Symlinks
+}
+
 func (f *folder) newScanError(path string, err error) {
 	f.errorsMut.Lock()
 	f.sl.Warn("Failed to scan", slogutil.FilePath(path), slogutil.Error(err))
-	f.scanErrors = append(f.scanErrors, FileError{
-		Err:  err.Error(),
-		Path: path,
-	})
+	f.scanErrors = append(f.scanErrors, newFileError(path, err.Error(), err))
 	f.errorsMut.Unlock()
 }
 
@@ -1264,6 +1458,9 @@ func (f *folder) updateLocalsFromPulling(fs []protocol.FileInfo) error {
 }
 
 func (f *folder) updateLocals(fs []protocol.FileInfo) error {
+	if err := faultinject.Check(faultinject.DBWrite, f.folderID); err != nil {
+		return err
+	}
 	if err := f.db.Update(f.folderID, protocol.LocalDeviceID, fs); err != nil {
 		return err
 	}
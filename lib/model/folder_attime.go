@@ -0,0 +1,284 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/itererr"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/versioner"
+)
+
+// Where the contents of a file at the point in time are found
+const (
+	AtTimeSourceCurrent     = "current"     // unchanged since, the file in the folder
+	AtTimeSourceVersion     = "version"     // an archived version
+	AtTimeSourceUnavailable = "unavailable" // changed or deleted since, without a retained copy
+)
+
+var (
+	errAtTimeTarget        = errors.New("restore target must be an absolute path outside the folder")
+	errAtTimeEncrypted     = errors.New("cannot restore receive encrypted folders")
+	errAtTimeChangedOnDisk = errors.New("changed on disk since the last scan")
+)
+
+// FolderAtTimeFile is a file as it was at the point in time.
+type FolderAtTimeFile struct {
+	Name    string    `json:"name"`
+	Source  string    `json:"source"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"modTime"`
+	// The archived version, for files from the versioner
+	VersionTime time.Time `json:"versionTime"`
+	// Why the file could not be restored
+	Error string `json:"error,omitempty"`
+}
+
+// FolderAtTimeReport is the state of a folder, or a subtree of it, at a
+// point in time, listed or restored.
+type FolderAtTimeReport struct {
+	Folder string    `json:"folder"`
+	Prefix string    `json:"prefix"`
+	Time   time.Time `json:"time"`
+	// Where the files were restored to, empty for a listing
+	Target        string             `json:"target"`
+	Files         []FolderAtTimeFile `json:"files"`
+	Unavailable   int                `json:"unavailable"`
+	RestoredFiles int                `json:"restoredFiles"`
+	RestoredBytes int64              `json:"restoredBytes"`
+	Failed        int                `json:"failed"`
+}
+
+// FolderAtTime reconstructs the files of the folder below prefix as they
+// were at the given time, from what is retained anyway: the index tells
+// which files are unchanged since then, and which were changed or deleted
+// after, for which the versioner may hold the earlier contents. With a
+// target directory the files are restored there, otherwise only listed.
+// Files are never overwritten in the target.
+func (m *model) FolderAtTime(folder, prefix string, at time.Time, target string) (*FolderAtTimeReport, error) {
+	m.mut.RLock()
+	err := m.checkFolderRunningRLocked(folder)
+	fcfg := m.folderCfgs[folder]
+	ver := m.folderVersioners[folder]
+	m.mut.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	if fcfg.Type == config.FolderTypeReceiveEncrypted {
+		return nil, errAtTimeEncrypted
+	}
+	if target != "" {
+		target = filepath.Clean(target)
+		folderPath := filepath.Clean(fcfg.Filesystem().URI())
+		if !filepath.IsAbs(target) || target == folderPath || fs.IsParent(target, folderPath) {
+			return nil, errAtTimeTarget
+		}
+	}
+	prefix = strings.Trim(filepath.Clean(prefix), string(fs.PathSeparator))
+	if prefix == "." {
+		prefix = ""
+	}
+
+	var versions map[string][]versioner.FileVersion
+	if ver != nil {
+		versions, err = ver.GetVersions()
+		if errors.Is(err, versioner.ErrRestorationNotSupported) {
+			versions, err = nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := &FolderAtTimeReport{
+		Folder: folder,
+		Prefix: prefix,
+		Time:   at,
+		Target: target,
+	}
+	addVersion := func(name string, v versioner.FileVersion) {
+		report.Files = append(report.Files, FolderAtTimeFile{
+			Name:        name,
+			Source:      AtTimeSourceVersion,
+			Size:        v.Size,
+			ModTime:     v.ModTime,
+			VersionTime: v.VersionTime,
+		})
+	}
+
+	seen := make(map[string]struct{})
+	for fi, err := range itererr.Zip(m.sdb.AllLocalFilesWithPrefix(folder, protocol.LocalDeviceID, prefix)) {
+		if err != nil {
+			return nil, err
+		}
+		if fi.IsDirectory() || fi.IsSymlink() || fi.IsIgnored() || fi.IsUnsupported() {
+			continue
+		}
+		seen[fi.Name] = struct{}{}
+		if !lastChanged(fi).After(at) {
+			if !fi.IsDeleted() {
+				report.Files = append(report.Files, FolderAtTimeFile{
+					Name:    fi.Name,
+					Source:  AtTimeSourceCurrent,
+					Size:    fi.Size,
+					ModTime: fi.ModTime(),
+				})
+			}
+			continue
+		}
+		if v, ok := versionAtTime(versions[fi.Name], at); ok {
+			addVersion(fi.Name, v)
+			continue
+		}
+		report.Files = append(report.Files, FolderAtTimeFile{
+			Name:   fi.Name,
+			Source: AtTimeSourceUnavailable,
+		})
+		report.Unavailable++
+	}
+	// Versions of files the index has forgotten about
+	for name, vs := range versions {
+		if _, ok := seen[name]; ok || (prefix != "" && name != prefix && !fs.IsParent(name, prefix)) {
+			continue
+		}
+		if v, ok := versionAtTime(vs, at); ok {
+			addVersion(name, v)
+		}
+	}
+	slices.SortFunc(report.Files, func(a, b FolderAtTimeFile) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	if target != "" {
+		restoreAtTime(fcfg, ver, report)
+		slog.Info("Restored folder to point in time", fcfg.LogAttr(), slog.String("prefix", prefix), slog.Time("time", at), slog.String("target", target), slog.Int("files", report.RestoredFiles), slog.Int("failed", report.Failed))
+	}
+	return report, nil
+}
+
+// lastChanged returns when the file was last changed, as far as the index
+// can tell: the later of its modification time and the counters of its
+// version vector, which are the times of the changes made by each device.
+func lastChanged(fi protocol.FileInfo) time.Time {
+	t := fi.ModTime()
+	for _, c := range fi.Version.Counters {
+		if ct := time.Unix(int64(c.Value), 0); ct.After(t) {
+			t = ct
+		}
+	}
+	return t
+}
+
+// versionAtTime returns the archived version that was the current one at
+// the given time, i.e. the latest modified before and archived after it.
+func versionAtTime(versions []versioner.FileVersion, at time.Time) (versioner.FileVersion, bool) {
+	var best versioner.FileVersion
+	found := false
+	for _, v := range versions {
+		// Untagged versions, as kept by the trash can, carry no archiving
+		// time; they're only known to have been archived at some point.
+		archivedAfter := v.VersionTime.After(at) || v.VersionTime.Equal(v.ModTime)
+		if v.ModTime.After(at) || !archivedAfter {
+			continue
+		}
+		if !found || v.ModTime.After(best.ModTime) {
+			best, found = v, true
+		}
+	}
+	return best, found
+}
+
+// restoreAtTime copies the files of the report to its target directory.
+func restoreAtTime(fcfg config.FolderConfiguration, ver versioner.Versioner, report *FolderAtTimeReport) {
+	folderFs := fcfg.Filesystem()
+	targetFs := fs.NewFilesystem(fs.FilesystemTypeBasic, report.Target)
+	for i := range report.Files {
+		file := &report.Files[i]
+		var open func() (fs.File, error)
+		switch file.Source {
+		case AtTimeSourceCurrent:
+			open = func() (fs.File, error) {
+				fd, err := folderFs.Open(file.Name)
+				if err != nil {
+					return nil, err
+				}
+				info, err := fd.Stat()
+				if err == nil && (info.Size() != file.Size || info.ModTime().Sub(file.ModTime).Abs() > fcfg.ModTimeWindow()) {
+					err = errAtTimeChangedOnDisk
+				}
+				if err != nil {
+					fd.Close()
+					return nil, err
+				}
+				return fd, nil
+			}
+		case AtTimeSourceVersion:
+			open = func() (fs.File, error) {
+				return ver.OpenVersion(file.Name, file.VersionTime)
+			}
+		default:
+			continue
+		}
+
+		if err := restoreAtTimeFile(targetFs, file.Name, file.ModTime, open); err != nil {
+			file.Error = err.Error()
+			report.Failed++
+			continue
+		}
+		report.RestoredFiles++
+		report.RestoredBytes += file.Size
+	}
+}
+
+// restoreAtTimeFile writes the file to the target through a temporary file,
+// keeping the modification time.
+func restoreAtTimeFile(targetFs fs.Filesystem, name string, modTime time.Time, open func() (fs.File, error)) error {
+	if _, err := targetFs.Lstat(name); !fs.IsNotExist(err) {
+		return fmt.Errorf("%s: already exists in target", name)
+	}
+	if dir := filepath.Dir(name); dir != "." {
+		if err := targetFs.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	src, err := open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tempName := fs.TempName(name)
+	dst, err := targetFs.Create(tempName)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = targetFs.Chtimes(tempName, modTime, modTime)
+	}
+	if err == nil {
+		err = targetFs.Rename(tempName, name)
+	}
+	if err != nil {
+		targetFs.Remove(tempName)
+	}
+	return err
+}
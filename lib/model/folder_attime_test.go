@@ -0,0 +1,57 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/versioner"
+)
+
+func TestLastChanged(t *testing.T) {
+	mod := time.Unix(1000, 0)
+	fi := protocol.FileInfo{ModifiedS: mod.Unix()}
+	if got := lastChanged(fi); !got.Equal(mod) {
+		t.Errorf("got %v, expected the modification time %v", got, mod)
+	}
+
+	// A later change, e.g. of permissions, shows in the version vector.
+	fi.Version = protocol.Vector{Counters: []protocol.Counter{{ID: protocol.LocalDeviceID.Short(), Value: 2000}}}
+	if got := lastChanged(fi); !got.Equal(time.Unix(2000, 0)) {
+		t.Errorf("got %v, expected the counter time", got)
+	}
+}
+
+func TestVersionAtTime(t *testing.T) {
+	at := time.Unix(1000, 0)
+	versions := []versioner.FileVersion{
+		// Modified and archived before, not current at the time
+		{ModTime: time.Unix(100, 0), VersionTime: time.Unix(500, 0)},
+		// Modified before and archived after, current at the time
+		{ModTime: time.Unix(600, 0), VersionTime: time.Unix(1500, 0)},
+		// An older one also archived after
+		{ModTime: time.Unix(200, 0), VersionTime: time.Unix(1600, 0)},
+		// Modified after
+		{ModTime: time.Unix(1200, 0), VersionTime: time.Unix(1800, 0)},
+	}
+	v, ok := versionAtTime(versions, at)
+	if !ok || !v.ModTime.Equal(time.Unix(600, 0)) {
+		t.Errorf("got %v (%v), expected the version modified at 600", v, ok)
+	}
+
+	if _, ok := versionAtTime(versions[:1], at); ok {
+		t.Error("a version archived before the time should not be used")
+	}
+
+	// Untagged versions may have been archived at any time.
+	untagged := []versioner.FileVersion{{ModTime: time.Unix(300, 0), VersionTime: time.Unix(300, 0)}}
+	if _, ok := versionAtTime(untagged, at); !ok {
+		t.Error("expected the untagged version to be used")
+	}
+}
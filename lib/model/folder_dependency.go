@@ -0,0 +1,119 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// dependencyCheckInterval is how often a folder waiting for another one to
+// be synced checks again, besides when the other one finishes a pull.
+const dependencyCheckInterval = 30 * time.Second
+
+// folderDependencyWaits records which folder each folder waiting for its
+// dependencies waits for.
+type folderDependencyWaits struct {
+	mut     sync.Mutex
+	waiting map[string]string // folder -> folder it waits for
+}
+
+func newFolderDependencyWaits() *folderDependencyWaits {
+	return &folderDependencyWaits{waiting: make(map[string]string)}
+}
+
+// set records what the folder waits for, "" meaning nothing.
+func (w *folderDependencyWaits) set(folder, dependency string) {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	if dependency == "" {
+		delete(w.waiting, folder)
+		return
+	}
+	w.waiting[folder] = dependency
+}
+
+func (w *folderDependencyWaits) get(folder string) string {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	return w.waiting[folder]
+}
+
+// dependents returns the folders waiting for the given one, sorted.
+func (w *folderDependencyWaits) dependents(dependency string) []string {
+	w.mut.Lock()
+	defer w.mut.Unlock()
+	var folders []string
+	for folder, dep := range w.waiting {
+		if dep == dependency {
+			folders = append(folders, folder)
+		}
+	}
+	slices.Sort(folders)
+	return folders
+}
+
+// unmetDependency returns the first folder the given one depends on that
+// isn't synced as far as required yet, or "" when there is none. A folder
+// that isn't running, for example because it's paused, isn't synced.
+func (m *model) unmetDependency(cfg config.FolderConfiguration) string {
+	for _, dep := range cfg.DependsOn {
+		comp, err := m.folderCompletion(protocol.LocalDeviceID, dep.Folder)
+		if err != nil || comp.CompletionPct < dep.Threshold() {
+			return dep.Folder
+		}
+	}
+	return ""
+}
+
+// dependenciesMet returns whether the folder may pull now. Otherwise it's
+// put in the dependency-waiting state and checked again later.
+func (f *folder) dependenciesMet() bool {
+	cfg, ok := f.model.cfg.Folder(f.ID)
+	if !ok {
+		return true
+	}
+	dep := f.model.unmetDependency(cfg)
+	prev := f.model.dependencyWaits.get(f.ID)
+	f.model.dependencyWaits.set(f.ID, dep)
+	if dep == "" {
+		if prev != "" {
+			f.sl.Info("Folder dependency met, starting to sync", slog.String("dependency", prev))
+		}
+		return true
+	}
+	if dep != prev {
+		f.sl.Info("Folder waits for another folder to be synced first", slog.String("dependency", dep))
+	}
+	f.setState(FolderDependencyWaiting)
+	f.pullFailTimer.Reset(dependencyCheckInterval)
+	return false
+}
+
+// scheduleDependents schedules a pull of the folders waiting for the given
+// one, as it may be synced far enough for them now.
+func (m *model) scheduleDependents(folder string) {
+	for _, dependent := range m.dependencyWaits.dependents(folder) {
+		m.mut.RLock()
+		runner, ok := m.folderRunners.Get(dependent)
+		m.mut.RUnlock()
+		if ok {
+			runner.SchedulePull()
+		}
+	}
+}
+
+// FolderDependencyWait returns the folder the given one waits for to be
+// synced before pulling, or "" when it isn't waiting.
+func (m *model) FolderDependencyWait(folder string) string {
+	return m.dependencyWaits.get(folder)
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+func TestFolderDependencyWaits(t *testing.T) {
+	w := newFolderDependencyWaits()
+	w.set("data", "apps")
+	w.set("logs", "apps")
+	w.set("other", "data")
+
+	if got := w.dependents("apps"); !slices.Equal(got, []string{"data", "logs"}) {
+		t.Errorf("unexpected dependents %v", got)
+	}
+	w.set("data", "")
+	if got := w.get("data"); got != "" {
+		t.Errorf("expected data not to wait, got %q", got)
+	}
+	if got := w.dependents("apps"); !slices.Equal(got, []string{"logs"}) {
+		t.Errorf("unexpected dependents %v", got)
+	}
+}
+
+func TestUnmetDependency(t *testing.T) {
+	m, _, fcfg, wcfgCancel := setupModelWithConnection(t)
+	defer wcfgCancel()
+	defer cleanupModel(m)
+
+	// The default folder is empty, so completely synced.
+	cfg := config.FolderConfiguration{ID: "dependent", DependsOn: []config.FolderDependency{{Folder: fcfg.ID}}}
+	if dep := m.unmetDependency(cfg); dep != "" {
+		t.Errorf("expected no unmet dependency, got %q", dep)
+	}
+
+	// A folder that isn't running isn't synced.
+	cfg.DependsOn = append(cfg.DependsOn, config.FolderDependency{Folder: "missing"})
+	if dep := m.unmetDependency(cfg); dep != "missing" {
+		t.Errorf("expected to wait for the missing folder, got %q", dep)
+	}
+}
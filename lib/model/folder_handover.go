@@ -0,0 +1,860 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/itererr"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+const (
+	folderHandoverKindPrefix = "folderhandover/"
+	// Sent to the device taking over, which answers with the next one
+	// once it has.
+	folderHandoverKindTakeOver  = folderHandoverKindPrefix + "takeover"
+	folderHandoverKindTakenOver = folderHandoverKindPrefix + "takenover"
+	// Sent to the other devices sharing the folder.
+	folderHandoverKindRepoint = folderHandoverKindPrefix + "repoint"
+
+	// Takeover and repoint messages waiting for local approval are kept by
+	// folder and the device they came from.
+	folderHandoverPendingPrefix = "handoverpending/"
+
+	// The state is re-evaluated at least this often, as the completion
+	// of remote devices isn't always accompanied by an event.
+	folderHandoverRecheckInterval = 10 * time.Second
+)
+
+// The steps of a folder handover, in order.
+const (
+	HandoverStepSync     = "sync"
+	HandoverStepTakeOver = "takeover"
+	HandoverStepRepoint  = "repoint"
+	HandoverStepFinish   = "finish"
+	HandoverStepDone     = "done"
+)
+
+// What the device handing a folder over does with it when done.
+const (
+	HandoverAfterKeep        = "keep"
+	HandoverAfterReceiveOnly = "receiveonly"
+	HandoverAfterRemove      = "remove"
+)
+
+var (
+	ErrHandoverInProgress = errors.New("the folder is already being handed over")
+	ErrHandoverNotFound   = errors.New("no such folder handover")
+	ErrHandoverTarget     = errors.New("the folder can only be handed over to another device it is shared with, without untrusted encryption")
+	ErrHandoverAfter      = errors.New("unknown action after handing over")
+	ErrHandoverNotPending = errors.New("no such pending folder handover")
+)
+
+// FolderHandover is the state of moving the responsibility for a folder from
+// this device to another one.
+type FolderHandover struct {
+	Folder   string              `json:"folder"`
+	From     protocol.DeviceID   `json:"from"`
+	To       protocol.DeviceID   `json:"to"`
+	After    string              `json:"after"`
+	Step     string              `json:"step"`
+	Progress float64             `json:"progress"` // completion of the device taking over, in percent
+	Notified []protocol.DeviceID `json:"notified"` // devices sent the repoint message
+	Error    string              `json:"error,omitempty"`
+	Started  time.Time           `json:"started"`
+	Updated  time.Time           `json:"updated"`
+	Finished time.Time           `json:"finished,omitempty"`
+}
+
+func (h FolderHandover) active() bool {
+	return h.Step != HandoverStepDone && h.Error == ""
+}
+
+// PendingFolderHandover is a takeover or repoint message from an introducer
+// that waits for local approval, as it changes who the folder is shared
+// with and how.
+type PendingFolderHandover struct {
+	Folder        string              `json:"folder"`
+	Kind          string              `json:"kind"` // HandoverStepTakeOver or HandoverStepRepoint
+	From          protocol.DeviceID   `json:"from"`
+	To            protocol.DeviceID   `json:"to"`
+	ToName        string              `json:"toName,omitempty"`
+	After         string              `json:"after"`
+	Type          config.FolderType   `json:"type"`
+	Devices       []protocol.DeviceID `json:"devices,omitempty"`
+	OwnedPrefixes []string            `json:"ownedPrefixes,omitempty"`
+	Received      time.Time           `json:"received"`
+}
+
+func (p PendingFolderHandover) message() folderHandoverMessage {
+	return folderHandoverMessage{
+		From:          p.From,
+		To:            p.To,
+		ToName:        p.ToName,
+		After:         p.After,
+		Type:          p.Type,
+		Devices:       p.Devices,
+		OwnedPrefixes: p.OwnedPrefixes,
+	}
+}
+
+// folderHandoverMessage is the payload of the folder handover control
+// messages.
+type folderHandoverMessage struct {
+	From          protocol.DeviceID   `json:"from"`
+	To            protocol.DeviceID   `json:"to"`
+	ToName        string              `json:"toName,omitempty"`
+	After         string              `json:"after"`
+	Type          config.FolderType   `json:"type"`
+	Devices       []protocol.DeviceID `json:"devices,omitempty"`
+	OwnedPrefixes []string            `json:"ownedPrefixes,omitempty"`
+}
+
+// folderHandovers runs the handovers of folders to other devices as a
+// sequence of steps, keeping their state in the database so that they
+// continue after a restart:
+//
+//   - sync: wait for the device taking over to have everything we have
+//   - takeover: tell it to take over, adopting the devices the folder is
+//     shared with, our ownership prefixes and our introductions, and wait
+//     for it to confirm
+//   - repoint: tell the other devices sharing the folder to share it with
+//     the device taking over, which also takes over our roles there
+//   - finish: keep the folder, make it receive only or remove it
+//
+// All messages go through the device queue, so devices that are offline at
+// the time get them when they next connect. On the receiving side, takeover
+// and repoint messages are only taken from introducers, and wait for local
+// approval; the confirmation of a takeover only counts from the device we
+// are handing the folder over to.
+type folderHandovers struct {
+	kv   db.KV
+	mut  sync.Mutex
+	wake chan struct{}
+}
+
+func newFolderHandovers(kv db.KV) *folderHandovers {
+	return &folderHandovers{
+		kv:   kv,
+		wake: make(chan struct{}, 1),
+	}
+}
+
+func folderHandoverKey(folder string) string {
+	return folderHandoverKindPrefix + folder
+}
+
+// The confirmation from the device taking over is kept on its own, as
+// it's received on the connection and mustn't wait for a handover that is
+// changing the configuration.
+func folderHandoverConfirmedKey(folder string) string {
+	return "folderhandoverconfirmed/" + folder
+}
+
+func pendingFolderHandoverKey(folder string, from protocol.DeviceID) string {
+	return folderHandoverPendingPrefix + folder + "/" + from.String()
+}
+
+// pendingLocked returns the pending handovers of the folder, or all of them
+// for the empty folder ID.
+func (s *folderHandovers) pendingLocked(folder string) ([]PendingFolderHandover, error) {
+	prefix := folderHandoverPendingPrefix
+	if folder != "" {
+		prefix += folder + "/"
+	}
+	var pending []PendingFolderHandover
+	it, errFn := s.kv.PrefixKV(prefix)
+	for kv, err := range itererr.Zip(it, errFn) {
+		if err != nil {
+			return nil, err
+		}
+		var p PendingFolderHandover
+		if err := json.Unmarshal(kv.Value, &p); err != nil {
+			l.Debugln("unmarshalling pending folder handover", kv.Key, err)
+			continue
+		}
+		if folder != "" && p.Folder != folder {
+			// A folder ID that is a prefix of another one
+			continue
+		}
+		pending = append(pending, p)
+	}
+	slices.SortFunc(pending, func(a, b PendingFolderHandover) int {
+		return a.Received.Compare(b.Received)
+	})
+	return pending, nil
+}
+
+func (s *folderHandovers) trigger() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *folderHandovers) getLocked(folder string) (FolderHandover, bool, error) {
+	bs, err := s.kv.GetKV(folderHandoverKey(folder))
+	if errors.Is(err, sql.ErrNoRows) {
+		return FolderHandover{}, false, nil
+	} else if err != nil {
+		return FolderHandover{}, false, err
+	}
+	var h FolderHandover
+	if err := json.Unmarshal(bs, &h); err != nil {
+		return FolderHandover{}, false, err
+	}
+	return h, true, nil
+}
+
+func (s *folderHandovers) listLocked() ([]FolderHandover, error) {
+	var handovers []FolderHandover
+	it, errFn := s.kv.PrefixKV(folderHandoverKindPrefix)
+	for kv, err := range itererr.Zip(it, errFn) {
+		if err != nil {
+			return nil, err
+		}
+		var h FolderHandover
+		if err := json.Unmarshal(kv.Value, &h); err != nil {
+			l.Debugln("unmarshalling folder handover", kv.Key, err)
+			continue
+		}
+		handovers = append(handovers, h)
+	}
+	slices.SortFunc(handovers, func(a, b FolderHandover) int {
+		return cmp.Compare(a.Folder, b.Folder)
+	})
+	return handovers, nil
+}
+
+func (s *folderHandovers) putLocked(h FolderHandover) error {
+	bs, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	return s.kv.PutKV(folderHandoverKey(h.Folder), bs)
+}
+
+// StartFolderHandover starts handing the folder over to the device, which
+// must share it already, doing what after says with the folder once done.
+func (m *model) StartFolderHandover(folder string, to protocol.DeviceID, after string) (FolderHandover, error) {
+	switch after {
+	case "":
+		after = HandoverAfterKeep
+	case HandoverAfterKeep, HandoverAfterReceiveOnly, HandoverAfterRemove:
+	default:
+		return FolderHandover{}, ErrHandoverAfter
+	}
+	fcfg, ok := m.cfg.Folder(folder)
+	if !ok {
+		return FolderHandover{}, ErrFolderMissing
+	}
+	if fcfg.Type == config.FolderTypeReceiveEncrypted {
+		return FolderHandover{}, ErrHandoverTarget
+	}
+	if dev, ok := fcfg.Device(to); !ok || to == m.id || dev.EncryptionPassword != "" {
+		return FolderHandover{}, ErrHandoverTarget
+	}
+
+	s := m.folderHandovers
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if h, ok, err := s.getLocked(folder); err != nil {
+		return FolderHandover{}, err
+	} else if ok && h.active() {
+		return FolderHandover{}, ErrHandoverInProgress
+	}
+
+	now := time.Now()
+	h := FolderHandover{
+		Folder:   folder,
+		From:     m.id,
+		To:       to,
+		After:    after,
+		Step:     HandoverStepSync,
+		Notified: []protocol.DeviceID{},
+		Started:  now,
+		Updated:  now,
+	}
+	if err := s.kv.DeleteKV(folderHandoverConfirmedKey(folder)); err != nil {
+		return FolderHandover{}, err
+	}
+	if err := s.putLocked(h); err != nil {
+		return FolderHandover{}, err
+	}
+	slog.Info("Starting folder handover", fcfg.LogAttr(), slog.String("to", to.String()), slog.String("after", after))
+	m.evLogger.Log(events.FolderHandoverProgress, h)
+	s.trigger()
+	return h, nil
+}
+
+// FolderHandovers returns the folder handovers in progress, and the ones
+// finished or failed that were not cleared yet.
+func (m *model) FolderHandovers() ([]FolderHandover, error) {
+	s := m.folderHandovers
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	handovers, err := s.listLocked()
+	if err != nil {
+		return nil, err
+	}
+	if handovers == nil {
+		handovers = []FolderHandover{}
+	}
+	return handovers, nil
+}
+
+// ClearFolderHandover stops the handover of the folder, if it's in
+// progress, and forgets about it. What the devices involved were told
+// already stays done.
+func (m *model) ClearFolderHandover(folder string) error {
+	s := m.folderHandovers
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	h, ok, err := s.getLocked(folder)
+	if err != nil {
+		return err
+	} else if !ok {
+		return ErrHandoverNotFound
+	}
+	if h.active() {
+		slog.Info("Cancelled folder handover", slog.String("folder", folder), slog.String("step", h.Step))
+	}
+	if err := s.kv.DeleteKV(folderHandoverConfirmedKey(folder)); err != nil {
+		return err
+	}
+	return s.kv.DeleteKV(folderHandoverKey(folder))
+}
+
+// serveFolderHandovers advances the handovers in progress as far as they
+// go, whenever something relevant happens.
+func (m *model) serveFolderHandovers(ctx context.Context) error {
+	select {
+	case <-m.started:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	sub := m.evLogger.Subscribe(events.FolderCompletion | events.RemoteIndexUpdated | events.StateChanged | events.DeviceConnected)
+	defer sub.Unsubscribe()
+	ticker := time.NewTicker(folderHandoverRecheckInterval)
+	defer ticker.Stop()
+
+	for {
+		m.advanceFolderHandovers()
+
+		select {
+		case <-m.folderHandovers.wake:
+		case <-sub.C():
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (m *model) advanceFolderHandovers() {
+	s := m.folderHandovers
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	handovers, err := s.listLocked()
+	if err != nil {
+		slog.Warn("Failed to load folder handovers", slogutil.Error(err))
+		return
+	}
+	for _, h := range handovers {
+		if !h.active() {
+			continue
+		}
+		prev := h
+		if err := m.advanceFolderHandoverLocked(&h); err != nil {
+			slog.Warn("Folder handover failed", slog.String("folder", h.Folder), slog.String("step", h.Step), slogutil.Error(err))
+			h.Error = err.Error()
+		}
+		if h.Step == prev.Step && h.Progress == prev.Progress && h.Error == prev.Error {
+			continue
+		}
+		h.Updated = time.Now()
+		if err := s.putLocked(h); err != nil {
+			slog.Warn("Failed to save folder handover", slog.String("folder", h.Folder), slogutil.Error(err))
+			continue
+		}
+		if h.Step != prev.Step {
+			l.Debugln("Folder handover", h.Folder, "to", h.To.Short(), "step", h.Step)
+		}
+		m.evLogger.Log(events.FolderHandoverProgress, h)
+	}
+}
+
+// advanceFolderHandoverLocked takes the handover through the steps that can
+// be done now. An error fails it.
+func (m *model) advanceFolderHandoverLocked(h *FolderHandover) error {
+	for {
+		fcfg, ok := m.cfg.Folder(h.Folder)
+		if !ok {
+			return ErrFolderMissing
+		}
+		if !fcfg.SharedWith(h.To) {
+			return ErrHandoverTarget
+		}
+
+		switch h.Step {
+		case HandoverStepSync:
+			synced, pct := m.folderHandoverSynced(h.Folder, h.To)
+			if pct >= 0 {
+				// Whole percents, not to send an event for every index
+				// update
+				h.Progress = float64(int(pct))
+			}
+			if !synced {
+				return nil
+			}
+			h.Progress = 100
+			if err := m.queueFolderHandover(h.To, folderHandoverKindTakeOver, fcfg, *h); err != nil {
+				return err
+			}
+			h.Step = HandoverStepTakeOver
+
+		case HandoverStepTakeOver:
+			bs, err := m.folderHandovers.kv.GetKV(folderHandoverConfirmedKey(h.Folder))
+			if errors.Is(err, sql.ErrNoRows) {
+				// Not confirmed yet
+				return nil
+			} else if err != nil {
+				return err
+			}
+			if string(bs) != h.To.String() {
+				return nil
+			}
+			if err := m.folderHandovers.kv.DeleteKV(folderHandoverConfirmedKey(h.Folder)); err != nil {
+				return err
+			}
+			h.Step = HandoverStepRepoint
+
+		case HandoverStepRepoint:
+			for _, dev := range fcfg.DeviceIDs() {
+				if dev == m.id || dev == h.To || slices.Contains(h.Notified, dev) {
+					continue
+				}
+				if err := m.queueFolderHandover(dev, folderHandoverKindRepoint, fcfg, *h); err != nil {
+					return err
+				}
+				h.Notified = append(h.Notified, dev)
+			}
+			h.Step = HandoverStepFinish
+
+		case HandoverStepFinish:
+			if err := m.finishFolderHandover(*h); err != nil {
+				return err
+			}
+			h.Step = HandoverStepDone
+			h.Finished = time.Now()
+			slog.Info("Handed folder over", fcfg.LogAttr(), slog.String("to", h.To.String()), slog.String("after", h.After))
+			return nil
+
+		default:
+			return fmt.Errorf("unknown step %q", h.Step)
+		}
+	}
+}
+
+// folderHandoverSynced returns whether the device has everything we have
+// in the folder, and how much of it it has, in percent, or -1 if unknown.
+func (m *model) folderHandoverSynced(folder string, device protocol.DeviceID) (bool, float64) {
+	if !m.ConnectedTo(device) {
+		return false, -1
+	}
+	comp, err := m.folderCompletion(device, folder)
+	if err != nil {
+		return false, -1
+	}
+	if comp.RemoteState != remoteFolderValid {
+		return false, comp.CompletionPct
+	}
+	if comp.NeedItems > 0 || comp.NeedDeletes > 0 || comp.NeedBytes > 0 {
+		return false, comp.CompletionPct
+	}
+	// Nothing may be waiting to be scanned or pulled here either
+	m.mut.RLock()
+	runner, ok := m.folderRunners.Get(folder)
+	m.mut.RUnlock()
+	if !ok {
+		return false, comp.CompletionPct
+	}
+	if state, _, err := runner.getState(); err != nil || state != FolderIdle {
+		return false, comp.CompletionPct
+	}
+	if need, err := m.sdb.CountNeed(folder, protocol.LocalDeviceID); err != nil || need.TotalItems() > 0 {
+		return false, comp.CompletionPct
+	}
+	return true, comp.CompletionPct
+}
+
+func (m *model) queueFolderHandover(device protocol.DeviceID, kind string, fcfg config.FolderConfiguration, h FolderHandover) error {
+	msg := folderHandoverMessage{
+		From:  m.id,
+		To:    h.To,
+		After: h.After,
+		Type:  fcfg.Type,
+	}
+	if dev, ok := m.cfg.Device(h.To); ok {
+		msg.ToName = dev.Name
+	}
+	for _, dev := range fcfg.Devices {
+		switch dev.DeviceID {
+		case m.id:
+			msg.OwnedPrefixes = dev.OwnedPrefixes
+		case h.To:
+		default:
+			msg.Devices = append(msg.Devices, dev.DeviceID)
+		}
+	}
+	bs, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	return m.QueueDeviceMessage(device, kind, fcfg.ID, bs)
+}
+
+// finishFolderHandover moves our ownership prefixes to the device that
+// took over, and keeps, converts or removes the folder.
+func (m *model) finishFolderHandover(h FolderHandover) error {
+	waiter, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		fcfg, idx, ok := cfg.Folder(h.Folder)
+		if !ok {
+			return
+		}
+		switch h.After {
+		case HandoverAfterRemove:
+			cfg.Folders = slices.Delete(cfg.Folders, idx, idx+1)
+			return
+		case HandoverAfterReceiveOnly:
+			fcfg.Type = config.FolderTypeReceiveOnly
+		}
+		var prefixes []string
+		for i := range fcfg.Devices {
+			if fcfg.Devices[i].DeviceID == m.id {
+				prefixes = fcfg.Devices[i].OwnedPrefixes
+				fcfg.Devices[i].OwnedPrefixes = nil
+			}
+		}
+		for i := range fcfg.Devices {
+			if fcfg.Devices[i].DeviceID == h.To {
+				fcfg.Devices[i].OwnedPrefixes = mergePrefixes(fcfg.Devices[i].OwnedPrefixes, prefixes)
+			}
+		}
+		cfg.SetFolder(fcfg)
+	})
+	if err != nil {
+		return err
+	}
+	waiter.Wait()
+	return nil
+}
+
+// handleFolderHandover handles a folder handover message from the device.
+func (m *model) handleFolderHandover(device protocol.DeviceID, msg *protocol.ControlMessage) error {
+	var hm folderHandoverMessage
+	if err := json.Unmarshal(msg.Payload, &hm); err != nil {
+		return fmt.Errorf("folder handover: %w", err)
+	}
+	if hm.From != device && msg.Kind != folderHandoverKindTakenOver {
+		l.Debugln("Ignoring folder handover on behalf of another device", device.Short(), msg.Key, hm.From.Short())
+		return nil
+	}
+	fcfg, ok := m.cfg.Folder(msg.Key)
+	if !ok || !fcfg.SharedWith(device) {
+		l.Debugln("Ignoring folder handover for a folder not shared with the device", device.Short(), msg.Key)
+		return nil
+	}
+
+	switch msg.Kind {
+	case folderHandoverKindTakeOver:
+		if hm.To != m.id {
+			return nil
+		}
+		return m.holdFolderHandover(device, fcfg, HandoverStepTakeOver, hm)
+
+	case folderHandoverKindTakenOver:
+		s := m.folderHandovers
+		s.mut.Lock()
+		defer s.mut.Unlock()
+		if h, ok, err := s.getLocked(fcfg.ID); err != nil {
+			return err
+		} else if !ok || !h.active() || h.To != device {
+			l.Debugln("Ignoring folder handover confirmation from a device we are not handing over to", device.Short(), fcfg.ID)
+			return nil
+		}
+		if err := s.kv.PutKV(folderHandoverConfirmedKey(fcfg.ID), []byte(device.String())); err != nil {
+			return err
+		}
+		s.trigger()
+		return nil
+
+	case folderHandoverKindRepoint:
+		if hm.To == m.id {
+			return nil
+		}
+		return m.holdFolderHandover(device, fcfg, HandoverStepRepoint, hm)
+
+	default:
+		l.Debugln("Ignoring unknown folder handover message", device.Short(), msg.Kind)
+		return nil
+	}
+}
+
+// holdFolderHandover keeps the takeover or repoint message from the device
+// until it's approved or rejected locally, if the device is one of our
+// introducers. Anything else is dropped.
+func (m *model) holdFolderHandover(from protocol.DeviceID, fcfg config.FolderConfiguration, kind string, hm folderHandoverMessage) error {
+	if dev, ok := m.cfg.Device(from); !ok || !dev.Introducer {
+		slog.Warn("Ignoring folder handover from a device that is not an introducer", fcfg.LogAttr(), slog.String("from", from.String()))
+		return nil
+	}
+	p := PendingFolderHandover{
+		Folder:        fcfg.ID,
+		Kind:          kind,
+		From:          from,
+		To:            hm.To,
+		ToName:        hm.ToName,
+		After:         hm.After,
+		Type:          hm.Type,
+		Devices:       hm.Devices,
+		OwnedPrefixes: hm.OwnedPrefixes,
+		Received:      time.Now(),
+	}
+	bs, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+	s := m.folderHandovers
+	s.mut.Lock()
+	err = s.kv.PutKV(pendingFolderHandoverKey(fcfg.ID, from), bs)
+	s.mut.Unlock()
+	if err != nil {
+		return err
+	}
+	slog.Info("Folder handover awaits approval", fcfg.LogAttr(), slog.String("from", from.String()), slog.String("to", hm.To.String()), slog.String("kind", kind))
+	m.evLogger.Log(events.FolderHandoverProgress, map[string]interface{}{
+		"folder":  fcfg.ID,
+		"pending": p,
+	})
+	return nil
+}
+
+// PendingFolderHandovers returns the takeover and repoint messages received
+// from introducers that wait for local approval.
+func (m *model) PendingFolderHandovers() ([]PendingFolderHandover, error) {
+	s := m.folderHandovers
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	pending, err := s.pendingLocked("")
+	if err != nil {
+		return nil, err
+	}
+	if pending == nil {
+		pending = []PendingFolderHandover{}
+	}
+	return pending, nil
+}
+
+// ApproveFolderHandover applies the pending handover of the folder from the
+// device. An approved takeover is confirmed to the device.
+func (m *model) ApproveFolderHandover(folder string, from protocol.DeviceID) error {
+	p, err := m.takePendingFolderHandover(folder, from)
+	if err != nil {
+		return err
+	}
+	fcfg, ok := m.cfg.Folder(folder)
+	if !ok {
+		return ErrFolderMissing
+	}
+
+	switch p.Kind {
+	case HandoverStepTakeOver:
+		if err := m.takeOverFolder(from, folder, p.message()); err != nil {
+			return err
+		}
+		slog.Info("Took over folder", fcfg.LogAttr(), slog.String("from", from.String()))
+		bs, err := json.Marshal(folderHandoverMessage{From: from, To: m.id})
+		if err != nil {
+			return err
+		}
+		return m.QueueDeviceMessage(from, folderHandoverKindTakenOver, folder, bs)
+
+	case HandoverStepRepoint:
+		if err := m.repointFolder(from, folder, p.message()); err != nil {
+			return err
+		}
+		slog.Info("Folder handed over between devices", fcfg.LogAttr(), slog.String("from", from.String()), slog.String("to", p.To.String()))
+		return nil
+
+	default:
+		return fmt.Errorf("unknown pending handover %q", p.Kind)
+	}
+}
+
+// RejectFolderHandover drops the pending handover of the folder from the
+// device.
+func (m *model) RejectFolderHandover(folder string, from protocol.DeviceID) error {
+	if _, err := m.takePendingFolderHandover(folder, from); err != nil {
+		return err
+	}
+	slog.Info("Rejected folder handover", slog.String("folder", folder), slog.String("from", from.String()))
+	return nil
+}
+
+// takePendingFolderHandover removes and returns the pending handover of
+// the folder from the device.
+func (m *model) takePendingFolderHandover(folder string, from protocol.DeviceID) (PendingFolderHandover, error) {
+	s := m.folderHandovers
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	key := pendingFolderHandoverKey(folder, from)
+	bs, err := s.kv.GetKV(key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return PendingFolderHandover{}, ErrHandoverNotPending
+	} else if err != nil {
+		return PendingFolderHandover{}, err
+	}
+	var p PendingFolderHandover
+	if err := json.Unmarshal(bs, &p); err != nil {
+		return PendingFolderHandover{}, err
+	}
+	if err := s.kv.DeleteKV(key); err != nil {
+		return PendingFolderHandover{}, err
+	}
+	return p, nil
+}
+
+// takeOverFolder makes us responsible for the folder in place of the
+// device: it's shared with all the known devices it shared it with, we own
+// what it owned, the devices it introduced stay and the folder is no
+// longer receive only.
+func (m *model) takeOverFolder(from protocol.DeviceID, folder string, hm folderHandoverMessage) error {
+	_, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		fcfg, _, ok := cfg.Folder(folder)
+		if !ok {
+			return
+		}
+		for _, dev := range hm.Devices {
+			if _, _, ok := cfg.Device(dev); ok && dev != m.id && !fcfg.SharedWith(dev) {
+				fcfg.Devices = append(fcfg.Devices, config.FolderDeviceConfiguration{DeviceID: dev})
+			}
+		}
+		for i := range fcfg.Devices {
+			switch fcfg.Devices[i].DeviceID {
+			case m.id:
+				fcfg.Devices[i].OwnedPrefixes = mergePrefixes(fcfg.Devices[i].OwnedPrefixes, hm.OwnedPrefixes)
+			case from:
+				fcfg.Devices[i].OwnedPrefixes = nil
+			}
+			if fcfg.Devices[i].IntroducedBy == from {
+				fcfg.Devices[i].IntroducedBy = protocol.EmptyDeviceID
+			}
+		}
+		if fcfg.Type == config.FolderTypeReceiveOnly && hm.Type != config.FolderTypeReceiveEncrypted {
+			fcfg.Type = hm.Type
+		}
+		cfg.SetFolder(fcfg)
+		clearIntroducedBy(cfg, from, fcfg.DeviceIDs())
+	})
+	return err
+}
+
+// repointFolder makes the folder shared with the device taking over from
+// the given one, which also takes over its ownership prefixes and its role
+// as an introducer.
+func (m *model) repointFolder(from protocol.DeviceID, folder string, hm folderHandoverMessage) error {
+	_, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		fcfg, _, ok := cfg.Folder(folder)
+		if !ok {
+			return
+		}
+		fromCfg, _, _ := cfg.Device(from)
+		toCfg, _, known := cfg.Device(hm.To)
+		if !known {
+			if !fromCfg.Introducer {
+				l.Debugln("Not adding unknown device taking over", folder, "from", from.Short(), hm.To.Short())
+				return
+			}
+			toCfg = cfg.Defaults.Device.Copy()
+			toCfg.DeviceID = hm.To
+			toCfg.Name = hm.ToName
+		}
+		toCfg.IntroducedBy = protocol.EmptyDeviceID
+		if fromCfg.Introducer {
+			toCfg.Introducer = true
+		}
+		cfg.SetDevice(toCfg)
+
+		if !fcfg.SharedWith(hm.To) {
+			fcfg.Devices = append(fcfg.Devices, config.FolderDeviceConfiguration{DeviceID: hm.To})
+		}
+		var prefixes []string
+		for i := range fcfg.Devices {
+			if fcfg.Devices[i].DeviceID == from {
+				prefixes = fcfg.Devices[i].OwnedPrefixes
+				fcfg.Devices[i].OwnedPrefixes = nil
+			}
+		}
+		for i := range fcfg.Devices {
+			dev := &fcfg.Devices[i]
+			if dev.DeviceID == hm.To {
+				dev.OwnedPrefixes = mergePrefixes(dev.OwnedPrefixes, prefixes)
+				dev.IntroducedBy = protocol.EmptyDeviceID
+			} else if dev.IntroducedBy == from {
+				if toCfg.Introducer {
+					dev.IntroducedBy = hm.To
+				} else {
+					dev.IntroducedBy = protocol.EmptyDeviceID
+				}
+			}
+		}
+		if hm.After == HandoverAfterRemove {
+			fcfg.Devices = slices.DeleteFunc(fcfg.Devices, func(dev config.FolderDeviceConfiguration) bool {
+				return dev.DeviceID == from
+			})
+		}
+		cfg.SetFolder(fcfg)
+	})
+	return err
+}
+
+// clearIntroducedBy makes the devices introduced by the given one
+// permanent, so that they stay when it stops sharing folders with them.
+func clearIntroducedBy(cfg *config.Configuration, introducer protocol.DeviceID, devices []protocol.DeviceID) {
+	for _, id := range devices {
+		if dev, _, ok := cfg.Device(id); ok && dev.IntroducedBy == introducer {
+			dev.IntroducedBy = protocol.EmptyDeviceID
+			cfg.SetDevice(dev)
+		}
+	}
+}
+
+func mergePrefixes(prefixes, add []string) []string {
+	for _, prefix := range add {
+		if !slices.Contains(prefixes, prefix) {
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
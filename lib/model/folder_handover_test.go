@@ -0,0 +1,277 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func setupHandoverModel(t *testing.T, fcfg config.FolderConfiguration, devices ...config.DeviceConfiguration) *testModel {
+	t.Helper()
+	tcfg := defaultAutoAcceptCfg.Copy()
+	for _, dev := range devices {
+		tcfg.SetDevice(dev)
+	}
+	tcfg.SetFolder(fcfg)
+	w, cancel := newConfigWrapper(tcfg)
+	t.Cleanup(cancel)
+	m := setupModel(t, w)
+	t.Cleanup(func() { cleanupModel(m) })
+	return m
+}
+
+func receiveHandover(t *testing.T, m *testModel, from protocol.DeviceID, kind, folder string, hm folderHandoverMessage) {
+	t.Helper()
+	bs, err := json.Marshal(hm)
+	must(t, err)
+	must(t, m.handleFolderHandover(from, &protocol.ControlMessage{
+		Kind:    kind,
+		Key:     folder,
+		Payload: bs,
+		Queued:  time.Now(),
+	}))
+}
+
+func queuedKinds(t *testing.T, m *testModel, device protocol.DeviceID) []string {
+	t.Helper()
+	msgs, err := m.QueuedDeviceMessages(device)
+	must(t, err)
+	var kinds []string
+	for _, msg := range msgs {
+		kinds = append(kinds, msg.Kind+" "+msg.Key)
+	}
+	return kinds
+}
+
+func TestFolderHandover(t *testing.T) {
+	fcfg := newFolderConfig()
+	for i := range fcfg.Devices {
+		if fcfg.Devices[i].DeviceID == myID {
+			fcfg.Devices[i].OwnedPrefixes = []string{"docs"}
+		}
+	}
+	fcfg.Devices = append(fcfg.Devices, config.FolderDeviceConfiguration{DeviceID: device2})
+	m := setupHandoverModel(t, fcfg)
+
+	if _, err := m.StartFolderHandover(fcfg.ID, device1, "archive"); !errors.Is(err, ErrHandoverAfter) {
+		t.Errorf("expected unknown action, got %v", err)
+	}
+	if _, err := m.StartFolderHandover(fcfg.ID, myID, ""); !errors.Is(err, ErrHandoverTarget) {
+		t.Errorf("expected invalid target, got %v", err)
+	}
+	if _, err := m.StartFolderHandover("nonexistent", device1, ""); !errors.Is(err, ErrFolderMissing) {
+		t.Errorf("expected missing folder, got %v", err)
+	}
+	h, err := m.StartFolderHandover(fcfg.ID, device1, HandoverAfterReceiveOnly)
+	must(t, err)
+	if h.Step != HandoverStepSync {
+		t.Errorf("expected to start syncing, got %v", h.Step)
+	}
+	if _, err := m.StartFolderHandover(fcfg.ID, device2, ""); !errors.Is(err, ErrHandoverInProgress) {
+		t.Errorf("expected handover in progress, got %v", err)
+	}
+
+	// Device1 isn't connected, so it can't be synced; pretend it was
+	// and was told to take over.
+	m.advanceFolderHandovers()
+	if hs, _ := m.FolderHandovers(); len(hs) != 1 || hs[0].Step != HandoverStepSync {
+		t.Fatalf("expected to wait for syncing, got %+v", hs)
+	}
+	s := m.folderHandovers
+	s.mut.Lock()
+	h.Step = HandoverStepTakeOver
+	must(t, s.putLocked(h))
+	s.mut.Unlock()
+
+	// A confirmation from another device doesn't count.
+	receiveHandover(t, m, device2, folderHandoverKindTakenOver, fcfg.ID, folderHandoverMessage{From: myID, To: device2})
+	m.advanceFolderHandovers()
+	if hs, _ := m.FolderHandovers(); hs[0].Step != HandoverStepTakeOver {
+		t.Fatalf("advanced without confirmation: %+v", hs[0])
+	}
+
+	receiveHandover(t, m, device1, folderHandoverKindTakenOver, fcfg.ID, folderHandoverMessage{From: myID, To: device1})
+	m.advanceFolderHandovers()
+	hs, err := m.FolderHandovers()
+	must(t, err)
+	if len(hs) != 1 || hs[0].Step != HandoverStepDone || hs[0].Finished.IsZero() {
+		t.Fatalf("expected handover done, got %+v", hs)
+	}
+	if !slices.Equal(hs[0].Notified, []protocol.DeviceID{device2}) {
+		t.Errorf("unexpected notified devices %v", hs[0].Notified)
+	}
+	if kinds := queuedKinds(t, m, device2); !slices.Contains(kinds, folderHandoverKindRepoint+" "+fcfg.ID) {
+		t.Errorf("device2 not repointed: %v", kinds)
+	}
+
+	newCfg, _ := m.cfg.Folder(fcfg.ID)
+	if newCfg.Type != config.FolderTypeReceiveOnly {
+		t.Errorf("expected receive only, got %v", newCfg.Type)
+	}
+	if dev, _ := newCfg.Device(device1); !slices.Equal(dev.OwnedPrefixes, []string{"docs"}) {
+		t.Errorf("prefixes not moved: %v", dev.OwnedPrefixes)
+	}
+	if dev, _ := newCfg.Device(myID); len(dev.OwnedPrefixes) != 0 {
+		t.Errorf("prefixes kept: %v", dev.OwnedPrefixes)
+	}
+
+	must(t, m.ClearFolderHandover(fcfg.ID))
+	if hs, _ := m.FolderHandovers(); len(hs) != 0 {
+		t.Errorf("handover not cleared: %+v", hs)
+	}
+	if err := m.ClearFolderHandover(fcfg.ID); !errors.Is(err, ErrHandoverNotFound) {
+		t.Errorf("expected no handover, got %v", err)
+	}
+}
+
+func TestFolderHandoverTakeOver(t *testing.T) {
+	device3 := protocol.DeviceID{3}
+	fcfg := newFolderConfig()
+	fcfg.Type = config.FolderTypeReceiveOnly
+	for i := range fcfg.Devices {
+		if fcfg.Devices[i].DeviceID == device1 {
+			fcfg.Devices[i].OwnedPrefixes = []string{"photos"}
+		}
+	}
+	m := setupHandoverModel(t, fcfg, config.DeviceConfiguration{DeviceID: device1, Introducer: true})
+
+	receiveHandover(t, m, device1, folderHandoverKindTakeOver, fcfg.ID, folderHandoverMessage{
+		From:          device1,
+		To:            myID,
+		Type:          config.FolderTypeSendReceive,
+		Devices:       []protocol.DeviceID{device2, device3},
+		OwnedPrefixes: []string{"photos"},
+	})
+
+	// Nothing happens before it's approved.
+	if newCfg, _ := m.cfg.Folder(fcfg.ID); newCfg.SharedWith(device2) || newCfg.Type != config.FolderTypeReceiveOnly {
+		t.Fatal("takeover applied without approval")
+	}
+	pending, err := m.PendingFolderHandovers()
+	must(t, err)
+	if len(pending) != 1 || pending[0].Kind != HandoverStepTakeOver || pending[0].From != device1 {
+		t.Fatalf("expected a pending takeover, got %+v", pending)
+	}
+	if err := m.ApproveFolderHandover(fcfg.ID, device2); !errors.Is(err, ErrHandoverNotPending) {
+		t.Errorf("expected nothing pending from device2, got %v", err)
+	}
+	must(t, m.ApproveFolderHandover(fcfg.ID, device1))
+	if pending, _ := m.PendingFolderHandovers(); len(pending) != 0 {
+		t.Errorf("takeover still pending: %+v", pending)
+	}
+
+	newCfg, _ := m.cfg.Folder(fcfg.ID)
+	if !newCfg.SharedWith(device2) {
+		t.Error("folder not shared with the known device")
+	}
+	if newCfg.SharedWith(device3) {
+		t.Error("folder shared with an unknown device")
+	}
+	if newCfg.Type != config.FolderTypeSendReceive {
+		t.Errorf("expected send receive, got %v", newCfg.Type)
+	}
+	if dev, _ := newCfg.Device(myID); !slices.Equal(dev.OwnedPrefixes, []string{"photos"}) {
+		t.Errorf("prefixes not taken over: %v", dev.OwnedPrefixes)
+	}
+	if dev, _ := newCfg.Device(device1); len(dev.OwnedPrefixes) != 0 {
+		t.Errorf("prefixes kept for the previous owner: %v", dev.OwnedPrefixes)
+	}
+	if kinds := queuedKinds(t, m, device1); !slices.Contains(kinds, folderHandoverKindTakenOver+" "+fcfg.ID) {
+		t.Errorf("takeover not confirmed: %v", kinds)
+	}
+
+	// A takeover for another device is ignored.
+	m2 := setupHandoverModel(t, newFolderConfig(), config.DeviceConfiguration{DeviceID: device1, Introducer: true})
+	receiveHandover(t, m2, device1, folderHandoverKindTakeOver, fcfg.ID, folderHandoverMessage{From: device1, To: device2, Devices: []protocol.DeviceID{device2}})
+	if pending, _ := m2.PendingFolderHandovers(); len(pending) != 0 {
+		t.Errorf("takeover for another device kept: %+v", pending)
+	}
+}
+
+func TestFolderHandoverNotIntroducer(t *testing.T) {
+	fcfg := newFolderConfig()
+	fcfg.Type = config.FolderTypeReceiveOnly
+	fcfg.Devices = append(fcfg.Devices, config.FolderDeviceConfiguration{DeviceID: device2, IntroducedBy: device1})
+	m := setupHandoverModel(t, fcfg,
+		config.DeviceConfiguration{DeviceID: device1, Introducer: true},
+		config.DeviceConfiguration{DeviceID: device2, IntroducedBy: device1},
+	)
+	before := m.cfg.RawCopy()
+
+	// Device2 shares the folder but isn't an introducer here.
+	device3 := protocol.DeviceID{3}
+	receiveHandover(t, m, device2, folderHandoverKindTakeOver, fcfg.ID, folderHandoverMessage{
+		From:          device2,
+		To:            myID,
+		Type:          config.FolderTypeSendReceive,
+		OwnedPrefixes: []string{"docs"},
+	})
+	receiveHandover(t, m, device2, folderHandoverKindRepoint, fcfg.ID, folderHandoverMessage{
+		From:   device2,
+		To:     device3,
+		ToName: "attacker",
+		After:  HandoverAfterRemove,
+	})
+
+	if pending, _ := m.PendingFolderHandovers(); len(pending) != 0 {
+		t.Errorf("handover from a non-introducer kept: %+v", pending)
+	}
+	if err := m.ApproveFolderHandover(fcfg.ID, device2); !errors.Is(err, ErrHandoverNotPending) {
+		t.Errorf("expected nothing pending, got %v", err)
+	}
+	if after := m.cfg.RawCopy(); !reflect.DeepEqual(before.Folders, after.Folders) || !reflect.DeepEqual(before.Devices, after.Devices) {
+		t.Error("handover from a non-introducer changed the configuration")
+	}
+}
+
+func TestFolderHandoverRepoint(t *testing.T) {
+	device3 := protocol.DeviceID{3}
+	fcfg := newFolderConfig()
+	for i := range fcfg.Devices {
+		if fcfg.Devices[i].DeviceID == device1 {
+			fcfg.Devices[i].OwnedPrefixes = []string{"docs"}
+		}
+	}
+	fcfg.Devices = append(fcfg.Devices, config.FolderDeviceConfiguration{DeviceID: device3, IntroducedBy: device1})
+	m := setupHandoverModel(t, fcfg,
+		config.DeviceConfiguration{DeviceID: device1, Introducer: true},
+		config.DeviceConfiguration{DeviceID: device3, IntroducedBy: device1},
+	)
+
+	receiveHandover(t, m, device1, folderHandoverKindRepoint, fcfg.ID, folderHandoverMessage{
+		From:  device1,
+		To:    device2,
+		After: HandoverAfterRemove,
+	})
+	if newCfg, _ := m.cfg.Folder(fcfg.ID); !newCfg.SharedWith(device1) || newCfg.SharedWith(device2) {
+		t.Fatal("repoint applied without approval")
+	}
+	must(t, m.ApproveFolderHandover(fcfg.ID, device1))
+
+	newCfg, _ := m.cfg.Folder(fcfg.ID)
+	if newCfg.SharedWith(device1) {
+		t.Error("folder still shared with the device that removed it")
+	}
+	dev, ok := newCfg.Device(device2)
+	if !ok || !slices.Equal(dev.OwnedPrefixes, []string{"docs"}) {
+		t.Errorf("folder not repointed: %+v", dev)
+	}
+	if dev, _ := newCfg.Device(device3); dev.IntroducedBy != device2 {
+		t.Errorf("introduction not taken over: %v", dev.IntroducedBy)
+	}
+	if dev, _ := m.cfg.Device(device2); !dev.Introducer {
+		t.Error("introducer role not taken over")
+	}
+}
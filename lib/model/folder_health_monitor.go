@@ -13,6 +13,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"sync"
 	"time"
 
@@ -35,6 +36,23 @@ type FolderPerformanceStats struct {
 	LastError        string        `json:"lastError"`
 }
 
+// FolderHealthWarning is a problem a folder is heading for, such as
+// resource usage above its limits.
+type FolderHealthWarning struct {
+	Type    string `json:"type"` // e.g. "high_cpu_usage"
+	Message string `json:"message"`
+}
+
+// FolderHealthChangedEventData is the payload of the FolderHealthChanged
+// event, sent when a folder becomes healthy or unhealthy, or the kinds of
+// warnings for it change.
+type FolderHealthChangedEventData struct {
+	Folder      string                    `json:"folder"`
+	Status      config.FolderHealthStatus `json:"status"`
+	Warnings    []FolderHealthWarning     `json:"warnings"`
+	Performance FolderPerformanceStats    `json:"performance"`
+}
+
 const (
 	// Default health check interval for active folders
 	defaultHealthCheckInterval = 30 * time.Second
@@ -62,8 +80,9 @@ type FolderHealthMonitor struct {
 	folderTickers map[string]*time.Ticker
 	tickersMut    sync.RWMutex
 
-	// Map of folder ID to last health status
+	// Map of folder ID to last health status and warnings
 	lastHealthStatus map[string]config.FolderHealthStatus
+	lastWarnings     map[string][]FolderHealthWarning
 	healthStatusMut  sync.RWMutex
 
 	// Performance monitoring
@@ -86,6 +105,7 @@ func NewFolderHealthMonitor(cfg config.Wrapper, model Model, evLogger events.Log
 		evLogger:         evLogger,
 		folderTickers:    make(map[string]*time.Ticker),
 		lastHealthStatus: make(map[string]config.FolderHealthStatus),
+		lastWarnings:     make(map[string][]FolderHealthWarning),
 		performanceStats: make(map[string]FolderPerformanceStats),
 		memoryLimiter:    NewMemoryLimiter(),
 	}
@@ -158,6 +178,14 @@ func (fhm *FolderHealthMonitor) stopMonitoringFolder(folderID string) {
 		delete(fhm.folderTickers, folderID)
 		slog.Debug("Stopped health monitoring for folder", "folder", folderID)
 	}
+
+	fhm.healthStatusMut.Lock()
+	delete(fhm.lastHealthStatus, folderID)
+	delete(fhm.lastWarnings, folderID)
+	fhm.healthStatusMut.Unlock()
+	fhm.perfStatsMut.Lock()
+	delete(fhm.performanceStats, folderID)
+	fhm.perfStatsMut.Unlock()
 }
 
 // monitorFolderHealth performs periodic health checks for a folder
@@ -211,7 +239,7 @@ func (fhm *FolderHealthMonitor) performHealthCheck(folderID string) {
 	}
 
 	// Check for predictive issues
-	fhm.checkPredictiveIssues(folderID, folder)
+	warnings := fhm.checkPredictiveIssues(folderID, folder)
 
 	// Collect final system stats
 	finalCPU, _ := cpu.Percent(0, false)
@@ -248,17 +276,22 @@ func (fhm *FolderHealthMonitor) performHealthCheck(folderID string) {
 	// Update performance stats
 	fhm.updatePerformanceStats(folderID, checkDuration, cpuUsage, memUsage, healthStatus)
 
-	// Store the health status
+	// Check if health status changed, then store it
+	changed := fhm.hasHealthStatusChanged(folderID, healthStatus, warnings)
+	healthStatus.FolderID = folderID
 	fhm.healthStatusMut.Lock()
 	fhm.lastHealthStatus[folderID] = healthStatus
+	fhm.lastWarnings[folderID] = warnings
 	fhm.healthStatusMut.Unlock()
 
-	// Check if health status changed
-	if fhm.hasHealthStatusChanged(folderID, healthStatus) {
+	if changed {
 		// Emit health status change event
-		fhm.evLogger.Log(events.FolderHealthChanged, map[string]interface{}{
-			"folder":       folderID,
-			"healthStatus": healthStatus,
+		perfStats, _ := fhm.GetFolderPerformanceStats(folderID)
+		fhm.evLogger.Log(events.FolderHealthChanged, FolderHealthChangedEventData{
+			Folder:      folderID,
+			Status:      healthStatus,
+			Warnings:    warnings,
+			Performance: perfStats,
 		})
 
 		// Log health issues
@@ -306,15 +339,17 @@ func (fhm *FolderHealthMonitor) applyMemoryOptimization(folderID string, memUsag
 }
 
 // checkPredictiveIssues checks for potential future issues based on performance trends
-func (fhm *FolderHealthMonitor) checkPredictiveIssues(folderID string, folder config.FolderConfiguration) {
+func (fhm *FolderHealthMonitor) checkPredictiveIssues(folderID string, folder config.FolderConfiguration) []FolderHealthWarning {
 	fhm.perfStatsMut.RLock()
 	defer fhm.perfStatsMut.RUnlock()
 
 	stats, exists := fhm.performanceStats[folderID]
 	if !exists {
-		return
+		return nil
 	}
 
+	var warnings []FolderHealthWarning
+
 	// Check for performance degradation trends
 	if stats.CheckCount >= 5 { // Need at least 5 data points
 		// Check if average check duration is increasing significantly
@@ -323,11 +358,9 @@ func (fhm *FolderHealthMonitor) checkPredictiveIssues(folderID string, folder co
 				"folder", folderID,
 				"avgDuration", stats.AvgCheckDuration)
 
-			// Emit predictive alert event
-			fhm.evLogger.Log(events.Failure, map[string]interface{}{
-				"folder":  folderID,
-				"type":    "performance_degradation",
-				"message": fmt.Sprintf("Folder %s health check duration is increasing: %v", folderID, stats.AvgCheckDuration),
+			warnings = append(warnings, FolderHealthWarning{
+				Type:    "performance_degradation",
+				Message: fmt.Sprintf("Folder %s health check duration is increasing: %v", folderID, stats.AvgCheckDuration),
 			})
 		}
 
@@ -338,11 +371,9 @@ func (fhm *FolderHealthMonitor) checkPredictiveIssues(folderID string, folder co
 				"folder", folderID,
 				"failureRate", failureRate)
 
-			// Emit predictive alert event
-			fhm.evLogger.Log(events.Failure, map[string]interface{}{
-				"folder":  folderID,
-				"type":    "high_failure_rate",
-				"message": fmt.Sprintf("Folder %s has high failure rate: %.2f%%", folderID, failureRate*100),
+			warnings = append(warnings, FolderHealthWarning{
+				Type:    "high_failure_rate",
+				Message: fmt.Sprintf("Folder %s has high failure rate: %.2f%%", folderID, failureRate*100),
 			})
 		}
 	}
@@ -356,11 +387,9 @@ func (fhm *FolderHealthMonitor) checkPredictiveIssues(folderID string, folder co
 				"cpuPercent", stats.CPUUsagePercent,
 				"maxAllowed", folder.MaxCPUUsagePercent)
 
-			// Emit throttling alert event
-			fhm.evLogger.Log(events.Failure, map[string]interface{}{
-				"folder":  folderID,
-				"type":    "high_cpu_usage",
-				"message": fmt.Sprintf("Folder %s is using high CPU: %.2f%% (max allowed: %d%%)", folderID, stats.CPUUsagePercent, folder.MaxCPUUsagePercent),
+			warnings = append(warnings, FolderHealthWarning{
+				Type:    "high_cpu_usage",
+				Message: fmt.Sprintf("Folder %s is using high CPU: %.2f%% (max allowed: %d%%)", folderID, stats.CPUUsagePercent, folder.MaxCPUUsagePercent),
 			})
 		}
 
@@ -374,11 +403,9 @@ func (fhm *FolderHealthMonitor) checkPredictiveIssues(folderID string, folder co
 				"memoryBytes", stats.MemoryUsageBytes,
 				"maxAllowedMB", folder.MaxMemoryUsageMB)
 
-			// Emit throttling alert event
-			fhm.evLogger.Log(events.Failure, map[string]interface{}{
-				"folder":  folderID,
-				"type":    "high_memory_usage",
-				"message": fmt.Sprintf("Folder %s is using high memory: %d bytes (%d MB) (max allowed: %d MB)", folderID, stats.MemoryUsageBytes, stats.MemoryUsageBytes/1024/1024, folder.MaxMemoryUsageMB),
+			warnings = append(warnings, FolderHealthWarning{
+				Type:    "high_memory_usage",
+				Message: fmt.Sprintf("Folder %s is using high memory: %d bytes (%d MB) (max allowed: %d MB)", folderID, stats.MemoryUsageBytes, stats.MemoryUsageBytes/1024/1024, folder.MaxMemoryUsageMB),
 			})
 		}
 	} else if stats.CPUUsagePercent > 80 {
@@ -386,11 +413,9 @@ func (fhm *FolderHealthMonitor) checkPredictiveIssues(folderID string, folder co
 			"folder", folderID,
 			"cpuPercent", stats.CPUUsagePercent)
 
-		// Emit predictive alert event
-		fhm.evLogger.Log(events.Failure, map[string]interface{}{
-			"folder":  folderID,
-			"type":    "high_cpu_usage",
-			"message": fmt.Sprintf("Folder %s is using high CPU: %.2f%%", folderID, stats.CPUUsagePercent),
+		warnings = append(warnings, FolderHealthWarning{
+			Type:    "high_cpu_usage",
+			Message: fmt.Sprintf("Folder %s is using high CPU: %.2f%%", folderID, stats.CPUUsagePercent),
 		})
 	}
 
@@ -400,13 +425,13 @@ func (fhm *FolderHealthMonitor) checkPredictiveIssues(folderID string, folder co
 			"folder", folderID,
 			"memoryBytes", stats.MemoryUsageBytes)
 
-		// Emit predictive alert event
-		fhm.evLogger.Log(events.Failure, map[string]interface{}{
-			"folder":  folderID,
-			"type":    "high_memory_usage",
-			"message": fmt.Sprintf("Folder %s is using high memory: %d bytes", folderID, stats.MemoryUsageBytes),
+		warnings = append(warnings, FolderHealthWarning{
+			Type:    "high_memory_usage",
+			Message: fmt.Sprintf("Folder %s is using high memory: %d bytes", folderID, stats.MemoryUsageBytes),
 		})
 	}
+
+	return warnings
 }
 
 // updatePerformanceStats updates the performance statistics for a folder
@@ -465,6 +490,13 @@ func (fhm *FolderHealthMonitor) checkFolderHealth(folder config.FolderConfigurat
 		healthStatus.Issues = append(healthStatus.Issues, err.Error())
 	}
 
+	// Writes that didn't read back the same mean the storage can't be
+	// trusted, until the folder starts over
+	if wv, ok := fhm.model.FolderWriteVerification(folder.ID); ok && wv.Failures > 0 {
+		healthStatus.Healthy = false
+		healthStatus.Issues = append(healthStatus.Issues, "data written to disk didn't read back the same, the storage may be failing")
+	}
+
 	return healthStatus
 }
 
@@ -517,8 +549,9 @@ func (fhm *FolderHealthMonitor) updateMonitoringInterval(folderID string, folder
 	}
 }
 
-// hasHealthStatusChanged checks if the health status has changed
-func (fhm *FolderHealthMonitor) hasHealthStatusChanged(folderID string, newStatus config.FolderHealthStatus) bool {
+// hasHealthStatusChanged checks if the health status or the kinds of
+// warnings have changed since the last check
+func (fhm *FolderHealthMonitor) hasHealthStatusChanged(folderID string, newStatus config.FolderHealthStatus, newWarnings []FolderHealthWarning) bool {
 	fhm.healthStatusMut.RLock()
 	defer fhm.healthStatusMut.RUnlock()
 
@@ -528,7 +561,19 @@ func (fhm *FolderHealthMonitor) hasHealthStatusChanged(folderID string, newStatu
 	}
 
 	// Compare health status
-	return oldStatus.Healthy != newStatus.Healthy
+	if oldStatus.Healthy != newStatus.Healthy || !slices.Equal(oldStatus.Issues, newStatus.Issues) {
+		return true
+	}
+	return !slices.Equal(warningTypes(fhm.lastWarnings[folderID]), warningTypes(newWarnings))
+}
+
+func warningTypes(warnings []FolderHealthWarning) []string {
+	types := make([]string, 0, len(warnings))
+	for _, w := range warnings {
+		types = append(types, w.Type)
+	}
+	slices.Sort(types)
+	return slices.Compact(types)
 }
 
 // cleanup stops all monitoring and releases resources
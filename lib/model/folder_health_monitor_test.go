@@ -10,6 +10,8 @@ import (
 	"context"
 	"iter"
 	"net"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -106,6 +108,60 @@ func TestFolderHealthMonitor(t *testing.T) {
 	}
 }
 
+func TestFolderHealthChangedEvent(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "folder")
+	if err := os.MkdirAll(filepath.Join(dir, config.DefaultMarkerName), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := config.Configuration{
+		Folders: []config.FolderConfiguration{
+			{
+				ID:             "test-folder",
+				Path:           dir,
+				FilesystemType: config.FilesystemTypeBasic,
+				MarkerName:     config.DefaultMarkerName,
+			},
+		},
+	}
+
+	evLogger := events.NewLogger()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go evLogger.Serve(ctx)
+	sub := evLogger.Subscribe(events.FolderHealthChanged)
+	defer sub.Unsubscribe()
+
+	fhm := NewFolderHealthMonitor(createMockConfigWrapper(cfg), &mockModel{}, evLogger)
+	expectEvent := func(healthy bool) {
+		t.Helper()
+		ev, err := sub.Poll(time.Second)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, ok := ev.Data.(FolderHealthChangedEventData)
+		if !ok || data.Folder != "test-folder" || data.Status.Healthy != healthy || data.Performance.CheckCount == 0 {
+			t.Fatalf("unexpected event data %+v", ev.Data)
+		}
+	}
+
+	// The first check reports the state, the same state again doesn't.
+	fhm.performHealthCheck("test-folder")
+	expectEvent(true)
+	fhm.performHealthCheck("test-folder")
+	if ev, err := sub.Poll(100 * time.Millisecond); err == nil {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	fhm.performHealthCheck("test-folder")
+	expectEvent(false)
+	if status, _ := fhm.GetFolderHealthStatus("test-folder"); status.FolderID != "test-folder" || len(status.Issues) == 0 {
+		t.Errorf("expected the issue in the stored status, got %+v", status)
+	}
+}
+
 // mockModel implements the Model interface for testing
 type mockModel struct{}
 
@@ -131,6 +187,11 @@ func (m *mockModel) DeviceStatistics() (map[protocol.DeviceID]stats.DeviceStatis
 	return nil, nil
 }
 
+func (m *mockModel) DeviceAddressLedgers() (map[protocol.DeviceID][]stats.AddressLedgerEntry, error) {
+	// No-op for testing
+	return nil, nil
+}
+
 func (m *mockModel) SetConnectionsService(service connections.Service) {
 	// No-op for testing
 }
@@ -213,6 +274,11 @@ func (m *mockModel) WatchError(folder string) error {
 	return nil
 }
 
+func (m *mockModel) RescanInterval(folder string) time.Duration {
+	// No-op for testing
+	return 0
+}
+
 func (m *mockModel) Override(folder string) {
 	// No-op for testing
 }
@@ -325,6 +391,221 @@ func (m *mockModel) Completion(device protocol.DeviceID, folder string) (FolderC
 	return FolderCompletion{}, nil
 }
 
+func (m *mockModel) PrefixCompletion(device protocol.DeviceID, folder, prefix string) (FolderCompletion, error) {
+	// No-op for testing
+	return FolderCompletion{}, nil
+}
+
+func (m *mockModel) LocalChangeReport(folder string, page, perpage int, withDiff bool) ([]LocalChange, error) {
+	// No-op for testing
+	return nil, nil
+}
+
+func (m *mockModel) PullPreview(folder string) (*PullPreview, error) {
+	// No-op for testing
+	return nil, nil
+}
+
+func (m *mockModel) CleanTempFiles(folder string) (*TempCleanupReport, error) {
+	// No-op for testing
+	return nil, nil
+}
+
+func (m *mockModel) MergeFolders(target, source string, dryRun bool) (*FolderMergeReport, error) {
+	// No-op for testing
+	return nil, nil
+}
+
+func (m *mockModel) FolderAtTime(folder, prefix string, at time.Time, target string) (*FolderAtTimeReport, error) {
+	// No-op for testing
+	return nil, nil
+}
+
+func (m *mockModel) Conflicts(folder string) ([]Conflict, error) {
+	// No-op for testing
+	return nil, nil
+}
+
+func (m *mockModel) ConflictVersions(folder, conflictPath string) (protocol.FileInfo, protocol.FileInfo, error) {
+	// No-op for testing
+	return protocol.FileInfo{}, protocol.FileInfo{}, nil
+}
+
+func (m *mockModel) ResolveConflict(folder, conflictPath, keep string) error {
+	// No-op for testing
+	return nil
+}
+
+func (m *mockModel) WaitForFolderIdle(ctx context.Context, folder string, timeout time.Duration) error {
+	// No-op for testing
+	return nil
+}
+
+func (m *mockModel) RequestFolderPreview(ctx context.Context, device protocol.DeviceID, folder string, maxEntries int) (*protocol.FolderPreview, error) {
+	// No-op for testing
+	return nil, nil
+}
+
+func (m *mockModel) IdentityChanges() map[protocol.DeviceID]IdentityChange {
+	// No-op for testing
+	return nil
+}
+
+func (m *mockModel) ApproveIdentityChange(device protocol.DeviceID) error {
+	// No-op for testing
+	return nil
+}
+
+func (m *mockModel) DismissIdentityChange(device protocol.DeviceID) error {
+	// No-op for testing
+	return nil
+}
+
+func (m *mockModel) QueueDeviceMessage(device protocol.DeviceID, kind, key string, payload []byte) error {
+	// No-op for testing
+	return nil
+}
+
+func (m *mockModel) QueuedDeviceMessages(device protocol.DeviceID) ([]QueuedMessage, error) {
+	// No-op for testing
+	return nil, nil
+}
+
+func (m *mockModel) ChangeAnomalies() map[string]ChangeAnomaly {
+	// No-op for testing
+	return nil
+}
+
+func (m *mockModel) FolderCapabilities(folder string) (*FolderCapabilities, error) {
+	// No-op for testing
+	return nil, nil
+}
+
+func (m *mockModel) ProbeFolderCapabilities(folder string) (*FolderCapabilities, error) {
+	// No-op for testing
+	return nil, nil
+}
+
+func (m *mockModel) EncryptionRecoveryCode(folder string, device protocol.DeviceID) (string, error) {
+	// No-op for testing
+	return "", nil
+}
+
+func (m *mockModel) AcknowledgeChangeAnomaly(folder string) error {
+	// No-op for testing
+	return nil
+}
+
+func (m *mockModel) FolderDependencyWait(folder string) string {
+	// No-op for testing
+	return ""
+}
+
+func (m *mockModel) FolderQuotaExceeded(folder string) int64 {
+	// No-op for testing
+	return 0
+}
+
+func (m *mockModel) FolderWriteVerification(folder string) (WriteVerificationStats, bool) {
+	// No-op for testing
+	return WriteVerificationStats{}, false
+}
+
+func (m *mockModel) FolderQueuePosition(folder string) (string, int) {
+	// No-op for testing
+	return "", 0
+}
+
+func (m *mockModel) PendingConfigSync() ([]ConfigSyncChange, error) {
+	// No-op for testing
+	return nil, nil
+}
+
+func (m *mockModel) ApproveConfigSync(device protocol.DeviceID, part, key string) error {
+	// No-op for testing
+	return nil
+}
+
+func (m *mockModel) RejectConfigSync(device protocol.DeviceID, part, key string) error {
+	// No-op for testing
+	return nil
+}
+
+func (m *mockModel) PromoteConfigSyncSpare(device protocol.DeviceID) ([]string, error) {
+	// No-op for testing
+	return nil, nil
+}
+
+func (m *mockModel) StartFolderHandover(folder string, to protocol.DeviceID, after string) (FolderHandover, error) {
+	// No-op for testing
+	return FolderHandover{}, nil
+}
+
+func (m *mockModel) FolderHandovers() ([]FolderHandover, error) {
+	// No-op for testing
+	return nil, nil
+}
+
+func (m *mockModel) ClearFolderHandover(folder string) error {
+	// No-op for testing
+	return nil
+}
+
+func (m *mockModel) PendingFolderHandovers() ([]PendingFolderHandover, error) {
+	// No-op for testing
+	return nil, nil
+}
+
+func (m *mockModel) ApproveFolderHandover(folder string, from protocol.DeviceID) error {
+	// No-op for testing
+	return nil
+}
+
+func (m *mockModel) RejectFolderHandover(folder string, from protocol.DeviceID) error {
+	// No-op for testing
+	return nil
+}
+
+func (m *mockModel) PendingOwnershipClaims() ([]PendingOwnershipClaim, error) {
+	// No-op for testing
+	return nil, nil
+}
+
+func (m *mockModel) ApproveOwnershipClaim(folder string, device protocol.DeviceID) error {
+	// No-op for testing
+	return nil
+}
+
+func (m *mockModel) RejectOwnershipClaim(folder string, device protocol.DeviceID) error {
+	// No-op for testing
+	return nil
+}
+
+func (m *mockModel) RevokeDevice(device protocol.DeviceID, distrust bool) (DeviceRevocation, error) {
+	// No-op for testing
+	return DeviceRevocation{}, nil
+}
+
+func (m *mockModel) DeviceRevocations() ([]DeviceRevocation, error) {
+	// No-op for testing
+	return nil, nil
+}
+
+func (m *mockModel) PendingDeviceRevocations() ([]PendingDeviceRevocation, error) {
+	// No-op for testing
+	return nil, nil
+}
+
+func (m *mockModel) ApproveDeviceRevocation(device protocol.DeviceID) (DeviceRevocation, error) {
+	// No-op for testing
+	return DeviceRevocation{}, nil
+}
+
+func (m *mockModel) RejectDeviceRevocation(device protocol.DeviceID) error {
+	// No-op for testing
+	return nil
+}
+
 func (m *mockModel) ConnectionStats() map[string]interface{} {
 	// No-op for testing
 	return nil
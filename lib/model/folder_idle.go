@@ -0,0 +1,124 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// ErrFolderIdleTimeout is returned by WaitForFolderIdle when the folder did
+// not become idle within the given timeout.
+var ErrFolderIdleTimeout = errors.New("timeout waiting for folder to become idle")
+
+// Completion of remote devices isn't always accompanied by an event we
+// subscribe to (e.g. download progress being dropped), so the idle
+// condition is also re-evaluated at this interval.
+const folderIdleRecheckInterval = time.Second
+
+// WaitForFolderIdle blocks until the folder is idle, i.e. no scan or pull
+// is in progress and it is in sync with all connected devices it is shared
+// with. A zero timeout waits until the context is cancelled. On success a
+// FolderIdle event is emitted.
+func (m *model) WaitForFolderIdle(ctx context.Context, folder string, timeout time.Duration) error {
+	m.mut.RLock()
+	err := m.checkFolderRunningRLocked(folder)
+	m.mut.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	sub := m.evLogger.Subscribe(events.StateChanged | events.LocalIndexUpdated | events.RemoteIndexUpdated | events.FolderCompletion | events.DeviceConnected | events.DeviceDisconnected)
+	defer sub.Unsubscribe()
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(folderIdleRecheckInterval)
+	defer ticker.Stop()
+
+	start := time.Now()
+	for {
+		idle, err := m.folderIdle(folder)
+		if err != nil {
+			return err
+		}
+		if idle {
+			m.evLogger.Log(events.FolderIdle, map[string]interface{}{
+				"folder": folder,
+				"waited": time.Since(start).Seconds(),
+			})
+			return nil
+		}
+
+		select {
+		case <-sub.C():
+		case <-ticker.C:
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return ErrFolderIdleTimeout
+			}
+			return ctx.Err()
+		}
+	}
+}
+
+// folderIdle returns true if the folder is in the idle state, needs nothing
+// locally and no connected device it is shared with needs anything either.
+func (m *model) folderIdle(folder string) (bool, error) {
+	m.mut.RLock()
+	err := m.checkFolderRunningRLocked(folder)
+	runner, _ := m.folderRunners.Get(folder)
+	cfg := m.folderCfgs[folder]
+	m.mut.RUnlock()
+	if err != nil {
+		return false, err
+	}
+
+	state, _, err := runner.getState()
+	if err != nil {
+		return false, err
+	}
+	if state != FolderIdle {
+		return false, nil
+	}
+
+	need, err := m.sdb.CountNeed(folder, protocol.LocalDeviceID)
+	if err != nil {
+		return false, err
+	}
+	if need.TotalItems() > 0 {
+		return false, nil
+	}
+
+	for _, device := range cfg.DeviceIDs() {
+		if device == m.id || !m.ConnectedTo(device) {
+			continue
+		}
+		comp, err := m.folderCompletion(device, folder)
+		if err != nil {
+			return false, err
+		}
+		if comp.RemoteState != remoteFolderValid {
+			// Not sharing it back or paused on their side; there is
+			// nothing to wait for.
+			continue
+		}
+		if comp.NeedItems > 0 || comp.NeedDeletes > 0 || comp.NeedBytes > 0 {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
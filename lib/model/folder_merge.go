@@ -0,0 +1,303 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/itererr"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/osutil"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+var errMergeSameFolder = errors.New("cannot merge a folder into itself")
+
+// What a folder merge does with a file of the source folder
+const (
+	MergeActionAdd      = "add"      // copied into the target
+	MergeActionConflict = "conflict" // copied into the target as a conflict copy
+	MergeActionSkip     = "skip"     // changed on disk since the last scan
+)
+
+// FolderMergeItem is a file of the source folder and what the merge does
+// with it.
+type FolderMergeItem struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	// Name of the file in the target folder, when different
+	TargetName string `json:"targetName,omitempty"`
+	Size       int64  `json:"size"`
+
+	file protocol.FileInfo
+}
+
+// FolderMergeReport describes a merge of one folder into another, planned
+// or done.
+type FolderMergeReport struct {
+	Target string `json:"target"`
+	Source string `json:"source"`
+	DryRun bool   `json:"dryRun"`
+	// Files of the source folder by action; identical files are not
+	// listed, only counted
+	Identical      int               `json:"identical"`
+	IdenticalBytes int64             `json:"identicalBytes"`
+	Added          int               `json:"added"`
+	Conflicts      int               `json:"conflicts"`
+	Skipped        int               `json:"skipped"`
+	CopiedBytes    int64             `json:"copiedBytes"`
+	Items          []FolderMergeItem `json:"items"`
+	// Devices that shared the source folder and now share the target
+	AddedDevices []protocol.DeviceID `json:"addedDevices"`
+}
+
+// MergeFolders merges the source folder into the target folder. Files of
+// the source with the same contents in the target are left alone, the
+// others are copied into the target, as conflict copies where the target
+// has a different file of the same name. The copies are entered into the
+// target's index with the source's block lists, so they are neither
+// hashed again nor transferred to devices already having the data. The
+// devices sharing the source are then added to the target and the source
+// folder is removed, leaving its files on disk. With dryRun the merge is
+// only planned.
+func (m *model) MergeFolders(target, source string, dryRun bool) (*FolderMergeReport, error) {
+	if target == source {
+		return nil, errMergeSameFolder
+	}
+
+	m.mut.RLock()
+	err := m.checkFolderRunningRLocked(target)
+	if err == nil {
+		err = m.checkFolderRunningRLocked(source)
+	}
+	runner, _ := m.folderRunners.Get(target)
+	targetCfg := m.folderCfgs[target]
+	sourceCfg := m.folderCfgs[source]
+	m.mut.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	for _, cfg := range []config.FolderConfiguration{targetCfg, sourceCfg} {
+		if cfg.Type == config.FolderTypeReceiveEncrypted {
+			return nil, fmt.Errorf("folder %s: cannot merge receive encrypted folders", cfg.Description())
+		}
+	}
+
+	report, err := m.planFolderMerge(targetCfg, sourceCfg)
+	if err != nil {
+		return nil, err
+	}
+	report.DryRun = dryRun
+	if dryRun {
+		return report, nil
+	}
+
+	if err := runner.mergeFrom(sourceCfg.Filesystem(), report); err != nil {
+		return nil, err
+	}
+
+	waiter, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		folder, _, ok := cfg.Folder(target)
+		if !ok {
+			return
+		}
+		for _, dev := range sourceCfg.Devices {
+			if dev.DeviceID != m.id && !folder.SharedWith(dev.DeviceID) {
+				folder.Devices = append(folder.Devices, dev)
+			}
+		}
+		cfg.SetFolder(folder)
+
+		folders := cfg.Folders[:0]
+		for _, folder := range cfg.Folders {
+			if folder.ID != source {
+				folders = append(folders, folder)
+			}
+		}
+		cfg.Folders = folders
+	})
+	if err != nil {
+		return nil, err
+	}
+	waiter.Wait()
+
+	slog.Info("Merged folders", targetCfg.LogAttr(), slog.String("source", sourceCfg.Description()), slog.Int("added", report.Added), slog.Int("conflicts", report.Conflicts), slog.Int("identical", report.Identical))
+	return report, nil
+}
+
+// planFolderMerge matches the files of the source folder against those of
+// the target by name and block list hash.
+func (m *model) planFolderMerge(targetCfg, sourceCfg config.FolderConfiguration) (*FolderMergeReport, error) {
+	report := &FolderMergeReport{Target: targetCfg.ID, Source: sourceCfg.ID}
+	for _, dev := range sourceCfg.Devices {
+		if dev.DeviceID != m.id && !targetCfg.SharedWith(dev.DeviceID) {
+			report.AddedDevices = append(report.AddedDevices, dev.DeviceID)
+		}
+	}
+
+	srcFs := sourceCfg.Filesystem()
+	modTimeWindow := sourceCfg.ModTimeWindow()
+	for file, err := range itererr.Zip(m.sdb.AllLocalFiles(sourceCfg.ID, protocol.LocalDeviceID)) {
+		if err != nil {
+			return nil, err
+		}
+		if file.Type != protocol.FileInfoTypeFile || file.IsDeleted() || file.IsInvalid() {
+			// Directories are created as needed; symlinks and the like
+			// are left out.
+			continue
+		}
+
+		item := FolderMergeItem{Name: file.Name, Size: file.Size, file: file}
+		cur, ok, err := m.sdb.GetDeviceFile(targetCfg.ID, protocol.LocalDeviceID, file.Name)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case ok && !cur.IsDeleted() && cur.Type == protocol.FileInfoTypeFile && cur.BlocksEqual(file):
+			report.Identical++
+			report.IdenticalBytes += file.Size
+			continue
+		case !unchangedOnDisk(srcFs, file, modTimeWindow):
+			item.Action = MergeActionSkip
+			report.Skipped++
+		case ok && !cur.IsDeleted():
+			item.Action = MergeActionConflict
+			item.TargetName = conflictName(file.Name, file.ModifiedBy.String())
+			report.Conflicts++
+			report.CopiedBytes += file.Size
+		default:
+			item.Action = MergeActionAdd
+			report.Added++
+			report.CopiedBytes += file.Size
+		}
+		report.Items = append(report.Items, item)
+	}
+	return report, nil
+}
+
+func unchangedOnDisk(filesystem fs.Filesystem, file protocol.FileInfo, modTimeWindow time.Duration) bool {
+	info, err := filesystem.Lstat(file.Name)
+	if err != nil || !info.IsRegular() || info.Size() != file.Size {
+		return false
+	}
+	diff := info.ModTime().Sub(file.ModTime())
+	return diff >= -modTimeWindow && diff <= modTimeWindow
+}
+
+// mergeFrom copies the files planned to be added or conflicted into the
+// folder and enters them into the index. It runs on the folder routine, so
+// the copies don't race with scans and pulls.
+func (f *folder) mergeFrom(srcFs fs.Filesystem, report *FolderMergeReport) error {
+	return f.doInSync(func() error {
+		if err := f.getHealthErrorWithoutIgnores(); err != nil {
+			return err
+		}
+
+		batch := NewFileInfoBatch(func(files []protocol.FileInfo) error {
+			return f.updateLocalsFromScanning(files)
+		})
+		for i := range report.Items {
+			item := &report.Items[i]
+			if item.Action != MergeActionAdd && item.Action != MergeActionConflict {
+				continue
+			}
+			name := item.Name
+			if item.TargetName != "" {
+				name = item.TargetName
+			}
+			if err := f.mergeCopy(srcFs, item.file, name); err != nil {
+				f.sl.Warn("Failed to merge file", slogutil.FilePath(item.Name), slogutil.Error(err))
+				if item.Action == MergeActionAdd {
+					report.Added--
+				} else {
+					report.Conflicts--
+				}
+				report.CopiedBytes -= item.Size
+				report.Skipped++
+				item.Action = MergeActionSkip
+				continue
+			}
+
+			version, err := f.mergeVersion(name)
+			if err != nil {
+				return err
+			}
+			file := item.file
+			file.Name = name
+			file.Version = version.Update(f.shortID)
+			file.ModifiedBy = f.shortID
+			file.Sequence = 0
+			file.LocalFlags = 0
+			file.InodeChangeNs = 0
+			file.HardLinkTarget = ""
+			file.PreviousBlocksHash = nil
+			batch.Append(file)
+			if err := batch.FlushIfFull(); err != nil {
+				return err
+			}
+		}
+		return batch.Flush()
+	})
+}
+
+// mergeVersion returns the version a merged file builds on: that of what
+// we and the other devices have at the name, e.g. a deleted file, so that
+// the merged one supersedes it instead of conflicting with it.
+func (f *folder) mergeVersion(name string) (protocol.Vector, error) {
+	var version protocol.Vector
+	if cur, ok, err := f.db.GetDeviceFile(f.folderID, protocol.LocalDeviceID, name); err != nil {
+		return protocol.Vector{}, err
+	} else if ok {
+		version = cur.Version
+	}
+	if gf, ok, err := f.db.GetGlobalFile(f.folderID, name); err != nil {
+		return protocol.Vector{}, err
+	} else if ok {
+		version = version.Merge(gf.Version)
+	}
+	return version, nil
+}
+
+// mergeCopy copies a file of another folder to name in this one, through a
+// temporary file and keeping the modification time, so that the scanner
+// sees it as unchanged.
+func (f *folder) mergeCopy(srcFs fs.Filesystem, file protocol.FileInfo, name string) error {
+	if _, err := f.mtimefs.Lstat(name); !fs.IsNotExist(err) {
+		return fmt.Errorf("%s: already exists", name)
+	}
+	if dir := filepath.Dir(name); dir != "." {
+		if err := f.mtimefs.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	tempName := fs.TempName(name)
+	if err := osutil.Copy(f.CopyRangeMethod.ToFS(), srcFs, f.mtimefs, file.Name, tempName); err != nil {
+		return err
+	}
+	if !f.IgnorePerms && !file.NoPermissions {
+		if err := f.mtimefs.Chmod(tempName, fs.FileMode(file.Permissions&0o777)); err != nil {
+			f.mtimefs.Remove(tempName)
+			return err
+		}
+	}
+	if err := f.mtimefs.Chtimes(tempName, file.ModTime(), file.ModTime()); err != nil {
+		f.mtimefs.Remove(tempName)
+		return err
+	}
+	if err := f.mtimefs.Rename(tempName, name); err != nil {
+		f.mtimefs.Remove(tempName)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/rand"
+)
+
+func TestMergeFolders(t *testing.T) {
+	w, fcfg, wCancel := newDefaultCfgWrapper()
+	defer wCancel()
+	source := newFolderConfiguration(defaultCfgWrapper, "source", "source", config.FilesystemTypeFake, rand.String(32)+"?content=true")
+	source.FSWatcherEnabled = false
+	source.Devices = append(source.Devices, config.FolderDeviceConfiguration{DeviceID: device2})
+	waiter, err := w.Modify(func(cfg *config.Configuration) {
+		cfg.SetDevice(newDeviceConfiguration(cfg.Defaults.Device, device2, "device2"))
+		cfg.SetFolder(source)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waiter.Wait()
+
+	targetFs := fcfg.Filesystem()
+	sourceFs := source.Filesystem()
+	writeFile(t, targetFs, "same", []byte("same data"))
+	writeFile(t, targetFs, "differs", []byte("target data"))
+	writeFile(t, sourceFs, "same", []byte("same data"))
+	writeFile(t, sourceFs, "differs", []byte("source data"))
+	must(t, sourceFs.MkdirAll("dir", 0o755))
+	writeFile(t, sourceFs, "dir/new", []byte("new data"))
+
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	report, err := m.MergeFolders(fcfg.ID, source.ID, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Identical != 1 || report.Conflicts != 1 || report.Added != 1 || report.Skipped != 0 {
+		t.Fatalf("unexpected plan %+v", report)
+	}
+	if _, ok := m.cfg.Folder(source.ID); !ok {
+		t.Fatal("dry run should not remove the source folder")
+	}
+
+	// Another device deleted a file by the same name before
+	deleted := protocol.FileInfo{Name: "dir/new", Deleted: true, Version: protocol.Vector{}.Update(device1.Short())}
+	must(t, m.sdb.Update(fcfg.ID, device1, []protocol.FileInfo{deleted}))
+
+	report, err = m.MergeFolders(fcfg.ID, source.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Conflicts != 1 || report.Added != 1 {
+		t.Fatalf("unexpected merge %+v", report)
+	}
+
+	if fi, ok, err := m.model.CurrentFolderFile(fcfg.ID, "dir/new"); err != nil || !ok {
+		t.Error("expected the added file in the target index", err)
+	} else if fi.Version.Compare(deleted.Version) != protocol.Greater {
+		t.Errorf("expected the added file to supersede the deleted one, got version %v", fi.Version)
+	}
+	if _, err := targetFs.Lstat("dir/new"); err != nil {
+		t.Error("expected the added file in the target folder:", err)
+	}
+	if conflicts := existingConflicts("differs", targetFs); len(conflicts) != 1 {
+		t.Error("expected a conflict copy, got", conflicts)
+	}
+	if _, ok := m.cfg.Folder(source.ID); ok {
+		t.Error("expected the source folder to be removed")
+	}
+	if target, _ := m.cfg.Folder(fcfg.ID); !target.SharedWith(device2) {
+		t.Error("expected the target to be shared with the source's devices")
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/syncthing/syncthing/internal/itererr"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+const (
+	// DefaultFolderPreviewEntries is the number of top level directories
+	// requested in a folder preview when no limit is given.
+	DefaultFolderPreviewEntries = 25
+
+	// Upper bound on the number of listed top level directories we ask
+	// for or hand out, keeping the preview message small.
+	maxFolderPreviewEntries = 1000
+)
+
+// ErrDeviceNotConnected is returned when an operation needs to talk to a
+// device we currently have no connection to.
+var ErrDeviceNotConnected = errors.New("device is not connected")
+
+// RequestFolderPreview asks the given device for a summary of the contents
+// of a folder it offers to us, i.e. its largest top level directories and
+// the total number of files, directories and bytes. This lets the user
+// decide whether to accept a pending folder before anything is synced.
+func (m *model) RequestFolderPreview(ctx context.Context, device protocol.DeviceID, folder string, maxEntries int) (*protocol.FolderPreview, error) {
+	m.mut.RLock()
+	var conn protocol.Connection
+	if connIDs, ok := m.deviceConnIDs[device]; ok {
+		// Metadata, so the primary connection.
+		conn = m.connections[connIDs[0]]
+	}
+	m.mut.RUnlock()
+	if conn == nil {
+		return nil, ErrDeviceNotConnected
+	}
+
+	return conn.FolderPreview(ctx, &protocol.FolderPreviewRequest{
+		Folder:     folder,
+		MaxEntries: clampFolderPreviewEntries(maxEntries),
+	})
+}
+
+// FolderPreview answers a folder preview request from a remote device. It
+// implements protocol.FolderPreviewHandler. Only devices the folder is
+// shared with in plain text get an answer, which may be before they have
+// accepted the folder themselves.
+func (m *model) FolderPreview(conn protocol.Connection, req *protocol.FolderPreviewRequest) (*protocol.FolderPreview, error) {
+	deviceID := conn.DeviceID()
+
+	m.mut.RLock()
+	folderCfg, ok := m.folderCfgs[req.Folder]
+	m.mut.RUnlock()
+	if !ok || !folderCfg.SharedWith(deviceID) || folderCfg.Paused {
+		l.Debugf("Folder preview from %s for unavailable folder %q", deviceID.Short(), req.Folder)
+		return nil, protocol.ErrNoSuchFile
+	}
+	if dev, _ := folderCfg.Device(deviceID); dev.EncryptionPassword != "" {
+		l.Debugf("Folder preview from %s for folder %q, which is encrypted for them", deviceID.Short(), req.Folder)
+		return nil, protocol.ErrNoSuchFile
+	}
+
+	return m.buildFolderPreview(req.Folder, clampFolderPreviewEntries(req.MaxEntries))
+}
+
+// buildFolderPreview aggregates the global state of the folder per top
+// level directory, listing at most maxEntries directories by size.
+func (m *model) buildFolderPreview(folder string, maxEntries int) (*protocol.FolderPreview, error) {
+	preview := &protocol.FolderPreview{}
+	dirs := make(map[string]*protocol.FolderPreviewEntry)
+	for f, err := range itererr.Zip(m.sdb.AllGlobalFiles(folder)) {
+		if err != nil {
+			return nil, err
+		}
+		if f.Deleted || f.IsInvalid() {
+			continue
+		}
+
+		if f.IsDirectory() {
+			preview.Directories++
+		} else {
+			preview.Files++
+			preview.Bytes += f.Size
+		}
+
+		top, rest, nested := strings.Cut(f.Name, string(filepath.Separator))
+		if !nested && !f.IsDirectory() {
+			// Files in the folder root count towards the totals only.
+			continue
+		}
+		entry, ok := dirs[top]
+		if !ok {
+			entry = &protocol.FolderPreviewEntry{Name: top}
+			dirs[top] = entry
+		}
+		switch {
+		case rest == "":
+			// The top level directory itself
+		case f.IsDirectory():
+			entry.Directories++
+		default:
+			entry.Files++
+			entry.Bytes += f.Size
+		}
+	}
+
+	preview.Entries = make([]protocol.FolderPreviewEntry, 0, len(dirs))
+	for _, entry := range dirs {
+		preview.Entries = append(preview.Entries, *entry)
+	}
+	slices.SortFunc(preview.Entries, func(a, b protocol.FolderPreviewEntry) int {
+		if c := cmp.Compare(b.Bytes, a.Bytes); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Name, b.Name)
+	})
+	if len(preview.Entries) > maxEntries {
+		preview.Entries = preview.Entries[:maxEntries]
+		preview.Truncated = true
+	}
+	return preview, nil
+}
+
+func clampFolderPreviewEntries(n int) int {
+	if n <= 0 {
+		return DefaultFolderPreviewEntries
+	}
+	return min(n, maxFolderPreviewEntries)
+}
@@ -0,0 +1,73 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestFolderPreview(t *testing.T) {
+	w, fcfg, wCancel := newDefaultCfgWrapper()
+	defer wCancel()
+	m := setupModel(t, w)
+	defer cleanupModelAndRemoveDir(m, fcfg.Filesystem().URI())
+
+	version := protocol.Vector{Counters: []protocol.Counter{{ID: 42, Value: 1}}}
+	var seq int64
+	file := func(name string, size int64) protocol.FileInfo {
+		seq++
+		return protocol.FileInfo{
+			Name:     name,
+			Size:     size,
+			Version:  version,
+			Sequence: seq,
+			Blocks:   []protocol.BlockInfo{{Size: int(size), Hash: []byte("some hash bytes")}},
+		}
+	}
+	dir := func(name string) protocol.FileInfo {
+		seq++
+		return protocol.FileInfo{Name: name, Type: protocol.FileInfoTypeDirectory, Version: version, Sequence: seq}
+	}
+	must(t, m.sdb.Update("default", device1, []protocol.FileInfo{
+		file("rootfile", 10),
+		dir("big"),
+		file(filepath.Join("big", "file"), 100),
+		dir(filepath.Join("big", "sub")),
+		file(filepath.Join("big", "sub", "file"), 50),
+		dir("small"),
+		file(filepath.Join("small", "file"), 5),
+	}))
+
+	conn := newFakeConnection(device1, m)
+	preview, err := m.FolderPreview(conn, &protocol.FolderPreviewRequest{Folder: "default", MaxEntries: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if preview.Files != 4 || preview.Directories != 3 || preview.Bytes != 165 {
+		t.Errorf("unexpected totals: %d files, %d directories, %d bytes", preview.Files, preview.Directories, preview.Bytes)
+	}
+	expected := []protocol.FolderPreviewEntry{{Name: "big", Files: 2, Directories: 1, Bytes: 150}}
+	if !slices.Equal(preview.Entries, expected) {
+		t.Errorf("expected entries %v, got %v", expected, preview.Entries)
+	}
+	if !preview.Truncated {
+		t.Error("expected preview to be truncated")
+	}
+
+	// The folder isn't shared with device2, so it must not learn anything
+	// about it.
+	conn = newFakeConnection(device2, m)
+	if _, err := m.FolderPreview(conn, &protocol.FolderPreviewRequest{Folder: "default"}); !errors.Is(err, protocol.ErrNoSuchFile) {
+		t.Errorf("expected ErrNoSuchFile for unshared folder, got %v", err)
+	}
+}
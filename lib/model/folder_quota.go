@@ -0,0 +1,137 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// quotaCheckInterval is how often a folder over its quota tries pulling
+// again, in case enough space was freed, besides other reasons to pull.
+const quotaCheckInterval = time.Minute
+
+// folderQuotas records by how many bytes pulling everything would take
+// each folder over its quota.
+type folderQuotas struct {
+	mut  sync.Mutex
+	over map[string]int64
+}
+
+func newFolderQuotas() *folderQuotas {
+	return &folderQuotas{over: make(map[string]int64)}
+}
+
+// set records how far the folder would go over its quota, zero meaning it
+// doesn't.
+func (q *folderQuotas) set(folder string, over int64) {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+	if over <= 0 {
+		delete(q.over, folder)
+		return
+	}
+	q.over[folder] = over
+}
+
+func (q *folderQuotas) get(folder string) int64 {
+	q.mut.Lock()
+	defer q.mut.Unlock()
+	return q.over[folder]
+}
+
+// FolderQuotaExceeded returns by how many bytes pulling everything would
+// take the folder over its quota, or zero when it's not refusing data.
+func (m *model) FolderQuotaExceeded(folder string) int64 {
+	return m.folderQuotas.get(folder)
+}
+
+// pullQuota keeps track of the usage of a folder during a puller
+// iteration, admitting files as long as they fit.
+type pullQuota struct {
+	limit   int64
+	usage   int64 // what the folder has, plus the growth of admitted files
+	refused int64 // growth of the files that didn't fit
+}
+
+// admit returns whether pulling a file of the given size, replacing one of
+// the current size, keeps the folder within its quota, counting it
+// either way.
+func (q *pullQuota) admit(size, curSize int64) bool {
+	growth := size - curSize
+	if growth <= 0 || q.usage+growth <= q.limit {
+		q.usage += growth
+		return true
+	}
+	q.refused += growth
+	return false
+}
+
+// over returns by how many bytes the refused files would have taken the
+// folder over its quota.
+func (q *pullQuota) over() int64 {
+	if q == nil || q.refused == 0 {
+		return 0
+	}
+	return q.usage + q.refused - q.limit
+}
+
+// startPullQuota sets up quota tracking for a puller iteration, if the
+// folder has a quota.
+func (f *sendReceiveFolder) startPullQuota() error {
+	f.pullQuota = nil
+	if f.QuotaBytes <= 0 {
+		return nil
+	}
+	counts, err := f.db.CountLocal(f.folderID, protocol.LocalDeviceID)
+	if err != nil {
+		return err
+	}
+	f.pullQuota = &pullQuota{limit: f.QuotaBytes, usage: counts.Bytes}
+	return nil
+}
+
+// admitToQuota returns whether the file may be pulled without taking the
+// folder over its quota.
+func (f *sendReceiveFolder) admitToQuota(file protocol.FileInfo) (bool, error) {
+	if f.pullQuota == nil {
+		return true, nil
+	}
+	var curSize int64
+	cur, ok, err := f.model.sdb.GetDeviceFile(f.folderID, protocol.LocalDeviceID, file.Name)
+	if err != nil {
+		return false, err
+	}
+	if ok && !cur.IsDeleted() && !cur.IsInvalid() {
+		curSize = cur.Size
+	}
+	if !f.pullQuota.admit(file.Size, curSize) {
+		l.Debugf("%v not pulling %s, over quota", f, file.Name)
+		return false, nil
+	}
+	return true, nil
+}
+
+// setQuotaExceeded records by how many bytes the folder would be over its
+// quota, and has it checked again later while it is.
+func (f *folder) setQuotaExceeded(over int64) {
+	prev := f.model.folderQuotas.get(f.ID)
+	f.model.folderQuotas.set(f.ID, over)
+	f.stateTracker.setQuotaExceeded(over > 0)
+	switch {
+	case over > 0 && prev == 0:
+		f.sl.Warn("Folder is over its quota, not accepting new data", slog.Int64("quota", f.QuotaBytes), slog.Int64("over", over))
+	case over == 0 && prev > 0:
+		f.sl.Info("Folder is within its quota again")
+	}
+	if over > 0 {
+		f.pullFailTimer.Reset(quotaCheckInterval)
+	}
+}
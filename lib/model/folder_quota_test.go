@@ -0,0 +1,64 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/events"
+)
+
+func TestPullQuota(t *testing.T) {
+	q := &pullQuota{limit: 1000, usage: 600}
+
+	if !q.admit(300, 0) {
+		t.Error("file within the quota refused")
+	}
+	if q.admit(200, 0) {
+		t.Error("file over the quota admitted")
+	}
+	// Replacing a file with a smaller one, or one that fits, is fine.
+	if !q.admit(50, 500) || !q.admit(150, 50) {
+		t.Error("file not growing the folder beyond the quota refused")
+	}
+	if q.admit(500, 0) {
+		t.Error("file over the quota admitted")
+	}
+	// 550 bytes used, and 700 refused
+	if over := q.over(); over != 250 {
+		t.Errorf("expected to be 250 bytes over, got %d", over)
+	}
+
+	var none *pullQuota
+	if none.over() != 0 {
+		t.Error("no quota can't be exceeded")
+	}
+}
+
+func TestStateTrackerQuotaExceeded(t *testing.T) {
+	s := newStateTracker("default", events.NoopLogger)
+
+	s.setState(FolderSyncing)
+	s.setQuotaExceeded(true)
+	if state, _, _ := s.getState(); state != FolderSyncing {
+		t.Errorf("expected syncing to go on, got %v", state)
+	}
+	s.setState(FolderIdle)
+	if state, _, _ := s.getState(); state != FolderQuotaExceeded {
+		t.Errorf("expected quota exceeded instead of idle, got %v", state)
+	}
+	s.setError(errors.New("boom"))
+	s.setError(nil)
+	if state, _, _ := s.getState(); state != FolderQuotaExceeded {
+		t.Errorf("expected quota exceeded after the error cleared, got %v", state)
+	}
+	s.setQuotaExceeded(false)
+	if state, _, _ := s.getState(); state != FolderIdle {
+		t.Errorf("expected idle, got %v", state)
+	}
+}
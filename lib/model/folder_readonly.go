@@ -0,0 +1,85 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+// While the filesystem is read-only, writability is probed this often.
+const readOnlyProbeInterval = time.Minute
+
+var errFolderReadOnly = errors.New("filesystem is read-only")
+
+// readOnlyPullErrors returns the number of pull errors so far due to the
+// filesystem being read-only.
+func (f *sendReceiveFolder) readOnlyPullErrors() int {
+	f.errorsMut.Lock()
+	defer f.errorsMut.Unlock()
+	n := 0
+	for _, fe := range f.tempPullErrors {
+		if fe.Code == ErrCodeReadOnlyFilesystem {
+			n++
+		}
+	}
+	return n
+}
+
+// enterReadOnly quarantines the folder: no pulls or scans are attempted
+// until the filesystem is found to be writable again.
+func (f *folder) enterReadOnly(failed int) {
+	if f.readOnly.Swap(true) {
+		return
+	}
+	f.sl.Warn("Folder filesystem is read-only, syncing is stopped until it is writable again", slog.Int("failed", failed))
+	f.evLogger.Log(events.FolderReadOnly, map[string]interface{}{
+		"folder":   f.folderID,
+		"readOnly": true,
+	})
+}
+
+// checkReadOnly returns an error wrapping errFolderReadOnly while the
+// folder is quarantined and its filesystem still isn't writable.
+func (f *folder) checkReadOnly() error {
+	if !f.readOnly.Load() {
+		return nil
+	}
+	if err := f.probeWritable(); err != nil {
+		return err
+	}
+	f.readOnly.Store(false)
+	f.sl.Info("Folder filesystem is writable again, resuming syncing")
+	f.evLogger.Log(events.FolderReadOnly, map[string]interface{}{
+		"folder":   f.folderID,
+		"readOnly": false,
+	})
+	f.SchedulePull()
+	return nil
+}
+
+// probeWritable creates and removes a temporary file in the folder root.
+// Failures other than the filesystem being read-only are left for the
+// regular operations to report.
+func (f *folder) probeWritable() error {
+	name := fs.TempName(".stwritable")
+	fd, err := f.mtimefs.Create(name)
+	if err != nil {
+		if fileErrorCode(err) == ErrCodeReadOnlyFilesystem {
+			return fmt.Errorf("%w: %w", errFolderReadOnly, err)
+		}
+		return nil
+	}
+	fd.Close()
+	f.mtimefs.Remove(name)
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+type readOnlyFS struct {
+	fs.Filesystem
+}
+
+func (*readOnlyFS) Create(name string) (fs.File, error) {
+	return nil, &os.PathError{Op: "open", Path: name, Err: syscall.EROFS}
+}
+
+func TestFolderReadOnly(t *testing.T) {
+	_, f, wcfgCancel := setupSendReceiveFolder(t)
+	defer wcfgCancel()
+
+	writable := f.mtimefs
+	f.mtimefs = &readOnlyFS{writable}
+
+	f.tempPullErrors["file"] = newFileError("file", "syncing: read-only", syscall.EROFS)
+	if n := f.readOnlyPullErrors(); n != 1 {
+		t.Fatalf("expected one read-only error, got %d", n)
+	}
+	f.enterReadOnly(1)
+
+	err := f.getHealthErrorWithoutIgnores()
+	if !errors.Is(err, errFolderReadOnly) {
+		t.Fatalf("expected read-only error, got %v", err)
+	}
+	f.setError(err)
+	if state, _, _ := f.getState(); state != FolderReadOnly {
+		t.Errorf("expected state %v, got %v", FolderReadOnly, state)
+	}
+
+	// Writable again
+	f.mtimefs = writable
+	if err := f.getHealthErrorWithoutIgnores(); err != nil {
+		t.Fatal(err)
+	}
+	if f.readOnly.Load() {
+		t.Error("folder should have left the read-only state")
+	}
+	f.setError(nil)
+	if state, _, _ := f.getState(); state != FolderIdle {
+		t.Errorf("expected state %v, got %v", FolderIdle, state)
+	}
+	if _, err := f.mtimefs.Lstat(fs.TempName(".stwritable")); !fs.IsNotExist(err) {
+		t.Error("probe file should have been removed")
+	}
+}
@@ -83,6 +83,7 @@ func (f *receiveOnlyFolder) revert() error {
 		handler:  f, // for the deleteItemOnDisk and deleteDirOnDisk methods
 		ignores:  f.ignores,
 		scanChan: scanChan,
+		cause:    versioner.ArchiveInfo{ModifiedBy: f.model.id.String()},
 	}
 
 	batch := NewFileInfoBatch(func(files []protocol.FileInfo) error {
@@ -182,12 +183,13 @@ func (f *receiveOnlyFolder) revert() error {
 // directories for last.
 type deleteQueue struct {
 	handler interface {
-		deleteItemOnDisk(item protocol.FileInfo, scanChan chan<- string) error
+		deleteItemOnDisk(item protocol.FileInfo, cause versioner.ArchiveInfo, scanChan chan<- string) error
 		deleteDirOnDisk(dir string, scanChan chan<- string) error
 	}
 	ignores  *ignore.Matcher
 	dirs     []string
 	scanChan chan<- string
+	cause    versioner.ArchiveInfo // what the files are deleted for
 }
 
 func (q *deleteQueue) handle(fi protocol.FileInfo) (bool, error) {
@@ -204,7 +206,7 @@ func (q *deleteQueue) handle(fi protocol.FileInfo) (bool, error) {
 	}
 
 	// Kill it.
-	err := q.handler.deleteItemOnDisk(fi, q.scanChan)
+	err := q.handler.deleteItemOnDisk(fi, q.cause, q.scanChan)
 	return true, err
 }
 
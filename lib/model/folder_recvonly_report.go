@@ -0,0 +1,107 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Kinds of local changes in a receive only folder, relative to the global
+// version of the item.
+const (
+	LocalChangeAdded    = "added"
+	LocalChangeModified = "modified"
+	LocalChangeDeleted  = "deleted"
+)
+
+// LocalChange describes an item that was changed locally in a receive only
+// folder, i.e. that would be reverted by a revert operation.
+type LocalChange struct {
+	Name       string           `json:"name"`
+	Type       string           `json:"type"`
+	Action     string           `json:"action"`
+	Modified   time.Time        `json:"modified"`
+	ModifiedBy protocol.ShortID `json:"modifiedBy"`
+	Size       int64            `json:"size"`
+	GlobalSize int64            `json:"globalSize"`
+	SizeDelta  int64            `json:"sizeDelta"`
+	Diff       *LocalChangeDiff `json:"diff,omitempty"`
+}
+
+// LocalChangeDiff summarizes how the content of a locally modified file
+// differs from the global version, based on block hashes.
+type LocalChangeDiff struct {
+	Blocks        int   `json:"blocks"`
+	ChangedBlocks int   `json:"changedBlocks"` // local blocks not present in the global version
+	ChangedBytes  int64 `json:"changedBytes"`
+}
+
+// LocalChangeReport returns a page of the locally changed items in a
+// receive only folder, with what kind of change it is compared to the
+// global version. With withDiff set, modified files also get a block level
+// summary of the content changes.
+func (m *model) LocalChangeReport(folder string, page, perpage int, withDiff bool) ([]LocalChange, error) {
+	files, err := m.LocalChangedFolderFiles(folder, page, perpage)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]LocalChange, 0, len(files))
+	for _, f := range files {
+		global, ok, err := m.sdb.GetGlobalFile(folder, f.Name)
+		if err != nil {
+			return nil, err
+		}
+		// Without a valid version elsewhere, the global file is our own
+		// invalid one.
+		hasGlobal := ok && !global.IsDeleted() && !global.IsInvalid()
+
+		change := LocalChange{
+			Name:       f.Name,
+			Type:       f.FileType().String(),
+			Modified:   f.ModTime(),
+			ModifiedBy: f.ModifiedBy,
+			Size:       f.FileSize(),
+		}
+		switch {
+		case f.IsDeleted():
+			change.Action = LocalChangeDeleted
+			change.Size = 0
+		case !hasGlobal:
+			change.Action = LocalChangeAdded
+		default:
+			change.Action = LocalChangeModified
+		}
+		if hasGlobal {
+			change.GlobalSize = global.FileSize()
+		}
+		change.SizeDelta = change.Size - change.GlobalSize
+
+		if withDiff && change.Action == LocalChangeModified && !f.IsDirectory() && !f.IsSymlink() {
+			change.Diff = localChangeDiff(f, global)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func localChangeDiff(local, global protocol.FileInfo) *LocalChangeDiff {
+	globalHashes := make(map[string]struct{}, len(global.Blocks))
+	for _, b := range global.Blocks {
+		globalHashes[string(b.Hash)] = struct{}{}
+	}
+	diff := &LocalChangeDiff{Blocks: len(local.Blocks)}
+	for _, b := range local.Blocks {
+		if _, ok := globalHashes[string(b.Hash)]; !ok {
+			diff.ChangedBlocks++
+			diff.ChangedBytes += int64(b.Size)
+		}
+	}
+	return diff
+}
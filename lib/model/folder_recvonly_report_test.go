@@ -0,0 +1,75 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestRecvOnlyLocalChangeReport(t *testing.T) {
+	m, f, wcfgCancel := setupROFolder(t)
+	defer wcfgCancel()
+	ffs := f.Filesystem()
+	defer cleanupModel(m)
+	conn := addFakeConn(m, device1, f.ID)
+
+	must(t, ffs.MkdirAll(".stfolder", 0o755))
+	oldData := []byte("hello\n")
+	knownFiles := setupKnownFiles(t, ffs, oldData)
+	must(t, m.Index(conn, &protocol.Index{Folder: "ro", Files: knownFiles}))
+	must(t, f.updateLocalsFromScanning(knownFiles))
+	must(t, m.ScanFolder("ro"))
+
+	// Modify the known file and add a new one.
+
+	newData := []byte("totally different data\n")
+	writeFilePerm(t, ffs, "knownDir/knownFile", newData, 0o644)
+	writeFilePerm(t, ffs, "newFile", []byte("new"), 0o644)
+	must(t, m.ScanFolder("ro"))
+
+	changes, err := m.LocalChangeReport("ro", 1, 10, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]LocalChange)
+	for _, c := range changes {
+		byName[c.Name] = c
+	}
+	if len(byName) != 2 {
+		t.Fatalf("expected two changes, got %+v", changes)
+	}
+
+	modified := byName["knownDir/knownFile"]
+	if modified.Action != LocalChangeModified {
+		t.Errorf("expected modified, got %q", modified.Action)
+	}
+	if modified.SizeDelta != int64(len(newData)-len(oldData)) {
+		t.Errorf("unexpected size delta %d", modified.SizeDelta)
+	}
+	if modified.Diff == nil || modified.Diff.ChangedBlocks != 1 || modified.Diff.ChangedBytes != int64(len(newData)) {
+		t.Errorf("unexpected diff %+v", modified.Diff)
+	}
+
+	added := byName["newFile"]
+	if added.Action != LocalChangeAdded || added.GlobalSize != 0 || added.Diff != nil {
+		t.Errorf("unexpected addition %+v", added)
+	}
+
+	// Without diffs requested there are none.
+
+	changes, err = m.LocalChangeReport("ro", 1, 10, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range changes {
+		if c.Diff != nil {
+			t.Errorf("unexpected diff for %s", c.Name)
+		}
+	}
+}
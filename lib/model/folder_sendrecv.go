@@ -22,6 +22,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/syncthing/syncthing/internal/faultinject"
 	"github.com/syncthing/syncthing/internal/itererr"
 	"github.com/syncthing/syncthing/internal/slogutil"
 	"github.com/syncthing/syncthing/lib/build"
@@ -74,6 +75,7 @@ var (
 	errModified               = errors.New("file modified but not rescanned; will try again later")
 	errUnexpectedDirOnFileDel = errors.New("encountered directory when trying to remove file/symlink")
 	errIncompatibleSymlink    = errors.New("incompatible symlink entry; rescan with newer Syncthing on source")
+	errDeleteRedundancy       = errors.New("too few other devices have this version of the file to delete it; will retry later")
 	contextRemovingOldItem    = "removing item to be replaced"
 )
 
@@ -128,8 +130,18 @@ type sendReceiveFolder struct {
 	queue              *jobQueue
 	blockPullReorderer blockPullReorderer
 	writeLimiter       *semaphore.Semaphore
-
-	tempPullErrors map[string]string // pull errors that might be just transient
+	pullFileLimiter    *semaphore.Semaphore // files being pulled, per iteration
+	pullBlockLimiter   *semaphore.Semaphore // block requests outstanding, per iteration
+
+	// Pull limits suited to the filesystem, detected on the first pull
+	fsPullLimitsDetected bool
+	fsPullFiles          int
+	fsPullBlocks         int
+
+	tempPullErrors    map[string]FileError // pull errors that might be just transient
+	deferredHardLinks map[string]struct{}  // hard links waiting for their target, in this pull
+	pipeline          *pullPipeline
+	pullQuota         *pullQuota // usage against the quota, in this iteration
 }
 
 func newSendReceiveFolder(model *model, ignores *ignore.Matcher, cfg config.FolderConfiguration, ver versioner.Versioner, evLogger events.Logger, ioLimiter *semaphore.Semaphore) service {
@@ -138,6 +150,10 @@ func newSendReceiveFolder(model *model, ignores *ignore.Matcher, cfg config.Fold
 		queue:              newJobQueue(),
 		blockPullReorderer: newBlockPullReorderer(cfg.BlockPullOrder, model.id, cfg.DeviceIDs()),
 		writeLimiter:       semaphore.New(cfg.MaxConcurrentWrites),
+		pullFileLimiter:    semaphore.New(0),
+		pullBlockLimiter:   semaphore.New(0),
+		deferredHardLinks:  make(map[string]struct{}),
+		pipeline:           newPullPipeline(cfg.ID),
 	}
 	f.puller = f
 
@@ -175,12 +191,17 @@ func (f *sendReceiveFolder) pull() (bool, error) {
 	defer cancel()
 	go addTimeUntilCancelled(ctx, metricFolderPullSeconds.WithLabelValues(f.ID))
 
+	f.pipeline.reset()
+	go f.detectPullStalls(ctx)
+
 	changed := 0
 
 	f.errorsMut.Lock()
 	f.pullErrors = nil
 	f.errorsMut.Unlock()
 
+	clear(f.deferredHardLinks)
+
 	var err error
 	for tries := range maxPullerIterations {
 		select {
@@ -200,6 +221,11 @@ func (f *sendReceiveFolder) pull() (bool, error) {
 
 		l.Debugln(f, "changed", changed, "on try", tries+1)
 
+		if f.readOnlyPullErrors() > 0 {
+			// Retrying is pointless until the filesystem is writable.
+			break
+		}
+
 		if changed == 0 {
 			// No files were changed by the puller, so we are in
 			// sync (except for unrecoverable stuff like invalid
@@ -208,16 +234,23 @@ func (f *sendReceiveFolder) pull() (bool, error) {
 		}
 	}
 
+	readOnlyErrNum := f.readOnlyPullErrors()
+	if readOnlyErrNum > 0 {
+		f.enterReadOnly(readOnlyErrNum)
+	}
+
+	f.setQuotaExceeded(f.pullQuota.over())
+
 	f.errorsMut.Lock()
 	pullErrNum := len(f.tempPullErrors)
 	if pullErrNum > 0 {
 		f.pullErrors = make([]FileError, 0, len(f.tempPullErrors))
-		for path, err := range f.tempPullErrors {
-			f.sl.Warn("Failed to sync", slogutil.FilePath(path), slogutil.Error(err))
-			f.pullErrors = append(f.pullErrors, FileError{
-				Err:  err,
-				Path: path,
-			})
+		for path, fe := range f.tempPullErrors {
+			if readOnlyErrNum == 0 {
+				// Otherwise the read-only state says it all.
+				f.sl.Warn("Failed to sync", slogutil.FilePath(path), slogutil.Error(fe.Err))
+			}
+			f.pullErrors = append(f.pullErrors, fe)
 		}
 		f.tempPullErrors = nil
 	}
@@ -230,6 +263,10 @@ func (f *sendReceiveFolder) pull() (bool, error) {
 		})
 	}
 
+	if readOnlyErrNum > 0 {
+		return false, errFolderReadOnly
+	}
+
 	return changed == 0, nil
 }
 
@@ -239,9 +276,19 @@ func (f *sendReceiveFolder) pull() (bool, error) {
 // flagged as needed in the folder.
 func (f *sendReceiveFolder) pullerIteration(scanChan chan<- string) (int, error) {
 	f.errorsMut.Lock()
-	f.tempPullErrors = make(map[string]string)
+	f.tempPullErrors = make(map[string]FileError)
 	f.errorsMut.Unlock()
 
+	// Fresh limiters every iteration, so that nothing is left over from
+	// files that failed in unexpected ways.
+	maxFiles, maxBlocks := f.pullLimits()
+	f.pullFileLimiter = semaphore.New(maxFiles)
+	f.pullBlockLimiter = semaphore.New(maxBlocks)
+
+	if err := f.startPullQuota(); err != nil {
+		return 0, err
+	}
+
 	pullChan := make(chan pullBlockState)
 	copyChan := make(chan copyBlocksState)
 	finisherChan := make(chan *sharedPullerState)
@@ -252,7 +299,7 @@ func (f *sendReceiveFolder) pullerIteration(scanChan chan<- string) (int, error)
 	var doneWg sync.WaitGroup
 	var updateWg sync.WaitGroup
 
-	l.Debugln(f, "copiers:", f.Copiers, "pullerPendingKiB:", f.PullerMaxPendingKiB)
+	l.Debugln(f, "copiers:", f.Copiers, "pullerPendingKiB:", f.PullerMaxPendingKiB, "maxFiles:", maxFiles, "maxBlockRequests:", maxBlocks)
 
 	updateWg.Add(1)
 	go func() {
@@ -339,11 +386,18 @@ loop:
 		default:
 		}
 
-		if f.IgnoreDelete && file.IsDeleted() {
+		if f.IgnoresIncomingDeletes() && file.IsDeleted() {
 			l.Debugln(f, "ignore file deletion (config)", file.FileName())
 			continue
 		}
 
+		if f.model.heldByDirTombstone(f.folderID, file) {
+			// A peer announced the directory as deleted; its contents'
+			// deletions are on the way.
+			l.Debugln(f, "skipping item in directory being deleted", file.FileName())
+			continue
+		}
+
 		changed++
 
 		switch {
@@ -367,6 +421,11 @@ loop:
 				changed--
 			}
 
+		case !file.IsDeleted() && f.checkName(file.Name) != nil:
+			// Creating the file would fail, so don't even try.
+			f.newPullError(file.Name, f.checkName(file.Name))
+			changed--
+
 		case file.IsDeleted():
 			switch {
 			case file.IsDirectory():
@@ -409,7 +468,7 @@ loop:
 				f.queue.Push(file.Name, file.Size, file.ModTime())
 			}
 
-		case (build.IsWindows || build.IsAndroid) && file.IsSymlink():
+		case (build.IsWindows || build.IsAndroid || !f.symlinksSupported()) && file.IsSymlink():
 			if err := f.handleSymlinkCheckExisting(file, scanChan); err != nil {
 				f.newPullError(file.Name, fmt.Errorf("handling unsupported symlink: %w", err))
 				break
@@ -478,6 +537,11 @@ nextFile:
 			continue
 		}
 
+		if f.PreserveHardLinks && fi.HardLinkTarget != "" && f.handleHardLink(fi, dbUpdateChan, scanChan) {
+			f.queue.Done(fileName)
+			continue
+		}
+
 		// Check our list of files to be removed for a match, in which case
 		// we can just do a rename instead.
 		key := string(fi.BlocksHash)
@@ -508,6 +572,16 @@ nextFile:
 			continue
 		}
 
+		// Files that would take us over the quota wait until there's
+		// room, without counting as failed.
+		if ok, err := f.admitToQuota(fi); err != nil {
+			return changed, nil, nil, err
+		} else if !ok {
+			changed--
+			f.queue.Done(fileName)
+			continue
+		}
+
 		// Verify we have space to handle the file before we start
 		// creating temp files etc.
 		if err := f.CheckAvailableSpace(uint64(fi.Size)); err != nil { //nolint:gosec
@@ -591,11 +665,18 @@ loop:
 		default:
 		}
 
-		if f.IgnoreDelete && file.IsDeleted() {
+		if f.IgnoresIncomingDeletes() && file.IsDeleted() {
 			l.Debugln(f, "ignore file deletion (config)", file.FileName())
 			continue
 		}
 
+		if f.model.heldByDirTombstone(f.folderID, file) {
+			// A peer announced the directory as deleted; its contents'
+			// deletions are on the way.
+			l.Debugln(f, "skipping item in directory being deleted", file.FileName())
+			continue
+		}
+
 		changed++
 
 		switch {
@@ -619,6 +700,11 @@ loop:
 				changed--
 			}
 
+		case !file.IsDeleted() && f.checkName(file.Name) != nil:
+			// Creating the file would fail, so don't even try.
+			f.newPullError(file.Name, f.checkName(file.Name))
+			changed--
+
 		case file.IsDeleted():
 			switch {
 			case file.IsDirectory():
@@ -661,7 +747,7 @@ loop:
 				f.queue.Push(file.Name, file.Size, file.ModTime())
 			}
 
-		case (build.IsWindows || build.IsAndroid) && file.IsSymlink():
+		case (build.IsWindows || build.IsAndroid || !f.symlinksSupported()) && file.IsSymlink():
 			if err := f.handleSymlinkCheckExisting(file, scanChan); err != nil {
 				f.newPullError(file.Name, fmt.Errorf("handling unsupported symlink: %w", err))
 				break
@@ -729,6 +815,11 @@ nextFile:
 			continue
 		}
 
+		if f.PreserveHardLinks && fi.HardLinkTarget != "" && f.handleHardLink(fi, dbUpdateChan, scanChan) {
+			f.queue.Done(fileName)
+			continue
+		}
+
 		// Check our list of files to be removed for a match, in which case
 		// we can just do a rename instead.
 		key := string(fi.BlocksHash)
@@ -750,6 +841,14 @@ nextFile:
 			continue nextFile
 		}
 
+		if ok, err := f.admitToQuota(fi); err != nil {
+			return changed, nil, nil, err
+		} else if !ok {
+			changed--
+			f.queue.Done(fileName)
+			continue
+		}
+
 		devices := f.model.fileAvailability(f.FolderConfiguration, fi)
 		if len(devices) > 0 {
 			if err := f.handleFile(fi, copyChan); err != nil {
@@ -861,7 +960,7 @@ func (f *sendReceiveFolder) handleDir(file protocol.FileInfo, dbUpdateChan chan<
 				return f.moveForConflict(name, file.ModifiedBy.String(), scanChan)
 			}, curFile.Name)
 		} else {
-			err = f.deleteItemOnDisk(curFile, scanChan)
+			err = f.deleteItemOnDisk(curFile, f.archiveInfo(file), scanChan)
 		}
 		if err != nil {
 			f.newPullError(file.Name, err)
@@ -1059,7 +1158,7 @@ func (f *sendReceiveFolder) handleSymlinkCheckExisting(file protocol.FileInfo, s
 			return f.moveForConflict(name, file.ModifiedBy.String(), scanChan)
 		}, curFile.Name)
 	} else {
-		return f.deleteItemOnDisk(curFile, scanChan)
+		return f.deleteItemOnDisk(curFile, f.archiveInfo(file), scanChan)
 	}
 }
 
@@ -1171,6 +1270,14 @@ func (f *sendReceiveFolder) deleteFileWithCurrent(file, cur protocol.FileInfo, h
 		return
 	}
 
+	// Unless kept as a conflict copy or by the versioner the file would be
+	// gone for good, which requires enough copies elsewhere.
+	lastCopy := !file.InConflictWith(cur) && f.versioner == nil && !cur.IsSymlink()
+	if lastCopy && f.MinDeleteRedundancy > 0 && !f.hasDeleteRedundancy(cur) {
+		err = errDeleteRedundancy
+		return
+	}
+
 	switch {
 	case file.InConflictWith(cur) && !cur.IsSymlink():
 		// If the delete constitutes winning a conflict, we move the file to
@@ -1181,7 +1288,7 @@ func (f *sendReceiveFolder) deleteFileWithCurrent(file, cur protocol.FileInfo, h
 
 	case f.versioner != nil && !cur.IsSymlink():
 		// If we have a versioner, use that to move the file away
-		err = f.inWritableDir(f.versioner.Archive, file.Name)
+		err = f.inWritableDir(f.archiver(f.archiveInfo(file)), file.Name)
 
 	default:
 		// Delete the file
@@ -1206,6 +1313,27 @@ func (f *sendReceiveFolder) deleteFileWithCurrent(file, cur protocol.FileInfo, h
 	slog.Info("Deleted file", f.LogAttr(), file.LogAttr())
 }
 
+// hasDeleteRedundancy returns true if at least MinDeleteRedundancy other
+// devices have the current version of the file, or a newer one, according
+// to their index.
+func (f *sendReceiveFolder) hasDeleteRedundancy(cur protocol.FileInfo) bool {
+	copies := 0
+	for _, dev := range f.DeviceIDs() {
+		if dev == f.model.id {
+			continue
+		}
+		fi, ok, err := f.model.sdb.GetDeviceFile(f.folderID, dev, cur.Name)
+		if err != nil || !ok || fi.IsDeleted() || fi.IsInvalid() || !fi.Version.GreaterEqual(cur.Version) {
+			continue
+		}
+		copies++
+		if copies >= f.MinDeleteRedundancy {
+			return true
+		}
+	}
+	return false
+}
+
 // renameFile attempts to rename an existing file to a destination
 // and set the right attributes on it.
 func (f *sendReceiveFolder) renameFile(cur, source, target protocol.FileInfo, dbUpdateChan chan<- dbUpdateJob, scanChan chan<- string) error {
@@ -1312,7 +1440,7 @@ func (f *sendReceiveFolder) renameFile(cur, source, target protocol.FileInfo, db
 		if err == nil {
 			err = osutil.Copy(f.CopyRangeMethod.ToFS(), f.mtimefs, f.mtimefs, source.Name, tempName)
 			if err == nil {
-				err = f.inWritableDir(f.versioner.Archive, source.Name)
+				err = f.inWritableDir(f.archiver(f.archiveInfo(target)), source.Name)
 			}
 		}
 	} else {
@@ -1424,6 +1552,13 @@ func (f *sendReceiveFolder) handleFile(file protocol.FileInfo, copyChan chan<- c
 		blocks:            blocks,
 		have:              len(have),
 	}
+
+	// Given back by the finisher once the file is done with.
+	if err := f.pullFileLimiter.TakeWithContext(f.ctx, 1); err != nil {
+		return err
+	}
+
+	f.pipeline.queue(stageCopier)
 	copyChan <- cs
 	return nil
 }
@@ -1586,7 +1721,10 @@ func (f *sendReceiveFolder) copierRoutine(in <-chan copyBlocksState, pullChan ch
 		otherFolderFilesystems[folder] = cfg.Filesystem()
 	}
 
+	throttle := osutil.ApplyIOPriority(f.PullIOPriority.Or(f.model.cfg.Options().PullIOPriority).OSPriority())
+
 	for state := range in {
+		t0 := f.pipeline.start(stageCopier, true)
 		if f.Type != config.FolderTypeReceiveEncrypted {
 			f.model.progressEmitter.Register(state.sharedPullerState)
 		}
@@ -1615,7 +1753,10 @@ func (f *sendReceiveFolder) copierRoutine(in <-chan copyBlocksState, pullChan ch
 				continue
 			}
 
-			if f.copyBlock(block, state, otherFolderFilesystems) {
+			t0 := time.Now()
+			copied := f.copyBlock(block, state, otherFolderFilesystems)
+			throttle(time.Since(t0))
+			if copied {
 				state.copyDone(block)
 				continue
 			}
@@ -1629,6 +1770,7 @@ func (f *sendReceiveFolder) copierRoutine(in <-chan copyBlocksState, pullChan ch
 				sharedPullerState: state.sharedPullerState,
 				block:             block,
 			}
+			f.pipeline.queue(stageRequestor)
 			pullChan <- ps
 		}
 		// If there are no blocks to pull/copy, we still need the temporary file in place.
@@ -1639,6 +1781,7 @@ func (f *sendReceiveFolder) copierRoutine(in <-chan copyBlocksState, pullChan ch
 			}
 		}
 
+		f.pipeline.done(stageCopier, t0)
 		out <- state.sharedPullerState
 	}
 }
@@ -1744,6 +1887,11 @@ func (f *sendReceiveFolder) copyBlockFromFile(srcName string, srcOffset int64, s
 		state.fail(fmt.Errorf("dst write: %w", err))
 		return false
 	}
+	// Either way the block now holds what we read and verified into buf.
+	if err := f.verifyWrite(dstFd, state.file.Name, block.Offset, buf); err != nil {
+		state.fail(err)
+		return false
+	}
 	return true
 }
 
@@ -1762,10 +1910,13 @@ func (*sendReceiveFolder) verifyBuffer(buf []byte, block protocol.BlockInfo) err
 
 func (f *sendReceiveFolder) pullerRoutine(in <-chan pullBlockState, out chan<- *sharedPullerState) {
 	requestLimiter := semaphore.New(f.PullerMaxPendingKiB * 1024)
+	blockLimiter := f.pullBlockLimiter
 	var wg sync.WaitGroup
 
 	for state := range in {
+		t0 := f.pipeline.start(stageRequestor, true)
 		if state.failed() != nil {
+			f.pipeline.done(stageRequestor, t0)
 			out <- state.sharedPullerState
 			continue
 		}
@@ -1780,6 +1931,17 @@ func (f *sendReceiveFolder) pullerRoutine(in <-chan pullBlockState, out chan<- *
 
 		if err := requestLimiter.TakeWithContext(f.ctx, bytes); err != nil {
 			state.fail(err)
+			f.pipeline.done(stageRequestor, t0)
+			out <- state.sharedPullerState
+			continue
+		}
+
+		// The blockLimiter additionally limits their number, as configured
+		// for the folder.
+		if err := blockLimiter.TakeWithContext(f.ctx, 1); err != nil {
+			requestLimiter.Give(bytes)
+			state.fail(err)
+			f.pipeline.done(stageRequestor, t0)
 			out <- state.sharedPullerState
 			continue
 		}
@@ -1789,6 +1951,8 @@ func (f *sendReceiveFolder) pullerRoutine(in <-chan pullBlockState, out chan<- *
 		go func() {
 			defer wg.Done()
 			defer requestLimiter.Give(bytes)
+			defer blockLimiter.Give(1)
+			defer f.pipeline.done(stageRequestor, t0)
 
 			f.pullBlock(state, out)
 		}()
@@ -1823,7 +1987,7 @@ func (f *sendReceiveFolder) pullBlock(state pullBlockState, out chan<- *sharedPu
 
 	// Fall back to the original implementation for non-resumable transfers
 	var lastError error
-	candidates := f.model.blockAvailability(f.FolderConfiguration, state.file, state.block)
+	candidates, fallback := f.model.preferIntactDevices(f.model.blockAvailability(f.FolderConfiguration, state.file, state.block))
 loop:
 	for {
 		select {
@@ -1837,6 +2001,11 @@ loop:
 		// feasible device at all, fail the block (and in the long run, the
 		// file).
 		found := activity.leastBusy(candidates)
+		if found == -1 && len(fallback) > 0 {
+			// Only devices flagged for corrupting data are left.
+			candidates, fallback = fallback, nil
+			found = activity.leastBusy(candidates)
+		}
 		if found == -1 {
 			if lastError != nil {
 				state.fail(fmt.Errorf("pull: %w", lastError))
@@ -1870,6 +2039,7 @@ loop:
 		// will verify.)
 		if f.Type != config.FolderTypeReceiveEncrypted {
 			lastError = f.verifyBuffer(buf, state.block)
+			f.model.recordBlockVerification(selected.ID, f.folderID, state.file.Name, state.block.Offset, lastError)
 		}
 		if lastError != nil {
 			l.Debugln("request:", f.folderID, state.file.Name, state.block.Offset, state.block.Size, "hash mismatch")
@@ -1878,6 +2048,9 @@ loop:
 
 		// Save the block data we got from the cluster
 		err = f.limitedWriteAt(fd, buf, state.block.Offset)
+		if err == nil {
+			err = f.verifyWrite(fd, state.file.Name, state.block.Offset, buf)
+		}
 		if err != nil {
 			state.fail(fmt.Errorf("save: %w", err))
 		} else {
@@ -1958,6 +2131,9 @@ func (f *sendReceiveFolder) pullBlockResumable(state pullBlockState, fd *lockedW
 
 			// Save the chunk data to the temporary file
 			err := f.limitedWriteAt(fd, buf, chunkOffset)
+			if err == nil {
+				err = f.verifyWrite(fd, state.file.Name, chunkOffset, buf)
+			}
 			if err != nil {
 				state.fail(fmt.Errorf("save chunk: %w", err))
 				out <- state.sharedPullerState
@@ -2024,7 +2200,7 @@ func (f *sendReceiveFolder) performFinish(file, curFile protocol.FileInfo, hasCu
 				return f.moveForConflict(name, file.ModifiedBy.String(), scanChan)
 			}, curFile.Name)
 		} else {
-			err = f.deleteItemOnDisk(curFile, scanChan)
+			err = f.deleteItemOnDisk(curFile, f.archiveInfo(file), scanChan)
 		}
 		if err != nil {
 			return fmt.Errorf("moving for conflict: %w", err)
@@ -2048,14 +2224,20 @@ func (f *sendReceiveFolder) performFinish(file, curFile protocol.FileInfo, hasCu
 }
 
 func (f *sendReceiveFolder) finisherRoutine(in <-chan *sharedPullerState, dbUpdateChan chan<- dbUpdateJob, scanChan chan<- string) {
+	throttle := osutil.ApplyIOPriority(f.PullIOPriority.Or(f.model.cfg.Options().PullIOPriority).OSPriority())
+
 	for state := range in {
+		t0 := f.pipeline.start(stageFinisher, false)
 		if closed, err := state.finalClose(); closed {
 			l.Debugln(f, "closing", state.file.Name)
 
 			f.queue.Done(state.file.Name)
+			f.pullFileLimiter.Give(1)
 
 			if err == nil {
+				t0 := time.Now()
 				err = f.performFinish(state.file, state.curFile, state.hasCurFile, state.tempName, dbUpdateChan, scanChan)
+				throttle(time.Since(t0))
 			}
 
 			if err != nil {
@@ -2076,7 +2258,11 @@ func (f *sendReceiveFolder) finisherRoutine(in <-chan *sharedPullerState, dbUpda
 			}
 
 			if f.Type != config.FolderTypeReceiveEncrypted {
-				f.model.progressEmitter.Deregister(state)
+				if state.failed() != nil {
+					f.model.progressEmitter.Interrupted(state)
+				} else {
+					f.model.progressEmitter.Deregister(state)
+				}
 			}
 
 			f.evLogger.Log(events.ItemFinished, map[string]interface{}{
@@ -2087,6 +2273,7 @@ func (f *sendReceiveFolder) finisherRoutine(in <-chan *sharedPullerState, dbUpda
 				"action": "update",
 			})
 		}
+		f.pipeline.done(stageFinisher, t0)
 	}
 }
 
@@ -2215,6 +2402,7 @@ func (f *sendReceiveFolder) moveForConflict(name, lastModBy string, scanChan cha
 		if err := f.mtimefs.Remove(name); err != nil && !fs.IsNotExist(err) {
 			return fmt.Errorf("%s: %w", contextRemovingOldItem, err)
 		}
+		f.model.conflicts.remove(f.folderID, name)
 		return nil
 	}
 
@@ -2234,6 +2422,8 @@ func (f *sendReceiveFolder) moveForConflict(name, lastModBy string, scanChan cha
 		// remote modification and a local delete. In either way it does not
 		// matter, go ahead as if the move succeeded.
 		err = nil
+	} else if err == nil {
+		f.recordConflict(name, newName)
 	}
 	if f.MaxConflicts > -1 {
 		matches := existingConflicts(name, f.mtimefs)
@@ -2244,7 +2434,9 @@ func (f *sendReceiveFolder) moveForConflict(name, lastModBy string, scanChan cha
 			for _, match := range matches[f.MaxConflicts:] {
 				if gerr := f.mtimefs.Remove(match); gerr != nil {
 					l.Debugln(f, "removing extra conflict", gerr)
+					continue
 				}
+				f.model.conflicts.remove(f.folderID, match)
 			}
 		}
 	}
@@ -2274,13 +2466,14 @@ func (f *sendReceiveFolder) newPullError(path string, err error) {
 	// Use "syncing" as opposed to "pulling" as the latter might be used
 	// for errors occurring specifically in the puller routine.
 	errStr := fmt.Sprintf("syncing: %s", err)
-	f.tempPullErrors[path] = errStr
+	f.tempPullErrors[path] = newFileError(path, errStr, err)
 
 	l.Debugf("%v new error for %v: %v", f, path, err)
 }
 
-// deleteItemOnDisk deletes the file represented by old that is about to be replaced by new.
-func (f *sendReceiveFolder) deleteItemOnDisk(item protocol.FileInfo, scanChan chan<- string) (err error) {
+// deleteItemOnDisk deletes the file represented by old that is about to be
+// replaced by new, as described by cause.
+func (f *sendReceiveFolder) deleteItemOnDisk(item protocol.FileInfo, cause versioner.ArchiveInfo, scanChan chan<- string) (err error) {
 	defer func() {
 		if err != nil {
 			err = fmt.Errorf("%s: %w", contextRemovingOldItem, err)
@@ -2299,7 +2492,7 @@ func (f *sendReceiveFolder) deleteItemOnDisk(item protocol.FileInfo, scanChan ch
 		// an error.
 		// Symlinks aren't archived.
 
-		return f.inWritableDir(f.versioner.Archive, item.Name)
+		return f.inWritableDir(f.archiver(cause), item.Name)
 	}
 
 	return f.inWritableDir(f.mtimefs.Remove, item.Name)
@@ -2319,6 +2512,7 @@ func (f *sendReceiveFolder) deleteDirOnDisk(dir string, scanChan chan<- string)
 	err := f.inWritableDir(f.mtimefs.Remove, dir)
 	if err == nil || fs.IsNotExist(err) {
 		// It was removed or it doesn't exist to start with
+		f.ignores.ForgetDir(dir)
 		return nil
 	}
 	if _, serr := f.mtimefs.Lstat(dir); serr != nil && !fs.IsPermission(serr) {
@@ -2547,6 +2741,11 @@ func (f *sendReceiveFolder) inWritableDir(fn func(string) error, path string) er
 
 func (f *sendReceiveFolder) limitedWriteAt(fd io.WriterAt, data []byte, offset int64) error {
 	return f.withLimiter(func() error {
+		t0 := f.pipeline.start(stageWriter, false)
+		defer f.pipeline.done(stageWriter, t0)
+		if err := faultinject.Check(faultinject.DiskWrite, f.folderID); err != nil {
+			return err
+		}
 		_, err := fd.WriteAt(data, offset)
 		return err
 	})
@@ -2578,8 +2777,20 @@ func (f *sendReceiveFolder) updateFileInfoChangeTime(file *protocol.FileInfo) er
 
 // A []FileError is sent as part of an event and will be JSON serialized.
 type FileError struct {
-	Path string `json:"path"`
-	Err  string `json:"error"`
+	Path        string `json:"path"`
+	Err         string `json:"error"`
+	Code        string `json:"code"`                  // one of the ErrCode* constants
+	Remediation string `json:"remediation,omitempty"` // suggested fix, if any
+}
+
+func newFileError(path, errStr string, err error) FileError {
+	code, remediation := classifyFileError(err)
+	return FileError{
+		Path:        path,
+		Err:         errStr,
+		Code:        code,
+		Remediation: remediation,
+	}
 }
 
 func conflictName(name, lastModBy string) string {
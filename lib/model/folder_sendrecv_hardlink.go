@@ -0,0 +1,86 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// handleHardLink creates the file as a hard link to its hard link target,
+// when that has the right contents locally. If the target is itself about
+// to be pulled, the file is left for the next puller iteration, once per
+// pull. It returns true if the file was handled, false if it should be
+// pulled as usual.
+func (f *sendReceiveFolder) handleHardLink(file protocol.FileInfo, dbUpdateChan chan<- dbUpdateJob, scanChan chan<- string) bool {
+	target, ok, err := f.model.sdb.GetDeviceFile(f.folderID, protocol.LocalDeviceID, file.HardLinkTarget)
+	if err != nil {
+		return false
+	}
+	if !ok || target.IsDeleted() || target.IsInvalid() || target.Type != protocol.FileInfoTypeFile || !target.BlocksEqual(file) {
+		global, ok, err := f.model.sdb.GetGlobalFile(f.folderID, file.HardLinkTarget)
+		if err != nil || !ok || !global.BlocksEqual(file) {
+			return false
+		}
+		if _, deferred := f.deferredHardLinks[file.Name]; deferred {
+			return false
+		}
+		l.Debugln(f, "deferring hard link", file.Name, "until its target is pulled")
+		f.deferredHardLinks[file.Name] = struct{}{}
+		return true
+	}
+
+	f.evLogger.Log(events.ItemStarted, map[string]string{
+		"folder": f.folderID,
+		"item":   file.Name,
+		"type":   "file",
+		"action": "update",
+	})
+
+	err = f.linkFile(file, scanChan, dbUpdateChan)
+
+	f.evLogger.Log(events.ItemFinished, map[string]interface{}{
+		"folder": f.folderID,
+		"item":   file.Name,
+		"error":  events.Error(err),
+		"type":   "file",
+		"action": "update",
+	})
+
+	if err != nil {
+		// Copying the data is still an option.
+		l.Debugln(f, "hard linking", file.Name, "failed:", err)
+		return false
+	}
+	slog.Info("Created hard link", f.LogAttr(), file.LogAttr(), slogutil.FilePath(file.HardLinkTarget))
+	return true
+}
+
+func (f *sendReceiveFolder) linkFile(file protocol.FileInfo, scanChan chan<- string, dbUpdateChan chan<- dbUpdateJob) error {
+	curFile, hasCurFile, err := f.model.sdb.GetDeviceFile(f.folderID, protocol.LocalDeviceID, file.Name)
+	if err != nil {
+		return err
+	}
+
+	tempName := fs.TempName(file.Name)
+	if err := f.mtimefs.Remove(tempName); err != nil && !fs.IsNotExist(err) {
+		return err
+	}
+	if err := f.mtimefs.CreateHardLink(file.HardLinkTarget, tempName); err != nil {
+		return fmt.Errorf("creating hard link: %w", err)
+	}
+	if err := f.performFinish(file, curFile, hasCurFile, tempName, dbUpdateChan, scanChan); err != nil {
+		f.mtimefs.Remove(tempName)
+		return err
+	}
+	return nil
+}
@@ -118,7 +118,7 @@ func setupSendReceiveFolder(t testing.TB, files ...protocol.FileInfo) (*testMode
 	<-model.stopped
 	r, _ := model.folderRunners.Get(fcfg.ID)
 	f := r.(*sendReceiveFolder)
-	f.tempPullErrors = make(map[string]string)
+	f.tempPullErrors = make(map[string]FileError)
 	f.ctx = context.Background()
 
 	// Update index
@@ -894,6 +894,53 @@ func TestDeleteBehindSymlink(t *testing.T) {
 	}
 }
 
+func TestDeleteFileRedundancy(t *testing.T) {
+	m, f, wcfgCancel := setupSendReceiveFolder(t)
+	defer wcfgCancel()
+	ffs := f.Filesystem()
+	f.MinDeleteRedundancy = 1
+	f.Devices = append(f.Devices, config.FolderDeviceConfiguration{DeviceID: device2})
+
+	cur := createEmptyFileInfo(t, "file", ffs)
+	cur.Version = cur.Version.Update(myID.Short())
+	f.updateLocalsFromScanning([]protocol.FileInfo{cur})
+
+	fi := cur
+	fi.Deleted = true
+	fi.Version = fi.Version.Update(device1.Short())
+	scanChan := make(chan string, 1)
+	dbUpdateChan := make(chan dbUpdateJob, 1)
+
+	// Only we have the file, the deletion has to wait.
+	f.deleteFile(fi, dbUpdateChan, scanChan)
+	if _, err := ffs.Stat("file"); err != nil {
+		t.Fatal("file should not have been deleted:", err)
+	}
+	if _, ok := f.tempPullErrors["file"]; !ok {
+		t.Error("expected a pull error for the postponed deletion")
+	}
+	select {
+	case u := <-dbUpdateChan:
+		t.Fatalf("unexpected db update %v", u)
+	default:
+	}
+
+	// Once another device has our version it can be deleted.
+	must(t, m.sdb.Update(f.folderID, device2, []protocol.FileInfo{cur}))
+	f.deleteFile(fi, dbUpdateChan, scanChan)
+	if _, err := ffs.Stat("file"); !fs.IsNotExist(err) {
+		t.Error("file should have been deleted:", err)
+	}
+	select {
+	case u := <-dbUpdateChan:
+		if u.jobType != dbUpdateDeleteFile {
+			t.Errorf("Expected jobType %v, got %v", dbUpdateDeleteFile, u.jobType)
+		}
+	default:
+		t.Fatal("No db update received")
+	}
+}
+
 // Reproduces https://github.com/syncthing/syncthing/issues/6559
 func TestPullCtxCancel(t *testing.T) {
 	_, f, wcfgCancel := setupSendReceiveFolder(t)
@@ -1079,10 +1126,12 @@ func testPullCaseOnlyDirOrSymlink(t *testing.T, dir bool) {
 		t.Error("no need to scan anything here")
 	default:
 	}
-	if errStr, ok := f.tempPullErrors[remote.Name]; !ok {
+	if fe, ok := f.tempPullErrors[remote.Name]; !ok {
 		t.Error("missing error for", remote.Name)
-	} else if !strings.Contains(errStr, "uses different upper or lowercase") {
-		t.Error("unexpected error", errStr, "for", remote.Name)
+	} else if !strings.Contains(fe.Err, "uses different upper or lowercase") {
+		t.Error("unexpected error", fe.Err, "for", remote.Name)
+	} else if fe.Code != ErrCodeCaseConflict {
+		t.Error("unexpected error code", fe.Code, "for", remote.Name)
 	}
 }
 
@@ -28,6 +28,7 @@ import (
 type FolderSummaryService interface {
 	suture.Service
 	Summary(folder string) (*FolderSummary, error)
+	Summaries(ctx context.Context) (*FolderSummarySnapshot, error)
 }
 
 // The folderSummaryService adds summary information events (FolderSummary and
@@ -44,6 +45,25 @@ type folderSummaryService struct {
 	// For keeping track of folders to recalculate for
 	foldersMut sync.Mutex
 	folders    map[string]struct{}
+	// The ID of the last event we have processed
+	lastEventID int
+
+	cacheMut sync.Mutex
+	cache    map[string]cachedFolderSummary
+}
+
+type cachedFolderSummary struct {
+	summary *FolderSummary
+	eventID int // the summary reflects all events up to this one
+}
+
+// FolderSummarySnapshot holds the summaries of all folders. Each summary
+// reflects at least all events up to EventID, so that following the event
+// stream from there on, including the FolderSummary events for summaries
+// that are due for recalculation, keeps the snapshot current.
+type FolderSummarySnapshot struct {
+	EventID int                       `json:"eventID"`
+	Folders map[string]*FolderSummary `json:"folders"`
 }
 
 func NewFolderSummaryService(cfg config.Wrapper, m Model, id protocol.DeviceID, evLogger events.Logger) FolderSummaryService {
@@ -55,6 +75,7 @@ func NewFolderSummaryService(cfg config.Wrapper, m Model, id protocol.DeviceID,
 		evLogger:   evLogger,
 		immediate:  make(chan string),
 		folders:    make(map[string]struct{}),
+		cache:      make(map[string]cachedFolderSummary),
 	}
 
 	service.Add(svcutil.AsService(service.listenForUpdates, fmt.Sprintf("%s/listenForUpdates", service)))
@@ -110,15 +131,118 @@ type FolderSummary struct {
 	StateChanged time.Time `json:"stateChanged"`
 	Error        string    `json:"error"`
 
+	// What the folder waits its turn at among other folders, and where in
+	// the queue it is, when in a waiting state.
+	QueuedFor     string `json:"queuedFor,omitempty"`
+	QueuePosition int    `json:"queuePosition,omitempty"`
+	// The folder it waits for to be synced before pulling, if any.
+	WaitingFor string `json:"waitingFor,omitempty"`
+	// By how many bytes pulling everything would take the folder over its
+	// quota, when it's refusing data.
+	QuotaExceededBy int64 `json:"quotaExceededBy,omitempty"`
+	// What verifying writes cost and found, when the folder does.
+	WriteVerification *WriteVerificationStats `json:"writeVerification,omitempty"`
+
 	Version        int64                       `json:"version"` // deprecated
 	Sequence       int64                       `json:"sequence"`
 	RemoteSequence map[protocol.DeviceID]int64 `json:"remoteSequence"`
 
 	IgnorePatterns bool   `json:"ignorePatterns"`
 	WatchError     string `json:"watchError"`
+
+	// The interval between periodic full scans currently in effect
+	RescanIntervalS int `json:"rescanIntervalS"`
 }
 
 func (c *folderSummaryService) Summary(folder string) (*FolderSummary, error) {
+	c.foldersMut.Lock()
+	eventID := c.lastEventID
+	c.foldersMut.Unlock()
+
+	res, err := c.summary(folder)
+	if err != nil {
+		return nil, err
+	}
+
+	c.cacheMut.Lock()
+	if _, ok := c.cfg.Folder(folder); ok {
+		if cached, ok := c.cache[folder]; !ok || cached.eventID <= eventID {
+			c.cache[folder] = cachedFolderSummary{summary: res, eventID: eventID}
+		}
+	}
+	c.cacheMut.Unlock()
+
+	return res, nil
+}
+
+// Summaries returns the summaries of all folders, from the cache. Cached
+// summaries that are stale are recalculated by the service in due course,
+// as for the FolderSummary events, and the snapshot's EventID is that of
+// the oldest summary. Only folders that have no summary yet get one now.
+func (c *folderSummaryService) Summaries(ctx context.Context) (*FolderSummarySnapshot, error) {
+	c.foldersMut.Lock()
+	eventID := c.lastEventID
+	c.foldersMut.Unlock()
+
+	folders := c.cfg.Folders()
+	snap := &FolderSummarySnapshot{
+		EventID: eventID,
+		Folders: make(map[string]*FolderSummary, len(folders)),
+	}
+	var missing []string
+	c.cacheMut.Lock()
+	for folder := range folders {
+		cached, ok := c.cache[folder]
+		if !ok {
+			missing = append(missing, folder)
+			continue
+		}
+		snap.Folders[folder] = c.withCurrentState(folder, cached.summary)
+		snap.EventID = min(snap.EventID, cached.eventID)
+	}
+	c.cacheMut.Unlock()
+
+	for _, folder := range missing {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		sum, err := c.Summary(folder)
+		if err != nil {
+			l.Debugf("Summary for folder %s: %v", folder, err)
+			continue
+		}
+		snap.Folders[folder] = sum
+	}
+	return snap, nil
+}
+
+// writeVerification returns the write verification stats of the folder,
+// or nil if it hasn't verified any writes.
+func (c *folderSummaryService) writeVerification(folder string) *WriteVerificationStats {
+	if wv, ok := c.model.FolderWriteVerification(folder); ok {
+		return &wv
+	}
+	return nil
+}
+
+// withCurrentState returns a copy of the cached summary, updated with the
+// current folder state which changes without making the rest stale.
+func (c *folderSummaryService) withCurrentState(folder string, cached *FolderSummary) *FolderSummary {
+	res := *cached
+	var err error
+	res.State, res.StateChanged, err = c.model.State(folder)
+	res.Error = ""
+	if err != nil {
+		res.Error = err.Error()
+	}
+	res.QueuedFor, res.QueuePosition = c.model.FolderQueuePosition(folder)
+	res.WaitingFor = c.model.FolderDependencyWait(folder)
+	res.QuotaExceededBy = c.model.FolderQuotaExceeded(folder)
+	res.WriteVerification = c.writeVerification(folder)
+	return &res
+}
+
+func (c *folderSummaryService) summary(folder string) (*FolderSummary, error) {
 	res := new(FolderSummary)
 
 	var local, global, need, ro db.Counts
@@ -151,7 +275,7 @@ func (c *folderSummaryService) Summary(folder string) (*FolderSummary, error) {
 
 	fcfg, haveFcfg := c.cfg.Folder(folder)
 
-	if haveFcfg && fcfg.IgnoreDelete {
+	if haveFcfg && fcfg.IgnoresIncomingDeletes() {
 		need.Deleted = 0
 	}
 
@@ -180,6 +304,10 @@ func (c *folderSummaryService) Summary(folder string) (*FolderSummary, error) {
 	if err != nil {
 		res.Error = err.Error()
 	}
+	res.QueuedFor, res.QueuePosition = c.model.FolderQueuePosition(folder)
+	res.WaitingFor = c.model.FolderDependencyWait(folder)
+	res.QuotaExceededBy = c.model.FolderQuotaExceeded(folder)
+	res.WriteVerification = c.writeVerification(folder)
 
 	res.Version = ourSeq // legacy
 	res.Sequence = ourSeq
@@ -199,6 +327,8 @@ func (c *folderSummaryService) Summary(folder string) (*FolderSummary, error) {
 		res.WatchError = err.Error()
 	}
 
+	res.RescanIntervalS = int(c.model.RescanInterval(folder) / time.Second)
+
 	return res, nil
 }
 
@@ -208,6 +338,9 @@ func (c *folderSummaryService) listenForUpdates(ctx context.Context) error {
 	sub := c.evLogger.Subscribe(events.LocalIndexUpdated | events.RemoteIndexUpdated | events.StateChanged | events.RemoteDownloadProgress | events.DeviceConnected | events.ClusterConfigReceived | events.FolderWatchStateChanged | events.DownloadProgress)
 	defer sub.Unsubscribe()
 
+	c.cfg.Subscribe(c)
+	defer c.cfg.Unsubscribe(c)
+
 	for {
 		// This loop needs to be fast so we don't miss too many events.
 
@@ -218,6 +351,9 @@ func (c *folderSummaryService) listenForUpdates(ctx context.Context) error {
 				return ctx.Err()
 			}
 			c.processUpdate(ev)
+			c.foldersMut.Lock()
+			c.lastEventID = ev.GlobalID
+			c.foldersMut.Unlock()
 		case <-ctx.Done():
 			return ctx.Err()
 		}
@@ -338,6 +474,23 @@ func (c *folderSummaryService) calculateSummaries(ctx context.Context) error {
 	}
 }
 
+// CommitConfiguration implements the config.Committer interface, forgetting
+// about removed folders.
+func (c *folderSummaryService) CommitConfiguration(from, to config.Configuration) bool {
+	toFolders := to.FolderMap()
+	c.foldersMut.Lock()
+	c.cacheMut.Lock()
+	for _, folder := range from.Folders {
+		if _, ok := toFolders[folder.ID]; !ok {
+			delete(c.folders, folder.ID)
+			delete(c.cache, folder.ID)
+		}
+	}
+	c.cacheMut.Unlock()
+	c.foldersMut.Unlock()
+	return true
+}
+
 // foldersToHandle returns the list of folders needing a summary update, and
 // clears the list.
 func (c *folderSummaryService) foldersToHandle() []string {
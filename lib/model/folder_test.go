@@ -7,12 +7,14 @@
 package model
 
 import (
+	"errors"
 	"path/filepath"
 	"slices"
 	"testing"
 
 	"github.com/d4l3k/messagediff"
 
+	"github.com/syncthing/syncthing/internal/faultinject"
 	"github.com/syncthing/syncthing/lib/build"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/fs"
@@ -183,3 +185,31 @@ func TestSetPlatformData(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestUpdateLocalsInjectedDBError(t *testing.T) {
+	if !faultinject.Enabled() {
+		t.Skip("built without the faultinject tag")
+	}
+	m, f, wcfgCancel := setupROFolder(t)
+	defer wcfgCancel()
+	defer cleanupModel(m)
+	defer faultinject.Set(faultinject.DBWrite, faultinject.Fault{Key: f.folderID, Count: 1})()
+
+	file := protocol.FileInfo{Name: "file", Version: protocol.Vector{}.Update(myID.Short())}
+	if err := f.updateLocals([]protocol.FileInfo{file}); !errors.Is(err, faultinject.ErrInjected) {
+		t.Fatalf("expected injected error, got %v", err)
+	}
+	if _, ok, err := m.sdb.GetDeviceFile(f.folderID, protocol.LocalDeviceID, file.Name); err != nil {
+		t.Fatal(err)
+	} else if ok {
+		t.Fatal("file was written to the database despite the error")
+	}
+
+	// The fault is spent, so the retry succeeds.
+	must(t, f.updateLocals([]protocol.FileInfo{file}))
+	if _, ok, err := m.sdb.GetDeviceFile(f.folderID, protocol.LocalDeviceID, file.Name); err != nil {
+		t.Fatal(err)
+	} else if !ok {
+		t.Fatal("file missing from the database")
+	}
+}
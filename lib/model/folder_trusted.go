@@ -0,0 +1,146 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"hash/fnv"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gobwas/glob"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+)
+
+// The most buckets verification is spread over, i.e. the least share of
+// the trusted files verified per scan.
+const maxTrustedBuckets = 1 << 16
+
+// trustedFiles implements scanner.ContentClassifier for folders with
+// trusted patterns. Verification rotates over the trusted files by
+// spreading them over buckets by name, one bucket per full scan. Whenever
+// a bucket exceeds the quota the buckets are made smaller, so that each
+// file gets its turn eventually.
+type trustedFiles struct {
+	patterns []glob.Glob
+	baseOnly []bool // pattern matches the file name rather than the path
+	quota    int64  // bytes per full scan, zero for no verification
+
+	mut       sync.Mutex
+	buckets   uint32
+	bucket    uint32
+	left      int64
+	exceeded  bool
+	active    bool                // a full scan is in progress
+	verifying map[string]struct{} // picked for verification in this scan
+}
+
+// newTrustedFiles returns the classifier for the patterns, or nil if there
+// are none. Patterns without a slash match the file name in any directory,
+// others the path from the folder root; both case insensitively.
+func newTrustedFiles(patterns []string, verifyMiB int) *trustedFiles {
+	t := &trustedFiles{
+		quota:   int64(verifyMiB) << 20,
+		buckets: 1,
+	}
+	for _, p := range patterns {
+		p = strings.ToLower(strings.Trim(filepath.ToSlash(p), "/"))
+		if p == "" {
+			continue
+		}
+		g, err := glob.Compile(p, '/')
+		if err != nil {
+			slog.Warn("Skipping invalid trusted pattern", slog.String("pattern", p), slogutil.Error(err))
+			continue
+		}
+		t.patterns = append(t.patterns, g)
+		t.baseOnly = append(t.baseOnly, !strings.Contains(p, "/"))
+	}
+	if len(t.patterns) == 0 {
+		return nil
+	}
+	return t
+}
+
+// Implements scanner.ContentClassifier
+func (t *trustedFiles) TrustModTime(name string) bool {
+	name = strings.ToLower(filepath.ToSlash(name))
+	base := name[strings.LastIndexByte(name, '/')+1:]
+	for i, g := range t.patterns {
+		if t.baseOnly[i] && g.Match(base) || !t.baseOnly[i] && g.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Implements scanner.ContentClassifier
+func (t *trustedFiles) Verify(name string, size int64) bool {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	if !t.active || t.bucketOf(name) != t.bucket {
+		return false
+	}
+	if t.left <= 0 {
+		t.exceeded = true
+		return false
+	}
+	t.left -= size
+	t.verifying[name] = struct{}{}
+	return true
+}
+
+// verified returns true if the file was picked for verification in the
+// current scan.
+func (t *trustedFiles) verified(name string) bool {
+	if t == nil {
+		return false
+	}
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	_, ok := t.verifying[name]
+	return ok
+}
+
+// startScan readies verification for a scan; only full scans verify.
+func (t *trustedFiles) startScan(full bool) {
+	if t == nil {
+		return
+	}
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	t.active = full && t.quota > 0
+	t.left = t.quota
+	t.exceeded = false
+	t.verifying = make(map[string]struct{})
+}
+
+// finishScan moves on to the next bucket if the scan completed verifying
+// the current one, or makes the buckets smaller if it didn't fit.
+func (t *trustedFiles) finishScan(completed bool) {
+	if t == nil {
+		return
+	}
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	switch {
+	case !t.active || !completed:
+	case !t.exceeded:
+		t.bucket = (t.bucket + 1) % t.buckets
+	case t.buckets < maxTrustedBuckets:
+		t.buckets *= 2
+	}
+	t.active = false
+}
+
+func (t *trustedFiles) bucketOf(name string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return h.Sum32() % t.buckets
+}
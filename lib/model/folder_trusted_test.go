@@ -0,0 +1,65 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestTrustedFilesPatterns(t *testing.T) {
+	if newTrustedFiles([]string{"", "/"}, 1) != nil {
+		t.Fatal("expected no classifier without patterns")
+	}
+
+	tf := newTrustedFiles([]string{"*.RAW", "media/*.mkv"}, 1)
+	cases := map[string]bool{
+		"a.raw":                                  true,
+		filepath.Join("photos", "2024", "b.raw"): true,
+		filepath.Join("media", "film.mkv"):       true,
+		filepath.Join("other", "film.mkv"):       false,
+		"notes.txt":                              false,
+	}
+	for name, trusted := range cases {
+		if tf.TrustModTime(name) != trusted {
+			t.Errorf("TrustModTime(%q) != %v", name, trusted)
+		}
+	}
+}
+
+func TestTrustedFilesVerifyRotation(t *testing.T) {
+	tf := newTrustedFiles([]string{"*"}, 1)
+
+	// Partial scans never verify.
+	tf.startScan(false)
+	if tf.Verify("a", 1) {
+		t.Error("partial scan should not verify")
+	}
+	tf.finishScan(true)
+
+	// With one bucket everything is verified until the quota runs out,
+	// after which the buckets are made smaller.
+	tf.startScan(true)
+	if !tf.Verify("a", 1<<20) || !tf.verified("a") {
+		t.Error("expected first file to be verified")
+	}
+	if tf.Verify("b", 1) || tf.verified("b") {
+		t.Error("expected quota to be exhausted")
+	}
+	tf.finishScan(true)
+	if tf.buckets != 2 {
+		t.Fatalf("expected two buckets, got %d", tf.buckets)
+	}
+
+	// A scan that fits moves on to the next bucket.
+	bucket := tf.bucket
+	tf.startScan(true)
+	tf.finishScan(true)
+	if tf.bucket == bucket {
+		t.Error("expected to move on to the next bucket")
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// viewFileSource implements scanner.FileSource for folders that are views
+// of other folders: a file is only part of the view when its source folder
+// has it in the index, i.e. not ignored there, and its blocks are taken
+// from that index instead of hashing the data again.
+type viewFileSource struct {
+	db      db.DB
+	sources []viewSource
+}
+
+type viewSource struct {
+	viewPath string // native, in the view
+	folder   string
+	path     string // native, in the source folder
+}
+
+func newViewFileSource(sdb db.DB, sources []config.FolderViewSource) viewFileSource {
+	vfs := viewFileSource{db: sdb}
+	for _, src := range sources {
+		vfs.sources = append(vfs.sources, viewSource{
+			viewPath: filepath.FromSlash(src.ViewPath()),
+			folder:   src.Folder,
+			path:     filepath.Clean(filepath.FromSlash(src.Path)),
+		})
+	}
+	return vfs
+}
+
+// Implements scanner.FileSource
+func (vfs viewFileSource) SourceFile(name string) (protocol.FileInfo, bool) {
+	for _, src := range vfs.sources {
+		var rel string
+		switch {
+		case name == src.viewPath:
+		case strings.HasPrefix(name, src.viewPath+string(filepath.Separator)):
+			rel = name[len(src.viewPath)+1:]
+		default:
+			continue
+		}
+		fi, ok, err := vfs.db.GetDeviceFile(src.folder, protocol.LocalDeviceID, filepath.Join(src.path, rel))
+		if err != nil || !ok || fi.IsDeleted() || fi.IsInvalid() {
+			return protocol.FileInfo{}, false
+		}
+		return fi, true
+	}
+	return protocol.FileInfo{}, false
+}
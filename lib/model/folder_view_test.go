@@ -0,0 +1,44 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestViewFileSource(t *testing.T) {
+	version := protocol.Vector{Counters: []protocol.Counter{{ID: 42, Value: 1}}}
+	m, f, wcfgCancel := setupSendReceiveFolder(t,
+		protocol.FileInfo{Name: filepath.Join("2024", "a"), Size: 1, Version: version},
+		protocol.FileInfo{Name: filepath.Join("2024", "deleted"), Deleted: true, Version: version},
+		protocol.FileInfo{Name: filepath.Join("2024", "ignored"), LocalFlags: protocol.FlagLocalIgnored, Version: version},
+		protocol.FileInfo{Name: "other", Size: 1, Version: version},
+	)
+	defer wcfgCancel()
+
+	src := newViewFileSource(m.sdb, []config.FolderViewSource{
+		{Folder: f.ID, Path: "2024", As: "Photos/2024"},
+	})
+
+	if fi, ok := src.SourceFile(filepath.Join("Photos", "2024", "a")); !ok || fi.Size != 1 {
+		t.Errorf("expected file from the source folder, got %v, %v", fi, ok)
+	}
+	for _, name := range []string{
+		filepath.Join("Photos", "2024", "deleted"),
+		filepath.Join("Photos", "2024", "ignored"),
+		filepath.Join("Photos", "2024", "missing"),
+		"other",
+	} {
+		if _, ok := src.SourceFile(name); ok {
+			t.Errorf("%s should not be part of the view", name)
+		}
+	}
+}
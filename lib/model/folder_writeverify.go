@@ -0,0 +1,114 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+var errWriteVerification = errors.New("data read back differs from what was written")
+
+// WriteVerificationStats accounts for reading blocks back after writing
+// them, in a folder verifying its writes, since it last started.
+type WriteVerificationStats struct {
+	BlocksVerified int64 `json:"blocksVerified"`
+	BytesVerified  int64 `json:"bytesVerified"`
+	// Time spent syncing and reading blocks back, which is what verifying
+	// costs in pull throughput, and the rate blocks were verified at.
+	Duration     time.Duration `json:"duration"`
+	BytesPerSec  float64       `json:"bytesPerSec"`
+	Failures     int64         `json:"failures"`
+	LastFailure  time.Time     `json:"lastFailure,omitempty"`
+	LastFailFile string        `json:"lastFailFile,omitempty"`
+}
+
+// folderWriteVerifications keeps the write verification stats of each
+// folder.
+type folderWriteVerifications struct {
+	mut   sync.Mutex
+	stats map[string]WriteVerificationStats
+}
+
+func newFolderWriteVerifications() *folderWriteVerifications {
+	return &folderWriteVerifications{stats: make(map[string]WriteVerificationStats)}
+}
+
+// record accounts for verifying a block of the file, returning whether
+// it's the first failure for the folder.
+func (v *folderWriteVerifications) record(folder, file string, size int, duration time.Duration, err error) bool {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	s := v.stats[folder]
+	s.Duration += duration
+	if err != nil {
+		s.Failures++
+		s.LastFailure = time.Now()
+		s.LastFailFile = file
+		v.stats[folder] = s
+		return s.Failures == 1
+	}
+	s.BlocksVerified++
+	s.BytesVerified += int64(size)
+	v.stats[folder] = s
+	return false
+}
+
+func (v *folderWriteVerifications) forget(folder string) {
+	v.mut.Lock()
+	delete(v.stats, folder)
+	v.mut.Unlock()
+}
+
+func (v *folderWriteVerifications) get(folder string) (WriteVerificationStats, bool) {
+	v.mut.Lock()
+	defer v.mut.Unlock()
+	s, ok := v.stats[folder]
+	if ok && s.Duration > 0 {
+		s.BytesPerSec = float64(s.BytesVerified) / s.Duration.Seconds()
+	}
+	return s, ok
+}
+
+// FolderWriteVerification returns the write verification stats of the
+// folder, and false when it hasn't verified any writes since it started.
+func (m *model) FolderWriteVerification(folder string) (WriteVerificationStats, bool) {
+	return m.writeVerifications.get(folder)
+}
+
+// verifyWrite reads back the data just written from buf to the temp file at
+// the offset, when the folder verifies its writes, and returns an error if
+// it's not what was written.
+func (f *sendReceiveFolder) verifyWrite(fd *lockedWriterAt, file string, offset int64, buf []byte) error {
+	if !f.VerifyWrites {
+		return nil
+	}
+
+	t0 := f.pipeline.start(stageVerifier, false)
+	readBack := protocol.BufferPool.Get(len(buf))
+	err := fd.ReadBack(readBack, offset)
+	if err == nil && !bytes.Equal(readBack, buf) {
+		err = errWriteVerification
+	}
+	protocol.BufferPool.Put(readBack)
+	f.pipeline.done(stageVerifier, t0)
+
+	if f.model.writeVerifications.record(f.ID, file, len(buf), time.Since(t0), err) {
+		f.sl.Warn("Data written to disk didn't read back the same, the storage may be failing", slogutil.FilePath(file), slog.Int64("offset", offset), slogutil.Error(err))
+	}
+	if err != nil {
+		return fmt.Errorf("verifying write at offset %d: %w", offset, err)
+	}
+	return nil
+}
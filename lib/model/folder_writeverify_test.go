@@ -0,0 +1,88 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFolderWriteVerifications(t *testing.T) {
+	v := newFolderWriteVerifications()
+
+	if _, ok := v.get("default"); ok {
+		t.Error("expected no stats before verifying anything")
+	}
+	if v.record("default", "a", 1000, time.Second, nil) {
+		t.Error("success reported as first failure")
+	}
+	if !v.record("default", "b", 1000, time.Second, errWriteVerification) {
+		t.Error("expected first failure to be reported")
+	}
+	if v.record("default", "c", 1000, time.Second, errWriteVerification) {
+		t.Error("second failure reported as first")
+	}
+
+	s, _ := v.get("default")
+	if s.BlocksVerified != 1 || s.BytesVerified != 1000 || s.Failures != 2 || s.LastFailFile != "c" {
+		t.Errorf("unexpected stats %+v", s)
+	}
+	// Failed verifications take time too.
+	if s.Duration != 3*time.Second || s.BytesPerSec < 333 || s.BytesPerSec > 334 {
+		t.Errorf("unexpected cost %v at %v B/s", s.Duration, s.BytesPerSec)
+	}
+
+	v.forget("default")
+	if _, ok := v.get("default"); ok {
+		t.Error("expected stats to be forgotten")
+	}
+}
+
+func TestVerifyWrite(t *testing.T) {
+	m, f, wcfgCancel := setupSendReceiveFolder(t)
+	defer wcfgCancel()
+
+	fd, err := f.mtimefs.Create("verified")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fd.Close()
+	w := &lockedWriterAt{fd: fd}
+
+	data := []byte("some data to write")
+	if _, err := w.WriteAt(data, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing is read back unless asked for.
+	if _, err := w.WriteAt([]byte("garbage"), 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.verifyWrite(w, "verified", 10, data); err != nil {
+		t.Error("unexpected error without verification:", err)
+	}
+	if _, ok := m.FolderWriteVerification(f.ID); ok {
+		t.Error("expected no stats without verification")
+	}
+
+	f.VerifyWrites = true
+	if err := f.verifyWrite(w, "verified", 10, data); !errors.Is(err, errWriteVerification) {
+		t.Error("expected verification failure, got", err)
+	}
+	if _, err := w.WriteAt(data, 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.verifyWrite(w, "verified", 10, data); err != nil {
+		t.Error("unexpected verification failure:", err)
+	}
+
+	s, ok := m.FolderWriteVerification(f.ID)
+	if !ok || s.BlocksVerified != 1 || s.BytesVerified != int64(len(data)) || s.Failures != 1 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+// slotClass is a kind of folder operation with a global limit on how many
+// may run at the same time, on top of the overall folder concurrency.
+type slotClass int
+
+const (
+	slotClassScan slotClass = iota
+	slotClassInitialSync
+	slotClassHashers
+	numSlotClasses
+)
+
+func (c slotClass) String() string {
+	switch c {
+	case slotClassScan:
+		return "scan"
+	case slotClassInitialSync:
+		return "initialSync"
+	case slotClassHashers:
+		return "hashers"
+	default:
+		return "unknown"
+	}
+}
+
+// folderSlots hands out slots of each class to folders in the order they
+// asked for them, so that a folder waiting for many slots isn't starved by
+// folders needing fewer, and so that each waiting folder has a position in
+// the queue.
+type folderSlots struct {
+	mut     sync.Mutex
+	classes [numSlotClasses]slotQueue
+}
+
+type slotQueue struct {
+	capacity int // zero is unlimited
+	used     int
+	waiting  []*slotWaiter
+}
+
+type slotWaiter struct {
+	folder string
+	size   int
+	ready  chan struct{}
+}
+
+func newFolderSlots(opts config.OptionsConfiguration) *folderSlots {
+	s := &folderSlots{}
+	s.setCapacities(opts)
+	return s
+}
+
+func (s *folderSlots) setCapacities(opts config.OptionsConfiguration) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.classes[slotClassScan].capacity = max(0, opts.MaxScanConcurrency)
+	s.classes[slotClassInitialSync].capacity = max(0, opts.MaxInitialSyncConcurrency)
+	s.classes[slotClassHashers].capacity = max(0, opts.MaxHashers)
+	for i := range s.classes {
+		s.grantLocked(&s.classes[i])
+	}
+}
+
+// take waits for size slots of the class, in turn with other folders, and
+// returns the number granted: size, or the capacity if that is less.
+func (s *folderSlots) take(ctx context.Context, class slotClass, folder string, size int) (int, error) {
+	s.mut.Lock()
+	q := &s.classes[class]
+	if q.capacity > 0 {
+		size = min(size, q.capacity)
+	}
+	if len(q.waiting) == 0 && (q.capacity == 0 || q.used+size <= q.capacity) {
+		q.used += size
+		s.mut.Unlock()
+		return size, nil
+	}
+	w := &slotWaiter{folder: folder, size: size, ready: make(chan struct{})}
+	q.waiting = append(q.waiting, w)
+	s.mut.Unlock()
+
+	select {
+	case <-w.ready:
+		return w.size, nil
+	case <-ctx.Done():
+		s.mut.Lock()
+		defer s.mut.Unlock()
+		select {
+		case <-w.ready:
+			// Granted in the meantime; hand it back.
+			q.used -= w.size
+		default:
+			q.waiting = slices.DeleteFunc(q.waiting, func(o *slotWaiter) bool { return o == w })
+		}
+		s.grantLocked(q)
+		return 0, ctx.Err()
+	}
+}
+
+func (s *folderSlots) give(class slotClass, size int) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	q := &s.classes[class]
+	q.used = max(0, q.used-size)
+	s.grantLocked(q)
+}
+
+// grantLocked lets waiters through from the front of the queue for as long
+// as they fit.
+func (*folderSlots) grantLocked(q *slotQueue) {
+	for len(q.waiting) > 0 {
+		w := q.waiting[0]
+		if q.capacity > 0 {
+			w.size = min(w.size, q.capacity)
+			if q.used+w.size > q.capacity {
+				return
+			}
+		}
+		q.used += w.size
+		q.waiting = q.waiting[1:]
+		close(w.ready)
+	}
+}
+
+// queuePosition returns the class the folder is waiting for and its
+// position in that queue, counting from one, or zero when not waiting.
+func (s *folderSlots) queuePosition(folder string) (slotClass, int) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	for class := range s.classes {
+		for i, w := range s.classes[class].waiting {
+			if w.folder == folder {
+				return slotClass(class), i + 1
+			}
+		}
+	}
+	return 0, 0
+}
+
+// FolderQueuePosition returns what the folder is waiting for its turn at,
+// "scan", "initialSync" or "hashers", and its position in that queue
+// counting from one. The position is zero when it isn't waiting.
+func (m *model) FolderQueuePosition(folder string) (string, int) {
+	class, pos := m.folderSlots.queuePosition(folder)
+	if pos == 0 {
+		return "", 0
+	}
+	return class.String(), pos
+}
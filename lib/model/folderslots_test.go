@@ -0,0 +1,99 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+func TestFolderSlots(t *testing.T) {
+	s := newFolderSlots(config.OptionsConfiguration{MaxHashers: 4})
+	ctx := context.Background()
+
+	// More than the capacity is capped to it.
+	if n, err := s.take(ctx, slotClassHashers, "a", 8); err != nil || n != 4 {
+		t.Fatalf("expected four hashers, got %d, %v", n, err)
+	}
+
+	// Waiters are let through in order, even when a later one would fit.
+	granted := make(chan string, 2)
+	wait := func(folder string, size int) {
+		go func() {
+			if _, err := s.take(ctx, slotClassHashers, folder, size); err == nil {
+				granted <- folder
+			}
+		}()
+		for {
+			if _, pos := s.queuePosition(folder); pos > 0 {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	wait("b", 3)
+	wait("c", 1)
+	if class, pos := s.queuePosition("c"); class != slotClassHashers || pos != 2 {
+		t.Errorf("unexpected queue position %v %d", class, pos)
+	}
+
+	s.give(slotClassHashers, 1)
+	select {
+	case f := <-granted:
+		t.Fatalf("%s should still wait", f)
+	case <-time.After(10 * time.Millisecond):
+	}
+	s.give(slotClassHashers, 3)
+	if f := <-granted; f != "b" {
+		t.Errorf("expected b first, got %s", f)
+	}
+	if f := <-granted; f != "c" {
+		t.Errorf("expected c second, got %s", f)
+	}
+
+	// Unlimited classes never wait.
+	for range 10 {
+		if _, err := s.take(ctx, slotClassScan, "a", 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFolderSlotsCancel(t *testing.T) {
+	s := newFolderSlots(config.OptionsConfiguration{MaxScanConcurrency: 1})
+	if _, err := s.take(context.Background(), slotClassScan, "a", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.take(ctx, slotClassScan, "b", 1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the wait to time out, got %v", err)
+	}
+	if _, pos := s.queuePosition("b"); pos != 0 {
+		t.Error("cancelled waiter still queued")
+	}
+
+	// Raising the capacity lets waiters through.
+	done := make(chan struct{})
+	go func() {
+		_, _ = s.take(context.Background(), slotClassScan, "c", 1)
+		close(done)
+	}()
+	for {
+		if _, pos := s.queuePosition("c"); pos > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	s.setCapacities(config.OptionsConfiguration{MaxScanConcurrency: 2})
+	<-done
+}
@@ -7,6 +7,7 @@
 package model
 
 import (
+	"errors"
 	"log/slog"
 	"sync"
 	"time"
@@ -27,6 +28,9 @@ const (
 	FolderCleaning
 	FolderCleanWaiting
 	FolderError
+	FolderReadOnly
+	FolderDependencyWaiting
+	FolderQuotaExceeded
 )
 
 func (s folderState) String() string {
@@ -49,6 +53,12 @@ func (s folderState) String() string {
 		return "clean-waiting"
 	case FolderError:
 		return "error"
+	case FolderReadOnly:
+		return "read-only"
+	case FolderDependencyWaiting:
+		return "dependency-waiting"
+	case FolderQuotaExceeded:
+		return "quota-exceeded"
 	default:
 		return "unknown"
 	}
@@ -86,10 +96,11 @@ type stateTracker struct {
 	folderID string
 	evLogger events.Logger
 
-	mut     sync.Mutex
-	current folderState
-	err     error
-	changed time.Time
+	mut           sync.Mutex
+	current       folderState
+	err           error
+	changed       time.Time
+	quotaExceeded bool // shown as FolderQuotaExceeded instead of FolderIdle
 }
 
 func newStateTracker(id string, evLogger events.Logger) stateTracker {
@@ -99,15 +110,19 @@ func newStateTracker(id string, evLogger events.Logger) stateTracker {
 	}
 }
 
-// setState sets the new folder state, for states other than FolderError.
+// setState sets the new folder state, for states other than FolderError and
+// FolderReadOnly.
 func (s *stateTracker) setState(newState folderState) {
-	if newState == FolderError {
+	if newState == FolderError || newState == FolderReadOnly {
 		panic("must use setError")
 	}
 
 	s.mut.Lock()
 	defer s.mut.Unlock()
 
+	if newState == FolderIdle && s.quotaExceeded {
+		newState = FolderQuotaExceeded
+	}
 	if newState == s.current {
 		return
 	}
@@ -143,8 +158,9 @@ func (s *stateTracker) getState() (current folderState, changed time.Time, err e
 	return current, changed, err
 }
 
-// setError sets the folder state to FolderError with the specified error or
-// to FolderIdle if the error is nil
+// setError sets the folder state to FolderError with the specified error,
+// to FolderReadOnly if it's because the filesystem is read-only, or to
+// FolderIdle if the error is nil
 func (s *stateTracker) setError(err error) {
 	s.mut.Lock()
 	defer s.mut.Unlock()
@@ -158,18 +174,26 @@ func (s *stateTracker) setError(err error) {
 		"from":   s.current.String(),
 	}
 
-	if err != nil && s.current != FolderError {
+	newState := FolderIdle
+	if s.quotaExceeded {
+		newState = FolderQuotaExceeded
+	}
+	if errors.Is(err, errFolderReadOnly) {
+		newState = FolderReadOnly
+	} else if err != nil {
+		newState = FolderError
+	}
+
+	if err != nil && s.current != newState {
 		slog.Warn("Folder is in error state", slog.String("folder", s.folderID), slogutil.Error(err))
-	} else if err == nil && s.current == FolderError {
+	} else if err == nil && (s.current == FolderError || s.current == FolderReadOnly) {
 		slog.Info("Folder error state was cleared", slog.String("folder", s.folderID))
 	}
 
 	if err != nil {
 		eventData["error"] = err.Error()
-		s.current = FolderError
-	} else {
-		s.current = FolderIdle
 	}
+	s.current = newState
 
 	eventData["to"] = s.current.String()
 
@@ -182,3 +206,15 @@ func (s *stateTracker) setError(err error) {
 
 	s.evLogger.Log(events.StateChanged, eventData)
 }
+
+// setQuotaExceeded sets whether the folder is over its storage quota,
+// which is shown in place of it being idle.
+func (s *stateTracker) setQuotaExceeded(exceeded bool) {
+	s.mut.Lock()
+	s.quotaExceeded = exceeded
+	idle := s.current == FolderIdle || s.current == FolderQuotaExceeded
+	s.mut.Unlock()
+	if idle {
+		s.setState(FolderIdle)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+// FolderCapabilities is what the filesystem of a folder was found to
+// support, and how the folder's behaviour is adjusted to that.
+type FolderCapabilities struct {
+	fs.Capabilities
+	Path        string   `json:"path"` // the folder path that was probed
+	Adjustments []string `json:"adjustments"`
+}
+
+// fsCapabilityStore keeps the probed filesystem capabilities of all folders
+// in the database.
+type fsCapabilityStore struct {
+	kv db.KV
+}
+
+func fsCapabilityKey(folder string) string {
+	return "fscapabilities/" + folder
+}
+
+// current returns the capabilities probed for the folder, if they were
+// probed at its current path.
+func (s *fsCapabilityStore) current(cfg config.FolderConfiguration) (FolderCapabilities, bool) {
+	bs, err := s.kv.GetKV(fsCapabilityKey(cfg.ID))
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			l.Debugln("getting filesystem capabilities of", cfg.ID, err)
+		}
+		return FolderCapabilities{}, false
+	}
+	var caps FolderCapabilities
+	if err := json.Unmarshal(bs, &caps); err != nil {
+		l.Debugln("unmarshalling filesystem capabilities of", cfg.ID, err)
+		return FolderCapabilities{}, false
+	}
+	if caps.Path != cfg.Path {
+		return FolderCapabilities{}, false
+	}
+	return caps, true
+}
+
+func (s *fsCapabilityStore) put(folder string, caps FolderCapabilities) error {
+	caps.Adjustments = nil
+	bs, err := json.Marshal(caps)
+	if err != nil {
+		return err
+	}
+	return s.kv.PutKV(fsCapabilityKey(folder), bs)
+}
+
+func (s *fsCapabilityStore) forget(folder string) {
+	_ = s.kv.DeleteKV(fsCapabilityKey(folder))
+}
+
+// adjustToCapabilities returns the configuration the folder runs with on a
+// filesystem with the given capabilities, with what the filesystem doesn't
+// support turned off instead of failing file by file when pulling, and
+// descriptions of the adjustments. Symlinks and too long names are handled
+// by the folder itself, see folder.capabilities.
+func adjustToCapabilities(cfg config.FolderConfiguration, caps fs.Capabilities) (config.FolderConfiguration, []string) {
+	var adjustments []string
+	if !caps.Xattrs && (cfg.SyncXattrs || cfg.SendXattrs) {
+		cfg.SyncXattrs = false
+		cfg.SendXattrs = false
+		adjustments = append(adjustments, "Extended attributes are not synced, as the filesystem doesn't support them")
+	}
+	if !caps.HardLinks && cfg.PreserveHardLinks {
+		cfg.PreserveHardLinks = false
+		adjustments = append(adjustments, "Hard links are not preserved, as the filesystem doesn't support them")
+	}
+	if !caps.CaseSensitive && cfg.CaseSensitiveFS {
+		cfg.CaseSensitiveFS = false
+		adjustments = append(adjustments, "Case conflicts are detected, as the filesystem is case insensitive")
+	}
+	if window := int(caps.TimestampResolution / time.Second); window > 1 && cfg.RawModTimeWindowS < window {
+		cfg.RawModTimeWindowS = window
+		adjustments = append(adjustments, fmt.Sprintf("Modification times are compared within %ds, the resolution of the filesystem", window))
+	}
+	if !caps.Symlinks && cfg.Type != config.FolderTypeSendOnly {
+		adjustments = append(adjustments, "Symlinks are not pulled, as the filesystem doesn't support them")
+	}
+	if maxName, maxPath := caps.NameLimits(); cfg.Type != config.FolderTypeSendOnly {
+		if maxName > 0 {
+			adjustments = append(adjustments, fmt.Sprintf("Files with names longer than %d characters are not pulled, as the filesystem doesn't support them", maxName))
+		}
+		if maxPath > 0 {
+			adjustments = append(adjustments, fmt.Sprintf("Files with paths longer than %d characters are not pulled, as the filesystem doesn't support them", maxPath))
+		}
+	}
+	return cfg, adjustments
+}
+
+// ensureFolderCapabilities probes the filesystem of the folder when it's
+// started for the first time, or at a new path.
+func (m *model) ensureFolderCapabilities(cfg config.FolderConfiguration) {
+	if _, ok := m.fsCapabilities.current(cfg); ok {
+		return
+	}
+	if cfg.FilesystemType != config.FilesystemTypeBasic {
+		return
+	}
+	if _, err := m.probeFolderCapabilities(cfg); err != nil {
+		slog.Warn("Failed to probe filesystem capabilities", cfg.LogAttr(), slogutil.Error(err))
+	}
+}
+
+func (m *model) probeFolderCapabilities(cfg config.FolderConfiguration) (FolderCapabilities, error) {
+	probed, err := fs.ProbeCapabilities(cfg.Filesystem())
+	if err != nil {
+		return FolderCapabilities{}, err
+	}
+	caps := FolderCapabilities{Capabilities: probed, Path: cfg.Path}
+	if err := m.fsCapabilities.put(cfg.ID, caps); err != nil {
+		return FolderCapabilities{}, err
+	}
+	_, caps.Adjustments = adjustToCapabilities(cfg, probed)
+	for _, adjustment := range caps.Adjustments {
+		slog.Info("Adjusting folder to its filesystem", cfg.LogAttr(), slog.String("adjustment", adjustment))
+	}
+	return caps, nil
+}
+
+// FolderCapabilities returns the capabilities of the filesystem of the
+// folder as last probed, or nil if they haven't been.
+func (m *model) FolderCapabilities(folder string) (*FolderCapabilities, error) {
+	cfg, ok := m.cfg.Folder(folder)
+	if !ok {
+		return nil, ErrFolderMissing
+	}
+	caps, ok := m.fsCapabilities.current(cfg)
+	if !ok {
+		return nil, nil
+	}
+	_, caps.Adjustments = adjustToCapabilities(cfg, caps.Capabilities)
+	return &caps, nil
+}
+
+// ProbeFolderCapabilities probes the filesystem of the folder again, for
+// example after it was remounted, and restarts the folder if that changes
+// how it's adjusted to the filesystem.
+func (m *model) ProbeFolderCapabilities(folder string) (*FolderCapabilities, error) {
+	cfg, ok := m.cfg.Folder(folder)
+	if !ok {
+		return nil, ErrFolderMissing
+	}
+	prev, hadPrev := m.fsCapabilities.current(cfg)
+	caps, err := m.probeFolderCapabilities(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if hadPrev {
+		_, prev.Adjustments = adjustToCapabilities(cfg, prev.Capabilities)
+	}
+	if !cfg.Paused && (!hadPrev || !slices.Equal(prev.Adjustments, caps.Adjustments)) {
+		if err := m.restartFolder(cfg, cfg, m.cfg.Options().CacheIgnoredFiles); err != nil {
+			return nil, err
+		}
+	}
+	return &caps, nil
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+func TestAdjustToCapabilities(t *testing.T) {
+	cfg := config.FolderConfiguration{
+		ID:                "default",
+		Type:              config.FolderTypeSendReceive,
+		SyncXattrs:        true,
+		PreserveHardLinks: true,
+		CaseSensitiveFS:   true,
+	}
+
+	full := fs.Capabilities{Symlinks: true, Xattrs: true, HardLinks: true, CaseSensitive: true, MaxNameLength: 1024, MaxPathLength: 4096, TimestampResolution: time.Nanosecond}
+	adjusted, adjustments := adjustToCapabilities(cfg, full)
+	if len(adjustments) != 0 || adjusted.SyncXattrs != cfg.SyncXattrs || adjusted.PreserveHardLinks != cfg.PreserveHardLinks || adjusted.CaseSensitiveFS != cfg.CaseSensitiveFS {
+		t.Errorf("expected no adjustments, got %v", adjustments)
+	}
+
+	// Something like a FAT formatted USB stick
+	fat := fs.Capabilities{MaxNameLength: 255, MaxPathLength: 4096, TimestampResolution: 2 * time.Second}
+	adjusted, adjustments = adjustToCapabilities(cfg, fat)
+	if adjusted.SyncXattrs || adjusted.PreserveHardLinks || adjusted.CaseSensitiveFS {
+		t.Errorf("expected unsupported features to be turned off, got %+v", adjusted)
+	}
+	if adjusted.RawModTimeWindowS != 2 {
+		t.Errorf("expected a modification time window of 2s, got %d", adjusted.RawModTimeWindowS)
+	}
+	// Xattrs, hard links, case, times, symlinks and the name length
+	if len(adjustments) != 6 {
+		t.Errorf("expected six adjustments, got %v", adjustments)
+	}
+
+	cfg.Type = config.FolderTypeSendOnly
+	if _, adjustments = adjustToCapabilities(cfg, fat); len(adjustments) != 4 {
+		t.Errorf("expected no pull related adjustments for a send only folder, got %v", adjustments)
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Sources of an identity change, i.e. how we noticed that a known device
+// may now be presenting a different certificate. What an unknown device
+// says about itself, such as its name, is never taken as one: anyone can
+// claim to be a known device that way.
+const (
+	// We dialed the device at one of its addresses and the certificate
+	// presented there belongs to another device ID.
+	IdentityChangeSourceDial = "dial"
+)
+
+var ErrNoIdentityChange = errors.New("no identity change recorded for device")
+
+// IdentityChange describes a configured device that has likely replaced its
+// certificate, and thereby its device ID. Since the device ID is the pinned
+// fingerprint of the certificate, such a device can't connect until the user
+// approves the new certificate.
+type IdentityChange struct {
+	DeviceID    protocol.DeviceID `json:"deviceID"`
+	NewDeviceID protocol.DeviceID `json:"newDeviceID"`
+	Source      string            `json:"source"`
+	Address     string            `json:"address"`
+	// Details of the new certificate, when known.
+	CertName      string    `json:"certName,omitempty"`
+	CertNotBefore time.Time `json:"certNotBefore,omitempty"`
+	FirstSeen     time.Time `json:"firstSeen"`
+	LastSeen      time.Time `json:"lastSeen"`
+}
+
+// OnIdentityMismatch is called by the connection service when a device we
+// dialed presented a certificate for another device ID.
+func (m *model) OnIdentityMismatch(expected protocol.DeviceID, cert *x509.Certificate, addr net.Addr) {
+	actual := protocol.NewDeviceID(cert.Raw)
+	if _, ok := m.cfg.Device(expected); !ok || actual == m.id {
+		return
+	}
+	if _, ok := m.cfg.Device(actual); ok {
+		// Another known device answering on this address, e.g. after
+		// an address change; not an identity change.
+		return
+	}
+	m.recordIdentityChange(IdentityChange{
+		DeviceID:      expected,
+		NewDeviceID:   actual,
+		Source:        IdentityChangeSourceDial,
+		Address:       addr.String(),
+		CertName:      cert.Subject.CommonName,
+		CertNotBefore: cert.NotBefore,
+	})
+}
+
+func (m *model) recordIdentityChange(change IdentityChange) {
+	now := time.Now().Truncate(time.Second)
+
+	m.mut.Lock()
+	prev, existed := m.identityChanges[change.DeviceID]
+	if existed && prev.NewDeviceID == change.NewDeviceID {
+		change.FirstSeen = prev.FirstSeen
+	} else {
+		change.FirstSeen = now
+	}
+	change.LastSeen = now
+	m.identityChanges[change.DeviceID] = change
+	m.mut.Unlock()
+
+	if existed && prev.NewDeviceID == change.NewDeviceID {
+		return
+	}
+	slog.Warn("Remote device identity changed; the new certificate must be approved before it can connect", change.DeviceID.LogAttr(), slog.String("newDevice", change.NewDeviceID.String()), slogutil.Address(change.Address), slog.String("source", change.Source))
+	m.evLogger.Log(events.DeviceIdentityChanged, change)
+
+	var name string
+	if devCfg, ok := m.cfg.Device(change.DeviceID); ok {
+		name = devCfg.Name
+	}
+	m.reportDeviceAnomaly(DeviceAnomaly{
+		DeviceID: change.DeviceID,
+		Name:     name,
+		Kind:     DeviceAnomalyCertificate,
+		Address:  change.Address,
+		Details:  fmt.Sprintf("presented the certificate of %s (%s)", change.NewDeviceID, change.Source),
+	})
+}
+
+// IdentityChanges returns the recorded identity changes, keyed by the
+// configured device ID.
+func (m *model) IdentityChanges() map[protocol.DeviceID]IdentityChange {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+	res := make(map[protocol.DeviceID]IdentityChange, len(m.identityChanges))
+	for id, change := range m.identityChanges {
+		res[id] = change
+	}
+	return res
+}
+
+// ApproveIdentityChange pins the new certificate for the device: the device
+// ID is replaced by the new one everywhere in the configuration, keeping
+// the device settings and folder shares.
+func (m *model) ApproveIdentityChange(device protocol.DeviceID) error {
+	m.mut.RLock()
+	change, ok := m.identityChanges[device]
+	m.mut.RUnlock()
+	if !ok {
+		return ErrNoIdentityChange
+	}
+	if _, ok := m.cfg.Device(change.NewDeviceID); ok {
+		return fmt.Errorf("device %s is already configured", change.NewDeviceID.Short())
+	}
+
+	waiter, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		replace := func(id *protocol.DeviceID) {
+			if *id == device {
+				*id = change.NewDeviceID
+			}
+		}
+		for i := range cfg.Devices {
+			replace(&cfg.Devices[i].DeviceID)
+			replace(&cfg.Devices[i].IntroducedBy)
+		}
+		for i := range cfg.Folders {
+			for j := range cfg.Folders[i].Devices {
+				replace(&cfg.Folders[i].Devices[j].DeviceID)
+				replace(&cfg.Folders[i].Devices[j].IntroducedBy)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	waiter.Wait()
+
+	m.mut.Lock()
+	delete(m.identityChanges, device)
+	m.mut.Unlock()
+
+	if err := m.observed.RemovePendingDevice(change.NewDeviceID); err != nil {
+		slog.Warn("Failed to remove pending device entry", change.NewDeviceID.LogAttr(), slogutil.Error(err))
+	}
+	slog.Info("Approved new certificate for remote device", device.LogAttr(), slog.String("newDevice", change.NewDeviceID.String()))
+	return nil
+}
+
+// DismissIdentityChange forgets about a recorded identity change, e.g.
+// because the other device was an impostor.
+func (m *model) DismissIdentityChange(device protocol.DeviceID) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if _, ok := m.identityChanges[device]; !ok {
+		return ErrNoIdentityChange
+	}
+	delete(m.identityChanges, device)
+	return nil
+}
+
+// retireReplacedDevice removes the device replaced by the given one, now
+// that the replacement has connected and thereby proven itself.
+func (m *model) retireReplacedDevice(device protocol.DeviceID) {
+	var oldID protocol.DeviceID
+	var retired bool
+	_, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		oldID, retired = cfg.RetireReplacedDevice(device)
+	})
+	if err != nil {
+		slog.Warn("Failed to retire replaced device", device.LogAttr(), slogutil.Error(err))
+		return
+	}
+	if retired {
+		slog.Info("Retired device replaced by newly connected device", device.LogAttr(), slog.String("oldDevice", oldID.String()))
+	}
+}
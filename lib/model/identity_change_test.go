@@ -0,0 +1,98 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"crypto/x509"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestIdentityChangeApprove(t *testing.T) {
+	w, fcfg, wCancel := newDefaultCfgWrapper()
+	defer wCancel()
+	m := setupModel(t, w)
+	defer cleanupModelAndRemoveDir(m, fcfg.Filesystem().URI())
+
+	cert := &x509.Certificate{Raw: []byte("a new certificate")}
+	newID := protocol.NewDeviceID(cert.Raw)
+	addr := &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 22000}
+
+	m.OnIdentityMismatch(device1, cert, addr)
+
+	changes := m.IdentityChanges()
+	change, ok := changes[device1]
+	if !ok || len(changes) != 1 {
+		t.Fatalf("expected one identity change for device1, got %v", changes)
+	}
+	if change.NewDeviceID != newID || change.Source != IdentityChangeSourceDial {
+		t.Errorf("unexpected identity change %+v", change)
+	}
+	if !m.ConnectionStats()["connections"].(map[string]ConnectionStats)[device1.String()].IdentityChanged {
+		t.Error("expected the identity change to show in the connection stats")
+	}
+
+	if err := m.ApproveIdentityChange(device1); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := w.Device(device1); ok {
+		t.Error("old device ID still configured")
+	}
+	if _, ok := w.Device(newID); !ok {
+		t.Error("new device ID not configured")
+	}
+	if folder, _ := w.Folder("default"); !folder.SharedWith(newID) {
+		t.Error("folder not shared with the new device ID")
+	}
+	if len(m.IdentityChanges()) != 0 {
+		t.Error("expected identity change to be cleared after approval")
+	}
+	if err := m.ApproveIdentityChange(device1); !errors.Is(err, ErrNoIdentityChange) {
+		t.Errorf("expected ErrNoIdentityChange on second approval, got %v", err)
+	}
+}
+
+func TestIdentityChangeDismiss(t *testing.T) {
+	w, fcfg, wCancel := newDefaultCfgWrapper()
+	defer wCancel()
+	m := setupModel(t, w)
+	defer cleanupModelAndRemoveDir(m, fcfg.Filesystem().URI())
+
+	cert := &x509.Certificate{Raw: []byte("some certificate")}
+	m.OnIdentityMismatch(device1, cert, &net.TCPAddr{})
+	if err := m.DismissIdentityChange(device1); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.IdentityChanges()) != 0 {
+		t.Error("expected no identity changes after dismissal")
+	}
+	if _, ok := w.Device(device1); !ok {
+		t.Error("dismissal must not touch the configuration")
+	}
+}
+
+func TestIdentityChangeNotFromHello(t *testing.T) {
+	w, fcfg, wCancel := newDefaultCfgWrapper()
+	defer wCancel()
+	m := setupModel(t, w)
+	defer cleanupModelAndRemoveDir(m, fcfg.Filesystem().URI())
+
+	// An unknown device claiming the name of a known, disconnected one is
+	// just a pending device.
+	dev, _ := w.Device(device1)
+	stranger := protocol.NewDeviceID([]byte("stranger"))
+	addr := &net.TCPAddr{IP: net.IPv4(192, 0, 2, 2), Port: 22000}
+	if err := m.OnHello(stranger, addr, protocol.Hello{DeviceName: dev.Name}); !errors.Is(err, errDeviceUnknown) {
+		t.Fatalf("expected errDeviceUnknown, got %v", err)
+	}
+	if changes := m.IdentityChanges(); len(changes) != 0 {
+		t.Errorf("expected no identity change, got %v", changes)
+	}
+}
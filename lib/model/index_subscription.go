@@ -0,0 +1,193 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/ignore"
+)
+
+const (
+	indexSubscriptionKeyPrefix = "indexsubscription/"
+
+	// Length of the hashes of ignored directories
+	ignoredPrefixHashLen = 16
+)
+
+// A device announces the directories of a folder everything below which it
+// ignores in the cluster config, so that other devices leave them out of
+// the index they send. Only hashes of the directories are sent, as the
+// ignore patterns may name things the other devices never heard of.
+func ignoredPrefixHash(folder, dir string) []byte {
+	h := sha256.Sum256([]byte(folder + "\x00" + dir))
+	return h[:ignoredPrefixHashLen]
+}
+
+// ignoredPrefixFilter tells the files below the directories ignored by a
+// peer, as it announced them.
+type ignoredPrefixFilter struct {
+	folder string
+	hashes map[string]struct{}
+}
+
+// newIgnoredPrefixFilter returns a filter for the hashes, or nil if there
+// are none.
+func newIgnoredPrefixFilter(folder string, hashes [][]byte) *ignoredPrefixFilter {
+	if len(hashes) == 0 {
+		return nil
+	}
+	f := &ignoredPrefixFilter{
+		folder: folder,
+		hashes: make(map[string]struct{}, len(hashes)),
+	}
+	for _, h := range hashes {
+		f.hashes[string(h)] = struct{}{}
+	}
+	return f
+}
+
+// ignores returns whether the file is below one of the directories.
+func (f *ignoredPrefixFilter) ignores(name string) bool {
+	if f == nil {
+		return false
+	}
+	name = filepath.ToSlash(name)
+	for i := strings.IndexByte(name, '/'); i > 0; {
+		if _, ok := f.hashes[string(ignoredPrefixHash(f.folder, name[:i]))]; ok {
+			return true
+		}
+		next := strings.IndexByte(name[i+1:], '/')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+	return false
+}
+
+// indexSubscriptions records the ignored directories announced for each
+// folder. They're kept in the database, as when one of them isn't ignored
+// anymore the index data left out for it must be asked for again, even
+// after a restart.
+type indexSubscriptions struct {
+	kv      db.KV
+	mut     sync.Mutex
+	ignored map[string][]string
+}
+
+func newIndexSubscriptions(kv db.KV) *indexSubscriptions {
+	return &indexSubscriptions{
+		kv:      kv,
+		ignored: make(map[string][]string),
+	}
+}
+
+func (s *indexSubscriptions) get(folder string) []string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.getLocked(folder)
+}
+
+func (s *indexSubscriptions) getLocked(folder string) []string {
+	if dirs, ok := s.ignored[folder]; ok {
+		return dirs
+	}
+	var dirs []string
+	if bs, err := s.kv.GetKV(indexSubscriptionKeyPrefix + folder); err == nil {
+		_ = json.Unmarshal(bs, &dirs)
+	}
+	s.ignored[folder] = dirs
+	return dirs
+}
+
+func (s *indexSubscriptions) set(folder string, dirs []string) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.ignored[folder] = dirs
+	if len(dirs) == 0 {
+		return s.kv.DeleteKV(indexSubscriptionKeyPrefix + folder)
+	}
+	bs, err := json.Marshal(dirs)
+	if err != nil {
+		return err
+	}
+	return s.kv.PutKV(indexSubscriptionKeyPrefix+folder, bs)
+}
+
+// hashes returns the hashes of the ignored directories of the folder, to
+// be announced.
+func (s *indexSubscriptions) hashes(folder string) [][]byte {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	dirs := s.getLocked(folder)
+	if len(dirs) == 0 {
+		return nil
+	}
+	hashes := make([][]byte, len(dirs))
+	for i, dir := range dirs {
+		hashes[i] = ignoredPrefixHash(folder, dir)
+	}
+	return hashes
+}
+
+func (s *indexSubscriptions) forget(folder string) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	delete(s.ignored, folder)
+	_ = s.kv.DeleteKV(indexSubscriptionKeyPrefix + folder)
+}
+
+// updateIndexSubscription records the directories now ignored in the
+// folder, returning whether they changed. When one of those announced
+// before isn't ignored anymore, the index IDs of the other devices are
+// forgotten, making them send their full index again.
+func (m *model) updateIndexSubscription(cfg config.FolderConfiguration, ignores *ignore.Matcher) bool {
+	var dirs []string
+	if cfg.Type != config.FolderTypeReceiveEncrypted {
+		dirs = ignores.IgnoredDirs()
+	}
+	prev := m.indexSubscriptions.get(cfg.ID)
+	if slices.Equal(prev, dirs) {
+		return false
+	}
+
+	if slices.ContainsFunc(prev, func(dir string) bool { return !slices.Contains(dirs, dir) }) {
+		slog.Info("Requesting full indexes for directories no longer ignored", cfg.LogAttr())
+		for _, dev := range cfg.DeviceIDs() {
+			if dev == m.id {
+				continue
+			}
+			if err := m.sdb.SetIndexID(cfg.ID, dev, 0); err != nil {
+				slog.Warn("Failed to reset index ID", cfg.LogAttr(), dev.LogAttr(), slogutil.Error(err))
+				return false
+			}
+		}
+	}
+
+	if err := m.indexSubscriptions.set(cfg.ID, dirs); err != nil {
+		slog.Warn("Failed to record ignored directories", cfg.LogAttr(), slogutil.Error(err))
+	}
+	return true
+}
+
+// ignoresChanged announces the ignored directories of the folder to the
+// other devices anew, if they changed.
+func (m *model) ignoresChanged(cfg config.FolderConfiguration, ignores *ignore.Matcher) {
+	if m.updateIndexSubscription(cfg, ignores) {
+		m.sendClusterConfig(cfg.DeviceIDs())
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/ignore"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestIgnoredPrefixFilter(t *testing.T) {
+	f := newIgnoredPrefixFilter("f", [][]byte{ignoredPrefixHash("f", "a/b"), ignoredPrefixHash("f", "c")})
+	for name, exp := range map[string]bool{
+		"a/b/file":   true,
+		"a/b/c/file": true,
+		"c/d":        true,
+		"a/b":        false,
+		"a/bc/file":  false,
+		"a/file":     false,
+		"c":          false,
+		"cd/file":    false,
+	} {
+		if got := f.ignores(filepath.FromSlash(name)); got != exp {
+			t.Errorf("ignores(%q) = %v, expected %v", name, got, exp)
+		}
+	}
+
+	if newIgnoredPrefixFilter("other", [][]byte{ignoredPrefixHash("f", "c")}).ignores(filepath.FromSlash("c/d")) {
+		t.Error("hash should only match in its own folder")
+	}
+	if newIgnoredPrefixFilter("f", nil).ignores(filepath.FromSlash("c/d")) {
+		t.Error("nothing should be ignored without hashes")
+	}
+}
+
+func TestIndexSubscription(t *testing.T) {
+	w, fcfg, wCancel := newDefaultCfgWrapper()
+	defer wCancel()
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	ownHashes := func() [][]byte {
+		cm, _ := m.generateClusterConfig(device1)
+		for _, dev := range cm.Folders[0].Devices {
+			if dev.ID == myID {
+				return dev.IgnoredPrefixes
+			}
+		}
+		t.Fatal("no device entry for us")
+		return nil
+	}
+
+	if err := m.sdb.SetIndexID(fcfg.ID, device1, 42); err != nil {
+		t.Fatal(err)
+	}
+	ignores := ignore.New(fcfg.Filesystem())
+	if err := ignores.Parse(strings.NewReader("/private\n/tmp/cache\n*.bak\n"), ".stignore"); err != nil {
+		t.Fatal(err)
+	}
+	if !m.updateIndexSubscription(fcfg, ignores) {
+		t.Fatal("expected the ignored directories to change")
+	}
+	if hashes := ownHashes(); len(hashes) != 2 || !slices.ContainsFunc(hashes, func(h []byte) bool {
+		return string(h) == string(ignoredPrefixHash(fcfg.ID, "tmp/cache"))
+	}) {
+		t.Errorf("unexpected announced hashes %x", hashes)
+	}
+	if id, _ := m.sdb.GetIndexID(fcfg.ID, device1); id != 42 {
+		t.Error("index ID should be kept when ignoring more")
+	}
+	if m.updateIndexSubscription(fcfg, ignores) {
+		t.Error("nothing should change for the same patterns")
+	}
+
+	// Ignoring less needs the full index again.
+	if err := ignores.Parse(strings.NewReader("/private\n"), ".stignore"); err != nil {
+		t.Fatal(err)
+	}
+	if !m.updateIndexSubscription(fcfg, ignores) {
+		t.Fatal("expected the ignored directories to change")
+	}
+	if hashes := ownHashes(); len(hashes) != 1 {
+		t.Errorf("unexpected announced hashes %x", hashes)
+	}
+	if id, _ := m.sdb.GetIndexID(fcfg.ID, device1); id != 0 {
+		t.Error("index ID should be forgotten when ignoring less, got", id)
+	}
+	if id, _ := m.sdb.GetIndexID(fcfg.ID, protocol.LocalDeviceID); id == 0 {
+		t.Error("our own index ID should be kept")
+	}
+
+	// Negated patterns leave nothing to announce, as they could include
+	// something below the directories again.
+	if err := ignores.Parse(strings.NewReader("!/private/keep\n/private\n"), ".stignore"); err != nil {
+		t.Fatal(err)
+	}
+	m.updateIndexSubscription(fcfg, ignores)
+	if hashes := ownHashes(); len(hashes) != 0 {
+		t.Errorf("unexpected announced hashes %x", hashes)
+	}
+}
+
+func TestIndexHandlerSkipsIgnoredPrefixes(t *testing.T) {
+	done := make(chan struct{})
+	defer close(done)
+
+	m, fc, fcfg, wcfgCancel := setupModelWithConnection(t)
+	defer wcfgCancel()
+	defer cleanupModelAndRemoveDir(m, fcfg.Filesystem().URI())
+
+	indexChan := make(chan []protocol.FileInfo)
+	fc.setIndexFn(func(ctx context.Context, folder string, fs []protocol.FileInfo) error {
+		select {
+		case indexChan <- fs:
+		case <-done:
+		case <-ctx.Done():
+		}
+		return nil
+	})
+
+	cc := basicClusterConfig(device1, myID, fcfg.ID)
+	cc.Folders[0].Devices[0].IgnoredPrefixes = [][]byte{ignoredPrefixHash(fcfg.ID, "private")}
+	m.ClusterConfig(fc, cc)
+
+	version := protocol.Vector{}.Update(myID.Short())
+	localIndexUpdate(m, fcfg.ID, []protocol.FileInfo{
+		{Name: "private", Type: protocol.FileInfoTypeDirectory, Version: version, Sequence: 1},
+		{Name: filepath.Join("private", "secret"), Size: 10, Version: version, Sequence: 2},
+		{Name: "public", Size: 10, Version: version, Sequence: 3},
+	})
+
+	var sent []string
+	timeout := time.After(5 * time.Second)
+	for !slices.Contains(sent, "public") {
+		select {
+		case <-timeout:
+			t.Fatal("timed out before receiving index, got", sent)
+		case fs := <-indexChan:
+			for _, f := range fs {
+				sent = append(sent, filepath.ToSlash(f.Name))
+			}
+		}
+	}
+	if slices.Contains(sent, "private/secret") {
+		t.Error("file below an ignored directory of the peer was sent")
+	}
+	if !slices.Contains(sent, "private") {
+		t.Error("the ignored directory itself should be sent")
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/faultinject"
 	"github.com/syncthing/syncthing/internal/itererr"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/events"
@@ -28,6 +29,8 @@ type indexHandler struct {
 	downloads                *deviceDownloadState
 	folder                   string
 	folderIsReceiveEncrypted bool
+	folderIsArchive          bool
+	remoteIndexID            protocol.IndexID
 	evLogger                 events.Logger
 
 	// We track the latest / highest sequence number in two ways for two
@@ -46,6 +49,16 @@ type indexHandler struct {
 	localPrevSequence int64 // the highest sequence number we've seen in our FileInfos
 	sentPrevSequence  int64 // the highest sequence number we've sent to the peer
 
+	// The peer understands directory tombstones, sent ahead of large
+	// deletions so it stops pulling files that are about to go away.
+	twoPhaseDeletes   bool
+	tombstonesScanned int64 // the highest sequence number looked at for tombstones
+	mostlyDeletes     bool  // the last index update was mostly deletions
+
+	// The directories the peer ignores everything below; it doesn't get
+	// index data for those.
+	ignoredPrefixes *ignoredPrefixFilter
+
 	cond   *sync.Cond
 	paused bool
 	sdb    db.DB
@@ -133,8 +146,12 @@ func newIndexHandler(conn protocol.Connection, downloads *deviceDownloadState, f
 		downloads:                downloads,
 		folder:                   folder.ID,
 		folderIsReceiveEncrypted: folder.Type == config.FolderTypeReceiveEncrypted,
+		folderIsArchive:          folder.ArchiveMode,
 		localPrevSequence:        startSequence,
 		sentPrevSequence:         startSequence,
+		twoPhaseDeletes:          startInfo.twoPhaseDeletes,
+		ignoredPrefixes:          newIgnoredPrefixFilter(folder.ID, startInfo.remote.IgnoredPrefixes),
+		remoteIndexID:            startInfo.remote.IndexID,
 		evLogger:                 evLogger,
 
 		sdb:    sdb,
@@ -223,11 +240,17 @@ func (s *indexHandler) Serve(ctx context.Context) (err error) {
 
 		// Wait a short amount of time before entering the next loop. If there
 		// are continuous changes happening to the local index, this gives us
-		// time to batch them up a little.
+		// time to batch them up a little. Streams of deletions are paced
+		// further for peers that got tombstones for them, so that the
+		// rest of their index processing isn't starved.
+		wait := 250 * time.Millisecond
+		if s.twoPhaseDeletes && s.mostlyDeletes {
+			wait = twoPhaseDeleteBatchInterval
+		}
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
-		case <-time.After(250 * time.Millisecond):
+		case <-time.After(wait):
 		}
 	}
 
@@ -259,6 +282,12 @@ func (s *indexHandler) pause() {
 // returns the highest sent sequence number.
 func (s *indexHandler) sendIndexTo(ctx context.Context) error {
 	initial := s.localPrevSequence == 0
+	if !initial && s.twoPhaseDeletes && !s.folderIsArchive {
+		if err := s.sendTombstones(ctx); err != nil {
+			return err
+		}
+	}
+	sent, deletes := 0, 0
 	batch := NewFileInfoBatch(nil)
 	var batchError error
 	batch.SetFlushFunc(func(fs []protocol.FileInfo) error {
@@ -344,15 +373,70 @@ func (s *indexHandler) sendIndexTo(ctx context.Context) error {
 			continue
 		}
 
+		// Local deletions in an archive folder stay local; the other
+		// devices keep the last version we announced.
+		if s.folderIsArchive && fi.IsDeleted() {
+			continue
+		}
+
+		// The peer would discard what is below the directories it ignores.
+		if s.ignoredPrefixes.ignores(fi.Name) {
+			continue
+		}
+
 		f = prepareFileInfoForIndex(f)
 
 		previousWasDelete = f.IsDeleted()
+		if previousWasDelete {
+			deletes++
+		}
+		sent++
 
 		batch.Append(f)
 	}
+	s.mostlyDeletes = 2*deletes > sent
 	return batch.Flush()
 }
 
+// sendTombstones sends the topmost deleted directories among a large
+// backlog of local changes, ahead of the changes themselves. Only the part
+// of the backlog not looked at before is scanned.
+func (s *indexHandler) sendTombstones(ctx context.Context) error {
+	seq, err := s.sdb.GetDeviceSequence(s.folder, protocol.LocalDeviceID)
+	if err != nil {
+		return err
+	}
+	if seq-s.localPrevSequence < twoPhaseDeleteThreshold || seq <= s.tombstonesScanned {
+		return nil
+	}
+
+	var dirs []protocol.FileInfo
+	for fi, err := range itererr.Zip(s.sdb.AllLocalFilesBySequence(s.folder, protocol.LocalDeviceID, max(s.localPrevSequence, s.tombstonesScanned)+1, 0)) {
+		if err != nil {
+			return err
+		}
+		s.tombstonesScanned = fi.Sequence
+		if !fi.IsDirectory() || !fi.IsDeleted() {
+			continue
+		}
+		if s.folderIsReceiveEncrypted && fi.IsReceiveOnlyChanged() {
+			continue
+		}
+		dirs = append(dirs, prepareFileInfoForIndex(fi))
+	}
+	dirs = topmostDirs(dirs)
+	if len(dirs) == 0 {
+		return nil
+	}
+
+	l.Debugf("%v: Sending %d directory tombstones", s, len(dirs))
+	return s.conn.IndexUpdate(ctx, &protocol.IndexUpdate{
+		Folder:     s.folder,
+		Files:      dirs,
+		Tombstones: true,
+	})
+}
+
 func (s *indexHandler) receive(fs []protocol.FileInfo, update bool, op string, prevSequence, lastSequence int64) error {
 	deviceID := s.conn.DeviceID()
 
@@ -374,6 +458,13 @@ func (s *indexHandler) receive(fs []protocol.FileInfo, update bool, op string, p
 		if err := s.sdb.DropAllFiles(s.folder, deviceID); err != nil {
 			return err
 		}
+		// We may have forgotten their index ID to get the full index,
+		// having stopped ignoring something.
+		if s.remoteIndexID != 0 {
+			if err := s.sdb.SetIndexID(s.folder, deviceID, s.remoteIndexID); err != nil {
+				return err
+			}
+		}
 	}
 
 	l.Debugf("Received %d files for %s from %s, prevSeq=%d, lastSeq=%d", len(fs), s.folder, deviceID.Short(), prevSequence, lastSequence)
@@ -434,6 +525,9 @@ func (s *indexHandler) receive(fs []protocol.FileInfo, update bool, op string, p
 		})
 	}
 
+	if err := faultinject.Check(faultinject.DBWrite, s.folder); err != nil {
+		return err
+	}
 	if err := s.sdb.Update(s.folder, deviceID, fs); err != nil {
 		return err
 	}
@@ -58,12 +58,83 @@ var (
 		Help:      "Total amount of data processed during folder syncing, per folder ID and data source (network/local_origin/local_other/skipped)",
 	}, []string{"folder", "source"})
 
+	metricFolderPullRequestedBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_pull_requested_bytes_total",
+		Help:      "Total amount of block data requested from other devices, per folder and device ID",
+	}, []string{"folder", "device"})
+	metricFolderPullWaitSeconds = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_pull_wait_seconds_total",
+		Help:      "Total time block requests waited for their turn to be sent, per folder and device ID",
+	}, []string{"folder", "device"})
+
 	metricFolderConflictsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: "syncthing",
 		Subsystem: "model",
 		Name:      "folder_conflicts_total",
 		Help:      "Total number of conflicts",
 	}, []string{"folder"})
+
+	metricFolderPullStageQueued = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_pull_stage_queued",
+		Help:      "Items waiting for a puller pipeline stage, per folder ID and stage",
+	}, []string{"folder", "stage"})
+	metricFolderPullStageActive = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_pull_stage_active",
+		Help:      "Items being worked on by a puller pipeline stage, per folder ID and stage",
+	}, []string{"folder", "stage"})
+	metricFolderPullStageItems = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_pull_stage_items_total",
+		Help:      "Total number of items through a puller pipeline stage, per folder ID and stage",
+	}, []string{"folder", "stage"})
+	metricFolderPullStageSeconds = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_pull_stage_seconds_total",
+		Help:      "Total time items spent being worked on by a puller pipeline stage, per folder ID and stage",
+	}, []string{"folder", "stage"})
+	metricFolderPullStalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_pull_stalls_total",
+		Help:      "Total number of times pulling made no progress for the stall timeout, per folder ID",
+	}, []string{"folder"})
+	metricFolderTempReclaimedBytes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_temp_reclaimed_bytes_total",
+		Help:      "Total size of leftover temporary files removed, per folder ID",
+	}, []string{"folder"})
+
+	metricFolderOwnershipViolations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_ownership_violations_total",
+		Help:      "Total number of local changes under path prefixes owned by another device, per folder ID",
+	}, []string{"folder"})
+
+	metricFolderServedBlocksCorrupt = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_served_blocks_corrupt_total",
+		Help:      "Total number of blocks found corrupt on disk when serving them to other devices, per folder ID",
+	}, []string{"folder"})
+
+	metricFolderRescanInterval = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "syncthing",
+		Subsystem: "model",
+		Name:      "folder_rescan_interval_seconds",
+		Help:      "Effective interval between periodic full scans, per folder ID",
+	}, []string{"folder"})
 )
 
 const (
@@ -96,4 +167,9 @@ func registerFolderMetrics(folderID string) {
 	metricFolderProcessedBytesTotal.WithLabelValues(folderID, metricSourceLocalOther)
 	metricFolderProcessedBytesTotal.WithLabelValues(folderID, metricSourceSkipped)
 	metricFolderConflictsTotal.WithLabelValues(folderID)
+	metricFolderPullStalls.WithLabelValues(folderID)
+	metricFolderTempReclaimedBytes.WithLabelValues(folderID)
+	metricFolderOwnershipViolations.WithLabelValues(folderID)
+	metricFolderServedBlocksCorrupt.WithLabelValues(folderID)
+	metricFolderRescanInterval.WithLabelValues(folderID)
 }
@@ -20,6 +20,19 @@ type FolderSummaryService struct {
 	serveReturnsOnCall map[int]struct {
 		result1 error
 	}
+	SummariesStub        func(context.Context) (*model.FolderSummarySnapshot, error)
+	summariesMutex       sync.RWMutex
+	summariesArgsForCall []struct {
+		arg1 context.Context
+	}
+	summariesReturns struct {
+		result1 *model.FolderSummarySnapshot
+		result2 error
+	}
+	summariesReturnsOnCall map[int]struct {
+		result1 *model.FolderSummarySnapshot
+		result2 error
+	}
 	SummaryStub        func(string) (*model.FolderSummary, error)
 	summaryMutex       sync.RWMutex
 	summaryArgsForCall []struct {
@@ -98,6 +111,70 @@ func (fake *FolderSummaryService) ServeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FolderSummaryService) Summaries(arg1 context.Context) (*model.FolderSummarySnapshot, error) {
+	fake.summariesMutex.Lock()
+	ret, specificReturn := fake.summariesReturnsOnCall[len(fake.summariesArgsForCall)]
+	fake.summariesArgsForCall = append(fake.summariesArgsForCall, struct {
+		arg1 context.Context
+	}{arg1})
+	stub := fake.SummariesStub
+	fakeReturns := fake.summariesReturns
+	fake.recordInvocation("Summaries", []interface{}{arg1})
+	fake.summariesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FolderSummaryService) SummariesCallCount() int {
+	fake.summariesMutex.RLock()
+	defer fake.summariesMutex.RUnlock()
+	return len(fake.summariesArgsForCall)
+}
+
+func (fake *FolderSummaryService) SummariesCalls(stub func(context.Context) (*model.FolderSummarySnapshot, error)) {
+	fake.summariesMutex.Lock()
+	defer fake.summariesMutex.Unlock()
+	fake.SummariesStub = stub
+}
+
+func (fake *FolderSummaryService) SummariesArgsForCall(i int) context.Context {
+	fake.summariesMutex.RLock()
+	defer fake.summariesMutex.RUnlock()
+	argsForCall := fake.summariesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FolderSummaryService) SummariesReturns(result1 *model.FolderSummarySnapshot, result2 error) {
+	fake.summariesMutex.Lock()
+	defer fake.summariesMutex.Unlock()
+	fake.SummariesStub = nil
+	fake.summariesReturns = struct {
+		result1 *model.FolderSummarySnapshot
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FolderSummaryService) SummariesReturnsOnCall(i int, result1 *model.FolderSummarySnapshot, result2 error) {
+	fake.summariesMutex.Lock()
+	defer fake.summariesMutex.Unlock()
+	fake.SummariesStub = nil
+	if fake.summariesReturnsOnCall == nil {
+		fake.summariesReturnsOnCall = make(map[int]struct {
+			result1 *model.FolderSummarySnapshot
+			result2 error
+		})
+	}
+	fake.summariesReturnsOnCall[i] = struct {
+		result1 *model.FolderSummarySnapshot
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FolderSummaryService) Summary(arg1 string) (*model.FolderSummary, error) {
 	fake.summaryMutex.Lock()
 	ret, specificReturn := fake.summaryReturnsOnCall[len(fake.summaryArgsForCall)]
@@ -19,6 +19,17 @@ import (
 )
 
 type HealthMonitoringModel struct {
+	AcknowledgeChangeAnomalyStub        func(string) error
+	acknowledgeChangeAnomalyMutex       sync.RWMutex
+	acknowledgeChangeAnomalyArgsForCall []struct {
+		arg1 string
+	}
+	acknowledgeChangeAnomalyReturns struct {
+		result1 error
+	}
+	acknowledgeChangeAnomalyReturnsOnCall map[int]struct {
+		result1 error
+	}
 	AddConnectionStub        func(protocol.Connection, protocol.Hello)
 	addConnectionMutex       sync.RWMutex
 	addConnectionArgsForCall []struct {
@@ -38,6 +49,67 @@ type HealthMonitoringModel struct {
 		result1 iter.Seq[db.FileMetadata]
 		result2 func() error
 	}
+	ApproveConfigSyncStub        func(protocol.DeviceID, string, string) error
+	approveConfigSyncMutex       sync.RWMutex
+	approveConfigSyncArgsForCall []struct {
+		arg1 protocol.DeviceID
+		arg2 string
+		arg3 string
+	}
+	approveConfigSyncReturns struct {
+		result1 error
+	}
+	approveConfigSyncReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ApproveDeviceRevocationStub        func(protocol.DeviceID) (model.DeviceRevocation, error)
+	approveDeviceRevocationMutex       sync.RWMutex
+	approveDeviceRevocationArgsForCall []struct {
+		arg1 protocol.DeviceID
+	}
+	approveDeviceRevocationReturns struct {
+		result1 model.DeviceRevocation
+		result2 error
+	}
+	approveDeviceRevocationReturnsOnCall map[int]struct {
+		result1 model.DeviceRevocation
+		result2 error
+	}
+	ApproveFolderHandoverStub        func(string, protocol.DeviceID) error
+	approveFolderHandoverMutex       sync.RWMutex
+	approveFolderHandoverArgsForCall []struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}
+	approveFolderHandoverReturns struct {
+		result1 error
+	}
+	approveFolderHandoverReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ApproveIdentityChangeStub        func(protocol.DeviceID) error
+	approveIdentityChangeMutex       sync.RWMutex
+	approveIdentityChangeArgsForCall []struct {
+		arg1 protocol.DeviceID
+	}
+	approveIdentityChangeReturns struct {
+		result1 error
+	}
+	approveIdentityChangeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ApproveOwnershipClaimStub        func(string, protocol.DeviceID) error
+	approveOwnershipClaimMutex       sync.RWMutex
+	approveOwnershipClaimArgsForCall []struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}
+	approveOwnershipClaimReturns struct {
+		result1 error
+	}
+	approveOwnershipClaimReturnsOnCall map[int]struct {
+		result1 error
+	}
 	AvailabilityStub        func(string, protocol.FileInfo, protocol.BlockInfo) ([]model.Availability, error)
 	availabilityMutex       sync.RWMutex
 	availabilityArgsForCall []struct {
@@ -59,6 +131,40 @@ type HealthMonitoringModel struct {
 		arg1 string
 		arg2 string
 	}
+	ChangeAnomaliesStub        func() map[string]model.ChangeAnomaly
+	changeAnomaliesMutex       sync.RWMutex
+	changeAnomaliesArgsForCall []struct {
+	}
+	changeAnomaliesReturns struct {
+		result1 map[string]model.ChangeAnomaly
+	}
+	changeAnomaliesReturnsOnCall map[int]struct {
+		result1 map[string]model.ChangeAnomaly
+	}
+	CleanTempFilesStub        func(string) (*model.TempCleanupReport, error)
+	cleanTempFilesMutex       sync.RWMutex
+	cleanTempFilesArgsForCall []struct {
+		arg1 string
+	}
+	cleanTempFilesReturns struct {
+		result1 *model.TempCleanupReport
+		result2 error
+	}
+	cleanTempFilesReturnsOnCall map[int]struct {
+		result1 *model.TempCleanupReport
+		result2 error
+	}
+	ClearFolderHandoverStub        func(string) error
+	clearFolderHandoverMutex       sync.RWMutex
+	clearFolderHandoverArgsForCall []struct {
+		arg1 string
+	}
+	clearFolderHandoverReturns struct {
+		result1 error
+	}
+	clearFolderHandoverReturnsOnCall map[int]struct {
+		result1 error
+	}
 	ClosedStub        func(protocol.Connection, error)
 	closedMutex       sync.RWMutex
 	closedArgsForCall []struct {
@@ -91,6 +197,35 @@ type HealthMonitoringModel struct {
 		result1 model.FolderCompletion
 		result2 error
 	}
+	ConflictVersionsStub        func(string, string) (protocol.FileInfo, protocol.FileInfo, error)
+	conflictVersionsMutex       sync.RWMutex
+	conflictVersionsArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	conflictVersionsReturns struct {
+		result1 protocol.FileInfo
+		result2 protocol.FileInfo
+		result3 error
+	}
+	conflictVersionsReturnsOnCall map[int]struct {
+		result1 protocol.FileInfo
+		result2 protocol.FileInfo
+		result3 error
+	}
+	ConflictsStub        func(string) ([]model.Conflict, error)
+	conflictsMutex       sync.RWMutex
+	conflictsArgsForCall []struct {
+		arg1 string
+	}
+	conflictsReturns struct {
+		result1 []model.Conflict
+		result2 error
+	}
+	conflictsReturnsOnCall map[int]struct {
+		result1 []model.Conflict
+		result2 error
+	}
 	ConnectedToStub        func(protocol.DeviceID) bool
 	connectedToMutex       sync.RWMutex
 	connectedToArgsForCall []struct {
@@ -165,6 +300,28 @@ type HealthMonitoringModel struct {
 		arg1 string
 		arg2 time.Duration
 	}
+	DeviceAddressLedgersStub        func() (map[protocol.DeviceID][]stats.AddressLedgerEntry, error)
+	deviceAddressLedgersMutex       sync.RWMutex
+	deviceAddressLedgersArgsForCall []struct{}
+	deviceAddressLedgersReturns     struct {
+		result1 map[protocol.DeviceID][]stats.AddressLedgerEntry
+		result2 error
+	}
+	deviceAddressLedgersReturnsOnCall map[int]struct {
+		result1 map[protocol.DeviceID][]stats.AddressLedgerEntry
+		result2 error
+	}
+	DeviceRevocationsStub        func() ([]model.DeviceRevocation, error)
+	deviceRevocationsMutex       sync.RWMutex
+	deviceRevocationsArgsForCall []struct{}
+	deviceRevocationsReturns     struct {
+		result1 []model.DeviceRevocation
+		result2 error
+	}
+	deviceRevocationsReturnsOnCall map[int]struct {
+		result1 []model.DeviceRevocation
+		result2 error
+	}
 	DeviceStatisticsStub        func() (map[protocol.DeviceID]stats.DeviceStatistics, error)
 	deviceStatisticsMutex       sync.RWMutex
 	deviceStatisticsArgsForCall []struct {
@@ -177,6 +334,17 @@ type HealthMonitoringModel struct {
 		result1 map[protocol.DeviceID]stats.DeviceStatistics
 		result2 error
 	}
+	DismissIdentityChangeStub        func(protocol.DeviceID) error
+	dismissIdentityChangeMutex       sync.RWMutex
+	dismissIdentityChangeArgsForCall []struct {
+		arg1 protocol.DeviceID
+	}
+	dismissIdentityChangeReturns struct {
+		result1 error
+	}
+	dismissIdentityChangeReturnsOnCall map[int]struct {
+		result1 error
+	}
 	DismissPendingDeviceStub        func(protocol.DeviceID) error
 	dismissPendingDeviceMutex       sync.RWMutex
 	dismissPendingDeviceArgsForCall []struct {
@@ -212,6 +380,60 @@ type HealthMonitoringModel struct {
 	downloadProgressReturnsOnCall map[int]struct {
 		result1 error
 	}
+	EncryptionRecoveryCodeStub        func(string, protocol.DeviceID) (string, error)
+	encryptionRecoveryCodeMutex       sync.RWMutex
+	encryptionRecoveryCodeArgsForCall []struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}
+	encryptionRecoveryCodeReturns struct {
+		result1 string
+		result2 error
+	}
+	encryptionRecoveryCodeReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	FolderAtTimeStub        func(string, string, time.Time, string) (*model.FolderAtTimeReport, error)
+	folderAtTimeMutex       sync.RWMutex
+	folderAtTimeArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 time.Time
+		arg4 string
+	}
+	folderAtTimeReturns struct {
+		result1 *model.FolderAtTimeReport
+		result2 error
+	}
+	folderAtTimeReturnsOnCall map[int]struct {
+		result1 *model.FolderAtTimeReport
+		result2 error
+	}
+	FolderCapabilitiesStub        func(string) (*model.FolderCapabilities, error)
+	folderCapabilitiesMutex       sync.RWMutex
+	folderCapabilitiesArgsForCall []struct {
+		arg1 string
+	}
+	folderCapabilitiesReturns struct {
+		result1 *model.FolderCapabilities
+		result2 error
+	}
+	folderCapabilitiesReturnsOnCall map[int]struct {
+		result1 *model.FolderCapabilities
+		result2 error
+	}
+	FolderDependencyWaitStub        func(string) string
+	folderDependencyWaitMutex       sync.RWMutex
+	folderDependencyWaitArgsForCall []struct {
+		arg1 string
+	}
+	folderDependencyWaitReturns struct {
+		result1 string
+	}
+	folderDependencyWaitReturnsOnCall map[int]struct {
+		result1 string
+	}
 	FolderErrorsStub        func(string) ([]model.FileError, error)
 	folderErrorsMutex       sync.RWMutex
 	folderErrorsArgsForCall []struct {
@@ -225,6 +447,17 @@ type HealthMonitoringModel struct {
 		result1 []model.FileError
 		result2 error
 	}
+	FolderHandoversStub        func() ([]model.FolderHandover, error)
+	folderHandoversMutex       sync.RWMutex
+	folderHandoversArgsForCall []struct{}
+	folderHandoversReturns     struct {
+		result1 []model.FolderHandover
+		result2 error
+	}
+	folderHandoversReturnsOnCall map[int]struct {
+		result1 []model.FolderHandover
+		result2 error
+	}
 	FolderProgressBytesCompletedStub        func(string) int64
 	folderProgressBytesCompletedMutex       sync.RWMutex
 	folderProgressBytesCompletedArgsForCall []struct {
@@ -236,6 +469,30 @@ type HealthMonitoringModel struct {
 	folderProgressBytesCompletedReturnsOnCall map[int]struct {
 		result1 int64
 	}
+	FolderQueuePositionStub        func(string) (string, int)
+	folderQueuePositionMutex       sync.RWMutex
+	folderQueuePositionArgsForCall []struct {
+		arg1 string
+	}
+	folderQueuePositionReturns struct {
+		result1 string
+		result2 int
+	}
+	folderQueuePositionReturnsOnCall map[int]struct {
+		result1 string
+		result2 int
+	}
+	FolderQuotaExceededStub        func(string) int64
+	folderQuotaExceededMutex       sync.RWMutex
+	folderQuotaExceededArgsForCall []struct {
+		arg1 string
+	}
+	folderQuotaExceededReturns struct {
+		result1 int64
+	}
+	folderQuotaExceededReturnsOnCall map[int]struct {
+		result1 int64
+	}
 	FolderStatisticsStub        func() (map[string]stats.FolderStatistics, error)
 	folderStatisticsMutex       sync.RWMutex
 	folderStatisticsArgsForCall []struct {
@@ -248,6 +505,19 @@ type HealthMonitoringModel struct {
 		result1 map[string]stats.FolderStatistics
 		result2 error
 	}
+	FolderWriteVerificationStub        func(string) (model.WriteVerificationStats, bool)
+	folderWriteVerificationMutex       sync.RWMutex
+	folderWriteVerificationArgsForCall []struct {
+		arg1 string
+	}
+	folderWriteVerificationReturns struct {
+		result1 model.WriteVerificationStats
+		result2 bool
+	}
+	folderWriteVerificationReturnsOnCall map[int]struct {
+		result1 model.WriteVerificationStats
+		result2 bool
+	}
 	GetAllFoldersHealthStatusStub        func() map[string]config.FolderHealthStatus
 	getAllFoldersHealthStatusMutex       sync.RWMutex
 	getAllFoldersHealthStatusArgsForCall []struct {
@@ -336,6 +606,16 @@ type HealthMonitoringModel struct {
 		result1 db.Counts
 		result2 error
 	}
+	IdentityChangesStub        func() map[protocol.DeviceID]model.IdentityChange
+	identityChangesMutex       sync.RWMutex
+	identityChangesArgsForCall []struct {
+	}
+	identityChangesReturns struct {
+		result1 map[protocol.DeviceID]model.IdentityChange
+	}
+	identityChangesReturnsOnCall map[int]struct {
+		result1 map[protocol.DeviceID]model.IdentityChange
+	}
 	IndexStub        func(protocol.Connection, *protocol.Index) error
 	indexMutex       sync.RWMutex
 	indexArgsForCall []struct {
@@ -375,6 +655,22 @@ type HealthMonitoringModel struct {
 		result2 []string
 		result3 error
 	}
+	LocalChangeReportStub        func(string, int, int, bool) ([]model.LocalChange, error)
+	localChangeReportMutex       sync.RWMutex
+	localChangeReportArgsForCall []struct {
+		arg1 string
+		arg2 int
+		arg3 int
+		arg4 bool
+	}
+	localChangeReportReturns struct {
+		result1 []model.LocalChange
+		result2 error
+	}
+	localChangeReportReturnsOnCall map[int]struct {
+		result1 []model.LocalChange
+		result2 error
+	}
 	LocalChangedFolderFilesStub        func(string, int, int) ([]protocol.FileInfo, error)
 	localChangedFolderFilesMutex       sync.RWMutex
 	localChangedFolderFilesArgsForCall []struct {
@@ -433,6 +729,21 @@ type HealthMonitoringModel struct {
 		result1 db.Counts
 		result2 error
 	}
+	MergeFoldersStub        func(string, string, bool) (*model.FolderMergeReport, error)
+	mergeFoldersMutex       sync.RWMutex
+	mergeFoldersArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 bool
+	}
+	mergeFoldersReturns struct {
+		result1 *model.FolderMergeReport
+		result2 error
+	}
+	mergeFoldersReturnsOnCall map[int]struct {
+		result1 *model.FolderMergeReport
+		result2 error
+	}
 	NeedFolderFilesStub        func(string, int, int) ([]protocol.FileInfo, []protocol.FileInfo, []protocol.FileInfo, error)
 	needFolderFilesMutex       sync.RWMutex
 	needFolderFilesArgsForCall []struct {
@@ -484,6 +795,29 @@ type HealthMonitoringModel struct {
 	overrideArgsForCall []struct {
 		arg1 string
 	}
+	PendingConfigSyncStub        func() ([]model.ConfigSyncChange, error)
+	pendingConfigSyncMutex       sync.RWMutex
+	pendingConfigSyncArgsForCall []struct {
+	}
+	pendingConfigSyncReturns struct {
+		result1 []model.ConfigSyncChange
+		result2 error
+	}
+	pendingConfigSyncReturnsOnCall map[int]struct {
+		result1 []model.ConfigSyncChange
+		result2 error
+	}
+	PendingDeviceRevocationsStub        func() ([]model.PendingDeviceRevocation, error)
+	pendingDeviceRevocationsMutex       sync.RWMutex
+	pendingDeviceRevocationsArgsForCall []struct{}
+	pendingDeviceRevocationsReturns     struct {
+		result1 []model.PendingDeviceRevocation
+		result2 error
+	}
+	pendingDeviceRevocationsReturnsOnCall map[int]struct {
+		result1 []model.PendingDeviceRevocation
+		result2 error
+	}
 	PendingDevicesStub        func() (map[protocol.DeviceID]db.ObservedDevice, error)
 	pendingDevicesMutex       sync.RWMutex
 	pendingDevicesArgsForCall []struct {
@@ -496,6 +830,17 @@ type HealthMonitoringModel struct {
 		result1 map[protocol.DeviceID]db.ObservedDevice
 		result2 error
 	}
+	PendingFolderHandoversStub        func() ([]model.PendingFolderHandover, error)
+	pendingFolderHandoversMutex       sync.RWMutex
+	pendingFolderHandoversArgsForCall []struct{}
+	pendingFolderHandoversReturns     struct {
+		result1 []model.PendingFolderHandover
+		result2 error
+	}
+	pendingFolderHandoversReturnsOnCall map[int]struct {
+		result1 []model.PendingFolderHandover
+		result2 error
+	}
 	PendingFoldersStub        func(protocol.DeviceID) (map[string]db.PendingFolder, error)
 	pendingFoldersMutex       sync.RWMutex
 	pendingFoldersArgsForCall []struct {
@@ -509,6 +854,98 @@ type HealthMonitoringModel struct {
 		result1 map[string]db.PendingFolder
 		result2 error
 	}
+	PendingOwnershipClaimsStub        func() ([]model.PendingOwnershipClaim, error)
+	pendingOwnershipClaimsMutex       sync.RWMutex
+	pendingOwnershipClaimsArgsForCall []struct{}
+	pendingOwnershipClaimsReturns     struct {
+		result1 []model.PendingOwnershipClaim
+		result2 error
+	}
+	pendingOwnershipClaimsReturnsOnCall map[int]struct {
+		result1 []model.PendingOwnershipClaim
+		result2 error
+	}
+	PrefixCompletionStub        func(protocol.DeviceID, string, string) (model.FolderCompletion, error)
+	prefixCompletionMutex       sync.RWMutex
+	prefixCompletionArgsForCall []struct {
+		arg1 protocol.DeviceID
+		arg2 string
+		arg3 string
+	}
+	prefixCompletionReturns struct {
+		result1 model.FolderCompletion
+		result2 error
+	}
+	prefixCompletionReturnsOnCall map[int]struct {
+		result1 model.FolderCompletion
+		result2 error
+	}
+	ProbeFolderCapabilitiesStub        func(string) (*model.FolderCapabilities, error)
+	probeFolderCapabilitiesMutex       sync.RWMutex
+	probeFolderCapabilitiesArgsForCall []struct {
+		arg1 string
+	}
+	probeFolderCapabilitiesReturns struct {
+		result1 *model.FolderCapabilities
+		result2 error
+	}
+	probeFolderCapabilitiesReturnsOnCall map[int]struct {
+		result1 *model.FolderCapabilities
+		result2 error
+	}
+	PromoteConfigSyncSpareStub        func(protocol.DeviceID) ([]string, error)
+	promoteConfigSyncSpareMutex       sync.RWMutex
+	promoteConfigSyncSpareArgsForCall []struct {
+		arg1 protocol.DeviceID
+	}
+	promoteConfigSyncSpareReturns struct {
+		result1 []string
+		result2 error
+	}
+	promoteConfigSyncSpareReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	PullPreviewStub        func(string) (*model.PullPreview, error)
+	pullPreviewMutex       sync.RWMutex
+	pullPreviewArgsForCall []struct {
+		arg1 string
+	}
+	pullPreviewReturns struct {
+		result1 *model.PullPreview
+		result2 error
+	}
+	pullPreviewReturnsOnCall map[int]struct {
+		result1 *model.PullPreview
+		result2 error
+	}
+	QueueDeviceMessageStub        func(protocol.DeviceID, string, string, []byte) error
+	queueDeviceMessageMutex       sync.RWMutex
+	queueDeviceMessageArgsForCall []struct {
+		arg1 protocol.DeviceID
+		arg2 string
+		arg3 string
+		arg4 []byte
+	}
+	queueDeviceMessageReturns struct {
+		result1 error
+	}
+	queueDeviceMessageReturnsOnCall map[int]struct {
+		result1 error
+	}
+	QueuedDeviceMessagesStub        func(protocol.DeviceID) ([]model.QueuedMessage, error)
+	queuedDeviceMessagesMutex       sync.RWMutex
+	queuedDeviceMessagesArgsForCall []struct {
+		arg1 protocol.DeviceID
+	}
+	queuedDeviceMessagesReturns struct {
+		result1 []model.QueuedMessage
+		result2 error
+	}
+	queuedDeviceMessagesReturnsOnCall map[int]struct {
+		result1 []model.QueuedMessage
+		result2 error
+	}
 	ReceiveOnlySizeStub        func(string) (db.Counts, error)
 	receiveOnlySizeMutex       sync.RWMutex
 	receiveOnlySizeArgsForCall []struct {
@@ -522,6 +959,54 @@ type HealthMonitoringModel struct {
 		result1 db.Counts
 		result2 error
 	}
+	RejectConfigSyncStub        func(protocol.DeviceID, string, string) error
+	rejectConfigSyncMutex       sync.RWMutex
+	rejectConfigSyncArgsForCall []struct {
+		arg1 protocol.DeviceID
+		arg2 string
+		arg3 string
+	}
+	rejectConfigSyncReturns struct {
+		result1 error
+	}
+	rejectConfigSyncReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RejectDeviceRevocationStub        func(protocol.DeviceID) error
+	rejectDeviceRevocationMutex       sync.RWMutex
+	rejectDeviceRevocationArgsForCall []struct {
+		arg1 protocol.DeviceID
+	}
+	rejectDeviceRevocationReturns struct {
+		result1 error
+	}
+	rejectDeviceRevocationReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RejectFolderHandoverStub        func(string, protocol.DeviceID) error
+	rejectFolderHandoverMutex       sync.RWMutex
+	rejectFolderHandoverArgsForCall []struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}
+	rejectFolderHandoverReturns struct {
+		result1 error
+	}
+	rejectFolderHandoverReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RejectOwnershipClaimStub        func(string, protocol.DeviceID) error
+	rejectOwnershipClaimMutex       sync.RWMutex
+	rejectOwnershipClaimArgsForCall []struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}
+	rejectOwnershipClaimReturns struct {
+		result1 error
+	}
+	rejectOwnershipClaimReturnsOnCall map[int]struct {
+		result1 error
+	}
 	RemoteNeedFolderFilesStub        func(string, protocol.DeviceID, int, int) ([]protocol.FileInfo, error)
 	remoteNeedFolderFilesMutex       sync.RWMutex
 	remoteNeedFolderFilesArgsForCall []struct {
@@ -565,6 +1050,22 @@ type HealthMonitoringModel struct {
 		result1 protocol.RequestResponse
 		result2 error
 	}
+	RequestFolderPreviewStub        func(context.Context, protocol.DeviceID, string, int) (*protocol.FolderPreview, error)
+	requestFolderPreviewMutex       sync.RWMutex
+	requestFolderPreviewArgsForCall []struct {
+		arg1 context.Context
+		arg2 protocol.DeviceID
+		arg3 string
+		arg4 int
+	}
+	requestFolderPreviewReturns struct {
+		result1 *protocol.FolderPreview
+		result2 error
+	}
+	requestFolderPreviewReturnsOnCall map[int]struct {
+		result1 *protocol.FolderPreview
+		result2 error
+	}
 	RequestGlobalStub        func(context.Context, protocol.DeviceID, string, string, int, int64, int, []byte, bool) ([]byte, error)
 	requestGlobalMutex       sync.RWMutex
 	requestGlobalArgsForCall []struct {
@@ -586,9 +1087,20 @@ type HealthMonitoringModel struct {
 		result1 []byte
 		result2 error
 	}
-	ResetFolderStub        func(string) error
-	resetFolderMutex       sync.RWMutex
-	resetFolderArgsForCall []struct {
+	RescanIntervalStub        func(string) time.Duration
+	rescanIntervalMutex       sync.RWMutex
+	rescanIntervalArgsForCall []struct {
+		arg1 string
+	}
+	rescanIntervalReturns struct {
+		result1 time.Duration
+	}
+	rescanIntervalReturnsOnCall map[int]struct {
+		result1 time.Duration
+	}
+	ResetFolderStub        func(string) error
+	resetFolderMutex       sync.RWMutex
+	resetFolderArgsForCall []struct {
 		arg1 string
 	}
 	resetFolderReturns struct {
@@ -597,6 +1109,19 @@ type HealthMonitoringModel struct {
 	resetFolderReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ResolveConflictStub        func(string, string, string) error
+	resolveConflictMutex       sync.RWMutex
+	resolveConflictArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}
+	resolveConflictReturns struct {
+		result1 error
+	}
+	resolveConflictReturnsOnCall map[int]struct {
+		result1 error
+	}
 	RestoreFolderVersionsStub        func(string, map[string]time.Time) (map[string]error, error)
 	restoreFolderVersionsMutex       sync.RWMutex
 	restoreFolderVersionsArgsForCall []struct {
@@ -616,6 +1141,20 @@ type HealthMonitoringModel struct {
 	revertArgsForCall []struct {
 		arg1 string
 	}
+	RevokeDeviceStub        func(protocol.DeviceID, bool) (model.DeviceRevocation, error)
+	revokeDeviceMutex       sync.RWMutex
+	revokeDeviceArgsForCall []struct {
+		arg1 protocol.DeviceID
+		arg2 bool
+	}
+	revokeDeviceReturns struct {
+		result1 model.DeviceRevocation
+		result2 error
+	}
+	revokeDeviceReturnsOnCall map[int]struct {
+		result1 model.DeviceRevocation
+		result2 error
+	}
 	ScanFolderStub        func(string) error
 	scanFolderMutex       sync.RWMutex
 	scanFolderArgsForCall []struct {
@@ -691,6 +1230,21 @@ type HealthMonitoringModel struct {
 	setIgnoresReturnsOnCall map[int]struct {
 		result1 error
 	}
+	StartFolderHandoverStub        func(string, protocol.DeviceID, string) (model.FolderHandover, error)
+	startFolderHandoverMutex       sync.RWMutex
+	startFolderHandoverArgsForCall []struct {
+		arg1 string
+		arg2 protocol.DeviceID
+		arg3 string
+	}
+	startFolderHandoverReturns struct {
+		result1 model.FolderHandover
+		result2 error
+	}
+	startFolderHandoverReturnsOnCall map[int]struct {
+		result1 model.FolderHandover
+		result2 error
+	}
 	StateStub        func(string) (string, time.Time, error)
 	stateMutex       sync.RWMutex
 	stateArgsForCall []struct {
@@ -713,6 +1267,19 @@ type HealthMonitoringModel struct {
 		arg2 int
 		arg3 bool
 	}
+	WaitForFolderIdleStub        func(context.Context, string, time.Duration) error
+	waitForFolderIdleMutex       sync.RWMutex
+	waitForFolderIdleArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Duration
+	}
+	waitForFolderIdleReturns struct {
+		result1 error
+	}
+	waitForFolderIdleReturnsOnCall map[int]struct {
+		result1 error
+	}
 	WatchErrorStub        func(string) error
 	watchErrorMutex       sync.RWMutex
 	watchErrorArgsForCall []struct {
@@ -728,6 +1295,67 @@ type HealthMonitoringModel struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *HealthMonitoringModel) AcknowledgeChangeAnomaly(arg1 string) error {
+	fake.acknowledgeChangeAnomalyMutex.Lock()
+	ret, specificReturn := fake.acknowledgeChangeAnomalyReturnsOnCall[len(fake.acknowledgeChangeAnomalyArgsForCall)]
+	fake.acknowledgeChangeAnomalyArgsForCall = append(fake.acknowledgeChangeAnomalyArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.AcknowledgeChangeAnomalyStub
+	fakeReturns := fake.acknowledgeChangeAnomalyReturns
+	fake.recordInvocation("AcknowledgeChangeAnomaly", []interface{}{arg1})
+	fake.acknowledgeChangeAnomalyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *HealthMonitoringModel) AcknowledgeChangeAnomalyCallCount() int {
+	fake.acknowledgeChangeAnomalyMutex.RLock()
+	defer fake.acknowledgeChangeAnomalyMutex.RUnlock()
+	return len(fake.acknowledgeChangeAnomalyArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) AcknowledgeChangeAnomalyCalls(stub func(string) error) {
+	fake.acknowledgeChangeAnomalyMutex.Lock()
+	defer fake.acknowledgeChangeAnomalyMutex.Unlock()
+	fake.AcknowledgeChangeAnomalyStub = stub
+}
+
+func (fake *HealthMonitoringModel) AcknowledgeChangeAnomalyArgsForCall(i int) string {
+	fake.acknowledgeChangeAnomalyMutex.RLock()
+	defer fake.acknowledgeChangeAnomalyMutex.RUnlock()
+	argsForCall := fake.acknowledgeChangeAnomalyArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) AcknowledgeChangeAnomalyReturns(result1 error) {
+	fake.acknowledgeChangeAnomalyMutex.Lock()
+	defer fake.acknowledgeChangeAnomalyMutex.Unlock()
+	fake.AcknowledgeChangeAnomalyStub = nil
+	fake.acknowledgeChangeAnomalyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) AcknowledgeChangeAnomalyReturnsOnCall(i int, result1 error) {
+	fake.acknowledgeChangeAnomalyMutex.Lock()
+	defer fake.acknowledgeChangeAnomalyMutex.Unlock()
+	fake.AcknowledgeChangeAnomalyStub = nil
+	if fake.acknowledgeChangeAnomalyReturnsOnCall == nil {
+		fake.acknowledgeChangeAnomalyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.acknowledgeChangeAnomalyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *HealthMonitoringModel) AddConnection(arg1 protocol.Connection, arg2 protocol.Hello) {
 	fake.addConnectionMutex.Lock()
 	fake.addConnectionArgsForCall = append(fake.addConnectionArgsForCall, struct {
@@ -825,6 +1453,318 @@ func (fake *HealthMonitoringModel) AllGlobalFilesReturnsOnCall(i int, result1 it
 	}{result1, result2}
 }
 
+func (fake *HealthMonitoringModel) ApproveConfigSync(arg1 protocol.DeviceID, arg2 string, arg3 string) error {
+	fake.approveConfigSyncMutex.Lock()
+	ret, specificReturn := fake.approveConfigSyncReturnsOnCall[len(fake.approveConfigSyncArgsForCall)]
+	fake.approveConfigSyncArgsForCall = append(fake.approveConfigSyncArgsForCall, struct {
+		arg1 protocol.DeviceID
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ApproveConfigSyncStub
+	fakeReturns := fake.approveConfigSyncReturns
+	fake.recordInvocation("ApproveConfigSync", []interface{}{arg1, arg2, arg3})
+	fake.approveConfigSyncMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *HealthMonitoringModel) ApproveConfigSyncCallCount() int {
+	fake.approveConfigSyncMutex.RLock()
+	defer fake.approveConfigSyncMutex.RUnlock()
+	return len(fake.approveConfigSyncArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) ApproveConfigSyncCalls(stub func(protocol.DeviceID, string, string) error) {
+	fake.approveConfigSyncMutex.Lock()
+	defer fake.approveConfigSyncMutex.Unlock()
+	fake.ApproveConfigSyncStub = stub
+}
+
+func (fake *HealthMonitoringModel) ApproveConfigSyncArgsForCall(i int) (protocol.DeviceID, string, string) {
+	fake.approveConfigSyncMutex.RLock()
+	defer fake.approveConfigSyncMutex.RUnlock()
+	argsForCall := fake.approveConfigSyncArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *HealthMonitoringModel) ApproveConfigSyncReturns(result1 error) {
+	fake.approveConfigSyncMutex.Lock()
+	defer fake.approveConfigSyncMutex.Unlock()
+	fake.ApproveConfigSyncStub = nil
+	fake.approveConfigSyncReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) ApproveConfigSyncReturnsOnCall(i int, result1 error) {
+	fake.approveConfigSyncMutex.Lock()
+	defer fake.approveConfigSyncMutex.Unlock()
+	fake.ApproveConfigSyncStub = nil
+	if fake.approveConfigSyncReturnsOnCall == nil {
+		fake.approveConfigSyncReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.approveConfigSyncReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) ApproveDeviceRevocation(arg1 protocol.DeviceID) (model.DeviceRevocation, error) {
+	fake.approveDeviceRevocationMutex.Lock()
+	ret, specificReturn := fake.approveDeviceRevocationReturnsOnCall[len(fake.approveDeviceRevocationArgsForCall)]
+	fake.approveDeviceRevocationArgsForCall = append(fake.approveDeviceRevocationArgsForCall, struct {
+		arg1 protocol.DeviceID
+	}{arg1})
+	stub := fake.ApproveDeviceRevocationStub
+	fakeReturns := fake.approveDeviceRevocationReturns
+	fake.recordInvocation("ApproveDeviceRevocation", []interface{}{arg1})
+	fake.approveDeviceRevocationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) ApproveDeviceRevocationCallCount() int {
+	fake.approveDeviceRevocationMutex.RLock()
+	defer fake.approveDeviceRevocationMutex.RUnlock()
+	return len(fake.approveDeviceRevocationArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) ApproveDeviceRevocationCalls(stub func(protocol.DeviceID) (model.DeviceRevocation, error)) {
+	fake.approveDeviceRevocationMutex.Lock()
+	defer fake.approveDeviceRevocationMutex.Unlock()
+	fake.ApproveDeviceRevocationStub = stub
+}
+
+func (fake *HealthMonitoringModel) ApproveDeviceRevocationArgsForCall(i int) protocol.DeviceID {
+	fake.approveDeviceRevocationMutex.RLock()
+	defer fake.approveDeviceRevocationMutex.RUnlock()
+	argsForCall := fake.approveDeviceRevocationArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) ApproveDeviceRevocationReturns(result1 model.DeviceRevocation, result2 error) {
+	fake.approveDeviceRevocationMutex.Lock()
+	defer fake.approveDeviceRevocationMutex.Unlock()
+	fake.ApproveDeviceRevocationStub = nil
+	fake.approveDeviceRevocationReturns = struct {
+		result1 model.DeviceRevocation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) ApproveDeviceRevocationReturnsOnCall(i int, result1 model.DeviceRevocation, result2 error) {
+	fake.approveDeviceRevocationMutex.Lock()
+	defer fake.approveDeviceRevocationMutex.Unlock()
+	fake.ApproveDeviceRevocationStub = nil
+	if fake.approveDeviceRevocationReturnsOnCall == nil {
+		fake.approveDeviceRevocationReturnsOnCall = make(map[int]struct {
+			result1 model.DeviceRevocation
+			result2 error
+		})
+	}
+	fake.approveDeviceRevocationReturnsOnCall[i] = struct {
+		result1 model.DeviceRevocation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) ApproveFolderHandover(arg1 string, arg2 protocol.DeviceID) error {
+	fake.approveFolderHandoverMutex.Lock()
+	ret, specificReturn := fake.approveFolderHandoverReturnsOnCall[len(fake.approveFolderHandoverArgsForCall)]
+	fake.approveFolderHandoverArgsForCall = append(fake.approveFolderHandoverArgsForCall, struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}{arg1, arg2})
+	stub := fake.ApproveFolderHandoverStub
+	fakeReturns := fake.approveFolderHandoverReturns
+	fake.recordInvocation("ApproveFolderHandover", []interface{}{arg1, arg2})
+	fake.approveFolderHandoverMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *HealthMonitoringModel) ApproveFolderHandoverCallCount() int {
+	fake.approveFolderHandoverMutex.RLock()
+	defer fake.approveFolderHandoverMutex.RUnlock()
+	return len(fake.approveFolderHandoverArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) ApproveFolderHandoverCalls(stub func(string, protocol.DeviceID) error) {
+	fake.approveFolderHandoverMutex.Lock()
+	defer fake.approveFolderHandoverMutex.Unlock()
+	fake.ApproveFolderHandoverStub = stub
+}
+
+func (fake *HealthMonitoringModel) ApproveFolderHandoverArgsForCall(i int) (string, protocol.DeviceID) {
+	fake.approveFolderHandoverMutex.RLock()
+	defer fake.approveFolderHandoverMutex.RUnlock()
+	argsForCall := fake.approveFolderHandoverArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *HealthMonitoringModel) ApproveFolderHandoverReturns(result1 error) {
+	fake.approveFolderHandoverMutex.Lock()
+	defer fake.approveFolderHandoverMutex.Unlock()
+	fake.ApproveFolderHandoverStub = nil
+	fake.approveFolderHandoverReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) ApproveFolderHandoverReturnsOnCall(i int, result1 error) {
+	fake.approveFolderHandoverMutex.Lock()
+	defer fake.approveFolderHandoverMutex.Unlock()
+	fake.ApproveFolderHandoverStub = nil
+	if fake.approveFolderHandoverReturnsOnCall == nil {
+		fake.approveFolderHandoverReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.approveFolderHandoverReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) ApproveIdentityChange(arg1 protocol.DeviceID) error {
+	fake.approveIdentityChangeMutex.Lock()
+	ret, specificReturn := fake.approveIdentityChangeReturnsOnCall[len(fake.approveIdentityChangeArgsForCall)]
+	fake.approveIdentityChangeArgsForCall = append(fake.approveIdentityChangeArgsForCall, struct {
+		arg1 protocol.DeviceID
+	}{arg1})
+	stub := fake.ApproveIdentityChangeStub
+	fakeReturns := fake.approveIdentityChangeReturns
+	fake.recordInvocation("ApproveIdentityChange", []interface{}{arg1})
+	fake.approveIdentityChangeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *HealthMonitoringModel) ApproveIdentityChangeCallCount() int {
+	fake.approveIdentityChangeMutex.RLock()
+	defer fake.approveIdentityChangeMutex.RUnlock()
+	return len(fake.approveIdentityChangeArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) ApproveIdentityChangeCalls(stub func(protocol.DeviceID) error) {
+	fake.approveIdentityChangeMutex.Lock()
+	defer fake.approveIdentityChangeMutex.Unlock()
+	fake.ApproveIdentityChangeStub = stub
+}
+
+func (fake *HealthMonitoringModel) ApproveIdentityChangeArgsForCall(i int) protocol.DeviceID {
+	fake.approveIdentityChangeMutex.RLock()
+	defer fake.approveIdentityChangeMutex.RUnlock()
+	argsForCall := fake.approveIdentityChangeArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) ApproveIdentityChangeReturns(result1 error) {
+	fake.approveIdentityChangeMutex.Lock()
+	defer fake.approveIdentityChangeMutex.Unlock()
+	fake.ApproveIdentityChangeStub = nil
+	fake.approveIdentityChangeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) ApproveIdentityChangeReturnsOnCall(i int, result1 error) {
+	fake.approveIdentityChangeMutex.Lock()
+	defer fake.approveIdentityChangeMutex.Unlock()
+	fake.ApproveIdentityChangeStub = nil
+	if fake.approveIdentityChangeReturnsOnCall == nil {
+		fake.approveIdentityChangeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.approveIdentityChangeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) ApproveOwnershipClaim(arg1 string, arg2 protocol.DeviceID) error {
+	fake.approveOwnershipClaimMutex.Lock()
+	ret, specificReturn := fake.approveOwnershipClaimReturnsOnCall[len(fake.approveOwnershipClaimArgsForCall)]
+	fake.approveOwnershipClaimArgsForCall = append(fake.approveOwnershipClaimArgsForCall, struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}{arg1, arg2})
+	stub := fake.ApproveOwnershipClaimStub
+	fakeReturns := fake.approveOwnershipClaimReturns
+	fake.recordInvocation("ApproveOwnershipClaim", []interface{}{arg1, arg2})
+	fake.approveOwnershipClaimMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *HealthMonitoringModel) ApproveOwnershipClaimCallCount() int {
+	fake.approveOwnershipClaimMutex.RLock()
+	defer fake.approveOwnershipClaimMutex.RUnlock()
+	return len(fake.approveOwnershipClaimArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) ApproveOwnershipClaimCalls(stub func(string, protocol.DeviceID) error) {
+	fake.approveOwnershipClaimMutex.Lock()
+	defer fake.approveOwnershipClaimMutex.Unlock()
+	fake.ApproveOwnershipClaimStub = stub
+}
+
+func (fake *HealthMonitoringModel) ApproveOwnershipClaimArgsForCall(i int) (string, protocol.DeviceID) {
+	fake.approveOwnershipClaimMutex.RLock()
+	defer fake.approveOwnershipClaimMutex.RUnlock()
+	argsForCall := fake.approveOwnershipClaimArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *HealthMonitoringModel) ApproveOwnershipClaimReturns(result1 error) {
+	fake.approveOwnershipClaimMutex.Lock()
+	defer fake.approveOwnershipClaimMutex.Unlock()
+	fake.ApproveOwnershipClaimStub = nil
+	fake.approveOwnershipClaimReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) ApproveOwnershipClaimReturnsOnCall(i int, result1 error) {
+	fake.approveOwnershipClaimMutex.Lock()
+	defer fake.approveOwnershipClaimMutex.Unlock()
+	fake.ApproveOwnershipClaimStub = nil
+	if fake.approveOwnershipClaimReturnsOnCall == nil {
+		fake.approveOwnershipClaimReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.approveOwnershipClaimReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *HealthMonitoringModel) Availability(arg1 string, arg2 protocol.FileInfo, arg3 protocol.BlockInfo) ([]model.Availability, error) {
 	fake.availabilityMutex.Lock()
 	ret, specificReturn := fake.availabilityReturnsOnCall[len(fake.availabilityArgsForCall)]
@@ -924,51 +1864,229 @@ func (fake *HealthMonitoringModel) BringToFrontArgsForCall(i int) (string, strin
 	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *HealthMonitoringModel) Closed(arg1 protocol.Connection, arg2 error) {
-	fake.closedMutex.Lock()
-	fake.closedArgsForCall = append(fake.closedArgsForCall, struct {
-		arg1 protocol.Connection
-		arg2 error
-	}{arg1, arg2})
-	stub := fake.ClosedStub
-	fake.recordInvocation("Closed", []interface{}{arg1, arg2})
-	fake.closedMutex.Unlock()
+func (fake *HealthMonitoringModel) ChangeAnomalies() map[string]model.ChangeAnomaly {
+	fake.changeAnomaliesMutex.Lock()
+	ret, specificReturn := fake.changeAnomaliesReturnsOnCall[len(fake.changeAnomaliesArgsForCall)]
+	fake.changeAnomaliesArgsForCall = append(fake.changeAnomaliesArgsForCall, struct {
+	}{})
+	stub := fake.ChangeAnomaliesStub
+	fakeReturns := fake.changeAnomaliesReturns
+	fake.recordInvocation("ChangeAnomalies", []interface{}{})
+	fake.changeAnomaliesMutex.Unlock()
 	if stub != nil {
-		fake.ClosedStub(arg1, arg2)
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
 	}
+	return fakeReturns.result1
 }
 
-func (fake *HealthMonitoringModel) ClosedCallCount() int {
-	fake.closedMutex.RLock()
-	defer fake.closedMutex.RUnlock()
-	return len(fake.closedArgsForCall)
+func (fake *HealthMonitoringModel) ChangeAnomaliesCallCount() int {
+	fake.changeAnomaliesMutex.RLock()
+	defer fake.changeAnomaliesMutex.RUnlock()
+	return len(fake.changeAnomaliesArgsForCall)
 }
 
-func (fake *HealthMonitoringModel) ClosedCalls(stub func(protocol.Connection, error)) {
-	fake.closedMutex.Lock()
-	defer fake.closedMutex.Unlock()
-	fake.ClosedStub = stub
+func (fake *HealthMonitoringModel) ChangeAnomaliesCalls(stub func() map[string]model.ChangeAnomaly) {
+	fake.changeAnomaliesMutex.Lock()
+	defer fake.changeAnomaliesMutex.Unlock()
+	fake.ChangeAnomaliesStub = stub
 }
 
-func (fake *HealthMonitoringModel) ClosedArgsForCall(i int) (protocol.Connection, error) {
-	fake.closedMutex.RLock()
-	defer fake.closedMutex.RUnlock()
-	argsForCall := fake.closedArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+func (fake *HealthMonitoringModel) ChangeAnomaliesReturns(result1 map[string]model.ChangeAnomaly) {
+	fake.changeAnomaliesMutex.Lock()
+	defer fake.changeAnomaliesMutex.Unlock()
+	fake.ChangeAnomaliesStub = nil
+	fake.changeAnomaliesReturns = struct {
+		result1 map[string]model.ChangeAnomaly
+	}{result1}
 }
 
-func (fake *HealthMonitoringModel) ClusterConfig(arg1 protocol.Connection, arg2 *protocol.ClusterConfig) error {
-	fake.clusterConfigMutex.Lock()
-	ret, specificReturn := fake.clusterConfigReturnsOnCall[len(fake.clusterConfigArgsForCall)]
-	fake.clusterConfigArgsForCall = append(fake.clusterConfigArgsForCall, struct {
-		arg1 protocol.Connection
-		arg2 *protocol.ClusterConfig
-	}{arg1, arg2})
-	stub := fake.ClusterConfigStub
-	fakeReturns := fake.clusterConfigReturns
-	fake.recordInvocation("ClusterConfig", []interface{}{arg1, arg2})
-	fake.clusterConfigMutex.Unlock()
-	if stub != nil {
+func (fake *HealthMonitoringModel) ChangeAnomaliesReturnsOnCall(i int, result1 map[string]model.ChangeAnomaly) {
+	fake.changeAnomaliesMutex.Lock()
+	defer fake.changeAnomaliesMutex.Unlock()
+	fake.ChangeAnomaliesStub = nil
+	if fake.changeAnomaliesReturnsOnCall == nil {
+		fake.changeAnomaliesReturnsOnCall = make(map[int]struct {
+			result1 map[string]model.ChangeAnomaly
+		})
+	}
+	fake.changeAnomaliesReturnsOnCall[i] = struct {
+		result1 map[string]model.ChangeAnomaly
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) CleanTempFiles(arg1 string) (*model.TempCleanupReport, error) {
+	fake.cleanTempFilesMutex.Lock()
+	ret, specificReturn := fake.cleanTempFilesReturnsOnCall[len(fake.cleanTempFilesArgsForCall)]
+	fake.cleanTempFilesArgsForCall = append(fake.cleanTempFilesArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.CleanTempFilesStub
+	fakeReturns := fake.cleanTempFilesReturns
+	fake.recordInvocation("CleanTempFiles", []interface{}{arg1})
+	fake.cleanTempFilesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) CleanTempFilesCallCount() int {
+	fake.cleanTempFilesMutex.RLock()
+	defer fake.cleanTempFilesMutex.RUnlock()
+	return len(fake.cleanTempFilesArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) CleanTempFilesCalls(stub func(string) (*model.TempCleanupReport, error)) {
+	fake.cleanTempFilesMutex.Lock()
+	defer fake.cleanTempFilesMutex.Unlock()
+	fake.CleanTempFilesStub = stub
+}
+
+func (fake *HealthMonitoringModel) CleanTempFilesArgsForCall(i int) string {
+	fake.cleanTempFilesMutex.RLock()
+	defer fake.cleanTempFilesMutex.RUnlock()
+	argsForCall := fake.cleanTempFilesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) CleanTempFilesReturns(result1 *model.TempCleanupReport, result2 error) {
+	fake.cleanTempFilesMutex.Lock()
+	defer fake.cleanTempFilesMutex.Unlock()
+	fake.CleanTempFilesStub = nil
+	fake.cleanTempFilesReturns = struct {
+		result1 *model.TempCleanupReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) CleanTempFilesReturnsOnCall(i int, result1 *model.TempCleanupReport, result2 error) {
+	fake.cleanTempFilesMutex.Lock()
+	defer fake.cleanTempFilesMutex.Unlock()
+	fake.CleanTempFilesStub = nil
+	if fake.cleanTempFilesReturnsOnCall == nil {
+		fake.cleanTempFilesReturnsOnCall = make(map[int]struct {
+			result1 *model.TempCleanupReport
+			result2 error
+		})
+	}
+	fake.cleanTempFilesReturnsOnCall[i] = struct {
+		result1 *model.TempCleanupReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) ClearFolderHandover(arg1 string) error {
+	fake.clearFolderHandoverMutex.Lock()
+	ret, specificReturn := fake.clearFolderHandoverReturnsOnCall[len(fake.clearFolderHandoverArgsForCall)]
+	fake.clearFolderHandoverArgsForCall = append(fake.clearFolderHandoverArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ClearFolderHandoverStub
+	fakeReturns := fake.clearFolderHandoverReturns
+	fake.recordInvocation("ClearFolderHandover", []interface{}{arg1})
+	fake.clearFolderHandoverMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *HealthMonitoringModel) ClearFolderHandoverCallCount() int {
+	fake.clearFolderHandoverMutex.RLock()
+	defer fake.clearFolderHandoverMutex.RUnlock()
+	return len(fake.clearFolderHandoverArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) ClearFolderHandoverCalls(stub func(string) error) {
+	fake.clearFolderHandoverMutex.Lock()
+	defer fake.clearFolderHandoverMutex.Unlock()
+	fake.ClearFolderHandoverStub = stub
+}
+
+func (fake *HealthMonitoringModel) ClearFolderHandoverArgsForCall(i int) string {
+	fake.clearFolderHandoverMutex.RLock()
+	defer fake.clearFolderHandoverMutex.RUnlock()
+	argsForCall := fake.clearFolderHandoverArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) ClearFolderHandoverReturns(result1 error) {
+	fake.clearFolderHandoverMutex.Lock()
+	defer fake.clearFolderHandoverMutex.Unlock()
+	fake.ClearFolderHandoverStub = nil
+	fake.clearFolderHandoverReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) ClearFolderHandoverReturnsOnCall(i int, result1 error) {
+	fake.clearFolderHandoverMutex.Lock()
+	defer fake.clearFolderHandoverMutex.Unlock()
+	fake.ClearFolderHandoverStub = nil
+	if fake.clearFolderHandoverReturnsOnCall == nil {
+		fake.clearFolderHandoverReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.clearFolderHandoverReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) Closed(arg1 protocol.Connection, arg2 error) {
+	fake.closedMutex.Lock()
+	fake.closedArgsForCall = append(fake.closedArgsForCall, struct {
+		arg1 protocol.Connection
+		arg2 error
+	}{arg1, arg2})
+	stub := fake.ClosedStub
+	fake.recordInvocation("Closed", []interface{}{arg1, arg2})
+	fake.closedMutex.Unlock()
+	if stub != nil {
+		fake.ClosedStub(arg1, arg2)
+	}
+}
+
+func (fake *HealthMonitoringModel) ClosedCallCount() int {
+	fake.closedMutex.RLock()
+	defer fake.closedMutex.RUnlock()
+	return len(fake.closedArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) ClosedCalls(stub func(protocol.Connection, error)) {
+	fake.closedMutex.Lock()
+	defer fake.closedMutex.Unlock()
+	fake.ClosedStub = stub
+}
+
+func (fake *HealthMonitoringModel) ClosedArgsForCall(i int) (protocol.Connection, error) {
+	fake.closedMutex.RLock()
+	defer fake.closedMutex.RUnlock()
+	argsForCall := fake.closedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *HealthMonitoringModel) ClusterConfig(arg1 protocol.Connection, arg2 *protocol.ClusterConfig) error {
+	fake.clusterConfigMutex.Lock()
+	ret, specificReturn := fake.clusterConfigReturnsOnCall[len(fake.clusterConfigArgsForCall)]
+	fake.clusterConfigArgsForCall = append(fake.clusterConfigArgsForCall, struct {
+		arg1 protocol.Connection
+		arg2 *protocol.ClusterConfig
+	}{arg1, arg2})
+	stub := fake.ClusterConfigStub
+	fakeReturns := fake.clusterConfigReturns
+	fake.recordInvocation("ClusterConfig", []interface{}{arg1, arg2})
+	fake.clusterConfigMutex.Unlock()
+	if stub != nil {
 		return stub(arg1, arg2)
 	}
 	if specificReturn {
@@ -1084,6 +2202,138 @@ func (fake *HealthMonitoringModel) CompletionReturnsOnCall(i int, result1 model.
 	}{result1, result2}
 }
 
+func (fake *HealthMonitoringModel) ConflictVersions(arg1 string, arg2 string) (protocol.FileInfo, protocol.FileInfo, error) {
+	fake.conflictVersionsMutex.Lock()
+	ret, specificReturn := fake.conflictVersionsReturnsOnCall[len(fake.conflictVersionsArgsForCall)]
+	fake.conflictVersionsArgsForCall = append(fake.conflictVersionsArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ConflictVersionsStub
+	fakeReturns := fake.conflictVersionsReturns
+	fake.recordInvocation("ConflictVersions", []interface{}{arg1, arg2})
+	fake.conflictVersionsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *HealthMonitoringModel) ConflictVersionsCallCount() int {
+	fake.conflictVersionsMutex.RLock()
+	defer fake.conflictVersionsMutex.RUnlock()
+	return len(fake.conflictVersionsArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) ConflictVersionsCalls(stub func(string, string) (protocol.FileInfo, protocol.FileInfo, error)) {
+	fake.conflictVersionsMutex.Lock()
+	defer fake.conflictVersionsMutex.Unlock()
+	fake.ConflictVersionsStub = stub
+}
+
+func (fake *HealthMonitoringModel) ConflictVersionsArgsForCall(i int) (string, string) {
+	fake.conflictVersionsMutex.RLock()
+	defer fake.conflictVersionsMutex.RUnlock()
+	argsForCall := fake.conflictVersionsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *HealthMonitoringModel) ConflictVersionsReturns(result1 protocol.FileInfo, result2 protocol.FileInfo, result3 error) {
+	fake.conflictVersionsMutex.Lock()
+	defer fake.conflictVersionsMutex.Unlock()
+	fake.ConflictVersionsStub = nil
+	fake.conflictVersionsReturns = struct {
+		result1 protocol.FileInfo
+		result2 protocol.FileInfo
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *HealthMonitoringModel) ConflictVersionsReturnsOnCall(i int, result1 protocol.FileInfo, result2 protocol.FileInfo, result3 error) {
+	fake.conflictVersionsMutex.Lock()
+	defer fake.conflictVersionsMutex.Unlock()
+	fake.ConflictVersionsStub = nil
+	if fake.conflictVersionsReturnsOnCall == nil {
+		fake.conflictVersionsReturnsOnCall = make(map[int]struct {
+			result1 protocol.FileInfo
+			result2 protocol.FileInfo
+			result3 error
+		})
+	}
+	fake.conflictVersionsReturnsOnCall[i] = struct {
+		result1 protocol.FileInfo
+		result2 protocol.FileInfo
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *HealthMonitoringModel) Conflicts(arg1 string) ([]model.Conflict, error) {
+	fake.conflictsMutex.Lock()
+	ret, specificReturn := fake.conflictsReturnsOnCall[len(fake.conflictsArgsForCall)]
+	fake.conflictsArgsForCall = append(fake.conflictsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ConflictsStub
+	fakeReturns := fake.conflictsReturns
+	fake.recordInvocation("Conflicts", []interface{}{arg1})
+	fake.conflictsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) ConflictsCallCount() int {
+	fake.conflictsMutex.RLock()
+	defer fake.conflictsMutex.RUnlock()
+	return len(fake.conflictsArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) ConflictsCalls(stub func(string) ([]model.Conflict, error)) {
+	fake.conflictsMutex.Lock()
+	defer fake.conflictsMutex.Unlock()
+	fake.ConflictsStub = stub
+}
+
+func (fake *HealthMonitoringModel) ConflictsArgsForCall(i int) string {
+	fake.conflictsMutex.RLock()
+	defer fake.conflictsMutex.RUnlock()
+	argsForCall := fake.conflictsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) ConflictsReturns(result1 []model.Conflict, result2 error) {
+	fake.conflictsMutex.Lock()
+	defer fake.conflictsMutex.Unlock()
+	fake.ConflictsStub = nil
+	fake.conflictsReturns = struct {
+		result1 []model.Conflict
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) ConflictsReturnsOnCall(i int, result1 []model.Conflict, result2 error) {
+	fake.conflictsMutex.Lock()
+	defer fake.conflictsMutex.Unlock()
+	fake.ConflictsStub = nil
+	if fake.conflictsReturnsOnCall == nil {
+		fake.conflictsReturnsOnCall = make(map[int]struct {
+			result1 []model.Conflict
+			result2 error
+		})
+	}
+	fake.conflictsReturnsOnCall[i] = struct {
+		result1 []model.Conflict
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *HealthMonitoringModel) ConnectedTo(arg1 protocol.DeviceID) bool {
 	fake.connectedToMutex.Lock()
 	ret, specificReturn := fake.connectedToReturnsOnCall[len(fake.connectedToArgsForCall)]
@@ -1434,6 +2684,116 @@ func (fake *HealthMonitoringModel) DelayScanArgsForCall(i int) (string, time.Dur
 	return argsForCall.arg1, argsForCall.arg2
 }
 
+func (fake *HealthMonitoringModel) DeviceAddressLedgers() (map[protocol.DeviceID][]stats.AddressLedgerEntry, error) {
+	fake.deviceAddressLedgersMutex.Lock()
+	ret, specificReturn := fake.deviceAddressLedgersReturnsOnCall[len(fake.deviceAddressLedgersArgsForCall)]
+	fake.deviceAddressLedgersArgsForCall = append(fake.deviceAddressLedgersArgsForCall, struct{}{})
+	stub := fake.DeviceAddressLedgersStub
+	fakeReturns := fake.deviceAddressLedgersReturns
+	fake.recordInvocation("DeviceAddressLedgers", []interface{}{})
+	fake.deviceAddressLedgersMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) DeviceAddressLedgersCallCount() int {
+	fake.deviceAddressLedgersMutex.RLock()
+	defer fake.deviceAddressLedgersMutex.RUnlock()
+	return len(fake.deviceAddressLedgersArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) DeviceAddressLedgersCalls(stub func() (map[protocol.DeviceID][]stats.AddressLedgerEntry, error)) {
+	fake.deviceAddressLedgersMutex.Lock()
+	defer fake.deviceAddressLedgersMutex.Unlock()
+	fake.DeviceAddressLedgersStub = stub
+}
+
+func (fake *HealthMonitoringModel) DeviceAddressLedgersReturns(result1 map[protocol.DeviceID][]stats.AddressLedgerEntry, result2 error) {
+	fake.deviceAddressLedgersMutex.Lock()
+	defer fake.deviceAddressLedgersMutex.Unlock()
+	fake.DeviceAddressLedgersStub = nil
+	fake.deviceAddressLedgersReturns = struct {
+		result1 map[protocol.DeviceID][]stats.AddressLedgerEntry
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) DeviceAddressLedgersReturnsOnCall(i int, result1 map[protocol.DeviceID][]stats.AddressLedgerEntry, result2 error) {
+	fake.deviceAddressLedgersMutex.Lock()
+	defer fake.deviceAddressLedgersMutex.Unlock()
+	fake.DeviceAddressLedgersStub = nil
+	if fake.deviceAddressLedgersReturnsOnCall == nil {
+		fake.deviceAddressLedgersReturnsOnCall = make(map[int]struct {
+			result1 map[protocol.DeviceID][]stats.AddressLedgerEntry
+			result2 error
+		})
+	}
+	fake.deviceAddressLedgersReturnsOnCall[i] = struct {
+		result1 map[protocol.DeviceID][]stats.AddressLedgerEntry
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) DeviceRevocations() ([]model.DeviceRevocation, error) {
+	fake.deviceRevocationsMutex.Lock()
+	ret, specificReturn := fake.deviceRevocationsReturnsOnCall[len(fake.deviceRevocationsArgsForCall)]
+	fake.deviceRevocationsArgsForCall = append(fake.deviceRevocationsArgsForCall, struct{}{})
+	stub := fake.DeviceRevocationsStub
+	fakeReturns := fake.deviceRevocationsReturns
+	fake.recordInvocation("DeviceRevocations", []interface{}{})
+	fake.deviceRevocationsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) DeviceRevocationsCallCount() int {
+	fake.deviceRevocationsMutex.RLock()
+	defer fake.deviceRevocationsMutex.RUnlock()
+	return len(fake.deviceRevocationsArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) DeviceRevocationsCalls(stub func() ([]model.DeviceRevocation, error)) {
+	fake.deviceRevocationsMutex.Lock()
+	defer fake.deviceRevocationsMutex.Unlock()
+	fake.DeviceRevocationsStub = stub
+}
+
+func (fake *HealthMonitoringModel) DeviceRevocationsReturns(result1 []model.DeviceRevocation, result2 error) {
+	fake.deviceRevocationsMutex.Lock()
+	defer fake.deviceRevocationsMutex.Unlock()
+	fake.DeviceRevocationsStub = nil
+	fake.deviceRevocationsReturns = struct {
+		result1 []model.DeviceRevocation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) DeviceRevocationsReturnsOnCall(i int, result1 []model.DeviceRevocation, result2 error) {
+	fake.deviceRevocationsMutex.Lock()
+	defer fake.deviceRevocationsMutex.Unlock()
+	fake.DeviceRevocationsStub = nil
+	if fake.deviceRevocationsReturnsOnCall == nil {
+		fake.deviceRevocationsReturnsOnCall = make(map[int]struct {
+			result1 []model.DeviceRevocation
+			result2 error
+		})
+	}
+	fake.deviceRevocationsReturnsOnCall[i] = struct {
+		result1 []model.DeviceRevocation
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *HealthMonitoringModel) DeviceStatistics() (map[protocol.DeviceID]stats.DeviceStatistics, error) {
 	fake.deviceStatisticsMutex.Lock()
 	ret, specificReturn := fake.deviceStatisticsReturnsOnCall[len(fake.deviceStatisticsArgsForCall)]
@@ -1490,6 +2850,67 @@ func (fake *HealthMonitoringModel) DeviceStatisticsReturnsOnCall(i int, result1
 	}{result1, result2}
 }
 
+func (fake *HealthMonitoringModel) DismissIdentityChange(arg1 protocol.DeviceID) error {
+	fake.dismissIdentityChangeMutex.Lock()
+	ret, specificReturn := fake.dismissIdentityChangeReturnsOnCall[len(fake.dismissIdentityChangeArgsForCall)]
+	fake.dismissIdentityChangeArgsForCall = append(fake.dismissIdentityChangeArgsForCall, struct {
+		arg1 protocol.DeviceID
+	}{arg1})
+	stub := fake.DismissIdentityChangeStub
+	fakeReturns := fake.dismissIdentityChangeReturns
+	fake.recordInvocation("DismissIdentityChange", []interface{}{arg1})
+	fake.dismissIdentityChangeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *HealthMonitoringModel) DismissIdentityChangeCallCount() int {
+	fake.dismissIdentityChangeMutex.RLock()
+	defer fake.dismissIdentityChangeMutex.RUnlock()
+	return len(fake.dismissIdentityChangeArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) DismissIdentityChangeCalls(stub func(protocol.DeviceID) error) {
+	fake.dismissIdentityChangeMutex.Lock()
+	defer fake.dismissIdentityChangeMutex.Unlock()
+	fake.DismissIdentityChangeStub = stub
+}
+
+func (fake *HealthMonitoringModel) DismissIdentityChangeArgsForCall(i int) protocol.DeviceID {
+	fake.dismissIdentityChangeMutex.RLock()
+	defer fake.dismissIdentityChangeMutex.RUnlock()
+	argsForCall := fake.dismissIdentityChangeArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) DismissIdentityChangeReturns(result1 error) {
+	fake.dismissIdentityChangeMutex.Lock()
+	defer fake.dismissIdentityChangeMutex.Unlock()
+	fake.DismissIdentityChangeStub = nil
+	fake.dismissIdentityChangeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) DismissIdentityChangeReturnsOnCall(i int, result1 error) {
+	fake.dismissIdentityChangeMutex.Lock()
+	defer fake.dismissIdentityChangeMutex.Unlock()
+	fake.DismissIdentityChangeStub = nil
+	if fake.dismissIdentityChangeReturnsOnCall == nil {
+		fake.dismissIdentityChangeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.dismissIdentityChangeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *HealthMonitoringModel) DismissPendingDevice(arg1 protocol.DeviceID) error {
 	fake.dismissPendingDeviceMutex.Lock()
 	ret, specificReturn := fake.dismissPendingDeviceReturnsOnCall[len(fake.dismissPendingDeviceArgsForCall)]
@@ -1675,16 +3096,273 @@ func (fake *HealthMonitoringModel) DownloadProgressReturnsOnCall(i int, result1
 	}{result1}
 }
 
-func (fake *HealthMonitoringModel) FolderErrors(arg1 string) ([]model.FileError, error) {
-	fake.folderErrorsMutex.Lock()
-	ret, specificReturn := fake.folderErrorsReturnsOnCall[len(fake.folderErrorsArgsForCall)]
-	fake.folderErrorsArgsForCall = append(fake.folderErrorsArgsForCall, struct {
+func (fake *HealthMonitoringModel) EncryptionRecoveryCode(arg1 string, arg2 protocol.DeviceID) (string, error) {
+	fake.encryptionRecoveryCodeMutex.Lock()
+	ret, specificReturn := fake.encryptionRecoveryCodeReturnsOnCall[len(fake.encryptionRecoveryCodeArgsForCall)]
+	fake.encryptionRecoveryCodeArgsForCall = append(fake.encryptionRecoveryCodeArgsForCall, struct {
 		arg1 string
-	}{arg1})
-	stub := fake.FolderErrorsStub
-	fakeReturns := fake.folderErrorsReturns
-	fake.recordInvocation("FolderErrors", []interface{}{arg1})
-	fake.folderErrorsMutex.Unlock()
+		arg2 protocol.DeviceID
+	}{arg1, arg2})
+	stub := fake.EncryptionRecoveryCodeStub
+	fakeReturns := fake.encryptionRecoveryCodeReturns
+	fake.recordInvocation("EncryptionRecoveryCode", []interface{}{arg1, arg2})
+	fake.encryptionRecoveryCodeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) EncryptionRecoveryCodeCallCount() int {
+	fake.encryptionRecoveryCodeMutex.RLock()
+	defer fake.encryptionRecoveryCodeMutex.RUnlock()
+	return len(fake.encryptionRecoveryCodeArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) EncryptionRecoveryCodeCalls(stub func(string, protocol.DeviceID) (string, error)) {
+	fake.encryptionRecoveryCodeMutex.Lock()
+	defer fake.encryptionRecoveryCodeMutex.Unlock()
+	fake.EncryptionRecoveryCodeStub = stub
+}
+
+func (fake *HealthMonitoringModel) EncryptionRecoveryCodeArgsForCall(i int) (string, protocol.DeviceID) {
+	fake.encryptionRecoveryCodeMutex.RLock()
+	defer fake.encryptionRecoveryCodeMutex.RUnlock()
+	argsForCall := fake.encryptionRecoveryCodeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *HealthMonitoringModel) EncryptionRecoveryCodeReturns(result1 string, result2 error) {
+	fake.encryptionRecoveryCodeMutex.Lock()
+	defer fake.encryptionRecoveryCodeMutex.Unlock()
+	fake.EncryptionRecoveryCodeStub = nil
+	fake.encryptionRecoveryCodeReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) EncryptionRecoveryCodeReturnsOnCall(i int, result1 string, result2 error) {
+	fake.encryptionRecoveryCodeMutex.Lock()
+	defer fake.encryptionRecoveryCodeMutex.Unlock()
+	fake.EncryptionRecoveryCodeStub = nil
+	if fake.encryptionRecoveryCodeReturnsOnCall == nil {
+		fake.encryptionRecoveryCodeReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.encryptionRecoveryCodeReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) FolderAtTime(arg1 string, arg2 string, arg3 time.Time, arg4 string) (*model.FolderAtTimeReport, error) {
+	fake.folderAtTimeMutex.Lock()
+	ret, specificReturn := fake.folderAtTimeReturnsOnCall[len(fake.folderAtTimeArgsForCall)]
+	fake.folderAtTimeArgsForCall = append(fake.folderAtTimeArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 time.Time
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.FolderAtTimeStub
+	fakeReturns := fake.folderAtTimeReturns
+	fake.recordInvocation("FolderAtTime", []interface{}{arg1, arg2, arg3, arg4})
+	fake.folderAtTimeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) FolderAtTimeCallCount() int {
+	fake.folderAtTimeMutex.RLock()
+	defer fake.folderAtTimeMutex.RUnlock()
+	return len(fake.folderAtTimeArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) FolderAtTimeCalls(stub func(string, string, time.Time, string) (*model.FolderAtTimeReport, error)) {
+	fake.folderAtTimeMutex.Lock()
+	defer fake.folderAtTimeMutex.Unlock()
+	fake.FolderAtTimeStub = stub
+}
+
+func (fake *HealthMonitoringModel) FolderAtTimeArgsForCall(i int) (string, string, time.Time, string) {
+	fake.folderAtTimeMutex.RLock()
+	defer fake.folderAtTimeMutex.RUnlock()
+	argsForCall := fake.folderAtTimeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *HealthMonitoringModel) FolderAtTimeReturns(result1 *model.FolderAtTimeReport, result2 error) {
+	fake.folderAtTimeMutex.Lock()
+	defer fake.folderAtTimeMutex.Unlock()
+	fake.FolderAtTimeStub = nil
+	fake.folderAtTimeReturns = struct {
+		result1 *model.FolderAtTimeReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) FolderAtTimeReturnsOnCall(i int, result1 *model.FolderAtTimeReport, result2 error) {
+	fake.folderAtTimeMutex.Lock()
+	defer fake.folderAtTimeMutex.Unlock()
+	fake.FolderAtTimeStub = nil
+	if fake.folderAtTimeReturnsOnCall == nil {
+		fake.folderAtTimeReturnsOnCall = make(map[int]struct {
+			result1 *model.FolderAtTimeReport
+			result2 error
+		})
+	}
+	fake.folderAtTimeReturnsOnCall[i] = struct {
+		result1 *model.FolderAtTimeReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) FolderCapabilities(arg1 string) (*model.FolderCapabilities, error) {
+	fake.folderCapabilitiesMutex.Lock()
+	ret, specificReturn := fake.folderCapabilitiesReturnsOnCall[len(fake.folderCapabilitiesArgsForCall)]
+	fake.folderCapabilitiesArgsForCall = append(fake.folderCapabilitiesArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FolderCapabilitiesStub
+	fakeReturns := fake.folderCapabilitiesReturns
+	fake.recordInvocation("FolderCapabilities", []interface{}{arg1})
+	fake.folderCapabilitiesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) FolderCapabilitiesCallCount() int {
+	fake.folderCapabilitiesMutex.RLock()
+	defer fake.folderCapabilitiesMutex.RUnlock()
+	return len(fake.folderCapabilitiesArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) FolderCapabilitiesCalls(stub func(string) (*model.FolderCapabilities, error)) {
+	fake.folderCapabilitiesMutex.Lock()
+	defer fake.folderCapabilitiesMutex.Unlock()
+	fake.FolderCapabilitiesStub = stub
+}
+
+func (fake *HealthMonitoringModel) FolderCapabilitiesArgsForCall(i int) string {
+	fake.folderCapabilitiesMutex.RLock()
+	defer fake.folderCapabilitiesMutex.RUnlock()
+	argsForCall := fake.folderCapabilitiesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) FolderCapabilitiesReturns(result1 *model.FolderCapabilities, result2 error) {
+	fake.folderCapabilitiesMutex.Lock()
+	defer fake.folderCapabilitiesMutex.Unlock()
+	fake.FolderCapabilitiesStub = nil
+	fake.folderCapabilitiesReturns = struct {
+		result1 *model.FolderCapabilities
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) FolderCapabilitiesReturnsOnCall(i int, result1 *model.FolderCapabilities, result2 error) {
+	fake.folderCapabilitiesMutex.Lock()
+	defer fake.folderCapabilitiesMutex.Unlock()
+	fake.FolderCapabilitiesStub = nil
+	if fake.folderCapabilitiesReturnsOnCall == nil {
+		fake.folderCapabilitiesReturnsOnCall = make(map[int]struct {
+			result1 *model.FolderCapabilities
+			result2 error
+		})
+	}
+	fake.folderCapabilitiesReturnsOnCall[i] = struct {
+		result1 *model.FolderCapabilities
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) FolderDependencyWait(arg1 string) string {
+	fake.folderDependencyWaitMutex.Lock()
+	ret, specificReturn := fake.folderDependencyWaitReturnsOnCall[len(fake.folderDependencyWaitArgsForCall)]
+	fake.folderDependencyWaitArgsForCall = append(fake.folderDependencyWaitArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FolderDependencyWaitStub
+	fakeReturns := fake.folderDependencyWaitReturns
+	fake.recordInvocation("FolderDependencyWait", []interface{}{arg1})
+	fake.folderDependencyWaitMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *HealthMonitoringModel) FolderDependencyWaitCallCount() int {
+	fake.folderDependencyWaitMutex.RLock()
+	defer fake.folderDependencyWaitMutex.RUnlock()
+	return len(fake.folderDependencyWaitArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) FolderDependencyWaitCalls(stub func(string) string) {
+	fake.folderDependencyWaitMutex.Lock()
+	defer fake.folderDependencyWaitMutex.Unlock()
+	fake.FolderDependencyWaitStub = stub
+}
+
+func (fake *HealthMonitoringModel) FolderDependencyWaitArgsForCall(i int) string {
+	fake.folderDependencyWaitMutex.RLock()
+	defer fake.folderDependencyWaitMutex.RUnlock()
+	argsForCall := fake.folderDependencyWaitArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) FolderDependencyWaitReturns(result1 string) {
+	fake.folderDependencyWaitMutex.Lock()
+	defer fake.folderDependencyWaitMutex.Unlock()
+	fake.FolderDependencyWaitStub = nil
+	fake.folderDependencyWaitReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) FolderDependencyWaitReturnsOnCall(i int, result1 string) {
+	fake.folderDependencyWaitMutex.Lock()
+	defer fake.folderDependencyWaitMutex.Unlock()
+	fake.FolderDependencyWaitStub = nil
+	if fake.folderDependencyWaitReturnsOnCall == nil {
+		fake.folderDependencyWaitReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.folderDependencyWaitReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) FolderErrors(arg1 string) ([]model.FileError, error) {
+	fake.folderErrorsMutex.Lock()
+	ret, specificReturn := fake.folderErrorsReturnsOnCall[len(fake.folderErrorsArgsForCall)]
+	fake.folderErrorsArgsForCall = append(fake.folderErrorsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FolderErrorsStub
+	fakeReturns := fake.folderErrorsReturns
+	fake.recordInvocation("FolderErrors", []interface{}{arg1})
+	fake.folderErrorsMutex.Unlock()
 	if stub != nil {
 		return stub(arg1)
 	}
@@ -1739,6 +3417,61 @@ func (fake *HealthMonitoringModel) FolderErrorsReturnsOnCall(i int, result1 []mo
 	}{result1, result2}
 }
 
+func (fake *HealthMonitoringModel) FolderHandovers() ([]model.FolderHandover, error) {
+	fake.folderHandoversMutex.Lock()
+	ret, specificReturn := fake.folderHandoversReturnsOnCall[len(fake.folderHandoversArgsForCall)]
+	fake.folderHandoversArgsForCall = append(fake.folderHandoversArgsForCall, struct{}{})
+	stub := fake.FolderHandoversStub
+	fakeReturns := fake.folderHandoversReturns
+	fake.recordInvocation("FolderHandovers", []interface{}{})
+	fake.folderHandoversMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) FolderHandoversCallCount() int {
+	fake.folderHandoversMutex.RLock()
+	defer fake.folderHandoversMutex.RUnlock()
+	return len(fake.folderHandoversArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) FolderHandoversCalls(stub func() ([]model.FolderHandover, error)) {
+	fake.folderHandoversMutex.Lock()
+	defer fake.folderHandoversMutex.Unlock()
+	fake.FolderHandoversStub = stub
+}
+
+func (fake *HealthMonitoringModel) FolderHandoversReturns(result1 []model.FolderHandover, result2 error) {
+	fake.folderHandoversMutex.Lock()
+	defer fake.folderHandoversMutex.Unlock()
+	fake.FolderHandoversStub = nil
+	fake.folderHandoversReturns = struct {
+		result1 []model.FolderHandover
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) FolderHandoversReturnsOnCall(i int, result1 []model.FolderHandover, result2 error) {
+	fake.folderHandoversMutex.Lock()
+	defer fake.folderHandoversMutex.Unlock()
+	fake.FolderHandoversStub = nil
+	if fake.folderHandoversReturnsOnCall == nil {
+		fake.folderHandoversReturnsOnCall = make(map[int]struct {
+			result1 []model.FolderHandover
+			result2 error
+		})
+	}
+	fake.folderHandoversReturnsOnCall[i] = struct {
+		result1 []model.FolderHandover
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *HealthMonitoringModel) FolderProgressBytesCompleted(arg1 string) int64 {
 	fake.folderProgressBytesCompletedMutex.Lock()
 	ret, specificReturn := fake.folderProgressBytesCompletedReturnsOnCall[len(fake.folderProgressBytesCompletedArgsForCall)]
@@ -1800,6 +3533,131 @@ func (fake *HealthMonitoringModel) FolderProgressBytesCompletedReturnsOnCall(i i
 	}{result1}
 }
 
+func (fake *HealthMonitoringModel) FolderQueuePosition(arg1 string) (string, int) {
+	fake.folderQueuePositionMutex.Lock()
+	ret, specificReturn := fake.folderQueuePositionReturnsOnCall[len(fake.folderQueuePositionArgsForCall)]
+	fake.folderQueuePositionArgsForCall = append(fake.folderQueuePositionArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FolderQueuePositionStub
+	fakeReturns := fake.folderQueuePositionReturns
+	fake.recordInvocation("FolderQueuePosition", []interface{}{arg1})
+	fake.folderQueuePositionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) FolderQueuePositionCallCount() int {
+	fake.folderQueuePositionMutex.RLock()
+	defer fake.folderQueuePositionMutex.RUnlock()
+	return len(fake.folderQueuePositionArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) FolderQueuePositionCalls(stub func(string) (string, int)) {
+	fake.folderQueuePositionMutex.Lock()
+	defer fake.folderQueuePositionMutex.Unlock()
+	fake.FolderQueuePositionStub = stub
+}
+
+func (fake *HealthMonitoringModel) FolderQueuePositionArgsForCall(i int) string {
+	fake.folderQueuePositionMutex.RLock()
+	defer fake.folderQueuePositionMutex.RUnlock()
+	argsForCall := fake.folderQueuePositionArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) FolderQueuePositionReturns(result1 string, result2 int) {
+	fake.folderQueuePositionMutex.Lock()
+	defer fake.folderQueuePositionMutex.Unlock()
+	fake.FolderQueuePositionStub = nil
+	fake.folderQueuePositionReturns = struct {
+		result1 string
+		result2 int
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) FolderQueuePositionReturnsOnCall(i int, result1 string, result2 int) {
+	fake.folderQueuePositionMutex.Lock()
+	defer fake.folderQueuePositionMutex.Unlock()
+	fake.FolderQueuePositionStub = nil
+	if fake.folderQueuePositionReturnsOnCall == nil {
+		fake.folderQueuePositionReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 int
+		})
+	}
+	fake.folderQueuePositionReturnsOnCall[i] = struct {
+		result1 string
+		result2 int
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) FolderQuotaExceeded(arg1 string) int64 {
+	fake.folderQuotaExceededMutex.Lock()
+	ret, specificReturn := fake.folderQuotaExceededReturnsOnCall[len(fake.folderQuotaExceededArgsForCall)]
+	fake.folderQuotaExceededArgsForCall = append(fake.folderQuotaExceededArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FolderQuotaExceededStub
+	fakeReturns := fake.folderQuotaExceededReturns
+	fake.recordInvocation("FolderQuotaExceeded", []interface{}{arg1})
+	fake.folderQuotaExceededMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *HealthMonitoringModel) FolderQuotaExceededCallCount() int {
+	fake.folderQuotaExceededMutex.RLock()
+	defer fake.folderQuotaExceededMutex.RUnlock()
+	return len(fake.folderQuotaExceededArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) FolderQuotaExceededCalls(stub func(string) int64) {
+	fake.folderQuotaExceededMutex.Lock()
+	defer fake.folderQuotaExceededMutex.Unlock()
+	fake.FolderQuotaExceededStub = stub
+}
+
+func (fake *HealthMonitoringModel) FolderQuotaExceededArgsForCall(i int) string {
+	fake.folderQuotaExceededMutex.RLock()
+	defer fake.folderQuotaExceededMutex.RUnlock()
+	argsForCall := fake.folderQuotaExceededArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) FolderQuotaExceededReturns(result1 int64) {
+	fake.folderQuotaExceededMutex.Lock()
+	defer fake.folderQuotaExceededMutex.Unlock()
+	fake.FolderQuotaExceededStub = nil
+	fake.folderQuotaExceededReturns = struct {
+		result1 int64
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) FolderQuotaExceededReturnsOnCall(i int, result1 int64) {
+	fake.folderQuotaExceededMutex.Lock()
+	defer fake.folderQuotaExceededMutex.Unlock()
+	fake.FolderQuotaExceededStub = nil
+	if fake.folderQuotaExceededReturnsOnCall == nil {
+		fake.folderQuotaExceededReturnsOnCall = make(map[int]struct {
+			result1 int64
+		})
+	}
+	fake.folderQuotaExceededReturnsOnCall[i] = struct {
+		result1 int64
+	}{result1}
+}
+
 func (fake *HealthMonitoringModel) FolderStatistics() (map[string]stats.FolderStatistics, error) {
 	fake.folderStatisticsMutex.Lock()
 	ret, specificReturn := fake.folderStatisticsReturnsOnCall[len(fake.folderStatisticsArgsForCall)]
@@ -1856,6 +3714,70 @@ func (fake *HealthMonitoringModel) FolderStatisticsReturnsOnCall(i int, result1
 	}{result1, result2}
 }
 
+func (fake *HealthMonitoringModel) FolderWriteVerification(arg1 string) (model.WriteVerificationStats, bool) {
+	fake.folderWriteVerificationMutex.Lock()
+	ret, specificReturn := fake.folderWriteVerificationReturnsOnCall[len(fake.folderWriteVerificationArgsForCall)]
+	fake.folderWriteVerificationArgsForCall = append(fake.folderWriteVerificationArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FolderWriteVerificationStub
+	fakeReturns := fake.folderWriteVerificationReturns
+	fake.recordInvocation("FolderWriteVerification", []interface{}{arg1})
+	fake.folderWriteVerificationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) FolderWriteVerificationCallCount() int {
+	fake.folderWriteVerificationMutex.RLock()
+	defer fake.folderWriteVerificationMutex.RUnlock()
+	return len(fake.folderWriteVerificationArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) FolderWriteVerificationCalls(stub func(string) (model.WriteVerificationStats, bool)) {
+	fake.folderWriteVerificationMutex.Lock()
+	defer fake.folderWriteVerificationMutex.Unlock()
+	fake.FolderWriteVerificationStub = stub
+}
+
+func (fake *HealthMonitoringModel) FolderWriteVerificationArgsForCall(i int) string {
+	fake.folderWriteVerificationMutex.RLock()
+	defer fake.folderWriteVerificationMutex.RUnlock()
+	argsForCall := fake.folderWriteVerificationArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) FolderWriteVerificationReturns(result1 model.WriteVerificationStats, result2 bool) {
+	fake.folderWriteVerificationMutex.Lock()
+	defer fake.folderWriteVerificationMutex.Unlock()
+	fake.FolderWriteVerificationStub = nil
+	fake.folderWriteVerificationReturns = struct {
+		result1 model.WriteVerificationStats
+		result2 bool
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) FolderWriteVerificationReturnsOnCall(i int, result1 model.WriteVerificationStats, result2 bool) {
+	fake.folderWriteVerificationMutex.Lock()
+	defer fake.folderWriteVerificationMutex.Unlock()
+	fake.FolderWriteVerificationStub = nil
+	if fake.folderWriteVerificationReturnsOnCall == nil {
+		fake.folderWriteVerificationReturnsOnCall = make(map[int]struct {
+			result1 model.WriteVerificationStats
+			result2 bool
+		})
+	}
+	fake.folderWriteVerificationReturnsOnCall[i] = struct {
+		result1 model.WriteVerificationStats
+		result2 bool
+	}{result1, result2}
+}
+
 func (fake *HealthMonitoringModel) GetAllFoldersHealthStatus() map[string]config.FolderHealthStatus {
 	fake.getAllFoldersHealthStatusMutex.Lock()
 	ret, specificReturn := fake.getAllFoldersHealthStatusReturnsOnCall[len(fake.getAllFoldersHealthStatusArgsForCall)]
@@ -2285,19 +4207,72 @@ func (fake *HealthMonitoringModel) GlobalSizeReturnsOnCall(i int, result1 db.Cou
 	}{result1, result2}
 }
 
-func (fake *HealthMonitoringModel) Index(arg1 protocol.Connection, arg2 *protocol.Index) error {
-	fake.indexMutex.Lock()
-	ret, specificReturn := fake.indexReturnsOnCall[len(fake.indexArgsForCall)]
-	fake.indexArgsForCall = append(fake.indexArgsForCall, struct {
-		arg1 protocol.Connection
-		arg2 *protocol.Index
-	}{arg1, arg2})
-	stub := fake.IndexStub
-	fakeReturns := fake.indexReturns
-	fake.recordInvocation("Index", []interface{}{arg1, arg2})
-	fake.indexMutex.Unlock()
+func (fake *HealthMonitoringModel) IdentityChanges() map[protocol.DeviceID]model.IdentityChange {
+	fake.identityChangesMutex.Lock()
+	ret, specificReturn := fake.identityChangesReturnsOnCall[len(fake.identityChangesArgsForCall)]
+	fake.identityChangesArgsForCall = append(fake.identityChangesArgsForCall, struct {
+	}{})
+	stub := fake.IdentityChangesStub
+	fakeReturns := fake.identityChangesReturns
+	fake.recordInvocation("IdentityChanges", []interface{}{})
+	fake.identityChangesMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *HealthMonitoringModel) IdentityChangesCallCount() int {
+	fake.identityChangesMutex.RLock()
+	defer fake.identityChangesMutex.RUnlock()
+	return len(fake.identityChangesArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) IdentityChangesCalls(stub func() map[protocol.DeviceID]model.IdentityChange) {
+	fake.identityChangesMutex.Lock()
+	defer fake.identityChangesMutex.Unlock()
+	fake.IdentityChangesStub = stub
+}
+
+func (fake *HealthMonitoringModel) IdentityChangesReturns(result1 map[protocol.DeviceID]model.IdentityChange) {
+	fake.identityChangesMutex.Lock()
+	defer fake.identityChangesMutex.Unlock()
+	fake.IdentityChangesStub = nil
+	fake.identityChangesReturns = struct {
+		result1 map[protocol.DeviceID]model.IdentityChange
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) IdentityChangesReturnsOnCall(i int, result1 map[protocol.DeviceID]model.IdentityChange) {
+	fake.identityChangesMutex.Lock()
+	defer fake.identityChangesMutex.Unlock()
+	fake.IdentityChangesStub = nil
+	if fake.identityChangesReturnsOnCall == nil {
+		fake.identityChangesReturnsOnCall = make(map[int]struct {
+			result1 map[protocol.DeviceID]model.IdentityChange
+		})
+	}
+	fake.identityChangesReturnsOnCall[i] = struct {
+		result1 map[protocol.DeviceID]model.IdentityChange
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) Index(arg1 protocol.Connection, arg2 *protocol.Index) error {
+	fake.indexMutex.Lock()
+	ret, specificReturn := fake.indexReturnsOnCall[len(fake.indexArgsForCall)]
+	fake.indexArgsForCall = append(fake.indexArgsForCall, struct {
+		arg1 protocol.Connection
+		arg2 *protocol.Index
+	}{arg1, arg2})
+	stub := fake.IndexStub
+	fakeReturns := fake.indexReturns
+	fake.recordInvocation("Index", []interface{}{arg1, arg2})
+	fake.indexMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
@@ -2476,6 +4451,73 @@ func (fake *HealthMonitoringModel) LoadIgnoresReturnsOnCall(i int, result1 []str
 	}{result1, result2, result3}
 }
 
+func (fake *HealthMonitoringModel) LocalChangeReport(arg1 string, arg2 int, arg3 int, arg4 bool) ([]model.LocalChange, error) {
+	fake.localChangeReportMutex.Lock()
+	ret, specificReturn := fake.localChangeReportReturnsOnCall[len(fake.localChangeReportArgsForCall)]
+	fake.localChangeReportArgsForCall = append(fake.localChangeReportArgsForCall, struct {
+		arg1 string
+		arg2 int
+		arg3 int
+		arg4 bool
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.LocalChangeReportStub
+	fakeReturns := fake.localChangeReportReturns
+	fake.recordInvocation("LocalChangeReport", []interface{}{arg1, arg2, arg3, arg4})
+	fake.localChangeReportMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) LocalChangeReportCallCount() int {
+	fake.localChangeReportMutex.RLock()
+	defer fake.localChangeReportMutex.RUnlock()
+	return len(fake.localChangeReportArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) LocalChangeReportCalls(stub func(string, int, int, bool) ([]model.LocalChange, error)) {
+	fake.localChangeReportMutex.Lock()
+	defer fake.localChangeReportMutex.Unlock()
+	fake.LocalChangeReportStub = stub
+}
+
+func (fake *HealthMonitoringModel) LocalChangeReportArgsForCall(i int) (string, int, int, bool) {
+	fake.localChangeReportMutex.RLock()
+	defer fake.localChangeReportMutex.RUnlock()
+	argsForCall := fake.localChangeReportArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *HealthMonitoringModel) LocalChangeReportReturns(result1 []model.LocalChange, result2 error) {
+	fake.localChangeReportMutex.Lock()
+	defer fake.localChangeReportMutex.Unlock()
+	fake.LocalChangeReportStub = nil
+	fake.localChangeReportReturns = struct {
+		result1 []model.LocalChange
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) LocalChangeReportReturnsOnCall(i int, result1 []model.LocalChange, result2 error) {
+	fake.localChangeReportMutex.Lock()
+	defer fake.localChangeReportMutex.Unlock()
+	fake.LocalChangeReportStub = nil
+	if fake.localChangeReportReturnsOnCall == nil {
+		fake.localChangeReportReturnsOnCall = make(map[int]struct {
+			result1 []model.LocalChange
+			result2 error
+		})
+	}
+	fake.localChangeReportReturnsOnCall[i] = struct {
+		result1 []model.LocalChange
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *HealthMonitoringModel) LocalChangedFolderFiles(arg1 string, arg2 int, arg3 int) ([]protocol.FileInfo, error) {
 	fake.localChangedFolderFilesMutex.Lock()
 	ret, specificReturn := fake.localChangedFolderFilesReturnsOnCall[len(fake.localChangedFolderFilesArgsForCall)]
@@ -2738,6 +4780,72 @@ func (fake *HealthMonitoringModel) LocalSizeReturnsOnCall(i int, result1 db.Coun
 	}{result1, result2}
 }
 
+func (fake *HealthMonitoringModel) MergeFolders(arg1 string, arg2 string, arg3 bool) (*model.FolderMergeReport, error) {
+	fake.mergeFoldersMutex.Lock()
+	ret, specificReturn := fake.mergeFoldersReturnsOnCall[len(fake.mergeFoldersArgsForCall)]
+	fake.mergeFoldersArgsForCall = append(fake.mergeFoldersArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 bool
+	}{arg1, arg2, arg3})
+	stub := fake.MergeFoldersStub
+	fakeReturns := fake.mergeFoldersReturns
+	fake.recordInvocation("MergeFolders", []interface{}{arg1, arg2, arg3})
+	fake.mergeFoldersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) MergeFoldersCallCount() int {
+	fake.mergeFoldersMutex.RLock()
+	defer fake.mergeFoldersMutex.RUnlock()
+	return len(fake.mergeFoldersArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) MergeFoldersCalls(stub func(string, string, bool) (*model.FolderMergeReport, error)) {
+	fake.mergeFoldersMutex.Lock()
+	defer fake.mergeFoldersMutex.Unlock()
+	fake.MergeFoldersStub = stub
+}
+
+func (fake *HealthMonitoringModel) MergeFoldersArgsForCall(i int) (string, string, bool) {
+	fake.mergeFoldersMutex.RLock()
+	defer fake.mergeFoldersMutex.RUnlock()
+	argsForCall := fake.mergeFoldersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *HealthMonitoringModel) MergeFoldersReturns(result1 *model.FolderMergeReport, result2 error) {
+	fake.mergeFoldersMutex.Lock()
+	defer fake.mergeFoldersMutex.Unlock()
+	fake.MergeFoldersStub = nil
+	fake.mergeFoldersReturns = struct {
+		result1 *model.FolderMergeReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) MergeFoldersReturnsOnCall(i int, result1 *model.FolderMergeReport, result2 error) {
+	fake.mergeFoldersMutex.Lock()
+	defer fake.mergeFoldersMutex.Unlock()
+	fake.MergeFoldersStub = nil
+	if fake.mergeFoldersReturnsOnCall == nil {
+		fake.mergeFoldersReturnsOnCall = make(map[int]struct {
+			result1 *model.FolderMergeReport
+			result2 error
+		})
+	}
+	fake.mergeFoldersReturnsOnCall[i] = struct {
+		result1 *model.FolderMergeReport
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *HealthMonitoringModel) NeedFolderFiles(arg1 string, arg2 int, arg3 int) ([]protocol.FileInfo, []protocol.FileInfo, []protocol.FileInfo, error) {
 	fake.needFolderFilesMutex.Lock()
 	ret, specificReturn := fake.needFolderFilesReturnsOnCall[len(fake.needFolderFilesArgsForCall)]
@@ -2970,6 +5078,117 @@ func (fake *HealthMonitoringModel) OverrideArgsForCall(i int) string {
 	return argsForCall.arg1
 }
 
+func (fake *HealthMonitoringModel) PendingConfigSync() ([]model.ConfigSyncChange, error) {
+	fake.pendingConfigSyncMutex.Lock()
+	ret, specificReturn := fake.pendingConfigSyncReturnsOnCall[len(fake.pendingConfigSyncArgsForCall)]
+	fake.pendingConfigSyncArgsForCall = append(fake.pendingConfigSyncArgsForCall, struct {
+	}{})
+	stub := fake.PendingConfigSyncStub
+	fakeReturns := fake.pendingConfigSyncReturns
+	fake.recordInvocation("PendingConfigSync", []interface{}{})
+	fake.pendingConfigSyncMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) PendingConfigSyncCallCount() int {
+	fake.pendingConfigSyncMutex.RLock()
+	defer fake.pendingConfigSyncMutex.RUnlock()
+	return len(fake.pendingConfigSyncArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) PendingConfigSyncCalls(stub func() ([]model.ConfigSyncChange, error)) {
+	fake.pendingConfigSyncMutex.Lock()
+	defer fake.pendingConfigSyncMutex.Unlock()
+	fake.PendingConfigSyncStub = stub
+}
+
+func (fake *HealthMonitoringModel) PendingConfigSyncReturns(result1 []model.ConfigSyncChange, result2 error) {
+	fake.pendingConfigSyncMutex.Lock()
+	defer fake.pendingConfigSyncMutex.Unlock()
+	fake.PendingConfigSyncStub = nil
+	fake.pendingConfigSyncReturns = struct {
+		result1 []model.ConfigSyncChange
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) PendingConfigSyncReturnsOnCall(i int, result1 []model.ConfigSyncChange, result2 error) {
+	fake.pendingConfigSyncMutex.Lock()
+	defer fake.pendingConfigSyncMutex.Unlock()
+	fake.PendingConfigSyncStub = nil
+	if fake.pendingConfigSyncReturnsOnCall == nil {
+		fake.pendingConfigSyncReturnsOnCall = make(map[int]struct {
+			result1 []model.ConfigSyncChange
+			result2 error
+		})
+	}
+	fake.pendingConfigSyncReturnsOnCall[i] = struct {
+		result1 []model.ConfigSyncChange
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) PendingDeviceRevocations() ([]model.PendingDeviceRevocation, error) {
+	fake.pendingDeviceRevocationsMutex.Lock()
+	ret, specificReturn := fake.pendingDeviceRevocationsReturnsOnCall[len(fake.pendingDeviceRevocationsArgsForCall)]
+	fake.pendingDeviceRevocationsArgsForCall = append(fake.pendingDeviceRevocationsArgsForCall, struct{}{})
+	stub := fake.PendingDeviceRevocationsStub
+	fakeReturns := fake.pendingDeviceRevocationsReturns
+	fake.recordInvocation("PendingDeviceRevocations", []interface{}{})
+	fake.pendingDeviceRevocationsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) PendingDeviceRevocationsCallCount() int {
+	fake.pendingDeviceRevocationsMutex.RLock()
+	defer fake.pendingDeviceRevocationsMutex.RUnlock()
+	return len(fake.pendingDeviceRevocationsArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) PendingDeviceRevocationsCalls(stub func() ([]model.PendingDeviceRevocation, error)) {
+	fake.pendingDeviceRevocationsMutex.Lock()
+	defer fake.pendingDeviceRevocationsMutex.Unlock()
+	fake.PendingDeviceRevocationsStub = stub
+}
+
+func (fake *HealthMonitoringModel) PendingDeviceRevocationsReturns(result1 []model.PendingDeviceRevocation, result2 error) {
+	fake.pendingDeviceRevocationsMutex.Lock()
+	defer fake.pendingDeviceRevocationsMutex.Unlock()
+	fake.PendingDeviceRevocationsStub = nil
+	fake.pendingDeviceRevocationsReturns = struct {
+		result1 []model.PendingDeviceRevocation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) PendingDeviceRevocationsReturnsOnCall(i int, result1 []model.PendingDeviceRevocation, result2 error) {
+	fake.pendingDeviceRevocationsMutex.Lock()
+	defer fake.pendingDeviceRevocationsMutex.Unlock()
+	fake.PendingDeviceRevocationsStub = nil
+	if fake.pendingDeviceRevocationsReturnsOnCall == nil {
+		fake.pendingDeviceRevocationsReturnsOnCall = make(map[int]struct {
+			result1 []model.PendingDeviceRevocation
+			result2 error
+		})
+	}
+	fake.pendingDeviceRevocationsReturnsOnCall[i] = struct {
+		result1 []model.PendingDeviceRevocation
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *HealthMonitoringModel) PendingDevices() (map[protocol.DeviceID]db.ObservedDevice, error) {
 	fake.pendingDevicesMutex.Lock()
 	ret, specificReturn := fake.pendingDevicesReturnsOnCall[len(fake.pendingDevicesArgsForCall)]
@@ -3026,6 +5245,61 @@ func (fake *HealthMonitoringModel) PendingDevicesReturnsOnCall(i int, result1 ma
 	}{result1, result2}
 }
 
+func (fake *HealthMonitoringModel) PendingFolderHandovers() ([]model.PendingFolderHandover, error) {
+	fake.pendingFolderHandoversMutex.Lock()
+	ret, specificReturn := fake.pendingFolderHandoversReturnsOnCall[len(fake.pendingFolderHandoversArgsForCall)]
+	fake.pendingFolderHandoversArgsForCall = append(fake.pendingFolderHandoversArgsForCall, struct{}{})
+	stub := fake.PendingFolderHandoversStub
+	fakeReturns := fake.pendingFolderHandoversReturns
+	fake.recordInvocation("PendingFolderHandovers", []interface{}{})
+	fake.pendingFolderHandoversMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) PendingFolderHandoversCallCount() int {
+	fake.pendingFolderHandoversMutex.RLock()
+	defer fake.pendingFolderHandoversMutex.RUnlock()
+	return len(fake.pendingFolderHandoversArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) PendingFolderHandoversCalls(stub func() ([]model.PendingFolderHandover, error)) {
+	fake.pendingFolderHandoversMutex.Lock()
+	defer fake.pendingFolderHandoversMutex.Unlock()
+	fake.PendingFolderHandoversStub = stub
+}
+
+func (fake *HealthMonitoringModel) PendingFolderHandoversReturns(result1 []model.PendingFolderHandover, result2 error) {
+	fake.pendingFolderHandoversMutex.Lock()
+	defer fake.pendingFolderHandoversMutex.Unlock()
+	fake.PendingFolderHandoversStub = nil
+	fake.pendingFolderHandoversReturns = struct {
+		result1 []model.PendingFolderHandover
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) PendingFolderHandoversReturnsOnCall(i int, result1 []model.PendingFolderHandover, result2 error) {
+	fake.pendingFolderHandoversMutex.Lock()
+	defer fake.pendingFolderHandoversMutex.Unlock()
+	fake.PendingFolderHandoversStub = nil
+	if fake.pendingFolderHandoversReturnsOnCall == nil {
+		fake.pendingFolderHandoversReturnsOnCall = make(map[int]struct {
+			result1 []model.PendingFolderHandover
+			result2 error
+		})
+	}
+	fake.pendingFolderHandoversReturnsOnCall[i] = struct {
+		result1 []model.PendingFolderHandover
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *HealthMonitoringModel) PendingFolders(arg1 protocol.DeviceID) (map[string]db.PendingFolder, error) {
 	fake.pendingFoldersMutex.Lock()
 	ret, specificReturn := fake.pendingFoldersReturnsOnCall[len(fake.pendingFoldersArgsForCall)]
@@ -3074,84 +5348,778 @@ func (fake *HealthMonitoringModel) PendingFoldersReturns(result1 map[string]db.P
 	}{result1, result2}
 }
 
-func (fake *HealthMonitoringModel) PendingFoldersReturnsOnCall(i int, result1 map[string]db.PendingFolder, result2 error) {
-	fake.pendingFoldersMutex.Lock()
-	defer fake.pendingFoldersMutex.Unlock()
-	fake.PendingFoldersStub = nil
-	if fake.pendingFoldersReturnsOnCall == nil {
-		fake.pendingFoldersReturnsOnCall = make(map[int]struct {
-			result1 map[string]db.PendingFolder
-			result2 error
+func (fake *HealthMonitoringModel) PendingFoldersReturnsOnCall(i int, result1 map[string]db.PendingFolder, result2 error) {
+	fake.pendingFoldersMutex.Lock()
+	defer fake.pendingFoldersMutex.Unlock()
+	fake.PendingFoldersStub = nil
+	if fake.pendingFoldersReturnsOnCall == nil {
+		fake.pendingFoldersReturnsOnCall = make(map[int]struct {
+			result1 map[string]db.PendingFolder
+			result2 error
+		})
+	}
+	fake.pendingFoldersReturnsOnCall[i] = struct {
+		result1 map[string]db.PendingFolder
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) PendingOwnershipClaims() ([]model.PendingOwnershipClaim, error) {
+	fake.pendingOwnershipClaimsMutex.Lock()
+	ret, specificReturn := fake.pendingOwnershipClaimsReturnsOnCall[len(fake.pendingOwnershipClaimsArgsForCall)]
+	fake.pendingOwnershipClaimsArgsForCall = append(fake.pendingOwnershipClaimsArgsForCall, struct{}{})
+	stub := fake.PendingOwnershipClaimsStub
+	fakeReturns := fake.pendingOwnershipClaimsReturns
+	fake.recordInvocation("PendingOwnershipClaims", []interface{}{})
+	fake.pendingOwnershipClaimsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) PendingOwnershipClaimsCallCount() int {
+	fake.pendingOwnershipClaimsMutex.RLock()
+	defer fake.pendingOwnershipClaimsMutex.RUnlock()
+	return len(fake.pendingOwnershipClaimsArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) PendingOwnershipClaimsCalls(stub func() ([]model.PendingOwnershipClaim, error)) {
+	fake.pendingOwnershipClaimsMutex.Lock()
+	defer fake.pendingOwnershipClaimsMutex.Unlock()
+	fake.PendingOwnershipClaimsStub = stub
+}
+
+func (fake *HealthMonitoringModel) PendingOwnershipClaimsReturns(result1 []model.PendingOwnershipClaim, result2 error) {
+	fake.pendingOwnershipClaimsMutex.Lock()
+	defer fake.pendingOwnershipClaimsMutex.Unlock()
+	fake.PendingOwnershipClaimsStub = nil
+	fake.pendingOwnershipClaimsReturns = struct {
+		result1 []model.PendingOwnershipClaim
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) PendingOwnershipClaimsReturnsOnCall(i int, result1 []model.PendingOwnershipClaim, result2 error) {
+	fake.pendingOwnershipClaimsMutex.Lock()
+	defer fake.pendingOwnershipClaimsMutex.Unlock()
+	fake.PendingOwnershipClaimsStub = nil
+	if fake.pendingOwnershipClaimsReturnsOnCall == nil {
+		fake.pendingOwnershipClaimsReturnsOnCall = make(map[int]struct {
+			result1 []model.PendingOwnershipClaim
+			result2 error
+		})
+	}
+	fake.pendingOwnershipClaimsReturnsOnCall[i] = struct {
+		result1 []model.PendingOwnershipClaim
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) PrefixCompletion(arg1 protocol.DeviceID, arg2 string, arg3 string) (model.FolderCompletion, error) {
+	fake.prefixCompletionMutex.Lock()
+	ret, specificReturn := fake.prefixCompletionReturnsOnCall[len(fake.prefixCompletionArgsForCall)]
+	fake.prefixCompletionArgsForCall = append(fake.prefixCompletionArgsForCall, struct {
+		arg1 protocol.DeviceID
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.PrefixCompletionStub
+	fakeReturns := fake.prefixCompletionReturns
+	fake.recordInvocation("PrefixCompletion", []interface{}{arg1, arg2, arg3})
+	fake.prefixCompletionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) PrefixCompletionCallCount() int {
+	fake.prefixCompletionMutex.RLock()
+	defer fake.prefixCompletionMutex.RUnlock()
+	return len(fake.prefixCompletionArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) PrefixCompletionCalls(stub func(protocol.DeviceID, string, string) (model.FolderCompletion, error)) {
+	fake.prefixCompletionMutex.Lock()
+	defer fake.prefixCompletionMutex.Unlock()
+	fake.PrefixCompletionStub = stub
+}
+
+func (fake *HealthMonitoringModel) PrefixCompletionArgsForCall(i int) (protocol.DeviceID, string, string) {
+	fake.prefixCompletionMutex.RLock()
+	defer fake.prefixCompletionMutex.RUnlock()
+	argsForCall := fake.prefixCompletionArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *HealthMonitoringModel) PrefixCompletionReturns(result1 model.FolderCompletion, result2 error) {
+	fake.prefixCompletionMutex.Lock()
+	defer fake.prefixCompletionMutex.Unlock()
+	fake.PrefixCompletionStub = nil
+	fake.prefixCompletionReturns = struct {
+		result1 model.FolderCompletion
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) PrefixCompletionReturnsOnCall(i int, result1 model.FolderCompletion, result2 error) {
+	fake.prefixCompletionMutex.Lock()
+	defer fake.prefixCompletionMutex.Unlock()
+	fake.PrefixCompletionStub = nil
+	if fake.prefixCompletionReturnsOnCall == nil {
+		fake.prefixCompletionReturnsOnCall = make(map[int]struct {
+			result1 model.FolderCompletion
+			result2 error
+		})
+	}
+	fake.prefixCompletionReturnsOnCall[i] = struct {
+		result1 model.FolderCompletion
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) ProbeFolderCapabilities(arg1 string) (*model.FolderCapabilities, error) {
+	fake.probeFolderCapabilitiesMutex.Lock()
+	ret, specificReturn := fake.probeFolderCapabilitiesReturnsOnCall[len(fake.probeFolderCapabilitiesArgsForCall)]
+	fake.probeFolderCapabilitiesArgsForCall = append(fake.probeFolderCapabilitiesArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ProbeFolderCapabilitiesStub
+	fakeReturns := fake.probeFolderCapabilitiesReturns
+	fake.recordInvocation("ProbeFolderCapabilities", []interface{}{arg1})
+	fake.probeFolderCapabilitiesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) ProbeFolderCapabilitiesCallCount() int {
+	fake.probeFolderCapabilitiesMutex.RLock()
+	defer fake.probeFolderCapabilitiesMutex.RUnlock()
+	return len(fake.probeFolderCapabilitiesArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) ProbeFolderCapabilitiesCalls(stub func(string) (*model.FolderCapabilities, error)) {
+	fake.probeFolderCapabilitiesMutex.Lock()
+	defer fake.probeFolderCapabilitiesMutex.Unlock()
+	fake.ProbeFolderCapabilitiesStub = stub
+}
+
+func (fake *HealthMonitoringModel) ProbeFolderCapabilitiesArgsForCall(i int) string {
+	fake.probeFolderCapabilitiesMutex.RLock()
+	defer fake.probeFolderCapabilitiesMutex.RUnlock()
+	argsForCall := fake.probeFolderCapabilitiesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) ProbeFolderCapabilitiesReturns(result1 *model.FolderCapabilities, result2 error) {
+	fake.probeFolderCapabilitiesMutex.Lock()
+	defer fake.probeFolderCapabilitiesMutex.Unlock()
+	fake.ProbeFolderCapabilitiesStub = nil
+	fake.probeFolderCapabilitiesReturns = struct {
+		result1 *model.FolderCapabilities
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) ProbeFolderCapabilitiesReturnsOnCall(i int, result1 *model.FolderCapabilities, result2 error) {
+	fake.probeFolderCapabilitiesMutex.Lock()
+	defer fake.probeFolderCapabilitiesMutex.Unlock()
+	fake.ProbeFolderCapabilitiesStub = nil
+	if fake.probeFolderCapabilitiesReturnsOnCall == nil {
+		fake.probeFolderCapabilitiesReturnsOnCall = make(map[int]struct {
+			result1 *model.FolderCapabilities
+			result2 error
+		})
+	}
+	fake.probeFolderCapabilitiesReturnsOnCall[i] = struct {
+		result1 *model.FolderCapabilities
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) PromoteConfigSyncSpare(arg1 protocol.DeviceID) ([]string, error) {
+	fake.promoteConfigSyncSpareMutex.Lock()
+	ret, specificReturn := fake.promoteConfigSyncSpareReturnsOnCall[len(fake.promoteConfigSyncSpareArgsForCall)]
+	fake.promoteConfigSyncSpareArgsForCall = append(fake.promoteConfigSyncSpareArgsForCall, struct {
+		arg1 protocol.DeviceID
+	}{arg1})
+	stub := fake.PromoteConfigSyncSpareStub
+	fakeReturns := fake.promoteConfigSyncSpareReturns
+	fake.recordInvocation("PromoteConfigSyncSpare", []interface{}{arg1})
+	fake.promoteConfigSyncSpareMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) PromoteConfigSyncSpareCallCount() int {
+	fake.promoteConfigSyncSpareMutex.RLock()
+	defer fake.promoteConfigSyncSpareMutex.RUnlock()
+	return len(fake.promoteConfigSyncSpareArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) PromoteConfigSyncSpareCalls(stub func(protocol.DeviceID) ([]string, error)) {
+	fake.promoteConfigSyncSpareMutex.Lock()
+	defer fake.promoteConfigSyncSpareMutex.Unlock()
+	fake.PromoteConfigSyncSpareStub = stub
+}
+
+func (fake *HealthMonitoringModel) PromoteConfigSyncSpareArgsForCall(i int) protocol.DeviceID {
+	fake.promoteConfigSyncSpareMutex.RLock()
+	defer fake.promoteConfigSyncSpareMutex.RUnlock()
+	argsForCall := fake.promoteConfigSyncSpareArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) PromoteConfigSyncSpareReturns(result1 []string, result2 error) {
+	fake.promoteConfigSyncSpareMutex.Lock()
+	defer fake.promoteConfigSyncSpareMutex.Unlock()
+	fake.PromoteConfigSyncSpareStub = nil
+	fake.promoteConfigSyncSpareReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) PromoteConfigSyncSpareReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.promoteConfigSyncSpareMutex.Lock()
+	defer fake.promoteConfigSyncSpareMutex.Unlock()
+	fake.PromoteConfigSyncSpareStub = nil
+	if fake.promoteConfigSyncSpareReturnsOnCall == nil {
+		fake.promoteConfigSyncSpareReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.promoteConfigSyncSpareReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) PullPreview(arg1 string) (*model.PullPreview, error) {
+	fake.pullPreviewMutex.Lock()
+	ret, specificReturn := fake.pullPreviewReturnsOnCall[len(fake.pullPreviewArgsForCall)]
+	fake.pullPreviewArgsForCall = append(fake.pullPreviewArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.PullPreviewStub
+	fakeReturns := fake.pullPreviewReturns
+	fake.recordInvocation("PullPreview", []interface{}{arg1})
+	fake.pullPreviewMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) PullPreviewCallCount() int {
+	fake.pullPreviewMutex.RLock()
+	defer fake.pullPreviewMutex.RUnlock()
+	return len(fake.pullPreviewArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) PullPreviewCalls(stub func(string) (*model.PullPreview, error)) {
+	fake.pullPreviewMutex.Lock()
+	defer fake.pullPreviewMutex.Unlock()
+	fake.PullPreviewStub = stub
+}
+
+func (fake *HealthMonitoringModel) PullPreviewArgsForCall(i int) string {
+	fake.pullPreviewMutex.RLock()
+	defer fake.pullPreviewMutex.RUnlock()
+	argsForCall := fake.pullPreviewArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) PullPreviewReturns(result1 *model.PullPreview, result2 error) {
+	fake.pullPreviewMutex.Lock()
+	defer fake.pullPreviewMutex.Unlock()
+	fake.PullPreviewStub = nil
+	fake.pullPreviewReturns = struct {
+		result1 *model.PullPreview
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) PullPreviewReturnsOnCall(i int, result1 *model.PullPreview, result2 error) {
+	fake.pullPreviewMutex.Lock()
+	defer fake.pullPreviewMutex.Unlock()
+	fake.PullPreviewStub = nil
+	if fake.pullPreviewReturnsOnCall == nil {
+		fake.pullPreviewReturnsOnCall = make(map[int]struct {
+			result1 *model.PullPreview
+			result2 error
+		})
+	}
+	fake.pullPreviewReturnsOnCall[i] = struct {
+		result1 *model.PullPreview
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) QueueDeviceMessage(arg1 protocol.DeviceID, arg2 string, arg3 string, arg4 []byte) error {
+	var arg4Copy []byte
+	if arg4 != nil {
+		arg4Copy = make([]byte, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.queueDeviceMessageMutex.Lock()
+	ret, specificReturn := fake.queueDeviceMessageReturnsOnCall[len(fake.queueDeviceMessageArgsForCall)]
+	fake.queueDeviceMessageArgsForCall = append(fake.queueDeviceMessageArgsForCall, struct {
+		arg1 protocol.DeviceID
+		arg2 string
+		arg3 string
+		arg4 []byte
+	}{arg1, arg2, arg3, arg4Copy})
+	stub := fake.QueueDeviceMessageStub
+	fakeReturns := fake.queueDeviceMessageReturns
+	fake.recordInvocation("QueueDeviceMessage", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.queueDeviceMessageMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *HealthMonitoringModel) QueueDeviceMessageCallCount() int {
+	fake.queueDeviceMessageMutex.RLock()
+	defer fake.queueDeviceMessageMutex.RUnlock()
+	return len(fake.queueDeviceMessageArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) QueueDeviceMessageCalls(stub func(protocol.DeviceID, string, string, []byte) error) {
+	fake.queueDeviceMessageMutex.Lock()
+	defer fake.queueDeviceMessageMutex.Unlock()
+	fake.QueueDeviceMessageStub = stub
+}
+
+func (fake *HealthMonitoringModel) QueueDeviceMessageArgsForCall(i int) (protocol.DeviceID, string, string, []byte) {
+	fake.queueDeviceMessageMutex.RLock()
+	defer fake.queueDeviceMessageMutex.RUnlock()
+	argsForCall := fake.queueDeviceMessageArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *HealthMonitoringModel) QueueDeviceMessageReturns(result1 error) {
+	fake.queueDeviceMessageMutex.Lock()
+	defer fake.queueDeviceMessageMutex.Unlock()
+	fake.QueueDeviceMessageStub = nil
+	fake.queueDeviceMessageReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) QueueDeviceMessageReturnsOnCall(i int, result1 error) {
+	fake.queueDeviceMessageMutex.Lock()
+	defer fake.queueDeviceMessageMutex.Unlock()
+	fake.QueueDeviceMessageStub = nil
+	if fake.queueDeviceMessageReturnsOnCall == nil {
+		fake.queueDeviceMessageReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.queueDeviceMessageReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) QueuedDeviceMessages(arg1 protocol.DeviceID) ([]model.QueuedMessage, error) {
+	fake.queuedDeviceMessagesMutex.Lock()
+	ret, specificReturn := fake.queuedDeviceMessagesReturnsOnCall[len(fake.queuedDeviceMessagesArgsForCall)]
+	fake.queuedDeviceMessagesArgsForCall = append(fake.queuedDeviceMessagesArgsForCall, struct {
+		arg1 protocol.DeviceID
+	}{arg1})
+	stub := fake.QueuedDeviceMessagesStub
+	fakeReturns := fake.queuedDeviceMessagesReturns
+	fake.recordInvocation("QueuedDeviceMessages", []interface{}{arg1})
+	fake.queuedDeviceMessagesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) QueuedDeviceMessagesCallCount() int {
+	fake.queuedDeviceMessagesMutex.RLock()
+	defer fake.queuedDeviceMessagesMutex.RUnlock()
+	return len(fake.queuedDeviceMessagesArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) QueuedDeviceMessagesCalls(stub func(protocol.DeviceID) ([]model.QueuedMessage, error)) {
+	fake.queuedDeviceMessagesMutex.Lock()
+	defer fake.queuedDeviceMessagesMutex.Unlock()
+	fake.QueuedDeviceMessagesStub = stub
+}
+
+func (fake *HealthMonitoringModel) QueuedDeviceMessagesArgsForCall(i int) protocol.DeviceID {
+	fake.queuedDeviceMessagesMutex.RLock()
+	defer fake.queuedDeviceMessagesMutex.RUnlock()
+	argsForCall := fake.queuedDeviceMessagesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) QueuedDeviceMessagesReturns(result1 []model.QueuedMessage, result2 error) {
+	fake.queuedDeviceMessagesMutex.Lock()
+	defer fake.queuedDeviceMessagesMutex.Unlock()
+	fake.QueuedDeviceMessagesStub = nil
+	fake.queuedDeviceMessagesReturns = struct {
+		result1 []model.QueuedMessage
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) QueuedDeviceMessagesReturnsOnCall(i int, result1 []model.QueuedMessage, result2 error) {
+	fake.queuedDeviceMessagesMutex.Lock()
+	defer fake.queuedDeviceMessagesMutex.Unlock()
+	fake.QueuedDeviceMessagesStub = nil
+	if fake.queuedDeviceMessagesReturnsOnCall == nil {
+		fake.queuedDeviceMessagesReturnsOnCall = make(map[int]struct {
+			result1 []model.QueuedMessage
+			result2 error
+		})
+	}
+	fake.queuedDeviceMessagesReturnsOnCall[i] = struct {
+		result1 []model.QueuedMessage
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) ReceiveOnlySize(arg1 string) (db.Counts, error) {
+	fake.receiveOnlySizeMutex.Lock()
+	ret, specificReturn := fake.receiveOnlySizeReturnsOnCall[len(fake.receiveOnlySizeArgsForCall)]
+	fake.receiveOnlySizeArgsForCall = append(fake.receiveOnlySizeArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ReceiveOnlySizeStub
+	fakeReturns := fake.receiveOnlySizeReturns
+	fake.recordInvocation("ReceiveOnlySize", []interface{}{arg1})
+	fake.receiveOnlySizeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) ReceiveOnlySizeCallCount() int {
+	fake.receiveOnlySizeMutex.RLock()
+	defer fake.receiveOnlySizeMutex.RUnlock()
+	return len(fake.receiveOnlySizeArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) ReceiveOnlySizeCalls(stub func(string) (db.Counts, error)) {
+	fake.receiveOnlySizeMutex.Lock()
+	defer fake.receiveOnlySizeMutex.Unlock()
+	fake.ReceiveOnlySizeStub = stub
+}
+
+func (fake *HealthMonitoringModel) ReceiveOnlySizeArgsForCall(i int) string {
+	fake.receiveOnlySizeMutex.RLock()
+	defer fake.receiveOnlySizeMutex.RUnlock()
+	argsForCall := fake.receiveOnlySizeArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) ReceiveOnlySizeReturns(result1 db.Counts, result2 error) {
+	fake.receiveOnlySizeMutex.Lock()
+	defer fake.receiveOnlySizeMutex.Unlock()
+	fake.ReceiveOnlySizeStub = nil
+	fake.receiveOnlySizeReturns = struct {
+		result1 db.Counts
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) ReceiveOnlySizeReturnsOnCall(i int, result1 db.Counts, result2 error) {
+	fake.receiveOnlySizeMutex.Lock()
+	defer fake.receiveOnlySizeMutex.Unlock()
+	fake.ReceiveOnlySizeStub = nil
+	if fake.receiveOnlySizeReturnsOnCall == nil {
+		fake.receiveOnlySizeReturnsOnCall = make(map[int]struct {
+			result1 db.Counts
+			result2 error
+		})
+	}
+	fake.receiveOnlySizeReturnsOnCall[i] = struct {
+		result1 db.Counts
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) RejectConfigSync(arg1 protocol.DeviceID, arg2 string, arg3 string) error {
+	fake.rejectConfigSyncMutex.Lock()
+	ret, specificReturn := fake.rejectConfigSyncReturnsOnCall[len(fake.rejectConfigSyncArgsForCall)]
+	fake.rejectConfigSyncArgsForCall = append(fake.rejectConfigSyncArgsForCall, struct {
+		arg1 protocol.DeviceID
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.RejectConfigSyncStub
+	fakeReturns := fake.rejectConfigSyncReturns
+	fake.recordInvocation("RejectConfigSync", []interface{}{arg1, arg2, arg3})
+	fake.rejectConfigSyncMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *HealthMonitoringModel) RejectConfigSyncCallCount() int {
+	fake.rejectConfigSyncMutex.RLock()
+	defer fake.rejectConfigSyncMutex.RUnlock()
+	return len(fake.rejectConfigSyncArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) RejectConfigSyncCalls(stub func(protocol.DeviceID, string, string) error) {
+	fake.rejectConfigSyncMutex.Lock()
+	defer fake.rejectConfigSyncMutex.Unlock()
+	fake.RejectConfigSyncStub = stub
+}
+
+func (fake *HealthMonitoringModel) RejectConfigSyncArgsForCall(i int) (protocol.DeviceID, string, string) {
+	fake.rejectConfigSyncMutex.RLock()
+	defer fake.rejectConfigSyncMutex.RUnlock()
+	argsForCall := fake.rejectConfigSyncArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *HealthMonitoringModel) RejectConfigSyncReturns(result1 error) {
+	fake.rejectConfigSyncMutex.Lock()
+	defer fake.rejectConfigSyncMutex.Unlock()
+	fake.RejectConfigSyncStub = nil
+	fake.rejectConfigSyncReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) RejectConfigSyncReturnsOnCall(i int, result1 error) {
+	fake.rejectConfigSyncMutex.Lock()
+	defer fake.rejectConfigSyncMutex.Unlock()
+	fake.RejectConfigSyncStub = nil
+	if fake.rejectConfigSyncReturnsOnCall == nil {
+		fake.rejectConfigSyncReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.rejectConfigSyncReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) RejectDeviceRevocation(arg1 protocol.DeviceID) error {
+	fake.rejectDeviceRevocationMutex.Lock()
+	ret, specificReturn := fake.rejectDeviceRevocationReturnsOnCall[len(fake.rejectDeviceRevocationArgsForCall)]
+	fake.rejectDeviceRevocationArgsForCall = append(fake.rejectDeviceRevocationArgsForCall, struct {
+		arg1 protocol.DeviceID
+	}{arg1})
+	stub := fake.RejectDeviceRevocationStub
+	fakeReturns := fake.rejectDeviceRevocationReturns
+	fake.recordInvocation("RejectDeviceRevocation", []interface{}{arg1})
+	fake.rejectDeviceRevocationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *HealthMonitoringModel) RejectDeviceRevocationCallCount() int {
+	fake.rejectDeviceRevocationMutex.RLock()
+	defer fake.rejectDeviceRevocationMutex.RUnlock()
+	return len(fake.rejectDeviceRevocationArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) RejectDeviceRevocationCalls(stub func(protocol.DeviceID) error) {
+	fake.rejectDeviceRevocationMutex.Lock()
+	defer fake.rejectDeviceRevocationMutex.Unlock()
+	fake.RejectDeviceRevocationStub = stub
+}
+
+func (fake *HealthMonitoringModel) RejectDeviceRevocationArgsForCall(i int) protocol.DeviceID {
+	fake.rejectDeviceRevocationMutex.RLock()
+	defer fake.rejectDeviceRevocationMutex.RUnlock()
+	argsForCall := fake.rejectDeviceRevocationArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) RejectDeviceRevocationReturns(result1 error) {
+	fake.rejectDeviceRevocationMutex.Lock()
+	defer fake.rejectDeviceRevocationMutex.Unlock()
+	fake.RejectDeviceRevocationStub = nil
+	fake.rejectDeviceRevocationReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) RejectDeviceRevocationReturnsOnCall(i int, result1 error) {
+	fake.rejectDeviceRevocationMutex.Lock()
+	defer fake.rejectDeviceRevocationMutex.Unlock()
+	fake.RejectDeviceRevocationStub = nil
+	if fake.rejectDeviceRevocationReturnsOnCall == nil {
+		fake.rejectDeviceRevocationReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.rejectDeviceRevocationReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) RejectFolderHandover(arg1 string, arg2 protocol.DeviceID) error {
+	fake.rejectFolderHandoverMutex.Lock()
+	ret, specificReturn := fake.rejectFolderHandoverReturnsOnCall[len(fake.rejectFolderHandoverArgsForCall)]
+	fake.rejectFolderHandoverArgsForCall = append(fake.rejectFolderHandoverArgsForCall, struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}{arg1, arg2})
+	stub := fake.RejectFolderHandoverStub
+	fakeReturns := fake.rejectFolderHandoverReturns
+	fake.recordInvocation("RejectFolderHandover", []interface{}{arg1, arg2})
+	fake.rejectFolderHandoverMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *HealthMonitoringModel) RejectFolderHandoverCallCount() int {
+	fake.rejectFolderHandoverMutex.RLock()
+	defer fake.rejectFolderHandoverMutex.RUnlock()
+	return len(fake.rejectFolderHandoverArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) RejectFolderHandoverCalls(stub func(string, protocol.DeviceID) error) {
+	fake.rejectFolderHandoverMutex.Lock()
+	defer fake.rejectFolderHandoverMutex.Unlock()
+	fake.RejectFolderHandoverStub = stub
+}
+
+func (fake *HealthMonitoringModel) RejectFolderHandoverArgsForCall(i int) (string, protocol.DeviceID) {
+	fake.rejectFolderHandoverMutex.RLock()
+	defer fake.rejectFolderHandoverMutex.RUnlock()
+	argsForCall := fake.rejectFolderHandoverArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *HealthMonitoringModel) RejectFolderHandoverReturns(result1 error) {
+	fake.rejectFolderHandoverMutex.Lock()
+	defer fake.rejectFolderHandoverMutex.Unlock()
+	fake.RejectFolderHandoverStub = nil
+	fake.rejectFolderHandoverReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) RejectFolderHandoverReturnsOnCall(i int, result1 error) {
+	fake.rejectFolderHandoverMutex.Lock()
+	defer fake.rejectFolderHandoverMutex.Unlock()
+	fake.RejectFolderHandoverStub = nil
+	if fake.rejectFolderHandoverReturnsOnCall == nil {
+		fake.rejectFolderHandoverReturnsOnCall = make(map[int]struct {
+			result1 error
 		})
 	}
-	fake.pendingFoldersReturnsOnCall[i] = struct {
-		result1 map[string]db.PendingFolder
-		result2 error
-	}{result1, result2}
+	fake.rejectFolderHandoverReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
 }
 
-func (fake *HealthMonitoringModel) ReceiveOnlySize(arg1 string) (db.Counts, error) {
-	fake.receiveOnlySizeMutex.Lock()
-	ret, specificReturn := fake.receiveOnlySizeReturnsOnCall[len(fake.receiveOnlySizeArgsForCall)]
-	fake.receiveOnlySizeArgsForCall = append(fake.receiveOnlySizeArgsForCall, struct {
+func (fake *HealthMonitoringModel) RejectOwnershipClaim(arg1 string, arg2 protocol.DeviceID) error {
+	fake.rejectOwnershipClaimMutex.Lock()
+	ret, specificReturn := fake.rejectOwnershipClaimReturnsOnCall[len(fake.rejectOwnershipClaimArgsForCall)]
+	fake.rejectOwnershipClaimArgsForCall = append(fake.rejectOwnershipClaimArgsForCall, struct {
 		arg1 string
-	}{arg1})
-	stub := fake.ReceiveOnlySizeStub
-	fakeReturns := fake.receiveOnlySizeReturns
-	fake.recordInvocation("ReceiveOnlySize", []interface{}{arg1})
-	fake.receiveOnlySizeMutex.Unlock()
+		arg2 protocol.DeviceID
+	}{arg1, arg2})
+	stub := fake.RejectOwnershipClaimStub
+	fakeReturns := fake.rejectOwnershipClaimReturns
+	fake.recordInvocation("RejectOwnershipClaim", []interface{}{arg1, arg2})
+	fake.rejectOwnershipClaimMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
-		return ret.result1, ret.result2
+		return ret.result1
 	}
-	return fakeReturns.result1, fakeReturns.result2
+	return fakeReturns.result1
 }
 
-func (fake *HealthMonitoringModel) ReceiveOnlySizeCallCount() int {
-	fake.receiveOnlySizeMutex.RLock()
-	defer fake.receiveOnlySizeMutex.RUnlock()
-	return len(fake.receiveOnlySizeArgsForCall)
+func (fake *HealthMonitoringModel) RejectOwnershipClaimCallCount() int {
+	fake.rejectOwnershipClaimMutex.RLock()
+	defer fake.rejectOwnershipClaimMutex.RUnlock()
+	return len(fake.rejectOwnershipClaimArgsForCall)
 }
 
-func (fake *HealthMonitoringModel) ReceiveOnlySizeCalls(stub func(string) (db.Counts, error)) {
-	fake.receiveOnlySizeMutex.Lock()
-	defer fake.receiveOnlySizeMutex.Unlock()
-	fake.ReceiveOnlySizeStub = stub
+func (fake *HealthMonitoringModel) RejectOwnershipClaimCalls(stub func(string, protocol.DeviceID) error) {
+	fake.rejectOwnershipClaimMutex.Lock()
+	defer fake.rejectOwnershipClaimMutex.Unlock()
+	fake.RejectOwnershipClaimStub = stub
 }
 
-func (fake *HealthMonitoringModel) ReceiveOnlySizeArgsForCall(i int) string {
-	fake.receiveOnlySizeMutex.RLock()
-	defer fake.receiveOnlySizeMutex.RUnlock()
-	argsForCall := fake.receiveOnlySizeArgsForCall[i]
-	return argsForCall.arg1
+func (fake *HealthMonitoringModel) RejectOwnershipClaimArgsForCall(i int) (string, protocol.DeviceID) {
+	fake.rejectOwnershipClaimMutex.RLock()
+	defer fake.rejectOwnershipClaimMutex.RUnlock()
+	argsForCall := fake.rejectOwnershipClaimArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *HealthMonitoringModel) ReceiveOnlySizeReturns(result1 db.Counts, result2 error) {
-	fake.receiveOnlySizeMutex.Lock()
-	defer fake.receiveOnlySizeMutex.Unlock()
-	fake.ReceiveOnlySizeStub = nil
-	fake.receiveOnlySizeReturns = struct {
-		result1 db.Counts
-		result2 error
-	}{result1, result2}
+func (fake *HealthMonitoringModel) RejectOwnershipClaimReturns(result1 error) {
+	fake.rejectOwnershipClaimMutex.Lock()
+	defer fake.rejectOwnershipClaimMutex.Unlock()
+	fake.RejectOwnershipClaimStub = nil
+	fake.rejectOwnershipClaimReturns = struct {
+		result1 error
+	}{result1}
 }
 
-func (fake *HealthMonitoringModel) ReceiveOnlySizeReturnsOnCall(i int, result1 db.Counts, result2 error) {
-	fake.receiveOnlySizeMutex.Lock()
-	defer fake.receiveOnlySizeMutex.Unlock()
-	fake.ReceiveOnlySizeStub = nil
-	if fake.receiveOnlySizeReturnsOnCall == nil {
-		fake.receiveOnlySizeReturnsOnCall = make(map[int]struct {
-			result1 db.Counts
-			result2 error
+func (fake *HealthMonitoringModel) RejectOwnershipClaimReturnsOnCall(i int, result1 error) {
+	fake.rejectOwnershipClaimMutex.Lock()
+	defer fake.rejectOwnershipClaimMutex.Unlock()
+	fake.RejectOwnershipClaimStub = nil
+	if fake.rejectOwnershipClaimReturnsOnCall == nil {
+		fake.rejectOwnershipClaimReturnsOnCall = make(map[int]struct {
+			result1 error
 		})
 	}
-	fake.receiveOnlySizeReturnsOnCall[i] = struct {
-		result1 db.Counts
-		result2 error
-	}{result1, result2}
+	fake.rejectOwnershipClaimReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
 }
 
 func (fake *HealthMonitoringModel) RemoteNeedFolderFiles(arg1 string, arg2 protocol.DeviceID, arg3 int, arg4 int) ([]protocol.FileInfo, error) {
@@ -3350,6 +6318,73 @@ func (fake *HealthMonitoringModel) RequestReturnsOnCall(i int, result1 protocol.
 	}{result1, result2}
 }
 
+func (fake *HealthMonitoringModel) RequestFolderPreview(arg1 context.Context, arg2 protocol.DeviceID, arg3 string, arg4 int) (*protocol.FolderPreview, error) {
+	fake.requestFolderPreviewMutex.Lock()
+	ret, specificReturn := fake.requestFolderPreviewReturnsOnCall[len(fake.requestFolderPreviewArgsForCall)]
+	fake.requestFolderPreviewArgsForCall = append(fake.requestFolderPreviewArgsForCall, struct {
+		arg1 context.Context
+		arg2 protocol.DeviceID
+		arg3 string
+		arg4 int
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.RequestFolderPreviewStub
+	fakeReturns := fake.requestFolderPreviewReturns
+	fake.recordInvocation("RequestFolderPreview", []interface{}{arg1, arg2, arg3, arg4})
+	fake.requestFolderPreviewMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) RequestFolderPreviewCallCount() int {
+	fake.requestFolderPreviewMutex.RLock()
+	defer fake.requestFolderPreviewMutex.RUnlock()
+	return len(fake.requestFolderPreviewArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) RequestFolderPreviewCalls(stub func(context.Context, protocol.DeviceID, string, int) (*protocol.FolderPreview, error)) {
+	fake.requestFolderPreviewMutex.Lock()
+	defer fake.requestFolderPreviewMutex.Unlock()
+	fake.RequestFolderPreviewStub = stub
+}
+
+func (fake *HealthMonitoringModel) RequestFolderPreviewArgsForCall(i int) (context.Context, protocol.DeviceID, string, int) {
+	fake.requestFolderPreviewMutex.RLock()
+	defer fake.requestFolderPreviewMutex.RUnlock()
+	argsForCall := fake.requestFolderPreviewArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *HealthMonitoringModel) RequestFolderPreviewReturns(result1 *protocol.FolderPreview, result2 error) {
+	fake.requestFolderPreviewMutex.Lock()
+	defer fake.requestFolderPreviewMutex.Unlock()
+	fake.RequestFolderPreviewStub = nil
+	fake.requestFolderPreviewReturns = struct {
+		result1 *protocol.FolderPreview
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) RequestFolderPreviewReturnsOnCall(i int, result1 *protocol.FolderPreview, result2 error) {
+	fake.requestFolderPreviewMutex.Lock()
+	defer fake.requestFolderPreviewMutex.Unlock()
+	fake.RequestFolderPreviewStub = nil
+	if fake.requestFolderPreviewReturnsOnCall == nil {
+		fake.requestFolderPreviewReturnsOnCall = make(map[int]struct {
+			result1 *protocol.FolderPreview
+			result2 error
+		})
+	}
+	fake.requestFolderPreviewReturnsOnCall[i] = struct {
+		result1 *protocol.FolderPreview
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *HealthMonitoringModel) RequestGlobal(arg1 context.Context, arg2 protocol.DeviceID, arg3 string, arg4 string, arg5 int, arg6 int64, arg7 int, arg8 []byte, arg9 bool) ([]byte, error) {
 	var arg8Copy []byte
 	if arg8 != nil {
@@ -3427,6 +6462,67 @@ func (fake *HealthMonitoringModel) RequestGlobalReturnsOnCall(i int, result1 []b
 	}{result1, result2}
 }
 
+func (fake *HealthMonitoringModel) RescanInterval(arg1 string) time.Duration {
+	fake.rescanIntervalMutex.Lock()
+	ret, specificReturn := fake.rescanIntervalReturnsOnCall[len(fake.rescanIntervalArgsForCall)]
+	fake.rescanIntervalArgsForCall = append(fake.rescanIntervalArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RescanIntervalStub
+	fakeReturns := fake.rescanIntervalReturns
+	fake.recordInvocation("RescanInterval", []interface{}{arg1})
+	fake.rescanIntervalMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *HealthMonitoringModel) RescanIntervalCallCount() int {
+	fake.rescanIntervalMutex.RLock()
+	defer fake.rescanIntervalMutex.RUnlock()
+	return len(fake.rescanIntervalArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) RescanIntervalCalls(stub func(string) time.Duration) {
+	fake.rescanIntervalMutex.Lock()
+	defer fake.rescanIntervalMutex.Unlock()
+	fake.RescanIntervalStub = stub
+}
+
+func (fake *HealthMonitoringModel) RescanIntervalArgsForCall(i int) string {
+	fake.rescanIntervalMutex.RLock()
+	defer fake.rescanIntervalMutex.RUnlock()
+	argsForCall := fake.rescanIntervalArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *HealthMonitoringModel) RescanIntervalReturns(result1 time.Duration) {
+	fake.rescanIntervalMutex.Lock()
+	defer fake.rescanIntervalMutex.Unlock()
+	fake.RescanIntervalStub = nil
+	fake.rescanIntervalReturns = struct {
+		result1 time.Duration
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) RescanIntervalReturnsOnCall(i int, result1 time.Duration) {
+	fake.rescanIntervalMutex.Lock()
+	defer fake.rescanIntervalMutex.Unlock()
+	fake.RescanIntervalStub = nil
+	if fake.rescanIntervalReturnsOnCall == nil {
+		fake.rescanIntervalReturnsOnCall = make(map[int]struct {
+			result1 time.Duration
+		})
+	}
+	fake.rescanIntervalReturnsOnCall[i] = struct {
+		result1 time.Duration
+	}{result1}
+}
+
 func (fake *HealthMonitoringModel) ResetFolder(arg1 string) error {
 	fake.resetFolderMutex.Lock()
 	ret, specificReturn := fake.resetFolderReturnsOnCall[len(fake.resetFolderArgsForCall)]
@@ -3488,6 +6584,69 @@ func (fake *HealthMonitoringModel) ResetFolderReturnsOnCall(i int, result1 error
 	}{result1}
 }
 
+func (fake *HealthMonitoringModel) ResolveConflict(arg1 string, arg2 string, arg3 string) error {
+	fake.resolveConflictMutex.Lock()
+	ret, specificReturn := fake.resolveConflictReturnsOnCall[len(fake.resolveConflictArgsForCall)]
+	fake.resolveConflictArgsForCall = append(fake.resolveConflictArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ResolveConflictStub
+	fakeReturns := fake.resolveConflictReturns
+	fake.recordInvocation("ResolveConflict", []interface{}{arg1, arg2, arg3})
+	fake.resolveConflictMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *HealthMonitoringModel) ResolveConflictCallCount() int {
+	fake.resolveConflictMutex.RLock()
+	defer fake.resolveConflictMutex.RUnlock()
+	return len(fake.resolveConflictArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) ResolveConflictCalls(stub func(string, string, string) error) {
+	fake.resolveConflictMutex.Lock()
+	defer fake.resolveConflictMutex.Unlock()
+	fake.ResolveConflictStub = stub
+}
+
+func (fake *HealthMonitoringModel) ResolveConflictArgsForCall(i int) (string, string, string) {
+	fake.resolveConflictMutex.RLock()
+	defer fake.resolveConflictMutex.RUnlock()
+	argsForCall := fake.resolveConflictArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *HealthMonitoringModel) ResolveConflictReturns(result1 error) {
+	fake.resolveConflictMutex.Lock()
+	defer fake.resolveConflictMutex.Unlock()
+	fake.ResolveConflictStub = nil
+	fake.resolveConflictReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) ResolveConflictReturnsOnCall(i int, result1 error) {
+	fake.resolveConflictMutex.Lock()
+	defer fake.resolveConflictMutex.Unlock()
+	fake.ResolveConflictStub = nil
+	if fake.resolveConflictReturnsOnCall == nil {
+		fake.resolveConflictReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.resolveConflictReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *HealthMonitoringModel) RestoreFolderVersions(arg1 string, arg2 map[string]time.Time) (map[string]error, error) {
 	fake.restoreFolderVersionsMutex.Lock()
 	ret, specificReturn := fake.restoreFolderVersionsReturnsOnCall[len(fake.restoreFolderVersionsArgsForCall)]
@@ -3585,6 +6744,71 @@ func (fake *HealthMonitoringModel) RevertArgsForCall(i int) string {
 	return argsForCall.arg1
 }
 
+func (fake *HealthMonitoringModel) RevokeDevice(arg1 protocol.DeviceID, arg2 bool) (model.DeviceRevocation, error) {
+	fake.revokeDeviceMutex.Lock()
+	ret, specificReturn := fake.revokeDeviceReturnsOnCall[len(fake.revokeDeviceArgsForCall)]
+	fake.revokeDeviceArgsForCall = append(fake.revokeDeviceArgsForCall, struct {
+		arg1 protocol.DeviceID
+		arg2 bool
+	}{arg1, arg2})
+	stub := fake.RevokeDeviceStub
+	fakeReturns := fake.revokeDeviceReturns
+	fake.recordInvocation("RevokeDevice", []interface{}{arg1, arg2})
+	fake.revokeDeviceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) RevokeDeviceCallCount() int {
+	fake.revokeDeviceMutex.RLock()
+	defer fake.revokeDeviceMutex.RUnlock()
+	return len(fake.revokeDeviceArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) RevokeDeviceCalls(stub func(protocol.DeviceID, bool) (model.DeviceRevocation, error)) {
+	fake.revokeDeviceMutex.Lock()
+	defer fake.revokeDeviceMutex.Unlock()
+	fake.RevokeDeviceStub = stub
+}
+
+func (fake *HealthMonitoringModel) RevokeDeviceArgsForCall(i int) (protocol.DeviceID, bool) {
+	fake.revokeDeviceMutex.RLock()
+	defer fake.revokeDeviceMutex.RUnlock()
+	argsForCall := fake.revokeDeviceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *HealthMonitoringModel) RevokeDeviceReturns(result1 model.DeviceRevocation, result2 error) {
+	fake.revokeDeviceMutex.Lock()
+	defer fake.revokeDeviceMutex.Unlock()
+	fake.RevokeDeviceStub = nil
+	fake.revokeDeviceReturns = struct {
+		result1 model.DeviceRevocation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) RevokeDeviceReturnsOnCall(i int, result1 model.DeviceRevocation, result2 error) {
+	fake.revokeDeviceMutex.Lock()
+	defer fake.revokeDeviceMutex.Unlock()
+	fake.RevokeDeviceStub = nil
+	if fake.revokeDeviceReturnsOnCall == nil {
+		fake.revokeDeviceReturnsOnCall = make(map[int]struct {
+			result1 model.DeviceRevocation
+			result2 error
+		})
+	}
+	fake.revokeDeviceReturnsOnCall[i] = struct {
+		result1 model.DeviceRevocation
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *HealthMonitoringModel) ScanFolder(arg1 string) error {
 	fake.scanFolderMutex.Lock()
 	ret, specificReturn := fake.scanFolderReturnsOnCall[len(fake.scanFolderArgsForCall)]
@@ -3991,6 +7215,72 @@ func (fake *HealthMonitoringModel) SetIgnoresReturnsOnCall(i int, result1 error)
 	}{result1}
 }
 
+func (fake *HealthMonitoringModel) StartFolderHandover(arg1 string, arg2 protocol.DeviceID, arg3 string) (model.FolderHandover, error) {
+	fake.startFolderHandoverMutex.Lock()
+	ret, specificReturn := fake.startFolderHandoverReturnsOnCall[len(fake.startFolderHandoverArgsForCall)]
+	fake.startFolderHandoverArgsForCall = append(fake.startFolderHandoverArgsForCall, struct {
+		arg1 string
+		arg2 protocol.DeviceID
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.StartFolderHandoverStub
+	fakeReturns := fake.startFolderHandoverReturns
+	fake.recordInvocation("StartFolderHandover", []interface{}{arg1, arg2, arg3})
+	fake.startFolderHandoverMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *HealthMonitoringModel) StartFolderHandoverCallCount() int {
+	fake.startFolderHandoverMutex.RLock()
+	defer fake.startFolderHandoverMutex.RUnlock()
+	return len(fake.startFolderHandoverArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) StartFolderHandoverCalls(stub func(string, protocol.DeviceID, string) (model.FolderHandover, error)) {
+	fake.startFolderHandoverMutex.Lock()
+	defer fake.startFolderHandoverMutex.Unlock()
+	fake.StartFolderHandoverStub = stub
+}
+
+func (fake *HealthMonitoringModel) StartFolderHandoverArgsForCall(i int) (string, protocol.DeviceID, string) {
+	fake.startFolderHandoverMutex.RLock()
+	defer fake.startFolderHandoverMutex.RUnlock()
+	argsForCall := fake.startFolderHandoverArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *HealthMonitoringModel) StartFolderHandoverReturns(result1 model.FolderHandover, result2 error) {
+	fake.startFolderHandoverMutex.Lock()
+	defer fake.startFolderHandoverMutex.Unlock()
+	fake.StartFolderHandoverStub = nil
+	fake.startFolderHandoverReturns = struct {
+		result1 model.FolderHandover
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *HealthMonitoringModel) StartFolderHandoverReturnsOnCall(i int, result1 model.FolderHandover, result2 error) {
+	fake.startFolderHandoverMutex.Lock()
+	defer fake.startFolderHandoverMutex.Unlock()
+	fake.StartFolderHandoverStub = nil
+	if fake.startFolderHandoverReturnsOnCall == nil {
+		fake.startFolderHandoverReturnsOnCall = make(map[int]struct {
+			result1 model.FolderHandover
+			result2 error
+		})
+	}
+	fake.startFolderHandoverReturnsOnCall[i] = struct {
+		result1 model.FolderHandover
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *HealthMonitoringModel) State(arg1 string) (string, time.Time, error) {
 	fake.stateMutex.Lock()
 	ret, specificReturn := fake.stateReturnsOnCall[len(fake.stateArgsForCall)]
@@ -4092,6 +7382,69 @@ func (fake *HealthMonitoringModel) UsageReportingStatsArgsForCall(i int) (*contr
 	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
+func (fake *HealthMonitoringModel) WaitForFolderIdle(arg1 context.Context, arg2 string, arg3 time.Duration) error {
+	fake.waitForFolderIdleMutex.Lock()
+	ret, specificReturn := fake.waitForFolderIdleReturnsOnCall[len(fake.waitForFolderIdleArgsForCall)]
+	fake.waitForFolderIdleArgsForCall = append(fake.waitForFolderIdleArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.WaitForFolderIdleStub
+	fakeReturns := fake.waitForFolderIdleReturns
+	fake.recordInvocation("WaitForFolderIdle", []interface{}{arg1, arg2, arg3})
+	fake.waitForFolderIdleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *HealthMonitoringModel) WaitForFolderIdleCallCount() int {
+	fake.waitForFolderIdleMutex.RLock()
+	defer fake.waitForFolderIdleMutex.RUnlock()
+	return len(fake.waitForFolderIdleArgsForCall)
+}
+
+func (fake *HealthMonitoringModel) WaitForFolderIdleCalls(stub func(context.Context, string, time.Duration) error) {
+	fake.waitForFolderIdleMutex.Lock()
+	defer fake.waitForFolderIdleMutex.Unlock()
+	fake.WaitForFolderIdleStub = stub
+}
+
+func (fake *HealthMonitoringModel) WaitForFolderIdleArgsForCall(i int) (context.Context, string, time.Duration) {
+	fake.waitForFolderIdleMutex.RLock()
+	defer fake.waitForFolderIdleMutex.RUnlock()
+	argsForCall := fake.waitForFolderIdleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *HealthMonitoringModel) WaitForFolderIdleReturns(result1 error) {
+	fake.waitForFolderIdleMutex.Lock()
+	defer fake.waitForFolderIdleMutex.Unlock()
+	fake.WaitForFolderIdleStub = nil
+	fake.waitForFolderIdleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *HealthMonitoringModel) WaitForFolderIdleReturnsOnCall(i int, result1 error) {
+	fake.waitForFolderIdleMutex.Lock()
+	defer fake.waitForFolderIdleMutex.Unlock()
+	fake.WaitForFolderIdleStub = nil
+	if fake.waitForFolderIdleReturnsOnCall == nil {
+		fake.waitForFolderIdleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.waitForFolderIdleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *HealthMonitoringModel) WatchError(arg1 string) error {
 	fake.watchErrorMutex.Lock()
 	ret, specificReturn := fake.watchErrorReturnsOnCall[len(fake.watchErrorArgsForCall)]
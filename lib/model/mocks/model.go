@@ -18,6 +18,17 @@ import (
 )
 
 type Model struct {
+	AcknowledgeChangeAnomalyStub        func(string) error
+	acknowledgeChangeAnomalyMutex       sync.RWMutex
+	acknowledgeChangeAnomalyArgsForCall []struct {
+		arg1 string
+	}
+	acknowledgeChangeAnomalyReturns struct {
+		result1 error
+	}
+	acknowledgeChangeAnomalyReturnsOnCall map[int]struct {
+		result1 error
+	}
 	AddConnectionStub        func(protocol.Connection, protocol.Hello)
 	addConnectionMutex       sync.RWMutex
 	addConnectionArgsForCall []struct {
@@ -37,6 +48,67 @@ type Model struct {
 		result1 iter.Seq[db.FileMetadata]
 		result2 func() error
 	}
+	ApproveConfigSyncStub        func(protocol.DeviceID, string, string) error
+	approveConfigSyncMutex       sync.RWMutex
+	approveConfigSyncArgsForCall []struct {
+		arg1 protocol.DeviceID
+		arg2 string
+		arg3 string
+	}
+	approveConfigSyncReturns struct {
+		result1 error
+	}
+	approveConfigSyncReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ApproveDeviceRevocationStub        func(protocol.DeviceID) (model.DeviceRevocation, error)
+	approveDeviceRevocationMutex       sync.RWMutex
+	approveDeviceRevocationArgsForCall []struct {
+		arg1 protocol.DeviceID
+	}
+	approveDeviceRevocationReturns struct {
+		result1 model.DeviceRevocation
+		result2 error
+	}
+	approveDeviceRevocationReturnsOnCall map[int]struct {
+		result1 model.DeviceRevocation
+		result2 error
+	}
+	ApproveFolderHandoverStub        func(string, protocol.DeviceID) error
+	approveFolderHandoverMutex       sync.RWMutex
+	approveFolderHandoverArgsForCall []struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}
+	approveFolderHandoverReturns struct {
+		result1 error
+	}
+	approveFolderHandoverReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ApproveIdentityChangeStub        func(protocol.DeviceID) error
+	approveIdentityChangeMutex       sync.RWMutex
+	approveIdentityChangeArgsForCall []struct {
+		arg1 protocol.DeviceID
+	}
+	approveIdentityChangeReturns struct {
+		result1 error
+	}
+	approveIdentityChangeReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ApproveOwnershipClaimStub        func(string, protocol.DeviceID) error
+	approveOwnershipClaimMutex       sync.RWMutex
+	approveOwnershipClaimArgsForCall []struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}
+	approveOwnershipClaimReturns struct {
+		result1 error
+	}
+	approveOwnershipClaimReturnsOnCall map[int]struct {
+		result1 error
+	}
 	AvailabilityStub        func(string, protocol.FileInfo, protocol.BlockInfo) ([]model.Availability, error)
 	availabilityMutex       sync.RWMutex
 	availabilityArgsForCall []struct {
@@ -58,6 +130,40 @@ type Model struct {
 		arg1 string
 		arg2 string
 	}
+	ChangeAnomaliesStub        func() map[string]model.ChangeAnomaly
+	changeAnomaliesMutex       sync.RWMutex
+	changeAnomaliesArgsForCall []struct {
+	}
+	changeAnomaliesReturns struct {
+		result1 map[string]model.ChangeAnomaly
+	}
+	changeAnomaliesReturnsOnCall map[int]struct {
+		result1 map[string]model.ChangeAnomaly
+	}
+	CleanTempFilesStub        func(string) (*model.TempCleanupReport, error)
+	cleanTempFilesMutex       sync.RWMutex
+	cleanTempFilesArgsForCall []struct {
+		arg1 string
+	}
+	cleanTempFilesReturns struct {
+		result1 *model.TempCleanupReport
+		result2 error
+	}
+	cleanTempFilesReturnsOnCall map[int]struct {
+		result1 *model.TempCleanupReport
+		result2 error
+	}
+	ClearFolderHandoverStub        func(string) error
+	clearFolderHandoverMutex       sync.RWMutex
+	clearFolderHandoverArgsForCall []struct {
+		arg1 string
+	}
+	clearFolderHandoverReturns struct {
+		result1 error
+	}
+	clearFolderHandoverReturnsOnCall map[int]struct {
+		result1 error
+	}
 	ClosedStub        func(protocol.Connection, error)
 	closedMutex       sync.RWMutex
 	closedArgsForCall []struct {
@@ -90,6 +196,35 @@ type Model struct {
 		result1 model.FolderCompletion
 		result2 error
 	}
+	ConflictVersionsStub        func(string, string) (protocol.FileInfo, protocol.FileInfo, error)
+	conflictVersionsMutex       sync.RWMutex
+	conflictVersionsArgsForCall []struct {
+		arg1 string
+		arg2 string
+	}
+	conflictVersionsReturns struct {
+		result1 protocol.FileInfo
+		result2 protocol.FileInfo
+		result3 error
+	}
+	conflictVersionsReturnsOnCall map[int]struct {
+		result1 protocol.FileInfo
+		result2 protocol.FileInfo
+		result3 error
+	}
+	ConflictsStub        func(string) ([]model.Conflict, error)
+	conflictsMutex       sync.RWMutex
+	conflictsArgsForCall []struct {
+		arg1 string
+	}
+	conflictsReturns struct {
+		result1 []model.Conflict
+		result2 error
+	}
+	conflictsReturnsOnCall map[int]struct {
+		result1 []model.Conflict
+		result2 error
+	}
 	ConnectedToStub        func(protocol.DeviceID) bool
 	connectedToMutex       sync.RWMutex
 	connectedToArgsForCall []struct {
@@ -164,6 +299,28 @@ type Model struct {
 		arg1 string
 		arg2 time.Duration
 	}
+	DeviceAddressLedgersStub        func() (map[protocol.DeviceID][]stats.AddressLedgerEntry, error)
+	deviceAddressLedgersMutex       sync.RWMutex
+	deviceAddressLedgersArgsForCall []struct{}
+	deviceAddressLedgersReturns     struct {
+		result1 map[protocol.DeviceID][]stats.AddressLedgerEntry
+		result2 error
+	}
+	deviceAddressLedgersReturnsOnCall map[int]struct {
+		result1 map[protocol.DeviceID][]stats.AddressLedgerEntry
+		result2 error
+	}
+	DeviceRevocationsStub        func() ([]model.DeviceRevocation, error)
+	deviceRevocationsMutex       sync.RWMutex
+	deviceRevocationsArgsForCall []struct{}
+	deviceRevocationsReturns     struct {
+		result1 []model.DeviceRevocation
+		result2 error
+	}
+	deviceRevocationsReturnsOnCall map[int]struct {
+		result1 []model.DeviceRevocation
+		result2 error
+	}
 	DeviceStatisticsStub        func() (map[protocol.DeviceID]stats.DeviceStatistics, error)
 	deviceStatisticsMutex       sync.RWMutex
 	deviceStatisticsArgsForCall []struct {
@@ -176,6 +333,17 @@ type Model struct {
 		result1 map[protocol.DeviceID]stats.DeviceStatistics
 		result2 error
 	}
+	DismissIdentityChangeStub        func(protocol.DeviceID) error
+	dismissIdentityChangeMutex       sync.RWMutex
+	dismissIdentityChangeArgsForCall []struct {
+		arg1 protocol.DeviceID
+	}
+	dismissIdentityChangeReturns struct {
+		result1 error
+	}
+	dismissIdentityChangeReturnsOnCall map[int]struct {
+		result1 error
+	}
 	DismissPendingDeviceStub        func(protocol.DeviceID) error
 	dismissPendingDeviceMutex       sync.RWMutex
 	dismissPendingDeviceArgsForCall []struct {
@@ -211,6 +379,60 @@ type Model struct {
 	downloadProgressReturnsOnCall map[int]struct {
 		result1 error
 	}
+	EncryptionRecoveryCodeStub        func(string, protocol.DeviceID) (string, error)
+	encryptionRecoveryCodeMutex       sync.RWMutex
+	encryptionRecoveryCodeArgsForCall []struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}
+	encryptionRecoveryCodeReturns struct {
+		result1 string
+		result2 error
+	}
+	encryptionRecoveryCodeReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	FolderAtTimeStub        func(string, string, time.Time, string) (*model.FolderAtTimeReport, error)
+	folderAtTimeMutex       sync.RWMutex
+	folderAtTimeArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 time.Time
+		arg4 string
+	}
+	folderAtTimeReturns struct {
+		result1 *model.FolderAtTimeReport
+		result2 error
+	}
+	folderAtTimeReturnsOnCall map[int]struct {
+		result1 *model.FolderAtTimeReport
+		result2 error
+	}
+	FolderCapabilitiesStub        func(string) (*model.FolderCapabilities, error)
+	folderCapabilitiesMutex       sync.RWMutex
+	folderCapabilitiesArgsForCall []struct {
+		arg1 string
+	}
+	folderCapabilitiesReturns struct {
+		result1 *model.FolderCapabilities
+		result2 error
+	}
+	folderCapabilitiesReturnsOnCall map[int]struct {
+		result1 *model.FolderCapabilities
+		result2 error
+	}
+	FolderDependencyWaitStub        func(string) string
+	folderDependencyWaitMutex       sync.RWMutex
+	folderDependencyWaitArgsForCall []struct {
+		arg1 string
+	}
+	folderDependencyWaitReturns struct {
+		result1 string
+	}
+	folderDependencyWaitReturnsOnCall map[int]struct {
+		result1 string
+	}
 	FolderErrorsStub        func(string) ([]model.FileError, error)
 	folderErrorsMutex       sync.RWMutex
 	folderErrorsArgsForCall []struct {
@@ -224,6 +446,17 @@ type Model struct {
 		result1 []model.FileError
 		result2 error
 	}
+	FolderHandoversStub        func() ([]model.FolderHandover, error)
+	folderHandoversMutex       sync.RWMutex
+	folderHandoversArgsForCall []struct{}
+	folderHandoversReturns     struct {
+		result1 []model.FolderHandover
+		result2 error
+	}
+	folderHandoversReturnsOnCall map[int]struct {
+		result1 []model.FolderHandover
+		result2 error
+	}
 	FolderProgressBytesCompletedStub        func(string) int64
 	folderProgressBytesCompletedMutex       sync.RWMutex
 	folderProgressBytesCompletedArgsForCall []struct {
@@ -235,6 +468,30 @@ type Model struct {
 	folderProgressBytesCompletedReturnsOnCall map[int]struct {
 		result1 int64
 	}
+	FolderQueuePositionStub        func(string) (string, int)
+	folderQueuePositionMutex       sync.RWMutex
+	folderQueuePositionArgsForCall []struct {
+		arg1 string
+	}
+	folderQueuePositionReturns struct {
+		result1 string
+		result2 int
+	}
+	folderQueuePositionReturnsOnCall map[int]struct {
+		result1 string
+		result2 int
+	}
+	FolderQuotaExceededStub        func(string) int64
+	folderQuotaExceededMutex       sync.RWMutex
+	folderQuotaExceededArgsForCall []struct {
+		arg1 string
+	}
+	folderQuotaExceededReturns struct {
+		result1 int64
+	}
+	folderQuotaExceededReturnsOnCall map[int]struct {
+		result1 int64
+	}
 	FolderStatisticsStub        func() (map[string]stats.FolderStatistics, error)
 	folderStatisticsMutex       sync.RWMutex
 	folderStatisticsArgsForCall []struct {
@@ -247,6 +504,19 @@ type Model struct {
 		result1 map[string]stats.FolderStatistics
 		result2 error
 	}
+	FolderWriteVerificationStub        func(string) (model.WriteVerificationStats, bool)
+	folderWriteVerificationMutex       sync.RWMutex
+	folderWriteVerificationArgsForCall []struct {
+		arg1 string
+	}
+	folderWriteVerificationReturns struct {
+		result1 model.WriteVerificationStats
+		result2 bool
+	}
+	folderWriteVerificationReturnsOnCall map[int]struct {
+		result1 model.WriteVerificationStats
+		result2 bool
+	}
 	GetFolderVersionsStub        func(string) (map[string][]versioner.FileVersion, error)
 	getFolderVersionsMutex       sync.RWMutex
 	getFolderVersionsArgsForCall []struct {
@@ -289,6 +559,16 @@ type Model struct {
 		result1 db.Counts
 		result2 error
 	}
+	IdentityChangesStub        func() map[protocol.DeviceID]model.IdentityChange
+	identityChangesMutex       sync.RWMutex
+	identityChangesArgsForCall []struct {
+	}
+	identityChangesReturns struct {
+		result1 map[protocol.DeviceID]model.IdentityChange
+	}
+	identityChangesReturnsOnCall map[int]struct {
+		result1 map[protocol.DeviceID]model.IdentityChange
+	}
 	IndexStub        func(protocol.Connection, *protocol.Index) error
 	indexMutex       sync.RWMutex
 	indexArgsForCall []struct {
@@ -328,6 +608,22 @@ type Model struct {
 		result2 []string
 		result3 error
 	}
+	LocalChangeReportStub        func(string, int, int, bool) ([]model.LocalChange, error)
+	localChangeReportMutex       sync.RWMutex
+	localChangeReportArgsForCall []struct {
+		arg1 string
+		arg2 int
+		arg3 int
+		arg4 bool
+	}
+	localChangeReportReturns struct {
+		result1 []model.LocalChange
+		result2 error
+	}
+	localChangeReportReturnsOnCall map[int]struct {
+		result1 []model.LocalChange
+		result2 error
+	}
 	LocalChangedFolderFilesStub        func(string, int, int) ([]protocol.FileInfo, error)
 	localChangedFolderFilesMutex       sync.RWMutex
 	localChangedFolderFilesArgsForCall []struct {
@@ -386,6 +682,21 @@ type Model struct {
 		result1 db.Counts
 		result2 error
 	}
+	MergeFoldersStub        func(string, string, bool) (*model.FolderMergeReport, error)
+	mergeFoldersMutex       sync.RWMutex
+	mergeFoldersArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 bool
+	}
+	mergeFoldersReturns struct {
+		result1 *model.FolderMergeReport
+		result2 error
+	}
+	mergeFoldersReturnsOnCall map[int]struct {
+		result1 *model.FolderMergeReport
+		result2 error
+	}
 	NeedFolderFilesStub        func(string, int, int) ([]protocol.FileInfo, []protocol.FileInfo, []protocol.FileInfo, error)
 	needFolderFilesMutex       sync.RWMutex
 	needFolderFilesArgsForCall []struct {
@@ -437,6 +748,29 @@ type Model struct {
 	overrideArgsForCall []struct {
 		arg1 string
 	}
+	PendingConfigSyncStub        func() ([]model.ConfigSyncChange, error)
+	pendingConfigSyncMutex       sync.RWMutex
+	pendingConfigSyncArgsForCall []struct {
+	}
+	pendingConfigSyncReturns struct {
+		result1 []model.ConfigSyncChange
+		result2 error
+	}
+	pendingConfigSyncReturnsOnCall map[int]struct {
+		result1 []model.ConfigSyncChange
+		result2 error
+	}
+	PendingDeviceRevocationsStub        func() ([]model.PendingDeviceRevocation, error)
+	pendingDeviceRevocationsMutex       sync.RWMutex
+	pendingDeviceRevocationsArgsForCall []struct{}
+	pendingDeviceRevocationsReturns     struct {
+		result1 []model.PendingDeviceRevocation
+		result2 error
+	}
+	pendingDeviceRevocationsReturnsOnCall map[int]struct {
+		result1 []model.PendingDeviceRevocation
+		result2 error
+	}
 	PendingDevicesStub        func() (map[protocol.DeviceID]db.ObservedDevice, error)
 	pendingDevicesMutex       sync.RWMutex
 	pendingDevicesArgsForCall []struct {
@@ -449,6 +783,17 @@ type Model struct {
 		result1 map[protocol.DeviceID]db.ObservedDevice
 		result2 error
 	}
+	PendingFolderHandoversStub        func() ([]model.PendingFolderHandover, error)
+	pendingFolderHandoversMutex       sync.RWMutex
+	pendingFolderHandoversArgsForCall []struct{}
+	pendingFolderHandoversReturns     struct {
+		result1 []model.PendingFolderHandover
+		result2 error
+	}
+	pendingFolderHandoversReturnsOnCall map[int]struct {
+		result1 []model.PendingFolderHandover
+		result2 error
+	}
 	PendingFoldersStub        func(protocol.DeviceID) (map[string]db.PendingFolder, error)
 	pendingFoldersMutex       sync.RWMutex
 	pendingFoldersArgsForCall []struct {
@@ -462,6 +807,98 @@ type Model struct {
 		result1 map[string]db.PendingFolder
 		result2 error
 	}
+	PendingOwnershipClaimsStub        func() ([]model.PendingOwnershipClaim, error)
+	pendingOwnershipClaimsMutex       sync.RWMutex
+	pendingOwnershipClaimsArgsForCall []struct{}
+	pendingOwnershipClaimsReturns     struct {
+		result1 []model.PendingOwnershipClaim
+		result2 error
+	}
+	pendingOwnershipClaimsReturnsOnCall map[int]struct {
+		result1 []model.PendingOwnershipClaim
+		result2 error
+	}
+	PrefixCompletionStub        func(protocol.DeviceID, string, string) (model.FolderCompletion, error)
+	prefixCompletionMutex       sync.RWMutex
+	prefixCompletionArgsForCall []struct {
+		arg1 protocol.DeviceID
+		arg2 string
+		arg3 string
+	}
+	prefixCompletionReturns struct {
+		result1 model.FolderCompletion
+		result2 error
+	}
+	prefixCompletionReturnsOnCall map[int]struct {
+		result1 model.FolderCompletion
+		result2 error
+	}
+	ProbeFolderCapabilitiesStub        func(string) (*model.FolderCapabilities, error)
+	probeFolderCapabilitiesMutex       sync.RWMutex
+	probeFolderCapabilitiesArgsForCall []struct {
+		arg1 string
+	}
+	probeFolderCapabilitiesReturns struct {
+		result1 *model.FolderCapabilities
+		result2 error
+	}
+	probeFolderCapabilitiesReturnsOnCall map[int]struct {
+		result1 *model.FolderCapabilities
+		result2 error
+	}
+	PromoteConfigSyncSpareStub        func(protocol.DeviceID) ([]string, error)
+	promoteConfigSyncSpareMutex       sync.RWMutex
+	promoteConfigSyncSpareArgsForCall []struct {
+		arg1 protocol.DeviceID
+	}
+	promoteConfigSyncSpareReturns struct {
+		result1 []string
+		result2 error
+	}
+	promoteConfigSyncSpareReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	PullPreviewStub        func(string) (*model.PullPreview, error)
+	pullPreviewMutex       sync.RWMutex
+	pullPreviewArgsForCall []struct {
+		arg1 string
+	}
+	pullPreviewReturns struct {
+		result1 *model.PullPreview
+		result2 error
+	}
+	pullPreviewReturnsOnCall map[int]struct {
+		result1 *model.PullPreview
+		result2 error
+	}
+	QueueDeviceMessageStub        func(protocol.DeviceID, string, string, []byte) error
+	queueDeviceMessageMutex       sync.RWMutex
+	queueDeviceMessageArgsForCall []struct {
+		arg1 protocol.DeviceID
+		arg2 string
+		arg3 string
+		arg4 []byte
+	}
+	queueDeviceMessageReturns struct {
+		result1 error
+	}
+	queueDeviceMessageReturnsOnCall map[int]struct {
+		result1 error
+	}
+	QueuedDeviceMessagesStub        func(protocol.DeviceID) ([]model.QueuedMessage, error)
+	queuedDeviceMessagesMutex       sync.RWMutex
+	queuedDeviceMessagesArgsForCall []struct {
+		arg1 protocol.DeviceID
+	}
+	queuedDeviceMessagesReturns struct {
+		result1 []model.QueuedMessage
+		result2 error
+	}
+	queuedDeviceMessagesReturnsOnCall map[int]struct {
+		result1 []model.QueuedMessage
+		result2 error
+	}
 	ReceiveOnlySizeStub        func(string) (db.Counts, error)
 	receiveOnlySizeMutex       sync.RWMutex
 	receiveOnlySizeArgsForCall []struct {
@@ -475,6 +912,54 @@ type Model struct {
 		result1 db.Counts
 		result2 error
 	}
+	RejectConfigSyncStub        func(protocol.DeviceID, string, string) error
+	rejectConfigSyncMutex       sync.RWMutex
+	rejectConfigSyncArgsForCall []struct {
+		arg1 protocol.DeviceID
+		arg2 string
+		arg3 string
+	}
+	rejectConfigSyncReturns struct {
+		result1 error
+	}
+	rejectConfigSyncReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RejectDeviceRevocationStub        func(protocol.DeviceID) error
+	rejectDeviceRevocationMutex       sync.RWMutex
+	rejectDeviceRevocationArgsForCall []struct {
+		arg1 protocol.DeviceID
+	}
+	rejectDeviceRevocationReturns struct {
+		result1 error
+	}
+	rejectDeviceRevocationReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RejectFolderHandoverStub        func(string, protocol.DeviceID) error
+	rejectFolderHandoverMutex       sync.RWMutex
+	rejectFolderHandoverArgsForCall []struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}
+	rejectFolderHandoverReturns struct {
+		result1 error
+	}
+	rejectFolderHandoverReturnsOnCall map[int]struct {
+		result1 error
+	}
+	RejectOwnershipClaimStub        func(string, protocol.DeviceID) error
+	rejectOwnershipClaimMutex       sync.RWMutex
+	rejectOwnershipClaimArgsForCall []struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}
+	rejectOwnershipClaimReturns struct {
+		result1 error
+	}
+	rejectOwnershipClaimReturnsOnCall map[int]struct {
+		result1 error
+	}
 	RemoteNeedFolderFilesStub        func(string, protocol.DeviceID, int, int) ([]protocol.FileInfo, error)
 	remoteNeedFolderFilesMutex       sync.RWMutex
 	remoteNeedFolderFilesArgsForCall []struct {
@@ -518,6 +1003,22 @@ type Model struct {
 		result1 protocol.RequestResponse
 		result2 error
 	}
+	RequestFolderPreviewStub        func(context.Context, protocol.DeviceID, string, int) (*protocol.FolderPreview, error)
+	requestFolderPreviewMutex       sync.RWMutex
+	requestFolderPreviewArgsForCall []struct {
+		arg1 context.Context
+		arg2 protocol.DeviceID
+		arg3 string
+		arg4 int
+	}
+	requestFolderPreviewReturns struct {
+		result1 *protocol.FolderPreview
+		result2 error
+	}
+	requestFolderPreviewReturnsOnCall map[int]struct {
+		result1 *protocol.FolderPreview
+		result2 error
+	}
 	RequestGlobalStub        func(context.Context, protocol.DeviceID, string, string, int, int64, int, []byte, bool) ([]byte, error)
 	requestGlobalMutex       sync.RWMutex
 	requestGlobalArgsForCall []struct {
@@ -539,9 +1040,20 @@ type Model struct {
 		result1 []byte
 		result2 error
 	}
-	ResetFolderStub        func(string) error
-	resetFolderMutex       sync.RWMutex
-	resetFolderArgsForCall []struct {
+	RescanIntervalStub        func(string) time.Duration
+	rescanIntervalMutex       sync.RWMutex
+	rescanIntervalArgsForCall []struct {
+		arg1 string
+	}
+	rescanIntervalReturns struct {
+		result1 time.Duration
+	}
+	rescanIntervalReturnsOnCall map[int]struct {
+		result1 time.Duration
+	}
+	ResetFolderStub        func(string) error
+	resetFolderMutex       sync.RWMutex
+	resetFolderArgsForCall []struct {
 		arg1 string
 	}
 	resetFolderReturns struct {
@@ -550,6 +1062,19 @@ type Model struct {
 	resetFolderReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ResolveConflictStub        func(string, string, string) error
+	resolveConflictMutex       sync.RWMutex
+	resolveConflictArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}
+	resolveConflictReturns struct {
+		result1 error
+	}
+	resolveConflictReturnsOnCall map[int]struct {
+		result1 error
+	}
 	RestoreFolderVersionsStub        func(string, map[string]time.Time) (map[string]error, error)
 	restoreFolderVersionsMutex       sync.RWMutex
 	restoreFolderVersionsArgsForCall []struct {
@@ -569,6 +1094,20 @@ type Model struct {
 	revertArgsForCall []struct {
 		arg1 string
 	}
+	RevokeDeviceStub        func(protocol.DeviceID, bool) (model.DeviceRevocation, error)
+	revokeDeviceMutex       sync.RWMutex
+	revokeDeviceArgsForCall []struct {
+		arg1 protocol.DeviceID
+		arg2 bool
+	}
+	revokeDeviceReturns struct {
+		result1 model.DeviceRevocation
+		result2 error
+	}
+	revokeDeviceReturnsOnCall map[int]struct {
+		result1 model.DeviceRevocation
+		result2 error
+	}
 	ScanFolderStub        func(string) error
 	scanFolderMutex       sync.RWMutex
 	scanFolderArgsForCall []struct {
@@ -644,6 +1183,21 @@ type Model struct {
 	setIgnoresReturnsOnCall map[int]struct {
 		result1 error
 	}
+	StartFolderHandoverStub        func(string, protocol.DeviceID, string) (model.FolderHandover, error)
+	startFolderHandoverMutex       sync.RWMutex
+	startFolderHandoverArgsForCall []struct {
+		arg1 string
+		arg2 protocol.DeviceID
+		arg3 string
+	}
+	startFolderHandoverReturns struct {
+		result1 model.FolderHandover
+		result2 error
+	}
+	startFolderHandoverReturnsOnCall map[int]struct {
+		result1 model.FolderHandover
+		result2 error
+	}
 	StateStub        func(string) (string, time.Time, error)
 	stateMutex       sync.RWMutex
 	stateArgsForCall []struct {
@@ -666,6 +1220,19 @@ type Model struct {
 		arg2 int
 		arg3 bool
 	}
+	WaitForFolderIdleStub        func(context.Context, string, time.Duration) error
+	waitForFolderIdleMutex       sync.RWMutex
+	waitForFolderIdleArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Duration
+	}
+	waitForFolderIdleReturns struct {
+		result1 error
+	}
+	waitForFolderIdleReturnsOnCall map[int]struct {
+		result1 error
+	}
 	WatchErrorStub        func(string) error
 	watchErrorMutex       sync.RWMutex
 	watchErrorArgsForCall []struct {
@@ -681,6 +1248,67 @@ type Model struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *Model) AcknowledgeChangeAnomaly(arg1 string) error {
+	fake.acknowledgeChangeAnomalyMutex.Lock()
+	ret, specificReturn := fake.acknowledgeChangeAnomalyReturnsOnCall[len(fake.acknowledgeChangeAnomalyArgsForCall)]
+	fake.acknowledgeChangeAnomalyArgsForCall = append(fake.acknowledgeChangeAnomalyArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.AcknowledgeChangeAnomalyStub
+	fakeReturns := fake.acknowledgeChangeAnomalyReturns
+	fake.recordInvocation("AcknowledgeChangeAnomaly", []interface{}{arg1})
+	fake.acknowledgeChangeAnomalyMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) AcknowledgeChangeAnomalyCallCount() int {
+	fake.acknowledgeChangeAnomalyMutex.RLock()
+	defer fake.acknowledgeChangeAnomalyMutex.RUnlock()
+	return len(fake.acknowledgeChangeAnomalyArgsForCall)
+}
+
+func (fake *Model) AcknowledgeChangeAnomalyCalls(stub func(string) error) {
+	fake.acknowledgeChangeAnomalyMutex.Lock()
+	defer fake.acknowledgeChangeAnomalyMutex.Unlock()
+	fake.AcknowledgeChangeAnomalyStub = stub
+}
+
+func (fake *Model) AcknowledgeChangeAnomalyArgsForCall(i int) string {
+	fake.acknowledgeChangeAnomalyMutex.RLock()
+	defer fake.acknowledgeChangeAnomalyMutex.RUnlock()
+	argsForCall := fake.acknowledgeChangeAnomalyArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) AcknowledgeChangeAnomalyReturns(result1 error) {
+	fake.acknowledgeChangeAnomalyMutex.Lock()
+	defer fake.acknowledgeChangeAnomalyMutex.Unlock()
+	fake.AcknowledgeChangeAnomalyStub = nil
+	fake.acknowledgeChangeAnomalyReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) AcknowledgeChangeAnomalyReturnsOnCall(i int, result1 error) {
+	fake.acknowledgeChangeAnomalyMutex.Lock()
+	defer fake.acknowledgeChangeAnomalyMutex.Unlock()
+	fake.AcknowledgeChangeAnomalyStub = nil
+	if fake.acknowledgeChangeAnomalyReturnsOnCall == nil {
+		fake.acknowledgeChangeAnomalyReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.acknowledgeChangeAnomalyReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *Model) AddConnection(arg1 protocol.Connection, arg2 protocol.Hello) {
 	fake.addConnectionMutex.Lock()
 	fake.addConnectionArgsForCall = append(fake.addConnectionArgsForCall, struct {
@@ -778,6 +1406,318 @@ func (fake *Model) AllGlobalFilesReturnsOnCall(i int, result1 iter.Seq[db.FileMe
 	}{result1, result2}
 }
 
+func (fake *Model) ApproveConfigSync(arg1 protocol.DeviceID, arg2 string, arg3 string) error {
+	fake.approveConfigSyncMutex.Lock()
+	ret, specificReturn := fake.approveConfigSyncReturnsOnCall[len(fake.approveConfigSyncArgsForCall)]
+	fake.approveConfigSyncArgsForCall = append(fake.approveConfigSyncArgsForCall, struct {
+		arg1 protocol.DeviceID
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ApproveConfigSyncStub
+	fakeReturns := fake.approveConfigSyncReturns
+	fake.recordInvocation("ApproveConfigSync", []interface{}{arg1, arg2, arg3})
+	fake.approveConfigSyncMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) ApproveConfigSyncCallCount() int {
+	fake.approveConfigSyncMutex.RLock()
+	defer fake.approveConfigSyncMutex.RUnlock()
+	return len(fake.approveConfigSyncArgsForCall)
+}
+
+func (fake *Model) ApproveConfigSyncCalls(stub func(protocol.DeviceID, string, string) error) {
+	fake.approveConfigSyncMutex.Lock()
+	defer fake.approveConfigSyncMutex.Unlock()
+	fake.ApproveConfigSyncStub = stub
+}
+
+func (fake *Model) ApproveConfigSyncArgsForCall(i int) (protocol.DeviceID, string, string) {
+	fake.approveConfigSyncMutex.RLock()
+	defer fake.approveConfigSyncMutex.RUnlock()
+	argsForCall := fake.approveConfigSyncArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Model) ApproveConfigSyncReturns(result1 error) {
+	fake.approveConfigSyncMutex.Lock()
+	defer fake.approveConfigSyncMutex.Unlock()
+	fake.ApproveConfigSyncStub = nil
+	fake.approveConfigSyncReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) ApproveConfigSyncReturnsOnCall(i int, result1 error) {
+	fake.approveConfigSyncMutex.Lock()
+	defer fake.approveConfigSyncMutex.Unlock()
+	fake.ApproveConfigSyncStub = nil
+	if fake.approveConfigSyncReturnsOnCall == nil {
+		fake.approveConfigSyncReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.approveConfigSyncReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) ApproveDeviceRevocation(arg1 protocol.DeviceID) (model.DeviceRevocation, error) {
+	fake.approveDeviceRevocationMutex.Lock()
+	ret, specificReturn := fake.approveDeviceRevocationReturnsOnCall[len(fake.approveDeviceRevocationArgsForCall)]
+	fake.approveDeviceRevocationArgsForCall = append(fake.approveDeviceRevocationArgsForCall, struct {
+		arg1 protocol.DeviceID
+	}{arg1})
+	stub := fake.ApproveDeviceRevocationStub
+	fakeReturns := fake.approveDeviceRevocationReturns
+	fake.recordInvocation("ApproveDeviceRevocation", []interface{}{arg1})
+	fake.approveDeviceRevocationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) ApproveDeviceRevocationCallCount() int {
+	fake.approveDeviceRevocationMutex.RLock()
+	defer fake.approveDeviceRevocationMutex.RUnlock()
+	return len(fake.approveDeviceRevocationArgsForCall)
+}
+
+func (fake *Model) ApproveDeviceRevocationCalls(stub func(protocol.DeviceID) (model.DeviceRevocation, error)) {
+	fake.approveDeviceRevocationMutex.Lock()
+	defer fake.approveDeviceRevocationMutex.Unlock()
+	fake.ApproveDeviceRevocationStub = stub
+}
+
+func (fake *Model) ApproveDeviceRevocationArgsForCall(i int) protocol.DeviceID {
+	fake.approveDeviceRevocationMutex.RLock()
+	defer fake.approveDeviceRevocationMutex.RUnlock()
+	argsForCall := fake.approveDeviceRevocationArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) ApproveDeviceRevocationReturns(result1 model.DeviceRevocation, result2 error) {
+	fake.approveDeviceRevocationMutex.Lock()
+	defer fake.approveDeviceRevocationMutex.Unlock()
+	fake.ApproveDeviceRevocationStub = nil
+	fake.approveDeviceRevocationReturns = struct {
+		result1 model.DeviceRevocation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) ApproveDeviceRevocationReturnsOnCall(i int, result1 model.DeviceRevocation, result2 error) {
+	fake.approveDeviceRevocationMutex.Lock()
+	defer fake.approveDeviceRevocationMutex.Unlock()
+	fake.ApproveDeviceRevocationStub = nil
+	if fake.approveDeviceRevocationReturnsOnCall == nil {
+		fake.approveDeviceRevocationReturnsOnCall = make(map[int]struct {
+			result1 model.DeviceRevocation
+			result2 error
+		})
+	}
+	fake.approveDeviceRevocationReturnsOnCall[i] = struct {
+		result1 model.DeviceRevocation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) ApproveFolderHandover(arg1 string, arg2 protocol.DeviceID) error {
+	fake.approveFolderHandoverMutex.Lock()
+	ret, specificReturn := fake.approveFolderHandoverReturnsOnCall[len(fake.approveFolderHandoverArgsForCall)]
+	fake.approveFolderHandoverArgsForCall = append(fake.approveFolderHandoverArgsForCall, struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}{arg1, arg2})
+	stub := fake.ApproveFolderHandoverStub
+	fakeReturns := fake.approveFolderHandoverReturns
+	fake.recordInvocation("ApproveFolderHandover", []interface{}{arg1, arg2})
+	fake.approveFolderHandoverMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) ApproveFolderHandoverCallCount() int {
+	fake.approveFolderHandoverMutex.RLock()
+	defer fake.approveFolderHandoverMutex.RUnlock()
+	return len(fake.approveFolderHandoverArgsForCall)
+}
+
+func (fake *Model) ApproveFolderHandoverCalls(stub func(string, protocol.DeviceID) error) {
+	fake.approveFolderHandoverMutex.Lock()
+	defer fake.approveFolderHandoverMutex.Unlock()
+	fake.ApproveFolderHandoverStub = stub
+}
+
+func (fake *Model) ApproveFolderHandoverArgsForCall(i int) (string, protocol.DeviceID) {
+	fake.approveFolderHandoverMutex.RLock()
+	defer fake.approveFolderHandoverMutex.RUnlock()
+	argsForCall := fake.approveFolderHandoverArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Model) ApproveFolderHandoverReturns(result1 error) {
+	fake.approveFolderHandoverMutex.Lock()
+	defer fake.approveFolderHandoverMutex.Unlock()
+	fake.ApproveFolderHandoverStub = nil
+	fake.approveFolderHandoverReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) ApproveFolderHandoverReturnsOnCall(i int, result1 error) {
+	fake.approveFolderHandoverMutex.Lock()
+	defer fake.approveFolderHandoverMutex.Unlock()
+	fake.ApproveFolderHandoverStub = nil
+	if fake.approveFolderHandoverReturnsOnCall == nil {
+		fake.approveFolderHandoverReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.approveFolderHandoverReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) ApproveIdentityChange(arg1 protocol.DeviceID) error {
+	fake.approveIdentityChangeMutex.Lock()
+	ret, specificReturn := fake.approveIdentityChangeReturnsOnCall[len(fake.approveIdentityChangeArgsForCall)]
+	fake.approveIdentityChangeArgsForCall = append(fake.approveIdentityChangeArgsForCall, struct {
+		arg1 protocol.DeviceID
+	}{arg1})
+	stub := fake.ApproveIdentityChangeStub
+	fakeReturns := fake.approveIdentityChangeReturns
+	fake.recordInvocation("ApproveIdentityChange", []interface{}{arg1})
+	fake.approveIdentityChangeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) ApproveIdentityChangeCallCount() int {
+	fake.approveIdentityChangeMutex.RLock()
+	defer fake.approveIdentityChangeMutex.RUnlock()
+	return len(fake.approveIdentityChangeArgsForCall)
+}
+
+func (fake *Model) ApproveIdentityChangeCalls(stub func(protocol.DeviceID) error) {
+	fake.approveIdentityChangeMutex.Lock()
+	defer fake.approveIdentityChangeMutex.Unlock()
+	fake.ApproveIdentityChangeStub = stub
+}
+
+func (fake *Model) ApproveIdentityChangeArgsForCall(i int) protocol.DeviceID {
+	fake.approveIdentityChangeMutex.RLock()
+	defer fake.approveIdentityChangeMutex.RUnlock()
+	argsForCall := fake.approveIdentityChangeArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) ApproveIdentityChangeReturns(result1 error) {
+	fake.approveIdentityChangeMutex.Lock()
+	defer fake.approveIdentityChangeMutex.Unlock()
+	fake.ApproveIdentityChangeStub = nil
+	fake.approveIdentityChangeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) ApproveIdentityChangeReturnsOnCall(i int, result1 error) {
+	fake.approveIdentityChangeMutex.Lock()
+	defer fake.approveIdentityChangeMutex.Unlock()
+	fake.ApproveIdentityChangeStub = nil
+	if fake.approveIdentityChangeReturnsOnCall == nil {
+		fake.approveIdentityChangeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.approveIdentityChangeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) ApproveOwnershipClaim(arg1 string, arg2 protocol.DeviceID) error {
+	fake.approveOwnershipClaimMutex.Lock()
+	ret, specificReturn := fake.approveOwnershipClaimReturnsOnCall[len(fake.approveOwnershipClaimArgsForCall)]
+	fake.approveOwnershipClaimArgsForCall = append(fake.approveOwnershipClaimArgsForCall, struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}{arg1, arg2})
+	stub := fake.ApproveOwnershipClaimStub
+	fakeReturns := fake.approveOwnershipClaimReturns
+	fake.recordInvocation("ApproveOwnershipClaim", []interface{}{arg1, arg2})
+	fake.approveOwnershipClaimMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) ApproveOwnershipClaimCallCount() int {
+	fake.approveOwnershipClaimMutex.RLock()
+	defer fake.approveOwnershipClaimMutex.RUnlock()
+	return len(fake.approveOwnershipClaimArgsForCall)
+}
+
+func (fake *Model) ApproveOwnershipClaimCalls(stub func(string, protocol.DeviceID) error) {
+	fake.approveOwnershipClaimMutex.Lock()
+	defer fake.approveOwnershipClaimMutex.Unlock()
+	fake.ApproveOwnershipClaimStub = stub
+}
+
+func (fake *Model) ApproveOwnershipClaimArgsForCall(i int) (string, protocol.DeviceID) {
+	fake.approveOwnershipClaimMutex.RLock()
+	defer fake.approveOwnershipClaimMutex.RUnlock()
+	argsForCall := fake.approveOwnershipClaimArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Model) ApproveOwnershipClaimReturns(result1 error) {
+	fake.approveOwnershipClaimMutex.Lock()
+	defer fake.approveOwnershipClaimMutex.Unlock()
+	fake.ApproveOwnershipClaimStub = nil
+	fake.approveOwnershipClaimReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) ApproveOwnershipClaimReturnsOnCall(i int, result1 error) {
+	fake.approveOwnershipClaimMutex.Lock()
+	defer fake.approveOwnershipClaimMutex.Unlock()
+	fake.ApproveOwnershipClaimStub = nil
+	if fake.approveOwnershipClaimReturnsOnCall == nil {
+		fake.approveOwnershipClaimReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.approveOwnershipClaimReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *Model) Availability(arg1 string, arg2 protocol.FileInfo, arg3 protocol.BlockInfo) ([]model.Availability, error) {
 	fake.availabilityMutex.Lock()
 	ret, specificReturn := fake.availabilityReturnsOnCall[len(fake.availabilityArgsForCall)]
@@ -877,51 +1817,229 @@ func (fake *Model) BringToFrontArgsForCall(i int) (string, string) {
 	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *Model) Closed(arg1 protocol.Connection, arg2 error) {
-	fake.closedMutex.Lock()
-	fake.closedArgsForCall = append(fake.closedArgsForCall, struct {
-		arg1 protocol.Connection
-		arg2 error
-	}{arg1, arg2})
-	stub := fake.ClosedStub
-	fake.recordInvocation("Closed", []interface{}{arg1, arg2})
-	fake.closedMutex.Unlock()
+func (fake *Model) ChangeAnomalies() map[string]model.ChangeAnomaly {
+	fake.changeAnomaliesMutex.Lock()
+	ret, specificReturn := fake.changeAnomaliesReturnsOnCall[len(fake.changeAnomaliesArgsForCall)]
+	fake.changeAnomaliesArgsForCall = append(fake.changeAnomaliesArgsForCall, struct {
+	}{})
+	stub := fake.ChangeAnomaliesStub
+	fakeReturns := fake.changeAnomaliesReturns
+	fake.recordInvocation("ChangeAnomalies", []interface{}{})
+	fake.changeAnomaliesMutex.Unlock()
 	if stub != nil {
-		fake.ClosedStub(arg1, arg2)
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
 	}
+	return fakeReturns.result1
 }
 
-func (fake *Model) ClosedCallCount() int {
-	fake.closedMutex.RLock()
-	defer fake.closedMutex.RUnlock()
-	return len(fake.closedArgsForCall)
+func (fake *Model) ChangeAnomaliesCallCount() int {
+	fake.changeAnomaliesMutex.RLock()
+	defer fake.changeAnomaliesMutex.RUnlock()
+	return len(fake.changeAnomaliesArgsForCall)
 }
 
-func (fake *Model) ClosedCalls(stub func(protocol.Connection, error)) {
-	fake.closedMutex.Lock()
-	defer fake.closedMutex.Unlock()
-	fake.ClosedStub = stub
+func (fake *Model) ChangeAnomaliesCalls(stub func() map[string]model.ChangeAnomaly) {
+	fake.changeAnomaliesMutex.Lock()
+	defer fake.changeAnomaliesMutex.Unlock()
+	fake.ChangeAnomaliesStub = stub
 }
 
-func (fake *Model) ClosedArgsForCall(i int) (protocol.Connection, error) {
-	fake.closedMutex.RLock()
-	defer fake.closedMutex.RUnlock()
-	argsForCall := fake.closedArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+func (fake *Model) ChangeAnomaliesReturns(result1 map[string]model.ChangeAnomaly) {
+	fake.changeAnomaliesMutex.Lock()
+	defer fake.changeAnomaliesMutex.Unlock()
+	fake.ChangeAnomaliesStub = nil
+	fake.changeAnomaliesReturns = struct {
+		result1 map[string]model.ChangeAnomaly
+	}{result1}
 }
 
-func (fake *Model) ClusterConfig(arg1 protocol.Connection, arg2 *protocol.ClusterConfig) error {
-	fake.clusterConfigMutex.Lock()
-	ret, specificReturn := fake.clusterConfigReturnsOnCall[len(fake.clusterConfigArgsForCall)]
-	fake.clusterConfigArgsForCall = append(fake.clusterConfigArgsForCall, struct {
-		arg1 protocol.Connection
-		arg2 *protocol.ClusterConfig
-	}{arg1, arg2})
-	stub := fake.ClusterConfigStub
-	fakeReturns := fake.clusterConfigReturns
-	fake.recordInvocation("ClusterConfig", []interface{}{arg1, arg2})
-	fake.clusterConfigMutex.Unlock()
-	if stub != nil {
+func (fake *Model) ChangeAnomaliesReturnsOnCall(i int, result1 map[string]model.ChangeAnomaly) {
+	fake.changeAnomaliesMutex.Lock()
+	defer fake.changeAnomaliesMutex.Unlock()
+	fake.ChangeAnomaliesStub = nil
+	if fake.changeAnomaliesReturnsOnCall == nil {
+		fake.changeAnomaliesReturnsOnCall = make(map[int]struct {
+			result1 map[string]model.ChangeAnomaly
+		})
+	}
+	fake.changeAnomaliesReturnsOnCall[i] = struct {
+		result1 map[string]model.ChangeAnomaly
+	}{result1}
+}
+
+func (fake *Model) CleanTempFiles(arg1 string) (*model.TempCleanupReport, error) {
+	fake.cleanTempFilesMutex.Lock()
+	ret, specificReturn := fake.cleanTempFilesReturnsOnCall[len(fake.cleanTempFilesArgsForCall)]
+	fake.cleanTempFilesArgsForCall = append(fake.cleanTempFilesArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.CleanTempFilesStub
+	fakeReturns := fake.cleanTempFilesReturns
+	fake.recordInvocation("CleanTempFiles", []interface{}{arg1})
+	fake.cleanTempFilesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) CleanTempFilesCallCount() int {
+	fake.cleanTempFilesMutex.RLock()
+	defer fake.cleanTempFilesMutex.RUnlock()
+	return len(fake.cleanTempFilesArgsForCall)
+}
+
+func (fake *Model) CleanTempFilesCalls(stub func(string) (*model.TempCleanupReport, error)) {
+	fake.cleanTempFilesMutex.Lock()
+	defer fake.cleanTempFilesMutex.Unlock()
+	fake.CleanTempFilesStub = stub
+}
+
+func (fake *Model) CleanTempFilesArgsForCall(i int) string {
+	fake.cleanTempFilesMutex.RLock()
+	defer fake.cleanTempFilesMutex.RUnlock()
+	argsForCall := fake.cleanTempFilesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) CleanTempFilesReturns(result1 *model.TempCleanupReport, result2 error) {
+	fake.cleanTempFilesMutex.Lock()
+	defer fake.cleanTempFilesMutex.Unlock()
+	fake.CleanTempFilesStub = nil
+	fake.cleanTempFilesReturns = struct {
+		result1 *model.TempCleanupReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) CleanTempFilesReturnsOnCall(i int, result1 *model.TempCleanupReport, result2 error) {
+	fake.cleanTempFilesMutex.Lock()
+	defer fake.cleanTempFilesMutex.Unlock()
+	fake.CleanTempFilesStub = nil
+	if fake.cleanTempFilesReturnsOnCall == nil {
+		fake.cleanTempFilesReturnsOnCall = make(map[int]struct {
+			result1 *model.TempCleanupReport
+			result2 error
+		})
+	}
+	fake.cleanTempFilesReturnsOnCall[i] = struct {
+		result1 *model.TempCleanupReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) ClearFolderHandover(arg1 string) error {
+	fake.clearFolderHandoverMutex.Lock()
+	ret, specificReturn := fake.clearFolderHandoverReturnsOnCall[len(fake.clearFolderHandoverArgsForCall)]
+	fake.clearFolderHandoverArgsForCall = append(fake.clearFolderHandoverArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ClearFolderHandoverStub
+	fakeReturns := fake.clearFolderHandoverReturns
+	fake.recordInvocation("ClearFolderHandover", []interface{}{arg1})
+	fake.clearFolderHandoverMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) ClearFolderHandoverCallCount() int {
+	fake.clearFolderHandoverMutex.RLock()
+	defer fake.clearFolderHandoverMutex.RUnlock()
+	return len(fake.clearFolderHandoverArgsForCall)
+}
+
+func (fake *Model) ClearFolderHandoverCalls(stub func(string) error) {
+	fake.clearFolderHandoverMutex.Lock()
+	defer fake.clearFolderHandoverMutex.Unlock()
+	fake.ClearFolderHandoverStub = stub
+}
+
+func (fake *Model) ClearFolderHandoverArgsForCall(i int) string {
+	fake.clearFolderHandoverMutex.RLock()
+	defer fake.clearFolderHandoverMutex.RUnlock()
+	argsForCall := fake.clearFolderHandoverArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) ClearFolderHandoverReturns(result1 error) {
+	fake.clearFolderHandoverMutex.Lock()
+	defer fake.clearFolderHandoverMutex.Unlock()
+	fake.ClearFolderHandoverStub = nil
+	fake.clearFolderHandoverReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) ClearFolderHandoverReturnsOnCall(i int, result1 error) {
+	fake.clearFolderHandoverMutex.Lock()
+	defer fake.clearFolderHandoverMutex.Unlock()
+	fake.ClearFolderHandoverStub = nil
+	if fake.clearFolderHandoverReturnsOnCall == nil {
+		fake.clearFolderHandoverReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.clearFolderHandoverReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) Closed(arg1 protocol.Connection, arg2 error) {
+	fake.closedMutex.Lock()
+	fake.closedArgsForCall = append(fake.closedArgsForCall, struct {
+		arg1 protocol.Connection
+		arg2 error
+	}{arg1, arg2})
+	stub := fake.ClosedStub
+	fake.recordInvocation("Closed", []interface{}{arg1, arg2})
+	fake.closedMutex.Unlock()
+	if stub != nil {
+		fake.ClosedStub(arg1, arg2)
+	}
+}
+
+func (fake *Model) ClosedCallCount() int {
+	fake.closedMutex.RLock()
+	defer fake.closedMutex.RUnlock()
+	return len(fake.closedArgsForCall)
+}
+
+func (fake *Model) ClosedCalls(stub func(protocol.Connection, error)) {
+	fake.closedMutex.Lock()
+	defer fake.closedMutex.Unlock()
+	fake.ClosedStub = stub
+}
+
+func (fake *Model) ClosedArgsForCall(i int) (protocol.Connection, error) {
+	fake.closedMutex.RLock()
+	defer fake.closedMutex.RUnlock()
+	argsForCall := fake.closedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Model) ClusterConfig(arg1 protocol.Connection, arg2 *protocol.ClusterConfig) error {
+	fake.clusterConfigMutex.Lock()
+	ret, specificReturn := fake.clusterConfigReturnsOnCall[len(fake.clusterConfigArgsForCall)]
+	fake.clusterConfigArgsForCall = append(fake.clusterConfigArgsForCall, struct {
+		arg1 protocol.Connection
+		arg2 *protocol.ClusterConfig
+	}{arg1, arg2})
+	stub := fake.ClusterConfigStub
+	fakeReturns := fake.clusterConfigReturns
+	fake.recordInvocation("ClusterConfig", []interface{}{arg1, arg2})
+	fake.clusterConfigMutex.Unlock()
+	if stub != nil {
 		return stub(arg1, arg2)
 	}
 	if specificReturn {
@@ -1037,6 +2155,138 @@ func (fake *Model) CompletionReturnsOnCall(i int, result1 model.FolderCompletion
 	}{result1, result2}
 }
 
+func (fake *Model) ConflictVersions(arg1 string, arg2 string) (protocol.FileInfo, protocol.FileInfo, error) {
+	fake.conflictVersionsMutex.Lock()
+	ret, specificReturn := fake.conflictVersionsReturnsOnCall[len(fake.conflictVersionsArgsForCall)]
+	fake.conflictVersionsArgsForCall = append(fake.conflictVersionsArgsForCall, struct {
+		arg1 string
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.ConflictVersionsStub
+	fakeReturns := fake.conflictVersionsReturns
+	fake.recordInvocation("ConflictVersions", []interface{}{arg1, arg2})
+	fake.conflictVersionsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *Model) ConflictVersionsCallCount() int {
+	fake.conflictVersionsMutex.RLock()
+	defer fake.conflictVersionsMutex.RUnlock()
+	return len(fake.conflictVersionsArgsForCall)
+}
+
+func (fake *Model) ConflictVersionsCalls(stub func(string, string) (protocol.FileInfo, protocol.FileInfo, error)) {
+	fake.conflictVersionsMutex.Lock()
+	defer fake.conflictVersionsMutex.Unlock()
+	fake.ConflictVersionsStub = stub
+}
+
+func (fake *Model) ConflictVersionsArgsForCall(i int) (string, string) {
+	fake.conflictVersionsMutex.RLock()
+	defer fake.conflictVersionsMutex.RUnlock()
+	argsForCall := fake.conflictVersionsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Model) ConflictVersionsReturns(result1 protocol.FileInfo, result2 protocol.FileInfo, result3 error) {
+	fake.conflictVersionsMutex.Lock()
+	defer fake.conflictVersionsMutex.Unlock()
+	fake.ConflictVersionsStub = nil
+	fake.conflictVersionsReturns = struct {
+		result1 protocol.FileInfo
+		result2 protocol.FileInfo
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *Model) ConflictVersionsReturnsOnCall(i int, result1 protocol.FileInfo, result2 protocol.FileInfo, result3 error) {
+	fake.conflictVersionsMutex.Lock()
+	defer fake.conflictVersionsMutex.Unlock()
+	fake.ConflictVersionsStub = nil
+	if fake.conflictVersionsReturnsOnCall == nil {
+		fake.conflictVersionsReturnsOnCall = make(map[int]struct {
+			result1 protocol.FileInfo
+			result2 protocol.FileInfo
+			result3 error
+		})
+	}
+	fake.conflictVersionsReturnsOnCall[i] = struct {
+		result1 protocol.FileInfo
+		result2 protocol.FileInfo
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *Model) Conflicts(arg1 string) ([]model.Conflict, error) {
+	fake.conflictsMutex.Lock()
+	ret, specificReturn := fake.conflictsReturnsOnCall[len(fake.conflictsArgsForCall)]
+	fake.conflictsArgsForCall = append(fake.conflictsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ConflictsStub
+	fakeReturns := fake.conflictsReturns
+	fake.recordInvocation("Conflicts", []interface{}{arg1})
+	fake.conflictsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) ConflictsCallCount() int {
+	fake.conflictsMutex.RLock()
+	defer fake.conflictsMutex.RUnlock()
+	return len(fake.conflictsArgsForCall)
+}
+
+func (fake *Model) ConflictsCalls(stub func(string) ([]model.Conflict, error)) {
+	fake.conflictsMutex.Lock()
+	defer fake.conflictsMutex.Unlock()
+	fake.ConflictsStub = stub
+}
+
+func (fake *Model) ConflictsArgsForCall(i int) string {
+	fake.conflictsMutex.RLock()
+	defer fake.conflictsMutex.RUnlock()
+	argsForCall := fake.conflictsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) ConflictsReturns(result1 []model.Conflict, result2 error) {
+	fake.conflictsMutex.Lock()
+	defer fake.conflictsMutex.Unlock()
+	fake.ConflictsStub = nil
+	fake.conflictsReturns = struct {
+		result1 []model.Conflict
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) ConflictsReturnsOnCall(i int, result1 []model.Conflict, result2 error) {
+	fake.conflictsMutex.Lock()
+	defer fake.conflictsMutex.Unlock()
+	fake.ConflictsStub = nil
+	if fake.conflictsReturnsOnCall == nil {
+		fake.conflictsReturnsOnCall = make(map[int]struct {
+			result1 []model.Conflict
+			result2 error
+		})
+	}
+	fake.conflictsReturnsOnCall[i] = struct {
+		result1 []model.Conflict
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) ConnectedTo(arg1 protocol.DeviceID) bool {
 	fake.connectedToMutex.Lock()
 	ret, specificReturn := fake.connectedToReturnsOnCall[len(fake.connectedToArgsForCall)]
@@ -1387,6 +2637,116 @@ func (fake *Model) DelayScanArgsForCall(i int) (string, time.Duration) {
 	return argsForCall.arg1, argsForCall.arg2
 }
 
+func (fake *Model) DeviceAddressLedgers() (map[protocol.DeviceID][]stats.AddressLedgerEntry, error) {
+	fake.deviceAddressLedgersMutex.Lock()
+	ret, specificReturn := fake.deviceAddressLedgersReturnsOnCall[len(fake.deviceAddressLedgersArgsForCall)]
+	fake.deviceAddressLedgersArgsForCall = append(fake.deviceAddressLedgersArgsForCall, struct{}{})
+	stub := fake.DeviceAddressLedgersStub
+	fakeReturns := fake.deviceAddressLedgersReturns
+	fake.recordInvocation("DeviceAddressLedgers", []interface{}{})
+	fake.deviceAddressLedgersMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) DeviceAddressLedgersCallCount() int {
+	fake.deviceAddressLedgersMutex.RLock()
+	defer fake.deviceAddressLedgersMutex.RUnlock()
+	return len(fake.deviceAddressLedgersArgsForCall)
+}
+
+func (fake *Model) DeviceAddressLedgersCalls(stub func() (map[protocol.DeviceID][]stats.AddressLedgerEntry, error)) {
+	fake.deviceAddressLedgersMutex.Lock()
+	defer fake.deviceAddressLedgersMutex.Unlock()
+	fake.DeviceAddressLedgersStub = stub
+}
+
+func (fake *Model) DeviceAddressLedgersReturns(result1 map[protocol.DeviceID][]stats.AddressLedgerEntry, result2 error) {
+	fake.deviceAddressLedgersMutex.Lock()
+	defer fake.deviceAddressLedgersMutex.Unlock()
+	fake.DeviceAddressLedgersStub = nil
+	fake.deviceAddressLedgersReturns = struct {
+		result1 map[protocol.DeviceID][]stats.AddressLedgerEntry
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) DeviceAddressLedgersReturnsOnCall(i int, result1 map[protocol.DeviceID][]stats.AddressLedgerEntry, result2 error) {
+	fake.deviceAddressLedgersMutex.Lock()
+	defer fake.deviceAddressLedgersMutex.Unlock()
+	fake.DeviceAddressLedgersStub = nil
+	if fake.deviceAddressLedgersReturnsOnCall == nil {
+		fake.deviceAddressLedgersReturnsOnCall = make(map[int]struct {
+			result1 map[protocol.DeviceID][]stats.AddressLedgerEntry
+			result2 error
+		})
+	}
+	fake.deviceAddressLedgersReturnsOnCall[i] = struct {
+		result1 map[protocol.DeviceID][]stats.AddressLedgerEntry
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) DeviceRevocations() ([]model.DeviceRevocation, error) {
+	fake.deviceRevocationsMutex.Lock()
+	ret, specificReturn := fake.deviceRevocationsReturnsOnCall[len(fake.deviceRevocationsArgsForCall)]
+	fake.deviceRevocationsArgsForCall = append(fake.deviceRevocationsArgsForCall, struct{}{})
+	stub := fake.DeviceRevocationsStub
+	fakeReturns := fake.deviceRevocationsReturns
+	fake.recordInvocation("DeviceRevocations", []interface{}{})
+	fake.deviceRevocationsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) DeviceRevocationsCallCount() int {
+	fake.deviceRevocationsMutex.RLock()
+	defer fake.deviceRevocationsMutex.RUnlock()
+	return len(fake.deviceRevocationsArgsForCall)
+}
+
+func (fake *Model) DeviceRevocationsCalls(stub func() ([]model.DeviceRevocation, error)) {
+	fake.deviceRevocationsMutex.Lock()
+	defer fake.deviceRevocationsMutex.Unlock()
+	fake.DeviceRevocationsStub = stub
+}
+
+func (fake *Model) DeviceRevocationsReturns(result1 []model.DeviceRevocation, result2 error) {
+	fake.deviceRevocationsMutex.Lock()
+	defer fake.deviceRevocationsMutex.Unlock()
+	fake.DeviceRevocationsStub = nil
+	fake.deviceRevocationsReturns = struct {
+		result1 []model.DeviceRevocation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) DeviceRevocationsReturnsOnCall(i int, result1 []model.DeviceRevocation, result2 error) {
+	fake.deviceRevocationsMutex.Lock()
+	defer fake.deviceRevocationsMutex.Unlock()
+	fake.DeviceRevocationsStub = nil
+	if fake.deviceRevocationsReturnsOnCall == nil {
+		fake.deviceRevocationsReturnsOnCall = make(map[int]struct {
+			result1 []model.DeviceRevocation
+			result2 error
+		})
+	}
+	fake.deviceRevocationsReturnsOnCall[i] = struct {
+		result1 []model.DeviceRevocation
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) DeviceStatistics() (map[protocol.DeviceID]stats.DeviceStatistics, error) {
 	fake.deviceStatisticsMutex.Lock()
 	ret, specificReturn := fake.deviceStatisticsReturnsOnCall[len(fake.deviceStatisticsArgsForCall)]
@@ -1443,6 +2803,67 @@ func (fake *Model) DeviceStatisticsReturnsOnCall(i int, result1 map[protocol.Dev
 	}{result1, result2}
 }
 
+func (fake *Model) DismissIdentityChange(arg1 protocol.DeviceID) error {
+	fake.dismissIdentityChangeMutex.Lock()
+	ret, specificReturn := fake.dismissIdentityChangeReturnsOnCall[len(fake.dismissIdentityChangeArgsForCall)]
+	fake.dismissIdentityChangeArgsForCall = append(fake.dismissIdentityChangeArgsForCall, struct {
+		arg1 protocol.DeviceID
+	}{arg1})
+	stub := fake.DismissIdentityChangeStub
+	fakeReturns := fake.dismissIdentityChangeReturns
+	fake.recordInvocation("DismissIdentityChange", []interface{}{arg1})
+	fake.dismissIdentityChangeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) DismissIdentityChangeCallCount() int {
+	fake.dismissIdentityChangeMutex.RLock()
+	defer fake.dismissIdentityChangeMutex.RUnlock()
+	return len(fake.dismissIdentityChangeArgsForCall)
+}
+
+func (fake *Model) DismissIdentityChangeCalls(stub func(protocol.DeviceID) error) {
+	fake.dismissIdentityChangeMutex.Lock()
+	defer fake.dismissIdentityChangeMutex.Unlock()
+	fake.DismissIdentityChangeStub = stub
+}
+
+func (fake *Model) DismissIdentityChangeArgsForCall(i int) protocol.DeviceID {
+	fake.dismissIdentityChangeMutex.RLock()
+	defer fake.dismissIdentityChangeMutex.RUnlock()
+	argsForCall := fake.dismissIdentityChangeArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) DismissIdentityChangeReturns(result1 error) {
+	fake.dismissIdentityChangeMutex.Lock()
+	defer fake.dismissIdentityChangeMutex.Unlock()
+	fake.DismissIdentityChangeStub = nil
+	fake.dismissIdentityChangeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) DismissIdentityChangeReturnsOnCall(i int, result1 error) {
+	fake.dismissIdentityChangeMutex.Lock()
+	defer fake.dismissIdentityChangeMutex.Unlock()
+	fake.DismissIdentityChangeStub = nil
+	if fake.dismissIdentityChangeReturnsOnCall == nil {
+		fake.dismissIdentityChangeReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.dismissIdentityChangeReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *Model) DismissPendingDevice(arg1 protocol.DeviceID) error {
 	fake.dismissPendingDeviceMutex.Lock()
 	ret, specificReturn := fake.dismissPendingDeviceReturnsOnCall[len(fake.dismissPendingDeviceArgsForCall)]
@@ -1628,16 +3049,273 @@ func (fake *Model) DownloadProgressReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
-func (fake *Model) FolderErrors(arg1 string) ([]model.FileError, error) {
-	fake.folderErrorsMutex.Lock()
-	ret, specificReturn := fake.folderErrorsReturnsOnCall[len(fake.folderErrorsArgsForCall)]
-	fake.folderErrorsArgsForCall = append(fake.folderErrorsArgsForCall, struct {
+func (fake *Model) EncryptionRecoveryCode(arg1 string, arg2 protocol.DeviceID) (string, error) {
+	fake.encryptionRecoveryCodeMutex.Lock()
+	ret, specificReturn := fake.encryptionRecoveryCodeReturnsOnCall[len(fake.encryptionRecoveryCodeArgsForCall)]
+	fake.encryptionRecoveryCodeArgsForCall = append(fake.encryptionRecoveryCodeArgsForCall, struct {
 		arg1 string
-	}{arg1})
-	stub := fake.FolderErrorsStub
-	fakeReturns := fake.folderErrorsReturns
-	fake.recordInvocation("FolderErrors", []interface{}{arg1})
-	fake.folderErrorsMutex.Unlock()
+		arg2 protocol.DeviceID
+	}{arg1, arg2})
+	stub := fake.EncryptionRecoveryCodeStub
+	fakeReturns := fake.encryptionRecoveryCodeReturns
+	fake.recordInvocation("EncryptionRecoveryCode", []interface{}{arg1, arg2})
+	fake.encryptionRecoveryCodeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) EncryptionRecoveryCodeCallCount() int {
+	fake.encryptionRecoveryCodeMutex.RLock()
+	defer fake.encryptionRecoveryCodeMutex.RUnlock()
+	return len(fake.encryptionRecoveryCodeArgsForCall)
+}
+
+func (fake *Model) EncryptionRecoveryCodeCalls(stub func(string, protocol.DeviceID) (string, error)) {
+	fake.encryptionRecoveryCodeMutex.Lock()
+	defer fake.encryptionRecoveryCodeMutex.Unlock()
+	fake.EncryptionRecoveryCodeStub = stub
+}
+
+func (fake *Model) EncryptionRecoveryCodeArgsForCall(i int) (string, protocol.DeviceID) {
+	fake.encryptionRecoveryCodeMutex.RLock()
+	defer fake.encryptionRecoveryCodeMutex.RUnlock()
+	argsForCall := fake.encryptionRecoveryCodeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Model) EncryptionRecoveryCodeReturns(result1 string, result2 error) {
+	fake.encryptionRecoveryCodeMutex.Lock()
+	defer fake.encryptionRecoveryCodeMutex.Unlock()
+	fake.EncryptionRecoveryCodeStub = nil
+	fake.encryptionRecoveryCodeReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) EncryptionRecoveryCodeReturnsOnCall(i int, result1 string, result2 error) {
+	fake.encryptionRecoveryCodeMutex.Lock()
+	defer fake.encryptionRecoveryCodeMutex.Unlock()
+	fake.EncryptionRecoveryCodeStub = nil
+	if fake.encryptionRecoveryCodeReturnsOnCall == nil {
+		fake.encryptionRecoveryCodeReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.encryptionRecoveryCodeReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) FolderAtTime(arg1 string, arg2 string, arg3 time.Time, arg4 string) (*model.FolderAtTimeReport, error) {
+	fake.folderAtTimeMutex.Lock()
+	ret, specificReturn := fake.folderAtTimeReturnsOnCall[len(fake.folderAtTimeArgsForCall)]
+	fake.folderAtTimeArgsForCall = append(fake.folderAtTimeArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 time.Time
+		arg4 string
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.FolderAtTimeStub
+	fakeReturns := fake.folderAtTimeReturns
+	fake.recordInvocation("FolderAtTime", []interface{}{arg1, arg2, arg3, arg4})
+	fake.folderAtTimeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) FolderAtTimeCallCount() int {
+	fake.folderAtTimeMutex.RLock()
+	defer fake.folderAtTimeMutex.RUnlock()
+	return len(fake.folderAtTimeArgsForCall)
+}
+
+func (fake *Model) FolderAtTimeCalls(stub func(string, string, time.Time, string) (*model.FolderAtTimeReport, error)) {
+	fake.folderAtTimeMutex.Lock()
+	defer fake.folderAtTimeMutex.Unlock()
+	fake.FolderAtTimeStub = stub
+}
+
+func (fake *Model) FolderAtTimeArgsForCall(i int) (string, string, time.Time, string) {
+	fake.folderAtTimeMutex.RLock()
+	defer fake.folderAtTimeMutex.RUnlock()
+	argsForCall := fake.folderAtTimeArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *Model) FolderAtTimeReturns(result1 *model.FolderAtTimeReport, result2 error) {
+	fake.folderAtTimeMutex.Lock()
+	defer fake.folderAtTimeMutex.Unlock()
+	fake.FolderAtTimeStub = nil
+	fake.folderAtTimeReturns = struct {
+		result1 *model.FolderAtTimeReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) FolderAtTimeReturnsOnCall(i int, result1 *model.FolderAtTimeReport, result2 error) {
+	fake.folderAtTimeMutex.Lock()
+	defer fake.folderAtTimeMutex.Unlock()
+	fake.FolderAtTimeStub = nil
+	if fake.folderAtTimeReturnsOnCall == nil {
+		fake.folderAtTimeReturnsOnCall = make(map[int]struct {
+			result1 *model.FolderAtTimeReport
+			result2 error
+		})
+	}
+	fake.folderAtTimeReturnsOnCall[i] = struct {
+		result1 *model.FolderAtTimeReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) FolderCapabilities(arg1 string) (*model.FolderCapabilities, error) {
+	fake.folderCapabilitiesMutex.Lock()
+	ret, specificReturn := fake.folderCapabilitiesReturnsOnCall[len(fake.folderCapabilitiesArgsForCall)]
+	fake.folderCapabilitiesArgsForCall = append(fake.folderCapabilitiesArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FolderCapabilitiesStub
+	fakeReturns := fake.folderCapabilitiesReturns
+	fake.recordInvocation("FolderCapabilities", []interface{}{arg1})
+	fake.folderCapabilitiesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) FolderCapabilitiesCallCount() int {
+	fake.folderCapabilitiesMutex.RLock()
+	defer fake.folderCapabilitiesMutex.RUnlock()
+	return len(fake.folderCapabilitiesArgsForCall)
+}
+
+func (fake *Model) FolderCapabilitiesCalls(stub func(string) (*model.FolderCapabilities, error)) {
+	fake.folderCapabilitiesMutex.Lock()
+	defer fake.folderCapabilitiesMutex.Unlock()
+	fake.FolderCapabilitiesStub = stub
+}
+
+func (fake *Model) FolderCapabilitiesArgsForCall(i int) string {
+	fake.folderCapabilitiesMutex.RLock()
+	defer fake.folderCapabilitiesMutex.RUnlock()
+	argsForCall := fake.folderCapabilitiesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) FolderCapabilitiesReturns(result1 *model.FolderCapabilities, result2 error) {
+	fake.folderCapabilitiesMutex.Lock()
+	defer fake.folderCapabilitiesMutex.Unlock()
+	fake.FolderCapabilitiesStub = nil
+	fake.folderCapabilitiesReturns = struct {
+		result1 *model.FolderCapabilities
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) FolderCapabilitiesReturnsOnCall(i int, result1 *model.FolderCapabilities, result2 error) {
+	fake.folderCapabilitiesMutex.Lock()
+	defer fake.folderCapabilitiesMutex.Unlock()
+	fake.FolderCapabilitiesStub = nil
+	if fake.folderCapabilitiesReturnsOnCall == nil {
+		fake.folderCapabilitiesReturnsOnCall = make(map[int]struct {
+			result1 *model.FolderCapabilities
+			result2 error
+		})
+	}
+	fake.folderCapabilitiesReturnsOnCall[i] = struct {
+		result1 *model.FolderCapabilities
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) FolderDependencyWait(arg1 string) string {
+	fake.folderDependencyWaitMutex.Lock()
+	ret, specificReturn := fake.folderDependencyWaitReturnsOnCall[len(fake.folderDependencyWaitArgsForCall)]
+	fake.folderDependencyWaitArgsForCall = append(fake.folderDependencyWaitArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FolderDependencyWaitStub
+	fakeReturns := fake.folderDependencyWaitReturns
+	fake.recordInvocation("FolderDependencyWait", []interface{}{arg1})
+	fake.folderDependencyWaitMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) FolderDependencyWaitCallCount() int {
+	fake.folderDependencyWaitMutex.RLock()
+	defer fake.folderDependencyWaitMutex.RUnlock()
+	return len(fake.folderDependencyWaitArgsForCall)
+}
+
+func (fake *Model) FolderDependencyWaitCalls(stub func(string) string) {
+	fake.folderDependencyWaitMutex.Lock()
+	defer fake.folderDependencyWaitMutex.Unlock()
+	fake.FolderDependencyWaitStub = stub
+}
+
+func (fake *Model) FolderDependencyWaitArgsForCall(i int) string {
+	fake.folderDependencyWaitMutex.RLock()
+	defer fake.folderDependencyWaitMutex.RUnlock()
+	argsForCall := fake.folderDependencyWaitArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) FolderDependencyWaitReturns(result1 string) {
+	fake.folderDependencyWaitMutex.Lock()
+	defer fake.folderDependencyWaitMutex.Unlock()
+	fake.FolderDependencyWaitStub = nil
+	fake.folderDependencyWaitReturns = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *Model) FolderDependencyWaitReturnsOnCall(i int, result1 string) {
+	fake.folderDependencyWaitMutex.Lock()
+	defer fake.folderDependencyWaitMutex.Unlock()
+	fake.FolderDependencyWaitStub = nil
+	if fake.folderDependencyWaitReturnsOnCall == nil {
+		fake.folderDependencyWaitReturnsOnCall = make(map[int]struct {
+			result1 string
+		})
+	}
+	fake.folderDependencyWaitReturnsOnCall[i] = struct {
+		result1 string
+	}{result1}
+}
+
+func (fake *Model) FolderErrors(arg1 string) ([]model.FileError, error) {
+	fake.folderErrorsMutex.Lock()
+	ret, specificReturn := fake.folderErrorsReturnsOnCall[len(fake.folderErrorsArgsForCall)]
+	fake.folderErrorsArgsForCall = append(fake.folderErrorsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FolderErrorsStub
+	fakeReturns := fake.folderErrorsReturns
+	fake.recordInvocation("FolderErrors", []interface{}{arg1})
+	fake.folderErrorsMutex.Unlock()
 	if stub != nil {
 		return stub(arg1)
 	}
@@ -1692,6 +3370,61 @@ func (fake *Model) FolderErrorsReturnsOnCall(i int, result1 []model.FileError, r
 	}{result1, result2}
 }
 
+func (fake *Model) FolderHandovers() ([]model.FolderHandover, error) {
+	fake.folderHandoversMutex.Lock()
+	ret, specificReturn := fake.folderHandoversReturnsOnCall[len(fake.folderHandoversArgsForCall)]
+	fake.folderHandoversArgsForCall = append(fake.folderHandoversArgsForCall, struct{}{})
+	stub := fake.FolderHandoversStub
+	fakeReturns := fake.folderHandoversReturns
+	fake.recordInvocation("FolderHandovers", []interface{}{})
+	fake.folderHandoversMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) FolderHandoversCallCount() int {
+	fake.folderHandoversMutex.RLock()
+	defer fake.folderHandoversMutex.RUnlock()
+	return len(fake.folderHandoversArgsForCall)
+}
+
+func (fake *Model) FolderHandoversCalls(stub func() ([]model.FolderHandover, error)) {
+	fake.folderHandoversMutex.Lock()
+	defer fake.folderHandoversMutex.Unlock()
+	fake.FolderHandoversStub = stub
+}
+
+func (fake *Model) FolderHandoversReturns(result1 []model.FolderHandover, result2 error) {
+	fake.folderHandoversMutex.Lock()
+	defer fake.folderHandoversMutex.Unlock()
+	fake.FolderHandoversStub = nil
+	fake.folderHandoversReturns = struct {
+		result1 []model.FolderHandover
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) FolderHandoversReturnsOnCall(i int, result1 []model.FolderHandover, result2 error) {
+	fake.folderHandoversMutex.Lock()
+	defer fake.folderHandoversMutex.Unlock()
+	fake.FolderHandoversStub = nil
+	if fake.folderHandoversReturnsOnCall == nil {
+		fake.folderHandoversReturnsOnCall = make(map[int]struct {
+			result1 []model.FolderHandover
+			result2 error
+		})
+	}
+	fake.folderHandoversReturnsOnCall[i] = struct {
+		result1 []model.FolderHandover
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) FolderProgressBytesCompleted(arg1 string) int64 {
 	fake.folderProgressBytesCompletedMutex.Lock()
 	ret, specificReturn := fake.folderProgressBytesCompletedReturnsOnCall[len(fake.folderProgressBytesCompletedArgsForCall)]
@@ -1753,6 +3486,131 @@ func (fake *Model) FolderProgressBytesCompletedReturnsOnCall(i int, result1 int6
 	}{result1}
 }
 
+func (fake *Model) FolderQueuePosition(arg1 string) (string, int) {
+	fake.folderQueuePositionMutex.Lock()
+	ret, specificReturn := fake.folderQueuePositionReturnsOnCall[len(fake.folderQueuePositionArgsForCall)]
+	fake.folderQueuePositionArgsForCall = append(fake.folderQueuePositionArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FolderQueuePositionStub
+	fakeReturns := fake.folderQueuePositionReturns
+	fake.recordInvocation("FolderQueuePosition", []interface{}{arg1})
+	fake.folderQueuePositionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) FolderQueuePositionCallCount() int {
+	fake.folderQueuePositionMutex.RLock()
+	defer fake.folderQueuePositionMutex.RUnlock()
+	return len(fake.folderQueuePositionArgsForCall)
+}
+
+func (fake *Model) FolderQueuePositionCalls(stub func(string) (string, int)) {
+	fake.folderQueuePositionMutex.Lock()
+	defer fake.folderQueuePositionMutex.Unlock()
+	fake.FolderQueuePositionStub = stub
+}
+
+func (fake *Model) FolderQueuePositionArgsForCall(i int) string {
+	fake.folderQueuePositionMutex.RLock()
+	defer fake.folderQueuePositionMutex.RUnlock()
+	argsForCall := fake.folderQueuePositionArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) FolderQueuePositionReturns(result1 string, result2 int) {
+	fake.folderQueuePositionMutex.Lock()
+	defer fake.folderQueuePositionMutex.Unlock()
+	fake.FolderQueuePositionStub = nil
+	fake.folderQueuePositionReturns = struct {
+		result1 string
+		result2 int
+	}{result1, result2}
+}
+
+func (fake *Model) FolderQueuePositionReturnsOnCall(i int, result1 string, result2 int) {
+	fake.folderQueuePositionMutex.Lock()
+	defer fake.folderQueuePositionMutex.Unlock()
+	fake.FolderQueuePositionStub = nil
+	if fake.folderQueuePositionReturnsOnCall == nil {
+		fake.folderQueuePositionReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 int
+		})
+	}
+	fake.folderQueuePositionReturnsOnCall[i] = struct {
+		result1 string
+		result2 int
+	}{result1, result2}
+}
+
+func (fake *Model) FolderQuotaExceeded(arg1 string) int64 {
+	fake.folderQuotaExceededMutex.Lock()
+	ret, specificReturn := fake.folderQuotaExceededReturnsOnCall[len(fake.folderQuotaExceededArgsForCall)]
+	fake.folderQuotaExceededArgsForCall = append(fake.folderQuotaExceededArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FolderQuotaExceededStub
+	fakeReturns := fake.folderQuotaExceededReturns
+	fake.recordInvocation("FolderQuotaExceeded", []interface{}{arg1})
+	fake.folderQuotaExceededMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) FolderQuotaExceededCallCount() int {
+	fake.folderQuotaExceededMutex.RLock()
+	defer fake.folderQuotaExceededMutex.RUnlock()
+	return len(fake.folderQuotaExceededArgsForCall)
+}
+
+func (fake *Model) FolderQuotaExceededCalls(stub func(string) int64) {
+	fake.folderQuotaExceededMutex.Lock()
+	defer fake.folderQuotaExceededMutex.Unlock()
+	fake.FolderQuotaExceededStub = stub
+}
+
+func (fake *Model) FolderQuotaExceededArgsForCall(i int) string {
+	fake.folderQuotaExceededMutex.RLock()
+	defer fake.folderQuotaExceededMutex.RUnlock()
+	argsForCall := fake.folderQuotaExceededArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) FolderQuotaExceededReturns(result1 int64) {
+	fake.folderQuotaExceededMutex.Lock()
+	defer fake.folderQuotaExceededMutex.Unlock()
+	fake.FolderQuotaExceededStub = nil
+	fake.folderQuotaExceededReturns = struct {
+		result1 int64
+	}{result1}
+}
+
+func (fake *Model) FolderQuotaExceededReturnsOnCall(i int, result1 int64) {
+	fake.folderQuotaExceededMutex.Lock()
+	defer fake.folderQuotaExceededMutex.Unlock()
+	fake.FolderQuotaExceededStub = nil
+	if fake.folderQuotaExceededReturnsOnCall == nil {
+		fake.folderQuotaExceededReturnsOnCall = make(map[int]struct {
+			result1 int64
+		})
+	}
+	fake.folderQuotaExceededReturnsOnCall[i] = struct {
+		result1 int64
+	}{result1}
+}
+
 func (fake *Model) FolderStatistics() (map[string]stats.FolderStatistics, error) {
 	fake.folderStatisticsMutex.Lock()
 	ret, specificReturn := fake.folderStatisticsReturnsOnCall[len(fake.folderStatisticsArgsForCall)]
@@ -1809,6 +3667,70 @@ func (fake *Model) FolderStatisticsReturnsOnCall(i int, result1 map[string]stats
 	}{result1, result2}
 }
 
+func (fake *Model) FolderWriteVerification(arg1 string) (model.WriteVerificationStats, bool) {
+	fake.folderWriteVerificationMutex.Lock()
+	ret, specificReturn := fake.folderWriteVerificationReturnsOnCall[len(fake.folderWriteVerificationArgsForCall)]
+	fake.folderWriteVerificationArgsForCall = append(fake.folderWriteVerificationArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.FolderWriteVerificationStub
+	fakeReturns := fake.folderWriteVerificationReturns
+	fake.recordInvocation("FolderWriteVerification", []interface{}{arg1})
+	fake.folderWriteVerificationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) FolderWriteVerificationCallCount() int {
+	fake.folderWriteVerificationMutex.RLock()
+	defer fake.folderWriteVerificationMutex.RUnlock()
+	return len(fake.folderWriteVerificationArgsForCall)
+}
+
+func (fake *Model) FolderWriteVerificationCalls(stub func(string) (model.WriteVerificationStats, bool)) {
+	fake.folderWriteVerificationMutex.Lock()
+	defer fake.folderWriteVerificationMutex.Unlock()
+	fake.FolderWriteVerificationStub = stub
+}
+
+func (fake *Model) FolderWriteVerificationArgsForCall(i int) string {
+	fake.folderWriteVerificationMutex.RLock()
+	defer fake.folderWriteVerificationMutex.RUnlock()
+	argsForCall := fake.folderWriteVerificationArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) FolderWriteVerificationReturns(result1 model.WriteVerificationStats, result2 bool) {
+	fake.folderWriteVerificationMutex.Lock()
+	defer fake.folderWriteVerificationMutex.Unlock()
+	fake.FolderWriteVerificationStub = nil
+	fake.folderWriteVerificationReturns = struct {
+		result1 model.WriteVerificationStats
+		result2 bool
+	}{result1, result2}
+}
+
+func (fake *Model) FolderWriteVerificationReturnsOnCall(i int, result1 model.WriteVerificationStats, result2 bool) {
+	fake.folderWriteVerificationMutex.Lock()
+	defer fake.folderWriteVerificationMutex.Unlock()
+	fake.FolderWriteVerificationStub = nil
+	if fake.folderWriteVerificationReturnsOnCall == nil {
+		fake.folderWriteVerificationReturnsOnCall = make(map[int]struct {
+			result1 model.WriteVerificationStats
+			result2 bool
+		})
+	}
+	fake.folderWriteVerificationReturnsOnCall[i] = struct {
+		result1 model.WriteVerificationStats
+		result2 bool
+	}{result1, result2}
+}
+
 func (fake *Model) GetFolderVersions(arg1 string) (map[string][]versioner.FileVersion, error) {
 	fake.getFolderVersionsMutex.Lock()
 	ret, specificReturn := fake.getFolderVersionsReturnsOnCall[len(fake.getFolderVersionsArgsForCall)]
@@ -2004,19 +3926,72 @@ func (fake *Model) GlobalSizeReturnsOnCall(i int, result1 db.Counts, result2 err
 	}{result1, result2}
 }
 
-func (fake *Model) Index(arg1 protocol.Connection, arg2 *protocol.Index) error {
-	fake.indexMutex.Lock()
-	ret, specificReturn := fake.indexReturnsOnCall[len(fake.indexArgsForCall)]
-	fake.indexArgsForCall = append(fake.indexArgsForCall, struct {
-		arg1 protocol.Connection
-		arg2 *protocol.Index
-	}{arg1, arg2})
-	stub := fake.IndexStub
-	fakeReturns := fake.indexReturns
-	fake.recordInvocation("Index", []interface{}{arg1, arg2})
-	fake.indexMutex.Unlock()
+func (fake *Model) IdentityChanges() map[protocol.DeviceID]model.IdentityChange {
+	fake.identityChangesMutex.Lock()
+	ret, specificReturn := fake.identityChangesReturnsOnCall[len(fake.identityChangesArgsForCall)]
+	fake.identityChangesArgsForCall = append(fake.identityChangesArgsForCall, struct {
+	}{})
+	stub := fake.IdentityChangesStub
+	fakeReturns := fake.identityChangesReturns
+	fake.recordInvocation("IdentityChanges", []interface{}{})
+	fake.identityChangesMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) IdentityChangesCallCount() int {
+	fake.identityChangesMutex.RLock()
+	defer fake.identityChangesMutex.RUnlock()
+	return len(fake.identityChangesArgsForCall)
+}
+
+func (fake *Model) IdentityChangesCalls(stub func() map[protocol.DeviceID]model.IdentityChange) {
+	fake.identityChangesMutex.Lock()
+	defer fake.identityChangesMutex.Unlock()
+	fake.IdentityChangesStub = stub
+}
+
+func (fake *Model) IdentityChangesReturns(result1 map[protocol.DeviceID]model.IdentityChange) {
+	fake.identityChangesMutex.Lock()
+	defer fake.identityChangesMutex.Unlock()
+	fake.IdentityChangesStub = nil
+	fake.identityChangesReturns = struct {
+		result1 map[protocol.DeviceID]model.IdentityChange
+	}{result1}
+}
+
+func (fake *Model) IdentityChangesReturnsOnCall(i int, result1 map[protocol.DeviceID]model.IdentityChange) {
+	fake.identityChangesMutex.Lock()
+	defer fake.identityChangesMutex.Unlock()
+	fake.IdentityChangesStub = nil
+	if fake.identityChangesReturnsOnCall == nil {
+		fake.identityChangesReturnsOnCall = make(map[int]struct {
+			result1 map[protocol.DeviceID]model.IdentityChange
+		})
+	}
+	fake.identityChangesReturnsOnCall[i] = struct {
+		result1 map[protocol.DeviceID]model.IdentityChange
+	}{result1}
+}
+
+func (fake *Model) Index(arg1 protocol.Connection, arg2 *protocol.Index) error {
+	fake.indexMutex.Lock()
+	ret, specificReturn := fake.indexReturnsOnCall[len(fake.indexArgsForCall)]
+	fake.indexArgsForCall = append(fake.indexArgsForCall, struct {
+		arg1 protocol.Connection
+		arg2 *protocol.Index
+	}{arg1, arg2})
+	stub := fake.IndexStub
+	fakeReturns := fake.indexReturns
+	fake.recordInvocation("Index", []interface{}{arg1, arg2})
+	fake.indexMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
 		return ret.result1
@@ -2195,6 +4170,73 @@ func (fake *Model) LoadIgnoresReturnsOnCall(i int, result1 []string, result2 []s
 	}{result1, result2, result3}
 }
 
+func (fake *Model) LocalChangeReport(arg1 string, arg2 int, arg3 int, arg4 bool) ([]model.LocalChange, error) {
+	fake.localChangeReportMutex.Lock()
+	ret, specificReturn := fake.localChangeReportReturnsOnCall[len(fake.localChangeReportArgsForCall)]
+	fake.localChangeReportArgsForCall = append(fake.localChangeReportArgsForCall, struct {
+		arg1 string
+		arg2 int
+		arg3 int
+		arg4 bool
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.LocalChangeReportStub
+	fakeReturns := fake.localChangeReportReturns
+	fake.recordInvocation("LocalChangeReport", []interface{}{arg1, arg2, arg3, arg4})
+	fake.localChangeReportMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) LocalChangeReportCallCount() int {
+	fake.localChangeReportMutex.RLock()
+	defer fake.localChangeReportMutex.RUnlock()
+	return len(fake.localChangeReportArgsForCall)
+}
+
+func (fake *Model) LocalChangeReportCalls(stub func(string, int, int, bool) ([]model.LocalChange, error)) {
+	fake.localChangeReportMutex.Lock()
+	defer fake.localChangeReportMutex.Unlock()
+	fake.LocalChangeReportStub = stub
+}
+
+func (fake *Model) LocalChangeReportArgsForCall(i int) (string, int, int, bool) {
+	fake.localChangeReportMutex.RLock()
+	defer fake.localChangeReportMutex.RUnlock()
+	argsForCall := fake.localChangeReportArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *Model) LocalChangeReportReturns(result1 []model.LocalChange, result2 error) {
+	fake.localChangeReportMutex.Lock()
+	defer fake.localChangeReportMutex.Unlock()
+	fake.LocalChangeReportStub = nil
+	fake.localChangeReportReturns = struct {
+		result1 []model.LocalChange
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) LocalChangeReportReturnsOnCall(i int, result1 []model.LocalChange, result2 error) {
+	fake.localChangeReportMutex.Lock()
+	defer fake.localChangeReportMutex.Unlock()
+	fake.LocalChangeReportStub = nil
+	if fake.localChangeReportReturnsOnCall == nil {
+		fake.localChangeReportReturnsOnCall = make(map[int]struct {
+			result1 []model.LocalChange
+			result2 error
+		})
+	}
+	fake.localChangeReportReturnsOnCall[i] = struct {
+		result1 []model.LocalChange
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) LocalChangedFolderFiles(arg1 string, arg2 int, arg3 int) ([]protocol.FileInfo, error) {
 	fake.localChangedFolderFilesMutex.Lock()
 	ret, specificReturn := fake.localChangedFolderFilesReturnsOnCall[len(fake.localChangedFolderFilesArgsForCall)]
@@ -2457,6 +4499,72 @@ func (fake *Model) LocalSizeReturnsOnCall(i int, result1 db.Counts, result2 erro
 	}{result1, result2}
 }
 
+func (fake *Model) MergeFolders(arg1 string, arg2 string, arg3 bool) (*model.FolderMergeReport, error) {
+	fake.mergeFoldersMutex.Lock()
+	ret, specificReturn := fake.mergeFoldersReturnsOnCall[len(fake.mergeFoldersArgsForCall)]
+	fake.mergeFoldersArgsForCall = append(fake.mergeFoldersArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 bool
+	}{arg1, arg2, arg3})
+	stub := fake.MergeFoldersStub
+	fakeReturns := fake.mergeFoldersReturns
+	fake.recordInvocation("MergeFolders", []interface{}{arg1, arg2, arg3})
+	fake.mergeFoldersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) MergeFoldersCallCount() int {
+	fake.mergeFoldersMutex.RLock()
+	defer fake.mergeFoldersMutex.RUnlock()
+	return len(fake.mergeFoldersArgsForCall)
+}
+
+func (fake *Model) MergeFoldersCalls(stub func(string, string, bool) (*model.FolderMergeReport, error)) {
+	fake.mergeFoldersMutex.Lock()
+	defer fake.mergeFoldersMutex.Unlock()
+	fake.MergeFoldersStub = stub
+}
+
+func (fake *Model) MergeFoldersArgsForCall(i int) (string, string, bool) {
+	fake.mergeFoldersMutex.RLock()
+	defer fake.mergeFoldersMutex.RUnlock()
+	argsForCall := fake.mergeFoldersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Model) MergeFoldersReturns(result1 *model.FolderMergeReport, result2 error) {
+	fake.mergeFoldersMutex.Lock()
+	defer fake.mergeFoldersMutex.Unlock()
+	fake.MergeFoldersStub = nil
+	fake.mergeFoldersReturns = struct {
+		result1 *model.FolderMergeReport
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) MergeFoldersReturnsOnCall(i int, result1 *model.FolderMergeReport, result2 error) {
+	fake.mergeFoldersMutex.Lock()
+	defer fake.mergeFoldersMutex.Unlock()
+	fake.MergeFoldersStub = nil
+	if fake.mergeFoldersReturnsOnCall == nil {
+		fake.mergeFoldersReturnsOnCall = make(map[int]struct {
+			result1 *model.FolderMergeReport
+			result2 error
+		})
+	}
+	fake.mergeFoldersReturnsOnCall[i] = struct {
+		result1 *model.FolderMergeReport
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) NeedFolderFiles(arg1 string, arg2 int, arg3 int) ([]protocol.FileInfo, []protocol.FileInfo, []protocol.FileInfo, error) {
 	fake.needFolderFilesMutex.Lock()
 	ret, specificReturn := fake.needFolderFilesReturnsOnCall[len(fake.needFolderFilesArgsForCall)]
@@ -2689,6 +4797,117 @@ func (fake *Model) OverrideArgsForCall(i int) string {
 	return argsForCall.arg1
 }
 
+func (fake *Model) PendingConfigSync() ([]model.ConfigSyncChange, error) {
+	fake.pendingConfigSyncMutex.Lock()
+	ret, specificReturn := fake.pendingConfigSyncReturnsOnCall[len(fake.pendingConfigSyncArgsForCall)]
+	fake.pendingConfigSyncArgsForCall = append(fake.pendingConfigSyncArgsForCall, struct {
+	}{})
+	stub := fake.PendingConfigSyncStub
+	fakeReturns := fake.pendingConfigSyncReturns
+	fake.recordInvocation("PendingConfigSync", []interface{}{})
+	fake.pendingConfigSyncMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) PendingConfigSyncCallCount() int {
+	fake.pendingConfigSyncMutex.RLock()
+	defer fake.pendingConfigSyncMutex.RUnlock()
+	return len(fake.pendingConfigSyncArgsForCall)
+}
+
+func (fake *Model) PendingConfigSyncCalls(stub func() ([]model.ConfigSyncChange, error)) {
+	fake.pendingConfigSyncMutex.Lock()
+	defer fake.pendingConfigSyncMutex.Unlock()
+	fake.PendingConfigSyncStub = stub
+}
+
+func (fake *Model) PendingConfigSyncReturns(result1 []model.ConfigSyncChange, result2 error) {
+	fake.pendingConfigSyncMutex.Lock()
+	defer fake.pendingConfigSyncMutex.Unlock()
+	fake.PendingConfigSyncStub = nil
+	fake.pendingConfigSyncReturns = struct {
+		result1 []model.ConfigSyncChange
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) PendingConfigSyncReturnsOnCall(i int, result1 []model.ConfigSyncChange, result2 error) {
+	fake.pendingConfigSyncMutex.Lock()
+	defer fake.pendingConfigSyncMutex.Unlock()
+	fake.PendingConfigSyncStub = nil
+	if fake.pendingConfigSyncReturnsOnCall == nil {
+		fake.pendingConfigSyncReturnsOnCall = make(map[int]struct {
+			result1 []model.ConfigSyncChange
+			result2 error
+		})
+	}
+	fake.pendingConfigSyncReturnsOnCall[i] = struct {
+		result1 []model.ConfigSyncChange
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) PendingDeviceRevocations() ([]model.PendingDeviceRevocation, error) {
+	fake.pendingDeviceRevocationsMutex.Lock()
+	ret, specificReturn := fake.pendingDeviceRevocationsReturnsOnCall[len(fake.pendingDeviceRevocationsArgsForCall)]
+	fake.pendingDeviceRevocationsArgsForCall = append(fake.pendingDeviceRevocationsArgsForCall, struct{}{})
+	stub := fake.PendingDeviceRevocationsStub
+	fakeReturns := fake.pendingDeviceRevocationsReturns
+	fake.recordInvocation("PendingDeviceRevocations", []interface{}{})
+	fake.pendingDeviceRevocationsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) PendingDeviceRevocationsCallCount() int {
+	fake.pendingDeviceRevocationsMutex.RLock()
+	defer fake.pendingDeviceRevocationsMutex.RUnlock()
+	return len(fake.pendingDeviceRevocationsArgsForCall)
+}
+
+func (fake *Model) PendingDeviceRevocationsCalls(stub func() ([]model.PendingDeviceRevocation, error)) {
+	fake.pendingDeviceRevocationsMutex.Lock()
+	defer fake.pendingDeviceRevocationsMutex.Unlock()
+	fake.PendingDeviceRevocationsStub = stub
+}
+
+func (fake *Model) PendingDeviceRevocationsReturns(result1 []model.PendingDeviceRevocation, result2 error) {
+	fake.pendingDeviceRevocationsMutex.Lock()
+	defer fake.pendingDeviceRevocationsMutex.Unlock()
+	fake.PendingDeviceRevocationsStub = nil
+	fake.pendingDeviceRevocationsReturns = struct {
+		result1 []model.PendingDeviceRevocation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) PendingDeviceRevocationsReturnsOnCall(i int, result1 []model.PendingDeviceRevocation, result2 error) {
+	fake.pendingDeviceRevocationsMutex.Lock()
+	defer fake.pendingDeviceRevocationsMutex.Unlock()
+	fake.PendingDeviceRevocationsStub = nil
+	if fake.pendingDeviceRevocationsReturnsOnCall == nil {
+		fake.pendingDeviceRevocationsReturnsOnCall = make(map[int]struct {
+			result1 []model.PendingDeviceRevocation
+			result2 error
+		})
+	}
+	fake.pendingDeviceRevocationsReturnsOnCall[i] = struct {
+		result1 []model.PendingDeviceRevocation
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) PendingDevices() (map[protocol.DeviceID]db.ObservedDevice, error) {
 	fake.pendingDevicesMutex.Lock()
 	ret, specificReturn := fake.pendingDevicesReturnsOnCall[len(fake.pendingDevicesArgsForCall)]
@@ -2745,6 +4964,61 @@ func (fake *Model) PendingDevicesReturnsOnCall(i int, result1 map[protocol.Devic
 	}{result1, result2}
 }
 
+func (fake *Model) PendingFolderHandovers() ([]model.PendingFolderHandover, error) {
+	fake.pendingFolderHandoversMutex.Lock()
+	ret, specificReturn := fake.pendingFolderHandoversReturnsOnCall[len(fake.pendingFolderHandoversArgsForCall)]
+	fake.pendingFolderHandoversArgsForCall = append(fake.pendingFolderHandoversArgsForCall, struct{}{})
+	stub := fake.PendingFolderHandoversStub
+	fakeReturns := fake.pendingFolderHandoversReturns
+	fake.recordInvocation("PendingFolderHandovers", []interface{}{})
+	fake.pendingFolderHandoversMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) PendingFolderHandoversCallCount() int {
+	fake.pendingFolderHandoversMutex.RLock()
+	defer fake.pendingFolderHandoversMutex.RUnlock()
+	return len(fake.pendingFolderHandoversArgsForCall)
+}
+
+func (fake *Model) PendingFolderHandoversCalls(stub func() ([]model.PendingFolderHandover, error)) {
+	fake.pendingFolderHandoversMutex.Lock()
+	defer fake.pendingFolderHandoversMutex.Unlock()
+	fake.PendingFolderHandoversStub = stub
+}
+
+func (fake *Model) PendingFolderHandoversReturns(result1 []model.PendingFolderHandover, result2 error) {
+	fake.pendingFolderHandoversMutex.Lock()
+	defer fake.pendingFolderHandoversMutex.Unlock()
+	fake.PendingFolderHandoversStub = nil
+	fake.pendingFolderHandoversReturns = struct {
+		result1 []model.PendingFolderHandover
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) PendingFolderHandoversReturnsOnCall(i int, result1 []model.PendingFolderHandover, result2 error) {
+	fake.pendingFolderHandoversMutex.Lock()
+	defer fake.pendingFolderHandoversMutex.Unlock()
+	fake.PendingFolderHandoversStub = nil
+	if fake.pendingFolderHandoversReturnsOnCall == nil {
+		fake.pendingFolderHandoversReturnsOnCall = make(map[int]struct {
+			result1 []model.PendingFolderHandover
+			result2 error
+		})
+	}
+	fake.pendingFolderHandoversReturnsOnCall[i] = struct {
+		result1 []model.PendingFolderHandover
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) PendingFolders(arg1 protocol.DeviceID) (map[string]db.PendingFolder, error) {
 	fake.pendingFoldersMutex.Lock()
 	ret, specificReturn := fake.pendingFoldersReturnsOnCall[len(fake.pendingFoldersArgsForCall)]
@@ -2793,84 +5067,778 @@ func (fake *Model) PendingFoldersReturns(result1 map[string]db.PendingFolder, re
 	}{result1, result2}
 }
 
-func (fake *Model) PendingFoldersReturnsOnCall(i int, result1 map[string]db.PendingFolder, result2 error) {
-	fake.pendingFoldersMutex.Lock()
-	defer fake.pendingFoldersMutex.Unlock()
-	fake.PendingFoldersStub = nil
-	if fake.pendingFoldersReturnsOnCall == nil {
-		fake.pendingFoldersReturnsOnCall = make(map[int]struct {
-			result1 map[string]db.PendingFolder
-			result2 error
+func (fake *Model) PendingFoldersReturnsOnCall(i int, result1 map[string]db.PendingFolder, result2 error) {
+	fake.pendingFoldersMutex.Lock()
+	defer fake.pendingFoldersMutex.Unlock()
+	fake.PendingFoldersStub = nil
+	if fake.pendingFoldersReturnsOnCall == nil {
+		fake.pendingFoldersReturnsOnCall = make(map[int]struct {
+			result1 map[string]db.PendingFolder
+			result2 error
+		})
+	}
+	fake.pendingFoldersReturnsOnCall[i] = struct {
+		result1 map[string]db.PendingFolder
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) PendingOwnershipClaims() ([]model.PendingOwnershipClaim, error) {
+	fake.pendingOwnershipClaimsMutex.Lock()
+	ret, specificReturn := fake.pendingOwnershipClaimsReturnsOnCall[len(fake.pendingOwnershipClaimsArgsForCall)]
+	fake.pendingOwnershipClaimsArgsForCall = append(fake.pendingOwnershipClaimsArgsForCall, struct{}{})
+	stub := fake.PendingOwnershipClaimsStub
+	fakeReturns := fake.pendingOwnershipClaimsReturns
+	fake.recordInvocation("PendingOwnershipClaims", []interface{}{})
+	fake.pendingOwnershipClaimsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) PendingOwnershipClaimsCallCount() int {
+	fake.pendingOwnershipClaimsMutex.RLock()
+	defer fake.pendingOwnershipClaimsMutex.RUnlock()
+	return len(fake.pendingOwnershipClaimsArgsForCall)
+}
+
+func (fake *Model) PendingOwnershipClaimsCalls(stub func() ([]model.PendingOwnershipClaim, error)) {
+	fake.pendingOwnershipClaimsMutex.Lock()
+	defer fake.pendingOwnershipClaimsMutex.Unlock()
+	fake.PendingOwnershipClaimsStub = stub
+}
+
+func (fake *Model) PendingOwnershipClaimsReturns(result1 []model.PendingOwnershipClaim, result2 error) {
+	fake.pendingOwnershipClaimsMutex.Lock()
+	defer fake.pendingOwnershipClaimsMutex.Unlock()
+	fake.PendingOwnershipClaimsStub = nil
+	fake.pendingOwnershipClaimsReturns = struct {
+		result1 []model.PendingOwnershipClaim
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) PendingOwnershipClaimsReturnsOnCall(i int, result1 []model.PendingOwnershipClaim, result2 error) {
+	fake.pendingOwnershipClaimsMutex.Lock()
+	defer fake.pendingOwnershipClaimsMutex.Unlock()
+	fake.PendingOwnershipClaimsStub = nil
+	if fake.pendingOwnershipClaimsReturnsOnCall == nil {
+		fake.pendingOwnershipClaimsReturnsOnCall = make(map[int]struct {
+			result1 []model.PendingOwnershipClaim
+			result2 error
+		})
+	}
+	fake.pendingOwnershipClaimsReturnsOnCall[i] = struct {
+		result1 []model.PendingOwnershipClaim
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) PrefixCompletion(arg1 protocol.DeviceID, arg2 string, arg3 string) (model.FolderCompletion, error) {
+	fake.prefixCompletionMutex.Lock()
+	ret, specificReturn := fake.prefixCompletionReturnsOnCall[len(fake.prefixCompletionArgsForCall)]
+	fake.prefixCompletionArgsForCall = append(fake.prefixCompletionArgsForCall, struct {
+		arg1 protocol.DeviceID
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.PrefixCompletionStub
+	fakeReturns := fake.prefixCompletionReturns
+	fake.recordInvocation("PrefixCompletion", []interface{}{arg1, arg2, arg3})
+	fake.prefixCompletionMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) PrefixCompletionCallCount() int {
+	fake.prefixCompletionMutex.RLock()
+	defer fake.prefixCompletionMutex.RUnlock()
+	return len(fake.prefixCompletionArgsForCall)
+}
+
+func (fake *Model) PrefixCompletionCalls(stub func(protocol.DeviceID, string, string) (model.FolderCompletion, error)) {
+	fake.prefixCompletionMutex.Lock()
+	defer fake.prefixCompletionMutex.Unlock()
+	fake.PrefixCompletionStub = stub
+}
+
+func (fake *Model) PrefixCompletionArgsForCall(i int) (protocol.DeviceID, string, string) {
+	fake.prefixCompletionMutex.RLock()
+	defer fake.prefixCompletionMutex.RUnlock()
+	argsForCall := fake.prefixCompletionArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Model) PrefixCompletionReturns(result1 model.FolderCompletion, result2 error) {
+	fake.prefixCompletionMutex.Lock()
+	defer fake.prefixCompletionMutex.Unlock()
+	fake.PrefixCompletionStub = nil
+	fake.prefixCompletionReturns = struct {
+		result1 model.FolderCompletion
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) PrefixCompletionReturnsOnCall(i int, result1 model.FolderCompletion, result2 error) {
+	fake.prefixCompletionMutex.Lock()
+	defer fake.prefixCompletionMutex.Unlock()
+	fake.PrefixCompletionStub = nil
+	if fake.prefixCompletionReturnsOnCall == nil {
+		fake.prefixCompletionReturnsOnCall = make(map[int]struct {
+			result1 model.FolderCompletion
+			result2 error
+		})
+	}
+	fake.prefixCompletionReturnsOnCall[i] = struct {
+		result1 model.FolderCompletion
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) ProbeFolderCapabilities(arg1 string) (*model.FolderCapabilities, error) {
+	fake.probeFolderCapabilitiesMutex.Lock()
+	ret, specificReturn := fake.probeFolderCapabilitiesReturnsOnCall[len(fake.probeFolderCapabilitiesArgsForCall)]
+	fake.probeFolderCapabilitiesArgsForCall = append(fake.probeFolderCapabilitiesArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ProbeFolderCapabilitiesStub
+	fakeReturns := fake.probeFolderCapabilitiesReturns
+	fake.recordInvocation("ProbeFolderCapabilities", []interface{}{arg1})
+	fake.probeFolderCapabilitiesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) ProbeFolderCapabilitiesCallCount() int {
+	fake.probeFolderCapabilitiesMutex.RLock()
+	defer fake.probeFolderCapabilitiesMutex.RUnlock()
+	return len(fake.probeFolderCapabilitiesArgsForCall)
+}
+
+func (fake *Model) ProbeFolderCapabilitiesCalls(stub func(string) (*model.FolderCapabilities, error)) {
+	fake.probeFolderCapabilitiesMutex.Lock()
+	defer fake.probeFolderCapabilitiesMutex.Unlock()
+	fake.ProbeFolderCapabilitiesStub = stub
+}
+
+func (fake *Model) ProbeFolderCapabilitiesArgsForCall(i int) string {
+	fake.probeFolderCapabilitiesMutex.RLock()
+	defer fake.probeFolderCapabilitiesMutex.RUnlock()
+	argsForCall := fake.probeFolderCapabilitiesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) ProbeFolderCapabilitiesReturns(result1 *model.FolderCapabilities, result2 error) {
+	fake.probeFolderCapabilitiesMutex.Lock()
+	defer fake.probeFolderCapabilitiesMutex.Unlock()
+	fake.ProbeFolderCapabilitiesStub = nil
+	fake.probeFolderCapabilitiesReturns = struct {
+		result1 *model.FolderCapabilities
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) ProbeFolderCapabilitiesReturnsOnCall(i int, result1 *model.FolderCapabilities, result2 error) {
+	fake.probeFolderCapabilitiesMutex.Lock()
+	defer fake.probeFolderCapabilitiesMutex.Unlock()
+	fake.ProbeFolderCapabilitiesStub = nil
+	if fake.probeFolderCapabilitiesReturnsOnCall == nil {
+		fake.probeFolderCapabilitiesReturnsOnCall = make(map[int]struct {
+			result1 *model.FolderCapabilities
+			result2 error
+		})
+	}
+	fake.probeFolderCapabilitiesReturnsOnCall[i] = struct {
+		result1 *model.FolderCapabilities
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) PromoteConfigSyncSpare(arg1 protocol.DeviceID) ([]string, error) {
+	fake.promoteConfigSyncSpareMutex.Lock()
+	ret, specificReturn := fake.promoteConfigSyncSpareReturnsOnCall[len(fake.promoteConfigSyncSpareArgsForCall)]
+	fake.promoteConfigSyncSpareArgsForCall = append(fake.promoteConfigSyncSpareArgsForCall, struct {
+		arg1 protocol.DeviceID
+	}{arg1})
+	stub := fake.PromoteConfigSyncSpareStub
+	fakeReturns := fake.promoteConfigSyncSpareReturns
+	fake.recordInvocation("PromoteConfigSyncSpare", []interface{}{arg1})
+	fake.promoteConfigSyncSpareMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) PromoteConfigSyncSpareCallCount() int {
+	fake.promoteConfigSyncSpareMutex.RLock()
+	defer fake.promoteConfigSyncSpareMutex.RUnlock()
+	return len(fake.promoteConfigSyncSpareArgsForCall)
+}
+
+func (fake *Model) PromoteConfigSyncSpareCalls(stub func(protocol.DeviceID) ([]string, error)) {
+	fake.promoteConfigSyncSpareMutex.Lock()
+	defer fake.promoteConfigSyncSpareMutex.Unlock()
+	fake.PromoteConfigSyncSpareStub = stub
+}
+
+func (fake *Model) PromoteConfigSyncSpareArgsForCall(i int) protocol.DeviceID {
+	fake.promoteConfigSyncSpareMutex.RLock()
+	defer fake.promoteConfigSyncSpareMutex.RUnlock()
+	argsForCall := fake.promoteConfigSyncSpareArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) PromoteConfigSyncSpareReturns(result1 []string, result2 error) {
+	fake.promoteConfigSyncSpareMutex.Lock()
+	defer fake.promoteConfigSyncSpareMutex.Unlock()
+	fake.PromoteConfigSyncSpareStub = nil
+	fake.promoteConfigSyncSpareReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) PromoteConfigSyncSpareReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.promoteConfigSyncSpareMutex.Lock()
+	defer fake.promoteConfigSyncSpareMutex.Unlock()
+	fake.PromoteConfigSyncSpareStub = nil
+	if fake.promoteConfigSyncSpareReturnsOnCall == nil {
+		fake.promoteConfigSyncSpareReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.promoteConfigSyncSpareReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) PullPreview(arg1 string) (*model.PullPreview, error) {
+	fake.pullPreviewMutex.Lock()
+	ret, specificReturn := fake.pullPreviewReturnsOnCall[len(fake.pullPreviewArgsForCall)]
+	fake.pullPreviewArgsForCall = append(fake.pullPreviewArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.PullPreviewStub
+	fakeReturns := fake.pullPreviewReturns
+	fake.recordInvocation("PullPreview", []interface{}{arg1})
+	fake.pullPreviewMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) PullPreviewCallCount() int {
+	fake.pullPreviewMutex.RLock()
+	defer fake.pullPreviewMutex.RUnlock()
+	return len(fake.pullPreviewArgsForCall)
+}
+
+func (fake *Model) PullPreviewCalls(stub func(string) (*model.PullPreview, error)) {
+	fake.pullPreviewMutex.Lock()
+	defer fake.pullPreviewMutex.Unlock()
+	fake.PullPreviewStub = stub
+}
+
+func (fake *Model) PullPreviewArgsForCall(i int) string {
+	fake.pullPreviewMutex.RLock()
+	defer fake.pullPreviewMutex.RUnlock()
+	argsForCall := fake.pullPreviewArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) PullPreviewReturns(result1 *model.PullPreview, result2 error) {
+	fake.pullPreviewMutex.Lock()
+	defer fake.pullPreviewMutex.Unlock()
+	fake.PullPreviewStub = nil
+	fake.pullPreviewReturns = struct {
+		result1 *model.PullPreview
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) PullPreviewReturnsOnCall(i int, result1 *model.PullPreview, result2 error) {
+	fake.pullPreviewMutex.Lock()
+	defer fake.pullPreviewMutex.Unlock()
+	fake.PullPreviewStub = nil
+	if fake.pullPreviewReturnsOnCall == nil {
+		fake.pullPreviewReturnsOnCall = make(map[int]struct {
+			result1 *model.PullPreview
+			result2 error
+		})
+	}
+	fake.pullPreviewReturnsOnCall[i] = struct {
+		result1 *model.PullPreview
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) QueueDeviceMessage(arg1 protocol.DeviceID, arg2 string, arg3 string, arg4 []byte) error {
+	var arg4Copy []byte
+	if arg4 != nil {
+		arg4Copy = make([]byte, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.queueDeviceMessageMutex.Lock()
+	ret, specificReturn := fake.queueDeviceMessageReturnsOnCall[len(fake.queueDeviceMessageArgsForCall)]
+	fake.queueDeviceMessageArgsForCall = append(fake.queueDeviceMessageArgsForCall, struct {
+		arg1 protocol.DeviceID
+		arg2 string
+		arg3 string
+		arg4 []byte
+	}{arg1, arg2, arg3, arg4Copy})
+	stub := fake.QueueDeviceMessageStub
+	fakeReturns := fake.queueDeviceMessageReturns
+	fake.recordInvocation("QueueDeviceMessage", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.queueDeviceMessageMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) QueueDeviceMessageCallCount() int {
+	fake.queueDeviceMessageMutex.RLock()
+	defer fake.queueDeviceMessageMutex.RUnlock()
+	return len(fake.queueDeviceMessageArgsForCall)
+}
+
+func (fake *Model) QueueDeviceMessageCalls(stub func(protocol.DeviceID, string, string, []byte) error) {
+	fake.queueDeviceMessageMutex.Lock()
+	defer fake.queueDeviceMessageMutex.Unlock()
+	fake.QueueDeviceMessageStub = stub
+}
+
+func (fake *Model) QueueDeviceMessageArgsForCall(i int) (protocol.DeviceID, string, string, []byte) {
+	fake.queueDeviceMessageMutex.RLock()
+	defer fake.queueDeviceMessageMutex.RUnlock()
+	argsForCall := fake.queueDeviceMessageArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *Model) QueueDeviceMessageReturns(result1 error) {
+	fake.queueDeviceMessageMutex.Lock()
+	defer fake.queueDeviceMessageMutex.Unlock()
+	fake.QueueDeviceMessageStub = nil
+	fake.queueDeviceMessageReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) QueueDeviceMessageReturnsOnCall(i int, result1 error) {
+	fake.queueDeviceMessageMutex.Lock()
+	defer fake.queueDeviceMessageMutex.Unlock()
+	fake.QueueDeviceMessageStub = nil
+	if fake.queueDeviceMessageReturnsOnCall == nil {
+		fake.queueDeviceMessageReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.queueDeviceMessageReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) QueuedDeviceMessages(arg1 protocol.DeviceID) ([]model.QueuedMessage, error) {
+	fake.queuedDeviceMessagesMutex.Lock()
+	ret, specificReturn := fake.queuedDeviceMessagesReturnsOnCall[len(fake.queuedDeviceMessagesArgsForCall)]
+	fake.queuedDeviceMessagesArgsForCall = append(fake.queuedDeviceMessagesArgsForCall, struct {
+		arg1 protocol.DeviceID
+	}{arg1})
+	stub := fake.QueuedDeviceMessagesStub
+	fakeReturns := fake.queuedDeviceMessagesReturns
+	fake.recordInvocation("QueuedDeviceMessages", []interface{}{arg1})
+	fake.queuedDeviceMessagesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) QueuedDeviceMessagesCallCount() int {
+	fake.queuedDeviceMessagesMutex.RLock()
+	defer fake.queuedDeviceMessagesMutex.RUnlock()
+	return len(fake.queuedDeviceMessagesArgsForCall)
+}
+
+func (fake *Model) QueuedDeviceMessagesCalls(stub func(protocol.DeviceID) ([]model.QueuedMessage, error)) {
+	fake.queuedDeviceMessagesMutex.Lock()
+	defer fake.queuedDeviceMessagesMutex.Unlock()
+	fake.QueuedDeviceMessagesStub = stub
+}
+
+func (fake *Model) QueuedDeviceMessagesArgsForCall(i int) protocol.DeviceID {
+	fake.queuedDeviceMessagesMutex.RLock()
+	defer fake.queuedDeviceMessagesMutex.RUnlock()
+	argsForCall := fake.queuedDeviceMessagesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) QueuedDeviceMessagesReturns(result1 []model.QueuedMessage, result2 error) {
+	fake.queuedDeviceMessagesMutex.Lock()
+	defer fake.queuedDeviceMessagesMutex.Unlock()
+	fake.QueuedDeviceMessagesStub = nil
+	fake.queuedDeviceMessagesReturns = struct {
+		result1 []model.QueuedMessage
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) QueuedDeviceMessagesReturnsOnCall(i int, result1 []model.QueuedMessage, result2 error) {
+	fake.queuedDeviceMessagesMutex.Lock()
+	defer fake.queuedDeviceMessagesMutex.Unlock()
+	fake.QueuedDeviceMessagesStub = nil
+	if fake.queuedDeviceMessagesReturnsOnCall == nil {
+		fake.queuedDeviceMessagesReturnsOnCall = make(map[int]struct {
+			result1 []model.QueuedMessage
+			result2 error
+		})
+	}
+	fake.queuedDeviceMessagesReturnsOnCall[i] = struct {
+		result1 []model.QueuedMessage
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) ReceiveOnlySize(arg1 string) (db.Counts, error) {
+	fake.receiveOnlySizeMutex.Lock()
+	ret, specificReturn := fake.receiveOnlySizeReturnsOnCall[len(fake.receiveOnlySizeArgsForCall)]
+	fake.receiveOnlySizeArgsForCall = append(fake.receiveOnlySizeArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ReceiveOnlySizeStub
+	fakeReturns := fake.receiveOnlySizeReturns
+	fake.recordInvocation("ReceiveOnlySize", []interface{}{arg1})
+	fake.receiveOnlySizeMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) ReceiveOnlySizeCallCount() int {
+	fake.receiveOnlySizeMutex.RLock()
+	defer fake.receiveOnlySizeMutex.RUnlock()
+	return len(fake.receiveOnlySizeArgsForCall)
+}
+
+func (fake *Model) ReceiveOnlySizeCalls(stub func(string) (db.Counts, error)) {
+	fake.receiveOnlySizeMutex.Lock()
+	defer fake.receiveOnlySizeMutex.Unlock()
+	fake.ReceiveOnlySizeStub = stub
+}
+
+func (fake *Model) ReceiveOnlySizeArgsForCall(i int) string {
+	fake.receiveOnlySizeMutex.RLock()
+	defer fake.receiveOnlySizeMutex.RUnlock()
+	argsForCall := fake.receiveOnlySizeArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) ReceiveOnlySizeReturns(result1 db.Counts, result2 error) {
+	fake.receiveOnlySizeMutex.Lock()
+	defer fake.receiveOnlySizeMutex.Unlock()
+	fake.ReceiveOnlySizeStub = nil
+	fake.receiveOnlySizeReturns = struct {
+		result1 db.Counts
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) ReceiveOnlySizeReturnsOnCall(i int, result1 db.Counts, result2 error) {
+	fake.receiveOnlySizeMutex.Lock()
+	defer fake.receiveOnlySizeMutex.Unlock()
+	fake.ReceiveOnlySizeStub = nil
+	if fake.receiveOnlySizeReturnsOnCall == nil {
+		fake.receiveOnlySizeReturnsOnCall = make(map[int]struct {
+			result1 db.Counts
+			result2 error
+		})
+	}
+	fake.receiveOnlySizeReturnsOnCall[i] = struct {
+		result1 db.Counts
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) RejectConfigSync(arg1 protocol.DeviceID, arg2 string, arg3 string) error {
+	fake.rejectConfigSyncMutex.Lock()
+	ret, specificReturn := fake.rejectConfigSyncReturnsOnCall[len(fake.rejectConfigSyncArgsForCall)]
+	fake.rejectConfigSyncArgsForCall = append(fake.rejectConfigSyncArgsForCall, struct {
+		arg1 protocol.DeviceID
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.RejectConfigSyncStub
+	fakeReturns := fake.rejectConfigSyncReturns
+	fake.recordInvocation("RejectConfigSync", []interface{}{arg1, arg2, arg3})
+	fake.rejectConfigSyncMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) RejectConfigSyncCallCount() int {
+	fake.rejectConfigSyncMutex.RLock()
+	defer fake.rejectConfigSyncMutex.RUnlock()
+	return len(fake.rejectConfigSyncArgsForCall)
+}
+
+func (fake *Model) RejectConfigSyncCalls(stub func(protocol.DeviceID, string, string) error) {
+	fake.rejectConfigSyncMutex.Lock()
+	defer fake.rejectConfigSyncMutex.Unlock()
+	fake.RejectConfigSyncStub = stub
+}
+
+func (fake *Model) RejectConfigSyncArgsForCall(i int) (protocol.DeviceID, string, string) {
+	fake.rejectConfigSyncMutex.RLock()
+	defer fake.rejectConfigSyncMutex.RUnlock()
+	argsForCall := fake.rejectConfigSyncArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Model) RejectConfigSyncReturns(result1 error) {
+	fake.rejectConfigSyncMutex.Lock()
+	defer fake.rejectConfigSyncMutex.Unlock()
+	fake.RejectConfigSyncStub = nil
+	fake.rejectConfigSyncReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) RejectConfigSyncReturnsOnCall(i int, result1 error) {
+	fake.rejectConfigSyncMutex.Lock()
+	defer fake.rejectConfigSyncMutex.Unlock()
+	fake.RejectConfigSyncStub = nil
+	if fake.rejectConfigSyncReturnsOnCall == nil {
+		fake.rejectConfigSyncReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.rejectConfigSyncReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) RejectDeviceRevocation(arg1 protocol.DeviceID) error {
+	fake.rejectDeviceRevocationMutex.Lock()
+	ret, specificReturn := fake.rejectDeviceRevocationReturnsOnCall[len(fake.rejectDeviceRevocationArgsForCall)]
+	fake.rejectDeviceRevocationArgsForCall = append(fake.rejectDeviceRevocationArgsForCall, struct {
+		arg1 protocol.DeviceID
+	}{arg1})
+	stub := fake.RejectDeviceRevocationStub
+	fakeReturns := fake.rejectDeviceRevocationReturns
+	fake.recordInvocation("RejectDeviceRevocation", []interface{}{arg1})
+	fake.rejectDeviceRevocationMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) RejectDeviceRevocationCallCount() int {
+	fake.rejectDeviceRevocationMutex.RLock()
+	defer fake.rejectDeviceRevocationMutex.RUnlock()
+	return len(fake.rejectDeviceRevocationArgsForCall)
+}
+
+func (fake *Model) RejectDeviceRevocationCalls(stub func(protocol.DeviceID) error) {
+	fake.rejectDeviceRevocationMutex.Lock()
+	defer fake.rejectDeviceRevocationMutex.Unlock()
+	fake.RejectDeviceRevocationStub = stub
+}
+
+func (fake *Model) RejectDeviceRevocationArgsForCall(i int) protocol.DeviceID {
+	fake.rejectDeviceRevocationMutex.RLock()
+	defer fake.rejectDeviceRevocationMutex.RUnlock()
+	argsForCall := fake.rejectDeviceRevocationArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) RejectDeviceRevocationReturns(result1 error) {
+	fake.rejectDeviceRevocationMutex.Lock()
+	defer fake.rejectDeviceRevocationMutex.Unlock()
+	fake.RejectDeviceRevocationStub = nil
+	fake.rejectDeviceRevocationReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) RejectDeviceRevocationReturnsOnCall(i int, result1 error) {
+	fake.rejectDeviceRevocationMutex.Lock()
+	defer fake.rejectDeviceRevocationMutex.Unlock()
+	fake.RejectDeviceRevocationStub = nil
+	if fake.rejectDeviceRevocationReturnsOnCall == nil {
+		fake.rejectDeviceRevocationReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.rejectDeviceRevocationReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) RejectFolderHandover(arg1 string, arg2 protocol.DeviceID) error {
+	fake.rejectFolderHandoverMutex.Lock()
+	ret, specificReturn := fake.rejectFolderHandoverReturnsOnCall[len(fake.rejectFolderHandoverArgsForCall)]
+	fake.rejectFolderHandoverArgsForCall = append(fake.rejectFolderHandoverArgsForCall, struct {
+		arg1 string
+		arg2 protocol.DeviceID
+	}{arg1, arg2})
+	stub := fake.RejectFolderHandoverStub
+	fakeReturns := fake.rejectFolderHandoverReturns
+	fake.recordInvocation("RejectFolderHandover", []interface{}{arg1, arg2})
+	fake.rejectFolderHandoverMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) RejectFolderHandoverCallCount() int {
+	fake.rejectFolderHandoverMutex.RLock()
+	defer fake.rejectFolderHandoverMutex.RUnlock()
+	return len(fake.rejectFolderHandoverArgsForCall)
+}
+
+func (fake *Model) RejectFolderHandoverCalls(stub func(string, protocol.DeviceID) error) {
+	fake.rejectFolderHandoverMutex.Lock()
+	defer fake.rejectFolderHandoverMutex.Unlock()
+	fake.RejectFolderHandoverStub = stub
+}
+
+func (fake *Model) RejectFolderHandoverArgsForCall(i int) (string, protocol.DeviceID) {
+	fake.rejectFolderHandoverMutex.RLock()
+	defer fake.rejectFolderHandoverMutex.RUnlock()
+	argsForCall := fake.rejectFolderHandoverArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Model) RejectFolderHandoverReturns(result1 error) {
+	fake.rejectFolderHandoverMutex.Lock()
+	defer fake.rejectFolderHandoverMutex.Unlock()
+	fake.RejectFolderHandoverStub = nil
+	fake.rejectFolderHandoverReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) RejectFolderHandoverReturnsOnCall(i int, result1 error) {
+	fake.rejectFolderHandoverMutex.Lock()
+	defer fake.rejectFolderHandoverMutex.Unlock()
+	fake.RejectFolderHandoverStub = nil
+	if fake.rejectFolderHandoverReturnsOnCall == nil {
+		fake.rejectFolderHandoverReturnsOnCall = make(map[int]struct {
+			result1 error
 		})
 	}
-	fake.pendingFoldersReturnsOnCall[i] = struct {
-		result1 map[string]db.PendingFolder
-		result2 error
-	}{result1, result2}
+	fake.rejectFolderHandoverReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
 }
 
-func (fake *Model) ReceiveOnlySize(arg1 string) (db.Counts, error) {
-	fake.receiveOnlySizeMutex.Lock()
-	ret, specificReturn := fake.receiveOnlySizeReturnsOnCall[len(fake.receiveOnlySizeArgsForCall)]
-	fake.receiveOnlySizeArgsForCall = append(fake.receiveOnlySizeArgsForCall, struct {
+func (fake *Model) RejectOwnershipClaim(arg1 string, arg2 protocol.DeviceID) error {
+	fake.rejectOwnershipClaimMutex.Lock()
+	ret, specificReturn := fake.rejectOwnershipClaimReturnsOnCall[len(fake.rejectOwnershipClaimArgsForCall)]
+	fake.rejectOwnershipClaimArgsForCall = append(fake.rejectOwnershipClaimArgsForCall, struct {
 		arg1 string
-	}{arg1})
-	stub := fake.ReceiveOnlySizeStub
-	fakeReturns := fake.receiveOnlySizeReturns
-	fake.recordInvocation("ReceiveOnlySize", []interface{}{arg1})
-	fake.receiveOnlySizeMutex.Unlock()
+		arg2 protocol.DeviceID
+	}{arg1, arg2})
+	stub := fake.RejectOwnershipClaimStub
+	fakeReturns := fake.rejectOwnershipClaimReturns
+	fake.recordInvocation("RejectOwnershipClaim", []interface{}{arg1, arg2})
+	fake.rejectOwnershipClaimMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
-		return ret.result1, ret.result2
+		return ret.result1
 	}
-	return fakeReturns.result1, fakeReturns.result2
+	return fakeReturns.result1
 }
 
-func (fake *Model) ReceiveOnlySizeCallCount() int {
-	fake.receiveOnlySizeMutex.RLock()
-	defer fake.receiveOnlySizeMutex.RUnlock()
-	return len(fake.receiveOnlySizeArgsForCall)
+func (fake *Model) RejectOwnershipClaimCallCount() int {
+	fake.rejectOwnershipClaimMutex.RLock()
+	defer fake.rejectOwnershipClaimMutex.RUnlock()
+	return len(fake.rejectOwnershipClaimArgsForCall)
 }
 
-func (fake *Model) ReceiveOnlySizeCalls(stub func(string) (db.Counts, error)) {
-	fake.receiveOnlySizeMutex.Lock()
-	defer fake.receiveOnlySizeMutex.Unlock()
-	fake.ReceiveOnlySizeStub = stub
+func (fake *Model) RejectOwnershipClaimCalls(stub func(string, protocol.DeviceID) error) {
+	fake.rejectOwnershipClaimMutex.Lock()
+	defer fake.rejectOwnershipClaimMutex.Unlock()
+	fake.RejectOwnershipClaimStub = stub
 }
 
-func (fake *Model) ReceiveOnlySizeArgsForCall(i int) string {
-	fake.receiveOnlySizeMutex.RLock()
-	defer fake.receiveOnlySizeMutex.RUnlock()
-	argsForCall := fake.receiveOnlySizeArgsForCall[i]
-	return argsForCall.arg1
+func (fake *Model) RejectOwnershipClaimArgsForCall(i int) (string, protocol.DeviceID) {
+	fake.rejectOwnershipClaimMutex.RLock()
+	defer fake.rejectOwnershipClaimMutex.RUnlock()
+	argsForCall := fake.rejectOwnershipClaimArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *Model) ReceiveOnlySizeReturns(result1 db.Counts, result2 error) {
-	fake.receiveOnlySizeMutex.Lock()
-	defer fake.receiveOnlySizeMutex.Unlock()
-	fake.ReceiveOnlySizeStub = nil
-	fake.receiveOnlySizeReturns = struct {
-		result1 db.Counts
-		result2 error
-	}{result1, result2}
+func (fake *Model) RejectOwnershipClaimReturns(result1 error) {
+	fake.rejectOwnershipClaimMutex.Lock()
+	defer fake.rejectOwnershipClaimMutex.Unlock()
+	fake.RejectOwnershipClaimStub = nil
+	fake.rejectOwnershipClaimReturns = struct {
+		result1 error
+	}{result1}
 }
 
-func (fake *Model) ReceiveOnlySizeReturnsOnCall(i int, result1 db.Counts, result2 error) {
-	fake.receiveOnlySizeMutex.Lock()
-	defer fake.receiveOnlySizeMutex.Unlock()
-	fake.ReceiveOnlySizeStub = nil
-	if fake.receiveOnlySizeReturnsOnCall == nil {
-		fake.receiveOnlySizeReturnsOnCall = make(map[int]struct {
-			result1 db.Counts
-			result2 error
+func (fake *Model) RejectOwnershipClaimReturnsOnCall(i int, result1 error) {
+	fake.rejectOwnershipClaimMutex.Lock()
+	defer fake.rejectOwnershipClaimMutex.Unlock()
+	fake.RejectOwnershipClaimStub = nil
+	if fake.rejectOwnershipClaimReturnsOnCall == nil {
+		fake.rejectOwnershipClaimReturnsOnCall = make(map[int]struct {
+			result1 error
 		})
 	}
-	fake.receiveOnlySizeReturnsOnCall[i] = struct {
-		result1 db.Counts
-		result2 error
-	}{result1, result2}
+	fake.rejectOwnershipClaimReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
 }
 
 func (fake *Model) RemoteNeedFolderFiles(arg1 string, arg2 protocol.DeviceID, arg3 int, arg4 int) ([]protocol.FileInfo, error) {
@@ -3069,6 +6037,73 @@ func (fake *Model) RequestReturnsOnCall(i int, result1 protocol.RequestResponse,
 	}{result1, result2}
 }
 
+func (fake *Model) RequestFolderPreview(arg1 context.Context, arg2 protocol.DeviceID, arg3 string, arg4 int) (*protocol.FolderPreview, error) {
+	fake.requestFolderPreviewMutex.Lock()
+	ret, specificReturn := fake.requestFolderPreviewReturnsOnCall[len(fake.requestFolderPreviewArgsForCall)]
+	fake.requestFolderPreviewArgsForCall = append(fake.requestFolderPreviewArgsForCall, struct {
+		arg1 context.Context
+		arg2 protocol.DeviceID
+		arg3 string
+		arg4 int
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.RequestFolderPreviewStub
+	fakeReturns := fake.requestFolderPreviewReturns
+	fake.recordInvocation("RequestFolderPreview", []interface{}{arg1, arg2, arg3, arg4})
+	fake.requestFolderPreviewMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) RequestFolderPreviewCallCount() int {
+	fake.requestFolderPreviewMutex.RLock()
+	defer fake.requestFolderPreviewMutex.RUnlock()
+	return len(fake.requestFolderPreviewArgsForCall)
+}
+
+func (fake *Model) RequestFolderPreviewCalls(stub func(context.Context, protocol.DeviceID, string, int) (*protocol.FolderPreview, error)) {
+	fake.requestFolderPreviewMutex.Lock()
+	defer fake.requestFolderPreviewMutex.Unlock()
+	fake.RequestFolderPreviewStub = stub
+}
+
+func (fake *Model) RequestFolderPreviewArgsForCall(i int) (context.Context, protocol.DeviceID, string, int) {
+	fake.requestFolderPreviewMutex.RLock()
+	defer fake.requestFolderPreviewMutex.RUnlock()
+	argsForCall := fake.requestFolderPreviewArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *Model) RequestFolderPreviewReturns(result1 *protocol.FolderPreview, result2 error) {
+	fake.requestFolderPreviewMutex.Lock()
+	defer fake.requestFolderPreviewMutex.Unlock()
+	fake.RequestFolderPreviewStub = nil
+	fake.requestFolderPreviewReturns = struct {
+		result1 *protocol.FolderPreview
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) RequestFolderPreviewReturnsOnCall(i int, result1 *protocol.FolderPreview, result2 error) {
+	fake.requestFolderPreviewMutex.Lock()
+	defer fake.requestFolderPreviewMutex.Unlock()
+	fake.RequestFolderPreviewStub = nil
+	if fake.requestFolderPreviewReturnsOnCall == nil {
+		fake.requestFolderPreviewReturnsOnCall = make(map[int]struct {
+			result1 *protocol.FolderPreview
+			result2 error
+		})
+	}
+	fake.requestFolderPreviewReturnsOnCall[i] = struct {
+		result1 *protocol.FolderPreview
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) RequestGlobal(arg1 context.Context, arg2 protocol.DeviceID, arg3 string, arg4 string, arg5 int, arg6 int64, arg7 int, arg8 []byte, arg9 bool) ([]byte, error) {
 	var arg8Copy []byte
 	if arg8 != nil {
@@ -3146,6 +6181,67 @@ func (fake *Model) RequestGlobalReturnsOnCall(i int, result1 []byte, result2 err
 	}{result1, result2}
 }
 
+func (fake *Model) RescanInterval(arg1 string) time.Duration {
+	fake.rescanIntervalMutex.Lock()
+	ret, specificReturn := fake.rescanIntervalReturnsOnCall[len(fake.rescanIntervalArgsForCall)]
+	fake.rescanIntervalArgsForCall = append(fake.rescanIntervalArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.RescanIntervalStub
+	fakeReturns := fake.rescanIntervalReturns
+	fake.recordInvocation("RescanInterval", []interface{}{arg1})
+	fake.rescanIntervalMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) RescanIntervalCallCount() int {
+	fake.rescanIntervalMutex.RLock()
+	defer fake.rescanIntervalMutex.RUnlock()
+	return len(fake.rescanIntervalArgsForCall)
+}
+
+func (fake *Model) RescanIntervalCalls(stub func(string) time.Duration) {
+	fake.rescanIntervalMutex.Lock()
+	defer fake.rescanIntervalMutex.Unlock()
+	fake.RescanIntervalStub = stub
+}
+
+func (fake *Model) RescanIntervalArgsForCall(i int) string {
+	fake.rescanIntervalMutex.RLock()
+	defer fake.rescanIntervalMutex.RUnlock()
+	argsForCall := fake.rescanIntervalArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *Model) RescanIntervalReturns(result1 time.Duration) {
+	fake.rescanIntervalMutex.Lock()
+	defer fake.rescanIntervalMutex.Unlock()
+	fake.RescanIntervalStub = nil
+	fake.rescanIntervalReturns = struct {
+		result1 time.Duration
+	}{result1}
+}
+
+func (fake *Model) RescanIntervalReturnsOnCall(i int, result1 time.Duration) {
+	fake.rescanIntervalMutex.Lock()
+	defer fake.rescanIntervalMutex.Unlock()
+	fake.RescanIntervalStub = nil
+	if fake.rescanIntervalReturnsOnCall == nil {
+		fake.rescanIntervalReturnsOnCall = make(map[int]struct {
+			result1 time.Duration
+		})
+	}
+	fake.rescanIntervalReturnsOnCall[i] = struct {
+		result1 time.Duration
+	}{result1}
+}
+
 func (fake *Model) ResetFolder(arg1 string) error {
 	fake.resetFolderMutex.Lock()
 	ret, specificReturn := fake.resetFolderReturnsOnCall[len(fake.resetFolderArgsForCall)]
@@ -3207,6 +6303,69 @@ func (fake *Model) ResetFolderReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *Model) ResolveConflict(arg1 string, arg2 string, arg3 string) error {
+	fake.resolveConflictMutex.Lock()
+	ret, specificReturn := fake.resolveConflictReturnsOnCall[len(fake.resolveConflictArgsForCall)]
+	fake.resolveConflictArgsForCall = append(fake.resolveConflictArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ResolveConflictStub
+	fakeReturns := fake.resolveConflictReturns
+	fake.recordInvocation("ResolveConflict", []interface{}{arg1, arg2, arg3})
+	fake.resolveConflictMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) ResolveConflictCallCount() int {
+	fake.resolveConflictMutex.RLock()
+	defer fake.resolveConflictMutex.RUnlock()
+	return len(fake.resolveConflictArgsForCall)
+}
+
+func (fake *Model) ResolveConflictCalls(stub func(string, string, string) error) {
+	fake.resolveConflictMutex.Lock()
+	defer fake.resolveConflictMutex.Unlock()
+	fake.ResolveConflictStub = stub
+}
+
+func (fake *Model) ResolveConflictArgsForCall(i int) (string, string, string) {
+	fake.resolveConflictMutex.RLock()
+	defer fake.resolveConflictMutex.RUnlock()
+	argsForCall := fake.resolveConflictArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Model) ResolveConflictReturns(result1 error) {
+	fake.resolveConflictMutex.Lock()
+	defer fake.resolveConflictMutex.Unlock()
+	fake.ResolveConflictStub = nil
+	fake.resolveConflictReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) ResolveConflictReturnsOnCall(i int, result1 error) {
+	fake.resolveConflictMutex.Lock()
+	defer fake.resolveConflictMutex.Unlock()
+	fake.ResolveConflictStub = nil
+	if fake.resolveConflictReturnsOnCall == nil {
+		fake.resolveConflictReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.resolveConflictReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *Model) RestoreFolderVersions(arg1 string, arg2 map[string]time.Time) (map[string]error, error) {
 	fake.restoreFolderVersionsMutex.Lock()
 	ret, specificReturn := fake.restoreFolderVersionsReturnsOnCall[len(fake.restoreFolderVersionsArgsForCall)]
@@ -3304,6 +6463,71 @@ func (fake *Model) RevertArgsForCall(i int) string {
 	return argsForCall.arg1
 }
 
+func (fake *Model) RevokeDevice(arg1 protocol.DeviceID, arg2 bool) (model.DeviceRevocation, error) {
+	fake.revokeDeviceMutex.Lock()
+	ret, specificReturn := fake.revokeDeviceReturnsOnCall[len(fake.revokeDeviceArgsForCall)]
+	fake.revokeDeviceArgsForCall = append(fake.revokeDeviceArgsForCall, struct {
+		arg1 protocol.DeviceID
+		arg2 bool
+	}{arg1, arg2})
+	stub := fake.RevokeDeviceStub
+	fakeReturns := fake.revokeDeviceReturns
+	fake.recordInvocation("RevokeDevice", []interface{}{arg1, arg2})
+	fake.revokeDeviceMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) RevokeDeviceCallCount() int {
+	fake.revokeDeviceMutex.RLock()
+	defer fake.revokeDeviceMutex.RUnlock()
+	return len(fake.revokeDeviceArgsForCall)
+}
+
+func (fake *Model) RevokeDeviceCalls(stub func(protocol.DeviceID, bool) (model.DeviceRevocation, error)) {
+	fake.revokeDeviceMutex.Lock()
+	defer fake.revokeDeviceMutex.Unlock()
+	fake.RevokeDeviceStub = stub
+}
+
+func (fake *Model) RevokeDeviceArgsForCall(i int) (protocol.DeviceID, bool) {
+	fake.revokeDeviceMutex.RLock()
+	defer fake.revokeDeviceMutex.RUnlock()
+	argsForCall := fake.revokeDeviceArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Model) RevokeDeviceReturns(result1 model.DeviceRevocation, result2 error) {
+	fake.revokeDeviceMutex.Lock()
+	defer fake.revokeDeviceMutex.Unlock()
+	fake.RevokeDeviceStub = nil
+	fake.revokeDeviceReturns = struct {
+		result1 model.DeviceRevocation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) RevokeDeviceReturnsOnCall(i int, result1 model.DeviceRevocation, result2 error) {
+	fake.revokeDeviceMutex.Lock()
+	defer fake.revokeDeviceMutex.Unlock()
+	fake.RevokeDeviceStub = nil
+	if fake.revokeDeviceReturnsOnCall == nil {
+		fake.revokeDeviceReturnsOnCall = make(map[int]struct {
+			result1 model.DeviceRevocation
+			result2 error
+		})
+	}
+	fake.revokeDeviceReturnsOnCall[i] = struct {
+		result1 model.DeviceRevocation
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) ScanFolder(arg1 string) error {
 	fake.scanFolderMutex.Lock()
 	ret, specificReturn := fake.scanFolderReturnsOnCall[len(fake.scanFolderArgsForCall)]
@@ -3710,6 +6934,72 @@ func (fake *Model) SetIgnoresReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *Model) StartFolderHandover(arg1 string, arg2 protocol.DeviceID, arg3 string) (model.FolderHandover, error) {
+	fake.startFolderHandoverMutex.Lock()
+	ret, specificReturn := fake.startFolderHandoverReturnsOnCall[len(fake.startFolderHandoverArgsForCall)]
+	fake.startFolderHandoverArgsForCall = append(fake.startFolderHandoverArgsForCall, struct {
+		arg1 string
+		arg2 protocol.DeviceID
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.StartFolderHandoverStub
+	fakeReturns := fake.startFolderHandoverReturns
+	fake.recordInvocation("StartFolderHandover", []interface{}{arg1, arg2, arg3})
+	fake.startFolderHandoverMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Model) StartFolderHandoverCallCount() int {
+	fake.startFolderHandoverMutex.RLock()
+	defer fake.startFolderHandoverMutex.RUnlock()
+	return len(fake.startFolderHandoverArgsForCall)
+}
+
+func (fake *Model) StartFolderHandoverCalls(stub func(string, protocol.DeviceID, string) (model.FolderHandover, error)) {
+	fake.startFolderHandoverMutex.Lock()
+	defer fake.startFolderHandoverMutex.Unlock()
+	fake.StartFolderHandoverStub = stub
+}
+
+func (fake *Model) StartFolderHandoverArgsForCall(i int) (string, protocol.DeviceID, string) {
+	fake.startFolderHandoverMutex.RLock()
+	defer fake.startFolderHandoverMutex.RUnlock()
+	argsForCall := fake.startFolderHandoverArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Model) StartFolderHandoverReturns(result1 model.FolderHandover, result2 error) {
+	fake.startFolderHandoverMutex.Lock()
+	defer fake.startFolderHandoverMutex.Unlock()
+	fake.StartFolderHandoverStub = nil
+	fake.startFolderHandoverReturns = struct {
+		result1 model.FolderHandover
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Model) StartFolderHandoverReturnsOnCall(i int, result1 model.FolderHandover, result2 error) {
+	fake.startFolderHandoverMutex.Lock()
+	defer fake.startFolderHandoverMutex.Unlock()
+	fake.StartFolderHandoverStub = nil
+	if fake.startFolderHandoverReturnsOnCall == nil {
+		fake.startFolderHandoverReturnsOnCall = make(map[int]struct {
+			result1 model.FolderHandover
+			result2 error
+		})
+	}
+	fake.startFolderHandoverReturnsOnCall[i] = struct {
+		result1 model.FolderHandover
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Model) State(arg1 string) (string, time.Time, error) {
 	fake.stateMutex.Lock()
 	ret, specificReturn := fake.stateReturnsOnCall[len(fake.stateArgsForCall)]
@@ -3811,6 +7101,69 @@ func (fake *Model) UsageReportingStatsArgsForCall(i int) (*contract.Report, int,
 	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
+func (fake *Model) WaitForFolderIdle(arg1 context.Context, arg2 string, arg3 time.Duration) error {
+	fake.waitForFolderIdleMutex.Lock()
+	ret, specificReturn := fake.waitForFolderIdleReturnsOnCall[len(fake.waitForFolderIdleArgsForCall)]
+	fake.waitForFolderIdleArgsForCall = append(fake.waitForFolderIdleArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 time.Duration
+	}{arg1, arg2, arg3})
+	stub := fake.WaitForFolderIdleStub
+	fakeReturns := fake.waitForFolderIdleReturns
+	fake.recordInvocation("WaitForFolderIdle", []interface{}{arg1, arg2, arg3})
+	fake.waitForFolderIdleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Model) WaitForFolderIdleCallCount() int {
+	fake.waitForFolderIdleMutex.RLock()
+	defer fake.waitForFolderIdleMutex.RUnlock()
+	return len(fake.waitForFolderIdleArgsForCall)
+}
+
+func (fake *Model) WaitForFolderIdleCalls(stub func(context.Context, string, time.Duration) error) {
+	fake.waitForFolderIdleMutex.Lock()
+	defer fake.waitForFolderIdleMutex.Unlock()
+	fake.WaitForFolderIdleStub = stub
+}
+
+func (fake *Model) WaitForFolderIdleArgsForCall(i int) (context.Context, string, time.Duration) {
+	fake.waitForFolderIdleMutex.RLock()
+	defer fake.waitForFolderIdleMutex.RUnlock()
+	argsForCall := fake.waitForFolderIdleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *Model) WaitForFolderIdleReturns(result1 error) {
+	fake.waitForFolderIdleMutex.Lock()
+	defer fake.waitForFolderIdleMutex.Unlock()
+	fake.WaitForFolderIdleStub = nil
+	fake.waitForFolderIdleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Model) WaitForFolderIdleReturnsOnCall(i int, result1 error) {
+	fake.waitForFolderIdleMutex.Lock()
+	defer fake.waitForFolderIdleMutex.Unlock()
+	fake.WaitForFolderIdleStub = nil
+	if fake.waitForFolderIdleReturnsOnCall == nil {
+		fake.waitForFolderIdleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.waitForFolderIdleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *Model) WatchError(arg1 string) error {
 	fake.watchErrorMutex.Lock()
 	ret, specificReturn := fake.watchErrorReturnsOnCall[len(fake.watchErrorArgsForCall)]
@@ -63,10 +63,14 @@ type service interface {
 	Scan(subs []string) error
 	Errors() []FileError
 	WatchError() error
+	RescanInterval() time.Duration
 	ScheduleForceRescan(path string)
 	GetStatistics() (stats.FolderStatistics, error)
+	CleanTempFiles() (*TempCleanupReport, error)
 
 	getState() (folderState, time.Time, error)
+	mergeFrom(srcFs fs.Filesystem, report *FolderMergeReport) error
+	quarantineFile(name string, version protocol.Vector) error
 }
 
 type Availability struct {
@@ -87,6 +91,7 @@ type Model interface {
 	State(folder string) (string, time.Time, error)
 	FolderErrors(folder string) ([]FileError, error)
 	WatchError(folder string) error
+	RescanInterval(folder string) time.Duration
 	Override(folder string)
 	Revert(folder string)
 	BringToFront(folder, file string)
@@ -110,6 +115,14 @@ type Model interface {
 	NeedFolderFiles(folder string, page, perpage int) ([]protocol.FileInfo, []protocol.FileInfo, []protocol.FileInfo, error)
 	RemoteNeedFolderFiles(folder string, device protocol.DeviceID, page, perpage int) ([]protocol.FileInfo, error)
 	LocalChangedFolderFiles(folder string, page, perpage int) ([]protocol.FileInfo, error)
+	LocalChangeReport(folder string, page, perpage int, withDiff bool) ([]LocalChange, error)
+	PullPreview(folder string) (*PullPreview, error)
+	CleanTempFiles(folder string) (*TempCleanupReport, error)
+	MergeFolders(target, source string, dryRun bool) (*FolderMergeReport, error)
+	FolderAtTime(folder, prefix string, at time.Time, target string) (*FolderAtTimeReport, error)
+	Conflicts(folder string) ([]Conflict, error)
+	ConflictVersions(folder, conflictPath string) (protocol.FileInfo, protocol.FileInfo, error)
+	ResolveConflict(folder, conflictPath, keep string) error
 	FolderProgressBytesCompleted(folder string) int64
 
 	CurrentFolderFile(folder string, file string) (protocol.FileInfo, bool, error)
@@ -117,8 +130,44 @@ type Model interface {
 	Availability(folder string, file protocol.FileInfo, block protocol.BlockInfo) ([]Availability, error)
 
 	Completion(device protocol.DeviceID, folder string) (FolderCompletion, error)
+	PrefixCompletion(device protocol.DeviceID, folder, prefix string) (FolderCompletion, error)
+	WaitForFolderIdle(ctx context.Context, folder string, timeout time.Duration) error
+	RequestFolderPreview(ctx context.Context, device protocol.DeviceID, folder string, maxEntries int) (*protocol.FolderPreview, error)
+	IdentityChanges() map[protocol.DeviceID]IdentityChange
+	ApproveIdentityChange(device protocol.DeviceID) error
+	DismissIdentityChange(device protocol.DeviceID) error
+	QueueDeviceMessage(device protocol.DeviceID, kind, key string, payload []byte) error
+	QueuedDeviceMessages(device protocol.DeviceID) ([]QueuedMessage, error)
+	ChangeAnomalies() map[string]ChangeAnomaly
+	AcknowledgeChangeAnomaly(folder string) error
+	FolderCapabilities(folder string) (*FolderCapabilities, error)
+	ProbeFolderCapabilities(folder string) (*FolderCapabilities, error)
+	EncryptionRecoveryCode(folder string, device protocol.DeviceID) (string, error)
+	FolderDependencyWait(folder string) string
+	FolderQuotaExceeded(folder string) int64
+	FolderWriteVerification(folder string) (WriteVerificationStats, bool)
+	FolderQueuePosition(folder string) (string, int)
+	PendingConfigSync() ([]ConfigSyncChange, error)
+	ApproveConfigSync(device protocol.DeviceID, part, key string) error
+	RejectConfigSync(device protocol.DeviceID, part, key string) error
+	PromoteConfigSyncSpare(device protocol.DeviceID) ([]string, error)
+	StartFolderHandover(folder string, to protocol.DeviceID, after string) (FolderHandover, error)
+	FolderHandovers() ([]FolderHandover, error)
+	ClearFolderHandover(folder string) error
+	PendingFolderHandovers() ([]PendingFolderHandover, error)
+	ApproveFolderHandover(folder string, from protocol.DeviceID) error
+	RejectFolderHandover(folder string, from protocol.DeviceID) error
+	PendingOwnershipClaims() ([]PendingOwnershipClaim, error)
+	ApproveOwnershipClaim(folder string, device protocol.DeviceID) error
+	RejectOwnershipClaim(folder string, device protocol.DeviceID) error
+	RevokeDevice(device protocol.DeviceID, distrust bool) (DeviceRevocation, error)
+	DeviceRevocations() ([]DeviceRevocation, error)
+	PendingDeviceRevocations() ([]PendingDeviceRevocation, error)
+	ApproveDeviceRevocation(device protocol.DeviceID) (DeviceRevocation, error)
+	RejectDeviceRevocation(device protocol.DeviceID) error
 	ConnectionStats() map[string]interface{}
 	DeviceStatistics() (map[protocol.DeviceID]stats.DeviceStatistics, error)
+	DeviceAddressLedgers() (map[protocol.DeviceID][]stats.AddressLedgerEntry, error)
 	FolderStatistics() (map[string]stats.FolderStatistics, error)
 	UsageReportingStats(report *contract.Report, version int, preview bool)
 	ConnectedTo(remoteID protocol.DeviceID) bool
@@ -178,6 +227,24 @@ type model struct {
 	keyGen          *protocol.KeyGenerator
 	promotionTimer  *time.Timer
 	observed        *db.ObservedDB
+	// transferIntegrity tracks blocks from each device failing hash
+	// verification
+	transferIntegrity  *transferIntegrity
+	servedBlocks       *servedBlockCache
+	prefixCompletions  *prefixCompletionCache
+	conflicts          *conflictInbox
+	deviceQueue        *deviceQueue
+	changeAnomalies    *changeAnomalyDetector
+	configSync         *configSync
+	fsCapabilities     *fsCapabilityStore
+	dependencyWaits    *folderDependencyWaits
+	folderQuotas       *folderQuotas
+	writeVerifications *folderWriteVerifications
+	folderHandovers    *folderHandovers
+	deviceRevocations  *deviceRevocations
+	indexSubscriptions *indexSubscriptions
+	// folderSlots limits scans, initial syncs and hashers across folders.
+	folderSlots *folderSlots
 
 	// fields protected by mut
 	mut                            sync.RWMutex
@@ -189,15 +256,19 @@ type model struct {
 	folderVersioners               map[string]versioner.Versioner                         // folder -> versioner (may be nil)
 	folderEncryptionPasswordTokens map[string][]byte                                      // folder -> encryption token (may be missing, and only for encryption type folders)
 	folderEncryptionFailures       map[string]map[protocol.DeviceID]error                 // folder -> device -> error regarding encryption consistency (may be missing)
+	ownershipClaims                map[string]map[protocol.DeviceID][]string              // folder -> device -> prefixes the device announced owning
 	connections                    map[string]protocol.Connection                         // connection ID -> connection
 	deviceConnIDs                  map[protocol.DeviceID][]string                         // device -> connection IDs (invariant: if the key exists, the value is len >= 1, with the primary connection at the start of the slice)
 	promotedConnID                 map[protocol.DeviceID]string                           // device -> latest promoted connection ID
 	connRequestLimiters            map[protocol.DeviceID]*semaphore.Semaphore
+	pullSchedulers                 map[protocol.DeviceID]*pullScheduler
 	closed                         map[string]chan struct{} // connection ID -> closed channel
 	helloMessages                  map[protocol.DeviceID]protocol.Hello
 	deviceDownloads                map[protocol.DeviceID]*deviceDownloadState
 	remoteFolderStates             map[protocol.DeviceID]map[string]remoteFolderState // deviceID -> folders
 	indexHandlers                  *serviceMap[protocol.DeviceID, *indexHandlerRegistry]
+	identityChanges                map[protocol.DeviceID]IdentityChange // configured device -> certificate change seen
+	dirTombstones                  *dirTombstones
 
 	// Folder health monitoring
 	folderHealthMonitor *FolderHealthMonitor
@@ -224,6 +295,7 @@ var (
 	errNoVersioner      = errors.New("folder has no versioner")
 	// errors about why a connection is closed
 	errStopped                            = errors.New("Syncthing is being stopped") //nolint:staticcheck
+	errDeviceReconnect                    = errors.New("reconnecting to apply configuration changes")
 	errEncryptionInvConfigLocal           = errors.New("can't encrypt outgoing data because local data is encrypted (folder-type receive-encrypted)")
 	errEncryptionInvConfigRemote          = errors.New("remote has encrypted data and encrypts that data for us - this is impossible")
 	errEncryptionNotEncryptedLocal        = errors.New("remote expects to exchange encrypted data, but is configured for plain data")
@@ -258,11 +330,26 @@ func NewModel(cfg config.Wrapper, id protocol.DeviceID, sdb db.DB, protectedFile
 		shortID:              id.Short(),
 		globalRequestLimiter: semaphore.New(1024 * cfg.Options().MaxConcurrentIncomingRequestKiB()),
 		folderIOLimiter:      semaphore.New(cfg.Options().MaxFolderConcurrency()),
+		folderSlots:          newFolderSlots(cfg.Options()),
 		fatalChan:            make(chan error),
 		started:              make(chan struct{}),
 		keyGen:               keyGen,
 		promotionTimer:       time.NewTimer(0),
 		observed:             db.NewObservedDB(sdb),
+		transferIntegrity:    newTransferIntegrity(),
+		servedBlocks:         newServedBlockCache(),
+		prefixCompletions:    newPrefixCompletionCache(),
+		conflicts:            &conflictInbox{kv: sdb},
+		deviceQueue:          newDeviceQueue(sdb),
+		changeAnomalies:      newChangeAnomalyDetector(),
+		configSync:           newConfigSync(sdb),
+		folderHandovers:      newFolderHandovers(sdb),
+		deviceRevocations:    newDeviceRevocations(sdb),
+		indexSubscriptions:   newIndexSubscriptions(sdb),
+		fsCapabilities:       &fsCapabilityStore{kv: sdb},
+		dependencyWaits:      newFolderDependencyWaits(),
+		folderQuotas:         newFolderQuotas(),
+		writeVerifications:   newFolderWriteVerifications(),
 
 		// fields protected by mut
 		folderCfgs:                     make(map[string]config.FolderConfiguration),
@@ -272,21 +359,34 @@ func NewModel(cfg config.Wrapper, id protocol.DeviceID, sdb db.DB, protectedFile
 		folderVersioners:               make(map[string]versioner.Versioner),
 		folderEncryptionPasswordTokens: make(map[string][]byte),
 		folderEncryptionFailures:       make(map[string]map[protocol.DeviceID]error),
+		ownershipClaims:                make(map[string]map[protocol.DeviceID][]string),
 		connections:                    make(map[string]protocol.Connection),
 		deviceConnIDs:                  make(map[protocol.DeviceID][]string),
 		promotedConnID:                 make(map[protocol.DeviceID]string),
 		connRequestLimiters:            make(map[protocol.DeviceID]*semaphore.Semaphore),
+		pullSchedulers:                 make(map[protocol.DeviceID]*pullScheduler),
 		closed:                         make(map[string]chan struct{}),
 		helloMessages:                  make(map[protocol.DeviceID]protocol.Hello),
 		deviceDownloads:                make(map[protocol.DeviceID]*deviceDownloadState),
 		remoteFolderStates:             make(map[protocol.DeviceID]map[string]remoteFolderState),
 		indexHandlers:                  newServiceMap[protocol.DeviceID, *indexHandlerRegistry](evLogger),
+		identityChanges:                make(map[protocol.DeviceID]IdentityChange),
 	}
 	for devID, cfg := range cfg.Devices() {
 		m.deviceStatRefs[devID] = stats.NewDeviceStatisticsReference(db.NewTyped(sdb, "devicestats/"+devID.String()))
 		m.setConnRequestLimitersLocked(cfg)
 	}
 
+	m.dirTombstones = newDirTombstones(func(folder string) {
+		// Files held back by the tombstones may be pulled now.
+		m.mut.RLock()
+		runner, ok := m.folderRunners.Get(folder)
+		m.mut.RUnlock()
+		if ok {
+			runner.SchedulePull()
+		}
+	})
+
 	// Initialize folder health monitor
 	m.folderHealthMonitor = NewFolderHealthMonitor(cfg, m, evLogger)
 	m.Add(m.folderHealthMonitor)
@@ -295,6 +395,7 @@ func NewModel(cfg config.Wrapper, id protocol.DeviceID, sdb db.DB, protectedFile
 	m.Add(m.progressEmitter)
 	m.Add(m.indexHandlers)
 	m.Add(svcutil.AsService(m.serve, m.String()))
+	m.Add(svcutil.AsService(m.serveFolderHandovers, fmt.Sprintf("%s/serveFolderHandovers", m)))
 
 	return m
 }
@@ -311,6 +412,10 @@ func (m *model) serve(ctx context.Context) error {
 	}
 
 	close(m.started)
+	m.sendConfigSync()
+
+	standbyExpiry := time.NewTicker(standbyIndexExpiryInterval)
+	defer standbyExpiry.Stop()
 
 	for {
 		select {
@@ -323,6 +428,8 @@ func (m *model) serve(ctx context.Context) error {
 		case <-m.promotionTimer.C:
 			slog.Debug("Promotion timer fired")
 			m.promoteConnections()
+		case <-standbyExpiry.C:
+			m.expireStandbyIndexes()
 		}
 	}
 }
@@ -377,6 +484,8 @@ func (m *model) addAndStartFolderLocked(cfg config.FolderConfiguration, cacheIgn
 		}
 	}
 
+	m.ensureFolderCapabilities(cfg)
+
 	m.addAndStartFolderLockedWithIgnores(cfg, ignores)
 }
 
@@ -398,6 +507,8 @@ func (m *model) addAndStartFolderLockedWithIgnores(cfg config.FolderConfiguratio
 
 	folder := cfg.ID
 
+	m.restoreFolderFromStandby(cfg)
+
 	// Find any devices for which we hold the index in the db, but the folder
 	// is not shared, and drop it.
 	expected := mapDevices(cfg.DeviceIDs())
@@ -452,8 +563,15 @@ func (m *model) addAndStartFolderLockedWithIgnores(cfg config.FolderConfiguratio
 	m.folderVersioners[folder] = ver
 
 	m.warnAboutOverwritingProtectedFiles(cfg, ignores)
+	m.updateIndexSubscription(cfg, ignores)
+
+	// The folder runs with what its filesystem doesn't support turned off.
+	runCfg := cfg
+	if caps, ok := m.fsCapabilities.current(cfg); ok {
+		runCfg, _ = adjustToCapabilities(cfg, caps.Capabilities)
+	}
 
-	p := folderFactory(m, ignores, cfg, ver, m.evLogger, m.folderIOLimiter)
+	p := folderFactory(m, ignores, runCfg, ver, m.evLogger, m.folderIOLimiter)
 	m.folderRunners.Add(folder, p)
 
 	slog.Info("Ready to synchronize", cfg.LogAttr())
@@ -500,6 +618,12 @@ func (m *model) removeFolder(cfg config.FolderConfiguration) {
 	wait := m.folderRunners.StopAndWaitChan(cfg.ID, 0)
 	m.mut.RUnlock()
 	<-wait
+	m.changeAnomalies.forget(cfg.ID)
+	m.fsCapabilities.forget(cfg.ID)
+
+	// Keeping the index on standby needs the marker to recognise the
+	// folder when it's added again.
+	standby := m.putFolderOnStandby(cfg)
 
 	m.mut.Lock()
 
@@ -510,7 +634,7 @@ func (m *model) removeFolder(cfg config.FolderConfiguration) {
 			break
 		}
 	}
-	if isPathUnique {
+	if isPathUnique && !standby {
 		// Remove (if empty and removable) or move away (if non-empty or
 		// otherwise not removable) Syncthing-specific marker files.
 		if err := cfg.RemoveMarker(); err != nil && !errors.Is(err, os.ErrNotExist) {
@@ -529,7 +653,10 @@ func (m *model) removeFolder(cfg config.FolderConfiguration) {
 	m.mut.Unlock()
 
 	// Remove it from the database
-	_ = m.sdb.DropFolder(cfg.ID)
+	if !standby {
+		_ = m.sdb.DropFolder(cfg.ID)
+		m.indexSubscriptions.forget(cfg.ID)
+	}
 }
 
 // Need to hold lock on m.mut when calling this.
@@ -541,6 +668,7 @@ func (m *model) cleanupFolderLocked(cfg config.FolderConfiguration) {
 	delete(m.folderVersioners, cfg.ID)
 	delete(m.folderEncryptionPasswordTokens, cfg.ID)
 	delete(m.folderEncryptionFailures, cfg.ID)
+	delete(m.ownershipClaims, cfg.ID)
 }
 
 func (m *model) restartFolder(from, to config.FolderConfiguration, cacheIgnoredFiles bool) error {
@@ -726,9 +854,10 @@ func (m *model) UsageReportingStats(report *contract.Report, version int, previe
 type ConnectionStats struct {
 	protocol.Statistics // Total for primary + secondaries
 
-	Connected     bool   `json:"connected"`
-	Paused        bool   `json:"paused"`
-	ClientVersion string `json:"clientVersion"`
+	Connected       bool   `json:"connected"`
+	Paused          bool   `json:"paused"`
+	ClientVersion   string `json:"clientVersion"`
+	IdentityChanged bool   `json:"identityChanged"` // the device presents a new, unapproved certificate
 
 	Address string `json:"address"` // mirror values from Primary, for compatibility with <1.24.0
 	Type    string `json:"type"`    // mirror values from Primary, for compatibility with <1.24.0
@@ -741,10 +870,11 @@ type ConnectionStats struct {
 
 type ConnectionInfo struct {
 	protocol.Statistics
-	Address string `json:"address"`
-	Type    string `json:"type"`
-	IsLocal bool   `json:"isLocal"`
-	Crypto  string `json:"crypto"`
+	Address   string                         `json:"address"`
+	Type      string                         `json:"type"`
+	IsLocal   bool                           `json:"isLocal"`
+	Crypto    string                         `json:"crypto"`
+	Bandwidth *connections.BandwidthEstimate `json:"bandwidth,omitempty"`
 }
 
 // ConnectionStats returns a map with connection statistics for each device.
@@ -755,6 +885,16 @@ func (m *model) ConnectionStats() map[string]interface{} {
 	res := make(map[string]interface{})
 	devs := m.cfg.Devices()
 	conns := make(map[string]ConnectionStats, len(devs))
+	var bandwidth map[string]connections.BandwidthEstimate
+	if m.connectionsService != nil {
+		bandwidth = m.connectionsService.BandwidthEstimates()
+	}
+	bandwidthFor := func(conn protocol.Connection) *connections.BandwidthEstimate {
+		if est, ok := bandwidth[conn.ConnectionID()]; ok {
+			return &est
+		}
+		return nil
+	}
 	for device, deviceCfg := range devs {
 		if device == m.id {
 			continue
@@ -765,10 +905,12 @@ func (m *model) ConnectionStats() map[string]interface{} {
 			versionString = hello.ClientName + " " + hello.ClientVersion
 		}
 		connIDs, ok := m.deviceConnIDs[device]
+		_, identityChanged := m.identityChanges[device]
 		cs := ConnectionStats{
-			Connected:     ok,
-			Paused:        deviceCfg.Paused,
-			ClientVersion: strings.TrimSpace(versionString),
+			Connected:       ok,
+			Paused:          deviceCfg.Paused,
+			ClientVersion:   strings.TrimSpace(versionString),
+			IdentityChanged: identityChanged,
 		}
 		if ok {
 			conn := m.connections[connIDs[0]]
@@ -778,6 +920,7 @@ func (m *model) ConnectionStats() map[string]interface{} {
 			cs.Primary.Crypto = conn.Crypto()
 			cs.Primary.Statistics = conn.Statistics()
 			cs.Primary.Address = conn.RemoteAddr().String()
+			cs.Primary.Bandwidth = bandwidthFor(conn)
 
 			cs.Type = cs.Primary.Type
 			cs.IsLocal = cs.Primary.IsLocal
@@ -793,6 +936,7 @@ func (m *model) ConnectionStats() map[string]interface{} {
 					Type:       conn.Type(),
 					IsLocal:    conn.IsLocal(),
 					Crypto:     conn.Crypto(),
+					Bandwidth:  bandwidthFor(conn),
 				}
 				if sec.At.After(cs.At) {
 					cs.At = sec.At
@@ -835,11 +979,30 @@ func (m *model) DeviceStatistics() (map[protocol.DeviceID]stats.DeviceStatistics
 			// now.
 			stats.LastSeen = time.Now().Truncate(time.Second)
 		}
+		verified, corrupt, flagged := m.transferIntegrity.unflushed(id)
+		stats.BlocksVerified += verified
+		stats.BlocksCorrupt += corrupt
+		stats.CorruptionFlagged = flagged
 		res[id] = stats
 	}
 	return res, nil
 }
 
+// DeviceAddressLedgers returns the addresses each device was seen at
+func (m *model) DeviceAddressLedgers() (map[protocol.DeviceID][]stats.AddressLedgerEntry, error) {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+	res := make(map[protocol.DeviceID][]stats.AddressLedgerEntry, len(m.deviceStatRefs))
+	for id, sr := range m.deviceStatRefs {
+		ledger, err := sr.GetAddressLedger()
+		if err != nil {
+			return nil, err
+		}
+		res[id] = ledger
+	}
+	return res, nil
+}
+
 // FolderStatistics returns statistics about each folder
 func (m *model) FolderStatistics() (map[string]stats.FolderStatistics, error) {
 	res := make(map[string]stats.FolderStatistics)
@@ -1090,7 +1253,7 @@ func (m *model) NeedFolderFiles(folder string, page, perpage int) ([]protocol.Fi
 		rest = make([]protocol.FileInfo, 0, p.get)
 		it, errFn := m.sdb.AllNeededGlobalFiles(folder, protocol.LocalDeviceID, config.PullOrderAlphabetic, 0, 0)
 		for f := range it {
-			if cfg.IgnoreDelete && f.IsDeleted() {
+			if cfg.IgnoresIncomingDeletes() && f.IsDeleted() {
 				continue
 			}
 
@@ -1235,9 +1398,27 @@ func (m *model) IndexUpdate(conn protocol.Connection, idxUp *protocol.IndexUpdat
 	dbLimiter.Take(1)
 	defer dbLimiter.Give(1)
 	
+	if idxUp.Tombstones {
+		return m.handleDirTombstones(conn, idxUp)
+	}
+	m.dirTombstones.resolved(idxUp.Folder, conn.DeviceID(), idxUp.Files)
 	return m.handleIndex(conn, &protocol.Index{Folder: idxUp.Folder, Files: idxUp.Files}, true)
 }
 
+// handleDirTombstones remembers the directories announced as deleted, ahead
+// of the index updates deleting them and their contents.
+func (m *model) handleDirTombstones(conn protocol.Connection, idxUp *protocol.IndexUpdate) error {
+	deviceID := conn.DeviceID()
+	fcfg, ok := m.cfg.Folder(idxUp.Folder)
+	if !ok || !fcfg.SharedWith(deviceID) {
+		l.Debugf("Ignoring directory tombstones for folder %q not shared with %v", idxUp.Folder, deviceID)
+		return nil
+	}
+	l.Debugf("Received %d directory tombstones for folder %q from %v", len(idxUp.Files), idxUp.Folder, deviceID)
+	m.dirTombstones.add(idxUp.Folder, deviceID, idxUp.Files)
+	return nil
+}
+
 // handleIndex processes both full index and index update messages
 func (m *model) handleIndex(conn protocol.Connection, idx *protocol.Index, update bool) error {
 	deviceID := conn.DeviceID()
@@ -1267,7 +1448,8 @@ func (m *model) handleIndex(conn protocol.Connection, idx *protocol.Index, updat
 }
 
 type clusterConfigDeviceInfo struct {
-	local, remote protocol.Device
+	local, remote   protocol.Device
+	twoPhaseDeletes bool
 }
 
 // ClusterConfig is called when a cluster configuration message is received from a peer device.
@@ -1309,7 +1491,7 @@ func (m *model) ClusterConfig(conn protocol.Connection, cm *protocol.ClusterConf
 	// Parse the cluster config information for each folder.
 	ccDeviceInfos := make(map[string]*clusterConfigDeviceInfo, len(cm.Folders))
 	for _, folder := range cm.Folders {
-		info := &clusterConfigDeviceInfo{}
+		info := &clusterConfigDeviceInfo{twoPhaseDeletes: cm.HasFeature(protocol.FeatureTwoPhaseDeletes)}
 		for _, device := range folder.Devices {
 			switch device.ID {
 			case m.id:
@@ -1561,6 +1743,7 @@ func (m *model) ccHandleFolders(folders []protocol.Folder, deviceCfg config.Devi
 				delete(m.folderEncryptionFailures[folder.ID], deviceID)
 			}
 		}
+		m.setOwnershipClaimLocked(folder.ID, deviceID, ccDeviceInfos[folder.ID].remote.OwnedPrefixes)
 		m.mut.Unlock()
 
 		// Handle indexes
@@ -1652,6 +1835,9 @@ func (m *model) ccCheckEncryption(fcfg config.FolderConfiguration, folderDevice
 		if !match {
 			return errEncryptionPassword
 		}
+		if err := rememberDeviceToken(fcfg, folderDevice.DeviceID, passwordToken); err != nil {
+			l.Debugf("Remembering encryption token of %v for %s: %v", folderDevice.DeviceID, fcfg.Description(), err)
+		}
 		return nil
 	}
 
@@ -1763,6 +1949,11 @@ func (m *model) handleIntroductions(introducerCfg config.DeviceConfiguration, cm
 				continue
 			}
 
+			// Revoked devices aren't brought back by the introducer.
+			if m.deviceRevocations.revoked(device.ID) {
+				continue
+			}
+
 			foldersDevices.set(device.ID, folder.ID)
 
 			if _, ok := devices[device.ID]; !ok {
@@ -1988,6 +2179,7 @@ func (m *model) Closed(conn protocol.Connection, err error) {
 		delete(m.deviceConnIDs, deviceID)
 		delete(m.promotedConnID, deviceID)
 		delete(m.connRequestLimiters, deviceID)
+		delete(m.pullSchedulers, deviceID)
 		delete(m.helloMessages, deviceID)
 		delete(m.remoteFolderStates, deviceID)
 		delete(m.deviceDownloads, deviceID)
@@ -2010,6 +2202,7 @@ func (m *model) Closed(conn protocol.Connection, err error) {
 
 	if len(remainingConns) == 0 {
 		slog.Info("Connection closed", deviceID.LogAttr(), slog.Any("connection", conn), slogutil.Error(err))
+		m.dirTombstones.forgetDevice(deviceID)
 		m.evLogger.Log(events.DeviceDisconnected, map[string]string{
 			"id":    deviceID.String(),
 			"error": err.Error(),
@@ -2126,6 +2319,12 @@ func (m *model) Request(conn protocol.Connection, req *protocol.Request) (out pr
 		return nil, protocol.ErrInvalid
 	}
 
+	// Pace the responses for the folder to its bandwidth limit, before
+	// taking any of the limits shared with other folders.
+	if m.connectionsService != nil && deviceID != protocol.LocalDeviceID {
+		_ = m.connectionsService.LimitFolderSend(context.TODO(), req.Folder, req.Size)
+	}
+
 	// Restrict parallel requests by connection/device
 
 	m.mut.RLock()
@@ -2212,6 +2411,16 @@ func (m *model) Request(conn protocol.Connection, req *protocol.Request) (out pr
 		return nil, protocol.ErrGeneric
 	}
 
+	if folderCfg.Type != config.FolderTypeReceiveEncrypted && m.cfg.Options().VerifyServedBlocks {
+		switch m.verifyServedBlock(folderCfg, folderFs, req, res.data[:n]) {
+		case servedBlockIntact:
+			return res, nil
+		case servedBlockCorrupt:
+			return nil, protocol.ErrNoSuchFile
+		}
+		// Not verifiable against the index, validate against the request
+	}
+
 	if folderCfg.Type != config.FolderTypeReceiveEncrypted && len(req.Hash) > 0 && !scanner.Validate(res.data[:n], req.Hash) {
 		m.recheckFile(deviceID, req.Folder, req.Name, req.Offset, req.Hash)
 		l.Debugf("%v REQ(in) failed validating data: %s: %q / %q o=%d s=%d", m, deviceID.Short(), req.Folder, req.Name, req.Offset, req.Size)
@@ -2437,9 +2646,14 @@ func (m *model) AddConnection(conn protocol.Connection, hello protocol.Hello) {
 	m.closed[connID] = closed
 	m.helloMessages[deviceID] = hello
 	m.deviceConnIDs[deviceID] = append(m.deviceConnIDs[deviceID], connID)
+	// The device connected with its pinned certificate after all.
+	delete(m.identityChanges, deviceID)
 	if m.deviceDownloads[deviceID] == nil {
 		m.deviceDownloads[deviceID] = newDeviceDownloadState()
 	}
+	if m.pullSchedulers[deviceID] == nil {
+		m.pullSchedulers[deviceID] = newPullScheduler(deviceID, 1024*m.cfg.Options().MaxPullPendingPerDeviceKiB())
+	}
 
 	event := map[string]string{
 		"id":            deviceID.String(),
@@ -2456,7 +2670,8 @@ func (m *model) AddConnection(conn protocol.Connection, hello protocol.Hello) {
 
 	m.evLogger.Log(events.DeviceConnected, event)
 
-	if len(m.deviceConnIDs[deviceID]) == 1 {
+	firstConn := len(m.deviceConnIDs[deviceID]) == 1
+	if firstConn {
 		slog.Info("New device connection", deviceID.LogAttr(), slogutil.Address(conn.RemoteAddr()), slog.Group("remote", slog.String("name", hello.DeviceName), slog.String("client", hello.ClientName), slog.String("version", hello.ClientVersion)))
 	} else {
 		slog.Info("Additional device connection", deviceID.LogAttr(), slogutil.Address(conn.RemoteAddr()), slog.Int("count", len(m.deviceConnIDs[deviceID])-1))
@@ -2477,7 +2692,15 @@ func (m *model) AddConnection(conn protocol.Connection, hello protocol.Hello) {
 		})
 	}
 
+	if deviceCfg.Replaces != protocol.EmptyDeviceID {
+		m.retireReplacedDevice(deviceID)
+	}
+
+	if firstConn {
+		m.checkConnectionAnomalies(deviceCfg, conn, hello)
+	}
 	m.deviceWasSeen(deviceID)
+	m.deviceSawAddress(deviceID, conn)
 	m.scheduleConnectionPromotion()
 }
 
@@ -2518,6 +2741,7 @@ func (m *model) promoteConnections() {
 					conn.Start()
 				}
 				conn.ClusterConfig(cm, passwords)
+				go m.deliverQueuedMessages(deviceID, conn)
 			})
 			m.promotedConnID[deviceID] = connIDs[0]
 		}
@@ -2571,46 +2795,62 @@ func (m *model) deviceWasSeen(deviceID protocol.DeviceID) {
 	}
 }
 
+// deviceSawAddress adds the remote address of the connection to the
+// address ledger of the device. For relayed connections that's the address
+// of the relay.
+func (m *model) deviceSawAddress(deviceID protocol.DeviceID, conn protocol.Connection) {
+	addr := conn.RemoteAddr()
+	if addr == nil {
+		return
+	}
+	address := addr.String()
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		// The port of an incoming connection is an arbitrary one.
+		address = host
+	}
+	m.mut.RLock()
+	sr, ok := m.deviceStatRefs[deviceID]
+	m.mut.RUnlock()
+	if ok {
+		if err := sr.SawAddress(address, conn.Transport()); err != nil {
+			slog.Warn("Failed to record address of device", deviceID.LogAttr(), slogutil.Error(err))
+		}
+	}
+}
+
 func (m *model) deviceDidCloseRLocked(deviceID protocol.DeviceID, duration time.Duration) {
 	if sr, ok := m.deviceStatRefs[deviceID]; ok {
 		_ = sr.LastConnectionDuration(duration)
 		_ = sr.WasSeen()
+		m.transferIntegrity.flush(deviceID, sr)
 	}
 }
 
 // requestConnectionForDevice returns a connection to the given device, to
-// be used for sending a request. If there is only one device connection,
+// be used for sending a request of the given size, and a function to call
+// with the outcome of the request. If there is only one device connection,
 // this is the one to use. If there are multiple then we avoid the first
 // ("primary") connection, which is dedicated to index data, and pick a
 // random one of the others.
-// When multipath is enabled, we use the PacketScheduler to select the best connection.
-func (m *model) requestConnectionForDevice(deviceID protocol.DeviceID) (protocol.Connection, bool) {
+// When multipath is enabled, the PacketScheduler stripes the requests over
+// all connections instead.
+func (m *model) requestConnectionForDevice(deviceID protocol.DeviceID, size int) (protocol.Connection, func(error), bool) {
 	m.mut.RLock()
 	defer m.mut.RUnlock()
 
-	// Check if multipath is enabled and we have a connections service with PacketScheduler
+	connIDs, ok := m.deviceConnIDs[deviceID]
+	if !ok {
+		return nil, nil, false
+	}
+
 	if m.connectionsService != nil {
-		// Get the configuration from the connections service to ensure consistency
 		if packetScheduler := m.connectionsService.PacketScheduler(); packetScheduler != nil {
-			// Try to select a connection for load balancing (link aggregation mode)
-			conn := packetScheduler.SelectConnectionForLoadBalancing(deviceID)
-			if conn != nil {
-				return conn, true
-			}
-			// If load balancing fails, try failover mode
-			conn = packetScheduler.SelectConnection(deviceID)
-			if conn != nil {
-				return conn, true
+			if conn, done := packetScheduler.StripeRequest(deviceID, size, connIDs[0]); conn != nil {
+				return conn, done, true
 			}
 		}
 	}
 
-	// Fall back to the original logic if multipath is not enabled or PacketScheduler is not available
-	connIDs, ok := m.deviceConnIDs[deviceID]
-	if !ok {
-		return nil, false
-	}
-
 	// If there is an entry in deviceConns, it always contains at least one
 	// connection.
 	connID := connIDs[0]
@@ -2621,17 +2861,42 @@ func (m *model) requestConnectionForDevice(deviceID protocol.DeviceID) (protocol
 	}
 
 	conn, connOK := m.connections[connID]
-	return conn, connOK
+	return conn, func(error) {}, connOK
 }
 
 func (m *model) RequestGlobal(ctx context.Context, deviceID protocol.DeviceID, folder, name string, blockNo int, offset int64, size int, hash []byte, fromTemporary bool) ([]byte, error) {
-	conn, connOK := m.requestConnectionForDevice(deviceID)
+	// The folder's own bandwidth limit comes first, so that a limited
+	// folder waits without holding a share of the device's requests.
+	if m.connectionsService != nil {
+		if err := m.connectionsService.LimitFolderRecv(ctx, folder, size); err != nil {
+			return nil, err
+		}
+	}
+
+	// Wait for our turn among the folders pulling from this device.
+	m.mut.RLock()
+	sched := m.pullSchedulers[deviceID]
+	weight := m.folderCfgs[folder].PullWeight
+	m.mut.RUnlock()
+	if sched != nil {
+		done, err := sched.take(ctx, folder, weight, size)
+		if err != nil {
+			return nil, err
+		}
+		defer done()
+	}
+
+	// The connection is selected once it's our turn, as striping accounts
+	// for the request on it from then on.
+	conn, requestDone, connOK := m.requestConnectionForDevice(deviceID, size)
 	if !connOK {
 		return nil, fmt.Errorf("requestGlobal: no connection to device: %s", deviceID.Short())
 	}
 
 	l.Debugf("%v REQ(out): %s (%s): %q / %q b=%d o=%d s=%d h=%x ft=%t", m, deviceID.Short(), conn, folder, name, blockNo, offset, size, hash, fromTemporary)
-	return conn.Request(ctx, &protocol.Request{Folder: folder, Name: name, BlockNo: blockNo, Offset: offset, Size: size, Hash: hash, FromTemporary: fromTemporary})
+	data, err := conn.Request(ctx, &protocol.Request{Folder: folder, Name: name, BlockNo: blockNo, Offset: offset, Size: size, Hash: hash, FromTemporary: fromTemporary})
+	requestDone(err)
+	return data, err
 }
 
 func (m *model) ScanFolders() map[string]error {
@@ -2734,7 +2999,7 @@ func (m *model) generateClusterConfig(device protocol.DeviceID) (*protocol.Clust
 }
 
 func (m *model) generateClusterConfigRLocked(device protocol.DeviceID) (*protocol.ClusterConfig, map[string]string) {
-	message := &protocol.ClusterConfig{}
+	message := &protocol.ClusterConfig{Features: []string{protocol.FeatureTwoPhaseDeletes}}
 	folders := m.cfg.FolderList()
 	passwords := make(map[string]string, len(folders))
 	for _, folderCfg := range folders {
@@ -2772,6 +3037,8 @@ func (m *model) generateClusterConfigRLocked(device protocol.DeviceID) (*protoco
 				Compression: deviceCfg.Compression.ToProtocol(),
 				CertName:    deviceCfg.CertName,
 				Introducer:  deviceCfg.Introducer,
+
+				OwnedPrefixes: folderDevice.OwnedPrefixes,
 			}
 
 			if deviceCfg.DeviceID == m.id && hasEncryptionToken {
@@ -2786,6 +3053,7 @@ func (m *model) generateClusterConfigRLocked(device protocol.DeviceID) (*protoco
 			if deviceCfg.DeviceID == m.id {
 				protocolDevice.IndexID, _ = m.sdb.GetIndexID(folderCfg.ID, protocol.LocalDeviceID)
 				protocolDevice.MaxSequence, _ = m.sdb.GetDeviceSequence(folderCfg.ID, protocol.LocalDeviceID)
+				protocolDevice.IgnoredPrefixes = m.indexSubscriptions.hashes(folderCfg.ID)
 			} else {
 				protocolDevice.IndexID, _ = m.sdb.GetIndexID(folderCfg.ID, deviceCfg.DeviceID)
 				protocolDevice.MaxSequence, _ = m.sdb.GetDeviceSequence(folderCfg.ID, deviceCfg.DeviceID)
@@ -2836,6 +3104,18 @@ func (m *model) WatchError(folder string) error {
 	return runner.WatchError()
 }
 
+// RescanInterval returns the current interval between periodic full scans
+// of the folder, which may differ from the configured one when adapted.
+func (m *model) RescanInterval(folder string) time.Duration {
+	m.mut.RLock()
+	runner, ok := m.folderRunners.Get(folder)
+	m.mut.RUnlock()
+	if !ok {
+		return 0
+	}
+	return runner.RescanInterval()
+}
+
 func (m *model) Override(folder string) {
 	// Grab the runner and the file set.
 
@@ -3106,7 +3386,7 @@ func (m *model) String() string {
 	return fmt.Sprintf("model@%p", m)
 }
 
-func (*model) VerifyConfiguration(from, to config.Configuration) error {
+func (m *model) VerifyConfiguration(from, to config.Configuration) error {
 	toFolders := to.FolderMap()
 	for _, from := range from.Folders {
 		to, ok := toFolders[from.ID]
@@ -3115,6 +3395,10 @@ func (*model) VerifyConfiguration(from, to config.Configuration) error {
 		}
 	}
 
+	if err := m.verifyEncryptionPasswords(from, to); err != nil {
+		return err
+	}
+
 	// Verify that any requested versioning is possible to construct, or we
 	// will panic later when starting the folder.
 	for _, to := range to.Folders {
@@ -3138,6 +3422,7 @@ func (m *model) CommitConfiguration(from, to config.Configuration) bool {
 	// Tracks devices affected by any configuration change to resend ClusterConfig.
 	clusterConfigDevices := make(deviceIDSet, len(from.Devices)+len(to.Devices))
 	closeDevices := make([]protocol.DeviceID, 0, len(to.Devices))
+	reconnectDevices := make([]protocol.DeviceID, 0, len(to.Devices))
 
 	fromFolders := mapFolders(from.Folders)
 	toFolders := mapFolders(to.Folders)
@@ -3190,7 +3475,7 @@ func (m *model) CommitConfiguration(from, to config.Configuration) bool {
 				_, ok := m.folderEncryptionPasswordTokens[toCfg.ID]
 				m.mut.RUnlock()
 				if !ok {
-					closeDevices = append(closeDevices, toCfg.DeviceIDs()...)
+					reconnectDevices = append(reconnectDevices, toCfg.DeviceIDs()...)
 				} else {
 					clusterConfigDevices.add(toCfg.DeviceIDs())
 				}
@@ -3231,7 +3516,7 @@ func (m *model) CommitConfiguration(from, to config.Configuration) bool {
 		} else {
 			// Ignored folder was removed, reconnect to retrigger the prompt.
 			if len(fromCfg.IgnoredFolders) > len(toCfg.IgnoredFolders) {
-				closeDevices = append(closeDevices, deviceID)
+				reconnectDevices = append(reconnectDevices, deviceID)
 			}
 
 			slog.Info("Resuming device", deviceID.LogAttr())
@@ -3272,15 +3557,45 @@ func (m *model) CommitConfiguration(from, to config.Configuration) bool {
 			}
 		}
 	}
+	// Devices to reconnect to are left with a connection throughout, by
+	// the connection service replacing their connections one at a time.
+	reconnect := make([]protocol.DeviceID, 0, len(reconnectDevices))
+	for _, id := range reconnectDevices {
+		if slices.Contains(reconnect, id) || slices.Contains(closeDevices, id) || slices.Contains(removedDevices, id) {
+			continue
+		}
+		delete(clusterConfigDevices, id)
+		reconnect = append(reconnect, id)
+	}
+	connectionsService := m.connectionsService
+	if connectionsService == nil {
+		for _, id := range reconnect {
+			for _, connID := range m.deviceConnIDs[id] {
+				go m.connections[connID].Close(errDeviceReconnect)
+			}
+		}
+	}
 	m.mut.RUnlock()
+	if connectionsService != nil {
+		for _, id := range reconnect {
+			connectionsService.ReconnectDevice(id, errDeviceReconnect)
+		}
+	}
 	// Generating cluster-configs acquires the mutex.
 	m.sendClusterConfig(clusterConfigDevices.AsSlice())
 
 	ignoredDevices := observedDeviceSet(to.IgnoredDevices)
 	m.cleanPending(toDevices, toFolders, ignoredDevices, removedFolders)
+	m.sendConfigSync()
 
 	m.globalRequestLimiter.SetCapacity(1024 * to.Options.MaxConcurrentIncomingRequestKiB())
 	m.folderIOLimiter.SetCapacity(to.Options.MaxFolderConcurrency())
+	m.folderSlots.setCapacities(to.Options)
+	m.mut.RLock()
+	for _, sched := range m.pullSchedulers {
+		sched.setCapacity(1024 * to.Options.MaxPullPendingPerDeviceKiB())
+	}
+	m.mut.RUnlock()
 
 	// Some options don't require restart as those components handle it fine
 	// by themselves. Compare the options structs containing only the
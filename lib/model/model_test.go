@@ -3150,6 +3150,53 @@ func TestSummaryPausedNoError(t *testing.T) {
 	}
 }
 
+func TestSummariesSnapshot(t *testing.T) {
+	wcfg, fcfg, wcfgCancel := newDefaultCfgWrapper()
+	defer wcfgCancel()
+	m := setupModel(t, wcfg)
+	defer cleanupModel(m)
+
+	fss := NewFolderSummaryService(wcfg, m, myID, events.NoopLogger).(*folderSummaryService)
+	snap := mustV(fss.Summaries(t.Context()))
+	if _, ok := snap.Folders[fcfg.ID]; !ok || snap.EventID != 0 {
+		t.Fatalf("unexpected snapshot %+v", snap)
+	}
+
+	// Mark the cached summary, to tell whether it is reused.
+	fss.cache[fcfg.ID].summary.GlobalFiles = 42
+	snap = mustV(fss.Summaries(t.Context()))
+	if snap.Folders[fcfg.ID].GlobalFiles != 42 {
+		t.Error("expected the cached summary to be reused")
+	}
+
+	// An index update makes the cached summary stale. It's still served,
+	// at the event it reflects, until the service gets to recalculating it.
+	ev := events.Event{GlobalID: 1, Type: events.LocalIndexUpdated, Data: map[string]interface{}{"folder": fcfg.ID}}
+	fss.processUpdate(ev)
+	fss.lastEventID = ev.GlobalID
+	snap = mustV(fss.Summaries(t.Context()))
+	if snap.Folders[fcfg.ID].GlobalFiles != 42 || snap.EventID != 0 {
+		t.Errorf("expected the stale summary at event 0, got %+v", snap)
+	}
+	if folders := fss.foldersToHandle(); len(folders) != 1 || folders[0] != fcfg.ID {
+		t.Fatalf("expected the folder to be due for recalculation, got %v", folders)
+	}
+	fss.sendSummary(t.Context(), fcfg.ID)
+	snap = mustV(fss.Summaries(t.Context()))
+	if snap.Folders[fcfg.ID].GlobalFiles == 42 || snap.EventID != 1 {
+		t.Errorf("expected a recalculated summary at event 1, got %+v", snap)
+	}
+
+	// Removing the folder forgets its summary.
+	from := wcfg.RawCopy()
+	to := from.Copy()
+	to.Folders = nil
+	fss.CommitConfiguration(from, to)
+	if _, ok := fss.cache[fcfg.ID]; ok {
+		t.Error("expected the summary of the removed folder to be evicted")
+	}
+}
+
 func TestFolderAPIErrors(t *testing.T) {
 	wcfg, fcfg, wcfgCancel := newDefaultCfgWrapper()
 	defer wcfgCancel()
@@ -3664,6 +3711,38 @@ func TestCompletionEmptyGlobal(t *testing.T) {
 	}
 }
 
+func TestPrefixCompletion(t *testing.T) {
+	m, conn, fcfg, wcfgCancel := setupModelWithConnection(t)
+	defer wcfgCancel()
+	defer cleanupModelAndRemoveDir(m, fcfg.Filesystem().URI())
+
+	version := protocol.Vector{}.Update(device1.Short())
+	files := []protocol.FileInfo{
+		{Name: "proj", Type: protocol.FileInfoTypeDirectory, Version: version, Sequence: 1},
+		{Name: "proj/a", Size: 100, Version: version, Sequence: 2},
+		{Name: "other", Size: 300, Version: version, Sequence: 3},
+	}
+	must(t, m.Index(conn, &protocol.Index{Folder: fcfg.ID, Files: files}))
+
+	comp, err := m.PrefixCompletion(protocol.LocalDeviceID, fcfg.ID, "proj")
+	must(t, err)
+	if comp.GlobalBytes != 100 || comp.NeedBytes != 100 || comp.NeedItems != 2 {
+		t.Fatalf("unexpected completion before sync: %+v", comp)
+	}
+
+	// Once we have the directory, it's complete even though the folder
+	// isn't.
+	m.sdb.Update(fcfg.ID, protocol.LocalDeviceID, files[:2])
+	comp, err = m.PrefixCompletion(protocol.LocalDeviceID, fcfg.ID, "proj")
+	must(t, err)
+	if comp.CompletionPct != 100 || comp.NeedItems != 0 {
+		t.Errorf("unexpected completion after sync: %+v", comp)
+	}
+	if comp := m.testCompletion(protocol.LocalDeviceID, fcfg.ID); comp.CompletionPct == 100 {
+		t.Error("folder shouldn't be complete")
+	}
+}
+
 func TestNeedMetaAfterIndexReset(t *testing.T) {
 	w, fcfg, wCancel := newDefaultCfgWrapper()
 	defer wCancel()
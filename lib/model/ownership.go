@@ -0,0 +1,238 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"cmp"
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// How many violating file names are listed in an ownership violation event
+const maxOwnershipViolationNames = 100
+
+var ErrOwnershipClaimNotPending = errors.New("no such pending ownership claim")
+
+// PendingOwnershipClaim is a set of prefixes a remote device announced
+// owning in a folder, which differs from what is configured for it here.
+// It has no effect until approved, which configures the prefixes for the
+// device.
+type PendingOwnershipClaim struct {
+	Folder   string            `json:"folder"`
+	Device   protocol.DeviceID `json:"device"`
+	Prefixes []string          `json:"prefixes"`
+	Current  []string          `json:"current"` // as configured here
+}
+
+// folderOwnership is the advisory ownership map of a folder, assigning path
+// prefixes to the devices expected to be the only ones writing there.
+type folderOwnership struct {
+	prefixes []ownedPrefix // longest prefix first
+}
+
+type ownedPrefix struct {
+	prefix string
+	owner  protocol.DeviceID
+}
+
+func newFolderOwnership(claims map[protocol.DeviceID][]string) *folderOwnership {
+	o := &folderOwnership{}
+	for dev, prefixes := range claims {
+		for _, prefix := range prefixes {
+			o.prefixes = append(o.prefixes, ownedPrefix{prefix: prefix, owner: dev})
+		}
+	}
+	if len(o.prefixes) == 0 {
+		return nil
+	}
+	// Longer prefixes are more specific and take precedence; the same
+	// prefix claimed by several devices goes to the lowest device ID, so
+	// that all devices agree on the owner.
+	slices.SortFunc(o.prefixes, func(a, b ownedPrefix) int {
+		if c := cmp.Compare(len(b.prefix), len(a.prefix)); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.prefix, b.prefix); c != 0 {
+			return c
+		}
+		return a.owner.Compare(b.owner)
+	})
+	return o
+}
+
+// owner returns the device owning the given file, if any.
+func (o *folderOwnership) owner(name string) (protocol.DeviceID, bool) {
+	if o == nil {
+		return protocol.EmptyDeviceID, false
+	}
+	name = filepath.ToSlash(name)
+	for _, p := range o.prefixes {
+		if name == p.prefix || strings.HasPrefix(name, p.prefix+"/") {
+			return p.owner, true
+		}
+	}
+	return protocol.EmptyDeviceID, false
+}
+
+// foreign returns whether the given file is owned by a device other than
+// ours.
+func (o *folderOwnership) foreign(name string, ours protocol.DeviceID) bool {
+	owner, ok := o.owner(name)
+	return ok && owner != ours
+}
+
+// setOwnershipClaimLocked records the prefixes a remote device announced
+// owning in the folder, to be approved locally. Must be called with m.mut
+// held.
+func (m *model) setOwnershipClaimLocked(folder string, device protocol.DeviceID, prefixes []string) {
+	if len(prefixes) == 0 {
+		if claims, ok := m.ownershipClaims[folder]; ok {
+			delete(claims, device)
+			if len(claims) == 0 {
+				delete(m.ownershipClaims, folder)
+			}
+		}
+		return
+	}
+	if _, ok := m.ownershipClaims[folder]; !ok {
+		m.ownershipClaims[folder] = make(map[protocol.DeviceID][]string)
+	}
+	if prev := m.ownershipClaims[folder][device]; !samePrefixes(prev, prefixes) {
+		if fcfg, ok := m.cfg.Folder(folder); ok {
+			if dev, ok := fcfg.Device(device); ok && !samePrefixes(dev.OwnedPrefixes, prefixes) {
+				slog.Info("Ownership claim awaits approval", fcfg.LogAttr(), device.LogAttr(), slog.Any("prefixes", prefixes))
+			}
+		}
+	}
+	m.ownershipClaims[folder][device] = prefixes
+}
+
+// folderOwnership returns the ownership map of the folder, or nil if there
+// is none. Only the prefixes configured locally count; what remote devices
+// announce for themselves is pending until approved.
+func (m *model) folderOwnership(cfg config.FolderConfiguration) *folderOwnership {
+	claims := make(map[protocol.DeviceID][]string)
+	for _, dev := range cfg.Devices {
+		if len(dev.OwnedPrefixes) > 0 {
+			claims[dev.DeviceID] = dev.OwnedPrefixes
+		}
+	}
+	return newFolderOwnership(claims)
+}
+
+// PendingOwnershipClaims returns the prefixes remote devices announced
+// owning that differ from the ones configured for them.
+func (m *model) PendingOwnershipClaims() ([]PendingOwnershipClaim, error) {
+	m.mut.RLock()
+	defer m.mut.RUnlock()
+	pending := []PendingOwnershipClaim{}
+	for folder, claims := range m.ownershipClaims {
+		fcfg, ok := m.cfg.Folder(folder)
+		if !ok {
+			continue
+		}
+		for device, prefixes := range claims {
+			dev, ok := fcfg.Device(device)
+			if !ok || samePrefixes(dev.OwnedPrefixes, prefixes) {
+				continue
+			}
+			pending = append(pending, PendingOwnershipClaim{
+				Folder:   folder,
+				Device:   device,
+				Prefixes: prefixes,
+				Current:  dev.OwnedPrefixes,
+			})
+		}
+	}
+	slices.SortFunc(pending, func(a, b PendingOwnershipClaim) int {
+		if c := strings.Compare(a.Folder, b.Folder); c != 0 {
+			return c
+		}
+		return a.Device.Compare(b.Device)
+	})
+	return pending, nil
+}
+
+// ApproveOwnershipClaim configures the prefixes the device announced owning
+// in the folder for it.
+func (m *model) ApproveOwnershipClaim(folder string, device protocol.DeviceID) error {
+	m.mut.RLock()
+	prefixes := slices.Clone(m.ownershipClaims[folder][device])
+	m.mut.RUnlock()
+	if len(prefixes) == 0 {
+		return ErrOwnershipClaimNotPending
+	}
+	if _, ok := m.cfg.Folder(folder); !ok {
+		return ErrFolderMissing
+	}
+
+	waiter, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		fcfg, _, ok := cfg.Folder(folder)
+		if !ok {
+			return
+		}
+		for i := range fcfg.Devices {
+			if fcfg.Devices[i].DeviceID == device {
+				fcfg.Devices[i].OwnedPrefixes = prefixes
+			}
+		}
+		cfg.SetFolder(fcfg)
+	})
+	if err != nil {
+		return err
+	}
+	waiter.Wait()
+	slog.Info("Approved ownership claim", slog.String("folder", folder), device.LogAttr(), slog.Any("prefixes", prefixes))
+	return nil
+}
+
+// RejectOwnershipClaim forgets the prefixes the device announced owning in
+// the folder, until it announces them again.
+func (m *model) RejectOwnershipClaim(folder string, device protocol.DeviceID) error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if _, ok := m.ownershipClaims[folder][device]; !ok {
+		return ErrOwnershipClaimNotPending
+	}
+	m.setOwnershipClaimLocked(folder, device, nil)
+	return nil
+}
+
+func samePrefixes(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+// reportOwnershipViolations announces the local changes of the scan that
+// were made under prefixes owned by another device.
+func (b *scanBatch) reportOwnershipViolations() {
+	if len(b.violations) == 0 {
+		return
+	}
+	f := b.f
+	metricFolderOwnershipViolations.WithLabelValues(f.ID).Add(float64(len(b.violations)))
+	f.sl.Warn("Local changes under paths owned by another device will not be synced", slog.Int("count", len(b.violations)), slog.String("example", b.violations[0]))
+	names := b.violations
+	if len(names) > maxOwnershipViolationNames {
+		names = names[:maxOwnershipViolationNames]
+	}
+	f.evLogger.Log(events.OwnershipViolation, map[string]interface{}{
+		"folder": f.ID,
+		"count":  len(b.violations),
+		"files":  names,
+	})
+	b.violations = nil
+}
@@ -0,0 +1,142 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestFolderOwnershipOwner(t *testing.T) {
+	o := newFolderOwnership(map[protocol.DeviceID][]string{
+		device1: {"shared", "team/a"},
+		device2: {"team", "shared"},
+	})
+
+	cases := []struct {
+		name  string
+		owner protocol.DeviceID
+		ok    bool
+	}{
+		{"team", device2, true},
+		{"team/b/file", device2, true},
+		{"team/a", device1, true},
+		{"team/a/file", device1, true},
+		{"team/ab", device2, true},
+		{"teams", protocol.EmptyDeviceID, false},
+		{"other/file", protocol.EmptyDeviceID, false},
+	}
+	for _, tc := range cases {
+		owner, ok := o.owner(tc.name)
+		if ok != tc.ok || owner != tc.owner {
+			t.Errorf("owner(%q) = %v, %v; expected %v, %v", tc.name, owner, ok, tc.owner, tc.ok)
+		}
+	}
+
+	// A prefix claimed by both goes to the lower device ID
+	expected := device1
+	if device2.Compare(device1) < 0 {
+		expected = device2
+	}
+	if owner, _ := o.owner("shared/file"); owner != expected {
+		t.Errorf("shared prefix owned by %v, expected %v", owner, expected)
+	}
+
+	if newFolderOwnership(nil) != nil {
+		t.Error("expected no ownership map without claims")
+	}
+}
+
+func TestScanFlagsOwnershipViolations(t *testing.T) {
+	m, f, wcfgCancel := setupSendReceiveFolder(t)
+	defer wcfgCancel()
+	ffs := f.Filesystem()
+
+	setOwnedPrefixes(t, m, f.ID, device1, []string{"theirs"})
+	f.FolderConfiguration, _ = m.cfg.Folder(f.ID)
+
+	must(t, ffs.MkdirAll("theirs", 0o755))
+	writeFile(t, ffs, "theirs/file", []byte("data"))
+	writeFile(t, ffs, "mine", []byte("data"))
+	must(t, f.scanSubdirs(nil))
+
+	for name, flagged := range map[string]bool{"theirs/file": true, "mine": false} {
+		fi, ok, err := m.sdb.GetDeviceFile(f.folderID, protocol.LocalDeviceID, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			t.Fatalf("%s not in the index", name)
+		}
+		if fi.IsReceiveOnlyChanged() != flagged {
+			t.Errorf("%s: receive only changed is %v, expected %v", name, fi.IsReceiveOnlyChanged(), flagged)
+		}
+	}
+}
+
+func TestOwnershipClaimsPending(t *testing.T) {
+	m, f, wcfgCancel := setupSendReceiveFolder(t)
+	defer wcfgCancel()
+
+	m.mut.Lock()
+	m.setOwnershipClaimLocked(f.ID, device1, []string{"theirs"})
+	m.mut.Unlock()
+
+	// An announced claim has no effect until approved.
+	fcfg, _ := m.cfg.Folder(f.ID)
+	if o := m.folderOwnership(fcfg); o != nil {
+		t.Fatalf("remote claim applied without approval: %+v", o.prefixes)
+	}
+	pending, err := m.PendingOwnershipClaims()
+	must(t, err)
+	if len(pending) != 1 || pending[0].Device != device1 || pending[0].Folder != f.ID {
+		t.Fatalf("expected a pending claim, got %+v", pending)
+	}
+	if err := m.ApproveOwnershipClaim(f.ID, device2); !errors.Is(err, ErrOwnershipClaimNotPending) {
+		t.Errorf("expected no claim from device2, got %v", err)
+	}
+
+	must(t, m.ApproveOwnershipClaim(f.ID, device1))
+	fcfg, _ = m.cfg.Folder(f.ID)
+	if !m.folderOwnership(fcfg).foreign("theirs/file", myID) {
+		t.Error("approved claim not applied")
+	}
+	if pending, _ := m.PendingOwnershipClaims(); len(pending) != 0 {
+		t.Errorf("approved claim still pending: %+v", pending)
+	}
+
+	// A new claim can be rejected, leaving the configured one.
+	m.mut.Lock()
+	m.setOwnershipClaimLocked(f.ID, device1, []string{"theirs", "more"})
+	m.mut.Unlock()
+	must(t, m.RejectOwnershipClaim(f.ID, device1))
+	if pending, _ := m.PendingOwnershipClaims(); len(pending) != 0 {
+		t.Errorf("rejected claim still pending: %+v", pending)
+	}
+	fcfg, _ = m.cfg.Folder(f.ID)
+	if m.folderOwnership(fcfg).foreign("more/file", myID) {
+		t.Error("rejected claim applied")
+	}
+}
+
+func setOwnedPrefixes(t *testing.T, m *testModel, folder string, device protocol.DeviceID, prefixes []string) {
+	t.Helper()
+	waiter, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		fcfg, _, _ := cfg.Folder(folder)
+		for i := range fcfg.Devices {
+			if fcfg.Devices[i].DeviceID == device {
+				fcfg.Devices[i].OwnedPrefixes = prefixes
+			}
+		}
+		cfg.SetFolder(fcfg)
+	})
+	must(t, err)
+	waiter.Wait()
+}
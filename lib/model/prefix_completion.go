@@ -0,0 +1,119 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"maps"
+	"sync"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// The maximum number of cached prefix completions; the cache is emptied
+// when it grows beyond this.
+const maxPrefixCompletions = 1000
+
+type prefixCompletionKey struct {
+	device protocol.DeviceID
+	folder string
+	prefix string
+}
+
+type cachedPrefixCompletion struct {
+	comp      FolderCompletion
+	sequences map[protocol.DeviceID]int64
+}
+
+// prefixCompletionCache keeps computed prefix completions for as long as
+// no device's index of the folder has changed.
+type prefixCompletionCache struct {
+	mut     sync.Mutex
+	entries map[prefixCompletionKey]cachedPrefixCompletion
+}
+
+func newPrefixCompletionCache() *prefixCompletionCache {
+	return &prefixCompletionCache{
+		entries: make(map[prefixCompletionKey]cachedPrefixCompletion),
+	}
+}
+
+func (c *prefixCompletionCache) get(key prefixCompletionKey, sequences map[protocol.DeviceID]int64) (FolderCompletion, bool) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	e, ok := c.entries[key]
+	if !ok || !maps.Equal(e.sequences, sequences) {
+		return FolderCompletion{}, false
+	}
+	return e.comp, true
+}
+
+func (c *prefixCompletionCache) put(key prefixCompletionKey, comp FolderCompletion, sequences map[protocol.DeviceID]int64) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	if len(c.entries) >= maxPrefixCompletions {
+		clear(c.entries)
+	}
+	c.entries[key] = cachedPrefixCompletion{comp: comp, sequences: sequences}
+}
+
+// PrefixCompletion returns the completion of the given directory, or
+// file, and everything below it in the folder for the device.
+func (m *model) PrefixCompletion(device protocol.DeviceID, folder, prefix string) (FolderCompletion, error) {
+	if device == m.id {
+		device = protocol.LocalDeviceID
+	}
+	if prefix == "" {
+		return m.Completion(device, folder)
+	}
+
+	m.mut.RLock()
+	err := m.checkFolderRunningRLocked(folder)
+	state := m.remoteFolderStates[device][folder]
+	m.mut.RUnlock()
+	if err != nil {
+		return FolderCompletion{}, err
+	}
+
+	// The completion can only change when the index of some device
+	// changes, which is seen in the sequence numbers.
+	sequences, err := m.sdb.RemoteSequences(folder)
+	if err != nil {
+		return FolderCompletion{}, err
+	}
+	localSeq, err := m.sdb.GetDeviceSequence(folder, protocol.LocalDeviceID)
+	if err != nil {
+		return FolderCompletion{}, err
+	}
+	if sequences == nil {
+		sequences = make(map[protocol.DeviceID]int64)
+	}
+	sequences[protocol.LocalDeviceID] = localSeq
+
+	key := prefixCompletionKey{device: device, folder: folder, prefix: prefix}
+	if comp, ok := m.prefixCompletions.get(key, sequences); ok {
+		comp.RemoteState = state
+		return comp, nil
+	}
+
+	need, err := m.sdb.CountNeedPrefix(folder, device, prefix)
+	if err != nil {
+		return FolderCompletion{}, err
+	}
+	glob, err := m.sdb.CountGlobalPrefix(folder, prefix)
+	if err != nil {
+		return FolderCompletion{}, err
+	}
+	seq := localSeq
+	if device != protocol.LocalDeviceID {
+		seq = sequences[device]
+	}
+	comp := newFolderCompletion(glob, need, seq, state)
+	m.prefixCompletions.put(key, comp, sequences)
+
+	l.Debugf("%v PrefixCompletion(%s, %q, %q): %v", m, device, folder, prefix, comp.Map())
+	return comp, nil
+}
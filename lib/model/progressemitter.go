@@ -21,6 +21,9 @@ import (
 type ProgressEmitter struct {
 	cfg                config.Wrapper
 	registry           map[string]map[string]*sharedPullerState // folder: name: puller
+	interrupted        map[string]map[string]*sharedPullerState // folder: name: puller, see Interrupted
+	keepInterrupted    time.Duration
+	pending            bool // updates are due even without progress
 	interval           time.Duration
 	minBlocks          int
 	sentDownloadStates map[protocol.DeviceID]*sentDownloadState // States representing what we've sent to the other peer via DownloadProgress messages.
@@ -49,6 +52,7 @@ func NewProgressEmitter(cfg config.Wrapper, evLogger events.Logger) *ProgressEmi
 	t := &ProgressEmitter{
 		cfg:                cfg,
 		registry:           make(map[string]map[string]*sharedPullerState),
+		interrupted:        make(map[string]map[string]*sharedPullerState),
 		timer:              time.NewTimer(time.Millisecond),
 		sentDownloadStates: make(map[protocol.DeviceID]*sentDownloadState),
 		connections:        make(map[protocol.DeviceID]protocol.Connection),
@@ -69,6 +73,7 @@ func (t *ProgressEmitter) Serve(ctx context.Context) error {
 
 	var lastUpdate time.Time
 	var lastCount, newCount int
+	var lastInterrupted, newInterrupted int
 	for {
 		select {
 		case <-ctx.Done():
@@ -79,6 +84,8 @@ func (t *ProgressEmitter) Serve(ctx context.Context) error {
 
 			newLastUpdated := lastUpdate
 			newCount = t.lenRegistryLocked()
+			t.expireInterruptedLocked()
+			newInterrupted = t.lenInterruptedLocked()
 			var progressUpdates []progressUpdate
 			for _, pullers := range t.registry {
 				for _, puller := range pullers {
@@ -93,9 +100,13 @@ func (t *ProgressEmitter) Serve(ctx context.Context) error {
 				lastCount = newCount
 				t.sendDownloadProgressEventLocked()
 				progressUpdates = t.computeProgressUpdates()
+			} else if newInterrupted != lastInterrupted || t.pending {
+				progressUpdates = t.computeProgressUpdates()
 			}
+			lastInterrupted = newInterrupted
+			t.pending = false
 
-			if newCount != 0 {
+			if newCount != 0 || newInterrupted != 0 {
 				t.timer.Reset(t.interval)
 			}
 			t.mut.Unlock()
@@ -134,7 +145,8 @@ func (t *ProgressEmitter) computeProgressUpdates() []progressUpdate {
 	for id, conn := range t.connections {
 		for _, folder := range t.foldersByConns[id] {
 			pullers, ok := t.registry[folder]
-			if !ok {
+			interrupted, iok := t.interrupted[folder]
+			if !ok && !iok {
 				// There's never been any puller registered for this folder yet
 				continue
 			}
@@ -154,6 +166,14 @@ func (t *ProgressEmitter) computeProgressUpdates() []progressUpdate {
 				}
 				activePullers = append(activePullers, puller)
 			}
+			// Interrupted pulls are advertised like active ones, unless the
+			// file is being pulled again.
+			for name, puller := range interrupted {
+				if _, ok := pullers[name]; ok || len(puller.file.Blocks) <= t.minBlocks {
+					continue
+				}
+				activePullers = append(activePullers, puller)
+			}
 
 			// For every new puller that hasn't yet been seen, it will send all the blocks the puller has available
 			// For every existing puller, it will check for new blocks, and send update for the new blocks only
@@ -230,6 +250,7 @@ func (t *ProgressEmitter) CommitConfiguration(_, to config.Configuration) bool {
 		slog.Debug("Progress emitter: disabled")
 	}
 	t.minBlocks = to.Options.TempIndexMinBlocks
+	t.keepInterrupted = time.Duration(to.Options.KeepTemporariesH) * time.Hour
 	if t.interval < time.Second {
 		// can't happen when we're not disabled, but better safe than sorry.
 		t.interval = time.Second
@@ -254,6 +275,7 @@ func (t *ProgressEmitter) Register(s *sharedPullerState) {
 		t.registry[s.folder] = make(map[string]*sharedPullerState)
 	}
 	t.registry[s.folder][s.file.Name] = s
+	delete(t.interrupted[s.folder], s.file.Name)
 }
 
 // Deregister a puller which will stop broadcasting pullers state.
@@ -269,6 +291,67 @@ func (t *ProgressEmitter) Deregister(s *sharedPullerState) {
 	delete(t.registry[s.folder], s.file.Name)
 }
 
+// Interrupted deregisters a puller that failed to complete its file, but
+// keeps advertising the blocks it got to other devices, as the temporary
+// file is kept for another attempt. Those devices can then fetch the
+// blocks from us rather than starting from scratch should the other
+// sources go away. This lasts until the file is pulled again or the
+// temporary file expires.
+func (t *ProgressEmitter) Interrupted(s *sharedPullerState) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	if t.disabled {
+		return
+	}
+
+	l.Debugln("progress emitter: interrupted", s.folder, s.file.Name)
+	delete(t.registry[s.folder], s.file.Name)
+	if t.keepInterrupted <= 0 || len(s.Available()) == 0 {
+		return
+	}
+	if _, ok := t.interrupted[s.folder]; !ok {
+		t.interrupted[s.folder] = make(map[string]*sharedPullerState)
+	}
+	t.interrupted[s.folder][s.file.Name] = s
+}
+
+// RetainInterrupted forgets the interrupted pulls of the folder whose
+// temporary files are no longer kept, e.g. once they have been cleaned up.
+func (t *ProgressEmitter) RetainInterrupted(folder string, kept map[string]struct{}) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	if t.disabled {
+		return
+	}
+
+	forgotten := false
+	for name := range t.interrupted[folder] {
+		if _, ok := kept[name]; !ok {
+			delete(t.interrupted[folder], name)
+			forgotten = true
+		}
+	}
+	if !forgotten {
+		return
+	}
+	t.pending = true
+	t.timer.Reset(t.interval)
+}
+
+// expireInterruptedLocked forgets interrupted pulls whose temporary files
+// are due for removal.
+func (t *ProgressEmitter) expireInterruptedLocked() {
+	for _, states := range t.interrupted {
+		for name, s := range states {
+			if time.Since(s.AvailableUpdated()) > t.keepInterrupted {
+				delete(states, name)
+			}
+		}
+	}
+}
+
 // BytesCompleted returns the number of bytes completed in the given folder.
 func (t *ProgressEmitter) BytesCompleted(folder string) (bytes int64) {
 	t.mut.Lock()
@@ -298,6 +381,13 @@ func (t *ProgressEmitter) lenRegistryLocked() (out int) {
 	return out
 }
 
+func (t *ProgressEmitter) lenInterruptedLocked() (out int) {
+	for _, states := range t.interrupted {
+		out += len(states)
+	}
+	return out
+}
+
 func (t *ProgressEmitter) emptyLocked() bool {
 	for _, pullers := range t.registry {
 		if len(pullers) != 0 {
@@ -312,6 +402,12 @@ func (t *ProgressEmitter) temporaryIndexSubscribe(conn protocol.Connection, fold
 	defer t.mut.Unlock()
 	t.connections[conn.DeviceID()] = conn
 	t.foldersByConns[conn.DeviceID()] = folders
+	if t.lenInterruptedLocked() > 0 {
+		// Nothing may change for a while, so make sure the new device
+		// learns about interrupted pulls anyway.
+		t.pending = true
+		t.timer.Reset(t.interval)
+	}
 }
 
 func (t *ProgressEmitter) temporaryIndexUnsubscribe(conn protocol.Connection) {
@@ -334,6 +430,7 @@ func (t *ProgressEmitter) clearLocked() {
 		}
 	}
 	t.registry = make(map[string]map[string]*sharedPullerState)
+	t.interrupted = make(map[string]map[string]*sharedPullerState)
 	t.sentDownloadStates = make(map[protocol.DeviceID]*sentDownloadState)
 	t.connections = make(map[protocol.DeviceID]protocol.Connection)
 	t.foldersByConns = make(map[protocol.DeviceID][]string)
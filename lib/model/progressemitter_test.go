@@ -468,3 +468,62 @@ func sendMsgs(p *ProgressEmitter) {
 		update.send(ctx)
 	}
 }
+
+func TestSendInterruptedDownloadProgress(t *testing.T) {
+	c, cfgCancel := newConfigWrapper(config.Configuration{Version: config.CurrentVersion})
+	defer os.Remove(c.ConfigPath())
+	defer cfgCancel()
+	waiter, err := c.Modify(func(cfg *config.Configuration) {
+		cfg.Options.ProgressUpdateIntervalS = 60 // irrelevant, but must be positive
+		cfg.Options.TempIndexMinBlocks = 1
+		cfg.Options.KeepTemporariesH = 24
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waiter.Wait()
+
+	fc := newFakeConnection(protocol.DeviceID{}, nil)
+	p := NewProgressEmitter(c, events.NoopLogger)
+	p.temporaryIndexSubscribe(fc, []string{"folder"})
+
+	updateTypes := func() []protocol.FileDownloadProgressUpdateType {
+		sendMsgs(p)
+		var types []protocol.FileDownloadProgressUpdateType
+		for _, msg := range fc.downloadProgressMessages {
+			for _, upd := range msg.updates {
+				types = append(types, upd.UpdateType)
+			}
+		}
+		fc.downloadProgressMessages = nil
+		return types
+	}
+
+	file := protocol.FileInfo{Name: "large", Version: (protocol.Vector{}).Update(0), Blocks: make([]protocol.BlockInfo, 4)}
+	puller := newInterruptedPullerState(file, "folder", []int{0, 1}, time.Now())
+	puller.closed = false
+	p.Register(puller)
+	if types := updateTypes(); len(types) != 1 || types[0] != protocol.FileDownloadProgressUpdateTypeAppend {
+		t.Fatalf("expected the active pull to be advertised, got %v", types)
+	}
+
+	// The blocks of an interrupted pull stay advertised.
+	p.Interrupted(puller)
+	if p.lenRegistry() != 0 {
+		t.Error("interrupted pull should not be registered")
+	}
+	if types := updateTypes(); len(types) != 0 {
+		t.Fatalf("expected nothing to change, got %v", types)
+	}
+
+	// They stay advertised while the temporary file is kept, and are
+	// forgotten once it isn't.
+	p.RetainInterrupted("folder", map[string]struct{}{"large": {}})
+	if types := updateTypes(); len(types) != 0 {
+		t.Fatalf("expected nothing to change, got %v", types)
+	}
+	p.RetainInterrupted("folder", nil)
+	if types := updateTypes(); len(types) != 1 || types[0] != protocol.FileDownloadProgressUpdateTypeForget {
+		t.Fatalf("expected the interrupted pull to be forgotten, got %v", types)
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+const (
+	// On network filesystems every file costs a number of round trips to
+	// the server, and too many at once time out.
+	networkFSPullFiles     = 8
+	networkFSBlockRequests = 32
+
+	// FAT formatted SD cards and USB sticks slow to a crawl when written
+	// in many places at once.
+	removableFSPullFiles     = 2
+	removableFSBlockRequests = 8
+)
+
+// pullLimits returns how many files may be pulled and how many block
+// requests may be outstanding at once, zero meaning no limit. Unset limits
+// are derived from the filesystem the folder lives on.
+func (f *sendReceiveFolder) pullLimits() (files, blocks int) {
+	files, blocks = f.MaxConcurrentPullFiles, f.MaxConcurrentBlockRequests
+	if files == 0 || blocks == 0 {
+		if !f.fsPullLimitsDetected {
+			f.fsPullFiles, f.fsPullBlocks = fsPullLimits(f.mtimefs)
+			f.fsPullLimitsDetected = true
+		}
+		if files == 0 {
+			files = f.fsPullFiles
+		}
+		if blocks == 0 {
+			blocks = f.fsPullBlocks
+		}
+	}
+	return max(files, 0), max(blocks, 0)
+}
+
+// fsPullLimits returns the pull limits suited to the filesystem.
+func fsPullLimits(ffs fs.Filesystem) (files, blocks int) {
+	if ok, fstype := fs.IsNetworkFilesystem(ffs); ok {
+		l.Debugln("Limiting concurrent pulls on network filesystem", fstype)
+		return networkFSPullFiles, networkFSBlockRequests
+	}
+	if ok, fstype := fs.IsRemovableMediaFilesystem(ffs); ok {
+		l.Debugln("Limiting concurrent pulls on removable media filesystem", fstype)
+		return removableFSPullFiles, removableFSBlockRequests
+	}
+	return 0, 0
+}
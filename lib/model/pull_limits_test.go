@@ -0,0 +1,47 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+)
+
+func TestPullLimits(t *testing.T) {
+	_, f, wcfgCancel := setupSendReceiveFolder(t)
+	defer wcfgCancel()
+
+	// Pretend the filesystem was detected as a network one
+	f.fsPullLimitsDetected = true
+	f.fsPullFiles, f.fsPullBlocks = networkFSPullFiles, networkFSBlockRequests
+
+	cases := []struct {
+		cfgFiles, cfgBlocks int
+		files, blocks       int
+	}{
+		{0, 0, networkFSPullFiles, networkFSBlockRequests},
+		{3, 0, 3, networkFSBlockRequests},
+		{0, 5, networkFSPullFiles, 5},
+		{-1, -1, 0, 0},
+	}
+	for _, tc := range cases {
+		f.MaxConcurrentPullFiles, f.MaxConcurrentBlockRequests = tc.cfgFiles, tc.cfgBlocks
+		files, blocks := f.pullLimits()
+		if files != tc.files || blocks != tc.blocks {
+			t.Errorf("configured %d/%d: got limits %d/%d, expected %d/%d", tc.cfgFiles, tc.cfgBlocks, files, blocks, tc.files, tc.blocks)
+		}
+	}
+}
+
+func TestPullLimitsLocalFilesystem(t *testing.T) {
+	_, f, wcfgCancel := setupSendReceiveFolder(t)
+	defer wcfgCancel()
+
+	// The test folder is neither on the network nor on removable media
+	if files, blocks := f.pullLimits(); files != 0 || blocks != 0 {
+		t.Errorf("got limits %d/%d, expected none", files, blocks)
+	}
+}
@@ -0,0 +1,216 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/events"
+)
+
+// Stages of the puller pipeline, in the order items pass through them.
+type pipelineStage int
+
+const (
+	stageNeeded    pipelineStage = iota // files waiting in the job queue
+	stageCopier                         // files having blocks copied locally
+	stageRequestor                      // blocks being requested from devices
+	stageWriter                         // blocks being written to temp files
+	stageVerifier                       // blocks being read back after writing
+	stageFinisher                       // files being moved into place
+	numPipelineStages
+)
+
+var pipelineStageNames = [numPipelineStages]string{"needed", "copier", "requestor", "writer", "verifier", "finisher"}
+
+func (s pipelineStage) String() string {
+	return pipelineStageNames[s]
+}
+
+// How often the stall detector looks at the pipeline
+const pullStallCheckInterval = 10 * time.Second
+
+// PipelineStageSnapshot is the state of one stage of the puller pipeline.
+type PipelineStageSnapshot struct {
+	Stage string `json:"stage"`
+	// Items waiting for the stage and being worked on by it
+	Queued int `json:"queued"`
+	Active int `json:"active"`
+	// Items that went through the stage during this pull, and the mean
+	// time each spent being worked on
+	Completed   int     `json:"completed"`
+	MeanLatency float64 `json:"meanLatencyS"`
+}
+
+// PipelineSnapshot is the state of a folder's puller pipeline.
+type PipelineSnapshot struct {
+	Folder       string                  `json:"folder"`
+	Stages       []PipelineStageSnapshot `json:"stages"`
+	LastProgress time.Time               `json:"lastProgress"`
+}
+
+// pullPipeline instruments the stages of a folder's puller for metrics and
+// stall detection. An item is queued for a stage when handed to it, active
+// once a routine of the stage picks it up, and done when that routine is
+// finished with it. Not every stage knows about its queue; those only count
+// active items.
+type pullPipeline struct {
+	folder string
+
+	mut          sync.Mutex
+	stages       [numPipelineStages]pipelineStageCounts
+	lastProgress time.Time
+}
+
+type pipelineStageCounts struct {
+	queued    int
+	active    int
+	completed int
+	latency   time.Duration
+}
+
+func newPullPipeline(folder string) *pullPipeline {
+	p := &pullPipeline{folder: folder}
+	p.reset()
+	return p
+}
+
+// reset clears the counts at the start of a pull.
+func (p *pullPipeline) reset() {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	p.stages = [numPipelineStages]pipelineStageCounts{}
+	p.lastProgress = time.Now()
+	for stage := range numPipelineStages {
+		metricFolderPullStageQueued.WithLabelValues(p.folder, stage.String()).Set(0)
+		metricFolderPullStageActive.WithLabelValues(p.folder, stage.String()).Set(0)
+	}
+}
+
+func (p *pullPipeline) queue(stage pipelineStage) {
+	p.mut.Lock()
+	p.stages[stage].queued++
+	p.mut.Unlock()
+	metricFolderPullStageQueued.WithLabelValues(p.folder, stage.String()).Inc()
+}
+
+// start marks an item as picked up by the stage, returning the time to
+// pass to done.
+func (p *pullPipeline) start(stage pipelineStage, queued bool) time.Time {
+	p.mut.Lock()
+	if queued && p.stages[stage].queued > 0 {
+		p.stages[stage].queued--
+		metricFolderPullStageQueued.WithLabelValues(p.folder, stage.String()).Dec()
+	}
+	p.stages[stage].active++
+	p.mut.Unlock()
+	metricFolderPullStageActive.WithLabelValues(p.folder, stage.String()).Inc()
+	return time.Now()
+}
+
+func (p *pullPipeline) done(stage pipelineStage, t0 time.Time) {
+	now := time.Now()
+	latency := now.Sub(t0)
+	p.mut.Lock()
+	s := &p.stages[stage]
+	s.active--
+	s.completed++
+	s.latency += latency
+	p.lastProgress = now
+	p.mut.Unlock()
+	metricFolderPullStageActive.WithLabelValues(p.folder, stage.String()).Dec()
+	metricFolderPullStageItems.WithLabelValues(p.folder, stage.String()).Inc()
+	metricFolderPullStageSeconds.WithLabelValues(p.folder, stage.String()).Add(latency.Seconds())
+}
+
+// snapshot returns the current state, with the needed stage taken from the
+// job queue.
+func (p *pullPipeline) snapshot(queue *jobQueue) PipelineSnapshot {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+	snap := PipelineSnapshot{
+		Folder:       p.folder,
+		Stages:       make([]PipelineStageSnapshot, numPipelineStages),
+		LastProgress: p.lastProgress,
+	}
+	for stage, s := range p.stages {
+		ss := PipelineStageSnapshot{
+			Stage:     pipelineStage(stage).String(),
+			Queued:    s.queued,
+			Active:    s.active,
+			Completed: s.completed,
+		}
+		if s.completed > 0 {
+			ss.MeanLatency = (s.latency / time.Duration(s.completed)).Seconds()
+		}
+		snap.Stages[stage] = ss
+	}
+	if queue != nil {
+		snap.Stages[stageNeeded].Queued = queue.lenQueued()
+		snap.Stages[stageNeeded].Active = queue.lenProgress()
+	}
+	return snap
+}
+
+// busy returns whether anything is queued or active in the snapshot.
+func (s PipelineSnapshot) busy() bool {
+	for _, stage := range s.Stages {
+		if stage.Queued > 0 || stage.Active > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// detectPullStalls runs during a pull and reports, once per stall, when
+// the pipeline has work but made no progress for the folder's stall
+// timeout while devices sharing the folder are connected. Without
+// connections a halt is expected and not a stall.
+func (f *sendReceiveFolder) detectPullStalls(ctx context.Context) {
+	timeout := time.Duration(f.PullStallTimeoutS) * time.Second
+	if timeout <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(pullStallCheckInterval)
+	defer ticker.Stop()
+
+	var reported time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		snap := f.pipeline.snapshot(f.queue)
+		if time.Since(snap.LastProgress) < timeout || !snap.busy() || !f.anyDeviceConnected() {
+			continue
+		}
+		if snap.LastProgress.Equal(reported) {
+			// Already reported this stall
+			continue
+		}
+		reported = snap.LastProgress
+
+		metricFolderPullStalls.WithLabelValues(f.ID).Inc()
+		f.sl.Warn("Pulling seems stalled", slog.Duration("since", time.Since(snap.LastProgress).Truncate(time.Second)), slog.Any("pipeline", snap.Stages))
+		f.evLogger.Log(events.PullStalled, snap)
+	}
+}
+
+func (f *sendReceiveFolder) anyDeviceConnected() bool {
+	for _, dev := range f.DeviceIDs() {
+		if dev != f.model.id && f.model.ConnectedTo(dev) {
+			return true
+		}
+	}
+	return false
+}
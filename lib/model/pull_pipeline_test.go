@@ -0,0 +1,50 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPullPipelineCounts(t *testing.T) {
+	p := newPullPipeline("pipeline")
+	queue := newJobQueue()
+	queue.Push("a", 1, time.Now())
+	queue.Push("b", 1, time.Now())
+	queue.Pop()
+
+	p.queue(stageCopier)
+	p.queue(stageCopier)
+	t0 := p.start(stageCopier, true)
+	snap := p.snapshot(queue)
+	if !snap.busy() {
+		t.Error("expected a busy pipeline")
+	}
+	if s := snap.Stages[stageCopier]; s.Queued != 1 || s.Active != 1 || s.Completed != 0 {
+		t.Errorf("unexpected copier stage %+v", s)
+	}
+	if s := snap.Stages[stageNeeded]; s.Queued != 1 || s.Active != 1 {
+		t.Errorf("unexpected needed stage %+v", s)
+	}
+
+	before := snap.LastProgress
+	time.Sleep(time.Millisecond)
+	p.done(stageCopier, t0)
+	snap = p.snapshot(nil)
+	if s := snap.Stages[stageCopier]; s.Queued != 1 || s.Active != 0 || s.Completed != 1 {
+		t.Errorf("unexpected copier stage %+v", s)
+	}
+	if !snap.LastProgress.After(before) {
+		t.Error("expected progress to be recorded")
+	}
+
+	p.reset()
+	if p.snapshot(nil).busy() {
+		t.Error("expected an idle pipeline after reset")
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/syncthing/syncthing/internal/itererr"
+	"github.com/syncthing/syncthing/lib/build"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// Each list in a pull preview holds at most this many items; the counts
+// and byte totals are always complete.
+const pullPreviewMaxItems = 1000
+
+// PullPreview describes what the next pull of a folder would do, computed
+// from the database without touching the folder contents.
+type PullPreview struct {
+	Folder        string          `json:"folder"`
+	Download      PullPreviewList `json:"download"` // new or changed file contents
+	Metadata      PullPreviewList `json:"metadata"` // changes to permissions, times, etc. only
+	Create        PullPreviewList `json:"create"`   // directories and symlinks
+	Delete        PullPreviewList `json:"delete"`
+	Rename        PullPreviewList `json:"rename"`    // deletions and downloads done as a rename
+	Conflicts     PullPreviewList `json:"conflicts"` // also in one of the lists above
+	Unavailable   PullPreviewList `json:"unavailable"`
+	Ignored       int             `json:"ignored"`
+	TotalBytes    int64           `json:"totalBytes"`    // size of the files to download
+	DownloadBytes int64           `json:"downloadBytes"` // what isn't already in the current versions
+}
+
+type PullPreviewList struct {
+	Count int               `json:"count"`
+	Items []PullPreviewItem `json:"items"`
+}
+
+type PullPreviewItem struct {
+	Name       string           `json:"name"`
+	From       string           `json:"from,omitempty"` // renames only
+	Type       string           `json:"type"`
+	Size       int64            `json:"size"`
+	ModifiedBy protocol.ShortID `json:"modifiedBy"`
+}
+
+func (l *PullPreviewList) add(f protocol.FileInfo, from string) {
+	l.Count++
+	if len(l.Items) < pullPreviewMaxItems {
+		l.Items = append(l.Items, PullPreviewItem{
+			Name:       f.Name,
+			From:       from,
+			Type:       f.FileType().String(),
+			Size:       f.FileSize(),
+			ModifiedBy: f.ModifiedBy,
+		})
+	}
+}
+
+// PullPreview returns what the next pull iteration of the folder would do,
+// without applying anything. It follows the same decisions as the puller,
+// except that those depending on the folder contents (such as whether a
+// rename succeeds) are assumed to go the usual way.
+func (m *model) PullPreview(folder string) (*PullPreview, error) {
+	m.mut.RLock()
+	cfg, ok := m.folderCfgs[folder]
+	ignores := m.folderIgnores[folder]
+	m.mut.RUnlock()
+	if !ok {
+		return nil, ErrFolderMissing
+	}
+
+	preview := &PullPreview{Folder: folder}
+	if cfg.Type == config.FolderTypeSendOnly {
+		// Send only folders don't pull.
+		return preview, nil
+	}
+
+	fileDeletions := make(map[string]protocol.FileInfo)
+	buckets := make(map[string][]protocol.FileInfo)
+	var files []protocol.FileInfo
+
+	for file, err := range itererr.Zip(m.sdb.AllNeededGlobalFiles(folder, protocol.LocalDeviceID, cfg.Order, 0, 0)) {
+		if err != nil {
+			return nil, err
+		}
+		if cfg.IgnoresIncomingDeletes() && file.IsDeleted() {
+			continue
+		}
+		if m.heldByDirTombstone(folder, file) {
+			continue
+		}
+
+		switch {
+		case ignores != nil && ignores.Match(file.Name).IsIgnored():
+			preview.Ignored++
+
+		case build.IsWindows && fs.WindowsInvalidFilename(file.Name) != nil:
+			if !file.IsDeleted() {
+				preview.Unavailable.add(file, "")
+			}
+
+		case file.IsDeleted():
+			cur, hasCur, err := m.sdb.GetDeviceFile(folder, protocol.LocalDeviceID, file.Name)
+			if err != nil {
+				return nil, err
+			}
+			if hasCur && !cur.IsDeleted() && !cur.IsSymlink() && !cur.IsDirectory() && !cur.IsInvalid() && !file.IsDirectory() {
+				// Possibly a rename, known once the files are looked at
+				fileDeletions[file.Name] = file
+				key := string(cur.BlocksHash)
+				buckets[key] = append(buckets[key], cur)
+				continue
+			}
+			preview.Delete.add(file, "")
+			if hasCur && file.InConflictWith(cur) && !cur.IsSymlink() && !cur.IsDirectory() {
+				preview.Conflicts.add(file, "")
+			}
+
+		case file.Type == protocol.FileInfoTypeFile:
+			files = append(files, file)
+
+		default:
+			preview.Create.add(file, "")
+		}
+	}
+
+	for _, file := range files {
+		cur, hasCur, err := m.sdb.GetDeviceFile(folder, protocol.LocalDeviceID, file.Name)
+		if err != nil {
+			return nil, err
+		}
+		if hasCur && file.BlocksEqual(cur) {
+			preview.Metadata.add(file, "")
+			continue
+		}
+		if candidate, ok := popCandidate(buckets, string(file.BlocksHash)); ok {
+			preview.Rename.add(file, candidate.Name)
+			delete(fileDeletions, candidate.Name)
+			continue
+		}
+		if len(m.fileAvailability(cfg, file)) == 0 {
+			preview.Unavailable.add(file, "")
+			continue
+		}
+
+		preview.Download.add(file, "")
+		preview.TotalBytes += file.Size
+		if hasCur && !cur.IsDeleted() && !cur.IsDirectory() && !cur.IsSymlink() {
+			preview.DownloadBytes += localChangeDiff(file, cur).ChangedBytes
+			if file.InConflictWith(cur) {
+				preview.Conflicts.add(file, "")
+			}
+		} else {
+			preview.DownloadBytes += file.Size
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(fileDeletions)) {
+		file := fileDeletions[name]
+		preview.Delete.add(file, "")
+		if cur, ok, err := m.sdb.GetDeviceFile(folder, protocol.LocalDeviceID, file.Name); err == nil && ok && file.InConflictWith(cur) {
+			preview.Conflicts.add(file, "")
+		}
+	}
+
+	return preview, nil
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestPullPreview(t *testing.T) {
+	m, f, wcfgCancel := setupSendReceiveFolder(t)
+	defer wcfgCancel()
+	conn := addFakeConn(m, device1, f.ID)
+
+	file := func(name string, version protocol.Vector, blockNumbers ...int) protocol.FileInfo {
+		fi := setupFile(name, blockNumbers)
+		for _, b := range fi.Blocks {
+			fi.Size += int64(b.Size)
+		}
+		fi.BlocksHash = protocol.BlocksHash(fi.Blocks)
+		fi.Version = version
+		return fi
+	}
+	local := protocol.Vector{}.Update(myID.Short())
+	remote := local.Update(device1.Short())
+	concurrent := protocol.Vector{}.Update(device1.Short())
+
+	must(t, f.updateLocalsFromScanning([]protocol.FileInfo{
+		file("changed", local, 1, 2),
+		file("old", local, 3, 4),
+		file("conflict", local, 5),
+		file("same", local, 6),
+	}))
+
+	deleted := file("old", remote)
+	deleted.Deleted = true
+	same := file("same", remote, 6)
+	same.Permissions = 0o600
+	must(t, m.Index(conn, &protocol.Index{Folder: f.ID, Files: []protocol.FileInfo{
+		file("changed", remote, 1, 7),
+		deleted,
+		file("new", remote, 3, 4),
+		file("conflict", concurrent, 8),
+		same,
+		{Name: "dir", Type: protocol.FileInfoTypeDirectory, Version: remote},
+	}}))
+
+	preview, err := m.PullPreview(f.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := func(l PullPreviewList) []string {
+		var res []string
+		for _, it := range l.Items {
+			res = append(res, it.Name)
+		}
+		return res
+	}
+	if preview.Download.Count != 2 {
+		t.Errorf("expected two downloads, got %v", names(preview.Download))
+	}
+	if preview.Rename.Count != 1 || preview.Rename.Items[0].Name != "new" || preview.Rename.Items[0].From != "old" {
+		t.Errorf("expected old to be renamed to new, got %+v", preview.Rename.Items)
+	}
+	if preview.Delete.Count != 0 {
+		t.Errorf("renamed file should not be deleted, got %v", names(preview.Delete))
+	}
+	if preview.Metadata.Count != 1 || preview.Metadata.Items[0].Name != "same" {
+		t.Errorf("expected metadata update of same, got %v", names(preview.Metadata))
+	}
+	if preview.Create.Count != 1 || preview.Create.Items[0].Name != "dir" {
+		t.Errorf("expected dir to be created, got %v", names(preview.Create))
+	}
+	if preview.Conflicts.Count != 1 || preview.Conflicts.Items[0].Name != "conflict" {
+		t.Errorf("expected a conflict on conflict, got %v", names(preview.Conflicts))
+	}
+
+	// Of the changed file only the new block needs downloading
+	exp := int64(blocks[7].Size + blocks[8].Size)
+	if preview.DownloadBytes != exp {
+		t.Errorf("expected %d bytes to download, got %d", exp, preview.DownloadBytes)
+	}
+	if preview.TotalBytes != exp+int64(blocks[1].Size) {
+		t.Errorf("unexpected total bytes %d", preview.TotalBytes)
+	}
+
+	// Nothing was applied
+	if changed, ok := m.testCurrentFolderFile(f.ID, "changed"); !ok || !changed.Version.Equal(local) {
+		t.Error("preview should not change the local files")
+	}
+}
@@ -0,0 +1,180 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// pullScheduler shares the outstanding block requests to a device fairly
+// between the folders pulling from it. Each folder gets a share of the
+// capacity in proportion to its weight, as long as it has requests
+// waiting, so that one big folder can't starve the others. This is start
+// time fair queuing: every folder carries a virtual time tag advanced by
+// size/weight for each request granted, and the waiting folder with the
+// lowest tag goes next.
+type pullScheduler struct {
+	device string
+
+	mut      sync.Mutex
+	capacity int
+	inUse    int
+	vtime    float64 // tag of the last granted request
+	folders  map[string]*pullSchedulerFolder
+}
+
+type pullSchedulerFolder struct {
+	tag     float64
+	waiting []*pullRequestWaiter
+}
+
+type pullRequestWaiter struct {
+	bytes   int
+	weight  int
+	granted chan struct{}
+}
+
+// newPullScheduler returns a scheduler for the given number of bytes of
+// outstanding requests, zero meaning unlimited.
+func newPullScheduler(device protocol.DeviceID, capacity int) *pullScheduler {
+	return &pullScheduler{
+		device:   device.String(),
+		capacity: capacity,
+		folders:  make(map[string]*pullSchedulerFolder),
+	}
+}
+
+// take waits for the folder's turn to have a request of the given size
+// outstanding. The returned function must be called when the request is
+// done.
+func (s *pullScheduler) take(ctx context.Context, folder string, weight, bytes int) (func(), error) {
+	if weight <= 0 {
+		weight = 1
+	}
+	t0 := time.Now()
+
+	s.mut.Lock()
+	if s.capacity <= 0 {
+		s.mut.Unlock()
+		metricFolderPullRequestedBytes.WithLabelValues(folder, s.device).Add(float64(bytes))
+		return func() {}, nil
+	}
+	if bytes > s.capacity {
+		// A request larger than the capacity would never fit; it gets
+		// the whole capacity instead.
+		bytes = s.capacity
+	}
+	w := &pullRequestWaiter{bytes: bytes, weight: weight, granted: make(chan struct{})}
+	f, ok := s.folders[folder]
+	if !ok {
+		f = &pullSchedulerFolder{}
+		s.folders[folder] = f
+	}
+	if len(f.waiting) == 0 && f.tag < s.vtime {
+		// A folder becoming active doesn't get credit for the time it
+		// was idle.
+		f.tag = s.vtime
+	}
+	f.waiting = append(f.waiting, w)
+	s.dispatchLocked()
+	s.mut.Unlock()
+
+	select {
+	case <-w.granted:
+	case <-ctx.Done():
+		s.mut.Lock()
+		select {
+		case <-w.granted:
+			// Granted just now, so hand it back.
+			s.mut.Unlock()
+			s.give(bytes)
+		default:
+			f.remove(w)
+			s.dispatchLocked()
+			s.mut.Unlock()
+		}
+		return nil, ctx.Err()
+	}
+
+	metricFolderPullRequestedBytes.WithLabelValues(folder, s.device).Add(float64(bytes))
+	metricFolderPullWaitSeconds.WithLabelValues(folder, s.device).Add(time.Since(t0).Seconds())
+	var once sync.Once
+	return func() { once.Do(func() { s.give(bytes) }) }, nil
+}
+
+func (s *pullScheduler) give(bytes int) {
+	s.mut.Lock()
+	s.inUse = max(s.inUse-bytes, 0)
+	s.dispatchLocked()
+	s.mut.Unlock()
+}
+
+// setCapacity changes the capacity, zero meaning unlimited. Requests
+// already outstanding aren't affected.
+func (s *pullScheduler) setCapacity(capacity int) {
+	s.mut.Lock()
+	s.capacity = capacity
+	if capacity <= 0 {
+		// Let everyone waiting through; what they give back later is
+		// of no consequence.
+		for _, f := range s.folders {
+			for _, w := range f.waiting {
+				close(w.granted)
+			}
+			f.waiting = nil
+		}
+		s.inUse = 0
+	} else {
+		s.dispatchLocked()
+	}
+	s.mut.Unlock()
+}
+
+// dispatchLocked grants waiting requests in order of their folders' tags,
+// as long as there is capacity.
+func (s *pullScheduler) dispatchLocked() {
+	for {
+		var next *pullSchedulerFolder
+		for name, f := range s.folders {
+			if len(f.waiting) == 0 {
+				if f.tag <= s.vtime {
+					delete(s.folders, name)
+				}
+				continue
+			}
+			if next == nil || f.tag < next.tag {
+				next = f
+			}
+		}
+		if next == nil {
+			return
+		}
+
+		w := next.waiting[0]
+		if s.inUse > 0 && s.inUse+w.bytes > s.capacity {
+			return
+		}
+		next.waiting = next.waiting[1:]
+		s.inUse += w.bytes
+		s.vtime = next.tag
+		next.tag += float64(w.bytes) / float64(w.weight)
+		close(w.granted)
+	}
+}
+
+func (f *pullSchedulerFolder) remove(w *pullRequestWaiter) {
+	for i, o := range f.waiting {
+		if o == w {
+			f.waiting = append(f.waiting[:i], f.waiting[i+1:]...)
+			return
+		}
+	}
+}
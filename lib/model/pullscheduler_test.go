@@ -0,0 +1,102 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPullSchedulerWeights(t *testing.T) {
+	const size = 128 << 10
+	s := newPullScheduler(device1, size)
+
+	// Hold the capacity while the folders queue up.
+	hold, err := s.take(context.Background(), "other", 1, size)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mut sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	for _, folder := range []string{"big", "important"} {
+		weight := 1
+		if folder == "important" {
+			weight = 3
+		}
+		for range 8 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				done, err := s.take(context.Background(), folder, weight, size)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				mut.Lock()
+				order = append(order, folder)
+				mut.Unlock()
+				done()
+			}()
+		}
+	}
+	waitQueued(t, s, 16)
+	hold()
+	wg.Wait()
+
+	important := 0
+	for _, folder := range order[:8] {
+		if folder == "important" {
+			important++
+		}
+	}
+	if important != 6 {
+		t.Errorf("expected six of the first eight requests for the important folder, got %d: %v", important, order)
+	}
+}
+
+func TestPullSchedulerCancel(t *testing.T) {
+	s := newPullScheduler(device1, 1)
+	hold, err := s.take(context.Background(), "default", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := s.take(ctx, "default", 1, 1); err == nil {
+		t.Fatal("expected the request to time out")
+	}
+	hold()
+
+	// The cancelled request doesn't hold on to the capacity
+	done, err := s.take(context.Background(), "default", 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done()
+}
+
+func waitQueued(t *testing.T, s *pullScheduler, n int) {
+	t.Helper()
+	for range 1000 {
+		s.mut.Lock()
+		queued := 0
+		for _, f := range s.folders {
+			queued += len(f.waiting)
+		}
+		s.mut.Unlock()
+		if queued == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("requests were not queued")
+}
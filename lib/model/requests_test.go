@@ -1061,6 +1061,55 @@ func TestIgnoreDeleteUnignore(t *testing.T) {
 	}
 }
 
+// TestArchiveModeKeepsDeletionsLocal checks that local deletions in an
+// archive folder aren't announced, while new files are.
+func TestArchiveModeKeepsDeletionsLocal(t *testing.T) {
+	w, fcfg, wCancel := newDefaultCfgWrapper()
+	defer wCancel()
+	fcfg.ArchiveMode = true
+	setFolder(t, w, fcfg)
+	m := setupModel(t, w)
+	fss := fcfg.Filesystem()
+	defer cleanupModel(m)
+
+	fc := addFakeConn(m, device1, fcfg.ID)
+
+	received := make(chan []protocol.FileInfo, 10)
+	fc.setIndexFn(func(_ context.Context, _ string, fs []protocol.FileInfo) error {
+		received <- fs
+		return nil
+	})
+	waitFor := func(name string) []protocol.FileInfo {
+		t.Helper()
+		timeout := time.After(5 * time.Second)
+		for {
+			select {
+			case fs := <-received:
+				for _, f := range fs {
+					if f.Name == name {
+						return fs
+					}
+				}
+			case <-timeout:
+				t.Fatalf("timed out waiting for index entry for %s", name)
+			}
+		}
+	}
+
+	writeFile(t, fss, "a", []byte("a"))
+	m.ScanFolders()
+	waitFor("a")
+
+	must(t, fss.Remove("a"))
+	writeFile(t, fss, "b", []byte("b"))
+	m.ScanFolders()
+	for _, f := range waitFor("b") {
+		if f.Name == "a" {
+			t.Errorf("deletion of a was announced: %v", f)
+		}
+	}
+}
+
 // TestRequestLastFileProgress checks that the last pulled file (here only) is registered
 // as in progress.
 func TestRequestLastFileProgress(t *testing.T) {
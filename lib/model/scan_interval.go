@@ -0,0 +1,118 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+const (
+	// The adaptive rescan interval stays between the configured interval
+	// divided by the first and multiplied by the second.
+	adaptiveRescanMinDivisor = 4
+	adaptiveRescanMaxFactor  = 8
+	// The number of consecutive periodic scans finding nothing, with the
+	// watcher working and losing nothing, before the interval is
+	// lengthened. Scans after the watcher delivered events count twice.
+	adaptiveRescanQuietScans = 3
+)
+
+// rescanTuner adapts the interval between periodic full scans to what they
+// and the watcher find. With a working watcher such scans are a safety net;
+// as long as they come up empty and the watcher loses no events the interval
+// doubles, up to the maximum, sooner if the watcher is seen delivering
+// events. A scan finding changes means the watcher missed them, as does the
+// watcher overflowing, and the interval is halved, down to the minimum.
+// Without a working watcher the configured interval is used.
+type rescanTuner struct {
+	base, min, max time.Duration
+	cur            time.Duration
+	quiet          int // consecutive scans without changes or lost events
+}
+
+// watchActivity is what the watcher did since the previous periodic scan.
+type watchActivity struct {
+	working bool
+	events  int64 // delivered
+	lost    int64 // dropped events and overflows
+}
+
+func newRescanTuner(base time.Duration) rescanTuner {
+	maxInterval := base * adaptiveRescanMaxFactor
+	if limit := config.MaxRescanIntervalS * time.Second; maxInterval > limit {
+		maxInterval = limit
+	}
+	return rescanTuner{
+		base: base,
+		min:  base / adaptiveRescanMinDivisor,
+		max:  maxInterval,
+		cur:  base,
+	}
+}
+
+// update records the outcome of a periodic full scan and returns the
+// interval until the next one.
+func (t *rescanTuner) update(changes int, watch watchActivity) time.Duration {
+	switch {
+	case !watch.working:
+		t.cur = t.base
+		t.quiet = 0
+	case changes > 0 || watch.lost > 0:
+		t.cur = max(t.cur/2, t.min)
+		t.quiet = 0
+	default:
+		t.quiet++
+		if watch.events > 0 {
+			// The watcher demonstrably works, not just idles
+			t.quiet++
+		}
+		if t.quiet >= adaptiveRescanQuietScans {
+			t.cur = min(t.cur*2, t.max)
+			t.quiet = 0
+		}
+	}
+	return t.cur
+}
+
+// tuneRescanInterval adapts the rescan interval after a successful periodic
+// full scan, if so configured.
+func (f *folder) tuneRescanInterval() {
+	if !f.AdaptiveRescanInterval || f.scanInterval == 0 {
+		return
+	}
+	watch := watchActivity{working: f.FSWatcherEnabled && f.WatchError() == nil}
+	if stats, ok := fs.GetWatchStats(f.mtimefs, "."); ok {
+		delta := stats.Sub(f.lastWatchStats)
+		watch.events = delta.Processed
+		watch.lost = delta.Dropped + delta.Overflows
+		f.lastWatchStats = stats
+	} else {
+		watch.working = false
+		f.lastWatchStats = fs.WatchStats{}
+	}
+	prev := f.RescanInterval()
+	next := f.rescanTuner.update(f.lastScanChanges, watch)
+	if next != prev {
+		f.sl.Debug("Adjusted rescan interval", slog.Duration("from", prev), slog.Duration("to", next), slog.Int64("watchEvents", watch.events), slog.Int64("watchLost", watch.lost))
+	}
+	f.setRescanInterval(next)
+}
+
+// RescanInterval returns the current interval between periodic full scans,
+// zero if disabled.
+func (f *folder) RescanInterval() time.Duration {
+	return time.Duration(f.rescanInterval.Load())
+}
+
+func (f *folder) setRescanInterval(d time.Duration) {
+	f.rescanInterval.Store(int64(d))
+	metricFolderRescanInterval.WithLabelValues(f.ID).Set(d.Seconds())
+}
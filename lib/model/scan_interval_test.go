@@ -0,0 +1,81 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRescanTuner(t *testing.T) {
+	base := time.Hour
+	tuner := newRescanTuner(base)
+	idle := watchActivity{working: true}
+
+	quietScans := func(n int) time.Duration {
+		var d time.Duration
+		for i := 0; i < n; i++ {
+			d = tuner.update(0, idle)
+		}
+		return d
+	}
+
+	// Lengthened after enough quiet scans, up to the maximum
+	if d := quietScans(adaptiveRescanQuietScans - 1); d != base {
+		t.Errorf("lengthened too early to %v", d)
+	}
+	if d := quietScans(1); d != 2*base {
+		t.Errorf("expected %v after quiet scans, got %v", 2*base, d)
+	}
+	if d := quietScans(10 * adaptiveRescanQuietScans); d != adaptiveRescanMaxFactor*base {
+		t.Errorf("expected maximum %v, got %v", adaptiveRescanMaxFactor*base, d)
+	}
+
+	// Shortened by scans finding changes, down to the minimum
+	if d := tuner.update(5, idle); d != adaptiveRescanMaxFactor*base/2 {
+		t.Errorf("expected %v after changes, got %v", adaptiveRescanMaxFactor*base/2, d)
+	}
+	for i := 0; i < 10; i++ {
+		tuner.update(1, idle)
+	}
+	if d := tuner.update(1, idle); d != base/adaptiveRescanMinDivisor {
+		t.Errorf("expected minimum %v, got %v", base/adaptiveRescanMinDivisor, d)
+	}
+
+	// Back to the configured interval without a working watcher
+	if d := tuner.update(0, watchActivity{}); d != base {
+		t.Errorf("expected %v without watcher, got %v", base, d)
+	}
+	if d := quietScans(adaptiveRescanQuietScans - 1); d != base {
+		t.Errorf("quiet scans without watcher counted, got %v", d)
+	}
+}
+
+func TestRescanTunerWatchActivity(t *testing.T) {
+	base := time.Hour
+	tuner := newRescanTuner(base)
+
+	// A watcher delivering events without losing any lengthens the
+	// interval sooner
+	busy := watchActivity{working: true, events: 100}
+	for i := 0; i < (adaptiveRescanQuietScans+1)/2-1; i++ {
+		if d := tuner.update(0, busy); d != base {
+			t.Fatalf("lengthened too early to %v", d)
+		}
+	}
+	if d := tuner.update(0, busy); d != 2*base {
+		t.Errorf("expected %v after busy scans, got %v", 2*base, d)
+	}
+
+	// Lost events shorten it, even if the scan found nothing
+	if d := tuner.update(0, watchActivity{working: true, events: 100, lost: 1}); d != base {
+		t.Errorf("expected %v after lost events, got %v", base, d)
+	}
+	if d := tuner.update(0, watchActivity{working: true, lost: 3}); d != base/2 {
+		t.Errorf("expected %v after lost events, got %v", base/2, d)
+	}
+}
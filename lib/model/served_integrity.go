@@ -0,0 +1,173 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"bytes"
+	"log/slog"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/scanner"
+)
+
+// The number of served blocks whose verification outcome is remembered, so
+// that blocks requested repeatedly, e.g. by several devices, are hashed
+// only once.
+const servedBlockCacheSize = 16384
+
+type servedBlockResult int
+
+const (
+	servedBlockUnverifiable servedBlockResult = iota
+	servedBlockIntact
+	servedBlockCorrupt
+)
+
+// servedBlockKey identifies a block of a file as scanned; any update of
+// the file in the index, or change of its size or modification time, makes
+// for a new key.
+type servedBlockKey struct {
+	folder, name string
+	offset       int64
+	hash         string
+	sequence     int64
+	size         int64
+	modTime      int64
+}
+
+// servedBlockCache remembers whether recently served blocks were intact.
+type servedBlockCache struct {
+	*lru.Cache[servedBlockKey, bool]
+}
+
+func newServedBlockCache() *servedBlockCache {
+	cache, _ := lru.New[servedBlockKey, bool](servedBlockCacheSize)
+	return &servedBlockCache{cache}
+}
+
+// verifyServedBlock checks the data read to serve a request against the
+// block hash in the local index. Requests not for exactly a block in the
+// index, e.g. for another version of the file or for part of a block, are
+// unverifiable. On a mismatch the whole block is read and hashed again;
+// only if that doesn't match either, on a file that is unchanged on disk
+// since it was scanned, the data went bad at rest and the file is
+// quarantined.
+func (m *model) verifyServedBlock(folderCfg config.FolderConfiguration, folderFs fs.Filesystem, req *protocol.Request, data []byte) servedBlockResult {
+	cf, ok, err := m.sdb.GetDeviceFile(folderCfg.ID, protocol.LocalDeviceID, req.Name)
+	if err != nil || !ok || cf.IsDeleted() || cf.IsInvalid() || cf.Type != protocol.FileInfoTypeFile {
+		return servedBlockUnverifiable
+	}
+	idx := int(req.Offset / int64(cf.BlockSize()))
+	if idx >= len(cf.Blocks) || cf.Blocks[idx].Offset != req.Offset {
+		return servedBlockUnverifiable
+	}
+	block := cf.Blocks[idx]
+	if req.Size != block.Size || len(data) != block.Size {
+		return servedBlockUnverifiable
+	}
+	if len(req.Hash) > 0 && !bytes.Equal(block.Hash, req.Hash) {
+		return servedBlockUnverifiable
+	}
+	if cf.Version.IsEmpty() {
+		// Quarantined, waiting to be pulled again
+		return servedBlockCorrupt
+	}
+	if !unchangedOnDisk(folderFs, cf, folderCfg.ModTimeWindow()) {
+		return servedBlockUnverifiable
+	}
+
+	key := servedBlockKey{
+		folder:   folderCfg.ID,
+		name:     req.Name,
+		offset:   req.Offset,
+		hash:     string(block.Hash),
+		sequence: cf.Sequence,
+		size:     cf.Size,
+		modTime:  cf.ModTime().UnixNano(),
+	}
+	if intact, ok := m.servedBlocks.Get(key); ok {
+		if intact {
+			return servedBlockIntact
+		}
+		return servedBlockCorrupt
+	}
+
+	if scanner.Validate(data, block.Hash) {
+		m.servedBlocks.Add(key, true)
+		return servedBlockIntact
+	}
+	// The read may have been off rather than the data on disk
+	intact, ok := rereadBlock(folderFs, cf.Name, block)
+	if !ok || intact || !unchangedOnDisk(folderFs, cf, folderCfg.ModTimeWindow()) {
+		return servedBlockUnverifiable
+	}
+	m.servedBlocks.Add(key, false)
+
+	metricFolderServedBlocksCorrupt.WithLabelValues(folderCfg.ID).Inc()
+	slog.Warn("Local file data is corrupt; withdrawing it to be replaced from other devices", folderCfg.LogAttr(), slogutil.FilePath(req.Name), slog.Int64("offset", req.Offset))
+	m.evLogger.Log(events.LocalBlockCorruption, map[string]interface{}{
+		"folder": folderCfg.ID,
+		"item":   req.Name,
+		"offset": req.Offset,
+	})
+
+	m.mut.RLock()
+	runner, ok := m.folderRunners.Get(folderCfg.ID)
+	m.mut.RUnlock()
+	if ok {
+		go func() {
+			if err := runner.quarantineFile(cf.Name, cf.Version); err != nil {
+				slog.Warn("Failed to quarantine corrupt file", folderCfg.LogAttr(), slogutil.FilePath(cf.Name), slogutil.Error(err))
+			}
+		}()
+	}
+	return servedBlockCorrupt
+}
+
+// rereadBlock reads the block from the file again and returns whether it
+// matches its hash, and whether it could be read at all.
+func rereadBlock(folderFs fs.Filesystem, name string, block protocol.BlockInfo) (intact, ok bool) {
+	fd, err := folderFs.Open(name)
+	if err != nil {
+		return false, false
+	}
+	defer fd.Close()
+	buf := make([]byte, block.Size)
+	if _, err := fd.ReadAt(buf, block.Offset); err != nil {
+		return false, false
+	}
+	return scanner.Validate(buf, block.Hash), true
+}
+
+// quarantineFile withdraws the local version of a file whose data was found
+// corrupt. The entry keeps its metadata, so that the scanner doesn't take
+// the corrupt data for a change, but loses its version. The file is thus no
+// longer announced as current and is pulled again from devices having it.
+func (f *folder) quarantineFile(name string, version protocol.Vector) error {
+	return f.doInSync(func() error {
+		fi, ok, err := f.db.GetDeviceFile(f.folderID, protocol.LocalDeviceID, name)
+		if err != nil {
+			return err
+		}
+		if !ok || !fi.Version.Equal(version) {
+			// Changed meanwhile
+			return nil
+		}
+		fi.Version = protocol.Vector{}
+		if err := f.updateLocals([]protocol.FileInfo{fi}); err != nil {
+			return err
+		}
+		f.SchedulePull()
+		return nil
+	})
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestVerifyServedBlock(t *testing.T) {
+	m, f, wcfgCancel := setupSendReceiveFolder(t)
+	defer wcfgCancel()
+	ffs := f.Filesystem()
+
+	data := []byte("served block data")
+	writeFile(t, ffs, "file", data)
+	must(t, f.scanSubdirs(nil))
+	fi, ok, err := m.sdb.GetDeviceFile(f.folderID, protocol.LocalDeviceID, "file")
+	if err != nil || !ok {
+		t.Fatal("file not in the index", err)
+	}
+	req := &protocol.Request{Folder: f.ID, Name: "file", Size: len(data), Hash: fi.Blocks[0].Hash}
+
+	if res := m.verifyServedBlock(f.FolderConfiguration, ffs, req, data); res != servedBlockIntact {
+		t.Fatalf("intact block verified as %v", res)
+	}
+	// Served from the cache the second time around
+	if res := m.verifyServedBlock(f.FolderConfiguration, ffs, req, data); res != servedBlockIntact {
+		t.Fatalf("cached intact block verified as %v", res)
+	}
+
+	// Data that went bad on the way, while the file is fine, doesn't make
+	// it corrupt.
+	m.servedBlocks.Purge()
+	if res := m.verifyServedBlock(f.FolderConfiguration, ffs, req, bytes.ToUpper(data)); res != servedBlockUnverifiable {
+		t.Fatalf("misread block verified as %v", res)
+	}
+	// Nor can part of a block be verified
+	partReq := &protocol.Request{Folder: f.ID, Name: "file", Size: len(data) - 1, Hash: fi.Blocks[0].Hash}
+	if res := m.verifyServedBlock(f.FolderConfiguration, ffs, partReq, data[:len(data)-1]); res != servedBlockUnverifiable {
+		t.Fatalf("partial block verified as %v", res)
+	}
+	if fi, _, _ := m.sdb.GetDeviceFile(f.folderID, protocol.LocalDeviceID, "file"); fi.Version.IsEmpty() {
+		t.Fatal("file quarantined")
+	}
+
+	// Bit rot: different data, same size and modification time
+	rotten := bytes.ToUpper(data)
+	writeFile(t, ffs, "file", rotten)
+	must(t, ffs.Chtimes("file", fi.ModTime(), fi.ModTime()))
+	// The cache would vouch for the block until evicted
+	m.servedBlocks.Purge()
+	if res := m.verifyServedBlock(f.FolderConfiguration, ffs, req, rotten); res != servedBlockCorrupt {
+		t.Fatalf("corrupt block verified as %v", res)
+	}
+
+	// A request for another version of the file can't be verified
+	req.Hash = []byte("some other hash")
+	if res := m.verifyServedBlock(f.FolderConfiguration, ffs, req, rotten); res != servedBlockUnverifiable {
+		t.Fatalf("block of another version verified as %v", res)
+	}
+}
@@ -75,6 +75,23 @@ func newSharedPullerState(file protocol.FileInfo, fs fs.Filesystem, folderID, te
 	}
 }
 
+// newInterruptedPullerState returns the state of a pull that is not in
+// progress, for a temporary file last written at modTime and holding the
+// given blocks of the file.
+func newInterruptedPullerState(file protocol.FileInfo, folderID string, available []int, modTime time.Time) *sharedPullerState {
+	return &sharedPullerState{
+		file:             file,
+		folder:           folderID,
+		tempName:         fs.TempName(file.Name),
+		realName:         file.Name,
+		updated:          modTime,
+		available:        available,
+		availableUpdated: modTime,
+		created:          modTime,
+		closed:           true,
+	}
+}
+
 // A momentary state representing the progress of the puller
 type PullerProgress struct {
 	Total                   int   `json:"total"`
@@ -103,6 +120,18 @@ func (w *lockedWriterAt) WriteAt(p []byte, off int64) (n int, err error) {
 	return w.fd.WriteAt(p, off)
 }
 
+// ReadBack syncs the file to disk and reads what was written at off into p,
+// for verifying writes. Like WriteAt it just needs a read-lock.
+func (w *lockedWriterAt) ReadBack(p []byte, off int64) error {
+	w.mut.RLock()
+	defer w.mut.RUnlock()
+	if err := w.fd.Sync(); err != nil {
+		return fmt.Errorf("sync: %w", err)
+	}
+	_, err := w.fd.ReadAt(p, off)
+	return err
+}
+
 // SyncClose ensures that no more writes are happening before going ahead and
 // syncing and closing the fd, thus needs to acquire a write-lock.
 func (w *lockedWriterAt) SyncClose(fsync bool) error {
@@ -329,11 +358,13 @@ func (s *sharedPullerState) finalClose() (bool, error) {
 		// time to do so. This also truncates the file to the correct size
 		// if we're using sparse file.
 		if err := s.addWriterLocked(); err != nil {
-			return false, err
+			// Nothing more will happen to the file; close it as failed,
+			// so that it is accounted as done with.
+			s.failLocked(err)
 		}
 	}
 
-	if len(s.file.Encrypted) > 0 {
+	if len(s.file.Encrypted) > 0 && s.writer != nil {
 		if err := s.finalizeEncrypted(); err != nil && s.err == nil {
 			// This is our error as we weren't errored before.
 			s.err = err
@@ -0,0 +1,138 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/db"
+	"github.com/syncthing/syncthing/internal/itererr"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+)
+
+const (
+	standbyIndexKeyPrefix = "folderstandby/"
+
+	// How often expired standby indexes are dropped
+	standbyIndexExpiryInterval = time.Hour
+)
+
+// standbyIndex records the folder a removed folder's index data belongs
+// to, while it's kept around for a possible re-add.
+type standbyIndex struct {
+	Path           string                `json:"path"`
+	FilesystemType config.FilesystemType `json:"filesystemType"`
+	Expires        time.Time             `json:"expires"`
+}
+
+func (s standbyIndex) matches(cfg config.FolderConfiguration) bool {
+	return s.Path == cfg.Path && s.FilesystemType == cfg.FilesystemType && time.Now().Before(s.Expires)
+}
+
+// putFolderOnStandby records the folder's index to be kept after the
+// folder is removed, returning whether it should be. That's only the case
+// when the folder is healthy, as otherwise there is no telling whether the
+// index matches what will be found on a re-add.
+func (m *model) putFolderOnStandby(cfg config.FolderConfiguration) bool {
+	keep := time.Duration(m.cfg.Options().KeepRemovedFolderIndexH) * time.Hour
+	if keep <= 0 || cfg.Type == config.FolderTypeReceiveEncrypted || cfg.CheckPath() != nil {
+		return false
+	}
+	bs, err := json.Marshal(standbyIndex{
+		Path:           cfg.Path,
+		FilesystemType: cfg.FilesystemType,
+		Expires:        time.Now().Add(keep),
+	})
+	if err != nil {
+		return false
+	}
+	if err := m.sdb.PutKV(standbyIndexKeyPrefix+cfg.ID, bs); err != nil {
+		slog.Warn("Failed to keep folder index on standby", cfg.LogAttr(), slogutil.Error(err))
+		return false
+	}
+	slog.Info("Keeping folder index on standby", cfg.LogAttr(), slog.Duration("for", keep))
+	return true
+}
+
+// restoreFolderFromStandby decides what to do with a standby index when the
+// folder is added again. The index is reused when it was kept for the same
+// path and the folder marker is still in place, in which case the initial
+// scan only looks for what changed meanwhile. Otherwise it's dropped, to be
+// built from scratch.
+func (m *model) restoreFolderFromStandby(cfg config.FolderConfiguration) {
+	key := standbyIndexKeyPrefix + cfg.ID
+	bs, err := m.sdb.GetKV(key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return
+	} else if err != nil {
+		slog.Warn("Failed to look up standby folder index", cfg.LogAttr(), slogutil.Error(err))
+		return
+	}
+	_ = m.sdb.DeleteKV(key)
+
+	var standby standbyIndex
+	if err := json.Unmarshal(bs, &standby); err == nil && standby.matches(cfg) && cfg.CheckPath() == nil {
+		slog.Info("Restored folder index from standby", cfg.LogAttr())
+		return
+	}
+	slog.Info("Discarding standby folder index", cfg.LogAttr())
+	_ = m.sdb.DropFolder(cfg.ID)
+}
+
+// expireStandbyIndexes drops the index data of removed folders once it has
+// been on standby for long enough.
+func (m *model) expireStandbyIndexes() {
+	for _, folder := range ExpiredStandbyIndexes(m.sdb) {
+		m.mut.RLock()
+		_, ok := m.folderCfgs[folder]
+		m.mut.RUnlock()
+		if ok {
+			// Added again meanwhile
+			continue
+		}
+		slog.Info("Dropping expired standby folder index", slog.String("folder", folder))
+		_ = m.sdb.DropFolder(folder)
+	}
+}
+
+// ExpiredStandbyIndexes removes the standby records that have expired and
+// returns the IDs of their folders, whose index data can then be dropped.
+func ExpiredStandbyIndexes(kv db.KV) []string {
+	it, errFn := kv.PrefixKV(standbyIndexKeyPrefix)
+	var expired []string
+	for entry, err := range itererr.Zip(it, errFn) {
+		if err != nil {
+			return expired
+		}
+		var standby standbyIndex
+		if err := json.Unmarshal(entry.Value, &standby); err == nil && time.Now().Before(standby.Expires) {
+			continue
+		}
+		expired = append(expired, strings.TrimPrefix(entry.Key, standbyIndexKeyPrefix))
+	}
+	for _, folder := range expired {
+		_ = kv.DeleteKV(standbyIndexKeyPrefix + folder)
+	}
+	return expired
+}
+
+// IsFolderOnStandby returns whether the index data of the removed folder is
+// being kept for a possible re-add.
+func IsFolderOnStandby(kv db.KV, folder string) bool {
+	bs, err := kv.GetKV(standbyIndexKeyPrefix + folder)
+	if err != nil {
+		return false
+	}
+	var standby standbyIndex
+	return json.Unmarshal(bs, &standby) == nil && time.Now().Before(standby.Expires)
+}
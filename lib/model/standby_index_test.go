@@ -0,0 +1,70 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestStandbyIndexRestore(t *testing.T) {
+	w, fcfg, wCancel := newDefaultCfgWrapper()
+	defer wCancel()
+	writeFile(t, fcfg.Filesystem(), "file", []byte("data"))
+
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+	seq, err := m.sdb.GetDeviceSequence(fcfg.ID, protocol.LocalDeviceID)
+	if err != nil || seq == 0 {
+		t.Fatal("expected a scanned folder", seq, err)
+	}
+
+	waiter, err := w.RemoveFolder(fcfg.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waiter.Wait()
+	if !IsFolderOnStandby(m.sdb, fcfg.ID) {
+		t.Fatal("expected the index on standby")
+	}
+	if fcfg.CheckPath() != nil {
+		t.Fatal("expected the marker to be kept")
+	}
+
+	setFolder(t, w, fcfg)
+	if IsFolderOnStandby(m.sdb, fcfg.ID) {
+		t.Error("expected the standby record to be consumed")
+	}
+	if after, err := m.sdb.GetDeviceSequence(fcfg.ID, protocol.LocalDeviceID); err != nil || after != seq {
+		t.Errorf("expected the index to be restored, sequence %d != %d (%v)", after, seq, err)
+	}
+}
+
+func TestStandbyIndexPathChanged(t *testing.T) {
+	w, fcfg, wCancel := newDefaultCfgWrapper()
+	defer wCancel()
+	writeFile(t, fcfg.Filesystem(), "file", []byte("data"))
+
+	m := setupModel(t, w)
+	defer cleanupModel(m)
+
+	if !m.putFolderOnStandby(fcfg) {
+		t.Fatal("expected the index to be put on standby")
+	}
+
+	moved := fcfg
+	moved.Path += "-elsewhere"
+	m.restoreFolderFromStandby(moved)
+	if seq, _ := m.sdb.GetDeviceSequence(fcfg.ID, protocol.LocalDeviceID); seq != 0 {
+		t.Error("expected the index to be dropped for a different path")
+	}
+	if m.putFolderOnStandby(config.FolderConfiguration{ID: "missing", FilesystemType: config.FilesystemTypeFake, Path: "missing"}) {
+		t.Error("expected no standby for a folder without marker")
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/itererr"
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/scanner"
+)
+
+// TempCleanupReport describes what a run of the temporary file janitor
+// found and did in a folder.
+type TempCleanupReport struct {
+	Folder string    `json:"folder"`
+	Time   time.Time `json:"time"`
+	Found  int       `json:"found"` // temporary files in the folder
+	// Removed as belonging to no needed file, or as too old
+	Orphaned       int   `json:"orphaned"`
+	Expired        int   `json:"expired"`
+	ReclaimedBytes int64 `json:"reclaimedBytes"`
+	// Kept for the puller to reuse, and their size
+	Kept      int   `json:"kept"`
+	KeptBytes int64 `json:"keptBytes"`
+}
+
+func (r *TempCleanupReport) removed() int {
+	return r.Orphaned + r.Expired
+}
+
+// CleanTempFiles runs the temporary file janitor on the folder now,
+// between scans and pulls, and returns what it did.
+func (f *folder) CleanTempFiles() (*TempCleanupReport, error) {
+	var report *TempCleanupReport
+	err := f.doInSync(func() error {
+		var err error
+		report, err = f.cleanTempFiles()
+		return err
+	})
+	return report, err
+}
+
+func (f *folder) tempCleanupTimerFired() {
+	if _, err := f.cleanTempFiles(); err != nil {
+		f.sl.Warn("Failed to clean up temporary files", slogutil.Error(err))
+	}
+	f.tempCleanupTimer.Reset(f.tempCleanupInterval())
+}
+
+func (f *folder) tempCleanupInterval() time.Duration {
+	interval := time.Duration(f.model.cfg.Options().TempCleanupIntervalS) * time.Second
+	if interval <= 0 {
+		// Disabled for now, but check again in a while in case that
+		// changes.
+		return time.Hour
+	}
+	return interval
+}
+
+// cleanTempFiles looks for temporary files left behind by crashes and
+// failed pulls. As it runs on the folder routine, none of them belong to
+// a pull in progress. Those of files still needed are kept for the puller
+// to reuse, which verifies their contents when it does, until they are
+// older than KeepTemporariesH. The rest are orphans, removed once older
+// than KeepOrphanTemporariesH. Ignored paths are left alone.
+func (f *folder) cleanTempFiles() (*TempCleanupReport, error) {
+	opts := f.model.cfg.Options()
+	if opts.TempCleanupIntervalS <= 0 {
+		return &TempCleanupReport{Folder: f.ID, Time: time.Now()}, nil
+	}
+	if err := f.getHealthErrorWithoutIgnores(); err != nil {
+		return nil, err
+	}
+
+	needed := make(map[string]string)
+	if f.Type != config.FolderTypeSendOnly {
+		for file, err := range itererr.Zip(f.db.AllNeededGlobalFiles(f.folderID, protocol.LocalDeviceID, config.PullOrderAlphabetic, 0, 0)) {
+			if err != nil {
+				return nil, err
+			}
+			if file.Type == protocol.FileInfoTypeFile && !file.IsDeleted() {
+				needed[fs.TempName(file.Name)] = file.Name
+			}
+		}
+	}
+
+	now := time.Now()
+	report := &TempCleanupReport{Folder: f.ID, Time: now}
+	maxAge := time.Duration(opts.KeepTemporariesH) * time.Hour
+	maxOrphanAge := time.Duration(opts.KeepOrphanTemporariesH) * time.Hour
+
+	remove := func(path string, size int64, counter *int) {
+		if err := f.mtimefs.Remove(path); err != nil && !fs.IsNotExist(err) {
+			l.Debugln(f, "removing temporary", path, err)
+			return
+		}
+		*counter++
+		report.ReclaimedBytes += size
+	}
+
+	// Interrupted pulls stay advertised to other devices as long as their
+	// temporary files are kept.
+	kept := make(map[string]struct{})
+
+	err := f.mtimefs.Walk(".", func(path string, info fs.FileInfo, err error) error {
+		select {
+		case <-f.ctx.Done():
+			return f.ctx.Err()
+		default:
+		}
+		if err != nil {
+			return nil //nolint:nilerr
+		}
+		if info.IsDir() {
+			if fs.IsInternal(path) {
+				return fs.SkipDir
+			}
+			if m := f.ignores.Match(path); m.IsIgnored() && m.CanSkipDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !fs.IsTemporary(path) || !info.IsRegular() || f.ignores.Match(tempOriginalName(path)).IsIgnored() {
+			return nil
+		}
+		report.Found++
+
+		name, ok := needed[path]
+		switch {
+		case !ok:
+			if scanner.IsExpiredTemporary(path, info, maxOrphanAge, now) {
+				remove(path, info.Size(), &report.Orphaned)
+			}
+		case scanner.IsExpiredTemporary(path, info, maxAge, now):
+			remove(path, info.Size(), &report.Expired)
+		default:
+			report.Kept++
+			report.KeptBytes += info.Size()
+			kept[name] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, f.ctx.Err()) {
+		return nil, err
+	}
+	if err == nil {
+		f.model.progressEmitter.RetainInterrupted(f.folderID, kept)
+	}
+
+	if report.removed() > 0 {
+		metricFolderTempReclaimedBytes.WithLabelValues(f.ID).Add(float64(report.ReclaimedBytes))
+		f.sl.Info("Cleaned up temporary files", slog.Int("removed", report.removed()), slog.Int64("bytes", report.ReclaimedBytes), slog.Int("kept", report.Kept))
+		f.evLogger.Log(events.TempFilesCleaned, report)
+	}
+	return report, nil
+}
+
+// tempOriginalName returns the name of the file the temporary file is
+// for. Temporary files of long names carry a hash of the name instead,
+// which is returned as is.
+func tempOriginalName(tempName string) string {
+	dir, base := filepath.Split(tempName)
+	for _, prefix := range []string{fs.UnixTempPrefix, fs.WindowsTempPrefix} {
+		if trimmed, ok := strings.CutPrefix(base, prefix); ok {
+			base = strings.TrimSuffix(trimmed, ".tmp")
+			break
+		}
+	}
+	return filepath.Join(dir, base)
+}
+
+// CleanTempFiles runs the temporary file janitor on the folder now and
+// returns what it did.
+func (m *model) CleanTempFiles(folder string) (*TempCleanupReport, error) {
+	m.mut.RLock()
+	err := m.checkFolderRunningRLocked(folder)
+	runner, _ := m.folderRunners.Get(folder)
+	m.mut.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	return runner.CleanTempFiles()
+}
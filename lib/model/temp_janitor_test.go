@@ -0,0 +1,67 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+func TestCleanTempFilesOrphans(t *testing.T) {
+	m, f, wcfgCancel := setupSendReceiveFolder(t)
+	defer wcfgCancel()
+	ffs := f.Filesystem()
+
+	// Disabled by default.
+	report, err := f.cleanTempFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Found != 0 {
+		t.Errorf("expected nothing to be done when disabled, got %+v", report)
+	}
+
+	waiter, err := m.cfg.Modify(func(cfg *config.Configuration) {
+		cfg.Options.TempCleanupIntervalS = 3600
+	})
+	must(t, err)
+	waiter.Wait()
+	must(t, f.ignores.Parse(strings.NewReader("/ignored\n/skipped\n"), ".stignore"))
+
+	old := time.Now().Add(-2 * time.Hour)
+	must(t, ffs.MkdirAll("ignored", 0o755))
+	for _, name := range []string{"old", "new", "ignored/old", "skipped"} {
+		tempName := fs.TempName(name)
+		writeFile(t, ffs, tempName, []byte("temporary data"))
+		if name != "new" {
+			must(t, ffs.Chtimes(tempName, old, old))
+		}
+	}
+
+	report, err = f.cleanTempFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Found != 2 || report.Orphaned != 1 || report.ReclaimedBytes != int64(len("temporary data")) {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if _, err := ffs.Lstat(fs.TempName("old")); !fs.IsNotExist(err) {
+		t.Error("expected the old orphan to be removed")
+	}
+	if _, err := ffs.Lstat(fs.TempName("new")); err != nil {
+		t.Error("expected the recent orphan to be kept:", err)
+	}
+	for _, name := range []string{"ignored/old", "skipped"} {
+		if _, err := ffs.Lstat(fs.TempName(name)); err != nil {
+			t.Errorf("expected the orphan of ignored %s to be kept: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"log/slog"
+	"sync"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/stats"
+)
+
+// The minimum number of blocks received from a device before its
+// corruption rate is judged.
+const corruptionMinBlocks = 100
+
+// transferIntegrity keeps track of the blocks received from each device
+// that passed or failed hash verification. A device that corrupts data in
+// transit, e.g. due to failing memory, is flagged once its corruption rate
+// exceeds the configured threshold.
+type transferIntegrity struct {
+	mut     sync.Mutex
+	devices map[protocol.DeviceID]*deviceIntegrity
+}
+
+type deviceIntegrity struct {
+	verified, corrupt int64 // since startup
+	flushedVerified   int64 // part of the above already in the statistics
+	flushedCorrupt    int64
+	flagged           bool
+}
+
+func newTransferIntegrity() *transferIntegrity {
+	return &transferIntegrity{
+		devices: make(map[protocol.DeviceID]*deviceIntegrity),
+	}
+}
+
+// record counts a verified or corrupt block and returns the counts since
+// startup, and whether the device became flagged with this block.
+func (t *transferIntegrity) record(deviceID protocol.DeviceID, corrupt bool, thresholdPct int) (verified, corrupted int64, newlyFlagged bool) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	d, ok := t.devices[deviceID]
+	if !ok {
+		d = &deviceIntegrity{}
+		t.devices[deviceID] = d
+	}
+	if !corrupt {
+		d.verified++
+		return d.verified, d.corrupt, false
+	}
+
+	d.corrupt++
+	total := d.verified + d.corrupt
+	if !d.flagged && thresholdPct > 0 && total >= corruptionMinBlocks && d.corrupt*100 > int64(thresholdPct)*total {
+		d.flagged = true
+		newlyFlagged = true
+	}
+	return d.verified, d.corrupt, newlyFlagged
+}
+
+func (t *transferIntegrity) flagged(deviceID protocol.DeviceID) bool {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	d, ok := t.devices[deviceID]
+	return ok && d.flagged
+}
+
+// unflushed returns the counts not yet added to the persisted statistics,
+// and whether the device is flagged.
+func (t *transferIntegrity) unflushed(deviceID protocol.DeviceID) (verified, corrupt int64, flagged bool) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	d, ok := t.devices[deviceID]
+	if !ok {
+		return 0, 0, false
+	}
+	return d.verified - d.flushedVerified, d.corrupt - d.flushedCorrupt, d.flagged
+}
+
+// flush adds the counts to the persisted statistics.
+func (t *transferIntegrity) flush(deviceID protocol.DeviceID, sr *stats.DeviceStatisticsReference) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+	d, ok := t.devices[deviceID]
+	if !ok {
+		return
+	}
+	verified, corrupt := d.verified-d.flushedVerified, d.corrupt-d.flushedCorrupt
+	if verified == 0 && corrupt == 0 {
+		return
+	}
+	if err := sr.AddBlocksReceived(verified, corrupt); err != nil {
+		slog.Warn("Failed to save transfer integrity statistics", deviceID.LogAttr(), slogutil.Error(err))
+		return
+	}
+	d.flushedVerified, d.flushedCorrupt = d.verified, d.corrupt
+}
+
+// recordBlockVerification records the outcome of verifying a block pulled
+// from the device.
+func (m *model) recordBlockVerification(deviceID protocol.DeviceID, folder, name string, offset int64, verifyErr error) {
+	verified, corrupt, newlyFlagged := m.transferIntegrity.record(deviceID, verifyErr != nil, m.cfg.Options().BlockCorruptionThresholdPct)
+	if verifyErr == nil {
+		return
+	}
+
+	m.evLogger.Log(events.DeviceBlockCorruption, map[string]interface{}{
+		"device":         deviceID.String(),
+		"folder":         folder,
+		"item":           name,
+		"offset":         offset,
+		"error":          verifyErr.Error(),
+		"blocksVerified": verified,
+		"blocksCorrupt":  corrupt,
+		"flagged":        newlyFlagged || m.transferIntegrity.flagged(deviceID),
+	})
+	if newlyFlagged {
+		slog.Warn("Device sends corrupted data; using it only as a last resort for pulling", deviceID.LogAttr(), slog.Int64("corrupt", corrupt), slog.Int64("verified", verified))
+	}
+}
+
+// preferIntactDevices splits the candidates into those that aren't
+// flagged for corrupting data, and those that are.
+func (m *model) preferIntactDevices(candidates []Availability) (preferred, fallback []Availability) {
+	for _, c := range candidates {
+		if m.transferIntegrity.flagged(c.ID) {
+			fallback = append(fallback, c)
+		} else {
+			preferred = append(preferred, c)
+		}
+	}
+	return preferred, fallback
+}
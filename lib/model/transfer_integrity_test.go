@@ -0,0 +1,58 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package model
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+func TestTransferIntegrityFlagging(t *testing.T) {
+	ti := newTransferIntegrity()
+	dev := protocol.DeviceID{1}
+
+	// A corrupt block among too few blocks doesn't flag the device.
+	for range corruptionMinBlocks - 2 {
+		ti.record(dev, false, 1)
+	}
+	if _, _, flagged := ti.record(dev, true, 1); flagged {
+		t.Fatal("flagged before the minimum number of blocks")
+	}
+
+	// Two corrupt blocks out of a hundred is above one percent.
+	verified, corrupt, flagged := ti.record(dev, true, 1)
+	if !flagged || verified != corruptionMinBlocks-2 || corrupt != 2 {
+		t.Fatalf("unexpected result %d verified, %d corrupt, flagged %v", verified, corrupt, flagged)
+	}
+	if _, _, flagged := ti.record(dev, true, 1); flagged {
+		t.Error("flagged again")
+	}
+	if !ti.flagged(dev) || ti.flagged(protocol.DeviceID{2}) {
+		t.Error("wrong devices flagged")
+	}
+}
+
+func TestPullPrefersIntactDevices(t *testing.T) {
+	m, _, fcfg, wcfgCancel := setupModelWithConnection(t)
+	defer wcfgCancel()
+	defer cleanupModelAndRemoveDir(m, fcfg.Filesystem().URI())
+
+	for range corruptionMinBlocks {
+		m.recordBlockVerification(device1, fcfg.ID, "file", 0, errors.New("hash mismatch"))
+	}
+	stats := mustV(m.DeviceStatistics())[device1]
+	if !stats.CorruptionFlagged || stats.BlocksCorrupt != corruptionMinBlocks {
+		t.Errorf("unexpected device statistics %+v", stats)
+	}
+
+	preferred, fallback := m.preferIntactDevices([]Availability{{ID: device1}, {ID: device2}})
+	if len(preferred) != 1 || preferred[0].ID != device2 || len(fallback) != 1 || fallback[0].ID != device1 {
+		t.Errorf("unexpected split %v, %v", preferred, fallback)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package osutil
+
+import (
+	"errors"
+	"runtime"
+	"time"
+)
+
+// IOPriority is a hint of how urgent the disk I/O of an operation is,
+// relative to other processes on the same system.
+type IOPriority int
+
+const (
+	IOPriorityNormal IOPriority = iota
+	// Lowest priority still competing for the disk, at the best-effort
+	// level.
+	IOPriorityLow
+	// Only use the disk when nobody else does.
+	IOPriorityIdle
+)
+
+var errIOPriorityUnsupported = errors.New("per-thread I/O priority not supported")
+
+// IOThrottle is returned by ApplyIOPriority and must be called after each
+// I/O operation with the time it took.
+type IOThrottle func(time.Duration)
+
+// ApplyIOPriority applies the I/O priority to the calling goroutine. As
+// I/O priorities are per thread, the goroutine stays locked to its thread,
+// which is discarded when the goroutine exits; this must only be called on
+// goroutines that are about to do a stretch of I/O and then exit. Where
+// the platform can't prioritize I/O, the returned throttle approximates
+// the priority by sleeping in proportion to the time spent on I/O.
+func ApplyIOPriority(prio IOPriority) IOThrottle {
+	if prio == IOPriorityNormal {
+		return func(time.Duration) {}
+	}
+
+	runtime.LockOSThread()
+	if err := setThreadIOPriority(prio); err == nil {
+		// Deliberately not unlocking the thread, as it now carries the
+		// priority.
+		return func(time.Duration) {}
+	}
+	runtime.UnlockOSThread()
+
+	// Low priority gets two thirds of the disk time it would otherwise
+	// use, idle one third.
+	factor := 0.5
+	if prio == IOPriorityIdle {
+		factor = 2
+	}
+	return func(d time.Duration) {
+		time.Sleep(time.Duration(float64(d) * factor))
+	}
+}
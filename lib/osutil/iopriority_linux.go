@@ -0,0 +1,42 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build !android
+// +build !android
+
+package osutil
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/unix"
+)
+
+// See https://www.kernel.org/doc/Documentation/block/ioprio.txt
+const (
+	ioprioWhoProcess = 1
+	ioprioClassShift = 13
+	ioprioClassBE    = 2
+	ioprioClassIdle  = 3
+)
+
+func setThreadIOPriority(prio IOPriority) error {
+	var ioprio uintptr
+	switch prio {
+	case IOPriorityLow:
+		ioprio = ioprioClassBE<<ioprioClassShift | 7
+	case IOPriorityIdle:
+		ioprio = ioprioClassIdle << ioprioClassShift
+	default:
+		return nil
+	}
+
+	// With IOPRIO_WHO_PROCESS, process zero is the calling thread.
+	if _, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, 0, ioprio); errno != 0 {
+		return fmt.Errorf("set I/O priority: %w", errno)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build !android
+// +build !android
+
+package osutil
+
+import (
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestApplyIOPriority(t *testing.T) {
+	cases := []struct {
+		prio IOPriority
+		want uintptr
+	}{
+		{IOPriorityLow, ioprioClassBE<<ioprioClassShift | 7},
+		{IOPriorityIdle, ioprioClassIdle << ioprioClassShift},
+	}
+	for _, tc := range cases {
+		res := make(chan uintptr)
+		go func() {
+			ApplyIOPriority(tc.prio)
+			got, _, errno := unix.Syscall(unix.SYS_IOPRIO_GET, ioprioWhoProcess, 0, 0)
+			if errno != 0 {
+				t.Error(errno)
+			}
+			res <- got
+		}()
+		if got := <-res; got != tc.want {
+			t.Errorf("priority %d: got I/O priority %#x, expected %#x", tc.prio, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build (!windows && !linux) || android
+// +build !windows,!linux android
+
+package osutil
+
+func setThreadIOPriority(prio IOPriority) error {
+	if prio == IOPriorityNormal {
+		return nil
+	}
+	return errIOPriorityUnsupported
+}
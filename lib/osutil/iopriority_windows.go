@@ -0,0 +1,30 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package osutil
+
+import (
+	"fmt"
+
+	"golang.org/x/sys/windows"
+)
+
+var procSetThreadPriority = windows.NewLazySystemDLL("kernel32.dll").NewProc("SetThreadPriority")
+
+// Background mode lowers both the CPU and the I/O priority of the thread.
+// Windows has no distinct idle I/O priority for threads, so both low and
+// idle map to it.
+const threadModeBackgroundBegin = 0x00010000
+
+func setThreadIOPriority(prio IOPriority) error {
+	if prio == IOPriorityNormal {
+		return nil
+	}
+	if r, _, err := procSetThreadPriority.Call(uintptr(windows.CurrentThread()), threadModeBackgroundBegin); r == 0 {
+		return fmt.Errorf("set thread priority: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package profile compares the configuration to a signed reference
+// profile, for deployments managed centrally, reporting where the local
+// configuration drifted from it and optionally undoing that.
+package profile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/signature"
+)
+
+// The kinds of drift
+const (
+	DriftOption = "option"
+	DriftFolder = "folder"
+	DriftDevice = "device"
+)
+
+// The changes drifted into
+const (
+	DriftChanged = "changed"
+	DriftExtra   = "extra"
+	DriftMissing = "missing"
+)
+
+// A Profile is what the configuration should look like. Only what it says
+// is compared: the options it has values for, and the folders and devices
+// it lists, whose fields given must match. Any folder not in the list is
+// extra, unless the list is left out altogether, and the same for devices;
+// our own device is never extra.
+type Profile struct {
+	Options map[string]json.RawMessage   `json:"options,omitempty"`
+	Folders []map[string]json.RawMessage `json:"folders,omitempty"`
+	Devices []map[string]json.RawMessage `json:"devices,omitempty"`
+}
+
+// Drift is one way the configuration differs from the profile. ID is that
+// of the folder or device, Field the option or field that changed.
+type Drift struct {
+	Kind     string          `json:"kind"`
+	Change   string          `json:"change"`
+	ID       string          `json:"id,omitempty"`
+	Field    string          `json:"field,omitempty"`
+	Expected json.RawMessage `json:"expected,omitempty"`
+	Actual   json.RawMessage `json:"actual,omitempty"`
+}
+
+// Load reads the profile at path and verifies its signature, in the file
+// next to it with ".sig" appended, with the public key in PEM format at
+// keyPath.
+func Load(path, keyPath string) (*Profile, error) {
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("reading profile key: %w", err)
+	}
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading profile: %w", err)
+	}
+	sig, err := os.ReadFile(path + ".sig")
+	if err != nil {
+		return nil, fmt.Errorf("reading profile signature: %w", err)
+	}
+	if err := signature.Verify(key, sig, bytes.NewReader(bs)); err != nil {
+		return nil, fmt.Errorf("verifying profile signature: %w", err)
+	}
+	return Parse(bs)
+}
+
+// Parse returns the profile in bs, checking that it names only options and
+// fields that exist and gives the ID of every folder and device.
+func Parse(bs []byte) (*Profile, error) {
+	dec := json.NewDecoder(bytes.NewReader(bs))
+	dec.DisallowUnknownFields()
+	var p Profile
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("parsing profile: %w", err)
+	}
+	if err := checkFields("option", p.Options, reflect.TypeFor[config.OptionsConfiguration]()); err != nil {
+		return nil, err
+	}
+	for _, f := range p.Folders {
+		if _, err := folderID(f); err != nil {
+			return nil, err
+		}
+		if err := checkFields("folder field", f, reflect.TypeFor[config.FolderConfiguration]()); err != nil {
+			return nil, err
+		}
+	}
+	for _, d := range p.Devices {
+		if _, err := deviceID(d); err != nil {
+			return nil, err
+		}
+		if err := checkFields("device field", d, reflect.TypeFor[config.DeviceConfiguration]()); err != nil {
+			return nil, err
+		}
+	}
+	return &p, nil
+}
+
+func checkFields(what string, fields map[string]json.RawMessage, t reflect.Type) error {
+	names := jsonNames(t)
+	for name := range fields {
+		if !names[name] {
+			return fmt.Errorf("profile: unknown %s %q", what, name)
+		}
+	}
+	return nil
+}
+
+// jsonNames returns the names of the fields of the struct type in JSON.
+func jsonNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool)
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		switch name {
+		case "-":
+		case "":
+			names[f.Name] = true
+		default:
+			names[name] = true
+		}
+	}
+	return names
+}
+
+func folderID(f map[string]json.RawMessage) (string, error) {
+	var id string
+	if err := json.Unmarshal(f["id"], &id); err != nil || id == "" {
+		return "", errors.New("profile: folder without an ID")
+	}
+	return id, nil
+}
+
+func deviceID(d map[string]json.RawMessage) (protocol.DeviceID, error) {
+	var id protocol.DeviceID
+	if err := json.Unmarshal(d["deviceID"], &id); err != nil || id == protocol.EmptyDeviceID {
+		return protocol.EmptyDeviceID, errors.New("profile: device without a valid ID")
+	}
+	return id, nil
+}
+
+// Compare returns how the configuration drifted from the profile, in a
+// stable order.
+func Compare(cfg config.Configuration, p *Profile, myID protocol.DeviceID) []Drift {
+	var drift []Drift
+
+	drift = append(drift, compareFields(DriftOption, "", p.Options, cfg.Options)...)
+
+	folders := cfg.FolderMap()
+	listed := make(map[string]bool)
+	for _, f := range p.Folders {
+		id, _ := folderID(f)
+		listed[id] = true
+		live, ok := folders[id]
+		if !ok {
+			drift = append(drift, Drift{Kind: DriftFolder, Change: DriftMissing, ID: id})
+			continue
+		}
+		drift = append(drift, compareFields(DriftFolder, id, f, live)...)
+	}
+	if p.Folders != nil {
+		for _, f := range cfg.Folders {
+			if !listed[f.ID] {
+				drift = append(drift, Drift{Kind: DriftFolder, Change: DriftExtra, ID: f.ID})
+			}
+		}
+	}
+
+	devices := cfg.DeviceMap()
+	listedDevices := make(map[protocol.DeviceID]bool)
+	for _, d := range p.Devices {
+		id, _ := deviceID(d)
+		listedDevices[id] = true
+		live, ok := devices[id]
+		if !ok {
+			drift = append(drift, Drift{Kind: DriftDevice, Change: DriftMissing, ID: id.String()})
+			continue
+		}
+		drift = append(drift, compareFields(DriftDevice, id.String(), d, live)...)
+	}
+	if p.Devices != nil {
+		for _, d := range cfg.Devices {
+			if d.DeviceID != myID && !listedDevices[d.DeviceID] {
+				drift = append(drift, Drift{Kind: DriftDevice, Change: DriftExtra, ID: d.DeviceID.String()})
+			}
+		}
+	}
+
+	return drift
+}
+
+// compareFields compares the fields given in the profile to those of the
+// live value, by their JSON.
+func compareFields(kind, id string, expected map[string]json.RawMessage, live any) []Drift {
+	if len(expected) == 0 {
+		return nil
+	}
+	var actual map[string]json.RawMessage
+	if bs, err := json.Marshal(live); err == nil {
+		_ = json.Unmarshal(bs, &actual)
+	}
+
+	var drift []Drift
+	for _, name := range slices.Sorted(maps.Keys(expected)) {
+		if kind != DriftOption && (name == "id" || name == "deviceID") {
+			continue
+		}
+		if jsonEqual(expected[name], actual[name]) {
+			continue
+		}
+		drift = append(drift, Drift{
+			Kind:     kind,
+			Change:   DriftChanged,
+			ID:       id,
+			Field:    name,
+			Expected: expected[name],
+			Actual:   actual[name],
+		})
+	}
+	return drift
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var va, vb any
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// Correct changes the configuration to match the profile: options and
+// fields are set as given, missing folders and devices are added from the
+// defaults and the fields given, and extra ones are removed.
+func Correct(cfg *config.Configuration, p *Profile, myID protocol.DeviceID) error {
+	if len(p.Options) > 0 {
+		if err := patch(&cfg.Options, p.Options); err != nil {
+			return fmt.Errorf("options: %w", err)
+		}
+	}
+
+	listed := make(map[string]bool)
+	for _, f := range p.Folders {
+		id, _ := folderID(f)
+		listed[id] = true
+		folder, _, ok := cfg.Folder(id)
+		if !ok {
+			folder = cfg.Defaults.Folder.Copy()
+		}
+		if err := patch(&folder, f); err != nil {
+			return fmt.Errorf("folder %s: %w", id, err)
+		}
+		cfg.SetFolder(folder)
+	}
+	if p.Folders != nil {
+		cfg.Folders = slices.DeleteFunc(cfg.Folders, func(f config.FolderConfiguration) bool {
+			return !listed[f.ID]
+		})
+	}
+
+	listedDevices := make(map[protocol.DeviceID]bool)
+	for _, d := range p.Devices {
+		id, _ := deviceID(d)
+		listedDevices[id] = true
+		device, _, ok := cfg.Device(id)
+		if !ok {
+			device = cfg.Defaults.Device.Copy()
+		}
+		if err := patch(&device, d); err != nil {
+			return fmt.Errorf("device %s: %w", id.Short(), err)
+		}
+		cfg.SetDevice(device)
+	}
+	if p.Devices != nil {
+		cfg.Devices = slices.DeleteFunc(cfg.Devices, func(d config.DeviceConfiguration) bool {
+			return d.DeviceID != myID && !listedDevices[d.DeviceID]
+		})
+	}
+
+	return nil
+}
+
+// patch sets the fields given in JSON on the value, leaving the others.
+func patch(v any, fields map[string]json.RawMessage) error {
+	bs, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bs, v)
+}
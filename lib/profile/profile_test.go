@@ -0,0 +1,148 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package profile
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/signature"
+)
+
+var (
+	device1, _ = protocol.DeviceIDFromString("AIR6LPZ-7K4PTTV-UXQSMUU-CPQ5YWH-OEDFIIQ-JUG777G-2YQXXR5-YD6AWQR")
+	device2, _ = protocol.DeviceIDFromString("GYRZZQB-IRNPV4Z-T7TC52W-EQYJ3TT-FDQW6MW-DFLMU42-SSSU6EM-FBK2VAY")
+)
+
+const testProfile = `{
+	"options": {"relaysEnabled": false, "maxSendKbps": 100},
+	"folders": [{"id": "default", "path": "/data/default", "type": "sendreceive"}]
+}`
+
+func testConfig() config.Configuration {
+	cfg := config.New(device1)
+	cfg.Options.RelaysEnabled = false
+	cfg.Options.MaxSendKbps = 100
+	cfg.SetFolder(config.FolderConfiguration{ID: "default", Path: "/data/default", Type: config.FolderTypeSendReceive})
+	cfg.SetDevice(config.DeviceConfiguration{DeviceID: device2})
+	return cfg
+}
+
+func TestCompareAndCorrect(t *testing.T) {
+	p, err := Parse([]byte(testProfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := testConfig()
+	if drift := Compare(cfg, p, device1); len(drift) != 0 {
+		t.Fatal("unexpected drift", drift)
+	}
+
+	cfg.Options.RelaysEnabled = true
+	folder, _, _ := cfg.Folder("default")
+	folder.Type = config.FolderTypeSendOnly
+	cfg.SetFolder(folder)
+	cfg.SetFolder(config.FolderConfiguration{ID: "extra", Path: "/data/extra"})
+
+	drift := Compare(cfg, p, device1)
+	expected := []Drift{
+		{Kind: DriftOption, Change: DriftChanged, Field: "relaysEnabled"},
+		{Kind: DriftFolder, Change: DriftChanged, ID: "default", Field: "type"},
+		{Kind: DriftFolder, Change: DriftExtra, ID: "extra"},
+	}
+	if len(drift) != len(expected) {
+		t.Fatalf("expected %d changes, got %v", len(expected), drift)
+	}
+	for i, d := range drift {
+		e := expected[i]
+		if d.Kind != e.Kind || d.Change != e.Change || d.ID != e.ID || d.Field != e.Field {
+			t.Errorf("change %d: expected %+v, got %+v", i, e, d)
+		}
+	}
+
+	if err := Correct(&cfg, p, device1); err != nil {
+		t.Fatal(err)
+	}
+	if drift := Compare(cfg, p, device1); len(drift) != 0 {
+		t.Error("drift after correcting", drift)
+	}
+	if _, ok := cfg.DeviceMap()[device2]; !ok {
+		t.Error("device removed, though the profile doesn't list devices")
+	}
+}
+
+func TestCorrectMissing(t *testing.T) {
+	p, err := Parse([]byte(`{"devices": [{"deviceID": "` + device2.String() + `", "name": "server"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.New(device1)
+	drift := Compare(cfg, p, device1)
+	if len(drift) != 1 || drift[0].Change != DriftMissing || drift[0].ID != device2.String() {
+		t.Fatal("unexpected drift", drift)
+	}
+	if err := Correct(&cfg, p, device1); err != nil {
+		t.Fatal(err)
+	}
+	dev, _, ok := cfg.Device(device2)
+	if !ok || dev.Name != "server" {
+		t.Error("missing device not added", dev)
+	}
+	if _, _, ok := cfg.Device(device1); !ok {
+		t.Error("own device removed")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, bs := range []string{
+		`{"options": {"noSuchOption": 1}}`,
+		`{"folders": [{"path": "/data"}]}`,
+		`{"devices": [{"deviceID": "nope"}]}`,
+		`{"settings": {}}`,
+	} {
+		if _, err := Parse([]byte(bs)); err == nil {
+			t.Errorf("expected %s to fail", bs)
+		}
+	}
+}
+
+func TestLoadSigned(t *testing.T) {
+	priv, pub, err := signature.GenerateKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "profile.json")
+	keyPath := filepath.Join(dir, "key.pem")
+	sig, err := signature.Sign(priv, bytes.NewReader([]byte(testProfile)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, bs := range map[string][]byte{path: []byte(testProfile), path + ".sig": sig, keyPath: pub} {
+		if err := os.WriteFile(name, bs, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := Load(path, keyPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// A changed profile doesn't pass
+	if err := os.WriteFile(path, []byte(`{"options": {"relaysEnabled": true}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path, keyPath); err == nil {
+		t.Error("expected a changed profile to fail verification")
+	}
+}
@@ -0,0 +1,204 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package profile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+)
+
+const minCheckInterval = 10 * time.Second
+
+var ErrNoProfile = errors.New("no reference profile is configured")
+
+// Status is the outcome of the latest comparison to the profile.
+type Status struct {
+	Profile   string    `json:"profile"`
+	Checked   time.Time `json:"checked"`
+	Error     string    `json:"error,omitempty"`
+	Drift     []Drift   `json:"drift"`
+	Corrected time.Time `json:"corrected"`
+}
+
+// DriftEventData is the data of the ConfigDriftDetected event, sent when
+// the drift from the profile changes.
+type DriftEventData struct {
+	Profile   string  `json:"profile"`
+	Drift     []Drift `json:"drift"`
+	Corrected bool    `json:"corrected"`
+}
+
+// The Service compares the configuration to the reference profile in the
+// options on every change and at the check interval, reloading the
+// profile each time so that updates to it are picked up.
+type Service struct {
+	cfg      config.Wrapper
+	evLogger events.Logger
+	changed  chan struct{}
+
+	mut    sync.Mutex
+	status Status
+	// The drift that remained after correcting it, not to try again
+	// until something changes.
+	uncorrectable string
+}
+
+func NewService(cfg config.Wrapper, evLogger events.Logger) *Service {
+	return &Service{
+		cfg:      cfg,
+		evLogger: evLogger,
+		changed:  make(chan struct{}, 1),
+		status:   Status{Drift: []Drift{}},
+	}
+}
+
+func (s *Service) Serve(ctx context.Context) error {
+	s.cfg.Subscribe(s)
+	defer s.cfg.Unsubscribe(s)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+		case <-s.changed:
+			timer.Stop()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		s.check(false)
+
+		interval := time.Duration(s.cfg.Options().ReferenceProfileCheckIntervalS) * time.Second
+		timer.Reset(max(interval, minCheckInterval))
+	}
+}
+
+// Status returns the outcome of the latest comparison to the profile.
+func (s *Service) Status() Status {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	return s.status
+}
+
+// Correct compares the configuration to the profile and undoes any drift,
+// whether or not that's done automatically.
+func (s *Service) Correct() (Status, error) {
+	if s.cfg.Options().ReferenceProfile == "" {
+		return Status{}, ErrNoProfile
+	}
+	status := s.check(true)
+	if status.Error != "" {
+		return status, errors.New(status.Error)
+	}
+	return status, nil
+}
+
+func (s *Service) check(correct bool) Status {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	opts := s.cfg.Options()
+	if opts.ReferenceProfile == "" {
+		s.status = Status{Drift: []Drift{}}
+		s.uncorrectable = ""
+		return s.status
+	}
+
+	prev := s.status
+	status := Status{Profile: opts.ReferenceProfile, Checked: time.Now(), Drift: []Drift{}, Corrected: prev.Corrected}
+	p, err := Load(opts.ReferenceProfile, opts.ReferenceProfileKey)
+	if err != nil {
+		if prev.Error != err.Error() {
+			slog.Warn("Failed to load reference configuration profile", slogutil.FilePath(opts.ReferenceProfile), slogutil.Error(err))
+		}
+		status.Error = err.Error()
+		s.status = status
+		return status
+	}
+
+	myID := s.cfg.MyID()
+	drift := Compare(s.cfg.RawCopy(), p, myID)
+	if len(drift) == 0 {
+		s.uncorrectable = ""
+	}
+	corrected := false
+	if len(drift) > 0 && (correct || opts.ReferenceProfileAutoCorrect && driftKey(drift) != s.uncorrectable) {
+		remaining, err := s.correct(p)
+		if err != nil {
+			slog.Warn("Failed to correct configuration drift", slogutil.FilePath(opts.ReferenceProfile), slogutil.Error(err))
+			status.Error = err.Error()
+		} else {
+			drift = remaining
+			corrected = true
+			status.Corrected = time.Now()
+		}
+		s.uncorrectable = driftKey(drift)
+	}
+	status.Drift = append(status.Drift, drift...)
+	s.status = status
+
+	if driftKey(status.Drift) != driftKey(prev.Drift) || prev.Profile != status.Profile || corrected {
+		if len(status.Drift) > 0 {
+			slog.Warn("Configuration drifted from the reference profile", slogutil.FilePath(status.Profile), slog.Int("changes", len(status.Drift)))
+		}
+		s.evLogger.Log(events.ConfigDriftDetected, DriftEventData{
+			Profile:   status.Profile,
+			Drift:     status.Drift,
+			Corrected: corrected,
+		})
+	}
+	return status
+}
+
+// correct undoes the drift from the profile, returning what remains.
+func (s *Service) correct(p *Profile) ([]Drift, error) {
+	var cerr error
+	waiter, err := s.cfg.Modify(func(cfg *config.Configuration) {
+		// Either all of it or nothing
+		corrected := cfg.Copy()
+		if cerr = Correct(&corrected, p, s.cfg.MyID()); cerr == nil {
+			*cfg = corrected
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	waiter.Wait()
+	if cerr != nil {
+		return nil, cerr
+	}
+	return Compare(s.cfg.RawCopy(), p, s.cfg.MyID()), nil
+}
+
+func driftKey(drift []Drift) string {
+	if len(drift) == 0 {
+		return ""
+	}
+	bs, _ := json.Marshal(drift)
+	return string(bs)
+}
+
+func (s *Service) CommitConfiguration(_, _ config.Configuration) bool {
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+func (*Service) String() string {
+	return "profile.Service"
+}
@@ -9,6 +9,7 @@ package protocol
 import (
 	"fmt"
 	"log/slog"
+	"slices"
 
 	"github.com/syncthing/syncthing/internal/gen/bep"
 )
@@ -37,9 +38,23 @@ const (
 	FolderStopReasonPaused  = FolderStopReason(bep.FolderStopReason_FOLDER_STOP_REASON_PAUSED)
 )
 
+// Optional protocol features, announced in the cluster config.
+const (
+	// Deleted directories are announced in a tombstones index update
+	// before the deletions of their contents, which follow in batches.
+	FeatureTwoPhaseDeletes = "two-phase-deletes"
+)
+
 type ClusterConfig struct {
 	Folders   []Folder
 	Secondary bool
+	Features  []string
+}
+
+// HasFeature returns true if the sender of the cluster config supports the
+// given feature.
+func (c *ClusterConfig) HasFeature(feature string) bool {
+	return slices.Contains(c.Features, feature)
 }
 
 func (c *ClusterConfig) toWire() *bep.ClusterConfig {
@@ -50,6 +65,7 @@ func (c *ClusterConfig) toWire() *bep.ClusterConfig {
 	return &bep.ClusterConfig{
 		Folders:   folders,
 		Secondary: c.Secondary,
+		Features:  c.Features,
 	}
 }
 
@@ -59,6 +75,7 @@ func clusterConfigFromWire(w *bep.ClusterConfig) *ClusterConfig {
 	}
 	c := &ClusterConfig{
 		Secondary: w.Secondary,
+		Features:  w.Features,
 	}
 	c.Folders = make([]Folder, len(w.Folders))
 	for i, f := range w.Folders {
@@ -138,6 +155,8 @@ type Device struct {
 	IndexID                  IndexID
 	SkipIntroductionRemovals bool
 	EncryptionPasswordToken  []byte
+	OwnedPrefixes            []string // path prefixes of the folder the device owns
+	IgnoredPrefixes          [][]byte // hashed directories of the folder the device ignores the contents of
 }
 
 func (d *Device) toWire() *bep.Device {
@@ -152,6 +171,8 @@ func (d *Device) toWire() *bep.Device {
 		IndexId:                  uint64(d.IndexID),
 		SkipIntroductionRemovals: d.SkipIntroductionRemovals,
 		EncryptionPasswordToken:  d.EncryptionPasswordToken,
+		OwnedPrefixes:            d.OwnedPrefixes,
+		IgnoredPrefixes:          d.IgnoredPrefixes,
 	}
 }
 
@@ -167,5 +188,7 @@ func deviceFromWire(w *bep.Device) Device {
 		IndexID:                  IndexID(w.IndexId),
 		SkipIntroductionRemovals: w.SkipIntroductionRemovals,
 		EncryptionPasswordToken:  w.EncryptionPasswordToken,
+		OwnedPrefixes:            w.OwnedPrefixes,
+		IgnoredPrefixes:          w.IgnoredPrefixes,
 	}
 }
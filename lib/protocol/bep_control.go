@@ -0,0 +1,42 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	"time"
+
+	"github.com/syncthing/syncthing/internal/gen/bep"
+)
+
+// ControlMessage carries a piece of cluster configuration, such as a folder
+// offer or a set of ignore patterns, that may have been queued while the
+// peer was offline. Messages with the same kind and key supersede each
+// other; only the latest one is of interest.
+type ControlMessage struct {
+	Kind    string
+	Key     string
+	Payload []byte
+	Queued  time.Time
+}
+
+func (m *ControlMessage) toWire() *bep.ControlMessage {
+	return &bep.ControlMessage{
+		Kind:    m.Kind,
+		Key:     m.Key,
+		Payload: m.Payload,
+		Queued:  m.Queued.UnixNano(),
+	}
+}
+
+func controlMessageFromWire(w *bep.ControlMessage) *ControlMessage {
+	return &ControlMessage{
+		Kind:    w.Kind,
+		Key:     w.Key,
+		Payload: w.Payload,
+		Queued:  time.Unix(0, w.Queued),
+	}
+}
@@ -121,6 +121,7 @@ type FileInfo struct {
 	Sequence           int64
 	Blocks             []BlockInfo
 	SymlinkTarget      []byte
+	HardLinkTarget     string // another file linked to the same data, if any
 	BlocksHash         []byte
 	PreviousBlocksHash []byte
 	Encrypted          []byte
@@ -170,6 +171,7 @@ func (f *FileInfo) ToWire(withInternalFields bool) *bep.FileInfo {
 		Sequence:           f.Sequence,
 		Blocks:             blocks,
 		SymlinkTarget:      f.SymlinkTarget,
+		HardLinkTarget:     f.HardLinkTarget,
 		BlocksHash:         f.BlocksHash,
 		PreviousBlocksHash: f.PreviousBlocksHash,
 		Encrypted:          f.Encrypted,
@@ -263,7 +265,9 @@ func FileInfoFromWire(w *bep.FileInfo) FileInfo {
 			blocks[j] = BlockInfoFromWire(b)
 		}
 	}
-	return fileInfoFromWireWithBlocks(w, blocks)
+	f := fileInfoFromWireWithBlocks(w, blocks)
+	f.HardLinkTarget = w.HardLinkTarget
+	return f
 }
 
 type FileInfoWithoutBlocks interface {
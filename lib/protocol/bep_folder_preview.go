@@ -0,0 +1,92 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import "github.com/syncthing/syncthing/internal/gen/bep"
+
+// FolderPreviewRequest asks the peer for a summary of the contents of a
+// folder it offers to us, before we decide whether to accept it.
+type FolderPreviewRequest struct {
+	ID         int
+	Folder     string
+	MaxEntries int
+}
+
+func (r *FolderPreviewRequest) toWire() *bep.FolderPreviewRequest {
+	return &bep.FolderPreviewRequest{
+		Id:         int32(r.ID),
+		Folder:     r.Folder,
+		MaxEntries: int32(r.MaxEntries),
+	}
+}
+
+func folderPreviewRequestFromWire(w *bep.FolderPreviewRequest) *FolderPreviewRequest {
+	return &FolderPreviewRequest{
+		ID:         int(w.Id),
+		Folder:     w.Folder,
+		MaxEntries: int(w.MaxEntries),
+	}
+}
+
+// FolderPreview is the answer to a FolderPreviewRequest: the largest top
+// level directories of the folder, and totals over the whole folder.
+type FolderPreview struct {
+	ID          int                  `json:"-"`
+	Entries     []FolderPreviewEntry `json:"entries"`
+	Files       int                  `json:"files"`
+	Directories int                  `json:"directories"`
+	Bytes       int64                `json:"bytes"`
+	Truncated   bool                 `json:"truncated"` // more top level directories exist than listed
+}
+
+type FolderPreviewEntry struct {
+	Name        string `json:"name"`
+	Files       int    `json:"files"`
+	Directories int    `json:"directories"`
+	Bytes       int64  `json:"bytes"`
+}
+
+func (p *FolderPreview) toWire(code ErrorCode) *bep.FolderPreviewResponse {
+	entries := make([]*bep.FolderPreviewEntry, len(p.Entries))
+	for i, e := range p.Entries {
+		entries[i] = &bep.FolderPreviewEntry{
+			Name:        e.Name,
+			Files:       int64(e.Files),
+			Directories: int64(e.Directories),
+			Bytes:       e.Bytes,
+		}
+	}
+	return &bep.FolderPreviewResponse{
+		Id:          int32(p.ID),
+		Entries:     entries,
+		Files:       int64(p.Files),
+		Directories: int64(p.Directories),
+		Bytes:       p.Bytes,
+		Truncated:   p.Truncated,
+		Code:        code,
+	}
+}
+
+func folderPreviewFromWire(w *bep.FolderPreviewResponse) *FolderPreview {
+	entries := make([]FolderPreviewEntry, len(w.Entries))
+	for i, e := range w.Entries {
+		entries[i] = FolderPreviewEntry{
+			Name:        e.Name,
+			Files:       int(e.Files),
+			Directories: int(e.Directories),
+			Bytes:       e.Bytes,
+		}
+	}
+	return &FolderPreview{
+		ID:          int(w.Id),
+		Entries:     entries,
+		Files:       int(w.Files),
+		Directories: int(w.Directories),
+		Bytes:       w.Bytes,
+		Truncated:   w.Truncated,
+	}
+}
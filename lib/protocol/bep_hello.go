@@ -42,6 +42,11 @@ type Hello struct {
 	ClientVersion  string
 	NumConnections int
 	Timestamp      int64
+	// Compression algorithms supported in addition to LZ4
+	Compressions []CompressionAlgorithm
+	// Whether block data on relayed connections should be sealed, see
+	// NegotiateBlockSealing
+	BlockSealing bool
 }
 
 func (h *Hello) toWire() *bep.Hello {
@@ -51,6 +56,8 @@ func (h *Hello) toWire() *bep.Hello {
 		ClientVersion:  h.ClientVersion,
 		NumConnections: int32(h.NumConnections),
 		Timestamp:      h.Timestamp,
+		Compressions:   compressionsToWire(h.Compressions),
+		BlockSealing:   h.BlockSealing,
 	}
 }
 
@@ -61,9 +68,33 @@ func helloFromWire(w *bep.Hello) Hello {
 		ClientVersion:  w.ClientVersion,
 		NumConnections: int(w.NumConnections),
 		Timestamp:      w.Timestamp,
+		Compressions:   compressionsFromWire(w.Compressions),
+		BlockSealing:   w.BlockSealing,
 	}
 }
 
+func compressionsToWire(cs []CompressionAlgorithm) []bep.MessageCompression {
+	if len(cs) == 0 {
+		return nil
+	}
+	w := make([]bep.MessageCompression, len(cs))
+	for i, c := range cs {
+		w[i] = bep.MessageCompression(c)
+	}
+	return w
+}
+
+func compressionsFromWire(w []bep.MessageCompression) []CompressionAlgorithm {
+	if len(w) == 0 {
+		return nil
+	}
+	cs := make([]CompressionAlgorithm, len(w))
+	for i, c := range w {
+		cs[i] = CompressionAlgorithm(c)
+	}
+	return cs
+}
+
 func (h Hello) Magic() uint32 {
 	// Check if this is a v2 client based on version string
 	// v2 clients will have version strings that indicate v2 compatibility
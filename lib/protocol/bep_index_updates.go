@@ -46,6 +46,9 @@ type IndexUpdate struct {
 	Files        []FileInfo
 	LastSequence int64
 	PrevSequence int64
+	// Tombstones marks an update announcing deleted directories ahead of
+	// the deletions of their contents. It's not part of the sequence.
+	Tombstones bool
 }
 
 func (i *IndexUpdate) toWire() *bep.IndexUpdate {
@@ -58,6 +61,7 @@ func (i *IndexUpdate) toWire() *bep.IndexUpdate {
 		Files:        files,
 		LastSequence: i.LastSequence,
 		PrevSequence: i.PrevSequence,
+		Tombstones:   i.Tombstones,
 	}
 }
 
@@ -69,6 +73,7 @@ func indexUpdateFromWire(w *bep.IndexUpdate) *IndexUpdate {
 		Folder:       w.Folder,
 		LastSequence: w.LastSequence,
 		PrevSequence: w.PrevSequence,
+		Tombstones:   w.Tombstones,
 	}
 	i.Files = make([]FileInfo, len(w.Files))
 	for j, f := range w.Files {
@@ -0,0 +1,65 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// One in this many compressions and decompressions is timed to account the
+// CPU time spent on them.
+const cpuSampleInterval = 16
+
+// CPUStatistics is the estimated CPU time spent compressing and encrypting
+// a connection, in seconds. Encryption is only known for connections that
+// account for it.
+type CPUStatistics struct {
+	CompressS   float64 `json:"compressS"`
+	DecompressS float64 `json:"decompressS"`
+	EncryptS    float64 `json:"encryptS"`
+	DecryptS    float64 `json:"decryptS"`
+}
+
+// cryptoTimer is implemented by a ConnectionInfo that accounts for the CPU
+// time of its encryption.
+type cryptoTimer interface {
+	CryptoTimes() (encrypt, decrypt time.Duration)
+}
+
+// cpuAccount estimates the CPU time of an operation done many times over,
+// from samples.
+type cpuAccount struct {
+	calls atomic.Uint64
+	nanos atomic.Int64
+}
+
+// timeCPU runs fn, timing it when it's a sample and accounting for the
+// calls since the previous one.
+func (c *rawConnection) timeCPU(a *cpuAccount, operation string, fn func()) {
+	if a.calls.Add(1)%cpuSampleInterval != 0 {
+		fn()
+		return
+	}
+	t0 := time.Now()
+	fn()
+	d := time.Since(t0) * cpuSampleInterval
+	a.nanos.Add(int64(d))
+	metricDeviceCompressionSeconds.WithLabelValues(c.idString, operation).Add(d.Seconds())
+}
+
+func (c *rawConnection) cpuStatistics() CPUStatistics {
+	stats := CPUStatistics{
+		CompressS:   time.Duration(c.compressCPU.nanos.Load()).Seconds(),
+		DecompressS: time.Duration(c.decompressCPU.nanos.Load()).Seconds(),
+	}
+	if ct, ok := c.ConnectionInfo.(cryptoTimer); ok {
+		encrypt, decrypt := ct.CryptoTimes()
+		stats.EncryptS, stats.DecryptS = encrypt.Seconds(), decrypt.Seconds()
+	}
+	return stats
+}
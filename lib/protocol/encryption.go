@@ -159,6 +159,19 @@ func (e encryptedModel) DownloadProgress(p *DownloadProgress) error {
 	return nil
 }
 
+func (e encryptedModel) FolderPreview(req *FolderPreviewRequest) (*FolderPreview, error) {
+	if _, ok := e.folderKeys.get(req.Folder); ok {
+		// The peer is untrusted for this folder and must not learn the
+		// plaintext names in it.
+		return nil, ErrNoSuchFile
+	}
+	return e.model.FolderPreview(req)
+}
+
+func (e encryptedModel) ControlMessage(msg *ControlMessage) error {
+	return e.model.ControlMessage(msg)
+}
+
 func (e encryptedModel) ClusterConfig(config *ClusterConfig) error {
 	return e.model.ClusterConfig(config)
 }
@@ -292,6 +305,16 @@ func (e encryptedConnection) ResponseDevice(ctx context.Context, response *bep.R
 	return e.conn.ResponseDevice(ctx, response)
 }
 
+// FolderPreview requests a preview of a folder offered by the peer device
+func (e encryptedConnection) FolderPreview(ctx context.Context, req *FolderPreviewRequest) (*FolderPreview, error) {
+	return e.conn.FolderPreview(ctx, req)
+}
+
+// ControlMessage sends a control message to the peer device
+func (e encryptedConnection) ControlMessage(ctx context.Context, msg *ControlMessage) error {
+	return e.conn.ControlMessage(ctx, msg)
+}
+
 func encryptFileInfos(keyGen *KeyGenerator, files []FileInfo, folderKey *[keySize]byte) {
 	for i, fi := range files {
 		files[i] = encryptFileInfo(keyGen, fi, folderKey)
@@ -619,7 +642,13 @@ func (g *KeyGenerator) FileKey(filename string, folderKey *[keySize]byte) *[keyS
 }
 
 func PasswordToken(keyGen *KeyGenerator, folderID, password string) []byte {
-	return encryptDeterministic(knownBytes(folderID), keyGen.KeyFromPassword(folderID, password), nil)
+	return KeyToken(folderID, keyGen.KeyFromPassword(folderID, password))
+}
+
+// KeyToken returns the token for the folder key, as PasswordToken does for
+// the password the key derives from.
+func KeyToken(folderID string, folderKey *[keySize]byte) []byte {
+	return encryptDeterministic(knownBytes(folderID), folderKey, nil)
 }
 
 // slashify inserts slashes (and file extension) in the string to create an
@@ -0,0 +1,45 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	"encoding/base32"
+	"errors"
+	"strings"
+)
+
+var errRecoveryCode = errors.New("invalid recovery code")
+
+// RecoveryCode returns the folder key in a form fit for printing and typing
+// back in, to decrypt the folder without the password. It's formatted like
+// a device ID, with check characters catching typos.
+func RecoveryCode(folderKey *[keySize]byte) string {
+	code := strings.Trim(base32.StdEncoding.EncodeToString(folderKey[:]), "=")
+	code, err := luhnify(code)
+	if err != nil {
+		// Should never happen
+		panic(err)
+	}
+	return chunkify(code)
+}
+
+// KeyFromRecoveryCode returns the folder key of the recovery code. The error
+// doesn't repeat the code, as it's as secret as the password.
+func KeyFromRecoveryCode(code string) (*[keySize]byte, error) {
+	code = untypeoify(unchunkify(strings.ToUpper(strings.TrimSpace(code))))
+	code, err := unluhnify(code)
+	if err != nil {
+		return nil, errRecoveryCode
+	}
+	dec, err := base32.StdEncoding.DecodeString(code + "====")
+	if err != nil || len(dec) != keySize {
+		return nil, errRecoveryCode
+	}
+	var key [keySize]byte
+	copy(key[:], dec)
+	return &key, nil
+}
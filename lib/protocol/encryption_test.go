@@ -254,3 +254,33 @@ func TestIsEncryptedParent(t *testing.T) {
 		}
 	}
 }
+
+func TestRecoveryCode(t *testing.T) {
+	folderKey := testKeyGen.KeyFromPassword("my folder", "my password")
+	code := RecoveryCode(folderKey)
+
+	key, err := KeyFromRecoveryCode(strings.ToLower(code))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *key != *folderKey {
+		t.Error("recovered key mismatch")
+	}
+	if !bytes.Equal(KeyToken("my folder", key), PasswordToken(testKeyGen, "my folder", "my password")) {
+		t.Error("token of the recovered key mismatch")
+	}
+
+	// A typo is caught by the check characters
+	typo := []byte(code)
+	if typo[0] == 'A' {
+		typo[0] = 'B'
+	} else {
+		typo[0] = 'A'
+	}
+	if _, err := KeyFromRecoveryCode(string(typo)); err == nil {
+		t.Error("expected an error for a mistyped code")
+	}
+	if _, err := KeyFromRecoveryCode("not a code"); err == nil {
+		t.Error("expected an error for garbage")
+	}
+}
@@ -49,6 +49,19 @@ var (
 		Name:      "recv_messages_total",
 		Help:      "Total number of messages received, per device",
 	}, []string{"device"})
+	metricDeviceRecvViolations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "protocol",
+		Name:      "recv_violations_total",
+		Help:      "Total number of malformed files and requests received, per device and kind (index, request)",
+	}, []string{"device", "kind"})
+
+	metricDeviceCompressionSeconds = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "protocol",
+		Name:      "compression_cpu_seconds_total",
+		Help:      "Estimated CPU time spent compressing and decompressing messages, per device and operation (compress, decompress)",
+	}, []string{"device", "operation"})
 )
 
 func registerDeviceMetrics(deviceID string) {
@@ -60,4 +73,8 @@ func registerDeviceMetrics(deviceID string) {
 	metricDeviceRecvBytes.WithLabelValues(deviceID)
 	metricDeviceRecvDecompressedBytes.WithLabelValues(deviceID)
 	metricDeviceRecvMessages.WithLabelValues(deviceID)
+	metricDeviceRecvViolations.WithLabelValues(deviceID, "index")
+	metricDeviceRecvViolations.WithLabelValues(deviceID, "request")
+	metricDeviceCompressionSeconds.WithLabelValues(deviceID, "compress")
+	metricDeviceCompressionSeconds.WithLabelValues(deviceID, "decompress")
 }
@@ -43,6 +43,18 @@ type Connection struct {
 	connectionIDReturnsOnCall map[int]struct {
 		result1 string
 	}
+	ControlMessageStub        func(context.Context, *protocol.ControlMessage) error
+	controlMessageMutex       sync.RWMutex
+	controlMessageArgsForCall []struct {
+		arg1 context.Context
+		arg2 *protocol.ControlMessage
+	}
+	controlMessageReturns struct {
+		result1 error
+	}
+	controlMessageReturnsOnCall map[int]struct {
+		result1 error
+	}
 	CryptoStub        func() string
 	cryptoMutex       sync.RWMutex
 	cryptoArgsForCall []struct {
@@ -79,6 +91,20 @@ type Connection struct {
 	establishedAtReturnsOnCall map[int]struct {
 		result1 time.Time
 	}
+	FolderPreviewStub        func(context.Context, *protocol.FolderPreviewRequest) (*protocol.FolderPreview, error)
+	folderPreviewMutex       sync.RWMutex
+	folderPreviewArgsForCall []struct {
+		arg1 context.Context
+		arg2 *protocol.FolderPreviewRequest
+	}
+	folderPreviewReturns struct {
+		result1 *protocol.FolderPreview
+		result2 error
+	}
+	folderPreviewReturnsOnCall map[int]struct {
+		result1 *protocol.FolderPreview
+		result2 error
+	}
 	GetPingLossRateStub        func() float64
 	getPingLossRateMutex       sync.RWMutex
 	getPingLossRateArgsForCall []struct {
@@ -400,6 +426,68 @@ func (fake *Connection) ConnectionIDReturnsOnCall(i int, result1 string) {
 	}{result1}
 }
 
+func (fake *Connection) ControlMessage(arg1 context.Context, arg2 *protocol.ControlMessage) error {
+	fake.controlMessageMutex.Lock()
+	ret, specificReturn := fake.controlMessageReturnsOnCall[len(fake.controlMessageArgsForCall)]
+	fake.controlMessageArgsForCall = append(fake.controlMessageArgsForCall, struct {
+		arg1 context.Context
+		arg2 *protocol.ControlMessage
+	}{arg1, arg2})
+	stub := fake.ControlMessageStub
+	fakeReturns := fake.controlMessageReturns
+	fake.recordInvocation("ControlMessage", []interface{}{arg1, arg2})
+	fake.controlMessageMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *Connection) ControlMessageCallCount() int {
+	fake.controlMessageMutex.RLock()
+	defer fake.controlMessageMutex.RUnlock()
+	return len(fake.controlMessageArgsForCall)
+}
+
+func (fake *Connection) ControlMessageCalls(stub func(context.Context, *protocol.ControlMessage) error) {
+	fake.controlMessageMutex.Lock()
+	defer fake.controlMessageMutex.Unlock()
+	fake.ControlMessageStub = stub
+}
+
+func (fake *Connection) ControlMessageArgsForCall(i int) (context.Context, *protocol.ControlMessage) {
+	fake.controlMessageMutex.RLock()
+	defer fake.controlMessageMutex.RUnlock()
+	argsForCall := fake.controlMessageArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Connection) ControlMessageReturns(result1 error) {
+	fake.controlMessageMutex.Lock()
+	defer fake.controlMessageMutex.Unlock()
+	fake.ControlMessageStub = nil
+	fake.controlMessageReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *Connection) ControlMessageReturnsOnCall(i int, result1 error) {
+	fake.controlMessageMutex.Lock()
+	defer fake.controlMessageMutex.Unlock()
+	fake.ControlMessageStub = nil
+	if fake.controlMessageReturnsOnCall == nil {
+		fake.controlMessageReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.controlMessageReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *Connection) Crypto() string {
 	fake.cryptoMutex.Lock()
 	ret, specificReturn := fake.cryptoReturnsOnCall[len(fake.cryptoArgsForCall)]
@@ -592,6 +680,71 @@ func (fake *Connection) EstablishedAtReturnsOnCall(i int, result1 time.Time) {
 	}{result1}
 }
 
+func (fake *Connection) FolderPreview(arg1 context.Context, arg2 *protocol.FolderPreviewRequest) (*protocol.FolderPreview, error) {
+	fake.folderPreviewMutex.Lock()
+	ret, specificReturn := fake.folderPreviewReturnsOnCall[len(fake.folderPreviewArgsForCall)]
+	fake.folderPreviewArgsForCall = append(fake.folderPreviewArgsForCall, struct {
+		arg1 context.Context
+		arg2 *protocol.FolderPreviewRequest
+	}{arg1, arg2})
+	stub := fake.FolderPreviewStub
+	fakeReturns := fake.folderPreviewReturns
+	fake.recordInvocation("FolderPreview", []interface{}{arg1, arg2})
+	fake.folderPreviewMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *Connection) FolderPreviewCallCount() int {
+	fake.folderPreviewMutex.RLock()
+	defer fake.folderPreviewMutex.RUnlock()
+	return len(fake.folderPreviewArgsForCall)
+}
+
+func (fake *Connection) FolderPreviewCalls(stub func(context.Context, *protocol.FolderPreviewRequest) (*protocol.FolderPreview, error)) {
+	fake.folderPreviewMutex.Lock()
+	defer fake.folderPreviewMutex.Unlock()
+	fake.FolderPreviewStub = stub
+}
+
+func (fake *Connection) FolderPreviewArgsForCall(i int) (context.Context, *protocol.FolderPreviewRequest) {
+	fake.folderPreviewMutex.RLock()
+	defer fake.folderPreviewMutex.RUnlock()
+	argsForCall := fake.folderPreviewArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *Connection) FolderPreviewReturns(result1 *protocol.FolderPreview, result2 error) {
+	fake.folderPreviewMutex.Lock()
+	defer fake.folderPreviewMutex.Unlock()
+	fake.FolderPreviewStub = nil
+	fake.folderPreviewReturns = struct {
+		result1 *protocol.FolderPreview
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *Connection) FolderPreviewReturnsOnCall(i int, result1 *protocol.FolderPreview, result2 error) {
+	fake.folderPreviewMutex.Lock()
+	defer fake.folderPreviewMutex.Unlock()
+	fake.FolderPreviewStub = nil
+	if fake.folderPreviewReturnsOnCall == nil {
+		fake.folderPreviewReturnsOnCall = make(map[int]struct {
+			result1 *protocol.FolderPreview
+			result2 error
+		})
+	}
+	fake.folderPreviewReturnsOnCall[i] = struct {
+		result1 *protocol.FolderPreview
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *Connection) GetPingLossRate() float64 {
 	fake.getPingLossRateMutex.Lock()
 	ret, specificReturn := fake.getPingLossRateReturnsOnCall[len(fake.getPingLossRateArgsForCall)]
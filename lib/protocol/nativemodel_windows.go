@@ -68,6 +68,7 @@ func fixupFiles(files []FileInfo) []FileInfo {
 
 		// Fixup the path separators
 		files[i].Name = filepath.FromSlash(files[i].Name)
+		files[i].HardLinkTarget = filepath.FromSlash(files[i].HardLinkTarget)
 
 		if out != nil {
 			out = append(out, files[i])
@@ -25,7 +25,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/klauspost/compress/zstd"
 	lz4 "github.com/pierrec/lz4/v4"
+	"golang.org/x/time/rate"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/syncthing/syncthing/internal/gen/bep"
@@ -56,6 +58,12 @@ const (
 
 	// don't bother compressing messages smaller than this many bytes
 	compressionThreshold = 128
+
+	// Folder preview requests are answered one at a time per connection,
+	// at a sustained rate of one per folderPreviewInterval with bursts of
+	// folderPreviewBurst. Requests beyond that are refused.
+	folderPreviewInterval = 5 * time.Second
+	folderPreviewBurst    = 3
 )
 
 var errNotCompressible = errors.New("not compressible")
@@ -110,6 +118,20 @@ type ResponseDeviceHandler interface {
 	HandleResponseDevice(response *bep.ResponseDevice) error
 }
 
+// FolderPreviewHandler is an optional interface that models can implement
+// to answer folder preview requests from peers. Models not implementing it
+// answer all such requests with ErrNoSuchFile.
+type FolderPreviewHandler interface {
+	FolderPreview(conn Connection, req *FolderPreviewRequest) (*FolderPreview, error)
+}
+
+// ControlMessageHandler is an optional interface that models can implement
+// to receive control messages from peers. Models not implementing it
+// ignore such messages.
+type ControlMessageHandler interface {
+	ControlMessage(conn Connection, msg *ControlMessage) error
+}
+
 // rawModel is the Model interface, but without the initial Connection
 // parameter. Internal use only.
 type rawModel interface {
@@ -119,6 +141,8 @@ type rawModel interface {
 	ClusterConfig(*ClusterConfig) error
 	Closed(err error)
 	DownloadProgress(*DownloadProgress) error
+	FolderPreview(*FolderPreviewRequest) (*FolderPreview, error)
+	ControlMessage(*ControlMessage) error
 	// HandleQueryDevice(*bep.QueryDevice) error
 	// HandleResponseDevice(*bep.ResponseDevice) error
 }
@@ -165,6 +189,14 @@ type Connection interface {
 	// for a specific device.
 	ResponseDevice(ctx context.Context, response *bep.ResponseDevice) error
 
+	// Send a Folder Preview Request to the peer device and wait for the
+	// summary of the contents of the folder it offers.
+	FolderPreview(ctx context.Context, req *FolderPreviewRequest) (*FolderPreview, error)
+
+	// Send a Control Message to the peer device. Returns once the message
+	// has been written to the connection.
+	ControlMessage(ctx context.Context, msg *ControlMessage) error
+
 	Start()
 	Close(err error)
 	DeviceID() DeviceID
@@ -246,12 +278,16 @@ type rawConnection struct {
 	cw     *countingWriter
 	closer io.Closer // Closing the underlying connection and thus cr and cw
 
-	awaitingMut sync.Mutex // Protects awaiting and nextID.
-	awaiting    map[int]chan asyncResult
-	nextID      int
+	awaitingMut     sync.Mutex // Protects awaiting, awaitingPreview and nextID.
+	awaiting        map[int]chan asyncResult
+	awaitingPreview map[int]chan folderPreviewResult
+	nextID          int
 
 	idxMut sync.Mutex // ensures serialization of Index calls
 
+	previewBusy    chan struct{} // holds a token while a folder preview is being computed
+	previewLimiter *rate.Limiter
+
 	inbox                 chan proto.Message
 	outbox                chan asyncMessage
 	closeBox              chan asyncMessage
@@ -261,7 +297,14 @@ type rawConnection struct {
 	closeOnce             sync.Once
 	sendCloseOnce         sync.Once
 	compression           Compression
-	startStopMut          sync.Mutex // start and stop must be serialized
+	compressionAlgo       CompressionAlgorithm
+	zstdEncoder           *zstd.Encoder // when compressing with zstd
+	sealKey               *[keySize]byte // when sealing block data
+	startStopMut          sync.Mutex    // start and stop must be serialized
+
+	compressCPU, decompressCPU cpuAccount
+
+	violations int // malformed messages received, see maxViolations
 
 	loopWG sync.WaitGroup // Need to ensure no leftover routines in testing
 
@@ -282,6 +325,11 @@ type asyncResult struct {
 	err error
 }
 
+type folderPreviewResult struct {
+	preview *FolderPreview
+	err     error
+}
+
 type asyncMessage struct {
 	msg  proto.Message
 	done chan struct{} // done closes when we're done sending the message
@@ -302,7 +350,7 @@ const (
 var CloseTimeout = 10 * time.Second
 
 // NewConnection creates a new connection with the given parameters
-func NewConnection(deviceID DeviceID, reader io.Reader, writer io.Writer, closer io.Closer, model Model, connInfo ConnectionInfo, compress Compression, keyGen *KeyGenerator) Connection {
+func NewConnection(deviceID DeviceID, reader io.Reader, writer io.Writer, closer io.Closer, model Model, connInfo ConnectionInfo, compress Compression, keyGen *KeyGenerator, opts ...ConnectionOption) Connection {
 	// We create the wrapper for the model first, as it needs to be passed
 	// in at the lowest level in the stack. At the end of construction,
 	// before returning, we add the connection to cwm so that it can be used
@@ -317,6 +365,9 @@ func NewConnection(deviceID DeviceID, reader io.Reader, writer io.Writer, closer
 	// We do the wire format conversion first (outermost) so that the
 	// metadata is in wire format when it reaches the encryption step.
 	rc := newRawConnection(deviceID, reader, writer, closer, em, connInfo, compress)
+	for _, opt := range opts {
+		opt(rc)
+	}
 	ec := newEncryptedConnection(rc, rc, em.folderKeys, keyGen)
 	wc := wireFormatConnection{ec}
 
@@ -325,7 +376,7 @@ func NewConnection(deviceID DeviceID, reader io.Reader, writer io.Writer, closer
 }
 
 // NewConnectionWithHealthMonitor creates a new connection with adaptive keep-alive support
-func NewConnectionWithHealthMonitor(deviceID DeviceID, reader io.Reader, writer io.Writer, closer io.Closer, model Model, connInfo ConnectionInfo, compress Compression, keyGen *KeyGenerator, healthMonitor HealthMonitorInterface) Connection {
+func NewConnectionWithHealthMonitor(deviceID DeviceID, reader io.Reader, writer io.Writer, closer io.Closer, model Model, connInfo ConnectionInfo, compress Compression, keyGen *KeyGenerator, healthMonitor HealthMonitorInterface, opts ...ConnectionOption) Connection {
 	// We create the wrapper for the model first, as it needs to be passed
 	// in at the lowest level in the stack. At the end of construction,
 	// before returning, we add the connection to cwm so that it can be used
@@ -340,6 +391,9 @@ func NewConnectionWithHealthMonitor(deviceID DeviceID, reader io.Reader, writer
 	// We do the wire format conversion first (outermost) so that the
 	// metadata is in wire format when it reaches the encryption step.
 	rc := newRawConnectionWithHealthMonitor(deviceID, reader, writer, closer, em, connInfo, compress, healthMonitor)
+	for _, opt := range opts {
+		opt(rc)
+	}
 	ec := newEncryptedConnection(rc, rc, em.folderKeys, keyGen)
 	wc := wireFormatConnection{ec}
 
@@ -363,6 +417,9 @@ func newRawConnection(deviceID DeviceID, reader io.Reader, writer io.Writer, clo
 		cw:                    cw,
 		closer:                closer,
 		awaiting:              make(map[int]chan asyncResult),
+		awaitingPreview:       make(map[int]chan folderPreviewResult),
+		previewBusy:           make(chan struct{}, 1),
+		previewLimiter:        rate.NewLimiter(rate.Every(folderPreviewInterval), folderPreviewBurst),
 		inbox:                 make(chan proto.Message),
 		outbox:                make(chan asyncMessage),
 		closeBox:              make(chan asyncMessage),
@@ -370,6 +427,7 @@ func newRawConnection(deviceID DeviceID, reader io.Reader, writer io.Writer, clo
 		dispatcherLoopStopped: make(chan struct{}),
 		closed:                make(chan struct{}),
 		compression:           compress,
+		compressionAlgo:       CompressionAlgorithmLZ4,
 		loopWG:                sync.WaitGroup{},
 	}
 }
@@ -390,6 +448,9 @@ func newRawConnectionWithHealthMonitor(deviceID DeviceID, reader io.Reader, writ
 		cw:                    cw,
 		closer:                closer,
 		awaiting:              make(map[int]chan asyncResult),
+		awaitingPreview:       make(map[int]chan folderPreviewResult),
+		previewBusy:           make(chan struct{}, 1),
+		previewLimiter:        rate.NewLimiter(rate.Every(folderPreviewInterval), folderPreviewBurst),
 		inbox:                 make(chan proto.Message),
 		outbox:                make(chan asyncMessage),
 		closeBox:              make(chan asyncMessage),
@@ -397,6 +458,7 @@ func newRawConnectionWithHealthMonitor(deviceID DeviceID, reader io.Reader, writ
 		dispatcherLoopStopped: make(chan struct{}),
 		closed:                make(chan struct{}),
 		compression:           compress,
+		compressionAlgo:       CompressionAlgorithmLZ4,
 		loopWG:                sync.WaitGroup{},
 		healthMonitor:         healthMonitor,
 	}
@@ -513,6 +575,13 @@ func (c *rawConnection) Request(ctx context.Context, req *Request) ([]byte, erro
 		if !ok {
 			return nil, ErrClosed
 		}
+		if res.err == nil && c.sealKey != nil {
+			data, err := openBlock(res.val, c.sealKey, req)
+			if err != nil {
+				return nil, fmt.Errorf("opening sealed block: %w", err)
+			}
+			return data, nil
+		}
 		return res.val, res.err
 	case <-ctx.Done():
 		return nil, ctx.Err()
@@ -566,6 +635,71 @@ func (c *rawConnection) ResponseDevice(ctx context.Context, response *bep.Respon
 	return nil
 }
 
+// FolderPreview asks the peer for a summary of the given folder and waits
+// for the answer.
+func (c *rawConnection) FolderPreview(ctx context.Context, req *FolderPreviewRequest) (*FolderPreview, error) {
+	select {
+	case <-c.closed:
+		return nil, ErrClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+
+	rc := make(chan folderPreviewResult, 1)
+
+	c.awaitingMut.Lock()
+	id := c.nextID
+	c.nextID++
+	c.awaitingPreview[id] = rc
+	c.awaitingMut.Unlock()
+
+	req.ID = id
+	if !c.send(ctx, req.toWire(), nil) {
+		c.awaitingMut.Lock()
+		delete(c.awaitingPreview, id)
+		c.awaitingMut.Unlock()
+		return nil, ErrClosed
+	}
+
+	select {
+	case res, ok := <-rc:
+		if !ok {
+			return nil, ErrClosed
+		}
+		return res.preview, res.err
+	case <-ctx.Done():
+		c.awaitingMut.Lock()
+		delete(c.awaitingPreview, id)
+		c.awaitingMut.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+// ControlMessage sends the control message to the peer and waits for it to
+// be written.
+func (c *rawConnection) ControlMessage(ctx context.Context, msg *ControlMessage) error {
+	select {
+	case <-c.closed:
+		return ErrClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+	done := make(chan struct{})
+	if !c.send(ctx, msg.toWire(), done) {
+		return ErrClosed
+	}
+	<-done
+	select {
+	case <-c.closed:
+		// The write may or may not have succeeded.
+		return ErrClosed
+	default:
+		return nil
+	}
+}
+
 func (c *rawConnection) ping() bool {
 	// Record timestamp when ping is sent if we have a health monitor
 	if c.healthMonitor != nil {
@@ -655,6 +789,9 @@ func (c *rawConnection) dispatcherLoop() (err error) {
 			if err := checkIndexConsistency(idx.Files); err != nil {
 				return newProtocolError(err, msgContext)
 			}
+			if idx.Files, err = c.validIndexFiles(idx.Files, true, 0); err != nil {
+				return newProtocolError(err, msgContext)
+			}
 			err = c.handleIndex(idx)
 
 		case *bep.IndexUpdate:
@@ -662,10 +799,21 @@ func (c *rawConnection) dispatcherLoop() (err error) {
 			if err := checkIndexConsistency(idxUp.Files); err != nil {
 				return newProtocolError(err, msgContext)
 			}
+			// Tombstones are sent outside of the sequence.
+			if idxUp.Files, err = c.validIndexFiles(idxUp.Files, !idxUp.Tombstones, idxUp.PrevSequence); err != nil {
+				return newProtocolError(err, msgContext)
+			}
 			err = c.handleIndexUpdate(idxUp)
 
 		case *bep.Request:
-			go c.handleRequest(requestFromWire(msg))
+			req := requestFromWire(msg)
+			if err := checkRequest(req); err != nil {
+				if err := c.refuseRequest(req, err); err != nil {
+					return newProtocolError(err, msgContext)
+				}
+				continue
+			}
+			go c.handleRequest(req)
 
 		case *bep.Response:
 			c.handleResponse(responseFromWire(msg))
@@ -687,6 +835,15 @@ func (c *rawConnection) dispatcherLoop() (err error) {
 			c.lastPingReceiveTime = time.Now()
 			c.pingStatsMut.Unlock()
 
+		case *bep.FolderPreviewRequest:
+			c.dispatchFolderPreviewRequest(folderPreviewRequestFromWire(msg))
+
+		case *bep.FolderPreviewResponse:
+			c.handleFolderPreviewResponse(msg)
+
+		case *bep.ControlMessage:
+			err = c.model.ControlMessage(controlMessageFromWire(msg))
+
 		case *bep.QueryDevice:
 			// Handle QueryDevice message
 			// Check if the model implements the optional QueryDeviceHandler interface
@@ -751,7 +908,22 @@ func (c *rawConnection) readMessageAfterHeader(hdr *bep.Header, fourByteBuf []by
 		// Nothing
 
 	case bep.MessageCompression_MESSAGE_COMPRESSION_LZ4:
-		decomp, err := lz4Decompress(buf)
+		var decomp []byte
+		var err error
+		c.timeCPU(&c.decompressCPU, "decompress", func() {
+			decomp, err = lz4Decompress(buf)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("decompressing message: %w", err)
+		}
+		buf = decomp
+
+	case bep.MessageCompression_MESSAGE_COMPRESSION_ZSTD:
+		var decomp []byte
+		var err error
+		c.timeCPU(&c.decompressCPU, "decompress", func() {
+			decomp, err = zstdDecompress(buf)
+		})
 		if err != nil {
 			return nil, fmt.Errorf("decompressing message: %w", err)
 		}
@@ -880,6 +1052,9 @@ func checkFilename(name string) error {
 }
 
 func (c *rawConnection) handleRequest(req *Request) {
+	// The request is sealed for as it came in, before the model adjusts
+	// it.
+	sealedReq := *req
 	res, err := c.model.Request(req)
 	if err != nil {
 		resp := &Response{
@@ -889,10 +1064,14 @@ func (c *rawConnection) handleRequest(req *Request) {
 		c.send(context.Background(), resp.toWire(), nil)
 		return
 	}
+	data := res.Data()
+	if c.sealKey != nil {
+		data = sealBlock(data, c.sealKey, &sealedReq)
+	}
 	done := make(chan struct{})
 	resp := &Response{
 		ID:   req.ID,
-		Data: res.Data(),
+		Data: data,
 		Code: errorToCode(nil),
 	}
 	c.send(context.Background(), resp.toWire(), done)
@@ -910,6 +1089,57 @@ func (c *rawConnection) handleResponse(resp *Response) {
 	c.awaitingMut.Unlock()
 }
 
+// dispatchFolderPreviewRequest starts handling the request unless another
+// preview is already in progress or the peer is over its rate, in which case
+// the request is refused straight away.
+func (c *rawConnection) dispatchFolderPreviewRequest(req *FolderPreviewRequest) {
+	select {
+	case c.previewBusy <- struct{}{}:
+	default:
+		l.Debugln("refusing folder preview request from", c.deviceID, "while another is in progress")
+		c.refuseFolderPreviewRequest(req)
+		return
+	}
+	if !c.previewLimiter.Allow() {
+		<-c.previewBusy
+		l.Debugln("refusing folder preview request from", c.deviceID, "over rate")
+		c.refuseFolderPreviewRequest(req)
+		return
+	}
+	go c.handleFolderPreviewRequest(req)
+}
+
+func (c *rawConnection) refuseFolderPreviewRequest(req *FolderPreviewRequest) {
+	preview := &FolderPreview{ID: req.ID}
+	c.send(context.Background(), preview.toWire(errorToCode(ErrGeneric)), nil)
+}
+
+// handleFolderPreviewRequest computes and sends the preview, releasing the
+// token taken by dispatchFolderPreviewRequest once it's done computing.
+func (c *rawConnection) handleFolderPreviewRequest(req *FolderPreviewRequest) {
+	preview, err := c.model.FolderPreview(req)
+	<-c.previewBusy
+	if err != nil {
+		preview = &FolderPreview{}
+	}
+	preview.ID = req.ID
+	c.send(context.Background(), preview.toWire(errorToCode(err)), nil)
+}
+
+func (c *rawConnection) handleFolderPreviewResponse(resp *bep.FolderPreviewResponse) {
+	c.awaitingMut.Lock()
+	if rc := c.awaitingPreview[int(resp.Id)]; rc != nil {
+		delete(c.awaitingPreview, int(resp.Id))
+		if err := codeToError(resp.Code); err != nil {
+			rc <- folderPreviewResult{err: err}
+		} else {
+			rc <- folderPreviewResult{preview: folderPreviewFromWire(resp)}
+		}
+		close(rc)
+	}
+	c.awaitingMut.Unlock()
+}
+
 func (c *rawConnection) send(ctx context.Context, msg proto.Message, done chan struct{}) bool {
 	select {
 	case c.outbox <- asyncMessage{msg, done}:
@@ -1041,6 +1271,9 @@ func (c *rawConnection) writeCompressedMessage(msg proto.Message, marshaled []by
 		Type:        typeOf(msg),
 		Compression: bep.MessageCompression_MESSAGE_COMPRESSION_LZ4,
 	}
+	if c.compressionAlgo == CompressionAlgorithmZstd {
+		hdr.Compression = bep.MessageCompression_MESSAGE_COMPRESSION_ZSTD
+	}
 	hdrSize := proto.Size(hdr)
 	if hdrSize > 1<<16-1 {
 		panic("impossibly large header")
@@ -1057,7 +1290,14 @@ func (c *rawConnection) writeCompressedMessage(msg proto.Message, marshaled []by
 	buf := BufferPool.Get(maxCompressed)
 	defer BufferPool.Put(buf)
 
-	compressedSize, err := lz4Compress(marshaled, buf[cOverhead:])
+	var compressedSize int
+	c.timeCPU(&c.compressCPU, "compress", func() {
+		if hdr.Compression == bep.MessageCompression_MESSAGE_COMPRESSION_ZSTD {
+			compressedSize, err = zstdCompress(c.zstdEncoder, marshaled, buf[cOverhead:])
+		} else {
+			compressedSize, err = lz4Compress(marshaled, buf[cOverhead:])
+		}
+	})
 	totSize := compressedSize + cOverhead
 	if err != nil {
 		return false, nil
@@ -1102,6 +1342,12 @@ func typeOf(msg proto.Message) bep.MessageType {
 		return bep.MessageType_MESSAGE_TYPE_QUERY_DEVICE
 	case *bep.ResponseDevice:
 		return bep.MessageType_MESSAGE_TYPE_RESPONSE_DEVICE
+	case *bep.FolderPreviewRequest:
+		return bep.MessageType_MESSAGE_TYPE_FOLDER_PREVIEW_REQUEST
+	case *bep.FolderPreviewResponse:
+		return bep.MessageType_MESSAGE_TYPE_FOLDER_PREVIEW_RESPONSE
+	case *bep.ControlMessage:
+		return bep.MessageType_MESSAGE_TYPE_CONTROL_MESSAGE
 	default:
 		panic("bug: unknown message type")
 	}
@@ -1129,6 +1375,12 @@ func newMessage(t bep.MessageType) (proto.Message, error) {
 		return new(bep.QueryDevice), nil
 	case bep.MessageType_MESSAGE_TYPE_RESPONSE_DEVICE:
 		return new(bep.ResponseDevice), nil
+	case bep.MessageType_MESSAGE_TYPE_FOLDER_PREVIEW_REQUEST:
+		return new(bep.FolderPreviewRequest), nil
+	case bep.MessageType_MESSAGE_TYPE_FOLDER_PREVIEW_RESPONSE:
+		return new(bep.FolderPreviewResponse), nil
+	case bep.MessageType_MESSAGE_TYPE_CONTROL_MESSAGE:
+		return new(bep.ControlMessage), nil
 	default:
 		return nil, errUnknownMessage
 	}
@@ -1197,6 +1449,10 @@ func (c *rawConnection) internalClose(err error) {
 				delete(c.awaiting, i)
 			}
 		}
+		for i, ch := range c.awaitingPreview {
+			close(ch)
+			delete(c.awaitingPreview, i)
+		}
 		c.awaitingMut.Unlock()
 
 		if !c.startTime.IsZero() {
@@ -1355,10 +1611,11 @@ func (c *rawConnection) HealthMonitor() HealthMonitorInterface {
 }
 
 type Statistics struct {
-	At            time.Time `json:"at"`
-	InBytesTotal  int64     `json:"inBytesTotal"`
-	OutBytesTotal int64     `json:"outBytesTotal"`
-	StartedAt     time.Time `json:"startedAt"`
+	At            time.Time     `json:"at"`
+	InBytesTotal  int64         `json:"inBytesTotal"`
+	OutBytesTotal int64         `json:"outBytesTotal"`
+	StartedAt     time.Time     `json:"startedAt"`
+	CPU           CPUStatistics `json:"cpu"`
 }
 
 func (c *rawConnection) Statistics() Statistics {
@@ -1367,6 +1624,7 @@ func (c *rawConnection) Statistics() Statistics {
 		InBytesTotal:  c.cr.Tot(),
 		OutBytesTotal: c.cw.Tot(),
 		StartedAt:     c.startTime,
+		CPU:           c.cpuStatistics(),
 	}
 }
 
@@ -1424,6 +1682,12 @@ func messageContext(msg proto.Message) (string, error) {
 		return "ping", nil
 	case *bep.Close:
 		return "close", nil
+	case *bep.FolderPreviewRequest:
+		return fmt.Sprintf("folder-preview-request for %v", msg.Folder), nil
+	case *bep.FolderPreviewResponse:
+		return "folder-preview-response", nil
+	case *bep.ControlMessage:
+		return fmt.Sprintf("control-message %v/%v", msg.Kind, msg.Key), nil
 	// case *bep.QueryDevice:
 	// 	return "query-device", nil
 	// case *bep.ResponseDevice:
@@ -1465,6 +1729,20 @@ func (c *connectionWrappingModel) DownloadProgress(p *DownloadProgress) error {
 	return c.model.DownloadProgress(c.conn, p)
 }
 
+func (c *connectionWrappingModel) FolderPreview(req *FolderPreviewRequest) (*FolderPreview, error) {
+	if handler, ok := c.model.(FolderPreviewHandler); ok {
+		return handler.FolderPreview(c.conn, req)
+	}
+	return nil, ErrNoSuchFile
+}
+
+func (c *connectionWrappingModel) ControlMessage(msg *ControlMessage) error {
+	if handler, ok := c.model.(ControlMessageHandler); ok {
+		return handler.ControlMessage(c.conn, msg)
+	}
+	return nil
+}
+
 // GetPingLossRate returns the current ping packet loss rate as a percentage
 func (c *connectionWrappingModel) GetPingLossRate() float64 {
 	if rawConn, ok := c.conn.(*rawConnection); ok {
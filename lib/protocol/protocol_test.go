@@ -17,6 +17,7 @@ import (
 	"time"
 
 	lz4 "github.com/pierrec/lz4/v4"
+	"golang.org/x/time/rate"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/syncthing/syncthing/internal/gen/bep"
@@ -580,3 +581,61 @@ func getRawConnection(c Connection) *rawConnection {
 	}
 	return raw
 }
+
+type blockingPreviewModel struct {
+	*TestModel
+	started chan struct{}
+	release chan struct{}
+}
+
+func (m *blockingPreviewModel) FolderPreview(_ Connection, req *FolderPreviewRequest) (*FolderPreview, error) {
+	m.started <- struct{}{}
+	<-m.release
+	return &FolderPreview{Files: 1}, nil
+}
+
+func TestFolderPreviewRequestsBounded(t *testing.T) {
+	ar, aw := io.Pipe()
+	br, bw := io.Pipe()
+
+	m1 := &blockingPreviewModel{TestModel: newTestModel(), started: make(chan struct{}, 4), release: make(chan struct{})}
+	c0 := getRawConnection(NewConnection(c0ID, ar, bw, testutil.NoopCloser{}, newTestModel(), new(mockedConnectionInfo), CompressionAlways, testKeyGen))
+	c0.Start()
+	defer closeAndWait(c0, ar, bw)
+	c1 := getRawConnection(NewConnection(c1ID, br, aw, testutil.NoopCloser{}, m1, new(mockedConnectionInfo), CompressionAlways, testKeyGen))
+	c1.previewLimiter = rate.NewLimiter(rate.Every(time.Hour), 2)
+	c1.Start()
+	defer closeAndWait(c1, ar, bw)
+	c0.ClusterConfig(&ClusterConfig{}, nil)
+	c1.ClusterConfig(&ClusterConfig{}, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	first := make(chan error, 1)
+	go func() {
+		_, err := c0.FolderPreview(ctx, &FolderPreviewRequest{Folder: "default"})
+		first <- err
+	}()
+	<-m1.started
+
+	// A second request while the first is still being computed is refused
+	// without waiting for it.
+	if _, err := c0.FolderPreview(ctx, &FolderPreviewRequest{Folder: "default"}); !errors.Is(err, ErrGeneric) {
+		t.Fatalf("concurrent preview: got %v, expected %v", err, ErrGeneric)
+	}
+
+	close(m1.release)
+	if err := <-first; err != nil {
+		t.Fatal("first preview:", err)
+	}
+
+	// Refused requests don't count against the rate, so there is one more
+	// in the burst before the peer is over it.
+	if _, err := c0.FolderPreview(ctx, &FolderPreviewRequest{Folder: "default"}); err != nil {
+		t.Fatal("preview within burst:", err)
+	}
+	if _, err := c0.FolderPreview(ctx, &FolderPreviewRequest{Folder: "default"}); !errors.Is(err, ErrGeneric) {
+		t.Fatalf("preview over rate: got %v, expected %v", err, ErrGeneric)
+	}
+}
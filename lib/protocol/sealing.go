@@ -0,0 +1,196 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/edwards25519"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Block sealing encrypts the data of responses end to end between two
+// trusted devices, on top of TLS, so that blocks passing through a relay
+// stay protected should TLS let us down. The key is agreed on from the
+// device keys of both sides, which are pinned by the device IDs, so no
+// additional exchange is needed.
+
+const blockSealingInfo = "syncthing block sealing"
+
+var errDeviceKeys = errors.New("device keys are not usable for key agreement")
+
+// NegotiateBlockSealing returns whether to seal block data on a connection,
+// given the hellos of both sides.
+func NegotiateBlockSealing(local, remote Hello) bool {
+	return local.BlockSealing && remote.BlockSealing
+}
+
+// BlockSealingSupported returns whether the private key can be used to
+// agree on a block sealing key.
+func BlockSealingSupported(key crypto.PrivateKey) bool {
+	switch key := key.(type) {
+	case ed25519.PrivateKey:
+		return true
+	case *ecdsa.PrivateKey:
+		_, err := key.ECDH()
+		return err == nil
+	default:
+		return false
+	}
+}
+
+// BlockSealingKey returns the key to seal block data with between us and
+// the device with the given certificate. Both devices derive the same key,
+// as long as their device keys are of the same kind.
+func BlockSealingKey(local crypto.PrivateKey, localID DeviceID, remote *x509.Certificate) (*[keySize]byte, error) {
+	key, err := DevicePairKey(local, localID, remote, blockSealingInfo)
+	if err != nil {
+		return nil, fmt.Errorf("block sealing key: %w", err)
+	}
+	return key, nil
+}
+
+// DevicePairKey returns a key shared by us and the device with the given
+// certificate, agreed on from the device keys of both sides and bound to
+// the pair of devices and the purpose given by info.
+func DevicePairKey(local crypto.PrivateKey, localID DeviceID, remote *x509.Certificate, info string) (*[keySize]byte, error) {
+	var secret []byte
+	var err error
+	switch local := local.(type) {
+	case ed25519.PrivateKey:
+		remotePub, ok := remote.PublicKey.(ed25519.PublicKey)
+		if !ok {
+			return nil, errDeviceKeys
+		}
+		secret, err = x25519Secret(local, remotePub)
+	case *ecdsa.PrivateKey:
+		remotePub, ok := remote.PublicKey.(*ecdsa.PublicKey)
+		if !ok || remotePub.Curve != local.Curve {
+			return nil, errDeviceKeys
+		}
+		secret, err = ecdsaSecret(local, remotePub)
+	default:
+		return nil, errDeviceKeys
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Bind the key to the pair of devices, in the same order on both
+	// sides.
+	remoteID := NewDeviceID(remote.Raw)
+	first, second := localID, remoteID
+	if first.Compare(second) > 0 {
+		first, second = second, first
+	}
+	infoBytes := append([]byte(info), first[:]...)
+	infoBytes = append(infoBytes, second[:]...)
+
+	var key [keySize]byte
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, infoBytes), key[:]); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// WithBlockSealing makes the connection seal the block data it serves, and
+// open the block data it receives, with the given key. Sealing must have
+// been negotiated with the other device.
+func WithBlockSealing(key *[keySize]byte) ConnectionOption {
+	return func(c *rawConnection) {
+		c.sealKey = key
+	}
+}
+
+func ecdsaSecret(local *ecdsa.PrivateKey, remote *ecdsa.PublicKey) ([]byte, error) {
+	localKey, err := local.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	remoteKey, err := remote.ECDH()
+	if err != nil {
+		return nil, err
+	}
+	return localKey.ECDH(remoteKey)
+}
+
+// x25519Secret agrees on a secret using the X25519 equivalents of the
+// Ed25519 keys.
+func x25519Secret(local ed25519.PrivateKey, remote ed25519.PublicKey) ([]byte, error) {
+	h := sha512.Sum512(local.Seed())
+	localKey, err := ecdh.X25519().NewPrivateKey(h[:32])
+	if err != nil {
+		return nil, err
+	}
+	u, err := edwardsToMontgomery(remote)
+	if err != nil {
+		return nil, err
+	}
+	remoteKey, err := ecdh.X25519().NewPublicKey(u)
+	if err != nil {
+		return nil, err
+	}
+	return localKey.ECDH(remoteKey)
+}
+
+// edwardsToMontgomery converts an Ed25519 public key to the X25519 one.
+func edwardsToMontgomery(pub ed25519.PublicKey) ([]byte, error) {
+	p, err := new(edwards25519.Point).SetBytes(pub)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Ed25519 public key: %w", err)
+	}
+	return p.BytesMontgomery(), nil
+}
+
+// sealBlock encrypts the data with a random nonce, authenticating it
+// together with the request it answers.
+func sealBlock(data []byte, key *[keySize]byte, req *Request) []byte {
+	aead, err := chacha20poly1305.NewX(key[:])
+	if err != nil {
+		panic("cipher failure: " + err.Error())
+	}
+	nonce := randomNonce()
+	return aead.Seal(nonce[:], nonce[:], data, sealedRequestData(req))
+}
+
+// openBlock decrypts data sealed by sealBlock for the request.
+func openBlock(data []byte, key *[keySize]byte, req *Request) ([]byte, error) {
+	if len(data) < blockOverhead {
+		return nil, errors.New("sealed block too short")
+	}
+	aead, err := chacha20poly1305.NewX(key[:])
+	if err != nil {
+		panic("cipher failure: " + err.Error())
+	}
+	return aead.Open(nil, data[:nonceSize], data[nonceSize:], sealedRequestData(req))
+}
+
+// sealedRequestData is the additional data identifying the block a sealed
+// response belongs to, so that responses can't be passed off as each
+// other.
+func sealedRequestData(req *Request) []byte {
+	buf := make([]byte, 0, len(req.Folder)+len(req.Name)+24)
+	buf = append(buf, req.Folder...)
+	buf = append(buf, 0)
+	buf = append(buf, req.Name...)
+	buf = append(buf, 0)
+	buf = binary.BigEndian.AppendUint64(buf, uint64(req.Offset))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(req.Size))
+	buf = binary.BigEndian.AppendUint32(buf, uint32(req.BlockNo))
+	return buf
+}
@@ -0,0 +1,137 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"testing"
+)
+
+type sealingTestDevice struct {
+	id   DeviceID
+	priv crypto.PrivateKey
+	cert *x509.Certificate
+}
+
+func newSealingTestDevice(t *testing.T, priv crypto.Signer) sealingTestDevice {
+	t.Helper()
+	// Only the raw bytes, for the device ID, and the public key are
+	// looked at.
+	raw := make([]byte, 64)
+	rand.Read(raw)
+	return sealingTestDevice{
+		id:   NewDeviceID(raw),
+		priv: priv,
+		cert: &x509.Certificate{Raw: raw, PublicKey: priv.Public()},
+	}
+}
+
+func TestBlockSealingKey(t *testing.T) {
+	newEd25519 := func() crypto.Signer {
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return priv
+	}
+	newECDSA := func(curve elliptic.Curve) func() crypto.Signer {
+		return func() crypto.Signer {
+			priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return priv
+		}
+	}
+
+	cases := []struct {
+		name       string
+		a, b       func() crypto.Signer
+		compatible bool
+	}{
+		{"ed25519", newEd25519, newEd25519, true},
+		{"p256", newECDSA(elliptic.P256()), newECDSA(elliptic.P256()), true},
+		{"p384", newECDSA(elliptic.P384()), newECDSA(elliptic.P384()), true},
+		{"mixed curves", newECDSA(elliptic.P256()), newECDSA(elliptic.P384()), false},
+		{"mixed kinds", newEd25519, newECDSA(elliptic.P256()), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newSealingTestDevice(t, tc.a())
+			b := newSealingTestDevice(t, tc.b())
+			keyA, errA := BlockSealingKey(a.priv, a.id, b.cert)
+			keyB, errB := BlockSealingKey(b.priv, b.id, a.cert)
+			if !tc.compatible {
+				if errA == nil || errB == nil {
+					t.Fatal("expected both sides to fail")
+				}
+				return
+			}
+			if errA != nil || errB != nil {
+				t.Fatal(errA, errB)
+			}
+			if *keyA != *keyB {
+				t.Fatal("both sides should derive the same key")
+			}
+
+			// Another pair of devices gets another key.
+			c := newSealingTestDevice(t, tc.b())
+			keyC, err := BlockSealingKey(a.priv, a.id, c.cert)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *keyC == *keyA {
+				t.Error("different pairs should derive different keys")
+			}
+		})
+	}
+}
+
+func TestSealBlock(t *testing.T) {
+	var key [keySize]byte
+	rand.Read(key[:])
+	req := &Request{Folder: "default", Name: "a/b", Offset: 128 << 10, Size: 128 << 10, BlockNo: 1}
+	data := bytes.Repeat([]byte("block"), 1000)
+
+	sealed := sealBlock(data, &key, req)
+	if bytes.Contains(sealed, data[:100]) {
+		t.Fatal("sealed data should not contain the plaintext")
+	}
+	opened, err := openBlock(sealed, &key, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, data) {
+		t.Error("opened data differs")
+	}
+
+	// A response to another request is rejected.
+	other := *req
+	other.Offset = 0
+	if _, err := openBlock(sealed, &key, &other); err == nil {
+		t.Error("expected failure opening the block for another request")
+	}
+	if _, err := openBlock(sealed[:10], &key, req); err == nil {
+		t.Error("expected failure opening a short block")
+	}
+}
+
+func TestNegotiateBlockSealing(t *testing.T) {
+	sealing := Hello{BlockSealing: true}
+	if !NegotiateBlockSealing(sealing, sealing) {
+		t.Error("both sides want sealing")
+	}
+	if NegotiateBlockSealing(sealing, Hello{}) || NegotiateBlockSealing(Hello{}, sealing) {
+		t.Error("sealing takes both sides")
+	}
+}
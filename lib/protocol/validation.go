@@ -0,0 +1,136 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// maxViolations is how many malformed files and requests a device may send
+// over a connection before it's disconnected. Up to that, the offending
+// files are dropped from the index and requests answered with an error.
+// Files failing the basic consistency checks still disconnect right away.
+const maxViolations = 10
+
+var (
+	errInvalidBlockSize      = errors.New("invalid block size")
+	errInvalidFileSize       = errors.New("invalid file size")
+	errTooManyBlocks         = errors.New("too many blocks for file size")
+	errBlocksDontCoverFile   = errors.New("blocks don't cover the file")
+	errSequenceNotIncreasing = errors.New("sequence not increasing")
+	errInvalidRequest        = errors.New("invalid request offset or size")
+	errTooManyViolations     = errors.New("too many malformed messages")
+)
+
+// checkFileBlocks verifies that the blocks of a file cover it exactly and
+// in order, within the limits given by its block size.
+func checkFileBlocks(f FileInfo) error {
+	if f.Type != FileInfoTypeFile || f.IsDeleted() || f.IsInvalid() {
+		return nil
+	}
+	if f.Size < 0 {
+		return errInvalidFileSize
+	}
+	if !validBlockSize(int(f.RawBlockSize)) {
+		return errInvalidBlockSize
+	}
+
+	blockSize := f.BlockSize()
+	if int64(len(f.Blocks)) > f.Size/int64(blockSize)+1 {
+		return errTooManyBlocks
+	}
+	var offset int64
+	for _, b := range f.Blocks {
+		if b.Offset != offset || b.Size < 0 || b.Size > blockSize || b.Size == 0 && f.Size != 0 {
+			return errBlocksDontCoverFile
+		}
+		offset += int64(b.Size)
+	}
+	if offset != f.Size {
+		return errBlocksDontCoverFile
+	}
+	return nil
+}
+
+// validBlockSize returns whether the block size is unset, one of the
+// BlockSizes, or one of them grown by the overhead of encryption.
+func validBlockSize(size int) bool {
+	if size == 0 {
+		return true
+	}
+	for _, bs := range BlockSizes {
+		if size == bs || size == bs+blockOverhead {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRequest verifies that a request stays within the limits of a
+// block, possibly an encrypted one.
+func checkRequest(req *Request) error {
+	if req.Offset < 0 || req.Size < 0 || req.Size > MaxBlockSize+blockOverhead || req.BlockNo < 0 {
+		return errInvalidRequest
+	}
+	return nil
+}
+
+// validIndexFiles returns the files passing checkFileBlocks, counting a
+// violation for each one that doesn't. When checking sequences, they must
+// increase through the files, starting after prevSequence, and files
+// going back are dropped as well.
+func (c *rawConnection) validIndexFiles(fs []FileInfo, checkSequence bool, prevSequence int64) ([]FileInfo, error) {
+	valid := fs[:0]
+	seq := prevSequence
+	for _, f := range fs {
+		err := checkFileBlocks(f)
+		if err == nil && checkSequence {
+			if f.Sequence <= seq {
+				err = errSequenceNotIncreasing
+			} else {
+				seq = f.Sequence
+			}
+		}
+		if err != nil {
+			if err := c.violation("index", fmt.Errorf("%q: %w", f.Name, err)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		valid = append(valid, f)
+	}
+	return valid, nil
+}
+
+// refuseRequest answers a malformed request with an error, without
+// bothering the model.
+func (c *rawConnection) refuseRequest(req *Request, err error) error {
+	if err := c.violation("request", err); err != nil {
+		return err
+	}
+	resp := &Response{
+		ID:   req.ID,
+		Code: errorToCode(ErrInvalid),
+	}
+	go c.send(context.Background(), resp.toWire(), nil)
+	return nil
+}
+
+// violation records a malformed message from the peer, returning an error
+// once there were too many of them to keep the connection. It's only
+// called from the dispatcher loop.
+func (c *rawConnection) violation(kind string, err error) error {
+	metricDeviceRecvViolations.WithLabelValues(c.idString, kind).Inc()
+	c.violations++
+	l.Debugf("Malformed %s from %s (%d so far): %v", kind, c.deviceID.Short(), c.violations, err)
+	if c.violations > maxViolations {
+		return fmt.Errorf("%w: %w", errTooManyViolations, err)
+	}
+	return nil
+}
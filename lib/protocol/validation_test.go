@@ -0,0 +1,162 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/syncthing/syncthing/internal/gen/bep"
+)
+
+// validFile returns a file of the given size, with blocks of MinBlockSize
+func validFile(name string, size int64, seq int64) FileInfo {
+	f := FileInfo{Name: name, Type: FileInfoTypeFile, Size: size, Sequence: seq, RawBlockSize: MinBlockSize}
+	for offset := int64(0); offset < size || offset == 0; offset += MinBlockSize {
+		f.Blocks = append(f.Blocks, BlockInfo{Offset: offset, Size: int(min(size-offset, MinBlockSize)), Hash: []byte{1}})
+	}
+	return f
+}
+
+func TestCheckFileBlocks(t *testing.T) {
+	cases := []struct {
+		name   string
+		modify func(*FileInfo)
+		err    error
+	}{
+		{"valid", func(*FileInfo) {}, nil},
+		{"negative size", func(f *FileInfo) { f.Size = -1 }, errInvalidFileSize},
+		{"odd block size", func(f *FileInfo) { f.RawBlockSize = MinBlockSize + 1 }, errInvalidBlockSize},
+		{"encrypted block size", func(f *FileInfo) { f.RawBlockSize = MinBlockSize + blockOverhead }, nil},
+		{"too many blocks", func(f *FileInfo) {
+			f.Blocks = append(f.Blocks, f.Blocks...)
+		}, errTooManyBlocks},
+		{"gap", func(f *FileInfo) { f.Blocks[1].Offset++ }, errBlocksDontCoverFile},
+		{"oversized block", func(f *FileInfo) { f.Blocks[0].Size = 2 * MinBlockSize }, errBlocksDontCoverFile},
+		{"short", func(f *FileInfo) { f.Size++ }, errBlocksDontCoverFile},
+		{"deleted", func(f *FileInfo) { f.Size = -1; f.Deleted = true }, nil},
+	}
+	for _, tc := range cases {
+		f := validFile("foo", 3*MinBlockSize-100, 1)
+		tc.modify(&f)
+		if err := checkFileBlocks(f); !errors.Is(err, tc.err) {
+			t.Errorf("%s: got %v, expected %v", tc.name, err, tc.err)
+		}
+	}
+
+	if err := checkFileBlocks(validFile("empty", 0, 1)); err != nil {
+		t.Error("empty file:", err)
+	}
+}
+
+func TestValidIndexFiles(t *testing.T) {
+	c := &rawConnection{idString: "test"}
+
+	bad := validFile("bad", MinBlockSize, 3)
+	bad.Size = 1
+	fs := []FileInfo{validFile("a", 1, 5), validFile("b", 1, 4), bad, validFile("c", 1, 6)}
+	valid, err := c.validIndexFiles(fs, true, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(valid) != 2 || valid[0].Name != "a" || valid[1].Name != "c" {
+		t.Errorf("unexpected valid files %v", valid)
+	}
+	if c.violations != 2 {
+		t.Errorf("expected two violations, got %d", c.violations)
+	}
+
+	// Without checking sequences only the bad file goes.
+	fs = []FileInfo{validFile("a", 1, 5), validFile("b", 1, 4), bad}
+	if valid, _ := c.validIndexFiles(fs, false, 0); len(valid) != 2 {
+		t.Errorf("unexpected valid files %v", valid)
+	}
+
+	for c.violations < maxViolations {
+		if _, err := c.validIndexFiles([]FileInfo{bad}, false, 0); err != nil {
+			t.Fatal("disconnect before reaching the limit:", err)
+		}
+	}
+	if _, err := c.validIndexFiles([]FileInfo{bad}, false, 0); !errors.Is(err, errTooManyViolations) {
+		t.Error("expected too many violations, got", err)
+	}
+}
+
+func TestCheckRequest(t *testing.T) {
+	for _, req := range []Request{
+		{Offset: -1, Size: 1},
+		{Size: -1},
+		{Size: MaxBlockSize + blockOverhead + 1},
+		{Size: 1, BlockNo: -1},
+	} {
+		if err := checkRequest(&req); err == nil {
+			t.Errorf("request %+v should be invalid", req)
+		}
+	}
+	if err := checkRequest(&Request{Offset: MaxBlockSize, Size: MaxBlockSize + blockOverhead, BlockNo: 1}); err != nil {
+		t.Error(err)
+	}
+}
+
+// wireMessage encodes the message as it's sent over the wire, uncompressed
+func wireMessage(typ bep.MessageType, msg proto.Message) []byte {
+	hdr, _ := proto.Marshal(&bep.Header{Type: typ})
+	bs, _ := proto.Marshal(msg)
+	buf := binary.BigEndian.AppendUint16(nil, uint16(len(hdr)))
+	buf = append(buf, hdr...)
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(bs)))
+	return append(buf, bs...)
+}
+
+func FuzzReadMessage(f *testing.F) {
+	idx := &Index{Folder: "default", Files: []FileInfo{validFile("foo", 3*MinBlockSize, 1)}}
+	f.Add(wireMessage(bep.MessageType_MESSAGE_TYPE_INDEX, idx.toWire()))
+	idxUp := &IndexUpdate{Folder: "default", Files: []FileInfo{validFile("bar", 0, 2)}, PrevSequence: 1}
+	f.Add(wireMessage(bep.MessageType_MESSAGE_TYPE_INDEX_UPDATE, idxUp.toWire()))
+	req := &Request{Folder: "default", Name: "foo", Size: MinBlockSize}
+	f.Add(wireMessage(bep.MessageType_MESSAGE_TYPE_REQUEST, req.toWire()))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		c := &rawConnection{idString: "fuzz", cr: &countingReader{Reader: bytes.NewReader(data), idString: "fuzz"}}
+		buf := make([]byte, 4)
+		for c.violations <= maxViolations {
+			msg, err := c.readMessage(buf)
+			if err != nil {
+				return
+			}
+			var files []FileInfo
+			switch msg := msg.(type) {
+			case *bep.Index:
+				files = indexFromWire(msg).Files
+			case *bep.IndexUpdate:
+				files = indexUpdateFromWire(msg).Files
+			case *bep.Request:
+				_ = checkFilename(msg.Name)
+				_ = checkRequest(requestFromWire(msg))
+				continue
+			default:
+				continue
+			}
+			if checkIndexConsistency(files) != nil {
+				continue
+			}
+			valid, err := c.validIndexFiles(files, true, 0)
+			if err != nil {
+				return
+			}
+			for _, f := range valid {
+				if err := checkFileBlocks(f); err != nil {
+					t.Fatalf("%q passed validation: %v", f.Name, err)
+				}
+			}
+		}
+	})
+}
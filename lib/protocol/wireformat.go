@@ -20,6 +20,7 @@ type wireFormatConnection struct {
 func (c wireFormatConnection) Index(ctx context.Context, idx *Index) error {
 	for i := range idx.Files {
 		idx.Files[i].Name = norm.NFC.String(filepath.ToSlash(idx.Files[i].Name))
+		idx.Files[i].HardLinkTarget = norm.NFC.String(filepath.ToSlash(idx.Files[i].HardLinkTarget))
 	}
 
 	return c.Connection.Index(ctx, idx)
@@ -28,6 +29,7 @@ func (c wireFormatConnection) Index(ctx context.Context, idx *Index) error {
 func (c wireFormatConnection) IndexUpdate(ctx context.Context, idxUp *IndexUpdate) error {
 	for i := range idxUp.Files {
 		idxUp.Files[i].Name = norm.NFC.String(filepath.ToSlash(idxUp.Files[i].Name))
+		idxUp.Files[i].HardLinkTarget = norm.NFC.String(filepath.ToSlash(idxUp.Files[i].HardLinkTarget))
 	}
 
 	return c.Connection.IndexUpdate(ctx, idxUp)
@@ -0,0 +1,143 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/syncthing/syncthing/internal/gen/bep"
+)
+
+// CompressionAlgorithm is the algorithm messages are compressed with, as
+// opposed to Compression, which is which messages are compressed. LZ4 is
+// understood by all devices; others are used on a connection only when both
+// sides announce them in their hello.
+type CompressionAlgorithm int32
+
+const (
+	CompressionAlgorithmLZ4  = CompressionAlgorithm(bep.MessageCompression_MESSAGE_COMPRESSION_LZ4)
+	CompressionAlgorithmZstd = CompressionAlgorithm(bep.MessageCompression_MESSAGE_COMPRESSION_ZSTD)
+)
+
+// DefaultZstdLevel is the zstd compression level used unless configured
+// otherwise, on the scale of the reference implementation (1-22).
+const DefaultZstdLevel = 3
+
+func (a CompressionAlgorithm) String() string {
+	switch a {
+	case CompressionAlgorithmLZ4:
+		return "lz4"
+	case CompressionAlgorithmZstd:
+		return "zstd"
+	default:
+		return fmt.Sprintf("unknown(%d)", int32(a))
+	}
+}
+
+// NegotiateCompression returns the algorithm to compress messages with on a
+// connection, given the hellos of both sides.
+func NegotiateCompression(local, remote Hello) CompressionAlgorithm {
+	if slices.Contains(local.Compressions, CompressionAlgorithmZstd) && slices.Contains(remote.Compressions, CompressionAlgorithmZstd) {
+		return CompressionAlgorithmZstd
+	}
+	return CompressionAlgorithmLZ4
+}
+
+// A ConnectionOption sets optional parameters of a new connection.
+type ConnectionOption func(*rawConnection)
+
+// WithCompressionAlgorithm makes the connection compress messages with the
+// given algorithm, at the given level where applicable. The algorithm must
+// have been negotiated with the other device.
+func WithCompressionAlgorithm(algo CompressionAlgorithm, level int) ConnectionOption {
+	return func(c *rawConnection) {
+		c.compressionAlgo = algo
+		if algo == CompressionAlgorithmZstd {
+			c.zstdEncoder = zstdEncoderForLevel(level)
+		}
+	}
+}
+
+var (
+	zstdEncoders    = make(map[zstd.EncoderLevel]*zstd.Encoder)
+	zstdEncodersMut sync.Mutex
+
+	zstdDecoderOnce sync.Once
+	zstdDecoder     *zstd.Decoder
+)
+
+// zstdEncoderForLevel returns an encoder, shared between connections, for
+// the given level.
+func zstdEncoderForLevel(level int) *zstd.Encoder {
+	if level <= 0 {
+		level = DefaultZstdLevel
+	}
+	encLevel := zstd.EncoderLevelFromZstd(level)
+
+	zstdEncodersMut.Lock()
+	defer zstdEncodersMut.Unlock()
+	if enc, ok := zstdEncoders[encLevel]; ok {
+		return enc
+	}
+	// Messages are compressed whole with EncodeAll, which is safe for
+	// concurrent use.
+	enc, _ := zstd.NewWriter(nil, zstd.WithEncoderLevel(encLevel), zstd.WithEncoderConcurrency(1))
+	zstdEncoders[encLevel] = enc
+	return enc
+}
+
+func sharedZstdDecoder() *zstd.Decoder {
+	zstdDecoderOnce.Do(func() {
+		zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0), zstd.WithDecoderMaxMemory(MaxMessageLen))
+	})
+	return zstdDecoder
+}
+
+// zstdCompress compresses src into buf, in the same format as
+// lz4Compress.
+func zstdCompress(enc *zstd.Encoder, src, buf []byte) (int, error) {
+	out := enc.EncodeAll(src, buf[4:4])
+	if len(out) > len(buf)-4 {
+		// Larger than the space we allow; the encoder grew the slice.
+		return -1, errNotCompressible
+	}
+	// Normally a no-op, the data already being in place
+	copy(buf[4:], out)
+
+	// The compressed block is prefixed by the size of the uncompressed data.
+	binary.BigEndian.PutUint32(buf, uint32(len(src)))
+
+	return len(out) + 4, nil
+}
+
+func zstdDecompress(src []byte) ([]byte, error) {
+	if len(src) < 4 {
+		return nil, fmt.Errorf("compressed message length %d too short", len(src))
+	}
+	size := binary.BigEndian.Uint32(src)
+	if size > MaxMessageLen {
+		return nil, fmt.Errorf("decompressed message length %d exceeds maximum %d", size, MaxMessageLen)
+	}
+	buf := BufferPool.Get(int(size))
+
+	out, err := sharedZstdDecoder().DecodeAll(src[4:], buf[:0])
+	if err != nil {
+		BufferPool.Put(buf)
+		return nil, err
+	}
+	if len(out) != int(size) {
+		BufferPool.Put(buf)
+		return nil, fmt.Errorf("decompressed message length %d, expected %d", len(out), size)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package protocol
+
+import (
+	"bytes"
+	"crypto/rand"
+	"slices"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/syncthing/syncthing/internal/gen/bep"
+)
+
+func TestWriteCompressedZstd(t *testing.T) {
+	for _, random := range []bool{false, true} {
+		buf := new(bytes.Buffer)
+		c := &rawConnection{
+			cr:          &countingReader{Reader: buf},
+			cw:          &countingWriter{Writer: buf},
+			compression: CompressionAlways,
+		}
+		WithCompressionAlgorithm(CompressionAlgorithmZstd, DefaultZstdLevel)(c)
+
+		msg := (&Response{Data: bytes.Repeat([]byte("text heavy folder "), 1024)}).toWire()
+		if random {
+			// This should make the message incompressible.
+			rand.Read(msg.Data)
+		}
+
+		if err := c.writeMessage(msg); err != nil {
+			t.Fatal(err)
+		}
+		got, err := c.readMessage(make([]byte, 4))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got.(*bep.Response).Data, msg.Data) {
+			t.Error("received the wrong message")
+		}
+
+		hdr := &bep.Header{Type: typeOf(msg)}
+		size := int64(2 + proto.Size(hdr) + 4 + proto.Size(msg))
+		if c.cr.Tot() > size {
+			t.Errorf("compression enlarged message from %d to %d", size, c.cr.Tot())
+		} else if !random && c.cr.Tot() > size/10 {
+			t.Errorf("compressible message only compressed from %d to %d", size, c.cr.Tot())
+		}
+	}
+}
+
+func TestNegotiateCompression(t *testing.T) {
+	zstd := Hello{Compressions: []CompressionAlgorithm{CompressionAlgorithmZstd}}
+	old := Hello{}
+
+	if algo := NegotiateCompression(zstd, zstd); algo != CompressionAlgorithmZstd {
+		t.Errorf("both offering zstd, got %v", algo)
+	}
+	if algo := NegotiateCompression(zstd, old); algo != CompressionAlgorithmLZ4 {
+		t.Errorf("remote not offering zstd, got %v", algo)
+	}
+	if algo := NegotiateCompression(old, zstd); algo != CompressionAlgorithmLZ4 {
+		t.Errorf("local not offering zstd, got %v", algo)
+	}
+
+	// The offer survives the trip over the wire
+	bs, err := proto.Marshal(zstd.toWire())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var w bep.Hello
+	if err := proto.Unmarshal(bs, &w); err != nil {
+		t.Fatal(err)
+	}
+	if res := helloFromWire(&w); !slices.Equal(res.Compressions, zstd.Compressions) {
+		t.Errorf("got compressions %v after unmarshalling", res.Compressions)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"net/url"
 	"time"
 
@@ -20,17 +21,24 @@ type RelayClient interface {
 	Error() error
 	String() string
 	Invitations() <-chan protocol.SessionInvitation
+	// JoinSession joins the session of an invitation from Invitations.
+	JoinSession(ctx context.Context, inv protocol.SessionInvitation) (net.Conn, error)
 	URI() *url.URL
 }
 
-func NewClient(uri *url.URL, certs []tls.Certificate, timeout time.Duration) (RelayClient, error) {
+func NewClient(uri *url.URL, certs []tls.Certificate, timeout time.Duration, opts ...Option) (RelayClient, error) {
 	invitations := make(chan protocol.SessionInvitation)
 
+	var options clientOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	switch uri.Scheme {
 	case "relay":
 		return newStaticClient(uri, certs, invitations, timeout), nil
 	case "dynamic+http", "dynamic+https":
-		return newDynamicClient(uri, certs, invitations, timeout), nil
+		return newDynamicClient(uri, certs, invitations, timeout, options), nil
 	default:
 		return nil, fmt.Errorf("unsupported scheme: %s", uri.Scheme)
 	}
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"slices"
@@ -25,16 +26,18 @@ type dynamicClient struct {
 	pooladdr *url.URL
 	certs    []tls.Certificate
 	timeout  time.Duration
+	options  clientOptions
 
 	mut    sync.RWMutex // Protects client.
 	client *staticClient
 }
 
-func newDynamicClient(uri *url.URL, certs []tls.Certificate, invitations chan protocol.SessionInvitation, timeout time.Duration) *dynamicClient {
+func newDynamicClient(uri *url.URL, certs []tls.Certificate, invitations chan protocol.SessionInvitation, timeout time.Duration, options clientOptions) *dynamicClient {
 	c := &dynamicClient{
 		pooladdr: uri,
 		certs:    certs,
 		timeout:  timeout,
+		options:  options,
 	}
 	c.commonClient = newCommonClient(invitations, c.serve, fmt.Sprintf("dynamicClient@%p", c))
 	return c
@@ -48,37 +51,18 @@ func (c *dynamicClient) serve(ctx context.Context) error {
 
 	l.Debugln(c, "looking up dynamic relays")
 
-	req, err := http.NewRequestWithContext(ctx, "GET", uri.String(), nil)
-	if err != nil {
-		l.Debugln(c, "failed to lookup dynamic relays", err)
-		return err
-	}
-	data, err := http.DefaultClient.Do(req)
-	if err != nil {
-		l.Debugln(c, "failed to lookup dynamic relays", err)
-		return err
-	}
-
-	var ann dynamicAnnouncement
-	err = json.NewDecoder(data.Body).Decode(&ann)
-	data.Body.Close()
+	addrs, err := cachedRelayPool(ctx, &uri)
 	if err != nil {
 		l.Debugln(c, "failed to lookup dynamic relays", err)
 		return err
 	}
 
-	var addrs []string
-	for _, relayAnn := range ann.Relays {
-		ruri, err := url.Parse(relayAnn.URL)
-		if err != nil {
-			l.Debugln(c, "failed to parse dynamic relay address", relayAnn.URL, err)
-			continue
-		}
-		l.Debugln(c, "found", ruri)
-		addrs = append(addrs, ruri.String())
+	ordered := relayAddressesOrder(ctx, addrs)
+	if c.options.speedTest {
+		ordered = rankBySpeed(ctx, ordered, c.certs, c.timeout)
 	}
 
-	for _, addr := range relayAddressesOrder(ctx, addrs) {
+	for _, addr := range ordered {
 		select {
 		case <-ctx.Done():
 			l.Debugln(c, "stopping")
@@ -103,6 +87,10 @@ func (c *dynamicClient) serve(ctx context.Context) error {
 		}
 	}
 	l.Debugln(c, "could not find a connectable relay")
+	// Look the list up again next time, in case it's stale
+	relayPools.mut.Lock()
+	delete(relayPools.lists, uri.String())
+	relayPools.mut.Unlock()
 	return errors.New("could not find a connectable relay")
 }
 
@@ -115,6 +103,16 @@ func (c *dynamicClient) Error() error {
 	return c.client.Error()
 }
 
+func (c *dynamicClient) JoinSession(ctx context.Context, inv protocol.SessionInvitation) (net.Conn, error) {
+	c.mut.RLock()
+	client := c.client
+	c.mut.RUnlock()
+	if client == nil {
+		return JoinSession(ctx, inv)
+	}
+	return client.JoinSession(ctx, inv)
+}
+
 func (c *dynamicClient) String() string {
 	return fmt.Sprintf("DynamicClient:%p:%s@%s", c, c.URI(), c.pooladdr)
 }
@@ -128,6 +126,70 @@ func (c *dynamicClient) URI() *url.URL {
 	return c.client.URI()
 }
 
+// How long a looked up relay pool list is used before looking it up again
+const relayPoolCacheTime = time.Hour
+
+var relayPools = struct {
+	mut   sync.Mutex
+	lists map[string]relayPoolList // pool URL -> relays
+}{lists: make(map[string]relayPoolList)}
+
+type relayPoolList struct {
+	relays  []string
+	fetched time.Time
+}
+
+// cachedRelayPool returns the relays of the pool, as looked up recently or
+// looked up now.
+func cachedRelayPool(ctx context.Context, pool *url.URL) ([]string, error) {
+	relayPools.mut.Lock()
+	list, ok := relayPools.lists[pool.String()]
+	relayPools.mut.Unlock()
+	if ok && time.Since(list.fetched) < relayPoolCacheTime {
+		return list.relays, nil
+	}
+	return LookupRelayPool(ctx, pool)
+}
+
+// LookupRelayPool fetches the list of relays from the pool endpoint, which
+// is an http(s) URL without the "dynamic+" prefix. The list is kept for
+// dynamic clients of the same pool to pick a relay from.
+func LookupRelayPool(ctx context.Context, pool *url.URL) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pool.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("relay pool: %s", resp.Status)
+	}
+
+	var ann dynamicAnnouncement
+	if err := json.NewDecoder(resp.Body).Decode(&ann); err != nil {
+		return nil, err
+	}
+
+	var addrs []string
+	for _, relayAnn := range ann.Relays {
+		ruri, err := url.Parse(relayAnn.URL)
+		if err != nil {
+			l.Debugln("failed to parse dynamic relay address", relayAnn.URL, err)
+			continue
+		}
+		l.Debugln("found", ruri)
+		addrs = append(addrs, ruri.String())
+	}
+
+	relayPools.mut.Lock()
+	relayPools.lists[pool.String()] = relayPoolList{relays: addrs, fetched: time.Now()}
+	relayPools.mut.Unlock()
+	return addrs, nil
+}
+
 // This is the announcement received from the relay server;
 // {"relays": [{"url": "relay://10.20.30.40:5060"}, ...]}
 type dynamicAnnouncement struct {
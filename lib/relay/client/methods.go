@@ -27,51 +27,139 @@ func (e *incorrectResponseCodeErr) Error() string {
 }
 
 func GetInvitationFromRelay(ctx context.Context, uri *url.URL, id syncthingprotocol.DeviceID, certs []tls.Certificate, timeout time.Duration) (protocol.SessionInvitation, error) {
+	conn, err := requestInvitation(ctx, uri, id, certs, timeout)
+	if conn == nil {
+		return protocol.SessionInvitation{}, err
+	}
+	conn.Close()
+	return conn.invitation, err
+}
+
+// DialSession gets an invitation for a session with the device from the
+// relay and joins it. Relays supporting protocol version 2 carry the
+// session over the connection used to ask for it, instead of a new one.
+func DialSession(ctx context.Context, uri *url.URL, id syncthingprotocol.DeviceID, certs []tls.Certificate, timeout time.Duration) (net.Conn, protocol.SessionInvitation, error) {
+	conn, err := requestInvitation(ctx, uri, id, certs, timeout)
+	if err != nil {
+		if conn != nil {
+			conn.Close()
+		}
+		return nil, protocol.SessionInvitation{}, err
+	}
+	inv := conn.invitation
+
+	if conn.ConnectionState().NegotiatedProtocol != protocol.ProtocolNameV2 {
+		conn.Close()
+		sconn, err := JoinSession(ctx, inv)
+		return sconn, inv, err
+	}
+
+	conn.SetDeadline(time.Time{})
+	mux := protocol.NewMux(conn)
+	go answerPings(mux)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	stream, err := mux.JoinSession(ctx, inv.Key)
+	if err != nil {
+		mux.Close()
+		return nil, protocol.SessionInvitation{}, err
+	}
+	return &dialedStream{Stream: stream, mux: mux}, inv, nil
+}
+
+// IsMultiplexed returns true if the session connection is carried over a
+// protocol version 2 relay connection, as opposed to being a TCP
+// connection of its own.
+func IsMultiplexed(conn net.Conn) bool {
+	switch conn.(type) {
+	case *protocol.Stream, *dialedStream:
+		return true
+	default:
+		return false
+	}
+}
+
+// dialedStream is the only stream on a connection, which is closed with
+// the stream.
+type dialedStream struct {
+	*protocol.Stream
+	mux *protocol.Mux
+}
+
+func (s *dialedStream) Close() error {
+	err := s.Stream.Close()
+	s.mux.Close()
+	return err
+}
+
+// answerPings keeps the relay from timing out a connection not used for
+// anything but a session.
+func answerPings(mux *protocol.Mux) {
+	for {
+		select {
+		case msg := <-mux.Messages():
+			if _, ok := msg.(protocol.Ping); ok {
+				if err := protocol.WriteMessage(mux, protocol.Pong{}); err != nil {
+					return
+				}
+			}
+		case <-mux.Done():
+			return
+		}
+	}
+}
+
+type invitationConn struct {
+	*tls.Conn
+	invitation protocol.SessionInvitation
+}
+
+func requestInvitation(ctx context.Context, uri *url.URL, id syncthingprotocol.DeviceID, certs []tls.Certificate, timeout time.Duration) (*invitationConn, error) {
 	if uri.Scheme != "relay" {
-		return protocol.SessionInvitation{}, fmt.Errorf("unsupported relay scheme: %v", uri.Scheme)
+		return nil, fmt.Errorf("unsupported relay scheme: %v", uri.Scheme)
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	rconn, err := dialer.DialContext(ctx, "tcp", uri.Host)
 	if err != nil {
-		return protocol.SessionInvitation{}, err
+		return nil, err
 	}
 
-	conn := tls.Client(rconn, configForCerts(certs))
+	conn := &invitationConn{Conn: tls.Client(rconn, configForCerts(certs))}
 	conn.SetDeadline(time.Now().Add(timeout))
 
-	if err := performHandshakeAndValidation(conn, uri); err != nil {
-		return protocol.SessionInvitation{}, err
+	if err := performHandshakeAndValidation(conn.Conn, uri); err != nil {
+		return conn, err
 	}
 
-	defer conn.Close()
-
 	request := protocol.ConnectRequest{
 		ID: id[:],
 	}
 
 	if err := protocol.WriteMessage(conn, request); err != nil {
-		return protocol.SessionInvitation{}, err
+		return conn, err
 	}
 
 	message, err := protocol.ReadMessage(conn)
 	if err != nil {
-		return protocol.SessionInvitation{}, err
+		return conn, err
 	}
 
 	switch msg := message.(type) {
 	case protocol.Response:
-		return protocol.SessionInvitation{}, &incorrectResponseCodeErr{msg.Code, msg.Message}
+		return conn, &incorrectResponseCodeErr{msg.Code, msg.Message}
 	case protocol.SessionInvitation:
 		l.Debugln("Received invitation", msg, "via", conn.LocalAddr())
 		ip := net.IP(msg.Address)
 		if len(ip) == 0 || ip.IsUnspecified() {
 			msg.Address, _ = osutil.IPFromAddr(conn.RemoteAddr())
 		}
-		return msg, nil
+		conn.invitation = msg
+		return conn, nil
 	default:
-		return protocol.SessionInvitation{}, fmt.Errorf("protocol error: unexpected message %v", msg)
+		return conn, fmt.Errorf("protocol error: unexpected message %v", msg)
 	}
 }
 
@@ -149,7 +237,7 @@ func TestRelay(ctx context.Context, uri *url.URL, certs []tls.Certificate, sleep
 func configForCerts(certs []tls.Certificate) *tls.Config {
 	return &tls.Config{
 		Certificates:           certs,
-		NextProtos:             []string{protocol.ProtocolName},
+		NextProtos:             []string{protocol.ProtocolNameV2, protocol.ProtocolName},
 		ClientAuth:             tls.RequestClientCert,
 		SessionTicketsDisabled: true,
 		InsecureSkipVerify:     true,
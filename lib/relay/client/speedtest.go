@@ -0,0 +1,234 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package client
+
+import (
+	"cmp"
+	"context"
+	"crypto/tls"
+	"errors"
+	"io"
+	"math"
+	"net/url"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	syncthingprotocol "github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/relay/protocol"
+)
+
+const (
+	// A speed test transfers this much through a relay, or as much as it
+	// can in the given time.
+	speedTestBytes    = 1 << 20
+	speedTestDuration = 3 * time.Second
+	// Speed tests are run against this many of the lowest latency relays
+	// of a pool.
+	speedTestCandidates = 3
+	// How long a measurement is used before measuring again, and after
+	// how long it counts half in the ranking.
+	speedTestRetest   = time.Hour
+	speedTestHalfLife = 6 * time.Hour
+)
+
+// An Option changes the behaviour of a relay client.
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	speedTest bool
+}
+
+// OptionSpeedTest makes dynamic clients rank the lowest latency relays of
+// the pool by their measured bandwidth, rather than by latency alone.
+func OptionSpeedTest() Option {
+	return func(o *clientOptions) {
+		o.speedTest = true
+	}
+}
+
+var relaySpeeds = struct {
+	mut     sync.Mutex
+	results map[string]relaySpeed // relay URL -> result
+}{results: make(map[string]relaySpeed)}
+
+type relaySpeed struct {
+	bps      float64
+	measured time.Time
+}
+
+// weight returns the weight of the result at the given time, halving
+// every speedTestHalfLife.
+func (s relaySpeed) weight(now time.Time) float64 {
+	return math.Exp2(-float64(now.Sub(s.measured)) / float64(speedTestHalfLife))
+}
+
+// recordRelaySpeed merges a measurement into the result for the relay, with
+// the previous result counting for what's left of it after decay.
+func recordRelaySpeed(relay string, bps float64, now time.Time) {
+	relaySpeeds.mut.Lock()
+	defer relaySpeeds.mut.Unlock()
+	if prev, ok := relaySpeeds.results[relay]; ok {
+		w := prev.weight(now)
+		bps = w*prev.bps + (1-w)*bps
+	}
+	relaySpeeds.results[relay] = relaySpeed{bps: bps, measured: now}
+}
+
+// relaySpeedScore returns the decayed bandwidth of the relay, or false when
+// it was never measured.
+func relaySpeedScore(relay string, now time.Time) (float64, bool) {
+	relaySpeeds.mut.Lock()
+	defer relaySpeeds.mut.Unlock()
+	s, ok := relaySpeeds.results[relay]
+	if !ok {
+		return 0, false
+	}
+	return s.bps * s.weight(now), true
+}
+
+func relaySpeedFresh(relay string, now time.Time) bool {
+	relaySpeeds.mut.Lock()
+	defer relaySpeeds.mut.Unlock()
+	s, ok := relaySpeeds.results[relay]
+	return ok && now.Sub(s.measured) < speedTestRetest
+}
+
+// rankBySpeed measures the bandwidth of the first relays of the latency
+// ordered list, unless measured recently, and orders those by it. The rest
+// of the list stays in latency order.
+func rankBySpeed(ctx context.Context, ordered []string, certs []tls.Certificate, timeout time.Duration) []string {
+	n := min(speedTestCandidates, len(ordered))
+	candidates := ordered[:n]
+	for _, relay := range candidates {
+		if relaySpeedFresh(relay, time.Now()) {
+			continue
+		}
+		uri, err := url.Parse(relay)
+		if err != nil {
+			continue
+		}
+		bps, err := MeasureBandwidth(ctx, uri, certs, timeout)
+		if err != nil {
+			l.Debugln("speed test of", relay, "failed:", err)
+			if ctx.Err() != nil {
+				return ordered
+			}
+			continue
+		}
+		l.Debugf("speed test of %s: %.0f KiB/s", relay, bps/1024)
+		recordRelaySpeed(relay, bps, time.Now())
+	}
+
+	now := time.Now()
+	ranked := slices.Clone(ordered)
+	slices.SortStableFunc(ranked[:n], func(a, b string) int {
+		sa, _ := relaySpeedScore(a, now)
+		sb, _ := relaySpeedScore(b, now)
+		return cmp.Compare(sb, sa)
+	})
+	return ranked
+}
+
+// MeasureBandwidth transfers a bounded amount of data through a session on
+// the relay and returns the rate in bytes per second. The session is one
+// with ourselves, joined as a relay client and connected to in the same
+// way as other devices would.
+func MeasureBandwidth(ctx context.Context, uri *url.URL, certs []tls.Certificate, timeout time.Duration) (float64, error) {
+	id := syncthingprotocol.NewDeviceID(certs[0].Certificate[0])
+
+	ctx, cancel := context.WithTimeout(ctx, 2*timeout+speedTestDuration)
+	defer cancel()
+
+	server := newStaticClient(uri, certs, make(chan protocol.SessionInvitation, 1), timeout)
+	go server.Serve(ctx)
+
+	// The invitation can only be had once the server side has joined.
+	var invErr *incorrectResponseCodeErr
+	for {
+		conn, _, err := DialSession(ctx, uri, id, certs, timeout)
+		if err == nil {
+			defer conn.Close()
+			select {
+			case inv := <-server.Invitations():
+				sconn, err := server.JoinSession(ctx, inv)
+				if err != nil {
+					return 0, err
+				}
+				defer sconn.Close()
+				return transferRate(ctx, conn, sconn)
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			}
+		}
+		if !errors.As(err, &invErr) {
+			return 0, err
+		}
+		if err := server.Error(); err != nil {
+			return 0, err
+		}
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// transferRate writes to w and reads from r until speedTestBytes have
+// arrived or speedTestDuration has passed.
+func transferRate(ctx context.Context, w io.Writer, r io.Reader) (float64, error) {
+	testCtx, cancel := context.WithTimeout(ctx, speedTestDuration)
+	defer cancel()
+
+	go func() {
+		buf := make([]byte, 32<<10)
+		for sent := 0; sent < speedTestBytes && testCtx.Err() == nil; {
+			n, err := w.Write(buf)
+			if err != nil {
+				return
+			}
+			sent += n
+		}
+	}()
+
+	// The connections are closed by the caller, ending the reader should
+	// we run out of time.
+	start := time.Now()
+	var received atomic.Int64
+	done := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 32<<10)
+		for received.Load() < speedTestBytes {
+			n, err := r.Read(buf)
+			received.Add(int64(n))
+			if err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return 0, err
+		}
+	case <-testCtx.Done():
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+		// Out of time; what has arrived so far counts.
+	}
+	n := received.Load()
+	if n == 0 {
+		return 0, errors.New("no data arrived through relay")
+	}
+	return float64(n) / time.Since(start).Seconds(), nil
+}
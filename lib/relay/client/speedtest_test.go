@@ -0,0 +1,70 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package client
+
+import (
+	"context"
+	"math"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRelaySpeedDecay(t *testing.T) {
+	const relay = "relay://192.0.2.1:22067"
+	t.Cleanup(func() {
+		relaySpeeds.mut.Lock()
+		delete(relaySpeeds.results, relay)
+		relaySpeeds.mut.Unlock()
+	})
+
+	now := time.Now()
+	recordRelaySpeed(relay, 1000, now)
+	if score, ok := relaySpeedScore(relay, now.Add(speedTestHalfLife)); !ok || math.Abs(score-500) > 1 {
+		t.Errorf("expected half the speed after the half life, got %v", score)
+	}
+
+	// A new measurement after one half life counts half.
+	later := now.Add(speedTestHalfLife)
+	recordRelaySpeed(relay, 3000, later)
+	if score, _ := relaySpeedScore(relay, later); math.Abs(score-2000) > 1 {
+		t.Errorf("expected merged speed 2000, got %v", score)
+	}
+	if !relaySpeedFresh(relay, later) || relaySpeedFresh(relay, later.Add(speedTestRetest)) {
+		t.Error("unexpected freshness")
+	}
+}
+
+func TestRankBySpeed(t *testing.T) {
+	relays := []string{
+		"relay://192.0.2.1:22067",
+		"relay://192.0.2.2:22067",
+		"relay://192.0.2.3:22067",
+		"relay://192.0.2.4:22067",
+	}
+	t.Cleanup(func() {
+		relaySpeeds.mut.Lock()
+		for _, relay := range relays {
+			delete(relaySpeeds.results, relay)
+		}
+		relaySpeeds.mut.Unlock()
+	})
+
+	// Fresh results need no measuring; the fourth relay is not a candidate
+	// no matter how fast.
+	now := time.Now()
+	recordRelaySpeed(relays[0], 100, now)
+	recordRelaySpeed(relays[1], 300, now)
+	recordRelaySpeed(relays[2], 200, now)
+	recordRelaySpeed(relays[3], 1000, now)
+
+	ranked := rankBySpeed(context.Background(), relays, nil, time.Second)
+	expected := []string{relays[1], relays[2], relays[0], relays[3]}
+	if !slices.Equal(ranked, expected) {
+		t.Errorf("got %v, expected %v", ranked, expected)
+	}
+}
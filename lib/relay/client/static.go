@@ -7,9 +7,11 @@ import (
 	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"net"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/syncthing/syncthing/internal/slogutil"
@@ -31,6 +33,9 @@ type staticClient struct {
 
 	conn  *tls.Conn
 	token string
+
+	mut sync.Mutex // Protects mux.
+	mux *protocol.Mux
 }
 
 func newStaticClient(uri *url.URL, certs []tls.Certificate, invitations chan protocol.SessionInvitation, timeout time.Duration) *staticClient {
@@ -69,10 +74,32 @@ func (c *staticClient) serve(ctx context.Context) error {
 
 	slog.InfoContext(ctx, "Joined relay", slogutil.URI(fmt.Sprintf("%s://%s", c.uri.Scheme, c.uri.Host)))
 
-	messages := make(chan interface{})
+	var messages <-chan interface{}
+	var w io.Writer = c.conn
 	errorsc := make(chan error, 1)
 
-	go messageReader(ctx, c.conn, messages, errorsc)
+	if c.conn.ConnectionState().NegotiatedProtocol == protocol.ProtocolNameV2 {
+		// Sessions are joined as streams over this connection.
+		mux := protocol.NewMux(c.conn)
+		c.mut.Lock()
+		c.mux = mux
+		c.mut.Unlock()
+		defer func() {
+			c.mut.Lock()
+			c.mux = nil
+			c.mut.Unlock()
+		}()
+		go func() {
+			<-mux.Done()
+			errorsc <- mux.Err()
+		}()
+		messages = mux.Messages()
+		w = mux
+	} else {
+		msgs := make(chan interface{})
+		go messageReader(ctx, c.conn, msgs, errorsc)
+		messages = msgs
+	}
 
 	timeout := time.NewTimer(c.messageTimeout)
 
@@ -84,7 +111,7 @@ func (c *staticClient) serve(ctx context.Context) error {
 
 			switch msg := message.(type) {
 			case protocol.Ping:
-				if err := protocol.WriteMessage(c.conn, protocol.Pong{}); err != nil {
+				if err := protocol.WriteMessage(w, protocol.Pong{}); err != nil {
 					l.Debugln("Relay write:", err)
 					return err
 				}
@@ -134,6 +161,25 @@ func (c *staticClient) URI() *url.URL {
 	return c.uri
 }
 
+// JoinSession joins the session of an invitation received from the relay,
+// over the connection to the relay if it supports that.
+func (c *staticClient) JoinSession(ctx context.Context, inv protocol.SessionInvitation) (net.Conn, error) {
+	c.mut.Lock()
+	mux := c.mux
+	c.mut.Unlock()
+	if mux == nil {
+		return JoinSession(ctx, inv)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	stream, err := mux.JoinSession(ctx, inv.Key)
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
 func (c *staticClient) connect(ctx context.Context) error {
 	if c.uri.Scheme != "relay" {
 		return fmt.Errorf("unsupported relay scheme: %v", c.uri.Scheme)
@@ -209,7 +255,7 @@ func performHandshakeAndValidation(conn *tls.Conn, uri *url.URL) error {
 	}
 
 	cs := conn.ConnectionState()
-	if cs.NegotiatedProtocol != protocol.ProtocolName {
+	if cs.NegotiatedProtocol != protocol.ProtocolName && cs.NegotiatedProtocol != protocol.ProtocolNameV2 {
 		return errors.New("protocol negotiation error")
 	}
 
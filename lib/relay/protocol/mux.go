@@ -0,0 +1,444 @@
+// Copyright (C) 2025 Audrius Butkevicius and Contributors (see the CONTRIBUTORS file).
+
+package protocol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// Each side of a stream may have this many bytes of data in flight,
+	// before the receiver has read them.
+	streamWindow = 256 << 10
+	// Writes to the underlying connection failing to complete in this time
+	// fail the connection, as a stalled connection stalls all streams.
+	muxWriteTimeout = 2 * time.Minute
+)
+
+var errMuxClosed = errors.New("multiplexed connection closed")
+
+// Mux multiplexes sessions as streams over a connection using
+// ProtocolNameV2. Messages not concerning streams are passed on through
+// Messages(). Mux implements io.Writer so that WriteMessage can be used on
+// it concurrently with the streams.
+type Mux struct {
+	conn net.Conn
+
+	wmut sync.Mutex // serializes writes to conn
+
+	mut     sync.Mutex
+	streams map[uint32]*Stream
+	joins   map[uint32]chan StreamResponse
+	nextID  uint32
+	err     error
+
+	messages chan interface{}
+	closed   chan struct{}
+}
+
+func NewMux(conn net.Conn) *Mux {
+	m := &Mux{
+		conn:     conn,
+		streams:  make(map[uint32]*Stream),
+		joins:    make(map[uint32]chan StreamResponse),
+		messages: make(chan interface{}),
+		closed:   make(chan struct{}),
+	}
+	go m.reader()
+	return m
+}
+
+// Messages returns the channel of received messages other than the stream
+// messages handled by the Mux. It must be drained for the streams to make
+// progress.
+func (m *Mux) Messages() <-chan interface{} {
+	return m.messages
+}
+
+// Done is closed when the underlying connection fails or is closed.
+func (m *Mux) Done() <-chan struct{} {
+	return m.closed
+}
+
+// Err returns the reason the Mux is done.
+func (m *Mux) Err() error {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	return m.err
+}
+
+func (m *Mux) Write(bs []byte) (int, error) {
+	m.wmut.Lock()
+	defer m.wmut.Unlock()
+	if err := m.conn.SetWriteDeadline(time.Now().Add(muxWriteTimeout)); err != nil {
+		return 0, err
+	}
+	n, err := m.conn.Write(bs)
+	if err != nil {
+		m.fail(err)
+	}
+	return n, err
+}
+
+func (m *Mux) Close() error {
+	m.fail(errMuxClosed)
+	return nil
+}
+
+// NumStreams returns the number of open streams.
+func (m *Mux) NumStreams() int {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	return len(m.streams)
+}
+
+// AddStream registers the stream the other side asked to join a session
+// as.
+func (m *Mux) AddStream(id uint32) (*Stream, error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	if m.err != nil {
+		return nil, m.err
+	}
+	if _, ok := m.streams[id]; ok {
+		return nil, fmt.Errorf("stream %d already exists", id)
+	}
+	s := newStream(m, id)
+	m.streams[id] = s
+	return s, nil
+}
+
+// JoinSession joins the session with the given key as a new stream.
+func (m *Mux) JoinSession(ctx context.Context, key []byte) (*Stream, error) {
+	m.mut.Lock()
+	if m.err != nil {
+		m.mut.Unlock()
+		return nil, m.err
+	}
+	m.nextID++
+	s := newStream(m, m.nextID)
+	m.streams[s.id] = s
+	resp := make(chan StreamResponse, 1)
+	m.joins[s.id] = resp
+	m.mut.Unlock()
+
+	defer func() {
+		m.mut.Lock()
+		delete(m.joins, s.id)
+		m.mut.Unlock()
+	}()
+
+	if err := WriteMessage(m, JoinStreamRequest{Stream: s.id, Key: key}); err != nil {
+		s.closeLocal(false)
+		return nil, err
+	}
+
+	select {
+	case msg := <-resp:
+		if msg.Code != 0 {
+			s.closeLocal(false)
+			return nil, fmt.Errorf("incorrect response code %d: %s", msg.Code, msg.Message)
+		}
+		return s, nil
+	case <-ctx.Done():
+		s.Close()
+		return nil, ctx.Err()
+	case <-m.closed:
+		return nil, m.Err()
+	}
+}
+
+func (m *Mux) reader() {
+	for {
+		msg, err := ReadMessage(m.conn)
+		if err != nil {
+			m.fail(err)
+			return
+		}
+
+		switch msg := msg.(type) {
+		case StreamData:
+			if s := m.stream(msg.Stream); s != nil {
+				if err := s.deliver(msg.Data); err != nil {
+					m.fail(err)
+					return
+				}
+			}
+
+		case StreamWindow:
+			if s := m.stream(msg.Stream); s != nil {
+				s.addCredit(int(msg.Increment))
+			}
+
+		case StreamClose:
+			if s := m.stream(msg.Stream); s != nil {
+				s.closeRemote()
+			}
+
+		case StreamResponse:
+			m.mut.Lock()
+			resp, ok := m.joins[msg.Stream]
+			m.mut.Unlock()
+			if ok {
+				select {
+				case resp <- msg:
+				default:
+				}
+			}
+
+		default:
+			select {
+			case m.messages <- msg:
+			case <-m.closed:
+				return
+			}
+		}
+	}
+}
+
+func (m *Mux) stream(id uint32) *Stream {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	return m.streams[id]
+}
+
+func (m *Mux) removeStream(id uint32) {
+	m.mut.Lock()
+	delete(m.streams, id)
+	m.mut.Unlock()
+}
+
+func (m *Mux) fail(err error) {
+	m.mut.Lock()
+	if m.err != nil {
+		m.mut.Unlock()
+		return
+	}
+	m.err = err
+	streams := m.streams
+	m.streams = make(map[uint32]*Stream)
+	close(m.closed)
+	m.mut.Unlock()
+
+	m.conn.Close()
+	for _, s := range streams {
+		s.closeRemote()
+	}
+}
+
+// Stream is a session multiplexed over a Mux.
+type Stream struct {
+	mux *Mux
+	id  uint32
+
+	mut           sync.Mutex
+	buf           []byte // received, not yet read
+	unacked       int    // read, but not yet announced to the sender
+	credit        int    // what we may send
+	localClosed   bool
+	remoteClosed  bool
+	readDeadline  time.Time
+	writeDeadline time.Time
+
+	readable chan struct{}
+	writable chan struct{}
+}
+
+var _ net.Conn = (*Stream)(nil)
+
+func newStream(m *Mux, id uint32) *Stream {
+	return &Stream{
+		mux:      m,
+		id:       id,
+		credit:   streamWindow,
+		readable: make(chan struct{}, 1),
+		writable: make(chan struct{}, 1),
+	}
+}
+
+func (s *Stream) Read(bs []byte) (int, error) {
+	for {
+		s.mut.Lock()
+		if len(s.buf) > 0 {
+			n := copy(bs, s.buf)
+			s.buf = s.buf[n:]
+			s.unacked += n
+			ack := 0
+			if s.unacked >= streamWindow/2 && !s.remoteClosed {
+				ack, s.unacked = s.unacked, 0
+			}
+			s.mut.Unlock()
+			if ack > 0 {
+				if err := WriteMessage(s.mux, StreamWindow{Stream: s.id, Increment: uint32(ack)}); err != nil {
+					return n, err
+				}
+			}
+			return n, nil
+		}
+		if s.localClosed {
+			s.mut.Unlock()
+			return 0, net.ErrClosed
+		}
+		if s.remoteClosed {
+			s.mut.Unlock()
+			return 0, io.EOF
+		}
+		deadline := s.readDeadline
+		s.mut.Unlock()
+
+		if err := wait(s.readable, deadline); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func (s *Stream) Write(bs []byte) (int, error) {
+	written := 0
+	for len(bs) > 0 {
+		s.mut.Lock()
+		if s.localClosed || s.remoteClosed {
+			s.mut.Unlock()
+			return written, net.ErrClosed
+		}
+		n := min(len(bs), s.credit, maxStreamDataLength)
+		if n == 0 {
+			deadline := s.writeDeadline
+			s.mut.Unlock()
+			if err := wait(s.writable, deadline); err != nil {
+				return written, err
+			}
+			continue
+		}
+		s.credit -= n
+		s.mut.Unlock()
+
+		if err := WriteMessage(s.mux, StreamData{Stream: s.id, Data: bs[:n]}); err != nil {
+			return written, err
+		}
+		written += n
+		bs = bs[n:]
+	}
+	return written, nil
+}
+
+func (s *Stream) Close() error {
+	return s.closeLocal(true)
+}
+
+func (s *Stream) closeLocal(announce bool) error {
+	s.mut.Lock()
+	if s.localClosed {
+		s.mut.Unlock()
+		return nil
+	}
+	s.localClosed = true
+	announce = announce && !s.remoteClosed
+	s.mut.Unlock()
+
+	s.mux.removeStream(s.id)
+	signal(s.readable)
+	signal(s.writable)
+	if announce {
+		return WriteMessage(s.mux, StreamClose{Stream: s.id})
+	}
+	return nil
+}
+
+func (s *Stream) closeRemote() {
+	s.mut.Lock()
+	s.remoteClosed = true
+	s.mut.Unlock()
+
+	s.mux.removeStream(s.id)
+	signal(s.readable)
+	signal(s.writable)
+}
+
+func (s *Stream) deliver(data []byte) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	if s.localClosed {
+		return nil
+	}
+	if len(s.buf)+len(data) > streamWindow {
+		return fmt.Errorf("stream %d: peer exceeded the flow control window", s.id)
+	}
+	s.buf = append(s.buf, data...)
+	signal(s.readable)
+	return nil
+}
+
+func (s *Stream) addCredit(n int) {
+	s.mut.Lock()
+	s.credit += n
+	s.mut.Unlock()
+	signal(s.writable)
+}
+
+func (s *Stream) LocalAddr() net.Addr {
+	return s.mux.conn.LocalAddr()
+}
+
+func (s *Stream) RemoteAddr() net.Addr {
+	return s.mux.conn.RemoteAddr()
+}
+
+func (s *Stream) SetDeadline(t time.Time) error {
+	s.SetReadDeadline(t)
+	return s.SetWriteDeadline(t)
+}
+
+func (s *Stream) SetReadDeadline(t time.Time) error {
+	s.mut.Lock()
+	s.readDeadline = t
+	s.mut.Unlock()
+	signal(s.readable)
+	return nil
+}
+
+func (s *Stream) SetWriteDeadline(t time.Time) error {
+	s.mut.Lock()
+	s.writeDeadline = t
+	s.mut.Unlock()
+	signal(s.writable)
+	return nil
+}
+
+func (s *Stream) String() string {
+	return fmt.Sprintf("stream %d@%s", s.id, s.mux.conn.RemoteAddr())
+}
+
+// wait waits for a signal on c, or returns an error when the deadline
+// passes first.
+func wait(c <-chan struct{}, deadline time.Time) error {
+	if deadline.IsZero() {
+		<-c
+		return nil
+	}
+	d := time.Until(deadline)
+	if d <= 0 {
+		return os.ErrDeadlineExceeded
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-c:
+		return nil
+	case <-t.C:
+		return os.ErrDeadlineExceeded
+	}
+}
+
+func signal(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,119 @@
+// Copyright (C) 2025 Audrius Butkevicius and Contributors (see the CONTRIBUTORS file).
+
+package protocol
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMuxStreams(t *testing.T) {
+	c0, c1 := net.Pipe()
+	client, relay := NewMux(c0), NewMux(c1)
+	defer client.Close()
+	defer relay.Close()
+
+	// The relay side accepts joins with a known key, passing other
+	// messages on.
+	accepted := make(chan *Stream, 2)
+	go func() {
+		for msg := range relay.Messages() {
+			req, ok := msg.(JoinStreamRequest)
+			if !ok {
+				continue
+			}
+			if string(req.Key) != "key" {
+				WriteMessage(relay, StreamResponse{Stream: req.Stream, Code: ResponseNotFound.Code, Message: ResponseNotFound.Message})
+				continue
+			}
+			s, err := relay.AddStream(req.Stream)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			accepted <- s
+			WriteMessage(relay, StreamResponse{Stream: req.Stream})
+		}
+	}()
+
+	ctx := context.Background()
+	if _, err := client.JoinSession(ctx, []byte("wrong")); err == nil {
+		t.Fatal("joining with an unknown key should fail")
+	}
+
+	s0, err := client.JoinSession(ctx, []byte("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r0 := <-accepted
+	s1, err := client.JoinSession(ctx, []byte("key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r1 := <-accepted
+	if n := client.NumStreams(); n != 2 {
+		t.Fatalf("expected two streams, got %d", n)
+	}
+
+	// Much more than the flow control window, in both directions on both
+	// streams at once.
+	data := make([]byte, 4*streamWindow+123)
+	rand.Read(data)
+	errs := make(chan error, 4)
+	transfer := func(w io.WriteCloser, r io.Reader) {
+		go func() {
+			_, err := w.Write(data)
+			errs <- err
+		}()
+		got := make([]byte, len(data))
+		if _, err := io.ReadFull(r, got); err != nil {
+			errs <- err
+			return
+		}
+		if !bytes.Equal(got, data) {
+			errs <- errors.New("data mismatch")
+			return
+		}
+		errs <- nil
+	}
+	go transfer(s0, r0)
+	go transfer(r1, s1)
+	for range 4 {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A stream with nothing to read times out without affecting others
+	r0.SetReadDeadline(time.Now().Add(10 * time.Millisecond))
+	if _, err := r0.Read(make([]byte, 1)); !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	// Closing is seen by the other side
+	s0.Close()
+	r0.SetReadDeadline(time.Time{})
+	if _, err := r0.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+	if _, err := s1.Write([]byte("still open")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Closing the connection closes all streams
+	client.Close()
+	if _, err := r1.Read(make([]byte, 32)); err != nil {
+		t.Fatal(err)
+	}
+	<-relay.Done()
+	if _, err := r1.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+}
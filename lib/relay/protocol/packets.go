@@ -20,6 +20,11 @@ const (
 	messageTypeConnectRequest
 	messageTypeSessionInvitation
 	messageTypeRelayFull
+	messageTypeJoinStreamRequest
+	messageTypeStreamResponse
+	messageTypeStreamData
+	messageTypeStreamWindow
+	messageTypeStreamClose
 )
 
 type header struct {
@@ -59,6 +64,39 @@ type SessionInvitation struct {
 	ServerSocket bool
 }
 
+// The messages below are only used on connections that negotiated
+// ProtocolNameV2, where sessions are multiplexed over the protocol
+// connection as streams instead of each using a connection of its own.
+
+// JoinStreamRequest joins the session with the given key as the stream
+// with the given number, chosen by the sender.
+type JoinStreamRequest struct {
+	Stream uint32
+	Key    []byte // max:32
+}
+
+type StreamResponse struct {
+	Stream  uint32
+	Code    int32
+	Message string
+}
+
+type StreamData struct {
+	Stream uint32
+	Data   []byte // max:32768
+}
+
+// StreamWindow allows the receiver to send Increment more bytes of data
+// on the stream.
+type StreamWindow struct {
+	Stream    uint32
+	Increment uint32
+}
+
+type StreamClose struct {
+	Stream uint32
+}
+
 func (i SessionInvitation) String() string {
 	device := "<invalid>"
 	if address, err := protocol.DeviceIDFromBytes(i.From); err == nil {
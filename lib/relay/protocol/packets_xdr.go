@@ -479,3 +479,293 @@ func (o *SessionInvitation) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
 	o.ServerSocket = u.UnmarshalBool()
 	return u.Error
 }
+
+/*
+
+JoinStreamRequest Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                            Stream                             |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                  Key (length + padded data)                   \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct JoinStreamRequest {
+	unsigned int Stream;
+	opaque Key<32>;
+}
+
+*/
+
+func (o JoinStreamRequest) XDRSize() int {
+	return 4 +
+		4 + len(o.Key) + xdr.Padding(len(o.Key))
+}
+
+func (o JoinStreamRequest) MarshalXDR() ([]byte, error) {
+	buf := make([]byte, o.XDRSize())
+	m := &xdr.Marshaller{Data: buf}
+	return buf, o.MarshalXDRInto(m)
+}
+
+func (o JoinStreamRequest) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+func (o JoinStreamRequest) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalUint32(o.Stream)
+	if l := len(o.Key); l > 32 {
+		return xdr.ElementSizeExceeded("Key", l, 32)
+	}
+	m.MarshalBytes(o.Key)
+	return m.Error
+}
+
+func (o *JoinStreamRequest) UnmarshalXDR(bs []byte) error {
+	u := &xdr.Unmarshaller{Data: bs}
+	return o.UnmarshalXDRFrom(u)
+}
+
+func (o *JoinStreamRequest) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	o.Stream = u.UnmarshalUint32()
+	o.Key = u.UnmarshalBytesMax(32)
+	return u.Error
+}
+
+/*
+
+StreamResponse Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                            Stream                             |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                             Code                              |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                Message (length + padded data)                 \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct StreamResponse {
+	unsigned int Stream;
+	int Code;
+	string Message<>;
+}
+
+*/
+
+func (o StreamResponse) XDRSize() int {
+	return 4 + 4 +
+		4 + len(o.Message) + xdr.Padding(len(o.Message))
+}
+
+func (o StreamResponse) MarshalXDR() ([]byte, error) {
+	buf := make([]byte, o.XDRSize())
+	m := &xdr.Marshaller{Data: buf}
+	return buf, o.MarshalXDRInto(m)
+}
+
+func (o StreamResponse) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+func (o StreamResponse) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalUint32(o.Stream)
+	m.MarshalUint32(uint32(o.Code))
+	m.MarshalString(o.Message)
+	return m.Error
+}
+
+func (o *StreamResponse) UnmarshalXDR(bs []byte) error {
+	u := &xdr.Unmarshaller{Data: bs}
+	return o.UnmarshalXDRFrom(u)
+}
+
+func (o *StreamResponse) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	o.Stream = u.UnmarshalUint32()
+	o.Code = int32(u.UnmarshalUint32())
+	o.Message = u.UnmarshalString()
+	return u.Error
+}
+
+/*
+
+StreamData Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                            Stream                             |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+/                                                               /
+\                  Data (length + padded data)                  \
+/                                                               /
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct StreamData {
+	unsigned int Stream;
+	opaque Data<32768>;
+}
+
+*/
+
+func (o StreamData) XDRSize() int {
+	return 4 +
+		4 + len(o.Data) + xdr.Padding(len(o.Data))
+}
+
+func (o StreamData) MarshalXDR() ([]byte, error) {
+	buf := make([]byte, o.XDRSize())
+	m := &xdr.Marshaller{Data: buf}
+	return buf, o.MarshalXDRInto(m)
+}
+
+func (o StreamData) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+func (o StreamData) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalUint32(o.Stream)
+	if l := len(o.Data); l > 32768 {
+		return xdr.ElementSizeExceeded("Data", l, 32768)
+	}
+	m.MarshalBytes(o.Data)
+	return m.Error
+}
+
+func (o *StreamData) UnmarshalXDR(bs []byte) error {
+	u := &xdr.Unmarshaller{Data: bs}
+	return o.UnmarshalXDRFrom(u)
+}
+
+func (o *StreamData) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	o.Stream = u.UnmarshalUint32()
+	o.Data = u.UnmarshalBytesMax(32768)
+	return u.Error
+}
+
+/*
+
+StreamWindow Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                            Stream                             |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                           Increment                           |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct StreamWindow {
+	unsigned int Stream;
+	unsigned int Increment;
+}
+
+*/
+
+func (o StreamWindow) XDRSize() int {
+	return 4 + 4
+}
+
+func (o StreamWindow) MarshalXDR() ([]byte, error) {
+	buf := make([]byte, o.XDRSize())
+	m := &xdr.Marshaller{Data: buf}
+	return buf, o.MarshalXDRInto(m)
+}
+
+func (o StreamWindow) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+func (o StreamWindow) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalUint32(o.Stream)
+	m.MarshalUint32(o.Increment)
+	return m.Error
+}
+
+func (o *StreamWindow) UnmarshalXDR(bs []byte) error {
+	u := &xdr.Unmarshaller{Data: bs}
+	return o.UnmarshalXDRFrom(u)
+}
+
+func (o *StreamWindow) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	o.Stream = u.UnmarshalUint32()
+	o.Increment = u.UnmarshalUint32()
+	return u.Error
+}
+
+/*
+
+StreamClose Structure:
+
+ 0                   1                   2                   3
+ 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+|                            Stream                             |
++-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+
+
+struct StreamClose {
+	unsigned int Stream;
+}
+
+*/
+
+func (o StreamClose) XDRSize() int {
+	return 4
+}
+
+func (o StreamClose) MarshalXDR() ([]byte, error) {
+	buf := make([]byte, o.XDRSize())
+	m := &xdr.Marshaller{Data: buf}
+	return buf, o.MarshalXDRInto(m)
+}
+
+func (o StreamClose) MustMarshalXDR() []byte {
+	bs, err := o.MarshalXDR()
+	if err != nil {
+		panic(err)
+	}
+	return bs
+}
+
+func (o StreamClose) MarshalXDRInto(m *xdr.Marshaller) error {
+	m.MarshalUint32(o.Stream)
+	return m.Error
+}
+
+func (o *StreamClose) UnmarshalXDR(bs []byte) error {
+	u := &xdr.Unmarshaller{Data: bs}
+	return o.UnmarshalXDRFrom(u)
+}
+
+func (o *StreamClose) UnmarshalXDRFrom(u *xdr.Unmarshaller) error {
+	o.Stream = u.UnmarshalUint32()
+	return u.Error
+}
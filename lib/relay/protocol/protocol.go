@@ -11,6 +11,12 @@ import (
 const (
 	magic        = 0x9E79BC40
 	ProtocolName = "bep-relay"
+	// ProtocolNameV2 is negotiated by relays and clients supporting
+	// sessions multiplexed over the protocol connection.
+	ProtocolNameV2 = "bep-relay/2"
+
+	maxMessageLength    = 1024
+	maxStreamDataLength = 32768
 )
 
 var (
@@ -54,6 +60,21 @@ func WriteMessage(w io.Writer, message interface{}) error {
 	case RelayFull:
 		payload, err = msg.MarshalXDR()
 		header.messageType = messageTypeRelayFull
+	case JoinStreamRequest:
+		payload, err = msg.MarshalXDR()
+		header.messageType = messageTypeJoinStreamRequest
+	case StreamResponse:
+		payload, err = msg.MarshalXDR()
+		header.messageType = messageTypeStreamResponse
+	case StreamData:
+		payload, err = msg.MarshalXDR()
+		header.messageType = messageTypeStreamData
+	case StreamWindow:
+		payload, err = msg.MarshalXDR()
+		header.messageType = messageTypeStreamWindow
+	case StreamClose:
+		payload, err = msg.MarshalXDR()
+		header.messageType = messageTypeStreamClose
 	default:
 		err = errors.New("unknown message type")
 	}
@@ -88,7 +109,11 @@ func ReadMessage(r io.Reader) (interface{}, error) {
 	if header.magic != magic {
 		return nil, errors.New("magic mismatch")
 	}
-	if header.messageLength < 0 || header.messageLength > 1024 {
+	maxLength := maxMessageLength
+	if header.messageType == messageTypeStreamData {
+		maxLength += maxStreamDataLength
+	}
+	if header.messageLength < 0 || int(header.messageLength) > maxLength {
 		return nil, fmt.Errorf("bad length (%d)", header.messageLength)
 	}
 
@@ -138,6 +163,26 @@ func ReadMessage(r io.Reader) (interface{}, error) {
 		var msg RelayFull
 		err := msg.UnmarshalXDR(buf)
 		return msg, err
+	case messageTypeJoinStreamRequest:
+		var msg JoinStreamRequest
+		err := msg.UnmarshalXDR(buf)
+		return msg, err
+	case messageTypeStreamResponse:
+		var msg StreamResponse
+		err := msg.UnmarshalXDR(buf)
+		return msg, err
+	case messageTypeStreamData:
+		var msg StreamData
+		err := msg.UnmarshalXDR(buf)
+		return msg, err
+	case messageTypeStreamWindow:
+		var msg StreamWindow
+		err := msg.UnmarshalXDR(buf)
+		return msg, err
+	case messageTypeStreamClose:
+		var msg StreamClose
+		err := msg.UnmarshalXDR(buf)
+		return msg, err
 	}
 
 	return nil, errors.New("unknown message type")
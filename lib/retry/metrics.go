@@ -0,0 +1,39 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package retry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	metricFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "retry",
+		Name:      "failures_total",
+		Help:      "Total number of failed attempts, per subsystem",
+	}, []string{"subsystem"})
+	metricDelaySeconds = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "retry",
+		Name:      "delay_seconds_total",
+		Help:      "Total time backed off before retrying, per subsystem",
+	}, []string{"subsystem"})
+	metricCircuitOpened = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "retry",
+		Name:      "circuit_opened_total",
+		Help:      "Total number of times a circuit opened after repeated failures, per subsystem",
+	}, []string{"subsystem"})
+	metricCircuitRejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "syncthing",
+		Subsystem: "retry",
+		Name:      "circuit_rejected_total",
+		Help:      "Total number of attempts not made as the circuit was open, per subsystem",
+	}, []string{"subsystem"})
+)
@@ -0,0 +1,233 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+// Package retry implements the backoff and circuit breaking shared by the
+// subsystems retrying things over the network, such as dialing devices and
+// talking to discovery servers. Each subsystem has a default policy, which
+// can be overridden by configuration.
+package retry
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Subsystems using a retry policy
+const (
+	Dialing      = "dialing"
+	Lookups      = "lookups"
+	Announcement = "announcement"
+)
+
+// ErrCircuitOpen is returned instead of making an attempt while the
+// circuit is open, after too many failures in a row.
+var ErrCircuitOpen = errors.New("circuit open after repeated failures")
+
+// A Policy decides how long to wait before retrying after failures, and
+// when to stop trying for a while.
+type Policy struct {
+	BaseDelay time.Duration // after the first failure
+	MaxDelay  time.Duration // zero for no limit
+	Factor    float64       // growth of the delay per failure, two if unset
+	Jitter    float64       // random variation of the delay, as a fraction of it
+
+	// After BreakAfter failures in a row the circuit opens: attempts fail
+	// right away with ErrCircuitOpen for the cool down, then one is let
+	// through. Zero disables circuit breaking.
+	BreakAfter int
+	CoolDown   time.Duration
+}
+
+// Delay returns how long to wait before retrying after the given number of
+// failures in a row.
+func (p Policy) Delay(failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	factor := p.Factor
+	if factor <= 0 {
+		factor = 2
+	}
+	delay := float64(p.BaseDelay) * math.Pow(factor, float64(failures-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 && p.Jitter <= 1 {
+		delay *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	if delay >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(delay)
+}
+
+// with returns the policy with the set fields of the override applied.
+func (p Policy) with(o Policy) Policy {
+	if o.BaseDelay > 0 {
+		p.BaseDelay = o.BaseDelay
+	}
+	if o.MaxDelay > 0 {
+		p.MaxDelay = o.MaxDelay
+	}
+	if o.Factor > 0 {
+		p.Factor = o.Factor
+	}
+	if o.Jitter > 0 {
+		p.Jitter = o.Jitter
+	}
+	if o.BreakAfter > 0 {
+		p.BreakAfter = o.BreakAfter
+	}
+	if o.CoolDown > 0 {
+		p.CoolDown = o.CoolDown
+	}
+	return p
+}
+
+var (
+	overridesMut sync.RWMutex
+	overrides    map[string]Policy
+)
+
+// Configure overrides the policies of subsystems, replacing the overrides
+// set before. Unset fields of an override keep the defaults.
+func Configure(policies map[string]Policy) {
+	overridesMut.Lock()
+	defer overridesMut.Unlock()
+	overrides = policies
+}
+
+// policyFor returns the policy in effect for the subsystem, the defaults
+// with any overrides applied.
+func policyFor(subsystem string, defaults Policy) Policy {
+	overridesMut.RLock()
+	defer overridesMut.RUnlock()
+	return defaults.with(overrides[subsystem])
+}
+
+// A Backoff keeps track of the failures in a row of one thing retried
+// according to the policy of a subsystem, e.g. requests to one server.
+type Backoff struct {
+	subsystem string
+	defaults  Policy
+
+	mut       sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// New returns a Backoff for the subsystem, with the given default policy.
+func New(subsystem string, defaults Policy) *Backoff {
+	return &Backoff{
+		subsystem: subsystem,
+		defaults:  defaults,
+	}
+}
+
+// Policy returns the policy in effect, the defaults with any configured
+// overrides applied.
+func (b *Backoff) Policy() Policy {
+	return policyFor(b.subsystem, b.defaults)
+}
+
+// Call makes the attempt unless the circuit is open, recording how it went.
+func (b *Backoff) Call(fn func() error) error {
+	if err := b.Allow(); err != nil {
+		return err
+	}
+	err := fn()
+	if err != nil {
+		b.Failure()
+	} else {
+		b.Success()
+	}
+	return err
+}
+
+// Allow returns ErrCircuitOpen while the circuit is open.
+func (b *Backoff) Allow() error {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	if time.Now().Before(b.openUntil) {
+		metricCircuitRejected.WithLabelValues(b.subsystem).Inc()
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// Failure records a failed attempt and returns how long to wait before the
+// next one.
+func (b *Backoff) Failure() time.Duration {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	p := b.Policy()
+	b.failures++
+	metricFailures.WithLabelValues(b.subsystem).Inc()
+	if p.BreakAfter > 0 && b.failures >= p.BreakAfter && p.CoolDown > 0 {
+		if b.failures == p.BreakAfter {
+			slog.Warn("Pausing attempts after repeated failures", slog.String("subsystem", b.subsystem), slog.Int("failures", b.failures), slog.Duration("pause", p.CoolDown))
+		}
+		b.openUntil = time.Now().Add(p.CoolDown)
+		metricCircuitOpened.WithLabelValues(b.subsystem).Inc()
+	}
+	delay := p.Delay(b.failures)
+	metricDelaySeconds.WithLabelValues(b.subsystem).Add(delay.Seconds())
+	return delay
+}
+
+// Success records a successful attempt, closing the circuit.
+func (b *Backoff) Success() {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	b.failures = 0
+	b.openUntil = time.Time{}
+}
+
+// After returns how long to wait before retrying after the given number of
+// failures in a row, per the policy in effect for the subsystem. It's for
+// callers keeping track of failures themselves, and is expected to be
+// called once per failure, to keep the metrics.
+func After(subsystem string, defaults Policy, failures int) time.Duration {
+	if failures <= 0 {
+		return 0
+	}
+	metricFailures.WithLabelValues(subsystem).Inc()
+	delay := policyFor(subsystem, defaults).Delay(failures)
+	metricDelaySeconds.WithLabelValues(subsystem).Add(delay.Seconds())
+	return delay
+}
+
+// Do makes attempts until one succeeds, at most the given number, waiting
+// between them according to the policy with the overrides configured for
+// the subsystem. It returns the error of the last attempt, or that of the
+// context if it's cancelled while waiting.
+func Do(ctx context.Context, subsystem string, defaults Policy, attempts int, fn func(context.Context) error) error {
+	p := policyFor(subsystem, defaults)
+	var err error
+	for failures := 1; ; failures++ {
+		if err = fn(ctx); err == nil {
+			return nil
+		}
+		metricFailures.WithLabelValues(subsystem).Inc()
+		if failures >= attempts {
+			return err
+		}
+		delay := p.Delay(failures)
+		metricDelaySeconds.WithLabelValues(subsystem).Add(delay.Seconds())
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
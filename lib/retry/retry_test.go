@@ -0,0 +1,105 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPolicyDelay(t *testing.T) {
+	p := Policy{BaseDelay: time.Second, MaxDelay: 10 * time.Second}
+	expected := []time.Duration{0, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 10 * time.Second, 10 * time.Second}
+	for failures, exp := range expected {
+		if d := p.Delay(failures); d != exp {
+			t.Errorf("delay after %d failures is %v, expected %v", failures, d, exp)
+		}
+	}
+
+	if d := (Policy{BaseDelay: time.Second}).Delay(1000); d <= 0 {
+		t.Error("unlimited delay overflowed:", d)
+	}
+
+	p.Jitter = 0.5
+	for range 100 {
+		if d := p.Delay(2); d < time.Second || d > 3*time.Second {
+			t.Fatal("delay out of jitter range:", d)
+		}
+	}
+}
+
+func TestBackoffCircuit(t *testing.T) {
+	b := New("test", Policy{BaseDelay: time.Second, BreakAfter: 3, CoolDown: time.Hour})
+	failing := errors.New("failing")
+	for i := range 3 {
+		if err := b.Call(func() error { return failing }); err != failing {
+			t.Fatalf("attempt %d: expected the call's error, got %v", i, err)
+		}
+	}
+	if err := b.Call(func() error { t.Fatal("called with open circuit"); return nil }); err != ErrCircuitOpen {
+		t.Fatal("expected open circuit, got", err)
+	}
+
+	// Past the cool down one attempt goes through; failing reopens the circuit.
+	b.openUntil = time.Now()
+	if err := b.Call(func() error { return failing }); err != failing {
+		t.Fatal("expected an attempt after the cool down, got", err)
+	}
+	if err := b.Allow(); err != ErrCircuitOpen {
+		t.Fatal("expected the circuit to reopen, got", err)
+	}
+
+	b.Success()
+	if err := b.Allow(); err != nil {
+		t.Fatal("expected success to close the circuit, got", err)
+	}
+	if d := b.Failure(); d != time.Second {
+		t.Error("expected success to reset the delay, got", d)
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	defer Configure(nil)
+
+	b := New("test", Policy{BaseDelay: time.Second, MaxDelay: time.Minute})
+	Configure(map[string]Policy{"test": {BaseDelay: 5 * time.Second}, "other": {BaseDelay: time.Hour}})
+	if p := b.Policy(); p.BaseDelay != 5*time.Second || p.MaxDelay != time.Minute {
+		t.Errorf("unexpected policy %+v", p)
+	}
+	Configure(nil)
+	if p := b.Policy(); p.BaseDelay != time.Second {
+		t.Errorf("unexpected policy %+v", p)
+	}
+}
+
+func TestDo(t *testing.T) {
+	p := Policy{BaseDelay: time.Millisecond}
+	calls := 0
+	err := Do(context.Background(), "test", p, 3, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errors.New("failing")
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("expected success on the third call, got %v after %d", err, calls)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	calls = 0
+	err = Do(ctx, "test", Policy{BaseDelay: time.Hour}, 3, func(context.Context) error {
+		calls++
+		cancel()
+		return errors.New("failing")
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Fatalf("expected cancellation after one call, got %v after %d", err, calls)
+	}
+}
@@ -10,8 +10,10 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/osutil"
 	"github.com/syncthing/syncthing/lib/protocol"
 )
 
@@ -64,23 +66,25 @@ func HashFile(ctx context.Context, folderID string, fs fs.Filesystem, path strin
 // workers are used in parallel. The outbox will become closed when the inbox
 // is closed and all items handled.
 type parallelHasher struct {
-	folderID string
-	fs       fs.Filesystem
-	outbox   chan<- ScanResult
-	inbox    <-chan protocol.FileInfo
-	counter  Counter
-	done     chan<- struct{}
-	wg       sync.WaitGroup
+	folderID   string
+	fs         fs.Filesystem
+	ioPriority osutil.IOPriority
+	outbox     chan<- ScanResult
+	inbox      <-chan protocol.FileInfo
+	counter    Counter
+	done       chan<- struct{}
+	wg         sync.WaitGroup
 }
 
-func newParallelHasher(ctx context.Context, folderID string, fs fs.Filesystem, workers int, outbox chan<- ScanResult, inbox <-chan protocol.FileInfo, counter Counter, done chan<- struct{}) {
+func newParallelHasher(ctx context.Context, folderID string, fs fs.Filesystem, workers int, ioPriority osutil.IOPriority, outbox chan<- ScanResult, inbox <-chan protocol.FileInfo, counter Counter, done chan<- struct{}) {
 	ph := &parallelHasher{
-		folderID: folderID,
-		fs:       fs,
-		outbox:   outbox,
-		inbox:    inbox,
-		counter:  counter,
-		done:     done,
+		folderID:   folderID,
+		fs:         fs,
+		ioPriority: ioPriority,
+		outbox:     outbox,
+		inbox:      inbox,
+		counter:    counter,
+		done:       done,
 	}
 
 	ph.wg.Add(workers)
@@ -94,6 +98,8 @@ func newParallelHasher(ctx context.Context, folderID string, fs fs.Filesystem, w
 func (ph *parallelHasher) hashFiles(ctx context.Context) {
 	defer ph.wg.Done()
 
+	throttle := osutil.ApplyIOPriority(ph.ioPriority)
+
 	for {
 		select {
 		case f, ok := <-ph.inbox:
@@ -107,7 +113,9 @@ func (ph *parallelHasher) hashFiles(ctx context.Context) {
 				panic("Bug. Asked to hash a directory or a deleted file.")
 			}
 
+			t0 := time.Now()
 			blocks, err := HashFile(ctx, ph.folderID, ph.fs, f.Name, f.BlockSize(), ph.counter)
+			throttle(time.Since(t0))
 			if err != nil {
 				handleError(ctx, "hashing", f.Name, err, ph.outbox)
 				continue
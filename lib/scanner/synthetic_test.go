@@ -0,0 +1,139 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/syncthing/syncthing/internal/db/sqlite"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+// The tests and benchmarks in this file use the synthetic filesystem to
+// simulate folders with far more files than is practical to create on
+// disk.
+
+func syntheticFs(files, size int) fs.Filesystem {
+	return fs.NewFilesystem(fs.FilesystemTypeSynthetic, fmt.Sprintf("?files=%d&fanout=100&size=%d", files, size))
+}
+
+// TestWalkSyntheticMemory scans a million files and checks that the memory
+// used by the scanner doesn't grow with the number of files, as long as
+// the results are consumed. Progress reporting needs the full list of
+// files to hash up front, so it's disabled here.
+func TestWalkSyntheticMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("slow test")
+	}
+
+	const numFiles = 1000000
+	cfg, cancel := testConfig()
+	defer cancel()
+	cfg.Filesystem = syntheticFs(numFiles, 0)
+	cfg.ProgressTickIntervalS = -1
+
+	var ms runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&ms)
+	base := ms.HeapInuse
+
+	var files int
+	var peak uint64
+	for res := range Walk(context.Background(), cfg) {
+		if res.Err != nil {
+			t.Fatal(res.Err)
+		}
+		if res.File.IsDirectory() {
+			continue
+		}
+		files++
+		if files%50000 == 0 {
+			runtime.ReadMemStats(&ms)
+			peak = max(peak, ms.HeapInuse)
+		}
+	}
+
+	if files != numFiles {
+		t.Errorf("scanned %d files, expected %d", files, numFiles)
+	}
+	if growth := int64(peak) - int64(base); growth > 64<<20 {
+		t.Errorf("heap grew by %d MiB while scanning", growth>>20)
+	}
+}
+
+func BenchmarkWalkSynthetic(b *testing.B) {
+	for _, size := range []int{0, 128 << 10} {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			const numFiles = 10000
+			cfg, cancel := testConfig()
+			defer cancel()
+			cfg.Filesystem = syntheticFs(numFiles, size)
+
+			b.ReportAllocs()
+			for range b.N {
+				for res := range Walk(context.Background(), cfg) {
+					if res.Err != nil {
+						b.Fatal(res.Err)
+					}
+				}
+			}
+			b.ReportMetric(numFiles*float64(b.N)/b.Elapsed().Seconds(), "files/s")
+		})
+	}
+}
+
+// BenchmarkScanIntoDB measures the throughput of a large initial scan,
+// from walking the folder to committing the results to the database in
+// batches the way the model does.
+func BenchmarkScanIntoDB(b *testing.B) {
+	const numFiles = 100000
+	const batchSize = 1000
+	if testing.Short() {
+		b.Skip("slow benchmark")
+	}
+
+	cfg, cancel := testConfig()
+	defer cancel()
+	cfg.Filesystem = syntheticFs(numFiles, 1000)
+
+	for range b.N {
+		b.StopTimer()
+		sdb, err := sqlite.Open(b.TempDir())
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+
+		batch := make([]protocol.FileInfo, 0, batchSize)
+		for res := range Walk(context.Background(), cfg) {
+			if res.Err != nil {
+				b.Fatal(res.Err)
+			}
+			batch = append(batch, res.File)
+			if len(batch) == batchSize {
+				if err := sdb.Update("synthetic", protocol.LocalDeviceID, batch); err != nil {
+					b.Fatal(err)
+				}
+				batch = batch[:0]
+			}
+		}
+		if err := sdb.Update("synthetic", protocol.LocalDeviceID, batch); err != nil {
+			b.Fatal(err)
+		}
+
+		b.StopTimer()
+		if err := sdb.Close(); err != nil {
+			b.Fatal(err)
+		}
+		b.StartTimer()
+	}
+	b.ReportMetric(numFiles*float64(b.N)/b.Elapsed().Seconds(), "files/s")
+}
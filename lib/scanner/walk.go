@@ -67,6 +67,19 @@ type Config struct {
 	ScanXattrs bool
 	// Filter for extended attributes
 	XattrFilter XattrFilter
+	// If ScanHardLinks is true, files that are hard links to the same data
+	// get the first of them as their hard link target.
+	ScanHardLinks bool
+	// Disk I/O priority of the hashers
+	IOPriority osutil.IOPriority
+	// If FileSource is not nil, only regular files it knows about are
+	// scanned, and their blocks are taken from it when unchanged instead
+	// of hashing them again.
+	FileSource FileSource
+	// If ContentClassifier is not nil, files it trusts are not hashed
+	// again while their size and modification time are unchanged, unless
+	// it picks them for verification.
+	ContentClassifier ContentClassifier
 }
 
 type CurrentFiler interface {
@@ -74,6 +87,21 @@ type CurrentFiler interface {
 	CurrentFile(name string) (protocol.FileInfo, bool)
 }
 
+type FileSource interface {
+	// SourceFile returns the file the given one is a view of, as last
+	// scanned in the folder it belongs to.
+	SourceFile(name string) (protocol.FileInfo, bool)
+}
+
+type ContentClassifier interface {
+	// TrustModTime returns true if the content of the file is taken to be
+	// unchanged for as long as its size and modification time are.
+	TrustModTime(name string) bool
+	// Verify returns true if the trusted and seemingly unchanged file is
+	// to be hashed nonetheless, to verify its content.
+	Verify(name string, size int64) bool
+}
+
 type XattrFilter interface {
 	Permit(string) bool
 	GetMaxSingleEntrySize() int
@@ -95,7 +123,7 @@ func WalkWithoutHashing(ctx context.Context, cfg Config) chan ScanResult {
 }
 
 func newWalker(cfg Config) *walker {
-	w := &walker{cfg}
+	w := &walker{Config: cfg, hardLinks: make(map[string]string)}
 
 	if w.CurrentFiler == nil {
 		w.CurrentFiler = noCurrentFiler{}
@@ -119,6 +147,7 @@ var (
 
 type walker struct {
 	Config
+	hardLinks map[string]string // hard link ID -> first file name seen
 }
 
 // Walk returns the list of files found in the local folder by scanning the
@@ -136,7 +165,7 @@ func (w *walker) walk(ctx context.Context) chan ScanResult {
 	// We're not required to emit scan progress events, just kick off hashers,
 	// and feed inputs directly from the walker.
 	if w.ProgressTickIntervalS < 0 {
-		newParallelHasher(ctx, w.Folder, w.Filesystem, w.Hashers, finishedChan, toHashChan, nil, nil)
+		newParallelHasher(ctx, w.Folder, w.Filesystem, w.Hashers, w.IOPriority, finishedChan, toHashChan, nil, nil)
 		return finishedChan
 	}
 
@@ -170,7 +199,7 @@ func (w *walker) walk(ctx context.Context) chan ScanResult {
 		done := make(chan struct{})
 		progress := newByteCounter()
 
-		newParallelHasher(ctx, w.Folder, w.Filesystem, w.Hashers, finishedChan, realToHashChan, progress, done)
+		newParallelHasher(ctx, w.Folder, w.Filesystem, w.Hashers, w.IOPriority, finishedChan, realToHashChan, progress, done)
 
 		// A routine which actually emits the FolderScanProgress events
 		// every w.ProgressTicker ticks, until the hasher routines terminate.
@@ -272,6 +301,13 @@ func isWarnableError(err error) bool {
 		!errors.Is(err, context.Canceled) // folder restarting
 }
 
+// IsExpiredTemporary returns true if the path is a temporary file last
+// modified more than lifetime before now. The walker removes those as it
+// comes across them.
+func IsExpiredTemporary(path string, info fs.FileInfo, lifetime time.Duration, now time.Time) bool {
+	return fs.IsTemporary(path) && info.IsRegular() && info.ModTime().Add(lifetime).Before(now)
+}
+
 func (w *walker) walkAndHashFiles(ctx context.Context, toHashChan chan<- protocol.FileInfo, finishedChan chan<- ScanResult) fs.WalkFunc {
 	now := time.Now()
 	ignoredParent := ""
@@ -300,7 +336,7 @@ func (w *walker) walkAndHashFiles(ctx context.Context, toHashChan chan<- protoco
 
 		if fs.IsTemporary(path) {
 			l.Debugln(w, "temporary:", path, "err:", err)
-			if err == nil && info.IsRegular() && info.ModTime().Add(w.TempLifetime).Before(now) {
+			if err == nil && IsExpiredTemporary(path, info, w.TempLifetime, now) {
 				w.Filesystem.Remove(path)
 				l.Debugln(w, "removing temporary:", path, info.ModTime())
 			}
@@ -421,7 +457,7 @@ func (w *walker) handleItem(ctx context.Context, path string, info fs.FileInfo,
 		return w.walkDir(ctx, path, info, finishedChan)
 
 	case info.IsRegular():
-		return w.walkRegular(ctx, path, info, toHashChan)
+		return w.walkRegular(ctx, path, info, toHashChan, finishedChan)
 
 	default:
 		// A special file, socket, fifo, etc. -- do nothing, just skip and continue scanning.
@@ -430,7 +466,18 @@ func (w *walker) handleItem(ctx context.Context, path string, info fs.FileInfo,
 	}
 }
 
-func (w *walker) walkRegular(ctx context.Context, relPath string, info fs.FileInfo, toHashChan chan<- protocol.FileInfo) error {
+func (w *walker) walkRegular(ctx context.Context, relPath string, info fs.FileInfo, toHashChan chan<- protocol.FileInfo, finishedChan chan<- ScanResult) error {
+	var srcFile protocol.FileInfo
+	if w.FileSource != nil {
+		var ok bool
+		srcFile, ok = w.FileSource.SourceFile(relPath)
+		if !ok {
+			// Ignored or not yet scanned where it comes from.
+			l.Debugln(w, "not in source:", relPath)
+			return nil
+		}
+	}
+
 	curFile, hasCurFile := w.CurrentFiler.CurrentFile(relPath)
 
 	blockSize := protocol.BlockSize(info.Size())
@@ -456,10 +503,23 @@ func (w *walker) walkRegular(ctx context.Context, relPath string, info fs.FileIn
 	f = w.updateFileInfo(f, curFile)
 	f.NoPermissions = w.IgnorePerms
 	f.RawBlockSize = int32(blockSize)
+	if w.ScanHardLinks {
+		f.HardLinkTarget = w.hardLinkTarget(relPath, info, curFile)
+	} else {
+		// Not ours to judge; keep what the index says.
+		f.HardLinkTarget = curFile.HardLinkTarget
+	}
 	l.Debugln(w, "checking:", f)
 
+	// A trusted file with the same size and modification time as before
+	// has the same content, whatever else changed.
+	trusted := hasCurFile && w.ContentClassifier != nil &&
+		!curFile.MustRescan() && !curFile.IsInvalid() && !curFile.IsDeleted() && curFile.Type == f.Type &&
+		curFile.Size == f.Size && protocol.ModTimeEqual(curFile.ModTime(), f.ModTime(), w.ModTimeWindow) &&
+		w.ContentClassifier.TrustModTime(relPath)
+
 	if hasCurFile {
-		if curFile.IsEquivalentOptional(f, protocol.FileInfoComparison{
+		if f.HardLinkTarget == curFile.HardLinkTarget && curFile.IsEquivalentOptional(f, protocol.FileInfoComparison{
 			ModTimeWindow:   w.ModTimeWindow,
 			IgnorePerms:     w.IgnorePerms,
 			IgnoreBlocks:    true,
@@ -467,8 +527,12 @@ func (w *walker) walkRegular(ctx context.Context, relPath string, info fs.FileIn
 			IgnoreOwnership: !w.ScanOwnership,
 			IgnoreXattrs:    !w.ScanXattrs,
 		}) {
-			l.Debugln(w, "unchanged:", curFile)
-			return nil
+			if !trusted || !w.ContentClassifier.Verify(relPath, f.Size) {
+				l.Debugln(w, "unchanged:", curFile)
+				return nil
+			}
+			l.Debugln(w, "verify:", curFile)
+			trusted = false
 		}
 		if curFile.ShouldConflict() && !f.ShouldConflict() {
 			// The old file was invalid for whatever reason and probably not
@@ -483,6 +547,34 @@ func (w *walker) walkRegular(ctx context.Context, relPath string, info fs.FileIn
 		l.Debugln(w, "rescan:", curFile)
 	}
 
+	if trusted {
+		// Only the metadata changed.
+		f.Blocks = curFile.Blocks
+		f.RawBlockSize = curFile.RawBlockSize
+		f.BlocksHash = curFile.BlocksHash
+		l.Debugln(w, "trusted, not hashed:", relPath, f)
+		select {
+		case finishedChan <- ScanResult{File: f}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
+	}
+
+	if w.FileSource != nil && srcFile.Size == f.Size && srcFile.ModTime().Equal(f.ModTime()) {
+		// The source folder has hashed it already.
+		f.Blocks = srcFile.Blocks
+		f.RawBlockSize = srcFile.RawBlockSize
+		f.BlocksHash = srcFile.BlocksHash
+		l.Debugln(w, "blocks from source:", relPath, f)
+		select {
+		case finishedChan <- ScanResult{File: f}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		return nil
+	}
+
 	l.Debugln(w, "to hash:", relPath, f)
 
 	select {
@@ -643,6 +735,31 @@ func (w *walker) applyNormalization(path, normPath string, info fs.FileInfo) (st
 	return "", errUTF8Conflict
 }
 
+// hardLinkTarget returns the name of the file that the given one is a hard
+// link to, or an empty string if it's not a hard link or the first one of
+// its group.
+func (w *walker) hardLinkTarget(relPath string, info fs.FileInfo, curFile protocol.FileInfo) string {
+	id, nlink, ok := fs.HardLinkID(info)
+	if !ok || nlink < 2 {
+		return ""
+	}
+	if first, ok := w.hardLinks[id]; ok {
+		return first
+	}
+	// Not seen before in this scan, which may cover only part of the
+	// folder. Keep the previous target if it's still the same data.
+	if target := curFile.HardLinkTarget; target != "" {
+		if tinfo, err := w.Filesystem.Lstat(target); err == nil {
+			if tid, _, ok := fs.HardLinkID(tinfo); ok && tid == id {
+				w.hardLinks[id] = target
+				return target
+			}
+		}
+	}
+	w.hardLinks[id] = relPath
+	return ""
+}
+
 // updateFileInfo updates walker specific members of protocol.FileInfo that
 // do not depend on type, and things that should be preserved from the
 // previous version of the FileInfo.
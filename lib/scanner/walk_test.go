@@ -373,6 +373,184 @@ func TestWalkSymlinkUnix(t *testing.T) {
 	}
 }
 
+func TestWalkHardLinks(t *testing.T) {
+	if build.IsWindows {
+		t.Skip("hard link detection is not supported on Windows")
+	}
+
+	dir := t.TempDir()
+	must := func(err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	must(os.WriteFile(filepath.Join(dir, "a"), []byte("data"), 0o644))
+	must(os.Mkdir(filepath.Join(dir, "sub"), 0o755))
+	must(os.Link(filepath.Join(dir, "a"), filepath.Join(dir, "sub", "b")))
+	must(os.WriteFile(filepath.Join(dir, "c"), []byte("data"), 0o644))
+
+	walk := func(sub string, cfiler CurrentFiler) map[string]protocol.FileInfo {
+		cfg, cancel := testConfig()
+		defer cancel()
+		cfg.Filesystem = fs.NewFilesystem(fs.FilesystemTypeBasic, dir)
+		cfg.Subs = []string{sub}
+		cfg.CurrentFiler = cfiler
+		cfg.ScanHardLinks = true
+		res := make(map[string]protocol.FileInfo)
+		for r := range Walk(context.TODO(), cfg) {
+			if r.Err == nil {
+				res[r.File.Name] = r.File
+			}
+		}
+		return res
+	}
+
+	// The walk order decides which of the two links is seen first and
+	// becomes the target of the other.
+	files := walk(".", nil)
+	b := filepath.Join("sub", "b")
+	link, target := b, "a"
+	if files[b].HardLinkTarget == "" {
+		link, target = target, link
+	}
+	if files[link].HardLinkTarget != target {
+		t.Errorf("expected %s to link to %s, got %q", link, target, files[link].HardLinkTarget)
+	}
+	if files[target].HardLinkTarget != "" || files["c"].HardLinkTarget != "" {
+		t.Errorf("unexpected hard link targets on %s or c", target)
+	}
+
+	// Scanning only the link's directory keeps the target from the index
+	files = walk(filepath.Dir(link), fakeCurrentFiler{link: files[link]})
+	if _, ok := files[link]; ok {
+		t.Errorf("%s should be unchanged, got %v", link, files[link])
+	}
+}
+
+func TestWalkFileSource(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"known", "stale", "unknown"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	walk := func(source fakeCurrentFiler) map[string]protocol.FileInfo {
+		cfg, cancel := testConfig()
+		defer cancel()
+		cfg.Filesystem = fs.NewFilesystem(fs.FilesystemTypeBasic, dir)
+		cfg.FileSource = fakeFileSource{source}
+		res := make(map[string]protocol.FileInfo)
+		for r := range Walk(context.TODO(), cfg) {
+			if r.Err == nil && r.File.Type == protocol.FileInfoTypeFile {
+				res[r.File.Name] = r.File
+			}
+		}
+		return res
+	}
+
+	// Hash everything once to get the real file infos.
+	hashed := walk(fakeCurrentFiler{"known": {}, "stale": {}, "unknown": {}})
+	if len(hashed) != 3 {
+		t.Fatalf("expected three files, got %v", hashed)
+	}
+
+	// Blocks of unchanged files come from the source, changed files are
+	// hashed and unknown files are skipped.
+	known := hashed["known"]
+	known.Blocks = []protocol.BlockInfo{{Size: 5, Hash: []byte("from source")}}
+	stale := hashed["stale"]
+	stale.Size++
+	stale.Blocks = []protocol.BlockInfo{{Size: 6, Hash: []byte("from source")}}
+	files := walk(fakeCurrentFiler{"known": known, "stale": stale})
+
+	if _, ok := files["unknown"]; ok {
+		t.Error("file unknown to the source should not be scanned")
+	}
+	if got := files["known"].Blocks; len(got) != 1 || string(got[0].Hash) != "from source" {
+		t.Errorf("expected blocks from source for unchanged file, got %v", got)
+	}
+	if got := files["stale"].Blocks; len(got) != 1 || string(got[0].Hash) == "from source" {
+		t.Errorf("expected changed file to be hashed, got %v", got)
+	}
+}
+
+type fakeFileSource struct {
+	fakeCurrentFiler
+}
+
+func (f fakeFileSource) SourceFile(name string) (protocol.FileInfo, bool) {
+	return f.CurrentFile(name)
+}
+
+func TestWalkContentClassifier(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"photo.raw", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	walk := func(current fakeCurrentFiler, classifier ContentClassifier) map[string]protocol.FileInfo {
+		cfg, cancel := testConfig()
+		defer cancel()
+		cfg.Filesystem = fs.NewFilesystem(fs.FilesystemTypeBasic, dir)
+		cfg.CurrentFiler = current
+		cfg.ContentClassifier = classifier
+		res := make(map[string]protocol.FileInfo)
+		for r := range Walk(context.TODO(), cfg) {
+			if r.Err == nil && r.File.Type == protocol.FileInfoTypeFile {
+				res[r.File.Name] = r.File
+			}
+		}
+		return res
+	}
+
+	hashed := walk(fakeCurrentFiler{}, nil)
+	if len(hashed) != 2 {
+		t.Fatalf("expected two files, got %v", hashed)
+	}
+
+	// Trusted files keep their blocks when only other metadata changed.
+	current := make(fakeCurrentFiler)
+	for name, f := range hashed {
+		f.Permissions = 0o600
+		f.Blocks = []protocol.BlockInfo{{Size: int(f.Size), Hash: []byte("previous")}}
+		f.BlocksHash = nil
+		current[name] = f
+	}
+	files := walk(current, fakeClassifier{trust: "photo.raw"})
+	if got := files["photo.raw"].Blocks; len(got) != 1 || string(got[0].Hash) != "previous" {
+		t.Errorf("expected trusted file not to be hashed, got %v", got)
+	}
+	if got := files["notes.txt"].Blocks; len(got) != 1 || string(got[0].Hash) == "previous" {
+		t.Errorf("expected other file to be hashed, got %v", got)
+	}
+
+	// Unchanged trusted files are only hashed when picked for verification.
+	if files := walk(fakeCurrentFiler(hashed), fakeClassifier{trust: "photo.raw"}); len(files) != 0 {
+		t.Errorf("expected no changes, got %v", files)
+	}
+	files = walk(fakeCurrentFiler(hashed), fakeClassifier{trust: "photo.raw", verify: true})
+	if _, ok := files["photo.raw"]; !ok || len(files) != 1 {
+		t.Errorf("expected the trusted file to be verified, got %v", files)
+	}
+}
+
+type fakeClassifier struct {
+	trust  string
+	verify bool
+}
+
+func (c fakeClassifier) TrustModTime(name string) bool {
+	return name == c.trust
+}
+
+func (c fakeClassifier) Verify(string, int64) bool {
+	return c.verify
+}
+
 func TestBlocksizeHysteresis(t *testing.T) {
 	// Verify that we select the right block size in the presence of old
 	// file information.
@@ -7,23 +7,50 @@
 package stats
 
 import (
+	"encoding/json"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/syncthing/syncthing/internal/db"
 )
 
 const (
-	lastSeenKey     = "lastSeen"
-	connDurationKey = "lastConnDuration"
+	lastSeenKey       = "lastSeen"
+	connDurationKey   = "lastConnDuration"
+	blocksVerifiedKey = "blocksVerified"
+	blocksCorruptKey  = "blocksCorrupt"
+	lastClientKey     = "lastClient"
+	addressLedgerKey  = "addressLedger"
+
+	// The address ledger of a device keeps at most this many addresses,
+	// dropping the least recently seen ones.
+	maxAddressLedgerEntries = 100
 )
 
 type DeviceStatistics struct {
 	LastSeen                time.Time `json:"lastSeen"`
 	LastConnectionDurationS float64   `json:"lastConnectionDurationS"`
+	BlocksVerified          int64     `json:"blocksVerified"`
+	BlocksCorrupt           int64     `json:"blocksCorrupt"`
+	CorruptionFlagged       bool      `json:"corruptionFlagged"`
+	// The client, version and capabilities the device last connected
+	// with, empty if unknown
+	LastClient string `json:"lastClient"`
+}
+
+// An AddressLedgerEntry records when a device was first and last seen at
+// an address, over the given transport.
+type AddressLedgerEntry struct {
+	Address   string    `json:"address"`
+	Transport string    `json:"transport"`
+	FirstSeen time.Time `json:"firstSeen"`
+	LastSeen  time.Time `json:"lastSeen"`
 }
 
 type DeviceStatisticsReference struct {
-	kv *db.Typed
+	kv        *db.Typed
+	ledgerMut sync.Mutex // serializes updates to the address ledger
 }
 
 func NewDeviceStatisticsReference(kv *db.Typed) *DeviceStatisticsReference {
@@ -62,6 +89,87 @@ func (s *DeviceStatisticsReference) LastConnectionDuration(d time.Duration) erro
 	return s.kv.PutInt64(connDurationKey, d.Nanoseconds())
 }
 
+// GetBlocksReceived returns the number of blocks received from the device
+// that passed and failed hash verification.
+func (s *DeviceStatisticsReference) GetBlocksReceived() (verified, corrupt int64, err error) {
+	if verified, _, err = s.kv.Int64(blocksVerifiedKey); err != nil {
+		return 0, 0, err
+	}
+	if corrupt, _, err = s.kv.Int64(blocksCorruptKey); err != nil {
+		return 0, 0, err
+	}
+	return verified, corrupt, nil
+}
+
+// AddBlocksReceived adds to the number of blocks received from the device
+// that passed and failed hash verification.
+func (s *DeviceStatisticsReference) AddBlocksReceived(verified, corrupt int64) error {
+	prevVerified, prevCorrupt, err := s.GetBlocksReceived()
+	if err != nil {
+		return err
+	}
+	if err := s.kv.PutInt64(blocksVerifiedKey, prevVerified+verified); err != nil {
+		return err
+	}
+	return s.kv.PutInt64(blocksCorruptKey, prevCorrupt+corrupt)
+}
+
+// GetLastClient returns the description of the client, version and
+// capabilities, the device last connected with, empty if unknown.
+func (s *DeviceStatisticsReference) GetLastClient() (string, error) {
+	client, _, err := s.kv.String(lastClientKey)
+	return client, err
+}
+
+func (s *DeviceStatisticsReference) SetLastClient(client string) error {
+	return s.kv.PutString(lastClientKey, client)
+}
+
+// GetAddressLedger returns the addresses the device was seen at, most
+// recently seen first.
+func (s *DeviceStatisticsReference) GetAddressLedger() ([]AddressLedgerEntry, error) {
+	bs, ok, err := s.kv.Bytes(addressLedgerKey)
+	if err != nil || !ok {
+		return nil, err
+	}
+	var ledger []AddressLedgerEntry
+	if err := json.Unmarshal(bs, &ledger); err != nil {
+		return nil, err
+	}
+	return ledger, nil
+}
+
+// SawAddress records the device being seen at the address over the
+// transport now.
+func (s *DeviceStatisticsReference) SawAddress(address, transport string) error {
+	s.ledgerMut.Lock()
+	defer s.ledgerMut.Unlock()
+
+	ledger, err := s.GetAddressLedger()
+	if err != nil {
+		return err
+	}
+	now := time.Now().Truncate(time.Second)
+	entry := AddressLedgerEntry{Address: address, Transport: transport, FirstSeen: now}
+	if idx := slices.IndexFunc(ledger, func(e AddressLedgerEntry) bool {
+		return e.Address == address && e.Transport == transport
+	}); idx >= 0 {
+		entry = ledger[idx]
+		ledger = slices.Delete(ledger, idx, idx+1)
+	}
+	entry.LastSeen = now
+	ledger = slices.Insert(ledger, 0, entry)
+	if len(ledger) > maxAddressLedgerEntries {
+		ledger = ledger[:maxAddressLedgerEntries]
+	}
+
+	bs, err := json.Marshal(ledger)
+	if err != nil {
+		return err
+	}
+	return s.kv.PutBytes(addressLedgerKey, bs)
+}
+
 func (s *DeviceStatisticsReference) GetStatistics() (DeviceStatistics, error) {
 	lastSeen, err := s.GetLastSeen()
 	if err != nil {
@@ -71,8 +179,19 @@ func (s *DeviceStatisticsReference) GetStatistics() (DeviceStatistics, error) {
 	if err != nil {
 		return DeviceStatistics{}, err
 	}
+	verified, corrupt, err := s.GetBlocksReceived()
+	if err != nil {
+		return DeviceStatistics{}, err
+	}
+	lastClient, err := s.GetLastClient()
+	if err != nil {
+		return DeviceStatistics{}, err
+	}
 	return DeviceStatistics{
 		LastSeen:                lastSeen,
 		LastConnectionDurationS: lastConnDuration.Seconds(),
+		BlocksVerified:          verified,
+		BlocksCorrupt:           corrupt,
+		LastClient:              lastClient,
 	}, nil
 }
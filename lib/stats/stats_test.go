@@ -10,6 +10,7 @@
 package stats
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -46,3 +47,43 @@ func TestDeviceStat(t *testing.T) {
 		t.Error("Bad last duration:", d)
 	}
 }
+
+func TestDeviceAddressLedger(t *testing.T) {
+	sdb, err := sqlite.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		sdb.Close()
+	})
+
+	sr := NewDeviceStatisticsReference(db.NewTyped(sdb, "devstatref"))
+	for _, addr := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.1"} {
+		if err := sr.SawAddress(addr, "tcp"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ledger, err := sr.GetAddressLedger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ledger) != 2 || ledger[0].Address != "192.0.2.1" || ledger[1].Address != "192.0.2.2" {
+		t.Fatalf("unexpected ledger %v", ledger)
+	}
+	if ledger[0].Transport != "tcp" || ledger[0].FirstSeen.IsZero() || ledger[0].LastSeen.Before(ledger[0].FirstSeen) {
+		t.Errorf("unexpected entry %v", ledger[0])
+	}
+
+	for i := range maxAddressLedgerEntries {
+		if err := sr.SawAddress(fmt.Sprintf("198.51.100.%d", i), "quic"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ledger, err = sr.GetAddressLedger()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ledger) != maxAddressLedgerEntries || ledger[len(ledger)-1].Address != "198.51.100.0" {
+		t.Errorf("expected the ledger to be bounded, dropping the oldest addresses")
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package svcutil
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// Jitter spreads out delays of periodic or recovering activity, like
+// dialing and announcing, so that devices coming back all at once after an
+// outage don't act in lockstep. Being seeded by something that differs
+// between devices, such as the device ID, the delays are stable for a
+// device yet spread across the cluster.
+type Jitter struct {
+	mut sync.Mutex
+	rnd *rand.Rand
+}
+
+func NewJitter(seed []byte) *Jitter {
+	h := fnv.New128a()
+	h.Write(seed)
+	sum := h.Sum(nil)
+	src := rand.NewPCG(binary.BigEndian.Uint64(sum[:8]), binary.BigEndian.Uint64(sum[8:]))
+	return &Jitter{rnd: rand.New(src)}
+}
+
+// Delay returns a random delay in [0, max).
+func (j *Jitter) Delay(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	j.mut.Lock()
+	defer j.mut.Unlock()
+	return time.Duration(j.rnd.Int64N(int64(max)))
+}
+
+// Spread returns d changed by a random fraction of at most frac either
+// way.
+func (j *Jitter) Spread(d time.Duration, frac float64) time.Duration {
+	if d <= 0 || frac <= 0 {
+		return d
+	}
+	j.mut.Lock()
+	defer j.mut.Unlock()
+	return time.Duration(float64(d) * (1 + frac*(2*j.rnd.Float64()-1)))
+}
@@ -0,0 +1,39 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package svcutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestJitter(t *testing.T) {
+	a, b, c := NewJitter([]byte("device a")), NewJitter([]byte("device a")), NewJitter([]byte("device b"))
+	var differs bool
+	for i := 0; i < 100; i++ {
+		da, db, dc := a.Delay(time.Minute), b.Delay(time.Minute), c.Delay(time.Minute)
+		if da != db {
+			t.Fatal("delays should be the same for the same seed")
+		}
+		if da < 0 || da >= time.Minute {
+			t.Fatal("delay out of range:", da)
+		}
+		differs = differs || da != dc
+	}
+	if !differs {
+		t.Error("delays should differ between seeds")
+	}
+
+	for i := 0; i < 100; i++ {
+		if d := a.Spread(time.Minute, 0.25); d < 45*time.Second || d > 75*time.Second {
+			t.Fatal("spread out of range:", d)
+		}
+	}
+	if a.Delay(0) != 0 || a.Spread(time.Minute, 0) != time.Minute {
+		t.Error("no jitter expected")
+	}
+}
@@ -72,6 +72,12 @@ func (m *Internals) Completion(deviceID protocol.DeviceID, folderID string) (mod
 	return m.model.Completion(deviceID, folderID)
 }
 
+// WaitForFolderIdle blocks until the folder is neither scanning nor pulling
+// and is in sync with all connected devices, or the timeout expires.
+func (m *Internals) WaitForFolderIdle(ctx context.Context, folderID string, timeout time.Duration) error {
+	return m.model.WaitForFolderIdle(ctx, folderID, timeout)
+}
+
 func (m *Internals) DeviceStatistics() (map[protocol.DeviceID]stats.DeviceStatistics, error) {
 	return m.model.DeviceStatistics()
 }
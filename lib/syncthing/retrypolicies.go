@@ -0,0 +1,42 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package syncthing
+
+import (
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/retry"
+)
+
+// retryPolicies keeps the retry policy overrides of the retry package in
+// line with the configuration.
+type retryPolicies struct{}
+
+func (retryPolicies) CommitConfiguration(_, to config.Configuration) bool {
+	configureRetryPolicies(to.Options)
+	return true
+}
+
+func (retryPolicies) String() string {
+	return "retryPolicies"
+}
+
+func configureRetryPolicies(opts config.OptionsConfiguration) {
+	policies := make(map[string]retry.Policy, len(opts.RetryPolicies))
+	for _, p := range opts.RetryPolicies {
+		policies[p.Subsystem] = retry.Policy{
+			BaseDelay:  time.Duration(p.BaseDelayMs) * time.Millisecond,
+			MaxDelay:   time.Duration(p.MaxDelayS) * time.Second,
+			Factor:     p.Factor,
+			Jitter:     float64(p.JitterPct) / 100,
+			BreakAfter: p.BreakAfter,
+			CoolDown:   time.Duration(p.CoolDownS) * time.Second,
+		}
+	}
+	retry.Configure(policies)
+}
@@ -0,0 +1,69 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package syncthing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+)
+
+// The securityWebhookService posts device anomalies, signs that a known
+// device may be impersonated, to the security webhook as they happen, so
+// that they can be alerted on elsewhere.
+type securityWebhookService struct {
+	cfg      config.Wrapper
+	evLogger events.Logger
+}
+
+func newSecurityWebhookService(cfg config.Wrapper, evLogger events.Logger) *securityWebhookService {
+	return &securityWebhookService{
+		cfg:      cfg,
+		evLogger: evLogger,
+	}
+}
+
+func (s *securityWebhookService) Serve(ctx context.Context) error {
+	sub := s.evLogger.Subscribe(events.DeviceSecurityAnomaly)
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case ev, ok := <-sub.C():
+			if !ok {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			url := s.cfg.Options().SecurityWebhookURL
+			if url == "" {
+				continue
+			}
+			// The event as seen on the REST API, with its ID and time.
+			bs, err := json.Marshal(ev)
+			if err != nil {
+				slog.Warn("Failed to encode device anomaly for security webhook", slogutil.Error(err))
+				continue
+			}
+			// Same delivery as for the statistics digest.
+			if err := postDigest(ctx, url, bs); err != nil {
+				slog.Warn("Failed to send device anomaly to security webhook", slogutil.URI(url), slogutil.Error(err))
+			}
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *securityWebhookService) String() string {
+	return fmt.Sprintf("securityWebhookService@%p", s)
+}
@@ -0,0 +1,144 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package syncthing
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/thejerf/suture/v4"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+)
+
+// Services are stopped in stages on shutdown, each stage before the ones
+// its services depend on: first what takes requests, then what talks to
+// other devices, then the folders and finally the database. The services
+// of a stage are stopped concurrently, each within its own time budget. A
+// service still running when its budget is spent is reported and left
+// behind, so that a single stuck service can't keep us from exiting.
+type shutdownStage int
+
+const (
+	shutdownStageAPI      shutdownStage = iota // GUI, REST API, reporting
+	shutdownStageNetwork                       // connections and discovery
+	shutdownStageFolders                       // pulls, scans, watchers
+	shutdownStageDatabase                      // database maintenance
+	numShutdownStages
+)
+
+func (s shutdownStage) String() string {
+	switch s {
+	case shutdownStageAPI:
+		return "api"
+	case shutdownStageNetwork:
+		return "network"
+	case shutdownStageFolders:
+		return "folders"
+	case shutdownStageDatabase:
+		return "database"
+	default:
+		return "unknown"
+	}
+}
+
+// Time budgets for stopping services
+const (
+	shutdownTimeoutAPI      = 5 * time.Second
+	shutdownTimeoutNetwork  = 10 * time.Second
+	shutdownTimeoutFolders  = 30 * time.Second
+	shutdownTimeoutDatabase = 30 * time.Second
+)
+
+type shutdownService struct {
+	name    string
+	token   suture.ServiceToken
+	timeout time.Duration
+}
+
+// shutdownOverrun is a service that didn't stop within its budget.
+type shutdownOverrun struct {
+	Stage   shutdownStage
+	Service string
+	Timeout time.Duration
+}
+
+type shutdownManager struct {
+	sup    *suture.Supervisor
+	mut    sync.Mutex
+	stages [numShutdownStages][]shutdownService
+}
+
+func newShutdownManager(sup *suture.Supervisor) *shutdownManager {
+	return &shutdownManager{sup: sup}
+}
+
+// add adds the service to the supervisor, to be stopped in the given stage
+// within the given time.
+func (m *shutdownManager) add(stage shutdownStage, name string, svc suture.Service, timeout time.Duration) {
+	token := m.sup.Add(svc)
+	m.mut.Lock()
+	m.stages[stage] = append(m.stages[stage], shutdownService{name: name, token: token, timeout: timeout})
+	m.mut.Unlock()
+}
+
+// stop stops the added services stage by stage and returns those that
+// failed to stop in time. Other services of the supervisor are left to be
+// stopped with it.
+func (m *shutdownManager) stop() []shutdownOverrun {
+	m.mut.Lock()
+	stages := m.stages
+	m.stages = [numShutdownStages][]shutdownService{}
+	m.mut.Unlock()
+
+	var overruns []shutdownOverrun
+	var overrunsMut sync.Mutex
+	t0 := time.Now()
+	for stage, svcs := range stages {
+		stage := shutdownStage(stage)
+		stageStart := time.Now()
+		var wg sync.WaitGroup
+		for _, svc := range svcs {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				start := time.Now()
+				err := m.sup.RemoveAndWait(svc.token, svc.timeout)
+				switch {
+				case err == nil:
+					slog.Debug("Stopped service", "stage", stage, "service", svc.name, slog.Duration("took", time.Since(start)))
+				case errors.Is(err, suture.ErrTimeout):
+					slog.Warn("Service failed to stop in time; continuing shutdown without it", "stage", stage, "service", svc.name, slog.Duration("timeout", svc.timeout))
+					overrunsMut.Lock()
+					overruns = append(overruns, shutdownOverrun{Stage: stage, Service: svc.name, Timeout: svc.timeout})
+					overrunsMut.Unlock()
+				default:
+					// The supervisor isn't running, so neither is the
+					// service.
+					slog.Debug("Service already stopped", "stage", stage, "service", svc.name, slogutil.Error(err))
+				}
+			}()
+		}
+		wg.Wait()
+		if len(svcs) > 0 {
+			slog.Debug("Completed shutdown stage", "stage", stage, slog.Duration("took", time.Since(stageStart)))
+		}
+	}
+
+	if len(overruns) > 0 {
+		names := make([]string, len(overruns))
+		for i, o := range overruns {
+			names[i] = o.Service
+		}
+		slog.Warn("Shutdown continued past services that failed to stop", slog.Any("services", names), slog.Duration("took", time.Since(t0)))
+	} else {
+		slog.Debug("Stopped services", slog.Duration("took", time.Since(t0)))
+	}
+	return overruns
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package syncthing
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/thejerf/suture/v4"
+
+	"github.com/syncthing/syncthing/lib/svcutil"
+)
+
+func TestShutdownOrder(t *testing.T) {
+	sup := suture.New("test", svcutil.SpecWithDebugLogger())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sup.ServeBackground(ctx)
+	m := newShutdownManager(sup)
+
+	var stoppedMut sync.Mutex
+	var stopped []string
+	started := make(chan struct{}, 3)
+	svc := func(name string, stuck bool) suture.Service {
+		return svcutil.AsService(func(ctx context.Context) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			if stuck {
+				time.Sleep(time.Minute)
+			}
+			stoppedMut.Lock()
+			stopped = append(stopped, name)
+			stoppedMut.Unlock()
+			return nil
+		}, name)
+	}
+
+	// Added in reverse, to be stopped in stage order regardless
+	m.add(shutdownStageDatabase, "db", svc("db", false), time.Second)
+	m.add(shutdownStageFolders, "stuck", svc("stuck", true), 10*time.Millisecond)
+	m.add(shutdownStageAPI, "api", svc("api", false), time.Second)
+	for i := 0; i < 3; i++ {
+		<-started
+	}
+
+	overruns := m.stop()
+	if len(overruns) != 1 || overruns[0].Service != "stuck" || overruns[0].Stage != shutdownStageFolders {
+		t.Errorf("unexpected overruns %+v", overruns)
+	}
+	stoppedMut.Lock()
+	defer stoppedMut.Unlock()
+	if len(stopped) != 2 || stopped[0] != "api" || stopped[1] != "db" {
+		t.Errorf("services stopped in order %v, expected api, db", stopped)
+	}
+}
@@ -0,0 +1,369 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package syncthing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/build"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/dialer"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/locations"
+	"github.com/syncthing/syncthing/lib/model"
+	"github.com/syncthing/syncthing/lib/tlsutil"
+)
+
+const (
+	statsDigestDaily  = "daily"
+	statsDigestWeekly = "weekly"
+
+	// How often device traffic and availability are sampled.
+	statsDigestSampleInterval = 5 * time.Minute
+	statsDigestSendTimeout    = time.Minute
+)
+
+type connectionStatser interface {
+	ConnectionStats() map[string]interface{}
+}
+
+// The statsDigestService aggregates sync statistics per folder and per
+// device over a day or a week, and at the end of each period delivers a
+// digest to a webhook and/or appends it to a local report file.
+type statsDigestService struct {
+	cfg      config.Wrapper
+	evLogger events.Logger
+	conns    connectionStatser
+	changed  chan struct{}
+
+	digest  *statsDigest                     // nil when disabled
+	traffic map[string]model.ConnectionStats // last sample, per device
+}
+
+type statsDigest struct {
+	Period  string                   `json:"period"`
+	From    time.Time                `json:"from"`
+	To      time.Time                `json:"to"`
+	Folders map[string]*folderDigest `json:"folders"`
+	Devices map[string]*deviceDigest `json:"devices"`
+}
+
+type folderDigest struct {
+	Label        string `json:"label"`
+	FilesUpdated int    `json:"filesUpdated"`
+	FilesDeleted int    `json:"filesDeleted"`
+	Failures     int    `json:"failures"`
+	Conflicts    int    `json:"conflicts"`
+}
+
+type deviceDigest struct {
+	Name         string  `json:"name"`
+	InBytes      int64   `json:"inBytes"`
+	OutBytes     int64   `json:"outBytes"`
+	Availability float64 `json:"availability"` // fraction of the period the device was connected
+
+	samples, connectedSamples int
+}
+
+func newStatsDigestService(cfg config.Wrapper, evLogger events.Logger, conns connectionStatser) *statsDigestService {
+	return &statsDigestService{
+		cfg:      cfg,
+		evLogger: evLogger,
+		conns:    conns,
+		changed:  make(chan struct{}, 1), // Buffered to prevent locking
+	}
+}
+
+func (s *statsDigestService) Serve(ctx context.Context) error {
+	cfg := s.cfg.Subscribe(s)
+	defer s.cfg.Unsubscribe(s)
+	opts := cfg.Options
+
+	sub := s.evLogger.Subscribe(events.ItemFinished | events.LocalChangeDetected)
+	defer sub.Unsubscribe()
+
+	sampleTicker := time.NewTicker(statsDigestSampleInterval)
+	defer sampleTicker.Stop()
+
+	periodTimer := s.reset(opts, time.Now())
+	defer periodTimer.Stop()
+
+	for {
+		select {
+		case <-s.changed:
+			newOpts := s.cfg.Options()
+			// A changed period starts over; changed destinations apply
+			// to the current digest.
+			if newOpts.StatsDigestPeriod != opts.StatsDigestPeriod {
+				periodTimer.Stop()
+				periodTimer = s.reset(newOpts, time.Now())
+			}
+			opts = newOpts
+
+		case ev, ok := <-sub.C():
+			if !ok {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			s.handleEvent(ev)
+
+		case <-sampleTicker.C:
+			s.sample(true)
+
+		case <-periodTimer.C:
+			now := time.Now()
+			s.sample(true)
+			s.deliver(ctx, opts, now)
+			periodTimer = s.reset(opts, now)
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// reset starts a new digest period and returns a timer firing at its end.
+// The timer never fires when digests are disabled.
+func (s *statsDigestService) reset(opts config.OptionsConfiguration, now time.Time) *time.Timer {
+	switch opts.StatsDigestPeriod {
+	case statsDigestDaily, statsDigestWeekly:
+	case "":
+		s.digest = nil
+		return stoppedTimer()
+	default:
+		slog.Warn("Unknown sync statistics digest period; digests are disabled", slog.String("period", opts.StatsDigestPeriod))
+		s.digest = nil
+		return stoppedTimer()
+	}
+
+	s.digest = &statsDigest{
+		Period:  opts.StatsDigestPeriod,
+		From:    now.Truncate(time.Second),
+		Folders: make(map[string]*folderDigest),
+		Devices: make(map[string]*deviceDigest),
+	}
+	s.traffic = make(map[string]model.ConnectionStats)
+	s.sample(false)
+	return time.NewTimer(time.Until(nextDigestAt(now, opts.StatsDigestPeriod)))
+}
+
+func stoppedTimer() *time.Timer {
+	t := time.NewTimer(time.Hour)
+	t.Stop()
+	return t
+}
+
+// nextDigestAt returns when the current period ends; daily periods end at
+// midnight and weekly ones at midnight between Sunday and Monday.
+func nextDigestAt(now time.Time, period string) time.Time {
+	y, m, d := now.Date()
+	next := time.Date(y, m, d+1, 0, 0, 0, 0, now.Location())
+	if period == statsDigestWeekly {
+		for next.Weekday() != time.Monday {
+			next = next.AddDate(0, 0, 1)
+		}
+	}
+	return next
+}
+
+func (s *statsDigestService) handleEvent(ev events.Event) {
+	if s.digest == nil {
+		return
+	}
+	switch ev.Type {
+	case events.ItemFinished:
+		data, ok := ev.Data.(map[string]interface{})
+		if !ok {
+			return
+		}
+		folder, _ := data["folder"].(string)
+		item, _ := data["item"].(string)
+		action, _ := data["action"].(string)
+		errStr, _ := data["error"].(*string)
+		fd := s.folder(folder)
+		switch {
+		case errStr != nil:
+			fd.Failures++
+		case action == "delete":
+			fd.FilesDeleted++
+		default:
+			fd.FilesUpdated++
+			if isConflictCopy(item) {
+				fd.Conflicts++
+			}
+		}
+
+	case events.LocalChangeDetected:
+		// Conflict copies we create ourselves are picked up by the
+		// following scan.
+		data, ok := ev.Data.(map[string]string)
+		if !ok {
+			return
+		}
+		if data["action"] != "deleted" && isConflictCopy(data["path"]) {
+			s.folder(data["folder"]).Conflicts++
+		}
+	}
+}
+
+func isConflictCopy(name string) bool {
+	return strings.Contains(filepath.Base(name), ".sync-conflict-")
+}
+
+func (s *statsDigestService) folder(id string) *folderDigest {
+	fd, ok := s.digest.Folders[id]
+	if !ok {
+		fd = &folderDigest{}
+		if fcfg, ok := s.cfg.Folder(id); ok {
+			fd.Label = fcfg.Label
+		}
+		s.digest.Folders[id] = fd
+	}
+	return fd
+}
+
+// sample records device availability and the traffic since the previous
+// sample. With count unset only the traffic baseline is taken.
+func (s *statsDigestService) sample(count bool) {
+	if s.digest == nil {
+		return
+	}
+	conns, _ := s.conns.ConnectionStats()["connections"].(map[string]model.ConnectionStats)
+	myID := s.cfg.MyID()
+	for id, devCfg := range s.cfg.Devices() {
+		if id == myID {
+			continue
+		}
+		key := id.String()
+		cur := conns[key]
+		prev, hadPrev := s.traffic[key]
+		s.traffic[key] = cur
+		if !count {
+			continue
+		}
+
+		dd, ok := s.digest.Devices[key]
+		if !ok {
+			dd = &deviceDigest{}
+			s.digest.Devices[key] = dd
+		}
+		dd.Name = devCfg.Name
+		dd.samples++
+		if !cur.Connected {
+			continue
+		}
+		dd.connectedSamples++
+		if hadPrev && prev.Connected && prev.StartedAt.Equal(cur.StartedAt) && cur.InBytesTotal >= prev.InBytesTotal && cur.OutBytesTotal >= prev.OutBytesTotal {
+			dd.InBytes += cur.InBytesTotal - prev.InBytesTotal
+			dd.OutBytes += cur.OutBytesTotal - prev.OutBytesTotal
+		} else {
+			// A new connection, with counters starting over.
+			dd.InBytes += cur.InBytesTotal
+			dd.OutBytes += cur.OutBytesTotal
+		}
+	}
+}
+
+func (s *statsDigestService) deliver(ctx context.Context, opts config.OptionsConfiguration, now time.Time) {
+	if s.digest == nil {
+		return
+	}
+	s.digest.To = now.Truncate(time.Second)
+	for _, dd := range s.digest.Devices {
+		if dd.samples > 0 {
+			dd.Availability = float64(dd.connectedSamples) / float64(dd.samples)
+		}
+	}
+
+	bs, err := json.Marshal(s.digest)
+	if err != nil {
+		slog.Warn("Failed to encode sync statistics digest", slogutil.Error(err))
+		return
+	}
+
+	if opts.StatsDigestFile != "" {
+		path := opts.StatsDigestFile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(locations.GetBaseDir(locations.ConfigBaseDir), path)
+		}
+		if err := appendLine(path, bs); err != nil {
+			slog.Warn("Failed to write sync statistics digest", slogutil.FilePath(path), slogutil.Error(err))
+		}
+	}
+	if opts.StatsDigestWebhookURL != "" {
+		if err := postDigest(ctx, opts.StatsDigestWebhookURL, bs); err != nil {
+			slog.Warn("Failed to send sync statistics digest", slogutil.URI(opts.StatsDigestWebhookURL), slogutil.Error(err))
+		}
+	}
+	slog.Info("Sync statistics digest completed", slog.String("period", s.digest.Period), slog.Int("folders", len(s.digest.Folders)), slog.Int("devices", len(s.digest.Devices)))
+}
+
+func appendLine(path string, bs []byte) error {
+	fd, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := fd.Write(append(bs, '\n')); err != nil {
+		fd.Close()
+		return err
+	}
+	return fd.Close()
+}
+
+func postDigest(ctx context.Context, url string, bs []byte) error {
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext:     dialer.DialContext,
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsutil.SecureDefaultWithTLS12(),
+		},
+		Timeout: statsDigestSendTimeout,
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(bs))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Syncthing/"+build.LongVersion)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *statsDigestService) CommitConfiguration(from, to config.Configuration) bool {
+	if from.Options.StatsDigestPeriod != to.Options.StatsDigestPeriod ||
+		from.Options.StatsDigestWebhookURL != to.Options.StatsDigestWebhookURL ||
+		from.Options.StatsDigestFile != to.Options.StatsDigestFile {
+		select {
+		case s.changed <- struct{}{}:
+		default:
+			// s.changed is one buffered, so even though nothing was
+			// sent, the new options will still be picked up.
+		}
+	}
+	return true
+}
+
+func (s *statsDigestService) String() string {
+	return fmt.Sprintf("statsDigestService@%p", s)
+}
@@ -0,0 +1,99 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package syncthing
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/model"
+	"github.com/syncthing/syncthing/lib/protocol"
+)
+
+type fakeConnectionStatser map[string]model.ConnectionStats
+
+func (f fakeConnectionStatser) ConnectionStats() map[string]interface{} {
+	return map[string]interface{}{"connections": map[string]model.ConnectionStats(f)}
+}
+
+func TestStatsDigest(t *testing.T) {
+	remote := protocol.DeviceID{1, 2, 3}
+	cfg := config.Wrap(tempCfgFilename(t), config.Configuration{
+		Devices: []config.DeviceConfiguration{{DeviceID: remote, Name: "remote"}},
+		Folders: []config.FolderConfiguration{{ID: "f", Label: "Folder"}},
+	}, protocol.LocalDeviceID, events.NoopLogger)
+	defer os.Remove(cfg.ConfigPath())
+
+	started := time.Now().Add(-time.Hour)
+	conns := fakeConnectionStatser{remote.String(): {
+		Connected:  true,
+		Statistics: protocol.Statistics{InBytesTotal: 1000, OutBytesTotal: 100, StartedAt: started},
+	}}
+	s := newStatsDigestService(cfg, events.NoopLogger, conns)
+	s.reset(config.OptionsConfiguration{StatsDigestPeriod: statsDigestDaily}, time.Now()).Stop()
+
+	// Traffic before the period began doesn't count.
+	conns[remote.String()] = model.ConnectionStats{
+		Connected:  true,
+		Statistics: protocol.Statistics{InBytesTotal: 1500, OutBytesTotal: 300, StartedAt: started},
+	}
+	s.sample(true)
+	conns[remote.String()] = model.ConnectionStats{}
+	s.sample(true)
+
+	failure := errors.New("boom")
+	for _, data := range []map[string]interface{}{
+		{"folder": "f", "item": "a", "action": "update", "error": events.Error(nil)},
+		{"folder": "f", "item": "b", "action": "delete", "error": events.Error(nil)},
+		{"folder": "f", "item": "c", "action": "update", "error": events.Error(failure)},
+		{"folder": "f", "item": "d.sync-conflict-20250101-120000-ABCDEFG", "action": "update", "error": events.Error(nil)},
+	} {
+		s.handleEvent(events.Event{Type: events.ItemFinished, Data: data})
+	}
+	s.handleEvent(events.Event{Type: events.LocalChangeDetected, Data: map[string]string{
+		"folder": "f", "action": "added", "path": "e.sync-conflict-20250101-120000-ABCDEFG.txt",
+	}})
+
+	s.deliver(t.Context(), config.OptionsConfiguration{}, time.Now())
+
+	fd := s.digest.Folders["f"]
+	if fd.Label != "Folder" || fd.FilesUpdated != 2 || fd.FilesDeleted != 1 || fd.Failures != 1 || fd.Conflicts != 2 {
+		t.Errorf("unexpected folder digest %+v", fd)
+	}
+	dd := s.digest.Devices[remote.String()]
+	if dd.Name != "remote" || dd.InBytes != 500 || dd.OutBytes != 200 || dd.Availability != 0.5 {
+		t.Errorf("unexpected device digest %+v", dd)
+	}
+}
+
+func TestNextDigestAt(t *testing.T) {
+	// A Wednesday afternoon
+	now := time.Date(2025, 3, 12, 15, 4, 5, 0, time.UTC)
+	if next := nextDigestAt(now, statsDigestDaily); !next.Equal(time.Date(2025, 3, 13, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected daily digest time %v", next)
+	}
+	if next := nextDigestAt(now, statsDigestWeekly); !next.Equal(time.Date(2025, 3, 17, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected weekly digest time %v", next)
+	}
+}
+
+func TestStatsDigestCommitDoesNotBlock(t *testing.T) {
+	s := newStatsDigestService(nil, events.NoopLogger, fakeConnectionStatser{})
+	from := config.Configuration{}
+	to := config.Configuration{}
+	to.Options.StatsDigestPeriod = "daily"
+	// Nothing is serving, as when the service was stopped.
+	for range 3 {
+		if !s.CommitConfiguration(from, to) {
+			t.Fatal("unexpected refusal")
+		}
+	}
+}
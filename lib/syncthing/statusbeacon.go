@@ -0,0 +1,301 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package syncthing
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/build"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/model"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/rand"
+)
+
+const (
+	// Failed sends are retried after twice the previous delay, starting at
+	// the interval, up to this.
+	statusBeaconMaxBackoff = 6 * time.Hour
+	// The first beacon is sent after a random delay up to this, so that a
+	// fleet restarted together doesn't report all at once.
+	statusBeaconMaxInitialDelay = time.Minute
+)
+
+type statusBeaconModel interface {
+	State(folder string) (string, time.Time, error)
+	FolderErrors(folder string) ([]model.FileError, error)
+	Completion(device protocol.DeviceID, folder string) (model.FolderCompletion, error)
+	ConnectedTo(remoteID protocol.DeviceID) bool
+}
+
+// The statusBeaconService periodically posts a short health summary to a
+// collection endpoint, so that many headless devices can be monitored in
+// one place.
+type statusBeaconService struct {
+	cfg       config.Wrapper
+	model     statusBeaconModel
+	startTime time.Time
+	changed   chan struct{}
+
+	certMut sync.Mutex
+	cert    tls.Certificate
+}
+
+// The statusBeaconEnvelope is what is posted. The signature is made with
+// the device certificate key over the report bytes (Ed25519) or their
+// SHA-256 (older ECDSA and RSA keys); the collector verifies it using the
+// certificate, whose SHA-256 is the device ID.
+type statusBeaconEnvelope struct {
+	Report      json.RawMessage `json:"report"`
+	Certificate []byte          `json:"certificate"`
+	Signature   []byte          `json:"signature"`
+}
+
+type statusBeacon struct {
+	DeviceID         string                        `json:"deviceID"`
+	DeviceName       string                        `json:"deviceName,omitempty"`
+	Version          string                        `json:"version"`
+	Time             time.Time                     `json:"time"`
+	UptimeS          int                           `json:"uptimeS"`
+	DevicesTotal     int                           `json:"devicesTotal"`
+	DevicesConnected int                           `json:"devicesConnected"`
+	FoldersTotal     int                           `json:"foldersTotal"`
+	FoldersInSync    int                           `json:"foldersInSync"`
+	FoldersPaused    int                           `json:"foldersPaused"`
+	CompletionPct    float64                       `json:"completionPct"`
+	Errors           int                           `json:"errors"`
+	Folders          map[string]statusBeaconFolder `json:"folders,omitempty"`
+}
+
+type statusBeaconFolder struct {
+	Label         string  `json:"label,omitempty"`
+	State         string  `json:"state"`
+	CompletionPct float64 `json:"completionPct"`
+	NeedBytes     int64   `json:"needBytes"`
+	Errors        int     `json:"errors"`
+}
+
+func newStatusBeaconService(cfg config.Wrapper, m statusBeaconModel, cert tls.Certificate) *statusBeaconService {
+	return &statusBeaconService{
+		cfg:       cfg,
+		model:     m,
+		cert:      cert,
+		startTime: time.Now(),
+		changed:   make(chan struct{}, 1), // Buffered to prevent locking
+	}
+}
+
+func (s *statusBeaconService) Serve(ctx context.Context) error {
+	cfg := s.cfg.Subscribe(s)
+	defer s.cfg.Unsubscribe(s)
+	opts := cfg.Options
+
+	timer := time.NewTimer(time.Duration(rand.Int63() % int64(statusBeaconMaxInitialDelay)))
+	defer timer.Stop()
+	failures := 0
+
+	for {
+		select {
+		case <-s.changed:
+			opts = s.cfg.Options()
+			// Report promptly with the new settings.
+			failures = 0
+			timer.Reset(time.Second)
+
+		case <-timer.C:
+			if opts.StatusBeaconURL == "" {
+				timer.Reset(time.Duration(opts.StatusBeaconIntervalS) * time.Second)
+				continue
+			}
+			if err := s.send(ctx, opts); err != nil {
+				failures++
+				if failures == 1 {
+					slog.Warn("Failed to send status beacon", slogutil.URI(opts.StatusBeaconURL), slogutil.Error(err))
+				} else {
+					slog.Debug("Failed to send status beacon", slogutil.URI(opts.StatusBeaconURL), slog.Int("failures", failures), slogutil.Error(err))
+				}
+			} else {
+				if failures > 0 {
+					slog.Info("Status beacon delivered again", slogutil.URI(opts.StatusBeaconURL))
+				}
+				failures = 0
+			}
+			timer.Reset(statusBeaconDelay(opts, failures))
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// statusBeaconDelay returns the time until the next beacon, backing off
+// exponentially after consecutive failures.
+func statusBeaconDelay(opts config.OptionsConfiguration, failures int) time.Duration {
+	interval := time.Duration(opts.StatusBeaconIntervalS) * time.Second
+	delay := interval
+	for range failures {
+		delay *= 2
+		if delay >= statusBeaconMaxBackoff {
+			return max(statusBeaconMaxBackoff, interval)
+		}
+	}
+	return delay
+}
+
+func (s *statusBeaconService) send(ctx context.Context, opts config.OptionsConfiguration) error {
+	report, err := json.Marshal(s.collect(opts.StatusBeaconDetail, time.Now()))
+	if err != nil {
+		return err
+	}
+	env, err := s.sign(report)
+	if err != nil {
+		return err
+	}
+	bs, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+	// Same delivery as for the statistics digest.
+	return postDigest(ctx, opts.StatusBeaconURL, bs)
+}
+
+// collect gathers the beacon contents, with more or less detail as the
+// privacy setting allows.
+func (s *statusBeaconService) collect(detail string, now time.Time) statusBeacon {
+	myID := s.cfg.MyID()
+	b := statusBeacon{
+		DeviceID: myID.String(),
+		Version:  build.Version,
+		Time:     now.Truncate(time.Second),
+		UptimeS:  int(now.Sub(s.startTime).Seconds()),
+	}
+	if detail == config.StatusBeaconDetailFull {
+		if dev, ok := s.cfg.Device(myID); ok {
+			b.DeviceName = dev.Name
+		}
+	}
+
+	for id := range s.cfg.Devices() {
+		if id == myID {
+			continue
+		}
+		b.DevicesTotal++
+		if s.model.ConnectedTo(id) {
+			b.DevicesConnected++
+		}
+	}
+
+	var globalBytes, needBytes int64
+	for id, fcfg := range s.cfg.Folders() {
+		b.FoldersTotal++
+		if fcfg.Paused {
+			b.FoldersPaused++
+			continue
+		}
+		state, _, err := s.model.State(id)
+		if err != nil {
+			state = "error"
+		}
+		comp, err := s.model.Completion(protocol.LocalDeviceID, id)
+		if err != nil {
+			continue
+		}
+		ferrs, _ := s.model.FolderErrors(id)
+
+		if comp.NeedBytes == 0 && comp.NeedItems == 0 && comp.NeedDeletes == 0 {
+			b.FoldersInSync++
+		}
+		b.Errors += len(ferrs)
+		globalBytes += comp.GlobalBytes
+		needBytes += comp.NeedBytes
+
+		if detail == config.StatusBeaconDetailFolders || detail == config.StatusBeaconDetailFull {
+			if b.Folders == nil {
+				b.Folders = make(map[string]statusBeaconFolder)
+			}
+			f := statusBeaconFolder{
+				State:         state,
+				CompletionPct: comp.CompletionPct,
+				NeedBytes:     comp.NeedBytes,
+				Errors:        len(ferrs),
+			}
+			if detail == config.StatusBeaconDetailFull {
+				f.Label = fcfg.Label
+			}
+			b.Folders[id] = f
+		}
+	}
+
+	b.CompletionPct = 100
+	if globalBytes > 0 {
+		b.CompletionPct = 100 * (1 - float64(needBytes)/float64(globalBytes))
+	}
+	return b
+}
+
+// SetCertificate makes the certificate the one beacons are signed with,
+// after it was reloaded or rotated.
+func (s *statusBeaconService) SetCertificate(cert tls.Certificate) {
+	s.certMut.Lock()
+	s.cert = cert
+	s.certMut.Unlock()
+}
+
+func (s *statusBeaconService) sign(report []byte) (statusBeaconEnvelope, error) {
+	s.certMut.Lock()
+	cert := s.cert
+	s.certMut.Unlock()
+	signer, ok := cert.PrivateKey.(crypto.Signer)
+	if !ok || len(cert.Certificate) == 0 {
+		return statusBeaconEnvelope{}, errors.New("certificate key cannot sign")
+	}
+	var sig []byte
+	var err error
+	if _, ok := signer.Public().(ed25519.PublicKey); ok {
+		sig, err = signer.Sign(rand.Reader, report, crypto.Hash(0))
+	} else {
+		hash := sha256.Sum256(report)
+		sig, err = signer.Sign(rand.Reader, hash[:], crypto.SHA256)
+	}
+	if err != nil {
+		return statusBeaconEnvelope{}, err
+	}
+	return statusBeaconEnvelope{
+		Report:      report,
+		Certificate: cert.Certificate[0],
+		Signature:   sig,
+	}, nil
+}
+
+func (s *statusBeaconService) CommitConfiguration(from, to config.Configuration) bool {
+	if from.Options.StatusBeaconURL != to.Options.StatusBeaconURL ||
+		from.Options.StatusBeaconIntervalS != to.Options.StatusBeaconIntervalS ||
+		from.Options.StatusBeaconDetail != to.Options.StatusBeaconDetail {
+		select {
+		case s.changed <- struct{}{}:
+		default:
+			// s.changed is one buffered, so even though nothing was
+			// sent, the new options will still be picked up.
+		}
+	}
+	return true
+}
+
+func (s *statusBeaconService) String() string {
+	return fmt.Sprintf("statusBeaconService@%p", s)
+}
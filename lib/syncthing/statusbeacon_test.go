@@ -0,0 +1,159 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package syncthing
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/events"
+	"github.com/syncthing/syncthing/lib/model"
+	"github.com/syncthing/syncthing/lib/protocol"
+	"github.com/syncthing/syncthing/lib/tlsutil"
+)
+
+type fakeStatusBeaconModel struct {
+	completions map[string]model.FolderCompletion
+	errors      map[string]int
+	connected   map[protocol.DeviceID]bool
+}
+
+func (*fakeStatusBeaconModel) State(string) (string, time.Time, error) {
+	return "idle", time.Time{}, nil
+}
+
+func (f *fakeStatusBeaconModel) FolderErrors(folder string) ([]model.FileError, error) {
+	return make([]model.FileError, f.errors[folder]), nil
+}
+
+func (f *fakeStatusBeaconModel) Completion(_ protocol.DeviceID, folder string) (model.FolderCompletion, error) {
+	return f.completions[folder], nil
+}
+
+func (f *fakeStatusBeaconModel) ConnectedTo(id protocol.DeviceID) bool {
+	return f.connected[id]
+}
+
+func TestStatusBeaconCollect(t *testing.T) {
+	remote1, remote2 := protocol.DeviceID{1}, protocol.DeviceID{2}
+	cfg := config.Wrap(tempCfgFilename(t), config.Configuration{
+		Devices: []config.DeviceConfiguration{
+			{DeviceID: protocol.LocalDeviceID, Name: "me"},
+			{DeviceID: remote1},
+			{DeviceID: remote2},
+		},
+		Folders: []config.FolderConfiguration{
+			{ID: "a", Label: "Secret A"},
+			{ID: "b", Label: "Secret B"},
+			{ID: "c", Paused: true},
+		},
+	}, protocol.LocalDeviceID, events.NoopLogger)
+	defer os.Remove(cfg.ConfigPath())
+
+	m := &fakeStatusBeaconModel{
+		completions: map[string]model.FolderCompletion{
+			"a": {CompletionPct: 100, GlobalBytes: 300},
+			"b": {CompletionPct: 0, GlobalBytes: 100, NeedBytes: 100, NeedItems: 1},
+		},
+		errors:    map[string]int{"b": 3},
+		connected: map[protocol.DeviceID]bool{remote1: true},
+	}
+	s := newStatusBeaconService(cfg, m, tlsCertificate(t))
+
+	b := s.collect(config.StatusBeaconDetailSummary, time.Now())
+	if b.DevicesTotal != 2 || b.DevicesConnected != 1 {
+		t.Errorf("unexpected devices %d/%d", b.DevicesConnected, b.DevicesTotal)
+	}
+	if b.FoldersTotal != 3 || b.FoldersInSync != 1 || b.FoldersPaused != 1 || b.Errors != 3 {
+		t.Errorf("unexpected folders %+v", b)
+	}
+	if b.CompletionPct != 75 {
+		t.Errorf("unexpected completion %v", b.CompletionPct)
+	}
+	if b.DeviceName != "" || b.Folders != nil {
+		t.Error("summary should not include names or folders")
+	}
+
+	b = s.collect(config.StatusBeaconDetailFolders, time.Now())
+	if len(b.Folders) != 2 || b.Folders["b"].Errors != 3 || b.Folders["a"].Label != "" || b.DeviceName != "" {
+		t.Errorf("unexpected folders %+v", b.Folders)
+	}
+
+	b = s.collect(config.StatusBeaconDetailFull, time.Now())
+	if b.Folders["a"].Label != "Secret A" || b.DeviceName != "me" {
+		t.Errorf("expected labels and name, got %+v", b)
+	}
+}
+
+func TestStatusBeaconSignature(t *testing.T) {
+	s := &statusBeaconService{cert: tlsCertificate(t)}
+	report := []byte(`{"deviceID":"x"}`)
+	env, err := s.sign(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Round trip like the collector would see it
+	bs, err := json.Marshal(env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got statusBeaconEnvelope
+	if err := json.Unmarshal(bs, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	cert, err := x509.ParseCertificate(got.Certificate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if protocol.NewDeviceID(got.Certificate) != protocol.NewDeviceID(s.cert.Certificate[0]) {
+		t.Error("certificate does not match the device ID")
+	}
+	if !ed25519.Verify(cert.PublicKey.(ed25519.PublicKey), got.Report, got.Signature) {
+		t.Error("signature does not verify")
+	}
+
+	// A replaced certificate is used from then on.
+	replaced := tlsCertificate(t)
+	s.SetCertificate(replaced)
+	env, err = s.sign(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(env.Certificate, replaced.Certificate[0]) {
+		t.Error("expected the replaced certificate")
+	}
+}
+
+func TestStatusBeaconDelay(t *testing.T) {
+	opts := config.OptionsConfiguration{StatusBeaconIntervalS: 300}
+	for failures, exp := range []time.Duration{5 * time.Minute, 10 * time.Minute, 20 * time.Minute} {
+		if d := statusBeaconDelay(opts, failures); d != exp {
+			t.Errorf("%d failures: got %v, expected %v", failures, d, exp)
+		}
+	}
+	if d := statusBeaconDelay(opts, 100); d != statusBeaconMaxBackoff {
+		t.Errorf("expected max backoff, got %v", d)
+	}
+}
+
+func tlsCertificate(t *testing.T) tls.Certificate {
+	t.Helper()
+	cert, err := tlsutil.NewCertificateInMemory("syncthing", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
@@ -26,7 +26,9 @@ import (
 	"github.com/syncthing/syncthing/internal/db"
 	"github.com/syncthing/syncthing/internal/slogutil"
 	"github.com/syncthing/syncthing/lib/api"
+	"github.com/syncthing/syncthing/lib/backup"
 	"github.com/syncthing/syncthing/lib/build"
+	"github.com/syncthing/syncthing/lib/certmanager"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/connections"
 	"github.com/syncthing/syncthing/lib/connections/registry"
@@ -35,6 +37,7 @@ import (
 	"github.com/syncthing/syncthing/lib/locations"
 	"github.com/syncthing/syncthing/lib/model"
 	"github.com/syncthing/syncthing/lib/osutil"
+	"github.com/syncthing/syncthing/lib/profile"
 	"github.com/syncthing/syncthing/lib/protocol"
 	"github.com/syncthing/syncthing/lib/svcutil"
 	"github.com/syncthing/syncthing/lib/tlsutil"
@@ -60,6 +63,7 @@ type Options struct {
 type App struct {
 	myID              protocol.DeviceID
 	mainService       *suture.Supervisor
+	shutdown          *shutdownManager
 	cfg               config.Wrapper
 	sdb               db.DB
 	evLogger          events.Logger
@@ -96,6 +100,7 @@ func (a *App) Start() error {
 	// We want any logging it does to go through our log system.
 	spec := svcutil.SpecWithDebugLogger()
 	a.mainService = suture.New("main", spec)
+	a.shutdown = newShutdownManager(a.mainService)
 
 	// Start the supervisor and wait for it to stop to handle cleanup.
 	a.stopped = make(chan struct{})
@@ -115,7 +120,7 @@ func (a *App) Start() error {
 func (a *App) startup() error {
 	a.mainService.Add(ur.NewFailureHandler(a.cfg, a.evLogger))
 
-	a.mainService.Add(a.sdb.Service(a.opts.DBMaintenanceInterval))
+	a.shutdown.add(shutdownStageDatabase, "database", a.sdb.Service(a.opts.DBMaintenanceInterval), shutdownTimeoutDatabase)
 
 	if a.opts.AuditWriter != nil {
 		a.mainService.Add(newAuditService(a.opts.AuditWriter, a.evLogger))
@@ -178,15 +183,17 @@ func (a *App) startup() error {
 		locations.Get(locations.KeyFile),
 	}
 
-	// Remove database entries for folders that no longer exist in the config
+	// Remove database entries for folders that no longer exist in the
+	// config, except those kept on standby for a re-add
 	cfgFolders := a.cfg.Folders()
 	dbFolders, err := a.sdb.ListFolders()
 	if err != nil {
 		slog.Warn("Failed to list folders", slogutil.Error(err))
 		return err
 	}
+	model.ExpiredStandbyIndexes(a.sdb)
 	for _, folder := range dbFolders {
-		if _, ok := cfgFolders[folder]; !ok {
+		if _, ok := cfgFolders[folder]; !ok && !model.IsFolderOnStandby(a.sdb, folder) {
 			slog.Info("Cleaning metadata for dropped folder", "folder", folder)
 			a.sdb.DropFolder(folder)
 		}
@@ -260,6 +267,11 @@ func (a *App) startup() error {
 
 	// Start discovery and connection management
 
+	// Discovery and dialing back off according to the configured retry
+	// policies, which apply as soon as they're changed.
+	configureRetryPolicies(a.cfg.Options())
+	a.cfg.Subscribe(retryPolicies{})
+
 	// Chicken and egg, discovery manager depends on connection service to tell it what addresses it's listening on
 	// Connection service depends on discovery manager to get addresses to connect to.
 	// Create a wrapper that is then wired after they are both set up.
@@ -276,19 +288,21 @@ func (a *App) startup() error {
 	m := model.NewModel(a.cfg, a.myID, a.sdb, protectedFiles, a.evLogger, keyGen, discoveryManager)
 	// Pass both protocol names to support v1 and v2 devices
 	connectionsService = connections.NewService(a.cfg, a.myID, m, tlsCfg, discoveryManager, bepProtocolName, tlsDefaultCommonName, a.evLogger, connRegistry, keyGen)
+	// Keep what's learned about connection stability across restarts
+	connectionsService.SetDatabase(a.sdb)
 	// Now we can properly set the connections service in the discovery manager
 	discoveryManager.SetConnectionsService(connectionsService)
 	a.Internals = newInternals(m)
 
-	a.mainService.Add(m)
+	a.shutdown.add(shutdownStageFolders, "model", m, shutdownTimeoutFolders)
 
 	// Set the connections service in the model to enable multipath functionality
 	m.SetConnectionsService(connectionsService)
 
 	addrLister.AddressLister = connectionsService
 
-	a.mainService.Add(discoveryManager)
-	a.mainService.Add(connectionsService)
+	a.shutdown.add(shutdownStageNetwork, "discovery", discoveryManager, shutdownTimeoutNetwork)
+	a.shutdown.add(shutdownStageNetwork, "connections", connectionsService, shutdownTimeoutNetwork)
 
 	a.cfg.Modify(func(cfg *config.Configuration) {
 		// Candidate builds always run with usage reporting.
@@ -302,11 +316,41 @@ func (a *App) startup() error {
 	})
 
 	usageReportingSvc := ur.New(a.cfg, m, connectionsService, a.opts.NoUpgrade)
-	a.mainService.Add(usageReportingSvc)
+	a.shutdown.add(shutdownStageAPI, "usage reporting", usageReportingSvc, shutdownTimeoutAPI)
+
+	a.mainService.Add(newStatsDigestService(a.cfg, a.evLogger, m))
+	statusBeacon := newStatusBeaconService(a.cfg, m, a.cert)
+	a.mainService.Add(statusBeacon)
+	a.mainService.Add(newSecurityWebhookService(a.cfg, a.evLogger))
+
+	// Take on a replaced device certificate without restarting, as long as
+	// the device ID stays the same. A certificate for a new device ID
+	// means a new identity all over, which takes a restart.
+	a.mainService.Add(certmanager.NewReloader(locations.Get(locations.CertFile), locations.Get(locations.KeyFile), a.cert, func(cert tls.Certificate) error {
+		err := connectionsService.SetCertificate(cert)
+		if errors.Is(err, connections.ErrCertificateIdentity) {
+			slog.Warn("Device certificate replaced by one for another device ID, restarting", slogutil.Error(err))
+			go a.Stop(svcutil.ExitRestart)
+			return nil
+		}
+		if err == nil {
+			statusBeacon.SetCertificate(cert)
+		}
+		return err
+	}))
+
+	// Rotating the certificate writes new files, which the reloader above
+	// then takes on.
+	if days := a.cfg.Options().CertificateRotationDays; days > 0 {
+		a.mainService.Add(certmanager.NewRotator(locations.Get(locations.CertFile), locations.Get(locations.KeyFile), tlsDefaultCommonName, deviceCertLifetimeDays, a.cert, time.Duration(days)*24*time.Hour))
+	}
 
 	// GUI
 
-	if err := a.setupGUI(m, defaultSub, diskSub, discoveryManager, connectionsService, usageReportingSvc, slogutil.ErrorRecorder, slogutil.GlobalRecorder, miscDB); err != nil {
+	profiles := profile.NewService(a.cfg, a.evLogger)
+	a.mainService.Add(profiles)
+
+	if err := a.setupGUI(m, defaultSub, diskSub, discoveryManager, connectionsService, usageReportingSvc, slogutil.ErrorRecorder, slogutil.GlobalRecorder, miscDB, profiles); err != nil {
 		slog.Error("Failed to start API", slogutil.Error(err))
 		return err
 	}
@@ -402,13 +446,14 @@ func (a *App) stopWithErr(stopReason svcutil.ExitStatus, err error) svcutil.Exit
 			slog.Debug("Services before stop:")
 			printServiceTree(os.Stdout, a.mainService, 0)
 		}
+		a.shutdown.stop()
 		a.mainServiceCancel()
 	})
 	<-a.stopped
 	return a.exitStatus
 }
 
-func (a *App) setupGUI(m model.Model, defaultSub, diskSub events.BufferedSubscription, discoverer discover.Manager, connectionsService connections.Service, urService *ur.Service, errors, systemLog slogutil.Recorder, miscDB *db.Typed) error {
+func (a *App) setupGUI(m model.Model, defaultSub, diskSub events.BufferedSubscription, discoverer discover.Manager, connectionsService connections.Service, urService *ur.Service, errors, systemLog slogutil.Recorder, miscDB *db.Typed, profiles *profile.Service) error {
 	guiCfg := a.cfg.GUI()
 
 	if !guiCfg.Enabled {
@@ -422,8 +467,13 @@ func (a *App) setupGUI(m model.Model, defaultSub, diskSub events.BufferedSubscri
 	summaryService := model.NewFolderSummaryService(a.cfg, m, a.myID, a.evLogger)
 	a.mainService.Add(summaryService)
 
-	apiSvc := api.New(a.myID, a.cfg, locations.Get(locations.GUIAssets), tlsDefaultCommonName, m, defaultSub, diskSub, a.evLogger, discoverer, connectionsService, urService, summaryService, errors, systemLog, a.opts.NoUpgrade, miscDB)
-	a.mainService.Add(apiSvc)
+	var backups *backup.Service
+	if snap, ok := a.sdb.(backup.Snapshotter); ok {
+		backups = backup.NewService(snap, locations.Get(locations.BackupDir))
+	}
+
+	apiSvc := api.New(a.myID, a.cfg, locations.Get(locations.GUIAssets), tlsDefaultCommonName, m, defaultSub, diskSub, a.evLogger, discoverer, connectionsService, urService, summaryService, errors, systemLog, a.opts.NoUpgrade, miscDB, backups, profiles)
+	a.shutdown.add(shutdownStageAPI, "api", apiSvc, shutdownTimeoutAPI)
 
 	if err := apiSvc.WaitForStart(); err != nil {
 		return err
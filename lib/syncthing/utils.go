@@ -145,8 +145,8 @@ func copyFile(src, dst string) error {
 }
 
 // Opens a database
-func OpenDatabase(path string, deleteRetention time.Duration) (db.DB, error) {
-	sql, err := sqlite.Open(path, sqlite.WithDeleteRetention(deleteRetention))
+func OpenDatabase(path string, deleteRetention time.Duration, bloomFilterMemory int64) (db.DB, error) {
+	sql, err := sqlite.Open(path, sqlite.WithDeleteRetention(deleteRetention), sqlite.WithBloomFilterMemory(bloomFilterMemory))
 	if err != nil {
 		return nil, err
 	}
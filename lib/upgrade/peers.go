@@ -0,0 +1,61 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package upgrade
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A Peer is a device we sync with, by the client it last connected with.
+type Peer struct {
+	DeviceID      string `json:"deviceID"`
+	ClientName    string `json:"clientName"`
+	ClientVersion string `json:"clientVersion"`
+	// Why the peer is incompatible with a release
+	Reason string `json:"reason,omitempty"`
+}
+
+// PeerFromClient returns the peer for a client description as kept in the
+// device statistics, i.e. the client name and version followed by its
+// capabilities, if there is one.
+func PeerFromClient(deviceID, client string) (Peer, bool) {
+	fields := strings.Fields(client)
+	if len(fields) < 2 {
+		return Peer{}, false
+	}
+	return Peer{DeviceID: deviceID, ClientName: fields[0], ClientVersion: fields[1]}, true
+}
+
+// IncompatiblePeers returns the peers the release would not be able to sync
+// with, should they not upgrade as well: those on an older major version,
+// as the protocol is only kept compatible within one, and those older than
+// the oldest peer version the release states it supports. Peers running
+// other clients than Syncthing are not judged.
+func IncompatiblePeers(rel Release, peers []Peer) []Peer {
+	var minPeerVersion string
+	if rel.Compatibility != nil {
+		minPeerVersion = rel.Compatibility.MinPeerVersion
+	}
+
+	var incompatible []Peer
+	for _, peer := range peers {
+		if peer.ClientName != "syncthing" {
+			continue
+		}
+		switch {
+		case CompareVersions(peer.ClientVersion, rel.Tag) == MajorOlder:
+			peer.Reason = fmt.Sprintf("%s is a major version behind %s", peer.ClientVersion, rel.Tag)
+		case minPeerVersion != "" && CompareVersions(peer.ClientVersion, minPeerVersion) < Equal:
+			peer.Reason = fmt.Sprintf("%s is older than %s, the oldest version %s syncs with", peer.ClientVersion, minPeerVersion, rel.Tag)
+		default:
+			continue
+		}
+		incompatible = append(incompatible, peer)
+	}
+	return incompatible
+}
@@ -0,0 +1,42 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package upgrade
+
+import (
+	"testing"
+)
+
+func TestPeerFromClient(t *testing.T) {
+	peer, ok := PeerFromClient("device", "syncthing v1.27.0 (lz4, zstd)")
+	if !ok || peer.ClientName != "syncthing" || peer.ClientVersion != "v1.27.0" || peer.DeviceID != "device" {
+		t.Errorf("unexpected peer %+v", peer)
+	}
+	if _, ok := PeerFromClient("device", ""); ok {
+		t.Error("an unknown client should not make a peer")
+	}
+}
+
+func TestIncompatiblePeers(t *testing.T) {
+	peers := []Peer{
+		{DeviceID: "same", ClientName: "syncthing", ClientVersion: "v2.0.3"},
+		{DeviceID: "old-major", ClientName: "syncthing", ClientVersion: "v1.29.7"},
+		{DeviceID: "old-minor", ClientName: "syncthing", ClientVersion: "v2.0.0"},
+		{DeviceID: "other", ClientName: "other", ClientVersion: "v0.1.0"},
+	}
+	rel := Release{Tag: "v2.1.0"}
+
+	incompatible := IncompatiblePeers(rel, peers)
+	if len(incompatible) != 1 || incompatible[0].DeviceID != "old-major" || incompatible[0].Reason == "" {
+		t.Errorf("expected only the peer on the older major version, got %+v", incompatible)
+	}
+
+	rel.Compatibility = &ReleaseCompatibility{MinPeerVersion: "v2.0.1"}
+	incompatible = IncompatiblePeers(rel, peers)
+	if len(incompatible) != 2 || incompatible[0].DeviceID != "old-major" || incompatible[1].DeviceID != "old-minor" {
+		t.Errorf("expected the peers older than the minimum version, got %+v", incompatible)
+	}
+}
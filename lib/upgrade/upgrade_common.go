@@ -46,6 +46,9 @@ type Asset struct {
 type ReleaseCompatibility struct {
 	Runtime      string            `json:"runtime,omitempty"`
 	Requirements map[string]string `json:"requirements,omitempty"`
+	// The oldest version of Syncthing the release can sync with, if
+	// newer than the major version alone implies.
+	MinPeerVersion string `json:"minPeerVersion,omitempty"`
 }
 
 var (
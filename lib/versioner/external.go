@@ -12,12 +12,15 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/syncthing/syncthing/lib/build"
 	"github.com/syncthing/syncthing/lib/config"
 	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/semaphore"
 
 	"github.com/kballard/go-shellquote"
 )
@@ -27,38 +30,98 @@ func init() {
 	factories["external"] = newExternal
 }
 
+const (
+	// How long to wait for the output of a command that was killed or
+	// exited while its children still hold on to its output.
+	externalWaitDelay = 5 * time.Second
+	// How much of the output of a failed command is kept for the error.
+	externalMaxOutput = 1024
+)
+
+// The environment variables passed to commands with a clean environment.
+var externalCleanEnv = []string{"PATH", "HOME", "USER", "LOGNAME", "LANG", "LC_ALL", "TMPDIR", "TEMP", "TMP", "SYSTEMROOT", "COMSPEC", "PATHEXT", "USERPROFILE"}
+
 type external struct {
 	command    string
 	filesystem fs.Filesystem
+	folderID   string
+	timeout    time.Duration        // zero for none
+	limiter    *semaphore.Semaphore // nil for no limit on concurrent commands
+	cleanEnv   bool                 // pass only the variables in externalCleanEnv
 }
 
 func newExternal(cfg config.FolderConfiguration) Versioner {
-	command := cfg.Versioning.Params["command"]
+	params := cfg.Versioning.Params
+	command := params["command"]
 
 	if build.IsWindows {
 		command = strings.ReplaceAll(command, `\`, `\\`)
 	}
 
+	// Commands run for as long as they take unless a timeout is set.
+	var timeout time.Duration
+	if timeoutS, _ := strconv.Atoi(params["timeoutS"]); timeoutS > 0 {
+		timeout = time.Duration(timeoutS) * time.Second
+	}
+
 	s := external{
 		command:    command,
 		filesystem: cfg.Filesystem(),
+		folderID:   cfg.ID,
+		timeout:    timeout,
+		cleanEnv:   params["cleanEnv"] == "true",
+	}
+	if maxConcurrent, _ := strconv.Atoi(params["maxConcurrent"]); maxConcurrent > 0 {
+		s.limiter = semaphore.New(maxConcurrent)
 	}
 
 	l.Debugf("instantiated %#v", s)
 	return s
 }
 
+// ExternalCommandError is the outcome of an external versioning command
+// that failed.
+type ExternalCommandError struct {
+	Command  string        `json:"command"`
+	ExitCode int           `json:"exitCode"` // -1 if it didn't exit by itself
+	TimedOut bool          `json:"timedOut"`
+	Duration time.Duration `json:"duration"`
+	Output   string        `json:"output"` // the end of the combined output
+	Err      error         `json:"-"`
+}
+
+func (e *ExternalCommandError) Error() string {
+	var msg string
+	if e.TimedOut {
+		msg = fmt.Sprintf("command %s timed out after %v", e.Command, e.Duration.Truncate(time.Millisecond))
+	} else {
+		msg = fmt.Sprintf("command %s failed after %v: %v", e.Command, e.Duration.Truncate(time.Millisecond), e.Err)
+	}
+	if e.Output != "" {
+		msg += ": " + e.Output
+	}
+	return msg
+}
+
+func (e *ExternalCommandError) Unwrap() error {
+	return e.Err
+}
+
 // Archive moves the named file away to a version archive. If this function
 // returns nil, the named file does not exist any more (has been archived).
 func (v external) Archive(filePath string) error {
-	info, err := v.filesystem.Lstat(filePath)
+	return v.archiveWithInfo(filePath, ArchiveInfo{})
+}
+
+func (v external) archiveWithInfo(filePath string, info ArchiveInfo) error {
+	fileInfo, err := v.filesystem.Lstat(filePath)
 	if fs.IsNotExist(err) {
 		l.Debugln("not archiving nonexistent file", filePath)
 		return nil
 	} else if err != nil {
 		return err
 	}
-	if info.IsSymlink() {
+	if fileInfo.IsSymlink() {
 		panic("bug: attempting to version a symlink")
 	}
 
@@ -76,7 +139,12 @@ func (v external) Archive(filePath string) error {
 	context := map[string]string{
 		"%FOLDER_FILESYSTEM%": string(v.filesystem.Type()),
 		"%FOLDER_PATH%":       v.filesystem.URI(),
+		"%FOLDER_ID%":         v.folderID,
 		"%FILE_PATH%":         filePath,
+		"%FILE_MOD_TIME%":     fileInfo.ModTime().UTC().Format(time.RFC3339),
+		"%ARCHIVE_TIME%":      time.Now().UTC().Format(time.RFC3339),
+		"%MODIFIED_BY%":       info.ModifiedBy,
+		"%CONFLICT%":          strconv.FormatBool(info.Conflict),
 	}
 
 	for i, word := range words {
@@ -87,23 +155,7 @@ func (v external) Archive(filePath string) error {
 		words[i] = word
 	}
 
-	cmd := exec.Command(words[0], words[1:]...)
-	env := os.Environ()
-	// filter STGUIAUTH and STGUIAPIKEY from environment variables
-	var filteredEnv []string
-
-	for _, x := range env {
-		if !strings.HasPrefix(x, "STGUIAUTH=") && !strings.HasPrefix(x, "STGUIAPIKEY=") {
-			filteredEnv = append(filteredEnv, x)
-		}
-	}
-	cmd.Env = filteredEnv
-	combinedOutput, err := cmd.CombinedOutput()
-	l.Debugln("external command output:", string(combinedOutput))
-	if err != nil {
-		if eerr, ok := err.(*exec.ExitError); ok && len(eerr.Stderr) > 0 {
-			return fmt.Errorf("%v: %v", err, string(eerr.Stderr))
-		}
+	if err := v.run(words); err != nil {
 		return err
 	}
 
@@ -114,6 +166,69 @@ func (v external) Archive(filePath string) error {
 	return errors.New("file was not removed by external script")
 }
 
+// run runs the command within the limits on time and concurrency.
+func (v external) run(words []string) error {
+	if v.limiter != nil {
+		v.limiter.Take(1)
+		defer v.limiter.Give(1)
+	}
+
+	ctx := context.Background()
+	if v.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, words[0], words[1:]...)
+	cmd.WaitDelay = externalWaitDelay
+	cmd.Env = v.environment()
+	t0 := time.Now()
+	combinedOutput, err := cmd.CombinedOutput()
+	l.Debugln("external command output:", string(combinedOutput))
+	if err == nil {
+		return nil
+	}
+
+	cerr := &ExternalCommandError{
+		Command:  words[0],
+		ExitCode: -1,
+		TimedOut: ctx.Err() != nil,
+		Duration: time.Since(t0),
+		Output:   strings.TrimSpace(string(combinedOutput)),
+		Err:      err,
+	}
+	if eerr, ok := err.(*exec.ExitError); ok {
+		cerr.ExitCode = eerr.ExitCode()
+	}
+	if len(cerr.Output) > externalMaxOutput {
+		cerr.Output = "..." + cerr.Output[len(cerr.Output)-externalMaxOutput:]
+	}
+	return cerr
+}
+
+// environment returns the environment for the command: ours without the
+// GUI credentials and the backup passphrase, or only the basics if a clean
+// environment is wanted.
+func (v external) environment() []string {
+	var env []string
+	for _, x := range os.Environ() {
+		name, _, _ := strings.Cut(x, "=")
+		if v.cleanEnv {
+			if slices.ContainsFunc(externalCleanEnv, func(keep string) bool { return strings.EqualFold(keep, name) }) {
+				env = append(env, x)
+			}
+			continue
+		}
+		// filter STGUIAUTH, STGUIAPIKEY and STBACKUPPASSPHRASE from
+		// environment variables
+		if name != "STGUIAUTH" && name != "STGUIAPIKEY" && name != "STBACKUPPASSPHRASE" {
+			env = append(env, x)
+		}
+	}
+	return env
+}
+
 func (external) GetVersions() (map[string][]FileVersion, error) {
 	return nil, ErrRestorationNotSupported
 }
@@ -122,6 +237,10 @@ func (external) Restore(_ string, _ time.Time) error {
 	return ErrRestorationNotSupported
 }
 
+func (external) OpenVersion(_ string, _ time.Time) (fs.File, error) {
+	return nil, ErrRestorationNotSupported
+}
+
 func (external) Clean(_ context.Context) error {
 	return nil
 }
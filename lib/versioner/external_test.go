@@ -7,9 +7,13 @@
 package versioner
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/syncthing/syncthing/lib/build"
 	"github.com/syncthing/syncthing/lib/fs"
@@ -76,6 +80,82 @@ func TestExternal(t *testing.T) {
 	}
 }
 
+func TestExternalTemplate(t *testing.T) {
+	if build.IsWindows {
+		t.Skip("uses sh")
+	}
+
+	file := filepath.Join("testdata", "folder path", "file.txt")
+	prepForRemoval(t, file)
+	defer os.RemoveAll("testdata")
+
+	e := external{
+		filesystem: fs.NewFilesystem(fs.FilesystemTypeBasic, "."),
+		folderID:   "default",
+		command:    `sh -c 'echo "$1 $2 $3" > testdata/out && rm -f "$4"' sh %FOLDER_ID% %MODIFIED_BY% %CONFLICT% %FILE_PATH%`,
+	}
+	if err := e.archiveWithInfo(file, ArchiveInfo{ModifiedBy: "device", Conflict: true}); err != nil {
+		t.Fatal(err)
+	}
+	bs, err := os.ReadFile(filepath.Join("testdata", "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := string(bs); got != "default device true\n" {
+		t.Errorf("got %q, expected the variables filled in", got)
+	}
+}
+
+func TestExternalFailure(t *testing.T) {
+	if build.IsWindows {
+		t.Skip("uses sh")
+	}
+
+	file := filepath.Join("testdata", "file.txt")
+	prepForRemoval(t, file)
+	defer os.RemoveAll("testdata")
+
+	e := external{
+		filesystem: fs.NewFilesystem(fs.FilesystemTypeBasic, "."),
+		command:    `sh -c 'echo no space left; exit 3'`,
+	}
+	var cerr *ExternalCommandError
+	if err := e.Archive(file); !errors.As(err, &cerr) {
+		t.Fatalf("expected a command error, got %v", err)
+	}
+	if cerr.ExitCode != 3 || cerr.TimedOut || cerr.Output != "no space left" {
+		t.Errorf("unexpected outcome %+v", cerr)
+	}
+
+	e.command = "sleep 10"
+	e.timeout = 100 * time.Millisecond
+	if err := e.Archive(file); !errors.As(err, &cerr) || !cerr.TimedOut {
+		t.Fatalf("expected a timeout, got %v", err)
+	}
+	if _, err := os.Lstat(file); err != nil {
+		t.Error("File should still exist")
+	}
+}
+
+func TestExternalEnvironment(t *testing.T) {
+	t.Setenv("STGUIAPIKEY", "secret")
+	t.Setenv("STBACKUPPASSPHRASE", "secret")
+	t.Setenv("STEXTERNALTEST", "kept")
+
+	env := external{}.environment()
+	for _, x := range env {
+		if strings.HasPrefix(x, "STGUIAPIKEY=") || strings.HasPrefix(x, "STBACKUPPASSPHRASE=") {
+			t.Errorf("secret passed to the command: %s", x)
+		}
+	}
+	if !slices.Contains(env, "STEXTERNALTEST=kept") {
+		t.Error("expected other variables to be passed to the command")
+	}
+	if slices.Contains(external{cleanEnv: true}.environment(), "STEXTERNALTEST=kept") {
+		t.Error("expected only the basics in a clean environment")
+	}
+}
+
 func prepForRemoval(t *testing.T, file string) {
 	if err := os.RemoveAll("testdata"); err != nil {
 		t.Fatal(err)
@@ -71,6 +71,10 @@ func (v simple) Restore(filepath string, versionTime time.Time) error {
 	return restoreFile(v.copyRangeMethod, v.versionsFs, v.folderFs, filepath, versionTime, TagFilename)
 }
 
+func (v simple) OpenVersion(filePath string, versionTime time.Time) (fs.File, error) {
+	return openVersion(v.versionsFs, filePath, versionTime)
+}
+
 func (v simple) Clean(ctx context.Context) error {
 	return clean(ctx, v.versionsFs, v.toRemove)
 }
@@ -0,0 +1,109 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+package versioner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/syncthing/syncthing/internal/slogutil"
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+	"github.com/syncthing/syncthing/lib/osutil"
+)
+
+func init() {
+	// Register the constructor for this type of versioner
+	factories["systemtrash"] = newSystemTrash
+}
+
+var errSystemTrashUnsupported = errors.New("no system trash available")
+
+// osTrash moves files into the trash of the operating system.
+type osTrash interface {
+	Trash(path string) error
+}
+
+// systemTrash hands deleted and replaced files to the trash or recycle bin
+// of the operating system: the FreeDesktop trash on Linux and BSD, the
+// Recycle Bin on Windows and the Trash on macOS. Old versions are then
+// managed, browsed and restored using the tools of the operating system
+// rather than by us.
+type systemTrash struct {
+	folderFs fs.Filesystem
+	trash    osTrash
+	err      error // why there is no system trash for this folder
+}
+
+func newSystemTrash(cfg config.FolderConfiguration) Versioner {
+	s := &systemTrash{
+		folderFs: cfg.Filesystem(),
+	}
+	if s.folderFs.Type() != fs.FilesystemTypeBasic {
+		s.err = fmt.Errorf("%w: filesystem type %s", errSystemTrashUnsupported, s.folderFs.Type())
+	} else {
+		s.trash, s.err = newOSTrash(s.folderFs.URI())
+	}
+	if s.err != nil {
+		// Archiving will fail, so deletions and replacements are refused
+		// rather than done without a way back.
+		slog.Warn("System trash versioning unavailable; files will be kept instead of deleted", cfg.LogAttr(), slogutil.Error(s.err))
+	}
+
+	l.Debugf("instantiated %#v", s)
+	return s
+}
+
+// Archive moves the named file to the system trash. If this function
+// returns nil, the named file does not exist any more (has been archived).
+func (t *systemTrash) Archive(filePath string) error {
+	filePath = osutil.NativeFilename(filePath)
+	info, err := t.folderFs.Lstat(filePath)
+	if fs.IsNotExist(err) {
+		l.Debugln("not archiving nonexistent file", filePath)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if info.IsSymlink() {
+		panic("bug: attempting to version a symlink")
+	}
+	if t.err != nil {
+		return t.err
+	}
+
+	l.Debugln("archiving", filePath, "to system trash")
+	return t.trash.Trash(filepath.Join(t.folderFs.URI(), filePath))
+}
+
+func (t *systemTrash) String() string {
+	return fmt.Sprintf("systemtrash@%p", t)
+}
+
+// Clean does nothing, as the operating system or the user empties the
+// trash.
+func (*systemTrash) Clean(context.Context) error {
+	return nil
+}
+
+// GetVersions returns nothing, as files in the system trash are browsed
+// using the tools of the operating system.
+func (*systemTrash) GetVersions() (map[string][]FileVersion, error) {
+	return map[string][]FileVersion{}, nil
+}
+
+func (*systemTrash) Restore(string, time.Time) error {
+	return ErrRestorationNotSupported
+}
+
+func (*systemTrash) OpenVersion(string, time.Time) (fs.File, error) {
+	return nil, ErrRestorationNotSupported
+}
@@ -0,0 +1,70 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build darwin && !ios
+
+package versioner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// macTrash moves files to the Trash of the user, which is ~/.Trash for the
+// volume of the home directory and /.Trashes/$uid on other volumes.
+type macTrash struct {
+	uid       int
+	homeTrash string
+}
+
+func newOSTrash(folderPath string) (osTrash, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errSystemTrashUnsupported, err)
+	}
+	t := &macTrash{
+		uid:       os.Getuid(),
+		homeTrash: filepath.Join(home, ".Trash"),
+	}
+
+	// There must be a usable Trash for the volume the folder is on.
+	if _, err := t.trashDir(folderPath); err != nil {
+		return nil, fmt.Errorf("%w: %w", errSystemTrashUnsupported, err)
+	}
+	return t, nil
+}
+
+func (t *macTrash) Trash(path string) error {
+	dir, err := t.trashDir(path)
+	if err != nil {
+		return err
+	}
+	name, err := trashName(filepath.Base(path), func(name string) (bool, error) {
+		return exists(filepath.Join(dir, name))
+	})
+	if err != nil {
+		return err
+	}
+	return os.Rename(path, filepath.Join(dir, name))
+}
+
+func (t *macTrash) trashDir(path string) (string, error) {
+	dev, err := deviceOf(path)
+	if err != nil {
+		return "", err
+	}
+	if homeDev, err := deviceOf(t.homeTrash); err == nil && homeDev == dev {
+		return t.homeTrash, nil
+	}
+
+	dir := filepath.Join(mountRoot(path, dev), ".Trashes", strconv.Itoa(t.uid))
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
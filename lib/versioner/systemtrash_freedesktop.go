@@ -0,0 +1,151 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build unix && !darwin && !android
+
+package versioner
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// freedesktopTrash implements the FreeDesktop.org trash specification,
+// https://specifications.freedesktop.org/trash-spec/latest/, as used by
+// the desktop environments on Linux and the BSDs.
+type freedesktopTrash struct {
+	uid       int
+	homeTrash string
+}
+
+func newOSTrash(folderPath string) (osTrash, error) {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errSystemTrashUnsupported, err)
+		}
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	t := &freedesktopTrash{
+		uid:       os.Getuid(),
+		homeTrash: filepath.Join(dataHome, "Trash"),
+	}
+
+	// There must be a usable trash for the filesystem the folder is on.
+	if _, _, err := t.trashDir(folderPath); err != nil {
+		return nil, fmt.Errorf("%w: %w", errSystemTrashUnsupported, err)
+	}
+	return t, nil
+}
+
+func (t *freedesktopTrash) Trash(path string) error {
+	dir, topdir, err := t.trashDir(path)
+	if err != nil {
+		return err
+	}
+
+	// The path in the trash info is relative to the top directory for
+	// trash directories on other filesystems than the home directory.
+	origPath := path
+	if topdir != "" {
+		if origPath, err = filepath.Rel(topdir, path); err != nil {
+			return err
+		}
+	}
+
+	// Reserve a name by atomically creating the info file, as the spec
+	// says.
+	var infoFile *os.File
+	name, err := trashName(filepath.Base(path), func(name string) (bool, error) {
+		if inUse, err := exists(filepath.Join(dir, "files", name)); inUse || err != nil {
+			return inUse, err
+		}
+		fd, err := os.OpenFile(filepath.Join(dir, "info", name+".trashinfo"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if errors.Is(err, fs.ErrExist) {
+			return true, nil
+		} else if err != nil {
+			return false, err
+		}
+		infoFile = fd
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+	infoPath := infoFile.Name()
+
+	info := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n", (&url.URL{Path: origPath}).EscapedPath(), time.Now().Format("2006-01-02T15:04:05"))
+	_, err = infoFile.WriteString(info)
+	if cerr := infoFile.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(path, filepath.Join(dir, "files", name))
+	}
+	if err != nil {
+		_ = os.Remove(infoPath)
+		return err
+	}
+	return nil
+}
+
+// trashDir returns the trash directory to use for the given path, and the
+// top directory of the mount it belongs to if that is not the home trash.
+func (t *freedesktopTrash) trashDir(path string) (string, string, error) {
+	dev, err := deviceOf(path)
+	if err != nil {
+		return "", "", err
+	}
+
+	if err := ensureTrashDir(t.homeTrash); err != nil {
+		return "", "", err
+	}
+	if homeDev, err := deviceOf(t.homeTrash); err == nil && homeDev == dev {
+		return t.homeTrash, "", nil
+	}
+
+	topdir := mountRoot(path, dev)
+	uid := strconv.Itoa(t.uid)
+
+	// An administrator provided $topdir/.Trash, which must be a sticky
+	// directory and not a symlink.
+	if info, err := os.Lstat(filepath.Join(topdir, ".Trash")); err == nil && info.IsDir() && info.Mode()&fs.ModeSticky != 0 {
+		dir := filepath.Join(topdir, ".Trash", uid)
+		if err := ensureTrashDir(dir); err == nil {
+			return dir, topdir, nil
+		}
+	}
+
+	// Otherwise our own $topdir/.Trash-$uid.
+	dir := filepath.Join(topdir, ".Trash-"+uid)
+	if err := ensureTrashDir(dir); err != nil {
+		return "", "", err
+	}
+	return dir, topdir, nil
+}
+
+// ensureTrashDir creates the trash directory with its files and info
+// subdirectories, if they don't exist.
+func ensureTrashDir(dir string) error {
+	for _, sub := range []string{"files", "info"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o700); err != nil {
+			return err
+		}
+	}
+	if info, err := os.Lstat(dir); err != nil {
+		return err
+	} else if !info.IsDir() {
+		return errors.New("trash is not a directory")
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build unix && !darwin && !android
+
+package versioner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
+)
+
+func TestSystemTrashFreedesktop(t *testing.T) {
+	dataHome := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", dataHome)
+
+	cfg := config.FolderConfiguration{
+		FilesystemType: config.FilesystemTypeBasic,
+		Path:           t.TempDir(),
+	}
+	folderFs := cfg.Filesystem()
+	versioner := newSystemTrash(cfg)
+
+	// Archive the same name twice; the second one must get a new name in
+	// the trash.
+	for _, content := range []string{"A", "B"} {
+		writeFile(t, folderFs, "a file.txt", content)
+		if err := versioner.Archive("a file.txt"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := folderFs.Stat("a file.txt"); !fs.IsNotExist(err) {
+			t.Fatal("file still exists after archiving")
+		}
+	}
+
+	trash := filepath.Join(dataHome, "Trash")
+	for _, name := range []string{"a file.txt", "a file.2.txt"} {
+		if _, err := os.Stat(filepath.Join(trash, "files", name)); err != nil {
+			t.Error(err)
+		}
+		info, err := os.ReadFile(filepath.Join(trash, "info", name+".trashinfo"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(info), "[Trash Info]\n") || !strings.Contains(string(info), "/a%20file.txt\n") {
+			t.Errorf("unexpected trash info for %s: %q", name, info)
+		}
+	}
+
+	if err := versioner.Restore("a file.txt", time.Now()); !errors.Is(err, ErrRestorationNotSupported) {
+		t.Errorf("expected restoration to be unsupported, got %v", err)
+	}
+}
+
+func TestSystemTrashUnsupportedFilesystem(t *testing.T) {
+	cfg := config.FolderConfiguration{
+		FilesystemType: config.FilesystemTypeFake,
+		Path:           "systemtrash?content=true",
+	}
+	folderFs := cfg.Filesystem()
+	versioner := newSystemTrash(cfg)
+
+	writeFile(t, folderFs, "file", "A")
+	if err := versioner.Archive("file"); !errors.Is(err, errSystemTrashUnsupported) {
+		t.Errorf("expected archiving to fail, got %v", err)
+	}
+	if _, err := folderFs.Stat("file"); err != nil {
+		t.Error("file must be kept when there is no system trash:", err)
+	}
+}
+
+func TestTrashName(t *testing.T) {
+	taken := map[string]bool{"file.txt": true, "file.2.txt": true}
+	name, err := trashName("file.txt", func(name string) (bool, error) {
+		return taken[name], nil
+	})
+	if err != nil || name != "file.3.txt" {
+		t.Errorf("expected file.3.txt, got %q, %v", name, err)
+	}
+
+	// Failing to check a name is an error, not a name in use.
+	errCheck := errors.New("permission denied")
+	calls := 0
+	if _, err := trashName("file.txt", func(string) (bool, error) {
+		calls++
+		return false, errCheck
+	}); !errors.Is(err, errCheck) || calls != 1 {
+		t.Errorf("expected the check error after one call, got %v after %d", err, calls)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build unix && !android && !ios
+
+package versioner
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// deviceOf returns the device the path resides on.
+func deviceOf(path string) (uint64, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return 0, err
+	}
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, errors.New("no device information")
+	}
+	return uint64(st.Dev), nil
+}
+
+// mountRoot returns the top directory of the mount the path, residing on
+// the given device, belongs to.
+func mountRoot(path string, dev uint64) string {
+	path = filepath.Clean(path)
+	for {
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		if pdev, err := deviceOf(parent); err != nil || pdev != dev {
+			return path
+		}
+		path = parent
+	}
+}
+
+// trashName returns a name for the file in the trash that does not exist
+// there yet, by appending a number to the base name if necessary. Taken
+// reports whether a name is in use; an error from it is returned as is.
+func trashName(name string, taken func(string) (bool, error)) (string, error) {
+	ext := filepath.Ext(name)
+	base := name[:len(name)-len(ext)]
+	candidate := name
+	for i := 2; i < 1000; i++ {
+		inUse, err := taken(candidate)
+		if err != nil {
+			return "", err
+		}
+		if !inUse {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s.%d%s", base, i, ext)
+	}
+	return "", fmt.Errorf("no free name for %q in trash", name)
+}
+
+func exists(path string) (bool, error) {
+	_, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,18 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build (!unix && !windows) || android || ios
+
+package versioner
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func newOSTrash(string) (osTrash, error) {
+	return nil, fmt.Errorf("%w on %s", errSystemTrashUnsupported, runtime.GOOS)
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2025 The Syncthing Authors.
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this file,
+// You can obtain one at https://mozilla.org/MPL/2.0/.
+
+//go:build windows
+
+package versioner
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+var procSHFileOperationW = windows.NewLazySystemDLL("shell32.dll").NewProc("SHFileOperationW")
+
+const (
+	foDelete = 0x0003
+
+	fofSilent         = 0x0004
+	fofNoConfirmation = 0x0010
+	fofAllowUndo      = 0x0040
+	fofNoErrorUI      = 0x0400
+)
+
+// shFileOpStruct is SHFILEOPSTRUCTW. It is packed on 32 bit Windows, which
+// this layout doesn't match, so we only use it on 64 bit.
+type shFileOpStruct struct {
+	hwnd                  uintptr
+	wFunc                 uint32
+	pFrom                 *uint16
+	pTo                   *uint16
+	fFlags                uint16
+	fAnyOperationsAborted int32
+	hNameMappings         uintptr
+	lpszProgressTitle     *uint16
+}
+
+// recycleBin moves files to the Recycle Bin using the shell.
+type recycleBin struct{}
+
+func newOSTrash(folderPath string) (osTrash, error) {
+	if unsafe.Sizeof(uintptr(0)) != 8 {
+		return nil, fmt.Errorf("%w: 32 bit Windows", errSystemTrashUnsupported)
+	}
+
+	// Only fixed drives have a Recycle Bin; on other drives the shell
+	// deletes files outright, even when asked to allow undo.
+	volume := filepath.VolumeName(shellPath(folderPath))
+	if volume == "" || strings.HasPrefix(volume, `\\`) {
+		return nil, fmt.Errorf("%w: network share", errSystemTrashUnsupported)
+	}
+	root, err := windows.UTF16PtrFromString(volume + `\`)
+	if err != nil {
+		return nil, err
+	}
+	if driveType := windows.GetDriveType(root); driveType != windows.DRIVE_FIXED {
+		return nil, fmt.Errorf("%w: drive type %d", errSystemTrashUnsupported, driveType)
+	}
+	return recycleBin{}, nil
+}
+
+func (recycleBin) Trash(path string) error {
+	from, err := windows.UTF16FromString(shellPath(path))
+	if err != nil {
+		return err
+	}
+	// The list of files is terminated by an additional NUL.
+	from = append(from, 0)
+
+	op := shFileOpStruct{
+		wFunc:  foDelete,
+		pFrom:  &from[0],
+		fFlags: fofAllowUndo | fofNoConfirmation | fofSilent | fofNoErrorUI,
+	}
+	if ret, _, _ := procSHFileOperationW.Call(uintptr(unsafe.Pointer(&op))); ret != 0 {
+		return fmt.Errorf("moving to recycle bin: error code %#x", ret)
+	}
+	if op.fAnyOperationsAborted != 0 {
+		return errors.New("moving to recycle bin: aborted")
+	}
+	return nil
+}
+
+// shellPath strips the long path prefix, which the shell doesn't accept.
+func shellPath(path string) string {
+	if strings.HasPrefix(path, `\\?\UNC\`) {
+		return `\\` + path[len(`\\?\UNC\`):]
+	}
+	return strings.TrimPrefix(path, `\\?\`)
+}
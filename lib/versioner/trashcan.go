@@ -146,3 +146,7 @@ func (t *trashcan) Restore(filepath string, versionTime time.Time) error {
 
 	return t.versionsFs.Rename(taggedName, filepath)
 }
+
+func (t *trashcan) OpenVersion(filePath string, versionTime time.Time) (fs.File, error) {
+	return openVersion(t.versionsFs, filePath, versionTime)
+}
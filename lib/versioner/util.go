@@ -264,29 +264,8 @@ func restoreFile(method fs.CopyRangeMethod, src, dst fs.Filesystem, filePath str
 
 	filePath = osutil.NativeFilename(filePath)
 
-	// Try and find a file that has the correct mtime
-	sourceFile := ""
-	sourceMtime := time.Time{}
-	if info, err := src.Lstat(taggedFilePath); err == nil && info.IsRegular() {
-		sourceFile = taggedFilePath
-		sourceMtime = info.ModTime()
-	} else if err == nil {
-		l.Debugln("restore:", taggedFilePath, "not regular")
-	} else {
-		l.Debugln("restore:", taggedFilePath, err.Error())
-	}
-
-	// Check for untagged file
-	if sourceFile == "" {
-		info, err := src.Lstat(filePath)
-		if err == nil && info.IsRegular() && info.ModTime().Truncate(time.Second).Equal(versionTime) {
-			sourceFile = filePath
-			sourceMtime = info.ModTime()
-		}
-
-	}
-
-	if sourceFile == "" {
+	sourceFile, sourceMtime, ok := findVersion(src, filePath, taggedFilePath, versionTime)
+	if !ok {
 		return errNotFound
 	}
 
@@ -304,6 +283,40 @@ func restoreFile(method fs.CopyRangeMethod, src, dst fs.Filesystem, filePath str
 	return err
 }
 
+// findVersion returns the name and modification time of the archived
+// version of the file, tagged or, failing that, untagged with the correct
+// mtime.
+func findVersion(src fs.Filesystem, filePath, taggedFilePath string, versionTime time.Time) (string, time.Time, bool) {
+	if info, err := src.Lstat(taggedFilePath); err == nil && info.IsRegular() {
+		return taggedFilePath, info.ModTime(), true
+	} else if err == nil {
+		l.Debugln("restore:", taggedFilePath, "not regular")
+	} else {
+		l.Debugln("restore:", taggedFilePath, err.Error())
+	}
+
+	// Check for untagged file
+	info, err := src.Lstat(filePath)
+	if err == nil && info.IsRegular() && info.ModTime().Truncate(time.Second).Equal(versionTime) {
+		return filePath, info.ModTime(), true
+	}
+	return "", time.Time{}, false
+}
+
+// openVersion opens the archived version of the file from the given time,
+// for reading.
+func openVersion(src fs.Filesystem, filePath string, versionTime time.Time) (fs.File, error) {
+	tag := versionTime.In(time.Local).Truncate(time.Second).Format(TimeFormat)
+	taggedFilePath := TagFilename(filePath, tag)
+	filePath = osutil.NativeFilename(filePath)
+
+	name, _, ok := findVersion(src, filePath, taggedFilePath, versionTime)
+	if !ok {
+		return nil, errNotFound
+	}
+	return src.Open(name)
+}
+
 func versionerFsFromFolderCfg(cfg config.FolderConfiguration) (versionsFs fs.Filesystem) {
 	folderFs := cfg.Filesystem()
 	if cfg.Versioning.FSPath == "" {
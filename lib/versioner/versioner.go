@@ -15,12 +15,16 @@ import (
 	"time"
 
 	"github.com/syncthing/syncthing/lib/config"
+	"github.com/syncthing/syncthing/lib/fs"
 )
 
 type Versioner interface {
 	Archive(filePath string) error
 	GetVersions() (map[string][]FileVersion, error)
 	Restore(filePath string, versionTime time.Time) error
+	// OpenVersion opens an archived version of the file for reading,
+	// leaving the archive as is.
+	OpenVersion(filePath string, versionTime time.Time) (fs.File, error)
 	Clean(context.Context) error
 }
 
@@ -30,6 +34,30 @@ type FileVersion struct {
 	Size        int64     `json:"size"`
 }
 
+// ArchiveInfo describes the change a file is archived for, for the
+// versioners that make use of it.
+type ArchiveInfo struct {
+	// The device that made the change, if known
+	ModifiedBy string
+	// The file is replaced by the copy it was in conflict with
+	Conflict bool
+}
+
+// infoArchiver is implemented by versioners that make use of what a file is
+// archived for.
+type infoArchiver interface {
+	archiveWithInfo(filePath string, info ArchiveInfo) error
+}
+
+// ArchiveWithInfo archives the file like Archive, telling the versioner
+// about the change it is archived for.
+func ArchiveWithInfo(v Versioner, filePath string, info ArchiveInfo) error {
+	if a, ok := v.(infoArchiver); ok {
+		return a.archiveWithInfo(filePath, info)
+	}
+	return v.Archive(filePath)
+}
+
 type factory func(cfg config.FolderConfiguration) Versioner
 
 var factories = make(map[string]factory)
@@ -69,6 +97,10 @@ func (v *versionerWithErrorContext) Archive(filePath string) error {
 	return v.wrapError(v.Versioner.Archive(filePath), "archive")
 }
 
+func (v *versionerWithErrorContext) archiveWithInfo(filePath string, info ArchiveInfo) error {
+	return v.wrapError(ArchiveWithInfo(v.Versioner, filePath, info), "archive")
+}
+
 func (v *versionerWithErrorContext) GetVersions() (map[string][]FileVersion, error) {
 	versions, err := v.Versioner.GetVersions()
 	return versions, v.wrapError(err, "get versions")
@@ -78,6 +110,11 @@ func (v *versionerWithErrorContext) Restore(filePath string, versionTime time.Ti
 	return v.wrapError(v.Versioner.Restore(filePath, versionTime), "restore")
 }
 
+func (v *versionerWithErrorContext) OpenVersion(filePath string, versionTime time.Time) (fs.File, error) {
+	fd, err := v.Versioner.OpenVersion(filePath, versionTime)
+	return fd, v.wrapError(err, "open version")
+}
+
 func (v *versionerWithErrorContext) Clean(ctx context.Context) error {
 	return v.wrapError(v.Versioner.Clean(ctx), "clean")
 }
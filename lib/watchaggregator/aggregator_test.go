@@ -370,3 +370,36 @@ func testAggregatorOutput(t *testing.T, fsWatchChan <-chan []string, expectedBat
 		}
 	}
 }
+
+// BenchmarkAggregateSynthetic measures aggregating a burst of change events
+// spread over a folder with a million files.
+func BenchmarkAggregateSynthetic(b *testing.B) {
+	const changes = 100000
+	folderCfg := defaultFolderCfg.Copy()
+	folderCfg.ID = "Synthetic"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sfs := fs.NewFilesystem(fs.FilesystemTypeSynthetic, "?files=1000000&fanout=100&changes="+strconv.Itoa(changes))
+
+	b.ReportAllocs()
+	for range b.N {
+		b.StopTimer()
+		wctx, wcancel := context.WithCancel(ctx)
+		in, _, err := sfs.Watch(".", nil, wctx, false)
+		if err != nil {
+			b.Fatal(err)
+		}
+		a := newAggregator(ctx, folderCfg)
+		inProgress := make(map[string]struct{})
+		b.StartTimer()
+
+		for range changes {
+			a.newEvent(<-in, inProgress)
+		}
+
+		b.StopTimer()
+		wcancel()
+		b.StartTimer()
+	}
+	b.ReportMetric(changes*float64(b.N)/b.Elapsed().Seconds(), "events/s")
+}